@@ -29,6 +29,8 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/consumer/entertainment"
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/reports"
+	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/services"
 	"github.com/mysteriumnetwork/node/tequilapi"
 	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
@@ -62,25 +64,79 @@ func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener ne
 				return nil
 			},
 			func(e *gin.Engine) error {
-				e.GET("/healthcheck", tequilapi_endpoints.HealthCheckEndpointFactory(time.Now, os.Getpid).HealthCheck)
+				if di.NodeAttester != nil {
+					return tequilapi_endpoints.AddRoutesForNodeAttestation(di.NodeAttester)(e)
+				}
+				return nil
+			},
+			func(e *gin.Engine) error {
+				if di.AccessLog != nil {
+					return tequilapi_endpoints.AddRoutesForAccessLog(di.AccessLog)(e)
+				}
+				return nil
+			},
+			func(e *gin.Engine) error {
+				if di.PaymentHooks != nil {
+					return tequilapi_endpoints.AddRoutesForPaymentHooks(di.PaymentHooks)(e)
+				}
+				return nil
+			},
+			func(e *gin.Engine) error {
+				if di.AutoPricer != nil {
+					return tequilapi_endpoints.AddRoutesForAutoPricing(di.AutoPricer)(e)
+				}
+				return nil
+			},
+			func(e *gin.Engine) error {
+				if di.PacketCapturer != nil {
+					return tequilapi_endpoints.AddRoutesForPcap(di.PacketCapturer)(e)
+				}
+				return nil
+			},
+			func(e *gin.Engine) error {
+				if di.Preflight != nil {
+					return tequilapi_endpoints.AddRoutesForPreflight(di.Preflight)(e)
+				}
+				return nil
+			},
+			func(e *gin.Engine) error {
+				if di.SignAudit != nil {
+					return tequilapi_endpoints.AddRoutesForSignAudit(di.SignAudit)(e)
+				}
+				return nil
+			},
+			func(e *gin.Engine) error {
+				e.GET("/healthcheck", tequilapi_endpoints.HealthCheckEndpointFactory(time.Now, os.Getpid, di.Supervisor).HealthCheck)
 				return nil
 			},
 			tequilapi_endpoints.AddRouteForStop(utils.SoftKiller(di.Shutdown)),
 			tequilapi_endpoints.AddRoutesForAuthentication(di.Authenticator, di.JWTAuthenticator),
-			tequilapi_endpoints.AddRoutesForIdentities(di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.AddressProvider, di.HermesChannelRepository, di.BCHelper, di.Transactor, di.BeneficiaryProvider, di.IdentityMover, di.PayoutAddressStorage, di.HermesMigrator),
-			tequilapi_endpoints.AddRoutesForConnection(di.MultiConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.EventBus, di.AddressProvider),
-			tequilapi_endpoints.AddRoutesForSessions(di.SessionStorage),
+			tequilapi_endpoints.AddRoutesForIdentities(di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.AddressProvider, di.HermesChannelRepository, di.BCHelper, di.Transactor, di.BeneficiaryProvider, di.IdentityMover, di.PayoutAddressStorage, di.HermesMigrator, di.ProviderMigrator),
+			tequilapi_endpoints.AddRoutesForKeystore(di.KeystoreDoctor),
+			tequilapi_endpoints.AddRoutesForConnection(di.MultiConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.EventBus, di.AddressProvider, di.ProviderBlacklist, registry.NewAutoRegistrar(di.IdentityRegistry, di.Transactor, di.ConsumerBalanceTracker, di.AddressProvider, registry.DefaultAutoRegistrationTimeout), di.ConsumerWallet, di.EphemeralIdentities),
+			tequilapi_endpoints.AddRoutesForSessions(di.SessionStorage, di.ServiceSessions, di.BandwidthScheduler, di.SessionTraceStore, di.StateKeeper),
+			tequilapi_endpoints.AddRoutesForReports(reports.NewGenerator(di.SessionStorage, di.SettlementHistoryStorage)),
 			tequilapi_endpoints.AddRoutesForConnectionLocation(di.IPResolver, di.LocationResolver, di.LocationResolver),
-			tequilapi_endpoints.AddRoutesForProposals(di.ProposalRepository, di.PricingHelper, di.LocationResolver, di.FilterPresetStorage, di.NATProber),
+			tequilapi_endpoints.AddRoutesForProposals(di.ProposalRepository, di.PricingHelper, di.LocationResolver, di.FilterPresetStorage, di.NATProber, di.ProviderFavorites),
+			tequilapi_endpoints.AddRoutesForFavorites(di.ProviderFavorites),
+			tequilapi_endpoints.AddRoutesForWallet(di.ConsumerWallet),
+			tequilapi_endpoints.AddRoutesForCostEstimate(di.ProposalRepository, di.AddressProvider, di.HermesPromiseSettler, di.Transactor),
 			tequilapi_endpoints.AddRoutesForService(di.ServicesManager, services.JSONParsersByType, di.ProposalRepository, tequilaApiClient),
 			tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, config.GetString(config.FlagAccessPolicyAddress)),
+			tequilapi_endpoints.AddRoutesForFeatures(di.FeatureRegistry),
 			tequilapi_endpoints.AddRoutesForNAT(di.StateKeeper, di.NATProber),
+			tequilapi_endpoints.AddRoutesForState(di.StateKeeper),
+			tequilapi_endpoints.AddRoutesForStorage(di.Storage),
 			tequilapi_endpoints.AddRoutesForNodeUI(versionmanager.NewVersionManager(di.UIServer, di.HTTPClient, di.uiVersionConfig)),
 			tequilapi_endpoints.AddRoutesForNode(di.NodeStatusTracker, di.NodeStatsTracker),
 			tequilapi_endpoints.AddRoutesForTransactor(di.IdentityRegistry, di.Transactor, di.Affiliator, di.HermesPromiseSettler, di.SettlementHistoryStorage, di.AddressProvider, di.BeneficiaryProvider, di.BeneficiarySaver, di.PilvytisAPI),
+			tequilapi_endpoints.AddRoutesForPromises(di.HermesPromiseStorage, di.HermesPromiseSettler),
+			tequilapi_endpoints.AddRoutesForWithdrawal(di.WithdrawalFlow),
+			tequilapi_endpoints.AddRoutesForRegistration(di.PendingRegistrations),
 			tequilapi_endpoints.AddRoutesForAffiliator(di.Affiliator),
 			tequilapi_endpoints.AddRoutesForConfig,
 			tequilapi_endpoints.AddRoutesForMMN(di.MMN),
+			tequilapi_endpoints.AddRoutesForReferral(di.ReferralTracker),
 			tequilapi_endpoints.AddRoutesForFeedback(di.Reporter),
 			tequilapi_endpoints.AddRoutesForConnectivityStatus(di.SessionConnectivityStatusStorage),
 			tequilapi_endpoints.AddRoutesForDocs,
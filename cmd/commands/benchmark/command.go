@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package benchmark
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/mysteriumnetwork/node/core/benchmark"
+	"github.com/mysteriumnetwork/node/services/wireguard"
+	wireguard_benchmark "github.com/mysteriumnetwork/node/services/wireguard/benchmark"
+)
+
+// CommandName for the benchmark command.
+const CommandName = "benchmark"
+
+var (
+	flagDuration = cli.DurationFlag{
+		Name:  "duration",
+		Usage: "Time to drive the synthetic traffic through each service data plane",
+		Value: benchmark.DefaultDuration,
+	}
+	flagStreams = cli.IntFlag{
+		Name:  "streams",
+		Usage: "Number of concurrent streams",
+		Value: benchmark.DefaultStreams,
+	}
+	flagServices = cli.StringSliceFlag{
+		Name:  "services",
+		Usage: "Service types to benchmark, all supported ones if empty",
+	}
+)
+
+// NewCommand creates benchmark command.
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:      CommandName,
+		Usage:     "Measures achievable throughput and CPU cost of the service data planes over a local loopback",
+		ArgsUsage: " ",
+		Flags:     []cli.Flag{&flagDuration, &flagStreams, &flagServices},
+		Action: func(ctx *cli.Context) error {
+			if ctx.Int(flagStreams.Name) < 1 {
+				return fmt.Errorf("--%s must be positive", flagStreams.Name)
+			}
+
+			harness := benchmark.New(ctx.Duration(flagDuration.Name), ctx.Int(flagStreams.Name))
+			harness.Register(wireguard.ServiceType, wireguard_benchmark.NewLoopbackTunnel)
+
+			results := harness.Run(ctx.Context, ctx.StringSlice(flagServices.Name)...)
+
+			w := tabwriter.NewWriter(ctx.App.Writer, 1, 1, 2, ' ', 0)
+			fmt.Fprintln(w, "SERVICE\tTHROUGHPUT\tCPU/GB\tERROR")
+			for _, r := range results {
+				fmt.Fprintf(w, "%s\t%.1f Mbps\t%s\t%s\n", r.ServiceType, r.ThroughputMbps(), r.CPUPerGB().Round(1e6), r.Error)
+			}
+			return w.Flush()
+		},
+	}
+}
@@ -133,6 +133,10 @@ func (sc *serviceCommand) Run(ctx *cli.Context) (err error) {
 	)
 	log.Info().Msgf("Unlocked identity: %v", providerID)
 
+	if err := sc.preflight(providerID, serviceTypes); err != nil {
+		return err
+	}
+
 	for _, serviceType := range serviceTypes {
 		serviceOpts, err := services.GetStartOptions(serviceType)
 		if err != nil {
@@ -151,6 +155,31 @@ func (sc *serviceCommand) Run(ctx *cli.Context) (err error) {
 	return <-sc.errorChannel
 }
 
+// preflight prints the provider startup checks report, failed checks prevent services from starting only in strict mode.
+func (sc *serviceCommand) preflight(providerID string, serviceTypes []string) error {
+	report, err := sc.tequilapi.ProviderPreflight(providerID, serviceTypes)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to run provider startup checks")
+		return nil
+	}
+
+	for _, check := range report.Checks {
+		switch {
+		case check.Skipped:
+			clio.Info(fmt.Sprintf("Startup check %s skipped: %s", check.Name, check.Details))
+		case check.OK:
+			clio.Success(fmt.Sprintf("Startup check %s passed: %s", check.Name, check.Details))
+		default:
+			clio.Error(fmt.Sprintf("Startup check %s failed: %s", check.Name, check.Error))
+		}
+	}
+
+	if !report.Passed && config.GetBool(config.FlagPreflightStrict) {
+		return errors.New("provider startup checks failed")
+	}
+	return nil
+}
+
 func (sc *serviceCommand) unlockIdentity(id, passphrase string) string {
 	const retryRate = 10 * time.Second
 	for {
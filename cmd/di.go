@@ -18,6 +18,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"net/http"
@@ -32,36 +33,54 @@ import (
 	"github.com/rs/zerolog/log"
 
 	paymentClient "github.com/mysteriumnetwork/payments/client"
-	psort "github.com/mysteriumnetwork/payments/client/sort"
+	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/mysteriumnetwork/payments/observer"
 
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/blacklist"
+	"github.com/mysteriumnetwork/node/consumer/ephemeral"
+	"github.com/mysteriumnetwork/node/consumer/favorites"
 	"github.com/mysteriumnetwork/node/consumer/migration"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/consumer/wallet"
+	"github.com/mysteriumnetwork/node/core/accesslog"
+	"github.com/mysteriumnetwork/node/core/attestation"
 	"github.com/mysteriumnetwork/node/core/auth"
 	"github.com/mysteriumnetwork/node/core/beneficiary"
+	"github.com/mysteriumnetwork/node/core/chain"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/connection/leaktest"
 	"github.com/mysteriumnetwork/node/core/discovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/feature"
+	"github.com/mysteriumnetwork/node/core/hooks"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/core/nodeattest"
 	"github.com/mysteriumnetwork/node/core/payout"
+	"github.com/mysteriumnetwork/node/core/pcap"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/preflight"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/core/referral"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/core/state"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/core/supervision"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/feedback"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/audit"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	identity_selector "github.com/mysteriumnetwork/node/identity/selector"
 	"github.com/mysteriumnetwork/node/logconfig"
@@ -84,6 +103,7 @@ import (
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	"github.com/mysteriumnetwork/node/sleep"
 	"github.com/mysteriumnetwork/node/tequilapi"
+	"github.com/mysteriumnetwork/node/trace"
 	"github.com/mysteriumnetwork/node/ui/versionmanager"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 )
@@ -95,61 +115,86 @@ type UIServer interface {
 	Stop()
 }
 
+// identityCacheFile remembers the last used identity in the keystore directory.
+const identityCacheFile = "remember.json"
+
 // Dependencies is DI container for top level components which is reused in several places
 type Dependencies struct {
 	Node *Node
 
 	HTTPTransport *http.Transport
 	HTTPClient    *requests.HTTPClient
+	// DirectHTTPClient bypasses the outbound proxy, it is used where the real public IP of the node matters.
+	DirectHTTPClient *requests.HTTPClient
 
 	NetworkDefinition metadata.NetworkDefinition
 	MysteriumAPI      *mysterium.MysteriumAPI
 	PricingHelper     *pingpong.Pricer
-	EtherClientL1     *paymentClient.EthMultiClient
-	EtherClientL2     *paymentClient.EthMultiClient
-
-	SorterClientL1 *psort.MultiClientSorter
-	SorterClientL2 *psort.MultiClientSorter
-
-	EtherClients []*paymentClient.ReconnectableEthClient
+	Chains            *chain.Registry
 
 	BrokerConnector  *nats.BrokerConnector
 	BrokerConnection nats.Connection
 
 	NATService       nat.NATService
 	NATProber        natprobe.NATProber
+	NATTypeTracker   *natprobe.NATTypeTracker
 	Storage          *boltdb.Bolt
+	StorageJournal   *boltdb.Journal
 	Keystore         *identity.Keystore
+	KeystoreDoctor   *identity.KeystoreDoctor
 	IdentityManager  identity.Manager
 	SignerFactory    identity.SignerFactory
 	IdentityRegistry registry.IdentityRegistry
 	IdentitySelector identity_selector.Handler
 	IdentityMover    *identity.Mover
 
+	PendingRegistrations *registry.PendingRegistrations
+
 	DiscoveryFactory    service.DiscoveryFactory
 	ProposalRepository  *discovery.PricedServiceProposalRepository
 	FilterPresetStorage *proposal.FilterPresetStorage
 	DiscoveryWorker     discovery.Worker
 
+	ProposalZombieDetector *discovery.ZombieDetector
+
 	QualityClient *quality.MysteriumMORQA
 
 	IPResolver       ip.Resolver
 	LocationResolver *location.Cache
 
-	PolicyOracle *policy.Oracle
+	PolicyOracle    *policy.Oracle
+	FeatureRegistry *feature.Registry
 
 	SessionStorage                   *consumer_session.Storage
+	SessionTraceStore                *trace.Store
+	ProviderBlacklist                *blacklist.Blacklist
+	ProviderFavorites                *favorites.Storage
+	ConsumerWallet                   *wallet.Wallet
+	EphemeralIdentities              *ephemeral.Manager
+	PaymentHooks                     *hooks.PaymentPublisher
+	HookDispatcher                   *hooks.Dispatcher
+	BandwidthScheduler               *shaper.FairScheduler
 	SessionConnectivityStatusStorage connectivity.StatusStorage
 
-	EventBus eventbus.EventBus
+	EventBus   eventbus.EventBus
+	Supervisor *supervision.Supervisor
 
 	MultiConnectionManager connection.MultiManager
 	ConnectionRegistry     *connection.Registry
 
-	ServicesManager *service.Manager
-	ServiceRegistry *service.Registry
-	ServiceSessions *service.SessionPool
-	ServiceFirewall firewall.IncomingTrafficFirewall
+	ServicesManager     *service.Manager
+	ServiceRegistry     *service.Registry
+	ServiceSessions     *service.SessionPool
+	SessionAdmission    *service.SessionAdmission
+	SessionCollector    *service.SessionCollector
+	ResourceShedder     *service.ResourceShedder
+	AutoPricer          *pingpong.AutoPricer
+	PacketCapturer      *pcap.Capturer
+	AccessLog           *accesslog.Logger
+	NodeAttester        *nodeattest.Attester
+	AttestationVerifier *attestation.Verifier
+	ServiceFirewall     firewall.IncomingTrafficFirewall
+	Preflight           *preflight.Runner
 
 	PortPool   *port.Pool
 	PortMapper mapping.PortMapper
@@ -164,7 +209,8 @@ type Dependencies struct {
 	UIServer         UIServer
 	Transactor       *registry.Transactor
 	Affiliator       *registry.Affiliator
-	BCHelper         *paymentClient.MultichainBlockchainClient
+	ReferralTracker  *referral.Tracker
+	BCHelper         *pingpong.CachedBlockchain
 
 	LogCollector *logconfig.Collector
 	Reporter     *feedback.Reporter
@@ -178,6 +224,12 @@ type Dependencies struct {
 	ConsumerBalanceTracker   *pingpong.ConsumerBalanceTracker
 	HermesChannelRepository  *pingpong.HermesChannelRepository
 	HermesPromiseSettler     pingpong.HermesPromiseSettler
+	HermesAvailability       *pingpong.HermesAvailabilityMonitor
+	ClockSkew                *pingpong.ClockSkewMonitor
+	InvoiceWatchdog          *pingpong.InvoiceWatchdog
+	ShutdownSettler          *pingpong.ShutdownSettler
+	LocalDNSResolver         *dns.LocalResolver
+	WithdrawalFlow           *pingpong.WithdrawalFlow
 	HermesURLGetter          *pingpong.HermesURLGetter
 	HermesCaller             *pingpong.HermesCaller
 	HermesPromiseHandler     *pingpong.HermesPromiseHandler
@@ -185,8 +237,12 @@ type Dependencies struct {
 	AddressProvider          *paymentClient.MultiChainAddressProvider
 	HermesStatusChecker      *pingpong.HermesStatusChecker
 	HermesMigrator           *migration.HermesMigrator
+	ProviderMigrator         *migration.ProviderMigrator
+
+	MMN               *mmn.MMN
+	MMNStatusReporter *mmn.StatusReporter
 
-	MMN *mmn.MMN
+	SignAudit *audit.Storage
 
 	PilvytisAPI         *pilvytis.API
 	PilvytisTracker     *pilvytis.StatusTracker
@@ -227,10 +283,12 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	}
 
 	di.bootstrapEventBus()
+	di.Supervisor = supervision.New()
 
-	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
+	if err := di.bootstrapStorage(nodeOptions.Directories.Storage, nodeOptions.StorageSlowOperationThreshold); err != nil {
 		return err
 	}
+	di.bootstrapSignAudit(nodeOptions.SignAudit)
 
 	if err := di.bootstrapNetworkComponents(nodeOptions); err != nil {
 		return err
@@ -270,7 +328,13 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 
 	di.PortPool = port.NewFixedRangePool(portRange)
 
-	di.bootstrapP2P()
+	if err := di.bootstrapFeatures(); err != nil {
+		return err
+	}
+
+	if err := di.bootstrapP2P(); err != nil {
+		return err
+	}
 	di.SessionConnectivityStatusStorage = connectivity.NewStatusStorage()
 
 	if err := di.bootstrapServices(nodeOptions); err != nil {
@@ -301,52 +365,64 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	return nil
 }
 
-func (di *Dependencies) bootstrapAddressProvider(nodeOptions node.Options) {
-	ch1 := nodeOptions.Chains.Chain1
-	ch2 := nodeOptions.Chains.Chain2
-	chain1KnownHermeses := parseAddressSlice(ch1.KnownHermeses)
-	chain2KnownHermeses := parseAddressSlice(ch2.KnownHermeses)
+func (di *Dependencies) bootstrapAddressProvider() {
 	hermesAddresses, err := di.ObserverAPI.GetApprovedHermesAdresses()
 	if err != nil {
 		log.Warn().AnErr("err", err).Msg("observer hermeses call failed, using fallback known hermeses")
-	} else {
-		chain1ApprovedHermeses, ok := hermesAddresses[ch1.ChainID]
-		if ok {
-			chain1KnownHermeses = chain1ApprovedHermeses
-		}
-		chain2ApprovedHermeses, ok := hermesAddresses[ch2.ChainID]
-		if ok {
-			chain2KnownHermeses = chain2ApprovedHermeses
-		}
-	}
-	addresses := map[int64]paymentClient.SmartContractAddresses{
-		ch1.ChainID: {
-			Registry:                    common.HexToAddress(ch1.RegistryAddress),
-			Myst:                        common.HexToAddress(ch1.MystAddress),
-			ActiveHermes:                common.HexToAddress(ch1.HermesID),
-			ActiveChannelImplementation: common.HexToAddress(ch1.ChannelImplAddress),
-			KnownHermeses:               chain1KnownHermeses,
-		},
-		ch2.ChainID: {
-			Registry:                    common.HexToAddress(ch2.RegistryAddress),
-			Myst:                        common.HexToAddress(ch2.MystAddress),
-			ActiveHermes:                common.HexToAddress(ch2.HermesID),
-			ActiveChannelImplementation: common.HexToAddress(ch2.ChannelImplAddress),
-			KnownHermeses:               chain2KnownHermeses,
-		},
+		hermesAddresses = nil
 	}
 
-	keeper := paymentClient.NewMultiChainAddressKeeper(addresses)
+	keeper := paymentClient.NewMultiChainAddressKeeper(di.Chains.Addresses(hermesAddresses))
 	di.AddressProvider = paymentClient.NewMultiChainAddressProvider(keeper, di.BCHelper)
 }
 
-func (di *Dependencies) bootstrapP2P() {
+func (di *Dependencies) bootstrapFeatures() error {
+	var featureVerifier identity.Verifier
+	if signer := config.GetString(config.FlagFeatureSigner); signer != "" {
+		featureVerifier = identity.NewVerifierIdentity(identity.FromAddress(signer))
+	}
+	di.FeatureRegistry = feature.NewRegistry(
+		feature.Config{
+			Enabled:  config.GetStringSlice(config.FlagFeatureEnable),
+			Disabled: config.GetStringSlice(config.FlagFeatureDisable),
+		},
+		di.HTTPClient,
+		config.GetString(config.FlagFeatureAddress),
+		config.GetDuration(config.FlagFeatureFetchInterval),
+		featureVerifier,
+		di.Storage,
+	)
+	if err := di.EventBus.SubscribeAsync(identity.AppTopicIdentityUnlock, di.FeatureRegistry.HandleIdentityUnlock); err != nil {
+		return err
+	}
+	go di.FeatureRegistry.Start()
+	return nil
+}
+
+func (di *Dependencies) bootstrapP2P() error {
 	verifierFactory := func(id identity.Identity) identity.Verifier {
 		return identity.NewVerifierIdentity(id)
 	}
 
-	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.SignerFactory, identity.NewVerifierSigned(), di.IPResolver, di.EventBus)
-	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.SignerFactory, verifierFactory, di.IPResolver, di.PortPool, di.EventBus)
+	di.NATTypeTracker = natprobe.NewNATTypeTracker(natprobe.DefaultNATTypeMaxAge)
+	if err := di.NATTypeTracker.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	di.P2PListener = p2p.NewListener(di.BrokerConnection, di.auditedSignerFactory("p2p", "p2p-message"), identity.NewVerifierSigned(), di.IPResolver, di.NATTypeTracker, di.EventBus)
+	di.P2PDialer = p2p.NewDialer(di.BrokerConnector, di.auditedSignerFactory("p2p", "p2p-message"), verifierFactory, di.IPResolver, di.PortPool, di.NATTypeTracker, di.EventBus, di.FeatureRegistry)
+	return nil
+}
+
+// detectNATType probes NAT type in background, so p2p connections could take
+// the full cone fast path without waiting for an explicit probe.
+func (di *Dependencies) detectNATType() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := di.NATProber.Probe(ctx); err != nil {
+		log.Debug().Err(err).Msg("Initial NAT type detection failed")
+	}
 }
 
 func (di *Dependencies) createTequilaListener(nodeOptions node.Options) (net.Listener, error) {
@@ -376,7 +452,7 @@ func (di *Dependencies) bootstrapStateKeeper(options node.Options) error {
 
 	di.StateKeeper = state.NewKeeper(deps, state.DefaultDebounceDuration)
 	if options.SSE.Enabled {
-		return di.StateKeeper.Subscribe(di.EventBus)
+		return di.StateKeeper.Subscribe(di.Supervisor.Subscriber("state", di.EventBus))
 	}
 
 	return nil
@@ -390,7 +466,7 @@ func (di *Dependencies) registerOpenvpnConnection(nodeOptions node.Options) {
 			nodeOptions.Openvpn.BinaryPath(),
 			nodeOptions.Directories.Script,
 			nodeOptions.Directories.Runtime,
-			di.SignerFactory,
+			di.auditedSignerFactory("openvpn", "session-auth"),
 			di.IPResolver,
 		)
 	}
@@ -427,33 +503,87 @@ func (di *Dependencies) Shutdown() (err error) {
 		}
 	}
 
+	// Sessions are gone at this point, try to secure the earnings before the transports are torn down.
+	if di.ShutdownSettler != nil {
+		if err := di.ShutdownSettler.Settle(); err != nil {
+			log.Warn().Err(err).Msg("Final settlement on shutdown failed")
+		}
+	}
+
+	if di.Supervisor != nil {
+		di.Supervisor.Stop()
+	}
+
 	if di.PolicyOracle != nil {
 		di.PolicyOracle.Stop()
 	}
 
-	if di.NATService != nil {
-		if err := di.NATService.Disable(); err != nil {
-			errs = append(errs, err)
-		}
+	if di.FeatureRegistry != nil {
+		di.FeatureRegistry.Stop()
 	}
 
-	if di.EtherClientL1 != nil {
-		di.EtherClientL1.Close()
+	if di.HermesAvailability != nil {
+		di.HermesAvailability.Stop()
 	}
-	if di.SorterClientL1 != nil {
-		di.SorterClientL1.Stop()
+
+	if di.ClockSkew != nil {
+		di.ClockSkew.Stop()
 	}
 
-	if di.EtherClientL2 != nil {
-		di.EtherClientL2.Close()
+	if di.InvoiceWatchdog != nil {
+		di.InvoiceWatchdog.Stop()
 	}
-	if di.SorterClientL2 != nil {
-		di.SorterClientL2.Stop()
+
+	if di.MMNStatusReporter != nil {
+		di.MMNStatusReporter.Stop()
+	}
+
+	if di.LocalDNSResolver != nil {
+		if err := di.LocalDNSResolver.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.NATService != nil {
+		if err := di.NATService.Disable(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.Chains != nil {
+		di.Chains.Close()
 	}
 
 	if di.DiscoveryWorker != nil {
 		di.DiscoveryWorker.Stop()
 	}
+	if di.ProposalZombieDetector != nil {
+		di.ProposalZombieDetector.Stop()
+	}
+	if di.SessionCollector != nil {
+		di.SessionCollector.Stop()
+	}
+	if di.ResourceShedder != nil {
+		di.ResourceShedder.Stop()
+	}
+	if di.AutoPricer != nil {
+		di.AutoPricer.Stop()
+	}
+	if di.AccessLog != nil {
+		di.AccessLog.Stop()
+	}
+	if di.PacketCapturer != nil {
+		di.PacketCapturer.Stop()
+	}
+	if di.HookDispatcher != nil {
+		di.HookDispatcher.Stop()
+	}
+	if di.PaymentHooks != nil {
+		di.PaymentHooks.Stop()
+	}
+	if di.EphemeralIdentities != nil {
+		di.EphemeralIdentities.Stop()
+	}
 	if di.PilvytisTracker != nil {
 		di.PilvytisTracker.Stop()
 	}
@@ -470,6 +600,16 @@ func (di *Dependencies) Shutdown() (err error) {
 	}
 	firewall.Reset()
 
+	if di.SignAudit != nil {
+		di.SignAudit.Stop()
+	}
+
+	if di.StorageJournal != nil {
+		if err := di.StorageJournal.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	if di.Storage != nil {
 		if err := di.Storage.Close(); err != nil {
 			errs = append(errs, err)
@@ -481,11 +621,28 @@ func (di *Dependencies) Shutdown() (err error) {
 	return nil
 }
 
-func (di *Dependencies) bootstrapStorage(path string) error {
+func (di *Dependencies) bootstrapSignAudit(options node.OptionsSignAudit) {
+	if !options.Enabled {
+		return
+	}
+	di.SignAudit = audit.NewStorage(di.Storage, options.Retention)
+	di.SignAudit.Start()
+}
+
+// auditedSignerFactory returns the signer factory for the given subsystem, recording its signing operations if the audit is enabled.
+func (di *Dependencies) auditedSignerFactory(subsystem, messageType string) identity.SignerFactory {
+	if di.SignAudit == nil {
+		return di.SignerFactory
+	}
+	return audit.SignerFactory(di.SignerFactory, di.SignAudit, subsystem, messageType)
+}
+
+func (di *Dependencies) bootstrapStorage(path string, slowOperationThreshold time.Duration) error {
 	localStorage, err := boltdb.NewStorage(path)
 	if err != nil {
 		return err
 	}
+	localStorage.SetSlowOperationThreshold(slowOperationThreshold)
 
 	migrator := migrator.NewMigrator(localStorage)
 	err = migrator.RunMigrations(history.Sequence)
@@ -495,13 +652,24 @@ func (di *Dependencies) bootstrapStorage(path string) error {
 
 	di.Storage = localStorage
 
-	invoiceStorage := pingpong.NewInvoiceStorage(di.Storage)
+	// Payment critical writes go through the journal so they survive an interrupted commit.
+	di.StorageJournal, err = boltdb.OpenJournal(di.Storage, filepath.Join(path, "myst.journal"))
+	if err != nil {
+		return err
+	}
+
+	invoiceStorage := pingpong.NewInvoiceStorage(di.StorageJournal)
 	di.ProviderInvoiceStorage = pingpong.NewProviderInvoiceStorage(invoiceStorage)
 	di.ConsumerTotalsStorage = pingpong.NewConsumerTotalsStorage(di.EventBus)
-	di.HermesPromiseStorage = pingpong.NewHermesPromiseStorage(di.Storage)
+	di.HermesPromiseStorage = pingpong.NewHermesPromiseStorage(di.Storage, di.StorageJournal)
 	di.SessionStorage = consumer_session.NewSessionStorage(di.Storage)
 	di.SettlementHistoryStorage = pingpong.NewSettlementHistoryStorage(di.Storage)
-	return di.SessionStorage.Subscribe(di.EventBus)
+	if err := di.SessionStorage.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+
+	di.SessionTraceStore = trace.NewStore(trace.DefaultStoreSize)
+	return di.SessionTraceStore.Subscribe(di.EventBus)
 }
 
 func (di *Dependencies) getHermesURL(nodeOptions node.Options) (string, error) {
@@ -538,11 +706,13 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		},
 	)
 
-	err := di.ConsumerBalanceTracker.Subscribe(di.EventBus)
+	err := di.ConsumerBalanceTracker.Subscribe(di.Supervisor.Subscriber("payments", di.EventBus))
 	if err != nil {
 		return errors.Wrap(err, "could not subscribe consumer balance tracker to relevant events")
 	}
 
+	di.ConsumerWallet = wallet.NewWallet(di.Storage, di.IdentityManager, di.IdentityRegistry, di.ConsumerBalanceTracker, di.SessionStorage)
+
 	di.PayoutAddressStorage = payout.NewAddressStorage(di.Storage)
 	di.bootstrapBeneficiaryProvider(nodeOptions)
 
@@ -555,11 +725,11 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		FeeProvider:     di.Transactor,
 		Encryption:      di.Keystore,
 		EventBus:        di.EventBus,
-		Signer:          di.SignerFactory,
-		Chains:          []int64{nodeOptions.Chains.Chain1.ChainID, nodeOptions.Chains.Chain2.ChainID},
+		Signer:          di.auditedSignerFactory("hermes", "promise-request"),
+		Chains:          di.Chains.ChainIDs(),
 	})
 
-	if err := di.HermesPromiseHandler.Subscribe(di.EventBus); err != nil {
+	if err := di.HermesPromiseHandler.Subscribe(di.Supervisor.Subscriber("payments", di.EventBus)); err != nil {
 		return err
 	}
 
@@ -569,34 +739,119 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 
 	di.bootstrapBeneficiarySaver(nodeOptions)
 
+	if err := di.bootstrapEphemeralIdentities(nodeOptions); err != nil {
+		return err
+	}
+
+	di.ClockSkew = pingpong.NewClockSkewMonitor(
+		di.HTTPClient,
+		di.EventBus,
+		[]string{di.NetworkDefinition.DiscoveryAddress, nodeOptions.Transactor.TransactorEndpointAddress},
+		nodeOptions.Payments.ClockSkewCheckInterval,
+		nodeOptions.Payments.ClockSkewThreshold,
+	)
+	if nodeOptions.Payments.ClockSkewCheckInterval > 0 {
+		di.Supervisor.Go("payments", di.ClockSkew.Start)
+	}
+
 	di.ConnectionRegistry = connection.NewRegistry()
+	di.LocalDNSResolver = dns.NewLocalResolver(dns.LocalResolverConfig{
+		Address:   nodeOptions.DNS.LocalAddress,
+		Port:      nodeOptions.DNS.LocalPort,
+		CacheSize: nodeOptions.DNS.LocalCacheSize,
+		Profiles:  dns.ParseProfiles(nodeOptions.DNS.Upstreams, nodeOptions.DNS.Blocklist),
+	})
 	di.MultiConnectionManager = connection.NewMultiConnectionManager(func() connection.Manager {
 		return connection.NewManager(
 			pingpong.ExchangeFactoryFunc(
 				di.Keystore,
-				di.SignerFactory,
+				di.auditedSignerFactory("payments", "exchange-message"),
 				di.ConsumerTotalsStorage,
 				di.AddressProvider,
 				di.EventBus,
 				nodeOptions.Payments.ConsumerDataLeewayMegabytes,
+				pingpong.PeerClockSkewConfig{
+					Tolerance:  nodeOptions.Payments.PeerClockSkewTolerance,
+					MaxSkew:    nodeOptions.Payments.PeerClockSkewMax,
+					LocalClock: di.ClockSkew,
+				},
+				di.HermesStatusChecker,
 			),
 			di.ConnectionRegistry.CreateConnection,
 			di.EventBus,
 			di.IPResolver,
 			di.LocationResolver,
-			connection.DefaultConfig(),
-			connection.DefaultStatsReportInterval,
+			connectionManagerConfig(),
+			statsReportInterval(),
 			connection.NewValidator(
 				di.ConsumerBalanceTracker,
 				di.IdentityManager,
 			),
 			di.P2PDialer,
+			di.LocalDNSResolver,
 			di.allowTrustedDomainBypassTunnel,
 			di.disallowTrustedDomainBypassTunnel,
 		)
 	})
 
+	di.ProviderBlacklist = blacklist.New(blacklist.Config{
+		Threshold: nodeOptions.Blacklist.Threshold,
+		HalfLife:  nodeOptions.Blacklist.HalfLife,
+	})
+	if err := di.ProviderBlacklist.Subscribe(di.EventBus); err != nil {
+		return errors.Wrap(err, "could not subscribe provider blacklist to relevant events")
+	}
+	di.ProviderFavorites = favorites.NewStorage(di.Storage)
+
+	if nodeOptions.LeakTest.Enabled {
+		leakTestMonitor := leaktest.NewMonitor(di.MultiConnectionManager, di.EventBus, nodeOptions.LeakTest.AutoDisconnect)
+		if err := leakTestMonitor.Subscribe(di.EventBus); err != nil {
+			return errors.Wrap(err, "could not subscribe leak test monitor to relevant events")
+		}
+	}
+
+	hookDispatcher := hooks.NewDispatcher(hooks.Config{
+		WebhookURL: nodeOptions.Hooks.WebhookURL,
+		Script:     nodeOptions.Hooks.Script,
+		Secret:     nodeOptions.Hooks.Secret,
+		Timeout:    nodeOptions.Hooks.Timeout,
+	})
+	if hookDispatcher.Enabled() {
+		if err := hookDispatcher.Subscribe(di.EventBus); err != nil {
+			return errors.Wrap(err, "could not subscribe lifecycle hooks to relevant events")
+		}
+		di.HookDispatcher = hookDispatcher
+	}
+
+	if len(nodeOptions.Hooks.PaymentURLs) > 0 {
+		paymentHooks, err := hooks.NewPaymentPublisher(hooks.PaymentConfig{
+			URLs:       nodeOptions.Hooks.PaymentURLs,
+			Events:     nodeOptions.Hooks.PaymentEvents,
+			Secret:     nodeOptions.Hooks.Secret,
+			Timeout:    nodeOptions.Hooks.Timeout,
+			Retries:    nodeOptions.Hooks.PaymentRetries,
+			RetryDelay: nodeOptions.Hooks.PaymentRetryDelay,
+		})
+		if err != nil {
+			return errors.Wrap(err, "could not create payment hooks")
+		}
+		if err := paymentHooks.Subscribe(di.EventBus); err != nil {
+			return errors.Wrap(err, "could not subscribe payment hooks to relevant events")
+		}
+		di.PaymentHooks = paymentHooks
+	}
+
+	if _, err := shaper.ConfiguredSchedule(); err != nil {
+		return err
+	}
+	di.BandwidthScheduler = shaper.NewFairScheduler(shaper.LimiterFunc(shaper.ConfiguredCapacity))
+
 	di.NATProber = natprobe.NewNATProber(di.MultiConnectionManager, di.EventBus)
+	go di.detectNATType()
+
+	if !nodeOptions.Consumer {
+		di.bootstrapPreflight(nodeOptions)
+	}
 
 	di.LogCollector = logconfig.NewCollector(&logconfig.CurrentLogOptions)
 	reporter, err := feedback.NewReporter(di.LogCollector, di.IdentityManager, di.LocationResolver, nodeOptions.FeedbackURL)
@@ -608,6 +863,7 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 	if err := di.bootstrapStateKeeper(nodeOptions); err != nil {
 		return err
 	}
+	di.bootstrapMMNStatusReporter()
 
 	di.bootstrapPilvytis(nodeOptions)
 
@@ -634,14 +890,18 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		di.QualityClient.ProviderTransferredDataSeries,
 		di.QualityClient.ProviderActivityStats,
 		di.QualityClient.ProviderQuality,
+		di.QualityClient.ProviderReputation,
 		di.QualityClient.ProviderServiceEarnings,
 		di.IdentityManager,
 	)
 
-	di.HermesMigrator = di.bootstrapHermesMigrator()
+	migrationStorage := migration.NewStorage(di.Storage, di.AddressProvider)
+	di.HermesMigrator = di.bootstrapHermesMigrator(migrationStorage)
 	if err := di.HermesMigrator.Subscribe(di.EventBus); err != nil {
 		return fmt.Errorf("error during subscribe: %w", err)
 	}
+	di.ProviderMigrator = migration.NewProviderMigrator(di.HermesPromiseSettler, di.HermesMigrator, migrationStorage)
+	di.ProviderMigrator.Resume(config.GetInt64(config.FlagChainID), di.IdentityManager.GetIdentities()...)
 
 	tequilapiHTTPServer, err := di.bootstrapTequilapi(nodeOptions, tequilaListener)
 	if err != nil {
@@ -700,6 +960,22 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 	dialer := requests.NewDialerSwarm(options.BindAddress, options.SwarmDialerDNSHeadstart)
 	dialer.ResolveContext = resolver
 	di.HTTPTransport = requests.NewTransport(dialer.DialContext)
+	di.DirectHTTPClient = requests.NewHTTPClientWithTransport(di.HTTPTransport.Clone(), requests.DefaultTimeout)
+	var rpcTransport *http.Transport
+	if optionsNetwork.OutboundProxy != "" {
+		proxyURL, err := requests.ParseProxyURL(optionsNetwork.OutboundProxy)
+		if err != nil {
+			return err
+		}
+		if err := di.AllowURLAccess(proxyURL.String()); err != nil {
+			return err
+		}
+
+		log.Info().Msgf("Using outbound proxy: %s", proxyURL.Redacted())
+		// Blockchain RPC clients get a dedicated transport, the default one is shared by clients which must not be proxied.
+		rpcTransport = http.DefaultTransport.(*http.Transport).Clone()
+		requests.SetOutboundProxy(proxyURL, di.HTTPTransport, rpcTransport)
+	}
 	di.HTTPClient = requests.NewHTTPClientWithTransport(di.HTTPTransport, requests.DefaultTimeout)
 	di.MysteriumAPI = mysterium.NewClient(di.HTTPClient, network.DiscoveryAddress)
 	di.PricingHelper = pingpong.NewPricer(di.MysteriumAPI)
@@ -722,69 +998,28 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 		return err
 	}
 
-	log.Info().Msgf("Using L1 Eth endpoints: %v", network.Chain1.EtherClientRPC)
-	log.Info().Msgf("Using L2 Eth endpoints: %v", network.Chain2.EtherClientRPC)
-
-	di.EtherClients = make([]*paymentClient.ReconnectableEthClient, 0)
-	bcClientsL1 := make([]paymentClient.AddressableEthClientGetter, 0)
-	for _, rpc := range network.Chain1.EtherClientRPC {
-		client, err := paymentClient.NewReconnectableEthClient(rpc, time.Second*10)
-		if err != nil {
-			log.Warn().Msgf("failed to load rpc endpoint: %s", rpc)
-			continue
-		}
-		di.EtherClients = append(di.EtherClients, client)
-		bcClientsL1 = append(bcClientsL1, client)
-	}
-
-	if len(bcClientsL1) == 0 {
-		log.Error().Msg("no l1 rpc endpoints loaded")
-	}
-
-	bcClientsL2 := make([]paymentClient.AddressableEthClientGetter, 0)
-	for _, rpc := range network.Chain2.EtherClientRPC {
-		client, err := paymentClient.NewReconnectableEthClient(rpc, time.Second*10)
-		if err != nil {
-			log.Warn().Msgf("failed to load rpc endpoint: %s", rpc)
-			continue
+	di.Chains = chain.NewRegistry()
+	chainOptions := chain.Options{BCTimeout: options.Payments.BCTimeout, RPCTransport: rpcTransport}
+	chain1 := options.Chains.Chain1
+	chain1.EtherClientRPC = network.Chain1.EtherClientRPC
+	chain2 := options.Chains.Chain2
+	chain2.EtherClientRPC = network.Chain2.EtherClientRPC
+	for _, definition := range []metadata.ChainDefinition{chain1, chain2} {
+		if _, err := di.Chains.Add(definition, chainOptions); err != nil {
+			return err
 		}
-
-		di.EtherClients = append(di.EtherClients, client)
-		bcClientsL2 = append(bcClientsL2, client)
-	}
-
-	if len(bcClientsL2) == 0 {
-		log.Error().Msg("no l1 rpc endpoints loaded")
 	}
 
-	notifyChannelL1 := make(chan paymentClient.Notification, 5)
-	di.EtherClientL1, err = paymentClient.NewEthMultiClientNotifyDown(time.Second*20, bcClientsL1, notifyChannelL1)
-	if err != nil {
-		return err
-	}
-	di.SorterClientL1 = psort.NewMultiClientSorterNoTicker(di.EtherClientL1, notifyChannelL1)
-	di.SorterClientL1.AddOnNotificationAction(psort.DefaultByAvailability)
-	go di.SorterClientL1.Run()
-
-	notifyChannelL2 := make(chan paymentClient.Notification, 5)
-	di.EtherClientL2, err = paymentClient.NewEthMultiClientNotifyDown(time.Second*20, bcClientsL2, notifyChannelL2)
-	if err != nil {
+	di.BCHelper = pingpong.NewCachedBlockchain(paymentClient.NewMultichainBlockchainClient(di.Chains.BlockchainClients()), pingpong.CachedBlockchainConfig{
+		StaticTTL:  options.Payments.BCCacheTTL,
+		ChannelTTL: options.Payments.BCChannelCacheTTL,
+	})
+	if err := di.BCHelper.Subscribe(di.EventBus); err != nil {
 		return err
 	}
-	di.SorterClientL2 = psort.NewMultiClientSorterNoTicker(di.EtherClientL2, notifyChannelL2)
-	di.SorterClientL2.AddOnNotificationAction(psort.DefaultByAvailability)
-	go di.SorterClientL2.Run()
-
-	bcL1 := paymentClient.NewBlockchain(di.EtherClientL1, options.Payments.BCTimeout)
-	bcL2 := paymentClient.NewBlockchain(di.EtherClientL2, options.Payments.BCTimeout)
-
-	clients := make(map[int64]paymentClient.BC)
-	clients[options.Chains.Chain1.ChainID] = bcL1
-	clients[options.Chains.Chain2.ChainID] = bcL2
-
-	di.BCHelper = paymentClient.NewMultichainBlockchainClient(clients)
 	di.ObserverAPI = observer.NewAPI(options.ObserverAddress, time.Second*30)
-	di.bootstrapAddressProvider(options)
+	di.HermesStatusChecker = pingpong.NewHermesStatusChecker(di.BCHelper, di.ObserverAPI, options.Payments.HermesStatusRecheckInterval)
+	di.bootstrapAddressProvider()
 	di.HermesURLGetter = pingpong.NewHermesURLGetter(di.BCHelper, di.AddressProvider, di.ObserverAPI)
 
 	registryStorage := registry.NewRegistrationStatusStorage(di.Storage)
@@ -802,21 +1037,28 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 		di.HTTPClient,
 		options.Transactor.TransactorEndpointAddress,
 		di.AddressProvider,
-		di.SignerFactory,
+		di.auditedSignerFactory("transactor", "transactor-request"),
 		di.EventBus,
 		di.BCHelper,
 		options.Transactor.TransactorFeesValidTime,
+		di.Chains,
 	)
 	di.Affiliator = registry.NewAffiliator(di.HTTPClient, options.Affiliator.AffiliatorEndpointAddress)
+	di.ReferralTracker = referral.NewTracker(config.Current, di.auditedSignerFactory("referral", "api-request"))
 
 	registryCfg := registry.IdentityRegistryConfig{
 		TransactorPollInterval: options.Payments.RegistryTransactorPollInterval,
 		TransactorPollTimeout:  options.Payments.RegistryTransactorPollTimeout,
 	}
 
-	if di.IdentityRegistry, err = registry.NewIdentityRegistryContract(di.EtherClientL2, di.AddressProvider, registryStorage, di.EventBus, di.HermesCaller, di.Transactor, registryCfg); err != nil {
+	chain2Backend, ok := di.Chains.Backend(options.Chains.Chain2.ChainID)
+	if !ok {
+		return fmt.Errorf("chain %v is not supported", options.Chains.Chain2.ChainID)
+	}
+	if di.IdentityRegistry, err = registry.NewIdentityRegistryContract(chain2Backend.EtherClient(), di.AddressProvider, registryStorage, di.EventBus, di.HermesCaller, di.Transactor, registryCfg); err != nil {
 		return err
 	}
+	di.PendingRegistrations = registry.NewPendingRegistrations(registryStorage, di.Transactor, di.EventBus, options.Payments.RegistryStuckTimeout)
 
 	allow := []string{
 		network.DiscoveryAddress,
@@ -835,10 +1077,21 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 }
 
 func (di *Dependencies) bootstrapEventBus() {
-	di.EventBus = eventbus.New()
+	bus := eventbus.New()
+	// Payload validation costs a reflection call per event, so it's only done in development builds.
+	if metadata.BuildNumber == "dev-build" {
+		bus.EnableValidation()
+	}
+	di.EventBus = bus
 }
 
 func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error {
+	// Keystore loads the keys once created, so unusable files are dealt with before.
+	di.KeystoreDoctor = identity.NewKeystoreDoctor(options.Directories.Keystore, identityCacheFile, options.Keystore.AutoRepair)
+	if _, err := di.KeystoreDoctor.Check(); err != nil {
+		log.Warn().Err(err).Msg("Could not check keystore health")
+	}
+
 	var ks *keystore.KeyStore
 	if options.Keystore.UseLightweight {
 		log.Debug().Msg("Using lightweight keystore")
@@ -856,13 +1109,13 @@ func (di *Dependencies) bootstrapIdentityComponents(options node.Options) error
 
 	di.IdentitySelector = identity_selector.NewHandler(
 		di.IdentityManager,
-		identity.NewIdentityCache(options.Directories.Keystore, "remember.json"),
-		di.SignerFactory,
+		identity.NewIdentityCache(options.Directories.Keystore, identityCacheFile),
+		di.auditedSignerFactory("identity", "unlock-check"),
 	)
 	di.IdentityMover = identity.NewMover(
 		di.Keystore,
 		di.EventBus,
-		di.SignerFactory)
+		di.auditedSignerFactory("identity", "identity-import"))
 	return nil
 }
 
@@ -874,9 +1127,9 @@ func (di *Dependencies) bootstrapQualityComponents(options node.OptionsQuality)
 	di.QualityClient = quality.NewMorqaClient(
 		requests.NewHTTPClientWithTransport(di.HTTPTransport, 10*time.Second),
 		options.Address,
-		di.SignerFactory,
+		di.auditedSignerFactory("quality", "quality-report"),
 	)
-	go di.QualityClient.Start()
+	di.Supervisor.Go("quality", di.QualityClient.Start)
 
 	var transport quality.Transport
 	switch options.Type {
@@ -895,7 +1148,8 @@ func (di *Dependencies) bootstrapQualityComponents(options node.OptionsQuality)
 
 	// Quality metrics
 	qualitySender := quality.NewSender(transport, metadata.VersionAsString())
-	if err := qualitySender.Subscribe(di.EventBus); err != nil {
+	qualitySender.Referrals = di.ReferralTracker
+	if err := qualitySender.Subscribe(di.Supervisor.Subscriber("quality", di.EventBus)); err != nil {
 		return err
 	}
 
@@ -903,7 +1157,7 @@ func (di *Dependencies) bootstrapQualityComponents(options node.OptionsQuality)
 	loader := &upnp.GatewayLoader{}
 	go loader.Get()
 	natSender := event.NewSender(qualitySender, di.IPResolver.GetPublicIP, loader.HumanReadable)
-	if err := natSender.Subscribe(di.EventBus); err != nil {
+	if err := natSender.Subscribe(di.Supervisor.Subscriber("quality", di.EventBus)); err != nil {
 		return err
 	}
 
@@ -915,7 +1169,7 @@ func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err e
 		return errors.Wrap(err, "failed to add firewall exception")
 	}
 
-	ipResolver := ip.NewResolver(di.HTTPClient, options.BindAddress, options.Location.IPDetectorURL, ip.IPFallbackAddresses)
+	ipResolver := ip.NewResolver(di.DirectHTTPClient, options.BindAddress, options.Location.IPDetectorURL, ip.IPFallbackAddresses)
 	di.IPResolver = ip.NewCachedResolver(ipResolver, 5*time.Minute)
 
 	var resolver location.Resolver
@@ -930,7 +1184,7 @@ func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err e
 		if err := di.AllowURLAccess(options.Location.Address); err != nil {
 			return err
 		}
-		resolver, err = location.NewOracleResolver(di.HTTPClient, options.Location.Address), nil
+		resolver, err = location.NewOracleResolver(di.DirectHTTPClient, options.Location.Address), nil
 	default:
 		err = errors.Errorf("unknown location provider: %s", options.Location.Type)
 	}
@@ -967,7 +1221,7 @@ func (di *Dependencies) bootstrapAuthenticator() error {
 }
 
 func (di *Dependencies) bootstrapPilvytis(options node.Options) {
-	di.PilvytisAPI = pilvytis.NewAPI(di.HTTPClient, options.PilvytisAddress, di.SignerFactory, di.LocationResolver, di.AddressProvider)
+	di.PilvytisAPI = pilvytis.NewAPI(di.HTTPClient, options.PilvytisAddress, di.auditedSignerFactory("pilvytis", "api-request"), di.LocationResolver, di.AddressProvider)
 	di.PilvytisTracker = pilvytis.NewStatusTracker(di.PilvytisAPI, di.IdentityManager, di.EventBus, time.Minute)
 	di.PilvytisOrderIssuer = pilvytis.NewOrderIssuer(di.PilvytisAPI, di.PilvytisTracker)
 
@@ -1019,7 +1273,30 @@ func (di *Dependencies) bootstrapBeneficiarySaver(options node.Options) {
 	)
 }
 
-func (di *Dependencies) bootstrapHermesMigrator() *migration.HermesMigrator {
+func (di *Dependencies) bootstrapEphemeralIdentities(options node.Options) error {
+	di.EphemeralIdentities = ephemeral.NewManager(
+		di.Storage,
+		di.IdentityManager,
+		di.Keystore,
+		di.SignerFactory,
+		registry.NewAutoRegistrar(di.IdentityRegistry, di.Transactor, di.ConsumerBalanceTracker, di.AddressProvider, registry.BackgroundAutoRegistrationTimeout),
+		di.HermesPromiseSettler,
+		di.AddressProvider,
+		di.ConsumerBalanceTracker,
+		ephemeral.Config{
+			FundAmount:      crypto.FloatToBigMyst(options.EphemeralIdentities.FundAmount),
+			MinBalance:      crypto.FloatToBigMyst(options.EphemeralIdentities.MinBalance),
+			CleanupInterval: options.EphemeralIdentities.CleanupInterval,
+		},
+	)
+	if err := di.EphemeralIdentities.Subscribe(di.EventBus); err != nil {
+		return errors.Wrap(err, "could not subscribe ephemeral identities to session events")
+	}
+	di.EphemeralIdentities.Start()
+	return nil
+}
+
+func (di *Dependencies) bootstrapHermesMigrator(st *migration.Storage) *migration.HermesMigrator {
 	return migration.NewHermesMigrator(
 		di.Transactor,
 		di.AddressProvider,
@@ -1030,7 +1307,7 @@ func (di *Dependencies) bootstrapHermesMigrator() *migration.HermesMigrator {
 		di.HermesPromiseSettler,
 		di.IdentityRegistry,
 		di.ConsumerBalanceTracker,
-		migration.NewStorage(di.Storage, di.AddressProvider),
+		st,
 		di.BCHelper,
 	)
 }
@@ -1059,17 +1336,9 @@ func (di *Dependencies) handleConnStateChange() error {
 			log.Info().Msg("Reconnecting HTTP clients due to VPN connection state change")
 			di.HTTPTransport.CloseIdleConnections()
 
-			for _, cl := range di.EtherClients {
-				if err := cl.Reconnect(time.Second * 15); err != nil {
-					log.Warn().Err(err).Msg("Ethereum client failed to reconnect, will retry one more time")
-					// Default golang DNS resolver does not allow to reload /etc/resolv.conf more than once per 5 seconds.
-					// This could lead to the problem, when right after connect/disconnect new DNS config not applied instantly.
-					// Doing a couple of retries here to make sure we reconnected Ethererum client correctly.
-					// Default DNS timeout is 10 seconds. It's enough to try to reconnect only twice to cover 5 seconds lag for DNS config reload.
-					// https://github.com/mysteriumnetwork/node/issues/2282
-					if err := cl.Reconnect(time.Second * 15); err != nil {
-						log.Error().Err(err).Msg("Ethereum client failed to reconnect")
-					}
+			for _, backend := range di.Chains.Backends() {
+				if err := backend.Reconnect(time.Second * 15); err != nil {
+					log.Error().Err(err).Msg("Ethereum client failed to reconnect")
 				}
 			}
 
@@ -1151,10 +1420,32 @@ func (di *Dependencies) disallowTrustedDomainBypassTunnel() {
 	}
 }
 
-func parseAddressSlice(slice []string) []common.Address {
-	res := make([]common.Address, len(slice))
-	for i, s := range slice {
-		res[i] = common.HexToAddress(s)
+func connectionManagerConfig() connection.Config {
+	cfg := connection.DefaultConfig()
+	cfg.KeepAlive.DeadPeer = keepAliveDeadPeerConfig(cfg.KeepAlive.DeadPeer)
+	return cfg
+}
+
+// statsReportInterval applies the configured sampling interval of active connections over the defaults.
+func statsReportInterval() connection.StatsReportInterval {
+	interval := connection.DefaultStatsReportInterval
+	if active := config.GetDuration(config.FlagStatsReportInterval); active > 0 {
+		interval.Active = active
+		if interval.Idle < active {
+			interval.Idle = active
+		}
+	}
+	return interval
+}
+
+// keepAliveDeadPeerConfig applies configured p2p dead peer detection thresholds over the given defaults.
+func keepAliveDeadPeerConfig(defaults p2p.DeadPeerConfig) p2p.DeadPeerConfig {
+	if maxLoss := config.GetInt(config.FlagKeepAliveMaxConsecutiveLoss); maxLoss > 0 {
+		defaults.MaxConsecutiveLoss = maxLoss
+	}
+	if window := config.GetInt(config.FlagKeepAliveLossWindow); window > 0 {
+		defaults.Window = window
 	}
-	return res
+	defaults.MaxLossRatio = config.GetFloat64(config.FlagKeepAliveMaxLossRatio)
+	return defaults
 }
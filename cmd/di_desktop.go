@@ -18,20 +18,34 @@
 package cmd
 
 import (
+	"path/filepath"
+	"runtime"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/accesslog"
+	"github.com/mysteriumnetwork/node/core/attestation"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/feature"
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/nodeattest"
+	"github.com/mysteriumnetwork/node/core/pcap"
 	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/preflight"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/mmn"
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/services"
 	"github.com/mysteriumnetwork/node/services/datatransfer"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
@@ -43,6 +57,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
 	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/payments/crypto"
 )
 
 // bootstrapServices loads all the components required for running services
@@ -59,7 +74,7 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 
 	di.bootstrapServiceOpenvpn(nodeOptions)
 	di.bootstrapServiceNoop(nodeOptions)
-	resourcesAllocator := resources.NewAllocator(di.PortPool, wireguard_service.GetOptions().Subnet)
+	resourcesAllocator := resources.NewAllocator(di.PortPool, wireguard_service.ConfiguredSubnet())
 	di.bootstrapServiceWireguard(nodeOptions, resourcesAllocator)
 	di.bootstrapServiceScraping(nodeOptions, resourcesAllocator)
 	di.bootstrapServiceDataTransfer(nodeOptions, resourcesAllocator)
@@ -83,6 +98,11 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options, reso
 				di.EventBus,
 				di.ServiceFirewall,
 				resourcesAllocator,
+				di.BandwidthScheduler,
+				wireguard_service.TunnelOptions{
+					KeepAlive:    nodeOptions.Wireguard.ProviderKeepAlive,
+					RekeyTimeout: nodeOptions.Wireguard.RekeyTimeout,
+				},
 			)
 			return svc, nil
 		},
@@ -105,6 +125,11 @@ func (di *Dependencies) bootstrapServiceScraping(nodeOptions node.Options, resou
 				di.EventBus,
 				di.ServiceFirewall,
 				resourcesAllocator,
+				di.BandwidthScheduler,
+				wireguard_service.TunnelOptions{
+					KeepAlive:    nodeOptions.Wireguard.ProviderKeepAlive,
+					RekeyTimeout: nodeOptions.Wireguard.RekeyTimeout,
+				},
 			)
 			return svc, nil
 		},
@@ -127,6 +152,11 @@ func (di *Dependencies) bootstrapServiceDataTransfer(nodeOptions node.Options, r
 				di.EventBus,
 				di.ServiceFirewall,
 				resourcesAllocator,
+				di.BandwidthScheduler,
+				wireguard_service.TunnelOptions{
+					KeepAlive:    nodeOptions.Wireguard.ProviderKeepAlive,
+					RekeyTimeout: nodeOptions.Wireguard.RekeyTimeout,
+				},
 			)
 			return svc, nil
 		},
@@ -179,11 +209,11 @@ func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options)
 		di.BeneficiaryProvider,
 		di.HermesCaller,
 		di.AddressProvider,
-		di.SignerFactory,
+		di.auditedSignerFactory("hermes", "channel-request"),
 		di.Keystore,
 	)
 
-	if err := di.HermesChannelRepository.Subscribe(di.EventBus); err != nil {
+	if err := di.HermesChannelRepository.Subscribe(di.Supervisor.Subscriber("payments", di.EventBus)); err != nil {
 		log.Error().Err(err).Msg("Failed to subscribe channel repository")
 		return errors.Wrap(err, "could not subscribe channel repository to relevant events")
 	}
@@ -213,13 +243,34 @@ func (di *Dependencies) bootstrapHermesPromiseSettler(nodeOptions node.Options)
 			SettlementCheckInterval: nodeOptions.Payments.SettlementRecheckInterval,
 			L1ChainID:               nodeOptions.Chains.Chain1.ChainID,
 			L2ChainID:               nodeOptions.Chains.Chain2.ChainID,
+			BatchSettlementEnabled: func() bool {
+				return di.FeatureRegistry.Enabled(feature.PaymentAggregation)
+			},
 		},
 	)
-	if err := settler.Subscribe(di.EventBus); err != nil {
+	if err := settler.Subscribe(di.Supervisor.Subscriber("payments", di.EventBus)); err != nil {
 		return errors.Wrap(err, "could not subscribe promise settler to relevant events")
 	}
 
 	di.HermesPromiseSettler = settler
+	if nodeOptions.Payments.ShutdownSettleTimeout > 0 {
+		di.ShutdownSettler = pingpong.NewShutdownSettler(
+			settler,
+			di.HermesChannelRepository,
+			di.HermesPromiseStorage,
+			di.HermesPromiseHandler,
+			di.AddressProvider,
+			pingpong.ShutdownSettlerConfig{
+				ChainID:   nodeOptions.ChainID,
+				Threshold: nodeOptions.Payments.ShutdownSettleThreshold,
+				Timeout:   nodeOptions.Payments.ShutdownSettleTimeout,
+			},
+		)
+		if err := di.ShutdownSettler.Subscribe(di.EventBus); err != nil {
+			return errors.Wrap(err, "could not subscribe shutdown settler to service events")
+		}
+	}
+	di.WithdrawalFlow = pingpong.NewWithdrawalFlow(settler, di.EventBus)
 	return nil
 }
 
@@ -232,20 +283,113 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 	di.ServiceRegistry = service.NewRegistry()
 
 	di.ServiceSessions = service.NewSessionPool(di.EventBus)
+	di.SessionAdmission = service.NewSessionAdmission(
+		di.EventBus,
+		config.GetInt(config.FlagSessionsMax),
+		config.GetInt(config.FlagSessionsQueueSize),
+		config.GetDuration(config.FlagSessionsQueueTimeout),
+	)
+	di.ResourceShedder = service.NewResourceShedder(
+		di.SessionAdmission,
+		di.ServiceSessions,
+		service.SystemResources{},
+		di.EventBus,
+		service.ResourceShedderConfig{
+			Thresholds: service.ResourceUsage{
+				CPU:             config.GetFloat64(config.FlagSessionsSheddingCPU),
+				Memory:          config.GetFloat64(config.FlagSessionsSheddingMemory),
+				Conntrack:       config.GetFloat64(config.FlagSessionsSheddingConntrack),
+				FileDescriptors: config.GetFloat64(config.FlagSessionsSheddingFileDescriptors),
+			},
+			Interval:              config.GetDuration(config.FlagSessionsSheddingInterval),
+			DeprioritizeBandwidth: config.GetUInt64(config.FlagSessionsSheddingDeprioritizeBandwidth),
+			DeprioritizePercent:   config.GetInt(config.FlagSessionsSheddingDeprioritizePercent),
+		},
+	)
+	di.ResourceShedder.Start()
+	di.AutoPricer = pingpong.NewAutoPricer(di.PricingHelper, di.Storage, pingpong.AutoPricingConfig{
+		Enabled:    nodeOptions.Payments.ProviderAutoPricing,
+		Interval:   nodeOptions.Payments.ProviderAutoPricingInterval,
+		MinPerHour: nodeOptions.Payments.ProviderAutoPricingMinPerHour,
+		MaxPerHour: nodeOptions.Payments.ProviderAutoPricingMaxPerHour,
+		MinPerGiB:  nodeOptions.Payments.ProviderAutoPricingMinPerGiB,
+		MaxPerGiB:  nodeOptions.Payments.ProviderAutoPricingMaxPerGiB,
+	})
+	di.AutoPricer.Start()
+	di.AttestationVerifier = attestation.NewVerifier(
+		di.HTTPClient,
+		config.GetString(config.FlagAttestationAddress),
+		strings.Split(config.GetString(config.FlagAttestationServices), ","),
+		config.GetDuration(config.FlagAttestationCacheTTL),
+	)
 
+	var policyVerifier identity.Verifier
+	if signer := config.GetString(config.FlagAccessPolicySigner); signer != "" {
+		policyVerifier = identity.NewVerifierIdentity(identity.FromAddress(signer))
+	} else {
+		log.Warn().Msgf("%s is not set, access policy rules are accepted without verifying their signature", config.FlagAccessPolicySigner.Name)
+	}
 	di.PolicyOracle = policy.NewOracle(
 		di.HTTPClient,
 		config.GetString(config.FlagAccessPolicyAddress),
 		config.GetDuration(config.FlagAccessPolicyFetchInterval),
+		policyVerifier,
+		di.Storage,
 	)
 	go di.PolicyOracle.Start()
 
-	di.HermesStatusChecker = pingpong.NewHermesStatusChecker(di.BCHelper, di.ObserverAPI, nodeOptions.Payments.HermesStatusRecheckInterval)
+	if nodeOptions.Payments.HermesAvailabilityInterval > 0 {
+		di.HermesAvailability = pingpong.NewHermesAvailabilityMonitor(
+			di.HermesURLGetter,
+			di.AddressProvider,
+			di.HTTPClient,
+			di.EventBus,
+			[]int64{nodeOptions.ChainID},
+			nodeOptions.Payments.HermesAvailabilityInterval,
+		)
+		di.Supervisor.Go("payments", di.HermesAvailability.Start)
+	}
+
+	if nodeOptions.Payments.InvoiceWatchdogInterval > 0 {
+		di.InvoiceWatchdog = pingpong.NewInvoiceWatchdog(di.EventBus, nodeOptions.Payments.InvoiceWatchdogInterval)
+		di.Supervisor.Go("payments", di.InvoiceWatchdog.Start)
+	}
+
+	chargePeriods, err := service.ParseChargePeriods(nodeOptions.Payments.ProviderChargePeriods)
+	if err != nil {
+		return err
+	}
+	sessionManagerConfig := service.DefaultConfig()
+	sessionManagerConfig.KeepAlive.DeadPeer = keepAliveDeadPeerConfig(sessionManagerConfig.KeepAlive.DeadPeer)
+	sessionManagerConfig.ChargePeriod = service.ChargePeriodConfig{
+		PerService: chargePeriods,
+		Min:        nodeOptions.Payments.ProviderChargePeriodMin,
+		Max:        nodeOptions.Payments.ProviderChargePeriodMax,
+	}
+	sessionManagerConfig.MaxPauseDuration = config.GetDuration(config.FlagSessionsMaxPause)
+	consumerAllowedNetworks, err := service.ParseConsumerAllowedNetworks(config.GetString(config.FlagFirewallConsumerAllowedNetworks))
+	if err != nil {
+		return err
+	}
+	sessionManagerConfig.Firewall = service.FirewallConfig{
+		AllowedNetworks:         nat.DefaultAllowedNetworks(),
+		ConsumerAllowedNetworks: consumerAllowedNetworks,
+	}
+
+	var consumerLocator *location.DBResolver
+	consumerCountries := config.GetStringSlice(config.FlagAccessPolicyConsumerCountries)
+	if len(consumerCountries) > 0 {
+		consumerLocator, err = location.NewBuiltInResolver(di.IPResolver)
+		if err != nil {
+			return errors.Wrap(err, "could not create consumer country resolver")
+		}
+	}
 
 	newP2PSessionHandler := func(serviceInstance *service.Instance, channel p2p.Channel) *service.SessionManager {
 		paymentEngineFactory := pingpong.InvoiceFactoryCreator(
 			channel, nodeOptions.Payments.ProviderInvoiceFrequency, nodeOptions.Payments.ProviderLimitInvoiceFrequency,
-			pingpong.PromiseWaitTimeout, di.ProviderInvoiceStorage,
+			pingpong.PromiseWaitTimeout, nodeOptions.Payments.ProviderInitialFreeWindow,
+			di.ProviderInvoiceStorage,
 			pingpong.DefaultHermesFailureCount,
 			uint16(nodeOptions.Payments.MaxAllowedPaymentPercentile),
 			nodeOptions.Payments.MaxUnpaidInvoiceValue,
@@ -255,6 +399,23 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			di.HermesPromiseHandler,
 			di.AddressProvider,
 			di.ObserverAPI,
+			pingpong.BillingAnomalyConfig{
+				Tolerance:     nodeOptions.Payments.ProviderBillingAnomalyTolerance,
+				MaxHourlyRate: nodeOptions.Payments.ProviderBillingAnomalyMaxHourlyRate,
+				PauseBilling:  nodeOptions.Payments.ProviderBillingAnomalyPause,
+				PauseDuration: nodeOptions.Payments.ProviderBillingAnomalyPauseDuration,
+			},
+			pingpong.HermesFeeChangeConfig{
+				Policy:        pingpong.HermesFeePolicy(nodeOptions.Payments.ProviderHermesFeePolicy),
+				GracePeriod:   nodeOptions.Payments.ProviderHermesFeeGracePeriod,
+				CheckInterval: nodeOptions.Payments.ProviderHermesFeeCheckInterval,
+			},
+			pingpong.PeerClockSkewConfig{
+				Tolerance:  nodeOptions.Payments.PeerClockSkewTolerance,
+				MaxSkew:    nodeOptions.Payments.PeerClockSkewMax,
+				LocalClock: di.ClockSkew,
+			},
+			di.InvoiceWatchdog,
 		)
 		return service.NewSessionManager(
 			serviceInstance,
@@ -262,11 +423,21 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			paymentEngineFactory,
 			di.EventBus,
 			channel,
-			service.DefaultConfig(),
-			di.PricingHelper,
+			sessionManagerConfig,
+			di.AutoPricer,
+			di.ResourceShedder,
+			di.AttestationVerifier,
+			consumerLocator,
+			di.ProviderMigrator,
 		)
 	}
 
+	di.NodeAttester = nodeattest.NewAttester(di.Storage, di.auditedSignerFactory("node", "attestation-key"), market.NodeMetadata{
+		Version:  metadata.VersionAsString(),
+		Launcher: config.GetString(config.FlagLauncherVersion),
+		OS:       runtime.GOOS,
+	})
+
 	di.ServicesManager = service.NewManager(
 		di.ServiceRegistry,
 		di.DiscoveryFactory,
@@ -276,13 +447,39 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		newP2PSessionHandler,
 		di.SessionConnectivityStatusStorage,
 		di.LocationResolver,
+		di.ServiceSessions,
+		di.NodeAttester,
+		di.AutoPricer,
+		consumerCountries,
 	)
+	di.ProposalZombieDetector.Start()
 
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessions}
 	if err := di.EventBus.Subscribe(servicestate.AppTopicServiceStatus, serviceCleaner.HandleServiceStatus); err != nil {
 		log.Error().Err(err).Msg("Failed to subscribe service cleaner")
 	}
 
+	di.SessionCollector = service.NewSessionCollector(di.ServiceSessions, config.GetDuration(config.FlagSessionsStaleTimeout), sessionManagerConfig.MaxPauseDuration)
+	if err := di.SessionCollector.Subscribe(di.Supervisor.Subscriber("session collector", di.EventBus)); err != nil {
+		return err
+	}
+	di.SessionCollector.Start()
+
+	if nodeOptions.AccessLog.Enabled {
+		di.AccessLog = accesslog.NewLogger(di.Storage, nodeOptions.AccessLog.Retention, accesslog.ParseAnonymization(nodeOptions.AccessLog.Anonymization))
+		if err := di.AccessLog.Subscribe(di.EventBus); err != nil {
+			return err
+		}
+		di.AccessLog.Start()
+	}
+
+	if config.GetBool(config.FlagPcapEnable) {
+		di.PacketCapturer = pcap.NewCapturer(filepath.Join(nodeOptions.Directories.Data, "pcap"))
+		if err := di.PacketCapturer.Subscribe(di.EventBus); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -307,6 +504,8 @@ func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options, re
 		opts := wireguard_connection.Options{
 			DNSScriptDir:     nodeOptions.Directories.Script,
 			HandshakeTimeout: 1 * time.Minute,
+			KeepAlive:        nodeOptions.Wireguard.KeepAlive,
+			RekeyTimeout:     nodeOptions.Wireguard.RekeyTimeout,
 		}
 		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
 	}
@@ -323,6 +522,8 @@ func (di *Dependencies) registerScrapingConnection(nodeOptions node.Options, res
 		opts := wireguard_connection.Options{
 			DNSScriptDir:     nodeOptions.Directories.Script,
 			HandshakeTimeout: 1 * time.Minute,
+			KeepAlive:        nodeOptions.Wireguard.KeepAlive,
+			RekeyTimeout:     nodeOptions.Wireguard.RekeyTimeout,
 		}
 		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
 	}
@@ -339,6 +540,8 @@ func (di *Dependencies) registerDataTransferConnection(nodeOptions node.Options,
 		opts := wireguard_connection.Options{
 			DNSScriptDir:     nodeOptions.Directories.Script,
 			HandshakeTimeout: 1 * time.Minute,
+			KeepAlive:        nodeOptions.Wireguard.KeepAlive,
+			RekeyTimeout:     nodeOptions.Wireguard.RekeyTimeout,
 		}
 		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
 	}
@@ -346,8 +549,51 @@ func (di *Dependencies) registerDataTransferConnection(nodeOptions node.Options,
 }
 
 func (di *Dependencies) bootstrapMMN() error {
-	client := mmn.NewClient(di.HTTPClient, config.GetString(config.FlagMMNAPIAddress), di.SignerFactory)
+	client := mmn.NewClient(di.HTTPClient, config.GetString(config.FlagMMNAPIAddress), di.auditedSignerFactory("mmn", "api-request"))
 
 	di.MMN = mmn.NewMMN(di.IPResolver, client)
 	return di.MMN.Subscribe(di.EventBus)
 }
+
+// bootstrapMMNStatusReporter starts pushing provider status to MMN, if enabled and the API key is configured.
+func (di *Dependencies) bootstrapMMNStatusReporter() {
+	apiKey := config.GetString(config.FlagMMNAPIKey)
+	interval := config.GetDuration(config.FlagMMNStatusReportInterval)
+	if !config.GetBool(config.FlagMMNStatusReportEnabled) || apiKey == "" || interval <= 0 {
+		log.Debug().Msg("Provider status reporting to MMN disabled")
+		return
+	}
+
+	client := mmn.NewClient(di.HTTPClient, config.GetString(config.FlagMMNAPIAddress), di.auditedSignerFactory("mmn", "api-request"))
+	di.MMNStatusReporter = mmn.NewStatusReporter(client, di.StateKeeper, di.NATTypeTracker, apiKey, interval)
+	di.MMNStatusReporter.Start()
+}
+
+// bootstrapPreflight initiates provider startup checks.
+func (di *Dependencies) bootstrapPreflight(nodeOptions node.Options) {
+	hermesChecker := di.HermesAvailability
+	if hermesChecker == nil {
+		// Monitor is not started, it's only used for one-off startup checks.
+		hermesChecker = pingpong.NewHermesAvailabilityMonitor(di.HermesURLGetter, di.AddressProvider, di.HTTPClient, di.EventBus, nil, 0)
+	}
+	di.Preflight = preflight.NewRunner(
+		preflight.Deps{
+			Registry:        di.IdentityRegistry,
+			AddressProvider: di.AddressProvider,
+			Channels:        di.HermesChannelRepository,
+			Hermes:          hermesChecker,
+			NATProber:       di.NATProber,
+			ValidateService: func(serviceType string) error {
+				_, err := services.GetStartOptions(serviceType)
+				return err
+			},
+		},
+		preflight.Config{
+			ChainID:         nodeOptions.ChainID,
+			MinStake:        crypto.FloatToBigMyst(config.GetFloat64(config.FlagPreflightMinStake)),
+			UDPPorts:        config.GetString(config.FlagUDPListenPorts),
+			ChargePeriodMin: nodeOptions.Payments.ProviderChargePeriodMin,
+			ChargePeriodMax: nodeOptions.Payments.ProviderChargePeriodMax,
+		},
+	)
+}
@@ -25,9 +25,11 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/apidiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/dhtdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/mdnsdiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/pkg/errors"
 )
 
@@ -36,12 +38,20 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 	proposalRepository := discovery.NewRepository()
 	proposalRegistry := discovery.NewRegistry()
 	discoveryWorker := discovery.NewWorker()
+	var freeProposalSources []discovery.FreeProposalSource
+
+	proposalTTL := options.ProposalTTL
+	if proposalTTL == 0 {
+		proposalTTL = 2 * options.PingInterval
+	} else if proposalTTL <= options.PingInterval {
+		return errors.Errorf("proposal TTL %s must exceed ping interval %s", proposalTTL, options.PingInterval)
+	}
 
 	for _, discoveryType := range options.Types {
 		switch discoveryType {
 		case node.DiscoveryTypeAPI:
 			// Broker is the way to announce node presence currently, so enabled by default no matter the users preferences.
-			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection))
+			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection, proposalTTL))
 			proposalRepository.Add(apidiscovery.NewRepository(di.MysteriumAPI))
 
 		case node.DiscoveryTypeBroker:
@@ -51,7 +61,7 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 				discoveryWorker.AddWorker(brokerRepository)
 			}
 
-			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection))
+			proposalRegistry.AddRegistry(brokerdiscovery.NewRegistry(di.BrokerConnection, proposalTTL))
 			proposalRepository.Add(brokerRepository)
 
 		case node.DiscoveryTypeDHT:
@@ -67,6 +77,19 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 			proposalRegistry.AddRegistry(dhtdiscovery.NewRegistry())
 			proposalRepository.Add(dhtdiscovery.NewRepository())
 
+		case node.DiscoveryTypeLAN:
+			lanRegistry := mdnsdiscovery.NewRegistry(proposalTTL, options.LAN.Free)
+			discoveryWorker.AddWorker(lanRegistry)
+
+			lanRepository := mdnsdiscovery.NewRepository(brokerdiscovery.NewStorage(di.EventBus), 10*time.Second)
+			if options.FetchEnabled {
+				discoveryWorker.AddWorker(lanRepository)
+			}
+
+			proposalRegistry.AddRegistry(lanRegistry)
+			proposalRepository.Add(lanRepository)
+			freeProposalSources = append(freeProposalSources, lanRepository)
+
 		default:
 			return errors.Errorf("unknown discovery adapter: %s", discoveryType)
 		}
@@ -77,9 +100,28 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 		return errors.Wrap(err, "failed to start discovery")
 	}
 
-	di.ProposalRepository = discovery.NewPricedServiceProposalRepository(proposalRepository, di.PricingHelper, di.FilterPresetStorage)
+	pricedRepository := discovery.NewPricedServiceProposalRepository(proposalRepository, di.PricingHelper, di.FilterPresetStorage)
+	for _, source := range freeProposalSources {
+		pricedRepository.AddFreeProposalSource(source)
+	}
+	di.ProposalRepository = pricedRepository
 	di.DiscoveryFactory = func() service.Discovery {
-		return discovery.NewService(di.IdentityRegistry, proposalRegistry, options.PingInterval, di.SignerFactory, di.EventBus)
+		return discovery.NewService(di.IdentityRegistry, proposalRegistry, options.PingInterval, di.auditedSignerFactory("discovery", "proposal"), di.Supervisor.EventBus("discovery", di.EventBus))
+	}
+
+	di.ProposalZombieDetector = discovery.NewZombieDetector(proposalRepository, proposalRegistry, di.auditedSignerFactory("discovery", "proposal"), di.servedProposals, di.EventBus, options.PingInterval)
+	if err := di.ProposalZombieDetector.Subscribe(di.Supervisor.Subscriber("discovery", di.EventBus)); err != nil {
+		return errors.Wrap(err, "failed to subscribe zombie proposal detector")
 	}
+
 	return nil
 }
+
+func (di *Dependencies) servedProposals() []market.ServiceProposal {
+	instances := di.ServicesManager.List(false)
+	proposals := make([]market.ServiceProposal, 0, len(instances))
+	for _, instance := range instances {
+		proposals = append(proposals, instance.Proposal)
+	}
+	return proposals
+}
@@ -22,6 +22,7 @@ import (
 	"sync"
 
 	"github.com/mysteriumnetwork/node/cmd/commands/account"
+	"github.com/mysteriumnetwork/node/cmd/commands/benchmark"
 	command_cli "github.com/mysteriumnetwork/node/cmd/commands/cli"
 	command_cfg "github.com/mysteriumnetwork/node/cmd/commands/config"
 	"github.com/mysteriumnetwork/node/cmd/commands/connection"
@@ -53,6 +54,7 @@ var (
 	accountCommand    = account.NewCommand()
 	connectionCommand = connection.NewCommand()
 	configCommand     = command_cfg.NewCommand()
+	benchmarkCommand  = benchmark.NewCommand()
 )
 
 func main() {
@@ -99,6 +101,12 @@ func NewCommand() (*cli.App, error) {
 		accountCommand,
 		connectionCommand,
 		configCommand,
+		benchmarkCommand,
+	}
+
+	config.BindEnvVars(app.Flags)
+	for _, command := range app.Commands {
+		config.BindCommandEnvVars(command)
 	}
 
 	return app, nil
@@ -117,6 +125,7 @@ var uiCommands = map[string]struct{}{
 	connection.CommandName:  {},
 	command_cfg.CommandName: {},
 	reset.CommandName:       {},
+	benchmark.CommandName:   {},
 }
 
 // configureLogging returns a func which configures global
@@ -47,12 +47,43 @@ type Config struct {
 	user               map[string]interface{}
 	cli                map[string]interface{}
 	eventBus           eventbus.EventBus
+	schema             map[string]Option
 	mu                 sync.RWMutex
 }
 
 // Current global configuration instance.
 var Current = NewConfig()
 
+// cliOnlyOptions may be set only through the CLI flags or the environment, as they choose executables
+// run by the node, destinations the node sends data to or hold secrets. User configuration can not set them,
+// so API clients able to change the user configuration can not change them either.
+var cliOnlyOptions = []string{
+	FlagHooksWebhookURL.Name,
+	FlagHooksScript.Name,
+	FlagHooksSecret.Name,
+	FlagHooksPaymentURLs.Name,
+}
+
+// secretOptions are redacted from the configuration returned by the node.
+var secretOptions = []string{
+	FlagHooksSecret.Name,
+}
+
+// redactedValue replaces the values of the secret options.
+const redactedValue = "[redacted]"
+
+// IsCLIOnly reports whether the option, or any of the options nested under the key, may only be set through
+// the CLI flags or the environment.
+func IsCLIOnly(key string) bool {
+	key = strings.ToLower(key)
+	for _, option := range cliOnlyOptions {
+		if option == key || strings.HasPrefix(option, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
 // NewConfig creates a new configuration instance.
 func NewConfig() *Config {
 	return &Config{
@@ -60,6 +91,7 @@ func NewConfig() *Config {
 		defaults:           make(map[string]interface{}),
 		user:               make(map[string]interface{}),
 		cli:                make(map[string]interface{}),
+		schema:             make(map[string]Option),
 	}
 }
 
@@ -86,6 +118,14 @@ func (cfg *Config) LoadUserConfig(location string) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to decode configuration file")
 	}
+	for _, option := range cliOnlyOptions {
+		segments := strings.Split(option, ".")
+		if SearchMap(cfg.user, segments) == nil {
+			continue
+		}
+		log.Warn().Msgf("Ignoring %q in the user configuration, it may only be set by a flag or an environment variable", option)
+		delete(deepSearch(cfg.user, segments[:len(segments)-1]), segments[len(segments)-1])
+	}
 	cfgJson, err := jsonutil.ToJson(cfg.user)
 	if err != nil {
 		return err
@@ -126,14 +166,14 @@ func (cfg *Config) GetDefaultConfig() map[string]interface{} {
 	return deepCopyStrMap(cfg.defaults)
 }
 
-// GetUserConfig returns user configuration.
+// GetUserConfig returns user configuration with the secrets redacted.
 func (cfg *Config) GetUserConfig() map[string]interface{} {
 	cfg.mu.RLock()
 	defer cfg.mu.RUnlock()
-	return deepCopyStrMap(cfg.user)
+	return redactSecrets(deepCopyStrMap(cfg.user))
 }
 
-// GetConfig returns current configuration.
+// GetConfig returns current configuration with the secrets redacted.
 func (cfg *Config) GetConfig() map[string]interface{} {
 	cfg.mu.RLock()
 	defer cfg.mu.RUnlock()
@@ -141,7 +181,19 @@ func (cfg *Config) GetConfig() map[string]interface{} {
 	mergeMaps(deepCopyStrMap(cfg.defaults), config, nil)
 	mergeMaps(deepCopyStrMap(cfg.user), config, nil)
 	mergeMaps(deepCopyStrMap(cfg.cli), config, nil)
-	return deepCopyStrMap(config)
+	return redactSecrets(deepCopyStrMap(config))
+}
+
+// redactSecrets replaces the values of the set secret options in the given configuration.
+func redactSecrets(config map[string]interface{}) map[string]interface{} {
+	for _, option := range secretOptions {
+		segments := strings.Split(option, ".")
+		if value := SearchMap(config, segments); value == nil || value == "" {
+			continue
+		}
+		deepSearch(config, segments[:len(segments)-1])[segments[len(segments)-1]] = redactedValue
+	}
+	return config
 }
 
 // SetDefault sets default value for key.
@@ -293,6 +345,7 @@ func (cfg *Config) GetStringSlice(key string) []string {
 // ParseBoolFlag parses a cli.BoolFlag from command's context and
 // sets default and CLI values to the application configuration.
 func (cfg *Config) ParseBoolFlag(ctx *cli.Context, flag cli.BoolFlag) {
+	cfg.describe(flag.Name, OptionTypeBool, flag.Usage, flag.Value)
 	cfg.SetDefault(flag.Name, flag.Value)
 	if ctx.IsSet(flag.Name) {
 		cfg.SetCLI(flag.Name, ctx.Bool(flag.Name))
@@ -304,6 +357,7 @@ func (cfg *Config) ParseBoolFlag(ctx *cli.Context, flag cli.BoolFlag) {
 // ParseIntFlag parses a cli.IntFlag from command's context and
 // sets default and CLI values to the application configuration.
 func (cfg *Config) ParseIntFlag(ctx *cli.Context, flag cli.IntFlag) {
+	cfg.describe(flag.Name, OptionTypeInt, flag.Usage, flag.Value)
 	cfg.SetDefault(flag.Name, flag.Value)
 	if ctx.IsSet(flag.Name) {
 		cfg.SetCLI(flag.Name, ctx.Int(flag.Name))
@@ -315,6 +369,7 @@ func (cfg *Config) ParseIntFlag(ctx *cli.Context, flag cli.IntFlag) {
 // ParseUInt64Flag parses a cli.Uint64Flag from command's context and
 // sets default and CLI values to the application configuration.
 func (cfg *Config) ParseUInt64Flag(ctx *cli.Context, flag cli.Uint64Flag) {
+	cfg.describe(flag.Name, OptionTypeUInt64, flag.Usage, flag.Value)
 	cfg.SetDefault(flag.Name, flag.Value)
 	if ctx.IsSet(flag.Name) {
 		cfg.SetCLI(flag.Name, ctx.Uint64(flag.Name))
@@ -326,6 +381,7 @@ func (cfg *Config) ParseUInt64Flag(ctx *cli.Context, flag cli.Uint64Flag) {
 // ParseInt64Flag parses a cli.Int64Flag from command's context and
 // sets default and CLI values to the application configuration.
 func (cfg *Config) ParseInt64Flag(ctx *cli.Context, flag cli.Int64Flag) {
+	cfg.describe(flag.Name, OptionTypeInt64, flag.Usage, flag.Value)
 	cfg.SetDefault(flag.Name, flag.Value)
 	if ctx.IsSet(flag.Name) {
 		cfg.SetCLI(flag.Name, ctx.Int64(flag.Name))
@@ -337,6 +393,7 @@ func (cfg *Config) ParseInt64Flag(ctx *cli.Context, flag cli.Int64Flag) {
 // ParseFloat64Flag parses a cli.Float64Flag from command's context and
 // sets default and CLI values to the application configuration.
 func (cfg *Config) ParseFloat64Flag(ctx *cli.Context, flag cli.Float64Flag) {
+	cfg.describe(flag.Name, OptionTypeFloat64, flag.Usage, flag.Value)
 	cfg.SetDefault(flag.Name, flag.Value)
 	if ctx.IsSet(flag.Name) {
 		cfg.SetCLI(flag.Name, ctx.Float64(flag.Name))
@@ -348,6 +405,7 @@ func (cfg *Config) ParseFloat64Flag(ctx *cli.Context, flag cli.Float64Flag) {
 // ParseDurationFlag parses a cli.DurationFlag from command's context and
 // sets default and CLI values to the application configuration.
 func (cfg *Config) ParseDurationFlag(ctx *cli.Context, flag cli.DurationFlag) {
+	cfg.describe(flag.Name, OptionTypeDuration, flag.Usage, durationDefault(flag.Value))
 	cfg.SetDefault(flag.Name, flag.Value)
 	if ctx.IsSet(flag.Name) {
 		cfg.SetCLI(flag.Name, ctx.Duration(flag.Name))
@@ -359,6 +417,7 @@ func (cfg *Config) ParseDurationFlag(ctx *cli.Context, flag cli.DurationFlag) {
 // ParseStringFlag parses a cli.StringFlag from command's context and
 // sets default and CLI values to the application configuration.
 func (cfg *Config) ParseStringFlag(ctx *cli.Context, flag cli.StringFlag) {
+	cfg.describe(flag.Name, OptionTypeString, flag.Usage, flag.Value)
 	cfg.SetDefault(flag.Name, flag.Value)
 	if ctx.IsSet(flag.Name) {
 		cfg.SetCLI(flag.Name, ctx.String(flag.Name))
@@ -370,6 +429,7 @@ func (cfg *Config) ParseStringFlag(ctx *cli.Context, flag cli.StringFlag) {
 // ParseStringSliceFlag parses a cli.StringSliceFlag from command's context and
 // sets default and CLI values to the application configuration.
 func (cfg *Config) ParseStringSliceFlag(ctx *cli.Context, flag cli.StringSliceFlag) {
+	cfg.describe(flag.Name, OptionTypeStringSlice, flag.Usage, flag.Value.Value())
 	cfg.SetDefault(flag.Name, flag.Value.Value())
 	if ctx.IsSet(flag.Name) {
 		cfg.SetCLI(flag.Name, ctx.StringSlice(flag.Name))
@@ -382,6 +442,7 @@ func (cfg *Config) ParseStringSliceFlag(ctx *cli.Context, flag cli.StringSliceFl
 // from command's context and sets default values for network parameters
 // and CLI values for the network to the application configuration.
 func (cfg *Config) ParseBlockchainNetworkFlag(ctx *cli.Context, flag cli.StringFlag) {
+	cfg.describe(flag.Name, OptionTypeString, flag.Usage, flag.Value)
 	cfg.SetDefault(flag.Name, flag.Value)
 	if ctx.IsSet(flag.Name) {
 		network, err := ParseBlockchainNetwork(ctx.String(flag.Name))
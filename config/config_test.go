@@ -232,6 +232,43 @@ func TestUserConfig_GetConfig(t *testing.T) {
 	)
 }
 
+func TestUserConfig_LoadIgnoresCLIOnlyOptions(t *testing.T) {
+	configFileName := NewTempFileName(t)
+	defer os.Remove(configFileName)
+
+	toml := `
+		[hooks]
+		script = "/tmp/evil.sh"
+		timeout = "5s"
+	`
+	err := ioutil.WriteFile(configFileName, []byte(toml), 0700)
+	assert.NoError(t, err)
+
+	cfg := NewConfig()
+	err = cfg.LoadUserConfig(configFileName)
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.Get(FlagHooksScript.Name))
+	assert.Equal(t, "5s", cfg.GetString(FlagHooksTimeout.Name))
+}
+
+func TestIsCLIOnly(t *testing.T) {
+	assert.True(t, IsCLIOnly("hooks.script"))
+	assert.True(t, IsCLIOnly("Hooks.Secret"))
+	assert.True(t, IsCLIOnly("hooks"))
+	assert.False(t, IsCLIOnly("hooks.timeout"))
+	assert.False(t, IsCLIOnly("hooks.scr"))
+}
+
+func TestConfig_RedactsSecrets(t *testing.T) {
+	cfg := NewConfig()
+	cfg.SetDefault(FlagHooksSecret.Name, "")
+	assert.Equal(t, "", cfg.GetConfig()["hooks"].(map[string]interface{})["secret"])
+
+	cfg.SetCLI(FlagHooksSecret.Name, "secret")
+	assert.Equal(t, redactedValue, cfg.GetConfig()["hooks"].(map[string]interface{})["secret"])
+	assert.Equal(t, "secret", cfg.GetString(FlagHooksSecret.Name))
+}
+
 func must(t *testing.T, err error) {
 	assert.NoError(t, err)
 }
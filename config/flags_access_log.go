@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagAccessLogEnabled enables recording of consumer connections served by the provider.
+	FlagAccessLogEnabled = cli.BoolFlag{
+		Name:  "provider.access-log.enabled",
+		Usage: "Record consumer connections served by the provider into a local access log",
+		Value: false,
+	}
+	// FlagAccessLogRetention how long the consumer connections are kept in the access log.
+	FlagAccessLogRetention = cli.DurationFlag{
+		Name:  "provider.access-log.retention",
+		Usage: "How long the consumer connections are kept in the access log, 0 keeps them forever",
+		Value: 7 * 24 * time.Hour,
+	}
+	// FlagAccessLogAnonymization how consumer identities are recorded in the access log.
+	FlagAccessLogAnonymization = cli.StringFlag{
+		Name:  "provider.access-log.anonymization",
+		Usage: "How consumer identities are recorded in the access log: 'none' keeps them as is, 'hash' replaces them with a keyed hash, 'strict' omits them",
+		Value: "hash",
+	}
+)
+
+// RegisterFlagsAccessLog function register provider access log flags to flag list
+func RegisterFlagsAccessLog(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagAccessLogEnabled,
+		&FlagAccessLogRetention,
+		&FlagAccessLogAnonymization,
+	)
+}
+
+// ParseFlagsAccessLog function fills in provider access log options from CLI context
+func ParseFlagsAccessLog(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagAccessLogEnabled)
+	Current.ParseDurationFlag(ctx, FlagAccessLogRetention)
+	Current.ParseStringFlag(ctx, FlagAccessLogAnonymization)
+}
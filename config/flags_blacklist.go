@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagBlacklistThreshold sets the failure score starting from which the provider is excluded from auto-selection.
+	FlagBlacklistThreshold = cli.Float64Flag{
+		Name:  "consumer.blacklist.threshold",
+		Usage: "Failure score starting from which the provider is excluded from auto-selection. Zero disables the blacklist",
+		Value: 3,
+	}
+	// FlagBlacklistHalfLife sets the period in which the provider failure score decays by half.
+	FlagBlacklistHalfLife = cli.DurationFlag{
+		Name:  "consumer.blacklist.half-life",
+		Usage: `Period in which the provider failure score decays by half { "30m", "1h" }`,
+		Value: 30 * time.Minute,
+	}
+)
+
+// RegisterFlagsBlacklist function register provider blacklist flags to flag list
+func RegisterFlagsBlacklist(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagBlacklistThreshold,
+		&FlagBlacklistHalfLife,
+	)
+}
+
+// ParseFlagsBlacklist function fills in provider blacklist options from CLI context
+func ParseFlagsBlacklist(ctx *cli.Context) {
+	Current.ParseFloat64Flag(ctx, FlagBlacklistThreshold)
+	Current.ParseDurationFlag(ctx, FlagBlacklistHalfLife)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagDNSLocalAddress address the local DNS resolver listens on.
+	FlagDNSLocalAddress = cli.StringFlag{
+		Name:  "dns.local.address",
+		Usage: "Address the local DNS resolver listens on, when the connection uses the 'local' DNS option",
+		Value: "127.0.0.1",
+	}
+	// FlagDNSLocalPort port the local DNS resolver listens on.
+	FlagDNSLocalPort = cli.IntFlag{
+		Name:  "dns.local.port",
+		Usage: "Port the local DNS resolver listens on",
+		Value: 53,
+	}
+	// FlagDNSLocalCacheSize maximum number of answers cached by the local DNS resolver.
+	FlagDNSLocalCacheSize = cli.IntFlag{
+		Name:  "dns.local.cache-size",
+		Usage: "Maximum number of answers cached by the local DNS resolver, 0 disables the cache",
+		Value: 1000,
+	}
+	// FlagDNSLocalUpstreams upstream DNS servers of the local resolver profiles.
+	FlagDNSLocalUpstreams = cli.StringSliceFlag{
+		Name:  "dns.local.upstreams",
+		Usage: "Upstream DNS servers of the local resolver in the form '[profile=]server', e.g. 'family=1.1.1.3'. Tunnel DNS servers are used if none given",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagDNSLocalBlocklist domains the local resolver profiles refuse to resolve.
+	FlagDNSLocalBlocklist = cli.StringSliceFlag{
+		Name:  "dns.local.blocklist",
+		Usage: "Domains the local resolver refuses to resolve in the form '[profile=]domain', e.g. 'ads.example.com'",
+		Value: cli.NewStringSlice(),
+	}
+)
+
+// RegisterFlagsDNS function register local DNS resolver flags to flag list
+func RegisterFlagsDNS(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagDNSLocalAddress,
+		&FlagDNSLocalPort,
+		&FlagDNSLocalCacheSize,
+		&FlagDNSLocalUpstreams,
+		&FlagDNSLocalBlocklist,
+	)
+}
+
+// ParseFlagsDNS function fills in local DNS resolver options from CLI context
+func ParseFlagsDNS(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagDNSLocalAddress)
+	Current.ParseIntFlag(ctx, FlagDNSLocalPort)
+	Current.ParseIntFlag(ctx, FlagDNSLocalCacheSize)
+	Current.ParseStringSliceFlag(ctx, FlagDNSLocalUpstreams)
+	Current.ParseStringSliceFlag(ctx, FlagDNSLocalBlocklist)
+}
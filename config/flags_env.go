@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// EnvPrefix prefixes the environment variables of all the flags,
+// e.g. flag "discovery.dht.port" is read from MYST_DISCOVERY_DHT_PORT.
+const EnvPrefix = "MYST_"
+
+var (
+	// FlagConfigFromEnvOnly restricts configuration sources to the environment variables.
+	FlagConfigFromEnvOnly = cli.BoolFlag{
+		Name: "config-from-env-only",
+		Usage: "Take configuration from " + EnvPrefix + "* environment variables and defaults only: " +
+			"the user config file is not loaded, flags given on the command line and unknown " + EnvPrefix + "* variables fail the start",
+		Value: false,
+	}
+)
+
+// RegisterFlagsEnv function register environment configuration flags to flag list
+func RegisterFlagsEnv(flags *[]cli.Flag) {
+	*flags = append(*flags, &FlagConfigFromEnvOnly)
+}
+
+// ParseFlagsEnv function fills in environment configuration options from CLI context
+func ParseFlagsEnv(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagConfigFromEnvOnly)
+}
+
+// EnvVarName returns the environment variable of the flag with the given name.
+func EnvVarName(flagName string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, flagName)
+	return EnvPrefix + strings.ToUpper(name)
+}
+
+// BindEnvVars makes the given flags readable from their environment variables.
+// Values given on the command line still take precedence over the environment.
+func BindEnvVars(flags []cli.Flag) {
+	for _, f := range flags {
+		env := []string{EnvVarName(f.Names()[0])}
+		switch flag := f.(type) {
+		case *cli.BoolFlag:
+			flag.EnvVars = env
+		case *cli.IntFlag:
+			flag.EnvVars = env
+		case *cli.Int64Flag:
+			flag.EnvVars = env
+		case *cli.Uint64Flag:
+			flag.EnvVars = env
+		case *cli.Float64Flag:
+			flag.EnvVars = env
+		case *cli.DurationFlag:
+			flag.EnvVars = env
+		case *cli.StringFlag:
+			flag.EnvVars = env
+		case *cli.StringSliceFlag:
+			flag.EnvVars = env
+		}
+	}
+}
+
+// BindCommandEnvVars binds the flags of the command and all its subcommands to their environment variables.
+func BindCommandEnvVars(command *cli.Command) {
+	BindEnvVars(command.Flags)
+	for _, sub := range command.Subcommands {
+		BindCommandEnvVars(sub)
+	}
+}
+
+// CheckEnvOnly verifies that the configuration of the running command comes from the environment only.
+// It fails on the prefixed environment variables no flag of the application reads
+// and on the flags of the command given on the command line, except the strict mode flag itself.
+func CheckEnvOnly(ctx *cli.Context, environ []string) error {
+	known := make(map[string]bool)
+	for _, f := range appFlags(ctx.App) {
+		known[EnvVarName(f.Names()[0])] = true
+	}
+
+	present := make(map[string]bool)
+	var unknown []string
+	for _, kv := range environ {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		present[name] = true
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown configuration environment variables: %s", strings.Join(unknown, ", "))
+	}
+
+	var given []string
+	for _, f := range contextFlags(ctx) {
+		name := f.Names()[0]
+		if name == FlagConfigFromEnvOnly.Name {
+			continue
+		}
+		if ctx.IsSet(name) && !present[EnvVarName(name)] {
+			given = append(given, "--"+name)
+		}
+	}
+	if len(given) > 0 {
+		sort.Strings(given)
+		return fmt.Errorf("flags given on the command line while configuration is taken from the environment only: %s", strings.Join(given, ", "))
+	}
+	return nil
+}
+
+func appFlags(app *cli.App) []cli.Flag {
+	if app == nil {
+		return nil
+	}
+	flags := append([]cli.Flag{}, app.Flags...)
+	var collect func(commands []*cli.Command)
+	collect = func(commands []*cli.Command) {
+		for _, c := range commands {
+			flags = append(flags, c.Flags...)
+			collect(c.Subcommands)
+		}
+	}
+	collect(app.Commands)
+	return flags
+}
+
+func contextFlags(ctx *cli.Context) []cli.Flag {
+	var flags []cli.Flag
+	seen := make(map[string]bool)
+	add := func(fs []cli.Flag) {
+		for _, f := range fs {
+			if name := f.Names()[0]; !seen[name] {
+				seen[name] = true
+				flags = append(flags, f)
+			}
+		}
+	}
+	for _, c := range ctx.Lineage() {
+		if c.Command != nil {
+			add(c.Command.Flags)
+		}
+	}
+	if ctx.App != nil {
+		add(ctx.App.Flags)
+	}
+	return flags
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
+)
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "MYST_DISCOVERY_DHT_PORT", EnvVarName("discovery.dht.port"))
+	assert.Equal(t, "MYST_FIREWALL_KILLSWITCH_ALWAYS", EnvVarName("firewall.killSwitch.always"))
+	assert.Equal(t, "MYST_CONFIG_FROM_ENV_ONLY", EnvVarName("config-from-env-only"))
+}
+
+func TestEnvVarNamesAreUnique(t *testing.T) {
+	var flags []cli.Flag
+	require.NoError(t, RegisterFlagsNode(&flags))
+	RegisterFlagsServiceStart(&flags)
+	RegisterFlagsServiceOpenvpn(&flags)
+	RegisterFlagsServiceWireguard(&flags)
+	RegisterFlagsServiceNoop(&flags)
+
+	flagNames := make(map[string]string)
+	for _, f := range flags {
+		name := f.Names()[0]
+		env := EnvVarName(name)
+		if other, ok := flagNames[env]; ok && other != name {
+			t.Errorf("flags %q and %q share environment variable %s", other, name, env)
+		}
+		flagNames[env] = name
+	}
+}
+
+func TestBindEnvVars_ReadsFlagsFromEnvironment(t *testing.T) {
+	t.Setenv("MYST_TEST_PORT", "4050")
+	t.Setenv("MYST_TEST_TIMEOUT", "3m")
+
+	portFlag := cli.IntFlag{Name: "test.port", Value: 1}
+	timeoutFlag := cli.DurationFlag{Name: "test.timeout", Value: time.Second}
+	nameFlag := cli.StringFlag{Name: "test.name", Value: "default"}
+
+	cfg := NewConfig()
+	app := newEnvTestApp(func(ctx *cli.Context) error {
+		cfg.ParseIntFlag(ctx, portFlag)
+		cfg.ParseDurationFlag(ctx, timeoutFlag)
+		cfg.ParseStringFlag(ctx, nameFlag)
+		return nil
+	}, &portFlag, &timeoutFlag, &nameFlag)
+
+	require.NoError(t, app.Run([]string{"app", "--test.timeout=1m"}))
+	assert.Equal(t, []string{"MYST_TEST_PORT"}, portFlag.EnvVars)
+	assert.Equal(t, 4050, cfg.GetInt("test.port"))
+	assert.Equal(t, time.Minute, cfg.GetDuration("test.timeout"))
+	assert.Equal(t, "default", cfg.GetString("test.name"))
+}
+
+func TestCheckEnvOnly(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		environ []string
+		wantErr string
+	}{
+		{
+			name:    "environment only",
+			args:    []string{"app", "--config-from-env-only"},
+			environ: []string{"PATH=/bin", "MYST_TEST_PORT=4050"},
+		},
+		{
+			name:    "unknown variable",
+			args:    []string{"app"},
+			environ: []string{"MYST_TEST_PORT=4050", "MYST_TEST_PROT=4050"},
+			wantErr: "unknown configuration environment variables: MYST_TEST_PROT",
+		},
+		{
+			name:    "flag given on the command line",
+			args:    []string{"app", "--test.port=4050"},
+			wantErr: "flags given on the command line while configuration is taken from the environment only: --test.port",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			portFlag := cli.IntFlag{Name: "test.port"}
+			strictFlag := cli.BoolFlag{Name: FlagConfigFromEnvOnly.Name}
+
+			var err error
+			app := newEnvTestApp(func(ctx *cli.Context) error {
+				err = CheckEnvOnly(ctx, tt.environ)
+				return nil
+			}, &portFlag, &strictFlag)
+
+			require.NoError(t, app.Run(tt.args))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func newEnvTestApp(action cli.ActionFunc, flags ...cli.Flag) *cli.App {
+	app := cli.NewApp()
+	app.Flags = flags
+	app.Action = action
+	BindEnvVars(app.Flags)
+	return app
+}
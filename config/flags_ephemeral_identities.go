@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagEphemeralIdentitiesEnabled connects with a new throwaway identity every time.
+	FlagEphemeralIdentitiesEnabled = cli.BoolFlag{
+		Name:  "consumer.ephemeral-identities.enabled",
+		Usage: "Connect with a new throwaway identity every time, so that the sessions can not be linked together by the consumer identity. Every connection pays the registration fee of its identity",
+		Value: false,
+	}
+	// FlagEphemeralIdentitiesFundAmount amount moved from the parent identity into every ephemeral identity.
+	FlagEphemeralIdentitiesFundAmount = cli.Float64Flag{
+		Name:  "consumer.ephemeral-identities.fund-amount",
+		Usage: "Amount of MYST withdrawn from the earnings of the connecting identity into every ephemeral identity, 0 relies on free registration. The withdrawal links the ephemeral identity to the connecting one on chain",
+		Value: 0,
+	}
+	// FlagEphemeralIdentitiesMinBalance balance at or below which an ephemeral identity is removed.
+	FlagEphemeralIdentitiesMinBalance = cli.Float64Flag{
+		Name:  "consumer.ephemeral-identities.min-balance",
+		Usage: "Balance in MYST at or below which a used ephemeral identity is considered exhausted and removed",
+		Value: 0.01,
+	}
+	// FlagEphemeralIdentitiesCleanupInterval how often exhausted ephemeral identities are removed.
+	FlagEphemeralIdentitiesCleanupInterval = cli.DurationFlag{
+		Name:  "consumer.ephemeral-identities.cleanup-interval",
+		Usage: "How often the ephemeral identities are checked for exhaustion",
+		Value: time.Hour,
+	}
+)
+
+// RegisterFlagsEphemeralIdentities function register ephemeral identities flags to flag list
+func RegisterFlagsEphemeralIdentities(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagEphemeralIdentitiesEnabled,
+		&FlagEphemeralIdentitiesFundAmount,
+		&FlagEphemeralIdentitiesMinBalance,
+		&FlagEphemeralIdentitiesCleanupInterval,
+	)
+}
+
+// ParseFlagsEphemeralIdentities function fills in ephemeral identities options from CLI context
+func ParseFlagsEphemeralIdentities(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagEphemeralIdentitiesEnabled)
+	Current.ParseFloat64Flag(ctx, FlagEphemeralIdentitiesFundAmount)
+	Current.ParseFloat64Flag(ctx, FlagEphemeralIdentitiesMinBalance)
+	Current.ParseDurationFlag(ctx, FlagEphemeralIdentitiesCleanupInterval)
+}
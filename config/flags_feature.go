@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagFeatureEnable experimental features enabled on this node.
+	FlagFeatureEnable = cli.StringSliceFlag{
+		Name:  "feature.enable",
+		Usage: "Experimental features to enable on this node regardless of remote rollout, separated by comma",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagFeatureDisable experimental features disabled on this node.
+	FlagFeatureDisable = cli.StringSliceFlag{
+		Name:  "feature.disable",
+		Usage: "Experimental features to disable on this node regardless of remote rollout, separated by comma",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagFeatureAddress URL for retrieving remote feature rollout rules.
+	FlagFeatureAddress = cli.StringFlag{
+		Name:  "feature.address",
+		Usage: "URL for retrieving feature rollout rules, remote rules are not used if empty",
+		Value: "",
+	}
+	// FlagFeatureFetchInterval feature rollout rules fetch interval.
+	FlagFeatureFetchInterval = cli.DurationFlag{
+		Name:  "feature.fetch",
+		Usage: `Feature rollout rules fetch interval { "30s", "3m", "1h20m30s" }`,
+		Value: 10 * time.Minute,
+	}
+	// FlagFeatureSigner identity signing the feature rollout rules.
+	FlagFeatureSigner = cli.StringFlag{
+		Name:  "feature.signer",
+		Usage: "Identity address signing the feature rollout rules, remote rules are not used if empty",
+		Value: "",
+	}
+)
+
+// RegisterFlagsFeature function registers feature flags to flag list.
+func RegisterFlagsFeature(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagFeatureEnable,
+		&FlagFeatureDisable,
+		&FlagFeatureAddress,
+		&FlagFeatureFetchInterval,
+		&FlagFeatureSigner,
+	)
+}
+
+// ParseFlagsFeature function fills in feature options from CLI context.
+func ParseFlagsFeature(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagFeatureEnable)
+	Current.ParseStringSliceFlag(ctx, FlagFeatureDisable)
+	Current.ParseStringFlag(ctx, FlagFeatureAddress)
+	Current.ParseDurationFlag(ctx, FlagFeatureFetchInterval)
+	Current.ParseStringFlag(ctx, FlagFeatureSigner)
+}
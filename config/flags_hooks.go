@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagHooksWebhookURL URL to which the lifecycle events are posted.
+	FlagHooksWebhookURL = cli.StringFlag{
+		Name:  "hooks.webhook-url",
+		Usage: "URL to which session and settlement events are posted as JSON, disabled if empty. May only be set by the flag or the environment",
+		Value: "",
+	}
+	// FlagHooksScript local script executed on the lifecycle events.
+	FlagHooksScript = cli.StringFlag{
+		Name:  "hooks.script",
+		Usage: "Path of the local script executed on session and settlement events with the JSON event passed to its standard input, disabled if empty. May only be set by the flag or the environment",
+		Value: "",
+	}
+	// FlagHooksSecret secret used to sign the lifecycle events.
+	FlagHooksSecret = cli.StringFlag{
+		Name:  "hooks.secret",
+		Usage: "Secret used to sign the events with HMAC-SHA256, events are not signed if empty. May only be set by the flag or the environment",
+		Value: "",
+	}
+	// FlagHooksTimeout how long a single event delivery may take.
+	FlagHooksTimeout = cli.DurationFlag{
+		Name:  "hooks.timeout",
+		Usage: "How long a single event delivery to the webhook or script may take",
+		Value: 10 * time.Second,
+	}
+	// FlagHooksPaymentURLs URLs to which the payment events are posted.
+	FlagHooksPaymentURLs = cli.StringSliceFlag{
+		Name:  "hooks.payments.urls",
+		Usage: "URLs separated by comma to which payment events are posted as signed JSON, disabled if empty. May only be set by the flag or the environment",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagHooksPaymentEvents selects the forwarded payment events.
+	FlagHooksPaymentEvents = cli.StringSliceFlag{
+		Name:  "hooks.payments.events",
+		Usage: `Payment events separated by comma to post. Options: { "promise_received", "settlement_completed", "payment_stalled" }`,
+		Value: cli.NewStringSlice("promise_received", "settlement_completed", "payment_stalled"),
+	}
+	// FlagHooksPaymentRetries how many times a failed payment event delivery is retried.
+	FlagHooksPaymentRetries = cli.IntFlag{
+		Name:  "hooks.payments.retries",
+		Usage: "How many times a failed payment event delivery is retried",
+		Value: 5,
+	}
+	// FlagHooksPaymentRetryDelay delay before the first retry of a failed payment event delivery.
+	FlagHooksPaymentRetryDelay = cli.DurationFlag{
+		Name:  "hooks.payments.retry-delay",
+		Usage: "Delay before the first retry of a failed payment event delivery, doubled on every next retry",
+		Value: 5 * time.Second,
+	}
+)
+
+// RegisterFlagsHooks function register lifecycle hooks flags to flag list
+func RegisterFlagsHooks(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagHooksWebhookURL,
+		&FlagHooksScript,
+		&FlagHooksSecret,
+		&FlagHooksTimeout,
+		&FlagHooksPaymentURLs,
+		&FlagHooksPaymentEvents,
+		&FlagHooksPaymentRetries,
+		&FlagHooksPaymentRetryDelay,
+	)
+}
+
+// ParseFlagsHooks function fills in lifecycle hooks options from CLI context
+func ParseFlagsHooks(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagHooksWebhookURL)
+	Current.ParseStringFlag(ctx, FlagHooksScript)
+	Current.ParseStringFlag(ctx, FlagHooksSecret)
+	Current.ParseDurationFlag(ctx, FlagHooksTimeout)
+	Current.ParseStringSliceFlag(ctx, FlagHooksPaymentURLs)
+	Current.ParseStringSliceFlag(ctx, FlagHooksPaymentEvents)
+	Current.ParseIntFlag(ctx, FlagHooksPaymentRetries)
+	Current.ParseDurationFlag(ctx, FlagHooksPaymentRetryDelay)
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagLeakTestEnabled enables the leak test after every established connection.
+	FlagLeakTestEnabled = cli.BoolFlag{
+		Name:  "consumer.leak-test.enabled",
+		Usage: "Test for DNS, IPv6 and WebRTC leaks after the connection is established",
+		Value: true,
+	}
+	// FlagLeakTestAutoDisconnect disconnects the connection if the leak test detects a leak.
+	FlagLeakTestAutoDisconnect = cli.BoolFlag{
+		Name:  "consumer.leak-test.auto-disconnect",
+		Usage: "Disconnect if the leak test detects traffic bypassing the tunnel",
+		Value: false,
+	}
+)
+
+// RegisterFlagsLeakTest function register connection leak test flags to flag list
+func RegisterFlagsLeakTest(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagLeakTestEnabled,
+		&FlagLeakTestAutoDisconnect,
+	)
+}
+
+// ParseFlagsLeakTest function fills in connection leak test options from CLI context
+func ParseFlagsLeakTest(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagLeakTestEnabled)
+	Current.ParseBoolFlag(ctx, FlagLeakTestAutoDisconnect)
+}
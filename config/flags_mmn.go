@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/urfave/cli/v2"
 )
@@ -41,6 +43,18 @@ var (
 		Usage: "Token of MMN API",
 		Value: "",
 	}
+	// FlagMMNStatusReportEnabled enables pushing provider status to my.mysterium.network API.
+	FlagMMNStatusReportEnabled = cli.BoolFlag{
+		Name:  "mmn.status-report.enabled",
+		Usage: "Periodically push provider status (services, sessions, earnings, NAT type) to MMN, requires MMN API key",
+		Value: true,
+	}
+	// FlagMMNStatusReportInterval interval of pushing provider status to my.mysterium.network API.
+	FlagMMNStatusReportInterval = cli.DurationFlag{
+		Name:  "mmn.status-report.interval",
+		Usage: "Interval of pushing provider status to MMN",
+		Value: 5 * time.Minute,
+	}
 )
 
 // RegisterFlagsMMN function registers MMN flags to flag list.
@@ -49,6 +63,8 @@ func RegisterFlagsMMN(flags *[]cli.Flag) {
 		&FlagMMNAddress,
 		&FlagMMNAPIAddress,
 		&FlagMMNAPIKey,
+		&FlagMMNStatusReportEnabled,
+		&FlagMMNStatusReportInterval,
 	)
 }
 
@@ -57,4 +73,6 @@ func ParseFlagsMMN(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagMMNAddress)
 	Current.ParseStringFlag(ctx, FlagMMNAPIAddress)
 	Current.ParseStringFlag(ctx, FlagMMNAPIKey)
+	Current.ParseBoolFlag(ctx, FlagMMNStatusReportEnabled)
+	Current.ParseDurationFlag(ctx, FlagMMNStatusReportInterval)
 }
@@ -138,6 +138,12 @@ var (
 		Value:  "echo.mysterium.network:4589",
 		Hidden: true,
 	}
+	// FlagOutboundProxy sets the proxy used for the node's own outbound traffic.
+	FlagOutboundProxy = cli.StringFlag{
+		Name:  "outbound-proxy",
+		Usage: "Proxy for node traffic to discovery, transactor, hermes, blockchain RPC (HTTP only) and quality services, e.g. http://127.0.0.1:3128 or socks5://127.0.0.1:1080. Service traffic is not proxied",
+		Value: "",
+	}
 )
 
 // RegisterFlagsNetwork function register network flags to flag list
@@ -161,6 +167,7 @@ func RegisterFlagsNetwork(flags *[]cli.Flag) {
 		&FlagUDPListenPorts,
 		&FlagTraversal,
 		&FlagPortCheckServers,
+		&FlagOutboundProxy,
 	)
 }
 
@@ -183,6 +190,7 @@ func ParseFlagsNetwork(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagUDPListenPorts)
 	Current.ParseStringFlag(ctx, FlagTraversal)
 	Current.ParseStringFlag(ctx, FlagPortCheckServers)
+	Current.ParseStringFlag(ctx, FlagOutboundProxy)
 }
 
 //BlockchainNetwork defines a blockchain network
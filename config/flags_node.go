@@ -36,7 +36,7 @@ var (
 	// FlagDiscoveryType proposal discovery adapter.
 	FlagDiscoveryType = cli.StringSliceFlag{
 		Name:  "discovery.type",
-		Usage: `Proposal discovery adapter(s) separated by comma. Options: { "api", "broker", "api,broker,dht" }`,
+		Usage: `Proposal discovery adapter(s) separated by comma. Options: { "api", "broker", "lan", "api,broker,dht" }`,
 		Value: cli.NewStringSlice("api"),
 	}
 	// FlagDiscoveryPingInterval proposal ping interval in seconds.
@@ -45,6 +45,12 @@ var (
 		Usage: `Proposal update interval { "30s", "3m", "1h20m30s" }`,
 		Value: 180 * time.Second,
 	}
+	// FlagDiscoveryProposalTTL proposal expiry announced to discovery.
+	FlagDiscoveryProposalTTL = cli.DurationFlag{
+		Name:  "discovery.proposal-ttl",
+		Usage: `Proposal expiry announced to discovery, zero uses twice the ping interval { "6m", "1h" }`,
+		Value: 0,
+	}
 	// FlagDiscoveryFetchInterval proposal fetch interval in seconds.
 	FlagDiscoveryFetchInterval = cli.DurationFlag{
 		Name:  "discovery.fetch",
@@ -75,6 +81,12 @@ var (
 		Usage: `Peer URL(s) for DHT bootstrap (e.g. /ip4/127.0.0.1/tcp/1234/p2p/QmNUZRp1zrk8i8TpfyeDZ9Yg3C4PjZ5o61yao3YhyY1TE8") separated by comma. They will tell us about the other nodes in the network.`,
 		Value: cli.NewStringSlice(),
 	}
+	// FlagLANDiscoveryFree announces proposals to the local network as free of charge.
+	FlagLANDiscoveryFree = cli.BoolFlag{
+		Name:  "discovery.lan.free",
+		Usage: "Announce proposals discovered through the local network (LAN) as free of charge",
+		Value: false,
+	}
 
 	// FlagBindAddress IP address to bind to.
 	FlagBindAddress = cli.StringFlag{
@@ -99,6 +111,18 @@ var (
 		Usage: "List of comma separated (no spaces) subnets to be protected from access via VPN",
 		Value: "10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,127.0.0.0/8",
 	}
+	// FlagFirewallAllowedNetworks exempts parts of the protected networks from the protection.
+	FlagFirewallAllowedNetworks = cli.StringFlag{
+		Name:  "firewall.protected.allow",
+		Usage: "List of comma separated (no spaces) subnets inside the protected networks which every session is still allowed to reach",
+		Value: "",
+	}
+	// FlagFirewallConsumerAllowedNetworks exempts parts of the protected networks from the protection for sessions of the given consumers.
+	FlagFirewallConsumerAllowedNetworks = cli.StringFlag{
+		Name:  "firewall.protected.allow.consumer",
+		Usage: "List of comma separated (no spaces) <consumer identity>=<subnet> pairs inside the protected networks which sessions of the consumer are allowed to reach",
+		Value: "",
+	}
 	// FlagShaperEnabled enables bandwidth limitation.
 	FlagShaperEnabled = cli.BoolFlag{
 		Name:  "shaper.enabled",
@@ -110,12 +134,131 @@ var (
 		Usage: "Set the bandwidth limit in Kbytes",
 		Value: 6250,
 	}
+	// FlagShaperSchedule sets time-of-day bandwidth limits.
+	FlagShaperSchedule = cli.StringFlag{
+		Name:  "shaper.schedule",
+		Usage: "Comma separated time-of-day bandwidth limits in Kbytes, 0 meaning unlimited, e.g. 09:00-18:00=2500,22:00-06:00=0",
+		Value: "",
+	}
+	// FlagShaperFairShare enables dividing the bandwidth limit between sessions.
+	FlagShaperFairShare = cli.BoolFlag{
+		Name:  "shaper.fair-share",
+		Usage: "Divide the bandwidth limit between active sessions proportionally to their shares",
+	}
+	// FlagSessionsMax limits the number of concurrent provider sessions.
+	FlagSessionsMax = cli.IntFlag{
+		Name:  "sessions.max",
+		Usage: "Maximum number of concurrent sessions across all provided services, 0 means unlimited",
+		Value: 0,
+	}
+	// FlagSessionsQueueSize sets how many session requests may wait for a free slot.
+	FlagSessionsQueueSize = cli.IntFlag{
+		Name:  "sessions.queue-size",
+		Usage: "Number of session requests waiting for a free slot once the session limit is reached, 0 rejects them immediately",
+		Value: 0,
+	}
+	// FlagSessionsQueueTimeout sets how long a queued session request waits for a free slot.
+	FlagSessionsQueueTimeout = cli.DurationFlag{
+		Name:  "sessions.queue-timeout",
+		Usage: "How long a queued session request waits for a free slot before being rejected",
+		Value: 10 * time.Second,
+	}
+	// FlagSessionsStaleTimeout sets how long a provider session may stay without data transfer and payments.
+	FlagSessionsStaleTimeout = cli.DurationFlag{
+		Name:  "sessions.stale-timeout",
+		Usage: "Terminate provider sessions which neither transfer data nor are paid for this long, 0 disables the termination",
+		Value: 30 * time.Minute,
+	}
+	// FlagSessionsMaxPause sets how long a consumer may keep a provider session paused.
+	FlagSessionsMaxPause = cli.DurationFlag{
+		Name:  "sessions.max-pause",
+		Usage: "Resume billing of provider sessions paused by consumer for this long, 0 allows pausing until the session ends",
+		Value: 30 * time.Minute,
+	}
+	// FlagSessionsSheddingCPU sets the CPU usage above which new sessions are rejected.
+	FlagSessionsSheddingCPU = cli.Float64Flag{
+		Name:  "sessions.shedding.cpu",
+		Usage: "CPU usage in percent above which new sessions are rejected, 0 disables the monitoring",
+		Value: 0,
+	}
+	// FlagSessionsSheddingMemory sets the memory usage above which new sessions are rejected.
+	FlagSessionsSheddingMemory = cli.Float64Flag{
+		Name:  "sessions.shedding.memory",
+		Usage: "Memory usage in percent above which new sessions are rejected, 0 disables the monitoring",
+		Value: 0,
+	}
+	// FlagSessionsSheddingConntrack sets the connection tracking table usage above which new sessions are rejected.
+	FlagSessionsSheddingConntrack = cli.Float64Flag{
+		Name:  "sessions.shedding.conntrack",
+		Usage: "Connection tracking table usage in percent above which new sessions are rejected, 0 disables the monitoring",
+		Value: 0,
+	}
+	// FlagSessionsSheddingFileDescriptors sets the file descriptor usage above which new sessions are rejected.
+	FlagSessionsSheddingFileDescriptors = cli.Float64Flag{
+		Name:  "sessions.shedding.fds",
+		Usage: "Open file descriptors in percent of the limit above which new sessions are rejected, 0 disables the monitoring",
+		Value: 0,
+	}
+	// FlagSessionsSheddingInterval sets how often the resource usage is checked.
+	FlagSessionsSheddingInterval = cli.DurationFlag{
+		Name:  "sessions.shedding.interval",
+		Usage: "How often the resource usage is checked against the shedding thresholds",
+		Value: 10 * time.Second,
+	}
+	// FlagSessionsSheddingDeprioritizeBandwidth sets the bandwidth left to the lowest-paying sessions while shedding.
+	FlagSessionsSheddingDeprioritizeBandwidth = cli.Uint64Flag{
+		Name:  "sessions.shedding.deprioritize-bandwidth",
+		Usage: "Bandwidth limit in Kbytes of the lowest-paying sessions while resources are exhausted, 0 leaves running sessions intact",
+		Value: 0,
+	}
+	// FlagSessionsSheddingDeprioritizePercent sets the part of the sessions deprioritized while shedding.
+	FlagSessionsSheddingDeprioritizePercent = cli.IntFlag{
+		Name:  "sessions.shedding.deprioritize-percent",
+		Usage: "Percent of the running sessions, the lowest-paying first, limited while resources are exhausted",
+		Value: 25,
+	}
+	// FlagReferralToken sets the referral token attached to registration and first session events.
+	FlagReferralToken = cli.StringFlag{
+		Name:  "referral.token",
+		Usage: "Referral token to attribute identity registration and first session to",
+		Value: "",
+	}
+	// FlagAttestationAddress sets the attestation service consulted before granting sessions.
+	FlagAttestationAddress = cli.StringFlag{
+		Name:  "attestation.address",
+		Usage: "URL of the attestation service verifying consumer attribute tokens, empty disables attestation",
+		Value: "",
+	}
+	// FlagAttestationServices sets service types requiring consumer attestation.
+	FlagAttestationServices = cli.StringFlag{
+		Name:  "attestation.services",
+		Usage: "List of comma separated (no spaces) service types requiring consumer attestation",
+		Value: "",
+	}
+	// FlagAttestationCacheTTL sets how long attestation results are cached.
+	FlagAttestationCacheTTL = cli.DurationFlag{
+		Name:  "attestation.cache-ttl",
+		Usage: "How long attestation results of a consumer token are cached",
+		Value: 10 * time.Minute,
+	}
 	// FlagKeystoreLightweight determines the scrypt memory complexity.
 	FlagKeystoreLightweight = cli.BoolFlag{
 		Name:  "keystore.lightweight",
 		Usage: "Determines the scrypt memory complexity. If set to true, will use 4MB blocks instead of the standard 256MB ones",
 		Value: true,
 	}
+	// FlagKeystoreAutoRepair quarantines unusable keystore files on startup.
+	FlagKeystoreAutoRepair = cli.BoolFlag{
+		Name:  "keystore.auto-repair",
+		Usage: "Move corrupt key files found on startup out of the keystore directory. If disabled, they are only reported. Duplicate key files are always only reported",
+		Value: true,
+	}
+	// FlagStorageSlowOperationThreshold sets the duration after which storage operations are logged as slow.
+	FlagStorageSlowOperationThreshold = cli.DurationFlag{
+		Name:  "storage.slow-operation-threshold",
+		Usage: "Log storage operations taking longer than this along with the bucket and key involved, 0 disables the logging",
+		Value: 500 * time.Millisecond,
+	}
 	// FlagLogHTTP enables HTTP payload logging.
 	FlagLogHTTP = cli.BoolFlag{
 		Name:  "log.http",
@@ -207,6 +350,12 @@ var (
 		Usage: "Enables pprof",
 		Value: false,
 	}
+	// FlagPcapEnable enables packet capture of session tunnels via TequilAPI.
+	FlagPcapEnable = cli.BoolFlag{
+		Name:  "pcap.enable",
+		Usage: "Enables packet capture of session tunnels for debugging, requires tcpdump",
+		Value: false,
+	}
 	// FlagUserMode allows running node under current user without sudo.
 	FlagUserMode = cli.BoolFlag{
 		Name:  "usermode",
@@ -228,6 +377,13 @@ var (
 		Value: false,
 	}
 
+	// FlagWireguardBackend selects WireGuard implementation used for tunnels.
+	FlagWireguardBackend = cli.StringFlag{
+		Name:  "wireguard.backend",
+		Usage: `WireGuard implementation, "auto" prefers kernel module when it is available { "auto", "kernel", "userspace" }`,
+		Value: "auto",
+	}
+
 	// FlagVendorID identifies 3rd party vendor (distributor) of Mysterium node.
 	FlagVendorID = cli.StringFlag{
 		Name: "vendor.id",
@@ -247,6 +403,30 @@ var (
 		Usage: "Deprecated flag, use --udp.ports to set range of listen ports",
 		Value: "0:0",
 	}
+	// FlagKeepAliveMaxConsecutiveLoss sets the number of lost p2p keepalive pings in a row after which the peer is considered dead.
+	FlagKeepAliveMaxConsecutiveLoss = cli.IntFlag{
+		Name:  "p2p.keepalive.max-consecutive-loss",
+		Usage: "Number of lost p2p keepalive pings in a row after which the peer is considered dead, 0 uses the built-in default",
+		Value: 0,
+	}
+	// FlagKeepAliveMaxLossRatio sets the ratio of lost p2p keepalive pings after which the peer is considered dead.
+	FlagKeepAliveMaxLossRatio = cli.Float64Flag{
+		Name:  "p2p.keepalive.max-loss-ratio",
+		Usage: "Ratio (0-1) of lost p2p keepalive pings within the loss window after which the peer is considered dead, 0 disables the check",
+		Value: 0,
+	}
+	// FlagStatsReportInterval sets how often the statistics of an active consumer connection are sampled.
+	FlagStatsReportInterval = cli.DurationFlag{
+		Name:  "consumer.stats-interval",
+		Usage: "How often the statistics of a consumer connection are sampled while traffic flows, idle connections are sampled less often",
+		Value: 10 * time.Second,
+	}
+	// FlagKeepAliveLossWindow sets the number of latest p2p keepalive pings the loss ratio is calculated over.
+	FlagKeepAliveLossWindow = cli.IntFlag{
+		Name:  "p2p.keepalive.loss-window",
+		Usage: "Number of latest p2p keepalive pings the loss ratio is calculated over",
+		Value: 10,
+	}
 
 	// FlagConsumer sets to run as consumer only which allows to skip bootstrap for some of the dependencies.
 	FlagConsumer = cli.BoolFlag{
@@ -298,28 +478,63 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 	RegisterFlagsAffiliator(flags)
 	RegisterFlagsPayments(flags)
 	RegisterFlagsPolicy(flags)
+	RegisterFlagsFeature(flags)
+	RegisterFlagsPreflight(flags)
 	RegisterFlagsMMN(flags)
 	RegisterFlagsPilvytis(flags)
 	RegisterFlagsChains(flags)
 	RegisterFlagsUI(flags)
 	RegisterFlagsBlockchainNetwork(flags)
 	RegisterFlagsSSE(flags)
+	RegisterFlagsBlacklist(flags)
+	RegisterFlagsLeakTest(flags)
+	RegisterFlagsEphemeralIdentities(flags)
+	RegisterFlagsDNS(flags)
+	RegisterFlagsSignAudit(flags)
+	RegisterFlagsAccessLog(flags)
+	RegisterFlagsHooks(flags)
+	RegisterFlagsTequilapiRequestLog(flags)
+	RegisterFlagsEnv(flags)
 
 	*flags = append(*flags,
 		&FlagBindAddress,
 		&FlagDiscoveryType,
 		&FlagDiscoveryPingInterval,
+		&FlagDiscoveryProposalTTL,
 		&FlagDiscoveryFetchInterval,
 		&FlagDHTAddress,
 		&FlagDHTPort,
 		&FlagDHTProtocol,
 		&FlagDHTBootstrapPeers,
+		&FlagLANDiscoveryFree,
 		&FlagFeedbackURL,
 		&FlagFirewallKillSwitch,
 		&FlagFirewallProtectedNetworks,
+		&FlagFirewallAllowedNetworks,
+		&FlagFirewallConsumerAllowedNetworks,
 		&FlagShaperEnabled,
 		&FlagShaperBandwidth,
+		&FlagShaperSchedule,
+		&FlagShaperFairShare,
+		&FlagSessionsMax,
+		&FlagSessionsQueueSize,
+		&FlagSessionsQueueTimeout,
+		&FlagSessionsStaleTimeout,
+		&FlagSessionsMaxPause,
+		&FlagSessionsSheddingCPU,
+		&FlagSessionsSheddingMemory,
+		&FlagSessionsSheddingConntrack,
+		&FlagSessionsSheddingFileDescriptors,
+		&FlagSessionsSheddingInterval,
+		&FlagSessionsSheddingDeprioritizeBandwidth,
+		&FlagSessionsSheddingDeprioritizePercent,
+		&FlagReferralToken,
+		&FlagAttestationAddress,
+		&FlagAttestationServices,
+		&FlagAttestationCacheTTL,
 		&FlagKeystoreLightweight,
+		&FlagKeystoreAutoRepair,
+		&FlagStorageSlowOperationThreshold,
 		&FlagLogHTTP,
 		&FlagLogLevel,
 		&FlagVerbose,
@@ -332,13 +547,19 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagTequilapiUsername,
 		&FlagTequilapiPassword,
 		&FlagPProfEnable,
+		&FlagPcapEnable,
 		&FlagUserMode,
 		&FlagProxyMode,
 		&FlagUserspace,
+		&FlagWireguardBackend,
 		&FlagVendorID,
 		&FlagLauncherVersion,
 		&FlagP2PListenPorts,
+		&FlagKeepAliveMaxConsecutiveLoss,
+		&FlagKeepAliveMaxLossRatio,
+		&FlagKeepAliveLossWindow,
 		&FlagConsumer,
+		&FlagStatsReportInterval,
 		&FlagDefaultCurrency,
 		&FlagDocsURL,
 		&FlagDNSResolutionHeadstart,
@@ -358,28 +579,63 @@ func ParseFlagsNode(ctx *cli.Context) {
 	ParseFlagsAffiliator(ctx)
 	ParseFlagsPayments(ctx)
 	ParseFlagsPolicy(ctx)
+	ParseFlagsFeature(ctx)
+	ParseFlagsPreflight(ctx)
 	ParseFlagsMMN(ctx)
 	ParseFlagPilvytis(ctx)
 	ParseFlagsChains(ctx)
 	ParseFlagsUI(ctx)
 	ParseFlagsSSE(ctx)
+	ParseFlagsBlacklist(ctx)
+	ParseFlagsLeakTest(ctx)
+	ParseFlagsEphemeralIdentities(ctx)
+	ParseFlagsDNS(ctx)
+	ParseFlagsSignAudit(ctx)
+	ParseFlagsAccessLog(ctx)
+	ParseFlagsHooks(ctx)
+	ParseFlagsTequilapiRequestLog(ctx)
+	ParseFlagsEnv(ctx)
 	//it is important to have this one at the end so it overwrites defaults correctly
 	ParseFlagsBlockchainNetwork(ctx)
 
 	Current.ParseStringFlag(ctx, FlagBindAddress)
 	Current.ParseStringSliceFlag(ctx, FlagDiscoveryType)
 	Current.ParseDurationFlag(ctx, FlagDiscoveryPingInterval)
+	Current.ParseDurationFlag(ctx, FlagDiscoveryProposalTTL)
 	Current.ParseDurationFlag(ctx, FlagDiscoveryFetchInterval)
 	Current.ParseStringFlag(ctx, FlagDHTAddress)
 	Current.ParseIntFlag(ctx, FlagDHTPort)
 	Current.ParseStringFlag(ctx, FlagDHTProtocol)
 	Current.ParseStringSliceFlag(ctx, FlagDHTBootstrapPeers)
+	Current.ParseBoolFlag(ctx, FlagLANDiscoveryFree)
 	Current.ParseStringFlag(ctx, FlagFeedbackURL)
 	Current.ParseBoolFlag(ctx, FlagFirewallKillSwitch)
 	Current.ParseStringFlag(ctx, FlagFirewallProtectedNetworks)
+	Current.ParseStringFlag(ctx, FlagFirewallAllowedNetworks)
+	Current.ParseStringFlag(ctx, FlagFirewallConsumerAllowedNetworks)
 	Current.ParseBoolFlag(ctx, FlagShaperEnabled)
 	Current.ParseUInt64Flag(ctx, FlagShaperBandwidth)
+	Current.ParseStringFlag(ctx, FlagShaperSchedule)
+	Current.ParseBoolFlag(ctx, FlagShaperFairShare)
+	Current.ParseIntFlag(ctx, FlagSessionsMax)
+	Current.ParseIntFlag(ctx, FlagSessionsQueueSize)
+	Current.ParseDurationFlag(ctx, FlagSessionsQueueTimeout)
+	Current.ParseDurationFlag(ctx, FlagSessionsStaleTimeout)
+	Current.ParseDurationFlag(ctx, FlagSessionsMaxPause)
+	Current.ParseFloat64Flag(ctx, FlagSessionsSheddingCPU)
+	Current.ParseFloat64Flag(ctx, FlagSessionsSheddingMemory)
+	Current.ParseFloat64Flag(ctx, FlagSessionsSheddingConntrack)
+	Current.ParseFloat64Flag(ctx, FlagSessionsSheddingFileDescriptors)
+	Current.ParseDurationFlag(ctx, FlagSessionsSheddingInterval)
+	Current.ParseUInt64Flag(ctx, FlagSessionsSheddingDeprioritizeBandwidth)
+	Current.ParseIntFlag(ctx, FlagSessionsSheddingDeprioritizePercent)
+	Current.ParseStringFlag(ctx, FlagReferralToken)
+	Current.ParseStringFlag(ctx, FlagAttestationAddress)
+	Current.ParseStringFlag(ctx, FlagAttestationServices)
+	Current.ParseDurationFlag(ctx, FlagAttestationCacheTTL)
 	Current.ParseBoolFlag(ctx, FlagKeystoreLightweight)
+	Current.ParseBoolFlag(ctx, FlagKeystoreAutoRepair)
+	Current.ParseDurationFlag(ctx, FlagStorageSlowOperationThreshold)
 	Current.ParseBoolFlag(ctx, FlagLogHTTP)
 	Current.ParseBoolFlag(ctx, FlagVerbose)
 	Current.ParseStringFlag(ctx, FlagLogLevel)
@@ -392,13 +648,19 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagTequilapiUsername)
 	Current.ParseStringFlag(ctx, FlagTequilapiPassword)
 	Current.ParseBoolFlag(ctx, FlagPProfEnable)
+	Current.ParseBoolFlag(ctx, FlagPcapEnable)
 	Current.ParseBoolFlag(ctx, FlagUserMode)
 	Current.ParseBoolFlag(ctx, FlagProxyMode)
 	Current.ParseBoolFlag(ctx, FlagUserspace)
+	Current.ParseStringFlag(ctx, FlagWireguardBackend)
 	Current.ParseStringFlag(ctx, FlagVendorID)
 	Current.ParseStringFlag(ctx, FlagLauncherVersion)
 	Current.ParseStringFlag(ctx, FlagP2PListenPorts)
+	Current.ParseIntFlag(ctx, FlagKeepAliveMaxConsecutiveLoss)
+	Current.ParseFloat64Flag(ctx, FlagKeepAliveMaxLossRatio)
+	Current.ParseIntFlag(ctx, FlagKeepAliveLossWindow)
 	Current.ParseBoolFlag(ctx, FlagConsumer)
+	Current.ParseDurationFlag(ctx, FlagStatsReportInterval)
 	Current.ParseStringFlag(ctx, FlagDefaultCurrency)
 	Current.ParseStringFlag(ctx, FlagDocsURL)
 	Current.ParseDurationFlag(ctx, FlagDNSResolutionHeadstart)
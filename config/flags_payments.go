@@ -38,6 +38,20 @@ var (
 		Value: time.Second * 30,
 		Usage: "The duration we'll wait before timing out BC calls.",
 	}
+	// FlagPaymentsBCCacheTTL represents how long rarely changing BC lookups are cached.
+	FlagPaymentsBCCacheTTL = cli.DurationFlag{
+		Name:   "payments.bc.cache-ttl",
+		Value:  time.Minute * 10,
+		Usage:  "The duration we'll cache BC lookups such as registration status or hermes fees.",
+		Hidden: true,
+	}
+	// FlagPaymentsBCChannelCacheTTL represents how long channel BC lookups are cached.
+	FlagPaymentsBCChannelCacheTTL = cli.DurationFlag{
+		Name:   "payments.bc.channel-cache-ttl",
+		Value:  time.Second * 15,
+		Usage:  "The duration we'll cache BC channel lookups, including channel balances.",
+		Hidden: true,
+	}
 	// FlagPaymentsHermesPromiseSettleThreshold represents the percentage of balance left when we go for promise settling.
 	FlagPaymentsHermesPromiseSettleThreshold = cli.Float64Flag{
 		Name:  "payments.hermes.promise.threshold",
@@ -111,6 +125,12 @@ var (
 		Usage:  "The duration we'll wait before giving up on transactors registration status",
 		Hidden: true,
 	}
+	// FlagPaymentsRegistryStuckTimeout The duration after which a registration not confirmed by transactor is considered stuck.
+	FlagPaymentsRegistryStuckTimeout = cli.DurationFlag{
+		Name:  "payments.registry-stuck-timeout",
+		Value: time.Minute * 10,
+		Usage: "The duration after which a pending registration is considered stuck and can be re-submitted with a higher fee or cancelled",
+	}
 	// FlagPaymentsConsumerDataLeewayMegabytes sets the data amount the consumer agrees to pay before establishing a session
 	FlagPaymentsConsumerDataLeewayMegabytes = cli.Uint64Flag{
 		Name:  metadata.FlagNames.PaymentsDataLeewayMegabytes,
@@ -124,6 +144,55 @@ var (
 		Usage:  "sets the hermes status recheck interval. Setting this to a lower value will decrease potential loss in case of Hermes getting locked.",
 		Value:  time.Hour * 2,
 	}
+	// FlagPaymentsHermesAvailabilityCheckInterval sets how often the active hermes is checked for availability.
+	FlagPaymentsHermesAvailabilityCheckInterval = cli.DurationFlag{
+		Name:  "payments.hermes-availability-check-interval",
+		Usage: "sets how often the active hermes is health-checked independently of sessions. Set to 0 to disable the checks.",
+		Value: 15 * time.Minute,
+	}
+	// FlagPaymentsClockSkewCheckInterval sets how often the local clock is compared against remote servers.
+	FlagPaymentsClockSkewCheckInterval = cli.DurationFlag{
+		Name:  "payments.clock-skew-check-interval",
+		Usage: "sets how often the local clock is compared against the time of remote servers. Set to 0 to disable the checks.",
+		Value: 10 * time.Minute,
+	}
+	// FlagPaymentsClockSkewThreshold sets the local clock drift which is tolerated in payments until the peer clock is measured.
+	FlagPaymentsClockSkewThreshold = cli.DurationFlag{
+		Name:  "payments.clock-skew-threshold",
+		Usage: "sets the local clock drift after which a warning is raised and payment checks tolerate the drift until the peer clock is measured",
+		Value: 30 * time.Second,
+	}
+	// FlagPaymentsPeerClockSkewTolerance sets how much the time-derived amounts of the peers may differ.
+	FlagPaymentsPeerClockSkewTolerance = cli.DurationFlag{
+		Name:  "payments.peer-clock-skew-tolerance",
+		Usage: "sets the session time worth of payments the invoiced and promised amounts may differ by on top of the measured peer clock skew",
+		Value: 5 * time.Second,
+	}
+	// FlagPaymentsPeerClockSkewMax caps the clock skew which is tolerated in payments.
+	FlagPaymentsPeerClockSkewMax = cli.DurationFlag{
+		Name:  "payments.peer-clock-skew-max",
+		Usage: "sets the maximum clock skew, measured during the invoice exchange or against remote servers, which is tolerated in payments",
+		Value: time.Minute,
+	}
+	// FlagPaymentsInvoiceWatchdogInterval sets how often provider payment goroutines are checked for being stuck.
+	FlagPaymentsInvoiceWatchdogInterval = cli.DurationFlag{
+		Name:  "payments.provider.invoice-watchdog-interval",
+		Usage: "sets how often the invoice trackers of provider sessions are checked for being stuck. Set to 0 to disable the checks.",
+		Value: 15 * time.Second,
+	}
+	// FlagPaymentsProviderShutdownSettleThreshold sets the unsettled amount which is settled when the node shuts down.
+	FlagPaymentsProviderShutdownSettleThreshold = cli.Float64Flag{
+		Name:  "payments.provider.shutdown-settle-threshold",
+		Usage: "settle the earnings of identities which provided services with hermes on node shutdown if they exceed this amount of MYST. Set to 0 to only reveal the pending promise R values.",
+		// Same as the automatic settling threshold, so restarting the node does not pay settlement fees for small amounts.
+		Value: 5,
+	}
+	// FlagPaymentsProviderShutdownSettleTimeout sets how long the node waits for the final settlement on shutdown.
+	FlagPaymentsProviderShutdownSettleTimeout = cli.DurationFlag{
+		Name:  "payments.provider.shutdown-settle-timeout",
+		Usage: "sets how long the node waits for the final settlement on shutdown. Set to 0 to disable the final settlement.",
+		Value: 30 * time.Second,
+	}
 	// FlagOffchainBalanceExpiration sets how often we re-check offchain balance on hermes when balance is depleting
 	FlagOffchainBalanceExpiration = cli.DurationFlag{
 		Hidden: true,
@@ -176,6 +245,133 @@ var (
 		Hidden: true,
 	}
 
+	// FlagPaymentsProviderInitialFreeWindow determines how long after the data plane start the consumer is not charged.
+	FlagPaymentsProviderInitialFreeWindow = cli.DurationFlag{
+		Name:  "payments.provider.initial-free-window",
+		Value: 0,
+		Usage: "Determines how long after the session tunnel starts passing traffic the consumer is not charged for time.",
+	}
+
+	// FlagPaymentsProviderChargePeriods sets the charge periods of service types.
+	FlagPaymentsProviderChargePeriods = cli.StringFlag{
+		Name:  "payments.provider.charge-period",
+		Usage: "Comma separated charge periods of service types, e.g. wireguard=30s,scraping=1m. Sessions of other services are charged as determined by payments.provider.invoice-frequency, unless consumer asks otherwise.",
+		Value: "",
+	}
+
+	// FlagPaymentsProviderChargePeriodMin sets the shortest charge period consumer may negotiate.
+	FlagPaymentsProviderChargePeriodMin = cli.DurationFlag{
+		Name:  "payments.provider.charge-period-min",
+		Usage: "Sets the shortest charge period consumer may negotiate.",
+		Value: 5 * time.Second,
+	}
+
+	// FlagPaymentsProviderChargePeriodMax sets the longest charge period consumer may negotiate.
+	FlagPaymentsProviderChargePeriodMax = cli.DurationFlag{
+		Name:  "payments.provider.charge-period-max",
+		Usage: "Sets the longest charge period consumer may negotiate.",
+		Value: 5 * time.Minute,
+	}
+
+	// FlagPaymentsConsumerChargePeriod sets the charge period consumer asks providers for.
+	FlagPaymentsConsumerChargePeriod = cli.DurationFlag{
+		Name:  "payments.consumer.charge-period",
+		Usage: "Sets the charge period consumer asks providers for, providers decide on their own if not set.",
+		Value: 0,
+	}
+
+	// FlagPaymentsProviderBillingAnomalyTolerance determines how far the billed time may run ahead of the local clock.
+	FlagPaymentsProviderBillingAnomalyTolerance = cli.DurationFlag{
+		Name:  "payments.provider.billing-anomaly-tolerance",
+		Value: time.Minute,
+		Usage: "Determines how far the billed session time may run ahead of the local clock before the session billing is flagged as anomalous. Set to 0 to disable the detection.",
+	}
+
+	// FlagPaymentsProviderBillingAnomalyMaxHourlyRate sets the highest amount a session may be billed per hour.
+	FlagPaymentsProviderBillingAnomalyMaxHourlyRate = cli.StringFlag{
+		Name:  "payments.provider.billing-anomaly-max-hourly-rate",
+		Usage: "Sets the highest amount in wei a session may be billed per hour before its billing is flagged as anomalous. Set to 0 to disable the check.",
+		Value: "0",
+	}
+
+	// FlagPaymentsProviderBillingAnomalyPause determines if billing of anomalous sessions is paused.
+	FlagPaymentsProviderBillingAnomalyPause = cli.BoolFlag{
+		Name:  "payments.provider.billing-anomaly-pause",
+		Usage: "Stops increasing the invoiced amount of a session once its billing is flagged as anomalous.",
+		Value: false,
+	}
+
+	// FlagPaymentsProviderBillingAnomalyPauseDuration determines how long billing of anomalous sessions stays paused.
+	FlagPaymentsProviderBillingAnomalyPauseDuration = cli.DurationFlag{
+		Name:  "payments.provider.billing-anomaly-pause-duration",
+		Usage: "Determines how long billing of an anomalous session stays paused, the amount accrued meanwhile is not invoiced. Set to 0 to keep it paused until the session ends.",
+		Value: 15 * time.Minute,
+	}
+
+	// FlagPaymentsProviderHermesFeePolicy determines what provider does when hermes raises its fee above the limit mid-session.
+	FlagPaymentsProviderHermesFeePolicy = cli.StringFlag{
+		Name:  "payments.provider.hermes-fee-policy",
+		Usage: "Determines what happens to a session once hermes keeps its fee above payments.hermes.max.fee for the grace period: absorb, renegotiate or terminate.",
+		Value: "terminate",
+	}
+
+	// FlagPaymentsProviderHermesFeeGracePeriod determines how long the session continues unchanged after hermes raised its fee above the limit.
+	FlagPaymentsProviderHermesFeeGracePeriod = cli.DurationFlag{
+		Name:  "payments.provider.hermes-fee-grace-period",
+		Usage: "Determines how long the session continues unchanged after hermes raised its fee above the limit.",
+		Value: 5 * time.Minute,
+	}
+
+	// FlagPaymentsProviderHermesFeeCheckInterval determines how often the hermes fee is checked during the session.
+	FlagPaymentsProviderHermesFeeCheckInterval = cli.DurationFlag{
+		Name:   "payments.provider.hermes-fee-check-interval",
+		Usage:  "Determines how often the hermes fee is checked during the session. Set to 0 to check it only when the session starts.",
+		Value:  time.Minute,
+		Hidden: true,
+	}
+
+	// FlagPaymentsProviderAutoPricing makes provider announce its own price following the network average.
+	FlagPaymentsProviderAutoPricing = cli.BoolFlag{
+		Name:  "payments.provider.auto-pricing",
+		Usage: "Periodically adjusts the announced service price to the network average within the bounds of payments.provider.auto-pricing-* options.",
+		Value: false,
+	}
+
+	// FlagPaymentsProviderAutoPricingInterval determines how often the announced price is adjusted.
+	FlagPaymentsProviderAutoPricingInterval = cli.DurationFlag{
+		Name:  "payments.provider.auto-pricing-interval",
+		Usage: "Determines how often the network prices are checked and the announced price is adjusted.",
+		Value: 10 * time.Minute,
+	}
+
+	// FlagPaymentsProviderAutoPricingMinPriceHour sets the lowest price per hour announced by auto pricing.
+	FlagPaymentsProviderAutoPricingMinPriceHour = cli.StringFlag{
+		Name:  "payments.provider.auto-pricing-min-price-hour",
+		Usage: "Sets the lowest price per hour in wei announced by auto pricing.",
+		Value: "0",
+	}
+
+	// FlagPaymentsProviderAutoPricingMaxPriceHour sets the highest price per hour announced by auto pricing.
+	FlagPaymentsProviderAutoPricingMaxPriceHour = cli.StringFlag{
+		Name:  "payments.provider.auto-pricing-max-price-hour",
+		Usage: "Sets the highest price per hour in wei announced by auto pricing. Set to 0 to leave the price unbounded.",
+		Value: "0",
+	}
+
+	// FlagPaymentsProviderAutoPricingMinPriceGiB sets the lowest price per GiB announced by auto pricing.
+	FlagPaymentsProviderAutoPricingMinPriceGiB = cli.StringFlag{
+		Name:  "payments.provider.auto-pricing-min-price-gib",
+		Usage: "Sets the lowest price per GiB in wei announced by auto pricing.",
+		Value: "0",
+	}
+
+	// FlagPaymentsProviderAutoPricingMaxPriceGiB sets the highest price per GiB announced by auto pricing.
+	FlagPaymentsProviderAutoPricingMaxPriceGiB = cli.StringFlag{
+		Name:  "payments.provider.auto-pricing-max-price-gib",
+		Usage: "Sets the highest price per GiB in wei announced by auto pricing. Set to 0 to leave the price unbounded.",
+		Value: "0",
+	}
+
 	// FlagPaymentsLimitProviderInvoiceFrequency determines how often the provider sends invoices.
 	FlagPaymentsLimitProviderInvoiceFrequency = cli.DurationFlag{
 		Name:  "payments.provider.invoice-frequency-limit",
@@ -190,6 +386,8 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 		*flags,
 		&FlagPaymentsMaxHermesFee,
 		&FlagPaymentsBCTimeout,
+		&FlagPaymentsBCCacheTTL,
+		&FlagPaymentsBCChannelCacheTTL,
 		&FlagPaymentsHermesPromiseSettleThreshold,
 		&FlagPaymentsPromiseSettleMaxFeeThreshold,
 		&FlagPaymentsUnsettledMaxAmount,
@@ -200,8 +398,17 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 		&FlagPaymentsFastBalancePollTimeout,
 		&FlagPaymentsRegistryTransactorPollTimeout,
 		&FlagPaymentsRegistryTransactorPollInterval,
+		&FlagPaymentsRegistryStuckTimeout,
 		&FlagPaymentsConsumerDataLeewayMegabytes,
 		&FlagPaymentsHermesStatusRecheckInterval,
+		&FlagPaymentsHermesAvailabilityCheckInterval,
+		&FlagPaymentsClockSkewCheckInterval,
+		&FlagPaymentsClockSkewThreshold,
+		&FlagPaymentsPeerClockSkewTolerance,
+		&FlagPaymentsPeerClockSkewMax,
+		&FlagPaymentsInvoiceWatchdogInterval,
+		&FlagPaymentsProviderShutdownSettleThreshold,
+		&FlagPaymentsProviderShutdownSettleTimeout,
 		&FlagOffchainBalanceExpiration,
 		&FlagPaymentsZeroStakeUnsettledAmount,
 		&FlagPaymentsDuringSessionDebug,
@@ -210,6 +417,24 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 
 		&FlagPaymentsProviderInvoiceFrequency,
 		&FlagPaymentsLimitProviderInvoiceFrequency,
+		&FlagPaymentsProviderInitialFreeWindow,
+		&FlagPaymentsProviderChargePeriods,
+		&FlagPaymentsProviderChargePeriodMin,
+		&FlagPaymentsProviderChargePeriodMax,
+		&FlagPaymentsConsumerChargePeriod,
+		&FlagPaymentsProviderBillingAnomalyTolerance,
+		&FlagPaymentsProviderBillingAnomalyMaxHourlyRate,
+		&FlagPaymentsProviderBillingAnomalyPause,
+		&FlagPaymentsProviderBillingAnomalyPauseDuration,
+		&FlagPaymentsProviderHermesFeePolicy,
+		&FlagPaymentsProviderHermesFeeGracePeriod,
+		&FlagPaymentsProviderHermesFeeCheckInterval,
+		&FlagPaymentsProviderAutoPricing,
+		&FlagPaymentsProviderAutoPricingInterval,
+		&FlagPaymentsProviderAutoPricingMinPriceHour,
+		&FlagPaymentsProviderAutoPricingMaxPriceHour,
+		&FlagPaymentsProviderAutoPricingMinPriceGiB,
+		&FlagPaymentsProviderAutoPricingMaxPriceGiB,
 
 		&FlagPaymentsUnpaidInvoiceValue,
 		&FlagPaymentsLimitUnpaidInvoiceValue,
@@ -220,6 +445,8 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 func ParseFlagsPayments(ctx *cli.Context) {
 	Current.ParseIntFlag(ctx, FlagPaymentsMaxHermesFee)
 	Current.ParseDurationFlag(ctx, FlagPaymentsBCTimeout)
+	Current.ParseDurationFlag(ctx, FlagPaymentsBCCacheTTL)
+	Current.ParseDurationFlag(ctx, FlagPaymentsBCChannelCacheTTL)
 	Current.ParseFloat64Flag(ctx, FlagPaymentsHermesPromiseSettleThreshold)
 	Current.ParseFloat64Flag(ctx, FlagPaymentsPromiseSettleMaxFeeThreshold)
 	Current.ParseFloat64Flag(ctx, FlagPaymentsUnsettledMaxAmount)
@@ -231,8 +458,17 @@ func ParseFlagsPayments(ctx *cli.Context) {
 	Current.ParseDurationFlag(ctx, FlagPaymentsLongBalancePollInterval)
 	Current.ParseDurationFlag(ctx, FlagPaymentsRegistryTransactorPollInterval)
 	Current.ParseDurationFlag(ctx, FlagPaymentsRegistryTransactorPollTimeout)
+	Current.ParseDurationFlag(ctx, FlagPaymentsRegistryStuckTimeout)
 	Current.ParseUInt64Flag(ctx, FlagPaymentsConsumerDataLeewayMegabytes)
 	Current.ParseDurationFlag(ctx, FlagPaymentsHermesStatusRecheckInterval)
+	Current.ParseDurationFlag(ctx, FlagPaymentsHermesAvailabilityCheckInterval)
+	Current.ParseDurationFlag(ctx, FlagPaymentsClockSkewCheckInterval)
+	Current.ParseDurationFlag(ctx, FlagPaymentsClockSkewThreshold)
+	Current.ParseDurationFlag(ctx, FlagPaymentsPeerClockSkewTolerance)
+	Current.ParseDurationFlag(ctx, FlagPaymentsPeerClockSkewMax)
+	Current.ParseDurationFlag(ctx, FlagPaymentsInvoiceWatchdogInterval)
+	Current.ParseFloat64Flag(ctx, FlagPaymentsProviderShutdownSettleThreshold)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderShutdownSettleTimeout)
 	Current.ParseDurationFlag(ctx, FlagOffchainBalanceExpiration)
 	Current.ParseFloat64Flag(ctx, FlagPaymentsZeroStakeUnsettledAmount)
 	Current.ParseBoolFlag(ctx, FlagPaymentsDuringSessionDebug)
@@ -241,6 +477,24 @@ func ParseFlagsPayments(ctx *cli.Context) {
 
 	Current.ParseDurationFlag(ctx, FlagPaymentsProviderInvoiceFrequency)
 	Current.ParseDurationFlag(ctx, FlagPaymentsLimitProviderInvoiceFrequency)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderInitialFreeWindow)
+	Current.ParseStringFlag(ctx, FlagPaymentsProviderChargePeriods)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderChargePeriodMin)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderChargePeriodMax)
+	Current.ParseDurationFlag(ctx, FlagPaymentsConsumerChargePeriod)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderBillingAnomalyTolerance)
+	Current.ParseStringFlag(ctx, FlagPaymentsProviderBillingAnomalyMaxHourlyRate)
+	Current.ParseBoolFlag(ctx, FlagPaymentsProviderBillingAnomalyPause)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderBillingAnomalyPauseDuration)
+	Current.ParseStringFlag(ctx, FlagPaymentsProviderHermesFeePolicy)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderHermesFeeGracePeriod)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderHermesFeeCheckInterval)
+	Current.ParseBoolFlag(ctx, FlagPaymentsProviderAutoPricing)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderAutoPricingInterval)
+	Current.ParseStringFlag(ctx, FlagPaymentsProviderAutoPricingMinPriceHour)
+	Current.ParseStringFlag(ctx, FlagPaymentsProviderAutoPricingMaxPriceHour)
+	Current.ParseStringFlag(ctx, FlagPaymentsProviderAutoPricingMinPriceGiB)
+	Current.ParseStringFlag(ctx, FlagPaymentsProviderAutoPricingMaxPriceGiB)
 
 	Current.ParseStringFlag(ctx, FlagPaymentsLimitUnpaidInvoiceValue)
 	Current.ParseStringFlag(ctx, FlagPaymentsUnpaidInvoiceValue)
@@ -37,6 +37,18 @@ var (
 		Usage: `Proposal fetch interval { "30s", "3m", "1h20m30s" }`,
 		Value: 10 * time.Minute,
 	}
+	// FlagAccessPolicySigner identity of the trust oracle signing the access policies.
+	FlagAccessPolicySigner = cli.StringFlag{
+		Name:  "access-policy.signer",
+		Usage: "Identity address of the trust oracle signing the access policies. If empty, signatures are not verified and any rules served by the policy oracle address are trusted",
+		Value: "",
+	}
+	// FlagAccessPolicyConsumerCountries countries the consumers are allowed to connect from.
+	FlagAccessPolicyConsumerCountries = cli.StringSliceFlag{
+		Name:  "access-policy.consumer-countries",
+		Usage: "Allow sessions only from consumers located in given countries (ISO 3166-1 alpha-2 codes, e.g. DE,NL). Consumers from all countries are allowed if empty",
+		Value: cli.NewStringSlice(),
+	}
 )
 
 // RegisterFlagsPolicy function registers Policy Oracle flags to flag list.
@@ -44,6 +56,8 @@ func RegisterFlagsPolicy(flags *[]cli.Flag) {
 	*flags = append(*flags,
 		&FlagAccessPolicyAddress,
 		&FlagAccessPolicyFetchInterval,
+		&FlagAccessPolicySigner,
+		&FlagAccessPolicyConsumerCountries,
 	)
 }
 
@@ -51,4 +65,6 @@ func RegisterFlagsPolicy(flags *[]cli.Flag) {
 func ParseFlagsPolicy(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagAccessPolicyAddress)
 	Current.ParseDurationFlag(ctx, FlagAccessPolicyFetchInterval)
+	Current.ParseStringFlag(ctx, FlagAccessPolicySigner)
+	Current.ParseStringSliceFlag(ctx, FlagAccessPolicyConsumerCountries)
 }
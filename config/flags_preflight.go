@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import "github.com/urfave/cli/v2"
+
+var (
+	// FlagPreflightStrict refuses to start services if startup checks fail.
+	FlagPreflightStrict = cli.BoolFlag{
+		Name:  "preflight.strict",
+		Usage: "Refuse to start services if provider startup checks fail",
+		Value: false,
+	}
+	// FlagPreflightMinStake minimum provider channel stake required by startup checks.
+	FlagPreflightMinStake = cli.Float64Flag{
+		Name:  "preflight.min-stake",
+		Usage: "Minimum provider channel stake in MYST required by startup checks, stake is not checked if 0",
+		Value: 0,
+	}
+)
+
+// RegisterFlagsPreflight function registers provider startup check flags to flag list.
+func RegisterFlagsPreflight(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagPreflightStrict,
+		&FlagPreflightMinStake,
+	)
+}
+
+// ParseFlagsPreflight function fills in provider startup check options from CLI context.
+func ParseFlagsPreflight(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagPreflightStrict)
+	Current.ParseFloat64Flag(ctx, FlagPreflightMinStake)
+}
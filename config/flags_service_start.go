@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -66,6 +68,13 @@ var (
 		Name:  "active-services",
 		Usage: "Comma separated list of active services.",
 	}
+
+	// FlagServiceDrainTimeout how long a replaced service instance keeps serving its sessions.
+	FlagServiceDrainTimeout = cli.DurationFlag{
+		Name:  "service.drain-timeout",
+		Usage: "Maximum time for the replaced service instance to keep serving active sessions after options change",
+		Value: 30 * time.Minute,
+	}
 )
 
 // RegisterFlagsServiceStart registers CLI flags used to start a service.
@@ -78,6 +87,7 @@ func RegisterFlagsServiceStart(flags *[]cli.Flag) {
 		&FlagPaymentPriceHour,
 		&FlagAccessPolicyList,
 		&FlagActiveServices,
+		&FlagServiceDrainTimeout,
 	)
 }
 
@@ -90,4 +100,5 @@ func ParseFlagsServiceStart(ctx *cli.Context) {
 	Current.ParseFloat64Flag(ctx, FlagPaymentPriceHour)
 	Current.ParseStringFlag(ctx, FlagAccessPolicyList)
 	Current.ParseStringFlag(ctx, FlagActiveServices)
+	Current.ParseDurationFlag(ctx, FlagServiceDrainTimeout)
 }
@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -35,11 +37,47 @@ var (
 		Usage: "Subnet to be used by the wireguard service",
 		Value: "10.182.0.0/16",
 	}
+	// FlagWireguardRoutes subnets advertised to consumers to be routed through the tunnel.
+	FlagWireguardRoutes = cli.StringFlag{
+		Name:  "wireguard.routes",
+		Usage: "Comma separated list of subnets routed through the tunnel for consumers, empty routes all the traffic",
+		Value: "",
+	}
+	// FlagWireguardBandwidthTiers bandwidth tiers offered to consumers at different prices.
+	FlagWireguardBandwidthTiers = cli.StringFlag{
+		Name:  "wireguard.bandwidth-tiers",
+		Usage: "Comma separated list of bandwidth tiers formatted as name:bandwidth_kbytes:price_percent, e.g. basic:1250:50,premium:0:150",
+		Value: "",
+	}
 	// FlagWireguardAccessPolicies a comma-separated list of access policies that determines allowed identities to use the service.
 	FlagWireguardAccessPolicies = cli.StringFlag{
 		Name:  "wireguard.access-policies",
 		Usage: "Comma separated list that determines the access policies of the wireguard service.",
 	}
+	// FlagWireguardTrafficClassification enables counting of the forwarded traffic per protocol and port bucket.
+	FlagWireguardTrafficClassification = cli.BoolFlag{
+		Name:  "wireguard.traffic-classification",
+		Usage: "Count forwarded traffic of every session by protocol and destination port bucket (web, dns, mail, ssh, p2p). Supported by the userspace netstack backend only",
+		Value: false,
+	}
+	// FlagWireguardKeepAlive sets the persistent keepalive interval of consumer WireGuard tunnels.
+	FlagWireguardKeepAlive = cli.DurationFlag{
+		Name:  "wireguard.keepalive",
+		Usage: `Interval of keepalive packets sent by consumer keeping NAT mappings open, zero disables them { "18s", "25s" }`,
+		Value: 18 * time.Second,
+	}
+	// FlagWireguardProviderKeepAlive sets the persistent keepalive interval of provider WireGuard tunnels.
+	FlagWireguardProviderKeepAlive = cli.DurationFlag{
+		Name:  "wireguard.provider.keepalive",
+		Usage: `Interval of keepalive packets sent by provider to consumers, zero disables them { "0s", "25s" }`,
+		Value: 0,
+	}
+	// FlagWireguardRekeyTimeout sets the age of the last handshake after which the WireGuard tunnel is considered stalled.
+	FlagWireguardRekeyTimeout = cli.DurationFlag{
+		Name:  "wireguard.rekey-timeout",
+		Usage: `Age of the last WireGuard handshake after which the tunnel is considered stalled and re-keyed, zero disables the check { "3m", "5m" }`,
+		Value: 3 * time.Minute,
+	}
 )
 
 // RegisterFlagsServiceWireguard function register Wireguard flags to flag list
@@ -48,6 +86,12 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 		&FlagWireguardListenPorts,
 		&FlagWireguardListenSubnet,
 		&FlagWireguardAccessPolicies,
+		&FlagWireguardRoutes,
+		&FlagWireguardBandwidthTiers,
+		&FlagWireguardTrafficClassification,
+		&FlagWireguardKeepAlive,
+		&FlagWireguardProviderKeepAlive,
+		&FlagWireguardRekeyTimeout,
 	)
 }
 
@@ -56,4 +100,10 @@ func ParseFlagsServiceWireguard(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagWireguardListenPorts)
 	Current.ParseStringFlag(ctx, FlagWireguardListenSubnet)
 	Current.ParseStringFlag(ctx, FlagWireguardAccessPolicies)
+	Current.ParseStringFlag(ctx, FlagWireguardRoutes)
+	Current.ParseStringFlag(ctx, FlagWireguardBandwidthTiers)
+	Current.ParseBoolFlag(ctx, FlagWireguardTrafficClassification)
+	Current.ParseDurationFlag(ctx, FlagWireguardKeepAlive)
+	Current.ParseDurationFlag(ctx, FlagWireguardProviderKeepAlive)
+	Current.ParseDurationFlag(ctx, FlagWireguardRekeyTimeout)
 }
@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagSignAuditEnabled enables recording of identity signing operations.
+	FlagSignAuditEnabled = cli.BoolFlag{
+		Name:  "identity.sign-audit.enabled",
+		Usage: "Record every signing operation performed by the identities into a local audit log",
+		Value: false,
+	}
+	// FlagSignAuditRetention how long the signing operations are kept in the audit log.
+	FlagSignAuditRetention = cli.DurationFlag{
+		Name:  "identity.sign-audit.retention",
+		Usage: "How long the signing operations are kept in the audit log, 0 keeps them forever",
+		Value: 30 * 24 * time.Hour,
+	}
+)
+
+// RegisterFlagsSignAudit function register identity signing audit flags to flag list
+func RegisterFlagsSignAudit(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagSignAuditEnabled,
+		&FlagSignAuditRetention,
+	)
+}
+
+// ParseFlagsSignAudit function fills in identity signing audit options from CLI context
+func ParseFlagsSignAudit(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagSignAuditEnabled)
+	Current.ParseDurationFlag(ctx, FlagSignAuditRetention)
+}
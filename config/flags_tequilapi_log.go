@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagTequilapiRequestLog enables logging of tequilapi requests.
+	FlagTequilapiRequestLog = cli.BoolFlag{
+		Name:  "tequilapi.request-log.enabled",
+		Usage: "Log tequilapi requests and responses with credentials and passphrases redacted",
+		Value: false,
+	}
+	// FlagTequilapiRequestLogSampleRate sets the fraction of tequilapi requests which are logged.
+	FlagTequilapiRequestLogSampleRate = cli.Float64Flag{
+		Name:  "tequilapi.request-log.sample-rate",
+		Usage: "Fraction of tequilapi requests to log, from 0 to 1",
+		Value: 1,
+	}
+	// FlagTequilapiRequestLogBodies enables logging of tequilapi request and response bodies.
+	FlagTequilapiRequestLogBodies = cli.BoolFlag{
+		Name:  "tequilapi.request-log.bodies",
+		Usage: "Include redacted request and response bodies in the tequilapi request log",
+		Value: false,
+	}
+	// FlagTequilapiRequestLogInclude limits the tequilapi request log to the given endpoints.
+	FlagTequilapiRequestLogInclude = cli.StringSliceFlag{
+		Name:  "tequilapi.request-log.include",
+		Usage: "Log only the tequilapi endpoints with given path prefixes, e.g. /identities. All endpoints are logged if empty",
+		Value: cli.NewStringSlice(),
+	}
+	// FlagTequilapiRequestLogExclude excludes the given endpoints from the tequilapi request log.
+	FlagTequilapiRequestLogExclude = cli.StringSliceFlag{
+		Name:  "tequilapi.request-log.exclude",
+		Usage: "Do not log the tequilapi endpoints with given path prefixes",
+		Value: cli.NewStringSlice("/healthcheck", "/events/state"),
+	}
+)
+
+// RegisterFlagsTequilapiRequestLog function register tequilapi request log flags to flag list
+func RegisterFlagsTequilapiRequestLog(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagTequilapiRequestLog,
+		&FlagTequilapiRequestLogSampleRate,
+		&FlagTequilapiRequestLogBodies,
+		&FlagTequilapiRequestLogInclude,
+		&FlagTequilapiRequestLogExclude,
+	)
+}
+
+// ParseFlagsTequilapiRequestLog function fills in tequilapi request log options from CLI context
+func ParseFlagsTequilapiRequestLog(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagTequilapiRequestLog)
+	Current.ParseFloat64Flag(ctx, FlagTequilapiRequestLogSampleRate)
+	Current.ParseBoolFlag(ctx, FlagTequilapiRequestLogBodies)
+	Current.ParseStringSliceFlag(ctx, FlagTequilapiRequestLogInclude)
+	Current.ParseStringSliceFlag(ctx, FlagTequilapiRequestLogExclude)
+}
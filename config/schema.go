@@ -0,0 +1,267 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cast"
+)
+
+// OptionType describes the type of configuration option value.
+type OptionType string
+
+const (
+	// OptionTypeBool is a boolean option.
+	OptionTypeBool OptionType = "bool"
+	// OptionTypeInt is an integer option.
+	OptionTypeInt OptionType = "int"
+	// OptionTypeInt64 is a 64-bit integer option.
+	OptionTypeInt64 OptionType = "int64"
+	// OptionTypeUInt64 is an unsigned 64-bit integer option.
+	OptionTypeUInt64 OptionType = "uint64"
+	// OptionTypeFloat64 is a floating point option.
+	OptionTypeFloat64 OptionType = "float64"
+	// OptionTypeDuration is a duration option, e.g. "1h20m30s".
+	OptionTypeDuration OptionType = "duration"
+	// OptionTypeString is a string option.
+	OptionTypeString OptionType = "string"
+	// OptionTypeStringSlice is a list of strings option.
+	OptionTypeStringSlice OptionType = "string_slice"
+)
+
+// OptionConstraints describes the values accepted by a configuration option.
+type OptionConstraints struct {
+	Min  *float64
+	Max  *float64
+	Enum []string
+}
+
+// Option describes a single configuration option.
+type Option struct {
+	Key             string
+	Type            OptionType
+	Description     string
+	Default         interface{}
+	Constraints     OptionConstraints
+	RestartRequired bool
+}
+
+func limit(v float64) *float64 {
+	return &v
+}
+
+var (
+	portRange    = OptionConstraints{Min: limit(0), Max: limit(65535)}
+	percentRange = OptionConstraints{Min: limit(0), Max: limit(100)}
+
+	// optionConstraints narrows down the values of options beyond their type.
+	optionConstraints = map[string]OptionConstraints{
+		FlagLogLevel.Name: {Enum: []string{
+			zerolog.TraceLevel.String(),
+			zerolog.DebugLevel.String(),
+			zerolog.InfoLevel.String(),
+			zerolog.WarnLevel.String(),
+			zerolog.ErrorLevel.String(),
+			zerolog.FatalLevel.String(),
+			zerolog.PanicLevel.String(),
+			zerolog.Disabled.String(),
+		}},
+		FlagBlockchainNetwork.Name:                   {Enum: []string{"mainnet", "testnet", "localnet"}},
+		FlagDHTPort.Name:                             portRange,
+		FlagDHTProtocol.Name:                         {Enum: []string{"udp", "tcp"}},
+		FlagDiscoveryType.Name:                       {Enum: []string{"api", "broker", "lan", "dht"}},
+		FlagOpenvpnPort.Name:                         portRange,
+		FlagOpenvpnProtocol.Name:                     {Enum: []string{"udp", "tcp"}},
+		FlagPaymentsProviderHermesFeePolicy.Name:     {Enum: []string{"absorb", "renegotiate", "terminate"}},
+		FlagSessionsMax.Name:                         {Min: limit(0)},
+		FlagSessionsSheddingCPU.Name:                 percentRange,
+		FlagSessionsSheddingMemory.Name:              percentRange,
+		FlagSessionsSheddingConntrack.Name:           percentRange,
+		FlagSessionsSheddingFileDescriptors.Name:     percentRange,
+		FlagSessionsSheddingDeprioritizePercent.Name: percentRange,
+		FlagTequilapiPort.Name:                       portRange,
+		FlagUIPort.Name:                              portRange,
+	}
+
+	// liveOptions are applied by the running node as soon as they change.
+	liveOptions = map[string]bool{
+		FlagShaperEnabled.Name: true,
+	}
+)
+
+// describe records the option parsed from a command line flag in the configuration schema.
+func (cfg *Config) describe(key string, optionType OptionType, description string, defaultValue interface{}) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+
+	cfg.schema[key] = Option{
+		Key:             key,
+		Type:            optionType,
+		Description:     description,
+		Default:         defaultValue,
+		Constraints:     optionConstraints[key],
+		RestartRequired: !liveOptions[key],
+	}
+}
+
+// Schema returns descriptions of all configuration options known to the node, sorted by key.
+func (cfg *Config) Schema() []Option {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	options := make([]Option, 0, len(cfg.schema))
+	for _, option := range cfg.schema {
+		options = append(options, option)
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].Key < options[j].Key
+	})
+	return options
+}
+
+// Validate checks the given configuration values against the schema without applying them.
+// Values may be given by dotted keys or as nested maps, nil values stand for removal and are always valid.
+// Returns a reason for every rejected key, empty if all the values are acceptable.
+func (cfg *Config) Validate(values map[string]interface{}) map[string]string {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+
+	problems := make(map[string]string)
+	cfg.validate("", values, problems)
+	return problems
+}
+
+func (cfg *Config) validate(prefix string, values map[string]interface{}, problems map[string]string) {
+	for k, v := range values {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		option, ok := cfg.schema[key]
+		if !ok {
+			if nested, ok := v.(map[string]interface{}); ok {
+				cfg.validate(key, nested, problems)
+				continue
+			}
+			problems[key] = "unknown option"
+			continue
+		}
+		if v == nil {
+			continue
+		}
+		if err := option.validate(v); err != nil {
+			problems[key] = err.Error()
+		}
+	}
+}
+
+func (o Option) validate(value interface{}) error {
+	switch o.Type {
+	case OptionTypeBool:
+		if _, err := cast.ToBoolE(value); err != nil {
+			return fmt.Errorf("expected boolean value")
+		}
+		return nil
+	case OptionTypeInt, OptionTypeInt64, OptionTypeUInt64:
+		number, err := cast.ToFloat64E(value)
+		if err != nil || number != math.Trunc(number) {
+			return fmt.Errorf("expected integer value")
+		}
+		if o.Type == OptionTypeUInt64 && number < 0 {
+			return fmt.Errorf("expected non-negative value")
+		}
+		return o.Constraints.validateNumber(number)
+	case OptionTypeFloat64:
+		number, err := cast.ToFloat64E(value)
+		if err != nil {
+			return fmt.Errorf("expected number value")
+		}
+		return o.Constraints.validateNumber(number)
+	case OptionTypeDuration:
+		d, err := cast.ToDurationE(value)
+		if err != nil {
+			return fmt.Errorf("expected duration value, e.g. \"1h20m30s\"")
+		}
+		return o.Constraints.validateNumber(d.Seconds())
+	case OptionTypeString:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected string value")
+		}
+		return o.Constraints.validateEnum(s)
+	case OptionTypeStringSlice:
+		var items []string
+		switch v := value.(type) {
+		case string:
+			items = strings.Split(v, ",")
+		case []string:
+			items = v
+		case []interface{}:
+			for _, item := range v {
+				s, ok := item.(string)
+				if !ok {
+					return fmt.Errorf("expected list of strings")
+				}
+				items = append(items, s)
+			}
+		default:
+			return fmt.Errorf("expected list of strings")
+		}
+		for _, item := range items {
+			if err := o.Constraints.validateEnum(strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+func (c OptionConstraints) validateNumber(number float64) error {
+	if c.Min != nil && number < *c.Min {
+		return fmt.Errorf("must be at least %v", *c.Min)
+	}
+	if c.Max != nil && number > *c.Max {
+		return fmt.Errorf("must be at most %v", *c.Max)
+	}
+	return nil
+}
+
+func (c OptionConstraints) validateEnum(value string) error {
+	if len(c.Enum) == 0 {
+		return nil
+	}
+	for _, allowed := range c.Enum {
+		if strings.EqualFold(value, allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of: %s", strings.Join(c.Enum, ", "))
+}
+
+// durationDefault keeps duration defaults readable in the schema.
+func durationDefault(d time.Duration) string {
+	return d.String()
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v2"
+)
+
+func parsedSchemaConfig(t *testing.T) *Config {
+	cfg := NewConfig()
+	flagSet := flag.NewFlagSet("", flag.ContinueOnError)
+	must(t, FlagOpenvpnPort.Apply(flagSet))
+	must(t, FlagShaperEnabled.Apply(flagSet))
+	must(t, FlagDiscoveryPingInterval.Apply(flagSet))
+	must(t, FlagDiscoveryType.Apply(flagSet))
+	must(t, FlagLogLevel.Apply(flagSet))
+	ctx := cli.NewContext(nil, flagSet, nil)
+	must(t, flagSet.Parse(nil))
+
+	cfg.ParseIntFlag(ctx, FlagOpenvpnPort)
+	cfg.ParseBoolFlag(ctx, FlagShaperEnabled)
+	cfg.ParseDurationFlag(ctx, FlagDiscoveryPingInterval)
+	cfg.ParseStringSliceFlag(ctx, FlagDiscoveryType)
+	cfg.ParseStringFlag(ctx, FlagLogLevel)
+	return cfg
+}
+
+func TestConfig_Schema(t *testing.T) {
+	// given
+	cfg := parsedSchemaConfig(t)
+
+	// when
+	schema := cfg.Schema()
+
+	// then
+	assert.Len(t, schema, 5)
+	assert.Equal(t, "discovery.ping", schema[0].Key)
+	assert.Equal(t, OptionTypeDuration, schema[0].Type)
+	assert.Equal(t, (180 * time.Second).String(), schema[0].Default)
+	assert.Equal(t, "discovery.type", schema[1].Key)
+	assert.Equal(t, []string{"api"}, schema[1].Default)
+
+	assert.Equal(t, Option{
+		Key:             FlagOpenvpnPort.Name,
+		Type:            OptionTypeInt,
+		Description:     FlagOpenvpnPort.Usage,
+		Default:         0,
+		Constraints:     portRange,
+		RestartRequired: true,
+	}, schema[3])
+	assert.Equal(t, "shaper.enabled", schema[4].Key)
+	assert.False(t, schema[4].RestartRequired)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := parsedSchemaConfig(t)
+
+	var tests = []struct {
+		name     string
+		values   map[string]interface{}
+		problems map[string]string
+	}{
+		{
+			name: "valid values",
+			values: map[string]interface{}{
+				"openvpn.port":   float64(1194),
+				"shaper.enabled": true,
+				"discovery":      map[string]interface{}{"ping": "5m", "type": []interface{}{"api", "broker"}},
+				"log-level":      nil,
+			},
+			problems: map[string]string{},
+		},
+		{
+			name: "invalid values",
+			values: map[string]interface{}{
+				"openvpn":        map[string]interface{}{"port": float64(70000)},
+				"shaper.enabled": "maybe",
+				"discovery.ping": "soon",
+				"discovery.type": "api,carrier-pigeon",
+				"log-level":      float64(1),
+				"unknown.option": 1,
+			},
+			problems: map[string]string{
+				"openvpn.port":   "must be at most 65535",
+				"shaper.enabled": "expected boolean value",
+				"discovery.ping": `expected duration value, e.g. "1h20m30s"`,
+				"discovery.type": "must be one of: api, broker, lan, dht",
+				"log-level":      "expected string value",
+				"unknown.option": "unknown option",
+			},
+		},
+		{
+			name:     "fractional integer",
+			values:   map[string]interface{}{"openvpn.port": 1194.5},
+			problems: map[string]string{"openvpn.port": "expected integer value"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.problems, cfg.Validate(tc.values))
+		})
+	}
+}
@@ -48,7 +48,17 @@ func LoadUserConfig(ctx *cli.Context) error {
 // LoadUserConfigQuietly like LoadUserConfig, but instead of returning an error,
 // it logs it on a `warn` level.
 // `error` is specified as a return to adhere to `cli.BeforeFunc` for convenience.
+// When configuration is taken from the environment only, the user config is not loaded
+// and the configuration sources are checked instead, failing the command on violations.
 func LoadUserConfigQuietly(ctx *cli.Context) error {
+	if ctx.Bool(config.FlagConfigFromEnvOnly.Name) {
+		if err := config.CheckEnvOnly(ctx, os.Environ()); err != nil {
+			return err
+		}
+		log.Info().Msg("Configuration is taken from the environment only, user config is not loaded")
+		return nil
+	}
+
 	err := LoadUserConfig(ctx)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to load user config")
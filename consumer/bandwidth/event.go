@@ -20,6 +20,7 @@ package bandwidth
 import (
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/eventbus"
 )
 
 // AppTopicConnectionThroughput represents the session throughput topic.
@@ -35,3 +36,7 @@ type AppEventConnectionThroughput struct {
 type Throughput struct {
 	Up, Down datasize.BitSpeed
 }
+
+func init() {
+	eventbus.RegisterSchema(AppTopicConnectionThroughput, 1, AppEventConnectionThroughput{})
+}
@@ -0,0 +1,234 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blacklist
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// Reason describes why a provider was penalized.
+type Reason string
+
+const (
+	// ReasonConnectionFailed means that the connection to the provider could not be established.
+	ReasonConnectionFailed = Reason("connection_failed")
+	// ReasonNoTraffic means that the traffic did not flow through the tunnel.
+	ReasonNoTraffic = Reason("no_traffic")
+	// ReasonPaymentFailed means that session payments with the provider failed.
+	ReasonPaymentFailed = Reason("payment_failed")
+	// ReasonDisconnected means that the provider stopped responding during the session.
+	ReasonDisconnected = Reason("disconnected")
+)
+
+// minScore is the score below which the entry is considered fully decayed and is dropped.
+const minScore = 0.01
+
+// Config represents the blacklist configuration.
+type Config struct {
+	// Threshold is the failure score starting from which the provider is excluded from auto-selection.
+	// Zero disables the exclusion, failures are still tracked.
+	Threshold float64
+	// HalfLife is the period in which the failure score decays by half.
+	HalfLife time.Duration
+}
+
+// Entry represents the failure record of a single provider.
+type Entry struct {
+	ProviderID  string
+	Score       float64
+	Failures    int
+	LastFailure time.Time
+	LastReason  Reason
+	Blacklisted bool
+}
+
+type entry struct {
+	score       float64
+	updatedAt   time.Time
+	failures    int
+	lastFailure time.Time
+	lastReason  Reason
+
+	blacklistedUntil time.Time
+}
+
+// Blacklist keeps track of failing providers and temporarily excludes them from auto-selection.
+// Every failure increases the provider's score, which decays exponentially over time.
+// Once the score reaches the threshold, the provider stays blacklisted until the score decays to half of it.
+type Blacklist struct {
+	cfg Config
+	now func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]*entry
+}
+
+// New returns a new instance of the provider blacklist.
+func New(cfg Config) *Blacklist {
+	return &Blacklist{
+		cfg:     cfg,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+}
+
+// Subscribe subscribes the blacklist to the connection events.
+func (b *Blacklist) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionState, b.handleStateEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(connectionstate.AppTopicConnectionIssue, b.handleIssueEvent)
+}
+
+// ReportFailure penalizes the given provider.
+func (b *Blacklist) ReportFailure(providerID string, reason Reason) {
+	if providerID == "" {
+		return
+	}
+	providerID = strings.ToLower(providerID)
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	e, ok := b.entries[providerID]
+	if !ok {
+		e = &entry{updatedAt: now}
+		b.entries[providerID] = e
+	}
+	e.score = b.decayedScore(e, now) + 1
+	e.updatedAt = now
+	e.failures++
+	e.lastFailure = now
+	e.lastReason = reason
+
+	if b.enabled() && e.score >= b.cfg.Threshold {
+		e.blacklistedUntil = b.decayedAt(now, e.score, b.cfg.Threshold/2)
+		log.Info().Msgf("Provider %s is blacklisted for auto-selection after failure: %s", providerID, reason)
+	}
+}
+
+// IsBlacklisted checks whether the given provider is currently excluded from auto-selection.
+func (b *Blacklist) IsBlacklisted(providerID string) bool {
+	if !b.enabled() {
+		return false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	e, ok := b.entries[strings.ToLower(providerID)]
+	if !ok {
+		return false
+	}
+	return b.now().Before(e.blacklistedUntil)
+}
+
+// List returns the providers which have recent failures, including the ones not yet blacklisted.
+func (b *Blacklist) List() []Entry {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	result := make([]Entry, 0, len(b.entries))
+	for providerID, e := range b.entries {
+		score := b.decayedScore(e, now)
+		if score < minScore {
+			delete(b.entries, providerID)
+			continue
+		}
+		result = append(result, Entry{
+			ProviderID:  providerID,
+			Score:       score,
+			Failures:    e.failures,
+			LastFailure: e.lastFailure,
+			LastReason:  e.lastReason,
+			Blacklisted: b.enabled() && now.Before(e.blacklistedUntil),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result
+}
+
+// Remove forgets the failures of the given provider.
+func (b *Blacklist) Remove(providerID string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	providerID = strings.ToLower(providerID)
+	_, ok := b.entries[providerID]
+	delete(b.entries, providerID)
+	return ok
+}
+
+// Clear forgets the failures of all the providers.
+func (b *Blacklist) Clear() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.entries = make(map[string]*entry)
+}
+
+func (b *Blacklist) enabled() bool {
+	return b.cfg.Threshold > 0
+}
+
+// decayedAt returns the moment the score decays to the given target.
+func (b *Blacklist) decayedAt(now time.Time, score, target float64) time.Time {
+	if b.cfg.HalfLife <= 0 {
+		return now.AddDate(100, 0, 0)
+	}
+	return now.Add(time.Duration(float64(b.cfg.HalfLife) * math.Log2(score/target)))
+}
+
+func (b *Blacklist) decayedScore(e *entry, now time.Time) float64 {
+	if b.cfg.HalfLife <= 0 {
+		return e.score
+	}
+	elapsed := now.Sub(e.updatedAt)
+	return e.score * math.Pow(0.5, float64(elapsed)/float64(b.cfg.HalfLife))
+}
+
+func (b *Blacklist) handleStateEvent(ev connectionstate.AppEventConnectionState) {
+	switch ev.State {
+	case connectionstate.StateConnectionFailed:
+		b.ReportFailure(ev.SessionInfo.Proposal.ProviderID, ReasonConnectionFailed)
+	case connectionstate.StateIPNotChanged:
+		b.ReportFailure(ev.SessionInfo.Proposal.ProviderID, ReasonNoTraffic)
+	}
+}
+
+func (b *Blacklist) handleIssueEvent(ev connectionstate.AppEventConnectionIssue) {
+	switch ev.Issue {
+	case connectionstate.IssuePaymentFailed:
+		b.ReportFailure(ev.SessionInfo.Proposal.ProviderID, ReasonPaymentFailed)
+	case connectionstate.IssueKeepAliveFailed:
+		b.ReportFailure(ev.SessionInfo.Proposal.ProviderID, ReasonDisconnected)
+	}
+}
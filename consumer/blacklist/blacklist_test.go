@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blacklist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type mockClock struct {
+	now time.Time
+}
+
+func (c *mockClock) Now() time.Time {
+	return c.now
+}
+
+func (c *mockClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestBlacklist(cfg Config) (*Blacklist, *mockClock) {
+	clock := &mockClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := New(cfg)
+	b.now = clock.Now
+	return b, clock
+}
+
+func TestBlacklist_BlacklistsAfterThreshold(t *testing.T) {
+	b, _ := newTestBlacklist(Config{Threshold: 2, HalfLife: time.Hour})
+
+	b.ReportFailure("0x1", ReasonConnectionFailed)
+	assert.False(t, b.IsBlacklisted("0x1"))
+
+	b.ReportFailure("0x1", ReasonNoTraffic)
+	assert.True(t, b.IsBlacklisted("0x1"))
+	assert.False(t, b.IsBlacklisted("0x2"))
+}
+
+func TestBlacklist_ScoreDecays(t *testing.T) {
+	b, clock := newTestBlacklist(Config{Threshold: 2, HalfLife: time.Hour})
+
+	b.ReportFailure("0x1", ReasonConnectionFailed)
+	b.ReportFailure("0x1", ReasonConnectionFailed)
+	assert.True(t, b.IsBlacklisted("0x1"))
+
+	clock.Advance(30 * time.Minute)
+	assert.True(t, b.IsBlacklisted("0x1"))
+
+	clock.Advance(30 * time.Minute)
+	assert.False(t, b.IsBlacklisted("0x1"))
+
+	entries := b.List()
+	assert.Len(t, entries, 1)
+	assert.InDelta(t, 1.0, entries[0].Score, 0.0001)
+	assert.Equal(t, 2, entries[0].Failures)
+	assert.False(t, entries[0].Blacklisted)
+
+	b.ReportFailure("0x1", ReasonPaymentFailed)
+	assert.True(t, b.IsBlacklisted("0x1"))
+
+	clock.Advance(24 * time.Hour)
+	assert.Empty(t, b.List())
+}
+
+func TestBlacklist_ZeroThresholdDisablesExclusion(t *testing.T) {
+	b, _ := newTestBlacklist(Config{Threshold: 0, HalfLife: time.Hour})
+
+	b.ReportFailure("0x1", ReasonConnectionFailed)
+
+	assert.False(t, b.IsBlacklisted("0x1"))
+	assert.Len(t, b.List(), 1)
+}
+
+func TestBlacklist_RemoveAndClear(t *testing.T) {
+	b, _ := newTestBlacklist(Config{Threshold: 1, HalfLife: time.Hour})
+
+	b.ReportFailure("0xAB", ReasonConnectionFailed)
+	b.ReportFailure("0x2", ReasonConnectionFailed)
+	assert.True(t, b.IsBlacklisted("0xab"))
+
+	assert.True(t, b.Remove("0xab"))
+	assert.False(t, b.Remove("0xab"))
+	assert.False(t, b.IsBlacklisted("0xab"))
+
+	b.Clear()
+	assert.Empty(t, b.List())
+}
+
+func TestBlacklist_ConsumesConnectionEvents(t *testing.T) {
+	b, _ := newTestBlacklist(Config{Threshold: 1, HalfLife: time.Hour})
+	info := connectionstate.Status{Proposal: proposal.PricedServiceProposal{ServiceProposal: market.ServiceProposal{ProviderID: "0x1"}}}
+
+	b.handleStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Connected, SessionInfo: info})
+	assert.Empty(t, b.List())
+
+	b.handleStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.StateConnectionFailed, SessionInfo: info})
+	b.handleIssueEvent(connectionstate.AppEventConnectionIssue{Issue: connectionstate.IssueKeepAliveFailed, SessionInfo: info})
+
+	entries := b.List()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 2, entries[0].Failures)
+	assert.Equal(t, ReasonDisconnected, entries[0].LastReason)
+	assert.True(t, entries[0].Blacklisted)
+}
@@ -0,0 +1,542 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package ephemeral creates throwaway consumer identities, one per connection, so that
+// the sessions of a single user can not be linked together by their consumer identity.
+//
+// Every ephemeral identity is registered on its own, so every connection pays the registration fee.
+// Funding the identities from the earnings of the parent identity is a withdrawal from the parent
+// to the channel of the ephemeral identity, which is public and links the two identities on chain.
+// The sessions stay unlinkable for the providers only if the identities rely on the free registration
+// or are topped up from elsewhere.
+package ephemeral
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	bucketName        = "consumer-ephemeral-identities"
+	fundsTimeout      = 10 * time.Minute
+	fundsPollInterval = 10 * time.Second
+	// createTimeout is how long a connection waits for its ephemeral identity, it stays below the timeout of API clients.
+	createTimeout = 60 * time.Second
+	saltSize      = 32
+)
+
+// ErrPending indicates that the ephemeral identity is still being funded or registered,
+// it is prepared in the background and the connection can be retried later.
+var ErrPending = errors.New("ephemeral identity is being prepared")
+
+// Storage keeps the ephemeral identities between node restarts.
+type Storage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+type identityCreator interface {
+	CreateNewIdentity(passphrase string) (identity.Identity, error)
+	Unlock(chainID int64, address string, passphrase string) error
+}
+
+type keyRemover interface {
+	Delete(a accounts.Account, passphrase string) error
+}
+
+type registrar interface {
+	Register(ctx context.Context, chainID int64, id identity.Identity) error
+}
+
+// funder moves the earnings of the parent identity into the channel of the ephemeral identity.
+// The withdrawal is public and links the ephemeral identity to its parent.
+type funder interface {
+	Withdraw(fromChainID int64, toChainID int64, providerID identity.Identity, hermesID, beneficiary common.Address, amount *big.Int) error
+}
+
+type addressProvider interface {
+	GetActiveHermes(chainID int64) (common.Address, error)
+	GetActiveChannelAddress(chainID int64, id common.Address) (common.Address, error)
+}
+
+type balanceProvider interface {
+	FetchBalance(chainID int64, id identity.Identity) (*big.Int, error)
+}
+
+// Config describes how the ephemeral identities are funded and cleaned up.
+type Config struct {
+	// FundAmount is moved from the parent identity's earnings into every new ephemeral identity, nothing if zero.
+	// The withdrawal links the ephemeral identity to its parent on chain.
+	FundAmount *big.Int
+	// MinBalance is the balance at or below which a used ephemeral identity is considered exhausted.
+	MinBalance *big.Int
+	// CleanupInterval is how often the used ephemeral identities are checked for exhaustion.
+	CleanupInterval time.Duration
+}
+
+// Record describes an ephemeral identity created by the node.
+type Record struct {
+	Address string `storm:"id"`
+	Parent  string
+	ChainID int64
+	// Salt derives the passphrase of the identity key together with the parent key, the passphrase itself is not stored.
+	Salt   string
+	Funded bool
+	// FundsArrived indicates that the funds moved from the parent reached the channel of the identity.
+	FundsArrived bool
+	// Ready indicates that the identity is registered and can be used by a connection.
+	Ready     bool
+	CreatedAt time.Time
+	// Sessions is the number of finished sessions of the identity.
+	Sessions      int
+	LastSessionAt time.Time
+}
+
+type preparation struct {
+	done chan struct{}
+	err  error
+}
+
+// Manager creates the ephemeral consumer identities and removes them once their balance is exhausted.
+type Manager struct {
+	storage    Storage
+	identities identityCreator
+	keys       keyRemover
+	signers    identity.SignerFactory
+	registrar  registrar
+	funder     funder
+	addresses  addressProvider
+	balances   balanceProvider
+	config     Config
+
+	now           func() time.Time
+	pollInterval  time.Duration
+	createTimeout time.Duration
+
+	mu        sync.Mutex
+	active    map[string]bool
+	claimed   map[string]bool
+	preparing map[string]*preparation
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+}
+
+// NewManager returns a new ephemeral identities manager.
+// The signers of the parent identities derive the passphrases of the ephemeral identity keys.
+func NewManager(storage Storage, identities identityCreator, keys keyRemover, signers identity.SignerFactory, registrar registrar, funder funder, addresses addressProvider, balances balanceProvider, config Config) *Manager {
+	if config.FundAmount == nil {
+		config.FundAmount = big.NewInt(0)
+	}
+	if config.MinBalance == nil {
+		config.MinBalance = big.NewInt(0)
+	}
+	if config.CleanupInterval <= 0 {
+		config.CleanupInterval = time.Hour
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		storage:       storage,
+		identities:    identities,
+		keys:          keys,
+		signers:       signers,
+		registrar:     registrar,
+		funder:        funder,
+		addresses:     addresses,
+		balances:      balances,
+		config:        config,
+		now:           time.Now,
+		pollInterval:  fundsPollInterval,
+		createTimeout: createTimeout,
+		active:        make(map[string]bool),
+		claimed:       make(map[string]bool),
+		preparing:     make(map[string]*preparation),
+		ctx:           ctx,
+		cancel:        cancel,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Create returns a registered ephemeral identity for a single connection of the parent identity.
+// The identity is funded from the parent's earnings if funding is configured, otherwise registering it
+// relies on the free registration or fails asking for a top up. The parent identity has to be unlocked. Funding and registration continue in the
+// background, ErrPending is returned if they take longer than the connection can wait.
+func (m *Manager) Create(ctx context.Context, chainID int64, parent identity.Identity) (identity.Identity, error) {
+	timeout := time.NewTimer(m.createTimeout)
+	defer timeout.Stop()
+
+	for {
+		id, ok, err := m.claimReady(chainID, parent)
+		if err != nil {
+			return identity.Identity{}, err
+		}
+		if ok {
+			return id, nil
+		}
+
+		p := m.prepare(chainID, parent)
+		select {
+		case <-p.done:
+			if p.err != nil {
+				return identity.Identity{}, p.err
+			}
+		case <-timeout.C:
+			return identity.Identity{}, ErrPending
+		case <-ctx.Done():
+			return identity.Identity{}, ctx.Err()
+		}
+	}
+}
+
+// claimReady hands out a prepared identity of the parent which was not used by any connection yet.
+func (m *Manager) claimReady(chainID int64, parent identity.Identity) (identity.Identity, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	records, err := m.List()
+	if err != nil {
+		return identity.Identity{}, false, fmt.Errorf("could not list ephemeral identities: %w", err)
+	}
+
+	for _, record := range records {
+		if !record.Ready || record.Parent != parent.Address || record.ChainID != chainID || record.Sessions > 0 {
+			continue
+		}
+		if m.claimed[record.Address] || m.active[record.Address] {
+			continue
+		}
+		passphrase, err := m.passphrase(record)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not unlock ephemeral identity %s", record.Address)
+			continue
+		}
+		if err := m.identities.Unlock(chainID, record.Address, passphrase); err != nil {
+			log.Warn().Err(err).Msgf("Could not unlock ephemeral identity %s", record.Address)
+			continue
+		}
+
+		m.claimed[record.Address] = true
+		return identity.FromAddress(record.Address), true, nil
+	}
+	return identity.Identity{}, false, nil
+}
+
+// prepare starts preparing a new identity for the parent unless one is being prepared already.
+func (m *Manager) prepare(chainID int64, parent identity.Identity) *preparation {
+	key := fmt.Sprintf("%d_%s", chainID, parent.Address)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if p, ok := m.preparing[key]; ok {
+		return p
+	}
+
+	p := &preparation{done: make(chan struct{})}
+	m.preparing[key] = p
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		p.err = m.createIdentity(m.ctx, chainID, parent)
+
+		m.mu.Lock()
+		delete(m.preparing, key)
+		m.mu.Unlock()
+		close(p.done)
+	}()
+	return p
+}
+
+func (m *Manager) createIdentity(ctx context.Context, chainID int64, parent identity.Identity) error {
+	salt, err := newSalt()
+	if err != nil {
+		return err
+	}
+	record := Record{
+		Parent:  parent.Address,
+		ChainID: chainID,
+		Salt:    salt,
+	}
+	passphrase, err := m.passphrase(record)
+	if err != nil {
+		return err
+	}
+
+	id, err := m.identities.CreateNewIdentity(passphrase)
+	if err != nil {
+		return fmt.Errorf("could not create ephemeral identity: %w", err)
+	}
+	record.Address = id.Address
+	if err := m.identities.Unlock(chainID, id.Address, passphrase); err != nil {
+		m.remove(record)
+		return fmt.Errorf("could not unlock ephemeral identity: %w", err)
+	}
+
+	record.CreatedAt = m.now().UTC()
+	if err := m.storage.Store(bucketName, &record); err != nil {
+		m.remove(record)
+		return fmt.Errorf("could not store ephemeral identity: %w", err)
+	}
+
+	if m.config.FundAmount.Sign() > 0 {
+		if err := m.fund(chainID, parent, id); err != nil {
+			m.remove(record)
+			return fmt.Errorf("could not fund ephemeral identity from %s: %w", parent.Address, err)
+		}
+		record.Funded = true
+		m.update(record)
+		if err := m.waitForFunds(ctx, chainID, id); err != nil {
+			return err
+		}
+		record.FundsArrived = true
+		m.update(record)
+	}
+
+	if err := m.registrar.Register(ctx, chainID, id); err != nil {
+		// Funded identities keep their balance, they are removed by the cleanup once it is exhausted.
+		if !record.Funded {
+			m.remove(record)
+		}
+		return fmt.Errorf("could not register ephemeral identity: %w", err)
+	}
+
+	record.Ready = true
+	m.update(record)
+	log.Info().Msgf("Created ephemeral identity %s for %s", id.Address, parent.Address)
+	return nil
+}
+
+func (m *Manager) update(record Record) {
+	if err := m.storage.Store(bucketName, &record); err != nil {
+		log.Warn().Err(err).Msgf("Could not update ephemeral identity %s", record.Address)
+	}
+}
+
+func newSalt() (string, error) {
+	b := make([]byte, saltSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("could not generate salt: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// passphrase derives the passphrase of the ephemeral identity key from the parent signature of its salt,
+// so it is never stored and the key can only be unlocked while the parent identity is unlocked.
+func (m *Manager) passphrase(record Record) (string, error) {
+	signature, err := m.signers(identity.FromAddress(record.Parent)).Sign([]byte("ephemeral identity key " + record.Salt))
+	if err != nil {
+		return "", fmt.Errorf("could not derive passphrase from parent identity %s: %w", record.Parent, err)
+	}
+	sum := sha256.Sum256(signature.Bytes())
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (m *Manager) fund(chainID int64, parent, id identity.Identity) error {
+	hermesID, err := m.addresses.GetActiveHermes(chainID)
+	if err != nil {
+		return fmt.Errorf("could not get active hermes: %w", err)
+	}
+	channel, err := m.addresses.GetActiveChannelAddress(chainID, id.ToCommonAddress())
+	if err != nil {
+		return fmt.Errorf("could not calculate channel address: %w", err)
+	}
+	return m.funder.Withdraw(chainID, chainID, parent, hermesID, channel, m.config.FundAmount)
+}
+
+// waitForFunds waits until the withdrawal of the parent's earnings reaches the channel of the ephemeral identity.
+func (m *Manager) waitForFunds(ctx context.Context, chainID int64, id identity.Identity) error {
+	ctx, cancel := context.WithTimeout(ctx, fundsTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+	for {
+		balance, err := m.balances.FetchBalance(chainID, id)
+		if err == nil && balance.Cmp(m.config.FundAmount) >= 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("funds of ephemeral identity %s did not arrive: %w", id.Address, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// List returns the ephemeral identities known to the node.
+func (m *Manager) List() ([]Record, error) {
+	var records []Record
+	if err := m.storage.GetAllFrom(bucketName, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Subscribe subscribes the manager to the consumer session events.
+func (m *Manager) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(connectionstate.AppTopicConnectionSession, m.handleSessionEvent)
+}
+
+// Start starts the periodic cleanup of the exhausted ephemeral identities.
+func (m *Manager) Start() {
+	if m.config.FundAmount.Sign() > 0 {
+		log.Warn().Msg("Ephemeral identities are funded from the earnings of the parent identity, the withdrawals link them to the parent on chain")
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.config.CleanupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.Cleanup()
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic cleanup and the preparation of identities.
+func (m *Manager) Stop() {
+	m.once.Do(func() {
+		m.cancel()
+		close(m.stop)
+	})
+	m.wg.Wait()
+}
+
+func (m *Manager) handleSessionEvent(e connectionstate.AppEventConnectionSession) {
+	address := e.SessionInfo.ConsumerID.Address
+
+	switch e.Status {
+	case connectionstate.SessionCreatedStatus:
+		m.mu.Lock()
+		m.active[address] = true
+		m.mu.Unlock()
+	case connectionstate.SessionEndedStatus:
+		m.mu.Lock()
+		delete(m.active, address)
+		m.mu.Unlock()
+
+		records, err := m.List()
+		if err != nil {
+			log.Error().Err(err).Msg("Could not list ephemeral identities")
+			return
+		}
+		for _, record := range records {
+			if record.Address != address {
+				continue
+			}
+			record.Sessions++
+			record.LastSessionAt = m.now().UTC()
+			if err := m.storage.Store(bucketName, &record); err != nil {
+				log.Error().Err(err).Msgf("Could not update ephemeral identity %s", address)
+			}
+		}
+	}
+}
+
+// Cleanup removes the ephemeral identities whose balance is exhausted, except the ones in use.
+// Identities are kept if their balance can not be read or the funds moved from the parent did not arrive yet.
+func (m *Manager) Cleanup() {
+	records, err := m.List()
+	if err != nil {
+		log.Error().Err(err).Msg("Could not list ephemeral identities")
+		return
+	}
+
+	for _, record := range records {
+		if m.isActive(record.Address) {
+			continue
+		}
+		// Give the identity a chance to be used by the connection it was created for.
+		if record.Sessions == 0 && m.now().Sub(record.CreatedAt) < m.config.CleanupInterval {
+			continue
+		}
+
+		balance, err := m.balances.FetchBalance(record.ChainID, identity.FromAddress(record.Address))
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not check balance of ephemeral identity %s, keeping it", record.Address)
+			continue
+		}
+		if record.Funded && !record.FundsArrived {
+			if balance.Sign() <= 0 {
+				log.Warn().Msgf("Funds of ephemeral identity %s did not arrive yet, keeping it", record.Address)
+				continue
+			}
+			record.FundsArrived = true
+			m.update(record)
+		}
+		if balance.Cmp(m.config.MinBalance) > 0 {
+			continue
+		}
+
+		log.Info().Msgf("Removing exhausted ephemeral identity %s", record.Address)
+		m.remove(record)
+	}
+}
+
+func (m *Manager) isActive(address string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active[address]
+}
+
+// remove deletes the identity key and its record. The identity is kept while the parent identity is locked,
+// as the passphrase of the key can not be derived.
+func (m *Manager) remove(record Record) {
+	passphrase, err := m.passphrase(record)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not delete the key of ephemeral identity %s, keeping it", record.Address)
+		return
+	}
+	if err := m.keys.Delete(accounts.Account{Address: common.HexToAddress(record.Address)}, passphrase); err != nil {
+		log.Warn().Err(err).Msgf("Could not delete the key of ephemeral identity %s", record.Address)
+	}
+	if err := m.storage.Delete(bucketName, &record); err != nil {
+		log.Warn().Err(err).Msgf("Could not delete ephemeral identity %s", record.Address)
+	}
+
+	m.mu.Lock()
+	delete(m.claimed, record.Address)
+	m.mu.Unlock()
+}
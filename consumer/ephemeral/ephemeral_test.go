@@ -0,0 +1,333 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ephemeral
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+var (
+	parent  = identity.FromAddress("0x000000000000000000000000000000000000000a")
+	hermes  = common.HexToAddress("0x00000000000000000000000000000000000000ff")
+	channel = common.HexToAddress("0x00000000000000000000000000000000000000cc")
+)
+
+type mockIdentities struct {
+	created     []identity.Identity
+	passphrases map[string]string
+	unlocked    []string
+}
+
+func (m *mockIdentities) CreateNewIdentity(passphrase string) (identity.Identity, error) {
+	id := identity.FromAddress(common.BigToAddress(big.NewInt(int64(len(m.created) + 1))).Hex())
+	m.created = append(m.created, id)
+	if m.passphrases == nil {
+		m.passphrases = make(map[string]string)
+	}
+	m.passphrases[id.Address] = passphrase
+	return id, nil
+}
+
+func (m *mockIdentities) Unlock(_ int64, address string, passphrase string) error {
+	if m.passphrases[address] != passphrase {
+		return errors.New("wrong passphrase")
+	}
+	m.unlocked = append(m.unlocked, address)
+	return nil
+}
+
+type mockKeys struct {
+	deleted     []common.Address
+	passphrases []string
+}
+
+func (m *mockKeys) Delete(a accounts.Account, passphrase string) error {
+	m.deleted = append(m.deleted, a.Address)
+	m.passphrases = append(m.passphrases, passphrase)
+	return nil
+}
+
+type mockRegistrar struct {
+	err        error
+	registered []identity.Identity
+}
+
+func (m *mockRegistrar) Register(_ context.Context, _ int64, id identity.Identity) error {
+	m.registered = append(m.registered, id)
+	return m.err
+}
+
+type withdrawal struct {
+	providerID  identity.Identity
+	hermesID    common.Address
+	beneficiary common.Address
+	amount      *big.Int
+}
+
+type mockFunder struct {
+	err         error
+	withdrawals []withdrawal
+}
+
+func (m *mockFunder) Withdraw(_ int64, _ int64, providerID identity.Identity, hermesID, beneficiary common.Address, amount *big.Int) error {
+	m.withdrawals = append(m.withdrawals, withdrawal{providerID: providerID, hermesID: hermesID, beneficiary: beneficiary, amount: amount})
+	return m.err
+}
+
+type mockAddresses struct{}
+
+func (m *mockAddresses) GetActiveHermes(int64) (common.Address, error) {
+	return hermes, nil
+}
+
+func (m *mockAddresses) GetActiveChannelAddress(int64, common.Address) (common.Address, error) {
+	return channel, nil
+}
+
+type mockBalances struct {
+	lock    sync.Mutex
+	balance *big.Int
+	err     error
+}
+
+func (m *mockBalances) FetchBalance(int64, identity.Identity) (*big.Int, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.balance, m.err
+}
+
+func (m *mockBalances) set(balance *big.Int, err error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.balance, m.err = balance, err
+}
+
+type fixture struct {
+	manager    *Manager
+	identities *mockIdentities
+	keys       *mockKeys
+	registrar  *mockRegistrar
+	funder     *mockFunder
+	balances   *mockBalances
+	signer     *identity.SignerFake
+}
+
+func newFixture(t *testing.T, config Config) (*fixture, func()) {
+	dir, err := os.MkdirTemp("", "ephemeralTest")
+	require.NoError(t, err)
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+
+	f := &fixture{
+		identities: &mockIdentities{},
+		keys:       &mockKeys{},
+		registrar:  &mockRegistrar{},
+		funder:     &mockFunder{},
+		balances:   &mockBalances{balance: big.NewInt(0)},
+	}
+	f.signer = &identity.SignerFake{}
+	signers := func(id identity.Identity) identity.Signer {
+		return f.signer
+	}
+	f.manager = NewManager(db, f.identities, f.keys, signers, f.registrar, f.funder, &mockAddresses{}, f.balances, config)
+	f.manager.pollInterval = time.Millisecond
+	return f, func() {
+		f.manager.Stop()
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestManager_CreatesFundedIdentity(t *testing.T) {
+	f, cleanup := newFixture(t, Config{FundAmount: big.NewInt(100)})
+	defer cleanup()
+	f.balances.balance = big.NewInt(100)
+
+	id, err := f.manager.Create(context.Background(), 137, parent)
+	require.NoError(t, err)
+
+	assert.Contains(t, f.identities.unlocked, id.Address)
+	assert.Equal(t, []withdrawal{{providerID: parent, hermesID: hermes, beneficiary: channel, amount: big.NewInt(100)}}, f.funder.withdrawals)
+	assert.Equal(t, []identity.Identity{id}, f.registrar.registered)
+
+	records, err := f.manager.List()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, id.Address, records[0].Address)
+	assert.Equal(t, parent.Address, records[0].Parent)
+	assert.True(t, records[0].Funded)
+	assert.True(t, records[0].FundsArrived)
+	assert.True(t, records[0].Ready)
+	assert.NotEmpty(t, records[0].Salt)
+	assert.NotEmpty(t, f.identities.passphrases[id.Address])
+	assert.NotEqual(t, f.identities.passphrases[id.Address], records[0].Salt)
+}
+
+func TestManager_KeepsIdentityWhileParentIsLocked(t *testing.T) {
+	f, cleanup := newFixture(t, Config{CleanupInterval: time.Hour})
+	defer cleanup()
+
+	id, err := f.manager.Create(context.Background(), 137, parent)
+	require.NoError(t, err)
+	f.manager.handleSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionEndedStatus,
+		SessionInfo: connectionstate.Status{ConsumerID: id},
+	})
+
+	f.signer.ErrorMock = errors.New("parent identity is locked")
+	f.manager.Cleanup()
+	assert.Empty(t, f.keys.deleted)
+	records, err := f.manager.List()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+
+	f.signer.ErrorMock = nil
+	f.manager.Cleanup()
+	assert.Equal(t, []common.Address{id.ToCommonAddress()}, f.keys.deleted)
+	assert.Equal(t, []string{f.identities.passphrases[id.Address]}, f.keys.passphrases)
+}
+
+func TestManager_ReturnsPendingIdentityOnceFundsArrive(t *testing.T) {
+	f, cleanup := newFixture(t, Config{FundAmount: big.NewInt(100)})
+	defer cleanup()
+	f.manager.createTimeout = 10 * time.Millisecond
+
+	_, err := f.manager.Create(context.Background(), 137, parent)
+	assert.ErrorIs(t, err, ErrPending)
+	_, err = f.manager.Create(context.Background(), 137, parent)
+	assert.ErrorIs(t, err, ErrPending)
+
+	f.balances.set(big.NewInt(100), nil)
+	f.manager.createTimeout = time.Second
+	id, err := f.manager.Create(context.Background(), 137, parent)
+	require.NoError(t, err)
+
+	assert.Len(t, f.identities.created, 1)
+	assert.Len(t, f.funder.withdrawals, 1)
+	assert.Equal(t, []identity.Identity{id}, f.registrar.registered)
+}
+
+func TestManager_RemovesIdentityWhichCouldNotBeFunded(t *testing.T) {
+	f, cleanup := newFixture(t, Config{FundAmount: big.NewInt(100)})
+	defer cleanup()
+	f.funder.err = errors.New("no earnings")
+
+	_, err := f.manager.Create(context.Background(), 137, parent)
+	assert.Error(t, err)
+
+	assert.Empty(t, f.registrar.registered)
+	assert.Len(t, f.keys.deleted, 1)
+	records, err := f.manager.List()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestManager_RemovesUnfundedIdentityWhichCouldNotBeRegistered(t *testing.T) {
+	f, cleanup := newFixture(t, Config{})
+	defer cleanup()
+	f.registrar.err = errors.New("insufficient balance")
+
+	_, err := f.manager.Create(context.Background(), 137, parent)
+	assert.Error(t, err)
+
+	assert.Empty(t, f.funder.withdrawals)
+	assert.Len(t, f.keys.deleted, 1)
+}
+
+func TestManager_CleansUpExhaustedIdentities(t *testing.T) {
+	f, cleanup := newFixture(t, Config{MinBalance: big.NewInt(10), CleanupInterval: time.Hour})
+	defer cleanup()
+
+	used, err := f.manager.Create(context.Background(), 137, parent)
+	require.NoError(t, err)
+	unused, err := f.manager.Create(context.Background(), 137, parent)
+	require.NoError(t, err)
+	active, err := f.manager.Create(context.Background(), 137, parent)
+	require.NoError(t, err)
+
+	f.manager.handleSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionCreatedStatus,
+		SessionInfo: connectionstate.Status{ConsumerID: used},
+	})
+	f.manager.handleSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionEndedStatus,
+		SessionInfo: connectionstate.Status{ConsumerID: used},
+	})
+	f.manager.handleSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionCreatedStatus,
+		SessionInfo: connectionstate.Status{ConsumerID: active},
+	})
+
+	f.balances.set(big.NewInt(11), nil)
+	f.manager.Cleanup()
+	assert.Empty(t, f.keys.deleted)
+
+	f.balances.set(nil, errors.New("hermes unavailable"))
+	f.manager.Cleanup()
+	assert.Empty(t, f.keys.deleted)
+
+	f.balances.set(big.NewInt(10), nil)
+	f.manager.Cleanup()
+	assert.Equal(t, []common.Address{used.ToCommonAddress()}, f.keys.deleted)
+
+	f.manager.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	f.manager.Cleanup()
+	assert.Equal(t, []common.Address{used.ToCommonAddress(), unused.ToCommonAddress()}, f.keys.deleted)
+
+	records, err := f.manager.List()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, active.Address, records[0].Address)
+	assert.NotContains(t, f.keys.passphrases, "")
+}
+
+func TestManager_KeepsFundedIdentityUntilFundsArrive(t *testing.T) {
+	f, cleanup := newFixture(t, Config{FundAmount: big.NewInt(100), CleanupInterval: time.Hour})
+	defer cleanup()
+
+	record := Record{Address: "0x00000000000000000000000000000000000000bb", Parent: parent.Address, ChainID: 137, Funded: true, CreatedAt: time.Now().UTC()}
+	require.NoError(t, f.manager.storage.Store(bucketName, &record))
+	f.manager.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	f.manager.Cleanup()
+	assert.Empty(t, f.keys.deleted)
+
+	f.balances.set(big.NewInt(100), nil)
+	f.manager.Cleanup()
+	assert.Empty(t, f.keys.deleted)
+
+	records, err := f.manager.List()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.True(t, records[0].FundsArrived)
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package favorites
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/asdine/storm/v3"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+)
+
+const bucketName = "provider-favorites"
+
+// ErrNotFound is returned when there are no curated details of the provider.
+var ErrNotFound = errors.New("provider is not curated")
+
+// Entry represents consumer curated details of a single provider.
+type Entry struct {
+	ProviderID string `storm:"id"`
+	Favorite   bool   `storm:"index"`
+	Note       string
+	Labels     []string
+	UpdatedAt  time.Time
+}
+
+// IsEmpty checks whether the entry carries no curated details.
+func (e Entry) IsEmpty() bool {
+	return !e.Favorite && e.Note == "" && len(e.Labels) == 0
+}
+
+// HasLabel checks whether the entry is labeled with the given label.
+func (e Entry) HasLabel(label string) bool {
+	for _, l := range e.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter narrows down the listed entries.
+type Filter struct {
+	FavoritesOnly bool
+	Label         string
+}
+
+// Storage keeps consumer curated provider lists.
+type Storage struct {
+	storage *boltdb.Bolt
+	now     func() time.Time
+}
+
+// NewStorage creates the storage of consumer curated provider lists.
+func NewStorage(storage *boltdb.Bolt) *Storage {
+	return &Storage{
+		storage: storage,
+		now:     time.Now,
+	}
+}
+
+// Save stores curated details of the provider, entry without any details removes the provider from the lists.
+func (s *Storage) Save(entry Entry) (Entry, error) {
+	entry.ProviderID = strings.ToLower(entry.ProviderID)
+	entry.Note = strings.TrimSpace(entry.Note)
+	entry.Labels = normalizeLabels(entry.Labels)
+	entry.UpdatedAt = s.now().UTC()
+
+	defer s.storage.Track("save", bucketName, entry.ProviderID)()
+	s.storage.Lock()
+	defer s.storage.Unlock()
+
+	if entry.IsEmpty() {
+		err := s.storage.DB().From(bucketName).DeleteStruct(&Entry{ProviderID: entry.ProviderID})
+		if err != nil && !errors.Is(err, storm.ErrNotFound) {
+			return Entry{}, err
+		}
+		return entry, nil
+	}
+
+	return entry, s.storage.DB().From(bucketName).Save(&entry)
+}
+
+// Get returns curated details of the provider.
+func (s *Storage) Get(providerID string) (Entry, error) {
+	defer s.storage.Track("get", bucketName, providerID)()
+	s.storage.RLock()
+	defer s.storage.RUnlock()
+
+	var entry Entry
+	err := s.storage.DB().From(bucketName).One("ProviderID", strings.ToLower(providerID), &entry)
+	if errors.Is(err, storm.ErrNotFound) {
+		return Entry{}, ErrNotFound
+	}
+	return entry, err
+}
+
+// Delete removes curated details of the provider.
+func (s *Storage) Delete(providerID string) error {
+	defer s.storage.Track("delete", bucketName, providerID)()
+	s.storage.Lock()
+	defer s.storage.Unlock()
+
+	err := s.storage.DB().From(bucketName).DeleteStruct(&Entry{ProviderID: strings.ToLower(providerID)})
+	if errors.Is(err, storm.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// List returns the curated providers matching the filter, favorites first.
+func (s *Storage) List(filter Filter) ([]Entry, error) {
+	defer s.storage.Track("list", bucketName, nil)()
+	s.storage.RLock()
+	defer s.storage.RUnlock()
+
+	var all []Entry
+	err := s.storage.DB().From(bucketName).All(&all)
+	if err != nil && !errors.Is(err, storm.ErrNotFound) {
+		return nil, err
+	}
+
+	result := make([]Entry, 0, len(all))
+	for _, entry := range all {
+		if filter.FavoritesOnly && !entry.Favorite {
+			continue
+		}
+		if filter.Label != "" && !entry.HasLabel(filter.Label) {
+			continue
+		}
+		result = append(result, entry)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Favorite != result[j].Favorite {
+			return result[i].Favorite
+		}
+		return result[i].ProviderID < result[j].ProviderID
+	})
+	return result, nil
+}
+
+// Lookup returns curated details of all the providers keyed by lowercase provider ID.
+func (s *Storage) Lookup() (map[string]Entry, error) {
+	entries, err := s.List(Filter{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		result[entry.ProviderID] = entry
+	}
+	return result, nil
+}
+
+func normalizeLabels(labels []string) []string {
+	var result []string
+	seen := make(map[string]struct{})
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		key := strings.ToLower(label)
+		if _, ok := seen[key]; ok || label == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, label)
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package favorites
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+)
+
+func TestStorage_SaveGetDelete(t *testing.T) {
+	storage, cleanup := newStorage(t)
+	defer cleanup()
+
+	saved, err := storage.Save(Entry{ProviderID: "0xABC", Favorite: true, Note: " fast ", Labels: []string{"gaming", " Gaming", ""}})
+	require.NoError(t, err)
+	assert.Equal(t, "0xabc", saved.ProviderID)
+
+	entry, err := storage.Get("0xAbc")
+	require.NoError(t, err)
+	assert.True(t, entry.Favorite)
+	assert.Equal(t, "fast", entry.Note)
+	assert.Equal(t, []string{"gaming"}, entry.Labels)
+	assert.True(t, entry.HasLabel("GAMING"))
+
+	require.NoError(t, storage.Delete("0xabc"))
+	_, err = storage.Get("0xabc")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, storage.Delete("0xabc"), ErrNotFound)
+}
+
+func TestStorage_SaveEmptyRemovesEntry(t *testing.T) {
+	storage, cleanup := newStorage(t)
+	defer cleanup()
+
+	_, err := storage.Save(Entry{ProviderID: "0x1", Favorite: true})
+	require.NoError(t, err)
+
+	_, err = storage.Save(Entry{ProviderID: "0x1"})
+	require.NoError(t, err)
+
+	_, err = storage.Get("0x1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStorage_List(t *testing.T) {
+	storage, cleanup := newStorage(t)
+	defer cleanup()
+
+	for _, e := range []Entry{
+		{ProviderID: "0x1", Note: "slow at night", Labels: []string{"work"}},
+		{ProviderID: "0x2", Favorite: true, Labels: []string{"streaming"}},
+		{ProviderID: "0x3", Favorite: true, Labels: []string{"work"}},
+	} {
+		_, err := storage.Save(e)
+		require.NoError(t, err)
+	}
+
+	all, err := storage.List(Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0x2", "0x3", "0x1"}, providerIDs(all))
+
+	favorites, err := storage.List(Filter{FavoritesOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0x2", "0x3"}, providerIDs(favorites))
+
+	work, err := storage.List(Filter{Label: "Work"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0x3", "0x1"}, providerIDs(work))
+
+	lookup, err := storage.Lookup()
+	require.NoError(t, err)
+	assert.Len(t, lookup, 3)
+	assert.Equal(t, "slow at night", lookup["0x1"].Note)
+}
+
+func providerIDs(entries []Entry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ProviderID)
+	}
+	return ids
+}
+
+func TestStorage_TracksOperations(t *testing.T) {
+	storage, cleanup := newStorage(t)
+	defer cleanup()
+
+	_, err := storage.Save(Entry{ProviderID: "0x1", Favorite: true})
+	require.NoError(t, err)
+	_, err = storage.List(Filter{})
+	require.NoError(t, err)
+
+	var operations []string
+	for _, stats := range storage.storage.Metrics() {
+		if stats.Bucket == bucketName {
+			operations = append(operations, stats.Operation)
+		}
+	}
+	assert.Equal(t, []string{"list", "save"}, operations)
+}
+
+func newStorage(t *testing.T) (*Storage, func()) {
+	dir, err := os.MkdirTemp("", "favoritesTest")
+	require.NoError(t, err)
+
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+
+	return NewStorage(db), func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
@@ -206,6 +206,15 @@ func (m *HermesMigrator) openChannel(id string, err error, chainID int64, active
 	return nil
 }
 
+func (m *HermesMigrator) openProviderChannel(chainID int64, id string, hermesID common.Address) error {
+	registryAddress, err := m.addressProvider.GetRegistryAddress(chainID)
+	if err != nil {
+		return fmt.Errorf("could not get registry address: %w", err)
+	}
+
+	return m.openChannel(id, nil, chainID, hermesID, registryAddress)
+}
+
 // IsMigrationRequired check whether migration required
 func (m *HermesMigrator) IsMigrationRequired(id string) (bool, error) {
 	chainID := config.GetInt64(config.FlagChainID)
@@ -20,6 +20,7 @@ package migration
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	storm "github.com/asdine/storm/v3"
 	"github.com/mysteriumnetwork/node/identity/registry"
@@ -28,6 +29,7 @@ import (
 
 const hermesMigrationBucketName = "hermes_migration"
 const hermesMigrationFinishedKey = "migration_finished"
+const providerMigrationKey = "provider_migration"
 
 // Storage keeps track of migration progress
 type Storage struct {
@@ -84,3 +86,24 @@ func (s *Storage) isMigrationRequired(chainID int64, identity string) bool {
 func (s *Storage) getMigrationKey(hermesId, id string) string {
 	return fmt.Sprintf("%s_%s_%s", hermesMigrationFinishedKey, hermesId, id)
 }
+
+func (s *Storage) providerMigration(chainID int64, identity string) (ProviderMigration, bool) {
+	var pm ProviderMigration
+	err := s.db.GetValue(hermesMigrationBucketName, s.getProviderMigrationKey(chainID, identity), &pm)
+	if err != nil {
+		if !errors.Is(err, storm.ErrNotFound) {
+			log.Warn().Err(err).Msg("Could not get provider migration state from local db")
+		}
+		return pm, false
+	}
+
+	return pm, true
+}
+
+func (s *Storage) saveProviderMigration(pm ProviderMigration) error {
+	return s.db.SetValue(hermesMigrationBucketName, s.getProviderMigrationKey(pm.ChainID, pm.ProviderID), pm)
+}
+
+func (s *Storage) getProviderMigrationKey(chainID int64, id string) string {
+	return fmt.Sprintf("%s_%d_%s", providerMigrationKey, chainID, strings.ToLower(id))
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/rs/zerolog/log"
+)
+
+// ProviderMigrationStep is a step of the provider migration between hermeses.
+type ProviderMigrationStep string
+
+const (
+	// ProviderMigrationStepSettle settles all the promises with the old hermes.
+	ProviderMigrationStepSettle ProviderMigrationStep = "settle"
+	// ProviderMigrationStepStake opens a channel (stake) with the new hermes.
+	ProviderMigrationStepStake ProviderMigrationStep = "stake"
+	// ProviderMigrationStepVerify checks that the new hermes is ready to serve the provider.
+	ProviderMigrationStepVerify ProviderMigrationStep = "verify"
+	// ProviderMigrationStepSwitch switches session acceptance to the new hermes.
+	ProviderMigrationStepSwitch ProviderMigrationStep = "switch"
+	// ProviderMigrationStepDone means migration is finished.
+	ProviderMigrationStepDone ProviderMigrationStep = "done"
+)
+
+var providerMigrationSteps = []ProviderMigrationStep{
+	ProviderMigrationStepSettle,
+	ProviderMigrationStepStake,
+	ProviderMigrationStepVerify,
+	ProviderMigrationStepSwitch,
+	ProviderMigrationStepDone,
+}
+
+// ErrProviderMigrationInProgress indicates that the provider is already migrating to another hermes.
+var ErrProviderMigrationInProgress = errors.New("provider migration to another hermes is in progress")
+
+// ProviderMigration is the persisted state of the provider migration job.
+type ProviderMigration struct {
+	ChainID    int64
+	ProviderID string
+	OldHermes  common.Address
+	NewHermes  common.Address
+	Step       ProviderMigrationStep
+	Error      string
+	UpdatedAt  time.Time
+}
+
+// Finished returns true if provider is fully migrated to the new hermes.
+func (pm ProviderMigration) Finished() bool {
+	return pm.Step == ProviderMigrationStepDone
+}
+
+// acceptsHermes tells whether sessions paid through the given hermes may be accepted.
+// Until the switch step is over, provider keeps serving its old hermes only.
+func (pm ProviderMigration) acceptsHermes(hermesID common.Address) bool {
+	if pm.Finished() {
+		return hermesID == pm.NewHermes
+	}
+	return hermesID == pm.OldHermes
+}
+
+type providerSettler interface {
+	ForceSettle(chainID int64, providerID identity.Identity, hermesID ...common.Address) error
+}
+
+type providerChannels interface {
+	openProviderChannel(chainID int64, id string, hermesID common.Address) error
+	isChannelOpened(chainID int64, identity, hermesID common.Address) (bool, error)
+	getUserData(chainID int64, hermesID, id string) (pingpong.HermesUserInfo, error)
+}
+
+type providerMigrationStorage interface {
+	providerMigration(chainID int64, id string) (ProviderMigration, bool)
+	saveProviderMigration(pm ProviderMigration) error
+}
+
+// ProviderMigrator moves provider from one hermes (accountant) to another.
+// Migration is split into steps and its state is persisted after each of them,
+// so an interrupted migration is resumed from the last unfinished step.
+type ProviderMigrator struct {
+	settler  providerSettler
+	channels providerChannels
+	st       providerMigrationStorage
+	now      func() time.Time
+
+	lock    sync.Mutex
+	running map[string]bool
+}
+
+// NewProviderMigrator creates new ProviderMigrator.
+func NewProviderMigrator(settler pingpong.HermesPromiseSettler, hm *HermesMigrator, st *Storage) *ProviderMigrator {
+	return newProviderMigrator(settler, hm, st)
+}
+
+func newProviderMigrator(settler providerSettler, channels providerChannels, st providerMigrationStorage) *ProviderMigrator {
+	return &ProviderMigrator{
+		settler:  settler,
+		channels: channels,
+		st:       st,
+		now:      time.Now,
+		running:  make(map[string]bool),
+	}
+}
+
+// Start begins provider migration from old hermes to the new one.
+// Starting a migration which failed before resumes it from the failed step.
+func (m *ProviderMigrator) Start(chainID int64, id identity.Identity, oldHermes, newHermes common.Address) (ProviderMigration, error) {
+	pm, found := m.st.providerMigration(chainID, id.Address)
+	switch {
+	case found && !pm.Finished() && pm.NewHermes != newHermes:
+		return pm, ErrProviderMigrationInProgress
+	case found && pm.NewHermes == newHermes:
+		// resume or report already finished migration
+	default:
+		if found {
+			oldHermes = pm.NewHermes
+		}
+		if oldHermes == newHermes {
+			return ProviderMigration{}, fmt.Errorf("provider is already using hermes %s", newHermes.Hex())
+		}
+		pm = ProviderMigration{
+			ChainID:    chainID,
+			ProviderID: id.Address,
+			OldHermes:  oldHermes,
+			NewHermes:  newHermes,
+			Step:       ProviderMigrationStepSettle,
+		}
+	}
+
+	if pm.Finished() {
+		return pm, nil
+	}
+
+	pm.Error = ""
+	if err := m.save(&pm); err != nil {
+		return pm, err
+	}
+	m.runAsync(pm)
+
+	return pm, nil
+}
+
+// Resume continues unfinished migrations of the given providers.
+func (m *ProviderMigrator) Resume(chainID int64, ids ...identity.Identity) {
+	for _, id := range ids {
+		pm, found := m.st.providerMigration(chainID, id.Address)
+		if !found || pm.Finished() || pm.Error != "" {
+			continue
+		}
+
+		log.Info().Msgf("Resuming provider %s migration to hermes %s at step %q", id.Address, pm.NewHermes.Hex(), pm.Step)
+		m.runAsync(pm)
+	}
+}
+
+// Status returns the last known state of provider migration.
+func (m *ProviderMigrator) Status(chainID int64, id identity.Identity) (ProviderMigration, bool) {
+	return m.st.providerMigration(chainID, id.Address)
+}
+
+// AcceptsHermes tells whether provider may accept sessions paid through the given hermes.
+func (m *ProviderMigrator) AcceptsHermes(chainID int64, providerID identity.Identity, hermesID common.Address) bool {
+	pm, found := m.st.providerMigration(chainID, providerID.Address)
+	if !found {
+		return true
+	}
+	return pm.acceptsHermes(hermesID)
+}
+
+func (m *ProviderMigrator) runAsync(pm ProviderMigration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.running[pm.ProviderID] {
+		return
+	}
+	m.running[pm.ProviderID] = true
+
+	go func() {
+		err := m.run(&pm)
+
+		m.lock.Lock()
+		delete(m.running, pm.ProviderID)
+		m.lock.Unlock()
+
+		if err != nil {
+			log.Err(err).Msgf("Provider %s migration to hermes %s failed at step %q", pm.ProviderID, pm.NewHermes.Hex(), pm.Step)
+			// Error is saved only after the job is released, so that it can be retried right away.
+			pm.Error = err.Error()
+			if err := m.save(&pm); err != nil {
+				log.Warn().Err(err).Msg("Could not save provider migration state")
+			}
+		}
+	}()
+}
+
+func (m *ProviderMigrator) run(pm *ProviderMigration) error {
+	for !pm.Finished() {
+		if err := m.runStep(*pm); err != nil {
+			return err
+		}
+
+		pm.Step = nextProviderMigrationStep(pm.Step)
+		if err := m.save(pm); err != nil {
+			return err
+		}
+	}
+
+	log.Info().Msgf("Provider %s migrated to hermes %s", pm.ProviderID, pm.NewHermes.Hex())
+	return nil
+}
+
+func (m *ProviderMigrator) runStep(pm ProviderMigration) error {
+	providerID := identity.FromAddress(pm.ProviderID)
+
+	switch pm.Step {
+	case ProviderMigrationStepSettle:
+		return m.settle(pm.ChainID, providerID, pm.OldHermes)
+	case ProviderMigrationStepStake:
+		if err := m.channels.openProviderChannel(pm.ChainID, pm.ProviderID, pm.NewHermes); err != nil {
+			return fmt.Errorf("could not open channel with hermes %s: %w", pm.NewHermes.Hex(), err)
+		}
+		return nil
+	case ProviderMigrationStepVerify:
+		opened, err := m.channels.isChannelOpened(pm.ChainID, providerID.ToCommonAddress(), pm.NewHermes)
+		if err != nil {
+			return fmt.Errorf("could not check channel with hermes %s: %w", pm.NewHermes.Hex(), err)
+		}
+		if !opened {
+			return fmt.Errorf("channel with hermes %s is not opened", pm.NewHermes.Hex())
+		}
+		if _, err := m.channels.getUserData(pm.ChainID, pm.NewHermes.Hex(), pm.ProviderID); err != nil {
+			return fmt.Errorf("hermes %s is unreachable: %w", pm.NewHermes.Hex(), err)
+		}
+		return nil
+	case ProviderMigrationStepSwitch:
+		// Sessions with the old hermes might have earned some more before acceptance
+		// is switched, settle them last time. Failure here is not fatal, since the
+		// inactive hermes settlement will pick those promises up later.
+		if err := m.settle(pm.ChainID, providerID, pm.OldHermes); err != nil {
+			log.Warn().Err(err).Msgf("Final settlement with hermes %s failed", pm.OldHermes.Hex())
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unknown provider migration step %q", pm.Step)
+}
+
+func (m *ProviderMigrator) settle(chainID int64, providerID identity.Identity, hermesID common.Address) error {
+	err := m.settler.ForceSettle(chainID, providerID, hermesID)
+	if err != nil && !errors.Is(err, pingpong.ErrNothingToSettle) {
+		return fmt.Errorf("could not settle with hermes %s: %w", hermesID.Hex(), err)
+	}
+	return nil
+}
+
+func (m *ProviderMigrator) save(pm *ProviderMigration) error {
+	pm.UpdatedAt = m.now().UTC()
+	if err := m.st.saveProviderMigration(*pm); err != nil {
+		return fmt.Errorf("could not save provider migration state: %w", err)
+	}
+	return nil
+}
+
+func nextProviderMigrationStep(step ProviderMigrationStep) ProviderMigrationStep {
+	for i, s := range providerMigrationSteps[:len(providerMigrationSteps)-1] {
+		if s == step {
+			return providerMigrationSteps[i+1]
+		}
+	}
+	return ProviderMigrationStepDone
+}
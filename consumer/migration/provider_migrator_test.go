@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migration
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	providerID = identity.FromAddress("0x1")
+	oldHermes  = common.HexToAddress("0x2")
+	newHermes  = common.HexToAddress("0x3")
+)
+
+func TestProviderMigrator_ResumesFromFailedStep(t *testing.T) {
+	settler := &mockProviderSettler{err: pingpong.ErrNothingToSettle}
+	channels := &mockProviderChannels{opened: false}
+	st := newMockProviderMigrationStorage()
+	m := newProviderMigrator(settler, channels, st)
+
+	pm, err := m.Start(1, providerID, oldHermes, newHermes)
+	assert.NoError(t, err)
+	assert.Equal(t, ProviderMigrationStepSettle, pm.Step)
+
+	assert.Eventually(t, func() bool {
+		pm, _ := m.Status(1, providerID)
+		return pm.Error != ""
+	}, 2*time.Second, 10*time.Millisecond)
+
+	pm, _ = m.Status(1, providerID)
+	assert.Equal(t, ProviderMigrationStepVerify, pm.Step)
+	assert.Equal(t, 1, channels.openCalls())
+	assert.True(t, m.AcceptsHermes(1, providerID, oldHermes))
+	assert.False(t, m.AcceptsHermes(1, providerID, newHermes))
+
+	_, err = m.Start(1, providerID, oldHermes, common.HexToAddress("0x4"))
+	assert.Equal(t, ErrProviderMigrationInProgress, err)
+
+	channels.setOpened(true)
+	_, err = m.Start(1, providerID, oldHermes, newHermes)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		pm, _ := m.Status(1, providerID)
+		return pm.Finished()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	pm, _ = m.Status(1, providerID)
+	assert.Empty(t, pm.Error)
+	assert.Equal(t, 1, channels.openCalls())
+	assert.Equal(t, 2, settler.settleCalls())
+	assert.False(t, m.AcceptsHermes(1, providerID, oldHermes))
+	assert.True(t, m.AcceptsHermes(1, providerID, newHermes))
+}
+
+func TestProviderMigrator_AcceptsAnyHermesWithoutMigration(t *testing.T) {
+	m := newProviderMigrator(&mockProviderSettler{}, &mockProviderChannels{}, newMockProviderMigrationStorage())
+
+	assert.True(t, m.AcceptsHermes(1, providerID, oldHermes))
+	assert.True(t, m.AcceptsHermes(1, providerID, newHermes))
+
+	_, err := m.Start(1, providerID, oldHermes, oldHermes)
+	assert.Error(t, err)
+}
+
+func TestProviderMigrator_StopsWhenSettlementFails(t *testing.T) {
+	settler := &mockProviderSettler{err: errors.New("boom")}
+	channels := &mockProviderChannels{}
+	m := newProviderMigrator(settler, channels, newMockProviderMigrationStorage())
+
+	_, err := m.Start(1, providerID, oldHermes, newHermes)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		pm, _ := m.Status(1, providerID)
+		return pm.Error != ""
+	}, 2*time.Second, 10*time.Millisecond)
+
+	pm, _ := m.Status(1, providerID)
+	assert.Equal(t, ProviderMigrationStepSettle, pm.Step)
+	assert.Equal(t, 0, channels.openCalls())
+}
+
+type mockProviderSettler struct {
+	lock  sync.Mutex
+	err   error
+	calls int
+}
+
+func (m *mockProviderSettler) ForceSettle(_ int64, _ identity.Identity, _ ...common.Address) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.calls++
+	return m.err
+}
+
+func (m *mockProviderSettler) settleCalls() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.calls
+}
+
+type mockProviderChannels struct {
+	lock   sync.Mutex
+	opened bool
+	opens  int
+}
+
+func (m *mockProviderChannels) openProviderChannel(_ int64, _ string, _ common.Address) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.opens++
+	return nil
+}
+
+func (m *mockProviderChannels) isChannelOpened(_ int64, _, _ common.Address) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.opened, nil
+}
+
+func (m *mockProviderChannels) getUserData(_ int64, _, _ string) (pingpong.HermesUserInfo, error) {
+	return pingpong.HermesUserInfo{}, nil
+}
+
+func (m *mockProviderChannels) setOpened(opened bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.opened = opened
+}
+
+func (m *mockProviderChannels) openCalls() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.opens
+}
+
+type mockProviderMigrationStorage struct {
+	lock       sync.Mutex
+	migrations map[string]ProviderMigration
+}
+
+func newMockProviderMigrationStorage() *mockProviderMigrationStorage {
+	return &mockProviderMigrationStorage{migrations: make(map[string]ProviderMigration)}
+}
+
+func (m *mockProviderMigrationStorage) providerMigration(_ int64, id string) (ProviderMigration, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	pm, ok := m.migrations[id]
+	return pm, ok
+}
+
+func (m *mockProviderMigrationStorage) saveProviderMigration(pm ProviderMigration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.migrations[pm.ProviderID] = pm
+	return nil
+}
@@ -18,6 +18,7 @@
 package session
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/asdine/storm/v3/q"
@@ -39,6 +40,8 @@ type Filter struct {
 	ProviderID  *identity.Identity
 	ServiceType *string
 	Status      *string
+	MinDuration *time.Duration
+	MinData     *uint64
 }
 
 // SetStartedFrom filters fetched sessions from given time.
@@ -91,6 +94,18 @@ func (f *Filter) SetStatus(status string) *Filter {
 	return f
 }
 
+// SetMinDuration filters fetched sessions lasting at least given duration.
+func (f *Filter) SetMinDuration(duration time.Duration) *Filter {
+	f.MinDuration = &duration
+	return f
+}
+
+// SetMinData filters fetched sessions which transferred at least given amount of bytes in total.
+func (f *Filter) SetMinData(bytes uint64) *Filter {
+	f.MinData = &bytes
+	return f
+}
+
 func (f *Filter) toMatcher() q.Matcher {
 	where := make([]q.Matcher, 0)
 	if f.StartedFrom != nil {
@@ -117,5 +132,34 @@ func (f *Filter) toMatcher() q.Matcher {
 	if f.Status != nil {
 		where = append(where, q.Eq("Status", *f.Status))
 	}
+	if f.MinDuration != nil || f.MinData != nil {
+		where = append(where, &thresholdMatcher{minDuration: f.MinDuration, minData: f.MinData})
+	}
 	return q.And(where...)
 }
+
+// thresholdMatcher matches sessions by values derived from several fields,
+// which can't be expressed with the field matchers.
+type thresholdMatcher struct {
+	minDuration *time.Duration
+	minData     *uint64
+}
+
+func (m *thresholdMatcher) Match(i interface{}) (bool, error) {
+	v := reflect.Indirect(reflect.ValueOf(i))
+	return m.MatchValue(&v)
+}
+
+func (m *thresholdMatcher) MatchValue(v *reflect.Value) (bool, error) {
+	se, ok := v.Interface().(History)
+	if !ok {
+		return false, nil
+	}
+	if m.minDuration != nil && se.GetDuration() < *m.minDuration {
+		return false, nil
+	}
+	if m.minData != nil && se.DataSent+se.DataReceived < *m.minData {
+		return false, nil
+	}
+	return true, nil
+}
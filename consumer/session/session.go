@@ -43,10 +43,10 @@ const (
 type History struct {
 	SessionID       node_session.ID `storm:"id"`
 	Direction       string
-	ConsumerID      identity.Identity
+	ConsumerID      identity.Identity `storm:"index"`
 	HermesID        string
 	ProviderID      identity.Identity
-	ServiceType     string
+	ServiceType     string `storm:"index"`
 	ConsumerCountry string
 	ProviderCountry string
 	DataSent        uint64
@@ -55,9 +55,21 @@ type History struct {
 
 	IPType string
 
-	Status  string
-	Started time.Time
-	Updated time.Time
+	// Speedtests are the throughput tests run over the session tunnel, the most recent last.
+	Speedtests []Speedtest
+
+	Status            string
+	TerminationReason string
+	Started           time.Time
+	Updated           time.Time
+}
+
+// Speedtest holds the throughput of the session tunnel measured by the consumer.
+type Speedtest struct {
+	Time     time.Time
+	Latency  time.Duration
+	Download uint64
+	Upload   uint64
 }
 
 // GetDuration returns delta in seconds (TimeUpdated - TimeStarted)
@@ -20,6 +20,7 @@ package session
 import (
 	"errors"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
 
@@ -35,7 +36,11 @@ import (
 	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
 )
 
-const sessionStorageBucketName = "session-history"
+const (
+	sessionStorageBucketName = "session-history"
+	// maxSpeedtests is the number of the most recent speedtests kept in the session history.
+	maxSpeedtests = 10
+)
 
 type timeGetter func() time.Time
 
@@ -66,6 +71,9 @@ func (repo *Storage) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(session_event.AppTopicDataTransferred, repo.consumeServiceSessionStatisticsEvent); err != nil {
 		return err
 	}
+	if err := bus.Subscribe(session_event.AppTopicSessionTerminated, repo.consumeSessionTerminatedEvent); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(session_event.AppTopicTokensEarned, repo.consumeServiceSessionEarningsEvent); err != nil {
 		return err
 	}
@@ -75,6 +83,9 @@ func (repo *Storage) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.Subscribe(connectionstate.AppTopicConnectionStatistics, repo.consumeConnectionStatisticsEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionSpeedtest, repo.consumeConnectionSpeedtestEvent); err != nil {
+		return err
+	}
 	return bus.Subscribe(pingpong_event.AppTopicInvoicePaid, repo.consumeConnectionSpendingEvent)
 }
 
@@ -85,8 +96,14 @@ func (repo *Storage) GetAll() ([]History, error) {
 
 // List retrieves stored entries.
 func (repo *Storage) List(filter *Filter) (result []History, err error) {
+	defer repo.storage.Track("list", sessionStorageBucketName, nil)()
 	repo.storage.RLock()
 	defer repo.storage.RUnlock()
+
+	if filter.ConsumerID != nil || filter.ServiceType != nil {
+		return repo.listIndexed(filter)
+	}
+
 	query := repo.storage.DB().
 		From(sessionStorageBucketName).
 		Select(filter.toMatcher()).
@@ -101,8 +118,44 @@ func (repo *Storage) List(filter *Filter) (result []History, err error) {
 	return result, err
 }
 
+// listIndexed looks up the sessions through consumer or service type index
+// and applies the rest of the filter on the narrowed down set.
+func (repo *Storage) listIndexed(filter *Filter) ([]History, error) {
+	var candidates []History
+	var err error
+	if filter.ConsumerID != nil {
+		err = repo.storage.DB().From(sessionStorageBucketName).Find("ConsumerID", *filter.ConsumerID, &candidates)
+	} else {
+		err = repo.storage.DB().From(sessionStorageBucketName).Find("ServiceType", *filter.ServiceType, &candidates)
+	}
+	if errors.Is(err, storm.ErrNotFound) {
+		return []History{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matcher := filter.toMatcher()
+	result := make([]History, 0, len(candidates))
+	for _, se := range candidates {
+		ok, err := matcher.Match(&se)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, se)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Started.After(result[j].Started)
+	})
+	return result, nil
+}
+
 // Stats fetches aggregated statistics to Filter.Stats.
 func (repo *Storage) Stats(filter *Filter) (result Stats, err error) {
+	defer repo.storage.Track("stats", sessionStorageBucketName, nil)()
 	repo.storage.RLock()
 	defer repo.storage.RUnlock()
 	query := repo.storage.DB().
@@ -126,6 +179,7 @@ const stepDay = 24 * time.Hour
 
 // StatsByDay retrieves aggregated statistics grouped by day to Filter.StatsByDay.
 func (repo *Storage) StatsByDay(filter *Filter) (result map[time.Time]Stats, err error) {
+	defer repo.storage.Track("stats_by_day", sessionStorageBucketName, nil)()
 	repo.storage.RLock()
 	defer repo.storage.RUnlock()
 	query := repo.storage.DB().
@@ -221,6 +275,20 @@ func (repo *Storage) consumeServiceSessionEarningsEvent(e session_event.AppEvent
 	repo.sessionsActive[sessionID] = row
 }
 
+func (repo *Storage) consumeSessionTerminatedEvent(e session_event.AppEventSessionTerminated) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sessionID := session_node.ID(e.ID)
+	row, ok := repo.activeSession(sessionID)
+	if !ok {
+		return
+	}
+
+	row.TerminationReason = e.Reason.String()
+	repo.sessionsActive[sessionID] = row
+}
+
 func (repo *Storage) activeSession(sessionID session_node.ID) (History, bool) {
 	history, ok := repo.sessionsActive[sessionID]
 	if !ok {
@@ -272,6 +340,36 @@ func (repo *Storage) consumeConnectionStatisticsEvent(e connectionstate.AppEvent
 	repo.sessionsActive[e.SessionInfo.SessionID] = row
 }
 
+func (repo *Storage) consumeConnectionSpeedtestEvent(e connectionstate.AppEventConnectionSpeedtest) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sessionID := e.SessionInfo.SessionID
+	row, ok := repo.activeSession(sessionID)
+	if !ok {
+		return
+	}
+
+	row.Speedtests = append(row.Speedtests, Speedtest{
+		Time:     e.Result.StartedAt,
+		Latency:  e.Result.Latency,
+		Download: e.Result.Download,
+		Upload:   e.Result.Upload,
+	})
+	if len(row.Speedtests) > maxSpeedtests {
+		row.Speedtests = row.Speedtests[len(row.Speedtests)-maxSpeedtests:]
+	}
+	row.Updated = repo.timeGetter().UTC()
+
+	err := repo.storage.Update(sessionStorageBucketName, &row)
+	if err != nil {
+		log.Error().Err(err).Msgf("Session %v update failed", sessionID)
+		return
+	}
+
+	repo.sessionsActive[sessionID] = row
+}
+
 func (repo *Storage) consumeConnectionSpendingEvent(e pingpong_event.AppEventInvoicePaid) {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
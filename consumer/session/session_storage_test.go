@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/speedtest"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
@@ -137,6 +138,95 @@ func TestSessionStorage_ListFiltersDirection(t *testing.T) {
 	assert.Equal(t, []History{}, result)
 }
 
+func TestSessionStorage_ListByConsumerAndService(t *testing.T) {
+	// given
+	session1 := History{
+		SessionID:   session_node.ID("session1"),
+		ConsumerID:  identity.FromAddress("consumer1"),
+		ServiceType: "wireguard",
+		Started:     time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC),
+		Updated:     time.Date(2020, 6, 17, 11, 0, 0, 0, time.UTC),
+	}
+	session2 := History{
+		SessionID:   session_node.ID("session2"),
+		ConsumerID:  identity.FromAddress("consumer2"),
+		ServiceType: "wireguard",
+		Started:     time.Date(2020, 6, 18, 10, 0, 0, 0, time.UTC),
+		Updated:     time.Date(2020, 6, 18, 11, 0, 0, 0, time.UTC),
+	}
+	session3 := History{
+		SessionID:   session_node.ID("session3"),
+		ConsumerID:  identity.FromAddress("consumer1"),
+		ServiceType: "openvpn",
+		Started:     time.Date(2020, 6, 19, 10, 0, 0, 0, time.UTC),
+		Updated:     time.Date(2020, 6, 19, 11, 0, 0, 0, time.UTC),
+	}
+	storage, storageCleanup := newStorageWithSessions(session1, session2, session3)
+	defer storageCleanup()
+
+	// when
+	result, err := storage.List(NewFilter().SetConsumerID(identity.FromAddress("consumer1")))
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{session3, session1}, result)
+
+	// when
+	result, err = storage.List(NewFilter().SetConsumerID(identity.FromAddress("consumer1")).SetServiceType("wireguard"))
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{session1}, result)
+
+	// when
+	result, err = storage.List(NewFilter().SetServiceType("wireguard").SetStartedFrom(session2.Started))
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{session2}, result)
+
+	// when
+	result, err = storage.List(NewFilter().SetConsumerID(identity.FromAddress("consumer3")))
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{}, result)
+}
+
+func TestSessionStorage_ListFiltersThresholds(t *testing.T) {
+	// given
+	sessionShort := History{
+		SessionID:    session_node.ID("session1"),
+		Started:      time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC),
+		Updated:      time.Date(2020, 6, 17, 10, 0, 30, 0, time.UTC),
+		DataSent:     5000,
+		DataReceived: 5000,
+	}
+	sessionLong := History{
+		SessionID:    session_node.ID("session2"),
+		Started:      time.Date(2020, 6, 18, 10, 0, 0, 0, time.UTC),
+		Updated:      time.Date(2020, 6, 18, 11, 0, 0, 0, time.UTC),
+		DataSent:     100,
+		DataReceived: 100,
+	}
+	storage, storageCleanup := newStorageWithSessions(sessionShort, sessionLong)
+	defer storageCleanup()
+
+	// when
+	result, err := storage.List(NewFilter().SetMinDuration(time.Minute))
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{sessionLong}, result)
+
+	// when
+	result, err = storage.List(NewFilter().SetMinData(10000))
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{sessionShort}, result)
+
+	// when
+	stats, err := storage.Stats(NewFilter().SetMinDuration(time.Minute).SetMinData(10000))
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.Count)
+}
+
 func TestSessionStorage_Stats(t *testing.T) {
 	// given
 	sessionExpected := History{
@@ -376,6 +466,32 @@ func TestSessionStorage_consumeEventEndedOK(t *testing.T) {
 	)
 }
 
+func TestSessionStorage_consumeSessionTerminatedEvent(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	defer storageCleanup()
+
+	// when
+	storage.consumeConnectionSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionCreatedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+	storage.consumeSessionTerminatedEvent(session_event.AppEventSessionTerminated{
+		ID:     string(connectionSessionMock.SessionID),
+		Reason: session_node.TerminationReasonPolicyViolation,
+	})
+	storage.consumeConnectionSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionEndedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+
+	// then
+	sessions, err := storage.GetAll()
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, "policy_violation", sessions[0].TerminationReason)
+}
+
 func TestSessionStorage_consumeEventConnectedOK(t *testing.T) {
 	// given
 	storage, storageCleanup := newStorage()
@@ -482,6 +598,47 @@ func TestSessionStorage_consumeSessionSpendingEvent(t *testing.T) {
 	)
 }
 
+func TestSessionStorage_consumeConnectionSpeedtestEvent(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	storage.timeGetter = func() time.Time {
+		return time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+	}
+	defer storageCleanup()
+
+	storage.consumeConnectionSessionEvent(connectionstate.AppEventConnectionSession{
+		Status:      connectionstate.SessionCreatedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+
+	// when
+	for i := 0; i < maxSpeedtests+1; i++ {
+		storage.consumeConnectionSpeedtestEvent(connectionstate.AppEventConnectionSpeedtest{
+			SessionInfo: connectionSessionMock,
+			Result: speedtest.Result{
+				StartedAt: time.Date(2020, 4, 1, 11, i, 0, 0, time.UTC),
+				Latency:   20 * time.Millisecond,
+				Download:  uint64(i),
+				Upload:    1000,
+			},
+		})
+	}
+
+	// then
+	sessions, err := storage.GetAll()
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Len(t, sessions[0].Speedtests, maxSpeedtests)
+	assert.Equal(t, Speedtest{
+		Time:     time.Date(2020, 4, 1, 11, maxSpeedtests, 0, 0, time.UTC),
+		Latency:  20 * time.Millisecond,
+		Download: maxSpeedtests,
+		Upload:   1000,
+	}, sessions[0].Speedtests[maxSpeedtests-1])
+	assert.Equal(t, uint64(1), sessions[0].Speedtests[0].Download)
+	assert.Equal(t, time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC), sessions[0].Updated)
+}
+
 func newStorage() (*Storage, func()) {
 	dir, err := ioutil.TempDir("", "sessionStorageTest")
 	if err != nil {
@@ -55,5 +55,7 @@ func (s *Stats) Add(session History) {
 	s.SumDataReceived += session.DataReceived
 	s.SumDataSent += session.DataSent
 	s.SumDuration += session.GetDuration()
-	s.SumTokens = new(big.Int).Add(s.SumTokens, session.Tokens)
+	if session.Tokens != nil {
+		s.SumTokens = new(big.Int).Add(s.SumTokens, session.Tokens)
+	}
 }
@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+const (
+	bucketName          = "consumer-wallet"
+	spendingIdentityKey = "spending-identity"
+)
+
+// ErrUnknownIdentity is returned when the selected identity does not exist on the node.
+var ErrUnknownIdentity = errors.New("identity does not exist")
+
+// Storage keeps the selected spending identity between node restarts.
+type Storage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+type identityProvider interface {
+	GetIdentities() []identity.Identity
+	HasIdentity(address string) bool
+}
+
+type registrationStatusProvider interface {
+	GetRegistrationStatus(chainID int64, id identity.Identity) (registry.RegistrationStatus, error)
+}
+
+type balanceProvider interface {
+	GetBalance(chainID int64, id identity.Identity) *big.Int
+}
+
+type sessionStatsProvider interface {
+	Stats(filter *session.Filter) (session.Stats, error)
+}
+
+// Entry represents the wallet state of a single consumer identity.
+type Entry struct {
+	Identity           identity.Identity
+	RegistrationStatus registry.RegistrationStatus
+	Balance            *big.Int
+	Spent              *big.Int
+	Sessions           int
+	Spending           bool
+}
+
+// Summary aggregates the wallet state of all local consumer identities.
+type Summary struct {
+	ChainID          int64
+	Entries          []Entry
+	Balance          *big.Int
+	Spent            *big.Int
+	Registered       int
+	SpendingIdentity *identity.Identity
+}
+
+// Wallet aggregates balances, registrations and spending across local consumer identities.
+type Wallet struct {
+	storage    Storage
+	identities identityProvider
+	registry   registrationStatusProvider
+	balance    balanceProvider
+	sessions   sessionStatsProvider
+
+	mu       sync.Mutex
+	spending *identity.Identity
+}
+
+// NewWallet returns a new wallet of local consumer identities.
+func NewWallet(storage Storage, identities identityProvider, registry registrationStatusProvider, balance balanceProvider, sessions sessionStatsProvider) *Wallet {
+	return &Wallet{
+		storage:    storage,
+		identities: identities,
+		registry:   registry,
+		balance:    balance,
+		sessions:   sessions,
+	}
+}
+
+// Summary returns the wallet state of every local identity on the given chain.
+func (w *Wallet) Summary(chainID int64) (Summary, error) {
+	spending, hasSpending := w.SpendingIdentity()
+
+	summary := Summary{
+		ChainID: chainID,
+		Balance: new(big.Int),
+		Spent:   new(big.Int),
+	}
+	if hasSpending {
+		summary.SpendingIdentity = &spending
+	}
+
+	for _, id := range w.identities.GetIdentities() {
+		status, err := w.registry.GetRegistrationStatus(chainID, id)
+		if err != nil {
+			return Summary{}, fmt.Errorf("could not get registration status of %q: %w", id.Address, err)
+		}
+
+		stats, err := w.sessions.Stats(session.NewFilter().
+			SetDirection(session.DirectionConsumed).
+			SetConsumerID(id))
+		if err != nil {
+			return Summary{}, fmt.Errorf("could not get spending of %q: %w", id.Address, err)
+		}
+
+		entry := Entry{
+			Identity:           id,
+			RegistrationStatus: status,
+			Balance:            new(big.Int),
+			Spent:              new(big.Int),
+			Sessions:           stats.Count,
+			Spending:           hasSpending && spending == id,
+		}
+		if balance := w.balance.GetBalance(chainID, id); balance != nil {
+			entry.Balance.Set(balance)
+		}
+		if stats.SumTokens != nil {
+			entry.Spent.Set(stats.SumTokens)
+		}
+
+		if status.Registered() {
+			summary.Registered++
+		}
+		summary.Balance.Add(summary.Balance, entry.Balance)
+		summary.Spent.Add(summary.Spent, entry.Spent)
+		summary.Entries = append(summary.Entries, entry)
+	}
+
+	return summary, nil
+}
+
+// SpendingIdentity returns the identity new connections spend from when none is requested explicitly.
+func (w *Wallet) SpendingIdentity() (identity.Identity, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.spending == nil {
+		var address string
+		if err := w.storage.GetValue(bucketName, spendingIdentityKey, &address); err != nil || address == "" {
+			return identity.Identity{}, false
+		}
+		id := identity.FromAddress(address)
+		w.spending = &id
+	}
+
+	if !w.identities.HasIdentity(w.spending.Address) {
+		return identity.Identity{}, false
+	}
+
+	return *w.spending, true
+}
+
+// SetSpendingIdentity selects the identity new connections spend from, empty address clears the selection.
+func (w *Wallet) SetSpendingIdentity(address string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var id *identity.Identity
+	if address != "" {
+		if !w.identities.HasIdentity(address) {
+			return ErrUnknownIdentity
+		}
+		selected := identity.FromAddress(address)
+		id = &selected
+		address = selected.Address
+	}
+
+	if err := w.storage.SetValue(bucketName, spendingIdentityKey, address); err != nil {
+		return fmt.Errorf("could not store spending identity: %w", err)
+	}
+	w.spending = id
+
+	return nil
+}
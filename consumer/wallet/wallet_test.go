@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+var (
+	id1 = identity.FromAddress("0x1")
+	id2 = identity.FromAddress("0x2")
+)
+
+func newTestWallet(storage Storage) *Wallet {
+	return NewWallet(
+		storage,
+		&mockIdentities{ids: []identity.Identity{id1, id2}},
+		&mockRegistry{statuses: map[identity.Identity]registry.RegistrationStatus{id1: registry.Registered, id2: registry.Unregistered}},
+		&mockBalance{balances: map[identity.Identity]*big.Int{id1: big.NewInt(100), id2: big.NewInt(20)}},
+		&mockSessions{spent: map[identity.Identity]*big.Int{id1: big.NewInt(7)}},
+	)
+}
+
+func TestWallet_Summary(t *testing.T) {
+	w := newTestWallet(newMockStorage())
+
+	summary, err := w.Summary(1)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), summary.ChainID)
+	assert.Equal(t, big.NewInt(120), summary.Balance)
+	assert.Equal(t, big.NewInt(7), summary.Spent)
+	assert.Equal(t, 1, summary.Registered)
+	assert.Nil(t, summary.SpendingIdentity)
+	require.Len(t, summary.Entries, 2)
+	assert.Equal(t, Entry{
+		Identity:           id1,
+		RegistrationStatus: registry.Registered,
+		Balance:            big.NewInt(100),
+		Spent:              big.NewInt(7),
+		Sessions:           1,
+	}, summary.Entries[0])
+	assert.Equal(t, Entry{
+		Identity:           id2,
+		RegistrationStatus: registry.Unregistered,
+		Balance:            big.NewInt(20),
+		Spent:              new(big.Int),
+	}, summary.Entries[1])
+}
+
+func TestWallet_SpendingIdentity(t *testing.T) {
+	storage := newMockStorage()
+	w := newTestWallet(storage)
+
+	_, ok := w.SpendingIdentity()
+	assert.False(t, ok)
+
+	assert.ErrorIs(t, w.SetSpendingIdentity("0x3"), ErrUnknownIdentity)
+
+	require.NoError(t, w.SetSpendingIdentity("0x2"))
+	id, ok := w.SpendingIdentity()
+	assert.True(t, ok)
+	assert.Equal(t, id2, id)
+
+	summary, err := w.Summary(1)
+	require.NoError(t, err)
+	assert.Equal(t, &id2, summary.SpendingIdentity)
+	assert.False(t, summary.Entries[0].Spending)
+	assert.True(t, summary.Entries[1].Spending)
+
+	id, ok = newTestWallet(storage).SpendingIdentity()
+	assert.True(t, ok, "selection should survive restarts")
+	assert.Equal(t, id2, id)
+
+	require.NoError(t, w.SetSpendingIdentity(""))
+	_, ok = w.SpendingIdentity()
+	assert.False(t, ok)
+}
+
+type mockIdentities struct {
+	ids []identity.Identity
+}
+
+func (m *mockIdentities) GetIdentities() []identity.Identity {
+	return m.ids
+}
+
+func (m *mockIdentities) HasIdentity(address string) bool {
+	for _, id := range m.ids {
+		if id == identity.FromAddress(address) {
+			return true
+		}
+	}
+	return false
+}
+
+type mockRegistry struct {
+	statuses map[identity.Identity]registry.RegistrationStatus
+}
+
+func (m *mockRegistry) GetRegistrationStatus(_ int64, id identity.Identity) (registry.RegistrationStatus, error) {
+	return m.statuses[id], nil
+}
+
+type mockBalance struct {
+	balances map[identity.Identity]*big.Int
+}
+
+func (m *mockBalance) GetBalance(_ int64, id identity.Identity) *big.Int {
+	return m.balances[id]
+}
+
+type mockSessions struct {
+	spent map[identity.Identity]*big.Int
+}
+
+func (m *mockSessions) Stats(filter *session.Filter) (session.Stats, error) {
+	spent, ok := m.spent[*filter.ConsumerID]
+	if !ok {
+		return session.Stats{}, nil
+	}
+	return session.Stats{Count: 1, SumTokens: spent}, nil
+}
+
+type mockStorage struct {
+	values map[interface{}][]byte
+}
+
+func newMockStorage() *mockStorage {
+	return &mockStorage{values: make(map[interface{}][]byte)}
+}
+
+func (s *mockStorage) GetValue(bucket string, key interface{}, to interface{}) error {
+	value, ok := s.values[key]
+	if !ok {
+		return errors.New("not found")
+	}
+	return json.Unmarshal(value, to)
+}
+
+func (s *mockStorage) SetValue(bucket string, key interface{}, to interface{}) error {
+	value, err := json.Marshal(to)
+	s.values[key] = value
+	return err
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package accesslog
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"session_id", "consumer", "country", "service_type", "started_at", "duration_seconds", "bytes_sent", "bytes_received"}
+
+// WriteCSV writes the entries as a flat CSV table, one entry per row.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		err := cw.Write([]string{
+			e.SessionID,
+			e.Consumer,
+			e.Country,
+			e.ServiceType,
+			e.StartedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(int64(e.Duration.Seconds()), 10),
+			strconv.FormatUint(e.BytesSent, 10),
+			strconv.FormatUint(e.BytesReceived, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
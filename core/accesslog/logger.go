@@ -0,0 +1,316 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package accesslog
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+const (
+	bucketName = "provider-access-log"
+	keyBucket  = "provider-access-log-key"
+	keyName    = "hash-key"
+	// pruneInterval is how often the expired entries are removed.
+	pruneInterval = time.Hour
+	// hashLength is the number of hex characters of the consumer hash kept in the log.
+	hashLength = 32
+)
+
+// Anonymization defines how consumer identities are recorded in the access log.
+type Anonymization string
+
+const (
+	// AnonymizationNone records consumer identities as is.
+	AnonymizationNone Anonymization = "none"
+	// AnonymizationHash records a hash of the consumer identity keyed by a secret of the node,
+	// so connections of the same consumer can be correlated while the identity can't be recovered from the log.
+	AnonymizationHash Anonymization = "hash"
+	// AnonymizationStrict omits consumer identities.
+	AnonymizationStrict Anonymization = "strict"
+)
+
+// ParseAnonymization parses the anonymization level, unknown levels fall back to the hash.
+func ParseAnonymization(level string) Anonymization {
+	switch a := Anonymization(strings.ToLower(level)); a {
+	case AnonymizationNone, AnonymizationHash, AnonymizationStrict:
+		return a
+	default:
+		log.Warn().Msgf("Unknown access log anonymization level %q, using %q", level, AnonymizationHash)
+		return AnonymizationHash
+	}
+}
+
+// Entry describes a single consumer connection served by the provider.
+type Entry struct {
+	ID            int    `storm:"id,increment"`
+	SessionID     string `storm:"index"`
+	Consumer      string `storm:"index"`
+	Country       string `storm:"index"`
+	ServiceType   string
+	StartedAt     time.Time `storm:"index"`
+	Duration      time.Duration
+	BytesSent     uint64
+	BytesReceived uint64
+}
+
+// Filter narrows down the listed entries.
+type Filter struct {
+	// ConsumerID is anonymized the same way as the stored entries before matching.
+	ConsumerID string
+	Country    string
+	From       *time.Time
+	To         *time.Time
+}
+
+// Logger records consumer connections served by the provider.
+type Logger struct {
+	storage       *boltdb.Bolt
+	retention     time.Duration
+	anonymization Anonymization
+	now           func() time.Time
+
+	lock   sync.Mutex
+	key    []byte
+	active map[string]*Entry
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewLogger creates the access log. Entries older than retention are removed, zero keeps them forever.
+func NewLogger(storage *boltdb.Bolt, retention time.Duration, anonymization Anonymization) *Logger {
+	return &Logger{
+		storage:       storage,
+		retention:     retention,
+		anonymization: anonymization,
+		now:           time.Now,
+		active:        make(map[string]*Entry),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start removes the expired entries and keeps removing them periodically until stopped.
+func (l *Logger) Start() {
+	if l.retention <= 0 {
+		return
+	}
+
+	go func() {
+		for {
+			l.pruneExpired()
+			select {
+			case <-l.stop:
+				return
+			case <-time.After(pruneInterval):
+			}
+		}
+	}()
+}
+
+// Stop stops removing the expired entries.
+func (l *Logger) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+}
+
+// Anonymization returns how consumer identities are recorded.
+func (l *Logger) Anonymization() Anonymization {
+	return l.anonymization
+}
+
+// Subscribe subscribes access log to provider session events.
+func (l *Logger) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(event.AppTopicSession, l.handleSession); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(event.AppTopicDataTransferred, l.handleDataTransferred)
+}
+
+func (l *Logger) handleSession(e event.AppEventSession) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	switch e.Status {
+	case event.CreatedStatus:
+		consumer, err := l.anonymize(e.Session.ConsumerID.Address)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to anonymize consumer of the access log entry")
+			return
+		}
+		l.active[e.Session.ID] = &Entry{
+			SessionID:   e.Session.ID,
+			Consumer:    consumer,
+			Country:     e.Session.ConsumerLocation.Country,
+			ServiceType: e.Session.Proposal.ServiceType,
+			StartedAt:   e.Session.StartedAt.UTC(),
+		}
+	case event.RemovedStatus:
+		entry, ok := l.active[e.Session.ID]
+		if !ok {
+			return
+		}
+		delete(l.active, e.Session.ID)
+
+		entry.Duration = l.now().Sub(entry.StartedAt).Truncate(time.Second)
+		if err := l.store(entry); err != nil {
+			log.Warn().Err(err).Msgf("Failed to store access log entry of session %s", e.Session.ID)
+		}
+	}
+}
+
+func (l *Logger) handleDataTransferred(e event.AppEventDataTransferred) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	entry, ok := l.active[e.ID]
+	if !ok {
+		return
+	}
+	entry.BytesSent = e.Down
+	entry.BytesReceived = e.Up
+}
+
+func (l *Logger) store(entry *Entry) error {
+	defer l.storage.Track("save", bucketName, nil)()
+	l.storage.Lock()
+	defer l.storage.Unlock()
+
+	return l.storage.DB().From(bucketName).Save(entry)
+}
+
+// List returns the entries matching the filter, most recent first.
+// Expired entries are left out even if they are not removed yet.
+func (l *Logger) List(filter Filter) ([]Entry, error) {
+	var matchers []q.Matcher
+	if filter.ConsumerID != "" {
+		l.lock.Lock()
+		consumer, err := l.anonymize(filter.ConsumerID)
+		l.lock.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if consumer == "" {
+			return []Entry{}, nil
+		}
+		matchers = append(matchers, q.Eq("Consumer", consumer))
+	}
+	if filter.Country != "" {
+		matchers = append(matchers, q.Eq("Country", strings.ToUpper(filter.Country)))
+	}
+	if filter.From != nil {
+		matchers = append(matchers, q.Gte("StartedAt", *filter.From))
+	}
+	if filter.To != nil {
+		matchers = append(matchers, q.Lte("StartedAt", *filter.To))
+	}
+	if l.retention > 0 {
+		matchers = append(matchers, q.Gte("StartedAt", l.now().Add(-l.retention)))
+	}
+
+	defer l.storage.Track("list", bucketName, nil)()
+	l.storage.RLock()
+	defer l.storage.RUnlock()
+
+	var result []Entry
+	err := l.storage.DB().
+		From(bucketName).
+		Select(matchers...).
+		OrderBy("StartedAt").
+		Reverse().
+		Find(&result)
+	if errors.Is(err, storm.ErrNotFound) {
+		return []Entry{}, nil
+	}
+	return result, err
+}
+
+func (l *Logger) pruneExpired() {
+	if err := l.prune(l.now().Add(-l.retention)); err != nil {
+		log.Warn().Err(err).Msg("Failed to remove expired access log entries")
+	}
+}
+
+func (l *Logger) prune(before time.Time) error {
+	defer l.storage.Track("prune", bucketName, nil)()
+	l.storage.Lock()
+	defer l.storage.Unlock()
+
+	err := l.storage.DB().From(bucketName).Select(q.Lt("StartedAt", before)).Delete(&Entry{})
+	if errors.Is(err, storm.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (l *Logger) anonymize(consumerID string) (string, error) {
+	consumerID = strings.ToLower(consumerID)
+	switch l.anonymization {
+	case AnonymizationNone:
+		return consumerID, nil
+	case AnonymizationStrict:
+		return "", nil
+	}
+
+	key, err := l.loadKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(consumerID))
+	return hex.EncodeToString(mac.Sum(nil))[:hashLength], nil
+}
+
+func (l *Logger) loadKey() ([]byte, error) {
+	if l.key != nil {
+		return l.key, nil
+	}
+
+	var key []byte
+	if err := l.storage.GetValue(keyBucket, keyName, &key); err == nil && len(key) == sha256.Size {
+		l.key = key
+		return l.key, nil
+	}
+
+	key = make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("could not generate access log hash key: %w", err)
+	}
+	if err := l.storage.SetValue(keyBucket, keyName, key); err != nil {
+		return nil, fmt.Errorf("could not store access log hash key: %w", err)
+	}
+	l.key = key
+
+	return l.key, nil
+}
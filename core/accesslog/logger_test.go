@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package accesslog
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+var now = time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func TestLogger_RecordsSessions(t *testing.T) {
+	logger, cleanup := newLogger(t, 0, AnonymizationNone)
+	defer cleanup()
+
+	serveSession(logger, "s1", "0xAA", "LT", now.Add(-time.Hour))
+
+	entries, err := logger.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "s1", entries[0].SessionID)
+	assert.Equal(t, "0xaa", entries[0].Consumer)
+	assert.Equal(t, "LT", entries[0].Country)
+	assert.Equal(t, "wireguard", entries[0].ServiceType)
+	assert.Equal(t, time.Hour, entries[0].Duration)
+	assert.Equal(t, uint64(20), entries[0].BytesSent)
+	assert.Equal(t, uint64(10), entries[0].BytesReceived)
+}
+
+func TestLogger_Anonymization(t *testing.T) {
+	logger, cleanup := newLogger(t, 0, AnonymizationHash)
+	defer cleanup()
+
+	serveSession(logger, "s1", "0xaa", "LT", now.Add(-time.Hour))
+	serveSession(logger, "s2", "0xAA", "DE", now.Add(-time.Minute))
+	serveSession(logger, "s3", "0xbb", "LT", now.Add(-time.Minute))
+
+	entries, err := logger.List(Filter{ConsumerID: "0xaa"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "s2", entries[0].SessionID)
+	assert.Equal(t, "s1", entries[1].SessionID)
+	assert.Len(t, entries[0].Consumer, hashLength)
+	assert.NotEqual(t, "0xaa", entries[0].Consumer)
+	assert.Equal(t, entries[0].Consumer, entries[1].Consumer)
+
+	entries, err = logger.List(Filter{Country: "lt"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.NotEqual(t, entries[0].Consumer, entries[1].Consumer)
+
+	strict := NewLogger(logger.storage, 0, AnonymizationStrict)
+	strict.now = logger.now
+	serveSession(strict, "s4", "0xaa", "LT", now.Add(-time.Second))
+
+	entries, err = strict.List(Filter{Country: "LT"})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "", entries[0].Consumer)
+}
+
+func TestLogger_RemovesExpiredEntries(t *testing.T) {
+	logger, cleanup := newLogger(t, time.Hour, AnonymizationStrict)
+	defer cleanup()
+
+	serveSession(logger, "s1", "0xaa", "LT", now.Add(-2*time.Hour))
+	serveSession(logger, "s2", "0xaa", "LT", now.Add(-time.Minute))
+
+	logger.Start()
+	defer logger.Stop()
+
+	assert.Eventually(t, func() bool {
+		var entries []Entry
+		err := logger.storage.DB().From(bucketName).All(&entries)
+		return err == nil && len(entries) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestLogger_ListSkipsExpiredEntries(t *testing.T) {
+	logger, cleanup := newLogger(t, time.Hour, AnonymizationStrict)
+	defer cleanup()
+
+	serveSession(logger, "s1", "0xaa", "LT", now.Add(-2*time.Hour))
+	serveSession(logger, "s2", "0xaa", "LT", now.Add(-time.Minute))
+
+	entries, err := logger.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "s2", entries[0].SessionID)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []Entry{{
+		SessionID:     "s1",
+		Consumer:      "0xaa",
+		Country:       "LT",
+		ServiceType:   "wireguard",
+		StartedAt:     now,
+		Duration:      time.Minute,
+		BytesSent:     20,
+		BytesReceived: 10,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "session_id,consumer,country,service_type,started_at,duration_seconds,bytes_sent,bytes_received\n"+
+		"s1,0xaa,LT,wireguard,2022-10-01T12:00:00Z,60,20,10\n", buf.String())
+}
+
+func serveSession(logger *Logger, sessionID, consumerID, country string, startedAt time.Time) {
+	session := event.SessionContext{
+		ID:               sessionID,
+		StartedAt:        startedAt,
+		ConsumerID:       identity.FromAddress(consumerID),
+		ConsumerLocation: market.Location{Country: country},
+		Proposal:         market.ServiceProposal{ServiceType: "wireguard"},
+	}
+	logger.handleSession(event.AppEventSession{Status: event.CreatedStatus, Session: session})
+	logger.handleDataTransferred(event.AppEventDataTransferred{ID: sessionID, Up: 10, Down: 20})
+	logger.handleSession(event.AppEventSession{Status: event.RemovedStatus, Session: session})
+}
+
+func newLogger(t *testing.T, retention time.Duration, anonymization Anonymization) (*Logger, func()) {
+	dir, err := os.MkdirTemp("", "accessLogTest")
+	require.NoError(t, err)
+
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+
+	logger := NewLogger(db, retention, anonymization)
+	logger.now = func() time.Time { return now }
+	return logger, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
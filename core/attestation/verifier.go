@@ -0,0 +1,171 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package attestation
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+var (
+	// ErrTokenRequired is returned when a service requires attestation but consumer sent no token.
+	ErrTokenRequired = errors.New("consumer attestation token is required")
+	// ErrTokenRejected is returned when the attestation service rejects the consumer token.
+	ErrTokenRejected = errors.New("consumer attestation token rejected")
+)
+
+type attestationRequest struct {
+	ConsumerID  string `json:"consumer_id"`
+	ServiceType string `json:"service_type"`
+	Token       string `json:"token"`
+}
+
+type attestationResponse struct {
+	Valid  bool   `json:"valid"`
+	Reason string `json:"reason"`
+}
+
+type cacheKey struct {
+	consumerID  identity.Identity
+	serviceType string
+	token       string
+}
+
+type cachedResult struct {
+	valid   bool
+	reason  string
+	expires time.Time
+}
+
+// Verifier consults an external attestation service to verify consumer attribute tokens
+// before sessions of the configured service types are granted.
+type Verifier struct {
+	client   *requests.HTTPClient
+	address  string
+	services map[string]struct{}
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	cache map[cacheKey]cachedResult
+}
+
+// NewVerifier returns a new attestation verifier.
+// Attestation is not required for any service when the address or service types are empty.
+func NewVerifier(client *requests.HTTPClient, address string, serviceTypes []string, cacheTTL time.Duration) *Verifier {
+	services := make(map[string]struct{}, len(serviceTypes))
+	for _, serviceType := range serviceTypes {
+		if serviceType != "" {
+			services[serviceType] = struct{}{}
+		}
+	}
+
+	return &Verifier{
+		client:   client,
+		address:  address,
+		services: services,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		cache:    make(map[cacheKey]cachedResult),
+	}
+}
+
+// Required checks whether sessions of the given service type require consumer attestation.
+func (v *Verifier) Required(serviceType string) bool {
+	if v.address == "" {
+		return false
+	}
+
+	_, ok := v.services[serviceType]
+	return ok
+}
+
+// Attest verifies the consumer token for the given service type.
+// Definitive results of the attestation service are cached, transport errors are not.
+func (v *Verifier) Attest(consumerID identity.Identity, serviceType, token string) error {
+	if !v.Required(serviceType) {
+		return nil
+	}
+	if token == "" {
+		return ErrTokenRequired
+	}
+
+	key := cacheKey{consumerID: consumerID, serviceType: serviceType, token: token}
+	result, ok := v.cached(key)
+	if !ok {
+		res, err := v.request(key)
+		if err != nil {
+			return fmt.Errorf("could not verify consumer attestation: %w", err)
+		}
+
+		result = cachedResult{valid: res.Valid, reason: res.Reason, expires: v.now().Add(v.cacheTTL)}
+		v.store(key, result)
+	}
+
+	if !result.valid {
+		log.Info().Msgf("Attestation of consumer %s for %s rejected: %s", consumerID.Address, serviceType, result.reason)
+		return ErrTokenRejected
+	}
+	return nil
+}
+
+func (v *Verifier) request(key cacheKey) (attestationResponse, error) {
+	req, err := requests.NewPostRequest(v.address, "", attestationRequest{
+		ConsumerID:  key.consumerID.Address,
+		ServiceType: key.serviceType,
+		Token:       key.token,
+	})
+	if err != nil {
+		return attestationResponse{}, err
+	}
+
+	var res attestationResponse
+	err = v.client.DoRequestAndParseResponse(req, &res)
+	return res, err
+}
+
+func (v *Verifier) cached(key cacheKey) (cachedResult, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	result, ok := v.cache[key]
+	if !ok || v.now().After(result.expires) {
+		return cachedResult{}, false
+	}
+	return result, true
+}
+
+func (v *Verifier) store(key cacheKey, result cachedResult) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := v.now()
+	for k, cached := range v.cache {
+		if now.After(cached.expires) {
+			delete(v.cache, k)
+		}
+	}
+	v.cache[key] = result
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package attestation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+var consumerID = identity.FromAddress("0x1")
+
+func mockAttestationServer(t *testing.T, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		var req attestationRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, consumerID.Address, req.ConsumerID)
+		assert.Equal(t, "wireguard", req.ServiceType)
+
+		json.NewEncoder(w).Encode(attestationResponse{Valid: req.Token == "valid", Reason: "unknown token"})
+	}))
+}
+
+func TestVerifier_Attest(t *testing.T) {
+	var calls int32
+	server := mockAttestationServer(t, &calls)
+	defer server.Close()
+
+	verifier := NewVerifier(requests.NewHTTPClient("0.0.0.0", time.Second), server.URL, []string{"wireguard"}, time.Minute)
+
+	assert.NoError(t, verifier.Attest(consumerID, "scraping", ""))
+	assert.Equal(t, ErrTokenRequired, verifier.Attest(consumerID, "wireguard", ""))
+	assert.NoError(t, verifier.Attest(consumerID, "wireguard", "valid"))
+	assert.Equal(t, ErrTokenRejected, verifier.Attest(consumerID, "wireguard", "invalid"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestVerifier_Attest_CachesResults(t *testing.T) {
+	var calls int32
+	server := mockAttestationServer(t, &calls)
+	defer server.Close()
+
+	now := time.Now()
+	verifier := NewVerifier(requests.NewHTTPClient("0.0.0.0", time.Second), server.URL, []string{"wireguard"}, time.Minute)
+	verifier.now = func() time.Time { return now }
+
+	assert.NoError(t, verifier.Attest(consumerID, "wireguard", "valid"))
+	assert.NoError(t, verifier.Attest(consumerID, "wireguard", "valid"))
+	assert.Equal(t, ErrTokenRejected, verifier.Attest(consumerID, "wireguard", "invalid"))
+	assert.Equal(t, ErrTokenRejected, verifier.Attest(consumerID, "wireguard", "invalid"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, verifier.Attest(consumerID, "wireguard", "valid"))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestVerifier_Attest_DoesNotCacheErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	verifier := NewVerifier(requests.NewHTTPClient("0.0.0.0", time.Second), server.URL, []string{"wireguard"}, time.Minute)
+
+	assert.Error(t, verifier.Attest(consumerID, "wireguard", "valid"))
+	assert.Empty(t, verifier.cache)
+}
+
+func TestVerifier_Required(t *testing.T) {
+	assert.False(t, NewVerifier(nil, "", []string{"wireguard"}, time.Minute).Required("wireguard"))
+	assert.False(t, NewVerifier(nil, "http://attestation", nil, time.Minute).Required("wireguard"))
+	assert.True(t, NewVerifier(nil, "http://attestation", []string{"wireguard"}, time.Minute).Required("wireguard"))
+}
@@ -0,0 +1,268 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ServiceTypeNoop is the baseline benchmark of the plain loopback TCP connection without any tunnel.
+const ServiceTypeNoop = "noop"
+
+const (
+	// DefaultDuration is the default time the traffic is driven through each data plane.
+	DefaultDuration = 10 * time.Second
+	// DefaultStreams is the default number of concurrent streams.
+	DefaultStreams = 4
+
+	chunkSize = 32 * 1024
+)
+
+// Tunnel is a loopback data plane of the service carrying the traffic from the local consumer to the local provider.
+type Tunnel interface {
+	// Dial opens a stream on the consumer side of the tunnel.
+	Dial(ctx context.Context) (net.Conn, error)
+	// Listener accepts the streams on the provider side of the tunnel.
+	Listener() net.Listener
+	// Close tears down both ends of the tunnel.
+	Close() error
+}
+
+// TunnelFactory creates a loopback tunnel of the service type.
+type TunnelFactory func() (Tunnel, error)
+
+// Result is the outcome of a single service type benchmark.
+type Result struct {
+	ServiceType string
+	Duration    time.Duration
+	Bytes       uint64
+	// CPUTime is the processor time spent by both ends of the tunnel.
+	CPUTime time.Duration
+	Error   string
+}
+
+// ThroughputMbps returns the achieved throughput in megabits per second.
+func (r Result) ThroughputMbps() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+	return float64(r.Bytes) * 8 / 1e6 / r.Duration.Seconds()
+}
+
+// CPUPerGB returns the processor time spent to carry a gigabyte of traffic.
+func (r Result) CPUPerGB() time.Duration {
+	if r.Bytes == 0 {
+		return 0
+	}
+	return time.Duration(float64(r.CPUTime) * 1e9 / float64(r.Bytes))
+}
+
+// Harness drives synthetic traffic through the loopback data planes of the services.
+type Harness struct {
+	duration time.Duration
+	streams  int
+
+	factories map[string]TunnelFactory
+}
+
+// New returns a new benchmark harness with the noop baseline registered.
+func New(duration time.Duration, streams int) *Harness {
+	h := &Harness{
+		duration:  duration,
+		streams:   streams,
+		factories: make(map[string]TunnelFactory),
+	}
+	h.Register(ServiceTypeNoop, newLoopbackTCP)
+	return h
+}
+
+// Register registers the loopback tunnel factory of the service type.
+func (h *Harness) Register(serviceType string, factory TunnelFactory) {
+	h.factories[serviceType] = factory
+}
+
+// ServiceTypes returns the service types which can be benchmarked.
+func (h *Harness) ServiceTypes() []string {
+	types := make([]string, 0, len(h.factories))
+	for serviceType := range h.factories {
+		types = append(types, serviceType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Run benchmarks the given service types one after another, all registered ones if none are given.
+func (h *Harness) Run(ctx context.Context, serviceTypes ...string) []Result {
+	if len(serviceTypes) == 0 {
+		serviceTypes = h.ServiceTypes()
+	}
+
+	results := make([]Result, 0, len(serviceTypes))
+	for _, serviceType := range serviceTypes {
+		result, err := h.run(ctx, serviceType)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.ServiceType = serviceType
+		results = append(results, result)
+	}
+	return results
+}
+
+func (h *Harness) run(ctx context.Context, serviceType string) (Result, error) {
+	factory, ok := h.factories[serviceType]
+	if !ok {
+		return Result{}, fmt.Errorf("benchmark is not supported for service type %q", serviceType)
+	}
+
+	tunnel, err := factory()
+	if err != nil {
+		return Result{}, fmt.Errorf("could not create loopback tunnel: %w", err)
+	}
+	defer func() {
+		if err := tunnel.Close(); err != nil {
+			log.Warn().Err(err).Msgf("Could not close %s loopback tunnel", serviceType)
+		}
+	}()
+
+	var received uint64
+	go sink(tunnel.Listener(), &received)
+
+	ctx, cancel := context.WithTimeout(ctx, h.duration)
+	defer cancel()
+
+	cpuStart, err := processCPUTime()
+	if err != nil {
+		return Result{}, fmt.Errorf("could not measure CPU time: %w", err)
+	}
+	start := time.Now()
+
+	errs := make(chan error, h.streams)
+	var wg sync.WaitGroup
+	for i := 0; i < h.streams; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := drive(ctx, tunnel); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	result := Result{
+		Duration: time.Since(start),
+		Bytes:    atomic.LoadUint64(&received),
+	}
+	cpuEnd, err := processCPUTime()
+	if err != nil {
+		return result, fmt.Errorf("could not measure CPU time: %w", err)
+	}
+	result.CPUTime = cpuEnd - cpuStart
+
+	if err, failed := <-errs; failed {
+		return result, err
+	}
+	return result, nil
+}
+
+// drive writes the synthetic traffic into a new stream until the context is done.
+func drive(ctx context.Context, tunnel Tunnel) error {
+	conn, err := tunnel.Dial(ctx)
+	if err != nil {
+		return fmt.Errorf("could not open stream: %w", err)
+	}
+	defer conn.Close()
+
+	go func() {
+		<-ctx.Done()
+		conn.SetWriteDeadline(time.Now())
+	}()
+
+	chunk := make([]byte, chunkSize)
+	for ctx.Err() == nil {
+		if _, err := conn.Write(chunk); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("could not write to stream: %w", err)
+		}
+	}
+	return nil
+}
+
+// sink counts the bytes received on the provider side of the tunnel.
+func sink(listener net.Listener, received *uint64) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			buf := make([]byte, chunkSize)
+			for {
+				n, err := conn.Read(buf)
+				atomic.AddUint64(received, uint64(n))
+				if err != nil {
+					if err != io.EOF {
+						log.Trace().Err(err).Msg("Benchmark stream closed")
+					}
+					return
+				}
+			}
+		}()
+	}
+}
+
+type loopbackTCP struct {
+	listener net.Listener
+}
+
+func newLoopbackTCP() (Tunnel, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	return &loopbackTCP{listener: listener}, nil
+}
+
+func (t *loopbackTCP) Dial(ctx context.Context) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", t.listener.Addr().String())
+}
+
+func (t *loopbackTCP) Listener() net.Listener {
+	return t.listener
+}
+
+func (t *loopbackTCP) Close() error {
+	return t.listener.Close()
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHarness_RunNoop(t *testing.T) {
+	harness := New(200*time.Millisecond, 2)
+
+	results := harness.Run(context.Background(), ServiceTypeNoop)
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, ServiceTypeNoop, results[0].ServiceType)
+	assert.Empty(t, results[0].Error)
+	assert.NotZero(t, results[0].Bytes)
+	assert.NotZero(t, results[0].ThroughputMbps())
+}
+
+func TestHarness_RunUnknownServiceType(t *testing.T) {
+	harness := New(200*time.Millisecond, 1)
+
+	results := harness.Run(context.Background(), "unknown")
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, "unknown", results[0].ServiceType)
+	assert.Equal(t, `benchmark is not supported for service type "unknown"`, results[0].Error)
+}
+
+func TestHarness_ServiceTypes(t *testing.T) {
+	harness := New(time.Second, 1)
+	harness.Register("wireguard", newLoopbackTCP)
+
+	assert.Equal(t, []string{"noop", "wireguard"}, harness.ServiceTypes())
+}
+
+func TestResult_CPUPerGB(t *testing.T) {
+	r := Result{Bytes: 500_000_000, CPUTime: time.Second}
+
+	assert.Equal(t, 2*time.Second, r.CPUPerGB())
+	assert.Zero(t, Result{}.CPUPerGB())
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+)
+
+type reconnectableClient interface {
+	paymentClient.AddressableEthClientGetter
+	Reconnect(connectTimeout time.Duration) error
+}
+
+// transportEthClient is a reconnectable chain RPC client dialing HTTP endpoints with a dedicated transport.
+type transportEthClient struct {
+	address   string
+	transport *http.Transport
+
+	mu     sync.Mutex
+	client *ethclient.Client
+}
+
+func newEthClient(address string, transport *http.Transport, connectTimeout time.Duration) (reconnectableClient, error) {
+	if transport == nil {
+		return paymentClient.NewReconnectableEthClient(address, connectTimeout)
+	}
+
+	client, err := dialEthClient(address, transport, connectTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("ethereum client failed to connect: %w", err)
+	}
+	return &transportEthClient{address: address, transport: transport, client: client}, nil
+}
+
+func dialEthClient(address string, transport *http.Transport, connectTimeout time.Duration) (*ethclient.Client, error) {
+	if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
+		ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+		defer cancel()
+		return ethclient.DialContext(ctx, address)
+	}
+
+	client, err := rpc.DialHTTPWithClient(address, &http.Client{Transport: transport})
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// Client returns the currently connected ethereum client.
+func (c *transportEthClient) Client() paymentClient.EtherClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.client
+}
+
+// Address returns the address of the RPC endpoint.
+func (c *transportEthClient) Address() string {
+	return c.address
+}
+
+// Reconnect creates new ethereum client and replaces the current one.
+func (c *transportEthClient) Reconnect(connectTimeout time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client, err := dialEthClient(c.address, c.transport, connectTimeout)
+	if err != nil {
+		return fmt.Errorf("ethereum client failed to dial: %w", err)
+	}
+
+	c.client.Close()
+	*c.client = *client
+
+	return nil
+}
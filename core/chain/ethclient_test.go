@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEthClient_UsesGivenTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x89"}`))
+	}))
+	defer server.Close()
+
+	var proxied int32
+	transport := &http.Transport{Proxy: func(*http.Request) (*url.URL, error) {
+		atomic.AddInt32(&proxied, 1)
+		return nil, nil
+	}}
+
+	client, err := newEthClient(server.URL, transport, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, server.URL, client.Address())
+
+	chainID, err := client.Client().ChainID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(137), chainID.Int64())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&proxied))
+
+	require.NoError(t, client.Reconnect(time.Second))
+	_, err = client.Client().ChainID(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&proxied))
+}
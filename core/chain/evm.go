@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chain
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+	psort "github.com/mysteriumnetwork/payments/client/sort"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/utils/retry"
+)
+
+// EVMBackend is the backend of an EVM compatible chain reached through a set of RPC endpoints.
+type EVMBackend struct {
+	definition metadata.ChainDefinition
+	clients    []reconnectableClient
+	multi      *paymentClient.EthMultiClient
+	sorter     *psort.MultiClientSorter
+	bc         paymentClient.BC
+}
+
+// NewEVMBackend connects to the RPC endpoints of the chain, the endpoints failing to connect are skipped.
+func NewEVMBackend(definition metadata.ChainDefinition, options Options) (Backend, error) {
+	log.Info().Msgf("Using chain %v Eth endpoints: %v", definition.ChainID, definition.EtherClientRPC)
+
+	backend := &EVMBackend{definition: definition}
+	clients := make([]paymentClient.AddressableEthClientGetter, 0)
+	for _, rpc := range definition.EtherClientRPC {
+		client, err := newEthClient(rpc, options.RPCTransport, time.Second*10)
+		if err != nil {
+			log.Warn().Msgf("failed to load rpc endpoint: %s", rpc)
+			continue
+		}
+		backend.clients = append(backend.clients, client)
+		clients = append(clients, client)
+	}
+
+	if len(clients) == 0 {
+		log.Error().Msgf("no rpc endpoints loaded for chain %v", definition.ChainID)
+	}
+
+	notifications := make(chan paymentClient.Notification, 5)
+	multi, err := paymentClient.NewEthMultiClientNotifyDown(time.Second*20, clients, notifications)
+	if err != nil {
+		return nil, err
+	}
+	backend.multi = multi
+	backend.sorter = psort.NewMultiClientSorterNoTicker(multi, notifications)
+	backend.sorter.AddOnNotificationAction(psort.DefaultByAvailability)
+	go backend.sorter.Run()
+
+	backend.bc = paymentClient.NewBlockchain(multi, options.BCTimeout)
+	return backend, nil
+}
+
+// Definition returns the chain id and the payment smart contracts deployed on the chain.
+func (b *EVMBackend) Definition() metadata.ChainDefinition {
+	return b.definition
+}
+
+// EtherClient returns the client of the chain RPC.
+func (b *EVMBackend) EtherClient() paymentClient.EtherClient {
+	return b.multi
+}
+
+// BC returns the client of the payment smart contracts.
+func (b *EVMBackend) BC() paymentClient.BC {
+	return b.bc
+}
+
+// AdjustFee keeps the quoted fee, it already covers the transaction costs of EVM chains.
+func (b *EVMBackend) AdjustFee(fee *big.Int) *big.Int {
+	return fee
+}
+
+// Reconnect reconnects the chain RPC clients.
+func (b *EVMBackend) Reconnect(timeout time.Duration) error {
+	var lastErr error
+	for _, cl := range b.clients {
+		// Default golang DNS resolver does not allow to reload /etc/resolv.conf more than once per 5 seconds.
+		// This could lead to the problem, when right after connect/disconnect new DNS config not applied instantly.
+		// Doing a couple of retries here to make sure we reconnected Ethererum client correctly.
+		// Default DNS timeout is 10 seconds. It's enough to try to reconnect only twice to cover 5 seconds lag for DNS config reload.
+		// https://github.com/mysteriumnetwork/node/issues/2282
+		cl := cl
+		err := retry.Do(context.Background(), retry.RPC, func() error {
+			err := cl.Reconnect(timeout)
+			if err != nil {
+				log.Warn().Err(err).Msg("Ethereum client failed to reconnect")
+			}
+			return err
+		})
+		if err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// Close releases the chain RPC clients.
+func (b *EVMBackend) Close() {
+	b.multi.Close()
+	b.sorter.Stop()
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+
+	"github.com/mysteriumnetwork/node/metadata"
+)
+
+// BackendEVM is the name of the backend used for EVM compatible chains, it is used when chain does not name one.
+const BackendEVM = "evm"
+
+// ErrUnsupportedBackend indicates that the chain names a backend which is not registered.
+var ErrUnsupportedBackend = errors.New("unsupported chain backend")
+
+// Backend is a blockchain the payments run on.
+// Payment code only talks to chains through backends, so supporting another chain does not touch it.
+type Backend interface {
+	// Definition returns the chain id and the payment smart contracts deployed on the chain.
+	Definition() metadata.ChainDefinition
+	// EtherClient returns the client of the chain RPC.
+	EtherClient() paymentClient.EtherClient
+	// BC returns the client of the payment smart contracts.
+	BC() paymentClient.BC
+	// AdjustFee adjusts the transactor fee quoted for the chain, e.g. to cover the L1 data costs of a rollup.
+	AdjustFee(fee *big.Int) *big.Int
+	// Reconnect reconnects the chain RPC clients after the network changes.
+	Reconnect(timeout time.Duration) error
+	// Close releases the chain RPC clients.
+	Close()
+}
+
+// Options are the options common to all chain backends.
+type Options struct {
+	BCTimeout time.Duration
+	// RPCTransport is used to reach HTTP RPC endpoints of the chain, the default transport is used if nil.
+	RPCTransport *http.Transport
+}
+
+// Factory creates the backend of the given chain.
+type Factory func(definition metadata.ChainDefinition, options Options) (Backend, error)
+
+// Registry holds the chains the node pays on and the factories of pluggable chain backends.
+type Registry struct {
+	lock      sync.Mutex
+	factories map[string]Factory
+	backends  []Backend
+}
+
+// NewRegistry creates a registry which supports EVM compatible chains.
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: map[string]Factory{
+			BackendEVM: NewEVMBackend,
+		},
+	}
+}
+
+// RegisterFactory registers a new pluggable chain backend.
+func (r *Registry) RegisterFactory(backend string, factory Factory) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.factories[backend] = factory
+}
+
+// Add creates the backend of the given chain and adds it to the registry.
+func (r *Registry) Add(definition metadata.ChainDefinition, options Options) (Backend, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, backend := range r.backends {
+		if backend.Definition().ChainID == definition.ChainID {
+			return nil, fmt.Errorf("chain %v is already added", definition.ChainID)
+		}
+	}
+
+	name := definition.Backend
+	if name == "" {
+		name = BackendEVM
+	}
+	factory, ok := r.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedBackend, name)
+	}
+
+	backend, err := factory(definition, options)
+	if err != nil {
+		return nil, fmt.Errorf("could not create backend of chain %v: %w", definition.ChainID, err)
+	}
+	r.backends = append(r.backends, backend)
+	return backend, nil
+}
+
+// Backend returns the backend of the given chain.
+func (r *Registry) Backend(chainID int64) (Backend, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, backend := range r.backends {
+		if backend.Definition().ChainID == chainID {
+			return backend, true
+		}
+	}
+	return nil, false
+}
+
+// Backends returns the backends of all chains in the order they were added.
+func (r *Registry) Backends() []Backend {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]Backend(nil), r.backends...)
+}
+
+// ChainIDs returns the ids of all chains in the order they were added.
+func (r *Registry) ChainIDs() []int64 {
+	backends := r.Backends()
+	ids := make([]int64, len(backends))
+	for i, backend := range backends {
+		ids[i] = backend.Definition().ChainID
+	}
+	return ids
+}
+
+// BlockchainClients returns the payment smart contract clients by the chain id.
+func (r *Registry) BlockchainClients() map[int64]paymentClient.BC {
+	clients := make(map[int64]paymentClient.BC)
+	for _, backend := range r.Backends() {
+		clients[backend.Definition().ChainID] = backend.BC()
+	}
+	return clients
+}
+
+// Addresses returns the payment smart contract addresses by the chain id.
+// Approved hermeses override the hermeses known from the chain definition.
+func (r *Registry) Addresses(approvedHermeses map[int64][]common.Address) map[int64]paymentClient.SmartContractAddresses {
+	addresses := make(map[int64]paymentClient.SmartContractAddresses)
+	for _, backend := range r.Backends() {
+		definition := backend.Definition()
+
+		knownHermeses, ok := approvedHermeses[definition.ChainID]
+		if !ok {
+			knownHermeses = make([]common.Address, len(definition.KnownHermeses))
+			for i, hermes := range definition.KnownHermeses {
+				knownHermeses[i] = common.HexToAddress(hermes)
+			}
+		}
+
+		addresses[definition.ChainID] = paymentClient.SmartContractAddresses{
+			Registry:                    common.HexToAddress(definition.RegistryAddress),
+			Myst:                        common.HexToAddress(definition.MystAddress),
+			ActiveHermes:                common.HexToAddress(definition.HermesID),
+			ActiveChannelImplementation: common.HexToAddress(definition.ChannelImplAddress),
+			KnownHermeses:               knownHermeses,
+		}
+	}
+	return addresses
+}
+
+// AdjustFee adjusts the transactor fee quoted for the given chain, fees of unknown chains are kept as is.
+func (r *Registry) AdjustFee(chainID int64, fee *big.Int) *big.Int {
+	backend, ok := r.Backend(chainID)
+	if !ok || fee == nil {
+		return fee
+	}
+	return backend.AdjustFee(fee)
+}
+
+// Close releases the backends of all chains.
+func (r *Registry) Close() {
+	for _, backend := range r.Backends() {
+		backend.Close()
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/metadata"
+)
+
+type rollupBackend struct {
+	definition metadata.ChainDefinition
+	closed     bool
+}
+
+func (b *rollupBackend) Definition() metadata.ChainDefinition   { return b.definition }
+func (b *rollupBackend) EtherClient() paymentClient.EtherClient { return nil }
+func (b *rollupBackend) BC() paymentClient.BC                   { return nil }
+func (b *rollupBackend) Reconnect(_ time.Duration) error        { return nil }
+func (b *rollupBackend) Close()                                 { b.closed = true }
+
+func (b *rollupBackend) AdjustFee(fee *big.Int) *big.Int {
+	return new(big.Int).Add(fee, big.NewInt(100))
+}
+
+func newRollupFactory(created *[]*rollupBackend) Factory {
+	return func(definition metadata.ChainDefinition, _ Options) (Backend, error) {
+		backend := &rollupBackend{definition: definition}
+		*created = append(*created, backend)
+		return backend, nil
+	}
+}
+
+func TestRegistry_AddsRegisteredBackends(t *testing.T) {
+	var created []*rollupBackend
+	registry := NewRegistry()
+	registry.RegisterFactory("rollup", newRollupFactory(&created))
+
+	_, err := registry.Add(metadata.ChainDefinition{ChainID: 1, Backend: "unknown"}, Options{})
+	assert.True(t, errors.Is(err, ErrUnsupportedBackend))
+
+	_, err = registry.Add(metadata.ChainDefinition{ChainID: 10, Backend: "rollup"}, Options{})
+	assert.NoError(t, err)
+	_, err = registry.Add(metadata.ChainDefinition{ChainID: 5, Backend: "rollup"}, Options{})
+	assert.NoError(t, err)
+	_, err = registry.Add(metadata.ChainDefinition{ChainID: 5, Backend: "rollup"}, Options{})
+	assert.Error(t, err)
+
+	assert.Equal(t, []int64{10, 5}, registry.ChainIDs())
+	assert.Len(t, registry.BlockchainClients(), 2)
+
+	backend, ok := registry.Backend(5)
+	assert.True(t, ok)
+	assert.Equal(t, int64(5), backend.Definition().ChainID)
+	_, ok = registry.Backend(1)
+	assert.False(t, ok)
+
+	registry.Close()
+	assert.Len(t, created, 2)
+	for _, backend := range created {
+		assert.True(t, backend.closed)
+	}
+}
+
+func TestRegistry_AdjustFee(t *testing.T) {
+	var created []*rollupBackend
+	registry := NewRegistry()
+	registry.RegisterFactory("rollup", newRollupFactory(&created))
+	_, err := registry.Add(metadata.ChainDefinition{ChainID: 10, Backend: "rollup"}, Options{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, big.NewInt(150), registry.AdjustFee(10, big.NewInt(50)))
+	assert.Equal(t, big.NewInt(50), registry.AdjustFee(1, big.NewInt(50)))
+	assert.Nil(t, registry.AdjustFee(10, nil))
+}
+
+func TestRegistry_Addresses(t *testing.T) {
+	var created []*rollupBackend
+	registry := NewRegistry()
+	registry.RegisterFactory("rollup", newRollupFactory(&created))
+	for _, definition := range []metadata.ChainDefinition{
+		{ChainID: 10, Backend: "rollup", RegistryAddress: "0x1", HermesID: "0x2", ChannelImplAddress: "0x3", MystAddress: "0x4", KnownHermeses: []string{"0x2"}},
+		{ChainID: 5, Backend: "rollup", KnownHermeses: []string{"0x5"}},
+	} {
+		_, err := registry.Add(definition, Options{})
+		assert.NoError(t, err)
+	}
+
+	addresses := registry.Addresses(map[int64][]common.Address{5: {common.HexToAddress("0x6")}})
+	assert.Equal(t, paymentClient.SmartContractAddresses{
+		Registry:                    common.HexToAddress("0x1"),
+		ActiveHermes:                common.HexToAddress("0x2"),
+		ActiveChannelImplementation: common.HexToAddress("0x3"),
+		Myst:                        common.HexToAddress("0x4"),
+		KnownHermeses:               []common.Address{common.HexToAddress("0x2")},
+	}, addresses[10])
+	assert.Equal(t, []common.Address{common.HexToAddress("0x6")}, addresses[5].KnownHermeses)
+}
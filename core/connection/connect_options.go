@@ -18,7 +18,9 @@
 package connection
 
 import (
+	"errors"
 	"net"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -35,6 +37,12 @@ type ConnectParams struct {
 	DNS DNSOption
 
 	ProxyPort int
+	// AttestationToken is a consumer attribute token sent to providers requiring attestation.
+	AttestationToken string
+	// ChargePeriod is how often consumer asks to be charged, zero uses the configured default.
+	ChargePeriod time.Duration
+	// BandwidthTier is the name of bandwidth tier advertised in proposal, required if the proposal advertises any.
+	BandwidthTier string
 }
 
 // ConnectOptions represents the params we need to ensure a successful connection
@@ -48,4 +56,28 @@ type ConnectOptions struct {
 	ProviderNATConn *net.UDPConn
 	ChannelConn     *net.UDPConn
 	HermesID        common.Address
+	DNSResolver     LocalDNSResolver
+}
+
+// LocalDNSResolver serves the DNS queries of the system locally, forwarding them to the upstream servers.
+type LocalDNSResolver interface {
+	Serve(profile string, upstreams []string) ([]string, error)
+}
+
+// ResolveDNS resolves DNS servers the tunnel should use, given the DNS servers of the provider.
+// If the local resolver was requested, it is started and the tunnel is pointed at it.
+func (o ConnectOptions) ResolveDNS(providerDNS string) ([]string, error) {
+	upstreams, err := o.Params.DNS.ResolveIPs(providerDNS)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, ok := o.Params.DNS.Local()
+	if !ok {
+		return upstreams, nil
+	}
+	if o.DNSResolver == nil {
+		return nil, errors.New("local DNS resolver is not available")
+	}
+	return o.DNSResolver.Serve(profile, upstreams)
 }
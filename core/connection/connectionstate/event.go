@@ -24,6 +24,8 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/core/speedtest"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session"
 )
@@ -36,6 +38,10 @@ const (
 	AppTopicConnectionStatistics = "Statistics"
 	// AppTopicConnectionSession represents the session lifetime changes
 	AppTopicConnectionSession = "Session"
+	// AppTopicConnectionIssue represents the issues of established connection which are attributed to the provider
+	AppTopicConnectionIssue = "ConnectionIssue"
+	// AppTopicConnectionSpeedtest represents the throughput tests of established connection
+	AppTopicConnectionSpeedtest = "ConnectionSpeedtest"
 )
 
 // AppEventConnectionState is the struct we'll emit on a AppEventConnectionState topic event
@@ -79,6 +85,10 @@ type Status struct {
 	State            State
 	SessionID        session.ID
 	Proposal         proposal.PricedServiceProposal
+	// DisconnectReason holds the reason given by provider when it terminated the session.
+	DisconnectReason session.TerminationReason
+	// Paused is set while consumer has the session paused on the provider side.
+	Paused bool
 }
 
 // Duration returns elapsed time from marked session start
@@ -103,8 +113,41 @@ type AppEventConnectionSession struct {
 	SessionInfo Status
 }
 
+// Issue represents the type of connection issue
+type Issue string
+
+const (
+	// IssuePaymentFailed means that session payments with the provider failed
+	IssuePaymentFailed = Issue("PaymentFailed")
+	// IssueKeepAliveFailed means that the provider stopped responding to keep alive pings
+	IssueKeepAliveFailed = Issue("KeepAliveFailed")
+)
+
+// AppEventConnectionIssue represents a connection issue event
+type AppEventConnectionIssue struct {
+	Issue       Issue
+	SessionInfo Status
+	Error       string
+}
+
+// AppEventConnectionSpeedtest represents a finished throughput test of the session tunnel
+type AppEventConnectionSpeedtest struct {
+	SessionInfo Status
+	Result      speedtest.Result
+}
+
 // AppEventConnectionStatistics represents a session statistics event
 type AppEventConnectionStatistics struct {
-	Stats       Statistics
+	Stats Statistics
+	// Delta holds the traffic transferred since the previously published statistics.
+	Delta       Statistics
 	SessionInfo Status
 }
+
+func init() {
+	eventbus.RegisterSchema(AppTopicConnectionState, 1, AppEventConnectionState{})
+	eventbus.RegisterSchema(AppTopicConnectionStatistics, 1, AppEventConnectionStatistics{})
+	eventbus.RegisterSchema(AppTopicConnectionSession, 1, AppEventConnectionSession{})
+	eventbus.RegisterSchema(AppTopicConnectionIssue, 1, AppEventConnectionIssue{})
+	eventbus.RegisterSchema(AppTopicConnectionSpeedtest, 1, AppEventConnectionSpeedtest{})
+}
@@ -29,14 +29,17 @@ type Statistics struct {
 	At            time.Time
 	BytesSent     uint64
 	BytesReceived uint64
+	// LastHandshakeAge is the time passed since the last tunnel handshake, zero if the tunnel does not report it.
+	LastHandshakeAge time.Duration
 }
 
 // Diff calculates the difference in bytes between the old stats and new.
 func (stats Statistics) Diff(new Statistics) Statistics {
 	return Statistics{
-		At:            new.At,
-		BytesSent:     diff(stats.BytesSent, new.BytesSent),
-		BytesReceived: diff(stats.BytesReceived, new.BytesReceived),
+		At:               new.At,
+		BytesSent:        diff(stats.BytesSent, new.BytesSent),
+		BytesReceived:    diff(stats.BytesReceived, new.BytesReceived),
+		LastHandshakeAge: new.LastHandshakeAge,
 	}
 }
 
@@ -51,9 +54,10 @@ func diff(old, new uint64) (res uint64) {
 // Plus adds up the given statistics with the diff and returns new stats
 func (stats Statistics) Plus(diff Statistics) Statistics {
 	return Statistics{
-		At:            stats.At,
-		BytesReceived: stats.BytesReceived + diff.BytesReceived,
-		BytesSent:     stats.BytesSent + diff.BytesSent,
+		At:               stats.At,
+		BytesReceived:    stats.BytesReceived + diff.BytesReceived,
+		BytesSent:        stats.BytesSent + diff.BytesSent,
+		LastHandshakeAge: diff.LastHandshakeAge,
 	}
 }
 
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package connectiontest provides in-memory fakes of the consumer connection,
+// so that the connection flow can be exercised in tests without bringing up a tunnel.
+package connectiontest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+var _ connection.Connection = &Connection{}
+
+// Connection is a fake connection which reports the usual state transitions without any tunnel.
+type Connection struct {
+	// StartErr is returned by Start and Reconnect when set.
+	StartErr error
+
+	lock     sync.Mutex
+	stateCh  chan connectionstate.State
+	stopOnce sync.Once
+	stats    connectionstate.Statistics
+	options  []connection.ConnectOptions
+}
+
+// NewConnection returns a new fake connection.
+func NewConnection() *Connection {
+	return &Connection{
+		stateCh: make(chan connectionstate.State, 100),
+	}
+}
+
+// Start records the options and reports the connection as connected.
+func (c *Connection) Start(_ context.Context, options connection.ConnectOptions) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.StartErr != nil {
+		return c.StartErr
+	}
+
+	c.options = append(c.options, options)
+	c.stateCh <- connectionstate.Connecting
+	c.stateCh <- connectionstate.Connected
+	return nil
+}
+
+// Reconnect records the options and reports the connection as reconnected.
+func (c *Connection) Reconnect(_ context.Context, options connection.ConnectOptions) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.StartErr != nil {
+		return c.StartErr
+	}
+
+	c.options = append(c.options, options)
+	c.stateCh <- connectionstate.Reconnecting
+	c.stateCh <- connectionstate.Connected
+	return nil
+}
+
+// Stop reports the connection as disconnected and closes the state channel.
+func (c *Connection) Stop() {
+	c.stopOnce.Do(func() {
+		c.stateCh <- connectionstate.Disconnecting
+		c.stateCh <- connectionstate.NotConnected
+		close(c.stateCh)
+	})
+}
+
+// GetConfig returns an empty consumer config.
+func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
+	return nil, nil
+}
+
+// State returns connection state channel.
+func (c *Connection) State() <-chan connectionstate.State {
+	return c.stateCh
+}
+
+// SetStatistics sets the statistics reported by the connection.
+func (c *Connection) SetStatistics(stats connectionstate.Statistics) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.stats = stats
+}
+
+// Statistics returns the statistics set with SetStatistics.
+func (c *Connection) Statistics() (connectionstate.Statistics, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.stats, nil
+}
+
+// Options returns the options the connection was started and reconnected with.
+func (c *Connection) Options() []connection.ConnectOptions {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]connection.ConnectOptions(nil), c.options...)
+}
+
+// Factory creates fake connections and keeps track of them.
+type Factory struct {
+	// Err is returned instead of a connection when set.
+	Err error
+	// StartErr is set on every created connection.
+	StartErr error
+
+	lock        sync.Mutex
+	connections []*Connection
+}
+
+// Create returns connection factory which can be registered in connection.Registry.
+func (f *Factory) Create() connection.Factory {
+	return func() (connection.Connection, error) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		if f.Err != nil {
+			return nil, f.Err
+		}
+
+		conn := NewConnection()
+		conn.StartErr = f.StartErr
+		f.connections = append(f.connections, conn)
+		return conn, nil
+	}
+}
+
+// Connections returns the connections created so far.
+func (f *Factory) Connections() []*Connection {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]*Connection(nil), f.connections...)
+}
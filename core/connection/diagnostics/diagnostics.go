@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	// CheckTunnelPing pings the provider's end of the tunnel.
+	CheckTunnelPing = "tunnel_ping"
+	// CheckDNS resolves a well known host through the tunnel DNS servers.
+	CheckDNS = "dns"
+	// CheckExternalIP checks that the public IP has changed after connecting.
+	CheckExternalIP = "external_ip"
+)
+
+const (
+	defaultCheckTimeout = 5 * time.Second
+	defaultDNSProbeHost = "mysterium.network"
+)
+
+// Target describes the established tunnel to diagnose.
+type Target struct {
+	// ProviderIP is the provider's address inside the tunnel, nil if unknown.
+	ProviderIP net.IP
+	// DNSServers are the DNS servers pushed by the provider, system resolver is used if empty.
+	DNSServers []string
+	// OriginalPublicIP is the consumer's public IP before connecting.
+	OriginalPublicIP string
+	// PublicIP looks up the current public IP.
+	PublicIP func() (string, error)
+}
+
+// CheckResult is the outcome of a single diagnostics check.
+type CheckResult struct {
+	Name     string
+	OK       bool
+	Skipped  bool
+	Duration time.Duration
+	Details  string
+	Error    string
+}
+
+// Report is a structured health report of the tunnel.
+type Report struct {
+	Healthy bool
+	Checks  []CheckResult
+}
+
+// Runner runs tunnel internal checks to tell provider side problems apart from the local ones.
+type Runner struct {
+	timeout      time.Duration
+	dnsProbeHost string
+
+	ping       func(ctx context.Context, ip net.IP) error
+	lookupHost func(ctx context.Context, servers []string, host string) ([]string, error)
+}
+
+// NewRunner returns a new diagnostics runner.
+func NewRunner() *Runner {
+	return &Runner{
+		timeout:      defaultCheckTimeout,
+		dnsProbeHost: defaultDNSProbeHost,
+		ping:         ping,
+		lookupHost:   LookupHost,
+	}
+}
+
+// Run runs all the checks against the given target.
+func (r *Runner) Run(ctx context.Context, target Target) Report {
+	report := Report{
+		Healthy: true,
+		Checks: []CheckResult{
+			r.checkTunnelPing(ctx, target),
+			r.checkDNS(ctx, target),
+			r.checkExternalIP(target),
+		},
+	}
+	for _, check := range report.Checks {
+		if !check.OK && !check.Skipped {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func (r *Runner) checkTunnelPing(ctx context.Context, target Target) CheckResult {
+	if target.ProviderIP == nil {
+		return CheckResult{Name: CheckTunnelPing, Skipped: true, Details: "provider tunnel IP is unknown"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return Measure(CheckTunnelPing, func() (string, error) {
+		return target.ProviderIP.String(), r.ping(ctx, target.ProviderIP)
+	})
+}
+
+func (r *Runner) checkDNS(ctx context.Context, target Target) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return Measure(CheckDNS, func() (string, error) {
+		addrs, err := r.lookupHost(ctx, target.DNSServers, r.dnsProbeHost)
+		if err != nil {
+			return r.dnsProbeHost, err
+		}
+		if len(addrs) == 0 {
+			return r.dnsProbeHost, fmt.Errorf("no addresses resolved for %s", r.dnsProbeHost)
+		}
+		return fmt.Sprintf("%s resolved to %s", r.dnsProbeHost, addrs[0]), nil
+	})
+}
+
+func (r *Runner) checkExternalIP(target Target) CheckResult {
+	if target.PublicIP == nil {
+		return CheckResult{Name: CheckExternalIP, Skipped: true, Details: "public IP lookup is not available"}
+	}
+
+	return Measure(CheckExternalIP, func() (string, error) {
+		ip, err := target.PublicIP()
+		if err != nil {
+			return "", err
+		}
+		if ip == target.OriginalPublicIP {
+			return ip, errors.New("public IP has not changed")
+		}
+		return ip, nil
+	})
+}
+
+// Measure runs the check and reports its outcome, details are reported even if the check fails.
+func Measure(name string, check func() (string, error)) CheckResult {
+	start := time.Now()
+	details, err := check()
+	result := CheckResult{
+		Name:     name,
+		OK:       err == nil,
+		Duration: time.Since(start),
+		Details:  details,
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LookupHost resolves the host through the first of the given DNS servers, system resolver is used if none given.
+func LookupHost(ctx context.Context, servers []string, host string) ([]string, error) {
+	if len(servers) == 0 {
+		return net.DefaultResolver.LookupHost(ctx, host)
+	}
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, net.JoinHostPort(servers[0], "53"))
+		},
+	}
+	return resolver.LookupHost(ctx, host)
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner_Run(t *testing.T) {
+	providerIP := net.ParseIP("10.182.0.1")
+	okPing := func(context.Context, net.IP) error { return nil }
+	okLookup := func(context.Context, []string, string) ([]string, error) { return []string{"1.2.3.4"}, nil }
+	newIP := func() (string, error) { return "5.6.7.8", nil }
+
+	tests := []struct {
+		name            string
+		ping            func(context.Context, net.IP) error
+		lookup          func(context.Context, []string, string) ([]string, error)
+		target          Target
+		expectedHealthy bool
+		expectedFailed  []string
+		expectedSkipped []string
+	}{
+		{
+			name:            "all checks pass",
+			ping:            okPing,
+			lookup:          okLookup,
+			target:          Target{ProviderIP: providerIP, OriginalPublicIP: "1.1.1.1", PublicIP: newIP},
+			expectedHealthy: true,
+		},
+		{
+			name:            "provider not reachable through tunnel",
+			ping:            func(context.Context, net.IP) error { return errors.New("timeout") },
+			lookup:          okLookup,
+			target:          Target{ProviderIP: providerIP, OriginalPublicIP: "1.1.1.1", PublicIP: newIP},
+			expectedHealthy: false,
+			expectedFailed:  []string{CheckTunnelPing},
+		},
+		{
+			name:            "DNS not resolving",
+			ping:            okPing,
+			lookup:          func(context.Context, []string, string) ([]string, error) { return nil, nil },
+			target:          Target{ProviderIP: providerIP, OriginalPublicIP: "1.1.1.1", PublicIP: newIP},
+			expectedHealthy: false,
+			expectedFailed:  []string{CheckDNS},
+		},
+		{
+			name:            "public IP not changed",
+			ping:            okPing,
+			lookup:          okLookup,
+			target:          Target{ProviderIP: providerIP, OriginalPublicIP: "5.6.7.8", PublicIP: newIP},
+			expectedHealthy: false,
+			expectedFailed:  []string{CheckExternalIP},
+		},
+		{
+			name:            "unknown tunnel address skips ping",
+			ping:            func(context.Context, net.IP) error { return errors.New("should not be called") },
+			lookup:          okLookup,
+			target:          Target{OriginalPublicIP: "1.1.1.1", PublicIP: newIP},
+			expectedHealthy: true,
+			expectedSkipped: []string{CheckTunnelPing},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRunner()
+			r.ping = tt.ping
+			r.lookupHost = tt.lookup
+
+			report := r.Run(context.Background(), tt.target)
+
+			assert.Equal(t, tt.expectedHealthy, report.Healthy)
+			assert.Len(t, report.Checks, 3)
+
+			var failed, skipped []string
+			for _, check := range report.Checks {
+				if check.Skipped {
+					skipped = append(skipped, check.Name)
+				} else if !check.OK {
+					failed = append(failed, check.Name)
+					assert.NotEmpty(t, check.Error)
+				}
+			}
+			assert.Equal(t, tt.expectedFailed, failed)
+			assert.Equal(t, tt.expectedSkipped, skipped)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package diagnostics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+const protocolICMP = 1
+
+// ping sends a single ICMP echo request to the given IPv4 address and waits for the reply.
+// Raw socket is used when permitted, falling back to the unprivileged datagram socket otherwise.
+func ping(ctx context.Context, ip net.IP) error {
+	if ip.To4() == nil {
+		return fmt.Errorf("only IPv4 is supported, got %s", ip)
+	}
+
+	var dst net.Addr = &net.IPAddr{IP: ip}
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		conn, err = icmp.ListenPacket("udp4", "0.0.0.0")
+		if err != nil {
+			return fmt.Errorf("could not open ICMP socket: %w", err)
+		}
+		dst = &net.UDPAddr{IP: ip}
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultCheckTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	seq := int(time.Now().UnixNano() & 0xffff)
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Body: &icmp.Echo{
+			ID:   os.Getpid() & 0xffff,
+			Seq:  seq,
+			Data: []byte("myst-diagnostics"),
+		},
+	}
+	data, err := msg.Marshal(nil)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.WriteTo(data, dst); err != nil {
+		return fmt.Errorf("could not send ICMP echo: %w", err)
+	}
+
+	buf := make([]byte, 1500)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return fmt.Errorf("no ICMP echo reply: %w", err)
+		}
+
+		reply, err := icmp.ParseMessage(protocolICMP, buf[:n])
+		if err != nil || reply.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		// The kernel rewrites the ID of the unprivileged echo requests, so we only match by sequence.
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.Seq == seq {
+			return nil
+		}
+	}
+}
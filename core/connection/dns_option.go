@@ -37,13 +37,24 @@ const (
 	DNSOptionProvider = DNSOption("provider")
 	// DNSOptionSystem uses DNS servers from client's system configuration
 	DNSOptionSystem = DNSOption("system")
+	// DNSOptionLocal points the system at the embedded caching resolver, which forwards the queries as DNSOptionAuto would.
+	// The resolver profile may be selected by "local:<profile>".
+	DNSOptionLocal = DNSOption("local")
 )
 
+const dnsOptionLocalProfileSeparator = ":"
+
 // NewDNSOption creates and validates DNSOption
 func NewDNSOption(str string) (DNSOption, error) {
 	opt := DNSOption(str)
 	switch opt {
-	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem, "":
+	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem, DNSOptionLocal, "":
+		return opt, nil
+	}
+	if profile, ok := opt.Local(); ok {
+		if profile == "" {
+			return "", errors.New("empty local DNS resolver profile provided as a DNS option")
+		}
 		return opt, nil
 	}
 	// It may also be a set of IP addresses, e.g. 1.1.1.1,8.8.8.8
@@ -76,9 +87,24 @@ func (o DNSOption) Exact() (servers []string, ok bool) {
 	case DNSOptionAuto, DNSOptionProvider, DNSOptionSystem:
 		return nil, false
 	}
+	if _, ok := o.Local(); ok {
+		return nil, false
+	}
 	return stringutil.Split(string(o), ','), true
 }
 
+// Local returns the local resolver profile, if the local resolver was requested
+func (o DNSOption) Local() (profile string, ok bool) {
+	if o == DNSOptionLocal {
+		return "", true
+	}
+	prefix := string(DNSOptionLocal) + dnsOptionLocalProfileSeparator
+	if strings.HasPrefix(string(o), prefix) {
+		return strings.TrimPrefix(string(o), prefix), true
+	}
+	return "", false
+}
+
 // ResolveIPs resolves DNS server IPs on the consumer side using self as the
 // consumer preference and `providerDNS` argument as received from the provider
 func (o *DNSOption) ResolveIPs(providerDNS string) ([]string, error) {
@@ -86,6 +112,11 @@ func (o *DNSOption) ResolveIPs(providerDNS string) ([]string, error) {
 	if exact, ok := o.Exact(); ok {
 		return exact, nil
 	}
+	if _, ok := o.Local(); ok {
+		// Local resolver forwards the queries to the servers which would be used otherwise.
+		auto := DNSOptionAuto
+		return auto.ResolveIPs(providerDNS)
+	}
 	switch *o {
 	case DNSOptionProvider:
 		return selectProviderDNS(providerDNS)
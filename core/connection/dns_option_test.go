@@ -33,6 +33,9 @@ func TestNewDNSOption(t *testing.T) {
 		{input: "auto", expect: DNSOptionAuto},
 		{input: "provider", expect: DNSOptionProvider},
 		{input: "system", expect: DNSOptionSystem},
+		{input: "local", expect: DNSOptionLocal},
+		{input: "local:family", expect: DNSOption("local:family")},
+		{input: "local:", expectErr: true},
 		{input: "1.1.1.1,9.9.9.9", expect: DNSOption("1.1.1.1,9.9.9.9")},
 		{input: "1.1.1.1", expect: DNSOption("1.1.1.1")},
 		{input: "", expect: DNSOption("")},
@@ -83,6 +86,8 @@ func TestDNSOption_Exact(t *testing.T) {
 		{option: DNSOptionAuto, expectOK: false},
 		{option: DNSOptionProvider, expectOK: false},
 		{option: DNSOptionSystem, expectOK: false},
+		{option: DNSOptionLocal, expectOK: false},
+		{option: DNSOption("local:family"), expectOK: false},
 		{option: DNSOption("1.1.1.1,9.9.9.9"), expectServers: []string{"1.1.1.1", "9.9.9.9"}, expectOK: true},
 		{option: DNSOption("9.9.9.9"), expectServers: []string{"9.9.9.9"}, expectOK: true},
 		{option: DNSOption(""), expectServers: nil, expectOK: true},
@@ -93,3 +98,57 @@ func TestDNSOption_Exact(t *testing.T) {
 		assert.Equal(tt.expectServers, servers)
 	}
 }
+
+func TestDNSOption_Local(t *testing.T) {
+	assert := assert.New(t)
+	tests := []struct {
+		option        DNSOption
+		expectProfile string
+		expectOK      bool
+	}{
+		{option: DNSOptionAuto, expectOK: false},
+		{option: DNSOption("1.1.1.1"), expectOK: false},
+		{option: DNSOptionLocal, expectProfile: "", expectOK: true},
+		{option: DNSOption("local:family"), expectProfile: "family", expectOK: true},
+	}
+	for _, tt := range tests {
+		profile, ok := tt.option.Local()
+		assert.Equal(tt.expectOK, ok)
+		assert.Equal(tt.expectProfile, profile)
+	}
+}
+
+func TestConnectOptions_ResolveDNS(t *testing.T) {
+	resolver := &mockLocalDNSResolver{servers: []string{"127.0.0.1"}}
+
+	options := ConnectOptions{Params: ConnectParams{DNS: DNSOption("1.1.1.1")}, DNSResolver: resolver}
+	servers, err := options.ResolveDNS("10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.1.1.1"}, servers)
+	assert.False(t, resolver.called)
+
+	options = ConnectOptions{Params: ConnectParams{DNS: DNSOption("local:family")}, DNSResolver: resolver}
+	servers, err = options.ResolveDNS("10.0.0.1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, servers)
+	assert.Equal(t, "family", resolver.profile)
+	assert.Equal(t, []string{"10.0.0.1"}, resolver.upstreams)
+
+	options = ConnectOptions{Params: ConnectParams{DNS: DNSOptionLocal}}
+	_, err = options.ResolveDNS("10.0.0.1")
+	assert.Error(t, err)
+}
+
+type mockLocalDNSResolver struct {
+	servers   []string
+	called    bool
+	profile   string
+	upstreams []string
+}
+
+func (m *mockLocalDNSResolver) Serve(profile string, upstreams []string) ([]string, error) {
+	m.called = true
+	m.profile = profile
+	m.upstreams = upstreams
+	return m.servers, nil
+}
@@ -19,10 +19,15 @@ package connection
 
 import (
 	"context"
+	"net"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/connection/diagnostics"
+	"github.com/mysteriumnetwork/node/core/connection/leaktest"
+	"github.com/mysteriumnetwork/node/core/speedtest"
 	"github.com/mysteriumnetwork/node/identity"
 )
 
@@ -39,6 +44,23 @@ type Connection interface {
 	Statistics() (connectionstate.Statistics, error)
 }
 
+// TunnelInfo holds the addressing of an established tunnel.
+type TunnelInfo struct {
+	ProviderIP net.IP
+	DNSServers []string
+}
+
+// TunnelInfoProvider is implemented by connections which are able to report their tunnel addressing.
+type TunnelInfoProvider interface {
+	TunnelInfo() (TunnelInfo, bool)
+}
+
+// ConfigExporter is implemented by connections which are able to export their
+// negotiated tunnel configuration for use with external clients.
+type ConfigExporter interface {
+	ExportConfig() (string, error)
+}
+
 // StateChannel is the channel we receive state change events on
 type StateChannel chan connectionstate.State
 
@@ -56,6 +78,18 @@ type Manager interface {
 	CheckChannel(context.Context) error
 	// Reconnect reconnects current session
 	Reconnect()
+	// Diagnose runs tunnel internal checks of current connection, reports error if no connection
+	Diagnose(context.Context) (diagnostics.Report, error)
+	// LeakTest checks whether traffic of current connection bypasses the tunnel, reports error if no connection
+	LeakTest(context.Context) (leaktest.Report, error)
+	// Speedtest measures throughput of current connection against the provider's echo endpoint, reports error if no connection
+	Speedtest(ctx context.Context, duration time.Duration) (speedtest.Result, error)
+	// ExportConfig exports negotiated tunnel configuration of current connection, reports error if no connection
+	ExportConfig() (string, error)
+	// Pause asks provider to stop billing and throttle the current session, reports error if no connection
+	Pause() error
+	// Resume asks provider to continue the paused session, reports error if no connection
+	Resume() error
 }
 
 // MultiManager interface provides methods to manage connection
@@ -72,4 +106,16 @@ type MultiManager interface {
 	CheckChannel(context.Context) error
 	// Reconnect reconnects current session
 	Reconnect(n int)
+	// Diagnose runs tunnel internal checks of given connection, reports error if no connection
+	Diagnose(ctx context.Context, n int) (diagnostics.Report, error)
+	// LeakTest checks whether traffic of given connection bypasses the tunnel, reports error if no connection
+	LeakTest(ctx context.Context, n int) (leaktest.Report, error)
+	// Speedtest measures throughput of given connection against the provider's echo endpoint, reports error if no connection
+	Speedtest(ctx context.Context, n int, duration time.Duration) (speedtest.Result, error)
+	// ExportConfig exports negotiated tunnel configuration of given connection, reports error if no connection
+	ExportConfig(n int) (string, error)
+	// Pause asks provider to stop billing and throttle the given session, reports error if no connection
+	Pause(n int) error
+	// Resume asks provider to continue the given paused session, reports error if no connection
+	Resume(n int) error
 }
@@ -0,0 +1,212 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leaktest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pion/stun"
+
+	"github.com/mysteriumnetwork/node/core/connection/diagnostics"
+)
+
+const (
+	// CheckDNS checks that system DNS queries are resolved through the tunnel.
+	CheckDNS = "dns"
+	// CheckIPv6 checks that IPv6 traffic does not bypass the IPv4 only tunnel.
+	CheckIPv6 = "ipv6"
+	// CheckWebRTC checks that STUN servers do not see the original public IP.
+	CheckWebRTC = "webrtc"
+)
+
+const (
+	// whoamiHost resolves to the address of the recursive resolver which queried it.
+	whoamiHost = "whoami.akamai.net"
+	ipv6Probe  = "[2001:4860:4860::8888]:53"
+)
+
+type dnsCheck struct {
+	lookupHost func(ctx context.Context, servers []string, host string) ([]string, error)
+}
+
+// NewDNSCheck returns a check which compares the egress address of the system resolver
+// with the one of the DNS servers pushed by the provider.
+func NewDNSCheck() Check {
+	return &dnsCheck{lookupHost: diagnostics.LookupHost}
+}
+
+func (c *dnsCheck) Name() string {
+	return CheckDNS
+}
+
+func (c *dnsCheck) Run(ctx context.Context, target Target) Result {
+	if len(target.DNSServers) == 0 {
+		return skipped("tunnel DNS servers are unknown")
+	}
+
+	systemEgress, err := c.lookupHost(ctx, nil, whoamiHost)
+	if err != nil {
+		return failed(fmt.Errorf("could not resolve through system resolver: %w", err))
+	}
+	if len(systemEgress) == 0 {
+		return failed(errors.New("system resolver returned no addresses"))
+	}
+	egress := systemEgress[0]
+
+	if egress == target.OriginalPublicIP {
+		return Result{Leaked: true, Details: fmt.Sprintf("DNS queries are sent from the original public IP %s", egress)}
+	}
+
+	tunnelEgress, err := c.lookupHost(ctx, target.DNSServers, whoamiHost)
+	if err != nil {
+		return failed(fmt.Errorf("could not resolve through tunnel DNS servers: %w", err))
+	}
+	for _, addr := range tunnelEgress {
+		if addr == egress {
+			return Result{Details: fmt.Sprintf("DNS queries are sent from %s", egress)}
+		}
+	}
+	if target.PublicIP != nil {
+		if ip, err := target.PublicIP(); err == nil && ip == egress {
+			return Result{Details: fmt.Sprintf("DNS queries are sent from the tunnel public IP %s", egress)}
+		}
+	}
+
+	return Result{
+		Leaked:  true,
+		Details: fmt.Sprintf("system resolver queries are sent from %s, tunnel resolver ones from %s", egress, strings.Join(tunnelEgress, ", ")),
+	}
+}
+
+type ipv6Check struct {
+	localAddr func(ctx context.Context) (net.IP, error)
+}
+
+// NewIPv6Check returns a check which verifies that there is no IPv6 route outside the tunnel.
+func NewIPv6Check() Check {
+	return &ipv6Check{localAddr: ipv6LocalAddr}
+}
+
+func (c *ipv6Check) Name() string {
+	return CheckIPv6
+}
+
+func (c *ipv6Check) Run(ctx context.Context, _ Target) Result {
+	ip, err := c.localAddr(ctx)
+	if err != nil {
+		return Result{Details: "no IPv6 route"}
+	}
+	if ip.IsGlobalUnicast() && !ip.IsPrivate() {
+		return Result{Leaked: true, Details: fmt.Sprintf("IPv6 traffic is routed outside the tunnel from %s", ip)}
+	}
+	return Result{Details: fmt.Sprintf("IPv6 traffic is routed from %s", ip)}
+}
+
+type webRTCCheck struct {
+	servers       []string
+	reflexiveAddr func(ctx context.Context, server string) (net.IP, error)
+}
+
+// NewWebRTCCheck returns a check which verifies that the given STUN servers do not see the original public IP.
+func NewWebRTCCheck(servers []string) Check {
+	return &webRTCCheck{servers: servers, reflexiveAddr: stunReflexiveAddr}
+}
+
+func (c *webRTCCheck) Name() string {
+	return CheckWebRTC
+}
+
+func (c *webRTCCheck) Run(ctx context.Context, target Target) Result {
+	if len(c.servers) == 0 {
+		return skipped("no STUN servers configured")
+	}
+	if target.OriginalPublicIP == "" {
+		return skipped("original public IP is unknown")
+	}
+
+	var err error
+	for _, server := range c.servers {
+		var ip net.IP
+		ip, err = c.reflexiveAddr(ctx, server)
+		if err != nil {
+			continue
+		}
+		if ip.String() == target.OriginalPublicIP {
+			return Result{Leaked: true, Details: fmt.Sprintf("STUN server %s sees the original public IP %s", server, ip)}
+		}
+		return Result{Details: fmt.Sprintf("STUN server %s sees %s", server, ip)}
+	}
+	return failed(fmt.Errorf("no STUN server responded: %w", err))
+}
+
+// ipv6LocalAddr returns the source address the system would use to reach the public IPv6 internet.
+// Dialing UDP sends no packets, it only selects the route.
+func ipv6LocalAddr(ctx context.Context) (net.IP, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp6", ipv6Probe)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).IP, nil
+}
+
+func stunReflexiveAddr(ctx context.Context, server string) (net.IP, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp4", server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial STUN server: %w", err)
+	}
+	defer conn.Close()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultCheckTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	req := stun.MustBuild(stun.TransactionID, stun.BindingRequest)
+	if _, err := conn.Write(req.Raw); err != nil {
+		return nil, fmt.Errorf("failed to send binding request to STUN server: %w", err)
+	}
+
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message from STUN server: %w", err)
+	}
+
+	resp := &stun.Message{Raw: buf[:n]}
+	if err := resp.Decode(); err != nil {
+		return nil, fmt.Errorf("failed to decode STUN server message: %w", err)
+	}
+
+	var xorAddr stun.XORMappedAddress
+	if err := xorAddr.GetFrom(resp); err != nil {
+		return nil, fmt.Errorf("failed to get reflexive address from STUN server message: %w", err)
+	}
+	return xorAddr.IP, nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leaktest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+)
+
+const defaultCheckTimeout = 10 * time.Second
+
+// Target describes the established tunnel to test for leaks.
+type Target struct {
+	// DNSServers are the DNS servers pushed by the provider, empty if unknown.
+	DNSServers []string
+	// OriginalPublicIP is the consumer's public IP before connecting.
+	OriginalPublicIP string
+	// PublicIP looks up the current public IP.
+	PublicIP func() (string, error)
+}
+
+// Result is the outcome of a single leak check.
+type Result struct {
+	Name     string
+	Leaked   bool
+	Skipped  bool
+	Duration time.Duration
+	Details  string
+	// Error is set when the check could not tell whether traffic leaks.
+	Error string
+}
+
+// Report is a structured leak test report of the connection.
+type Report struct {
+	Leaked    bool
+	Checks    []Result
+	CheckedAt time.Time
+}
+
+// Check tests a single way for the traffic to bypass the tunnel.
+type Check interface {
+	// Name returns a unique name of the check.
+	Name() string
+	// Run runs the check, result name and duration are filled in by the suite.
+	Run(ctx context.Context, target Target) Result
+}
+
+// Suite runs a set of leak checks against the established tunnel.
+type Suite struct {
+	timeout time.Duration
+	checks  []Check
+}
+
+// NewSuite returns a new leak test suite consisting of the given checks.
+func NewSuite(checks ...Check) *Suite {
+	return &Suite{
+		timeout: defaultCheckTimeout,
+		checks:  checks,
+	}
+}
+
+// NewDefaultSuite returns a leak test suite with DNS, IPv6 and WebRTC checks.
+func NewDefaultSuite() *Suite {
+	return NewSuite(
+		NewDNSCheck(),
+		NewIPv6Check(),
+		NewWebRTCCheck(config.GetStringSlice(config.FlagSTUNservers)),
+	)
+}
+
+// Run runs all the checks concurrently against the given target.
+func (s *Suite) Run(ctx context.Context, target Target) Report {
+	report := Report{
+		Checks:    make([]Result, len(s.checks)),
+		CheckedAt: time.Now().UTC(),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range s.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Checks[i] = s.run(ctx, check, target)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Leaked {
+			report.Leaked = true
+		}
+	}
+	return report
+}
+
+func (s *Suite) run(ctx context.Context, check Check, target Target) Result {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := check.Run(ctx, target)
+	result.Name = check.Name()
+	result.Duration = time.Since(start)
+	return result
+}
+
+func skipped(details string) Result {
+	return Result{Skipped: true, Details: details}
+}
+
+func failed(err error) Result {
+	return Result{Error: err.Error()}
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leaktest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeCheck struct {
+	name   string
+	result Result
+}
+
+func (c fakeCheck) Name() string { return c.name }
+
+func (c fakeCheck) Run(context.Context, Target) Result { return c.result }
+
+func TestSuite_Run(t *testing.T) {
+	report := NewSuite(
+		fakeCheck{name: "first", result: Result{Details: "ok"}},
+		fakeCheck{name: "second", result: Result{Skipped: true}},
+	).Run(context.Background(), Target{})
+	assert.False(t, report.Leaked)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, "first", report.Checks[0].Name)
+	assert.Equal(t, "second", report.Checks[1].Name)
+	assert.False(t, report.CheckedAt.IsZero())
+
+	report = NewSuite(
+		fakeCheck{name: "first", result: Result{Details: "ok"}},
+		fakeCheck{name: "second", result: Result{Leaked: true}},
+	).Run(context.Background(), Target{})
+	assert.True(t, report.Leaked)
+}
+
+func TestDNSCheck(t *testing.T) {
+	lookup := func(system, tunnel []string) func(context.Context, []string, string) ([]string, error) {
+		return func(_ context.Context, servers []string, _ string) ([]string, error) {
+			if len(servers) == 0 {
+				return system, nil
+			}
+			return tunnel, nil
+		}
+	}
+	publicIP := func() (string, error) { return "5.6.7.8", nil }
+
+	tests := []struct {
+		name           string
+		lookup         func(context.Context, []string, string) ([]string, error)
+		target         Target
+		expectedLeaked bool
+		expectedSkip   bool
+		expectedError  bool
+	}{
+		{
+			name:   "system resolver goes through the tunnel",
+			lookup: lookup([]string{"9.9.9.9"}, []string{"9.9.9.9"}),
+			target: Target{DNSServers: []string{"10.182.0.1"}, OriginalPublicIP: "1.1.1.1"},
+		},
+		{
+			name:   "system resolver is queried from tunnel public IP",
+			lookup: lookup([]string{"5.6.7.8"}, []string{"9.9.9.9"}),
+			target: Target{DNSServers: []string{"10.182.0.1"}, OriginalPublicIP: "1.1.1.1", PublicIP: publicIP},
+		},
+		{
+			name:           "system resolver uses other egress",
+			lookup:         lookup([]string{"8.8.4.4"}, []string{"9.9.9.9"}),
+			target:         Target{DNSServers: []string{"10.182.0.1"}, OriginalPublicIP: "1.1.1.1", PublicIP: publicIP},
+			expectedLeaked: true,
+		},
+		{
+			name:           "system resolver queried from original IP",
+			lookup:         lookup([]string{"1.1.1.1"}, []string{"9.9.9.9"}),
+			target:         Target{DNSServers: []string{"10.182.0.1"}, OriginalPublicIP: "1.1.1.1"},
+			expectedLeaked: true,
+		},
+		{
+			name:         "unknown tunnel DNS",
+			lookup:       lookup([]string{"8.8.4.4"}, nil),
+			target:       Target{OriginalPublicIP: "1.1.1.1"},
+			expectedSkip: true,
+		},
+		{
+			name: "lookup failure",
+			lookup: func(context.Context, []string, string) ([]string, error) {
+				return nil, errors.New("i/o timeout")
+			},
+			target:        Target{DNSServers: []string{"10.182.0.1"}},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := &dnsCheck{lookupHost: tt.lookup}
+			result := check.Run(context.Background(), tt.target)
+			assert.Equal(t, tt.expectedLeaked, result.Leaked)
+			assert.Equal(t, tt.expectedSkip, result.Skipped)
+			assert.Equal(t, tt.expectedError, result.Error != "")
+		})
+	}
+}
+
+func TestIPv6Check(t *testing.T) {
+	localAddr := func(ip string, err error) func(context.Context) (net.IP, error) {
+		return func(context.Context) (net.IP, error) { return net.ParseIP(ip), err }
+	}
+
+	check := &ipv6Check{localAddr: localAddr("", errors.New("network is unreachable"))}
+	assert.False(t, check.Run(context.Background(), Target{}).Leaked)
+
+	check = &ipv6Check{localAddr: localAddr("fd00::2", nil)}
+	assert.False(t, check.Run(context.Background(), Target{}).Leaked)
+
+	check = &ipv6Check{localAddr: localAddr("2001:db8::1", nil)}
+	assert.True(t, check.Run(context.Background(), Target{}).Leaked)
+}
+
+func TestWebRTCCheck(t *testing.T) {
+	reflexive := map[string]net.IP{"stun2:3478": net.ParseIP("5.6.7.8")}
+	reflexiveAddr := func(_ context.Context, server string) (net.IP, error) {
+		if ip, ok := reflexive[server]; ok {
+			return ip, nil
+		}
+		return nil, errors.New("timeout")
+	}
+
+	check := &webRTCCheck{servers: []string{"stun1:3478", "stun2:3478"}, reflexiveAddr: reflexiveAddr}
+	result := check.Run(context.Background(), Target{OriginalPublicIP: "1.1.1.1"})
+	assert.False(t, result.Leaked)
+	assert.Empty(t, result.Error)
+
+	result = check.Run(context.Background(), Target{OriginalPublicIP: "5.6.7.8"})
+	assert.True(t, result.Leaked)
+
+	result = check.Run(context.Background(), Target{})
+	assert.True(t, result.Skipped)
+
+	check = &webRTCCheck{servers: []string{"stun1:3478"}, reflexiveAddr: reflexiveAddr}
+	result = check.Run(context.Background(), Target{OriginalPublicIP: "1.1.1.1"})
+	assert.False(t, result.Leaked)
+	assert.NotEmpty(t, result.Error)
+}
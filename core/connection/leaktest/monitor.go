@@ -0,0 +1,106 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leaktest
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// AppTopicLeakTest represents the topic on which the results of the automatic leak test are published.
+const AppTopicLeakTest = "LeakTest"
+
+func init() {
+	eventbus.RegisterSchema(AppTopicLeakTest, 1, AppEventLeakTest{})
+}
+
+// AppEventLeakTest is the event published after the automatic leak test of a new connection.
+type AppEventLeakTest struct {
+	SessionID    string
+	Report       Report
+	Disconnected bool
+}
+
+const monitorTimeout = 30 * time.Second
+
+// tunnelConnection is the connection tested by the monitor, proxy connections do not affect the system traffic.
+const tunnelConnection = 0
+
+type connectionManager interface {
+	Status(id int) connectionstate.Status
+	LeakTest(ctx context.Context, id int) (Report, error)
+	Disconnect(id int) error
+}
+
+// Monitor runs the leak test after every established connection.
+type Monitor struct {
+	manager        connectionManager
+	publisher      eventbus.Publisher
+	autoDisconnect bool
+}
+
+// NewMonitor returns a new leak test monitor, which disconnects a leaking connection if autoDisconnect is set.
+func NewMonitor(manager connectionManager, publisher eventbus.Publisher, autoDisconnect bool) *Monitor {
+	return &Monitor{
+		manager:        manager,
+		publisher:      publisher,
+		autoDisconnect: autoDisconnect,
+	}
+}
+
+// Subscribe subscribes the monitor to the connection events.
+func (m *Monitor) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(connectionstate.AppTopicConnectionState, m.handleStateEvent)
+}
+
+func (m *Monitor) handleStateEvent(e connectionstate.AppEventConnectionState) {
+	if e.State != connectionstate.Connected {
+		return
+	}
+	sessionID := string(e.SessionInfo.SessionID)
+	if m.manager.Status(tunnelConnection).SessionID != e.SessionInfo.SessionID {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), monitorTimeout)
+	defer cancel()
+
+	report, err := m.manager.LeakTest(ctx, tunnelConnection)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not run leak test for session %s", sessionID)
+		return
+	}
+
+	event := AppEventLeakTest{SessionID: sessionID, Report: report}
+	if report.Leaked {
+		log.Warn().Msgf("Traffic leak detected for session %s", sessionID)
+		if m.autoDisconnect {
+			if err := m.manager.Disconnect(tunnelConnection); err != nil {
+				log.Error().Err(err).Msg("Could not disconnect leaking connection")
+			} else {
+				event.Disconnected = true
+			}
+		}
+	}
+	m.publisher.Publish(AppTopicLeakTest, event)
+}
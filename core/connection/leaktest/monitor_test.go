@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package leaktest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+type mockConnectionManager struct {
+	sessionID    session.ID
+	report       Report
+	disconnected bool
+}
+
+func (m *mockConnectionManager) Status(int) connectionstate.Status {
+	return connectionstate.Status{SessionID: m.sessionID}
+}
+
+func (m *mockConnectionManager) LeakTest(context.Context, int) (Report, error) {
+	return m.report, nil
+}
+
+func (m *mockConnectionManager) Disconnect(int) error {
+	m.disconnected = true
+	return nil
+}
+
+func TestMonitor_PublishesReport(t *testing.T) {
+	manager := &mockConnectionManager{sessionID: "s1", report: Report{Leaked: true}}
+	bus := mocks.NewEventBus()
+	monitor := NewMonitor(manager, bus, false)
+
+	monitor.handleStateEvent(connectionstate.AppEventConnectionState{State: connectionstate.Connecting})
+	assert.Nil(t, bus.Pop())
+
+	monitor.handleStateEvent(connectionstate.AppEventConnectionState{
+		State:       connectionstate.Connected,
+		SessionInfo: connectionstate.Status{SessionID: "proxy-session"},
+	})
+	assert.Nil(t, bus.Pop())
+
+	monitor.handleStateEvent(connectionstate.AppEventConnectionState{
+		State:       connectionstate.Connected,
+		SessionInfo: connectionstate.Status{SessionID: "s1"},
+	})
+	assert.Equal(t, AppEventLeakTest{SessionID: "s1", Report: Report{Leaked: true}}, bus.Pop())
+	assert.False(t, manager.disconnected)
+}
+
+func TestMonitor_DisconnectsOnLeak(t *testing.T) {
+	manager := &mockConnectionManager{sessionID: "s1", report: Report{Leaked: true}}
+	bus := mocks.NewEventBus()
+	monitor := NewMonitor(manager, bus, true)
+
+	monitor.handleStateEvent(connectionstate.AppEventConnectionState{
+		State:       connectionstate.Connected,
+		SessionInfo: connectionstate.Status{SessionID: "s1"},
+	})
+	assert.True(t, manager.disconnected)
+	assert.Equal(t, AppEventLeakTest{SessionID: "s1", Report: Report{Leaked: true}, Disconnected: true}, bus.Pop())
+
+	manager = &mockConnectionManager{sessionID: "s1"}
+	monitor = NewMonitor(manager, bus, true)
+	monitor.handleStateEvent(connectionstate.AppEventConnectionState{
+		State:       connectionstate.Connected,
+		SessionInfo: connectionstate.Status{SessionID: "s1"},
+	})
+	assert.False(t, manager.disconnected)
+}
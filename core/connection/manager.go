@@ -23,6 +23,8 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -31,10 +33,15 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/connection/diagnostics"
+	"github.com/mysteriumnetwork/node/core/connection/leaktest"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/core/speedtest"
+	"github.com/mysteriumnetwork/node/core/supervision"
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
@@ -43,6 +50,7 @@ import (
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
+	sevent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/trace"
 )
 
@@ -65,6 +73,18 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	// ErrUnlockRequired indicates that the consumer identity has not been unlocked yet
 	ErrUnlockRequired = errors.New("unlock required")
+	// ErrConfigExportUnsupported indicates that active connection is not able to export its configuration
+	ErrConfigExportUnsupported = errors.New("config export is not supported by the connection")
+	// ErrLeakTestUnsupported indicates that proxy connections do not carry the system traffic to be tested for leaks
+	ErrLeakTestUnsupported = errors.New("leak test is not supported for proxy connections")
+	// ErrSpeedtestUnsupported indicates that the tunnel of the connection does not reach the provider's echo endpoint
+	ErrSpeedtestUnsupported = errors.New("speedtest is not supported by the connection")
+	// ErrUnknownBandwidthTier indicates that requested bandwidth tier is not advertised in proposal.
+	ErrUnknownBandwidthTier = errors.New("bandwidth tier is not advertised in proposal")
+	// ErrBandwidthTierRequired indicates that proposal advertises bandwidth tiers, but none of them was chosen.
+	ErrBandwidthTierRequired = errors.New("proposal requires choosing one of its bandwidth tiers")
+	// ErrConsumerCountryNotAllowed indicates that provider does not accept consumers from the origin country.
+	ErrConsumerCountryNotAllowed = errors.New("provider does not allow consumers from this country")
 )
 
 // IPCheckConfig contains common params for connection ip check.
@@ -75,9 +95,9 @@ type IPCheckConfig struct {
 
 // KeepAliveConfig contains keep alive options.
 type KeepAliveConfig struct {
-	SendInterval    time.Duration
-	SendTimeout     time.Duration
-	MaxSendErrCount int
+	SendInterval time.Duration
+	SendTimeout  time.Duration
+	DeadPeer     p2p.DeadPeerConfig
 }
 
 // Config contains common configuration options for connection manager.
@@ -94,9 +114,12 @@ func DefaultConfig() Config {
 			SleepDurationAfterCheck: 3 * time.Second,
 		},
 		KeepAlive: KeepAliveConfig{
-			SendInterval:    5 * time.Second,
-			SendTimeout:     5 * time.Second,
-			MaxSendErrCount: 3,
+			SendInterval: 5 * time.Second,
+			SendTimeout:  5 * time.Second,
+			DeadPeer: p2p.DeadPeerConfig{
+				MaxConsecutiveLoss: 3,
+				Window:             10,
+			},
 		},
 	}
 }
@@ -140,9 +163,10 @@ type connectionManager struct {
 	ipResolver           ip.Resolver
 	locationResolver     location.OriginResolver
 	config               Config
-	statsReportInterval  time.Duration
+	statsReportInterval  StatsReportInterval
 	validator            validator
 	p2pDialer            p2p.Dialer
+	localDNS             LocalDNSResolver
 	timeGetter           TimeGetter
 
 	// These are populated by Connect at runtime.
@@ -167,6 +191,8 @@ type connectionManager struct {
 
 	activeConnection Connection
 	statsTracker     statsTracker
+	diagnostics      *diagnostics.Runner
+	leakTest         *leaktest.Suite
 }
 
 // NewManager creates connection manager with given dependencies
@@ -177,9 +203,10 @@ func NewManager(
 	ipResolver ip.Resolver,
 	locationResolver location.OriginResolver,
 	config Config,
-	statsReportInterval time.Duration,
+	statsReportInterval StatsReportInterval,
 	validator validator,
 	p2pDialer p2p.Dialer,
+	localDNS LocalDNSResolver,
 	preReconnect, postReconnect func(),
 ) *connectionManager {
 	m := &connectionManager{
@@ -195,9 +222,12 @@ func NewManager(
 		statsReportInterval:  statsReportInterval,
 		validator:            validator,
 		p2pDialer:            p2pDialer,
+		localDNS:             localDNS,
 		timeGetter:           time.Now,
 		preReconnect:         preReconnect,
 		postReconnect:        postReconnect,
+		diagnostics:          diagnostics.NewRunner(),
+		leakTest:             leaktest.NewDefaultSuite(),
 	}
 
 	m.eventBus.SubscribeAsync(connectionstate.AppTopicConnectionState, m.reconnectOnHold)
@@ -212,17 +242,19 @@ func (m *connectionManager) chainID() int64 {
 func (m *connectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposalLookup ProposalLookup, params ConnectParams) (err error) {
 	var sessionID session.ID
 
-	proposal, err := proposalLookup()
-	if err != nil {
-		return fmt.Errorf("failed to lookup proposal: %w", err)
-	}
-
 	tracer := trace.NewTracer("Consumer whole Connect")
 	defer func() {
 		traceResult := tracer.Finish(m.eventBus, string(sessionID))
 		log.Debug().Msgf("Consumer connection trace: %s", traceResult)
 	}()
 
+	traceLookup := tracer.StartStage("Consumer proposal fetch")
+	proposal, err := proposalLookup()
+	tracer.EndStage(traceLookup)
+	if err != nil {
+		return fmt.Errorf("failed to lookup proposal: %w", err)
+	}
+
 	// make sure cache is cleared when connect terminates at any stage as part of disconnect
 	// we assume that IPResolver might be used / cache IP before connect
 	m.addCleanup(func() error {
@@ -234,9 +266,19 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		return ErrAlreadyExists
 	}
 
+	// Origin country may be unknown yet, provider makes the final decision in that case.
+	if country := m.locationResolver.GetOrigin().Country; country != "" && !proposal.AllowsConsumerCountry(country) {
+		return ErrConsumerCountryNotAllowed
+	}
+
 	prc := m.priceFromProposal(*proposal)
 
-	err = m.validator.Validate(m.chainID(), consumerID, prc)
+	agreed, err := tieredPrice(*proposal, params, prc)
+	if err != nil {
+		return err
+	}
+
+	err = m.validator.Validate(m.chainID(), consumerID, agreed)
 	if err != nil {
 		return err
 	}
@@ -259,6 +301,7 @@ func (m *connectionManager) Connect(consumerID identity.Identity, hermesID commo
 		Proposal:       *proposal,
 		ProposalLookup: proposalLookup,
 		Params:         params,
+		DNSResolver:    m.localDNS,
 	}
 
 	m.activeConnection, err = m.newConnection(proposal.ServiceType)
@@ -349,6 +392,23 @@ func (m *connectionManager) priceFromProposal(proposal proposal.PricedServicePro
 	return p
 }
 
+// tieredPrice returns the price of the bandwidth tier selected in params, provider
+// validates the proposal price sent along with the tier name and invoices for the tier price.
+func tieredPrice(p proposal.PricedServiceProposal, params ConnectParams, prc market.Price) (market.Price, error) {
+	if params.BandwidthTier == "" {
+		if len(p.BandwidthTiers) > 0 {
+			return market.Price{}, ErrBandwidthTierRequired
+		}
+		return prc, nil
+	}
+
+	tier, ok := p.BandwidthTier(params.BandwidthTier)
+	if !ok {
+		return market.Price{}, fmt.Errorf("%w: %s", ErrUnknownBandwidthTier, params.BandwidthTier)
+	}
+	return tier.Price(prc), nil
+}
+
 func (m *connectionManager) initSession(tracer *trace.Tracer, prc market.Price) (sessionID session.ID, err error) {
 	err = m.createP2PChannel(m.connectOptions, tracer)
 	if err != nil {
@@ -358,20 +418,27 @@ func (m *connectionManager) initSession(tracer *trace.Tracer, prc market.Price)
 	m.connectOptions.ProviderNATConn = m.channel.ServiceConn()
 	m.connectOptions.ChannelConn = m.channel.Conn()
 
-	paymentSession, err := m.paymentLoop(m.connectOptions, prc)
+	agreed, err := tieredPrice(m.connectOptions.Proposal, m.connectOptions.Params, prc)
+	if err != nil {
+		return sessionID, err
+	}
+
+	paymentSession, err := m.paymentLoop(m.connectOptions, agreed)
 	if err != nil {
 		return sessionID, err
 	}
 
 	sessionDTO, err := m.createP2PSession(m.activeConnection, m.connectOptions, tracer, prc)
-	sessionID = session.ID(sessionDTO.GetID())
+	sessionID = session.ID(sessionDTO.ID)
 	if err != nil {
 		m.sendSessionStatus(m.channel, m.connectOptions.ConsumerID, sessionID, connectivity.StatusSessionEstablishmentFailed, err)
 		return sessionID, err
 	}
 
 	traceStart := tracer.StartStage("Consumer session creation (start)")
-	go m.keepAliveLoop(m.channel, sessionID)
+	go m.keepAliveLoop(m.channel, sessionID, identity.FromAddress(m.connectOptions.Proposal.ProviderID))
+	m.handleSessionTerminate(m.channel, sessionID)
+	m.handleSessionResume(m.channel, sessionID)
 	m.setStatus(func(status *connectionstate.Status) {
 		status.SessionID = sessionID
 	})
@@ -380,7 +447,7 @@ func (m *connectionManager) initSession(tracer *trace.Tracer, prc market.Price)
 	tracer.EndStage(traceStart)
 
 	m.connectOptions.SessionID = sessionID
-	m.connectOptions.SessionConfig = sessionDTO.GetConfig()
+	m.connectOptions.SessionConfig = sessionDTO.Config
 
 	return sessionID, nil
 }
@@ -487,9 +554,10 @@ func (m *connectionManager) paymentLoop(opts ConnectOptions, price market.Price)
 	})
 
 	go func() {
-		err := payments.Start()
+		err := supervision.Run(payments.Start)
 		if err != nil {
 			log.Error().Err(err).Msg("Payment error")
+			m.publishIssue(connectionstate.IssuePaymentFailed, err)
 
 			if config.GetBool(config.FlagKeepConnectedOnFail) {
 				m.statusOnHold()
@@ -572,10 +640,15 @@ func (m *connectionManager) addCleanup(fn func() error) {
 	m.cleanup = append(m.cleanup, fn)
 }
 
-func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions, tracer *trace.Tracer, requestedPrice market.Price) (*pb.SessionResponse, error) {
+func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions, tracer *trace.Tracer, requestedPrice market.Price) (*dto.SessionResponse, error) {
 	trace := tracer.StartStage("Consumer session creation")
 	defer tracer.EndStage(trace)
 
+	chargePeriod := opts.Params.ChargePeriod
+	if chargePeriod == 0 {
+		chargePeriod = config.GetDuration(config.FlagPaymentsConsumerChargePeriod)
+	}
+
 	sessionCreateConfig, err := c.GetConfig()
 	if err != nil {
 		return nil, fmt.Errorf("could not get session config: %w", err)
@@ -586,42 +659,45 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 		return nil, fmt.Errorf("could not marshal session config: %w", err)
 	}
 
-	sessionRequest := &pb.SessionRequest{
-		Consumer: &pb.ConsumerInfo{
-			Id:             opts.ConsumerID.Address,
+	sessionRequest := &dto.SessionRequest{
+		Consumer: dto.ConsumerInfo{
+			ID:             opts.ConsumerID.Address,
 			HermesID:       opts.HermesID.Hex(),
 			PaymentVersion: "v3",
-			Location: &pb.LocationInfo{
-				Country: m.Status().ConsumerLocation.Country,
-			},
-			Pricing: &pb.Pricing{
-				PerGib:  requestedPrice.PricePerGiB.Bytes(),
-				PerHour: requestedPrice.PricePerHour.Bytes(),
-			},
+			Country:        m.Status().ConsumerLocation.Country,
+			PricePerHour:   requestedPrice.PricePerHour,
+			PricePerGiB:    requestedPrice.PricePerGiB,
+			Attestation:    opts.Params.AttestationToken,
+			ChargePeriod:   chargePeriod,
+			BandwidthTier:  opts.Params.BandwidthTier,
 		},
 		ProposalID: opts.Proposal.ID,
 		Config:     config,
 	}
-	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionCreate, sessionRequest.String())
+	codec := m.channel.Codec()
+	log.Debug().Msgf("Sending P2P message to %q: %+v", p2p.TopicSessionCreate, sessionRequest)
 	ctx, cancel := context.WithTimeout(m.currentCtx(), 20*time.Second)
 	defer cancel()
-	res, err := m.channel.Send(ctx, p2p.TopicSessionCreate, p2p.ProtoMessage(sessionRequest))
+	res, err := m.channel.Send(ctx, p2p.TopicSessionCreate, p2p.DTOMessage(codec, sessionRequest))
 	if err != nil {
 		return nil, fmt.Errorf("could not send p2p session create request: %w", err)
 	}
 
-	var sessionResponse pb.SessionResponse
-	err = res.UnmarshalProto(&sessionResponse)
+	var sessionResponse dto.SessionResponse
+	err = res.UnmarshalDTO(codec, &sessionResponse)
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal session reply to proto: %w", err)
+		return nil, fmt.Errorf("could not unmarshal session reply: %w", err)
 	}
 	log.Info().Msgf("Provider's session config: %s", string(sessionResponse.Config))
+	if sessionResponse.ChargePeriod > 0 {
+		log.Info().Msgf("Provider charges every %s", sessionResponse.ChargePeriod)
+	}
 
 	channel := m.channel
 	m.acknowledge = func() {
 		pc := &pb.SessionInfo{
 			ConsumerID: opts.ConsumerID.Address,
-			SessionID:  sessionResponse.GetID(),
+			SessionID:  sessionResponse.ID,
 		}
 		log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionAcknowledge, pc.String())
 		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
@@ -637,7 +713,7 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 
 		sessionDestroy := &pb.SessionInfo{
 			ConsumerID: opts.ConsumerID.Address,
-			SessionID:  sessionResponse.GetID(),
+			SessionID:  sessionResponse.ID,
 		}
 
 		log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionDestroy, sessionDestroy.String())
@@ -654,6 +730,57 @@ func (m *connectionManager) createP2PSession(c Connection, opts ConnectOptions,
 	return &sessionResponse, nil
 }
 
+func (m *connectionManager) handleSessionTerminate(channel p2p.ChannelHandler, sessionID session.ID) {
+	channel.Handle(p2p.TopicSessionTerminate, func(c p2p.Context) error {
+		var st pb.SessionTerminate
+		if err := c.Request().UnmarshalProto(&st); err != nil {
+			return err
+		}
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionTerminate, st.String())
+
+		if session.ID(st.GetSessionID()) != sessionID {
+			return fmt.Errorf("session %s is not active", st.GetSessionID())
+		}
+
+		reason := session.TerminationReason(st.GetReason())
+		log.Warn().Msgf("Session %s terminated by provider, reason: %s, message: %q", sessionID, reason, st.GetMessage())
+		m.setStatus(func(status *connectionstate.Status) {
+			status.DisconnectReason = reason
+		})
+		m.eventBus.Publish(sevent.AppTopicSessionTerminated, sevent.AppEventSessionTerminated{
+			ID:      string(sessionID),
+			Reason:  reason,
+			Message: st.GetMessage(),
+		})
+
+		// Disconnect asynchronously, as it tears down the channel we're replying on.
+		go func() {
+			logDisconnectError(m.Disconnect())
+		}()
+		return c.OK()
+	})
+}
+
+func (m *connectionManager) handleSessionResume(channel p2p.ChannelHandler, sessionID session.ID) {
+	channel.Handle(p2p.TopicSessionResume, func(c p2p.Context) error {
+		var si pb.SessionInfo
+		if err := c.Request().UnmarshalProto(&si); err != nil {
+			return err
+		}
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionResume, si.String())
+
+		if session.ID(si.GetSessionID()) != sessionID {
+			return fmt.Errorf("session %s is not active", si.GetSessionID())
+		}
+
+		log.Info().Msgf("Session %s resumed by provider", sessionID)
+		m.setStatus(func(status *connectionstate.Status) {
+			status.Paused = false
+		})
+		return c.OK()
+	})
+}
+
 func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
 	m.eventBus.Publish(connectionstate.AppTopicConnectionSession, connectionstate.AppEventConnectionSession{
 		Status:      connectionstate.SessionCreatedStatus,
@@ -788,12 +915,135 @@ func (m *connectionManager) Disconnect() error {
 }
 
 func (m *connectionManager) CheckChannel(ctx context.Context) error {
-	if err := m.sendKeepAlivePing(ctx, m.channel, m.Status().SessionID); err != nil {
+	if _, err := m.sendKeepAlivePing(ctx, m.channel, m.Status().SessionID); err != nil {
 		return fmt.Errorf("keep alive ping failed: %w", err)
 	}
 	return nil
 }
 
+func (m *connectionManager) Diagnose(ctx context.Context) (diagnostics.Report, error) {
+	if m.Status().State != connectionstate.Connected {
+		return diagnostics.Report{}, ErrNoConnection
+	}
+
+	target := diagnostics.Target{
+		OriginalPublicIP: m.locationResolver.GetOrigin().IP,
+		PublicIP:         m.ipResolver.GetPublicIP,
+	}
+	if proxyPort := m.connectOptions.Params.ProxyPort; proxyPort > 0 {
+		target.PublicIP = func() (string, error) {
+			return m.ipResolver.GetProxyIP(proxyPort)
+		}
+	}
+	if tip, ok := m.activeConnection.(TunnelInfoProvider); ok {
+		if info, ok := tip.TunnelInfo(); ok {
+			target.ProviderIP = info.ProviderIP
+			target.DNSServers = info.DNSServers
+		}
+	}
+
+	return m.diagnostics.Run(ctx, target), nil
+}
+
+func (m *connectionManager) LeakTest(ctx context.Context) (leaktest.Report, error) {
+	if m.Status().State != connectionstate.Connected {
+		return leaktest.Report{}, ErrNoConnection
+	}
+	if m.connectOptions.Params.ProxyPort > 0 {
+		return leaktest.Report{}, ErrLeakTestUnsupported
+	}
+
+	target := leaktest.Target{
+		OriginalPublicIP: m.locationResolver.GetOrigin().IP,
+		PublicIP:         m.ipResolver.GetPublicIP,
+	}
+	if tip, ok := m.activeConnection.(TunnelInfoProvider); ok {
+		if info, ok := tip.TunnelInfo(); ok {
+			target.DNSServers = info.DNSServers
+		}
+	}
+
+	return m.leakTest.Run(ctx, target), nil
+}
+
+func (m *connectionManager) Speedtest(ctx context.Context, duration time.Duration) (speedtest.Result, error) {
+	status := m.Status()
+	if status.State != connectionstate.Connected {
+		return speedtest.Result{}, ErrNoConnection
+	}
+
+	tip, ok := m.activeConnection.(TunnelInfoProvider)
+	if !ok {
+		return speedtest.Result{}, ErrSpeedtestUnsupported
+	}
+	info, ok := tip.TunnelInfo()
+	if !ok || info.ProviderIP == nil {
+		return speedtest.Result{}, ErrSpeedtestUnsupported
+	}
+
+	result, err := speedtest.Run(ctx, net.JoinHostPort(info.ProviderIP.String(), strconv.Itoa(speedtest.Port)), duration)
+	if err != nil {
+		return speedtest.Result{}, err
+	}
+
+	m.eventBus.Publish(connectionstate.AppTopicConnectionSpeedtest, connectionstate.AppEventConnectionSpeedtest{
+		SessionInfo: status,
+		Result:      result,
+	})
+	return result, nil
+}
+
+func (m *connectionManager) ExportConfig() (string, error) {
+	if m.Status().State != connectionstate.Connected {
+		return "", ErrNoConnection
+	}
+
+	exporter, ok := m.activeConnection.(ConfigExporter)
+	if !ok {
+		return "", ErrConfigExportUnsupported
+	}
+	return exporter.ExportConfig()
+}
+
+func (m *connectionManager) Pause() error {
+	return m.setPaused(true)
+}
+
+func (m *connectionManager) Resume() error {
+	return m.setPaused(false)
+}
+
+func (m *connectionManager) setPaused(paused bool) error {
+	status := m.Status()
+	if status.State != connectionstate.Connected {
+		return ErrNoConnection
+	}
+	if status.Paused == paused {
+		return nil
+	}
+
+	topic := p2p.TopicSessionResume
+	if paused {
+		topic = p2p.TopicSessionPause
+	}
+	msg := &pb.SessionInfo{
+		ConsumerID: status.ConsumerID.Address,
+		SessionID:  string(status.SessionID),
+	}
+
+	log.Debug().Msgf("Sending P2P message to %q: %s", topic, msg.String())
+	ctx, cancel := context.WithTimeout(m.currentCtx(), 20*time.Second)
+	defer cancel()
+	if _, err := m.channel.Send(ctx, topic, p2p.ProtoMessage(msg)); err != nil {
+		return fmt.Errorf("could not send %s request: %w", topic, err)
+	}
+
+	m.setStatus(func(status *connectionstate.Status) {
+		status.Paused = paused
+	})
+	return nil
+}
+
 func (m *connectionManager) disconnect() {
 	m.discoLock.Lock()
 	defer m.discoLock.Unlock()
@@ -913,7 +1163,15 @@ func (m *connectionManager) publishStateEvent(state connectionstate.State) {
 	})
 }
 
-func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session.ID) {
+func (m *connectionManager) publishIssue(issue connectionstate.Issue, err error) {
+	m.eventBus.Publish(connectionstate.AppTopicConnectionIssue, connectionstate.AppEventConnectionIssue{
+		Issue:       issue,
+		SessionInfo: m.Status(),
+		Error:       err.Error(),
+	})
+}
+
+func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session.ID, providerID identity.Identity) {
 	// Register handler for handling p2p keep alive pings from provider.
 	channel.Handle(p2p.TopicKeepAlive, func(c p2p.Context) error {
 		var ping pb.P2PKeepAlivePing
@@ -926,7 +1184,7 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 	})
 
 	// Send pings to provider.
-	var errCount int
+	stats := p2p.NewKeepAliveStats(m.config.KeepAlive.DeadPeer.Window)
 	for {
 		select {
 		case <-m.currentCtx().Done():
@@ -934,28 +1192,41 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 			return
 		case <-time.After(m.config.KeepAlive.SendInterval):
 			ctx, cancel := context.WithTimeout(context.Background(), m.config.KeepAlive.SendTimeout)
-			if err := m.sendKeepAlivePing(ctx, channel, sessionID); err != nil {
+			rtt, err := m.sendKeepAlivePing(ctx, channel, sessionID)
+			cancel()
+			if err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sessionID)
-				errCount++
-				if errCount == m.config.KeepAlive.MaxSendErrCount {
-					log.Error().Msgf("Max p2p keepalive err count reached, disconnecting. SessionID=%s", sessionID)
-					if config.GetBool(config.FlagKeepConnectedOnFail) {
-						m.statusOnHold()
-					} else {
-						m.Disconnect()
-					}
-					cancel()
-					return
-				}
+				stats.RecordLoss()
 			} else {
-				errCount = 0
+				stats.RecordRTT(rtt)
 			}
-			cancel()
+
+			snapshot := stats.Snapshot()
+			if !m.config.KeepAlive.DeadPeer.Unresponsive(snapshot) {
+				continue
+			}
+
+			log.Error().Msgf("Provider stopped responding to p2p keepalive pings, disconnecting. SessionID=%s, stats: %+v", sessionID, snapshot)
+			m.eventBus.Publish(p2p.AppTopicPeerUnresponsive, p2p.AppEventPeerUnresponsive{
+				SessionID: string(sessionID),
+				PeerID:    providerID,
+				Stats:     snapshot,
+			})
+			if err == nil {
+				err = errors.New("provider stopped responding to keepalive pings")
+			}
+			m.publishIssue(connectionstate.IssueKeepAliveFailed, err)
+			if config.GetBool(config.FlagKeepConnectedOnFail) {
+				m.statusOnHold()
+			} else {
+				m.Disconnect()
+			}
+			return
 		}
 	}
 }
 
-func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.Channel, sessionID session.ID) error {
+func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.Channel, sessionID session.ID) (time.Duration, error) {
 	msg := &pb.P2PKeepAlivePing{
 		SessionID: string(sessionID),
 	}
@@ -963,15 +1234,16 @@ func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.C
 	start := time.Now()
 	_, err := channel.Send(ctx, p2p.TopicKeepAlive, p2p.ProtoMessage(msg))
 	if err != nil {
-		return err
+		return 0, err
 	}
 
+	rtt := time.Since(start)
 	m.eventBus.Publish(quality.AppTopicConsumerPingP2P, quality.PingEvent{
 		SessionID: string(sessionID),
-		Duration:  time.Since(start),
+		Duration:  rtt,
 	})
 
-	return nil
+	return rtt, nil
 }
 
 func (m *connectionManager) currentCtx() context.Context {
@@ -38,6 +38,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
@@ -58,7 +59,7 @@ type testContext struct {
 	fakeIPResolver        ip.Resolver
 	fakeLocationResolver  location.OriginResolver
 	config                Config
-	statsReportInterval   time.Duration
+	statsReportInterval   StatsReportInterval
 	mockP2P               *mockP2PDialer
 	mockTime              time.Time
 	sync.RWMutex
@@ -127,13 +128,15 @@ func (tc *testContext) SetupTest() {
 			SleepDurationAfterCheck: 1 * time.Millisecond,
 		},
 		KeepAlive: KeepAliveConfig{
-			SendInterval:    100 * time.Millisecond,
-			MaxSendErrCount: 5,
+			SendInterval: 100 * time.Millisecond,
+			DeadPeer: p2p.DeadPeerConfig{
+				MaxConsecutiveLoss: 5,
+			},
 		},
 	}
 	tc.fakeIPResolver = ip.NewResolverMock("ip")
 	tc.fakeLocationResolver = &mockLocationResolver{}
-	tc.statsReportInterval = 1 * time.Millisecond
+	tc.statsReportInterval = StatsReportInterval{Active: time.Millisecond, Idle: time.Millisecond}
 
 	brokerConn := nats.StartConnectionMock()
 	brokerConn.MockResponse("fake-node-1.p2p-config-exchange", []byte("123"))
@@ -157,6 +160,7 @@ func (tc *testContext) SetupTest() {
 		tc.statsReportInterval,
 		&mockValidator{},
 		tc.mockP2P,
+		nil,
 		func() {}, func() {},
 	)
 	tc.connManager.timeGetter = func() time.Time {
@@ -336,6 +340,21 @@ func (tc *testContext) TestConnectFailsIfConnectionFactoryReturnsError() {
 	assert.Error(tc.T(), tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{}))
 }
 
+func (tc *testContext) TestConnectFailsIfConsumerCountryIsNotAllowed() {
+	restricted := activeProposal
+	restricted.ServiceProposal = market.NewProposal(activeProviderID.Address, activeServiceType, market.NewProposalOpts{
+		Contacts:       []market.Contact{activeProviderContact},
+		AccessPolicies: []market.AccessPolicy{market.NewConsumerCountryPolicy([]string{"DE"})},
+	})
+	lookup := func() (*proposal.PricedServiceProposal, error) {
+		return &restricted, nil
+	}
+
+	err := tc.connManager.Connect(consumerID, hermesID, lookup, ConnectParams{})
+	assert.ErrorIs(tc.T(), err, ErrConsumerCountryNotAllowed)
+	assert.Equal(tc.T(), connectionstate.NotConnected, tc.connManager.Status().State)
+}
+
 func (tc *testContext) TestStatusIsConnectedWhenConnectCommandReturnsWithoutError() {
 	tc.connManager.Connect(consumerID, hermesID, activeProposalLookup, ConnectParams{})
 	assert.Equal(
@@ -569,6 +588,29 @@ func (tc *testContext) Test_ManagerNotifiesAboutSuccessfulConnection() {
 	)
 }
 
+func TestTieredPrice(t *testing.T) {
+	p := proposal.PricedServiceProposal{
+		ServiceProposal: market.NewProposal("0x1", "wireguard", market.NewProposalOpts{
+			BandwidthTiers: []market.BandwidthTier{{Name: "basic", Bandwidth: 1250, PricePercent: 50}},
+		}),
+	}
+	base := market.Price{PricePerHour: big.NewInt(10), PricePerGiB: big.NewInt(100)}
+
+	prc, err := tieredPrice(proposal.PricedServiceProposal{ServiceProposal: market.NewProposal("0x1", "wireguard", market.NewProposalOpts{})}, ConnectParams{}, base)
+	assert.NoError(t, err)
+	assert.Equal(t, base, prc)
+
+	_, err = tieredPrice(p, ConnectParams{}, base)
+	assert.ErrorIs(t, err, ErrBandwidthTierRequired)
+
+	prc, err = tieredPrice(p, ConnectParams{BandwidthTier: "basic"}, base)
+	assert.NoError(t, err)
+	assert.Equal(t, market.Price{PricePerHour: big.NewInt(5), PricePerGiB: big.NewInt(50)}, prc)
+
+	_, err = tieredPrice(p, ConnectParams{BandwidthTier: "premium"}, base)
+	assert.ErrorIs(t, err, ErrUnknownBandwidthTier)
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
@@ -634,6 +676,10 @@ func (m *mockP2PChannel) Conn() *net.UDPConn {
 	return &net.UDPConn{}
 }
 
+func (m *mockP2PChannel) PeerAddr() *net.UDPAddr {
+	return &net.UDPAddr{}
+}
+
 func (m *mockP2PChannel) getSentMsg() proto.Message {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -678,6 +724,10 @@ func (m *mockP2PChannel) Close() error {
 	return nil
 }
 
+func (m *mockP2PChannel) Codec() dto.Codec {
+	return dto.CodecFor(dto.EncodingProtobuf)
+}
+
 func (m *mockP2PChannel) ID() string {
 	return fmt.Sprintf("%p", m)
 }
@@ -20,11 +20,15 @@ package connection
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/connection/diagnostics"
+	"github.com/mysteriumnetwork/node/core/connection/leaktest"
+	"github.com/mysteriumnetwork/node/core/speedtest"
 	"github.com/mysteriumnetwork/node/identity"
 )
 
@@ -121,3 +125,81 @@ func (mcm *multiConnectionManager) Reconnect(id int) {
 		m.Reconnect()
 	}
 }
+
+// Diagnose runs tunnel internal checks of given connection, reports error if no connection.
+func (mcm *multiConnectionManager) Diagnose(ctx context.Context, id int) (diagnostics.Report, error) {
+	mcm.mu.RLock()
+	m, ok := mcm.cms[id]
+	mcm.mu.RUnlock()
+
+	if !ok {
+		return diagnostics.Report{}, ErrNoConnection
+	}
+
+	return m.Diagnose(ctx)
+}
+
+// LeakTest checks whether traffic of given connection bypasses the tunnel, reports error if no connection.
+func (mcm *multiConnectionManager) LeakTest(ctx context.Context, id int) (leaktest.Report, error) {
+	mcm.mu.RLock()
+	m, ok := mcm.cms[id]
+	mcm.mu.RUnlock()
+
+	if !ok {
+		return leaktest.Report{}, ErrNoConnection
+	}
+
+	return m.LeakTest(ctx)
+}
+
+// Speedtest measures throughput of given connection against the provider's echo endpoint, reports error if no connection.
+func (mcm *multiConnectionManager) Speedtest(ctx context.Context, id int, duration time.Duration) (speedtest.Result, error) {
+	mcm.mu.RLock()
+	m, ok := mcm.cms[id]
+	mcm.mu.RUnlock()
+
+	if !ok {
+		return speedtest.Result{}, ErrNoConnection
+	}
+
+	return m.Speedtest(ctx, duration)
+}
+
+// ExportConfig exports negotiated tunnel configuration of given connection, reports error if no connection.
+func (mcm *multiConnectionManager) ExportConfig(id int) (string, error) {
+	mcm.mu.RLock()
+	m, ok := mcm.cms[id]
+	mcm.mu.RUnlock()
+
+	if !ok {
+		return "", ErrNoConnection
+	}
+
+	return m.ExportConfig()
+}
+
+// Pause asks provider to stop billing and throttle the given session, reports error if no connection.
+func (mcm *multiConnectionManager) Pause(id int) error {
+	mcm.mu.RLock()
+	m, ok := mcm.cms[id]
+	mcm.mu.RUnlock()
+
+	if !ok {
+		return ErrNoConnection
+	}
+
+	return m.Pause()
+}
+
+// Resume asks provider to continue the given paused session, reports error if no connection.
+func (mcm *multiConnectionManager) Resume(id int) error {
+	mcm.mu.RLock()
+	m, ok := mcm.cms[id]
+	mcm.mu.RUnlock()
+
+	if !ok {
+		return ErrNoConnection
+	}
+
+	return m.Resume()
+}
@@ -28,8 +28,13 @@ type proposalRepository interface {
 	Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error)
 }
 
+type providerBlacklist interface {
+	IsBlacklisted(providerID string) bool
+}
+
 // FilteredProposals create an function to keep getting proposals from the discovery based on the provided filters.
-func FilteredProposals(f *proposal.Filter, sortBy string, repo proposalRepository) func() (*proposal.PricedServiceProposal, error) {
+// Providers blacklisted for poor quality are skipped unless the filter explicitly asks for them.
+func FilteredProposals(f *proposal.Filter, sortBy string, repo proposalRepository, blacklist providerBlacklist) func() (*proposal.PricedServiceProposal, error) {
 	usedProposals := make(map[string]time.Time)
 
 	return func() (*proposal.PricedServiceProposal, error) {
@@ -43,6 +48,10 @@ func FilteredProposals(f *proposal.Filter, sortBy string, repo proposalRepositor
 			return nil, fmt.Errorf("failed to sort proposals: %w", err)
 		}
 
+		if blacklist != nil && len(f.ProviderIDs) == 0 {
+			proposals = withoutBlacklisted(proposals, blacklist)
+		}
+
 		for _, p := range proposals { // Trying to find providers that we didn't try to connect during 5 minutes.
 			if t, ok := usedProposals[p.ProviderID]; !ok || time.Since(t) > 5*time.Minute {
 				usedProposals[p.ProviderID] = time.Now()
@@ -58,3 +67,13 @@ func FilteredProposals(f *proposal.Filter, sortBy string, repo proposalRepositor
 		return nil, fmt.Errorf("no providers available for the filter")
 	}
 }
+
+func withoutBlacklisted(proposals []proposal.PricedServiceProposal, blacklist providerBlacklist) []proposal.PricedServiceProposal {
+	result := make([]proposal.PricedServiceProposal, 0, len(proposals))
+	for _, p := range proposals {
+		if !blacklist.IsBlacklisted(p.ProviderID) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
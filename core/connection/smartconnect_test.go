@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type mockProposalRepository struct {
+	proposals []proposal.PricedServiceProposal
+}
+
+func (m *mockProposalRepository) Proposals(*proposal.Filter) ([]proposal.PricedServiceProposal, error) {
+	return m.proposals, nil
+}
+
+type mockBlacklist map[string]bool
+
+func (m mockBlacklist) IsBlacklisted(providerID string) bool {
+	return m[providerID]
+}
+
+func testProposal(providerID string) proposal.PricedServiceProposal {
+	return proposal.PricedServiceProposal{ServiceProposal: market.ServiceProposal{ProviderID: providerID}}
+}
+
+func TestFilteredProposals_SkipsBlacklistedProviders(t *testing.T) {
+	repo := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{testProposal("0x1"), testProposal("0x2")}}
+	lookup := FilteredProposals(&proposal.Filter{}, "", repo, mockBlacklist{"0x1": true})
+
+	p, err := lookup()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x2", p.ProviderID)
+
+	p, err = lookup()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x2", p.ProviderID)
+}
+
+func TestFilteredProposals_KeepsExplicitlyRequestedProviders(t *testing.T) {
+	repo := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{testProposal("0x1")}}
+	lookup := FilteredProposals(&proposal.Filter{ProviderIDs: []string{"0x1"}}, "", repo, mockBlacklist{"0x1": true})
+
+	p, err := lookup()
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", p.ProviderID)
+}
+
+func TestFilteredProposals_FailsWhenAllProvidersBlacklisted(t *testing.T) {
+	repo := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{testProposal("0x1")}}
+	lookup := FilteredProposals(&proposal.Filter{}, "", repo, mockBlacklist{"0x1": true})
+
+	_, err := lookup()
+	assert.Error(t, err)
+}
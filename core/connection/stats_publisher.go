@@ -27,8 +27,33 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 )
 
+// StatsReportInterval bounds the adaptive interval of consumer connection statistics reporting.
+// Statistics are sampled every Active interval while traffic flows, sampling backs off up to
+// the Idle interval while the connection stays idle.
+type StatsReportInterval struct {
+	Active time.Duration
+	Idle   time.Duration
+}
+
 // DefaultStatsReportInterval is interval for consumer connection statistics reporting.
-const DefaultStatsReportInterval = 10 * time.Second
+// Faster sampling of active connections is opt-in, see config.FlagStatsReportInterval.
+var DefaultStatsReportInterval = StatsReportInterval{
+	Active: 10 * time.Second,
+	Idle:   30 * time.Second,
+}
+
+// next returns the interval to wait before taking the sample following the given delta.
+func (i StatsReportInterval) next(current time.Duration, delta connectionstate.Statistics) time.Duration {
+	if delta.BytesSent > 0 || delta.BytesReceived > 0 {
+		return i.Active
+	}
+
+	current *= 2
+	if current > i.Idle {
+		return i.Idle
+	}
+	return current
+}
 
 type statsSupplier interface {
 	Statistics() (connectionstate.Statistics, error)
@@ -37,13 +62,13 @@ type statsSupplier interface {
 type statsTracker struct {
 	done     chan struct{}
 	bus      eventbus.Publisher
-	interval time.Duration
+	interval StatsReportInterval
 
 	mu        sync.RWMutex
 	lastStats connectionstate.Statistics
 }
 
-func newStatsTracker(bus eventbus.Publisher, interval time.Duration) statsTracker {
+func newStatsTracker(bus eventbus.Publisher, interval StatsReportInterval) statsTracker {
 	return statsTracker{
 		done:     make(chan struct{}),
 		bus:      bus,
@@ -52,24 +77,29 @@ func newStatsTracker(bus eventbus.Publisher, interval time.Duration) statsTracke
 }
 
 func (s *statsTracker) start(sessionSupplier *connectionManager, statsSupplier statsSupplier) {
+	interval := s.interval.Active
 	for {
 		select {
-		case <-time.After(s.interval):
+		case <-time.After(interval):
 			stats, err := statsSupplier.Statistics()
 			if err != nil {
 				log.Warn().Err(err).Msg("Could not get connection statistics")
 				continue
 			}
 
+			s.mu.Lock()
+			delta := s.lastStats.Diff(stats)
+			s.lastStats = stats
+			s.mu.Unlock()
+
+			interval = s.interval.next(interval, delta)
+
 			s.bus.Publish(connectionstate.AppTopicConnectionStatistics, connectionstate.AppEventConnectionStatistics{
 				Stats:       stats,
+				Delta:       delta,
 				SessionInfo: sessionSupplier.Status(),
 			})
 
-			s.mu.Lock()
-			s.lastStats = stats
-			s.mu.Unlock()
-
 		case <-s.done:
 			log.Info().Msg("Stopped publishing connection statistics")
 			return
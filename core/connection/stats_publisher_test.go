@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/mocks"
+)
+
+func TestStatsReportInterval_Next(t *testing.T) {
+	interval := StatsReportInterval{Active: time.Second, Idle: 5 * time.Second}
+	idle := connectionstate.Statistics{}
+	active := connectionstate.Statistics{BytesReceived: 1}
+
+	current := interval.Active
+	current = interval.next(current, idle)
+	assert.Equal(t, 2*time.Second, current)
+	current = interval.next(current, idle)
+	assert.Equal(t, 4*time.Second, current)
+	current = interval.next(current, idle)
+	assert.Equal(t, 5*time.Second, current)
+	current = interval.next(current, idle)
+	assert.Equal(t, 5*time.Second, current)
+
+	current = interval.next(current, active)
+	assert.Equal(t, time.Second, current)
+}
+
+type constantStatsSupplier struct {
+	stats connectionstate.Statistics
+}
+
+func (s *constantStatsSupplier) Statistics() (connectionstate.Statistics, error) {
+	return s.stats, nil
+}
+
+func TestStatsTracker_PublishesDeltas(t *testing.T) {
+	bus := mocks.NewEventBus()
+	tracker := newStatsTracker(bus, StatsReportInterval{Active: time.Millisecond, Idle: time.Millisecond})
+	supplier := &constantStatsSupplier{stats: connectionstate.Statistics{BytesSent: 10, BytesReceived: 20}}
+
+	go tracker.start(&connectionManager{}, supplier)
+	defer tracker.stop()
+
+	assert.Eventually(t, func() bool {
+		return len(bus.GetEventHistory()) >= 2
+	}, 2*time.Second, 5*time.Millisecond)
+
+	history := bus.GetEventHistory()
+	first := history[0].Event.(connectionstate.AppEventConnectionStatistics)
+	assert.Equal(t, uint64(10), first.Delta.BytesSent)
+	assert.Equal(t, uint64(20), first.Delta.BytesReceived)
+
+	second := history[1].Event.(connectionstate.AppEventConnectionStatistics)
+	assert.Equal(t, uint64(10), second.Stats.BytesSent)
+	assert.Zero(t, second.Delta.BytesSent)
+	assert.Zero(t, second.Delta.BytesReceived)
+}
@@ -27,6 +27,9 @@ import (
 // pingMessage structure represents message that the Provider sends about healthy Proposal
 type pingMessage struct {
 	Proposal market.ServiceProposal `json:"proposal"`
+	// TTLSeconds is how long the proposal should be considered alive unless pinged again.
+	// It is relative to the moment the message is received, so clocks of the nodes do not need to agree.
+	TTLSeconds int64 `json:"ttl_seconds,omitempty"`
 }
 
 const pingEndpoint = communication.MessageEndpoint("*.proposal-ping.v3")
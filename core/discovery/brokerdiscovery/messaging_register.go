@@ -27,6 +27,9 @@ import (
 // registerMessage structure represents message that the Provider sends about newly announced Proposal
 type registerMessage struct {
 	Proposal market.ServiceProposal `json:"proposal"`
+	// TTLSeconds is how long the proposal should be considered alive unless pinged again.
+	// It is relative to the moment the message is received, so clocks of the nodes do not need to agree.
+	TTLSeconds int64 `json:"ttl_seconds,omitempty"`
 }
 
 const registerEndpoint = communication.MessageEndpoint("*.proposal-register.v3")
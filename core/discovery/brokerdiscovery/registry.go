@@ -18,6 +18,8 @@
 package brokerdiscovery
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/identity"
@@ -25,19 +27,22 @@ import (
 )
 
 type registryBroker struct {
-	sender communication.Sender
+	sender      communication.Sender
+	proposalTTL time.Duration
 }
 
-// NewRegistry create an instance of Broker registryBroker
-func NewRegistry(connection nats.Connection) *registryBroker {
+// NewRegistry create an instance of Broker registryBroker.
+// Non zero proposalTTL makes registrations and pings carry an explicit proposal TTL.
+func NewRegistry(connection nats.Connection, proposalTTL time.Duration) *registryBroker {
 	return &registryBroker{
-		sender: nats.NewSender(connection, communication.NewCodecJSON()),
+		sender:      nats.NewSender(connection, communication.NewCodecJSON()),
+		proposalTTL: proposalTTL,
 	}
 }
 
 // RegisterProposal registers service proposal to discovery service
 func (rb *registryBroker) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	message := &registerMessage{Proposal: proposal}
+	message := &registerMessage{Proposal: proposal, TTLSeconds: rb.ttlSeconds()}
 	return rb.sender.Send(&registerProducer{message: message, signer: signer})
 }
 
@@ -49,6 +54,13 @@ func (rb *registryBroker) UnregisterProposal(proposal market.ServiceProposal, si
 
 // PingProposal pings service proposal as being alive
 func (rb *registryBroker) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	message := &pingMessage{Proposal: proposal}
+	message := &pingMessage{Proposal: proposal, TTLSeconds: rb.ttlSeconds()}
 	return rb.sender.Send(&pingProducer{message: message, signer: signer})
 }
+
+func (rb *registryBroker) ttlSeconds() int64 {
+	if rb.proposalTTL <= 0 {
+		return 0
+	}
+	return int64(rb.proposalTTL / time.Second)
+}
@@ -20,6 +20,7 @@ package brokerdiscovery
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/communication"
 	"github.com/mysteriumnetwork/node/communication/nats"
@@ -39,9 +40,10 @@ func Test_NewRegistry(t *testing.T) {
 	assert.Equal(
 		t,
 		&registryBroker{
-			sender: nats.NewSender(connection, communication.NewCodecJSON()),
+			sender:      nats.NewSender(connection, communication.NewCodecJSON()),
+			proposalTTL: time.Minute,
 		},
-		NewRegistry(connection),
+		NewRegistry(connection, time.Minute),
 	)
 }
 
@@ -49,7 +51,7 @@ func Test_Registry_RegisterProposal(t *testing.T) {
 	connection := nats.StartConnectionMock()
 	defer connection.Close()
 
-	registry := NewRegistry(connection)
+	registry := NewRegistry(connection, 0)
 	err := registry.RegisterProposal(newProposal, &identity.SignerFake{})
 	assert.NoError(t, err)
 
@@ -66,7 +68,7 @@ func Test_Registry_UnregisterProposal(t *testing.T) {
 	connection := nats.StartConnectionMock()
 	defer connection.Close()
 
-	registry := NewRegistry(connection)
+	registry := NewRegistry(connection, 0)
 	err := registry.UnregisterProposal(newProposal, &identity.SignerFake{})
 	assert.NoError(t, err)
 
@@ -83,7 +85,7 @@ func Test_Registry_PingProposal(t *testing.T) {
 	connection := nats.StartConnectionMock()
 	defer connection.Close()
 
-	registry := NewRegistry(connection)
+	registry := NewRegistry(connection, 0)
 	err := registry.PingProposal(newProposal, &identity.SignerFake{})
 	assert.NoError(t, err)
 
@@ -95,3 +97,17 @@ func Test_Registry_PingProposal(t *testing.T) {
 		string(connection.GetLastMessage()),
 	)
 }
+
+func Test_Registry_PingProposalWithExpiry(t *testing.T) {
+	connection := nats.StartConnectionMock()
+	defer connection.Close()
+
+	registry := NewRegistry(connection, time.Minute)
+	err := registry.PingProposal(newProposal, &identity.SignerFake{})
+	assert.NoError(t, err)
+
+	var message pingMessage
+	err = json.Unmarshal(connection.GetLastMessage(), &message)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60), message.TTLSeconds)
+}
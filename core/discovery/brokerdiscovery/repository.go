@@ -27,6 +27,9 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 )
 
+// maxProposalTTL caps the TTL announced by providers, so a proposal can not outlive its provider for long.
+const maxProposalTTL = time.Hour
+
 // Repository provides proposals from the broker.
 type Repository struct {
 	storage         *ProposalStorage
@@ -36,9 +39,9 @@ type Repository struct {
 	stopOnce sync.Once
 	stopChan chan struct{}
 
-	timeoutCheckStep  time.Duration
-	watchdogLock      sync.Mutex
-	timeoutCheckSeens map[market.ProposalID]time.Time
+	timeoutCheckStep time.Duration
+	watchdogLock     sync.Mutex
+	expirations      map[market.ProposalID]time.Time
 }
 
 // NewRepository constructs a new proposal repository (backed by the broker).
@@ -53,9 +56,9 @@ func NewRepository(
 		receiver:        nats.NewReceiver(connection, communication.NewCodecJSON(), "*"),
 		timeoutInterval: proposalTimeoutInterval,
 
-		stopChan:          make(chan struct{}),
-		timeoutCheckStep:  proposalCheckInterval,
-		timeoutCheckSeens: make(map[market.ProposalID]time.Time),
+		stopChan:         make(chan struct{}),
+		timeoutCheckStep: proposalCheckInterval,
+		expirations:      make(map[market.ProposalID]time.Time),
 	}
 }
 
@@ -113,10 +116,7 @@ func (r *Repository) proposalRegisterMessage(message registerMessage) error {
 	}
 
 	r.storage.AddProposal(message.Proposal)
-
-	r.watchdogLock.Lock()
-	defer r.watchdogLock.Unlock()
-	r.timeoutCheckSeens[message.Proposal.UniqueID()] = time.Now().UTC()
+	r.setExpiration(message.Proposal.UniqueID(), message.TTLSeconds)
 
 	return nil
 }
@@ -126,7 +126,7 @@ func (r *Repository) proposalUnregisterMessage(message unregisterMessage) error
 
 	r.watchdogLock.Lock()
 	defer r.watchdogLock.Unlock()
-	delete(r.timeoutCheckSeens, message.Proposal.UniqueID())
+	delete(r.expirations, message.Proposal.UniqueID())
 
 	return nil
 }
@@ -137,12 +137,25 @@ func (r *Repository) proposalPingMessage(message pingMessage) error {
 	}
 
 	r.storage.AddProposal(message.Proposal)
+	r.setExpiration(message.Proposal.UniqueID(), message.TTLSeconds)
+
+	return nil
+}
+
+// setExpiration prefers the TTL announced by the provider capped to maxProposalTTL, older providers fall back to the local timeout.
+func (r *Repository) setExpiration(id market.ProposalID, ttlSeconds int64) {
+	ttl := r.timeoutInterval
+	if ttlSeconds > 0 {
+		ttl = maxProposalTTL
+		if ttlSeconds < int64(maxProposalTTL/time.Second) {
+			ttl = time.Duration(ttlSeconds) * time.Second
+		}
+	}
+	expiration := time.Now().Add(ttl)
 
 	r.watchdogLock.Lock()
 	defer r.watchdogLock.Unlock()
-	r.timeoutCheckSeens[message.Proposal.UniqueID()] = time.Now()
-
-	return nil
+	r.expirations[id] = expiration
 }
 
 func (r *Repository) timeoutCheckLoop() {
@@ -152,10 +165,10 @@ func (r *Repository) timeoutCheckLoop() {
 			return
 		case <-time.After(r.timeoutCheckStep):
 			r.watchdogLock.Lock()
-			for proposalID, proposalSeen := range r.timeoutCheckSeens {
-				if time.Now().After(proposalSeen.Add(r.timeoutInterval)) {
+			for proposalID, expiration := range r.expirations {
+				if time.Now().After(expiration) {
 					r.storage.RemoveProposal(proposalID)
-					delete(r.timeoutCheckSeens, proposalID)
+					delete(r.expirations, proposalID)
 				}
 			}
 			r.watchdogLock.Unlock()
@@ -164,6 +164,48 @@ func Test_Subscriber_StartSyncsHealthyProposals(t *testing.T) {
 	assert.Exactly(t, expected, actual)
 }
 
+func Test_Subscriber_RespectsAnnouncedExpiry(t *testing.T) {
+	connection := nats.StartConnectionMock()
+	defer connection.Close()
+
+	repo := NewRepository(connection, NewStorage(eventbus.New()), 10*time.Millisecond, 10*time.Millisecond)
+	err := repo.Start()
+	defer repo.Stop()
+	assert.NoError(t, err)
+
+	proposalRegister(connection, `{
+	  "proposal": {
+		"format": "service-proposal/v3",
+		"compatibility": 2,
+		"provider_id": "0x1",
+		"service_type": "mock_service",
+		"contacts": [
+		  {
+			"type": "mock_contact"
+		  }
+		]
+	  },
+	  "ttl_seconds": 3600
+	}`)
+
+	assert.Eventually(t, proposalCountEquals(repo, 1), 2*time.Second, 10*time.Millisecond)
+	assert.Never(t, proposalCountEquals(repo, 0), 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func Test_Subscriber_CapsAnnouncedTTL(t *testing.T) {
+	repo := NewRepository(nats.StartConnectionMock(), NewStorage(eventbus.New()), time.Minute, time.Minute)
+	id := market.ProposalID{ProviderID: "0x1", ServiceType: "mock_service"}
+
+	repo.setExpiration(id, 1<<62)
+	assert.WithinDuration(t, time.Now().Add(maxProposalTTL), repo.expirations[id], 5*time.Second)
+
+	repo.setExpiration(id, 30)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), repo.expirations[id], 5*time.Second)
+
+	repo.setExpiration(id, 0)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), repo.expirations[id], 5*time.Second)
+}
+
 func Test_Subscriber_StartSyncsStoppedProposals(t *testing.T) {
 	connection := nats.StartConnectionMock()
 	defer connection.Close()
@@ -28,6 +28,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/utils/retry"
 )
 
 // Status describes stage of proposal registration
@@ -183,17 +184,20 @@ func (d *Discovery) registerIdentity() {
 }
 
 func (d *Discovery) registerProposal() {
-	proposal := d.proposal()
-	err := d.proposalRegistry.RegisterProposal(proposal, d.signer)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to register proposal, retrying after 1 min")
-		select {
-		case <-d.stop:
-			return
-		case <-time.After(1 * time.Minute):
-			d.changeStatus(RegisterProposal)
-			return
+	ctx, cancel := retry.UntilClosed(d.stop)
+	defer cancel()
+
+	var proposal market.ServiceProposal
+	err := retry.Do(ctx, retry.Discovery, func() error {
+		proposal = d.proposal()
+		err := d.proposalRegistry.RegisterProposal(proposal, d.signer)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to register proposal, will retry")
 		}
+		return err
+	})
+	if err != nil {
+		return
 	}
 	d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
 	d.changeStatus(PingProposal)
@@ -207,7 +211,10 @@ func (d *Discovery) pingProposal() {
 		proposal := d.proposal()
 		err := d.proposalRegistry.PingProposal(proposal, d.signer)
 		if err != nil {
-			log.Error().Err(err).Msg("Failed to ping proposal")
+			// Discovery may have already expired the proposal, so the heartbeat alone is not enough to restore it.
+			log.Error().Err(err).Msg("Failed to ping proposal, re-registering")
+			d.changeStatus(RegisterProposal)
+			return
 		}
 		d.eventBus.Publish(AppTopicProposalAnnounce, proposal)
 		d.changeStatus(PingProposal)
@@ -18,6 +18,7 @@
 package discovery
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -114,6 +115,21 @@ func TestStartStopUnregisterProposal(t *testing.T) {
 	assert.Equal(t, ProposalUnregistered, actualStatus)
 }
 
+func TestPingFailureReregistersProposal(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identityregistry.FakeRegistry{RegistrationStatus: identityregistry.Registered}
+	d.proposalPingTTL = 10 * time.Millisecond
+	registry := &mockedProposalRegistry{pingErr: errors.New("no ack")}
+	d.proposalRegistry = registry
+
+	d.Start(providerID, func() market.ServiceProposal { return serviceProposal })
+	defer d.Stop()
+
+	assert.Eventually(t, func() bool {
+		return registry.registrations() > 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 func observeStatus(d *Discovery, status Status) Status {
 	for {
 		d.mu.RLock()
@@ -126,18 +142,42 @@ func observeStatus(d *Discovery, status Status) Status {
 	}
 }
 
-type mockedProposalRegistry struct{}
+type mockedProposalRegistry struct {
+	pingErr error
 
-func (mockedProposalRegistry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
-	return nil
+	mu           sync.Mutex
+	registered   int
+	unregistered []market.ServiceProposal
 }
 
-func (mockedProposalRegistry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+func (m *mockedProposalRegistry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.registered++
 	return nil
 }
 
-func (mockedProposalRegistry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+func (m *mockedProposalRegistry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return m.pingErr
+}
+
+func (m *mockedProposalRegistry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unregistered = append(m.unregistered, proposal)
 	return nil
 }
 
+func (m *mockedProposalRegistry) registrations() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.registered
+}
+
+func (m *mockedProposalRegistry) unregistrations() []market.ServiceProposal {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.unregistered
+}
+
 var _ ProposalRegistry = &mockedProposalRegistry{}
@@ -17,6 +17,11 @@
 
 package discovery
 
+import (
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/market"
+)
+
 // Topic represents the different topics a consumer can subscribe to
 const (
 	// AppTopicProposalAdded represents newly announced proposal
@@ -27,4 +32,14 @@ const (
 	AppTopicProposalRemoved = "ProposalRemoved"
 	// AppTopicProposalAnnounce represent proposal events topic.
 	AppTopicProposalAnnounce = "proposalEvent"
+	// AppTopicProposalZombie represents proposal listed in discovery, but not served by the node anymore.
+	AppTopicProposalZombie = "ProposalZombie"
 )
+
+func init() {
+	eventbus.RegisterSchema(AppTopicProposalAdded, 1, market.ServiceProposal{})
+	eventbus.RegisterSchema(AppTopicProposalUpdated, 1, market.ServiceProposal{})
+	eventbus.RegisterSchema(AppTopicProposalRemoved, 1, market.ServiceProposal{})
+	eventbus.RegisterSchema(AppTopicProposalAnnounce, 1, market.ServiceProposal{})
+	eventbus.RegisterSchema(AppTopicProposalZombie, 1, market.ServiceProposal{})
+}
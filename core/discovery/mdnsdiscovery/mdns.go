@@ -0,0 +1,176 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	// serviceName is the DNS-SD service under which providers announce their proposals.
+	serviceName = "_mysterium._udp.local."
+
+	txtKeyFree      = "free="
+	txtKeyProposal  = "p="
+	txtKeySignature = "sig="
+	// txtChunkSize keeps every TXT character-string within the 255 bytes limit.
+	txtChunkSize = 250
+
+	maxPacketSize = 9000
+)
+
+var multicastAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+type packetConn interface {
+	ReadFrom(b []byte) (int, net.Addr, error)
+	WriteTo(b []byte, addr net.Addr) (int, error)
+	Close() error
+}
+
+func listenMulticast() (packetConn, error) {
+	return net.ListenMulticastUDP("udp4", nil, multicastAddr)
+}
+
+// announcement is a proposal announced by a provider in the local network.
+type announcement struct {
+	proposal market.ServiceProposal
+	free     bool
+	ttl      time.Duration
+}
+
+func instanceName(proposal market.ServiceProposal) string {
+	return fmt.Sprintf("%s-%s.%s", proposal.ServiceType, strings.TrimPrefix(proposal.ProviderID, "0x"), serviceName)
+}
+
+// announcementMessage returns the message provider signs to announce the proposal, so that nobody else
+// in the local network can announce or withdraw the proposal, or offer it for free, on the provider's behalf.
+func announcementMessage(data []byte, free bool, ttlSeconds uint32) []byte {
+	return []byte(fmt.Sprintf("mdns announcement %d %t %s", ttlSeconds, free, data))
+}
+
+// announcementRecords describes the proposal as a DNS-SD service instance, zero TTL withdraws it.
+// The proposal is base64 encoded so that the TXT strings survive DNS escaping unchanged.
+func announcementRecords(proposal market.ServiceProposal, signer identity.Signer, free bool, ttl time.Duration) ([]dns.RR, error) {
+	data, err := json.Marshal(proposal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode proposal: %w", err)
+	}
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	ttlSeconds := uint32(ttl / time.Second)
+	signature, err := signer.Sign(announcementMessage(data, free, ttlSeconds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign proposal: %w", err)
+	}
+
+	txt := []string{txtKeyFree + strconv.FormatBool(free)}
+	txt = appendChunks(txt, txtKeySignature, hex.EncodeToString(signature.Bytes()))
+	txt = appendChunks(txt, txtKeyProposal, encoded)
+
+	name := instanceName(proposal)
+	return []dns.RR{
+		&dns.PTR{
+			Hdr: dns.RR_Header{Name: serviceName, Rrtype: dns.TypePTR, Class: dns.ClassINET, Ttl: ttlSeconds},
+			Ptr: name,
+		},
+		&dns.TXT{
+			Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: ttlSeconds},
+			Txt: txt,
+		},
+	}, nil
+}
+
+// appendChunks splits the value into TXT strings with the given key prefix.
+func appendChunks(txt []string, key, value string) []string {
+	for len(value) > 0 {
+		size := txtChunkSize
+		if len(value) < size {
+			size = len(value)
+		}
+		txt = append(txt, key+value[:size])
+		value = value[size:]
+	}
+	return txt
+}
+
+// parseAnnouncements returns the announcements signed by the providers of the announced proposals.
+func parseAnnouncements(msg *dns.Msg, verifiers identity.VerifierFactory) []announcement {
+	records := append(append([]dns.RR{}, msg.Answer...), msg.Extra...)
+
+	announcements := make([]announcement, 0)
+	for _, record := range records {
+		txt, ok := record.(*dns.TXT)
+		if !ok || !strings.HasSuffix(txt.Hdr.Name, "."+serviceName) {
+			continue
+		}
+
+		a, err := parseTXT(txt, verifiers)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Ignoring mDNS announcement %s", txt.Hdr.Name)
+			continue
+		}
+		announcements = append(announcements, a)
+	}
+	return announcements
+}
+
+func parseTXT(txt *dns.TXT, verifiers identity.VerifierFactory) (announcement, error) {
+	a := announcement{ttl: time.Duration(txt.Hdr.Ttl) * time.Second}
+
+	var encoded, signature strings.Builder
+	for _, value := range txt.Txt {
+		switch {
+		case strings.HasPrefix(value, txtKeyFree):
+			a.free, _ = strconv.ParseBool(strings.TrimPrefix(value, txtKeyFree))
+		case strings.HasPrefix(value, txtKeySignature):
+			signature.WriteString(strings.TrimPrefix(value, txtKeySignature))
+		case strings.HasPrefix(value, txtKeyProposal):
+			encoded.WriteString(strings.TrimPrefix(value, txtKeyProposal))
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded.String())
+	if err != nil {
+		return a, fmt.Errorf("failed to decode proposal: %w", err)
+	}
+	if err := json.Unmarshal(data, &a.proposal); err != nil {
+		return a, fmt.Errorf("failed to decode proposal: %w", err)
+	}
+	if txt.Hdr.Name != instanceName(a.proposal) {
+		return a, fmt.Errorf("proposal does not match service instance %s", txt.Hdr.Name)
+	}
+
+	verifier := verifiers(identity.FromAddress(a.proposal.ProviderID))
+	if ok, _ := verifier.Verify(announcementMessage(data, a.free, txt.Hdr.Ttl), identity.SignatureHex(signature.String())); !ok {
+		return a, fmt.Errorf("proposal is not signed by provider %s", a.proposal.ProviderID)
+	}
+	return a, nil
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func init() {
+	market.RegisterServiceType("mock_service")
+	market.RegisterContactUnserializer("mock_contact",
+		func(rawMessage *json.RawMessage) (market.ContactDefinition, error) {
+			return mockContact{}, nil
+		},
+	)
+}
+
+type mockContact struct{}
+
+var proposalFirst = func() market.ServiceProposal {
+	return market.NewProposal("0x1", "mock_service", market.NewProposalOpts{
+		Location: &market.Location{Country: "LT", City: "Vilnius \"Old Town\""},
+		Contacts: []market.Contact{{Type: "mock_contact", Definition: mockContact{}}},
+	})
+}
+
+var firstProposalID = market.ProposalID{ProviderID: "0x1", ServiceType: "mock_service"}
+
+func fakeVerifiers(identity.Identity) identity.Verifier {
+	return &identity.VerifierFake{}
+}
+
+func Test_AnnouncementRecords_SurviveWireFormat(t *testing.T) {
+	records, err := announcementRecords(proposalFirst(), &identity.SignerFake{}, true, 2*time.Minute)
+	assert.NoError(t, err)
+
+	msg := new(dns.Msg)
+	msg.Response = true
+	msg.Answer = records
+	packet, err := msg.Pack()
+	assert.NoError(t, err)
+
+	var received dns.Msg
+	assert.NoError(t, received.Unpack(packet))
+	assert.Equal(t, []announcement{{proposal: proposalFirst(), free: true, ttl: 2 * time.Minute}}, parseAnnouncements(&received, fakeVerifiers))
+}
+
+func Test_ParseAnnouncements_SkipsUnsignedAndTamperedRecords(t *testing.T) {
+	unsigned, err := announcementRecords(proposalFirst(), &identity.SignerFake{}, false, time.Minute)
+	assert.NoError(t, err)
+	txt := unsigned[1].(*dns.TXT)
+	strs := txt.Txt[:0]
+	for _, value := range txt.Txt {
+		if !strings.HasPrefix(value, txtKeySignature) {
+			strs = append(strs, value)
+		}
+	}
+	txt.Txt = strs
+	assert.Empty(t, parseAnnouncements(&dns.Msg{Answer: unsigned}, fakeVerifiers))
+
+	tampered, err := announcementRecords(proposalFirst(), &identity.SignerFake{}, false, time.Minute)
+	assert.NoError(t, err)
+	tampered[1].(*dns.TXT).Txt[0] = txtKeyFree + "true"
+	assert.Empty(t, parseAnnouncements(&dns.Msg{Answer: tampered}, fakeVerifiers))
+}
+
+func Test_ParseAnnouncements_SkipsForeignRecords(t *testing.T) {
+	records, err := announcementRecords(proposalFirst(), &identity.SignerFake{}, false, time.Minute)
+	assert.NoError(t, err)
+	records[1].Header().Name = "printer._ipp._tcp.local."
+
+	msg := new(dns.Msg)
+	msg.Answer = append(records, &dns.TXT{
+		Hdr: dns.RR_Header{Name: instanceName(proposalFirst()), Rrtype: dns.TypeTXT, Class: dns.ClassINET, Ttl: 60},
+		Txt: []string{"p=not-a-proposal"},
+	})
+	assert.Empty(t, parseAnnouncements(msg, fakeVerifiers))
+}
+
+// mockConn delivers written packets to the paired connections.
+type mockConn struct {
+	incoming chan []byte
+	peers    []*mockConn
+	closed   chan struct{}
+}
+
+func newMockConn() *mockConn {
+	return &mockConn{incoming: make(chan []byte, 10), closed: make(chan struct{})}
+}
+
+func pairMockConns() (*mockConn, *mockConn) {
+	first, second := newMockConn(), newMockConn()
+	first.peers = []*mockConn{second}
+	second.peers = []*mockConn{first}
+	return first, second
+}
+
+func (c *mockConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case packet := <-c.incoming:
+		return copy(b, packet), multicastAddr, nil
+	case <-c.closed:
+		return 0, nil, errors.New("closed")
+	}
+}
+
+func (c *mockConn) WriteTo(b []byte, _ net.Addr) (int, error) {
+	for _, peer := range c.peers {
+		peer.incoming <- append([]byte{}, b...)
+	}
+	return len(b), nil
+}
+
+func (c *mockConn) Close() error {
+	close(c.closed)
+	return nil
+}
+
+func (c *mockConn) listen() (packetConn, error) {
+	return c, nil
+}
@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// Registry announces proposals to consumers in the local network through mDNS.
+type Registry struct {
+	proposalTTL time.Duration
+	free        bool
+	listen      func() (packetConn, error)
+
+	mu        sync.Mutex
+	conn      packetConn
+	proposals map[market.ProposalID]registration
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// registration is a proposal announced with the signer of its provider.
+type registration struct {
+	proposal market.ServiceProposal
+	signer   identity.Signer
+}
+
+// NewRegistry creates an instance of mDNS registry.
+// Free marks announced proposals as free of charge for consumers in the local network.
+func NewRegistry(proposalTTL time.Duration, free bool) *Registry {
+	return &Registry{
+		proposalTTL: proposalTTL,
+		free:        free,
+		listen:      listenMulticast,
+		proposals:   make(map[market.ProposalID]registration),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Start begins answering mDNS queries for registered proposals.
+func (r *Registry) Start() error {
+	conn, err := r.listen()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.conn = conn
+	r.mu.Unlock()
+
+	go r.serve(conn)
+	return nil
+}
+
+// Stop withdraws registered proposals and stops answering mDNS queries.
+func (r *Registry) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if r.conn == nil {
+			return
+		}
+		for _, reg := range r.proposals {
+			r.announce(0, reg)
+		}
+		r.conn.Close()
+		r.conn = nil
+	})
+}
+
+// RegisterProposal registers service proposal to discovery service.
+func (r *Registry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reg := registration{proposal: proposal, signer: signer}
+	r.proposals[proposal.UniqueID()] = reg
+	r.announce(r.proposalTTL, reg)
+	return nil
+}
+
+// UnregisterProposal unregisters a service proposal when client disconnects.
+func (r *Registry) UnregisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.proposals, proposal.UniqueID())
+	r.announce(0, registration{proposal: proposal, signer: signer})
+	return nil
+}
+
+// PingProposal pings service proposal as being alive.
+func (r *Registry) PingProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	return r.RegisterProposal(proposal, signer)
+}
+
+func (r *Registry) serve(conn packetConn) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-r.stopChan:
+				return
+			default:
+			}
+			log.Warn().Err(err).Msg("Failed to read mDNS query")
+			continue
+		}
+
+		var msg dns.Msg
+		if err := msg.Unpack(buf[:n]); err != nil || msg.Response {
+			continue
+		}
+		if r.isQueried(&msg) {
+			r.mu.Lock()
+			regs := make([]registration, 0, len(r.proposals))
+			for _, reg := range r.proposals {
+				regs = append(regs, reg)
+			}
+			r.announce(r.proposalTTL, regs...)
+			r.mu.Unlock()
+		}
+	}
+}
+
+func (r *Registry) isQueried(msg *dns.Msg) bool {
+	for _, question := range msg.Question {
+		if question.Name == serviceName && (question.Qtype == dns.TypePTR || question.Qtype == dns.TypeANY) {
+			return true
+		}
+	}
+	return false
+}
+
+// announce multicasts given proposals, must be called with the lock held.
+func (r *Registry) announce(ttl time.Duration, regs ...registration) {
+	if r.conn == nil || len(regs) == 0 {
+		return
+	}
+
+	msg := new(dns.Msg)
+	msg.Response = true
+	msg.Authoritative = true
+	for _, reg := range regs {
+		records, err := announcementRecords(reg.proposal, reg.signer, r.free, ttl)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to announce proposal %v", reg.proposal.UniqueID())
+			continue
+		}
+		msg.Answer = append(msg.Answer, records...)
+	}
+	if len(msg.Answer) == 0 {
+		return
+	}
+
+	packet, err := msg.Pack()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to pack mDNS announcement")
+		return
+	}
+	if _, err := r.conn.WriteTo(packet, multicastAddr); err != nil {
+		log.Warn().Err(err).Msg("Failed to send mDNS announcement")
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// Repository provides proposals announced in the local network through mDNS.
+type Repository struct {
+	storage       *brokerdiscovery.ProposalStorage
+	queryInterval time.Duration
+	verifiers     identity.VerifierFactory
+	listen        func() (packetConn, error)
+	conn          packetConn
+
+	mu          sync.Mutex
+	expirations map[market.ProposalID]time.Time
+	free        map[market.ProposalID]bool
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// NewRepository constructs a new proposal repository (backed by mDNS).
+// Only the proposals signed by their providers are accepted.
+func NewRepository(storage *brokerdiscovery.ProposalStorage, queryInterval time.Duration) *Repository {
+	return &Repository{
+		storage:       storage,
+		queryInterval: queryInterval,
+		verifiers: func(id identity.Identity) identity.Verifier {
+			return identity.NewVerifierIdentity(id)
+		},
+		listen:      listenMulticast,
+		expirations: make(map[market.ProposalID]time.Time),
+		free:        make(map[market.ProposalID]bool),
+		stopChan:    make(chan struct{}),
+	}
+}
+
+// Proposal returns a single proposal by its ID.
+func (r *Repository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	return r.storage.GetProposal(id)
+}
+
+// Proposals returns proposals matching the filter.
+func (r *Repository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	return r.storage.FindProposals(filter)
+}
+
+// Countries returns proposals per country matching the filter.
+func (r *Repository) Countries(filter *proposal.Filter) (map[string]int, error) {
+	return r.storage.Countries(filter)
+}
+
+// IsFree tells whether the provider announced the proposal as free of charge in the local network.
+func (r *Repository) IsFree(id market.ProposalID) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.free[id]
+}
+
+// Start begins browsing the local network for proposals.
+func (r *Repository) Start() error {
+	conn, err := r.listen()
+	if err != nil {
+		return err
+	}
+	r.conn = conn
+
+	go r.receiveLoop()
+	go r.queryLoop()
+	return nil
+}
+
+// Stop ends browsing the local network for proposals.
+func (r *Repository) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stopChan)
+		if r.conn != nil {
+			r.conn.Close()
+		}
+	})
+}
+
+func (r *Repository) queryLoop() {
+	for {
+		r.query()
+		r.removeExpired()
+
+		select {
+		case <-r.stopChan:
+			return
+		case <-time.After(r.queryInterval):
+		}
+	}
+}
+
+func (r *Repository) query() {
+	msg := new(dns.Msg)
+	msg.SetQuestion(serviceName, dns.TypePTR)
+	msg.RecursionDesired = false
+
+	packet, err := msg.Pack()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to pack mDNS query")
+		return
+	}
+	if _, err := r.conn.WriteTo(packet, multicastAddr); err != nil {
+		log.Warn().Err(err).Msg("Failed to send mDNS query")
+	}
+}
+
+func (r *Repository) receiveLoop() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, _, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			select {
+			case <-r.stopChan:
+				return
+			default:
+			}
+			log.Warn().Err(err).Msg("Failed to read mDNS response")
+			continue
+		}
+
+		var msg dns.Msg
+		if err := msg.Unpack(buf[:n]); err != nil || !msg.Response {
+			continue
+		}
+		r.handleAnnouncements(parseAnnouncements(&msg, r.verifiers))
+	}
+}
+
+func (r *Repository) handleAnnouncements(announcements []announcement) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range announcements {
+		id := a.proposal.UniqueID()
+		if a.ttl == 0 {
+			r.storage.RemoveProposal(id)
+			delete(r.expirations, id)
+			delete(r.free, id)
+			continue
+		}
+		if !a.proposal.IsSupported() {
+			continue
+		}
+
+		r.storage.AddProposal(a.proposal)
+		r.expirations[id] = time.Now().Add(a.ttl)
+		r.free[id] = a.free
+	}
+}
+
+func (r *Repository) removeExpired() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, expiration := range r.expirations {
+		if time.Now().After(expiration) {
+			r.storage.RemoveProposal(id)
+			delete(r.expirations, id)
+			delete(r.free, id)
+		}
+	}
+}
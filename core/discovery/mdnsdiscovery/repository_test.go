@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mdnsdiscovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func newTestPair(free bool) (*Registry, *Repository) {
+	providerConn, consumerConn := pairMockConns()
+
+	registry := NewRegistry(time.Minute, free)
+	registry.listen = providerConn.listen
+
+	repository := NewRepository(brokerdiscovery.NewStorage(eventbus.New()), 10*time.Millisecond)
+	repository.listen = consumerConn.listen
+	repository.verifiers = fakeVerifiers
+
+	return registry, repository
+}
+
+func proposalCountEquals(repository *Repository, count int) func() bool {
+	return func() bool {
+		return len(repository.storage.Proposals()) == count
+	}
+}
+
+func Test_Repository_DiscoversRegisteredProposals(t *testing.T) {
+	registry, repository := newTestPair(true)
+	assert.NoError(t, registry.Start())
+	defer registry.Stop()
+	assert.NoError(t, registry.RegisterProposal(proposalFirst(), &identity.SignerFake{}))
+
+	assert.NoError(t, repository.Start())
+	defer repository.Stop()
+
+	assert.Eventually(t, proposalCountEquals(repository, 1), 2*time.Second, 10*time.Millisecond)
+	found, err := repository.Proposal(firstProposalID)
+	assert.NoError(t, err)
+	assert.Equal(t, proposalFirst(), *found)
+	assert.True(t, repository.IsFree(firstProposalID))
+
+	assert.NoError(t, registry.UnregisterProposal(proposalFirst(), &identity.SignerFake{}))
+	assert.Eventually(t, proposalCountEquals(repository, 0), 2*time.Second, 10*time.Millisecond)
+	assert.False(t, repository.IsFree(firstProposalID))
+}
+
+func Test_Repository_ExpiresProposals(t *testing.T) {
+	_, repository := newTestPair(false)
+	assert.NoError(t, repository.Start())
+	defer repository.Stop()
+
+	repository.handleAnnouncements([]announcement{{proposal: proposalFirst(), ttl: 50 * time.Millisecond}})
+	assert.Eventually(t, proposalCountEquals(repository, 1), time.Second, 10*time.Millisecond)
+	assert.False(t, repository.IsFree(firstProposalID))
+	assert.Eventually(t, proposalCountEquals(repository, 0), 2*time.Second, 10*time.Millisecond)
+}
+
+func Test_Repository_SkipsUnsupportedProposals(t *testing.T) {
+	_, repository := newTestPair(false)
+
+	unsupported := market.NewProposal("0x2", "unknown_service", market.NewProposalOpts{})
+	repository.handleAnnouncements([]announcement{{proposal: unsupported, ttl: time.Minute}})
+
+	assert.True(t, proposalCountEquals(repository, 0)())
+}
@@ -18,6 +18,8 @@
 package discovery
 
 import (
+	"math/big"
+
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
@@ -29,11 +31,18 @@ type PricedServiceProposalRepository struct {
 	baseRepo      proposal.Repository
 	pip           PriceInfoProvider
 	filterPresets proposal.FilterPresetRepository
+	freeSources   []FreeProposalSource
 }
 
 // PriceInfoProvider allows to fetch the current pricing for services.
 type PriceInfoProvider interface {
 	GetCurrentPrice(nodeType string, country string, serviceType string) (market.Price, error)
+	IsPriceValid(in market.Price, nodeType string, country string, serviceType string) bool
+}
+
+// FreeProposalSource tells whether a proposal was announced by its provider as free of charge.
+type FreeProposalSource interface {
+	IsFree(id market.ProposalID) bool
 }
 
 // NewPricedServiceProposalRepository returns a new instance of PricedServiceProposalRepository.
@@ -45,6 +54,11 @@ func NewPricedServiceProposalRepository(baseRepo proposal.Repository, pip PriceI
 	}
 }
 
+// AddFreeProposalSource adds a source of proposals which are priced at zero instead of the network price.
+func (pspr *PricedServiceProposalRepository) AddFreeProposalSource(source FreeProposalSource) {
+	pspr.freeSources = append(pspr.freeSources, source)
+}
+
 // Proposal fetches the proposal from base repository and enriches it with pricing data.
 func (pspr *PricedServiceProposalRepository) Proposal(id market.ProposalID) (*proposal.PricedServiceProposal, error) {
 	prop, err := pspr.baseRepo.Proposal(id)
@@ -105,6 +119,27 @@ func (pspr *PricedServiceProposalRepository) toPricedProposals(in []market.Servi
 }
 
 func (pspr *PricedServiceProposalRepository) toPricedProposal(in market.ServiceProposal) (proposal.PricedServiceProposal, error) {
+	for _, source := range pspr.freeSources {
+		if source.IsFree(in.UniqueID()) {
+			return proposal.PricedServiceProposal{
+				ServiceProposal: in,
+				Price: market.Price{
+					PricePerHour: big.NewInt(0),
+					PricePerGiB:  big.NewInt(0),
+				},
+			}, nil
+		}
+	}
+
+	// Prices announced by providers are honoured only within the range allowed by the network.
+	if in.Price != nil && in.Price.PricePerHour != nil && in.Price.PricePerGiB != nil &&
+		pspr.pip.IsPriceValid(*in.Price, in.Location.IPType, in.Location.Country, in.ServiceType) {
+		return proposal.PricedServiceProposal{
+			ServiceProposal: in,
+			Price:           *in.Price,
+		}, nil
+	}
+
 	price, err := pspr.pip.GetCurrentPrice(in.Location.IPType, in.Location.Country, in.ServiceType)
 	if err != nil {
 		return proposal.PricedServiceProposal{}, err
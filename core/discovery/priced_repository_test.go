@@ -100,6 +100,51 @@ func TestGetProposal(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, mockError, err)
 	})
+	t.Run("prices free proposals at zero", func(t *testing.T) {
+		mp := &mockPriceInfoProvider{
+			errorToReturn: errors.New("boom"),
+		}
+		repo := NewPricedServiceProposalRepository(&mockRepository{
+			proposalToReturn: &mockProposal,
+		}, mp, presetRepository)
+		repo.AddFreeProposalSource(&mockFreeProposalSource{free: mockProposal.UniqueID()})
+
+		result, err := repo.Proposal(mockProposal.UniqueID())
+		assert.NoError(t, err)
+		assert.True(t, result.Price.IsFree())
+	})
+	t.Run("uses price announced by provider", func(t *testing.T) {
+		announced := market.Price{
+			PricePerHour: big.NewInt(3),
+			PricePerGiB:  big.NewInt(4),
+		}
+		prop := mockProposal
+		prop.Price = &announced
+		mp := &mockPriceInfoProvider{
+			errorToReturn: errors.New("boom"),
+			validPrice:    &announced,
+		}
+		repo := NewPricedServiceProposalRepository(&mockRepository{
+			proposalToReturn: &prop,
+		}, mp, presetRepository)
+
+		result, err := repo.Proposal(prop.UniqueID())
+		assert.NoError(t, err)
+		assert.EqualValues(t, announced, result.Price)
+	})
+	t.Run("uses network price if the announced price is not allowed", func(t *testing.T) {
+		prop := mockProposal
+		prop.Price = market.NewPrice(3000, 4000)
+		networkPrice := *market.NewPrice(1, 2)
+		mp := &mockPriceInfoProvider{priceToReturn: networkPrice}
+		repo := NewPricedServiceProposalRepository(&mockRepository{
+			proposalToReturn: &prop,
+		}, mp, presetRepository)
+
+		result, err := repo.Proposal(prop.UniqueID())
+		assert.NoError(t, err)
+		assert.EqualValues(t, networkPrice, result.Price)
+	})
 }
 
 func TestGetProposals(t *testing.T) {
@@ -151,6 +196,14 @@ func TestGetProposals(t *testing.T) {
 	})
 }
 
+type mockFreeProposalSource struct {
+	free market.ProposalID
+}
+
+func (mfs *mockFreeProposalSource) IsFree(id market.ProposalID) bool {
+	return mfs.free == id
+}
+
 type mockRepository struct {
 	proposalsToReturn []market.ServiceProposal
 	errToReturn       error
@@ -172,6 +225,11 @@ func (mr *mockRepository) Countries(filter *proposal.Filter) (map[string]int, er
 type mockPriceInfoProvider struct {
 	priceToReturn market.Price
 	errorToReturn error
+	validPrice    *market.Price
+}
+
+func (mpip *mockPriceInfoProvider) IsPriceValid(in market.Price, nodeType string, country string, serviceType string) bool {
+	return mpip.validPrice != nil && mpip.validPrice.PricePerHour.Cmp(in.PricePerHour) == 0 && mpip.validPrice.PricePerGiB.Cmp(in.PricePerGiB) == 0
 }
 
 func (mpip *mockPriceInfoProvider) GetCurrentPrice(nodeType string, country string, serviceType string) (market.Price, error) {
@@ -33,6 +33,7 @@ type Filter struct {
 	ProviderIDs                        []string
 	ServiceType                        string
 	LocationCountry                    string
+	ConsumerCountry                    string
 	IPType                             string
 	AccessPolicy, AccessPolicySource   string
 	CompatibilityMin, CompatibilityMax int
@@ -71,11 +72,17 @@ func (filter *Filter) Build() {
 		if filter.LocationCountry != "" {
 			conditions = append(conditions, reducer.Equal(reducer.LocationCountry, filter.LocationCountry))
 		}
+		if filter.ConsumerCountry != "" {
+			conditions = append(conditions, reducer.ConsumerCountry(filter.ConsumerCountry))
+		}
 		if filter.AccessPolicy != "all" {
 			if filter.AccessPolicy != "" || filter.AccessPolicySource != "" {
 				conditions = append(conditions, reducer.AccessPolicy(filter.AccessPolicy, filter.AccessPolicySource))
 			}
 		}
+		if filter.NATCompatibility != "" {
+			conditions = append(conditions, reducer.NATCompatible(filter.NATCompatibility))
+		}
 		filter.condition = reducer.And(conditions...)
 	})
 }
@@ -19,6 +19,8 @@ package reducer
 
 import (
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/p2p"
 )
 
 // ProviderID selects provider id value from proposal
@@ -66,9 +68,27 @@ func AccessPolicy(id, source string) func(market.ServiceProposal) bool {
 	}
 }
 
+// ConsumerCountry returns a matcher for checking if proposal allows consumers from given country
+func ConsumerCountry(country string) func(market.ServiceProposal) bool {
+	return func(proposal market.ServiceProposal) bool {
+		return proposal.AllowsConsumerCountry(country)
+	}
+}
+
 // Unsupported filters out unsupported proposals
 func Unsupported() func(market.ServiceProposal) bool {
 	return func(proposal market.ServiceProposal) bool {
 		return proposal.IsSupported()
 	}
 }
+
+// NATCompatible filters out proposals which p2p contact is known to be unreachable from given NAT type
+func NATCompatible(natType nat.NATType) func(market.ServiceProposal) bool {
+	return func(proposal market.ServiceProposal) bool {
+		contact, err := p2p.ParseContact(proposal.Contacts)
+		if err != nil {
+			return true
+		}
+		return contact.Reachable(natType)
+	}
+}
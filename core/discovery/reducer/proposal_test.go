@@ -21,6 +21,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/p2p"
 )
 
 func Test_ProviderID(t *testing.T) {
@@ -85,3 +89,17 @@ func Test_AccessPolicy_FiltersByIDAndSource(t *testing.T) {
 	assert.False(t, match(proposalProvider1Noop))
 	assert.True(t, match(proposalProvider2Streaming))
 }
+
+func Test_NATCompatible(t *testing.T) {
+	proposalSymmetric := market.NewProposal(provider1, serviceTypeStreaming, market.NewProposalOpts{
+		Contacts: []market.Contact{{
+			Type:       p2p.ContactTypeV1,
+			Definition: p2p.ContactDefinition{NATType: string(nat.NATTypeSymmetric), TraversalMethods: []string{"holepunching"}},
+		}},
+	})
+	match := NATCompatible(nat.NATTypeSymmetric)
+
+	assert.True(t, match(proposalEmpty))
+	assert.False(t, match(proposalSymmetric))
+	assert.True(t, NATCompatible(nat.NATTypeRestrictedCone)(proposalSymmetric))
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type proposalLister interface {
+	Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error)
+}
+
+// ZombieDetector looks for "zombie" registrations - proposals which discovery still lists,
+// although the node does not serve them anymore - and unregisters them.
+type ZombieDetector struct {
+	repository       proposalLister
+	proposalRegistry ProposalRegistry
+	signerCreate     identity.SignerFactory
+	served           func() []market.ServiceProposal
+	eventBus         eventbus.Publisher
+	checkInterval    time.Duration
+
+	mu        sync.Mutex
+	providers map[string]struct{}
+
+	// suspects are accessed only by the checking routine.
+	suspects map[market.ProposalID]struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewZombieDetector creates new zombie registration detector.
+func NewZombieDetector(
+	repository proposalLister,
+	proposalRegistry ProposalRegistry,
+	signerCreate identity.SignerFactory,
+	served func() []market.ServiceProposal,
+	eventBus eventbus.Publisher,
+	checkInterval time.Duration,
+) *ZombieDetector {
+	return &ZombieDetector{
+		repository:       repository,
+		proposalRegistry: proposalRegistry,
+		signerCreate:     signerCreate,
+		served:           served,
+		eventBus:         eventBus,
+		checkInterval:    checkInterval,
+		providers:        make(map[string]struct{}),
+		suspects:         make(map[market.ProposalID]struct{}),
+		stop:             make(chan struct{}),
+	}
+}
+
+// Subscribe subscribes to own proposal announcements to learn which providers to check.
+func (zd *ZombieDetector) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(AppTopicProposalAnnounce, zd.handleProposalAnnounce)
+}
+
+// Start launches periodic zombie registration checks.
+func (zd *ZombieDetector) Start() {
+	go func() {
+		for {
+			select {
+			case <-zd.stop:
+				return
+			case <-time.After(zd.checkInterval):
+				zd.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops periodic zombie registration checks.
+func (zd *ZombieDetector) Stop() {
+	zd.stopOnce.Do(func() {
+		close(zd.stop)
+	})
+}
+
+// Check compares proposals listed in discovery with the served ones. Listed proposal is
+// unregistered when it is found not served on two consecutive checks, which gives discovery
+// time to catch up with the regular unregistration.
+func (zd *ZombieDetector) Check() {
+	served := make(map[market.ProposalID]struct{})
+	for _, p := range zd.served() {
+		served[p.UniqueID()] = struct{}{}
+	}
+
+	zd.mu.Lock()
+	providers := make([]string, 0, len(zd.providers))
+	for providerID := range zd.providers {
+		providers = append(providers, providerID)
+	}
+	zd.mu.Unlock()
+
+	suspects := make(map[market.ProposalID]struct{})
+	for _, providerID := range providers {
+		listed, err := zd.repository.Proposals(&proposal.Filter{
+			ProviderID:              providerID,
+			AccessPolicy:            "all",
+			IncludeMonitoringFailed: true,
+		})
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to list proposals of %s from discovery", providerID)
+			continue
+		}
+
+		for _, p := range listed {
+			id := p.UniqueID()
+			if _, ok := served[id]; ok {
+				continue
+			}
+			if _, ok := zd.suspects[id]; !ok {
+				suspects[id] = struct{}{}
+				continue
+			}
+			zd.unregister(p)
+		}
+	}
+	zd.suspects = suspects
+}
+
+func (zd *ZombieDetector) unregister(p market.ServiceProposal) {
+	log.Warn().Msgf("Discovery lists proposal %s/%s which is not served anymore, unregistering", p.ProviderID, p.ServiceType)
+	zd.eventBus.Publish(AppTopicProposalZombie, p)
+
+	signer := zd.signerCreate(identity.FromAddress(p.ProviderID))
+	if err := zd.proposalRegistry.UnregisterProposal(p, signer); err != nil {
+		log.Error().Err(err).Msgf("Failed to unregister zombie proposal %s/%s", p.ProviderID, p.ServiceType)
+	}
+}
+
+func (zd *ZombieDetector) handleProposalAnnounce(p market.ServiceProposal) {
+	zd.mu.Lock()
+	defer zd.mu.Unlock()
+
+	zd.providers[p.ProviderID] = struct{}{}
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type mockProposalLister struct {
+	proposals []market.ServiceProposal
+}
+
+func (m *mockProposalLister) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	var result []market.ServiceProposal
+	for _, p := range m.proposals {
+		if p.ProviderID == filter.ProviderID {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func TestZombieDetector_UnregistersNotServedProposals(t *testing.T) {
+	wireguard := market.ServiceProposal{ProviderID: providerID.Address, ServiceType: "wireguard"}
+	scraping := market.ServiceProposal{ProviderID: providerID.Address, ServiceType: "scraping"}
+	foreign := market.ServiceProposal{ProviderID: "0x2", ServiceType: "scraping"}
+
+	registry := &mockedProposalRegistry{}
+	detector := NewZombieDetector(
+		&mockProposalLister{proposals: []market.ServiceProposal{wireguard, scraping, foreign}},
+		registry,
+		func(id identity.Identity) identity.Signer { return &identity.SignerFake{} },
+		func() []market.ServiceProposal { return []market.ServiceProposal{wireguard} },
+		eventbus.New(),
+		0,
+	)
+	detector.handleProposalAnnounce(wireguard)
+
+	detector.Check()
+	assert.Empty(t, registry.unregistrations(), "zombie must be confirmed by the second check")
+
+	detector.Check()
+	assert.Equal(t, []market.ServiceProposal{scraping}, registry.unregistrations())
+}
+
+func TestZombieDetector_ForgetsSuspectWhenServedAgain(t *testing.T) {
+	wireguard := market.ServiceProposal{ProviderID: providerID.Address, ServiceType: "wireguard"}
+
+	served := []market.ServiceProposal{}
+	registry := &mockedProposalRegistry{}
+	detector := NewZombieDetector(
+		&mockProposalLister{proposals: []market.ServiceProposal{wireguard}},
+		registry,
+		func(id identity.Identity) identity.Signer { return &identity.SignerFake{} },
+		func() []market.ServiceProposal { return served },
+		eventbus.New(),
+		0,
+	)
+	detector.handleProposalAnnounce(wireguard)
+
+	detector.Check()
+	served = []market.ServiceProposal{wireguard}
+	detector.Check()
+	served = []market.ServiceProposal{}
+	detector.Check()
+
+	assert.Empty(t, registry.unregistrations())
+}
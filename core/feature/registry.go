@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package feature
+
+import (
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/node/requests/signed"
+)
+
+const (
+	// TraversalStrategies enables experimental NAT traversal strategies, such as dialing providers via NAT64.
+	TraversalStrategies = "traversal-strategies"
+	// PaymentAggregation enables settling the promises of multiple hermeses in a single transaction.
+	PaymentAggregation = "payment-aggregation"
+)
+
+// Source describes where the state of the feature comes from.
+type Source string
+
+const (
+	// SourceDefault means the feature is in its default state.
+	SourceDefault Source = "default"
+	// SourceRemote means the feature state is decided by the remote rollout rules.
+	SourceRemote Source = "remote"
+	// SourceConfig means the feature is explicitly enabled or disabled in node configuration.
+	SourceConfig Source = "config"
+)
+
+// Definition describes a feature known to the node.
+type Definition struct {
+	Name        string
+	Description string
+	// Default tells whether the feature is enabled unless decided otherwise by remote rules or configuration.
+	Default bool
+}
+
+// Known lists experimental features the node knows about.
+// Features enabled by default can still be rolled back remotely or disabled in configuration.
+var Known = []Definition{
+	{Name: TraversalStrategies, Description: "Experimental NAT traversal strategies, such as dialing providers via NAT64", Default: true},
+	{Name: PaymentAggregation, Description: "Settling promises of multiple hermeses in a single transaction", Default: true},
+}
+
+// Status describes the state of the feature on this node.
+type Status struct {
+	Name        string
+	Description string
+	Enabled     bool
+	Source      Source
+	// Rollout is the percentage of nodes the feature is rolled out to, if decided remotely.
+	Rollout int
+}
+
+// Config holds the local feature configuration.
+type Config struct {
+	Enabled  []string
+	Disabled []string
+}
+
+// Registry decides which features are enabled on this node.
+// Local configuration has precedence over remote rollout rules.
+type Registry struct {
+	local map[string]bool
+
+	fetcher       *signed.Fetcher
+	fetchURL      string
+	fetchInterval time.Duration
+
+	lock       sync.RWMutex
+	rules      Rules
+	eTag       string
+	rolloutKey string
+
+	fetchShutdown     chan struct{}
+	fetchShutdownOnce sync.Once
+}
+
+// NewRegistry creates feature registry.
+// Remote rules are fetched from given URL if it's not empty and verified with given verifier, they are not used without the verifier.
+// Fetched rules are cached in given storage, which is optional.
+func NewRegistry(cfg Config, client *requests.HTTPClient, rulesURL string, interval time.Duration, verifier identity.Verifier, storage signed.Storage) *Registry {
+	if rulesURL != "" && verifier == nil {
+		log.Warn().Msg("Feature rules signer is not set, remote feature rules are not used")
+		rulesURL = ""
+	}
+
+	local := make(map[string]bool)
+	for _, name := range cfg.Enabled {
+		local[name] = true
+	}
+	for _, name := range cfg.Disabled {
+		local[name] = false
+	}
+	for name := range local {
+		if !isKnown(name) {
+			log.Warn().Msgf("Unknown feature %q in configuration", name)
+		}
+	}
+
+	return &Registry{
+		local:         local,
+		fetcher:       signed.NewFetcher(client, verifier, storage, cacheBucket),
+		fetchURL:      rulesURL,
+		fetchInterval: interval,
+		fetchShutdown: make(chan struct{}),
+	}
+}
+
+// SetRolloutKey sets the key used to place this node into percentage rollouts, usually the node identity.
+func (r *Registry) SetRolloutKey(key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.rolloutKey = key
+}
+
+// HandleIdentityUnlock uses unlocked identity as the rollout key.
+func (r *Registry) HandleIdentityUnlock(e identity.AppEventIdentityUnlock) {
+	r.SetRolloutKey(e.ID.Address)
+}
+
+// Enabled reports whether the given feature is enabled on this node.
+func (r *Registry) Enabled(name string) bool {
+	return r.status(name).Enabled
+}
+
+// Features returns the state of all known and configured features.
+func (r *Registry) Features() []Status {
+	names := make(map[string]struct{})
+	for _, def := range Known {
+		names[def.Name] = struct{}{}
+	}
+	for name := range r.local {
+		names[name] = struct{}{}
+	}
+	r.lock.RLock()
+	for name := range r.rules.Features {
+		names[name] = struct{}{}
+	}
+	r.lock.RUnlock()
+
+	result := make([]Status, 0, len(names))
+	for name := range names {
+		result = append(result, r.status(name))
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func (r *Registry) status(name string) Status {
+	status := Status{Name: name, Source: SourceDefault}
+	for _, def := range Known {
+		if def.Name == name {
+			status.Description = def.Description
+			status.Enabled = def.Default
+		}
+	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if rule, ok := r.rules.Features[name]; ok {
+		status.Source = SourceRemote
+		status.Rollout = rule.Rollout
+		status.Enabled = inRollout(name, r.rolloutKey, rule.Rollout)
+	}
+	if enabled, ok := r.local[name]; ok {
+		status.Source = SourceConfig
+		status.Enabled = enabled
+	}
+	return status
+}
+
+// inRollout deterministically places the node into a bucket of the feature rollout.
+// Nodes without a rollout key only get features rolled out to everyone.
+func inRollout(name, key string, percentage int) bool {
+	if percentage >= 100 {
+		return true
+	}
+	if percentage <= 0 || key == "" {
+		return false
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + key))
+	return int(h.Sum32()%100) < percentage
+}
+
+func isKnown(name string) bool {
+	for _, def := range Known {
+		if def.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package feature
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+func Test_Registry_Defaults(t *testing.T) {
+	registry := NewRegistry(Config{}, nil, "", time.Minute, nil, nil)
+
+	assert.True(t, registry.Enabled(PaymentAggregation))
+	assert.False(t, registry.Enabled("unknown"))
+	assert.Equal(t, []Status{
+		{Name: PaymentAggregation, Description: "Settling promises of multiple hermeses in a single transaction", Enabled: true, Source: SourceDefault},
+		{Name: TraversalStrategies, Description: "Experimental NAT traversal strategies, such as dialing providers via NAT64", Enabled: true, Source: SourceDefault},
+	}, registry.Features())
+}
+
+func Test_Registry_LocalConfigOverridesRemote(t *testing.T) {
+	registry := NewRegistry(Config{
+		Enabled:  []string{TraversalStrategies, "local-only"},
+		Disabled: []string{PaymentAggregation},
+	}, nil, "", time.Minute, nil, nil)
+	registry.rules = Rules{Features: map[string]Rule{
+		PaymentAggregation:  {Rollout: 100},
+		TraversalStrategies: {Rollout: 0},
+	}}
+
+	assert.True(t, registry.Enabled(TraversalStrategies))
+	assert.False(t, registry.Enabled(PaymentAggregation))
+	assert.True(t, registry.Enabled("local-only"))
+	assert.Equal(t, []Status{
+		{Name: "local-only", Enabled: true, Source: SourceConfig},
+		{Name: PaymentAggregation, Description: "Settling promises of multiple hermeses in a single transaction", Source: SourceConfig, Rollout: 100},
+		{Name: TraversalStrategies, Description: "Experimental NAT traversal strategies, such as dialing providers via NAT64", Enabled: true, Source: SourceConfig},
+	}, registry.Features())
+}
+
+func Test_Registry_Rollout(t *testing.T) {
+	registry := NewRegistry(Config{}, nil, "", time.Minute, nil, nil)
+	registry.rules = Rules{Features: map[string]Rule{
+		PaymentAggregation:  {Rollout: 100},
+		TraversalStrategies: {Rollout: 50},
+	}}
+
+	assert.True(t, registry.Enabled(PaymentAggregation))
+	assert.False(t, registry.Enabled(TraversalStrategies), "partial rollout requires a rollout key")
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		registry.HandleIdentityUnlock(identity.AppEventIdentityUnlock{ID: identity.FromAddress(fmt.Sprintf("0x%x", i))})
+		if registry.Enabled(TraversalStrategies) {
+			enabled++
+		}
+		assert.Equal(t, registry.Enabled(TraversalStrategies), registry.Enabled(TraversalStrategies))
+	}
+	assert.InDelta(t, 500, enabled, 100)
+}
+
+func Test_Registry_FetchRules_VerifiesSignature(t *testing.T) {
+	body := []byte(`{"features": {"payment-aggregation": {"rollout": 0}}}`)
+	signer := &identity.SignerFake{}
+	signature, _ := signer.Sign(body)
+
+	tests := []struct {
+		name          string
+		signature     string
+		expectedError bool
+	}{
+		{name: "accepts signed rules", signature: hex.EncodeToString(signature.Bytes())},
+		{name: "rejects unsigned rules", signature: "", expectedError: true},
+		{name: "rejects invalid signature", signature: hex.EncodeToString([]byte("forged")), expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.signature != "" {
+					w.Header().Set(SignatureHeader, tt.signature)
+				}
+				w.Write(body)
+			}))
+			defer server.Close()
+
+			registry := NewRegistry(Config{}, requests.NewHTTPClient("0.0.0.0", time.Second), server.URL, time.Minute, &identity.VerifierFake{}, nil)
+			err := registry.fetchRules()
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.True(t, registry.Enabled(PaymentAggregation))
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, registry.Enabled(PaymentAggregation))
+			}
+		})
+	}
+}
+
+func Test_Registry_IgnoresRemoteRulesWithoutVerifier(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.Write([]byte(`{"features": {"traversal-strategies": {"rollout": 0}}}`))
+	}))
+	defer server.Close()
+
+	registry := NewRegistry(Config{}, requests.NewHTTPClient("0.0.0.0", time.Second), server.URL, time.Minute, nil, nil)
+	registry.Start()
+
+	assert.False(t, requested)
+	assert.True(t, registry.Enabled(TraversalStrategies))
+}
+
+func Test_Registry_Start_FallsBackToCache(t *testing.T) {
+	storage := newMockRuleStorage()
+
+	body := []byte(`{"features": {"traversal-strategies": {"rollout": 0}}}`)
+	signature, _ := (&identity.SignerFake{}).Sign(body)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(SignatureHeader, hex.EncodeToString(signature.Bytes()))
+		w.Write(body)
+	}))
+	registry := NewRegistry(Config{}, requests.NewHTTPClient("0.0.0.0", time.Second), server.URL, time.Minute, &identity.VerifierFake{}, storage)
+	assert.NoError(t, registry.fetchRules())
+	server.Close()
+
+	registry = NewRegistry(Config{}, requests.NewHTTPClient("0.0.0.0", 100*time.Millisecond), server.URL, time.Minute, &identity.VerifierFake{}, storage)
+	go registry.Start()
+	defer registry.Stop()
+
+	assert.Eventually(t, func() bool {
+		return !registry.Enabled(TraversalStrategies)
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+type mockRuleStorage struct {
+	lock   sync.Mutex
+	values map[interface{}][]byte
+}
+
+func newMockRuleStorage() *mockRuleStorage {
+	return &mockRuleStorage{values: make(map[interface{}][]byte)}
+}
+
+func (s *mockRuleStorage) GetValue(bucket string, key interface{}, to interface{}) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, ok := s.values[key]
+	if !ok {
+		return errors.New("not found")
+	}
+	return json.Unmarshal(value, to)
+}
+
+func (s *mockRuleStorage) SetValue(bucket string, key interface{}, to interface{}) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, err := json.Marshal(to)
+	if err != nil {
+		return err
+	}
+	s.values[key] = value
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package feature
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/requests/signed"
+)
+
+const (
+	// SignatureHeader is the response header carrying the signature of the feature rules.
+	SignatureHeader = signed.SignatureHeader
+
+	cacheBucket = "feature-rules"
+)
+
+// Rules is the remote feature rollout document.
+type Rules struct {
+	Features map[string]Rule `json:"features"`
+}
+
+// Rule describes the remote rollout of a single feature.
+type Rule struct {
+	// Rollout is the percentage of nodes the feature is enabled for.
+	Rollout int `json:"rollout"`
+}
+
+// Start fetches remote feature rules periodically until stopped.
+// Cached rules are used if the initial fetch fails.
+func (r *Registry) Start() {
+	if r.fetchURL == "" {
+		return
+	}
+
+	if err := r.fetchRules(); err != nil {
+		if r.restoreRules() {
+			log.Warn().Err(err).Msg("Initial feature rules fetch failed, using cached rules")
+		} else {
+			log.Warn().Err(err).Msg("Initial feature rules fetch failed")
+		}
+	}
+
+	for {
+		select {
+		case <-r.fetchShutdown:
+			return
+		case <-time.After(r.fetchInterval):
+			if err := r.fetchRules(); err != nil {
+				log.Warn().Err(err).Msg("Feature rules fetch failed")
+			}
+		}
+	}
+}
+
+// Stop ends fetching of remote feature rules.
+func (r *Registry) Stop() {
+	r.fetchShutdownOnce.Do(func() {
+		close(r.fetchShutdown)
+	})
+}
+
+func (r *Registry) fetchRules() error {
+	r.lock.RLock()
+	eTag := r.eTag
+	r.lock.RUnlock()
+
+	var rules Rules
+	eTag, modified, err := r.fetcher.Fetch(r.fetchURL, eTag, &rules)
+	if err != nil {
+		return errors.Wrap(err, "failed to fetch feature rules")
+	}
+	if !modified {
+		return nil
+	}
+
+	r.lock.Lock()
+	r.rules = rules
+	r.eTag = eTag
+	r.lock.Unlock()
+	return nil
+}
+
+// restoreRules loads cached feature rules, reports whether any were cached.
+func (r *Registry) restoreRules() bool {
+	var rules Rules
+	eTag, ok := r.fetcher.Restore(r.fetchURL, &rules)
+	if !ok {
+		return false
+	}
+
+	r.lock.Lock()
+	r.rules = rules
+	r.eTag = eTag
+	r.lock.Unlock()
+	return true
+}
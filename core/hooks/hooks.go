@@ -0,0 +1,285 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+const (
+	// EventSessionStarted is sent when a provider session is created.
+	EventSessionStarted = "session_started"
+	// EventSessionStopped is sent when a provider session is removed.
+	EventSessionStopped = "session_stopped"
+	// EventSettlementCompleted is sent when the provider earnings were settled with hermes.
+	EventSettlementCompleted = "settlement_completed"
+
+	// SignatureHeader is the webhook request header carrying the event signature.
+	SignatureHeader = "X-Myst-Signature"
+	// EventHeader is the webhook request header carrying the event type.
+	EventHeader = "X-Myst-Event"
+	// SignatureEnv is the script environment variable carrying the event signature.
+	SignatureEnv = "MYST_HOOK_SIGNATURE"
+	// EventEnv is the script environment variable carrying the event type.
+	EventEnv = "MYST_HOOK_EVENT"
+)
+
+// Config describes where the lifecycle events are delivered.
+type Config struct {
+	WebhookURL string
+	Script     string
+	Secret     string
+	Timeout    time.Duration
+}
+
+// Event is the JSON payload delivered to the webhook and the script.
+type Event struct {
+	Type       string        `json:"type"`
+	Timestamp  time.Time     `json:"timestamp"`
+	Session    *Session      `json:"session,omitempty"`
+	Settlement *Settlement   `json:"settlement,omitempty"`
+	Promise    *Promise      `json:"promise,omitempty"`
+	Stall      *PaymentStall `json:"stall,omitempty"`
+}
+
+// Session describes the provider session of the event.
+type Session struct {
+	ID              string    `json:"id"`
+	ServiceID       string    `json:"service_id"`
+	ServiceType     string    `json:"service_type"`
+	ConsumerID      string    `json:"consumer_id"`
+	ConsumerCountry string    `json:"consumer_country"`
+	HermesID        string    `json:"hermes_id"`
+	StartedAt       time.Time `json:"started_at"`
+}
+
+// Settlement describes the settlement of the event.
+type Settlement struct {
+	ProviderID string `json:"provider_id"`
+	HermesID   string `json:"hermes_id"`
+	ChainID    int64  `json:"chain_id"`
+}
+
+// Dispatcher delivers the session lifecycle and settlement events to the configured webhook and script.
+// Events are delivered one by one in the order they were published.
+type Dispatcher struct {
+	config Config
+	client *http.Client
+	now    func() time.Time
+
+	queue chan Event
+	stop  chan struct{}
+	once  sync.Once
+}
+
+const (
+	defaultTimeout = 10 * time.Second
+	// queueSize is the number of events waiting for the delivery, newer events are dropped once it fills up.
+	queueSize = 100
+)
+
+// NewDispatcher returns a new lifecycle events dispatcher.
+func NewDispatcher(config Config) *Dispatcher {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	return &Dispatcher{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		queue:  make(chan Event, queueSize),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Enabled reports whether any event destination is configured.
+func (d *Dispatcher) Enabled() bool {
+	return d.config.WebhookURL != "" || d.config.Script != ""
+}
+
+// Subscribe subscribes the dispatcher to the lifecycle events and starts delivering them.
+// Events are queued synchronously, so they keep the order they were published in.
+func (d *Dispatcher) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(sessionEvent.AppTopicSession, d.handleSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(pingpongEvent.AppTopicSettlementComplete, d.handleSettlementEvent); err != nil {
+		return err
+	}
+
+	go d.run()
+	return nil
+}
+
+// Stop stops delivering the events, the queued events are dropped.
+func (d *Dispatcher) Stop() {
+	d.once.Do(func() {
+		close(d.stop)
+	})
+}
+
+func (d *Dispatcher) run() {
+	for {
+		select {
+		case <-d.stop:
+			return
+		case event := <-d.queue:
+			d.deliver(event)
+		}
+	}
+}
+
+func (d *Dispatcher) handleSessionEvent(e sessionEvent.AppEventSession) {
+	var eventType string
+	switch e.Status {
+	case sessionEvent.CreatedStatus:
+		eventType = EventSessionStarted
+	case sessionEvent.RemovedStatus:
+		eventType = EventSessionStopped
+	default:
+		return
+	}
+
+	d.dispatch(Event{
+		Type: eventType,
+		Session: &Session{
+			ID:              e.Session.ID,
+			ServiceID:       e.Service.ID,
+			ServiceType:     e.Session.Proposal.ServiceType,
+			ConsumerID:      e.Session.ConsumerID.Address,
+			ConsumerCountry: e.Session.ConsumerLocation.Country,
+			HermesID:        e.Session.HermesID.Hex(),
+			StartedAt:       e.Session.StartedAt,
+		},
+	})
+}
+
+func (d *Dispatcher) handleSettlementEvent(e pingpongEvent.AppEventSettlementComplete) {
+	d.dispatch(Event{
+		Type: EventSettlementCompleted,
+		Settlement: &Settlement{
+			ProviderID: e.ProviderID.Address,
+			HermesID:   e.HermesID.Hex(),
+			ChainID:    e.ChainID,
+		},
+	})
+}
+
+// dispatch queues the event for the delivery.
+func (d *Dispatcher) dispatch(event Event) {
+	event.Timestamp = d.now().UTC()
+
+	select {
+	case d.queue <- event:
+	default:
+		log.Warn().Msgf("Hook event queue is full, dropping %s event", event.Type)
+	}
+}
+
+func (d *Dispatcher) deliver(event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Msgf("Could not marshal %s hook event", event.Type)
+		return
+	}
+	signature := Sign(d.config.Secret, body)
+
+	if d.config.WebhookURL != "" {
+		if err := d.postWebhook(event.Type, body, signature); err != nil {
+			log.Warn().Err(err).Msgf("Could not deliver %s event to the webhook", event.Type)
+		}
+	}
+	if d.config.Script != "" {
+		if err := d.runScript(event.Type, body, signature); err != nil {
+			log.Warn().Err(err).Msgf("Could not deliver %s event to the script", event.Type)
+		}
+	}
+}
+
+func (d *Dispatcher) postWebhook(eventType string, body []byte, signature string) error {
+	_, err := postEvent(d.client, d.config.WebhookURL, eventType, body, signature)
+	return err
+}
+
+// postEvent posts the event body to the url, returning the response status code.
+func postEvent(client *http.Client, url, eventType string, body []byte, signature string) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	if signature != "" {
+		req.Header.Set(SignatureHeader, signature)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) runScript(eventType string, body []byte, signature string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, d.config.Script)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), EventEnv+"="+eventType, SignatureEnv+"="+signature)
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("script failed: %w output: %s", err, out)
+	}
+	return nil
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the event body prefixed by the algorithm,
+// or an empty string if no secret is given.
+func Sign(secret string, body []byte) string {
+	if secret == "" {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
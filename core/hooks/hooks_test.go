@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package hooks
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+var testTime = time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func TestDispatcher_PostsSignedSessionEvents(t *testing.T) {
+	type request struct {
+		event, signature string
+		body             []byte
+	}
+	requests := make(chan request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{event: r.Header.Get(EventHeader), signature: r.Header.Get(SignatureHeader), body: body}
+	}))
+	defer server.Close()
+
+	dispatcher := NewDispatcher(Config{WebhookURL: server.URL, Secret: "secret"})
+	dispatcher.now = func() time.Time { return testTime }
+	go dispatcher.run()
+	defer dispatcher.Stop()
+
+	sessionContext := sessionEvent.SessionContext{
+		ID:               "session1",
+		StartedAt:        testTime,
+		ConsumerID:       identity.FromAddress("0x1"),
+		ConsumerLocation: market.Location{Country: "LT"},
+		HermesID:         common.HexToAddress("0x2"),
+		Proposal:         market.ServiceProposal{ServiceType: "wireguard"},
+	}
+	dispatcher.handleSessionEvent(sessionEvent.AppEventSession{Status: sessionEvent.AcknowledgedStatus, Session: sessionContext})
+	dispatcher.handleSessionEvent(sessionEvent.AppEventSession{Status: sessionEvent.CreatedStatus, Service: sessionEvent.ServiceContext{ID: "service1"}, Session: sessionContext})
+
+	req := <-requests
+	assert.Equal(t, EventSessionStarted, req.event)
+	assert.Equal(t, Sign("secret", req.body), req.signature)
+
+	var event Event
+	require.NoError(t, json.Unmarshal(req.body, &event))
+	assert.Equal(t, Event{
+		Type:      EventSessionStarted,
+		Timestamp: testTime,
+		Session: &Session{
+			ID:              "session1",
+			ServiceID:       "service1",
+			ServiceType:     "wireguard",
+			ConsumerID:      "0x1",
+			ConsumerCountry: "LT",
+			HermesID:        common.HexToAddress("0x2").Hex(),
+			StartedAt:       testTime,
+		},
+	}, event)
+
+	dispatcher.handleSessionEvent(sessionEvent.AppEventSession{Status: sessionEvent.RemovedStatus, Session: sessionContext})
+	assert.Equal(t, EventSessionStopped, (<-requests).event)
+	assert.Len(t, requests, 0)
+}
+
+func TestDispatcher_RunsScriptWithSettlementEvent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not supported on windows")
+	}
+
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	script := filepath.Join(dir, "hook.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$"+EventEnv+" $"+SignatureEnv+"\" > "+output+"\ncat >> "+output+"\n"), 0700)
+	require.NoError(t, err)
+
+	dispatcher := NewDispatcher(Config{Script: script})
+	dispatcher.now = func() time.Time { return testTime }
+
+	dispatcher.handleSettlementEvent(pingpongEvent.AppEventSettlementComplete{
+		ProviderID: identity.FromAddress("0x1"),
+		HermesID:   common.HexToAddress("0x2"),
+		ChainID:    137,
+	})
+	dispatcher.deliver(<-dispatcher.queue)
+
+	out, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t,
+		EventSettlementCompleted+" \n"+`{"type":"settlement_completed","timestamp":"2022-03-01T10:00:00Z","settlement":{"provider_id":"0x1","hermes_id":"`+common.HexToAddress("0x2").Hex()+`","chain_id":137}}`,
+		string(out),
+	)
+}
+
+func TestDispatcher_DeliversEventsInPublishOrder(t *testing.T) {
+	events := make(chan string, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Slow first delivery must not let the later events overtake it.
+		if r.Header.Get(EventHeader) == EventSessionStarted {
+			time.Sleep(20 * time.Millisecond)
+		}
+		events <- r.Header.Get(EventHeader)
+	}))
+	defer server.Close()
+
+	bus := eventbus.New()
+	dispatcher := NewDispatcher(Config{WebhookURL: server.URL})
+	require.NoError(t, dispatcher.Subscribe(bus))
+	defer dispatcher.Stop()
+
+	bus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{Status: sessionEvent.CreatedStatus})
+	bus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{Status: sessionEvent.RemovedStatus})
+	bus.Publish(pingpongEvent.AppTopicSettlementComplete, pingpongEvent.AppEventSettlementComplete{})
+
+	assert.Equal(t, EventSessionStarted, <-events)
+	assert.Equal(t, EventSessionStopped, <-events)
+	assert.Equal(t, EventSettlementCompleted, <-events)
+}
+
+func TestSign(t *testing.T) {
+	assert.Equal(t, "", Sign("", []byte("body")))
+	assert.Equal(t, "sha256=dc46983557fea127b43af721467eb9b3fde2338fe3e14f51952aa8478c13d355", Sign("secret", []byte("body")))
+}
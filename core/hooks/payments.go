@@ -0,0 +1,315 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package hooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/utils/retry"
+)
+
+const (
+	// EventPromiseReceived is sent when the provider received a promise from hermes.
+	EventPromiseReceived = "promise_received"
+	// EventPaymentStalled is sent when a provider session was terminated because its payments stopped making progress.
+	EventPaymentStalled = "payment_stalled"
+
+	// DeliveryPending is the status of a delivery which is being attempted.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDelivered is the status of a delivery accepted by the endpoint.
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryFailed is the status of a delivery which ran out of attempts.
+	DeliveryFailed DeliveryStatus = "failed"
+
+	// maxDeliveries is the number of the most recent deliveries kept for the status API.
+	maxDeliveries = 100
+)
+
+// PaymentEvents are the payment events the publisher is able to forward.
+var PaymentEvents = []string{EventPromiseReceived, EventSettlementCompleted, EventPaymentStalled}
+
+// PaymentConfig describes where and how the payment events are delivered.
+type PaymentConfig struct {
+	URLs []string
+	// Events selects the forwarded payment events, all of them if empty.
+	Events     []string
+	Secret     string
+	Timeout    time.Duration
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// Promise describes the hermes promise of the event.
+type Promise struct {
+	ProviderID string `json:"provider_id"`
+	HermesID   string `json:"hermes_id"`
+	ChainID    int64  `json:"chain_id"`
+	Amount     string `json:"amount"`
+	Fee        string `json:"fee"`
+}
+
+// PaymentStall describes the session whose payments stalled.
+type PaymentStall struct {
+	SessionID  string   `json:"session_id"`
+	Goroutines []string `json:"goroutines"`
+}
+
+// DeliveryStatus is the state of a single event delivery to a single endpoint.
+type DeliveryStatus string
+
+// Delivery describes the delivery of a payment event to a single endpoint.
+type Delivery struct {
+	ID             uint64
+	Event          string
+	URL            string
+	Status         DeliveryStatus
+	Attempts       int
+	LastError      string
+	LastStatusCode int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// PaymentPublisher forwards the selected payment events to the operator endpoints as signed HTTP POSTs,
+// retrying failed deliveries with exponential backoff.
+type PaymentPublisher struct {
+	config PaymentConfig
+	events map[string]bool
+	client *http.Client
+	now    func() time.Time
+
+	mu         sync.Mutex
+	deliveries []*Delivery
+	nextID     uint64
+
+	wg   sync.WaitGroup
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewPaymentPublisher returns a new payment events publisher.
+func NewPaymentPublisher(config PaymentConfig) (*PaymentPublisher, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+
+	events := make(map[string]bool)
+	selected := config.Events
+	if len(selected) == 0 {
+		selected = PaymentEvents
+	}
+	for _, e := range selected {
+		if !isPaymentEvent(e) {
+			return nil, fmt.Errorf("unknown payment event %q, expected one of %v", e, PaymentEvents)
+		}
+		events[e] = true
+	}
+
+	return &PaymentPublisher{
+		config: config,
+		events: events,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		stop:   make(chan struct{}),
+	}, nil
+}
+
+func isPaymentEvent(event string) bool {
+	for _, e := range PaymentEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether any endpoint is configured.
+func (p *PaymentPublisher) Enabled() bool {
+	return len(p.config.URLs) > 0
+}
+
+// Subscribe subscribes the publisher to the selected payment events.
+func (p *PaymentPublisher) Subscribe(bus eventbus.Subscriber) error {
+	if p.events[EventPromiseReceived] {
+		if err := bus.SubscribeAsync(pingpongEvent.AppTopicHermesPromise, p.handlePromiseEvent); err != nil {
+			return err
+		}
+	}
+	if p.events[EventSettlementCompleted] {
+		if err := bus.SubscribeAsync(pingpongEvent.AppTopicSettlementComplete, p.handleSettlementEvent); err != nil {
+			return err
+		}
+	}
+	if p.events[EventPaymentStalled] {
+		if err := bus.SubscribeAsync(pingpongEvent.AppTopicInvoiceTrackerStuck, p.handleStallEvent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop aborts the pending retries and waits for the running attempts to finish.
+func (p *PaymentPublisher) Stop() {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+	p.wg.Wait()
+}
+
+// Deliveries returns the most recent deliveries, newest first, optionally narrowed down to the given status.
+func (p *PaymentPublisher) Deliveries(status DeliveryStatus) []Delivery {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]Delivery, 0, len(p.deliveries))
+	for i := len(p.deliveries) - 1; i >= 0; i-- {
+		if status != "" && p.deliveries[i].Status != status {
+			continue
+		}
+		result = append(result, *p.deliveries[i])
+	}
+	return result
+}
+
+func (p *PaymentPublisher) handlePromiseEvent(e pingpongEvent.AppEventHermesPromise) {
+	promise := &Promise{
+		ProviderID: e.ProviderID.Address,
+		HermesID:   e.HermesID.Hex(),
+		ChainID:    e.Promise.ChainID,
+	}
+	if e.Promise.Amount != nil {
+		promise.Amount = e.Promise.Amount.String()
+	}
+	if e.Promise.Fee != nil {
+		promise.Fee = e.Promise.Fee.String()
+	}
+	p.publish(Event{Type: EventPromiseReceived, Promise: promise})
+}
+
+func (p *PaymentPublisher) handleSettlementEvent(e pingpongEvent.AppEventSettlementComplete) {
+	p.publish(Event{
+		Type: EventSettlementCompleted,
+		Settlement: &Settlement{
+			ProviderID: e.ProviderID.Address,
+			HermesID:   e.HermesID.Hex(),
+			ChainID:    e.ChainID,
+		},
+	})
+}
+
+func (p *PaymentPublisher) handleStallEvent(e pingpongEvent.AppEventInvoiceTrackerStuck) {
+	p.publish(Event{
+		Type: EventPaymentStalled,
+		Stall: &PaymentStall{
+			SessionID:  e.SessionID,
+			Goroutines: e.Goroutines,
+		},
+	})
+}
+
+func (p *PaymentPublisher) publish(event Event) {
+	event.Timestamp = p.now().UTC()
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Error().Err(err).Msgf("Could not marshal %s payment event", event.Type)
+		return
+	}
+	signature := Sign(p.config.Secret, body)
+
+	for _, url := range p.config.URLs {
+		d := p.newDelivery(event.Type, url)
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.deliver(d, body, signature)
+		}()
+	}
+}
+
+func (p *PaymentPublisher) newDelivery(eventType, url string) *Delivery {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.nextID++
+	now := p.now().UTC()
+	d := &Delivery{
+		ID:        p.nextID,
+		Event:     eventType,
+		URL:       url,
+		Status:    DeliveryPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	p.deliveries = append(p.deliveries, d)
+	if len(p.deliveries) > maxDeliveries {
+		p.deliveries = p.deliveries[len(p.deliveries)-maxDeliveries:]
+	}
+	return d
+}
+
+func (p *PaymentPublisher) deliver(d *Delivery, body []byte, signature string) {
+	ctx, cancel := retry.UntilClosed(p.stop)
+	defer cancel()
+
+	policy := retry.Webhook
+	policy.Interval = p.config.RetryDelay
+
+	attempt := 0
+	err := retry.Do(ctx, policy, func() error {
+		attempt++
+		statusCode, err := postEvent(p.client, d.URL, d.Event, body, signature)
+
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		d.Attempts = attempt
+		d.LastStatusCode = statusCode
+		d.UpdatedAt = p.now().UTC()
+		if err == nil {
+			d.Status = DeliveryDelivered
+			d.LastError = ""
+			return nil
+		}
+		d.LastError = err.Error()
+		if attempt > p.config.Retries {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+	if err == nil {
+		return
+	}
+
+	p.mu.Lock()
+	d.Status = DeliveryFailed
+	p.mu.Unlock()
+	log.Warn().Err(err).Msgf("Could not deliver %s event to %s after %d attempts", d.Event, d.URL, attempt)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package hooks
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func TestPaymentPublisher_PostsSignedPromiseEvents(t *testing.T) {
+	type request struct {
+		event, signature string
+		body             []byte
+	}
+	requests := make(chan request, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{event: r.Header.Get(EventHeader), signature: r.Header.Get(SignatureHeader), body: body}
+	}))
+	defer server.Close()
+
+	publisher, err := NewPaymentPublisher(PaymentConfig{URLs: []string{server.URL, server.URL}, Secret: "secret"})
+	require.NoError(t, err)
+	publisher.now = func() time.Time { return testTime }
+
+	publisher.handlePromiseEvent(pingpongEvent.AppEventHermesPromise{
+		Promise:    crypto.Promise{ChainID: 137, Amount: big.NewInt(100), Fee: big.NewInt(1)},
+		HermesID:   common.HexToAddress("0x2"),
+		ProviderID: identity.FromAddress("0x1"),
+	})
+	publisher.Stop()
+
+	for i := 0; i < 2; i++ {
+		req := <-requests
+		assert.Equal(t, EventPromiseReceived, req.event)
+		assert.Equal(t, Sign("secret", req.body), req.signature)
+
+		var event Event
+		require.NoError(t, json.Unmarshal(req.body, &event))
+		assert.Equal(t, Event{
+			Type:      EventPromiseReceived,
+			Timestamp: testTime,
+			Promise: &Promise{
+				ProviderID: "0x1",
+				HermesID:   common.HexToAddress("0x2").Hex(),
+				ChainID:    137,
+				Amount:     "100",
+				Fee:        "1",
+			},
+		}, event)
+	}
+
+	deliveries := publisher.Deliveries(DeliveryDelivered)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, uint64(2), deliveries[0].ID)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusOK, deliveries[0].LastStatusCode)
+}
+
+func TestPaymentPublisher_RetriesFailedDeliveries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	publisher, err := NewPaymentPublisher(PaymentConfig{URLs: []string{server.URL}, Retries: 2, RetryDelay: time.Millisecond})
+	require.NoError(t, err)
+
+	publisher.handleSettlementEvent(pingpongEvent.AppEventSettlementComplete{ProviderID: identity.FromAddress("0x1")})
+	publisher.wg.Wait()
+
+	deliveries := publisher.Deliveries("")
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, DeliveryDelivered, deliveries[0].Status)
+	assert.Equal(t, 3, deliveries[0].Attempts)
+	assert.Empty(t, deliveries[0].LastError)
+}
+
+func TestPaymentPublisher_FailsAfterRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	publisher, err := NewPaymentPublisher(PaymentConfig{URLs: []string{server.URL}, Retries: 1, RetryDelay: time.Millisecond})
+	require.NoError(t, err)
+
+	publisher.handleStallEvent(pingpongEvent.AppEventInvoiceTrackerStuck{SessionID: "session1"})
+	publisher.wg.Wait()
+
+	assert.Empty(t, publisher.Deliveries(DeliveryDelivered))
+	deliveries := publisher.Deliveries(DeliveryFailed)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, EventPaymentStalled, deliveries[0].Event)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, deliveries[0].LastStatusCode)
+	assert.Equal(t, "webhook responded with status 500", deliveries[0].LastError)
+}
+
+func TestPaymentPublisher_ForwardsSelectedEvents(t *testing.T) {
+	_, err := NewPaymentPublisher(PaymentConfig{Events: []string{EventSessionStarted}})
+	assert.Error(t, err)
+
+	events := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events <- r.Header.Get(EventHeader)
+	}))
+	defer server.Close()
+
+	publisher, err := NewPaymentPublisher(PaymentConfig{URLs: []string{server.URL}, Events: []string{EventSettlementCompleted}})
+	require.NoError(t, err)
+
+	bus := eventbus.New()
+	require.NoError(t, publisher.Subscribe(bus))
+	bus.Publish(pingpongEvent.AppTopicHermesPromise, pingpongEvent.AppEventHermesPromise{Promise: crypto.Promise{Amount: big.NewInt(1)}})
+	bus.Publish(pingpongEvent.AppTopicSettlementComplete, pingpongEvent.AppEventSettlementComplete{ProviderID: identity.FromAddress("0x1")})
+
+	assert.Equal(t, EventSettlementCompleted, <-events)
+	publisher.Stop()
+	assert.Len(t, publisher.Deliveries(""), 1)
+}
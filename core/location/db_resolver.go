@@ -67,6 +67,11 @@ func (r *DBResolver) DetectProxyLocation(proxyPort int) (loc locationstate.Locat
 	return r.detectLocation(ipAddress)
 }
 
+// LocateIP provides location information for the given IP-address.
+func (r *DBResolver) LocateIP(ipAddress string) (locationstate.Location, error) {
+	return r.detectLocation(ipAddress)
+}
+
 func (r *DBResolver) detectLocation(ipAddress string) (loc locationstate.Location, err error) {
 	log.Debug().Msg("Detecting with DB resolver")
 
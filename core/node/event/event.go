@@ -17,6 +17,10 @@
 
 package event
 
+import (
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
 const (
 	// AppTopicNode represents the topic we're gonna be publishing and subscribing on
 	AppTopicNode = "Node"
@@ -33,3 +37,7 @@ type Status string
 type Payload struct {
 	Status Status
 }
+
+func init() {
+	eventbus.RegisterSchema(AppTopicNode, 1, Payload{})
+}
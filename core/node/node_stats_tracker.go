@@ -61,6 +61,9 @@ type ProviderActivityStats func(id identity.Identity) (ActivityStats, error)
 // ProviderQuality should return provider quality
 type ProviderQuality func(id identity.Identity) (QualityInfo, error)
 
+// ProviderReputation should return provider ranking and per service quality metrics
+type ProviderReputation func(id identity.Identity) (Reputation, error)
+
 // StatsTracker tracks metrics for service
 type StatsTracker struct {
 	providerStatuses              ProviderStatuses
@@ -73,6 +76,7 @@ type StatsTracker struct {
 	providerTransferredDataSeries ProviderTransferredDataSeries
 	providerActivityStats         ProviderActivityStats
 	providerQuality               ProviderQuality
+	providerReputation            ProviderReputation
 	providerServiceEarnings       ProviderServiceEarnings
 	currentIdentity               currentIdentity
 }
@@ -89,6 +93,7 @@ func NewNodeStatsTracker(
 	providerTransferredDataSeries ProviderTransferredDataSeries,
 	providerActivityStats ProviderActivityStats,
 	providerQuality ProviderQuality,
+	providerReputation ProviderReputation,
 	providerServiceEarnings ProviderServiceEarnings,
 	currentIdentity currentIdentity,
 ) *StatsTracker {
@@ -103,6 +108,7 @@ func NewNodeStatsTracker(
 		providerTransferredDataSeries: providerTransferredDataSeries,
 		providerActivityStats:         providerActivityStats,
 		providerQuality:               providerQuality,
+		providerReputation:            providerReputation,
 		providerServiceEarnings:       providerServiceEarnings,
 		currentIdentity:               currentIdentity,
 	}
@@ -178,6 +184,28 @@ type QualityInfo struct {
 	Quality float64 `json:"quality"`
 }
 
+// Reputation represents provider standing among the other providers as seen by quality oracle.
+type Reputation struct {
+	Quality               float64             `json:"quality"`
+	Rank                  int                 `json:"rank"`
+	ProvidersCount        int                 `json:"providers_count"`
+	Country               string              `json:"country"`
+	CountryRank           int                 `json:"country_rank"`
+	CountryProvidersCount int                 `json:"country_providers_count"`
+	Services              []ServiceReputation `json:"services"`
+	UpdatedAt             int64               `json:"updated_at"`
+}
+
+// ServiceReputation represents quality oracle metrics of a single service provided by the node.
+type ServiceReputation struct {
+	ServiceType      string  `json:"service_type"`
+	Quality          float64 `json:"quality"`
+	Latency          float64 `json:"latency"`
+	Bandwidth        float64 `json:"bandwidth"`
+	Uptime           float64 `json:"uptime"`
+	MonitoringFailed bool    `json:"monitoring_failed"`
+}
+
 // EarningsPerService represents information about earnings per service
 type EarningsPerService struct {
 	EarningsPublic   string `json:"public"`
@@ -266,6 +294,16 @@ func (m *StatsTracker) ProviderQuality() (QualityInfo, error) {
 	return QualityInfo{}, errIdentityNotFound
 }
 
+// ProviderReputation retrieves and resolved provider ranking among the other providers
+func (m *StatsTracker) ProviderReputation() (Reputation, error) {
+	id, ok := m.currentIdentity.GetUnlockedIdentity()
+	if ok {
+		return m.providerReputation(id)
+	}
+
+	return Reputation{}, errIdentityNotFound
+}
+
 // ProviderActivityStats retrieves and resolved provider activity stats
 func (m *StatsTracker) ProviderActivityStats() (ActivityStats, error) {
 	id, ok := m.currentIdentity.GetUnlockedIdentity()
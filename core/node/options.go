@@ -60,6 +60,7 @@ type Options struct {
 	TequilapiPort          int
 	FlagTequilapiDebugMode bool
 	TequilapiEnabled       bool
+	TequilapiRequestLog    OptionsRequestLog
 	BindAddress            string
 	UI                     OptionsUI
 	FeedbackURL            string
@@ -75,17 +76,27 @@ type Options struct {
 	Affiliator OptionsAffiliator
 	Chains     OptionsChains
 
-	Openvpn  Openvpn
-	Firewall OptionsFirewall
+	Openvpn   Openvpn
+	Wireguard OptionsWireguard
+	Firewall  OptionsFirewall
 
 	Payments OptionsPayments
 
 	Consumer bool
 
 	SwarmDialerDNSHeadstart time.Duration
-	PilvytisAddress         string
-	ObserverAddress         string
-	SSE                     OptionsSSE
+	// StorageSlowOperationThreshold is the duration after which storage operations are logged as slow.
+	StorageSlowOperationThreshold time.Duration
+	PilvytisAddress               string
+	ObserverAddress               string
+	SSE                           OptionsSSE
+	Blacklist                     OptionsBlacklist
+	LeakTest                      OptionsLeakTest
+	EphemeralIdentities           OptionsEphemeralIdentities
+	DNS                           OptionsDNS
+	SignAudit                     OptionsSignAudit
+	AccessLog                     OptionsAccessLog
+	Hooks                         OptionsHooks
 }
 
 // GetOptions retrieves node options from the app configuration.
@@ -97,6 +108,7 @@ func GetOptions() *Options {
 		EtherClientRPCL1: config.GetStringSlice(config.FlagEtherRPCL1),
 		EtherClientRPCL2: config.GetStringSlice(config.FlagEtherRPCL2),
 		ChainID:          config.GetInt64(config.FlagChainID),
+		OutboundProxy:    config.GetString(config.FlagOutboundProxy),
 		DNSMap: map[string][]string{
 			"location.mysterium.network": {"51.158.129.204"},
 			"quality.mysterium.network":  {"51.158.129.204"},
@@ -122,10 +134,19 @@ func GetOptions() *Options {
 			UIBindAddress: config.GetString(config.FlagUIAddress),
 			UIPort:        config.GetInt(config.FlagUIPort),
 		},
-		SwarmDialerDNSHeadstart: config.GetDuration(config.FlagDNSResolutionHeadstart),
-		FeedbackURL:             config.GetString(config.FlagFeedbackURL),
+		TequilapiRequestLog: OptionsRequestLog{
+			Enabled:    config.GetBool(config.FlagTequilapiRequestLog),
+			SampleRate: config.GetFloat64(config.FlagTequilapiRequestLogSampleRate),
+			Bodies:     config.GetBool(config.FlagTequilapiRequestLogBodies),
+			Include:    config.GetStringSlice(config.FlagTequilapiRequestLogInclude),
+			Exclude:    config.GetStringSlice(config.FlagTequilapiRequestLogExclude),
+		},
+		SwarmDialerDNSHeadstart:       config.GetDuration(config.FlagDNSResolutionHeadstart),
+		StorageSlowOperationThreshold: config.GetDuration(config.FlagStorageSlowOperationThreshold),
+		FeedbackURL:                   config.GetString(config.FlagFeedbackURL),
 		Keystore: OptionsKeystore{
 			UseLightweight: config.GetBool(config.FlagKeystoreLightweight),
+			AutoRepair:     config.GetBool(config.FlagKeystoreAutoRepair),
 		},
 		LogOptions:     *GetLogOptions(),
 		OptionsNetwork: network,
@@ -153,6 +174,8 @@ func GetOptions() *Options {
 		Payments: OptionsPayments{
 			MaxAllowedPaymentPercentile:    config.GetInt(config.FlagPaymentsMaxHermesFee),
 			BCTimeout:                      config.GetDuration(config.FlagPaymentsBCTimeout),
+			BCCacheTTL:                     config.GetDuration(config.FlagPaymentsBCCacheTTL),
+			BCChannelCacheTTL:              config.GetDuration(config.FlagPaymentsBCChannelCacheTTL),
 			HermesPromiseSettlingThreshold: config.GetFloat64(config.FlagPaymentsHermesPromiseSettleThreshold),
 			MaxFeeSettlingThreshold:        config.GetFloat64(config.FlagPaymentsPromiseSettleMaxFeeThreshold),
 			MaxUnSettledAmount:             config.GetFloat64(config.FlagPaymentsUnsettledMaxAmount),
@@ -163,14 +186,43 @@ func GetOptions() *Options {
 			BalanceFastPollTimeout:         config.GetDuration(config.FlagPaymentsFastBalancePollTimeout),
 			RegistryTransactorPollInterval: config.GetDuration(config.FlagPaymentsRegistryTransactorPollInterval),
 			RegistryTransactorPollTimeout:  config.GetDuration(config.FlagPaymentsRegistryTransactorPollTimeout),
+			RegistryStuckTimeout:           config.GetDuration(config.FlagPaymentsRegistryStuckTimeout),
 			ConsumerDataLeewayMegabytes:    config.GetUInt64(config.FlagPaymentsConsumerDataLeewayMegabytes),
 			HermesStatusRecheckInterval:    config.GetDuration(config.FlagPaymentsHermesStatusRecheckInterval),
+			HermesAvailabilityInterval:     config.GetDuration(config.FlagPaymentsHermesAvailabilityCheckInterval),
+			ClockSkewCheckInterval:         config.GetDuration(config.FlagPaymentsClockSkewCheckInterval),
+			ClockSkewThreshold:             config.GetDuration(config.FlagPaymentsClockSkewThreshold),
+			PeerClockSkewTolerance:         config.GetDuration(config.FlagPaymentsPeerClockSkewTolerance),
+			PeerClockSkewMax:               config.GetDuration(config.FlagPaymentsPeerClockSkewMax),
+			InvoiceWatchdogInterval:        config.GetDuration(config.FlagPaymentsInvoiceWatchdogInterval),
+			ShutdownSettleThreshold:        config.GetFloat64(config.FlagPaymentsProviderShutdownSettleThreshold),
+			ShutdownSettleTimeout:          config.GetDuration(config.FlagPaymentsProviderShutdownSettleTimeout),
 			MinAutoSettleAmount:            config.GetFloat64(config.FlagPaymentsZeroStakeUnsettledAmount),
 
 			ProviderInvoiceFrequency:      config.GetDuration(config.FlagPaymentsProviderInvoiceFrequency),
 			ProviderLimitInvoiceFrequency: config.GetDuration(config.FlagPaymentsLimitProviderInvoiceFrequency),
+			ProviderInitialFreeWindow:     config.GetDuration(config.FlagPaymentsProviderInitialFreeWindow),
 			MaxUnpaidInvoiceValue:         config.GetBigInt(config.FlagPaymentsUnpaidInvoiceValue),
 			LimitUnpaidInvoiceValue:       config.GetBigInt(config.FlagPaymentsLimitUnpaidInvoiceValue),
+
+			ProviderChargePeriods:   config.GetString(config.FlagPaymentsProviderChargePeriods),
+			ProviderChargePeriodMin: config.GetDuration(config.FlagPaymentsProviderChargePeriodMin),
+			ProviderChargePeriodMax: config.GetDuration(config.FlagPaymentsProviderChargePeriodMax),
+
+			ProviderBillingAnomalyTolerance:     config.GetDuration(config.FlagPaymentsProviderBillingAnomalyTolerance),
+			ProviderBillingAnomalyMaxHourlyRate: config.GetBigInt(config.FlagPaymentsProviderBillingAnomalyMaxHourlyRate),
+			ProviderBillingAnomalyPause:         config.GetBool(config.FlagPaymentsProviderBillingAnomalyPause),
+			ProviderBillingAnomalyPauseDuration: config.GetDuration(config.FlagPaymentsProviderBillingAnomalyPauseDuration),
+			ProviderHermesFeePolicy:             config.GetString(config.FlagPaymentsProviderHermesFeePolicy),
+			ProviderHermesFeeGracePeriod:        config.GetDuration(config.FlagPaymentsProviderHermesFeeGracePeriod),
+			ProviderHermesFeeCheckInterval:      config.GetDuration(config.FlagPaymentsProviderHermesFeeCheckInterval),
+
+			ProviderAutoPricing:           config.GetBool(config.FlagPaymentsProviderAutoPricing),
+			ProviderAutoPricingInterval:   config.GetDuration(config.FlagPaymentsProviderAutoPricingInterval),
+			ProviderAutoPricingMinPerHour: config.GetBigInt(config.FlagPaymentsProviderAutoPricingMinPriceHour),
+			ProviderAutoPricingMaxPerHour: config.GetBigInt(config.FlagPaymentsProviderAutoPricingMaxPriceHour),
+			ProviderAutoPricingMinPerGiB:  config.GetBigInt(config.FlagPaymentsProviderAutoPricingMinPriceGiB),
+			ProviderAutoPricingMaxPerGiB:  config.GetBigInt(config.FlagPaymentsProviderAutoPricingMaxPriceGiB),
 		},
 		Chains: OptionsChains{
 			Chain1: metadata.ChainDefinition{
@@ -193,6 +245,11 @@ func GetOptions() *Options {
 		Openvpn: wrapper{nodeOptions: openvpn_core.NodeOptions{
 			BinaryPath: config.GetString(config.FlagOpenvpnBinary),
 		}},
+		Wireguard: OptionsWireguard{
+			KeepAlive:         config.GetDuration(config.FlagWireguardKeepAlive),
+			ProviderKeepAlive: config.GetDuration(config.FlagWireguardProviderKeepAlive),
+			RekeyTimeout:      config.GetDuration(config.FlagWireguardRekeyTimeout),
+		},
 		Firewall: OptionsFirewall{
 			BlockAlways: config.GetBool(config.FlagFirewallKillSwitch),
 		},
@@ -202,6 +259,47 @@ func GetOptions() *Options {
 		SSE: OptionsSSE{
 			Enabled: config.GetBool(config.FlagSSEEnable),
 		},
+		Blacklist: OptionsBlacklist{
+			Threshold: config.GetFloat64(config.FlagBlacklistThreshold),
+			HalfLife:  config.GetDuration(config.FlagBlacklistHalfLife),
+		},
+		LeakTest: OptionsLeakTest{
+			Enabled:        config.GetBool(config.FlagLeakTestEnabled),
+			AutoDisconnect: config.GetBool(config.FlagLeakTestAutoDisconnect),
+		},
+		EphemeralIdentities: OptionsEphemeralIdentities{
+			Enabled:         config.GetBool(config.FlagEphemeralIdentitiesEnabled),
+			FundAmount:      config.GetFloat64(config.FlagEphemeralIdentitiesFundAmount),
+			MinBalance:      config.GetFloat64(config.FlagEphemeralIdentitiesMinBalance),
+			CleanupInterval: config.GetDuration(config.FlagEphemeralIdentitiesCleanupInterval),
+		},
+		DNS: OptionsDNS{
+			LocalAddress:   config.GetString(config.FlagDNSLocalAddress),
+			LocalPort:      config.GetInt(config.FlagDNSLocalPort),
+			LocalCacheSize: config.GetInt(config.FlagDNSLocalCacheSize),
+			Upstreams:      config.GetStringSlice(config.FlagDNSLocalUpstreams),
+			Blocklist:      config.GetStringSlice(config.FlagDNSLocalBlocklist),
+		},
+		SignAudit: OptionsSignAudit{
+			Enabled:   config.GetBool(config.FlagSignAuditEnabled),
+			Retention: config.GetDuration(config.FlagSignAuditRetention),
+		},
+		AccessLog: OptionsAccessLog{
+			Enabled:       config.GetBool(config.FlagAccessLogEnabled),
+			Retention:     config.GetDuration(config.FlagAccessLogRetention),
+			Anonymization: config.GetString(config.FlagAccessLogAnonymization),
+		},
+		Hooks: OptionsHooks{
+			WebhookURL: config.GetString(config.FlagHooksWebhookURL),
+			Script:     config.GetString(config.FlagHooksScript),
+			Secret:     config.GetString(config.FlagHooksSecret),
+			Timeout:    config.GetDuration(config.FlagHooksTimeout),
+
+			PaymentURLs:       config.GetStringSlice(config.FlagHooksPaymentURLs),
+			PaymentEvents:     config.GetStringSlice(config.FlagHooksPaymentEvents),
+			PaymentRetries:    config.GetInt(config.FlagHooksPaymentRetries),
+			PaymentRetryDelay: config.GetDuration(config.FlagHooksPaymentRetryDelay),
+		},
 	}
 }
 
@@ -234,9 +332,13 @@ func GetDiscoveryOptions() *OptionsDiscovery {
 	return &OptionsDiscovery{
 		Types:         types,
 		PingInterval:  config.GetDuration(config.FlagDiscoveryPingInterval),
+		ProposalTTL:   config.GetDuration(config.FlagDiscoveryProposalTTL),
 		FetchEnabled:  true,
 		FetchInterval: config.GetDuration(config.FlagDiscoveryFetchInterval),
 		DHT:           *GetDHTOptions(),
+		LAN: OptionsLAN{
+			Free: config.GetBool(config.FlagLANDiscoveryFree),
+		},
 	}
 }
 
@@ -253,4 +355,5 @@ func GetDHTOptions() *OptionsDHT {
 // OptionsKeystore stores the keystore configuration
 type OptionsKeystore struct {
 	UseLightweight bool
+	AutoRepair     bool
 }
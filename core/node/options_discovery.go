@@ -29,6 +29,8 @@ const (
 	DiscoveryTypeBroker = DiscoveryType("broker")
 	// DiscoveryTypeDHT defines type which discovers proposals through DHT (Distributed Hash Table).
 	DiscoveryTypeDHT = DiscoveryType("dht")
+	// DiscoveryTypeLAN defines type which discovers proposals in the local network through mDNS.
+	DiscoveryTypeLAN = DiscoveryType("lan")
 )
 
 // OptionsDiscovery describes possible parameters of discovery configuration.
@@ -36,9 +38,11 @@ type OptionsDiscovery struct {
 	Types         []DiscoveryType
 	Address       string
 	PingInterval  time.Duration
+	ProposalTTL   time.Duration
 	FetchEnabled  bool
 	FetchInterval time.Duration
 	DHT           OptionsDHT
+	LAN           OptionsLAN
 }
 
 // OptionsDHT describes possible parameters of DHT configuration.
@@ -48,3 +52,8 @@ type OptionsDHT struct {
 	Protocol       string
 	BootstrapPeers []string
 }
+
+// OptionsLAN describes possible parameters of local network discovery configuration.
+type OptionsLAN struct {
+	Free bool
+}
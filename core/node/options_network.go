@@ -28,4 +28,5 @@ type OptionsNetwork struct {
 	EtherClientRPCL2 []string
 	ChainID          int64
 	DNSMap           map[string][]string
+	OutboundProxy    string
 }
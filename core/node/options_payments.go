@@ -26,22 +26,54 @@ import (
 type OptionsPayments struct {
 	MaxAllowedPaymentPercentile    int
 	BCTimeout                      time.Duration
+	BCCacheTTL                     time.Duration
+	BCChannelCacheTTL              time.Duration
 	HermesPromiseSettlingThreshold float64
 	MaxFeeSettlingThreshold        float64
 	SettlementTimeout              time.Duration
 	SettlementRecheckInterval      time.Duration
 	ConsumerDataLeewayMegabytes    uint64
 	HermesStatusRecheckInterval    time.Duration
+	HermesAvailabilityInterval     time.Duration
+	ClockSkewCheckInterval         time.Duration
+	ClockSkewThreshold             time.Duration
+	PeerClockSkewTolerance         time.Duration
+	PeerClockSkewMax               time.Duration
+	InvoiceWatchdogInterval        time.Duration
+	ShutdownSettleThreshold        float64
+	ShutdownSettleTimeout          time.Duration
 	BalanceFastPollInterval        time.Duration
 	BalanceFastPollTimeout         time.Duration
 	BalanceLongPollInterval        time.Duration
 	RegistryTransactorPollInterval time.Duration
 	RegistryTransactorPollTimeout  time.Duration
+	RegistryStuckTimeout           time.Duration
 	MinAutoSettleAmount            float64
 	MaxUnSettledAmount             float64
 
 	ProviderInvoiceFrequency      time.Duration
 	ProviderLimitInvoiceFrequency time.Duration
+	ProviderInitialFreeWindow     time.Duration
+
+	ProviderChargePeriods   string
+	ProviderChargePeriodMin time.Duration
+	ProviderChargePeriodMax time.Duration
+
+	ProviderBillingAnomalyTolerance     time.Duration
+	ProviderBillingAnomalyMaxHourlyRate *big.Int
+	ProviderBillingAnomalyPause         bool
+	ProviderBillingAnomalyPauseDuration time.Duration
+
+	ProviderHermesFeePolicy        string
+	ProviderHermesFeeGracePeriod   time.Duration
+	ProviderHermesFeeCheckInterval time.Duration
+
+	ProviderAutoPricing           bool
+	ProviderAutoPricingInterval   time.Duration
+	ProviderAutoPricingMinPerHour *big.Int
+	ProviderAutoPricingMaxPerHour *big.Int
+	ProviderAutoPricingMinPerGiB  *big.Int
+	ProviderAutoPricingMaxPerGiB  *big.Int
 
 	MaxUnpaidInvoiceValue   *big.Int
 	LimitUnpaidInvoiceValue *big.Int
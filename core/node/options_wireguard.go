@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "time"
+
+// OptionsWireguard describes keepalive options of WireGuard tunnels
+type OptionsWireguard struct {
+	// KeepAlive is the interval of keepalive packets sent by consumer, zero disables them.
+	KeepAlive time.Duration
+	// ProviderKeepAlive is the interval of keepalive packets sent by provider, zero disables them.
+	ProviderKeepAlive time.Duration
+	// RekeyTimeout is the age of the last handshake after which the tunnel is considered stalled, zero disables the check.
+	RekeyTimeout time.Duration
+}
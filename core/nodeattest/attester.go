@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nodeattest
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	keyBucket = "node-attestation"
+	keyName   = "attestation-key"
+
+	// refreshInterval is how long a signed attestation is reused before the metadata is signed again.
+	refreshInterval = time.Hour
+)
+
+// Storage keeps the attestation key between node restarts.
+type Storage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+// Attester signs the node metadata attached to the provider proposals.
+type Attester struct {
+	storage       Storage
+	signerFactory identity.SignerFactory
+	metadata      market.NodeMetadata
+	now           func() time.Time
+
+	mu           sync.Mutex
+	key          ed25519.PrivateKey
+	attestations map[identity.Identity]market.NodeAttestation
+}
+
+// NewAttester returns a new attester of the given node metadata.
+func NewAttester(storage Storage, signerFactory identity.SignerFactory, metadata market.NodeMetadata) *Attester {
+	return &Attester{
+		storage:       storage,
+		signerFactory: signerFactory,
+		metadata:      metadata,
+		now:           time.Now,
+		attestations:  make(map[identity.Identity]market.NodeAttestation),
+	}
+}
+
+// Attest returns the node metadata signed for the given provider.
+func (a *Attester) Attest(providerID identity.Identity) (*market.NodeAttestation, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	if attestation, ok := a.attestations[providerID]; ok && now.Sub(time.Unix(attestation.Metadata.IssuedAt, 0)) < refreshInterval {
+		return &attestation, nil
+	}
+
+	key, err := a.loadKey()
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	keySignature, err := a.signerFactory(providerID).Sign(market.AttestationKeyMessage(providerID.Address, publicKey))
+	if err != nil {
+		return nil, fmt.Errorf("could not endorse attestation key: %w", err)
+	}
+
+	metadata := a.metadata
+	metadata.IssuedAt = now.Unix()
+	attestation := market.NodeAttestation{
+		Metadata:     metadata,
+		PublicKey:    publicKey,
+		KeySignature: hex.EncodeToString(keySignature.Bytes()),
+		Signature:    hex.EncodeToString(ed25519.Sign(key, market.AttestationMessage(providerID.Address, metadata))),
+	}
+	a.attestations[providerID] = attestation
+
+	return &attestation, nil
+}
+
+// PublicKey returns the hex encoded public attestation key.
+func (a *Attester) PublicKey() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, err := a.loadKey()
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key.Public().(ed25519.PublicKey)), nil
+}
+
+// RotateKey replaces the attestation key, proposals get attested with the new key on the next announcement.
+func (a *Attester) RotateKey() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key, err := a.generateKey()
+	if err != nil {
+		return "", err
+	}
+
+	a.key = key
+	a.attestations = make(map[identity.Identity]market.NodeAttestation)
+
+	return hex.EncodeToString(key.Public().(ed25519.PublicKey)), nil
+}
+
+func (a *Attester) loadKey() (ed25519.PrivateKey, error) {
+	if a.key != nil {
+		return a.key, nil
+	}
+
+	var seed []byte
+	if err := a.storage.GetValue(keyBucket, keyName, &seed); err == nil && len(seed) == ed25519.SeedSize {
+		a.key = ed25519.NewKeyFromSeed(seed)
+		return a.key, nil
+	}
+
+	key, err := a.generateKey()
+	if err != nil {
+		return nil, err
+	}
+	a.key = key
+
+	return a.key, nil
+}
+
+func (a *Attester) generateKey() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("could not generate attestation key: %w", err)
+	}
+
+	if err := a.storage.SetValue(keyBucket, keyName, key.Seed()); err != nil {
+		return nil, fmt.Errorf("could not store attestation key: %w", err)
+	}
+
+	return key, nil
+}
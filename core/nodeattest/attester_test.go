@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nodeattest
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var providerID = identity.FromAddress("0x53a835143c0ef3bbcbfa796d7eb738ca7dd28f68")
+
+func newTestAttester(t *testing.T, storage Storage) *Attester {
+	ks := identity.NewMockKeystoreWith(identity.MockKeys)
+	require.NoError(t, ks.Unlock(accounts.Account{Address: common.HexToAddress(providerID.Address)}, ""))
+
+	return NewAttester(storage, func(id identity.Identity) identity.Signer {
+		return identity.NewSigner(ks, id)
+	}, market.NodeMetadata{Version: "1.2.3", Launcher: "docker", OS: "linux"})
+}
+
+func attestedProposal(t *testing.T, attester *Attester) market.ServiceProposal {
+	attestation, err := attester.Attest(providerID)
+	require.NoError(t, err)
+
+	return market.ServiceProposal{ProviderID: providerID.Address, ServiceType: "wireguard", Attestation: attestation}
+}
+
+func TestAttester_AttestIsVerifiable(t *testing.T) {
+	proposal := attestedProposal(t, newTestAttester(t, newMockStorage()))
+
+	assert.NoError(t, Verify(proposal))
+	assert.Equal(t, "1.2.3", proposal.Attestation.Metadata.Version)
+	assert.NotZero(t, proposal.Attestation.Metadata.IssuedAt)
+}
+
+func TestVerify_RejectsTamperedProposals(t *testing.T) {
+	attester := newTestAttester(t, newMockStorage())
+
+	assert.ErrorIs(t, Verify(market.ServiceProposal{ProviderID: providerID.Address}), ErrNotAttested)
+
+	proposal := attestedProposal(t, attester)
+	tampered := *proposal.Attestation
+	tampered.Metadata.Version = "9.9.9"
+	proposal.Attestation = &tampered
+	assert.ErrorIs(t, Verify(proposal), ErrInvalidAttestation)
+
+	proposal = attestedProposal(t, attester)
+	proposal.ProviderID = "0x0000000000000000000000000000000000000001"
+	assert.ErrorIs(t, Verify(proposal), ErrInvalidAttestation)
+
+	proposal = attestedProposal(t, attester)
+	spoofed := *proposal.Attestation
+	spoofed.PublicKey = "00"
+	proposal.Attestation = &spoofed
+	assert.ErrorIs(t, Verify(proposal), ErrInvalidAttestation)
+}
+
+func TestAttester_RotateKey(t *testing.T) {
+	storage := newMockStorage()
+	attester := newTestAttester(t, storage)
+
+	before, err := attester.PublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, before, attestedProposal(t, attester).Attestation.PublicKey)
+
+	after, err := attester.RotateKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+
+	proposal := attestedProposal(t, attester)
+	assert.Equal(t, after, proposal.Attestation.PublicKey)
+	assert.NoError(t, Verify(proposal))
+
+	restored, err := newTestAttester(t, storage).PublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, after, restored)
+}
+
+func TestAttester_RefreshesAttestation(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	attester := newTestAttester(t, newMockStorage())
+	attester.now = func() time.Time { return now }
+
+	first := attestedProposal(t, attester).Attestation
+	now = now.Add(time.Minute)
+	assert.Equal(t, first.Metadata.IssuedAt, attestedProposal(t, attester).Attestation.Metadata.IssuedAt)
+
+	now = now.Add(refreshInterval)
+	assert.Equal(t, now.Unix(), attestedProposal(t, attester).Attestation.Metadata.IssuedAt)
+}
+
+type mockStorage struct {
+	values map[interface{}][]byte
+}
+
+func newMockStorage() *mockStorage {
+	return &mockStorage{values: make(map[interface{}][]byte)}
+}
+
+func (s *mockStorage) GetValue(bucket string, key interface{}, to interface{}) error {
+	value, ok := s.values[key]
+	if !ok {
+		return errors.New("not found")
+	}
+	return json.Unmarshal(value, to)
+}
+
+func (s *mockStorage) SetValue(bucket string, key interface{}, to interface{}) error {
+	value, err := json.Marshal(to)
+	s.values[key] = value
+	return err
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nodeattest
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var (
+	// ErrNotAttested is returned when the proposal carries no node attestation.
+	ErrNotAttested = errors.New("proposal is not attested")
+	// ErrInvalidAttestation is returned when the node attestation signatures do not match.
+	ErrInvalidAttestation = errors.New("invalid node attestation")
+)
+
+// Verify checks that the node metadata of the proposal is signed by an attestation key endorsed by the provider identity.
+// It proves the metadata is the one the provider reported, not that the reported software is genuine.
+func Verify(proposal market.ServiceProposal) error {
+	attestation := proposal.Attestation
+	if attestation == nil {
+		return ErrNotAttested
+	}
+
+	publicKey, err := hex.DecodeString(attestation.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: malformed attestation key", ErrInvalidAttestation)
+	}
+
+	verifier := identity.NewVerifierIdentity(identity.FromAddress(proposal.ProviderID))
+	if ok, _ := verifier.Verify(market.AttestationKeyMessage(proposal.ProviderID, attestation.PublicKey), identity.SignatureHex(attestation.KeySignature)); !ok {
+		return fmt.Errorf("%w: attestation key is not endorsed by the provider", ErrInvalidAttestation)
+	}
+
+	signature, err := hex.DecodeString(attestation.Signature)
+	if err != nil || !ed25519.Verify(publicKey, market.AttestationMessage(proposal.ProviderID, attestation.Metadata), signature) {
+		return fmt.Errorf("%w: node metadata signature mismatch", ErrInvalidAttestation)
+	}
+
+	return nil
+}
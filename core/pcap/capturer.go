@@ -0,0 +1,377 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pcap
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+const (
+	// DefaultDuration is the capture duration used when none is requested.
+	DefaultDuration = 30 * time.Second
+	// MaxDuration is the longest capture allowed.
+	MaxDuration = 10 * time.Minute
+	// DefaultMaxBytes is the capture size limit used when none is requested.
+	DefaultMaxBytes = 10 << 20
+	// MaxBytes is the largest capture allowed.
+	MaxBytes = 100 << 20
+	// MaxStoredCaptures is the number of capture files kept in the capture directory, the oldest ones are removed.
+	MaxStoredCaptures = 10
+
+	sizeCheckInterval = 500 * time.Millisecond
+)
+
+var (
+	// ErrSessionNotFound is returned when the session has no tunnel to capture.
+	ErrSessionNotFound = errors.New("session tunnel not found")
+	// ErrCaptureRunning is returned when the session tunnel is already being captured.
+	ErrCaptureRunning = errors.New("capture of the session is already running")
+	// ErrCaptureNotFound is returned for unknown capture.
+	ErrCaptureNotFound = errors.New("capture not found")
+)
+
+// Capture describes a packet capture of a session tunnel.
+type Capture struct {
+	ID         string
+	SessionID  string
+	Interface  string
+	Path       string
+	Duration   time.Duration
+	MaxBytes   int64
+	Size       int64
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Error      string
+}
+
+// Running returns true if capture is still writing packets.
+func (c Capture) Running() bool {
+	return c.FinishedAt.IsZero()
+}
+
+// process is the running packet capture.
+type process interface {
+	Stop() error
+	Done() <-chan error
+}
+
+// startFunc starts capturing packets of the interface into the file, which must not grow far beyond maxBytes.
+type startFunc func(iface, path string, maxBytes int64) (process, error)
+
+// Capturer captures packets of session tunnels into pcap files for debugging.
+// Captures are bounded by duration and file size and only the most recent ones are kept, so they can't exhaust the disk.
+type Capturer struct {
+	dir   string
+	start startFunc
+	now   func() time.Time
+
+	lock       sync.Mutex
+	interfaces map[string]string
+	captures   map[string]*Capture
+	stops      map[string]func()
+}
+
+// NewCapturer returns a new capturer writing pcap files into the given directory.
+func NewCapturer(dir string) *Capturer {
+	return newCapturer(dir, startTcpdump)
+}
+
+func newCapturer(dir string, start startFunc) *Capturer {
+	return &Capturer{
+		dir:        dir,
+		start:      start,
+		now:        time.Now,
+		interfaces: make(map[string]string),
+		captures:   make(map[string]*Capture),
+		stops:      make(map[string]func()),
+	}
+}
+
+// Subscribe subscribes capturer to session tunnel events.
+func (c *Capturer) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(event.AppTopicSessionTunnel, c.handleTunnel)
+}
+
+func (c *Capturer) handleTunnel(e event.AppEventSessionTunnel) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if e.Up {
+		c.interfaces[e.ID] = e.Interface
+		return
+	}
+
+	delete(c.interfaces, e.ID)
+	if stop, ok := c.stops[e.ID]; ok {
+		stop()
+	}
+}
+
+// Start starts capturing session tunnel packets, zero limits are replaced by the defaults.
+func (c *Capturer) Start(sessionID string, duration time.Duration, maxBytes int64) (Capture, error) {
+	if duration <= 0 {
+		duration = DefaultDuration
+	}
+	if duration > MaxDuration {
+		return Capture{}, fmt.Errorf("capture duration can't exceed %s", MaxDuration)
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBytes
+	}
+	if maxBytes > MaxBytes {
+		return Capture{}, fmt.Errorf("capture size can't exceed %d MB", MaxBytes>>20)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	iface, ok := c.interfaces[sessionID]
+	if !ok {
+		return Capture{}, ErrSessionNotFound
+	}
+	if _, ok := c.stops[sessionID]; ok {
+		return Capture{}, ErrCaptureRunning
+	}
+
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return Capture{}, fmt.Errorf("could not create capture directory: %w", err)
+	}
+	c.prune()
+
+	now := c.now().UTC()
+	id := fmt.Sprintf("%s-%d", sessionID, now.Unix())
+	capture := &Capture{
+		ID:        id,
+		SessionID: sessionID,
+		Interface: iface,
+		Path:      filepath.Join(c.dir, id+".pcap"),
+		Duration:  duration,
+		MaxBytes:  maxBytes,
+		StartedAt: now,
+	}
+
+	proc, err := c.start(iface, capture.Path, maxBytes)
+	if err != nil {
+		return Capture{}, fmt.Errorf("could not start capture: %w", err)
+	}
+
+	stop := make(chan struct{})
+	var once sync.Once
+	c.stops[sessionID] = func() { once.Do(func() { close(stop) }) }
+	c.captures[id] = capture
+
+	log.Info().Msgf("Capturing packets of session %s on %s into %s", sessionID, iface, capture.Path)
+	go c.watch(*capture, proc, stop)
+
+	return *capture, nil
+}
+
+func (c *Capturer) watch(capture Capture, proc process, stop <-chan struct{}) {
+	timeout := time.NewTimer(capture.Duration)
+	defer timeout.Stop()
+	sizeCheck := time.NewTicker(sizeCheckInterval)
+	defer sizeCheck.Stop()
+
+	var err error
+	exited := false
+loop:
+	for {
+		select {
+		case err = <-proc.Done():
+			exited = true
+			break loop
+		case <-stop:
+			break loop
+		case <-timeout.C:
+			break loop
+		case <-sizeCheck.C:
+			if fileSize(capture.Path) >= capture.MaxBytes {
+				break loop
+			}
+		}
+	}
+	if !exited {
+		err = proc.Stop()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.stops, capture.SessionID)
+	finished := c.captures[capture.ID]
+	finished.FinishedAt = c.now().UTC()
+	finished.Size = fileSize(finished.Path)
+	if err != nil {
+		finished.Error = err.Error()
+		log.Warn().Err(err).Msgf("Packet capture %s finished with error", capture.ID)
+		return
+	}
+	log.Info().Msgf("Packet capture %s finished, %d bytes captured", capture.ID, finished.Size)
+}
+
+// prune removes the oldest finished capture files, leaving room for a new capture.
+func (c *Capturer) prune() {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not list packet captures")
+		return
+	}
+
+	running := make(map[string]bool)
+	for _, capture := range c.captures {
+		if capture.Running() {
+			running[capture.Path] = true
+		}
+	}
+
+	type storedCapture struct {
+		path    string
+		modTime time.Time
+	}
+	var stored []storedCapture
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pcap" {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		info, err := entry.Info()
+		if err != nil || running[path] {
+			continue
+		}
+		stored = append(stored, storedCapture{path: path, modTime: info.ModTime()})
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].modTime.Before(stored[j].modTime)
+	})
+
+	removed := make(map[string]bool)
+	for i := 0; len(stored)-i+len(running) >= MaxStoredCaptures && i < len(stored); i++ {
+		if err := os.Remove(stored[i].path); err != nil {
+			log.Warn().Err(err).Msgf("Could not remove packet capture %s", stored[i].path)
+			continue
+		}
+		removed[stored[i].path] = true
+	}
+	for id, capture := range c.captures {
+		if removed[capture.Path] {
+			delete(c.captures, id)
+		}
+	}
+}
+
+// List returns all the captures, the most recent first.
+func (c *Capturer) List() []Capture {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	list := make([]Capture, 0, len(c.captures))
+	for _, capture := range c.captures {
+		cp := *capture
+		if cp.Running() {
+			cp.Size = fileSize(cp.Path)
+		}
+		list = append(list, cp)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].StartedAt.After(list[j].StartedAt)
+	})
+	return list
+}
+
+// Get returns the capture by its ID.
+func (c *Capturer) Get(id string) (Capture, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	capture, ok := c.captures[id]
+	if !ok {
+		return Capture{}, ErrCaptureNotFound
+	}
+	return *capture, nil
+}
+
+// Stop stops all the running captures.
+func (c *Capturer) Stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, stop := range c.stops {
+		stop()
+	}
+}
+
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+type tcpdump struct {
+	cmd  *exec.Cmd
+	done chan error
+}
+
+func startTcpdump(iface, path string, maxBytes int64) (process, error) {
+	// -U flushes every packet, so the size limit is checked against the data actually written.
+	// -C with -W 1 makes tcpdump itself start the file over once it exceeds the limit (in millions of bytes),
+	// so the file stays bounded even if the capture is not stopped in time.
+	fileSize := (maxBytes + 999_999) / 1_000_000
+	cmd := exec.Command("tcpdump", "-i", iface, "-n", "-U", "-C", strconv.FormatInt(fileSize, 10), "-W", "1", "-w", path)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &tcpdump{cmd: cmd, done: make(chan error, 1)}
+	go func() {
+		p.done <- cmd.Wait()
+	}()
+	return p, nil
+}
+
+func (p *tcpdump) Done() <-chan error {
+	return p.done
+}
+
+func (p *tcpdump) Stop() error {
+	// Interrupt lets tcpdump flush the file, it is not supported on every platform though.
+	if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
+		return p.cmd.Process.Kill()
+	}
+
+	select {
+	case <-p.done:
+		return nil
+	case <-time.After(5 * time.Second):
+		return p.cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pcap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+type mockProcess struct {
+	done    chan error
+	stopped chan struct{}
+}
+
+func (p *mockProcess) Done() <-chan error {
+	return p.done
+}
+
+func (p *mockProcess) Stop() error {
+	close(p.stopped)
+	return nil
+}
+
+func newTestCapturer(t *testing.T) (*Capturer, *mockProcess) {
+	proc := &mockProcess{done: make(chan error, 1), stopped: make(chan struct{})}
+	c := newCapturer(t.TempDir(), func(iface, path string, maxBytes int64) (process, error) {
+		return proc, os.WriteFile(path, []byte("pcap"), 0600)
+	})
+	return c, proc
+}
+
+func TestCapturer_StartRequiresSessionTunnel(t *testing.T) {
+	c, _ := newTestCapturer(t)
+
+	_, err := c.Start("session1", 0, 0)
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	c.handleTunnel(event.AppEventSessionTunnel{ID: "session1", Interface: "myst0", Up: true})
+	c.handleTunnel(event.AppEventSessionTunnel{ID: "session1", Interface: "myst0"})
+
+	_, err = c.Start("session1", 0, 0)
+	assert.Equal(t, ErrSessionNotFound, err)
+}
+
+func TestCapturer_ValidatesLimits(t *testing.T) {
+	c, _ := newTestCapturer(t)
+	c.handleTunnel(event.AppEventSessionTunnel{ID: "session1", Interface: "myst0", Up: true})
+
+	_, err := c.Start("session1", MaxDuration+time.Second, 0)
+	assert.Error(t, err)
+
+	_, err = c.Start("session1", 0, MaxBytes+1)
+	assert.Error(t, err)
+}
+
+func TestCapturer_StopsWhenSizeLimitReached(t *testing.T) {
+	c, proc := newTestCapturer(t)
+	c.handleTunnel(event.AppEventSessionTunnel{ID: "session1", Interface: "myst0", Up: true})
+
+	capture, err := c.Start("session1", time.Minute, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "myst0", capture.Interface)
+	assert.True(t, capture.Running())
+
+	_, err = c.Start("session1", time.Minute, 4)
+	assert.Equal(t, ErrCaptureRunning, err)
+
+	select {
+	case <-proc.stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("capture was not stopped")
+	}
+
+	assert.Eventually(t, func() bool {
+		capture, err := c.Get(capture.ID)
+		return err == nil && !capture.Running()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	list := c.List()
+	assert.Len(t, list, 1)
+	assert.Equal(t, int64(4), list[0].Size)
+	assert.Empty(t, list[0].Error)
+}
+
+func TestCapturer_StopsWhenSessionTunnelGoesDown(t *testing.T) {
+	c, proc := newTestCapturer(t)
+	c.handleTunnel(event.AppEventSessionTunnel{ID: "session1", Interface: "myst0", Up: true})
+
+	_, err := c.Start("session1", time.Minute, 0)
+	assert.NoError(t, err)
+
+	c.handleTunnel(event.AppEventSessionTunnel{ID: "session1", Interface: "myst0"})
+
+	select {
+	case <-proc.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("capture was not stopped")
+	}
+}
+
+func TestCapturer_PrunesOldCaptures(t *testing.T) {
+	c, _ := newTestCapturer(t)
+	c.handleTunnel(event.AppEventSessionTunnel{ID: "session1", Interface: "myst0", Up: true})
+
+	old := time.Now().Add(-time.Hour)
+	for i := 0; i < MaxStoredCaptures; i++ {
+		path := filepath.Join(c.dir, fmt.Sprintf("old-%d.pcap", i))
+		assert.NoError(t, os.WriteFile(path, []byte("pcap"), 0600))
+		assert.NoError(t, os.Chtimes(path, old, old.Add(time.Duration(i)*time.Minute)))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(c.dir, "notes.txt"), []byte("keep"), 0600))
+
+	capture, err := c.Start("session1", time.Minute, 0)
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.pcap"))
+	assert.NoError(t, err)
+	assert.Len(t, files, MaxStoredCaptures)
+	assert.Contains(t, files, capture.Path)
+	assert.NotContains(t, files, filepath.Join(c.dir, "old-0.pcap"))
+	assert.FileExists(t, filepath.Join(c.dir, "notes.txt"))
+}
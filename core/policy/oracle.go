@@ -19,16 +19,24 @@ package policy
 
 import (
 	"fmt"
-	"net/http"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/mysteriumnetwork/node/logconfig/httptrace"
-	"github.com/mysteriumnetwork/node/market"
-	"github.com/mysteriumnetwork/node/requests"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/node/requests/signed"
+)
+
+const (
+	// SignatureHeader is the response header carrying the trust oracle signature of the policy rules.
+	SignatureHeader = signed.SignatureHeader
+
+	cacheBucket = "access-policies"
 )
 
 type policySubscription struct {
@@ -39,7 +47,7 @@ type policySubscription struct {
 
 // Oracle represents async policy fetcher from TrustOracle
 type Oracle struct {
-	client             *requests.HTTPClient
+	fetcher            *signed.Fetcher
 	fetchURL           string
 	fetchInterval      time.Duration
 	fetchLock          sync.RWMutex
@@ -49,10 +57,11 @@ type Oracle struct {
 	fetchShutdownOnce sync.Once
 }
 
-// NewOracle create instance of policy fetcher
-func NewOracle(client *requests.HTTPClient, policyURL string, interval time.Duration) *Oracle {
+// NewOracle create instance of policy fetcher.
+// Fetched rules are verified with given verifier and cached in given storage, both are optional.
+func NewOracle(client *requests.HTTPClient, policyURL string, interval time.Duration, verifier identity.Verifier, storage signed.Storage) *Oracle {
 	return &Oracle{
-		client:             client,
+		fetcher:            signed.NewFetcher(client, verifier, storage, cacheBucket),
 		fetchURL:           policyURL,
 		fetchInterval:      interval,
 		fetchSubscriptions: make([]policySubscription, 0),
@@ -128,7 +137,10 @@ func (pr *Oracle) SubscribePolicies(policies []market.AccessPolicy, repository *
 		})
 
 		if err := pr.fetchPolicyRules(&subscriptionsNew[index]); err != nil {
-			return errors.Wrap(err, "initial fetch failed")
+			if !pr.restorePolicyRules(&subscriptionsNew[index]) {
+				return errors.Wrap(err, "initial fetch failed")
+			}
+			log.Warn().Err(err).Msgf("Initial fetch failed, using cached policy rules %s", policy)
 		}
 	}
 
@@ -137,37 +149,33 @@ func (pr *Oracle) SubscribePolicies(policies []market.AccessPolicy, repository *
 }
 
 func (pr *Oracle) fetchPolicyRules(subscription *policySubscription) error {
-	req, err := requests.NewGetRequest(subscription.policy.Source, "", nil)
-	if err != nil {
-		return errors.Wrap(err, "failed to create policy request")
-	}
-	req.Header.Add("If-None-Match", subscription.eTag)
-
-	res, err := pr.client.Do(req)
+	var rules = market.AccessPolicyRuleSet{}
+	eTag, modified, err := pr.fetcher.Fetch(subscription.policy.Source, subscription.eTag, &rules)
 	if err != nil {
-		return errors.Wrapf(err, "failed fetch policy rule %s", subscription.policy)
+		return errors.Wrapf(err, "failed to fetch policy rule %s", subscription.policy)
 	}
-	defer res.Body.Close()
-
-	httptrace.TraceRequestResponse(req, res)
-
-	if res.StatusCode == http.StatusNotModified {
+	if !modified {
 		return nil
 	}
-	if err := requests.ParseResponseError(res); err != nil {
-		return errors.Wrapf(err, "failed to fetch policy rule %s", subscription.policy)
+	subscription.eTag = eTag
+
+	for _, subscriber := range subscription.subscribers {
+		subscriber.SetPolicyRules(subscription.policy, rules)
 	}
+	return nil
+}
 
+// restorePolicyRules passes cached policy rules to the subscribers, reports whether any were cached.
+func (pr *Oracle) restorePolicyRules(subscription *policySubscription) bool {
 	var rules = market.AccessPolicyRuleSet{}
-	err = requests.ParseResponseJSON(res, &rules)
-	if err != nil {
-		return errors.Wrapf(err, "failed to parse policy rule %s", subscription.policy)
+	eTag, ok := pr.fetcher.Restore(subscription.policy.Source, &rules)
+	if !ok {
+		return false
 	}
-	subscription.eTag = res.Header.Get("ETag")
+	subscription.eTag = eTag
 
 	for _, subscriber := range subscription.subscribers {
 		subscriber.SetPolicyRules(subscription.policy, rules)
 	}
-
-	return nil
+	return true
 }
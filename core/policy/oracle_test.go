@@ -18,6 +18,9 @@
 package policy
 
 import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -25,6 +28,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/stretchr/testify/assert"
@@ -179,8 +183,67 @@ func Test_Oracle_StartSyncsPolicies(t *testing.T) {
 	assert.Equal(t, []market.AccessPolicyRuleSet{policyOneRulesUpdated, policyTwoRulesUpdated}, policiesRules)
 }
 
+func Test_Oracle_SubscribePolicies_VerifiesSignature(t *testing.T) {
+	body := []byte(`{"id": "1", "title": "One", "allow": [{"type": "identity", "value": "0x1"}]}`)
+	signer := &identity.SignerFake{}
+	signature, _ := signer.Sign(body)
+
+	tests := []struct {
+		name          string
+		signature     string
+		expectedError bool
+	}{
+		{name: "accepts signed rules", signature: hex.EncodeToString(signature.Bytes())},
+		{name: "rejects unsigned rules", signature: "", expectedError: true},
+		{name: "rejects invalid signature", signature: hex.EncodeToString([]byte("forged")), expectedError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.signature != "" {
+					w.Header().Set(SignatureHeader, tt.signature)
+				}
+				w.Write(body)
+			}))
+			defer server.Close()
+
+			repo := NewRepository()
+			oracle := NewOracle(requests.NewHTTPClient("0.0.0.0", time.Second), server.URL+"/", time.Minute, &identity.VerifierFake{}, nil)
+			err := oracle.SubscribePolicies(oracle.Policies([]string{"1"}), repo)
+
+			if tt.expectedError {
+				assert.Error(t, err)
+				assert.Empty(t, repo.Rules())
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, repo.IsIdentityAllowed(identity.FromAddress("0x2")))
+			}
+		})
+	}
+}
+
+func Test_Oracle_SubscribePolicies_FallsBackToCache(t *testing.T) {
+	storage := newMockPolicyStorage()
+
+	server := mockPolicyServer()
+	oracle := NewOracle(requests.NewHTTPClient("0.0.0.0", time.Second), server.URL+"/", time.Minute, nil, storage)
+	err := oracle.SubscribePolicies(oracle.Policies([]string{"1"}), NewRepository())
+	assert.NoError(t, err)
+	server.Close()
+
+	repo := NewRepository()
+	oracle = NewOracle(requests.NewHTTPClient("0.0.0.0", 100*time.Millisecond), server.URL+"/", time.Minute, nil, storage)
+	err = oracle.SubscribePolicies(oracle.Policies([]string{"1"}), repo)
+	assert.NoError(t, err)
+	assert.Equal(t, []market.AccessPolicyRuleSet{policyOneRulesUpdated}, repo.Rules())
+
+	err = oracle.SubscribePolicies(oracle.Policies([]string{"2"}), NewRepository())
+	assert.Error(t, err)
+}
+
 func Test_PolicyRepository_StartMultipleTimes(t *testing.T) {
-	oracle := NewOracle(requests.NewHTTPClient("0.0.0.0", time.Second), "http://policy.localhost", time.Minute)
+	oracle := NewOracle(requests.NewHTTPClient("0.0.0.0", time.Second), "http://policy.localhost", time.Minute, nil, nil)
 	go oracle.Start()
 	oracle.Stop()
 
@@ -193,6 +256,8 @@ func createEmptyOracle(mockServerURL string) *Oracle {
 		requests.NewHTTPClient("0.0.0.0", 100*time.Millisecond),
 		mockServerURL+"/",
 		time.Minute,
+		nil,
+		nil,
 	)
 }
 
@@ -201,6 +266,8 @@ func createFilledOracle(mockServerURL string, interval time.Duration, repo *Repo
 		requests.NewHTTPClient("0.0.0.0", time.Second),
 		mockServerURL+"/",
 		interval,
+		nil,
+		nil,
 	)
 	oracle.SubscribePolicies(
 		[]market.AccessPolicy{oracle.Policy("1"), oracle.Policy("2")},
@@ -247,3 +314,25 @@ func mockPolicyServer() *httptest.Server {
 		}
 	}))
 }
+
+type mockPolicyStorage struct {
+	values map[interface{}][]byte
+}
+
+func newMockPolicyStorage() *mockPolicyStorage {
+	return &mockPolicyStorage{values: make(map[interface{}][]byte)}
+}
+
+func (s *mockPolicyStorage) GetValue(bucket string, key interface{}, to interface{}) error {
+	value, ok := s.values[key]
+	if !ok {
+		return errors.New("not found")
+	}
+	return json.Unmarshal(value, to)
+}
+
+func (s *mockPolicyStorage) SetValue(bucket string, key interface{}, to interface{}) error {
+	value, err := json.Marshal(to)
+	s.values[key] = value
+	return err
+}
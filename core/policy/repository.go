@@ -183,7 +183,7 @@ func (r *Repository) IsHostAllowed(host string) bool {
 
 func (r *Repository) findItemFor(policy market.AccessPolicy) (*listItem, error) {
 	for i, item := range r.items {
-		if item.policy == policy {
+		if item.policy.ID == policy.ID && item.policy.Source == policy.Source {
 			return &r.items[i], nil
 		}
 	}
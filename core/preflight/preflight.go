@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/node/core/connection/diagnostics"
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+const (
+	// CheckConfig validates the configuration of the services to start.
+	CheckConfig = "config"
+	// CheckPortsFree checks that the configured UDP port range has free ports.
+	CheckPortsFree = "ports_free"
+	// CheckIdentityRegistered checks that the provider identity is registered.
+	CheckIdentityRegistered = "identity_registered"
+	// CheckStake checks that the provider channel stake is sufficient.
+	CheckStake = "stake"
+	// CheckHermesReachable checks that the active hermes responds.
+	CheckHermesReachable = "hermes_reachable"
+	// CheckNATTraversal checks that consumers are able to traverse the provider NAT.
+	CheckNATTraversal = "nat_traversal"
+)
+
+const defaultCheckTimeout = 30 * time.Second
+
+type registrationStatusProvider interface {
+	GetRegistrationStatus(chainID int64, id identity.Identity) (registry.RegistrationStatus, error)
+}
+
+type activeHermesProvider interface {
+	GetActiveHermes(chainID int64) (common.Address, error)
+}
+
+type channelProvider interface {
+	Fetch(chainID int64, id identity.Identity, hermesID common.Address) (pingpong.HermesChannel, error)
+}
+
+type hermesChecker interface {
+	Check(chainID int64, hermesID common.Address) event.AppEventHermesAvailability
+}
+
+type natProber interface {
+	Probe(ctx context.Context) (nat.NATType, error)
+}
+
+// Config holds the provider settings checks are run against.
+type Config struct {
+	ChainID int64
+	// MinStake is the minimum provider channel stake, stake is not required if nil or zero.
+	MinStake *big.Int
+	// UDPPorts is the UDP port range services listen on.
+	UDPPorts        string
+	ChargePeriodMin time.Duration
+	ChargePeriodMax time.Duration
+}
+
+// Deps holds the dependencies of the checks.
+type Deps struct {
+	Registry        registrationStatusProvider
+	AddressProvider activeHermesProvider
+	Channels        channelProvider
+	Hermes          hermesChecker
+	NATProber       natProber
+	// ValidateService validates the configured options of the given service type.
+	ValidateService func(serviceType string) error
+}
+
+// CheckResult is the outcome of a single startup check.
+type CheckResult = diagnostics.CheckResult
+
+// Report is a structured pass/fail report of the provider startup checks.
+type Report struct {
+	Passed bool
+	Checks []CheckResult
+}
+
+// Runner validates whether the provider is ready to start services.
+type Runner struct {
+	deps    Deps
+	config  Config
+	timeout time.Duration
+}
+
+// NewRunner returns a new startup checks runner.
+func NewRunner(deps Deps, config Config) *Runner {
+	return &Runner{
+		deps:    deps,
+		config:  config,
+		timeout: defaultCheckTimeout,
+	}
+}
+
+// Run runs all the checks for the given provider and services.
+func (r *Runner) Run(ctx context.Context, providerID identity.Identity, serviceTypes []string) Report {
+	registered := r.checkIdentityRegistered(providerID)
+	report := Report{
+		Passed: true,
+		Checks: []CheckResult{
+			r.checkConfig(serviceTypes),
+			r.checkPortsFree(serviceTypes),
+			registered,
+			r.checkStake(providerID, registered.OK),
+			r.checkHermesReachable(),
+			r.checkNATTraversal(ctx),
+		},
+	}
+	for _, check := range report.Checks {
+		if !check.OK && !check.Skipped {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+func (r *Runner) checkConfig(serviceTypes []string) CheckResult {
+	return diagnostics.Measure(CheckConfig, func() (string, error) {
+		var problems []string
+		if len(serviceTypes) == 0 {
+			problems = append(problems, "no services configured")
+		}
+		for _, serviceType := range serviceTypes {
+			if r.deps.ValidateService == nil {
+				break
+			}
+			if err := r.deps.ValidateService(serviceType); err != nil {
+				problems = append(problems, fmt.Sprintf("service %s: %v", serviceType, err))
+			}
+		}
+		if r.config.ChargePeriodMin > 0 && r.config.ChargePeriodMax > 0 && r.config.ChargePeriodMin > r.config.ChargePeriodMax {
+			problems = append(problems, fmt.Sprintf("charge period minimum %s exceeds maximum %s", r.config.ChargePeriodMin, r.config.ChargePeriodMax))
+		}
+		if len(problems) > 0 {
+			return "", errors.New(strings.Join(problems, "; "))
+		}
+		return fmt.Sprintf("services: %s", strings.Join(serviceTypes, ",")), nil
+	})
+}
+
+func (r *Runner) checkPortsFree(serviceTypes []string) CheckResult {
+	portRange, err := port.ParseRange(r.config.UDPPorts)
+	if err != nil {
+		return CheckResult{Name: CheckPortsFree, Error: fmt.Sprintf("invalid UDP port range %q: %v", r.config.UDPPorts, err)}
+	}
+	if portRange.Start == 0 {
+		return CheckResult{Name: CheckPortsFree, Skipped: true, Details: "ports are assigned by the system"}
+	}
+
+	return diagnostics.Measure(CheckPortsFree, func() (string, error) {
+		needed := len(serviceTypes)
+		if needed < 1 {
+			needed = 1
+		}
+		if needed > portRange.Capacity() {
+			return portRange.String(), fmt.Errorf("range %s is too small for %d services", portRange.String(), needed)
+		}
+		if _, err := port.NewFixedRangePool(portRange).AcquireMultiple(needed); err != nil {
+			return portRange.String(), err
+		}
+		return fmt.Sprintf("%d free ports found in range %s", needed, portRange.String()), nil
+	})
+}
+
+func (r *Runner) checkIdentityRegistered(providerID identity.Identity) CheckResult {
+	return diagnostics.Measure(CheckIdentityRegistered, func() (string, error) {
+		status, err := r.deps.Registry.GetRegistrationStatus(r.config.ChainID, providerID)
+		if err != nil {
+			return providerID.Address, fmt.Errorf("could not get registration status: %w", err)
+		}
+		if status != registry.Registered {
+			return providerID.Address, fmt.Errorf("identity %s is %s", providerID.Address, status)
+		}
+		return providerID.Address, nil
+	})
+}
+
+func (r *Runner) checkStake(providerID identity.Identity, registered bool) CheckResult {
+	if r.config.MinStake == nil || r.config.MinStake.Sign() <= 0 {
+		return CheckResult{Name: CheckStake, Skipped: true, Details: "stake is not required"}
+	}
+	if !registered {
+		return CheckResult{Name: CheckStake, Skipped: true, Details: "identity is not registered"}
+	}
+
+	return diagnostics.Measure(CheckStake, func() (string, error) {
+		hermesID, err := r.deps.AddressProvider.GetActiveHermes(r.config.ChainID)
+		if err != nil {
+			return "", fmt.Errorf("could not get active hermes: %w", err)
+		}
+		channel, err := r.deps.Channels.Fetch(r.config.ChainID, providerID, hermesID)
+		if err != nil {
+			return "", fmt.Errorf("could not get provider channel: %w", err)
+		}
+
+		stake := new(big.Int)
+		if channel.Channel.Stake != nil {
+			stake = channel.Channel.Stake
+		}
+		details := fmt.Sprintf("stake %s, minimum %s", stake, r.config.MinStake)
+		if stake.Cmp(r.config.MinStake) < 0 {
+			return details, errors.New("stake is insufficient")
+		}
+		return details, nil
+	})
+}
+
+func (r *Runner) checkHermesReachable() CheckResult {
+	return diagnostics.Measure(CheckHermesReachable, func() (string, error) {
+		hermesID, err := r.deps.AddressProvider.GetActiveHermes(r.config.ChainID)
+		if err != nil {
+			return "", fmt.Errorf("could not get active hermes: %w", err)
+		}
+
+		result := r.deps.Hermes.Check(r.config.ChainID, hermesID)
+		if !result.Available {
+			return hermesID.Hex(), errors.New(result.Error)
+		}
+		return fmt.Sprintf("%s responded in %s", hermesID.Hex(), result.Latency), nil
+	})
+}
+
+func (r *Runner) checkNATTraversal(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return diagnostics.Measure(CheckNATTraversal, func() (string, error) {
+		natType, err := r.deps.NATProber.Probe(ctx)
+		if err != nil {
+			return "", fmt.Errorf("could not detect NAT type: %w", err)
+		}
+		if natType == nat.NATTypeSymmetric {
+			return string(natType), errors.New("consumers will not be able to traverse symmetric NAT")
+		}
+		return string(natType), nil
+	})
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+var (
+	providerID = identity.FromAddress("0x1")
+	hermesID   = common.HexToAddress("0x2")
+)
+
+func TestRunner_Run_Passes(t *testing.T) {
+	runner := NewRunner(healthyDeps(), Config{
+		ChainID:         1,
+		MinStake:        big.NewInt(10),
+		UDPPorts:        "0:0",
+		ChargePeriodMin: time.Second,
+		ChargePeriodMax: time.Minute,
+	})
+
+	report := runner.Run(context.Background(), providerID, []string{"wireguard"})
+
+	assert.True(t, report.Passed)
+	assert.Equal(t, []string{CheckConfig, CheckPortsFree, CheckIdentityRegistered, CheckStake, CheckHermesReachable, CheckNATTraversal}, checkNames(report))
+	assert.True(t, report.Checks[1].Skipped)
+	assert.Equal(t, "stake 20, minimum 10", report.Checks[3].Details)
+	assert.Equal(t, "prcone", report.Checks[5].Details)
+}
+
+func TestRunner_Run_Fails(t *testing.T) {
+	deps := healthyDeps()
+	deps.Registry = &mockRegistry{status: registry.Unregistered}
+	deps.Hermes = &mockHermes{result: event.AppEventHermesAvailability{Error: "could not reach hermes"}}
+	deps.NATProber = &mockNATProber{natType: nat.NATTypeSymmetric}
+	deps.ValidateService = func(serviceType string) error {
+		return errors.New("unknown service type")
+	}
+	runner := NewRunner(deps, Config{
+		MinStake:        big.NewInt(10),
+		UDPPorts:        "0:0",
+		ChargePeriodMin: time.Minute,
+		ChargePeriodMax: time.Second,
+	})
+
+	report := runner.Run(context.Background(), providerID, []string{"unknown"})
+
+	assert.False(t, report.Passed)
+	assert.Equal(t, "service unknown: unknown service type; charge period minimum 1m0s exceeds maximum 1s", report.Checks[0].Error)
+	assert.Equal(t, "identity 0x1 is Unregistered", report.Checks[2].Error)
+	assert.True(t, report.Checks[3].Skipped, "stake is not checked for unregistered identity")
+	assert.Equal(t, "could not reach hermes", report.Checks[4].Error)
+	assert.Equal(t, "consumers will not be able to traverse symmetric NAT", report.Checks[5].Error)
+}
+
+func TestRunner_checkStake(t *testing.T) {
+	deps := healthyDeps()
+	runner := NewRunner(deps, Config{MinStake: big.NewInt(30)})
+	result := runner.checkStake(providerID, true)
+	assert.False(t, result.OK)
+	assert.Equal(t, "stake is insufficient", result.Error)
+
+	runner = NewRunner(deps, Config{})
+	result = runner.checkStake(providerID, true)
+	assert.True(t, result.Skipped)
+}
+
+func TestRunner_checkPortsFree(t *testing.T) {
+	runner := NewRunner(healthyDeps(), Config{UDPPorts: "invalid"})
+	assert.False(t, runner.checkPortsFree([]string{"wireguard"}).OK)
+
+	runner = NewRunner(healthyDeps(), Config{UDPPorts: "50000:50001"})
+	result := runner.checkPortsFree([]string{"wireguard", "scraping"})
+	assert.False(t, result.OK)
+	assert.Equal(t, "range 50000:50001 is too small for 2 services", result.Error)
+}
+
+func checkNames(report Report) []string {
+	names := make([]string, len(report.Checks))
+	for i, check := range report.Checks {
+		names[i] = check.Name
+	}
+	return names
+}
+
+func healthyDeps() Deps {
+	return Deps{
+		Registry:        &mockRegistry{status: registry.Registered},
+		AddressProvider: &mockAddressProvider{},
+		Channels:        &mockChannels{stake: big.NewInt(20)},
+		Hermes:          &mockHermes{result: event.AppEventHermesAvailability{Available: true, Latency: time.Millisecond}},
+		NATProber:       &mockNATProber{natType: nat.NATTypePortRestrictedCone},
+		ValidateService: func(serviceType string) error { return nil },
+	}
+}
+
+type mockRegistry struct {
+	status registry.RegistrationStatus
+}
+
+func (m *mockRegistry) GetRegistrationStatus(int64, identity.Identity) (registry.RegistrationStatus, error) {
+	return m.status, nil
+}
+
+type mockAddressProvider struct{}
+
+func (m *mockAddressProvider) GetActiveHermes(int64) (common.Address, error) {
+	return hermesID, nil
+}
+
+type mockChannels struct {
+	stake *big.Int
+}
+
+func (m *mockChannels) Fetch(_ int64, id identity.Identity, hermesID common.Address) (pingpong.HermesChannel, error) {
+	return pingpong.HermesChannel{Identity: id, HermesID: hermesID, Channel: client.ProviderChannel{Stake: m.stake}}, nil
+}
+
+type mockHermes struct {
+	result event.AppEventHermesAvailability
+}
+
+func (m *mockHermes) Check(int64, common.Address) event.AppEventHermesAvailability {
+	return m.result
+}
+
+type mockNATProber struct {
+	natType nat.NATType
+}
+
+func (m *mockNATProber) Probe(context.Context) (nat.NATType, error) {
+	return m.natType, nil
+}
@@ -17,7 +17,11 @@
 
 package quality
 
-import "time"
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
 
 const (
 	// StagePraseRequest describes connection request parse event.
@@ -47,6 +51,8 @@ const (
 	StageRegistrationInProgress = "registration_in_progress"
 	// StageRegistrationRegistered describes registration registered status event.
 	StageRegistrationRegistered = "registration_registered"
+	// StageRegistrationAutoRegister describes automatic registration before connecting event.
+	StageRegistrationAutoRegister = "registration_auto_register"
 	// StageRegistrationUnknown describes registration unknown status event.
 	StageRegistrationUnknown = "registration_unknown"
 )
@@ -104,3 +110,9 @@ const (
 	// AppTopicProviderPingP2P represents event bus topic for provider p2p pings to consumer.
 	AppTopicProviderPingP2P = "provider_ping_p2p"
 )
+
+func init() {
+	eventbus.RegisterSchema(AppTopicConnectionEvents, 1, ConnectionEvent{})
+	eventbus.RegisterSchema(AppTopicConsumerPingP2P, 1, PingEvent{})
+	eventbus.RegisterSchema(AppTopicProviderPingP2P, 1, PingEvent{})
+}
@@ -537,6 +537,28 @@ func (m *MysteriumMORQA) ProviderQuality(id identity.Identity) (node.QualityInfo
 	return res, nil
 }
 
+// ProviderReputation fetch provider ranking and per service quality metrics from quality oracle.
+func (m *MysteriumMORQA) ProviderReputation(id identity.Identity) (node.Reputation, error) {
+	var res node.Reputation
+	request, err := requests.NewSignedGetRequest(m.baseURL, "provider/reputation", m.signer(id))
+	if err != nil {
+		return res, err
+	}
+
+	response, err := m.client.Do(request)
+	if err != nil {
+		return res, fmt.Errorf("failed to request provider reputation: %w", err)
+	}
+	defer response.Body.Close()
+
+	if err = parseResponseJSON(response, &res); err != nil {
+		log.Err(err).Msg("Failed to parse provider reputation")
+		return res, err
+	}
+
+	return res, nil
+}
+
 // SendMetric submits new metric.
 func (m *MysteriumMORQA) SendMetric(id string, event *metrics.Event) error {
 	m.metrics <- metric{
@@ -31,6 +31,7 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/referral"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
@@ -60,6 +61,7 @@ const (
 	stunDetectionEvent       = "stun_detection_event"
 	natTypeDetectionEvent    = "nat_type_detection_event"
 	natTraversalMethod       = "nat_traversal_method"
+	hermesAvailabilityName   = "hermes_availability"
 )
 
 // Transport allows sending events
@@ -67,6 +69,12 @@ type Transport interface {
 	SendEvent(Event) error
 }
 
+// ReferralAttributor provides signed referral tokens attached to identity events.
+type ReferralAttributor interface {
+	Attribution(id identity.Identity) *referral.Attribution
+	FirstSessionAttribution(id identity.Identity) *referral.Attribution
+}
+
 // NewSender creates metrics sender with appropriate transport
 func NewSender(transport Transport, appVersion string) *Sender {
 	return &Sender{
@@ -81,6 +89,8 @@ func NewSender(transport Transport, appVersion string) *Sender {
 type Sender struct {
 	Transport  Transport
 	AppVersion string
+	// Referrals is optional, referral attribution is not sent when it is not set.
+	Referrals ReferralAttributor
 
 	identitiesMu       sync.RWMutex
 	identitiesUnlocked []identity.Identity
@@ -123,6 +133,7 @@ type natMappingContext struct {
 type sessionEventContext struct {
 	IsProvider bool
 	Event      string
+	Referral   *referral.Attribution `json:",omitempty"`
 	sessionContext
 }
 
@@ -140,6 +151,7 @@ type sessionTokensContext struct {
 type registrationEvent struct {
 	Identity string
 	Status   string
+	Referral *referral.Attribution `json:",omitempty"`
 }
 
 type sessionTraceContext struct {
@@ -169,6 +181,15 @@ type natTypeEvent struct {
 	NATType string
 }
 
+type hermesAvailabilityEvent struct {
+	ID        string
+	ChainID   int64
+	HermesID  string
+	Available bool
+	LatencyMs int64
+	Error     string
+}
+
 type natMethodEvent struct {
 	ID        string
 	NATMethod string
@@ -195,6 +216,7 @@ func (s *Sender) Subscribe(bus eventbus.Subscriber) error {
 		p2p.AppTopicSTUN:                             s.sendSTUNDetectionStatus,
 		behavior.AppTopicNATTypeDetected:             s.sendNATType,
 		p2pnat.AppTopicNATTraversalMethod:            s.sendNATtraversalMethod,
+		pingpongEvent.AppTopicHermesAvailability:     s.sendHermesAvailability,
 	}
 
 	for topic, fn := range subscription {
@@ -226,6 +248,22 @@ func (s *Sender) sendNATType(natType nat.NATType) {
 	}
 }
 
+func (s *Sender) sendHermesAvailability(e pingpongEvent.AppEventHermesAvailability) {
+	s.identitiesMu.RLock()
+	defer s.identitiesMu.RUnlock()
+
+	for _, id := range s.identitiesUnlocked {
+		s.sendEvent(hermesAvailabilityName, hermesAvailabilityEvent{
+			ID:        id.Address,
+			ChainID:   e.ChainID,
+			HermesID:  e.HermesID.Hex(),
+			Available: e.Available,
+			LatencyMs: e.Latency.Milliseconds(),
+			Error:     e.Error,
+		})
+	}
+}
+
 func (s *Sender) sendSTUNDetectionStatus(status p2p.STUNDetectionStatus) {
 	s.sendEvent(stunDetectionEvent, natTypeEvent{
 		ID:      status.Identity,
@@ -361,9 +399,14 @@ func (s *Sender) sendSessionEvent(e connectionstate.AppEventConnectionSession) {
 	switch e.Status {
 	case connectionstate.SessionCreatedStatus:
 		s.rememberSessionContext(sessionContext)
+		var attribution *referral.Attribution
+		if s.Referrals != nil {
+			attribution = s.Referrals.FirstSessionAttribution(e.SessionInfo.ConsumerID)
+		}
 		s.sendEvent(sessionEventName, sessionEventContext{
 			IsProvider:     false,
 			Event:          e.Status,
+			Referral:       attribution,
 			sessionContext: sessionContext,
 		})
 	case connectionstate.SessionEndedStatus:
@@ -391,9 +434,14 @@ func (s *Sender) sendProposalEvent(p market.ServiceProposal) {
 }
 
 func (s *Sender) sendRegistrationEvent(r registry.AppEventIdentityRegistration) {
+	var attribution *referral.Attribution
+	if s.Referrals != nil {
+		attribution = s.Referrals.Attribution(r.ID)
+	}
 	s.sendEvent(registerIdentity, registrationEvent{
 		Identity: r.ID.Address,
 		Status:   r.Status.String(),
+		Referral: attribution,
 	})
 }
 
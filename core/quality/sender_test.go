@@ -22,7 +22,9 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/referral"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -87,3 +89,27 @@ func TestSender_SendNATMappingFailEvent_SendsToTransport(t *testing.T) {
 	assert.Equal(t, "id", c.ID)
 	assert.Equal(t, mockGateways, c.Gateways)
 }
+
+type mockReferrals struct {
+	attribution *referral.Attribution
+}
+
+func (r *mockReferrals) Attribution(id identity.Identity) *referral.Attribution {
+	return r.attribution
+}
+
+func (r *mockReferrals) FirstSessionAttribution(id identity.Identity) *referral.Attribution {
+	return r.attribution
+}
+
+func TestSender_SendRegistrationEvent_AttachesReferral(t *testing.T) {
+	mockTransport := buildMockEventsTransport(nil)
+	attribution := &referral.Attribution{Token: "friend-42", Signature: "signature"}
+	sender := &Sender{Transport: mockTransport, AppVersion: "test version", Referrals: &mockReferrals{attribution: attribution}}
+
+	sender.sendRegistrationEvent(registry.AppEventIdentityRegistration{ID: identity.FromAddress("0x1"), Status: registry.Registered})
+
+	sentEvent := mockTransport.sentEvent
+	assert.Equal(t, "register_identity", sentEvent.EventName)
+	assert.Equal(t, registrationEvent{Identity: "0x1", Status: registry.Registered.String(), Referral: attribution}, sentEvent.Context)
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package referral
+
+import (
+	"errors"
+	"sync"
+	"unicode"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// maxTokenLength limits the length of referral token accepted from the user.
+const maxTokenLength = 64
+
+// ErrInvalidToken is returned when referral token is empty, too long or contains unprintable characters.
+var ErrInvalidToken = errors.New("invalid referral token")
+
+// Attribution is a referral token signed by the identity it is attributed to.
+type Attribution struct {
+	Token     string `json:"token"`
+	Signature string `json:"signature"`
+}
+
+type configStore interface {
+	GetString(key string) string
+	SetUser(key string, value interface{})
+	RemoveUser(key string)
+	SaveUserConfig() error
+}
+
+// Tracker keeps the referral token of the node and signs it for attribution.
+type Tracker struct {
+	config        configStore
+	signerFactory identity.SignerFactory
+
+	mu       sync.Mutex
+	reported map[identity.Identity]struct{}
+}
+
+// NewTracker returns a new referral tracker.
+func NewTracker(config configStore, signerFactory identity.SignerFactory) *Tracker {
+	return &Tracker{
+		config:        config,
+		signerFactory: signerFactory,
+		reported:      make(map[identity.Identity]struct{}),
+	}
+}
+
+// Token returns the referral token, empty if none is set.
+func (t *Tracker) Token() string {
+	return t.config.GetString(config.FlagReferralToken.Name)
+}
+
+// SetToken validates and persists the referral token.
+func (t *Tracker) SetToken(token string) error {
+	if !validToken(token) {
+		return ErrInvalidToken
+	}
+
+	t.config.SetUser(config.FlagReferralToken.Name, token)
+	if err := t.config.SaveUserConfig(); err != nil {
+		return err
+	}
+
+	t.resetReported()
+	return nil
+}
+
+// ClearToken removes the referral token.
+func (t *Tracker) ClearToken() error {
+	t.config.RemoveUser(config.FlagReferralToken.Name)
+	if err := t.config.SaveUserConfig(); err != nil {
+		return err
+	}
+
+	t.resetReported()
+	return nil
+}
+
+// Attribution returns the referral token signed by the given identity, nil if no token is set.
+func (t *Tracker) Attribution(id identity.Identity) *Attribution {
+	token := t.Token()
+	if token == "" {
+		return nil
+	}
+
+	signature, err := t.signerFactory(id).Sign([]byte(token))
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not sign referral token for %s", id.Address)
+		return nil
+	}
+
+	return &Attribution{
+		Token:     token,
+		Signature: signature.Base64(),
+	}
+}
+
+// FirstSessionAttribution returns the signed referral token only for the first session
+// of the given identity since the node start or the token change, nil otherwise.
+func (t *Tracker) FirstSessionAttribution(id identity.Identity) *Attribution {
+	if t.Token() == "" {
+		return nil
+	}
+
+	t.mu.Lock()
+	if _, ok := t.reported[id]; ok {
+		t.mu.Unlock()
+		return nil
+	}
+	t.reported[id] = struct{}{}
+	t.mu.Unlock()
+
+	return t.Attribution(id)
+}
+
+func (t *Tracker) resetReported() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reported = make(map[identity.Identity]struct{})
+}
+
+func validToken(token string) bool {
+	if token == "" || len(token) > maxTokenLength {
+		return false
+	}
+
+	for _, r := range token {
+		if !unicode.IsPrint(r) || unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package referral
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockConfig struct {
+	values map[string]interface{}
+	saved  int
+}
+
+func (c *mockConfig) GetString(key string) string {
+	v, _ := c.values[key].(string)
+	return v
+}
+
+func (c *mockConfig) SetUser(key string, value interface{}) {
+	c.values[key] = value
+}
+
+func (c *mockConfig) RemoveUser(key string) {
+	delete(c.values, key)
+}
+
+func (c *mockConfig) SaveUserConfig() error {
+	c.saved++
+	return nil
+}
+
+func newTestTracker() (*Tracker, *mockConfig) {
+	cfg := &mockConfig{values: make(map[string]interface{})}
+	return NewTracker(cfg, func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}), cfg
+}
+
+func TestTracker_SetToken(t *testing.T) {
+	tracker, cfg := newTestTracker()
+
+	for _, token := range []string{"", "has space", strings.Repeat("a", maxTokenLength+1), "tab\t"} {
+		assert.Equal(t, ErrInvalidToken, tracker.SetToken(token), token)
+	}
+	assert.Zero(t, cfg.saved)
+
+	assert.NoError(t, tracker.SetToken("friend-42"))
+	assert.Equal(t, "friend-42", tracker.Token())
+	assert.Equal(t, 1, cfg.saved)
+
+	assert.NoError(t, tracker.ClearToken())
+	assert.Equal(t, "", tracker.Token())
+	assert.Equal(t, 2, cfg.saved)
+}
+
+func TestTracker_Attribution(t *testing.T) {
+	tracker, _ := newTestTracker()
+	id := identity.FromAddress("0x1")
+
+	assert.Nil(t, tracker.Attribution(id))
+
+	assert.NoError(t, tracker.SetToken("friend-42"))
+	signature := identity.SignatureBytes([]byte("signedfriend-42"))
+	assert.Equal(t, &Attribution{Token: "friend-42", Signature: signature.Base64()}, tracker.Attribution(id))
+}
+
+func TestTracker_FirstSessionAttribution(t *testing.T) {
+	tracker, _ := newTestTracker()
+	id := identity.FromAddress("0x1")
+
+	assert.Nil(t, tracker.FirstSessionAttribution(id))
+
+	assert.NoError(t, tracker.SetToken("friend-42"))
+	assert.NotNil(t, tracker.FirstSessionAttribution(id))
+	assert.Nil(t, tracker.FirstSessionAttribution(id))
+	assert.NotNil(t, tracker.FirstSessionAttribution(identity.FromAddress("0x2")))
+
+	assert.NoError(t, tracker.SetToken("friend-43"))
+	assert.Equal(t, "friend-43", tracker.FirstSessionAttribution(id).Token)
+}
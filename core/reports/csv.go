@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reports
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+const (
+	recordEarning    = "earning"
+	recordSettlement = "settlement"
+	recordWithdrawal = "withdrawal"
+	recordSpending   = "spending"
+)
+
+var csvHeader = []string{"record", "period_start", "period_end", "time", "service_type", "provider_id", "sessions", "tx_hash", "hermes_id", "beneficiary", "amount_myst", "fees_myst"}
+
+// WriteCSV writes the statement as a flat CSV table, one record per row.
+func WriteCSV(w io.Writer, st Statement) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	from, to := st.From.Format(time.RFC3339), st.To.Format(time.RFC3339)
+	row := func(record string) []string {
+		r := make([]string, len(csvHeader))
+		r[0], r[1], r[2] = record, from, to
+		return r
+	}
+
+	for _, e := range st.Earnings {
+		r := row(recordEarning)
+		r[4] = e.ServiceType
+		r[6] = strconv.Itoa(e.Sessions)
+		r[10] = myst(e.Tokens)
+		if err := cw.Write(r); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range st.Settlements {
+		record := recordSettlement
+		if s.IsWithdrawal {
+			record = recordWithdrawal
+		}
+		r := row(record)
+		r[3] = s.Time.UTC().Format(time.RFC3339)
+		r[5] = s.ProviderID.Address
+		r[7] = s.TxHash.Hex()
+		r[8] = s.HermesID.Hex()
+		r[9] = s.Beneficiary.Hex()
+		r[10] = myst(s.Amount)
+		r[11] = myst(s.Fees)
+		if err := cw.Write(r); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range st.Spending {
+		r := row(recordSpending)
+		r[5] = s.ProviderID.Address
+		r[6] = strconv.Itoa(s.Sessions)
+		r[10] = myst(s.Tokens)
+		if err := cw.Write(r); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func myst(amount *big.Int) string {
+	return crypto.BigMystToDecimal(nonNil(amount)).String()
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reports
+
+import (
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+type sessionStorage interface {
+	List(*session.Filter) ([]session.History, error)
+}
+
+type settlementStorage interface {
+	List(pingpong.SettlementHistoryFilter) ([]pingpong.SettlementHistoryEntry, error)
+}
+
+// ServiceEarnings holds the earnings of a single service type.
+type ServiceEarnings struct {
+	ServiceType string
+	Sessions    int
+	Tokens      *big.Int
+}
+
+// ProviderSpending holds the spending on a single provider.
+type ProviderSpending struct {
+	ProviderID identity.Identity
+	Sessions   int
+	Tokens     *big.Int
+}
+
+// Settlement holds a single settlement or withdrawal transaction.
+type Settlement struct {
+	Time             time.Time
+	TxHash           common.Hash
+	BlockExplorerURL string
+	ProviderID       identity.Identity
+	HermesID         common.Address
+	Beneficiary      common.Address
+	Amount           *big.Int
+	Fees             *big.Int
+	IsWithdrawal     bool
+}
+
+// Statement is a summary of earnings, settlements and spending over a period.
+type Statement struct {
+	From, To time.Time
+
+	Earnings    []ServiceEarnings
+	TotalEarned *big.Int
+
+	Settlements  []Settlement
+	TotalSettled *big.Int
+	TotalFees    *big.Int
+
+	Spending   []ProviderSpending
+	TotalSpent *big.Int
+}
+
+// Generator generates statements from the session and settlement history.
+type Generator struct {
+	sessions    sessionStorage
+	settlements settlementStorage
+}
+
+// NewGenerator returns a new statement generator.
+func NewGenerator(sessions sessionStorage, settlements settlementStorage) *Generator {
+	return &Generator{
+		sessions:    sessions,
+		settlements: settlements,
+	}
+}
+
+// MonthlyStatement generates a statement for the given calendar month in UTC.
+func (g *Generator) MonthlyStatement(year int, month time.Month) (Statement, error) {
+	from := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	return g.Statement(from, to)
+}
+
+// Statement generates a statement for the given period.
+func (g *Generator) Statement(from, to time.Time) (Statement, error) {
+	st := Statement{
+		From:         from,
+		To:           to,
+		TotalEarned:  new(big.Int),
+		TotalSettled: new(big.Int),
+		TotalFees:    new(big.Int),
+		TotalSpent:   new(big.Int),
+	}
+
+	sessions, err := g.sessions.List(session.NewFilter().SetStartedFrom(from).SetStartedTo(to))
+	if err != nil {
+		return Statement{}, err
+	}
+	st.Earnings, st.Spending = aggregateSessions(sessions)
+	for _, e := range st.Earnings {
+		st.TotalEarned.Add(st.TotalEarned, e.Tokens)
+	}
+	for _, s := range st.Spending {
+		st.TotalSpent.Add(st.TotalSpent, s.Tokens)
+	}
+
+	entries, err := g.settlements.List(pingpong.SettlementHistoryFilter{TimeFrom: &from, TimeTo: &to})
+	if err != nil {
+		return Statement{}, err
+	}
+	st.Settlements = make([]Settlement, 0, len(entries))
+	for _, e := range entries {
+		// Failed settlements have not reached the chain, so they are not part of the statement.
+		if e.Error != "" {
+			continue
+		}
+
+		s := Settlement{
+			Time:             e.Time,
+			TxHash:           e.TxHash,
+			BlockExplorerURL: e.BlockExplorerURL,
+			ProviderID:       e.ProviderID,
+			HermesID:         e.HermesID,
+			Beneficiary:      e.Beneficiary,
+			Amount:           nonNil(e.Amount),
+			Fees:             nonNil(e.Fees),
+			IsWithdrawal:     e.IsWithdrawal,
+		}
+		st.TotalSettled.Add(st.TotalSettled, s.Amount)
+		st.TotalFees.Add(st.TotalFees, s.Fees)
+		st.Settlements = append(st.Settlements, s)
+	}
+	sort.Slice(st.Settlements, func(i, j int) bool {
+		return st.Settlements[i].Time.Before(st.Settlements[j].Time)
+	})
+
+	return st, nil
+}
+
+func aggregateSessions(sessions []session.History) ([]ServiceEarnings, []ProviderSpending) {
+	earnings := make(map[string]*ServiceEarnings)
+	spending := make(map[identity.Identity]*ProviderSpending)
+
+	for _, s := range sessions {
+		switch s.Direction {
+		case session.DirectionProvided:
+			e, ok := earnings[s.ServiceType]
+			if !ok {
+				e = &ServiceEarnings{ServiceType: s.ServiceType, Tokens: new(big.Int)}
+				earnings[s.ServiceType] = e
+			}
+			e.Sessions++
+			e.Tokens.Add(e.Tokens, nonNil(s.Tokens))
+		case session.DirectionConsumed:
+			sp, ok := spending[s.ProviderID]
+			if !ok {
+				sp = &ProviderSpending{ProviderID: s.ProviderID, Tokens: new(big.Int)}
+				spending[s.ProviderID] = sp
+			}
+			sp.Sessions++
+			sp.Tokens.Add(sp.Tokens, nonNil(s.Tokens))
+		}
+	}
+
+	earningsList := make([]ServiceEarnings, 0, len(earnings))
+	for _, e := range earnings {
+		earningsList = append(earningsList, *e)
+	}
+	sort.Slice(earningsList, func(i, j int) bool {
+		return earningsList[i].ServiceType < earningsList[j].ServiceType
+	})
+
+	spendingList := make([]ProviderSpending, 0, len(spending))
+	for _, s := range spending {
+		spendingList = append(spendingList, *s)
+	}
+	sort.Slice(spendingList, func(i, j int) bool {
+		return spendingList[i].ProviderID.Address < spendingList[j].ProviderID.Address
+	})
+
+	return earningsList, spendingList
+}
+
+func nonNil(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package reports
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+var (
+	providerA = identity.FromAddress("0x000000000000000000000000000000000000000a")
+	providerB = identity.FromAddress("0x000000000000000000000000000000000000000b")
+	txHash    = common.HexToHash("0x20c070a9be65355adbd2ba479e095e2e8ed7e692596548734984eab75d3fdfa5")
+)
+
+func newTestGenerator() (*Generator, *mockSessionStorage, *mockSettlementStorage) {
+	sessions := &mockSessionStorage{
+		toReturn: []session.History{
+			{Direction: session.DirectionProvided, ServiceType: "wireguard", Tokens: big.NewInt(100)},
+			{Direction: session.DirectionProvided, ServiceType: "wireguard", Tokens: big.NewInt(50)},
+			{Direction: session.DirectionProvided, ServiceType: "scraping", Tokens: big.NewInt(10)},
+			{Direction: session.DirectionConsumed, ProviderID: providerB, Tokens: big.NewInt(7)},
+			{Direction: session.DirectionConsumed, ProviderID: providerA, Tokens: nil},
+		},
+	}
+	settlements := &mockSettlementStorage{
+		toReturn: []pingpong.SettlementHistoryEntry{
+			{TxHash: txHash, Time: time.Date(2022, 5, 20, 0, 0, 0, 0, time.UTC), Amount: big.NewInt(90), Fees: big.NewInt(9)},
+			{TxHash: common.HexToHash("0x1"), Time: time.Date(2022, 5, 10, 0, 0, 0, 0, time.UTC), Amount: big.NewInt(40), Fees: big.NewInt(4), IsWithdrawal: true},
+			{TxHash: common.HexToHash("0x2"), Amount: big.NewInt(1000), Error: "failed"},
+		},
+	}
+	return NewGenerator(sessions, settlements), sessions, settlements
+}
+
+func TestGenerator_MonthlyStatement(t *testing.T) {
+	generator, sessions, settlements := newTestGenerator()
+
+	st, err := generator.MonthlyStatement(2022, time.May)
+	assert.NoError(t, err)
+
+	from := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, 5, 31, 23, 59, 59, 999999999, time.UTC)
+	assert.Equal(t, from, st.From)
+	assert.Equal(t, to, st.To)
+	assert.Equal(t, from, *sessions.calledWith.StartedFrom)
+	assert.Equal(t, to, *sessions.calledWith.StartedTo)
+	assert.Equal(t, from, *settlements.calledWith.TimeFrom)
+	assert.Equal(t, to, *settlements.calledWith.TimeTo)
+
+	assert.Equal(t, []ServiceEarnings{
+		{ServiceType: "scraping", Sessions: 1, Tokens: big.NewInt(10)},
+		{ServiceType: "wireguard", Sessions: 2, Tokens: big.NewInt(150)},
+	}, st.Earnings)
+	assert.Equal(t, big.NewInt(160), st.TotalEarned)
+
+	assert.Equal(t, []ProviderSpending{
+		{ProviderID: providerA, Sessions: 1, Tokens: big.NewInt(0)},
+		{ProviderID: providerB, Sessions: 1, Tokens: big.NewInt(7)},
+	}, st.Spending)
+	assert.Equal(t, big.NewInt(7), st.TotalSpent)
+
+	assert.Len(t, st.Settlements, 2)
+	assert.True(t, st.Settlements[0].IsWithdrawal)
+	assert.Equal(t, txHash, st.Settlements[1].TxHash)
+	assert.Equal(t, big.NewInt(130), st.TotalSettled)
+	assert.Equal(t, big.NewInt(13), st.TotalFees)
+}
+
+func TestWriteCSV(t *testing.T) {
+	generator, _, _ := newTestGenerator()
+	st, err := generator.MonthlyStatement(2022, time.May)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCSV(&buf, st))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, records, 7)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{"earning", "2022-05-01T00:00:00Z", "2022-05-31T23:59:59Z", "", "scraping", "", "1", "", "", "", "0.00000000000000001", ""}, records[1])
+	assert.Equal(t, "withdrawal", records[3][0])
+	assert.Equal(t, "settlement", records[4][0])
+	assert.Equal(t, txHash.Hex(), records[4][7])
+	assert.Equal(t, "spending", records[6][0])
+	assert.Equal(t, providerB.Address, records[6][5])
+}
+
+type mockSessionStorage struct {
+	toReturn   []session.History
+	calledWith *session.Filter
+}
+
+func (m *mockSessionStorage) List(filter *session.Filter) ([]session.History, error) {
+	m.calledWith = filter
+	return m.toReturn, nil
+}
+
+type mockSettlementStorage struct {
+	toReturn   []pingpong.SettlementHistoryEntry
+	calledWith pingpong.SettlementHistoryFilter
+}
+
+func (m *mockSettlementStorage) List(filter pingpong.SettlementHistoryFilter) ([]pingpong.SettlementHistoryEntry, error) {
+	m.calledWith = filter
+	return m.toReturn, nil
+}
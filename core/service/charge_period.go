@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChargePeriodConfig determines how often consumers are charged and how far they may negotiate it.
+type ChargePeriodConfig struct {
+	// Default is the charge period of services not listed in PerService, zero leaves it to the payment engine.
+	Default time.Duration
+	// PerService overrides the charge period of the given service types.
+	PerService map[string]time.Duration
+	// Min and Max bound the charge period requested by the consumer, zero means unbounded.
+	Min, Max time.Duration
+}
+
+// Negotiate picks the charge period of the given service type, preferring the one requested by the consumer.
+// Zero is returned when neither side has a preference.
+func (c ChargePeriodConfig) Negotiate(serviceType string, requested time.Duration) time.Duration {
+	period := c.Default
+	if p, ok := c.PerService[serviceType]; ok {
+		period = p
+	}
+	if requested > 0 {
+		period = requested
+	}
+	if period <= 0 {
+		return 0
+	}
+
+	if c.Min > 0 && period < c.Min {
+		period = c.Min
+	}
+	if c.Max > 0 && period > c.Max {
+		period = c.Max
+	}
+	return period
+}
+
+// ParseChargePeriods parses charge periods of service types given as "wireguard=30s,scraping=1m".
+func ParseChargePeriods(value string) (map[string]time.Duration, error) {
+	periods := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid charge period %q, expected <service type>=<duration>", entry)
+		}
+
+		period, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid charge period of %s: %w", parts[0], err)
+		}
+		periods[strings.TrimSpace(parts[0])] = period
+	}
+	return periods, nil
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChargePeriodConfig_Negotiate(t *testing.T) {
+	config := ChargePeriodConfig{
+		PerService: map[string]time.Duration{"wireguard": 30 * time.Second},
+		Min:        20 * time.Second,
+		Max:        5 * time.Minute,
+	}
+
+	tests := []struct {
+		name        string
+		serviceType string
+		requested   time.Duration
+		want        time.Duration
+	}{
+		{name: "no preference", serviceType: "openvpn", want: 0},
+		{name: "service default", serviceType: "wireguard", want: 30 * time.Second},
+		{name: "consumer request", serviceType: "wireguard", requested: time.Minute, want: time.Minute},
+		{name: "request below bounds", serviceType: "openvpn", requested: time.Second, want: 20 * time.Second},
+		{name: "request above bounds", serviceType: "openvpn", requested: time.Hour, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, config.Negotiate(tt.serviceType, tt.requested))
+		})
+	}
+}
+
+func TestParseChargePeriods(t *testing.T) {
+	periods, err := ParseChargePeriods("wireguard=30s, scraping=1m")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{"wireguard": 30 * time.Second, "scraping": time.Minute}, periods)
+
+	periods, err = ParseChargePeriods("")
+	assert.NoError(t, err)
+	assert.Empty(t, periods)
+
+	_, err = ParseChargePeriods("wireguard")
+	assert.Error(t, err)
+
+	_, err = ParseChargePeriods("wireguard=soon")
+	assert.Error(t, err)
+}
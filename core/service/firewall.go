@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// SessionFirewall is implemented by services able to apply firewall rules of a single session.
+type SessionFirewall interface {
+	// AllowSessionNetworks exempts the networks from the protection of the session with the given ID, nil removes the exemption.
+	AllowSessionNetworks(sessionID string, networks []net.IPNet)
+}
+
+// FirewallConfig determines which parts of the protected provider networks the sessions are allowed to reach.
+// Everything else inside the protected networks stays blocked.
+type FirewallConfig struct {
+	// AllowedNetworks are reachable by every session.
+	AllowedNetworks []net.IPNet
+	// ConsumerAllowedNetworks are additionally reachable by sessions of the given consumer identities.
+	ConsumerAllowedNetworks map[identity.Identity][]net.IPNet
+}
+
+// SessionNetworks returns the networks the session of the given consumer is allowed to reach.
+func (c FirewallConfig) SessionNetworks(consumer identity.Identity) []net.IPNet {
+	consumerNetworks := c.ConsumerAllowedNetworks[identity.FromAddress(consumer.Address)]
+	if len(consumerNetworks) == 0 {
+		return c.AllowedNetworks
+	}
+
+	networks := make([]net.IPNet, 0, len(c.AllowedNetworks)+len(consumerNetworks))
+	networks = append(networks, c.AllowedNetworks...)
+	return append(networks, consumerNetworks...)
+}
+
+// ParseConsumerAllowedNetworks parses networks allowed for consumers given as "0x1=192.168.1.10/32,0x1=10.0.0.0/24".
+func ParseConsumerAllowedNetworks(value string) (map[identity.Identity][]net.IPNet, error) {
+	networks := make(map[identity.Identity][]net.IPNet)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid allowed network %q, expected <consumer identity>=<subnet>", entry)
+		}
+
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed network of %s: %w", parts[0], err)
+		}
+		consumer := identity.FromAddress(strings.TrimSpace(parts[0]))
+		networks[consumer] = append(networks[consumer], *ipNet)
+	}
+	return networks, nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+func TestFirewallConfig_SessionNetworks(t *testing.T) {
+	lan := net.IPNet{IP: net.ParseIP("10.0.0.0").To4(), Mask: net.CIDRMask(24, 32)}
+	printer := net.IPNet{IP: net.ParseIP("192.168.1.10").To4(), Mask: net.CIDRMask(32, 32)}
+	cfg := FirewallConfig{
+		AllowedNetworks:         []net.IPNet{lan},
+		ConsumerAllowedNetworks: map[identity.Identity][]net.IPNet{identity.FromAddress("0xabc"): {printer}},
+	}
+
+	assert.Equal(t, []net.IPNet{lan}, cfg.SessionNetworks(identity.FromAddress("0x1")))
+	assert.Equal(t, []net.IPNet{lan, printer}, cfg.SessionNetworks(identity.Identity{Address: "0xABC"}))
+	assert.Empty(t, FirewallConfig{}.SessionNetworks(identity.FromAddress("0xabc")))
+}
+
+func TestParseConsumerAllowedNetworks(t *testing.T) {
+	networks, err := ParseConsumerAllowedNetworks("0xABC=192.168.1.10/32, 0xabc=10.0.0.0/24,0x1=172.16.0.1/32")
+	assert.NoError(t, err)
+	assert.Equal(t, map[identity.Identity][]net.IPNet{
+		identity.FromAddress("0xabc"): {
+			{IP: net.ParseIP("192.168.1.10").To4(), Mask: net.CIDRMask(32, 32)},
+			{IP: net.ParseIP("10.0.0.0").To4(), Mask: net.CIDRMask(24, 32)},
+		},
+		identity.FromAddress("0x1"): {
+			{IP: net.ParseIP("172.16.0.1").To4(), Mask: net.CIDRMask(32, 32)},
+		},
+	}, networks)
+
+	networks, err = ParseConsumerAllowedNetworks("")
+	assert.NoError(t, err)
+	assert.Empty(t, networks)
+
+	_, err = ParseConsumerAllowedNetworks("192.168.1.10/32")
+	assert.Error(t, err)
+
+	_, err = ParseConsumerAllowedNetworks("0xabc=printer")
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ package service
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -34,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/datatransfer"
 	"github.com/mysteriumnetwork/node/services/scraping"
 	"github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/mysteriumnetwork/node/utils/reftracker"
@@ -46,10 +48,13 @@ var (
 	ErrUnsupportedServiceType = errors.New("unsupported service type")
 	// ErrUnsupportedAccessPolicy indicates that manager tried to create service with unsupported access policy
 	ErrUnsupportedAccessPolicy = errors.New("unsupported access policy")
+	// ErrInstanceNotRunning indicates that manager tried to restart service which is not running
+	ErrInstanceNotRunning = errors.New("service instance is not running")
 )
 
 const (
 	channelIdleTimeout = 1 * time.Minute
+	drainCheckInterval = 5 * time.Second
 )
 
 // Service interface represents pluggable Mysterium service
@@ -74,6 +79,25 @@ type locationResolver interface {
 	DetectLocation() (locationstate.Location, error)
 }
 
+type contactProvider interface {
+	GetContact() market.Contact
+}
+
+// proposalAttester signs the node metadata attached to the proposals of the provider.
+type proposalAttester interface {
+	Attest(providerID identity.Identity) (*market.NodeAttestation, error)
+}
+
+// proposalPricer returns the price announced in the proposals of the provider, nil if the network price is charged.
+type proposalPricer interface {
+	ProposalPrice(nodeType, country, serviceType string) *market.Price
+}
+
+type serviceSessions interface {
+	GetAll() []*Session
+	Terminate(id session.ID, reason session.TerminationReason, message string) error
+}
+
 // WaitForNATHole blocks until NAT hole is punched towards consumer through local NAT or until hole punching failed
 type WaitForNATHole func() error
 
@@ -87,6 +111,10 @@ func NewManager(
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager,
 	statusStorage connectivity.StatusStorage,
 	location locationResolver,
+	sessions serviceSessions,
+	attester proposalAttester,
+	pricer proposalPricer,
+	consumerCountries []string,
 ) *Manager {
 	return &Manager{
 		serviceRegistry:  serviceRegistry,
@@ -98,6 +126,11 @@ func NewManager(
 		sessionManager:   sessionManager,
 		statusStorage:    statusStorage,
 		location:         location,
+		sessions:         sessions,
+		attester:         attester,
+		pricer:           pricer,
+
+		consumerCountries: consumerCountries,
 	}
 }
 
@@ -114,12 +147,101 @@ type Manager struct {
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager
 	statusStorage  connectivity.StatusStorage
 	location       locationResolver
+	sessions       serviceSessions
+	attester       proposalAttester
+	pricer         proposalPricer
+
+	// consumerCountries restricts the countries consumers may connect from, all countries are allowed if empty.
+	consumerCountries []string
 }
 
 // Start starts an instance of the given service type if knows one in service registry.
 // It passes the options to the start method of the service.
 // If an error occurs in the underlying service, the error is then returned.
 func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options) (id ID, err error) {
+	return manager.start(providerID, serviceType, policyIDs, options, nil)
+}
+
+// Restart applies given options to the running service without dropping its sessions.
+// New instance is started alongside the old one and takes over new sessions and the proposal
+// announcement, while the old instance keeps serving its sessions until they end or drain timeout expires.
+func (manager *Manager) Restart(id ID, options Options, drainTimeout time.Duration) (ID, error) {
+	old := manager.servicePool.Instance(id)
+	if old == nil {
+		return "", ErrNoSuchInstance
+	}
+	if old.State() != servicestate.Running {
+		return "", ErrInstanceNotRunning
+	}
+
+	newID, err := manager.start(old.ProviderID, old.Type, old.policyIDs, options, old)
+	if err != nil {
+		return "", err
+	}
+
+	old.setState(servicestate.Draining)
+	go manager.drain(old, drainTimeout)
+
+	return newID, nil
+}
+
+// Preview returns the proposal which would be published for the service started with the given parameters.
+// Service is neither started nor announced and the rules of access policies are not fetched.
+func (manager *Manager) Preview(providerID identity.Identity, serviceType string, policyIDs []string, options Options) (market.ServiceProposal, error) {
+	if !manager.serviceRegistry.Supports(serviceType) {
+		return market.ServiceProposal{}, ErrUnsupportedServiceType
+	}
+
+	var accessPolicies []market.AccessPolicy
+	if len(policyIDs) > 0 {
+		accessPolicies = manager.policyOracle.Policies(policyIDs)
+	}
+
+	proposal, err := manager.newProposal(providerID, serviceType, accessPolicies, options)
+	if err != nil {
+		return market.ServiceProposal{}, err
+	}
+
+	if scheduled, ok := options.(scheduledOptions); ok {
+		proposal.Quality.Bandwidth = scheduledBandwidth(scheduled, time.Now())
+	}
+	return proposal, nil
+}
+
+// tieredOptions is implemented by service options offering bandwidth tiers at different prices.
+type tieredOptions interface {
+	ProposalBandwidthTiers() []market.BandwidthTier
+}
+
+// newProposal builds the proposal announcing the service of the provider.
+func (manager *Manager) newProposal(providerID identity.Identity, serviceType string, accessPolicies []market.AccessPolicy, options Options) (market.ServiceProposal, error) {
+	if len(manager.consumerCountries) > 0 {
+		accessPolicies = append(accessPolicies, market.NewConsumerCountryPolicy(manager.consumerCountries))
+	}
+
+	location, err := manager.location.DetectLocation()
+	if err != nil {
+		return market.ServiceProposal{}, err
+	}
+
+	var bandwidthTiers []market.BandwidthTier
+	if tiered, ok := options.(tieredOptions); ok {
+		bandwidthTiers = tiered.ProposalBandwidthTiers()
+	}
+
+	proposal := market.NewProposal(providerID.Address, serviceType, market.NewProposalOpts{
+		Location:       market.NewLocation(location),
+		AccessPolicies: accessPolicies,
+		Contacts:       []market.Contact{manager.p2pListener.GetContact()},
+		BandwidthTiers: bandwidthTiers,
+	})
+	if manager.pricer != nil {
+		proposal.Price = manager.pricer.ProposalPrice(proposal.Location.IPType, proposal.Location.Country, serviceType)
+	}
+	return proposal, nil
+}
+
+func (manager *Manager) start(providerID identity.Identity, serviceType string, policyIDs []string, options Options, predecessor *Instance) (id ID, err error) {
 	log.Debug().Fields(map[string]interface{}{
 		"providerID":  providerID.Address,
 		"serviceType": serviceType,
@@ -141,19 +263,11 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		}
 	}
 
-	location, err := manager.location.DetectLocation()
+	proposal, err := manager.newProposal(providerID, serviceType, accessPolicies, options)
 	if err != nil {
 		return "", err
 	}
 
-	proposal := market.NewProposal(providerID.Address, serviceType, market.NewProposalOpts{
-		Location:       market.NewLocation(location),
-		AccessPolicies: accessPolicies,
-		Contacts:       []market.Contact{manager.p2pListener.GetContact()},
-	})
-
-	discovery := manager.discoveryFactory()
-
 	id, err = generateID()
 	if err != nil {
 		return id, err
@@ -168,31 +282,31 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 		service:        service,
 		Proposal:       proposal,
 		policies:       policyRules,
-		discovery:      discovery,
+		policyIDs:      policyIDs,
 		eventPublisher: manager.eventPublisher,
 		location:       manager.location,
+		contacts:       manager.p2pListener,
+		attester:       manager.attester,
+		pricer:         manager.pricer,
 	}
 
-	discovery.Start(providerID, instance.proposalWithCurrentLocation)
+	if predecessor == nil {
+		instance.discovery = manager.discoveryFactory()
+		instance.discovery.Start(providerID, instance.currentProposal)
 
-	channelHandlers := func(ch p2p.Channel) {
-		chID := "channel:" + ch.ID()
-		log.Info().Msgf("tracking p2p.Channel: %q", chID)
-		reftracker.Singleton().Put(chID, channelIdleTimeout, func() {
-			log.Debug().Msgf("collecting unused p2p.Channel %q", chID)
-			ch.Close()
-		})
-		instance.addP2PChannel(ch)
-		mng := manager.sessionManager(instance, ch)
-		subscribeSessionCreate(mng, ch)
-		subscribeSessionStatus(ch, manager.statusStorage)
-		subscribeSessionAcknowledge(mng, ch)
-		subscribeSessionDestroy(mng, ch)
-		subscribeSessionPayments(mng, ch)
-	}
-	stopP2PListener, err := manager.p2pListener.Listen(providerID, serviceType, channelHandlers)
-	if err != nil {
-		return id, fmt.Errorf("could not subscribe to p2p channels: %w", err)
+		if err := manager.listen(instance); err != nil {
+			return id, fmt.Errorf("could not subscribe to p2p channels: %w", err)
+		}
+	} else {
+		// Only one instance may listen for the new p2p channels of the same service.
+		predecessor.stopListening()
+		if err := manager.listen(instance); err != nil {
+			if err := manager.listen(predecessor); err != nil {
+				log.Error().Err(err).Msgf("Could not resume p2p listener of service %s", predecessor.ID)
+			}
+			return id, fmt.Errorf("could not subscribe to p2p channels: %w", err)
+		}
+		predecessor.handOver(instance)
 	}
 
 	manager.servicePool.Add(instance)
@@ -205,14 +319,16 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 			log.Error().Err(serveErr).Msg("Service serve failed")
 		}
 
-		stopP2PListener()
+		instance.stopListening()
 
 		stopErr := manager.servicePool.Stop(id)
 		if stopErr != nil {
 			log.Error().Err(stopErr).Msg("Service stop failed")
 		}
 
-		discovery.Wait()
+		if discovery := instance.ownDiscovery(); discovery != nil {
+			discovery.Wait()
+		}
 	}()
 
 	netutil.LogNetworkStats()
@@ -220,6 +336,88 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 	return id, nil
 }
 
+func (manager *Manager) listen(instance *Instance) error {
+	channelHandlers := func(ch p2p.Channel) {
+		chID := "channel:" + ch.ID()
+		log.Info().Msgf("tracking p2p.Channel: %q", chID)
+		reftracker.Singleton().Put(chID, channelIdleTimeout, func() {
+			log.Debug().Msgf("collecting unused p2p.Channel %q", chID)
+			ch.Close()
+		})
+		instance.addP2PChannel(ch)
+		mng := manager.sessionManager(instance, ch)
+		subscribeSessionCreate(mng, ch, ch.Codec())
+		subscribeSessionStatus(ch, manager.statusStorage)
+		subscribeSessionAcknowledge(mng, ch)
+		subscribeSessionDestroy(mng, ch)
+		subscribeSessionPause(mng, ch)
+		subscribeSessionResume(mng, ch)
+		subscribeSessionPayments(mng, ch, ch.Codec())
+	}
+	stop, err := manager.p2pListener.Listen(instance.ProviderID, instance.Type, channelHandlers)
+	if err != nil {
+		return err
+	}
+
+	instance.setListener(stop)
+	return nil
+}
+
+// drain stops given instance once all its sessions are finished or timeout expires.
+func (manager *Manager) drain(instance *Instance, timeout time.Duration) {
+	deadline := time.After(timeout)
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+
+	for manager.sessionCount(instance.ID) > 0 {
+		select {
+		case <-deadline:
+			log.Warn().Msgf("Drain timeout expired, stopping service %s with active sessions", instance.ID)
+			manager.stopDrained(instance)
+			return
+		case <-ticker.C:
+		}
+	}
+
+	manager.stopDrained(instance)
+}
+
+func (manager *Manager) stopDrained(instance *Instance) {
+	log.Info().Msgf("Stopping drained service %s", instance.ID)
+	manager.terminateSessions(func(s *Session) bool { return s.ServiceID == string(instance.ID) })
+	if err := manager.servicePool.Stop(instance.ID); err != nil && err != ErrNoSuchInstance {
+		log.Error().Err(err).Msgf("Failed to stop drained service %s", instance.ID)
+	}
+}
+
+// terminateSessions notifies consumers of the matching sessions about the provider shutdown.
+func (manager *Manager) terminateSessions(match func(*Session) bool) {
+	var wg sync.WaitGroup
+	for _, s := range manager.sessions.GetAll() {
+		if !match(s) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(id session.ID) {
+			defer wg.Done()
+			if err := manager.sessions.Terminate(id, session.TerminationReasonProviderShutdown, "service stopped"); err != nil && err != ErrorSessionNotExists {
+				log.Warn().Err(err).Msgf("Failed to terminate session %s", id)
+			}
+		}(s.ID)
+	}
+	wg.Wait()
+}
+
+func (manager *Manager) sessionCount(id ID) (count int) {
+	for _, s := range manager.sessions.GetAll() {
+		if s.ServiceID == string(id) {
+			count++
+		}
+	}
+	return count
+}
+
 func generateID() (ID, error) {
 	uid, err := uuid.NewV4()
 	if err != nil {
@@ -263,11 +461,13 @@ func (manager *Manager) List(includeAll bool) []*Instance {
 
 // Kill stops all services.
 func (manager *Manager) Kill() error {
+	manager.terminateSessions(func(*Session) bool { return true })
 	return manager.servicePool.StopAll()
 }
 
 // Stop stops the service.
 func (manager *Manager) Stop(id ID) error {
+	manager.terminateSessions(func(s *Session) bool { return s.ServiceID == string(id) })
 	err := manager.servicePool.Stop(id)
 	if err != nil {
 		return err
@@ -29,14 +29,18 @@ import (
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/node/session"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/trace"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/stretchr/testify/assert"
 )
 
 var (
 	serviceType      = "the-very-awesome-test-service-type"
-	mockPolicyOracle = policy.NewOracle(requests.NewHTTPClient("0.0.0.0", requests.DefaultTimeout), "http://policy.localhost/", 1*time.Minute)
+	mockPolicyOracle = policy.NewOracle(requests.NewHTTPClient("0.0.0.0", requests.DefaultTimeout), "http://policy.localhost/", 1*time.Minute, nil, nil)
 )
 
 func init() {
@@ -58,7 +62,7 @@ func TestManager_StartRemovesServiceFromPoolIfServiceCrashes(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil, mockLocationResolver{},
+		&mockP2PListener{}, nil, nil, mockLocationResolver{}, NewSessionPool(mocks.NewEventBus()), nil, nil, nil,
 	)
 	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
 	assert.Nil(t, err)
@@ -83,7 +87,7 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 		mocks.NewEventBus(),
 		mockPolicyOracle,
 		&mockP2PListener{}, nil, nil,
-		mockLocationResolver{},
+		mockLocationResolver{}, NewSessionPool(mocks.NewEventBus()), nil, nil, nil,
 	)
 	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
 	assert.Nil(t, err)
@@ -110,7 +114,7 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 		eventBus,
 		mockPolicyOracle,
 		&mockP2PListener{}, nil, nil,
-		mockLocationResolver{},
+		mockLocationResolver{}, NewSessionPool(mocks.NewEventBus()), nil, nil, nil,
 	)
 
 	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
@@ -148,6 +152,130 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 	assert.True(t, matchFound)
 }
 
+func TestManager_RestartHandsOverToNewInstance(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		return &serviceFake{mockProcess: make(chan struct{})}, nil
+	})
+
+	discovery := mockDiscovery{}
+	eventBus := mocks.NewEventBus()
+	sessions := NewSessionPool(eventBus)
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&discovery),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, sessions, nil, nil, nil,
+	)
+
+	oldID, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	assert.NoError(t, err)
+	old := manager.Service(oldID)
+	assert.Eventually(t, func() bool { return old.State() == servicestate.Running }, time.Second, 10*time.Millisecond)
+	sess, err := NewSession(old, &pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: "deadbeef"}}, trace.NewTracer(""))
+	assert.NoError(t, err)
+	sessions.Add(sess)
+	sess.addCleanup(func() error {
+		sessions.Remove(sess.ID)
+		return nil
+	})
+
+	newID, err := manager.Restart(oldID, struct{}{}, 50*time.Millisecond)
+	assert.NoError(t, err)
+	assert.NotEqual(t, oldID, newID)
+	assert.Equal(t, servicestate.Draining, old.State())
+
+	next := manager.Service(newID)
+	assert.Nil(t, old.ownDiscovery())
+	assert.Equal(t, &discovery, next.ownDiscovery())
+
+	assert.Eventually(t, func() bool { return manager.Service(oldID) == nil }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, servicestate.Running, next.State())
+	assert.Contains(t, eventBus.GetEventHistory(), mocks.EventBusEntry{
+		Topic: sessionEvent.AppTopicSessionTerminated,
+		Event: sessionEvent.AppEventSessionTerminated{
+			ID:      string(sess.ID),
+			Reason:  session.TerminationReasonProviderShutdown,
+			Message: "service stopped",
+		},
+	})
+
+	assert.NoError(t, manager.Stop(newID))
+	discovery.Wait()
+}
+
+func TestManager_RestartFailsForUnknownService(t *testing.T) {
+	manager := NewManager(
+		NewRegistry(),
+		MockDiscoveryFactoryFunc(&mockDiscovery{}),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, NewSessionPool(mocks.NewEventBus()), nil, nil, nil,
+	)
+
+	_, err := manager.Restart("unknown", struct{}{}, time.Minute)
+	assert.Equal(t, ErrNoSuchInstance, err)
+}
+
+func TestManager_PreviewDoesNotStartService(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		t.Fatal("service should not be created for preview")
+		return nil, nil
+	})
+	discovery := mockDiscovery{}
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&discovery),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, NewSessionPool(mocks.NewEventBus()), nil, nil, nil,
+	)
+
+	proposal, err := manager.Preview(identity.FromAddress(proposalMock.ProviderID), serviceType, []string{"verified-traffic"}, struct{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, proposalMock.ProviderID, proposal.ProviderID)
+	assert.Equal(t, serviceType, proposal.ServiceType)
+	assert.Equal(t, &[]market.AccessPolicy{{ID: "verified-traffic", Source: "http://policy.localhost/verified-traffic"}}, proposal.AccessPolicies)
+	assert.Len(t, manager.servicePool.List(), 0)
+
+	_, err = manager.Preview(identity.FromAddress(proposalMock.ProviderID), "unknown", nil, struct{}{})
+	assert.Equal(t, ErrUnsupportedServiceType, err)
+}
+
+func TestManager_PreviewAnnouncesPrice(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, error) {
+		return &serviceFake{}, nil
+	})
+	discovery := mockDiscovery{}
+	price := market.NewPrice(100, 1000)
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&discovery),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil,
+		mockLocationResolver{}, NewSessionPool(mocks.NewEventBus()), nil, &mockProposalPricer{price: price}, nil,
+	)
+
+	proposal, err := manager.Preview(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{})
+	assert.NoError(t, err)
+	assert.Equal(t, price, proposal.Price)
+}
+
+type mockProposalPricer struct {
+	price *market.Price
+}
+
+func (m *mockProposalPricer) ProposalPrice(nodeType, country, serviceType string) *market.Price {
+	return m.price
+}
+
 type mockP2PListener struct {
 }
 
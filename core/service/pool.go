@@ -19,12 +19,14 @@ package service
 
 import (
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
@@ -158,11 +160,17 @@ type Instance struct {
 	service         Service
 	Proposal        market.ServiceProposal
 	policies        *policy.Repository
+	policyIDs       []string
 	discovery       Discovery
+	successor       *Instance
 	eventPublisher  Publisher
 	p2pChannelsLock sync.Mutex
 	p2pChannels     []p2p.Channel
+	stopListener    func()
 	location        locationResolver
+	contacts        contactProvider
+	attester        proposalAttester
+	pricer          proposalPricer
 }
 
 // Service returns the running service implementation.
@@ -182,7 +190,40 @@ func (i *Instance) State() servicestate.State {
 	return i.state
 }
 
-func (i *Instance) proposalWithCurrentLocation() market.ServiceProposal {
+// scheduledOptions is implemented by service options supporting time-of-day bandwidth limits.
+type scheduledOptions interface {
+	ShaperSchedule() shaper.Schedule
+}
+
+// scheduledBandwidth returns the bandwidth limit in Mbps effective at the given time, it is advertised as the expected quality.
+// Unlimited bandwidth is zero, which leaves the bandwidth out of the proposal.
+func scheduledBandwidth(options scheduledOptions, at time.Time) float64 {
+	return float64(options.ShaperSchedule().Limit(at)) * 8 * 1024 / 1e6
+}
+
+func (i *Instance) currentProposal() market.ServiceProposal {
+	if next := i.nextInstance(); next != nil {
+		// Proposal announcement was handed over to the instance which replaced this one.
+		return next.currentProposal()
+	}
+
+	if options, ok := i.Options.(scheduledOptions); ok {
+		i.Proposal.Quality.Bandwidth = scheduledBandwidth(options, time.Now())
+	}
+
+	if i.contacts != nil {
+		// Contact carries NAT type and traversal methods which may change during the service lifetime.
+		i.Proposal.Contacts = market.ContactList{i.contacts.GetContact()}
+	}
+
+	if i.attester != nil {
+		attestation, err := i.attester.Attest(i.ProviderID)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to attest node metadata for proposal")
+		}
+		i.Proposal.Attestation = attestation
+	}
+
 	location, err := i.location.DetectLocation()
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to get current location for proposal, using last known location")
@@ -190,6 +231,9 @@ func (i *Instance) proposalWithCurrentLocation() market.ServiceProposal {
 	}
 
 	i.Proposal.Location = *market.NewLocation(location)
+	if i.pricer != nil {
+		i.Proposal.Price = i.pricer.ProposalPrice(i.Proposal.Location.IPType, i.Proposal.Location.Country, i.Type)
+	}
 
 	return i.Proposal
 }
@@ -202,6 +246,50 @@ func (i *Instance) setState(newState servicestate.State) {
 	i.eventPublisher.Publish(servicestate.AppTopicServiceStatus, i.toEvent())
 }
 
+// handOver transfers proposal announcement to the given instance replacing this one.
+func (i *Instance) handOver(next *Instance) {
+	i.stateLock.Lock()
+	discovery := i.discovery
+	i.discovery = nil
+	i.successor = next
+	i.stateLock.Unlock()
+
+	next.stateLock.Lock()
+	next.discovery = discovery
+	next.stateLock.Unlock()
+}
+
+func (i *Instance) nextInstance() *Instance {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	return i.successor
+}
+
+func (i *Instance) ownDiscovery() Discovery {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	return i.discovery
+}
+
+func (i *Instance) setListener(stop func()) {
+	i.p2pChannelsLock.Lock()
+	defer i.p2pChannelsLock.Unlock()
+
+	i.stopListener = stop
+}
+
+// stopListening stops accepting new p2p channels, already established ones are kept.
+func (i *Instance) stopListening() {
+	i.p2pChannelsLock.Lock()
+	stop := i.stopListener
+	i.stopListener = nil
+	i.p2pChannelsLock.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
 func (i *Instance) addP2PChannel(ch p2p.Channel) {
 	i.p2pChannelsLock.Lock()
 	defer i.p2pChannelsLock.Unlock()
@@ -211,8 +299,8 @@ func (i *Instance) addP2PChannel(ch p2p.Channel) {
 
 func (i *Instance) stop() error {
 	errStop := utils.ErrorCollection{}
-	if i.discovery != nil {
-		i.discovery.Stop()
+	if discovery := i.ownDiscovery(); discovery != nil {
+		discovery.Stop()
 	}
 	if i.service != nil {
 		errStop.Add(i.service.Stop())
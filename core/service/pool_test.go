@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,7 +54,7 @@ func (mr *mockService) Stop() error {
 	return mr.killErr
 }
 
-func (mr *mockService) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*ConfigParams, error) {
+func (mr *mockService) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn, _ *zerolog.Logger) (*ConfigParams, error) {
 	return &ConfigParams{}, nil
 }
 
@@ -37,6 +37,12 @@ func (registry *Registry) Register(serviceType string, creator RegistryFactory)
 	registry.factories[serviceType] = creator
 }
 
+// Supports checks whether the given service type is registered
+func (registry *Registry) Supports(serviceType string) bool {
+	_, exists := registry.factories[serviceType]
+	return exists
+}
+
 // Create creates pluggable service
 func (registry *Registry) Create(serviceType string, options Options) (Service, error) {
 	createService, exists := registry.factories[serviceType]
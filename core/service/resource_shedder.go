@@ -0,0 +1,284 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+// ErrResourcePressure is returned when the provider does not accept new sessions because its resources are exhausted.
+var ErrResourcePressure = errors.New("provider is under resource pressure")
+
+// resourcePressureHysteresis is how many percent below its threshold every resource must drop before shedding stops,
+// so the provider does not flap between accepting and rejecting sessions.
+const resourcePressureHysteresis = 5
+
+// Names of the monitored resources.
+const (
+	ResourceCPU             = "cpu"
+	ResourceMemory          = "memory"
+	ResourceConntrack       = "conntrack"
+	ResourceFileDescriptors = "file_descriptors"
+)
+
+type resourceSampler interface {
+	Sample() ResourceUsage
+}
+
+type sheddableSessions interface {
+	GetAll() []*Session
+}
+
+// ResourceShedderConfig describes when the provider sheds load and how.
+type ResourceShedderConfig struct {
+	// Thresholds are the usage percentages above which new sessions are rejected, zero disables monitoring of the resource.
+	Thresholds ResourceUsage
+	Interval   time.Duration
+	// DeprioritizeBandwidth limits the bandwidth of the lowest-paying sessions in Kbytes while shedding, zero disables it.
+	DeprioritizeBandwidth uint64
+	// DeprioritizePercent is the part of the sessions, the lowest-paying first, which are deprioritized while shedding.
+	DeprioritizePercent int
+}
+
+// ResourceShedder stops admitting new sessions while the system resources are exhausted and optionally
+// throttles the lowest-paying sessions until the pressure is relieved.
+type ResourceShedder struct {
+	admission sessionAdmitter
+	sessions  sheddableSessions
+	sampler   resourceSampler
+	publisher publisher
+	config    ResourceShedderConfig
+
+	lock          sync.Mutex
+	shedding      bool
+	usage         ResourceUsage
+	exceeded      []string
+	deprioritized map[session.ID]*Session
+	rejected      uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewResourceShedder returns a new resource shedder admitting the sessions through the given admission while the resources last.
+func NewResourceShedder(admission sessionAdmitter, sessions sheddableSessions, sampler resourceSampler, publisher publisher, config ResourceShedderConfig) *ResourceShedder {
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	return &ResourceShedder{
+		admission:     admission,
+		sessions:      sessions,
+		sampler:       sampler,
+		publisher:     publisher,
+		config:        config,
+		deprioritized: make(map[session.ID]*Session),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Enabled reports whether any resource is monitored.
+func (rs *ResourceShedder) Enabled() bool {
+	return rs.config.Thresholds != ResourceUsage{}
+}
+
+// Admit rejects the session while the provider sheds load, otherwise passes it to the underlying admission.
+func (rs *ResourceShedder) Admit() (release func(), err error) {
+	rs.lock.Lock()
+	if rs.shedding {
+		rs.rejected++
+		e := rs.eventLocked()
+		rs.lock.Unlock()
+		rs.publisher.Publish(event.AppTopicResourcePressure, e)
+		return nil, ErrResourcePressure
+	}
+	rs.lock.Unlock()
+
+	return rs.admission.Admit()
+}
+
+// Start starts monitoring the resources periodically.
+func (rs *ResourceShedder) Start() {
+	if !rs.Enabled() {
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case <-rs.stop:
+				return
+			case <-time.After(rs.config.Interval):
+				rs.check()
+			}
+		}
+	}()
+}
+
+// Stop stops the monitoring.
+func (rs *ResourceShedder) Stop() {
+	rs.stopOnce.Do(func() {
+		close(rs.stop)
+	})
+}
+
+// Pressure returns the last observed resource pressure.
+func (rs *ResourceShedder) Pressure() event.AppEventResourcePressure {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	return rs.eventLocked()
+}
+
+func (rs *ResourceShedder) check() {
+	usage := rs.sampler.Sample()
+	exceeded := rs.exceededResources(usage, 0)
+
+	rs.lock.Lock()
+	changed := false
+	rs.usage = usage
+	switch {
+	case !rs.shedding && len(exceeded) > 0:
+		log.Warn().Msgf("Resources exhausted (%s), not accepting new sessions", strings.Join(exceeded, ", "))
+		rs.shedding = true
+		changed = true
+	case rs.shedding && len(rs.exceededResources(usage, resourcePressureHysteresis)) == 0:
+		log.Info().Msg("Resource pressure relieved, accepting new sessions")
+		rs.shedding = false
+		changed = true
+	}
+	if strings.Join(exceeded, ",") != strings.Join(rs.exceeded, ",") {
+		changed = true
+	}
+	rs.exceeded = exceeded
+	if rs.deprioritizeLocked() {
+		changed = true
+	}
+	e := rs.eventLocked()
+	rs.lock.Unlock()
+
+	if changed {
+		rs.publisher.Publish(event.AppTopicResourcePressure, e)
+	}
+}
+
+// exceededResources returns the resources used above their thresholds lowered by the given margin.
+func (rs *ResourceShedder) exceededResources(usage ResourceUsage, margin float64) []string {
+	var exceeded []string
+	check := func(name string, used, threshold float64) {
+		if threshold > 0 && used > threshold-margin {
+			exceeded = append(exceeded, name)
+		}
+	}
+	check(ResourceCPU, usage.CPU, rs.config.Thresholds.CPU)
+	check(ResourceMemory, usage.Memory, rs.config.Thresholds.Memory)
+	check(ResourceConntrack, usage.Conntrack, rs.config.Thresholds.Conntrack)
+	check(ResourceFileDescriptors, usage.FileDescriptors, rs.config.Thresholds.FileDescriptors)
+	return exceeded
+}
+
+// deprioritizeLocked throttles the lowest-paying sessions while shedding and lifts the throttling otherwise.
+// It reports whether the set of deprioritized sessions changed.
+func (rs *ResourceShedder) deprioritizeLocked() bool {
+	target := make(map[session.ID]*Session)
+	if rs.shedding && rs.config.DeprioritizeBandwidth > 0 && rs.config.DeprioritizePercent > 0 {
+		sessions := rs.sessions.GetAll()
+		sort.SliceStable(sessions, func(i, j int) bool {
+			return lowerPrice(sessions[i], sessions[j])
+		})
+		count := (len(sessions)*rs.config.DeprioritizePercent + 99) / 100
+		if count > len(sessions) {
+			count = len(sessions)
+		}
+		for _, sess := range sessions[:count] {
+			target[sess.ID] = sess
+		}
+	}
+
+	changed := false
+	for id, sess := range rs.deprioritized {
+		if _, ok := target[id]; ok {
+			continue
+		}
+		select {
+		case <-sess.Done():
+		default:
+			sess.setThrottle(0)
+		}
+		delete(rs.deprioritized, id)
+		changed = true
+	}
+	for id, sess := range target {
+		if _, ok := rs.deprioritized[id]; ok {
+			continue
+		}
+		if !sess.setThrottle(rs.config.DeprioritizeBandwidth) {
+			continue
+		}
+		sess.Logger().Info().Msgf("Session %s deprioritized because of resource pressure", id)
+		rs.deprioritized[id] = sess
+		changed = true
+	}
+	return changed
+}
+
+// lowerPrice orders the sessions by the price per GiB and then by the price per hour the consumer pays.
+func lowerPrice(a, b *Session) bool {
+	if c := compareAmounts(a.price.PricePerGiB, b.price.PricePerGiB); c != 0 {
+		return c < 0
+	}
+	return compareAmounts(a.price.PricePerHour, b.price.PricePerHour) < 0
+}
+
+func compareAmounts(a, b *big.Int) int {
+	if a == nil {
+		a = big.NewInt(0)
+	}
+	if b == nil {
+		b = big.NewInt(0)
+	}
+	return a.Cmp(b)
+}
+
+func (rs *ResourceShedder) eventLocked() event.AppEventResourcePressure {
+	deprioritized := make([]string, 0, len(rs.deprioritized))
+	for id := range rs.deprioritized {
+		deprioritized = append(deprioritized, string(id))
+	}
+	sort.Strings(deprioritized)
+
+	return event.AppEventResourcePressure{
+		Shedding:        rs.shedding,
+		CPU:             rs.usage.CPU,
+		Memory:          rs.usage.Memory,
+		Conntrack:       rs.usage.Conntrack,
+		FileDescriptors: rs.usage.FileDescriptors,
+		Exceeded:        append([]string(nil), rs.exceeded...),
+		Deprioritized:   deprioritized,
+		Rejected:        rs.rejected,
+	}
+}
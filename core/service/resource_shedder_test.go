@@ -0,0 +1,179 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+type mockResourceSampler struct {
+	usage ResourceUsage
+}
+
+func (m *mockResourceSampler) Sample() ResourceUsage {
+	return m.usage
+}
+
+// mockSessionCapper caps the session limiters the same way the services shaping the session tunnels do.
+type mockSessionCapper struct {
+	lock       sync.Mutex
+	limits     map[string]*shaper.CapLimiter
+	unenforced bool
+}
+
+func (m *mockSessionCapper) CanCapBandwidth() bool {
+	return !m.unenforced
+}
+
+func (m *mockSessionCapper) CapSessionBandwidth(sessionID string, bandwidth uint64) {
+	m.limiter(sessionID).SetCap(bandwidth)
+}
+
+func (m *mockSessionCapper) limiter(sessionID string) *shaper.CapLimiter {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	limiter, ok := m.limits[sessionID]
+	if !ok {
+		limiter = shaper.NewCapLimiter(shaper.Schedule{{Bandwidth: 5000}}, 0)
+		m.limits[sessionID] = limiter
+	}
+	return limiter
+}
+
+type mockShapedService struct {
+	mockService
+	*mockSessionCapper
+}
+
+// rate returns the bandwidth the session tunnel is shaped to.
+func (m *mockSessionCapper) rate(sessionID session.ID) uint64 {
+	return m.limiter(string(sessionID)).Limit(time.Now())
+}
+
+func TestResourceShedder_RejectsSessionsUnderPressure(t *testing.T) {
+	bus := mocks.NewEventBus()
+	sampler := &mockResourceSampler{usage: ResourceUsage{CPU: 95, Memory: 99}}
+	shedder := NewResourceShedder(NewSessionAdmission(bus, 0, 0, 0), NewSessionPool(bus), sampler, bus, ResourceShedderConfig{
+		Thresholds: ResourceUsage{CPU: 90},
+	})
+	assert.True(t, shedder.Enabled())
+
+	_, err := shedder.Admit()
+	assert.NoError(t, err)
+
+	shedder.check()
+	assert.Equal(t, event.AppEventResourcePressure{
+		Shedding:      true,
+		CPU:           95,
+		Memory:        99,
+		Exceeded:      []string{ResourceCPU},
+		Deprioritized: []string{},
+	}, bus.Pop())
+
+	_, err = shedder.Admit()
+	assert.Equal(t, ErrResourcePressure, err)
+	assert.Equal(t, uint64(1), shedder.Pressure().Rejected)
+
+	// Shedding continues until the usage drops well below the threshold.
+	sampler.usage.CPU = 87
+	shedder.check()
+	assert.True(t, shedder.Pressure().Shedding)
+	_, err = shedder.Admit()
+	assert.Equal(t, ErrResourcePressure, err)
+
+	sampler.usage.CPU = 80
+	shedder.check()
+	assert.False(t, shedder.Pressure().Shedding)
+	_, err = shedder.Admit()
+	assert.NoError(t, err)
+}
+
+func TestResourceShedder_DeprioritizesLowestPayingSessions(t *testing.T) {
+	bus := mocks.NewEventBus()
+	pool := NewSessionPool(bus)
+	capper := &mockSessionCapper{limits: make(map[string]*shaper.CapLimiter)}
+	service := &Instance{service: &mockShapedService{mockSessionCapper: capper}}
+
+	newSession := func(perGiB int64, bandwidth uint64) *Session {
+		sess, err := NewSession(service, &pb.SessionRequest{}, trace.NewTracer(""))
+		assert.NoError(t, err)
+		sess.price = market.Price{PricePerHour: big.NewInt(0), PricePerGiB: big.NewInt(perGiB)}
+		sess.bandwidth = bandwidth
+		capper.CapSessionBandwidth(string(sess.ID), bandwidth)
+		pool.Add(sess)
+		return sess
+	}
+	cheapest, cheap, expensive := newSession(1, 0), newSession(2, 50), newSession(3, 0)
+
+	sampler := &mockResourceSampler{usage: ResourceUsage{Conntrack: 100}}
+	shedder := NewResourceShedder(NewSessionAdmission(bus, 0, 0, 0), pool, sampler, bus, ResourceShedderConfig{
+		Thresholds:            ResourceUsage{Conntrack: 90},
+		DeprioritizeBandwidth: 100,
+		DeprioritizePercent:   50,
+	})
+
+	shedder.check()
+	assert.Equal(t, uint64(100), capper.rate(cheapest.ID))
+	assert.Equal(t, uint64(50), capper.rate(cheap.ID))
+	assert.Equal(t, uint64(5000), capper.rate(expensive.ID))
+	assert.ElementsMatch(t, []string{string(cheapest.ID), string(cheap.ID)}, shedder.Pressure().Deprioritized)
+	assert.NotContains(t, shedder.Pressure().Deprioritized, string(expensive.ID))
+
+	sampler.usage.Conntrack = 10
+	shedder.check()
+	assert.Equal(t, uint64(5000), capper.rate(cheapest.ID))
+	assert.Equal(t, uint64(50), capper.rate(cheap.ID))
+	assert.Empty(t, shedder.Pressure().Deprioritized)
+}
+
+func TestResourceShedder_SkipsSessionsWhichCanNotBeThrottled(t *testing.T) {
+	bus := mocks.NewEventBus()
+	pool := NewSessionPool(bus)
+	capper := &mockSessionCapper{limits: make(map[string]*shaper.CapLimiter), unenforced: true}
+	service := &Instance{service: &mockShapedService{mockSessionCapper: capper}}
+
+	sess, err := NewSession(service, &pb.SessionRequest{}, trace.NewTracer(""))
+	assert.NoError(t, err)
+	sess.price = market.Price{PricePerHour: big.NewInt(0), PricePerGiB: big.NewInt(1)}
+	pool.Add(sess)
+
+	sampler := &mockResourceSampler{usage: ResourceUsage{Conntrack: 100}}
+	shedder := NewResourceShedder(NewSessionAdmission(bus, 0, 0, 0), pool, sampler, bus, ResourceShedderConfig{
+		Thresholds:            ResourceUsage{Conntrack: 90},
+		DeprioritizeBandwidth: 100,
+		DeprioritizePercent:   100,
+	})
+
+	shedder.check()
+	assert.Empty(t, shedder.Pressure().Deprioritized)
+	assert.Equal(t, uint64(5000), capper.rate(sess.ID))
+}
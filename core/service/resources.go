@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/shirou/gopsutil/cpu"
+	"github.com/shirou/gopsutil/mem"
+	"github.com/shirou/gopsutil/net"
+	"github.com/shirou/gopsutil/process"
+)
+
+// ResourceUsage describes the usage of the system resources in percent, zero if the resource is not monitored.
+type ResourceUsage struct {
+	CPU             float64
+	Memory          float64
+	Conntrack       float64
+	FileDescriptors float64
+}
+
+// SystemResources samples the resource usage of the host and the node process.
+// Resources which can not be sampled on the platform are reported as unused.
+type SystemResources struct{}
+
+// Sample returns the current resource usage.
+// CPU usage is measured since the previous sample.
+func (SystemResources) Sample() ResourceUsage {
+	var usage ResourceUsage
+
+	if percent, err := cpu.Percent(0, false); err != nil || len(percent) == 0 {
+		log.Trace().Err(err).Msg("Could not sample CPU usage")
+	} else {
+		usage.CPU = percent[0]
+	}
+
+	if vm, err := mem.VirtualMemory(); err != nil {
+		log.Trace().Err(err).Msg("Could not sample memory usage")
+	} else {
+		usage.Memory = vm.UsedPercent
+	}
+
+	if counters, err := net.FilterCounters(); err != nil || len(counters) == 0 {
+		log.Trace().Err(err).Msg("Could not sample conntrack usage")
+	} else {
+		usage.Conntrack = percentOf(counters[0].ConnTrackCount, counters[0].ConnTrackMax)
+	}
+
+	if fds, limit, err := fileDescriptors(); err != nil {
+		log.Trace().Err(err).Msg("Could not sample file descriptor usage")
+	} else {
+		usage.FileDescriptors = percentOf(fds, limit)
+	}
+
+	return usage
+}
+
+// fileDescriptors returns the number of open file descriptors of the node process and their soft limit.
+func fileDescriptors() (open, limit int64, err error) {
+	p, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, 0, err
+	}
+	fds, err := p.NumFDs()
+	if err != nil {
+		return 0, 0, err
+	}
+	limits, err := p.Rlimit()
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, l := range limits {
+		if l.Resource == process.RLIMIT_NOFILE {
+			return int64(fds), int64(l.Soft), nil
+		}
+	}
+	return int64(fds), 0, nil
+}
+
+func percentOf(value, max int64) float64 {
+	if max <= 0 {
+		return 0
+	}
+	return float64(value) * 100 / float64(max)
+}
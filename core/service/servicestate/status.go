@@ -17,6 +17,10 @@
 
 package servicestate
 
+import (
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
 const (
 	// AppTopicServiceStatus is used in event bus to announce the service status.
 	AppTopicServiceStatus = "Service status"
@@ -40,4 +44,10 @@ const (
 	Starting = State("Starting")
 	// Running means that fully established service exists
 	Running = State("Running")
+	// Draining means that service was replaced and only serves its remaining sessions
+	Draining = State("Draining")
 )
+
+func init() {
+	eventbus.RegisterSchema(AppTopicServiceStatus, 1, AppEventServiceStatus{})
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package servicetest provides in-memory fakes of the provider service and its discovery,
+// so that the service lifecycle can be exercised in tests without running a real service.
+package servicetest
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+var _ service.Service = &Service{}
+
+// Service is a fake service which serves until stopped.
+type Service struct {
+	// ServeErr is returned by Serve when set, without waiting for Stop.
+	ServeErr error
+	// Config is returned by ProvideConfig.
+	Config service.ConfigParams
+
+	once sync.Once
+	done chan struct{}
+}
+
+// NewService returns a new fake service.
+func NewService() *Service {
+	return &Service{
+		done: make(chan struct{}),
+	}
+}
+
+// Serve blocks until the service is stopped.
+func (s *Service) Serve(instance *service.Instance) error {
+	if s.ServeErr != nil {
+		return s.ServeErr
+	}
+
+	<-s.done
+	return nil
+}
+
+// Stop stops the service.
+func (s *Service) Stop() error {
+	s.once.Do(func() {
+		close(s.done)
+	})
+	return nil
+}
+
+// ProvideConfig returns the configured session config.
+func (s *Service) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn, _ *zerolog.Logger) (*service.ConfigParams, error) {
+	config := s.Config
+	return &config, nil
+}
+
+var _ service.Discovery = &Discovery{}
+
+// Discovery is a fake discovery which keeps the announced proposal for inspection.
+type Discovery struct {
+	lock     sync.Mutex
+	wg       sync.WaitGroup
+	identity identity.Identity
+	proposal func() market.ServiceProposal
+	running  bool
+}
+
+// Factory returns discovery factory which always returns this fake.
+func (d *Discovery) Factory() service.DiscoveryFactory {
+	return func() service.Discovery {
+		return d
+	}
+}
+
+// Start starts announcing the proposal.
+func (d *Discovery) Start(ownIdentity identity.Identity, proposal func() market.ServiceProposal) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.wg.Add(1)
+	d.identity = ownIdentity
+	d.proposal = proposal
+	d.running = true
+}
+
+// Stop stops announcing the proposal.
+func (d *Discovery) Stop() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if !d.running {
+		return
+	}
+	d.running = false
+	d.wg.Done()
+}
+
+// Wait waits until discovery is stopped.
+func (d *Discovery) Wait() {
+	d.wg.Wait()
+}
+
+// Running reports whether discovery is announcing.
+func (d *Discovery) Running() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.running
+}
+
+// Proposal returns the currently announced proposal.
+func (d *Discovery) Proposal() (market.ServiceProposal, bool) {
+	d.lock.Lock()
+	proposal := d.proposal
+	d.lock.Unlock()
+
+	if proposal == nil {
+		return market.ServiceProposal{}, false
+	}
+	return proposal(), true
+}
@@ -23,10 +23,12 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gofrs/uuid"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/event"
@@ -43,11 +45,27 @@ type Session struct {
 	ServiceID        string
 	CreatedAt        time.Time
 	request          *pb.SessionRequest
+	channel          p2p.ChannelSender
 	done             chan struct{}
 	cleanupLock      sync.Mutex
 	cleanup          []func() error
 	tracer           *trace.Tracer
 	once             sync.Once
+	logger           *zerolog.Logger
+
+	// price is the price the consumer pays for the session, including its bandwidth tier.
+	price market.Price
+	// bandwidth is the limit of the session bandwidth tier in Kbytes, zero if session is not limited.
+	bandwidth uint64
+	// throttle is the bandwidth limit in Kbytes imposed while the provider sheds load, zero if session is not throttled.
+	throttle  uint64
+	capper    BandwidthCapper
+	pauseLock sync.Mutex
+	paused    bool
+	pausedAt  time.Time
+	// pauseTimer resumes the session once it stays paused for the maximum pause duration.
+	pauseTimer *time.Timer
+	payments   pausablePaymentEngine
 }
 
 // Close ends session.
@@ -59,16 +77,62 @@ func (s *Session) Close() {
 		defer s.cleanupLock.Unlock()
 
 		for i := len(s.cleanup) - 1; i >= 0; i-- {
-			log.Trace().Msgf("Session cleaning up: (%v/%v)", i+1, len(s.cleanup))
+			s.Logger().Trace().Msgf("Session cleaning up: (%v/%v)", i+1, len(s.cleanup))
 			err := s.cleanup[i]()
 			if err != nil {
-				log.Warn().Err(err).Msg("Cleanup error")
+				s.Logger().Warn().Err(err).Msg("Cleanup error")
 			}
 		}
 		s.cleanup = nil
 	})
 }
 
+// Logger returns the logger annotating messages with the session identifiers.
+func (s *Session) Logger() *zerolog.Logger {
+	if s.logger == nil {
+		return &log.Logger
+	}
+	return s.logger
+}
+
+// bandwidthLimit returns the bandwidth limit of the session in Kbytes, the stricter of its tier and throttling,
+// zero means unlimited. Must be called with the pause lock held.
+func (s *Session) bandwidthLimit() uint64 {
+	if s.throttle == 0 || (s.bandwidth != 0 && s.bandwidth < s.throttle) {
+		return s.bandwidth
+	}
+	return s.throttle
+}
+
+// setThrottle limits the session bandwidth in Kbytes while the provider sheds load, zero lifts the limit.
+// Paused sessions keep their stricter limit, throttling applies once they are resumed.
+func (s *Session) setThrottle(bandwidth uint64) bool {
+	if s.capper == nil {
+		return false
+	}
+
+	s.pauseLock.Lock()
+	defer s.pauseLock.Unlock()
+
+	s.throttle = bandwidth
+	if !s.paused {
+		s.capper.CapSessionBandwidth(string(s.ID), s.bandwidthLimit())
+	}
+	return true
+}
+
+func (s *Session) isPaused() bool {
+	_, paused := s.pausedSince()
+	return paused
+}
+
+// pausedSince returns the time the session was paused at, if it is paused.
+func (s *Session) pausedSince() (time.Time, bool) {
+	s.pauseLock.Lock()
+	defer s.pauseLock.Unlock()
+	return s.pausedAt, s.paused
+}
+
 // Done returns readonly done channel.
 func (s *Session) Done() <-chan struct{} {
 	return s.done
@@ -84,7 +148,7 @@ func (s *Session) addCleanup(fn func() error) {
 	case <-s.done:
 		err := fn()
 		if err != nil {
-			log.Warn().Err(err).Msg("Cleanup error")
+			s.Logger().Warn().Err(err).Msg("Cleanup error")
 		}
 	default:
 		s.cleanup = append(s.cleanup, fn)
@@ -120,9 +184,14 @@ func NewSession(service *Instance, request *pb.SessionRequest, tracer *trace.Tra
 		consumerLocation.Country = location.GetCountry()
 	}
 
+	id := session.ID(uid.String())
+	consumerID := identity.FromAddress(request.GetConsumer().GetId())
+	logger := session.NewLogger(id, consumerID.Address, service.Type)
+	capper, _ := bandwidthCapper(service)
+
 	return &Session{
-		ID:               session.ID(uid.String()),
-		ConsumerID:       identity.FromAddress(request.GetConsumer().GetId()),
+		ID:               id,
+		ConsumerID:       consumerID,
 		ConsumerLocation: consumerLocation,
 		HermesID:         common.HexToAddress(request.GetConsumer().GetHermesID()),
 		Proposal:         service.Proposal,
@@ -132,5 +201,7 @@ func NewSession(service *Instance, request *pb.SessionRequest, tracer *trace.Tra
 		done:             make(chan struct{}),
 		cleanup:          make([]func() error, 0),
 		tracer:           tracer,
+		logger:           &logger,
+		capper:           capper,
 	}, nil
 }
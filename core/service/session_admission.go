@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+// ErrSessionLimitReached is returned when all session slots are taken and the session request could not be queued.
+var ErrSessionLimitReached = errors.New("provider session limit reached")
+
+// SessionAdmission bounds the number of concurrent sessions across all services.
+// Session requests above the limit wait in a short queue for a slot to be released.
+type SessionAdmission struct {
+	limit        int
+	queueSize    int
+	queueTimeout time.Duration
+	publisher    publisher
+	slots        chan struct{}
+
+	lock     sync.Mutex
+	active   int
+	queued   int
+	rejected uint64
+}
+
+// NewSessionAdmission returns a new session admission, zero limit admits all sessions.
+func NewSessionAdmission(publisher publisher, limit, queueSize int, queueTimeout time.Duration) *SessionAdmission {
+	return &SessionAdmission{
+		limit:        limit,
+		queueSize:    queueSize,
+		queueTimeout: queueTimeout,
+		publisher:    publisher,
+		slots:        make(chan struct{}, limit),
+	}
+}
+
+// Admit takes a session slot, waiting in the queue if all of them are taken.
+// The returned function releases the slot and must be called once the session ends.
+func (sa *SessionAdmission) Admit() (release func(), err error) {
+	if sa.limit <= 0 {
+		return func() {}, nil
+	}
+
+	select {
+	case sa.slots <- struct{}{}:
+		return sa.admitted(false), nil
+	default:
+	}
+
+	sa.lock.Lock()
+	if sa.queued >= sa.queueSize {
+		sa.rejected++
+		sa.lock.Unlock()
+		sa.publish()
+		return nil, ErrSessionLimitReached
+	}
+	sa.queued++
+	sa.lock.Unlock()
+	sa.publish()
+
+	select {
+	case sa.slots <- struct{}{}:
+		return sa.admitted(true), nil
+	case <-time.After(sa.queueTimeout):
+		sa.lock.Lock()
+		sa.queued--
+		sa.rejected++
+		sa.lock.Unlock()
+		sa.publish()
+		return nil, ErrSessionLimitReached
+	}
+}
+
+// Occupancy returns the current state of the session slots.
+func (sa *SessionAdmission) Occupancy() event.AppEventSessionAdmission {
+	sa.lock.Lock()
+	defer sa.lock.Unlock()
+
+	return event.AppEventSessionAdmission{
+		Active:   sa.active,
+		Limit:    sa.limit,
+		Queued:   sa.queued,
+		Rejected: sa.rejected,
+	}
+}
+
+func (sa *SessionAdmission) admitted(fromQueue bool) func() {
+	sa.lock.Lock()
+	sa.active++
+	if fromQueue {
+		sa.queued--
+	}
+	sa.lock.Unlock()
+	sa.publish()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sa.lock.Lock()
+			sa.active--
+			sa.lock.Unlock()
+			<-sa.slots
+			sa.publish()
+		})
+	}
+}
+
+func (sa *SessionAdmission) publish() {
+	sa.publisher.Publish(event.AppTopicSessionAdmission, sa.Occupancy())
+}
@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+func TestSessionAdmission_Unlimited(t *testing.T) {
+	admission := NewSessionAdmission(mocks.NewEventBus(), 0, 0, 0)
+
+	for i := 0; i < 10; i++ {
+		_, err := admission.Admit()
+		assert.NoError(t, err)
+	}
+}
+
+func TestSessionAdmission_RejectsAboveLimit(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	admission := NewSessionAdmission(publisher, 1, 0, time.Second)
+
+	release, err := admission.Admit()
+	assert.NoError(t, err)
+	assert.Equal(t, event.AppEventSessionAdmission{Active: 1, Limit: 1}, admission.Occupancy())
+
+	_, err = admission.Admit()
+	assert.Equal(t, ErrSessionLimitReached, err)
+	assert.Equal(t, event.AppEventSessionAdmission{Active: 1, Limit: 1, Rejected: 1}, publisher.Pop())
+
+	release()
+	release()
+	assert.Equal(t, event.AppEventSessionAdmission{Limit: 1, Rejected: 1}, admission.Occupancy())
+
+	_, err = admission.Admit()
+	assert.NoError(t, err)
+}
+
+func TestSessionAdmission_QueuesUntilReleased(t *testing.T) {
+	admission := NewSessionAdmission(mocks.NewEventBus(), 1, 1, time.Second)
+
+	release, err := admission.Admit()
+	assert.NoError(t, err)
+
+	admitted := make(chan error)
+	go func() {
+		_, err := admission.Admit()
+		admitted <- err
+	}()
+	assert.Eventually(t, func() bool {
+		return admission.Occupancy().Queued == 1
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = admission.Admit()
+	assert.Equal(t, ErrSessionLimitReached, err)
+
+	release()
+	assert.NoError(t, <-admitted)
+	assert.Equal(t, event.AppEventSessionAdmission{Active: 1, Limit: 1, Rejected: 1}, admission.Occupancy())
+}
+
+func TestSessionAdmission_RejectsAfterQueueTimeout(t *testing.T) {
+	admission := NewSessionAdmission(mocks.NewEventBus(), 1, 1, 10*time.Millisecond)
+
+	_, err := admission.Admit()
+	assert.NoError(t, err)
+
+	_, err = admission.Admit()
+	assert.Equal(t, ErrSessionLimitReached, err)
+	assert.Equal(t, event.AppEventSessionAdmission{Active: 1, Limit: 1, Rejected: 1}, admission.Occupancy())
+}
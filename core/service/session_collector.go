@@ -0,0 +1,212 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+// collectableSessions is the session storage swept by the session collector.
+type collectableSessions interface {
+	GetAll() []*Session
+	Terminate(id session.ID, reason session.TerminationReason, message string) error
+}
+
+// sessionActivity is the last observed activity of the session.
+type sessionActivity struct {
+	at                    time.Time
+	up, down              uint64
+	lastExchangeMessageAt time.Time
+}
+
+// SessionCollector terminates provider sessions which neither transfer data nor pay for too long.
+// Termination closes the session, so the service frees its ports and peers, and the reason is recorded in the session history.
+type SessionCollector struct {
+	sessions  collectableSessions
+	threshold time.Duration
+	maxPause  time.Duration
+	interval  time.Duration
+	now       func() time.Time
+
+	lock     sync.Mutex
+	activity map[session.ID]sessionActivity
+	// removed keeps the removal time of the sessions, so that their late asynchronous events do not track them again.
+	removed map[session.ID]time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewSessionCollector returns a new session collector, zero threshold disables the collection.
+// Paused sessions are not collected until they stay paused for the maximum pause duration, zero spares them until they are resumed.
+func NewSessionCollector(sessions collectableSessions, threshold, maxPause time.Duration) *SessionCollector {
+	return &SessionCollector{
+		sessions:  sessions,
+		threshold: threshold,
+		maxPause:  maxPause,
+		interval:  threshold / 4,
+		now:       time.Now,
+		activity:  make(map[session.ID]sessionActivity),
+		removed:   make(map[session.ID]time.Time),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Subscribe subscribes the collector to the session activity events.
+func (sc *SessionCollector) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(event.AppTopicDataTransferred, sc.consumeDataTransferredEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(event.AppTopicSessionPayment, sc.consumeSessionPaymentEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(event.AppTopicSession, sc.consumeSessionEvent)
+}
+
+// Start starts collecting stale sessions periodically.
+func (sc *SessionCollector) Start() {
+	if sc.threshold <= 0 {
+		return
+	}
+
+	go func() {
+		for {
+			select {
+			case <-sc.stop:
+				return
+			case <-time.After(sc.interval):
+				sc.collect()
+			}
+		}
+	}()
+}
+
+// Stop stops the collection.
+func (sc *SessionCollector) Stop() {
+	sc.stopOnce.Do(func() {
+		close(sc.stop)
+	})
+}
+
+func (sc *SessionCollector) collect() {
+	now := sc.now()
+	sc.forgetRemoved(now)
+	for _, sess := range sc.sessions.GetAll() {
+		lastActive := sc.lastActive(sess)
+		if pausedAt, paused := sess.pausedSince(); paused {
+			// Paused session is idle on purpose, so the idle time is counted only once the pause runs out.
+			if sc.maxPause <= 0 {
+				continue
+			}
+			if pauseEnd := pausedAt.Add(sc.maxPause); pauseEnd.After(lastActive) {
+				lastActive = pauseEnd
+			}
+		}
+
+		if now.Sub(lastActive) <= sc.threshold {
+			continue
+		}
+
+		message := fmt.Sprintf("no data transfer nor payments since %s", lastActive.UTC().Format(time.RFC3339))
+		sess.Logger().Warn().Msgf("Terminating stale session %s: %s", sess.ID, message)
+		if err := sc.sessions.Terminate(sess.ID, session.TerminationReasonInactive, message); err != nil {
+			sess.Logger().Warn().Err(err).Msgf("Could not terminate stale session %s", sess.ID)
+		}
+	}
+}
+
+// forgetRemoved drops the removed sessions once their late events are no longer expected.
+func (sc *SessionCollector) forgetRemoved(now time.Time) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	for id, removedAt := range sc.removed {
+		if now.Sub(removedAt) > sc.threshold {
+			delete(sc.removed, id)
+		}
+	}
+}
+
+// lastActive returns the time of the last session activity, sessions without any activity are active since their creation.
+func (sc *SessionCollector) lastActive(sess *Session) time.Time {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	if activity, ok := sc.activity[sess.ID]; ok && activity.at.After(sess.CreatedAt) {
+		return activity.at
+	}
+	return sess.CreatedAt
+}
+
+func (sc *SessionCollector) consumeDataTransferredEvent(e event.AppEventDataTransferred) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	id := session.ID(e.ID)
+	if _, ok := sc.removed[id]; ok {
+		return
+	}
+	activity := sc.activity[id]
+	if activity.at.IsZero() || e.Up != activity.up || e.Down != activity.down {
+		activity.at = sc.now()
+	}
+	activity.up, activity.down = e.Up, e.Down
+	sc.activity[id] = activity
+}
+
+func (sc *SessionCollector) consumeSessionPaymentEvent(e event.AppEventSessionPayment) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	id := session.ID(e.SessionID)
+	if _, ok := sc.removed[id]; ok {
+		return
+	}
+	activity := sc.activity[id]
+	if e.LastExchangeMessageAt.After(activity.lastExchangeMessageAt) {
+		activity.at = sc.now()
+		activity.lastExchangeMessageAt = e.LastExchangeMessageAt
+	}
+	sc.activity[id] = activity
+}
+
+func (sc *SessionCollector) consumeSessionEvent(e event.AppEventSession) {
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+
+	id := session.ID(e.Session.ID)
+	if _, ok := sc.removed[id]; ok {
+		return
+	}
+	switch e.Status {
+	case event.RemovedStatus:
+		delete(sc.activity, id)
+		sc.removed[id] = sc.now()
+	case event.ResumedStatus:
+		// Paused session is neither billed nor passing traffic, so it is stale only if it stays idle after resuming.
+		activity := sc.activity[id]
+		activity.at = sc.now()
+		sc.activity[id] = activity
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/trace"
+)
+
+func TestSessionCollector_TerminatesStaleSessions(t *testing.T) {
+	now := time.Now()
+	bus := mocks.NewEventBus()
+	pool := NewSessionPool(bus)
+
+	newSession := func() *Session {
+		sess, err := NewSession(&Instance{}, &pb.SessionRequest{}, trace.NewTracer(""))
+		assert.NoError(t, err)
+		sess.CreatedAt = now.Add(-time.Hour)
+		pool.Add(sess)
+		return sess
+	}
+	transferring, paying, paused, stale, overPaused := newSession(), newSession(), newSession(), newSession(), newSession()
+	paused.paused = true
+	paused.pausedAt = now.Add(-10 * time.Minute)
+	overPaused.paused = true
+	overPaused.pausedAt = now.Add(-50 * time.Minute)
+
+	collector := NewSessionCollector(pool, 30*time.Minute, 15*time.Minute)
+	collector.now = func() time.Time { return now }
+	collector.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: string(transferring.ID), Up: 1, Down: 2})
+	collector.consumeSessionPaymentEvent(sessionEvent.AppEventSessionPayment{SessionID: string(paying.ID), LastExchangeMessageAt: now})
+
+	collector.now = func() time.Time { return now.Add(20 * time.Minute) }
+	collector.collect()
+
+	assertDone := func(sess *Session, expected bool) {
+		select {
+		case <-sess.Done():
+			assert.True(t, expected, "session %s should not be terminated", sess.ID)
+		default:
+			assert.False(t, expected, "session %s should be terminated", sess.ID)
+		}
+	}
+	assertDone(transferring, false)
+	assertDone(paying, false)
+	assertDone(paused, false)
+	assertDone(stale, true)
+	assertDone(overPaused, true)
+
+	var terminatedIDs []string
+	for _, e := range bus.GetEventHistory() {
+		if terminated, ok := e.Event.(sessionEvent.AppEventSessionTerminated); ok {
+			assert.Equal(t, session.TerminationReasonInactive, terminated.Reason)
+			terminatedIDs = append(terminatedIDs, terminated.ID)
+		}
+	}
+	assert.ElementsMatch(t, []string{string(stale.ID), string(overPaused.ID)}, terminatedIDs)
+
+	// Unchanged counters are not an activity.
+	collector.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: string(transferring.ID), Up: 1, Down: 2})
+	collector.now = func() time.Time { return now.Add(40 * time.Minute) }
+	collector.collect()
+
+	assertDone(transferring, true)
+	assertDone(paying, true)
+	// Pause ran out 35 minutes ago.
+	assertDone(paused, true)
+
+	// Without the maximum pause duration paused sessions are never collected.
+	pausedForever := newSession()
+	pausedForever.paused = true
+	pausedForever.pausedAt = now.Add(-time.Hour)
+	collector.maxPause = 0
+	collector.collect()
+	assertDone(pausedForever, false)
+}
+
+func TestSessionCollector_IgnoresEventsOfRemovedSessions(t *testing.T) {
+	now := time.Now()
+	collector := NewSessionCollector(NewSessionPool(mocks.NewEventBus()), 30*time.Minute, 0)
+	collector.now = func() time.Time { return now }
+
+	id := "removed"
+	collector.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: id, Up: 1, Down: 2})
+	collector.consumeSessionEvent(sessionEvent.AppEventSession{
+		Status:  sessionEvent.RemovedStatus,
+		Session: sessionEvent.SessionContext{ID: id},
+	})
+	assert.Empty(t, collector.activity)
+
+	// Late asynchronous events of the removed session.
+	collector.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: id, Up: 3, Down: 4})
+	collector.consumeSessionPaymentEvent(sessionEvent.AppEventSessionPayment{SessionID: id, LastExchangeMessageAt: now})
+	collector.consumeSessionEvent(sessionEvent.AppEventSession{
+		Status:  sessionEvent.ResumedStatus,
+		Session: sessionEvent.SessionContext{ID: id},
+	})
+	assert.Empty(t, collector.activity)
+
+	// Removed sessions are forgotten once their late events are no longer expected.
+	collector.now = func() time.Time { return now.Add(time.Hour) }
+	collector.collect()
+	assert.Empty(t, collector.removed)
+}
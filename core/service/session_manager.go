@@ -27,10 +27,12 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/core/supervision"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat/event"
@@ -49,8 +51,19 @@ var (
 	ErrorSessionNotExists = errors.New("session does not exists")
 	// ErrorWrongSessionOwner returned when consumer tries to destroy session that does not belongs to him
 	ErrorWrongSessionOwner = errors.New("wrong session owner")
+	// ErrorUnknownBandwidthTier returned when consumer asks for bandwidth tier not advertised in proposal
+	ErrorUnknownBandwidthTier = errors.New("unknown bandwidth tier")
+	// ErrorBandwidthTierRequired returned when consumer does not choose any of the bandwidth tiers advertised in proposal
+	ErrorBandwidthTierRequired = errors.New("bandwidth tier is required")
+	// ErrorBandwidthTierUnsupported returned when service is not able to enforce bandwidth tier limits
+	ErrorBandwidthTierUnsupported = errors.New("bandwidth tiers are not supported by service")
+	// ErrorSessionPauseUnsupported returned when consumer tries to pause session which service is not able to throttle
+	ErrorSessionPauseUnsupported = errors.New("session pause is not supported by service")
 )
 
+// pausedSessionBandwidth is the bandwidth in Kbytes left for the paused sessions, just enough to keep the tunnel alive.
+const pausedSessionBandwidth = 1
+
 // IDGenerator defines method for session id generation
 type IDGenerator func() (session.ID, error)
 
@@ -70,30 +83,57 @@ type publisher interface {
 
 // KeepAliveConfig contains keep alive options.
 type KeepAliveConfig struct {
-	SendInterval    time.Duration
-	SendTimeout     time.Duration
-	MaxSendErrCount int
+	SendInterval time.Duration
+	SendTimeout  time.Duration
+	DeadPeer     p2p.DeadPeerConfig
 }
 
 // Config contains common configuration options for session manager.
 type Config struct {
-	KeepAlive KeepAliveConfig
+	KeepAlive    KeepAliveConfig
+	ChargePeriod ChargePeriodConfig
+	Firewall     FirewallConfig
+	// MaxPauseDuration is how long consumer may keep the session paused before its billing resumes, zero means no limit.
+	MaxPauseDuration time.Duration
 }
 
 // DefaultConfig returns default params.
 func DefaultConfig() Config {
 	return Config{
 		KeepAlive: KeepAliveConfig{
-			SendInterval:    14 * time.Second,
-			SendTimeout:     5 * time.Second,
-			MaxSendErrCount: 5,
+			SendInterval: 14 * time.Second,
+			SendTimeout:  5 * time.Second,
+			DeadPeer: p2p.DeadPeerConfig{
+				MaxConsecutiveLoss: 5,
+				Window:             10,
+			},
 		},
+		MaxPauseDuration: 30 * time.Minute,
 	}
 }
 
 // ConfigProvider is able to handle config negotiations
 type ConfigProvider interface {
-	ProvideConfig(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn) (*ConfigParams, error)
+	// ProvideConfig negotiates the session config, logger is annotated with the session identifiers.
+	ProvideConfig(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn, logger *zerolog.Logger) (*ConfigParams, error)
+}
+
+// BandwidthCapper is implemented by services able to limit bandwidth of a single session.
+type BandwidthCapper interface {
+	// CanCapBandwidth reports whether the limits are enforced on this platform.
+	CanCapBandwidth() bool
+	// CapSessionBandwidth limits bandwidth in Kbytes of the session with the given ID, zero removes the limit.
+	// The limit applies to the already running session too.
+	CapSessionBandwidth(sessionID string, bandwidth uint64)
+}
+
+// bandwidthCapper returns the service bandwidth capper if the service is able to enforce the limits.
+func bandwidthCapper(service *Instance) (BandwidthCapper, bool) {
+	capper, ok := service.Service().(BandwidthCapper)
+	if !ok || !capper.CanCapBandwidth() {
+		return nil, false
+	}
+	return capper, true
 }
 
 // DestroyCallback cleanups session
@@ -108,7 +148,9 @@ type PromiseProcessor interface {
 }
 
 // PaymentEngineFactory creates a new instance of payment engine
-type PaymentEngineFactory func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, price market.Price) (PaymentEngine, error)
+// Zero charge period leaves it to the payment engine.
+// Logger is annotated with the session identifiers.
+type PaymentEngineFactory func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, price market.Price, chargePeriod time.Duration, logger *zerolog.Logger) (PaymentEngine, error)
 
 // PriceValidator allows to validate prices against those in discovery.
 type PriceValidator interface {
@@ -122,6 +164,33 @@ type PaymentEngine interface {
 	Stop()
 }
 
+// pausablePaymentEngine is implemented by payment engines able to stop billing while consumer has paused the session.
+type pausablePaymentEngine interface {
+	Pause()
+	Resume()
+}
+
+// stuckPaymentEngine is implemented by payment engines which report being stuck instead of returning from Start.
+type stuckPaymentEngine interface {
+	Stuck() <-chan error
+}
+
+type sessionAdmitter interface {
+	Admit() (release func(), err error)
+}
+
+type consumerAttester interface {
+	Attest(consumerID identity.Identity, serviceType, token string) error
+}
+
+type consumerLocator interface {
+	LocateIP(ip string) (locationstate.Location, error)
+}
+
+type hermesAcceptor interface {
+	AcceptsHermes(chainID int64, providerID identity.Identity, hermesID common.Address) bool
+}
+
 // NATEventGetter lets us access the last known traversal event
 type NATEventGetter interface {
 	LastEvent() *event.Event
@@ -136,6 +205,10 @@ func NewSessionManager(
 	channel p2p.Channel,
 	config Config,
 	priceValidator PriceValidator,
+	admission sessionAdmitter,
+	attester consumerAttester,
+	locator consumerLocator,
+	hermes hermesAcceptor,
 ) *SessionManager {
 	return &SessionManager{
 		service:              service,
@@ -146,6 +219,10 @@ func NewSessionManager(
 		channel:              channel,
 		config:               config,
 		priceValidator:       priceValidator,
+		admission:            admission,
+		attester:             attester,
+		locator:              locator,
+		hermes:               hermes,
 	}
 }
 
@@ -159,6 +236,10 @@ type SessionManager struct {
 	channel              p2p.Channel
 	config               Config
 	priceValidator       PriceValidator
+	admission            sessionAdmitter
+	attester             consumerAttester
+	locator              consumerLocator
+	hermes               hermesAcceptor
 }
 
 // Start starts a session on the provider side for the given consumer.
@@ -175,37 +256,128 @@ func (manager *SessionManager) Start(request *pb.SessionRequest) (_ pb.SessionRe
 	if rt.Incr(chID) != nil {
 		return pb.SessionResponse{}, fmt.Errorf("unable to hold the channel: %w", err)
 	}
-	log.Info().Msgf("session ref incr for %q", chID)
+	session.Logger().Info().Msgf("session ref incr for %q", chID)
 
 	session.addCleanup(func() error {
-		log.Info().Msgf("session ref decr for %q", chID)
+		session.Logger().Info().Msgf("session ref decr for %q", chID)
 		return rt.Decr(chID)
 	})
 
 	defer func() {
 		if err != nil {
-			log.Err(err).Msg("Session failed, disconnecting")
+			session.Logger().Err(err).Msg("Session failed, disconnecting")
 			session.Close()
 		}
 	}()
 
+	release, err := manager.admission.Admit()
+	if err != nil {
+		return pb.SessionResponse{}, err
+	}
+	session.addCleanup(func() error {
+		release()
+		return nil
+	})
+
 	trace := session.tracer.StartStage("Provider session create")
 	defer func() {
 		session.tracer.EndStage(trace)
 		traceResult := session.tracer.Finish(manager.publisher, string(session.ID))
-		log.Debug().Msgf("Provider connection trace: %s", traceResult)
+		session.Logger().Debug().Msgf("Provider connection trace: %s", traceResult)
 	}()
 
 	prices := manager.remapPricing(request.Consumer.Pricing)
+	requestedChargePeriod := time.Duration(request.Consumer.GetChargePeriod()) * time.Second
+	chargePeriod := manager.config.ChargePeriod.Negotiate(manager.service.Type, requestedChargePeriod)
+
+	tier, err := manager.bandwidthTier(request.Consumer.GetBandwidthTier())
+	if err != nil {
+		return pb.SessionResponse{}, err
+	}
+
+	// Discovery validates the proposal price, invoices follow the price of the selected tier.
+	session.price = prices
+	if tier != nil {
+		session.price = tier.Price(prices)
+	}
 
 	if err = manager.startSession(session, prices); err != nil {
 		return pb.SessionResponse{}, err
 	}
-	if err = manager.paymentLoop(session, prices); err != nil {
+
+	if tier != nil {
+		manager.capSessionBandwidth(session, *tier)
+	}
+	if err = manager.paymentLoop(session, session.price, chargePeriod); err != nil {
 		return pb.SessionResponse{}, err
 	}
 
-	return manager.providerService(session, manager.channel)
+	manager.allowSessionNetworks(session)
+	return manager.providerService(session, manager.channel, chargePeriod)
+}
+
+// bandwidthTier looks up the tier requested by consumer, nil means the proposal offers no tiers.
+func (manager *SessionManager) bandwidthTier(name string) (*market.BandwidthTier, error) {
+	if name == "" {
+		if len(manager.service.Proposal.BandwidthTiers) > 0 {
+			return nil, ErrorBandwidthTierRequired
+		}
+		return nil, nil
+	}
+
+	tier, ok := manager.service.Proposal.BandwidthTier(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrorUnknownBandwidthTier, name)
+	}
+
+	if tier.Bandwidth > 0 {
+		if _, ok := bandwidthCapper(manager.service); !ok {
+			return nil, ErrorBandwidthTierUnsupported
+		}
+	}
+
+	return &tier, nil
+}
+
+func (manager *SessionManager) capSessionBandwidth(session *Session, tier market.BandwidthTier) {
+	if tier.Bandwidth == 0 {
+		return
+	}
+
+	capper, ok := bandwidthCapper(manager.service)
+	if !ok {
+		return
+	}
+
+	session.Logger().Info().Msgf("Session %s uses bandwidth tier %s", session.ID, tier)
+	session.pauseLock.Lock()
+	session.bandwidth = tier.Bandwidth
+	capper.CapSessionBandwidth(string(session.ID), session.bandwidthLimit())
+	session.pauseLock.Unlock()
+	session.addCleanup(func() error {
+		capper.CapSessionBandwidth(string(session.ID), 0)
+		return nil
+	})
+}
+
+// allowSessionNetworks applies the firewall exemptions of the session, they take effect once the data plane comes up.
+func (manager *SessionManager) allowSessionNetworks(session *Session) {
+	networks := manager.config.Firewall.SessionNetworks(session.ConsumerID)
+	if len(networks) == 0 {
+		return
+	}
+
+	firewall, ok := manager.service.Service().(SessionFirewall)
+	if !ok {
+		return
+	}
+
+	session.Logger().Info().Msgf("Session %s is allowed to reach protected networks %v", session.ID, networks)
+	firewall.AllowSessionNetworks(string(session.ID), networks)
+	session.addCleanup(func() error {
+		firewall.AllowSessionNetworks(string(session.ID), nil)
+		return nil
+	})
 }
 
 func (manager *SessionManager) validatePrice(in market.Price, nodeType, country, serviceType string) error {
@@ -255,6 +427,7 @@ func (manager *SessionManager) startSession(session *Session, prices market.Pric
 
 	manager.clearStaleSession(session.ConsumerID, manager.service.Type)
 
+	session.channel = manager.channel
 	manager.sessionStorage.Add(session)
 	session.addCleanup(func() error {
 		manager.sessionStorage.Remove(session.ID)
@@ -271,9 +444,43 @@ func (manager *SessionManager) validateSession(session *Session, prices market.P
 		return fmt.Errorf("consumer identity is not allowed: %s", session.ConsumerID.Address)
 	}
 
+	if err := manager.attester.Attest(session.ConsumerID, manager.service.Type, session.request.GetConsumer().GetAttestation()); err != nil {
+		return fmt.Errorf("consumer attestation failed: %w", err)
+	}
+
+	if err := manager.validateConsumerCountry(); err != nil {
+		return err
+	}
+
+	if manager.hermes != nil && !manager.hermes.AcceptsHermes(config.GetInt64(config.FlagChainID), manager.service.ProviderID, session.HermesID) {
+		return fmt.Errorf("hermes is not accepted: %s", session.HermesID.Hex())
+	}
+
 	return manager.validatePrice(prices, manager.service.Proposal.Location.IPType, manager.service.Proposal.Location.Country, manager.service.Proposal.ServiceType)
 }
 
+func (manager *SessionManager) validateConsumerCountry() error {
+	countries := manager.service.Proposal.ConsumerCountries()
+	if len(countries) == 0 {
+		return nil
+	}
+
+	addr := manager.channel.PeerAddr()
+	if addr == nil || manager.locator == nil {
+		return errors.New("consumer country can not be resolved")
+	}
+
+	location, err := manager.locator.LocateIP(addr.IP.String())
+	if err != nil {
+		return fmt.Errorf("consumer country can not be resolved: %w", err)
+	}
+	if !manager.service.Proposal.AllowsConsumerCountry(location.Country) {
+		return fmt.Errorf("consumer country is not allowed: %s", location.Country)
+	}
+
+	return nil
+}
+
 func (manager *SessionManager) clearStaleSession(consumerID identity.Identity, serviceType string) {
 	// Reading stale session before starting the clean up in goroutine.
 	// This is required to make sure we are not cleaning the newly created session.
@@ -284,7 +491,7 @@ func (manager *SessionManager) clearStaleSession(consumerID identity.Identity, s
 		if serviceType != session.Proposal.ServiceType {
 			continue
 		}
-		log.Info().Msgf("Cleaning stale session %s for %s consumer", session.ID, consumerID.Address)
+		session.Logger().Info().Msgf("Cleaning stale session %s for %s consumer", session.ID, consumerID.Address)
 		go session.Close()
 	}
 }
@@ -303,33 +510,180 @@ func (manager *SessionManager) Destroy(consumerID identity.Identity, sessionID s
 	return nil
 }
 
-func (manager *SessionManager) paymentLoop(session *Session, price market.Price) error {
-	trace := session.tracer.StartStage("Provider session create (payment)")
-	defer session.tracer.EndStage(trace)
+// Pause stops billing of the session and throttles its traffic until consumer resumes it.
+func (manager *SessionManager) Pause(consumerID identity.Identity, sessionID string) error {
+	sess, err := manager.ownedSession(consumerID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	capper, ok := bandwidthCapper(manager.service)
+	if !ok {
+		return ErrorSessionPauseUnsupported
+	}
+
+	sess.pauseLock.Lock()
+	defer sess.pauseLock.Unlock()
+
+	if sess.paused {
+		return nil
+	}
+	if sess.payments == nil {
+		return ErrorSessionPauseUnsupported
+	}
+
+	capper.CapSessionBandwidth(sessionID, pausedSessionBandwidth)
+	sess.payments.Pause()
+	sess.paused = true
+	sess.pausedAt = time.Now()
+	if manager.config.MaxPauseDuration > 0 {
+		sess.pauseTimer = time.AfterFunc(manager.config.MaxPauseDuration, func() {
+			manager.resumeExpired(sess)
+		})
+	}
+
+	sess.Logger().Info().Msgf("Session %s paused by consumer", sessionID)
+	manager.publisher.Publish(sevent.AppTopicSession, sess.toEvent(sevent.PausedStatus))
+	return nil
+}
+
+// Resume continues billing of the paused session and lifts the traffic throttling.
+func (manager *SessionManager) Resume(consumerID identity.Identity, sessionID string) error {
+	sess, err := manager.ownedSession(consumerID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	sess.pauseLock.Lock()
+	defer sess.pauseLock.Unlock()
+
+	if manager.resume(sess) {
+		sess.Logger().Info().Msgf("Session %s resumed by consumer", sessionID)
+	}
+	return nil
+}
+
+// resumeExpired resumes the session which stayed paused longer than allowed.
+func (manager *SessionManager) resumeExpired(sess *Session) {
+	select {
+	case <-sess.Done():
+		return
+	default:
+	}
+
+	sess.pauseLock.Lock()
+	defer sess.pauseLock.Unlock()
+
+	if manager.resume(sess) {
+		sess.Logger().Info().Msgf("Session %s resumed after staying paused for %s", sess.ID, manager.config.MaxPauseDuration)
+		go manager.notifyResumed(sess)
+	}
+}
 
-	log.Info().Msg("Using new payments")
+// notifyResumed tells the consumer that the provider resumed its paused session.
+func (manager *SessionManager) notifyResumed(sess *Session) {
+	if sess.channel == nil {
+		return
+	}
+
+	msg := &pb.SessionInfo{
+		ConsumerID: sess.ConsumerID.Address,
+		SessionID:  string(sess.ID),
+	}
+	sess.Logger().Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionResume, msg.String())
+	ctx, cancel := context.WithTimeout(context.Background(), terminateNotifyTimeout)
+	defer cancel()
+	if _, err := sess.channel.Send(ctx, p2p.TopicSessionResume, p2p.ProtoMessage(msg)); err != nil {
+		sess.Logger().Warn().Err(err).Msgf("Failed to notify consumer about session %s resume", sess.ID)
+	}
+}
+
+// resume continues billing of the paused session, must be called with the pause lock held.
+func (manager *SessionManager) resume(sess *Session) bool {
+	if !sess.paused {
+		return false
+	}
+	if sess.pauseTimer != nil {
+		sess.pauseTimer.Stop()
+		sess.pauseTimer = nil
+	}
+
+	// Billing resumes before the throttling is lifted, so no traffic goes unbilled.
+	sess.payments.Resume()
+	if capper, ok := bandwidthCapper(manager.service); ok {
+		capper.CapSessionBandwidth(string(sess.ID), sess.bandwidthLimit())
+	}
+	sess.paused = false
+
+	manager.publisher.Publish(sevent.AppTopicSession, sess.toEvent(sevent.ResumedStatus))
+	return true
+}
+
+// sessionLogger returns the logger of the given session, or a logger annotated with
+// the requested session identifiers if the session is not known.
+func (manager *SessionManager) sessionLogger(consumerID identity.Identity, sessionID string) *zerolog.Logger {
+	if sess, found := manager.sessionStorage.Find(session.ID(sessionID)); found {
+		return sess.Logger()
+	}
+	logger := session.NewLogger(session.ID(sessionID), consumerID.Address, manager.service.Type)
+	return &logger
+}
+
+func (manager *SessionManager) ownedSession(consumerID identity.Identity, sessionID string) (*Session, error) {
+	sess, found := manager.sessionStorage.Find(session.ID(sessionID))
+	if !found {
+		return nil, ErrorSessionNotExists
+	}
+	if sess.ConsumerID != consumerID {
+		return nil, ErrorWrongSessionOwner
+	}
+	return sess, nil
+}
+
+func (manager *SessionManager) paymentLoop(sess *Session, price market.Price, chargePeriod time.Duration) error {
+	trace := sess.tracer.StartStage("Provider session create (payment)")
+	defer sess.tracer.EndStage(trace)
+
+	sess.Logger().Info().Msg("Using new payments")
 
 	chainID := config.GetInt64(config.FlagChainID)
-	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, session.ConsumerID, chainID, session.HermesID, string(session.ID), manager.paymentEngineChan, price)
+	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, sess.ConsumerID, chainID, sess.HermesID, string(sess.ID), manager.paymentEngineChan, price, chargePeriod, sess.Logger())
 	if err != nil {
 		return err
 	}
 
 	// stop the balance tracker once the session is finished
-	session.addCleanup(func() error {
+	sess.addCleanup(func() error {
 		engine.Stop()
 		return nil
 	})
 
+	if pausable, ok := engine.(pausablePaymentEngine); ok {
+		sess.pauseLock.Lock()
+		sess.payments = pausable
+		sess.pauseLock.Unlock()
+	}
+
 	go func() {
-		err := engine.Start()
+		err := supervision.Run(engine.Start)
 		if err != nil {
-			log.Error().Err(err).Msg("Payment engine error")
-			session.Close()
+			sess.Logger().Error().Err(err).Msg("Payment engine error")
+			manager.terminate(sess, session.TerminationReasonPaymentFailure, err.Error())
 		}
 	}()
 
-	log.Info().Msg("Waiting for a first invoice to be paid")
+	if stuckEngine, ok := engine.(stuckPaymentEngine); ok {
+		go func() {
+			select {
+			case err := <-stuckEngine.Stuck():
+				sess.Logger().Error().Err(err).Msg("Payment engine is stuck")
+				manager.terminate(sess, session.TerminationReasonPaymentFailure, err.Error())
+			case <-sess.Done():
+			}
+		}()
+	}
+
+	sess.Logger().Info().Msg("Waiting for a first invoice to be paid")
 	if err := engine.WaitFirstInvoice(30 * time.Second); err != nil {
 		return fmt.Errorf("first invoice was not paid: %w", err)
 	}
@@ -337,11 +691,18 @@ func (manager *SessionManager) paymentLoop(session *Session, price market.Price)
 	return nil
 }
 
-func (manager *SessionManager) providerService(session *Session, channel p2p.Channel) (pb.SessionResponse, error) {
+// terminate notifies the consumer about the reason and ends the session.
+func (manager *SessionManager) terminate(sess *Session, reason session.TerminationReason, message string) {
+	if err := manager.sessionStorage.Terminate(sess.ID, reason, message); err != nil {
+		sess.Close()
+	}
+}
+
+func (manager *SessionManager) providerService(session *Session, channel p2p.Channel, chargePeriod time.Duration) (pb.SessionResponse, error) {
 	trace := session.tracer.StartStage("Provider session create (configure)")
 	defer session.tracer.EndStage(trace)
 
-	config, err := manager.service.Service().ProvideConfig(string(session.ID), session.request.GetConfig(), channel.ServiceConn())
+	config, err := manager.service.Service().ProvideConfig(string(session.ID), session.request.GetConfig(), channel.ServiceConn(), session.Logger())
 	if err != nil {
 		return pb.SessionResponse{}, fmt.Errorf("cannot get provider config for session %s: %w", string(session.ID), err)
 	}
@@ -359,9 +720,10 @@ func (manager *SessionManager) providerService(session *Session, channel p2p.Cha
 	}
 
 	return pb.SessionResponse{
-		ID:          string(session.ID),
-		PaymentInfo: "v3",
-		Config:      data,
+		ID:           string(session.ID),
+		PaymentInfo:  "v3",
+		Config:       data,
+		ChargePeriod: uint32(chargePeriod / time.Second),
 	}, nil
 }
 
@@ -373,33 +735,42 @@ func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel)
 			return err
 		}
 
-		log.Debug().Msgf("Received p2p keepalive ping with SessionID=%s from %s", ping.SessionID, c.PeerID().ToCommonAddress())
+		sess.Logger().Debug().Msgf("Received p2p keepalive ping with SessionID=%s from %s", ping.SessionID, c.PeerID().ToCommonAddress())
 		return c.OK()
 	})
 
 	// Send pings to consumer.
-	var errCount int
+	stats := p2p.NewKeepAliveStats(manager.config.KeepAlive.DeadPeer.Window)
 	for {
 		select {
 		case <-sess.Done():
 			return
 		case <-time.After(manager.config.KeepAlive.SendInterval):
-			if err := manager.sendKeepAlivePing(channel, sess.ID); err != nil {
-				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sess.ID)
-				errCount++
-				if errCount == manager.config.KeepAlive.MaxSendErrCount {
-					log.Error().Msgf("Max p2p keepalive err count reached, closing SessionID=%s", sess.ID)
-					sess.Close()
-					return
-				}
+			if rtt, err := manager.sendKeepAlivePing(channel, sess.ID); err != nil {
+				sess.Logger().Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sess.ID)
+				stats.RecordLoss()
 			} else {
-				errCount = 0
+				stats.RecordRTT(rtt)
 			}
+
+			snapshot := stats.Snapshot()
+			if !manager.config.KeepAlive.DeadPeer.Unresponsive(snapshot) {
+				continue
+			}
+
+			sess.Logger().Error().Msgf("Consumer stopped responding to p2p keepalive pings, closing SessionID=%s, stats: %+v", sess.ID, snapshot)
+			manager.publisher.Publish(p2p.AppTopicPeerUnresponsive, p2p.AppEventPeerUnresponsive{
+				SessionID: string(sess.ID),
+				PeerID:    sess.ConsumerID,
+				Stats:     snapshot,
+			})
+			manager.terminate(sess, session.TerminationReasonIdleTimeout, "consumer stopped responding to keep alive pings")
+			return
 		}
 	}
 }
 
-func (manager *SessionManager) sendKeepAlivePing(channel p2p.Channel, sessionID session.ID) error {
+func (manager *SessionManager) sendKeepAlivePing(channel p2p.Channel, sessionID session.ID) (time.Duration, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), manager.config.KeepAlive.SendTimeout)
 	defer cancel()
 	msg := &pb.P2PKeepAlivePing{
@@ -408,10 +779,11 @@ func (manager *SessionManager) sendKeepAlivePing(channel p2p.Channel, sessionID
 
 	start := time.Now()
 	_, err := channel.Send(ctx, p2p.TopicKeepAlive, p2p.ProtoMessage(msg))
+	rtt := time.Since(start)
 	manager.publisher.Publish(quality.AppTopicProviderPingP2P, quality.PingEvent{
 		SessionID: string(sessionID),
-		Duration:  time.Since(start),
+		Duration:  rtt,
 	})
 
-	return err
+	return rtt, err
 }
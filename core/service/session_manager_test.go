@@ -27,10 +27,13 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
@@ -75,8 +78,18 @@ func (m mockBalanceTracker) WaitFirstInvoice(time.Duration) error {
 	return m.firstPaymentError
 }
 
+// mockPanickingEngine panics once started, after the first invoice is paid.
+type mockPanickingEngine struct {
+	mockBalanceTracker
+}
+
+func (m mockPanickingEngine) Start() error {
+	panic("payment engine bug")
+}
+
 type mockP2PChannel struct {
-	tracer *trace.Tracer
+	tracer   *trace.Tracer
+	peerAddr *net.UDPAddr
 }
 
 func (m *mockP2PChannel) Send(_ context.Context, _ string, _ *p2p.Message) (*p2p.Message, error) {
@@ -94,8 +107,12 @@ func (m *mockP2PChannel) ServiceConn() *net.UDPConn { return nil }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn { return nil }
 
+func (m *mockP2PChannel) PeerAddr() *net.UDPAddr { return m.peerAddr }
+
 func (m *mockP2PChannel) Close() error { return nil }
 
+func (m *mockP2PChannel) Codec() dto.Codec { return dto.CodecFor(dto.EncodingProtobuf) }
+
 func (m *mockP2PChannel) ID() string { return fmt.Sprintf("%p", m) }
 
 func TestManager_Start_StoresSession(t *testing.T) {
@@ -121,7 +138,7 @@ func TestManager_Start_StoresSession(t *testing.T) {
 
 	assert.Eventually(t, func() bool {
 		history := publisher.GetEventHistory()
-		if len(history) != 6 {
+		if len(history) != 7 {
 			return false
 		}
 
@@ -152,6 +169,11 @@ func TestManager_Start_StoresSession(t *testing.T) {
 		traceEvent5 := history[5].Event.(trace.Event)
 		assert.Equal(t, "Provider session create (configure)", traceEvent5.Key)
 
+		assert.Equal(t, trace.AppTopicTraceFinished, history[6].Topic)
+		finishedEvent := history[6].Event.(trace.FinishedEvent)
+		assert.Equal(t, string(session.ID), finishedEvent.ID)
+		assert.Len(t, finishedEvent.Spans, 5)
+
 		return true
 	}, 2*time.Second, 10*time.Millisecond)
 }
@@ -177,7 +199,7 @@ func TestManager_Start_DisconnectsOnPaymentError(t *testing.T) {
 	assert.EqualError(t, err, "first invoice was not paid: sorry, your money ended")
 	assert.Eventually(t, func() bool {
 		history := publisher.GetEventHistory()
-		if len(history) != 6 {
+		if len(history) != 7 {
 			return false
 		}
 
@@ -204,8 +226,12 @@ func TestManager_Start_DisconnectsOnPaymentError(t *testing.T) {
 		traceEvent4 := history[4].Event.(trace.Event)
 		assert.Equal(t, "Provider session create (payment)", traceEvent4.Key)
 
-		assert.Equal(t, sessionEvent.AppTopicSession, history[5].Topic)
-		closeEvent := history[5].Event.(sessionEvent.AppEventSession)
+		assert.Equal(t, trace.AppTopicTraceFinished, history[5].Topic)
+		finishedEvent := history[5].Event.(trace.FinishedEvent)
+		assert.Len(t, finishedEvent.Spans, 4)
+
+		assert.Equal(t, sessionEvent.AppTopicSession, history[6].Topic)
+		closeEvent := history[6].Event.(sessionEvent.AppEventSession)
 		assert.Equal(t, sessionEvent.RemovedStatus, closeEvent.Status)
 		assert.Equal(t, consumerID, closeEvent.Session.ConsumerID)
 		assert.Equal(t, hermesID, closeEvent.Session.HermesID)
@@ -215,6 +241,28 @@ func TestManager_Start_DisconnectsOnPaymentError(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func TestManager_Start_StopsSessionOnPaymentEnginePanic(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(currentService, sessionStore, publisher, &mockPanickingEngine{}, true)
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(1).Bytes(),
+				PerHour: big.NewInt(1).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(sessionStore.GetAll()) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 func TestManager_Start_Second_Session_Destroy_Stale_Session(t *testing.T) {
 	sessionRequest := &pb.SessionRequest{
 		Consumer: &pb.ConsumerInfo{
@@ -311,7 +359,7 @@ func newManager(service *Instance, sessions *SessionPool, publisher publisher, p
 	m := NewSessionManager(
 		service,
 		sessions,
-		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage, price market.Price) (PaymentEngine, error) {
+		func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage, price market.Price, _ time.Duration, _ *zerolog.Logger) (PaymentEngine, error) {
 			return paymentEngine, nil
 		},
 		publisher,
@@ -320,6 +368,10 @@ func newManager(service *Instance, sessions *SessionPool, publisher publisher, p
 		&mockPriceValidator{
 			toReturn: isPriceValid,
 		},
+		NewSessionAdmission(publisher, 0, 0, 0),
+		&mockAttester{},
+		nil,
+		nil,
 	)
 	reftracker.Singleton().Put("channel:"+ch.ID(), 10*time.Second, func() { ch.Close() })
 	return m
@@ -345,6 +397,336 @@ func TestManager_Start_RejectsInvalidPricing(t *testing.T) {
 	assert.Equal(t, "consumer asking for invalid price", err.Error())
 }
 
+func TestManager_Start_RejectsNotAcceptedHermes(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	manager := newManager(currentService, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	manager.hermes = &mockHermesAcceptor{accepted: common.HexToAddress("0x999")}
+
+	request := &pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	}
+	_, err := manager.Start(request)
+	assert.EqualError(t, err, "hermes is not accepted: "+hermesID.Hex())
+
+	manager.hermes = &mockHermesAcceptor{accepted: hermesID}
+	_, err = manager.Start(request)
+	assert.NoError(t, err)
+}
+
+func TestManager_Start_RejectsFailedAttestation(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{}, true)
+	attester := &mockAttester{err: errors.New("token rejected")}
+	manager.attester = attester
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:          consumerID.Address,
+			HermesID:    hermesID.String(),
+			Attestation: "token",
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.Error(t, err)
+	assert.Equal(t, "consumer attestation failed: token rejected", err.Error())
+	assert.Equal(t, "token", attester.token)
+	assert.Equal(t, currentService.Type, attester.serviceType)
+}
+
+func TestManager_Start_RejectsDisallowedConsumerCountry(t *testing.T) {
+	proposal := market.NewProposal("0x1", "mockservice", market.NewProposalOpts{
+		AccessPolicies: []market.AccessPolicy{market.NewConsumerCountryPolicy([]string{"de", "NL"})},
+	})
+	request := &pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:       consumerID.Address,
+			HermesID: hermesID.String(),
+			Pricing: &pb.Pricing{
+				PerGib:  big.NewInt(100).Bytes(),
+				PerHour: big.NewInt(10).Bytes(),
+			},
+		},
+		ProposalID: int64(currentProposalID),
+	}
+
+	publisher := mocks.NewEventBus()
+	service := NewInstance(identity.FromAddress(proposal.ProviderID), proposal.ServiceType, struct{}{}, proposal, servicestate.Running, &mockService{}, policy.NewRepository(), &mockDiscovery{})
+
+	manager := newManager(service, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	_, err := manager.Start(request)
+	assert.EqualError(t, err, "consumer country can not be resolved")
+
+	locator := &mockConsumerLocator{country: "US"}
+	manager = newManager(service, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	manager.channel.(*mockP2PChannel).peerAddr = &net.UDPAddr{IP: net.ParseIP("1.2.3.4"), Port: 1234}
+	manager.locator = locator
+	_, err = manager.Start(request)
+	assert.EqualError(t, err, "consumer country is not allowed: US")
+	assert.Equal(t, "1.2.3.4", locator.ip)
+
+	locator.country = "NL"
+	_, err = manager.Start(request)
+	assert.NoError(t, err)
+}
+
+func TestManager_Start_BandwidthTier(t *testing.T) {
+	proposal := market.NewProposal("0x1", "mockservice", market.NewProposalOpts{
+		BandwidthTiers: []market.BandwidthTier{
+			{Name: "basic", Bandwidth: 1250, PricePercent: 50},
+		},
+	})
+	newRequest := func(tier string) *pb.SessionRequest {
+		return &pb.SessionRequest{
+			Consumer: &pb.ConsumerInfo{
+				Id:       consumerID.Address,
+				HermesID: hermesID.String(),
+				Pricing: &pb.Pricing{
+					PerGib:  big.NewInt(100).Bytes(),
+					PerHour: big.NewInt(10).Bytes(),
+				},
+				BandwidthTier: tier,
+			},
+			ProposalID: int64(currentProposalID),
+		}
+	}
+
+	publisher := mocks.NewEventBus()
+	unsupported := NewInstance(identity.FromAddress(proposal.ProviderID), proposal.ServiceType, struct{}{}, proposal, servicestate.Running, &mockService{}, policy.NewRepository(), &mockDiscovery{})
+	manager := newManager(unsupported, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	_, err := manager.Start(newRequest("basic"))
+	assert.ErrorIs(t, err, ErrorBandwidthTierUnsupported)
+
+	unenforced := NewInstance(identity.FromAddress(proposal.ProviderID), proposal.ServiceType, struct{}{}, proposal, servicestate.Running, &mockCappedService{unenforced: true}, policy.NewRepository(), &mockDiscovery{})
+	manager = newManager(unenforced, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	_, err = manager.Start(newRequest("basic"))
+	assert.ErrorIs(t, err, ErrorBandwidthTierUnsupported)
+
+	capper := &mockCappedService{}
+	supported := NewInstance(identity.FromAddress(proposal.ProviderID), proposal.ServiceType, struct{}{}, proposal, servicestate.Running, capper, policy.NewRepository(), &mockDiscovery{})
+	manager = newManager(supported, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	_, err = manager.Start(newRequest("premium"))
+	assert.ErrorIs(t, err, ErrorUnknownBandwidthTier)
+	_, err = manager.Start(newRequest(""))
+	assert.ErrorIs(t, err, ErrorBandwidthTierRequired)
+	assert.Equal(t, uint64(0), capper.bandwidth)
+
+	var agreed market.Price
+	manager.paymentEngineFactory = func(_, _ identity.Identity, _ int64, _ common.Address, _ string, _ chan crypto.ExchangeMessage, price market.Price, _ time.Duration, _ *zerolog.Logger) (PaymentEngine, error) {
+		agreed = price
+		return &mockBalanceTracker{}, nil
+	}
+	_, err = manager.Start(newRequest("basic"))
+	assert.NoError(t, err)
+	assert.Equal(t, market.Price{PricePerHour: big.NewInt(5), PricePerGiB: big.NewInt(50)}, agreed)
+	assert.Equal(t, uint64(1250), capper.bandwidth)
+}
+
+func TestManager_Start_AllowsSessionNetworks(t *testing.T) {
+	proposal := market.NewProposal("0x1", "mockservice", market.NewProposalOpts{})
+	newRequest := func(consumer identity.Identity) *pb.SessionRequest {
+		return &pb.SessionRequest{
+			Consumer: &pb.ConsumerInfo{
+				Id:       consumer.Address,
+				HermesID: hermesID.String(),
+				Pricing: &pb.Pricing{
+					PerGib:  big.NewInt(100).Bytes(),
+					PerHour: big.NewInt(10).Bytes(),
+				},
+			},
+			ProposalID: int64(currentProposalID),
+		}
+	}
+	printer := net.IPNet{IP: net.ParseIP("192.168.1.10").To4(), Mask: net.CIDRMask(32, 32)}
+
+	publisher := mocks.NewEventBus()
+	firewall := &mockFirewalledService{networks: map[string][]net.IPNet{}}
+	service := NewInstance(identity.FromAddress(proposal.ProviderID), proposal.ServiceType, struct{}{}, proposal, servicestate.Running, firewall, policy.NewRepository(), &mockDiscovery{})
+	manager := newManager(service, NewSessionPool(publisher), publisher, &mockBalanceTracker{}, true)
+	manager.config.Firewall.ConsumerAllowedNetworks = map[identity.Identity][]net.IPNet{consumerID: {printer}}
+
+	resp, err := manager.Start(newRequest(identity.FromAddress("0x2")))
+	assert.NoError(t, err)
+	assert.NotContains(t, firewall.networks, resp.ID)
+
+	resp, err = manager.Start(newRequest(consumerID))
+	assert.NoError(t, err)
+	assert.Equal(t, []net.IPNet{printer}, firewall.networks[resp.ID])
+}
+
+func TestManager_PauseAndResumeSession(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	capper := &mockCappedService{}
+	service := NewInstance(identity.FromAddress(currentProposal.ProviderID), currentProposal.ServiceType, struct{}{}, currentProposal, servicestate.Running, capper, policy.NewRepository(), &mockDiscovery{})
+
+	sessionStore := NewSessionPool(publisher)
+	session, _ := NewSession(
+		service,
+		&pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}},
+		trace.NewTracer(""),
+	)
+	session.bandwidth = 1250
+	sessionStore.Add(session)
+
+	manager := newManager(service, sessionStore, publisher, &mockBalanceTracker{}, true)
+
+	err := manager.Pause(consumerID, string(session.ID))
+	assert.ErrorIs(t, err, ErrorSessionPauseUnsupported)
+
+	payments := &mockPausablePayments{}
+	session.payments = payments
+
+	err = manager.Pause(identity.FromAddress("0x1"), string(session.ID))
+	assert.ErrorIs(t, err, ErrorWrongSessionOwner)
+
+	err = manager.Pause(consumerID, string(session.ID))
+	assert.NoError(t, err)
+	assert.True(t, payments.paused)
+	assert.Equal(t, uint64(pausedSessionBandwidth), capper.bandwidth)
+
+	err = manager.Resume(consumerID, string(session.ID))
+	assert.NoError(t, err)
+	assert.False(t, payments.paused)
+	assert.Equal(t, uint64(1250), capper.bandwidth)
+
+	var statuses []sessionEvent.Status
+	for _, v := range publisher.GetEventHistory() {
+		if v.Topic == sessionEvent.AppTopicSession {
+			statuses = append(statuses, v.Event.(sessionEvent.AppEventSession).Status)
+		}
+	}
+	assert.Contains(t, statuses, sessionEvent.PausedStatus)
+	assert.Contains(t, statuses, sessionEvent.ResumedStatus)
+}
+
+func TestManager_ResumesSessionAfterMaxPauseDuration(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	capper := &mockCappedService{}
+	service := NewInstance(identity.FromAddress(currentProposal.ProviderID), currentProposal.ServiceType, struct{}{}, currentProposal, servicestate.Running, capper, policy.NewRepository(), &mockDiscovery{})
+
+	sessionStore := NewSessionPool(publisher)
+	session, _ := NewSession(
+		service,
+		&pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}},
+		trace.NewTracer(""),
+	)
+	payments := &mockPausablePayments{}
+	session.payments = payments
+	channel := &mockChannelSender{}
+	session.channel = channel
+	sessionStore.Add(session)
+
+	manager := newManager(service, sessionStore, publisher, &mockBalanceTracker{}, true)
+	manager.config.MaxPauseDuration = 10 * time.Millisecond
+
+	err := manager.Pause(consumerID, string(session.ID))
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return !session.isPaused()
+	}, time.Second, 5*time.Millisecond)
+	assert.False(t, payments.paused)
+	assert.Eventually(t, func() bool {
+		return channel.sentTopic() == p2p.TopicSessionResume
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestManager_Pause_RefusedWhenBandwidthIsNotEnforced(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	capper := &mockCappedService{unenforced: true}
+	service := NewInstance(identity.FromAddress(currentProposal.ProviderID), currentProposal.ServiceType, struct{}{}, currentProposal, servicestate.Running, capper, policy.NewRepository(), &mockDiscovery{})
+
+	sessionStore := NewSessionPool(publisher)
+	session, _ := NewSession(
+		service,
+		&pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: consumerID.Address}},
+		trace.NewTracer(""),
+	)
+	payments := &mockPausablePayments{}
+	session.payments = payments
+	sessionStore.Add(session)
+
+	manager := newManager(service, sessionStore, publisher, &mockBalanceTracker{}, true)
+
+	err := manager.Pause(consumerID, string(session.ID))
+	assert.ErrorIs(t, err, ErrorSessionPauseUnsupported)
+	assert.False(t, payments.paused)
+	assert.False(t, session.isPaused())
+}
+
+type mockPausablePayments struct {
+	paused bool
+}
+
+func (m *mockPausablePayments) Pause() {
+	m.paused = true
+}
+
+func (m *mockPausablePayments) Resume() {
+	m.paused = false
+}
+
+type mockCappedService struct {
+	mockService
+	bandwidth  uint64
+	unenforced bool
+}
+
+func (m *mockCappedService) CanCapBandwidth() bool {
+	return !m.unenforced
+}
+
+func (m *mockCappedService) CapSessionBandwidth(_ string, bandwidth uint64) {
+	m.bandwidth = bandwidth
+}
+
+type mockFirewalledService struct {
+	mockService
+	networks map[string][]net.IPNet
+}
+
+func (m *mockFirewalledService) AllowSessionNetworks(sessionID string, networks []net.IPNet) {
+	if networks == nil {
+		delete(m.networks, sessionID)
+		return
+	}
+	m.networks[sessionID] = networks
+}
+
+type mockAttester struct {
+	err         error
+	serviceType string
+	token       string
+}
+
+func (ma *mockAttester) Attest(_ identity.Identity, serviceType, token string) error {
+	ma.serviceType = serviceType
+	ma.token = token
+	return ma.err
+}
+
+type mockConsumerLocator struct {
+	country string
+	ip      string
+}
+
+func (ml *mockConsumerLocator) LocateIP(ip string) (locationstate.Location, error) {
+	ml.ip = ip
+	return locationstate.Location{Country: ml.country}, nil
+}
+
+type mockHermesAcceptor struct {
+	accepted common.Address
+}
+
+func (ma *mockHermesAcceptor) AcceptsHermes(_ int64, _ identity.Identity, hermesID common.Address) bool {
+	return hermesID == ma.accepted
+}
+
 type mockPriceValidator struct {
 	toReturn bool
 }
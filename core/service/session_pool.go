@@ -18,13 +18,21 @@
 package service
 
 import (
+	"context"
 	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/event"
 )
 
+const terminateNotifyTimeout = 5 * time.Second
+
 // NewSessionPool initiates new session storage
 func NewSessionPool(publisher publisher) *SessionPool {
 	sm := &SessionPool{
@@ -112,6 +120,38 @@ func (sp *SessionPool) Remove(id session.ID) {
 	}
 }
 
+// Terminate notifies the consumer about the session termination with the given reason and closes the session.
+func (sp *SessionPool) Terminate(id session.ID, reason session.TerminationReason, message string) error {
+	instance, found := sp.Find(id)
+	if !found {
+		return ErrorSessionNotExists
+	}
+
+	sp.publisher.Publish(event.AppTopicSessionTerminated, event.AppEventSessionTerminated{
+		ID:      string(id),
+		Reason:  reason,
+		Message: message,
+	})
+
+	if instance.channel != nil {
+		msg := &pb.SessionTerminate{
+			ConsumerID: instance.ConsumerID.Address,
+			SessionID:  string(id),
+			Reason:     uint32(reason),
+			Message:    message,
+		}
+		log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionTerminate, msg.String())
+		ctx, cancel := context.WithTimeout(context.Background(), terminateNotifyTimeout)
+		defer cancel()
+		if _, err := instance.channel.Send(ctx, p2p.TopicSessionTerminate, p2p.ProtoMessage(msg)); err != nil {
+			log.Warn().Err(err).Msgf("Failed to notify consumer about session %s termination", id)
+		}
+	}
+
+	instance.Close()
+	return nil
+}
+
 // RemoveForService removes all sessions which belong to given service
 func (sp *SessionPool) RemoveForService(serviceID string) {
 	sessions := sp.GetAll()
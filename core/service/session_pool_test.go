@@ -18,11 +18,14 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
@@ -157,6 +160,68 @@ func TestSessionPool_RemoveForService_PublishesEvents(t *testing.T) {
 	assert.Eventually(t, lastEventMatches(mp, sessionExisting.ID, sessionEvent.RemovedStatus), 2*time.Second, 10*time.Millisecond)
 }
 
+func TestSessionPool_Terminate(t *testing.T) {
+	// given
+	sessionInstance, _ := NewSession(&Instance{ID: "1"}, &pb.SessionRequest{Consumer: &pb.ConsumerInfo{Id: "deadbeef"}}, trace.NewTracer(""))
+	channel := &mockChannelSender{}
+	sessionInstance.channel = channel
+	mp := mocks.NewEventBus()
+	pool := mockPool(mp, sessionInstance)
+	sessionInstance.addCleanup(func() error {
+		pool.Remove(sessionInstance.ID)
+		return nil
+	})
+
+	// when
+	err := pool.Terminate(sessionInstance.ID, session.TerminationReasonAbusiveConsumer, "bye")
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, pool.sessions, 0)
+	assert.Equal(t, p2p.TopicSessionTerminate, channel.topic)
+
+	var msg pb.SessionTerminate
+	assert.NoError(t, channel.msg.UnmarshalProto(&msg))
+	assert.Equal(t, string(sessionInstance.ID), msg.SessionID)
+	assert.Equal(t, uint32(session.TerminationReasonAbusiveConsumer), msg.Reason)
+	assert.Equal(t, "bye", msg.Message)
+
+	assert.Equal(t, sessionEvent.AppTopicSessionTerminated, mp.GetEventHistory()[0].Topic)
+	assert.Equal(t, sessionEvent.AppEventSessionTerminated{
+		ID:      string(sessionInstance.ID),
+		Reason:  session.TerminationReasonAbusiveConsumer,
+		Message: "bye",
+	}, mp.GetEventHistory()[0].Event)
+}
+
+func TestSessionPool_Terminate_Unknown(t *testing.T) {
+	pool := mockPool(mocks.NewEventBus(), sessionExisting)
+
+	err := pool.Terminate(session.ID("unknown-id"), session.TerminationReasonUnspecified, "")
+	assert.Equal(t, ErrorSessionNotExists, err)
+	assert.Len(t, pool.sessions, 1)
+}
+
+type mockChannelSender struct {
+	lock  sync.Mutex
+	topic string
+	msg   *p2p.Message
+}
+
+func (m *mockChannelSender) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.topic = topic
+	m.msg = msg
+	return nil, nil
+}
+
+func (m *mockChannelSender) sentTopic() string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.topic
+}
+
 func mockPool(publisher publisher, sessionInstance *Session) *SessionPool {
 	return &SessionPool{
 		sessions:  map[session.ID]*Session{sessionInstance.ID: sessionInstance},
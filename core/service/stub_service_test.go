@@ -22,6 +22,8 @@ import (
 	"net"
 	"sync"
 
+	"github.com/rs/zerolog"
+
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 )
@@ -52,7 +54,7 @@ func (service *serviceFake) GetType() string {
 	return "fake"
 }
 
-func (service *serviceFake) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*ConfigParams, error) {
+func (service *serviceFake) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn, _ *zerolog.Logger) (*ConfigParams, error) {
 	return &ConfigParams{}, nil
 }
 
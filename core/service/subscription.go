@@ -19,38 +19,37 @@ package service
 
 import (
 	"fmt"
-	"math/big"
 	"time"
 
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session/connectivity"
-	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/rs/zerolog/log"
 )
 
-func subscribeSessionCreate(mng *SessionManager, ch p2p.ChannelHandler) {
+func subscribeSessionCreate(mng *SessionManager, ch p2p.ChannelHandler, codec dto.Codec) {
 	ch.Handle(p2p.TopicSessionCreate, func(c p2p.Context) error {
-		var request pb.SessionRequest
-		if err := c.Request().UnmarshalProto(&request); err != nil {
+		var request dto.SessionRequest
+		if err := c.Request().UnmarshalDTO(codec, &request); err != nil {
 			return err
 		}
-		if identity.FromAddress(request.GetConsumer().GetId()) != c.PeerID() {
+		if identity.FromAddress(request.Consumer.ID) != c.PeerID() {
 			return fmt.Errorf("wrong consumer identity in session create request. Expected: %s, got: %s",
 				c.PeerID().ToCommonAddress(),
-				identity.FromAddress(request.GetConsumer().GetId()),
+				identity.FromAddress(request.Consumer.ID),
 			)
 		}
 
-		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionCreate, request.String())
+		log.Debug().Msgf("Received P2P message for %q: %+v", p2p.TopicSessionCreate, request)
 
-		response, err := mng.Start(&request)
+		response, err := mng.Start(request.Proto())
 		if err != nil {
 			return fmt.Errorf("cannot start session: %s: %w", response.ID, err)
 		}
 
-		return c.OkWithReply(p2p.ProtoMessage(&response))
+		return c.OkWithReply(p2p.DTOMessage(codec, dto.NewSessionResponse(&response)))
 	})
 }
 
@@ -95,7 +94,7 @@ func subscribeSessionDestroy(mng *SessionManager, ch p2p.ChannelHandler) {
 			)
 		}
 
-		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionDestroy, si.String())
+		mng.sessionLogger(c.PeerID(), si.GetSessionID()).Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionDestroy, si.String())
 
 		go func() {
 			consumerID := identity.FromAddress(si.GetConsumerID())
@@ -103,7 +102,7 @@ func subscribeSessionDestroy(mng *SessionManager, ch p2p.ChannelHandler) {
 
 			err := mng.Destroy(consumerID, sessionID)
 			if err != nil {
-				log.Err(err).Msgf("Could not destroy session %s: %v", sessionID, err)
+				mng.sessionLogger(consumerID, sessionID).Err(err).Msgf("Could not destroy session %s: %v", sessionID, err)
 			}
 		}()
 
@@ -111,6 +110,50 @@ func subscribeSessionDestroy(mng *SessionManager, ch p2p.ChannelHandler) {
 	})
 }
 
+func subscribeSessionPause(mng *SessionManager, ch p2p.ChannelHandler) {
+	ch.Handle(p2p.TopicSessionPause, func(c p2p.Context) error {
+		var si pb.SessionInfo
+		if err := c.Request().UnmarshalProto(&si); err != nil {
+			return err
+		}
+		if identity.FromAddress(si.GetConsumerID()) != c.PeerID() {
+			return fmt.Errorf("wrong consumer identity in session pause request. Expected: %s, got: %s",
+				c.PeerID().ToCommonAddress(),
+				identity.FromAddress(si.GetConsumerID()),
+			)
+		}
+
+		mng.sessionLogger(c.PeerID(), si.GetSessionID()).Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionPause, si.String())
+		if err := mng.Pause(c.PeerID(), si.GetSessionID()); err != nil {
+			return fmt.Errorf("cannot pause session %s: %w", si.GetSessionID(), err)
+		}
+
+		return c.OK()
+	})
+}
+
+func subscribeSessionResume(mng *SessionManager, ch p2p.ChannelHandler) {
+	ch.Handle(p2p.TopicSessionResume, func(c p2p.Context) error {
+		var si pb.SessionInfo
+		if err := c.Request().UnmarshalProto(&si); err != nil {
+			return err
+		}
+		if identity.FromAddress(si.GetConsumerID()) != c.PeerID() {
+			return fmt.Errorf("wrong consumer identity in session resume request. Expected: %s, got: %s",
+				c.PeerID().ToCommonAddress(),
+				identity.FromAddress(si.GetConsumerID()),
+			)
+		}
+
+		mng.sessionLogger(c.PeerID(), si.GetSessionID()).Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionResume, si.String())
+		if err := mng.Resume(c.PeerID(), si.GetSessionID()); err != nil {
+			return fmt.Errorf("cannot resume session %s: %w", si.GetSessionID(), err)
+		}
+
+		return c.OK()
+	})
+}
+
 func subscribeSessionAcknowledge(mng *SessionManager, ch p2p.ChannelHandler) {
 	ch.Handle(p2p.TopicSessionAcknowledge, func(c p2p.Context) error {
 		var si pb.SessionInfo
@@ -124,7 +167,7 @@ func subscribeSessionAcknowledge(mng *SessionManager, ch p2p.ChannelHandler) {
 			)
 		}
 
-		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionAcknowledge, si.String())
+		mng.sessionLogger(c.PeerID(), si.GetSessionID()).Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionAcknowledge, si.String())
 		consumerID := identity.FromAddress(si.GetConsumerID())
 		sessionID := si.GetSessionID()
 
@@ -137,53 +180,15 @@ func subscribeSessionAcknowledge(mng *SessionManager, ch p2p.ChannelHandler) {
 	})
 }
 
-const bigIntBase int = 10
-
-func subscribeSessionPayments(mng *SessionManager, ch p2p.ChannelHandler) {
+func subscribeSessionPayments(mng *SessionManager, ch p2p.ChannelHandler, codec dto.Codec) {
 	ch.Handle(p2p.TopicPaymentMessage, func(c p2p.Context) error {
-		var msg pb.ExchangeMessage
-		if err := c.Request().UnmarshalProto(&msg); err != nil {
-			return fmt.Errorf("could not unmarshal exchange message proto: %w", err)
-		}
-		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicPaymentMessage, msg.String())
-
-		amount, ok := new(big.Int).SetString(msg.GetPromise().GetAmount(), bigIntBase)
-		if !ok {
-			return fmt.Errorf("could not unmarshal field amount of value %v", amount)
-		}
-
-		fee, ok := new(big.Int).SetString(msg.GetPromise().GetFee(), bigIntBase)
-		if !ok {
-			return fmt.Errorf("could not unmarshal field fee of value %v", fee)
+		var msg dto.ExchangeMessage
+		if err := c.Request().UnmarshalDTO(codec, &msg); err != nil {
+			return fmt.Errorf("could not unmarshal exchange message: %w", err)
 		}
+		log.Debug().Msgf("Received P2P message for %q: %+v", p2p.TopicPaymentMessage, msg)
 
-		agreementID, ok := new(big.Int).SetString(msg.GetAgreementID(), bigIntBase)
-		if !ok {
-			return fmt.Errorf("could not unmarshal field agreementID of value %v", agreementID)
-		}
-
-		agreementTotal, ok := new(big.Int).SetString(msg.GetAgreementTotal(), bigIntBase)
-		if !ok {
-			return fmt.Errorf("could not unmarshal field agreementTotal of value %v", agreementTotal)
-		}
-
-		mng.paymentEngineChan <- crypto.ExchangeMessage{
-			Promise: crypto.Promise{
-				ChannelID: msg.GetPromise().GetChannelID(),
-				Amount:    amount,
-				Fee:       fee,
-				Hashlock:  msg.GetPromise().GetHashlock(),
-				R:         msg.GetPromise().GetR(),
-				Signature: msg.GetPromise().GetSignature(),
-				ChainID:   msg.GetPromise().GetChainID(),
-			},
-			AgreementID:    agreementID,
-			AgreementTotal: agreementTotal,
-			Provider:       msg.GetProvider(),
-			Signature:      msg.GetSignature(),
-			HermesID:       msg.GetHermesID(),
-			ChainID:        msg.GetChainID(),
-		}
+		mng.paymentEngineChan <- msg.Crypto()
 
 		return nil
 	})
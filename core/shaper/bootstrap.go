@@ -29,7 +29,7 @@ type eventListener interface {
 	SubscribeAsync(topic string, fn interface{}) error
 }
 
-// New creates a traffic shaper (linux) or no-op.
-func New(listener eventListener) (shaper Shaper) {
-	return create(listener)
+// New creates a traffic shaper (linux) or no-op. Bandwidth limits follow the given limiter.
+func New(listener eventListener, limiter Limiter) (shaper Shaper) {
+	return create(listener, limiter)
 }
@@ -0,0 +1,350 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/config"
+)
+
+// DefaultShare is the bandwidth share given to a session unless configured otherwise.
+const DefaultShare = 1
+
+// ErrAllocationNotFound is returned when there is no bandwidth allocation for the session.
+var ErrAllocationNotFound = errors.New("bandwidth allocation not found")
+
+// Limiter provides bandwidth limit in Kbytes effective at the given time, zero means unlimited.
+type Limiter interface {
+	Limit(t time.Time) uint64
+}
+
+// LimiterFunc is an adapter to use ordinary functions as limiters.
+type LimiterFunc func(t time.Time) uint64
+
+// Limit returns bandwidth limit effective at the given time.
+func (f LimiterFunc) Limit(t time.Time) uint64 {
+	return f(t)
+}
+
+// CapLimiter restricts the limiter to the bandwidth cap which may change while the limiter is in use.
+type CapLimiter struct {
+	limiter Limiter
+
+	mu        sync.Mutex
+	bandwidth uint64
+	observers []chan struct{}
+}
+
+// NewCapLimiter restricts the limiter to the given bandwidth in Kbytes, zero bandwidth leaves the limiter intact.
+func NewCapLimiter(limiter Limiter, bandwidth uint64) *CapLimiter {
+	return &CapLimiter{
+		limiter:   limiter,
+		bandwidth: bandwidth,
+	}
+}
+
+// SetCap changes the bandwidth cap in Kbytes and notifies the shapers following the limiter, zero removes the cap.
+func (c *CapLimiter) SetCap(bandwidth uint64) {
+	c.mu.Lock()
+	if c.bandwidth == bandwidth {
+		c.mu.Unlock()
+		return
+	}
+	c.bandwidth = bandwidth
+	observers := c.observers
+	c.mu.Unlock()
+
+	for _, ch := range observers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Limit returns bandwidth limit in Kbytes effective at the given time, zero means unlimited.
+func (c *CapLimiter) Limit(t time.Time) uint64 {
+	var limit uint64
+	if c.limiter != nil {
+		limit = c.limiter.Limit(t)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return minLimit(limit, c.bandwidth)
+}
+
+// Changed signals when the cap changes.
+func (c *CapLimiter) Changed() <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	c.observe(ch)
+	return ch
+}
+
+func (c *CapLimiter) observe(ch chan struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.observers = append(c.observers, ch)
+}
+
+// changeObserver is implemented by limiters able to signal their changes to the wrapping limiters.
+type changeObserver interface {
+	observe(ch chan struct{})
+}
+
+// minLimit returns the stricter of two limits, zero means unlimited.
+func minLimit(a, b uint64) uint64 {
+	switch {
+	case a == 0:
+		return b
+	case b == 0:
+		return a
+	case a < b:
+		return a
+	default:
+		return b
+	}
+}
+
+// ConfiguredCapacity returns bandwidth capacity shared between sessions according
+// to the application configuration, zero if fair sharing is disabled.
+// The schedule is validated on startup, if it is changed to an invalid one later the global limit applies.
+func ConfiguredCapacity(t time.Time) uint64 {
+	if !config.GetBool(config.FlagShaperFairShare) {
+		return 0
+	}
+	schedule, err := ConfiguredSchedule()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get bandwidth schedule, applying the global bandwidth limit")
+	}
+	return schedule.Limit(t)
+}
+
+// AllocationInfo describes bandwidth allocation of a session.
+type AllocationInfo struct {
+	// Share is the relative weight of the session.
+	Share uint64
+	// Bandwidth is the currently allocated bandwidth in Kbytes, zero means unlimited.
+	Bandwidth uint64
+}
+
+// FairScheduler divides the provider bandwidth capacity between active sessions
+// proportionally to their shares, so one heavy session can not starve others.
+// Bandwidth a session can not use because of its service limit is divided between the other sessions.
+type FairScheduler struct {
+	capacity Limiter
+
+	mu          sync.Mutex
+	allocations map[string]*Allocation
+}
+
+// NewFairScheduler creates fair scheduler dividing the given bandwidth capacity.
+// Sessions are not limited by the scheduler while the capacity is unlimited.
+func NewFairScheduler(capacity Limiter) *FairScheduler {
+	return &FairScheduler{
+		capacity:    capacity,
+		allocations: make(map[string]*Allocation),
+	}
+}
+
+// Join registers the session with the default share. Allocated bandwidth never
+// exceeds the limit of the service the session belongs to.
+func (fs *FairScheduler) Join(sessionID string, serviceLimit Limiter) *Allocation {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	allocation := &Allocation{
+		scheduler:    fs,
+		sessionID:    sessionID,
+		share:        DefaultShare,
+		serviceLimit: serviceLimit,
+		changed:      make(chan struct{}, 1),
+	}
+	if observer, ok := serviceLimit.(changeObserver); ok {
+		observer.observe(allocation.changed)
+	}
+	fs.allocations[sessionID] = allocation
+	fs.notifyLocked()
+
+	return allocation
+}
+
+// SetShare changes the share of the session and rebalances all allocations.
+func (fs *FairScheduler) SetShare(sessionID string, share uint64) error {
+	if share == 0 {
+		return errors.New("share must be positive")
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	allocation, ok := fs.allocations[sessionID]
+	if !ok {
+		return ErrAllocationNotFound
+	}
+	allocation.share = share
+	fs.notifyLocked()
+
+	return nil
+}
+
+// Allocation returns current bandwidth allocation of the session.
+func (fs *FairScheduler) Allocation(sessionID string) (AllocationInfo, bool) {
+	fs.mu.Lock()
+	allocation, ok := fs.allocations[sessionID]
+	fs.mu.Unlock()
+	if !ok {
+		return AllocationInfo{}, false
+	}
+
+	return AllocationInfo{
+		Share:     allocation.Share(),
+		Bandwidth: allocation.Limit(time.Now()),
+	}, true
+}
+
+func (fs *FairScheduler) leave(sessionID string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	delete(fs.allocations, sessionID)
+	fs.notifyLocked()
+}
+
+func (fs *FairScheduler) notifyLocked() {
+	for _, allocation := range fs.allocations {
+		select {
+		case allocation.changed <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// demand is the share and the service limit of a session, taken outside of the scheduler lock.
+type demand struct {
+	allocation *Allocation
+	share      uint64
+	limit      uint64
+}
+
+// fairShare returns the bandwidth allocated to the given session, zero means unlimited.
+// Capacity is divided by shares, sessions whose service limit is below their part get the service limit
+// and the rest of the capacity is divided again between the remaining sessions.
+func (fs *FairScheduler) fairShare(t time.Time, target *Allocation) uint64 {
+	capacity := fs.capacity.Limit(t)
+	if capacity == 0 {
+		return 0
+	}
+
+	fs.mu.Lock()
+	demands := make([]demand, 0, len(fs.allocations))
+	for _, allocation := range fs.allocations {
+		demands = append(demands, demand{allocation: allocation, share: allocation.share})
+	}
+	fs.mu.Unlock()
+
+	for i := range demands {
+		if demands[i].allocation.serviceLimit != nil {
+			demands[i].limit = demands[i].allocation.serviceLimit.Limit(t)
+		}
+	}
+
+	remaining := capacity
+	for {
+		var total uint64
+		for _, d := range demands {
+			total += d.share
+		}
+		if total == 0 {
+			return remaining
+		}
+
+		available := remaining
+		unconstrained := demands[:0]
+		for _, d := range demands {
+			if d.limit == 0 || d.limit >= available*d.share/total {
+				unconstrained = append(unconstrained, d)
+				continue
+			}
+			if d.allocation == target {
+				return d.limit
+			}
+			remaining -= d.limit
+		}
+
+		if len(unconstrained) == len(demands) {
+			for _, d := range demands {
+				if d.allocation != target {
+					continue
+				}
+				if limit := available * d.share / total; limit > 0 {
+					return limit
+				}
+				return 1
+			}
+			return available
+		}
+		demands = unconstrained
+	}
+}
+
+// Allocation is a bandwidth allocation of a single session.
+type Allocation struct {
+	scheduler    *FairScheduler
+	sessionID    string
+	share        uint64
+	serviceLimit Limiter
+	changed      chan struct{}
+}
+
+// Limit returns bandwidth limit of the session in Kbytes effective at the given time, zero means unlimited.
+func (a *Allocation) Limit(t time.Time) uint64 {
+	fair := a.scheduler.fairShare(t, a)
+
+	var service uint64
+	if a.serviceLimit != nil {
+		service = a.serviceLimit.Limit(t)
+	}
+
+	return minLimit(fair, service)
+}
+
+// Share returns the current share of the session.
+func (a *Allocation) Share() uint64 {
+	a.scheduler.mu.Lock()
+	defer a.scheduler.mu.Unlock()
+
+	return a.share
+}
+
+// Changed signals when the allocation should be re-evaluated.
+func (a *Allocation) Changed() <-chan struct{} {
+	return a.changed
+}
+
+// Leave removes the session from the scheduler and rebalances the remaining allocations.
+func (a *Allocation) Leave() {
+	a.scheduler.leave(a.sessionID)
+}
@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairScheduler_DividesCapacityByShares(t *testing.T) {
+	scheduler := NewFairScheduler(LimiterFunc(func(time.Time) uint64 { return 3000 }))
+	now := time.Now()
+
+	first := scheduler.Join("first", nil)
+	assert.Equal(t, uint64(3000), first.Limit(now))
+
+	second := scheduler.Join("second", nil)
+	assert.Equal(t, uint64(1500), first.Limit(now))
+	assert.Equal(t, uint64(1500), second.Limit(now))
+
+	assert.NoError(t, scheduler.SetShare("first", 2))
+	assert.Equal(t, uint64(2000), first.Limit(now))
+	assert.Equal(t, uint64(1000), second.Limit(now))
+
+	info, ok := scheduler.Allocation("first")
+	assert.True(t, ok)
+	assert.Equal(t, AllocationInfo{Share: 2, Bandwidth: 2000}, info)
+
+	second.Leave()
+	assert.Equal(t, uint64(3000), first.Limit(now))
+	_, ok = scheduler.Allocation("second")
+	assert.False(t, ok)
+	assert.ErrorIs(t, scheduler.SetShare("second", 1), ErrAllocationNotFound)
+	assert.Error(t, scheduler.SetShare("first", 0))
+}
+
+func TestFairScheduler_RedistributesUnusedShare(t *testing.T) {
+	scheduler := NewFairScheduler(LimiterFunc(func(time.Time) uint64 { return 3000 }))
+	now := time.Now()
+
+	first := scheduler.Join("first", nil)
+	second := scheduler.Join("second", nil)
+	capped := scheduler.Join("capped", Schedule{{Bandwidth: 500}})
+
+	assert.Equal(t, uint64(500), capped.Limit(now), "service limit should cap the fair share")
+	assert.Equal(t, uint64(1250), first.Limit(now))
+	assert.Equal(t, uint64(1250), second.Limit(now))
+
+	assert.NoError(t, scheduler.SetShare("first", 4))
+	assert.Equal(t, uint64(2000), first.Limit(now))
+	assert.Equal(t, uint64(500), second.Limit(now))
+	assert.Equal(t, uint64(500), capped.Limit(now))
+}
+
+func TestCapLimiter(t *testing.T) {
+	now := time.Now()
+	limit := func(bandwidth uint64) Limiter {
+		return LimiterFunc(func(time.Time) uint64 { return bandwidth })
+	}
+
+	assert.Equal(t, uint64(1250), NewCapLimiter(limit(0), 1250).Limit(now))
+	assert.Equal(t, uint64(1250), NewCapLimiter(limit(5000), 1250).Limit(now))
+	assert.Equal(t, uint64(500), NewCapLimiter(limit(500), 1250).Limit(now))
+	assert.Equal(t, uint64(1250), NewCapLimiter(nil, 1250).Limit(now))
+	assert.Equal(t, uint64(5000), NewCapLimiter(limit(5000), 0).Limit(now))
+
+	capped := NewCapLimiter(limit(5000), 0)
+	changed := capped.Changed()
+	capped.SetCap(1)
+	assert.Equal(t, uint64(1), capped.Limit(now))
+	select {
+	case <-changed:
+	default:
+		t.Fatal("expected cap change notification")
+	}
+
+	capped.SetCap(0)
+	assert.Equal(t, uint64(5000), capped.Limit(now))
+}
+
+func TestFairScheduler_NotifiesAboutServiceCapChanges(t *testing.T) {
+	scheduler := NewFairScheduler(LimiterFunc(func(time.Time) uint64 { return 3000 }))
+	capped := NewCapLimiter(nil, 0)
+
+	allocation := scheduler.Join("first", capped)
+	<-allocation.Changed()
+
+	capped.SetCap(1)
+	select {
+	case <-allocation.Changed():
+	default:
+		t.Fatal("expected allocation change notification")
+	}
+	assert.Equal(t, uint64(1), allocation.Limit(time.Now()))
+}
+
+func TestFairScheduler_UnlimitedCapacity(t *testing.T) {
+	scheduler := NewFairScheduler(LimiterFunc(func(time.Time) uint64 { return 0 }))
+	now := time.Now()
+
+	first := scheduler.Join("first", nil)
+	second := scheduler.Join("second", Schedule{{Bandwidth: 500}})
+	assert.Equal(t, uint64(0), first.Limit(now))
+	assert.Equal(t, uint64(500), second.Limit(now))
+}
+
+func TestFairScheduler_NotifiesAboutChanges(t *testing.T) {
+	scheduler := NewFairScheduler(LimiterFunc(func(time.Time) uint64 { return 3000 }))
+
+	first := scheduler.Join("first", nil)
+	<-first.Changed()
+
+	scheduler.Join("second", nil)
+	select {
+	case <-first.Changed():
+	default:
+		t.Fatal("expected allocation change notification")
+	}
+}
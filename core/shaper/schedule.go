@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+)
+
+// Window limits bandwidth during a part of the day.
+type Window struct {
+	// From is the start of the window as offset from the local midnight.
+	From time.Duration
+	// To is the end of the window as offset from the local midnight, window wraps around midnight if it is before From.
+	To time.Duration
+	// Bandwidth limit in Kbytes, zero means unlimited.
+	Bandwidth uint64
+}
+
+func (w Window) contains(offset time.Duration) bool {
+	switch {
+	case w.From == w.To:
+		return true
+	case w.From < w.To:
+		return offset >= w.From && offset < w.To
+	default:
+		return offset >= w.From || offset < w.To
+	}
+}
+
+// String returns window in "HH:MM-HH:MM=bandwidth" format.
+func (w Window) String() string {
+	return fmt.Sprintf("%s-%s=%d", formatTimeOfDay(w.From), formatTimeOfDay(w.To), w.Bandwidth)
+}
+
+// Schedule is a list of time-of-day bandwidth limits, the first matching window wins.
+type Schedule []Window
+
+// ParseSchedule parses comma separated list of windows, e.g. "09:00-18:00=2500,22:00-06:00=0".
+func ParseSchedule(value string) (Schedule, error) {
+	var schedule Schedule
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		window, err := parseWindow(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bandwidth window %q: %w", part, err)
+		}
+		schedule = append(schedule, window)
+	}
+	return schedule, nil
+}
+
+// ConfiguredSchedule returns bandwidth schedule from the application configuration.
+func ConfiguredSchedule() (Schedule, error) {
+	schedule, err := ParseSchedule(config.GetString(config.FlagShaperSchedule))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s option: %w", config.FlagShaperSchedule.Name, err)
+	}
+	return schedule, nil
+}
+
+// Limit returns bandwidth limit in Kbytes effective at the given time, zero means unlimited.
+// Outside of the scheduled windows the global shaper configuration applies.
+func (s Schedule) Limit(t time.Time) uint64 {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+	for _, w := range s {
+		if w.contains(offset) {
+			return w.Bandwidth
+		}
+	}
+
+	if config.GetBool(config.FlagShaperEnabled) {
+		return config.GetUInt64(config.FlagShaperBandwidth)
+	}
+	return 0
+}
+
+// String returns schedule in the same format as accepted by ParseSchedule.
+func (s Schedule) String() string {
+	windows := make([]string, len(s))
+	for i, w := range s {
+		windows[i] = w.String()
+	}
+	return strings.Join(windows, ",")
+}
+
+// MarshalJSON implements json.Marshaler interface to provide human readable configuration.
+func (s Schedule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
+func (s *Schedule) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	schedule, err := ParseSchedule(value)
+	if err != nil {
+		return err
+	}
+	*s = schedule
+	return nil
+}
+
+func parseWindow(value string) (Window, error) {
+	period, bandwidth, ok := strings.Cut(value, "=")
+	if !ok {
+		return Window{}, fmt.Errorf("expected format HH:MM-HH:MM=bandwidth")
+	}
+	from, to, ok := strings.Cut(period, "-")
+	if !ok {
+		return Window{}, fmt.Errorf("expected format HH:MM-HH:MM=bandwidth")
+	}
+
+	var (
+		w   Window
+		err error
+	)
+	if w.From, err = parseTimeOfDay(from); err != nil {
+		return Window{}, err
+	}
+	if w.To, err = parseTimeOfDay(to); err != nil {
+		return Window{}, err
+	}
+	if w.Bandwidth, err = strconv.ParseUint(strings.TrimSpace(bandwidth), 10, 64); err != nil {
+		return Window{}, fmt.Errorf("invalid bandwidth: %w", err)
+	}
+	return w, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatTimeOfDay(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset.Hours()), int(offset.Minutes())%60)
+}
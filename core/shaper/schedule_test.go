@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/config"
+)
+
+func TestParseSchedule(t *testing.T) {
+	schedule, err := ParseSchedule("09:00-18:00=2500, 22:00-06:30=0")
+	assert.NoError(t, err)
+	assert.Equal(t, Schedule{
+		{From: 9 * time.Hour, To: 18 * time.Hour, Bandwidth: 2500},
+		{From: 22 * time.Hour, To: 6*time.Hour + 30*time.Minute, Bandwidth: 0},
+	}, schedule)
+	assert.Equal(t, "09:00-18:00=2500,22:00-06:30=0", schedule.String())
+
+	schedule, err = ParseSchedule("")
+	assert.NoError(t, err)
+	assert.Empty(t, schedule)
+
+	for _, value := range []string{"09:00-18:00", "09:00=100", "9am-18:00=100", "09:00-18:00=fast"} {
+		_, err = ParseSchedule(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestConfiguredSchedule_FailsOnInvalidSchedule(t *testing.T) {
+	config.Current.SetUser(config.FlagShaperSchedule.Name, "09:00-18:00=fast")
+	defer config.Current.RemoveUser(config.FlagShaperSchedule.Name)
+
+	_, err := ConfiguredSchedule()
+	assert.Error(t, err)
+}
+
+func TestSchedule_Limit(t *testing.T) {
+	config.Current.SetUser(config.FlagShaperEnabled.Name, true)
+	config.Current.SetUser(config.FlagShaperBandwidth.Name, uint64(100))
+	defer config.Current.RemoveUser(config.FlagShaperEnabled.Name)
+	defer config.Current.RemoveUser(config.FlagShaperBandwidth.Name)
+
+	schedule := Schedule{
+		{From: 9 * time.Hour, To: 18 * time.Hour, Bandwidth: 2500},
+		{From: 22 * time.Hour, To: 6 * time.Hour, Bandwidth: 0},
+	}
+	at := func(hour, min int) time.Time {
+		return time.Date(2022, 5, 10, hour, min, 0, 0, time.Local)
+	}
+
+	assert.Equal(t, uint64(2500), schedule.Limit(at(9, 0)))
+	assert.Equal(t, uint64(2500), schedule.Limit(at(17, 59)))
+	assert.Equal(t, uint64(100), schedule.Limit(at(18, 0)))
+	assert.Equal(t, uint64(0), schedule.Limit(at(23, 0)))
+	assert.Equal(t, uint64(0), schedule.Limit(at(3, 0)))
+	assert.Equal(t, uint64(100), schedule.Limit(at(6, 0)))
+
+	config.Current.SetUser(config.FlagShaperEnabled.Name, false)
+	assert.Equal(t, uint64(0), schedule.Limit(at(7, 0)))
+}
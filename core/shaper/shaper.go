@@ -20,21 +20,29 @@
 package shaper
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/rs/zerolog/log"
 )
 
 // noopShaper does not shaping
 type noopShaper struct {
+	limiter Limiter
+}
+
+// Supported reports whether traffic shaping is able to enforce bandwidth limits on this platform.
+func Supported() bool {
+	return false
 }
 
-func create(_ eventListener) *noopShaper {
-	return &noopShaper{}
+func create(_ eventListener, limiter Limiter) *noopShaper {
+	return &noopShaper{limiter: limiter}
 }
 
 // Start noop
-func (noopShaper) Start(_ string) error {
-	if config.GetBool(config.FlagShaperEnabled) {
+func (s noopShaper) Start(_ string) error {
+	if config.GetBool(config.FlagShaperEnabled) || s.limiter.Limit(time.Now()) > 0 {
 		log.Warn().Msgf("Flag %q is only supported under linux", config.FlagShaperEnabled.Name)
 	}
 	return nil
@@ -18,6 +18,9 @@
 package shaper
 
 import (
+	"sync"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
@@ -25,13 +28,34 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 )
 
+// limitCheckInterval defines how often the bandwidth limit is re-evaluated.
+const limitCheckInterval = time.Minute
+
+// interfaceShaper limits bandwidth of a network interface.
+type interfaceShaper interface {
+	LimitDownlink(interfaceName string, limitKbps int) error
+	LimitUplink(interfaceName string, limitKbps int) error
+	Clear(interfaceName string)
+}
+
 type linuxShaper struct {
-	ws          *wondershaper.Shaper
+	ws          interfaceShaper
 	listener    eventListener
 	listenTopic string
+	limiter     Limiter
+
+	mu       sync.Mutex
+	applied  uint64
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
-func create(listener eventListener) *linuxShaper {
+// Supported reports whether traffic shaping is able to enforce bandwidth limits on this platform.
+func Supported() bool {
+	return true
+}
+
+func create(listener eventListener, limiter Limiter) *linuxShaper {
 	ws := wondershaper.New()
 	ws.Stdout = log.Logger
 	ws.Stderr = log.Logger
@@ -39,27 +63,15 @@ func create(listener eventListener) *linuxShaper {
 		ws:          ws,
 		listener:    listener,
 		listenTopic: config.AppTopicConfig(config.FlagShaperEnabled.Name),
+		limiter:     limiter,
+		stop:        make(chan struct{}),
 	}
 }
 
 // Start applies shaping configuration on the specified interface and then continuously ensures it.
 func (s *linuxShaper) Start(interfaceName string) error {
 	applyLimits := func() error {
-		s.ws.Clear(interfaceName)
-
-		if config.GetBool(config.FlagShaperEnabled) {
-			err := s.ws.LimitDownlink(interfaceName, int(config.GetUInt64(config.FlagShaperBandwidth)))
-			if err != nil {
-				log.Error().Err(err).Msg("Could not limit download speed")
-				return err
-			}
-			err = s.ws.LimitUplink(interfaceName, int(config.GetUInt64(config.FlagShaperBandwidth)))
-			if err != nil {
-				log.Error().Err(err).Msg("Could not limit upload speed")
-				return err
-			}
-		}
-		return nil
+		return s.applyLimits(interfaceName)
 	}
 
 	err := s.listener.SubscribeAsync(s.listenTopic, applyLimits)
@@ -67,10 +79,69 @@ func (s *linuxShaper) Start(interfaceName string) error {
 		return errors.Wrap(err, "could not subscribe to topic: "+s.listenTopic)
 	}
 
+	go s.followLimits(interfaceName)
+
 	return applyLimits()
 }
 
+func (s *linuxShaper) applyLimits(interfaceName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ws.Clear(interfaceName)
+
+	limit := s.limiter.Limit(time.Now())
+	s.applied = limit
+	if limit == 0 {
+		return nil
+	}
+
+	err := s.ws.LimitDownlink(interfaceName, int(limit))
+	if err != nil {
+		log.Error().Err(err).Msg("Could not limit download speed")
+		return err
+	}
+	err = s.ws.LimitUplink(interfaceName, int(limit))
+	if err != nil {
+		log.Error().Err(err).Msg("Could not limit upload speed")
+		return err
+	}
+	return nil
+}
+
+// followLimits re-applies limits whenever the bandwidth limit changes.
+func (s *linuxShaper) followLimits(interfaceName string) {
+	ticker := time.NewTicker(limitCheckInterval)
+	defer ticker.Stop()
+
+	var changed <-chan struct{}
+	if notifier, ok := s.limiter.(interface{ Changed() <-chan struct{} }); ok {
+		changed = notifier.Changed()
+	}
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		case <-changed:
+		}
+
+		s.mu.Lock()
+		outdated := s.applied != s.limiter.Limit(time.Now())
+		s.mu.Unlock()
+
+		if outdated {
+			log.Info().Msgf("Applying updated bandwidth limit on %s", interfaceName)
+			if err := s.applyLimits(interfaceName); err != nil {
+				log.Error().Err(err).Msg("Could not apply updated bandwidth limit")
+			}
+		}
+	}
+}
+
 // Clear clears shaping rules.
 func (s *linuxShaper) Clear(interfaceName string) {
+	s.stopOnce.Do(func() { close(s.stop) })
 	s.ws.Clear(interfaceName)
 }
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package shaper
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinuxShaper_AppliesChangedCap(t *testing.T) {
+	ws := &fakeInterfaceShaper{}
+	capped := NewCapLimiter(Schedule{{Bandwidth: 5000}}, 0)
+	scheduler := NewFairScheduler(LimiterFunc(func(time.Time) uint64 { return 0 }))
+	allocation := scheduler.Join("session", capped)
+
+	s := create(&fakeListener{}, allocation)
+	s.ws = ws
+	assert.NoError(t, s.Start("wg0"))
+	defer s.Clear("wg0")
+	assert.Equal(t, 5000, ws.rate())
+
+	capped.SetCap(1)
+	assert.Eventually(t, func() bool { return ws.rate() == 1 }, time.Second, 10*time.Millisecond)
+
+	capped.SetCap(0)
+	assert.Eventually(t, func() bool { return ws.rate() == 5000 }, time.Second, 10*time.Millisecond)
+}
+
+type fakeListener struct{}
+
+func (fakeListener) SubscribeAsync(string, interface{}) error { return nil }
+
+type fakeInterfaceShaper struct {
+	mu       sync.Mutex
+	downlink int
+	uplink   int
+}
+
+func (f *fakeInterfaceShaper) LimitDownlink(_ string, limitKbps int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.downlink = limitKbps
+	return nil
+}
+
+func (f *fakeInterfaceShaper) LimitUplink(_ string, limitKbps int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.uplink = limitKbps
+	return nil
+}
+
+func (f *fakeInterfaceShaper) Clear(string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.downlink, f.uplink = 0, 0
+}
+
+// rate returns the applied rate, zero if the directions are shaped differently.
+func (f *fakeInterfaceShaper) rate() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.downlink != f.uplink {
+		return 0
+	}
+	return f.downlink
+}
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package speedtest
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// Run measures download and then upload throughput against the echo endpoint at the given address.
+func Run(ctx context.Context, addr string, duration time.Duration) (Result, error) {
+	duration = ClampDuration(duration)
+	result := Result{
+		StartedAt: time.Now().UTC(),
+		Duration:  duration,
+	}
+
+	start := time.Now()
+	conn, err := dial(ctx, addr, commandDownload, duration)
+	if err != nil {
+		return Result{}, fmt.Errorf("could not start download: %w", err)
+	}
+	result.Latency = time.Since(start)
+
+	start = time.Now()
+	result.DownloadBytes, err = download(ctx, conn, duration)
+	if err != nil {
+		return Result{}, fmt.Errorf("download failed: %w", err)
+	}
+	result.Download = bitsPerSecond(result.DownloadBytes, time.Since(start))
+
+	conn, err = dial(ctx, addr, commandUpload, duration)
+	if err != nil {
+		return Result{}, fmt.Errorf("could not start upload: %w", err)
+	}
+
+	start = time.Now()
+	result.UploadBytes, err = upload(ctx, conn, duration)
+	if err != nil {
+		return Result{}, fmt.Errorf("upload failed: %w", err)
+	}
+	result.Upload = bitsPerSecond(result.UploadBytes, time.Since(start))
+
+	return result, nil
+}
+
+func dial(ctx context.Context, addr string, command byte, duration time.Duration) (net.Conn, error) {
+	var d net.Dialer
+	dialCtx, cancel := context.WithTimeout(ctx, ioTimeout)
+	defer cancel()
+
+	conn, err := d.DialContext(dialCtx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 5)
+	header[0] = command
+	binary.BigEndian.PutUint32(header[1:], uint32(duration.Milliseconds()))
+	if err := conn.SetWriteDeadline(time.Now().Add(ioTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if _, err := conn.Write(header); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func download(ctx context.Context, conn net.Conn, duration time.Duration) (uint64, error) {
+	defer conn.Close()
+	defer closeOnCancel(ctx, conn)()
+
+	if err := conn.SetReadDeadline(time.Now().Add(duration + ioTimeout)); err != nil {
+		return 0, err
+	}
+	received, err := io.Copy(io.Discard, conn)
+	if err != nil && ctx.Err() == nil {
+		return 0, err
+	}
+	return uint64(received), ctx.Err()
+}
+
+func upload(ctx context.Context, conn net.Conn, duration time.Duration) (uint64, error) {
+	defer conn.Close()
+	defer closeOnCancel(ctx, conn)()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(duration)); err != nil {
+		return 0, err
+	}
+	chunk := make([]byte, chunkSize)
+	for {
+		if _, err := conn.Write(chunk); err != nil {
+			if isTimeout(err) {
+				break
+			}
+			return 0, err
+		}
+	}
+
+	if tcp, ok := conn.(*net.TCPConn); ok {
+		if err := tcp.CloseWrite(); err != nil {
+			return 0, err
+		}
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(ioTimeout)); err != nil {
+		return 0, err
+	}
+	ack := make([]byte, 8)
+	if _, err := io.ReadFull(conn, ack); err != nil {
+		return 0, fmt.Errorf("no upload acknowledgement: %w", err)
+	}
+	return binary.BigEndian.Uint64(ack), nil
+}
+
+// closeOnCancel closes the connection to interrupt the blocked transfer once the context is done.
+func closeOnCancel(ctx context.Context, conn net.Conn) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	return func() { close(done) }
+}
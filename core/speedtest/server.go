@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package speedtest
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Server is the echo endpoint the consumer measures the tunnel throughput against.
+type Server struct {
+	listener net.Listener
+
+	wg   sync.WaitGroup
+	once sync.Once
+}
+
+// Listen starts the echo endpoint on the given provider's tunnel address.
+func Listen(ip net.IP) (*Server, error) {
+	listener, err := net.Listen("tcp", net.JoinHostPort(ip.String(), strconv.Itoa(Port)))
+	if err != nil {
+		return nil, err
+	}
+	return Serve(listener), nil
+}
+
+// Serve starts serving speed tests on the given listener.
+func Serve(listener net.Listener) *Server {
+	s := &Server{listener: listener}
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Stop stops accepting new tests and waits for the running ones to finish.
+func (s *Server) Stop() {
+	s.once.Do(func() {
+		s.listener.Close()
+	})
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Warn().Err(err).Msg("Speedtest server stopped accepting connections")
+			}
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			if err := handle(conn); err != nil {
+				log.Debug().Err(err).Msg("Speedtest failed")
+			}
+		}()
+	}
+}
+
+func handle(conn net.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(ioTimeout)); err != nil {
+		return err
+	}
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	duration := ClampDuration(time.Duration(binary.BigEndian.Uint32(header[1:])) * time.Millisecond)
+
+	switch header[0] {
+	case commandDownload:
+		return serveDownload(conn, duration)
+	case commandUpload:
+		return serveUpload(conn, duration)
+	default:
+		return errors.New("unknown speedtest command")
+	}
+}
+
+func serveDownload(conn net.Conn, duration time.Duration) error {
+	if err := conn.SetDeadline(time.Now().Add(duration)); err != nil {
+		return err
+	}
+
+	chunk := make([]byte, chunkSize)
+	for {
+		if _, err := conn.Write(chunk); err != nil {
+			if isTimeout(err) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func serveUpload(conn net.Conn, duration time.Duration) error {
+	if err := conn.SetReadDeadline(time.Now().Add(duration + ioTimeout)); err != nil {
+		return err
+	}
+
+	received, err := io.Copy(io.Discard, conn)
+	if err != nil {
+		return err
+	}
+
+	if err := conn.SetWriteDeadline(time.Now().Add(ioTimeout)); err != nil {
+		return err
+	}
+	ack := make([]byte, 8)
+	binary.BigEndian.PutUint64(ack, uint64(received))
+	_, err = conn.Write(ack)
+	return err
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package speedtest measures throughput of the session tunnel against the echo endpoint
+// the provider runs on its end of the tunnel.
+//
+// The protocol is a single TCP connection per direction. The client sends a command byte followed by
+// the requested duration in milliseconds as a big-endian uint32. For a download the server streams data
+// until the duration elapses. For an upload the server discards the received data until the client closes
+// its write side and replies with the number of received bytes as a big-endian uint64.
+package speedtest
+
+import (
+	"time"
+)
+
+const (
+	// Port is the TCP port of the echo endpoint on the provider's end of the tunnel.
+	Port = 4590
+	// DefaultDuration is the duration of each direction used when none is requested.
+	DefaultDuration = 5 * time.Second
+	// MaxDuration is the longest duration of each direction allowed.
+	MaxDuration = 15 * time.Second
+
+	commandDownload byte = 'D'
+	commandUpload   byte = 'U'
+
+	chunkSize = 32 << 10
+	// ioTimeout bounds the protocol exchange outside of the measured transfer.
+	ioTimeout = 5 * time.Second
+)
+
+// Result is the outcome of a bidirectional throughput test.
+type Result struct {
+	StartedAt time.Time
+	// Latency is the time it took to establish the TCP connection through the tunnel.
+	Latency time.Duration
+	// Duration is the duration of each direction.
+	Duration time.Duration
+	// DownloadBytes are the bytes received from the provider.
+	DownloadBytes uint64
+	// UploadBytes are the bytes the provider acknowledged as received.
+	UploadBytes uint64
+	// Download is the throughput from the provider in bits per second.
+	Download uint64
+	// Upload is the throughput to the provider in bits per second.
+	Upload uint64
+}
+
+// ClampDuration replaces zero duration with the default and limits it to the maximum.
+func ClampDuration(duration time.Duration) time.Duration {
+	if duration <= 0 {
+		return DefaultDuration
+	}
+	if duration > MaxDuration {
+		return MaxDuration
+	}
+	return duration
+}
+
+func bitsPerSecond(bytes uint64, elapsed time.Duration) uint64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return uint64(float64(bytes*8) / elapsed.Seconds())
+}
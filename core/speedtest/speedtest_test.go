@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package speedtest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := Serve(listener)
+	defer server.Stop()
+
+	result, err := Run(context.Background(), server.Addr().String(), 200*time.Millisecond)
+	require.NoError(t, err)
+
+	assert.Equal(t, 200*time.Millisecond, result.Duration)
+	assert.NotZero(t, result.DownloadBytes)
+	assert.NotZero(t, result.UploadBytes)
+	assert.NotZero(t, result.Download)
+	assert.NotZero(t, result.Upload)
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := Serve(listener)
+	defer server.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = Run(ctx, server.Addr().String(), MaxDuration)
+	assert.Error(t, err)
+}
+
+func TestClampDuration(t *testing.T) {
+	assert.Equal(t, DefaultDuration, ClampDuration(0))
+	assert.Equal(t, time.Second, ClampDuration(time.Second))
+	assert.Equal(t, MaxDuration, ClampDuration(time.Hour))
+}
@@ -20,6 +20,7 @@ package event
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
@@ -27,10 +28,12 @@ import (
 	"github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
 	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/session/traffic"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -45,6 +48,54 @@ type State struct {
 	Connections      map[string]Connection
 	Identities       []Identity
 	ProviderChannels []pingpong.HermesChannel
+	SessionAdmission SessionAdmission
+	ResourcePressure ResourcePressure
+	SessionPayments  map[string]SessionPayment
+	SessionTraffic   map[string]traffic.Counters
+	NATTraversals    []NATTraversal
+}
+
+// NATTraversal represents the timeline of a single NAT traversal attempt.
+type NATTraversal struct {
+	ID     string
+	Stages []NATTraversalStage
+}
+
+// NATTraversalStage represents the outcome of a single NAT traversal stage.
+type NATTraversalStage struct {
+	Stage      string
+	Successful bool
+	Error      string
+	CreatedAt  time.Time
+}
+
+// SessionPayment represents the payment state of an ongoing provider session.
+type SessionPayment struct {
+	LastInvoiceAmount     *big.Int
+	LastInvoiceAt         time.Time
+	LastExchangeMessageAt time.Time
+	Unpaid                *big.Int
+	Shortfall             *big.Int
+}
+
+// SessionAdmission represents the occupancy of the provider session slots.
+type SessionAdmission struct {
+	Active   int
+	Limit    int
+	Queued   int
+	Rejected uint64
+}
+
+// ResourcePressure represents the system resource usage of the provider and the sessions shed because of it.
+type ResourcePressure struct {
+	Shedding        bool
+	CPU             float64
+	Memory          float64
+	Conntrack       float64
+	FileDescriptors float64
+	Exceeded        []string
+	Deprioritized   []string
+	Rejected        uint64
 }
 
 // Identity represents identity and its status.
@@ -67,6 +118,18 @@ type Connection struct {
 	Statistics connectionstate.Statistics
 	Throughput bandwidth.Throughput
 	Invoice    crypto.Invoice
+	Payment    ConsumerPayment
+}
+
+// ConsumerPayment represents the payment engine state of an ongoing consumer session.
+type ConsumerPayment struct {
+	LastInvoiceAmount *big.Int
+	LastInvoiceAt     time.Time
+	Hashlock          string
+	LastPromiseAt     time.Time
+	AgreementTotal    *big.Int
+	TotalPromised     *big.Int
+	LastError         string
 }
 
 func (c Connection) String() string {
@@ -87,3 +150,7 @@ func (c Connection) String() string {
 		spent,
 	)
 }
+
+func init() {
+	eventbus.RegisterSchema(AppTopicState, 1, State{})
+}
@@ -20,10 +20,10 @@ package state
 import (
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/jinzhu/copier"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
@@ -37,10 +37,12 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
 	nodeSession "github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/session/traffic"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 )
 
@@ -75,16 +77,21 @@ type earningsProvider interface {
 
 // Keeper keeps track of state through eventual consistency.
 // This should become the de-facto place to get your info about node.
+//
+// Events are applied to the working state one at a time, each change is published as an immutable
+// snapshot of the working state. Readers only load the latest snapshot, so they never wait for writers.
+// Every snapshot is numbered by the revision, which lets readers tell whether the state has changed.
 type Keeper struct {
-	state *stateEvent.State
-	lock  sync.RWMutex
-	deps  KeeperDeps
+	state    *stateEvent.State
+	revision uint64
+	snapshot atomic.Value
+	lock     sync.Mutex
+	deps     KeeperDeps
 
 	// provider
 	consumeServiceStateEvent             func(e interface{})
 	consumeServiceSessionStatisticsEvent func(e interface{})
 	consumeServiceSessionEarningsEvent   func(e interface{})
-	consumeNATStatusUpdateEvent          func(e interface{})
 	// consumer
 	consumeConnectionStatisticsEvent func(interface{})
 	consumeConnectionThroughputEvent func(interface{})
@@ -114,13 +121,16 @@ type proposalPricer interface {
 func NewKeeper(deps KeeperDeps, debounceDuration time.Duration) *Keeper {
 	k := &Keeper{
 		state: &stateEvent.State{
-			Sessions:    make([]session.History, 0),
-			Connections: make(map[string]stateEvent.Connection),
+			Sessions:        make([]session.History, 0),
+			SessionPayments: make(map[string]stateEvent.SessionPayment),
+			SessionTraffic:  make(map[string]traffic.Counters),
+			Connections:     make(map[string]stateEvent.Connection),
 		},
 		deps: deps,
 	}
 	k.state.Identities = k.fetchIdentities()
 	k.state.ProviderChannels = k.deps.EarningsProvider.List(deps.ChainID)
+	k.publishSnapshot()
 
 	// provider
 	k.consumeServiceStateEvent = debounce(k.updateServiceState, debounceDuration)
@@ -180,12 +190,25 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(sessionEvent.AppTopicSession, k.consumeServiceSessionEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(sessionEvent.AppTopicSessionAdmission, k.consumeSessionAdmissionEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(sessionEvent.AppTopicResourcePressure, k.consumeResourcePressureEvent); err != nil {
+		return err
+	}
+	// Traversal stages are consumed synchronously to keep them in the order they were published.
+	if err := bus.Subscribe(natEvent.AppTopicTraversal, k.consumeNATEvent); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(sessionEvent.AppTopicDataTransferred, k.consumeServiceSessionStatisticsEvent); err != nil {
 		return err
 	}
 	if err := bus.SubscribeAsync(sessionEvent.AppTopicTokensEarned, k.consumeServiceSessionEarningsEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(sessionEvent.AppTopicSessionPayment, k.consumeSessionPaymentEvent); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(connectionstate.AppTopicConnectionState, k.consumeConnectionStateEvent); err != nil {
 		return err
 	}
@@ -198,6 +221,9 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(pingpongEvent.AppTopicInvoicePaid, k.consumeConnectionSpendingEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(pingpongEvent.AppTopicConsumerPayment, k.consumeConsumerPaymentEvent); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(identity.AppTopicIdentityCreated, k.consumeIdentityCreatedEvent); err != nil {
 		return err
 	}
@@ -213,21 +239,88 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 	return nil
 }
 
-func (k *Keeper) announceState(_ interface{}) {
-	var state stateEvent.State
-	func() {
-		k.lock.RLock()
-		defer k.lock.RUnlock()
-		if err := copier.CopyWithOption(&state, *k.state, copier.Option{DeepCopy: true}); err != nil {
-			panic(err)
+// commit publishes the changes of the working state and releases the write lock.
+func (k *Keeper) commit() {
+	k.publishSnapshot()
+	k.lock.Unlock()
+}
+
+// publishSnapshot replaces the snapshot with a copy of the working state.
+// Snapshot is never modified once published, therefore it's safe to share it with readers.
+// Only the collections which are modified in place by the keeper are copied, values they
+// hold are always replaced as a whole.
+func (k *Keeper) publishSnapshot() {
+	state := *k.state
+
+	if k.state.Services != nil {
+		state.Services = make([]contract.ServiceInfoDTO, len(k.state.Services))
+		for i, service := range k.state.Services {
+			if service.ConnectionStatistics != nil {
+				stats := *service.ConnectionStatistics
+				service.ConnectionStatistics = &stats
+			}
+			state.Services[i] = service
 		}
-	}()
-	k.deps.Publisher.Publish(stateEvent.AppTopicState, state)
+	}
+	if k.state.Sessions != nil {
+		state.Sessions = make([]session.History, len(k.state.Sessions))
+		copy(state.Sessions, k.state.Sessions)
+	}
+	if k.state.Identities != nil {
+		state.Identities = make([]stateEvent.Identity, len(k.state.Identities))
+		copy(state.Identities, k.state.Identities)
+	}
+	if k.state.NATTraversals != nil {
+		state.NATTraversals = make([]stateEvent.NATTraversal, len(k.state.NATTraversals))
+		for i, traversal := range k.state.NATTraversals {
+			traversal.Stages = append([]stateEvent.NATTraversalStage(nil), traversal.Stages...)
+			state.NATTraversals[i] = traversal
+		}
+	}
+	if k.state.Connections != nil {
+		state.Connections = make(map[string]stateEvent.Connection, len(k.state.Connections))
+		for id, conn := range k.state.Connections {
+			state.Connections[id] = conn
+		}
+	}
+	if k.state.SessionTraffic != nil {
+		state.SessionTraffic = make(map[string]traffic.Counters, len(k.state.SessionTraffic))
+		for id, counters := range k.state.SessionTraffic {
+			state.SessionTraffic[id] = counters
+		}
+	}
+	if k.state.SessionPayments != nil {
+		state.SessionPayments = make(map[string]stateEvent.SessionPayment, len(k.state.SessionPayments))
+		for id, payment := range k.state.SessionPayments {
+			state.SessionPayments[id] = payment
+		}
+	}
+
+	k.revision++
+	k.snapshot.Store(&keeperSnapshot{state: &state, revision: k.revision})
+}
+
+// keeperSnapshot is the published state along with its revision.
+type keeperSnapshot struct {
+	state    *stateEvent.State
+	revision uint64
+}
+
+func (k *Keeper) loadSnapshot() *keeperSnapshot {
+	return k.snapshot.Load().(*keeperSnapshot)
+}
+
+func (k *Keeper) currentSnapshot() *stateEvent.State {
+	return k.loadSnapshot().state
+}
+
+func (k *Keeper) announceState(_ interface{}) {
+	k.deps.Publisher.Publish(stateEvent.AppTopicState, *k.currentSnapshot())
 }
 
 func (k *Keeper) updateServiceState(_ interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	k.updateServices()
 	go k.announceStateChanges(nil)
 }
@@ -279,7 +372,7 @@ func (k *Keeper) getServiceByID(id string) (se contract.ServiceInfoDTO, found bo
 // consumeServiceSessionEvent consumes the session change events
 func (k *Keeper) consumeServiceSessionEvent(e sessionEvent.AppEventSession) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 
 	switch e.Status {
 	case sessionEvent.CreatedStatus:
@@ -294,6 +387,81 @@ func (k *Keeper) consumeServiceSessionEvent(e sessionEvent.AppEventSession) {
 	go k.announceStateChanges(nil)
 }
 
+func (k *Keeper) consumeSessionAdmissionEvent(e sessionEvent.AppEventSessionAdmission) {
+	k.lock.Lock()
+	defer k.commit()
+
+	k.state.SessionAdmission = stateEvent.SessionAdmission{
+		Active:   e.Active,
+		Limit:    e.Limit,
+		Queued:   e.Queued,
+		Rejected: e.Rejected,
+	}
+	go k.announceStateChanges(nil)
+}
+
+func (k *Keeper) consumeResourcePressureEvent(e sessionEvent.AppEventResourcePressure) {
+	k.lock.Lock()
+	defer k.commit()
+
+	k.state.ResourcePressure = stateEvent.ResourcePressure{
+		Shedding:        e.Shedding,
+		CPU:             e.CPU,
+		Memory:          e.Memory,
+		Conntrack:       e.Conntrack,
+		FileDescriptors: e.FileDescriptors,
+		Exceeded:        e.Exceeded,
+		Deprioritized:   e.Deprioritized,
+		Rejected:        e.Rejected,
+	}
+	go k.announceStateChanges(nil)
+}
+
+const (
+	// natTimelineAttempts is the number of the latest NAT traversal attempts retained in the state.
+	natTimelineAttempts = 20
+	// natTimelineStages is the number of the latest stages retained per NAT traversal attempt.
+	natTimelineStages = 50
+)
+
+// consumeNATEvent appends the traversal stage to the timeline of its attempt.
+// Updates are not debounced, since intermittent failures would be lost otherwise.
+func (k *Keeper) consumeNATEvent(e natEvent.Event) {
+	k.lock.Lock()
+	defer k.commit()
+
+	stage := stateEvent.NATTraversalStage{
+		Stage:      e.Stage,
+		Successful: e.Successful,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if e.Error != nil {
+		stage.Error = e.Error.Error()
+	}
+
+	idx := -1
+	for i := range k.state.NATTraversals {
+		if k.state.NATTraversals[i].ID == e.ID {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		k.state.NATTraversals = append(k.state.NATTraversals, stateEvent.NATTraversal{ID: e.ID})
+		if len(k.state.NATTraversals) > natTimelineAttempts {
+			k.state.NATTraversals = k.state.NATTraversals[len(k.state.NATTraversals)-natTimelineAttempts:]
+		}
+		idx = len(k.state.NATTraversals) - 1
+	}
+
+	traversal := &k.state.NATTraversals[idx]
+	traversal.Stages = append(traversal.Stages, stage)
+	if len(traversal.Stages) > natTimelineStages {
+		traversal.Stages = traversal.Stages[len(traversal.Stages)-natTimelineStages:]
+	}
+	go k.announceStateChanges(nil)
+}
+
 func (k *Keeper) addSession(e sessionEvent.AppEventSession) {
 	k.state.Sessions = append(k.state.Sessions, session.History{
 		SessionID:       nodeSession.ID(e.Session.ID),
@@ -315,6 +483,8 @@ func (k *Keeper) removeSession(e sessionEvent.AppEventSession) {
 	for i := range k.state.Sessions {
 		if string(k.state.Sessions[i].SessionID) == e.Session.ID {
 			k.state.Sessions = append(k.state.Sessions[:i], k.state.Sessions[i+1:]...)
+			delete(k.state.SessionPayments, e.Session.ID)
+			delete(k.state.SessionTraffic, e.Session.ID)
 			found = true
 			break
 		}
@@ -327,7 +497,7 @@ func (k *Keeper) removeSession(e sessionEvent.AppEventSession) {
 // updates the data transfer info on the session
 func (k *Keeper) updateSessionStats(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 
 	evt, ok := e.(sessionEvent.AppEventDataTransferred)
 	if !ok {
@@ -351,13 +521,16 @@ func (k *Keeper) updateSessionStats(e interface{}) {
 	// This way, the session will show that it downloaded the bytes in a manner that is easier to comprehend.
 	session.DataReceived = evt.Up
 	session.DataSent = evt.Down
+	if len(evt.Traffic) > 0 {
+		k.state.SessionTraffic[evt.ID] = evt.Traffic
+	}
 	go k.announceStateChanges(nil)
 }
 
 // updates total tokens earned during the session.
 func (k *Keeper) updateSessionEarnings(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 
 	evt, ok := e.(sessionEvent.AppEventTokensEarned)
 	if !ok {
@@ -380,9 +553,37 @@ func (k *Keeper) updateSessionEarnings(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
+// consumeSessionPaymentEvent updates the payment state of the ongoing session.
+// Updates are not debounced, since they come for many sessions and each of them has to be kept.
+func (k *Keeper) consumeSessionPaymentEvent(e sessionEvent.AppEventSessionPayment) {
+	k.lock.Lock()
+	defer k.commit()
+
+	found := false
+	for i := range k.state.Sessions {
+		if string(k.state.Sessions[i].SessionID) == e.SessionID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		log.Warn().Msgf("Couldn't find a matching session for payment change: %s", e.SessionID)
+		return
+	}
+
+	k.state.SessionPayments[e.SessionID] = stateEvent.SessionPayment{
+		LastInvoiceAmount:     e.LastInvoiceAmount,
+		LastInvoiceAt:         e.LastInvoiceAt,
+		LastExchangeMessageAt: e.LastExchangeMessageAt,
+		Unpaid:                e.Unpaid,
+		Shortfall:             e.Shortfall,
+	}
+	go k.announceStateChanges(nil)
+}
+
 func (k *Keeper) consumeConnectionStateEvent(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	evt, ok := e.(connectionstate.AppEventConnectionState)
 	if !ok {
 		log.Warn().Msg("Received a wrong kind of event for connection state update")
@@ -404,7 +605,7 @@ func (k *Keeper) consumeConnectionStateEvent(e interface{}) {
 
 func (k *Keeper) updateConnectionStats(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	evt, ok := e.(connectionstate.AppEventConnectionStatistics)
 	if !ok {
 		log.Warn().Msg("Received a wrong kind of event for connection state update")
@@ -412,15 +613,19 @@ func (k *Keeper) updateConnectionStats(e interface{}) {
 	}
 
 	conn := k.state.Connections[string(evt.SessionInfo.SessionID)]
+	changed := conn.Statistics.BytesSent != evt.Stats.BytesSent || conn.Statistics.BytesReceived != evt.Stats.BytesReceived
 	conn.Statistics = evt.Stats
 	k.state.Connections[string(evt.SessionInfo.SessionID)] = conn
 
-	go k.announceStateChanges(nil)
+	// Idle connections keep reporting the same totals, there is nothing new to announce.
+	if changed {
+		go k.announceStateChanges(nil)
+	}
 }
 
 func (k *Keeper) updateConnectionThroughput(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	evt, ok := e.(bandwidth.AppEventConnectionThroughput)
 	if !ok {
 		log.Warn().Msg("Received a wrong kind of event for connection state update")
@@ -436,7 +641,7 @@ func (k *Keeper) updateConnectionThroughput(e interface{}) {
 
 func (k *Keeper) updateConnectionSpending(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	evt, ok := e.(pingpongEvent.AppEventInvoicePaid)
 	if !ok {
 		log.Warn().Msg("Received a wrong kind of event for connection state update")
@@ -452,9 +657,34 @@ func (k *Keeper) updateConnectionSpending(e interface{}) {
 	go k.announceStateChanges(nil)
 }
 
+// consumeConsumerPaymentEvent updates the payment engine state of the ongoing consumer session.
+// Updates are not debounced, since every one of them may carry the reason of a payment stall.
+func (k *Keeper) consumeConsumerPaymentEvent(e pingpongEvent.AppEventConsumerPayment) {
+	k.lock.Lock()
+	defer k.commit()
+
+	conn, ok := k.state.Connections[e.SessionID]
+	if !ok {
+		log.Warn().Msgf("Couldn't find a matching connection for payment change: %s", e.SessionID)
+		return
+	}
+
+	conn.Payment = stateEvent.ConsumerPayment{
+		LastInvoiceAmount: e.LastInvoiceAmount,
+		LastInvoiceAt:     e.LastInvoiceAt,
+		Hashlock:          e.Hashlock,
+		LastPromiseAt:     e.LastPromiseAt,
+		AgreementTotal:    e.AgreementTotal,
+		TotalPromised:     e.TotalPromised,
+		LastError:         e.LastError,
+	}
+	k.state.Connections[e.SessionID] = conn
+	go k.announceStateChanges(nil)
+}
+
 func (k *Keeper) consumeBalanceChangedEvent(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	evt, ok := e.(pingpongEvent.AppEventBalanceChanged)
 	if !ok {
 		log.Warn().Msg("Received a wrong kind of event for balance change")
@@ -477,7 +707,7 @@ func (k *Keeper) consumeBalanceChangedEvent(e interface{}) {
 
 func (k *Keeper) consumeEarningsChangedEvent(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	evt, ok := e.(pingpongEvent.AppEventEarningsChanged)
 	if !ok {
 		log.Warn().Msg("Received a wrong kind of event for earnings change")
@@ -507,14 +737,14 @@ func (k *Keeper) consumeEarningsChangedEvent(e interface{}) {
 
 func (k *Keeper) consumeIdentityCreatedEvent(_ interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	k.state.Identities = k.fetchIdentities()
 	go k.announceStateChanges(nil)
 }
 
 func (k *Keeper) consumeIdentityRegistrationEvent(e interface{}) {
 	k.lock.Lock()
-	defer k.lock.Unlock()
+	defer k.commit()
 	evt, ok := e.(registry.AppEventIdentityRegistration)
 	if !ok {
 		log.Warn().Msg("Received a wrong kind of event for identity registration")
@@ -547,29 +777,30 @@ func (k *Keeper) incrementConnectCount(serviceID string, isSuccess bool) {
 	}
 }
 
-// GetState returns the current state
-func (k *Keeper) GetState() (res stateEvent.State) {
-	k.lock.RLock()
-	defer k.lock.RUnlock()
+// GetState returns the current state.
+// Returned state is shared between the readers and must not be modified.
+func (k *Keeper) GetState() stateEvent.State {
+	return *k.currentSnapshot()
+}
 
-	if err := copier.CopyWithOption(&res, *k.state, copier.Option{DeepCopy: true}); err != nil {
-		panic(err)
-	}
-	return
+// GetStateWithRevision returns the current state and its revision, revision changes whenever the state changes.
+// Returned state is shared between the readers and must not be modified.
+func (k *Keeper) GetStateWithRevision() (stateEvent.State, uint64) {
+	snapshot := k.loadSnapshot()
+	return *snapshot.state, snapshot.revision
 }
 
 // GetConnection returns the connection state.
 func (k *Keeper) GetConnection(id string) (conn stateEvent.Connection) {
-	k.lock.RLock()
-	defer k.lock.RUnlock()
+	snapshot := k.currentSnapshot()
 
 	if len(id) == 0 {
-		for _, state := range k.state.Connections {
+		for _, state := range snapshot.Connections {
 			return state
 		}
 	}
 
-	state, ok := k.state.Connections[id]
+	state, ok := snapshot.Connections[id]
 	if !ok {
 		state.Session.State = connectionstate.NotConnected
 	}
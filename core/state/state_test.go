@@ -18,6 +18,8 @@
 package state
 
 import (
+	"errors"
+	"fmt"
 	"math/big"
 	"reflect"
 	"sync"
@@ -32,16 +34,19 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
+	natEvent "github.com/mysteriumnetwork/node/nat/event"
 	nodeSession "github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/session/traffic"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -212,6 +217,65 @@ func Test_ConsumesSessionAcknowledgeEvents(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func Test_GetStateReturnsImmutableSnapshot(t *testing.T) {
+	// given
+	myID := "test"
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		Publisher:        eventBus,
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	keeper.Subscribe(eventBus)
+	keeper.lock.Lock()
+	keeper.state.Services = []contract.ServiceInfoDTO{
+		{ID: myID, ConnectionStatistics: &contract.ServiceStatisticsDTO{}},
+	}
+	keeper.state.Sessions = []session.History{{SessionID: nodeSession.ID("1")}}
+	keeper.commit()
+	before := keeper.GetState()
+
+	// when
+	eventBus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{
+		Status:  sessionEvent.AcknowledgedStatus,
+		Service: sessionEvent.ServiceContext{ID: myID},
+		Session: sessionEvent.SessionContext{ID: "1"},
+	})
+	eventBus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{
+		Status:  sessionEvent.RemovedStatus,
+		Session: sessionEvent.SessionContext{ID: "1"},
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		state := keeper.GetState()
+		return len(state.Sessions) == 0 && state.Services[0].ConnectionStatistics.Successful == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Len(t, before.Sessions, 1)
+	assert.Equal(t, 0, before.Services[0].ConnectionStatistics.Successful)
+}
+
+func Test_GetStateWithRevisionChangesWithState(t *testing.T) {
+	keeper := NewKeeper(KeeperDeps{
+		Publisher:        eventbus.New(),
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}, time.Millisecond)
+
+	_, before := keeper.GetStateWithRevision()
+	_, unchanged := keeper.GetStateWithRevision()
+	assert.Equal(t, before, unchanged)
+
+	keeper.lock.Lock()
+	keeper.state.Sessions = []session.History{{SessionID: nodeSession.ID("1")}}
+	keeper.commit()
+
+	state, after := keeper.GetStateWithRevision()
+	assert.Greater(t, after, before)
+	assert.Len(t, state.Sessions, 1)
+}
+
 func Test_consumeServiceSessionEarningsEvent(t *testing.T) {
 	// given
 	eventBus := eventbus.New()
@@ -245,6 +309,104 @@ func Test_consumeServiceSessionEarningsEvent(t *testing.T) {
 	)
 }
 
+func Test_consumeSessionPaymentEvent(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		Publisher:        eventBus,
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	keeper.Subscribe(eventBus)
+	keeper.state.Sessions = []session.History{
+		{SessionID: nodeSession.ID("1")},
+	}
+	invoicedAt := time.Now().UTC()
+
+	// when
+	eventBus.Publish(sessionEvent.AppTopicSessionPayment, sessionEvent.AppEventSessionPayment{
+		SessionID:         "1",
+		LastInvoiceAmount: big.NewInt(500),
+		LastInvoiceAt:     invoicedAt,
+		Paid:              big.NewInt(200),
+		Unpaid:            big.NewInt(300),
+	})
+	eventBus.Publish(sessionEvent.AppTopicSessionPayment, sessionEvent.AppEventSessionPayment{
+		SessionID: "unknown",
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return len(keeper.GetState().SessionPayments) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(
+		t,
+		map[string]stateEvent.SessionPayment{
+			"1": {LastInvoiceAmount: big.NewInt(500), LastInvoiceAt: invoicedAt, Unpaid: big.NewInt(300)},
+		},
+		keeper.GetState().SessionPayments,
+	)
+}
+
+func Test_consumeSessionAdmissionEvent(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		Publisher:        eventBus,
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	keeper.Subscribe(eventBus)
+
+	// when
+	eventBus.Publish(sessionEvent.AppTopicSessionAdmission, sessionEvent.AppEventSessionAdmission{
+		Active:   2,
+		Limit:    2,
+		Queued:   1,
+		Rejected: 3,
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return keeper.GetState().SessionAdmission == stateEvent.SessionAdmission{Active: 2, Limit: 2, Queued: 1, Rejected: 3}
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func Test_consumeResourcePressureEvent(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		Publisher:        eventBus,
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	keeper.Subscribe(eventBus)
+
+	// when
+	eventBus.Publish(sessionEvent.AppTopicResourcePressure, sessionEvent.AppEventResourcePressure{
+		Shedding:      true,
+		CPU:           95,
+		Exceeded:      []string{"cpu"},
+		Deprioritized: []string{"session1"},
+		Rejected:      2,
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return keeper.GetState().ResourcePressure.Shedding
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, stateEvent.ResourcePressure{
+		Shedding:      true,
+		CPU:           95,
+		Exceeded:      []string{"cpu"},
+		Deprioritized: []string{"session1"},
+		Rejected:      2,
+	}, keeper.GetState().ResourcePressure)
+}
+
 func Test_consumeServiceSessionStatisticsEvent(t *testing.T) {
 	// given
 	eventBus := eventbus.New()
@@ -279,6 +441,48 @@ func Test_consumeServiceSessionStatisticsEvent(t *testing.T) {
 	)
 }
 
+func Test_consumeServiceSessionStatisticsEventWithTraffic(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		Publisher:        eventBus,
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	keeper.Subscribe(eventBus)
+	keeper.state.Sessions = []session.History{
+		{SessionID: nodeSession.ID("1")},
+	}
+	counters := traffic.Counters{"tcp/web": {Packets: 3, Bytes: 180}}
+
+	// when
+	eventBus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{
+		ID:      "1",
+		Up:      1,
+		Down:    2,
+		Traffic: counters,
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return len(keeper.GetState().SessionTraffic["1"]) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, counters, keeper.GetState().SessionTraffic["1"])
+
+	// when
+	eventBus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{
+		Status:  sessionEvent.RemovedStatus,
+		Session: sessionEvent.SessionContext{ID: "1"},
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		_, ok := keeper.GetState().SessionTraffic["1"]
+		return !ok
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 func Test_ConsumesServiceEvents(t *testing.T) {
 	mpr := mockProposalRepository{
 		priceToAdd: market.Price{
@@ -422,6 +626,101 @@ func Test_ConsumesConnectionInvoiceEvents(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func Test_ConsumesConsumerPaymentEvents(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		Publisher:        eventBus,
+		ServiceLister:    &serviceListerMock{},
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	keeper.state.Connections["session"] = stateEvent.Connection{}
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+
+	// when
+	eventBus.Publish(pingpongEvent.AppTopicConsumerPayment, pingpongEvent.AppEventConsumerPayment{
+		SessionID:         "unknown",
+		LastInvoiceAmount: big.NewInt(1),
+	})
+	eventBus.Publish(pingpongEvent.AppTopicConsumerPayment, pingpongEvent.AppEventConsumerPayment{
+		SessionID:         "session",
+		LastInvoiceAmount: big.NewInt(15),
+		Hashlock:          "0x1",
+		TotalPromised:     big.NewInt(100),
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return reflect.DeepEqual(stateEvent.ConsumerPayment{
+			LastInvoiceAmount: big.NewInt(15),
+			Hashlock:          "0x1",
+			TotalPromised:     big.NewInt(100),
+		}, keeper.GetConnection("session").Payment)
+	}, 2*time.Second, 10*time.Millisecond)
+	_, ok := keeper.GetState().Connections["unknown"]
+	assert.False(t, ok)
+}
+
+func Test_ConsumesNATEvents(t *testing.T) {
+	// given
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		Publisher:        eventBus,
+		ServiceLister:    &serviceListerMock{},
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+
+	// when
+	eventBus.Publish(natEvent.AppTopicTraversal, natEvent.BuildFailureEvent("attempt-1", "hole_punching", errors.New("too few connections")))
+	eventBus.Publish(natEvent.AppTopicTraversal, natEvent.BuildSuccessfulEvent("attempt-1", "hole_punching"))
+
+	// then
+	assert.Eventually(t, func() bool {
+		traversals := keeper.GetState().NATTraversals
+		return len(traversals) == 1 && len(traversals[0].Stages) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	stages := keeper.GetState().NATTraversals[0].Stages
+	assert.Equal(t, "attempt-1", keeper.GetState().NATTraversals[0].ID)
+	assert.False(t, stages[0].Successful)
+	assert.Equal(t, "too few connections", stages[0].Error)
+	assert.True(t, stages[1].Successful)
+	assert.Empty(t, stages[1].Error)
+	assert.False(t, stages[1].CreatedAt.IsZero())
+}
+
+func Test_NATTimelineIsBounded(t *testing.T) {
+	// given
+	deps := KeeperDeps{
+		Publisher:        eventbus.New(),
+		ServiceLister:    &serviceListerMock{},
+		IdentityProvider: &mocks.IdentityProvider{},
+		EarningsProvider: &mockEarningsProvider{},
+	}
+	keeper := NewKeeper(deps, time.Millisecond)
+
+	// when
+	for i := 0; i < natTimelineAttempts+5; i++ {
+		keeper.consumeNATEvent(natEvent.BuildSuccessfulEvent(fmt.Sprintf("attempt-%d", i), "port_mapping"))
+	}
+	for i := 0; i < natTimelineStages+5; i++ {
+		keeper.consumeNATEvent(natEvent.BuildSuccessfulEvent("attempt-last", "hole_punching"))
+	}
+
+	// then
+	traversals := keeper.GetState().NATTraversals
+	assert.Len(t, traversals, natTimelineAttempts)
+	assert.Equal(t, "attempt-6", traversals[0].ID)
+	assert.Equal(t, "attempt-last", traversals[len(traversals)-1].ID)
+	assert.Len(t, traversals[len(traversals)-1].Stages, natTimelineStages)
+}
+
 func Test_ConsumesBalanceChangeEvent(t *testing.T) {
 	// given
 	eventBus := eventbus.New()
@@ -597,14 +896,14 @@ func Test_incrementConnectionCount(t *testing.T) {
 	}
 
 	keeper.incrementConnectCount(myID, false)
-	s, found := serviceByID(keeper.GetState().Services, myID)
+	s, found := serviceByID(keeper.state.Services, myID)
 	assert.True(t, found)
 
 	assert.Equal(t, 1, s.ConnectionStatistics.Attempted)
 	assert.Equal(t, 0, s.ConnectionStatistics.Successful)
 
 	keeper.incrementConnectCount(myID, true)
-	s, found = serviceByID(keeper.GetState().Services, myID)
+	s, found = serviceByID(keeper.state.Services, myID)
 	assert.True(t, found)
 
 	assert.Equal(t, 1, s.ConnectionStatistics.Successful)
@@ -0,0 +1,250 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/asdine/storm/v3"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	journalOpSet    = "set"
+	journalOpDelete = "delete"
+
+	// journalHeaderSize is the size of record length and checksum preceding every journal record.
+	journalHeaderSize = 8
+	// journalMaxRecordSize protects from allocating memory for a corrupted record length.
+	journalMaxRecordSize = 16 << 20
+)
+
+// ErrJournalKey is returned when a journaled write is given a key which is not a string.
+var ErrJournalKey = errors.New("journal supports string keys only")
+
+type journalRecord struct {
+	Op     string `json:"op"`
+	Bucket string `json:"bucket"`
+	Key    []byte `json:"key"`
+	Value  []byte `json:"value,omitempty"`
+}
+
+// Journal is a write-ahead log guarding key value writes of the Bolt storage.
+// Every write is persisted to the journal before it is applied to the database and
+// the journal is cleared once the write is committed, so a write interrupted by a crash
+// or power loss is replayed on the next start.
+type Journal struct {
+	bolt *Bolt
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenJournal verifies the integrity of the given storage, replays writes left
+// in the journal at the given path and returns the journal ready for new writes.
+func OpenJournal(bolt *Bolt, path string) (*Journal, error) {
+	if err := bolt.Check(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("could not open storage journal: %w", err)
+	}
+
+	j := &Journal{
+		bolt: bolt,
+		file: file,
+	}
+
+	recovered, err := j.recover()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not recover storage journal: %w", err)
+	}
+	if recovered > 0 {
+		log.Warn().Msgf("Recovered %d interrupted storage writes from journal", recovered)
+	}
+
+	return j, nil
+}
+
+// GetValue gets key value.
+func (j *Journal) GetValue(bucket string, key interface{}, to interface{}) error {
+	return j.bolt.GetValue(bucket, key, to)
+}
+
+// SetValue sets key value through the journal.
+func (j *Journal) SetValue(bucket string, key interface{}, value interface{}) error {
+	k, ok := key.(string)
+	if !ok {
+		return ErrJournalKey
+	}
+
+	data, err := j.bolt.db.Codec().Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return j.write(journalRecord{Op: journalOpSet, Bucket: bucket, Key: []byte(k), Value: data})
+}
+
+// DeleteKey deletes the given key through the journal.
+func (j *Journal) DeleteKey(bucket string, key interface{}) error {
+	k, ok := key.(string)
+	if !ok {
+		return ErrJournalKey
+	}
+
+	return j.write(journalRecord{Op: journalOpDelete, Bucket: bucket, Key: []byte(k)})
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.file.Close()
+}
+
+func (j *Journal) write(record journalRecord) error {
+	defer j.bolt.Track("journal_"+record.Op, record.Bucket, string(record.Key))()
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.append(record); err != nil {
+		return fmt.Errorf("could not write storage journal: %w", err)
+	}
+
+	if err := j.apply(record); err != nil {
+		return err
+	}
+
+	// Committed records are not needed anymore. If clearing the journal does not reach the disk,
+	// the record is replayed on the next start which is harmless as writes are idempotent.
+	return j.clear()
+}
+
+func (j *Journal) append(record journalRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, journalHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[journalHeaderSize:], payload)
+
+	if _, err := j.file.Write(buf); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+func (j *Journal) apply(record journalRecord) error {
+	j.bolt.mux.Lock()
+	defer j.bolt.mux.Unlock()
+
+	switch record.Op {
+	case journalOpSet:
+		return j.bolt.db.SetBytes(record.Bucket, record.Key, record.Value)
+	case journalOpDelete:
+		err := j.bolt.db.Delete(record.Bucket, record.Key)
+		if errors.Is(err, storm.ErrNotFound) {
+			return nil
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown journal operation %q", record.Op)
+	}
+}
+
+func (j *Journal) clear() error {
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := j.file.Seek(0, io.SeekStart)
+	return err
+}
+
+// recover replays all complete records of the journal, a partially written trailing record is discarded.
+func (j *Journal) recover() (int, error) {
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	var records []journalRecord
+	reader := bufio.NewReader(j.file)
+	for {
+		record, err := readJournalRecord(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				log.Warn().Err(err).Msg("Discarding incomplete storage journal record")
+			}
+			break
+		}
+		records = append(records, record)
+	}
+
+	for _, record := range records {
+		if err := j.apply(record); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := j.clear(); err != nil {
+		return 0, err
+	}
+	return len(records), j.file.Sync()
+}
+
+func readJournalRecord(r io.Reader) (journalRecord, error) {
+	header := make([]byte, journalHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return journalRecord{}, errors.New("truncated record header")
+		}
+		return journalRecord{}, err
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size > journalMaxRecordSize {
+		return journalRecord{}, errors.New("record size exceeds the limit")
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return journalRecord{}, errors.New("truncated record payload")
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return journalRecord{}, errors.New("record checksum mismatch")
+	}
+
+	var record journalRecord
+	err := json.Unmarshal(payload, &record)
+	return record, err
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+)
+
+type journalTestValue struct {
+	Amount int
+}
+
+func openTestJournal(t *testing.T) (*Bolt, *Journal, string) {
+	dir := boltdbtest.CreateTempDir(t)
+	t.Cleanup(func() { boltdbtest.RemoveTempDir(t, dir) })
+
+	storage, err := NewStorage(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close() })
+
+	path := filepath.Join(dir, "test.journal")
+	journal, err := OpenJournal(storage, path)
+	require.NoError(t, err)
+	t.Cleanup(func() { journal.Close() })
+
+	return storage, journal, path
+}
+
+func journalSize(t *testing.T, path string) int64 {
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	return info.Size()
+}
+
+func TestJournal_SetValueAndDeleteKey(t *testing.T) {
+	storage, journal, path := openTestJournal(t)
+
+	assert.NoError(t, journal.SetValue(bucket, "key", journalTestValue{Amount: 10}))
+	assert.Zero(t, journalSize(t, path))
+
+	var value journalTestValue
+	assert.NoError(t, storage.GetValue(bucket, "key", &value))
+	assert.Equal(t, 10, value.Amount)
+
+	assert.NoError(t, journal.DeleteKey(bucket, "key"))
+	assert.Error(t, storage.GetValue(bucket, "key", &value))
+	assert.NoError(t, journal.DeleteKey(bucket, "key"))
+
+	assert.Equal(t, ErrJournalKey, journal.SetValue(bucket, 1, journalTestValue{}))
+}
+
+func TestJournal_RecoversInterruptedWrites(t *testing.T) {
+	storage, journal, path := openTestJournal(t)
+	assert.NoError(t, journal.SetValue(bucket, "deleted", journalTestValue{Amount: 1}))
+
+	// Simulate writes persisted to the journal, but interrupted before they were applied.
+	assert.NoError(t, journal.append(journalRecord{Op: journalOpSet, Bucket: bucket, Key: []byte("key"), Value: []byte(`{"Amount":20}`)}))
+	assert.NoError(t, journal.append(journalRecord{Op: journalOpDelete, Bucket: bucket, Key: []byte("deleted")}))
+	// And a record torn in the middle of writing.
+	_, err := journal.file.Write([]byte{0, 0, 0, 42, 1, 2})
+	assert.NoError(t, err)
+	assert.NoError(t, journal.Close())
+
+	recovered, err := OpenJournal(storage, path)
+	require.NoError(t, err)
+	defer recovered.Close()
+
+	var value journalTestValue
+	assert.NoError(t, storage.GetValue(bucket, "key", &value))
+	assert.Equal(t, 20, value.Amount)
+	assert.Error(t, storage.GetValue(bucket, "deleted", &value))
+	assert.Zero(t, journalSize(t, path))
+
+	assert.NoError(t, recovered.SetValue(bucket, "key", journalTestValue{Amount: 30}))
+	assert.NoError(t, storage.GetValue(bucket, "key", &value))
+	assert.Equal(t, 30, value.Amount)
+}
+
+func TestJournal_DiscardsCorruptedRecord(t *testing.T) {
+	storage, journal, path := openTestJournal(t)
+
+	assert.NoError(t, journal.append(journalRecord{Op: journalOpSet, Bucket: bucket, Key: []byte("key"), Value: []byte(`{"Amount":20}`)}))
+	// Flip a byte of the payload so the checksum no longer matches.
+	_, err := journal.file.WriteAt([]byte{'X'}, journalHeaderSize+2)
+	assert.NoError(t, err)
+	assert.NoError(t, journal.Close())
+
+	recovered, err := OpenJournal(storage, path)
+	require.NoError(t, err)
+	defer recovered.Close()
+
+	var value journalTestValue
+	assert.Error(t, storage.GetValue(bucket, "key", &value))
+	assert.Zero(t, journalSize(t, path))
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultSlowOperationThreshold is the duration after which storage operations are logged as slow.
+const DefaultSlowOperationThreshold = 500 * time.Millisecond
+
+// OperationStats aggregates the latencies of a storage operation on a bucket.
+type OperationStats struct {
+	Operation string
+	Bucket    string
+	Count     uint64
+	// Slow is the number of operations which took longer than the slow operation threshold.
+	Slow  uint64
+	Total time.Duration
+	Max   time.Duration
+}
+
+// Average returns the average latency of the operation.
+func (s OperationStats) Average() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+type operationKey struct {
+	operation string
+	bucket    string
+}
+
+type storageMetrics struct {
+	lock       sync.Mutex
+	threshold  time.Duration
+	operations map[operationKey]*OperationStats
+}
+
+func newStorageMetrics() *storageMetrics {
+	return &storageMetrics{
+		threshold:  DefaultSlowOperationThreshold,
+		operations: make(map[operationKey]*OperationStats),
+	}
+}
+
+func (m *storageMetrics) setThreshold(threshold time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.threshold = threshold
+}
+
+func (m *storageMetrics) observe(operation, bucket string, key interface{}, took time.Duration) {
+	m.lock.Lock()
+	k := operationKey{operation: operation, bucket: bucket}
+	stats, ok := m.operations[k]
+	if !ok {
+		stats = &OperationStats{Operation: operation, Bucket: bucket}
+		m.operations[k] = stats
+	}
+	stats.Count++
+	stats.Total += took
+	if took > stats.Max {
+		stats.Max = took
+	}
+	slow := m.threshold > 0 && took > m.threshold
+	if slow {
+		stats.Slow++
+	}
+	m.lock.Unlock()
+
+	if !slow {
+		return
+	}
+	if key != nil {
+		log.Warn().Msgf("Slow storage operation %s on bucket %q with key digest %s took %s", operation, bucket, keyDigest(key), took)
+	} else {
+		log.Warn().Msgf("Slow storage operation %s on bucket %q took %s", operation, bucket, took)
+	}
+}
+
+// keyDigest returns a short hash of the key, so that keys and field values are not leaked into the logs
+// while repeated slow operations on the same key can still be told apart.
+func keyDigest(key interface{}) string {
+	sum := sha256.Sum256([]byte(fmt.Sprint(key)))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (m *storageMetrics) snapshot() []OperationStats {
+	m.lock.Lock()
+	res := make([]OperationStats, 0, len(m.operations))
+	for _, stats := range m.operations {
+		res = append(res, *stats)
+	}
+	m.lock.Unlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Operation != res[j].Operation {
+			return res[i].Operation < res[j].Operation
+		}
+		return res[i].Bucket < res[j].Bucket
+	})
+	return res
+}
+
+// SetSlowOperationThreshold sets the duration after which storage operations are logged as slow, zero disables the logging.
+func (b *Bolt) SetSlowOperationThreshold(threshold time.Duration) {
+	b.metrics.setThreshold(threshold)
+}
+
+// Metrics returns the latencies of the storage operations aggregated by operation and bucket.
+func (b *Bolt) Metrics() []OperationStats {
+	return b.metrics.snapshot()
+}
+
+// Track starts timing a storage operation, the returned function records it once the operation is done.
+// The time spent waiting for the storage lock is included, so operations stalled by a slow write are accounted for.
+// Digest of the key is included in the slow operation log, nil if the operation is not bound to a key.
+func (b *Bolt) Track(operation, bucket string, key interface{}) func() {
+	started := time.Now()
+	return func() {
+		b.metrics.observe(operation, bucket, key, time.Since(started))
+	}
+}
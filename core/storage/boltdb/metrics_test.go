@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StorageMetrics(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.NoError(t, err)
+	defer close()
+
+	assert.NoError(t, storage.Store(bucket, &myTestType{ID: 1}))
+	assert.NoError(t, storage.SetValue("values", "key", "value"))
+	var value string
+	assert.NoError(t, storage.GetValue("values", "key", &value))
+	assert.NoError(t, storage.GetValue("values", "key", &value))
+
+	metrics := storage.Metrics()
+	assert.Len(t, metrics, 3)
+	assert.Equal(t, "get", metrics[0].Operation)
+	assert.Equal(t, "values", metrics[0].Bucket)
+	assert.Equal(t, uint64(2), metrics[0].Count)
+	assert.Equal(t, "set", metrics[1].Operation)
+	assert.Equal(t, "store", metrics[2].Operation)
+	assert.Equal(t, bucket, metrics[2].Bucket)
+	assert.Equal(t, uint64(1), metrics[2].Count)
+}
+
+func Test_StorageMetrics_CountsSlowOperations(t *testing.T) {
+	m := newStorageMetrics()
+	m.setThreshold(100 * time.Millisecond)
+
+	m.observe("get", "values", "key", 10*time.Millisecond)
+	m.observe("get", "values", "key", 200*time.Millisecond)
+	m.observe("get", "values", "key", 30*time.Millisecond)
+
+	stats := m.snapshot()
+	assert.Len(t, stats, 1)
+	assert.Equal(t, uint64(3), stats[0].Count)
+	assert.Equal(t, uint64(1), stats[0].Slow)
+	assert.Equal(t, 200*time.Millisecond, stats[0].Max)
+	assert.Equal(t, 80*time.Millisecond, stats[0].Average())
+
+	m.setThreshold(0)
+	m.observe("get", "values", "key", time.Second)
+	assert.Equal(t, uint64(1), m.snapshot()[0].Slow)
+}
+
+func Test_KeyDigest(t *testing.T) {
+	digest := keyDigest("0x0c9fa2e7d3a41b7e")
+	assert.Len(t, digest, 16)
+	assert.NotContains(t, digest, "0c9fa2e7d3a41b7e")
+	assert.Equal(t, digest, keyDigest("0x0c9fa2e7d3a41b7e"))
+	assert.NotEqual(t, digest, keyDigest("0x0c9fa2e7d3a41b7f"))
+}
@@ -49,4 +49,10 @@ var Sequence = []migrations.Migration{
 			2021, 10, 11, 0, 00, 00, 0, time.UTC),
 		Migrate: migrations.MigrateRegistrationState,
 	},
+	{
+		Name: "session-history-index",
+		Date: time.Date(
+			2022, 10, 3, 12, 00, 00, 0, time.UTC),
+		Migrate: migrations.ReindexSessionHistory,
+	},
 }
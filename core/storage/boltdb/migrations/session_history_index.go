@@ -0,0 +1,37 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"errors"
+
+	"github.com/asdine/storm/v3"
+	"github.com/mysteriumnetwork/node/consumer/session"
+)
+
+const sessionHistoryBucket = "session-history"
+
+// ReindexSessionHistory builds the indexes of session history lookup fields for the already stored sessions.
+func ReindexSessionHistory(db *storm.DB) error {
+	err := db.From(sessionHistoryBucket).ReIndex(&session.History{})
+	if errors.Is(err, storm.ErrNotFound) {
+		// Nothing to reindex
+		return nil
+	}
+	return err
+}
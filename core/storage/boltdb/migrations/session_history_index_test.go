@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"testing"
+
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/identity"
+	node_session "github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/bbolt"
+)
+
+func TestReindexSessionHistoryWithNoData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	err := ReindexSessionHistory(db)
+	assert.NoError(t, err)
+}
+
+func TestReindexSessionHistoryWithData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	consumerID := identity.FromAddress("consumer1")
+	err := db.From(sessionHistoryBucket).Save(&consumer_session.History{
+		SessionID:   node_session.ID("session1"),
+		ConsumerID:  consumerID,
+		ServiceType: "wireguard",
+	})
+	assert.NoError(t, err)
+
+	// drop the index to mimic sessions stored before the field was indexed
+	err = db.Bolt.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket([]byte(sessionHistoryBucket)).Bucket([]byte("History")).DeleteBucket([]byte("__storm_index_ConsumerID"))
+	})
+	assert.NoError(t, err)
+
+	var histories []consumer_session.History
+	err = db.From(sessionHistoryBucket).Find("ConsumerID", consumerID, &histories)
+	assert.Error(t, err)
+
+	err = ReindexSessionHistory(db)
+	assert.NoError(t, err)
+
+	err = db.From(sessionHistoryBucket).Find("ConsumerID", consumerID, &histories)
+	assert.NoError(t, err)
+	assert.Len(t, histories, 1)
+}
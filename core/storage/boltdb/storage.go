@@ -18,17 +18,20 @@
 package boltdb
 
 import (
+	"fmt"
 	"path/filepath"
 	"sync"
 
 	"github.com/asdine/storm/v3"
 	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
 )
 
 // Bolt is a wrapper around boltdb
 type Bolt struct {
-	mux sync.RWMutex
-	db  *storm.DB
+	mux     sync.RWMutex
+	db      *storm.DB
+	metrics *storageMetrics
 }
 
 // NewStorage creates a new BoltDB storage for service promises
@@ -40,12 +43,14 @@ func NewStorage(path string) (*Bolt, error) {
 func openDB(name string) (*Bolt, error) {
 	db, err := storm.Open(name)
 	return &Bolt{
-		db: db,
+		db:      db,
+		metrics: newStorageMetrics(),
 	}, errors.Wrap(err, "failed to open boltDB")
 }
 
 // GetValue gets key value
 func (b *Bolt) GetValue(bucket string, key interface{}, to interface{}) error {
+	defer b.Track("get", bucket, key)()
 	b.mux.RLock()
 	defer b.mux.RUnlock()
 	return b.db.Get(bucket, key, to)
@@ -53,6 +58,7 @@ func (b *Bolt) GetValue(bucket string, key interface{}, to interface{}) error {
 
 // SetValue sets key value
 func (b *Bolt) SetValue(bucket string, key interface{}, to interface{}) error {
+	defer b.Track("set", bucket, key)()
 	b.mux.Lock()
 	defer b.mux.Unlock()
 	return b.db.Set(bucket, key, to)
@@ -60,6 +66,7 @@ func (b *Bolt) SetValue(bucket string, key interface{}, to interface{}) error {
 
 // Store allows to keep struct grouped by the bucket
 func (b *Bolt) Store(bucket string, data interface{}) error {
+	defer b.Track("store", bucket, nil)()
 	b.mux.Lock()
 	defer b.mux.Unlock()
 	return b.db.From(bucket).Save(data)
@@ -67,6 +74,7 @@ func (b *Bolt) Store(bucket string, data interface{}) error {
 
 // GetAllFrom allows to get all structs from the bucket
 func (b *Bolt) GetAllFrom(bucket string, data interface{}) error {
+	defer b.Track("get_all", bucket, nil)()
 	b.mux.RLock()
 	defer b.mux.RUnlock()
 	return b.db.From(bucket).All(data)
@@ -74,6 +82,7 @@ func (b *Bolt) GetAllFrom(bucket string, data interface{}) error {
 
 // Delete removes the given struct from the given bucket
 func (b *Bolt) Delete(bucket string, data interface{}) error {
+	defer b.Track("delete", bucket, nil)()
 	b.mux.Lock()
 	defer b.mux.Unlock()
 	return b.db.From(bucket).DeleteStruct(data)
@@ -81,6 +90,7 @@ func (b *Bolt) Delete(bucket string, data interface{}) error {
 
 // DeleteKey the given struct from the given bucket
 func (b *Bolt) DeleteKey(bucket string, key interface{}) error {
+	defer b.Track("delete_key", bucket, key)()
 	b.mux.Lock()
 	defer b.mux.Unlock()
 	return b.db.Delete(bucket, key)
@@ -88,6 +98,7 @@ func (b *Bolt) DeleteKey(bucket string, key interface{}) error {
 
 // Update allows to update the struct in the given bucket
 func (b *Bolt) Update(bucket string, object interface{}) error {
+	defer b.Track("update", bucket, nil)()
 	b.mux.Lock()
 	defer b.mux.Unlock()
 	return b.db.From(bucket).Update(object)
@@ -95,6 +106,7 @@ func (b *Bolt) Update(bucket string, object interface{}) error {
 
 // GetOneByField returns an object from the given bucket by the given field
 func (b *Bolt) GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error {
+	defer b.Track("get_one", bucket, fieldName+"="+fmt.Sprint(key))()
 	b.mux.RLock()
 	defer b.mux.RUnlock()
 	return b.db.From(bucket).One(fieldName, key, to)
@@ -102,6 +114,7 @@ func (b *Bolt) GetOneByField(bucket string, fieldName string, key interface{}, t
 
 // GetLast returns the last entry in the bucket
 func (b *Bolt) GetLast(bucket string, to interface{}) error {
+	defer b.Track("get_last", bucket, nil)()
 	b.mux.RLock()
 	defer b.mux.RUnlock()
 	return b.db.From(bucket).Select().Reverse().First(to)
@@ -114,11 +127,29 @@ func (b *Bolt) GetBuckets() []string {
 	return b.db.Bucket()
 }
 
-// DB returns raw storm DB.
+// DB returns raw storm DB, callers account their operations with Track.
 func (b *Bolt) DB() *storm.DB {
 	return b.db
 }
 
+// Check verifies the consistency of the database pages.
+func (b *Bolt) Check() error {
+	defer b.Track("check", "", nil)()
+	b.mux.RLock()
+	defer b.mux.RUnlock()
+
+	return b.db.Bolt.View(func(tx *bbolt.Tx) error {
+		var checkErr error
+		// The channel has to be drained for the check to complete.
+		for err := range tx.Check() {
+			if checkErr == nil {
+				checkErr = fmt.Errorf("storage integrity check failed: %w", err)
+			}
+		}
+		return checkErr
+	})
+}
+
 // Close closes database
 func (b *Bolt) Close() error {
 	b.mux.Lock()
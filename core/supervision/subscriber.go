@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package supervision
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+type subscription struct {
+	topic   string
+	handler reflect.Value
+	wrapped interface{}
+}
+
+// subscriber subscribes the event handlers of the subsystem, recovering from their panics.
+type subscriber struct {
+	eventbus.Subscriber
+
+	supervisor *Supervisor
+	subsystem  string
+
+	lock          sync.Mutex
+	subscriptions []subscription
+}
+
+// Subscriber returns the event subscriber which isolates the panics of the subsystem event handlers.
+// Panicked handler is reported and keeps receiving the following events.
+func (s *Supervisor) Subscriber(subsystem string, bus eventbus.Subscriber) eventbus.Subscriber {
+	return &subscriber{
+		Subscriber: bus,
+		supervisor: s,
+		subsystem:  subsystem,
+	}
+}
+
+func (sub *subscriber) Subscribe(topic string, fn interface{}) error {
+	return sub.Subscriber.Subscribe(topic, sub.wrap(topic, fn))
+}
+
+func (sub *subscriber) SubscribeAsync(topic string, fn interface{}) error {
+	return sub.Subscriber.SubscribeAsync(topic, sub.wrap(topic, fn))
+}
+
+func (sub *subscriber) SubscribeWithUID(topic, uid string, fn interface{}) error {
+	return sub.Subscriber.SubscribeWithUID(topic, uid, sub.wrap(topic+uid, fn))
+}
+
+func (sub *subscriber) Unsubscribe(topic string, fn interface{}) error {
+	return sub.Subscriber.Unsubscribe(topic, sub.unwrap(topic, fn))
+}
+
+func (sub *subscriber) UnsubscribeWithUID(topic, uid string, fn interface{}) error {
+	return sub.Subscriber.UnsubscribeWithUID(topic, uid, sub.unwrap(topic+uid, fn))
+}
+
+func (sub *subscriber) wrap(topic string, fn interface{}) interface{} {
+	wrapped := sub.supervisor.wrap(sub.subsystem, fn)
+
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	sub.subscriptions = append(sub.subscriptions, subscription{topic: topic, handler: reflect.ValueOf(fn), wrapped: wrapped})
+
+	return wrapped
+}
+
+// unwrap finds the wrapped handler the same way the event bus matches the handlers.
+func (sub *subscriber) unwrap(topic string, fn interface{}) interface{} {
+	handler := reflect.ValueOf(fn)
+	if handler.Kind() != reflect.Func {
+		return fn
+	}
+
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+
+	for i, s := range sub.subscriptions {
+		if s.topic == topic && s.handler.Type() == handler.Type() && s.handler.Pointer() == handler.Pointer() {
+			sub.subscriptions = append(sub.subscriptions[:i], sub.subscriptions[i+1:]...)
+			return s.wrapped
+		}
+	}
+	return fn
+}
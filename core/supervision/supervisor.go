@@ -0,0 +1,206 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package supervision
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+const (
+	initialBackoff = time.Second
+	maxBackoff     = time.Minute
+	// degradedPeriod is how long the subsystem is reported as degraded after its last panic.
+	degradedPeriod = 10 * time.Minute
+)
+
+// SubsystemStatus describes the panics recovered in the subsystem.
+type SubsystemStatus struct {
+	Name        string
+	Panics      int
+	LastPanic   string
+	LastPanicAt time.Time
+}
+
+// Supervisor isolates the panics of the node subsystems, so a failing subsystem
+// is restarted and reported as degraded instead of crashing the whole process.
+type Supervisor struct {
+	lock       sync.Mutex
+	subsystems map[string]*SubsystemStatus
+	now        func() time.Time
+	backoff    time.Duration
+	stop       chan struct{}
+	stopOnce   sync.Once
+}
+
+// New returns a new subsystem supervisor.
+func New() *Supervisor {
+	return &Supervisor{
+		subsystems: make(map[string]*SubsystemStatus),
+		now:        time.Now,
+		backoff:    initialBackoff,
+		stop:       make(chan struct{}),
+	}
+}
+
+// Go runs the long running function of the subsystem in a goroutine.
+// The function is restarted with exponential backoff if it panics and is not restarted once it returns.
+func (s *Supervisor) Go(subsystem string, run func()) {
+	go func() {
+		backoff := s.backoff
+		for {
+			started := s.now()
+			if !s.panics(subsystem, run) {
+				return
+			}
+
+			if s.now().Sub(started) > maxBackoff {
+				backoff = s.backoff
+			}
+			log.Warn().Msgf("Restarting subsystem %q in %s", subsystem, backoff)
+
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+	}()
+}
+
+// Stop stops restarting the panicked subsystems.
+func (s *Supervisor) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Status returns the subsystems which had panics, sorted by name.
+func (s *Supervisor) Status() []SubsystemStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make([]SubsystemStatus, 0, len(s.subsystems))
+	for _, status := range s.subsystems {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Degraded returns the subsystems which recently panicked.
+func (s *Supervisor) Degraded() []SubsystemStatus {
+	var result []SubsystemStatus
+	for _, status := range s.Status() {
+		if s.now().Sub(status.LastPanicAt) < degradedPeriod {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+// Run calls the function and returns its panic as an error, so the caller can stop the work the panic
+// interrupted instead of restarting it or crashing the process. Session payment loops are run this way,
+// as a session which can not be paid for must not go on.
+func Run(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error().Msgf("Recovered from panic: %v\n%s", r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn()
+}
+
+// EventBus returns the event bus which isolates the panics of the subsystem event handlers, see Subscriber.
+func (s *Supervisor) EventBus(subsystem string, bus eventbus.EventBus) eventbus.EventBus {
+	return struct {
+		eventbus.Publisher
+		eventbus.Subscriber
+	}{
+		Publisher:  bus,
+		Subscriber: s.Subscriber(subsystem, bus),
+	}
+}
+
+// panics runs the function and reports whether it has panicked.
+func (s *Supervisor) panics(subsystem string, run func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			s.recordPanic(subsystem, r)
+		}
+	}()
+
+	run()
+	return false
+}
+
+func (s *Supervisor) recordPanic(subsystem string, r interface{}) {
+	log.Error().Str("subsystem", subsystem).Msgf("Recovered from panic: %v\n%s", r, debug.Stack())
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	status, ok := s.subsystems[subsystem]
+	if !ok {
+		status = &SubsystemStatus{Name: subsystem}
+		s.subsystems[subsystem] = status
+	}
+	status.Panics++
+	status.LastPanic = fmt.Sprint(r)
+	status.LastPanicAt = s.now()
+}
+
+// wrap returns the event handler which recovers from the panics of the given handler.
+func (s *Supervisor) wrap(subsystem string, fn interface{}) interface{} {
+	handler := reflect.ValueOf(fn)
+	if handler.Kind() != reflect.Func {
+		return fn
+	}
+
+	return reflect.MakeFunc(handler.Type(), func(args []reflect.Value) (results []reflect.Value) {
+		defer func() {
+			if r := recover(); r != nil {
+				s.recordPanic(subsystem, r)
+				results = make([]reflect.Value, handler.Type().NumOut())
+				for i := range results {
+					results[i] = reflect.Zero(handler.Type().Out(i))
+				}
+			}
+		}()
+
+		return handler.Call(args)
+	}).Interface()
+}
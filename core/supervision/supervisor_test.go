@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package supervision
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+func TestSupervisor_RestartsPanickedSubsystem(t *testing.T) {
+	supervisor := New()
+	supervisor.backoff = time.Millisecond
+	defer supervisor.Stop()
+
+	var runs int32
+	done := make(chan struct{})
+	supervisor.Go("payments", func() {
+		if atomic.AddInt32(&runs, 1) < 3 {
+			panic("boom")
+		}
+		close(done)
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("subsystem was not restarted")
+	}
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&runs))
+	status := supervisor.Status()
+	assert.Len(t, status, 1)
+	assert.Equal(t, "payments", status[0].Name)
+	assert.Equal(t, 2, status[0].Panics)
+	assert.Equal(t, "boom", status[0].LastPanic)
+}
+
+func TestSupervisor_DoesNotRestartAfterStop(t *testing.T) {
+	supervisor := New()
+	supervisor.backoff = 10 * time.Millisecond
+
+	var runs int32
+	supervisor.Go("quality", func() {
+		atomic.AddInt32(&runs, 1)
+		panic("boom")
+	})
+	assert.Eventually(t, func() bool { return len(supervisor.Status()) == 1 }, time.Second, time.Millisecond)
+	supervisor.Stop()
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&runs))
+}
+
+func TestSupervisor_ReportsRecentPanicsAsDegraded(t *testing.T) {
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	supervisor := New()
+	supervisor.now = func() time.Time { return now }
+
+	supervisor.recordPanic("state", "boom")
+	assert.Len(t, supervisor.Degraded(), 1)
+
+	now = now.Add(degradedPeriod)
+	assert.Empty(t, supervisor.Degraded())
+	assert.Len(t, supervisor.Status(), 1)
+}
+
+func TestSubscriber_IsolatesHandlerPanics(t *testing.T) {
+	supervisor := New()
+	bus := eventbus.New()
+	subscriber := supervisor.Subscriber("state", bus)
+
+	var handled []string
+	handler := func(e string) {
+		if e == "bad" {
+			panic("bad event")
+		}
+		handled = append(handled, e)
+	}
+	assert.NoError(t, subscriber.Subscribe("topic", handler))
+
+	bus.Publish("topic", "bad")
+	bus.Publish("topic", "good")
+	assert.Equal(t, []string{"good"}, handled)
+	assert.Equal(t, 1, supervisor.Status()[0].Panics)
+
+	assert.NoError(t, subscriber.Unsubscribe("topic", handler))
+	bus.Publish("topic", "ignored")
+	assert.Equal(t, []string{"good"}, handled)
+}
+
+func TestRun_ReturnsPanicAsError(t *testing.T) {
+	err := Run(func() error {
+		panic("boom")
+	})
+	assert.EqualError(t, err, "panic: boom")
+
+	assert.NoError(t, Run(func() error { return nil }))
+}
+
+func TestEventBus_IsolatesHandlerPanics(t *testing.T) {
+	supervisor := New()
+	bus := supervisor.EventBus("discovery", eventbus.New())
+
+	var handled []string
+	assert.NoError(t, bus.Subscribe("topic", func(e string) {
+		if e == "bad" {
+			panic("bad event")
+		}
+		handled = append(handled, e)
+	}))
+
+	bus.Publish("topic", "bad")
+	bus.Publish("topic", "good")
+	assert.Equal(t, []string{"good"}, handled)
+	assert.Equal(t, "discovery", supervisor.Status()[0].Name)
+}
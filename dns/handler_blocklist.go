@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"strings"
+
+	"github.com/miekg/dns"
+	"github.com/rs/zerolog/log"
+)
+
+// BlockDomains creates a DNS handler which refuses to resolve the given domains and their subdomains.
+func BlockDomains(resolver dns.Handler, domains []string) dns.Handler {
+	blocked := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		blocked[normalizeDomain(domain)] = struct{}{}
+	}
+
+	return &blocklistHandler{
+		resolver: resolver,
+		blocked:  blocked,
+	}
+}
+
+type blocklistHandler struct {
+	resolver dns.Handler
+	blocked  map[string]struct{}
+}
+
+func (bh *blocklistHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	for _, question := range req.Question {
+		if bh.isBlocked(question.Name) {
+			log.Debug().Msgf("Refusing to resolve blocked domain: %s", question.Name)
+
+			resp := &dns.Msg{}
+			resp.SetRcode(req, dns.RcodeNameError)
+			writer.WriteMsg(resp)
+			return
+		}
+	}
+
+	bh.resolver.ServeDNS(writer, req)
+}
+
+func (bh *blocklistHandler) isBlocked(name string) bool {
+	name = normalizeDomain(name)
+	for name != "" {
+		if _, ok := bh.blocked[name]; ok {
+			return true
+		}
+
+		i := strings.Index(name, ".")
+		if i < 0 {
+			return false
+		}
+		name = name[i+1:]
+	}
+	return false
+}
+
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, "."))
+}
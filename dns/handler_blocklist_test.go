@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BlockDomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		blocked bool
+	}{
+		{"should block listed domain", "ads.com.", true},
+		{"should block subdomain of listed domain", "cdn.Ads.com.", true},
+		{"should not block parent of listed domain", "example.com.", false},
+		{"should not block domain with the same suffix", "notads.com.", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved := false
+			handler := BlockDomains(
+				dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+					resolved = true
+					resp := &dns.Msg{}
+					resp.SetReply(req)
+					writer.WriteMsg(resp)
+				}),
+				[]string{"ads.com", "tracker.example.com."},
+			)
+
+			req := &dns.Msg{}
+			req.SetQuestion(tt.query, dns.TypeA)
+			writer := &recordingWriter{}
+			handler.ServeDNS(writer, req)
+
+			assert.Equal(t, !tt.blocked, resolved)
+			if tt.blocked {
+				assert.Equal(t, dns.RcodeNameError, writer.responseMsg.Rcode)
+			} else {
+				assert.Equal(t, dns.RcodeSuccess, writer.responseMsg.Rcode)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// CacheAnswers creates a DNS handler which reuses resolved answers until their TTL expires.
+func CacheAnswers(resolver dns.Handler, size int) dns.Handler {
+	return &cacheHandler{
+		resolver: resolver,
+		size:     size,
+		now:      time.Now,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+type cacheEntry struct {
+	msg     *dns.Msg
+	stored  time.Time
+	expires time.Time
+}
+
+type cacheHandler struct {
+	resolver dns.Handler
+	size     int
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func (ch *cacheHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	if len(req.Question) != 1 || ch.size <= 0 {
+		ch.resolver.ServeDNS(writer, req)
+		return
+	}
+
+	key := cacheKey(req.Question[0])
+	if resp, ok := ch.get(key); ok {
+		resp.Id = req.Id
+		writer.WriteMsg(resp)
+		return
+	}
+
+	resolverWriter := &recordingWriter{writer: writer}
+	ch.resolver.ServeDNS(resolverWriter, req)
+	resp := resolverWriter.responseMsg
+	if resp == nil {
+		return
+	}
+
+	ch.put(key, resp)
+	writer.WriteMsg(resp)
+}
+
+func (ch *cacheHandler) get(key string) (*dns.Msg, bool) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	entry, ok := ch.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	now := ch.now()
+	if !now.Before(entry.expires) {
+		delete(ch.entries, key)
+		return nil, false
+	}
+
+	// Answer with the time to live which is left, the same way the upstream would.
+	resp := entry.msg.Copy()
+	elapsed := uint32(now.Sub(entry.stored).Seconds())
+	for _, records := range [][]dns.RR{resp.Answer, resp.Ns, resp.Extra} {
+		for _, record := range records {
+			if record.Header().Rrtype == dns.TypeOPT {
+				continue
+			}
+			record.Header().Ttl -= elapsed
+		}
+	}
+	return resp, true
+}
+
+func (ch *cacheHandler) put(key string, resp *dns.Msg) {
+	if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+		return
+	}
+	ttl, ok := minTTL(resp)
+	if !ok || ttl == 0 {
+		return
+	}
+
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+
+	now := ch.now()
+	if len(ch.entries) >= ch.size {
+		ch.evict(now)
+	}
+	ch.entries[key] = cacheEntry{
+		msg:     resp.Copy(),
+		stored:  now,
+		expires: now.Add(time.Duration(ttl) * time.Second),
+	}
+}
+
+// evict drops the expired entries, or any entry if none of them are expired yet.
+func (ch *cacheHandler) evict(now time.Time) {
+	for key, entry := range ch.entries {
+		if !now.Before(entry.expires) {
+			delete(ch.entries, key)
+		}
+	}
+	for key := range ch.entries {
+		if len(ch.entries) < ch.size {
+			return
+		}
+		delete(ch.entries, key)
+	}
+}
+
+func minTTL(resp *dns.Msg) (ttl uint32, ok bool) {
+	for _, records := range [][]dns.RR{resp.Answer, resp.Ns} {
+		for _, record := range records {
+			if !ok || record.Header().Ttl < ttl {
+				ttl = record.Header().Ttl
+				ok = true
+			}
+		}
+	}
+	return ttl, ok
+}
+
+func cacheKey(question dns.Question) string {
+	return strings.ToLower(question.Name) + "/" + dns.TypeToString[question.Qtype] + "/" + dns.ClassToString[question.Qclass]
+}
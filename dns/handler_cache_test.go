@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CacheAnswers(t *testing.T) {
+	calls := 0
+	resolver := dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+		calls++
+		resp := &dns.Msg{}
+		resp.SetReply(req)
+		resp.Answer = []dns.RR{
+			&dns.A{
+				Hdr: dns.RR_Header{Name: "cached.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP("0.0.0.1"),
+			},
+		}
+		writer.WriteMsg(resp)
+	})
+
+	now := time.Now()
+	handler := CacheAnswers(resolver, 10).(*cacheHandler)
+	handler.now = func() time.Time { return now }
+
+	req := &dns.Msg{}
+	req.SetQuestion("cached.com.", dns.TypeA)
+
+	writer := &recordingWriter{}
+	handler.ServeDNS(writer, req)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, uint32(60), writer.responseMsg.Answer[0].Header().Ttl)
+
+	now = now.Add(20 * time.Second)
+	writer = &recordingWriter{}
+	handler.ServeDNS(writer, req)
+	assert.Equal(t, 1, calls, "should answer from the cache")
+	assert.Equal(t, req.Id, writer.responseMsg.Id)
+	assert.Equal(t, uint32(40), writer.responseMsg.Answer[0].Header().Ttl)
+
+	now = now.Add(40 * time.Second)
+	writer = &recordingWriter{}
+	handler.ServeDNS(writer, req)
+	assert.Equal(t, 2, calls, "should resolve again when the TTL expires")
+	assert.Equal(t, uint32(60), writer.responseMsg.Answer[0].Header().Ttl)
+}
+
+func Test_CacheAnswers_SkipsFailures(t *testing.T) {
+	calls := 0
+	resolver := dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+		calls++
+		resp := &dns.Msg{}
+		resp.SetRcode(req, dns.RcodeServerFailure)
+		writer.WriteMsg(resp)
+	})
+	handler := CacheAnswers(resolver, 10)
+
+	req := &dns.Msg{}
+	req.SetQuestion("failing.com.", dns.TypeA)
+	handler.ServeDNS(&recordingWriter{}, req)
+	handler.ServeDNS(&recordingWriter{}, req)
+
+	assert.Equal(t, 2, calls)
+}
@@ -41,6 +41,24 @@ func ResolveViaSystem() (dns.Handler, error) {
 	return handler, nil
 }
 
+// ResolveVia creates DNS handler proxying queries to the given DNS servers.
+func ResolveVia(servers []string) dns.Handler {
+	handler := &proxyHandler{
+		client: &dns.Client{
+			DialTimeout:  dnsTimeout,
+			ReadTimeout:  dnsTimeout,
+			WriteTimeout: dnsTimeout,
+		},
+	}
+	for _, server := range servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+		handler.proxyAddrs = append(handler.proxyAddrs, server)
+	}
+	return handler
+}
+
 type proxyHandler struct {
 	proxyAddrs []string
 	client     *dns.Client
@@ -0,0 +1,190 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultProfile is the local resolver profile used when the connection does not ask for a specific one.
+const DefaultProfile = "default"
+
+// Profile defines where the local resolver forwards the queries and which domains it refuses to resolve.
+type Profile struct {
+	Upstreams []string
+	Blocklist []string
+}
+
+// ParseProfiles builds the resolver profiles from upstream and blocklist entries in the form "[profile=]value".
+// Entries without the profile name belong to the default profile.
+func ParseProfiles(upstreams, blocklist []string) map[string]Profile {
+	profiles := make(map[string]Profile)
+	for _, entry := range upstreams {
+		name, value := splitProfileEntry(entry)
+		p := profiles[name]
+		p.Upstreams = append(p.Upstreams, value)
+		profiles[name] = p
+	}
+	for _, entry := range blocklist {
+		name, value := splitProfileEntry(entry)
+		p := profiles[name]
+		p.Blocklist = append(p.Blocklist, value)
+		profiles[name] = p
+	}
+	return profiles
+}
+
+func splitProfileEntry(entry string) (profile, value string) {
+	if i := strings.Index(entry, "="); i >= 0 {
+		return strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+	}
+	return DefaultProfile, strings.TrimSpace(entry)
+}
+
+// LocalResolverConfig configures the local resolver.
+type LocalResolverConfig struct {
+	Address   string
+	Port      int
+	CacheSize int
+	Profiles  map[string]Profile
+}
+
+// LocalResolver is an embedded caching DNS forwarder the consumer tunnel can point the system at.
+// Being the only resolver of the system while connected, it is the single place where all the queries pass through.
+type LocalResolver struct {
+	config  LocalResolverConfig
+	handler *switchHandler
+
+	mu      sync.Mutex
+	proxy   *Proxy
+	stopped bool
+}
+
+// NewLocalResolver returns a new instance of the local resolver.
+func NewLocalResolver(config LocalResolverConfig) *LocalResolver {
+	return &LocalResolver{
+		config:  config,
+		handler: &switchHandler{},
+	}
+}
+
+// Serve starts the resolver if it is not running yet and makes it resolve the queries according to the given profile.
+// Given tunnel upstreams are used unless the profile defines its own.
+// Returns the DNS servers the system should be configured with.
+func (lr *LocalResolver) Serve(profile string, upstreams []string) ([]string, error) {
+	if profile == "" {
+		profile = DefaultProfile
+	}
+	p, ok := lr.config.Profiles[profile]
+	if !ok && profile != DefaultProfile {
+		return nil, fmt.Errorf("unknown DNS profile: %s", profile)
+	}
+
+	if len(p.Upstreams) > 0 {
+		upstreams = p.Upstreams
+	}
+	if len(upstreams) == 0 {
+		upstreams = lr.systemUpstreams()
+	}
+	if len(upstreams) == 0 {
+		return nil, errors.New("no upstream DNS servers available")
+	}
+
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	if lr.stopped {
+		return nil, errors.New("local DNS resolver is stopped")
+	}
+
+	log.Info().Msgf("Local DNS resolver uses profile %q with upstreams %v", profile, upstreams)
+	lr.handler.set(BlockDomains(CacheAnswers(ResolveVia(upstreams), lr.config.CacheSize), p.Blocklist))
+
+	if lr.proxy == nil {
+		proxy := NewProxy(lr.config.Address, lr.config.Port, lr.handler)
+		if err := proxy.Run(); err != nil {
+			return nil, err
+		}
+		lr.proxy = proxy
+	}
+
+	return []string{lr.config.Address}, nil
+}
+
+// systemUpstreams returns the system DNS servers, leaving out the local resolver itself.
+func (lr *LocalResolver) systemUpstreams() []string {
+	servers, err := ConfiguredServers()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not get system DNS servers for the local resolver")
+		return nil
+	}
+
+	upstreams := make([]string, 0, len(servers))
+	for _, server := range servers {
+		if server != lr.config.Address {
+			upstreams = append(upstreams, server)
+		}
+	}
+	return upstreams
+}
+
+// Stop stops the resolver for good, a stopped resolver does not serve again.
+func (lr *LocalResolver) Stop() error {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	lr.stopped = true
+	if lr.proxy == nil {
+		return nil
+	}
+	err := lr.proxy.Stop()
+	lr.proxy = nil
+	return err
+}
+
+// switchHandler allows to replace the handler of the running server.
+type switchHandler struct {
+	mu      sync.RWMutex
+	handler dns.Handler
+}
+
+func (sh *switchHandler) set(handler dns.Handler) {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.handler = handler
+}
+
+func (sh *switchHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	sh.mu.RLock()
+	handler := sh.handler
+	sh.mu.RUnlock()
+
+	if handler == nil {
+		resp := &dns.Msg{}
+		resp.SetRcode(req, dns.RcodeServerFailure)
+		writer.WriteMsg(resp)
+		return
+	}
+	handler.ServeDNS(writer, req)
+}
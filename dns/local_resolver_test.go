@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseProfiles(t *testing.T) {
+	profiles := ParseProfiles(
+		[]string{"1.1.1.1", "family=1.1.1.3", "family = 1.0.0.3"},
+		[]string{"ads.com", "family=adult.com"},
+	)
+
+	assert.Equal(t, map[string]Profile{
+		DefaultProfile: {Upstreams: []string{"1.1.1.1"}, Blocklist: []string{"ads.com"}},
+		"family":       {Upstreams: []string{"1.1.1.3", "1.0.0.3"}, Blocklist: []string{"adult.com"}},
+	}, profiles)
+}
+
+func Test_LocalResolver_UnknownProfile(t *testing.T) {
+	resolver := NewLocalResolver(LocalResolverConfig{
+		Address:  "127.0.0.1",
+		Profiles: ParseProfiles(nil, []string{"family=adult.com"}),
+	})
+
+	_, err := resolver.Serve("unknown", []string{"1.1.1.1"})
+	assert.Error(t, err)
+}
+
+func Test_LocalResolver_DoesNotServeAfterStop(t *testing.T) {
+	resolver := NewLocalResolver(LocalResolverConfig{
+		Address: "127.0.0.1",
+		Port:    0,
+	})
+
+	servers, err := resolver.Serve(DefaultProfile, []string{"1.1.1.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, servers)
+
+	assert.NoError(t, resolver.Stop())
+	assert.Nil(t, resolver.proxy)
+
+	_, err = resolver.Serve(DefaultProfile, []string{"1.1.1.1"})
+	assert.Error(t, err)
+	assert.Nil(t, resolver.proxy)
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrUnsupportedVersion is returned when the peer sent a message of a newer version than this node understands.
+var ErrUnsupportedVersion = errors.New("unsupported message version")
+
+// Codec encodes and decodes the messages.
+type Codec interface {
+	Encoding() Encoding
+	Marshal(m Message) ([]byte, error)
+	Unmarshal(data []byte, m Message) error
+}
+
+// CodecFor returns the codec of the given encoding, protobuf codec is returned for unknown encodings.
+func CodecFor(encoding Encoding) Codec {
+	if encoding == EncodingJSON {
+		return jsonCodec{}
+	}
+	return protoCodec{}
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Encoding() Encoding {
+	return EncodingProtobuf
+}
+
+func (protoCodec) Marshal(m Message) ([]byte, error) {
+	return proto.Marshal(m.toProto())
+}
+
+func (protoCodec) Unmarshal(data []byte, m Message) error {
+	pm := m.newProto()
+	if err := proto.Unmarshal(data, pm); err != nil {
+		return err
+	}
+	return m.fromProto(pm)
+}
+
+// jsonEnvelope carries the version of the JSON encoded message.
+type jsonEnvelope struct {
+	Version int             `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Encoding() Encoding {
+	return EncodingJSON
+}
+
+func (jsonCodec) Marshal(m Message) ([]byte, error) {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonEnvelope{Version: Version, Payload: payload})
+}
+
+func (jsonCodec) Unmarshal(data []byte, m Message) error {
+	var envelope jsonEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+	if envelope.Version > Version {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, envelope.Version)
+	}
+	return json.Unmarshal(envelope.Payload, m)
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dto
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mysteriumnetwork/node/pb"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	sentAt := time.Unix(1000, 500).UTC()
+	messages := []struct {
+		in  Message
+		out Message
+	}{
+		{
+			in: &Invoice{
+				AgreementID:    big.NewInt(1),
+				AgreementTotal: big.NewInt(1000),
+				TransactorFee:  big.NewInt(10),
+				Hashlock:       "0xhashlock",
+				Provider:       "0xprovider",
+				ChainID:        137,
+				SentAt:         sentAt,
+			},
+			out: &Invoice{},
+		},
+		{
+			in:  &InvoiceReceipt{Hashlock: "0xhashlock", SentAt: sentAt, ReceivedAt: sentAt.Add(time.Second)},
+			out: &InvoiceReceipt{},
+		},
+		{
+			in: &ExchangeMessage{
+				Promise: Promise{
+					ChannelID: []byte{1},
+					ChainID:   137,
+					Amount:    big.NewInt(1000),
+					Fee:       big.NewInt(10),
+					Hashlock:  []byte{2},
+					R:         []byte{3},
+					Signature: []byte{4},
+				},
+				AgreementID:    big.NewInt(1),
+				AgreementTotal: big.NewInt(1000),
+				Provider:       "0xprovider",
+				Signature:      "0xsignature",
+				HermesID:       "0xhermes",
+				ChainID:        137,
+			},
+			out: &ExchangeMessage{},
+		},
+		{
+			in: &SessionRequest{
+				Consumer: ConsumerInfo{
+					ID:             "0xconsumer",
+					HermesID:       "0xhermes",
+					PaymentVersion: "v3",
+					Country:        "LT",
+					PricePerHour:   big.NewInt(100),
+					PricePerGiB:    big.NewInt(200),
+					ChargePeriod:   time.Minute,
+				},
+				ProposalID: 5,
+				Config:     []byte(`{"key":"value"}`),
+			},
+			out: &SessionRequest{},
+		},
+		{
+			in:  &SessionResponse{ID: "session", Config: []byte(`{}`), ChargePeriod: time.Minute},
+			out: &SessionResponse{},
+		},
+	}
+
+	for _, encoding := range SupportedEncodings {
+		codec := CodecFor(encoding)
+		assert.Equal(t, encoding, codec.Encoding())
+		for _, m := range messages {
+			data, err := codec.Marshal(m.in)
+			require.NoError(t, err)
+			require.NoError(t, codec.Unmarshal(data, m.out))
+			assert.Equal(t, m.in, m.out, "encoding %s", encoding)
+		}
+	}
+}
+
+func TestCodec_ProtobufIsCompatibleWithPlainMessages(t *testing.T) {
+	codec := CodecFor(EncodingProtobuf)
+
+	data, err := proto.Marshal(&pb.SessionResponse{ID: "session", ChargePeriod: 60})
+	require.NoError(t, err)
+
+	var response SessionResponse
+	require.NoError(t, codec.Unmarshal(data, &response))
+	assert.Equal(t, SessionResponse{ID: "session", ChargePeriod: time.Minute}, response)
+}
+
+func TestCodec_JSONRejectsNewerVersion(t *testing.T) {
+	codec := CodecFor(EncodingJSON)
+
+	err := codec.Unmarshal([]byte(`{"version":2,"payload":{"id":"session"}}`), &SessionResponse{})
+	assert.True(t, errors.Is(err, ErrUnsupportedVersion))
+
+	var response SessionResponse
+	assert.NoError(t, codec.Unmarshal([]byte(`{"version":1,"payload":{"id":"session"}}`), &response))
+	assert.Equal(t, "session", response.ID)
+}
+
+func TestNegotiate(t *testing.T) {
+	assert.Equal(t, EncodingProtobuf, Negotiate([]string{"protobuf", "json"}, []string{"json", "protobuf"}))
+	assert.Equal(t, EncodingJSON, Negotiate([]string{"json", "protobuf"}, []string{"protobuf", "json"}))
+	assert.Equal(t, EncodingJSON, Negotiate([]string{"cbor", "json"}, []string{"cbor", "json"}))
+	assert.Equal(t, EncodingProtobuf, Negotiate([]string{"protobuf", "json"}, nil))
+	assert.Equal(t, EncodingProtobuf, Negotiate(nil, []string{"json"}))
+}
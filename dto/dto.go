@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package dto holds the session and payment messages exchanged between the peers over the p2p channel.
+// Messages are encoded either as protobuf, which is understood by every peer, or as versioned JSON,
+// the encoding of a channel is negotiated by the peers when the channel is established.
+package dto
+
+import (
+	"google.golang.org/protobuf/proto"
+)
+
+// Version is the version of the messages in this package.
+// It is bumped whenever a message changes in a way older peers can not decode.
+const Version = 1
+
+// Encoding is the name of a wire encoding of the messages.
+type Encoding string
+
+const (
+	// EncodingProtobuf encodes the messages as protobuf, it is used with peers which do not negotiate the encoding.
+	EncodingProtobuf Encoding = "protobuf"
+	// EncodingJSON encodes the messages as versioned JSON.
+	EncodingJSON Encoding = "json"
+)
+
+// SupportedEncodings lists the encodings this node supports in the order of preference.
+var SupportedEncodings = []Encoding{EncodingProtobuf, EncodingJSON}
+
+// Message is a message which can be encoded by every codec.
+type Message interface {
+	toProto() proto.Message
+	fromProto(m proto.Message) error
+	newProto() proto.Message
+}
+
+// Negotiate selects the encoding of a channel. Both peers know each other's encodings once the
+// channel is established, so the provider's preference is honored to end up with the same choice.
+// Peers which do not advertise any encoding only speak protobuf.
+func Negotiate(provider, consumer []string) Encoding {
+	for _, p := range provider {
+		for _, c := range consumer {
+			if p == c && supported(Encoding(p)) {
+				return Encoding(p)
+			}
+		}
+	}
+	return EncodingProtobuf
+}
+
+// EncodingNames returns the names of the given encodings to be advertised to the peer.
+func EncodingNames(encodings []Encoding) []string {
+	names := make([]string, len(encodings))
+	for i, e := range encodings {
+		names[i] = string(e)
+	}
+	return names
+}
+
+func supported(encoding Encoding) bool {
+	for _, e := range SupportedEncodings {
+		if e == encoding {
+			return true
+		}
+	}
+	return false
+}
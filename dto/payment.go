@@ -0,0 +1,274 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dto
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mysteriumnetwork/node/pb"
+)
+
+const amountBase = 10
+
+// Invoice is sent by provider to ask the consumer for a payment.
+type Invoice struct {
+	AgreementID    *big.Int `json:"agreement_id"`
+	AgreementTotal *big.Int `json:"agreement_total"`
+	TransactorFee  *big.Int `json:"transactor_fee"`
+	Hashlock       string   `json:"hashlock"`
+	Provider       string   `json:"provider"`
+	ChainID        int64    `json:"chain_id"`
+	// SentAt is the provider's time of sending the invoice, zero if the provider does not stamp invoices.
+	SentAt time.Time `json:"sent_at,omitempty"`
+}
+
+// NewInvoice returns the message of the given invoice.
+func NewInvoice(invoice crypto.Invoice, sentAt time.Time) *Invoice {
+	return &Invoice{
+		AgreementID:    invoice.AgreementID,
+		AgreementTotal: invoice.AgreementTotal,
+		TransactorFee:  invoice.TransactorFee,
+		Hashlock:       invoice.Hashlock,
+		Provider:       invoice.Provider,
+		ChainID:        invoice.ChainID,
+		SentAt:         sentAt,
+	}
+}
+
+// Crypto returns the invoice used by the payments.
+func (i *Invoice) Crypto() crypto.Invoice {
+	return crypto.Invoice{
+		AgreementID:    i.AgreementID,
+		AgreementTotal: i.AgreementTotal,
+		TransactorFee:  i.TransactorFee,
+		Hashlock:       i.Hashlock,
+		Provider:       i.Provider,
+		ChainID:        i.ChainID,
+	}
+}
+
+func (i *Invoice) newProto() proto.Message {
+	return &pb.Invoice{}
+}
+
+func (i *Invoice) toProto() proto.Message {
+	return &pb.Invoice{
+		AgreementID:    amountText(i.AgreementID),
+		AgreementTotal: amountText(i.AgreementTotal),
+		TransactorFee:  amountText(i.TransactorFee),
+		Hashlock:       i.Hashlock,
+		Provider:       i.Provider,
+		ChainID:        i.ChainID,
+		SentAt:         timeNanos(i.SentAt),
+	}
+}
+
+func (i *Invoice) fromProto(m proto.Message) (err error) {
+	msg := m.(*pb.Invoice)
+	if i.AgreementID, err = parseAmount("agreementID", msg.GetAgreementID()); err != nil {
+		return err
+	}
+	if i.AgreementTotal, err = parseAmount("agreementTotal", msg.GetAgreementTotal()); err != nil {
+		return err
+	}
+	if i.TransactorFee, err = parseAmount("transactorFee", msg.GetTransactorFee()); err != nil {
+		return err
+	}
+	i.Hashlock = msg.GetHashlock()
+	i.Provider = msg.GetProvider()
+	i.ChainID = msg.GetChainID()
+	i.SentAt = nanosTime(msg.GetSentAt())
+	return nil
+}
+
+// InvoiceReceipt is replied by consumer to a stamped invoice, it lets provider measure the peer clock skew.
+type InvoiceReceipt struct {
+	Hashlock string `json:"hashlock"`
+	// SentAt echoes the provider's time of sending the invoice.
+	SentAt time.Time `json:"sent_at"`
+	// ReceivedAt is the consumer's time of receiving the invoice.
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+func (r *InvoiceReceipt) newProto() proto.Message {
+	return &pb.InvoiceReceipt{}
+}
+
+func (r *InvoiceReceipt) toProto() proto.Message {
+	return &pb.InvoiceReceipt{
+		Hashlock:   r.Hashlock,
+		SentAt:     timeNanos(r.SentAt),
+		ReceivedAt: timeNanos(r.ReceivedAt),
+	}
+}
+
+func (r *InvoiceReceipt) fromProto(m proto.Message) error {
+	msg := m.(*pb.InvoiceReceipt)
+	r.Hashlock = msg.GetHashlock()
+	r.SentAt = nanosTime(msg.GetSentAt())
+	r.ReceivedAt = nanosTime(msg.GetReceivedAt())
+	return nil
+}
+
+// Promise is the consumer's promise to pay the provider.
+type Promise struct {
+	ChannelID []byte   `json:"channel_id"`
+	ChainID   int64    `json:"chain_id"`
+	Amount    *big.Int `json:"amount"`
+	Fee       *big.Int `json:"fee"`
+	Hashlock  []byte   `json:"hashlock"`
+	R         []byte   `json:"r,omitempty"`
+	Signature []byte   `json:"signature"`
+}
+
+// ExchangeMessage is sent by consumer to pay the invoice of the provider.
+type ExchangeMessage struct {
+	Promise        Promise  `json:"promise"`
+	AgreementID    *big.Int `json:"agreement_id"`
+	AgreementTotal *big.Int `json:"agreement_total"`
+	Provider       string   `json:"provider"`
+	Signature      string   `json:"signature"`
+	HermesID       string   `json:"hermes_id"`
+	ChainID        int64    `json:"chain_id"`
+}
+
+// NewExchangeMessage returns the message of the given exchange message.
+func NewExchangeMessage(em crypto.ExchangeMessage) *ExchangeMessage {
+	return &ExchangeMessage{
+		Promise: Promise{
+			ChannelID: em.Promise.ChannelID,
+			ChainID:   em.Promise.ChainID,
+			Amount:    em.Promise.Amount,
+			Fee:       em.Promise.Fee,
+			Hashlock:  em.Promise.Hashlock,
+			R:         em.Promise.R,
+			Signature: em.Promise.Signature,
+		},
+		AgreementID:    em.AgreementID,
+		AgreementTotal: em.AgreementTotal,
+		Provider:       em.Provider,
+		Signature:      em.Signature,
+		HermesID:       em.HermesID,
+		ChainID:        em.ChainID,
+	}
+}
+
+// Crypto returns the exchange message used by the payments.
+func (em *ExchangeMessage) Crypto() crypto.ExchangeMessage {
+	return crypto.ExchangeMessage{
+		Promise: crypto.Promise{
+			ChannelID: em.Promise.ChannelID,
+			ChainID:   em.Promise.ChainID,
+			Amount:    em.Promise.Amount,
+			Fee:       em.Promise.Fee,
+			Hashlock:  em.Promise.Hashlock,
+			R:         em.Promise.R,
+			Signature: em.Promise.Signature,
+		},
+		AgreementID:    em.AgreementID,
+		AgreementTotal: em.AgreementTotal,
+		Provider:       em.Provider,
+		Signature:      em.Signature,
+		HermesID:       em.HermesID,
+		ChainID:        em.ChainID,
+	}
+}
+
+func (em *ExchangeMessage) newProto() proto.Message {
+	return &pb.ExchangeMessage{}
+}
+
+func (em *ExchangeMessage) toProto() proto.Message {
+	return &pb.ExchangeMessage{
+		Promise: &pb.Promise{
+			ChannelID: em.Promise.ChannelID,
+			Amount:    amountText(em.Promise.Amount),
+			Fee:       amountText(em.Promise.Fee),
+			Hashlock:  em.Promise.Hashlock,
+			R:         em.Promise.R,
+			Signature: em.Promise.Signature,
+			ChainID:   em.Promise.ChainID,
+		},
+		AgreementID:    amountText(em.AgreementID),
+		AgreementTotal: amountText(em.AgreementTotal),
+		Provider:       em.Provider,
+		Signature:      em.Signature,
+		HermesID:       em.HermesID,
+		ChainID:        em.ChainID,
+	}
+}
+
+func (em *ExchangeMessage) fromProto(m proto.Message) (err error) {
+	msg := m.(*pb.ExchangeMessage)
+	if em.Promise.Amount, err = parseAmount("amount", msg.GetPromise().GetAmount()); err != nil {
+		return err
+	}
+	if em.Promise.Fee, err = parseAmount("fee", msg.GetPromise().GetFee()); err != nil {
+		return err
+	}
+	if em.AgreementID, err = parseAmount("agreementID", msg.GetAgreementID()); err != nil {
+		return err
+	}
+	if em.AgreementTotal, err = parseAmount("agreementTotal", msg.GetAgreementTotal()); err != nil {
+		return err
+	}
+	em.Promise.ChannelID = msg.GetPromise().GetChannelID()
+	em.Promise.ChainID = msg.GetPromise().GetChainID()
+	em.Promise.Hashlock = msg.GetPromise().GetHashlock()
+	em.Promise.R = msg.GetPromise().GetR()
+	em.Promise.Signature = msg.GetPromise().GetSignature()
+	em.Provider = msg.GetProvider()
+	em.Signature = msg.GetSignature()
+	em.HermesID = msg.GetHermesID()
+	em.ChainID = msg.GetChainID()
+	return nil
+}
+
+func amountText(amount *big.Int) string {
+	if amount == nil {
+		return "0"
+	}
+	return amount.Text(amountBase)
+}
+
+func parseAmount(field, value string) (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(value, amountBase)
+	if !ok {
+		return nil, fmt.Errorf("could not unmarshal field %s of value %q", field, value)
+	}
+	return amount, nil
+}
+
+func timeNanos(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func nanosTime(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos).UTC()
+}
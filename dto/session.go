@@ -0,0 +1,142 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dto
+
+import (
+	"math/big"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/mysteriumnetwork/node/pb"
+)
+
+// ConsumerInfo describes the consumer requesting a session.
+type ConsumerInfo struct {
+	ID             string `json:"id"`
+	HermesID       string `json:"hermes_id"`
+	PaymentVersion string `json:"payment_version"`
+	Country        string `json:"country,omitempty"`
+	// PricePerHour and PricePerGiB are the prices the consumer agrees to pay, nil if not sent.
+	PricePerHour *big.Int `json:"price_per_hour,omitempty"`
+	PricePerGiB  *big.Int `json:"price_per_gib,omitempty"`
+	Attestation  string   `json:"attestation,omitempty"`
+	// ChargePeriod is the requested charge period, zero leaves the choice to provider.
+	ChargePeriod  time.Duration `json:"charge_period,omitempty"`
+	BandwidthTier string        `json:"bandwidth_tier,omitempty"`
+}
+
+// SessionRequest is sent by consumer to create a session.
+type SessionRequest struct {
+	Consumer   ConsumerInfo `json:"consumer"`
+	ProposalID int64        `json:"proposal_id"`
+	Config     []byte       `json:"config,omitempty"`
+}
+
+// Proto returns the session request used by the session manager.
+func (r *SessionRequest) Proto() *pb.SessionRequest {
+	request := &pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:             r.Consumer.ID,
+			HermesID:       r.Consumer.HermesID,
+			PaymentVersion: r.Consumer.PaymentVersion,
+			Location: &pb.LocationInfo{
+				Country: r.Consumer.Country,
+			},
+			Attestation:   r.Consumer.Attestation,
+			ChargePeriod:  uint32(r.Consumer.ChargePeriod / time.Second),
+			BandwidthTier: r.Consumer.BandwidthTier,
+		},
+		ProposalID: r.ProposalID,
+		Config:     r.Config,
+	}
+	if r.Consumer.PricePerHour != nil && r.Consumer.PricePerGiB != nil {
+		request.Consumer.Pricing = &pb.Pricing{
+			PerGib:  r.Consumer.PricePerGiB.Bytes(),
+			PerHour: r.Consumer.PricePerHour.Bytes(),
+		}
+	}
+	return request
+}
+
+func (r *SessionRequest) newProto() proto.Message {
+	return &pb.SessionRequest{}
+}
+
+func (r *SessionRequest) toProto() proto.Message {
+	return r.Proto()
+}
+
+func (r *SessionRequest) fromProto(m proto.Message) error {
+	msg := m.(*pb.SessionRequest)
+	consumer := msg.GetConsumer()
+	r.Consumer = ConsumerInfo{
+		ID:             consumer.GetId(),
+		HermesID:       consumer.GetHermesID(),
+		PaymentVersion: consumer.GetPaymentVersion(),
+		Country:        consumer.GetLocation().GetCountry(),
+		Attestation:    consumer.GetAttestation(),
+		ChargePeriod:   time.Duration(consumer.GetChargePeriod()) * time.Second,
+		BandwidthTier:  consumer.GetBandwidthTier(),
+	}
+	if pricing := consumer.GetPricing(); pricing != nil {
+		r.Consumer.PricePerHour = new(big.Int).SetBytes(pricing.GetPerHour())
+		r.Consumer.PricePerGiB = new(big.Int).SetBytes(pricing.GetPerGib())
+	}
+	r.ProposalID = msg.GetProposalID()
+	r.Config = msg.GetConfig()
+	return nil
+}
+
+// SessionResponse is replied by provider once the session is created.
+type SessionResponse struct {
+	ID          string `json:"id"`
+	PaymentInfo string `json:"payment_info,omitempty"`
+	Config      []byte `json:"config,omitempty"`
+	// ChargePeriod is the negotiated charge period, zero if provider did not negotiate it.
+	ChargePeriod time.Duration `json:"charge_period,omitempty"`
+}
+
+// NewSessionResponse returns the message of the session manager's response.
+func NewSessionResponse(response *pb.SessionResponse) *SessionResponse {
+	r := &SessionResponse{}
+	_ = r.fromProto(response)
+	return r
+}
+
+func (r *SessionResponse) newProto() proto.Message {
+	return &pb.SessionResponse{}
+}
+
+func (r *SessionResponse) toProto() proto.Message {
+	return &pb.SessionResponse{
+		ID:           r.ID,
+		PaymentInfo:  r.PaymentInfo,
+		Config:       r.Config,
+		ChargePeriod: uint32(r.ChargePeriod / time.Second),
+	}
+}
+
+func (r *SessionResponse) fromProto(m proto.Message) error {
+	msg := m.(*pb.SessionResponse)
+	r.ID = msg.GetID()
+	r.PaymentInfo = msg.GetPaymentInfo()
+	r.Config = msg.GetConfig()
+	r.ChargePeriod = time.Duration(msg.GetChargePeriod()) * time.Second
+	return nil
+}
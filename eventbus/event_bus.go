@@ -51,6 +51,14 @@ type simplifiedEventBus struct {
 
 	mu  sync.RWMutex
 	sub map[string][]string
+
+	validate bool
+}
+
+// EnableValidation makes the bus check published payloads against the registered schemas.
+// Mismatching payloads are logged, but still delivered to the subscribers.
+func (b *simplifiedEventBus) EnableValidation() {
+	b.validate = true
 }
 
 func (b *simplifiedEventBus) Unsubscribe(topic string, fn interface{}) error {
@@ -98,6 +106,12 @@ func (b *simplifiedEventBus) SubscribeAsync(topic string, fn interface{}) error
 }
 
 func (b *simplifiedEventBus) Publish(topic string, data interface{}) {
+	if b.validate {
+		if err := ValidatePayload(topic, data); err != nil {
+			log.Error().Err(err).Msg("Published malformed event payload")
+		}
+	}
+
 	log.WithLevel(levelFor(topic)).Msgf("Published topic=%q event=%+v", topic, data)
 	b.bus.Publish(topic, data)
 
@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// Schema describes the payload published on a topic.
+type Schema struct {
+	Topic   string
+	Version int
+	Type    reflect.Type
+}
+
+var schemas = struct {
+	sync.RWMutex
+	byTopic map[string]Schema
+}{byTopic: make(map[string]Schema)}
+
+// RegisterSchema registers the payload published on the given topic.
+// It is meant to be called from the init of the package declaring the topic
+// and panics if the topic is already registered with a different payload.
+func RegisterSchema(topic string, version int, payload interface{}) {
+	if payload == nil {
+		panic(fmt.Sprintf("eventbus: nil payload schema for topic %q", topic))
+	}
+
+	schema := Schema{Topic: topic, Version: version, Type: reflect.TypeOf(payload)}
+
+	schemas.Lock()
+	defer schemas.Unlock()
+
+	if existing, ok := schemas.byTopic[topic]; ok && existing != schema {
+		panic(fmt.Sprintf("eventbus: topic %q already registered with %s v%d", topic, existing.Type, existing.Version))
+	}
+	schemas.byTopic[topic] = schema
+}
+
+// SchemaFor returns the schema registered for the given topic.
+func SchemaFor(topic string) (Schema, bool) {
+	schemas.RLock()
+	defer schemas.RUnlock()
+
+	schema, ok := schemas.byTopic[topic]
+	return schema, ok
+}
+
+// Schemas returns all registered schemas sorted by topic.
+func Schemas() []Schema {
+	schemas.RLock()
+	defer schemas.RUnlock()
+
+	result := make([]Schema, 0, len(schemas.byTopic))
+	for _, schema := range schemas.byTopic {
+		result = append(result, schema)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Topic < result[j].Topic
+	})
+	return result
+}
+
+// ValidatePayload checks that the payload matches the schema registered for the topic.
+// Topics without a registered schema are not validated.
+func ValidatePayload(topic string, payload interface{}) error {
+	schema, ok := SchemaFor(topic)
+	if !ok {
+		return nil
+	}
+
+	if payload == nil {
+		return fmt.Errorf("topic %q expects %s v%d, got nil", topic, schema.Type, schema.Version)
+	}
+	if actual := reflect.TypeOf(payload); actual != schema.Type {
+		return fmt.Errorf("topic %q expects %s v%d, got %s", topic, schema.Type, schema.Version, actual)
+	}
+	return nil
+}
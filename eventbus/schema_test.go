@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaTestEvent struct {
+	ID string
+}
+
+func TestValidatePayload(t *testing.T) {
+	RegisterSchema("schema test topic", 1, schemaTestEvent{})
+
+	assert.NoError(t, ValidatePayload("schema test topic", schemaTestEvent{ID: "1"}))
+	assert.NoError(t, ValidatePayload("unregistered topic", 42))
+	assert.EqualError(t, ValidatePayload("schema test topic", &schemaTestEvent{}), `topic "schema test topic" expects eventbus.schemaTestEvent v1, got *eventbus.schemaTestEvent`)
+	assert.EqualError(t, ValidatePayload("schema test topic", nil), `topic "schema test topic" expects eventbus.schemaTestEvent v1, got nil`)
+}
+
+func TestRegisterSchema_PanicsOnConflict(t *testing.T) {
+	RegisterSchema("schema conflict topic", 1, schemaTestEvent{})
+
+	assert.NotPanics(t, func() { RegisterSchema("schema conflict topic", 1, schemaTestEvent{}) })
+	assert.Panics(t, func() { RegisterSchema("schema conflict topic", 1, "payload") })
+	assert.Panics(t, func() { RegisterSchema("schema conflict topic", 2, schemaTestEvent{}) })
+
+	schema, ok := SchemaFor("schema conflict topic")
+	assert.True(t, ok)
+	assert.Equal(t, 1, schema.Version)
+}
+
+func TestValidatingEventBus_DeliversMalformedPayload(t *testing.T) {
+	RegisterSchema("schema delivery topic", 1, schemaTestEvent{})
+	bus := New()
+	bus.EnableValidation()
+
+	var received interface{}
+	assert.NoError(t, bus.Subscribe("schema delivery topic", func(data interface{}) {
+		received = data
+	}))
+
+	bus.Publish("schema delivery topic", "malformed")
+
+	assert.Equal(t, "malformed", received)
+}
+
+func TestPublishedTopicsAreRegistered(t *testing.T) {
+	published, registered := make(map[string][]string), make(map[string]bool)
+	err := filepath.Walk("..", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if strings.HasPrefix(info.Name(), ".") && path != ".." {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			method, topic := calleeName(call.Fun), calleeName(call.Args[0])
+			if !strings.HasPrefix(topic, "AppTopic") {
+				return true
+			}
+			switch method {
+			case "Publish":
+				published[topic] = append(published[topic], path)
+			case "RegisterSchema":
+				registered[topic] = true
+			}
+			return true
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, published)
+
+	var missing []string
+	for topic, paths := range published {
+		if !registered[topic] {
+			missing = append(missing, topic+" published in "+strings.Join(paths, ", "))
+		}
+	}
+	sort.Strings(missing)
+	assert.Empty(t, missing, "topics published without a registered schema")
+}
+
+// calleeName returns the name of the identifier or the selected field, empty for other expressions.
+func calleeName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	}
+	return ""
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package audit
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type recorder interface {
+	Store(record Record) error
+}
+
+// SignerFactory wraps the given signer factory so that every signing operation
+// of the requesting subsystem is recorded into the audit log.
+func SignerFactory(factory identity.SignerFactory, audit recorder, subsystem, messageType string) identity.SignerFactory {
+	return func(id identity.Identity) identity.Signer {
+		return &auditedSigner{
+			signer:      factory(id),
+			audit:       audit,
+			identity:    id,
+			subsystem:   subsystem,
+			messageType: messageType,
+		}
+	}
+}
+
+type auditedSigner struct {
+	signer      identity.Signer
+	audit       recorder
+	identity    identity.Identity
+	subsystem   string
+	messageType string
+}
+
+// Sign signs given message and records the operation.
+func (s *auditedSigner) Sign(message []byte) (identity.Signature, error) {
+	signature, err := s.signer.Sign(message)
+	if err != nil {
+		return signature, err
+	}
+
+	record := Record{
+		Identity:    s.identity.Address,
+		Subsystem:   s.subsystem,
+		MessageType: s.messageType,
+		Digest:      hex.EncodeToString(crypto.Keccak256(message)),
+		Timestamp:   time.Now().UTC(),
+	}
+	if err := s.audit.Store(record); err != nil {
+		log.Warn().Err(err).Msgf("Failed to record signing operation of %s", s.subsystem)
+	}
+
+	return signature, nil
+}
@@ -0,0 +1,236 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package audit
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/q"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+)
+
+const (
+	bucketName = "sign-audit"
+	// queueSize is the number of records waiting to be written, records are dropped once the queue is full.
+	queueSize = 1000
+	// batchSize is the maximum number of records written in a single transaction.
+	batchSize = 100
+	// pruneInterval is how often the expired records are removed.
+	pruneInterval = time.Hour
+)
+
+// ErrQueueFull is returned when the record is dropped because the audit log can not keep up with the signing.
+var ErrQueueFull = errors.New("signing audit queue is full")
+
+// Record describes a single signing operation performed by an identity.
+type Record struct {
+	ID          int    `storm:"id,increment"`
+	Identity    string `storm:"index"`
+	Subsystem   string `storm:"index"`
+	MessageType string `storm:"index"`
+	Digest      string
+	Timestamp   time.Time `storm:"index"`
+}
+
+// Filter narrows down the listed records.
+type Filter struct {
+	Identity    string
+	Subsystem   string
+	MessageType string
+	From        *time.Time
+	To          *time.Time
+}
+
+// Storage keeps the signing audit log. Records are written in batches in the background,
+// so signing never waits for the database.
+type Storage struct {
+	storage   *boltdb.Bolt
+	retention time.Duration
+	now       func() time.Time
+
+	queue    chan Record
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewStorage creates the signing audit log storage. Records older than retention are removed, zero keeps them forever.
+func NewStorage(storage *boltdb.Bolt, retention time.Duration) *Storage {
+	return &Storage{
+		storage:   storage,
+		retention: retention,
+		now:       time.Now,
+		queue:     make(chan Record, queueSize),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Store queues the record to be appended to the audit log.
+func (s *Storage) Store(record Record) error {
+	select {
+	case s.queue <- record:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start starts writing the queued records and removing the expired ones.
+func (s *Storage) Start() {
+	s.wg.Add(1)
+	go s.run()
+}
+
+// Stop writes the records still in the queue and stops the writer.
+func (s *Storage) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	s.wg.Wait()
+}
+
+func (s *Storage) run() {
+	defer s.wg.Done()
+
+	s.pruneExpired()
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case record := <-s.queue:
+			s.write(s.collect(record))
+		case <-ticker.C:
+			s.pruneExpired()
+		case <-s.stop:
+			for {
+				select {
+				case record := <-s.queue:
+					s.write(s.collect(record))
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// collect appends the records already waiting in the queue to the given one, up to the batch size.
+func (s *Storage) collect(first Record) []Record {
+	batch := []Record{first}
+	for len(batch) < batchSize {
+		select {
+		case record := <-s.queue:
+			batch = append(batch, record)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (s *Storage) write(records []Record) {
+	if err := s.save(records); err != nil {
+		log.Warn().Err(err).Msgf("Failed to store %d signing audit records", len(records))
+	}
+}
+
+func (s *Storage) save(records []Record) error {
+	defer s.storage.Track("save", bucketName, nil)()
+	s.storage.Lock()
+	defer s.storage.Unlock()
+
+	tx, err := s.storage.DB().From(bucketName).Begin(true)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range records {
+		if err := tx.Save(&records[i]); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// List returns the records matching the filter, most recent first.
+// Expired records are left out even if they are not removed yet.
+func (s *Storage) List(filter Filter) ([]Record, error) {
+	defer s.storage.Track("list", bucketName, nil)()
+	s.storage.RLock()
+	defer s.storage.RUnlock()
+
+	var matchers []q.Matcher
+	if filter.Identity != "" {
+		matchers = append(matchers, q.Eq("Identity", filter.Identity))
+	}
+	if filter.Subsystem != "" {
+		matchers = append(matchers, q.Eq("Subsystem", filter.Subsystem))
+	}
+	if filter.MessageType != "" {
+		matchers = append(matchers, q.Eq("MessageType", filter.MessageType))
+	}
+	if filter.From != nil {
+		matchers = append(matchers, q.Gte("Timestamp", *filter.From))
+	}
+	if filter.To != nil {
+		matchers = append(matchers, q.Lte("Timestamp", *filter.To))
+	}
+	if s.retention > 0 {
+		matchers = append(matchers, q.Gte("Timestamp", s.now().Add(-s.retention)))
+	}
+
+	var result []Record
+	err := s.storage.DB().
+		From(bucketName).
+		Select(matchers...).
+		OrderBy("Timestamp").
+		Reverse().
+		Find(&result)
+	if errors.Is(err, storm.ErrNotFound) {
+		return []Record{}, nil
+	}
+	return result, err
+}
+
+func (s *Storage) pruneExpired() {
+	if s.retention <= 0 {
+		return
+	}
+	if err := s.prune(s.now().Add(-s.retention)); err != nil {
+		log.Warn().Err(err).Msg("Failed to remove expired signing audit records")
+	}
+}
+
+func (s *Storage) prune(before time.Time) error {
+	defer s.storage.Track("prune", bucketName, nil)()
+	s.storage.Lock()
+	defer s.storage.Unlock()
+
+	err := s.storage.DB().From(bucketName).Select(q.Lt("Timestamp", before)).Delete(&Record{})
+	if errors.Is(err, storm.ErrNotFound) {
+		return nil
+	}
+	return err
+}
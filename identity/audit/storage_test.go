@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package audit
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+func TestSignerFactory_RecordsSigning(t *testing.T) {
+	storage, cleanup := newStorage(t, 0)
+	defer cleanup()
+	storage.Start()
+
+	factory := SignerFactory(func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}, storage, "payments", "exchange-message")
+
+	_, err := factory(identity.FromAddress("0x1")).Sign([]byte("message"))
+	require.NoError(t, err)
+	storage.Stop()
+
+	records, err := storage.List(Filter{Identity: "0x1"})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "payments", records[0].Subsystem)
+	assert.Equal(t, "exchange-message", records[0].MessageType)
+	assert.Equal(t, hex.EncodeToString(crypto.Keccak256([]byte("message"))), records[0].Digest)
+}
+
+func TestSignerFactory_SkipsFailedSigning(t *testing.T) {
+	storage, cleanup := newStorage(t, 0)
+	defer cleanup()
+	storage.Start()
+
+	factory := SignerFactory(func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{ErrorMock: errors.New("locked")}
+	}, storage, "payments", "exchange-message")
+
+	_, err := factory(identity.FromAddress("0x1")).Sign([]byte("message"))
+	assert.Error(t, err)
+	storage.Stop()
+
+	records, err := storage.List(Filter{})
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestStorage_List(t *testing.T) {
+	storage, cleanup := newStorage(t, 0)
+	defer cleanup()
+	storage.Start()
+
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Identity: "0x1", Subsystem: "p2p", MessageType: "channel-config", Timestamp: now.Add(-2 * time.Hour)},
+		{Identity: "0x1", Subsystem: "payments", MessageType: "exchange-message", Timestamp: now.Add(-time.Hour)},
+		{Identity: "0x2", Subsystem: "payments", MessageType: "exchange-message", Timestamp: now},
+	}
+	for _, record := range records {
+		require.NoError(t, storage.Store(record))
+	}
+	storage.Stop()
+
+	result, err := storage.List(Filter{})
+	require.NoError(t, err)
+	require.Len(t, result, 3)
+	assert.Equal(t, now, result[0].Timestamp)
+
+	result, err = storage.List(Filter{Identity: "0x1", Subsystem: "payments"})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "exchange-message", result[0].MessageType)
+
+	from := now.Add(-90 * time.Minute)
+	result, err = storage.List(Filter{Identity: "0x1", From: &from})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "payments", result[0].Subsystem)
+}
+
+func TestStorage_PrunesExpired(t *testing.T) {
+	storage, cleanup := newStorage(t, time.Hour)
+	defer cleanup()
+
+	now := time.Now()
+	storage.now = func() time.Time { return now }
+
+	require.NoError(t, storage.save([]Record{
+		{Identity: "0x1", Timestamp: now.Add(-2 * time.Hour)},
+		{Identity: "0x1", Timestamp: now},
+	}))
+	storage.Start()
+	storage.Stop()
+
+	var stored []Record
+	require.NoError(t, storage.storage.DB().From(bucketName).All(&stored))
+	assert.Len(t, stored, 1)
+}
+
+func TestStorage_ListSkipsExpired(t *testing.T) {
+	storage, cleanup := newStorage(t, time.Hour)
+	defer cleanup()
+
+	now := time.Now()
+	storage.now = func() time.Time { return now }
+
+	require.NoError(t, storage.save([]Record{
+		{Identity: "0x1", Timestamp: now.Add(-2 * time.Hour)},
+		{Identity: "0x1", Timestamp: now},
+	}))
+
+	result, err := storage.List(Filter{})
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+}
+
+func TestStorage_DropsRecordsWhenQueueIsFull(t *testing.T) {
+	storage, cleanup := newStorage(t, 0)
+	defer cleanup()
+
+	for i := 0; i < queueSize; i++ {
+		require.NoError(t, storage.Store(Record{Identity: "0x1"}))
+	}
+	assert.ErrorIs(t, storage.Store(Record{Identity: "0x1"}), ErrQueueFull)
+
+	storage.Start()
+	storage.Stop()
+	result, err := storage.List(Filter{})
+	require.NoError(t, err)
+	assert.Len(t, result, queueSize)
+}
+
+func newStorage(t *testing.T, retention time.Duration) (*Storage, func()) {
+	dir, err := os.MkdirTemp("", "signAuditTest")
+	require.NoError(t, err)
+
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+
+	storage := NewStorage(db, retention)
+	return storage, func() {
+		storage.Stop()
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
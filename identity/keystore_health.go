@@ -0,0 +1,252 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+)
+
+// KeystoreQuarantineDir is the keystore subdirectory unusable files are moved to.
+// Keystore skips hidden entries, so the quarantined files are not loaded again.
+const KeystoreQuarantineDir = ".quarantine"
+
+// KeyFileIssue describes a keystore file which can not be used.
+type KeyFileIssue struct {
+	File    string
+	Address string
+	Problem string
+	// Quarantined is the path the file was moved to, it is empty if the file was left in place.
+	Quarantined string
+}
+
+// KeystoreHealthReport is the result of the keystore directory check.
+type KeystoreHealthReport struct {
+	Directory     string
+	CheckedAt     time.Time
+	Keys          []string
+	Issues        []KeyFileIssue
+	IndexRepaired bool
+}
+
+// Healthy returns true if no unusable files were found.
+func (r KeystoreHealthReport) Healthy() bool {
+	return len(r.Issues) == 0
+}
+
+type keyFile struct {
+	name    string
+	address string
+}
+
+// KeystoreDoctor checks the keystore directory for corrupt and duplicate key files.
+// Such files make the keystore fail with cryptic errors when listing or unlocking identities,
+// so when repairing corrupt files are quarantined together with the index referring to a missing identity.
+// Duplicates are only reported, which of them holds the right key can not be told from the files.
+type KeystoreDoctor struct {
+	directory string
+	indexFile string
+	repair    bool
+	now       func() time.Time
+
+	lock   sync.Mutex
+	report *KeystoreHealthReport
+}
+
+// NewKeystoreDoctor returns a new instance of keystore doctor.
+func NewKeystoreDoctor(directory, indexFile string, repair bool) *KeystoreDoctor {
+	return &KeystoreDoctor{
+		directory: directory,
+		indexFile: indexFile,
+		repair:    repair,
+		now:       time.Now,
+	}
+}
+
+// Report returns the result of the last check.
+func (kd *KeystoreDoctor) Report() (KeystoreHealthReport, bool) {
+	kd.lock.Lock()
+	defer kd.lock.Unlock()
+
+	if kd.report == nil {
+		return KeystoreHealthReport{}, false
+	}
+	return *kd.report, true
+}
+
+// Check scans the keystore directory, it has to run before the keystore loads the keys.
+func (kd *KeystoreDoctor) Check() (KeystoreHealthReport, error) {
+	report := KeystoreHealthReport{
+		Directory: kd.directory,
+		CheckedAt: kd.now().UTC(),
+	}
+
+	entries, err := ioutil.ReadDir(kd.directory)
+	if err != nil && !os.IsNotExist(err) {
+		return report, fmt.Errorf("could not read keystore directory: %w", err)
+	}
+
+	byAddress := make(map[string][]keyFile)
+	for _, entry := range entries {
+		if !isKeyFileCandidate(entry) || entry.Name() == kd.indexFile {
+			continue
+		}
+
+		address, err := readKeyFileAddress(filepath.Join(kd.directory, entry.Name()))
+		if err != nil {
+			report.Issues = append(report.Issues, kd.issue(entry.Name(), "", err.Error()))
+			continue
+		}
+		byAddress[address] = append(byAddress[address], keyFile{name: entry.Name(), address: address})
+	}
+
+	for address, files := range byAddress {
+		report.Keys = append(report.Keys, address)
+		if len(files) == 1 {
+			continue
+		}
+
+		// Keystore refuses to pick one of several files for the same address, the user has to.
+		for _, duplicate := range files {
+			report.Issues = append(report.Issues, KeyFileIssue{
+				File:    duplicate.name,
+				Address: address,
+				Problem: fmt.Sprintf("one of %d key files for the same address", len(files)),
+			})
+		}
+	}
+	sort.Strings(report.Keys)
+	sort.Slice(report.Issues, func(i, j int) bool { return report.Issues[i].File < report.Issues[j].File })
+
+	if problem := kd.checkIndex(byAddress); problem != "" {
+		issue := kd.issue(kd.indexFile, "", problem)
+		report.IndexRepaired = issue.Quarantined != ""
+		report.Issues = append(report.Issues, issue)
+	}
+
+	for _, issue := range report.Issues {
+		log.Warn().Msgf("Keystore file %s can not be used: %s", issue.File, issue.Problem)
+	}
+
+	kd.lock.Lock()
+	kd.report = &report
+	kd.lock.Unlock()
+
+	return report, nil
+}
+
+// checkIndex returns the problem of the remembered identity index, if any.
+func (kd *KeystoreDoctor) checkIndex(keys map[string][]keyFile) string {
+	if kd.indexFile == "" {
+		return ""
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(kd.directory, kd.indexFile))
+	if os.IsNotExist(err) {
+		return ""
+	}
+	if err != nil {
+		return "unreadable index: " + err.Error()
+	}
+
+	var cache cacheData
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return "corrupt index: " + err.Error()
+	}
+	if _, ok := keys[strings.ToLower(cache.Identity.Address)]; !ok {
+		return "index refers to missing identity " + cache.Identity.Address
+	}
+	return ""
+}
+
+// issue records the problem and quarantines the file when repairing.
+func (kd *KeystoreDoctor) issue(name, address, problem string) KeyFileIssue {
+	issue := KeyFileIssue{
+		File:    name,
+		Address: address,
+		Problem: problem,
+	}
+	if !kd.repair {
+		return issue
+	}
+
+	quarantined, err := kd.quarantine(name)
+	if err != nil {
+		log.Error().Err(err).Msgf("Could not quarantine keystore file %s", name)
+		return issue
+	}
+	issue.Quarantined = quarantined
+	return issue
+}
+
+func (kd *KeystoreDoctor) quarantine(name string) (string, error) {
+	dir := filepath.Join(kd.directory, KeystoreQuarantineDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	target := filepath.Join(dir, name)
+	if _, err := os.Stat(target); err == nil {
+		target = fmt.Sprintf("%s.%d", target, kd.now().Unix())
+	}
+	if err := os.Rename(filepath.Join(kd.directory, name), target); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// isKeyFileCandidate mirrors the files keystore tries to load keys from.
+func isKeyFileCandidate(entry os.FileInfo) bool {
+	name := entry.Name()
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+		return false
+	}
+	return entry.Mode().IsRegular()
+}
+
+func readKeyFileAddress(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unreadable file: %w", err)
+	}
+
+	var key struct {
+		Address string          `json:"address"`
+		Crypto  json.RawMessage `json:"crypto"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return "", fmt.Errorf("corrupt key file: %w", err)
+	}
+	if !common.IsHexAddress(key.Address) {
+		return "", fmt.Errorf("invalid key address %q", key.Address)
+	}
+	if len(key.Crypto) == 0 {
+		return "", fmt.Errorf("missing encrypted key")
+	}
+	return strings.ToLower(common.HexToAddress(key.Address).Hex()), nil
+}
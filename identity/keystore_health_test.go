@@ -0,0 +1,124 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	healthyKeyA = `{"address":"000000000000000000000000000000000000000a","crypto":{"cipher":"aes-128-ctr"},"version":3}`
+	healthyKeyB = `{"address":"000000000000000000000000000000000000000b","crypto":{"cipher":"aes-128-ctr"},"version":3}`
+)
+
+func writeKeystoreFile(t *testing.T, dir, name, content string, modTime time.Time) {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	assert.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestKeystoreDoctor_QuarantinesUnusableFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	writeKeystoreFile(t, dir, "key-a", healthyKeyA, now)
+	writeKeystoreFile(t, dir, "key-b", healthyKeyB, now)
+	writeKeystoreFile(t, dir, "corrupt", `{"address":`, now)
+	writeKeystoreFile(t, dir, "no-crypto", `{"address":"000000000000000000000000000000000000000c"}`, now)
+	writeKeystoreFile(t, dir, ".hidden", "ignored", now)
+	writeKeystoreFile(t, dir, "remember.json", `{"identity":{"address":"0x000000000000000000000000000000000000000C"}}`, now)
+
+	doctor := NewKeystoreDoctor(dir, "remember.json", true)
+	_, ok := doctor.Report()
+	assert.False(t, ok)
+
+	report, err := doctor.Check()
+	assert.NoError(t, err)
+	assert.False(t, report.Healthy())
+	assert.True(t, report.IndexRepaired)
+	assert.Equal(t, []string{"0x000000000000000000000000000000000000000a", "0x000000000000000000000000000000000000000b"}, report.Keys)
+
+	files := make([]string, len(report.Issues))
+	for i, issue := range report.Issues {
+		files[i] = issue.File
+		assert.Equal(t, filepath.Join(dir, KeystoreQuarantineDir, issue.File), issue.Quarantined)
+		assert.FileExists(t, issue.Quarantined)
+	}
+	assert.Equal(t, []string{"corrupt", "no-crypto", "remember.json"}, files)
+
+	stored, ok := doctor.Report()
+	assert.True(t, ok)
+	assert.Equal(t, report, stored)
+
+	report, err = doctor.Check()
+	assert.NoError(t, err)
+	assert.True(t, report.Healthy())
+	assert.False(t, report.IndexRepaired)
+}
+
+func TestKeystoreDoctor_ReportsDuplicatesWithoutMovingThem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	writeKeystoreFile(t, dir, "key-a-old", healthyKeyA, now.Add(-time.Hour))
+	writeKeystoreFile(t, dir, "key-a-new", healthyKeyA, now)
+
+	report, err := NewKeystoreDoctor(dir, "", true).Check()
+	assert.NoError(t, err)
+	assert.Len(t, report.Issues, 2)
+	for _, issue := range report.Issues {
+		assert.Equal(t, "0x000000000000000000000000000000000000000a", issue.Address)
+		assert.Equal(t, "one of 2 key files for the same address", issue.Problem)
+		assert.Empty(t, issue.Quarantined)
+	}
+	assert.FileExists(t, filepath.Join(dir, "key-a-old"))
+	assert.FileExists(t, filepath.Join(dir, "key-a-new"))
+}
+
+func TestKeystoreDoctor_ReportsWithoutRepair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeKeystoreFile(t, dir, "key-a", healthyKeyA, time.Now())
+	writeKeystoreFile(t, dir, "corrupt", "garbage", time.Now())
+	writeKeystoreFile(t, dir, "remember.json", `{"identity":{"address":"0x000000000000000000000000000000000000000A"}}`, time.Now())
+
+	report, err := NewKeystoreDoctor(dir, "remember.json", false).Check()
+	assert.NoError(t, err)
+	assert.Len(t, report.Issues, 1)
+	assert.Empty(t, report.Issues[0].Quarantined)
+	assert.FileExists(t, filepath.Join(dir, "corrupt"))
+	assert.False(t, report.IndexRepaired)
+}
+
+func TestKeystoreDoctor_MissingDirectory(t *testing.T) {
+	report, err := NewKeystoreDoctor(filepath.Join(os.TempDir(), "missing-keystore-dir"), "remember.json", true).Check()
+	assert.NoError(t, err)
+	assert.True(t, report.Healthy())
+}
@@ -37,6 +37,11 @@ const (
 	AppTopicIdentityCreated = "identity-created"
 )
 
+func init() {
+	eventbus.RegisterSchema(AppTopicIdentityUnlock, 1, AppEventIdentityUnlock{})
+	eventbus.RegisterSchema(AppTopicIdentityCreated, 1, "")
+}
+
 // AppEventIdentityUnlock represents the payload that is sent on identity unlock.
 type AppEventIdentityUnlock struct {
 	ChainID int64
@@ -0,0 +1,182 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	// DefaultAutoRegistrationTimeout is the maximum time an API request waits for the registration to be confirmed,
+	// it stays below the timeout of API clients.
+	DefaultAutoRegistrationTimeout = 60 * time.Second
+	// BackgroundAutoRegistrationTimeout is the maximum time to wait for the registration done in the background.
+	BackgroundAutoRegistrationTimeout = 10 * time.Minute
+	autoRegistrationPollInterval      = 10 * time.Second
+)
+
+// ErrRegistrationPending indicates that the registration was submitted but not confirmed in time, its status can be polled.
+var ErrRegistrationPending = errors.New("identity registration is pending")
+
+// ErrRegistrationFailed indicates that the transactor failed to register the identity.
+var ErrRegistrationFailed = errors.New("identity registration failed")
+
+// InsufficientBalanceError indicates that the channel balance does not cover the registration fee.
+type InsufficientBalanceError struct {
+	Fee            *big.Int
+	Balance        *big.Int
+	ChannelAddress common.Address
+}
+
+// Error implements error interface.
+func (e *InsufficientBalanceError) Error() string {
+	return fmt.Sprintf("insufficient balance %v to pay registration fee %v, top up channel %s", e.Balance, e.Fee, e.ChannelAddress.Hex())
+}
+
+type registrationStatusProvider interface {
+	GetRegistrationStatus(chainID int64, id identity.Identity) (RegistrationStatus, error)
+}
+
+type registrationTransactor interface {
+	FetchRegistrationFees(chainID int64) (FeesResponse, error)
+	GetFreeRegistrationEligibility(identity identity.Identity) (bool, error)
+	RegisterIdentity(id string, stake, fee *big.Int, beneficiary string, chainID int64, referralToken *string) error
+}
+
+type registrationBalanceProvider interface {
+	ForceBalanceUpdateCached(chainID int64, id identity.Identity) *big.Int
+}
+
+type channelAddressProvider interface {
+	GetActiveChannelAddress(chainID int64, id common.Address) (common.Address, error)
+}
+
+// AutoRegistrar registers consumer identities on demand, paying the fee from the identity's channel balance.
+type AutoRegistrar struct {
+	registry   registrationStatusProvider
+	transactor registrationTransactor
+	balances   registrationBalanceProvider
+	channels   channelAddressProvider
+
+	timeout      time.Duration
+	pollInterval time.Duration
+}
+
+// NewAutoRegistrar returns a new identity auto registrar which waits up to timeout for the registration to be confirmed.
+func NewAutoRegistrar(registry registrationStatusProvider, transactor registrationTransactor, balances registrationBalanceProvider, channels channelAddressProvider, timeout time.Duration) *AutoRegistrar {
+	return &AutoRegistrar{
+		registry:     registry,
+		transactor:   transactor,
+		balances:     balances,
+		channels:     channels,
+		timeout:      timeout,
+		pollInterval: autoRegistrationPollInterval,
+	}
+}
+
+// EstimateFee returns the fee the given identity has to pay for the registration.
+func (r *AutoRegistrar) EstimateFee(chainID int64, id identity.Identity) (*big.Int, error) {
+	free, err := r.transactor.GetFreeRegistrationEligibility(id)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not check free registration eligibility for %s", id.Address)
+	}
+	if free {
+		return big.NewInt(0), nil
+	}
+
+	fees, err := r.transactor.FetchRegistrationFees(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch registration fees: %w", err)
+	}
+	return fees.Fee, nil
+}
+
+// Register registers the given identity unless it is registered already and waits for the registration to be confirmed.
+// ErrRegistrationPending is returned if the registration is not confirmed in time.
+func (r *AutoRegistrar) Register(ctx context.Context, chainID int64, id identity.Identity) error {
+	status, err := r.registry.GetRegistrationStatus(chainID, id)
+	if err != nil {
+		return fmt.Errorf("could not check registration status: %w", err)
+	}
+
+	switch status {
+	case Registered:
+		return nil
+	case InProgress:
+		log.Info().Msgf("Identity %s registration is in progress, waiting for confirmation", id.Address)
+	default:
+		fee, err := r.EstimateFee(chainID, id)
+		if err != nil {
+			return err
+		}
+
+		if balance := r.balances.ForceBalanceUpdateCached(chainID, id); balance.Cmp(fee) < 0 {
+			channelAddress, err := r.channels.GetActiveChannelAddress(chainID, id.ToCommonAddress())
+			if err != nil {
+				return fmt.Errorf("could not calculate channel address: %w", err)
+			}
+			return &InsufficientBalanceError{Fee: fee, Balance: balance, ChannelAddress: channelAddress}
+		}
+
+		log.Info().Msgf("Registering identity %s with fee %v", id.Address, fee)
+		if err := r.transactor.RegisterIdentity(id.Address, big.NewInt(0), fee, "", chainID, nil); err != nil {
+			return fmt.Errorf("could not register identity: %w", err)
+		}
+	}
+
+	return r.waitForRegistration(ctx, chainID, id)
+}
+
+func (r *AutoRegistrar) waitForRegistration(ctx context.Context, chainID int64, id identity.Identity) error {
+	timeout := time.NewTimer(r.timeout)
+	defer timeout.Stop()
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("registration of %s was not confirmed: %w", id.Address, ctx.Err())
+		case <-timeout.C:
+			return fmt.Errorf("registration of %s was not confirmed in %s: %w", id.Address, r.timeout, ErrRegistrationPending)
+		case <-ticker.C:
+			status, err := r.registry.GetRegistrationStatus(chainID, id)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Could not check registration status of %s", id.Address)
+				continue
+			}
+
+			switch status {
+			case Registered:
+				return nil
+			case RegistrationError:
+				return ErrRegistrationFailed
+			}
+		}
+	}
+}
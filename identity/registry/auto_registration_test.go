@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockStatusSequence struct {
+	mu       sync.Mutex
+	statuses []RegistrationStatus
+}
+
+func (m *mockStatusSequence) GetRegistrationStatus(int64, identity.Identity) (RegistrationStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := m.statuses[0]
+	if len(m.statuses) > 1 {
+		m.statuses = m.statuses[1:]
+	}
+	return status, nil
+}
+
+type mockRegistrationTransactor struct {
+	free          bool
+	fee           *big.Int
+	registeredFee *big.Int
+}
+
+func (m *mockRegistrationTransactor) FetchRegistrationFees(int64) (FeesResponse, error) {
+	return FeesResponse{Fee: m.fee}, nil
+}
+
+func (m *mockRegistrationTransactor) GetFreeRegistrationEligibility(identity.Identity) (bool, error) {
+	return m.free, nil
+}
+
+func (m *mockRegistrationTransactor) RegisterIdentity(_ string, _, fee *big.Int, _ string, _ int64, _ *string) error {
+	m.registeredFee = fee
+	return nil
+}
+
+type mockBalanceProvider struct {
+	balance *big.Int
+}
+
+func (m *mockBalanceProvider) ForceBalanceUpdateCached(int64, identity.Identity) *big.Int {
+	return m.balance
+}
+
+type mockChannelAddressProvider struct{}
+
+func (m *mockChannelAddressProvider) GetActiveChannelAddress(int64, common.Address) (common.Address, error) {
+	return common.HexToAddress("0x1"), nil
+}
+
+func newTestAutoRegistrar(statuses []RegistrationStatus, transactor *mockRegistrationTransactor, balance int64) *AutoRegistrar {
+	r := NewAutoRegistrar(
+		&mockStatusSequence{statuses: statuses},
+		transactor,
+		&mockBalanceProvider{balance: big.NewInt(balance)},
+		&mockChannelAddressProvider{},
+		time.Second,
+	)
+	r.pollInterval = time.Millisecond
+	return r
+}
+
+func TestAutoRegistrar_Register(t *testing.T) {
+	id := identity.FromAddress("0x2")
+
+	t.Run("registered identity is left as is", func(t *testing.T) {
+		transactor := &mockRegistrationTransactor{fee: big.NewInt(10)}
+		r := newTestAutoRegistrar([]RegistrationStatus{Registered}, transactor, 0)
+
+		assert.NoError(t, r.Register(context.Background(), 1, id))
+		assert.Nil(t, transactor.registeredFee)
+	})
+
+	t.Run("registers and waits for confirmation", func(t *testing.T) {
+		transactor := &mockRegistrationTransactor{fee: big.NewInt(10)}
+		r := newTestAutoRegistrar([]RegistrationStatus{Unregistered, InProgress, Registered}, transactor, 10)
+
+		assert.NoError(t, r.Register(context.Background(), 1, id))
+		assert.Equal(t, big.NewInt(10), transactor.registeredFee)
+	})
+
+	t.Run("free registration does not require balance", func(t *testing.T) {
+		transactor := &mockRegistrationTransactor{free: true, fee: big.NewInt(10)}
+		r := newTestAutoRegistrar([]RegistrationStatus{Unregistered, Registered}, transactor, 0)
+
+		assert.NoError(t, r.Register(context.Background(), 1, id))
+		assert.Equal(t, big.NewInt(0), transactor.registeredFee)
+	})
+
+	t.Run("insufficient balance", func(t *testing.T) {
+		transactor := &mockRegistrationTransactor{fee: big.NewInt(10)}
+		r := newTestAutoRegistrar([]RegistrationStatus{Unregistered}, transactor, 5)
+
+		err := r.Register(context.Background(), 1, id)
+		var balanceErr *InsufficientBalanceError
+		assert.True(t, errors.As(err, &balanceErr))
+		assert.Equal(t, common.HexToAddress("0x1"), balanceErr.ChannelAddress)
+		assert.Nil(t, transactor.registeredFee)
+	})
+
+	t.Run("failed registration", func(t *testing.T) {
+		transactor := &mockRegistrationTransactor{fee: big.NewInt(10)}
+		r := newTestAutoRegistrar([]RegistrationStatus{Unregistered, RegistrationError}, transactor, 10)
+
+		assert.ErrorIs(t, r.Register(context.Background(), 1, id), ErrRegistrationFailed)
+	})
+
+	t.Run("unconfirmed registration is pending", func(t *testing.T) {
+		transactor := &mockRegistrationTransactor{fee: big.NewInt(10)}
+		r := newTestAutoRegistrar([]RegistrationStatus{Unregistered, InProgress}, transactor, 10)
+		r.timeout = 20 * time.Millisecond
+
+		assert.ErrorIs(t, r.Register(context.Background(), 1, id), ErrRegistrationPending)
+	})
+}
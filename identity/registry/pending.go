@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// ErrNoPendingRegistration represents an error when identity has no registration waiting for confirmation.
+var ErrNoPendingRegistration = errors.New("identity has no pending registration")
+
+// ErrRegistrationNotStuck represents an error when re-submitting a registration which is still being processed.
+var ErrRegistrationNotStuck = errors.New("registration is not stuck yet")
+
+// ErrFeeTooLow represents an error when re-submitting a registration without increasing its fee.
+var ErrFeeTooLow = errors.New("fee must be higher than the fee of the pending registration")
+
+// PendingRegistration represents a registration which was submitted to transactor but is not confirmed yet.
+type PendingRegistration struct {
+	Identity         identity.Identity
+	ChainID          int64
+	Status           RegistrationStatus
+	TransactorStatus TransactorRegistrationEntryStatus
+	TxHash           string
+	Fee              *big.Int
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	// Stuck is set once the registration was not confirmed within the configured timeout.
+	Stuck bool
+}
+
+type pendingRegistrationTransactor interface {
+	FetchRegistrationStatus(id string) ([]TransactorStatusResponse, error)
+	RegisterIdentity(id string, stake, fee *big.Int, beneficiary string, chainID int64, referralToken *string) error
+}
+
+// PendingRegistrations detects registrations stuck in the transactor queue,
+// allowing to re-submit them with a higher fee or to cancel them.
+type PendingRegistrations struct {
+	storage      registryStorage
+	transactor   pendingRegistrationTransactor
+	publisher    eventbus.Publisher
+	stuckTimeout time.Duration
+}
+
+// NewPendingRegistrations returns a new instance of pending registrations.
+func NewPendingRegistrations(storage registryStorage, transactor pendingRegistrationTransactor, publisher eventbus.Publisher, stuckTimeout time.Duration) *PendingRegistrations {
+	return &PendingRegistrations{
+		storage:      storage,
+		transactor:   transactor,
+		publisher:    publisher,
+		stuckTimeout: stuckTimeout,
+	}
+}
+
+// Get returns the pending registration of the given identity.
+func (pr *PendingRegistrations) Get(chainID int64, id identity.Identity) (PendingRegistration, error) {
+	pending, _, err := pr.get(chainID, id)
+	return pending, err
+}
+
+func (pr *PendingRegistrations) get(chainID int64, id identity.Identity) (PendingRegistration, StoredRegistrationStatus, error) {
+	stored, err := pr.getStored(chainID, id)
+	if err != nil {
+		return PendingRegistration{}, StoredRegistrationStatus{}, err
+	}
+
+	pending := PendingRegistration{
+		Identity:  id,
+		ChainID:   chainID,
+		Status:    stored.RegistrationStatus,
+		Fee:       stored.RegistrationRequest.Fee,
+		CreatedAt: stored.UpdatedAt,
+		UpdatedAt: stored.UpdatedAt,
+	}
+
+	entries, err := pr.transactor.FetchRegistrationStatus(id.Address)
+	if err != nil {
+		return PendingRegistration{}, StoredRegistrationStatus{}, errors.Wrap(err, "could not fetch registration status from transactor")
+	}
+	for _, entry := range entries {
+		if entry.ChainID != chainID {
+			continue
+		}
+		pending.TransactorStatus = entry.Status
+		pending.TxHash = entry.TxHash
+		pending.CreatedAt = entry.CreatedAt
+		pending.UpdatedAt = entry.UpdatedAt
+		break
+	}
+
+	pending.Stuck = stored.RegistrationStatus == RegistrationError ||
+		pending.TransactorStatus == TransactorRegistrationEntryStatusFailed ||
+		time.Since(pending.UpdatedAt) > pr.stuckTimeout
+
+	return pending, stored, nil
+}
+
+// BumpFee re-submits the stuck registration with a higher fee.
+func (pr *PendingRegistrations) BumpFee(chainID int64, id identity.Identity, fee *big.Int) error {
+	pending, stored, err := pr.get(chainID, id)
+	if err != nil {
+		return err
+	}
+
+	if !pending.Stuck {
+		return ErrRegistrationNotStuck
+	}
+
+	if fee == nil || (pending.Fee != nil && fee.Cmp(pending.Fee) <= 0) {
+		return ErrFeeTooLow
+	}
+
+	req := stored.RegistrationRequest
+	var referralToken *string
+	if req.ReferralToken != "" {
+		referralToken = &req.ReferralToken
+	}
+	return pr.transactor.RegisterIdentity(id.Address, req.Stake, fee, req.Beneficiary, chainID, referralToken)
+}
+
+// Cancel stops waiting for the pending registration and marks the identity as unregistered,
+// so the registration can be started again. Should the cancelled transaction still get mined,
+// the identity will be reported as registered on the next status check.
+func (pr *PendingRegistrations) Cancel(chainID int64, id identity.Identity) error {
+	if _, err := pr.getStored(chainID, id); err != nil {
+		return err
+	}
+
+	err := pr.storage.Store(StoredRegistrationStatus{
+		Identity:           id,
+		RegistrationStatus: Unregistered,
+		ChainID:            chainID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "could not store registration status")
+	}
+
+	pr.publisher.Publish(AppTopicIdentityRegistration, AppEventIdentityRegistration{
+		ID:      id,
+		Status:  Unregistered,
+		ChainID: chainID,
+	})
+	return nil
+}
+
+func (pr *PendingRegistrations) getStored(chainID int64, id identity.Identity) (StoredRegistrationStatus, error) {
+	stored, err := pr.storage.Get(chainID, id)
+	if errors.Is(err, ErrNotFound) {
+		return StoredRegistrationStatus{}, ErrNoPendingRegistration
+	}
+	if err != nil {
+		return StoredRegistrationStatus{}, err
+	}
+
+	if stored.RegistrationStatus != InProgress && stored.RegistrationStatus != RegistrationError {
+		return StoredRegistrationStatus{}, ErrNoPendingRegistration
+	}
+	return stored, nil
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockPendingRegistrationTransactor struct {
+	entries []TransactorStatusResponse
+
+	registeredFee           *big.Int
+	registeredBeneficiary   string
+	registeredReferralToken *string
+}
+
+func (m *mockPendingRegistrationTransactor) FetchRegistrationStatus(_ string) ([]TransactorStatusResponse, error) {
+	return m.entries, nil
+}
+
+func (m *mockPendingRegistrationTransactor) RegisterIdentity(_ string, _, fee *big.Int, beneficiary string, _ int64, referralToken *string) error {
+	m.registeredFee = fee
+	m.registeredBeneficiary = beneficiary
+	m.registeredReferralToken = referralToken
+	return nil
+}
+
+type mockRegistrationPublisher struct {
+	published []interface{}
+}
+
+func (m *mockRegistrationPublisher) Publish(_ string, data interface{}) {
+	m.published = append(m.published, data)
+}
+
+func newTestRegistrationStorage(t *testing.T) *RegistrationStatusStorage {
+	dir, err := os.MkdirTemp("", "pendingRegistrationsTest")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	t.Cleanup(func() { bolt.Close() })
+
+	return NewRegistrationStatusStorage(bolt)
+}
+
+func TestPendingRegistrations_BumpFee(t *testing.T) {
+	var chainID int64 = 1
+	id := identity.FromAddress("0x001")
+	storage := newTestRegistrationStorage(t)
+	assert.NoError(t, storage.Store(StoredRegistrationStatus{
+		RegistrationStatus: InProgress,
+		Identity:           id,
+		ChainID:            chainID,
+		RegistrationRequest: IdentityRegistrationRequest{
+			Identity:    id.Address,
+			Fee:         big.NewInt(100),
+			Beneficiary: "0xbeneficiary",
+			Signature:   "0xsig",
+		},
+	}))
+
+	transactor := &mockPendingRegistrationTransactor{entries: []TransactorStatusResponse{
+		{ChainID: 2, Status: TransactorRegistrationEntryStatusSucceed},
+		{ChainID: chainID, Status: TransactorRegistrationEntryStatusCreated, TxHash: "0xhash", CreatedAt: time.Now(), UpdatedAt: time.Now()},
+	}}
+	pending := NewPendingRegistrations(storage, transactor, &mockRegistrationPublisher{}, time.Hour)
+
+	registration, err := pending.Get(chainID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "0xhash", registration.TxHash)
+	assert.Equal(t, TransactorRegistrationEntryStatusCreated, registration.TransactorStatus)
+	assert.Equal(t, big.NewInt(100), registration.Fee)
+	assert.False(t, registration.Stuck)
+
+	assert.Equal(t, ErrRegistrationNotStuck, pending.BumpFee(chainID, id, big.NewInt(200)))
+
+	transactor.entries[1].UpdatedAt = time.Now().Add(-2 * time.Hour)
+	registration, err = pending.Get(chainID, id)
+	assert.NoError(t, err)
+	assert.True(t, registration.Stuck)
+
+	assert.Equal(t, ErrFeeTooLow, pending.BumpFee(chainID, id, big.NewInt(100)))
+	assert.Nil(t, transactor.registeredFee)
+
+	assert.NoError(t, pending.BumpFee(chainID, id, big.NewInt(200)))
+	assert.Equal(t, big.NewInt(200), transactor.registeredFee)
+	assert.Equal(t, "0xbeneficiary", transactor.registeredBeneficiary)
+	assert.Nil(t, transactor.registeredReferralToken)
+}
+
+func TestPendingRegistrations_BumpFee_KeepsReferralToken(t *testing.T) {
+	var chainID int64 = 1
+	id := identity.FromAddress("0x001")
+	storage := newTestRegistrationStorage(t)
+	assert.NoError(t, storage.Store(StoredRegistrationStatus{
+		RegistrationStatus: RegistrationError,
+		Identity:           id,
+		ChainID:            chainID,
+		RegistrationRequest: IdentityRegistrationRequest{
+			Identity:      id.Address,
+			Fee:           big.NewInt(0),
+			Beneficiary:   "0xbeneficiary",
+			ReferralToken: "token",
+		},
+	}))
+
+	transactor := &mockPendingRegistrationTransactor{}
+	pending := NewPendingRegistrations(storage, transactor, &mockRegistrationPublisher{}, time.Hour)
+
+	assert.NoError(t, pending.BumpFee(chainID, id, big.NewInt(200)))
+	if assert.NotNil(t, transactor.registeredReferralToken) {
+		assert.Equal(t, "token", *transactor.registeredReferralToken)
+	}
+}
+
+func TestPendingRegistrations_Cancel(t *testing.T) {
+	var chainID int64 = 1
+	id := identity.FromAddress("0x001")
+	storage := newTestRegistrationStorage(t)
+	publisher := &mockRegistrationPublisher{}
+	pending := NewPendingRegistrations(storage, &mockPendingRegistrationTransactor{}, publisher, time.Hour)
+
+	assert.Equal(t, ErrNoPendingRegistration, pending.Cancel(chainID, id))
+
+	assert.NoError(t, storage.Store(StoredRegistrationStatus{
+		RegistrationStatus: RegistrationError,
+		Identity:           id,
+		ChainID:            chainID,
+	}))
+	assert.NoError(t, pending.Cancel(chainID, id))
+
+	stored, err := storage.Get(chainID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, Unregistered, stored.RegistrationStatus)
+	assert.Equal(t, []interface{}{AppEventIdentityRegistration{ID: id, Status: Unregistered, ChainID: chainID}}, publisher.published)
+
+	_, err = pending.Get(chainID, id)
+	assert.Equal(t, ErrNoPendingRegistration, err)
+}
@@ -210,9 +210,10 @@ func (registry *contractRegistry) handleRegistrationEvent(ev IdentityRegistratio
 		ChainID: ev.ChainID,
 	})
 	err = registry.storage.Store(StoredRegistrationStatus{
-		Identity:           ID,
-		RegistrationStatus: s,
-		ChainID:            ev.ChainID,
+		Identity:            ID,
+		RegistrationStatus:  s,
+		ChainID:             ev.ChainID,
+		RegistrationRequest: ev,
 	})
 	if err != nil {
 		log.Error().Err(err).Stack().Msg("Could not store registration status")
@@ -233,6 +234,11 @@ func (registry *contractRegistry) subscribeToRegistrationEventViaTransactor(ev I
 			registry.saveRegistrationStatus(ev.ChainID, ev.Identity, RegistrationError)
 			return
 		case <-time.After(registry.cfg.TransactorPollInterval):
+			if registry.isSuperseded(ev) {
+				log.Info().Msgf("Registration of %q was re-submitted or cancelled, stopping the watch", ev.Identity)
+				return
+			}
+
 			res, err := registry.transactor.FetchRegistrationStatus(ev.Identity)
 			if err != nil {
 				log.Warn().Err(err).Msg("could not fetch registration status from transactor")
@@ -278,6 +284,21 @@ func (registry *contractRegistry) subscribeToRegistrationEventViaTransactor(ev I
 	}
 }
 
+// isSuperseded checks whether the watched registration request is no longer the pending one,
+// e.g. it was re-submitted with a higher fee or cancelled.
+func (registry *contractRegistry) isSuperseded(ev IdentityRegistrationRequest) bool {
+	status, err := registry.storage.Get(ev.ChainID, identity.FromAddress(ev.Identity))
+	if err != nil {
+		return false
+	}
+
+	if status.RegistrationStatus != InProgress {
+		return true
+	}
+
+	return status.RegistrationRequest.Signature != "" && status.RegistrationRequest.Signature != ev.Signature
+}
+
 func (registry *contractRegistry) resyncWithBC(chainID int64, id string) {
 	status, err := registry.bcRegistrationStatus(chainID, identity.FromAddress(id))
 	if err != nil {
@@ -438,6 +459,10 @@ func (registry *contractRegistry) bcRegistrationStatus(chainID int64, id identit
 // AppTopicEthereumClientReconnected indicates that the ethereum client has reconnected.
 var AppTopicEthereumClientReconnected = "ether-client-reconnect"
 
+func init() {
+	eventbus.RegisterSchema(AppTopicEthereumClientReconnected, 1, struct{}{})
+}
+
 func (registry *contractRegistry) handleEtherClientReconnect(_ interface{}) {
 	err := registry.loadInitialState()
 	if err != nil {
@@ -20,6 +20,7 @@ package registry
 import (
 	"time"
 
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 )
 
@@ -83,6 +84,10 @@ func (srs StoredRegistrationStatus) FromEvent(status RegistrationStatus, ev Iden
 // AppTopicIdentityRegistration represents the registration event topic.
 const AppTopicIdentityRegistration = "registration_event_topic"
 
+func init() {
+	eventbus.RegisterSchema(AppTopicIdentityRegistration, 1, AppEventIdentityRegistration{})
+}
+
 // AppEventIdentityRegistration represents the registration event payload.
 type AppEventIdentityRegistration struct {
 	ID      identity.Identity
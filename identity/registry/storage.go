@@ -77,6 +77,11 @@ func (rss *RegistrationStatusStorage) Store(status StoredRegistrationStatus) err
 		s.RegistrationStatus = status.RegistrationStatus
 	}
 
+	// keep the latest submitted request, so it can be re-submitted if it gets stuck
+	if status.RegistrationRequest.Identity != "" {
+		s.RegistrationRequest = status.RegistrationRequest
+	}
+
 	return rss.store(s)
 }
 
@@ -21,10 +21,12 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/payments/client"
 	pc "github.com/mysteriumnetwork/payments/crypto"
 	"github.com/mysteriumnetwork/payments/registration"
@@ -39,6 +41,10 @@ import (
 // AppTopicTransactorRegistration represents the registration topic to which events regarding registration attempts on transactor will occur
 const AppTopicTransactorRegistration = "transactor_identity_registration"
 
+func init() {
+	eventbus.RegisterSchema(AppTopicTransactorRegistration, 1, IdentityRegistrationRequest{})
+}
+
 type channelProvider interface {
 	GetProviderChannel(chainID int64, hermesAddress common.Address, addressToCheck common.Address, pending bool) (client.ProviderChannel, error)
 	GetLastRegistryNonce(chainID int64, registry common.Address) (*big.Int, error)
@@ -54,12 +60,18 @@ type AddressProvider interface {
 	GetMystAddress(chainID int64) (common.Address, error)
 }
 
+// feeAdjuster adjusts the transactor fees to the chain they are paid on.
+type feeAdjuster interface {
+	AdjustFee(chainID int64, fee *big.Int) *big.Int
+}
+
 type feeType uint8
 
 const (
 	settleFeeType        feeType = iota
 	registrationFeeType          = 1
 	stakeDecreaseFeeType         = 2
+	batchSettleFeeType           = 3
 )
 
 type feeCacher struct {
@@ -115,10 +127,11 @@ type Transactor struct {
 	bc              channelProvider
 	addresser       AddressProvider
 	feeCache        *feeCacher
+	fees            feeAdjuster
 }
 
 // NewTransactor creates and returns new Transactor instance
-func NewTransactor(httpClient *requests.HTTPClient, endpointAddress string, addresser AddressProvider, signerFactory identity.SignerFactory, publisher eventbus.Publisher, bc channelProvider, feesValidTime time.Duration) *Transactor {
+func NewTransactor(httpClient *requests.HTTPClient, endpointAddress string, addresser AddressProvider, signerFactory identity.SignerFactory, publisher eventbus.Publisher, bc channelProvider, feesValidTime time.Duration, fees feeAdjuster) *Transactor {
 	return &Transactor{
 		httpClient:      httpClient,
 		endpointAddress: endpointAddress,
@@ -127,9 +140,17 @@ func NewTransactor(httpClient *requests.HTTPClient, endpointAddress string, addr
 		publisher:       publisher,
 		bc:              bc,
 		feeCache:        newFeeCacher(feesValidTime),
+		fees:            fees,
 	}
 }
 
+func (t *Transactor) adjustFee(chainID int64, fee *big.Int) *big.Int {
+	if t.fees == nil {
+		return fee
+	}
+	return t.fees.AdjustFee(chainID, fee)
+}
+
 // FeesResponse represents fees applied by Transactor
 type FeesResponse struct {
 	Fee        *big.Int  `json:"fee"`
@@ -157,6 +178,9 @@ type IdentityRegistrationRequest struct {
 	Signature string `json:"signature"`
 	Identity  string `json:"identity"`
 	ChainID   int64  `json:"chainID"`
+	// ReferralToken is the token the registration was requested with, kept to re-submit the registration.
+	// It is not a part of the request body, referral registrations send it separately.
+	ReferralToken string `json:"referralToken,omitempty"`
 }
 
 // PromiseSettlementRequest represents the settlement request body
@@ -186,6 +210,7 @@ func (t *Transactor) FetchRegistrationFees(chainID int64) (FeesResponse, error)
 
 	err = t.httpClient.DoRequestAndParseResponse(req, &f)
 	if err == nil {
+		f.Fee = t.adjustFee(chainID, f.Fee)
 		t.feeCache.cacheFee(chainID, registrationFeeType, f)
 	}
 	return f, err
@@ -206,11 +231,37 @@ func (t *Transactor) FetchSettleFees(chainID int64) (FeesResponse, error) {
 
 	err = t.httpClient.DoRequestAndParseResponse(req, &f)
 	if err == nil {
+		f.Fee = t.adjustFee(chainID, f.Fee)
 		t.feeCache.cacheFee(chainID, settleFeeType, f)
 	}
 	return f, err
 }
 
+// FetchBatchSettleFees fetches the current transactor fee of settling multiple promises in a single transaction.
+// It returns ErrBatchSettlementUnsupported if the transactor can not settle them in a single transaction.
+func (t *Transactor) FetchBatchSettleFees(chainID int64) (FeesResponse, error) {
+	cachedFees := t.feeCache.getCachedFee(chainID, batchSettleFeeType)
+	if cachedFees != nil {
+		return *cachedFees, nil
+	}
+
+	f := FeesResponse{}
+	req, err := requests.NewGetRequest(t.endpointAddress, fmt.Sprintf("fee/%v/settle/batch", chainID), nil)
+	if err != nil {
+		return f, errors.Wrap(err, "failed to fetch transactor fees")
+	}
+
+	err = t.httpClient.DoRequestAndParseResponse(req, &f)
+	if isUnsupported(err) {
+		return f, ErrBatchSettlementUnsupported
+	}
+	if err == nil {
+		f.Fee = t.adjustFee(chainID, f.Fee)
+		t.feeCache.cacheFee(chainID, batchSettleFeeType, f)
+	}
+	return f, err
+}
+
 // FetchStakeDecreaseFee fetches current transactor stake decrease fees.
 func (t *Transactor) FetchStakeDecreaseFee(chainID int64) (FeesResponse, error) {
 	cachedFees := t.feeCache.getCachedFee(chainID, stakeDecreaseFeeType)
@@ -226,6 +277,7 @@ func (t *Transactor) FetchStakeDecreaseFee(chainID int64) (FeesResponse, error)
 
 	err = t.httpClient.DoRequestAndParseResponse(req, &f)
 	if err == nil {
+		f.Fee = t.adjustFee(chainID, f.Fee)
 		t.feeCache.cacheFee(chainID, stakeDecreaseFeeType, f)
 	}
 	return f, err
@@ -255,6 +307,13 @@ func (t *Transactor) FetchCombinedFees(chainID int64) (CombinedFeesResponse, err
 	}
 
 	err = t.httpClient.DoRequestAndParseResponse(req, &f)
+	if err == nil {
+		for _, fees := range []*Fees{&f.Current, &f.Last} {
+			fees.DecreaseStake = t.adjustFee(chainID, fees.DecreaseStake)
+			fees.Settle = t.adjustFee(chainID, fees.Settle)
+			fees.Register = t.adjustFee(chainID, fees.Register)
+		}
+	}
 	return f, err
 }
 
@@ -279,6 +338,64 @@ func (t *Transactor) SettleAndRebalance(hermesID, providerID string, promise pc.
 	return res.ID, t.httpClient.DoRequestAndParseResponse(req, &res)
 }
 
+// ErrBatchSettlementUnsupported indicates that the transactor can not settle multiple promises in a single transaction.
+var ErrBatchSettlementUnsupported = errors.New("batch settlement is not supported by transactor")
+
+// BatchSettlement represents a single promise settled within a batch settlement.
+type BatchSettlement struct {
+	HermesID string
+	Promise  pc.Promise
+}
+
+// BatchSettlementRequest represents the request to settle multiple promises of the provider in a single transaction.
+type BatchSettlementRequest struct {
+	ProviderID  string                     `json:"providerID"`
+	ChainID     int64                      `json:"chainID"`
+	Settlements []PromiseSettlementRequest `json:"settlements"`
+}
+
+// SettleAndRebalanceBatch requests the transactor to settle and rebalance the given channels of the provider in a single transaction.
+func (t *Transactor) SettleAndRebalanceBatch(providerID string, settlements []BatchSettlement) (string, error) {
+	if len(settlements) == 0 {
+		return "", errors.New("nothing to settle")
+	}
+
+	payload := BatchSettlementRequest{
+		ProviderID: providerID,
+		ChainID:    settlements[0].Promise.ChainID,
+	}
+	for _, settlement := range settlements {
+		promise := settlement.Promise
+		payload.Settlements = append(payload.Settlements, PromiseSettlementRequest{
+			HermesID:      settlement.HermesID,
+			ProviderID:    providerID,
+			ChannelID:     hex.EncodeToString(promise.ChannelID),
+			Amount:        promise.Amount,
+			TransactorFee: promise.Fee,
+			Preimage:      hex.EncodeToString(promise.R),
+			Signature:     hex.EncodeToString(promise.Signature),
+			ChainID:       promise.ChainID,
+		})
+	}
+
+	req, err := requests.NewPostRequest(t.endpointAddress, "identity/settle_and_rebalance/batch", payload)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create batch settle and rebalance request")
+	}
+
+	res := SettleResponse{}
+	err = t.httpClient.DoRequestAndParseResponse(req, &res)
+	if isUnsupported(err) {
+		return "", ErrBatchSettlementUnsupported
+	}
+	return res.ID, err
+}
+
+func isUnsupported(err error) bool {
+	var apiErr *apierror.APIError
+	return errors.As(err, &apiErr) && (apiErr.Status == http.StatusNotFound || apiErr.Status == http.StatusNotImplemented)
+}
+
 func (t *Transactor) registerIdentity(endpoint string, id string, stake, fee *big.Int, beneficiary string, chainID int64) error {
 	regReq, err := t.fillIdentityRegistrationRequest(id, stake, fee, beneficiary, chainID)
 	if err != nil {
@@ -340,6 +457,7 @@ func (t *Transactor) registerIdentityWithReferralToken(id string, stake *big.Int
 
 	// This is left as a synchronous call on purpose.
 	// We need to notify registry before returning.
+	regReq.ReferralToken = token
 	t.publisher.Publish(AppTopicTransactorRegistration, regReq)
 
 	return nil
@@ -821,14 +939,22 @@ func (t *Transactor) PayAndSettle(hermesID, providerID string, promise pc.Promis
 
 // DecreaseProviderStakeRequest represents all the parameters required for decreasing provider stake.
 type DecreaseProviderStakeRequest struct {
-	ChannelID     string `json:"channel_id,omitempty"`
-	Nonce         uint64 `json:"nonce,omitempty"`
-	HermesID      string `json:"hermes_id,omitempty"`
-	Amount        uint64 `json:"amount,omitempty"`
-	TransactorFee uint64 `json:"transactor_fee,omitempty"`
-	Signature     string `json:"signature,omitempty"`
-	ChainID       int64  `json:"chain_id"`
-	ProviderID    string `json:"providerID"`
+	ChannelID     string   `json:"channel_id,omitempty"`
+	Nonce         *big.Int `json:"nonce,omitempty"`
+	HermesID      string   `json:"hermes_id,omitempty"`
+	Amount        *big.Int `json:"amount,omitempty"`
+	TransactorFee *big.Int `json:"transactor_fee,omitempty"`
+	Signature     string   `json:"signature,omitempty"`
+	ChainID       int64    `json:"chain_id"`
+	ProviderID    string   `json:"providerID"`
+}
+
+// omitZero returns nil for zero amounts, so they are omitted from the request as zero integers used to be.
+func omitZero(amount *big.Int) *big.Int {
+	if amount == nil || amount.Sign() == 0 {
+		return nil
+	}
+	return amount
 }
 
 // DecreaseStake requests the transactor to decrease stake.
@@ -888,10 +1014,10 @@ func (t *Transactor) fillDecreaseStakeRequest(id string, chainID int64, amount,
 	regReq := DecreaseProviderStakeRequest{
 		Signature:     signatureHex,
 		ChannelID:     common.Bytes2Hex(req.ChannelID[:]),
-		Nonce:         req.Nonce.Uint64(),
+		Nonce:         omitZero(req.Nonce),
 		HermesID:      req.HermesID.Hex(),
-		Amount:        req.Amount.Uint64(),
-		TransactorFee: req.TransactorFee.Uint64(),
+		Amount:        omitZero(req.Amount),
+		TransactorFee: omitZero(req.TransactorFee),
 		ChainID:       req.ChainID,
 		ProviderID:    id,
 	}
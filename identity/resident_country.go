@@ -29,6 +29,10 @@ import (
 // AppTopicResidentCountry resident country event topic
 const AppTopicResidentCountry = "resident-country"
 
+func init() {
+	eventbus.RegisterSchema(AppTopicResidentCountry, 1, ResidentCountryEvent{})
+}
+
 type locationProvider interface {
 	GetOrigin() locationstate.Location
 }
@@ -17,6 +17,11 @@
 
 package market
 
+import (
+	"fmt"
+	"strings"
+)
+
 const (
 	// AccessPolicyTypeIdentity Explicitly allow just specific identities ("0xd1faed693fec75389c3d1e59b863e4835ac6f5d1")
 	AccessPolicyTypeIdentity = "identity"
@@ -24,12 +29,33 @@ const (
 	AccessPolicyTypeDNSHostname = "dns_hostname"
 	// AccessPolicyTypeDNSZone Explicitly allow just specific DNS zone ("example.com" matches "example.com" and all of its subdomains)
 	AccessPolicyTypeDNSZone = "dns_zone"
+	// AccessPolicyTypeCountry Explicitly allow just consumers from specific country ("DE")
+	AccessPolicyTypeCountry = "country"
 )
 
+// AccessPolicyConsumerCountry is the ID of the policy which lists the countries consumers may connect from.
+const AccessPolicyConsumerCountry = "consumer-country"
+
 // AccessPolicy represents the access controls for proposal
 type AccessPolicy struct {
 	ID     string `json:"id"`
 	Source string `json:"source"`
+	// Allow carries the rules of the policies advertised along with the proposal instead of being fetched from the source.
+	Allow []AccessRule `json:"allow,omitempty"`
+}
+
+// String returns the policy identification, the advertised rules are omitted.
+func (ap AccessPolicy) String() string {
+	return fmt.Sprintf("{%s %s}", ap.ID, ap.Source)
+}
+
+// NewConsumerCountryPolicy returns the policy allowing consumers from the given countries only.
+func NewConsumerCountryPolicy(countries []string) AccessPolicy {
+	rules := make([]AccessRule, 0, len(countries))
+	for _, country := range countries {
+		rules = append(rules, AccessRule{Type: AccessPolicyTypeCountry, Value: strings.ToUpper(strings.TrimSpace(country))})
+	}
+	return AccessPolicy{ID: AccessPolicyConsumerCountry, Allow: rules}
 }
 
 // AccessPolicyRuleSet represents named list with rules specifying whether access is allowed
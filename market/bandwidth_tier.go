@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// BandwidthTier is a service level offered within a single proposal,
+// limiting session bandwidth for a price relative to the proposal price.
+type BandwidthTier struct {
+	// Name identifies the tier selected by consumer at session setup.
+	Name string `json:"name"`
+	// Bandwidth is the session bandwidth limit in Kbytes, zero means unlimited.
+	Bandwidth uint64 `json:"bandwidth"`
+	// PricePercent is the tier price expressed as a percentage of the proposal price.
+	PricePercent uint64 `json:"price_percent"`
+}
+
+// Price returns the tier price derived from the given proposal price.
+func (t BandwidthTier) Price(base Price) Price {
+	percent := new(big.Int).SetUint64(t.PricePercent)
+	hundred := big.NewInt(100)
+
+	scale := func(v *big.Int) *big.Int {
+		if v == nil {
+			return big.NewInt(0)
+		}
+		res := new(big.Int).Mul(v, percent)
+		return res.Div(res, hundred)
+	}
+
+	return Price{
+		PricePerHour: scale(base.PricePerHour),
+		PricePerGiB:  scale(base.PricePerGiB),
+	}
+}
+
+// String returns tier in the same format as accepted by ParseBandwidthTiers.
+func (t BandwidthTier) String() string {
+	return fmt.Sprintf("%s:%d:%d", t.Name, t.Bandwidth, t.PricePercent)
+}
+
+// ParseBandwidthTiers parses comma separated list of tiers formatted as "name:bandwidth:price_percent",
+// e.g. "basic:1250:50,premium:0:150".
+func ParseBandwidthTiers(value string) ([]BandwidthTier, error) {
+	var tiers []BandwidthTier
+	names := make(map[string]struct{})
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid bandwidth tier %q, expected name:bandwidth:price_percent", entry)
+		}
+		if _, ok := names[parts[0]]; ok {
+			return nil, fmt.Errorf("duplicate bandwidth tier %q", parts[0])
+		}
+		names[parts[0]] = struct{}{}
+
+		bandwidth, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bandwidth of tier %q: %w", parts[0], err)
+		}
+		percent, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price percent of tier %q: %w", parts[0], err)
+		}
+
+		tiers = append(tiers, BandwidthTier{Name: parts[0], Bandwidth: bandwidth, PricePercent: percent})
+	}
+
+	return tiers, nil
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BandwidthTier_Price(t *testing.T) {
+	base := Price{PricePerHour: big.NewInt(1000), PricePerGiB: big.NewInt(333)}
+
+	assert.Equal(t, Price{PricePerHour: big.NewInt(500), PricePerGiB: big.NewInt(166)}, BandwidthTier{PricePercent: 50}.Price(base))
+	assert.Equal(t, Price{PricePerHour: big.NewInt(1500), PricePerGiB: big.NewInt(499)}, BandwidthTier{PricePercent: 150}.Price(base))
+	assert.True(t, BandwidthTier{PricePercent: 0}.Price(base).IsFree())
+}
+
+func Test_ParseBandwidthTiers(t *testing.T) {
+	tiers, err := ParseBandwidthTiers("basic:1250:50, premium:0:150")
+	assert.NoError(t, err)
+	assert.Equal(t, []BandwidthTier{
+		{Name: "basic", Bandwidth: 1250, PricePercent: 50},
+		{Name: "premium", Bandwidth: 0, PricePercent: 150},
+	}, tiers)
+	assert.Equal(t, "basic:1250:50", tiers[0].String())
+
+	tiers, err = ParseBandwidthTiers("")
+	assert.NoError(t, err)
+	assert.Empty(t, tiers)
+
+	for _, value := range []string{"basic", ":1250:50", "basic:fast:50", "basic:1250:-1", "basic:1:1,basic:2:2"} {
+		_, err = ParseBandwidthTiers(value)
+		assert.Error(t, err, value)
+	}
+}
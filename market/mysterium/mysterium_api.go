@@ -18,6 +18,10 @@
 package mysterium
 
 import (
+	"bufio"
+	"encoding/json"
+	"io"
+
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
@@ -56,13 +60,18 @@ func (mApi *MysteriumAPI) QueryProposals(query ProposalsQuery) ([]market.Service
 		return nil, err
 	}
 
-	var proposals []market.ServiceProposal
-	if err := requests.ParseResponseJSON(res, &proposals); err != nil {
+	// Unsupported proposals are dropped while decoding, so the whole
+	// response is never kept in memory at once.
+	var supported []market.ServiceProposal
+	total, err := decodeProposals(res.Body, func(proposal market.ServiceProposal) {
+		if proposal.Validate() == nil && proposal.IsSupported() {
+			supported = append(supported, proposal)
+		}
+	})
+	if err != nil {
 		return nil, errors.Wrap(err, "cannot parse proposals response")
 	}
 
-	total := len(proposals)
-	supported := supportedProposalsOnly(proposals)
 	log.Debug().Msgf("Total proposals: %d supported: %d", total, len(supported))
 	return supported, nil
 }
@@ -112,11 +121,64 @@ func (mApi *MysteriumAPI) GetPricing() (market.LatestPrices, error) {
 	return result, nil
 }
 
-func supportedProposalsOnly(proposals []market.ServiceProposal) (supported []market.ServiceProposal) {
-	for _, proposal := range proposals {
-		if proposal.Validate() == nil && proposal.IsSupported() {
-			supported = append(supported, proposal)
+// decodeProposals incrementally decodes either JSON array or newline delimited
+// JSON stream of proposals, passing each of them to the handler.
+func decodeProposals(r io.Reader, handler func(market.ServiceProposal)) (total int, err error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+
+	first, err := peekNonSpace(reader)
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if first != '[' {
+		for {
+			var proposal *market.ServiceProposal
+			if err := decoder.Decode(&proposal); err == io.EOF {
+				return total, nil
+			} else if err != nil {
+				return total, err
+			}
+			if proposal == nil {
+				continue
+			}
+			total++
+			handler(*proposal)
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return 0, err
+	}
+	for decoder.More() {
+		var proposal market.ServiceProposal
+		if err := decoder.Decode(&proposal); err != nil {
+			return total, err
+		}
+		total++
+		handler(proposal)
+	}
+	_, err = decoder.Token()
+	return total, err
+}
+
+func peekNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			if _, err := reader.Discard(1); err != nil {
+				return 0, err
+			}
+		default:
+			return b[0], nil
 		}
 	}
-	return
 }
@@ -18,13 +18,32 @@
 package mysterium
 
 import (
+	"compress/gzip"
+	"encoding/json"
 	"net"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockContact struct{}
+
+func init() {
+	market.RegisterServiceType("mock_service")
+	market.RegisterContactUnserializer("mock_contact", func(rawMessage *json.RawMessage) (market.ContactDefinition, error) {
+		return mockContact{}, nil
+	})
+}
+
+const (
+	supportedProposalJSON   = `{"format":"service-proposal/v3","provider_id":"0x1","service_type":"mock_service","location":{"country":"LT"},"contacts":[{"type":"mock_contact"}]}`
+	unsupportedProposalJSON = `{"format":"service-proposal/v3","provider_id":"0x2","service_type":"unknown_service","location":{"country":"LT"},"contacts":[{"type":"mock_contact"}]}`
 )
 
 const bindAllAddress = "0.0.0.0"
@@ -56,6 +75,69 @@ func TestHttpTransportDoesntBlockForeverIfServerFailsToSendAnyResponse(t *testin
 	}
 }
 
+func TestQueryProposalsDecodesCompressedResponse(t *testing.T) {
+	address, err := createHTTPServer(func(writer http.ResponseWriter, request *http.Request) {
+		if !strings.Contains(request.Header.Get("Accept-Encoding"), "gzip") {
+			writer.Write([]byte("[]"))
+			return
+		}
+		writer.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(writer)
+		defer gz.Close()
+		gz.Write([]byte("[" + supportedProposalJSON + "," + unsupportedProposalJSON + "]"))
+	})
+	require.NoError(t, err)
+
+	api := NewClient(requests.NewHTTPClient(bindAllAddress, time.Second), "http://"+address+"/")
+	proposals, err := api.QueryProposals(ProposalsQuery{})
+
+	require.NoError(t, err)
+	require.Len(t, proposals, 1)
+	assert.Equal(t, "0x1", proposals[0].ProviderID)
+}
+
+func TestDecodeProposals(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty body", body: ""},
+		{name: "null", body: "null"},
+		{name: "empty array", body: " []"},
+		{
+			name: "array",
+			body: "[" + supportedProposalJSON + "," + unsupportedProposalJSON + "]",
+			want: []string{"0x1", "0x2"},
+		},
+		{
+			name: "newline delimited",
+			body: supportedProposalJSON + "\n" + unsupportedProposalJSON + "\n",
+			want: []string{"0x1", "0x2"},
+		},
+		{
+			name:    "truncated array",
+			body:    "[" + supportedProposalJSON + ",",
+			want:    []string{"0x1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			total, err := decodeProposals(strings.NewReader(tt.body), func(proposal market.ServiceProposal) {
+				got = append(got, proposal.ProviderID)
+			})
+
+			assert.Equal(t, tt.wantErr, err != nil, "unexpected error: %v", err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.want), total)
+		})
+	}
+}
+
 func createHTTPServer(handlerFunc http.HandlerFunc) (address string, err error) {
 	listener, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
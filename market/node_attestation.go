@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// NodeMetadata describes the software the provider node is running.
+type NodeMetadata struct {
+	Version  string `json:"version"`
+	Launcher string `json:"launcher,omitempty"`
+	OS       string `json:"os"`
+	// IssuedAt is the unix time the metadata was signed at.
+	IssuedAt int64 `json:"issued_at"`
+}
+
+// NodeAttestation is the node metadata signed by the attestation key of the provider.
+// The attestation key itself is endorsed by the provider identity, so the key can be
+// rotated without changing the identity.
+//
+// The metadata is reported by the provider itself. The signatures only prove that the provider identity
+// vouches for it and that it was not altered on the way, not that the node really runs the reported software.
+type NodeAttestation struct {
+	Metadata NodeMetadata `json:"metadata"`
+	// PublicKey is the hex encoded ed25519 attestation key.
+	PublicKey string `json:"public_key"`
+	// KeySignature is the provider identity signature of the attestation key.
+	KeySignature string `json:"key_signature"`
+	// Signature is the attestation key signature of the metadata.
+	Signature string `json:"signature"`
+}
+
+// AttestationKeyMessage returns the message provider identity signs to endorse the attestation key.
+func AttestationKeyMessage(providerID, publicKey string) []byte {
+	return attestationMessage("node attestation key", providerID, publicKey)
+}
+
+// AttestationMessage returns the message attestation key signs to attest the node metadata.
+func AttestationMessage(providerID string, metadata NodeMetadata) []byte {
+	return attestationMessage("node attestation", providerID, metadata.Version, metadata.Launcher, metadata.OS, strconv.FormatInt(metadata.IssuedAt, 10))
+}
+
+// attestationMessage prefixes every field with its length, so no two different sets of fields encode to the same message.
+func attestationMessage(fields ...string) []byte {
+	var msg []byte
+	for _, field := range fields {
+		msg = binary.BigEndian.AppendUint32(msg, uint32(len(field)))
+		msg = append(msg, field...)
+	}
+	return msg
+}
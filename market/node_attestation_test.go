@@ -0,0 +1,32 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttestationMessage_FieldsCanNotBeShifted(t *testing.T) {
+	shifted := AttestationMessage("0x1", NodeMetadata{Version: "1.2.3 docker", OS: "linux", IssuedAt: 1})
+	original := AttestationMessage("0x1", NodeMetadata{Version: "1.2.3", Launcher: "docker linux", IssuedAt: 1})
+
+	assert.NotEqual(t, original, shifted)
+	assert.NotEqual(t, AttestationMessage("0x1", NodeMetadata{Version: "1.2.3", OS: "linux", IssuedAt: 1}), AttestationKeyMessage("0x1", "1.2.3"))
+}
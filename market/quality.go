@@ -21,6 +21,7 @@ package market
 type Quality struct {
 	Quality   float64 `json:"quality"`
 	Latency   float64 `json:"latency"`
-	Bandwidth float64 `json:"bandwidth"`
+	// Bandwidth is the expected bandwidth in Mbps, left out if it is not known or not limited.
+	Bandwidth float64 `json:"bandwidth,omitempty"`
 	Uptime    float64 `json:"uptime"`
 }
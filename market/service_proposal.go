@@ -19,6 +19,7 @@ package market
 
 import (
 	"encoding/json"
+	"strings"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/mysteriumnetwork/node/p2p/compat"
@@ -56,6 +57,15 @@ type ServiceProposal struct {
 
 	// Quality represents the service quality.
 	Quality Quality `json:"quality"`
+
+	// BandwidthTiers represents service levels consumer can choose from, empty means a single unlimited level.
+	BandwidthTiers []BandwidthTier `json:"bandwidth_tiers,omitempty"`
+
+	// Attestation is the signed metadata of the provider node, nil if provider does not attest its node.
+	Attestation *NodeAttestation `json:"attestation,omitempty"`
+
+	// Price is the price announced by the provider, nil if provider charges the network price.
+	Price *Price `json:"price,omitempty"`
 }
 
 // NewProposalOpts optional params for the new proposal creation.
@@ -64,6 +74,7 @@ type NewProposalOpts struct {
 	AccessPolicies []AccessPolicy
 	Contacts       []Contact
 	Quality        *Quality
+	BandwidthTiers []BandwidthTier
 }
 
 // NewProposal creates a new proposal.
@@ -89,6 +100,9 @@ func NewProposal(providerID, serviceType string, opts NewProposalOpts) ServicePr
 	if q := opts.Quality; q != nil {
 		p.Quality = *q
 	}
+	if bt := opts.BandwidthTiers; len(bt) > 0 {
+		p.BandwidthTiers = bt
+	}
 	return p
 }
 
@@ -123,6 +137,9 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 		Contacts       *json.RawMessage `json:"contacts"`
 		AccessPolicies *[]AccessPolicy  `json:"access_policies,omitempty"`
 		Quality        Quality          `json:"quality"`
+		BandwidthTiers []BandwidthTier  `json:"bandwidth_tiers,omitempty"`
+		Attestation    *NodeAttestation `json:"attestation,omitempty"`
+		Price          *Price           `json:"price,omitempty"`
 	}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
 		return err
@@ -139,10 +156,58 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 	proposal.Contacts = unserializeContacts(jsonData.Contacts)
 	proposal.AccessPolicies = jsonData.AccessPolicies
 	proposal.Quality = jsonData.Quality
+	proposal.BandwidthTiers = jsonData.BandwidthTiers
+	proposal.Attestation = jsonData.Attestation
+	proposal.Price = jsonData.Price
 
 	return nil
 }
 
+// BandwidthTier returns the advertised bandwidth tier with the given name.
+func (proposal *ServiceProposal) BandwidthTier(name string) (BandwidthTier, bool) {
+	for _, tier := range proposal.BandwidthTiers {
+		if tier.Name == name {
+			return tier, true
+		}
+	}
+	return BandwidthTier{}, false
+}
+
+// ConsumerCountries returns the countries consumers may connect from, empty if consumers from all countries are allowed.
+func (proposal *ServiceProposal) ConsumerCountries() []string {
+	if proposal.AccessPolicies == nil {
+		return nil
+	}
+
+	var countries []string
+	for _, policy := range *proposal.AccessPolicies {
+		if policy.ID != AccessPolicyConsumerCountry {
+			continue
+		}
+		for _, rule := range policy.Allow {
+			if rule.Type == AccessPolicyTypeCountry {
+				countries = append(countries, rule.Value)
+			}
+		}
+	}
+	return countries
+}
+
+// AllowsConsumerCountry returns true if consumers from the given country may connect to the service.
+func (proposal *ServiceProposal) AllowsConsumerCountry(country string) bool {
+	countries := proposal.ConsumerCountries()
+	if len(countries) == 0 {
+		return true
+	}
+
+	for _, allowed := range countries {
+		if strings.EqualFold(allowed, country) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsSupported returns true if this service proposal can be used for connections by service consumer
 // can be used as a filter to filter out all proposals which are unsupported for any reason
 func (proposal *ServiceProposal) IsSupported() bool {
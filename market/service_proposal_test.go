@@ -150,3 +150,52 @@ func Test_ServiceProposal_UnserializeAccessPolicy(t *testing.T) {
 	assert.Equal(t, expected, actual)
 	assert.True(t, actual.IsSupported())
 }
+
+func Test_ServiceProposal_UnserializeBandwidthTiers(t *testing.T) {
+	jsonData := []byte(`{
+		"id": 1,
+		"format": "service-proposal/v3",
+		"service_type": "mock_service",
+		"provider_id": "node",
+		"bandwidth_tiers": [
+			{"name": "basic", "bandwidth": 1250, "price_percent": 50},
+			{"name": "premium", "bandwidth": 0, "price_percent": 150}
+		]
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+
+	tier, ok := actual.BandwidthTier("basic")
+	assert.True(t, ok)
+	assert.Equal(t, BandwidthTier{Name: "basic", Bandwidth: 1250, PricePercent: 50}, tier)
+
+	_, ok = actual.BandwidthTier("unknown")
+	assert.False(t, ok)
+}
+
+func Test_ServiceProposal_UnserializeConsumerCountries(t *testing.T) {
+	jsonData := []byte(`{
+		"id": 1,
+		"format": "service-proposal/v3",
+		"service_type": "mock_service",
+		"provider_id": "node",
+		"access_policies": [
+			{"id": "mysterium", "source": "https://trust-oracle.mysterium.network/api/v1/access-policies/mysterium"},
+			{"id": "consumer-country", "source": "", "allow": [{"type": "country", "value": "DE"}, {"type": "country", "value": "NL"}]}
+		]
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"DE", "NL"}, actual.ConsumerCountries())
+	assert.True(t, actual.AllowsConsumerCountry("nl"))
+	assert.False(t, actual.AllowsConsumerCountry("US"))
+
+	unrestricted := NewProposal("node", "mock_service", NewProposalOpts{})
+	assert.Empty(t, unrestricted.ConsumerCountries())
+	assert.True(t, unrestricted.AllowsConsumerCountry("US"))
+}
@@ -50,6 +50,8 @@ type ChainDefinition struct {
 	MystAddress        string
 	EtherClientRPC     []string
 	KnownHermeses      []string
+	// Backend names the chain backend payments use, EVM compatible backend is used when empty.
+	Backend string
 }
 
 // Payments defines payments configuration
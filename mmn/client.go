@@ -63,3 +63,16 @@ func (m *client) RegisterNode(info *NodeInformationDto) error {
 
 	return m.httpClient.DoRequest(req)
 }
+
+// SendNodeStatus does an HTTP call to MMN and updates the status of the provider
+func (m *client) SendNodeStatus(status *NodeStatusDto) error {
+	log.Debug().Msgf("Reporting provider status to MMN: %s", status.Identity)
+
+	id := identity.FromAddress(status.Identity)
+	req, err := requests.NewSignedPostRequest(m.mmnAddress, "node/status", status, m.signer(id))
+	if err != nil {
+		return err
+	}
+
+	return m.httpClient.DoRequest(req)
+}
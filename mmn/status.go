@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mmn
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+// ServiceStatusDto contains the status of a provider service to be sent to MMN
+type ServiceStatusDto struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// NodeStatusDto contains provider status to be sent to MMN
+type NodeStatusDto struct {
+	Identity       string             `json:"identity"`
+	APIKey         string             `json:"api_key"`
+	Services       []ServiceStatusDto `json:"services"`
+	ActiveSessions int                `json:"active_sessions"`
+	Earnings       *big.Int           `json:"earnings"`
+	EarningsTotal  *big.Int           `json:"earnings_total"`
+	NATType        string             `json:"nat_type"`
+	NodeVersion    string             `json:"node_version"`
+}
+
+type stateProvider interface {
+	GetState() stateEvent.State
+}
+
+type natTypeProvider interface {
+	NATType() nat.NATType
+}
+
+type statusSender interface {
+	SendNodeStatus(status *NodeStatusDto) error
+}
+
+// StatusReporter periodically pushes the status of the provider to MMN.
+type StatusReporter struct {
+	sender   statusSender
+	state    stateProvider
+	natType  natTypeProvider
+	apiKey   string
+	interval time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewStatusReporter creates new instance of the provider status reporter.
+func NewStatusReporter(sender statusSender, state stateProvider, natType natTypeProvider, apiKey string, interval time.Duration) *StatusReporter {
+	return &StatusReporter{
+		sender:   sender,
+		state:    state,
+		natType:  natType,
+		apiKey:   apiKey,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start starts pushing the status until the reporter is stopped.
+func (r *StatusReporter) Start() {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.report()
+			}
+		}
+	}()
+}
+
+// Stop stops pushing the status.
+func (r *StatusReporter) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+func (r *StatusReporter) report() {
+	for _, status := range r.statuses() {
+		if err := r.sender.SendNodeStatus(status); err != nil {
+			log.Warn().Err(err).Msgf("Failed to report provider %s status to MMN", status.Identity)
+		}
+	}
+}
+
+// statuses builds the status of every provider identity which has services running.
+func (r *StatusReporter) statuses() []*NodeStatusDto {
+	state := r.state.GetState()
+
+	var natType string
+	if r.natType != nil {
+		natType = string(r.natType.NATType())
+	}
+
+	var statuses []*NodeStatusDto
+	byProvider := make(map[string]*NodeStatusDto)
+	for _, service := range state.Services {
+		status, ok := byProvider[service.ProviderID]
+		if !ok {
+			status = &NodeStatusDto{
+				Identity:      service.ProviderID,
+				APIKey:        r.apiKey,
+				Services:      []ServiceStatusDto{},
+				Earnings:      new(big.Int),
+				EarningsTotal: new(big.Int),
+				NATType:       natType,
+				NodeVersion:   metadata.VersionAsString(),
+			}
+			byProvider[service.ProviderID] = status
+			statuses = append(statuses, status)
+		}
+		status.Services = append(status.Services, ServiceStatusDto{
+			ID:     service.ID,
+			Type:   service.Type,
+			Status: service.Status,
+		})
+	}
+
+	for _, session := range state.Sessions {
+		if status, ok := byProvider[session.ProviderID.Address]; ok {
+			status.ActiveSessions++
+		}
+	}
+
+	for _, id := range state.Identities {
+		status, ok := byProvider[id.Address]
+		if !ok {
+			continue
+		}
+		if id.Earnings != nil {
+			status.Earnings = id.Earnings
+		}
+		if id.EarningsTotal != nil {
+			status.EarningsTotal = id.EarningsTotal
+		}
+	}
+
+	return statuses
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mmn
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+func TestStatusReporter_report(t *testing.T) {
+	state := &mockStateProvider{state: stateEvent.State{
+		Services: []contract.ServiceInfoDTO{
+			{ID: "1", ProviderID: "0x1", Type: "wireguard", Status: "Running"},
+			{ID: "2", ProviderID: "0x1", Type: "scraping", Status: "Starting"},
+		},
+		Sessions: []session.History{
+			{ProviderID: identity.FromAddress("0x1")},
+			{ProviderID: identity.FromAddress("0x1")},
+			{ProviderID: identity.FromAddress("0x2")},
+		},
+		Identities: []stateEvent.Identity{
+			{Address: "0x1", Earnings: big.NewInt(10), EarningsTotal: big.NewInt(100)},
+			{Address: "0x2", Earnings: big.NewInt(20)},
+		},
+	}}
+	sender := &mockStatusSender{}
+	reporter := NewStatusReporter(sender, state, &mockNATTypeProvider{natType: nat.NATTypeFullCone}, "key", 0)
+
+	reporter.report()
+
+	assert.Equal(t, []*NodeStatusDto{
+		{
+			Identity: "0x1",
+			APIKey:   "key",
+			Services: []ServiceStatusDto{
+				{ID: "1", Type: "wireguard", Status: "Running"},
+				{ID: "2", Type: "scraping", Status: "Starting"},
+			},
+			ActiveSessions: 2,
+			Earnings:       big.NewInt(10),
+			EarningsTotal:  big.NewInt(100),
+			NATType:        string(nat.NATTypeFullCone),
+			NodeVersion:    metadata.VersionAsString(),
+		},
+	}, sender.sent)
+}
+
+type mockStateProvider struct {
+	state stateEvent.State
+}
+
+func (m *mockStateProvider) GetState() stateEvent.State {
+	return m.state
+}
+
+type mockNATTypeProvider struct {
+	natType nat.NATType
+}
+
+func (m *mockNATTypeProvider) NATType() nat.NATType {
+	return m.natType
+}
+
+type mockStatusSender struct {
+	sent []*NodeStatusDto
+}
+
+func (m *mockStatusSender) SendNodeStatus(status *NodeStatusDto) error {
+	m.sent = append(m.sent, status)
+	return nil
+}
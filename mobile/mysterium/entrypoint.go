@@ -32,6 +32,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/cmd"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/blacklist"
 	"github.com/mysteriumnetwork/node/consumer/entertainment"
 	"github.com/mysteriumnetwork/node/consumer/migration"
 	"github.com/mysteriumnetwork/node/core/connection"
@@ -42,6 +43,7 @@ import (
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/core/state"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/feedback"
 	"github.com/mysteriumnetwork/node/identity"
@@ -91,6 +93,7 @@ type MobileNode struct {
 	residentCountry           *identity.ResidentCountry
 	filterPresetStorage       *proposal.FilterPresetStorage
 	hermesMigrator            *migration.HermesMigrator
+	providerBlacklist         *blacklist.Blacklist
 }
 
 // MobileNodeOptions contains common mobile node options.
@@ -242,8 +245,9 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 			Runtime:  currentDir,
 		},
 
-		TequilapiEnabled:        false,
-		SwarmDialerDNSHeadstart: time.Millisecond * 1500,
+		TequilapiEnabled:              false,
+		SwarmDialerDNSHeadstart:       time.Millisecond * 1500,
+		StorageSlowOperationThreshold: boltdb.DefaultSlowOperationThreshold,
 		Keystore: node.OptionsKeystore{
 			UseLightweight: true,
 		},
@@ -281,6 +285,8 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		Payments: node.OptionsPayments{
 			MaxAllowedPaymentPercentile:    1500,
 			BCTimeout:                      time.Second * 30,
+			BCCacheTTL:                     time.Minute * 10,
+			BCChannelCacheTTL:              time.Second * 15,
 			SettlementTimeout:              time.Hour * 2,
 			HermesStatusRecheckInterval:    time.Hour * 2,
 			BalanceFastPollInterval:        time.Second * 30,
@@ -288,6 +294,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 			BalanceLongPollInterval:        time.Hour * 1,
 			RegistryTransactorPollInterval: time.Second * 20,
 			RegistryTransactorPollTimeout:  time.Minute * 20,
+			RegistryStuckTimeout:           time.Minute * 10,
 		},
 		Chains: node.OptionsChains{
 			Chain1: metadata.ChainDefinition{
@@ -311,6 +318,13 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		SSE: node.OptionsSSE{
 			Enabled: true,
 		},
+		Blacklist: node.OptionsBlacklist{
+			Threshold: 3,
+			HalfLife:  30 * time.Minute,
+		},
+		LeakTest: node.OptionsLeakTest{
+			Enabled: true,
+		},
 	}
 
 	err = di.Bootstrap(nodeOptions)
@@ -336,6 +350,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		identityRegistry:          di.IdentityRegistry,
 		consumerBalanceTracker:    di.ConsumerBalanceTracker,
 		identityChannelCalculator: di.AddressProvider,
+		providerBlacklist:         di.ProviderBlacklist,
 		proposalsManager: newProposalsManager(
 			di.ProposalRepository,
 			di.FilterPresetStorage,
@@ -540,7 +555,7 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 		ExcludeUnsupported:      true,
 	}
 
-	proposalLookup := connection.FilteredProposals(f, req.SortBy, mb.proposalsManager.repository)
+	proposalLookup := connection.FilteredProposals(f, req.SortBy, mb.proposalsManager.repository, mb.providerBlacklist)
 
 	qualityEvent := quality.ConnectionEvent{
 		ServiceType: req.ServiceType,
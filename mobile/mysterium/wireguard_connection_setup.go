@@ -261,6 +261,12 @@ func (w *wireguardDeviceImpl) Stats() (wgcfg.Stats, error) {
 }
 
 func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey string, config wireguard.ServiceConfig) error {
+	// All traffic through this peer (unfortunately 0.0.0.0/0 didn't work as it was treated as ipv6)
+	allowedIPs := []string{"0.0.0.0/1", "128.0.0.0/1"}
+	if len(config.Routes) > 0 {
+		allowedIPs = config.AllowedIPs()
+	}
+
 	deviceConfig := wgcfg.DeviceConfig{
 		PrivateKey: privateKey,
 		ListenPort: config.LocalPort,
@@ -268,8 +274,7 @@ func (w *wireguardDeviceImpl) applyConfig(devApi *device.Device, privateKey stri
 			Endpoint:               &config.Provider.Endpoint,
 			PublicKey:              config.Provider.PublicKey,
 			KeepAlivePeriodSeconds: 18,
-			// All traffic through this peer (unfortunately 0.0.0.0/0 didn't work as it was treated as ipv6)
-			AllowedIPs: []string{"0.0.0.0/1", "128.0.0.0/1"},
+			AllowedIPs:             allowedIPs,
 		},
 		ReplacePeers: true,
 	}
@@ -297,11 +302,19 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 		wgTunnSetup.AddDNS(dnsIP)
 	}
 
-	// Route all traffic through tunnel
-	wgTunnSetup.AddRoute("0.0.0.0", 1)
-	wgTunnSetup.AddRoute("128.0.0.0", 1)
-	wgTunnSetup.AddRoute("::", 1)
-	wgTunnSetup.AddRoute("8000::", 1)
+	if len(config.Routes) > 0 {
+		// Route only the subnets advertised by the provider through tunnel
+		for _, route := range config.Routes {
+			routePrefixLen, _ := route.Mask.Size()
+			wgTunnSetup.AddRoute(route.IP.String(), routePrefixLen)
+		}
+	} else {
+		// Route all traffic through tunnel
+		wgTunnSetup.AddRoute("0.0.0.0", 1)
+		wgTunnSetup.AddRoute("128.0.0.0", 1)
+		wgTunnSetup.AddRoute("::", 1)
+		wgTunnSetup.AddRoute("8000::", 1)
+	}
 
 	fd, err := wgTunnSetup.Establish()
 	if err != nil {
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// ErrAmountOverflow indicates that the amount does not fit into the requested type.
+var ErrAmountOverflow = errors.New("amount overflows uint64")
+
+// ErrNegativeAmount indicates that the amount is negative where it's not allowed to be.
+var ErrNegativeAmount = errors.New("amount is negative")
+
+// ToUint64 converts the given amount to uint64.
+// Unlike big.Int.Uint64, values that do not fit are saturated instead of wrapped around and an error is returned.
+func ToUint64(amount *big.Int) (uint64, error) {
+	if amount == nil {
+		return 0, nil
+	}
+	if amount.Sign() < 0 {
+		return 0, ErrNegativeAmount
+	}
+	if !amount.IsUint64() {
+		return math.MaxUint64, ErrAmountOverflow
+	}
+	return amount.Uint64(), nil
+}
+
+// SaturatedUint64 converts the given amount to uint64, saturating values that do not fit.
+func SaturatedUint64(amount *big.Int) uint64 {
+	v, _ := ToUint64(amount)
+	return v
+}
+
+// AddUint64 adds two uint64 values, saturating the result and returning an error on overflow.
+func AddUint64(a, b uint64) (uint64, error) {
+	if a > math.MaxUint64-b {
+		return math.MaxUint64, ErrAmountOverflow
+	}
+	return a + b, nil
+}
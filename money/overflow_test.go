@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package money
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToUint64(t *testing.T) {
+	tooBig := new(big.Int).Mul(MystSize, big.NewInt(100))
+
+	for _, test := range []struct {
+		name    string
+		amount  *big.Int
+		want    uint64
+		wantErr error
+	}{
+		{name: "nil", amount: nil, want: 0},
+		{name: "fits", amount: big.NewInt(42), want: 42},
+		{name: "max", amount: new(big.Int).SetUint64(math.MaxUint64), want: math.MaxUint64},
+		{name: "saturates", amount: tooBig, want: math.MaxUint64, wantErr: ErrAmountOverflow},
+		{name: "negative", amount: big.NewInt(-1), want: 0, wantErr: ErrNegativeAmount},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ToUint64(test.amount)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.wantErr, err)
+		})
+	}
+}
+
+func TestAddUint64(t *testing.T) {
+	v, err := AddUint64(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), v)
+
+	v, err = AddUint64(math.MaxUint64, 1)
+	assert.Equal(t, ErrAmountOverflow, err)
+	assert.Equal(t, uint64(math.MaxUint64), v)
+}
@@ -41,6 +41,10 @@ const (
 	concurrentRequestTimeout = 1 * time.Second
 )
 
+func init() {
+	eventbus.RegisterSchema(AppTopicNATTypeDetected, 1, nat.NATType(""))
+}
+
 // ErrInappropriateState error is returned by gatedNATProber when connection
 // is active
 var ErrInappropriateState = errors.New("NAT probing is impossible at this connection state")
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package behavior
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+// DefaultNATTypeMaxAge is the period after which detected NAT type is considered stale.
+const DefaultNATTypeMaxAge = 30 * time.Minute
+
+// NATTypeTracker remembers the most recently detected NAT type.
+type NATTypeTracker struct {
+	maxAge time.Duration
+
+	mu         sync.RWMutex
+	natType    nat.NATType
+	detectedAt time.Time
+}
+
+// NewNATTypeTracker creates new NAT type tracker. Detected NAT type is
+// forgotten after maxAge since network conditions may change.
+func NewNATTypeTracker(maxAge time.Duration) *NATTypeTracker {
+	return &NATTypeTracker{maxAge: maxAge}
+}
+
+// Subscribe subscribes tracker to NAT type detection events.
+func (t *NATTypeTracker) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(AppTopicNATTypeDetected, t.handleNATTypeDetected)
+}
+
+func (t *NATTypeTracker) handleNATTypeDetected(natType nat.NATType) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.natType = natType
+	t.detectedAt = time.Now()
+}
+
+// NATType returns last detected NAT type or empty value if it is unknown or stale.
+func (t *NATTypeTracker) NATType() nat.NATType {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.detectedAt.IsZero() || time.Since(t.detectedAt) > t.maxAge {
+		return ""
+	}
+	return t.natType
+}
@@ -17,6 +17,10 @@
 
 package event
 
+import (
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
 // AppTopicTraversal the topic that traversal events are published on
 const AppTopicTraversal = "Traversal"
 
@@ -37,3 +41,7 @@ type Event struct {
 	Successful bool   `json:"successful"`
 	Error      error  `json:"error,omitempty"`
 }
+
+func init() {
+	eventbus.RegisterSchema(AppTopicTraversal, 1, Event{})
+}
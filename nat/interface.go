@@ -35,4 +35,8 @@ type Options struct {
 	EnableDNSRedirect bool
 	DNSIP             net.IP
 	DNSPort           int
+	// AllowedNetworks are the parts of the protected networks which the session is still allowed to reach.
+	AllowedNetworks []net.IPNet
+	// Routes restrict the forwarded session traffic to the given destinations, empty allows all of them.
+	Routes []net.IPNet
 }
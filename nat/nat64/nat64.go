@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat64
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// wellKnownName is resolved to discover the NAT64 prefix as described in RFC 7050.
+const wellKnownName = "ipv4only.arpa"
+
+// wellKnownIPs are the only IPv4 addresses ipv4only.arpa resolves to.
+var wellKnownIPs = []net.IP{
+	net.IPv4(192, 0, 0, 170),
+	net.IPv4(192, 0, 0, 171),
+}
+
+// ipv4Probe is a public IPv4 address used to check whether the host has an IPv4 route.
+// Nothing is sent to it, the kernel is only asked to select a source address.
+var ipv4Probe = &net.UDPAddr{IP: net.IPv4(1, 1, 1, 1), Port: 53}
+
+// ErrNoPrefix is returned when the network does not provide a NAT64 prefix.
+var ErrNoPrefix = errors.New("NAT64 prefix not found")
+
+type lookuper interface {
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+// DetectPrefix discovers the /96 NAT64 prefix announced by the DNS64 resolver
+// by looking up AAAA records of ipv4only.arpa.
+func DetectPrefix(ctx context.Context) (net.IP, error) {
+	return detectPrefix(ctx, net.DefaultResolver)
+}
+
+func detectPrefix(ctx context.Context, resolver lookuper) (net.IP, error) {
+	ips, err := resolver.LookupIP(ctx, "ip6", wellKnownName)
+	if err != nil {
+		return nil, ErrNoPrefix
+	}
+
+	for _, ip := range ips {
+		if ip.To4() != nil || len(ip) != net.IPv6len {
+			continue
+		}
+		for _, known := range wellKnownIPs {
+			if net.IP(ip[12:]).Equal(known) {
+				prefix := make(net.IP, net.IPv6len)
+				copy(prefix, ip[:12])
+				return prefix, nil
+			}
+		}
+	}
+
+	return nil, ErrNoPrefix
+}
+
+// Synthesize embeds IPv4 address into the /96 NAT64 prefix.
+func Synthesize(prefix, ip net.IP) net.IP {
+	ip4 := ip.To4()
+	if ip4 == nil || len(prefix) != net.IPv6len {
+		return nil
+	}
+
+	res := make(net.IP, net.IPv6len)
+	copy(res, prefix[:12])
+	copy(res[12:], ip4)
+	return res
+}
+
+// HasIPv4Route reports whether the host is able to send IPv4 traffic.
+// Hosts behind 464XLAT have a local CLAT IPv4 address and report true.
+func HasIPv4Route() bool {
+	conn, err := net.DialUDP("udp4", nil, ipv4Probe)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Translator converts IPv4 peer addresses into the addresses reachable from the host.
+type Translator struct {
+	detectTimeout time.Duration
+	hasIPv4Route  func() bool
+	detectPrefix  func(ctx context.Context) (net.IP, error)
+
+	mu     sync.Mutex
+	prefix net.IP
+}
+
+// NewTranslator returns a new translator which detects NAT64 prefix lazily when
+// the host has no IPv4 route.
+func NewTranslator() *Translator {
+	return &Translator{
+		detectTimeout: 3 * time.Second,
+		hasIPv4Route:  HasIPv4Route,
+		detectPrefix:  DetectPrefix,
+	}
+}
+
+// Translate returns NAT64 synthesized address of the given IPv4 address if the host
+// is on IPv6-only network. Otherwise the address is returned unchanged.
+func (t *Translator) Translate(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil || parsed.To4() == nil {
+		return ip
+	}
+
+	prefix := t.nat64Prefix()
+	if prefix == nil {
+		return ip
+	}
+
+	return Synthesize(prefix, parsed).String()
+}
+
+func (t *Translator) nat64Prefix() net.IP {
+	// Network may change between calls (e.g. mobile switching from Wi-Fi to cellular).
+	if t.hasIPv4Route() {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.prefix != nil {
+		return t.prefix
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.detectTimeout)
+	defer cancel()
+
+	prefix, err := t.detectPrefix(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Host has no IPv4 route and no NAT64 prefix was detected")
+		return nil
+	}
+
+	log.Info().Msgf("Detected IPv6-only network with NAT64 prefix %s/96", prefix)
+	t.prefix = prefix
+	return t.prefix
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat64
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLookuper struct {
+	ips []net.IP
+	err error
+}
+
+func (m *mockLookuper) LookupIP(_ context.Context, network, host string) ([]net.IP, error) {
+	if network != "ip6" || host != wellKnownName {
+		return nil, errors.New("unexpected lookup")
+	}
+	return m.ips, m.err
+}
+
+func TestDetectPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		ips     []net.IP
+		err     error
+		want    net.IP
+		wantErr error
+	}{
+		{
+			name: "well-known prefix",
+			ips:  []net.IP{net.ParseIP("64:ff9b::c000:aa"), net.ParseIP("64:ff9b::c000:ab")},
+			want: net.ParseIP("64:ff9b::"),
+		},
+		{
+			name: "network specific prefix",
+			ips:  []net.IP{net.ParseIP("2001:db8:64::c000:ab")},
+			want: net.ParseIP("2001:db8:64::"),
+		},
+		{
+			name:    "no AAAA records",
+			err:     errors.New("no such host"),
+			wantErr: ErrNoPrefix,
+		},
+		{
+			name:    "native IPv6 address",
+			ips:     []net.IP{net.ParseIP("2001:db8::1")},
+			wantErr: ErrNoPrefix,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, err := detectPrefix(context.Background(), &mockLookuper{ips: tt.ips, err: tt.err})
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.True(t, tt.want.Equal(prefix), "got prefix %s", prefix)
+		})
+	}
+}
+
+func TestSynthesize(t *testing.T) {
+	prefix := net.ParseIP("64:ff9b::")
+
+	assert.Equal(t, "64:ff9b::102:304", Synthesize(prefix, net.ParseIP("1.2.3.4")).String())
+	assert.Nil(t, Synthesize(prefix, net.ParseIP("2001:db8::1")))
+	assert.Nil(t, Synthesize(nil, net.ParseIP("1.2.3.4")))
+}
+
+func TestTranslator_Translate(t *testing.T) {
+	hasIPv4 := true
+	detections := 0
+	translator := &Translator{
+		hasIPv4Route: func() bool { return hasIPv4 },
+		detectPrefix: func(ctx context.Context) (net.IP, error) {
+			detections++
+			return net.ParseIP("64:ff9b::"), nil
+		},
+	}
+
+	assert.Equal(t, "1.2.3.4", translator.Translate("1.2.3.4"))
+	assert.Equal(t, 0, detections)
+
+	hasIPv4 = false
+	assert.Equal(t, "64:ff9b::102:304", translator.Translate("1.2.3.4"))
+	assert.Equal(t, "64:ff9b::506:708", translator.Translate("5.6.7.8"))
+	assert.Equal(t, "2001:db8::1", translator.Translate("2001:db8::1"))
+	require.Equal(t, 1, detections)
+}
+
+func TestTranslator_TranslateWithoutNAT64(t *testing.T) {
+	translator := &Translator{
+		hasIPv4Route: func() bool { return false },
+		detectPrefix: func(ctx context.Context) (net.IP, error) { return nil, ErrNoPrefix },
+	}
+
+	assert.Equal(t, "1.2.3.4", translator.Translate("1.2.3.4"))
+}
@@ -25,15 +25,26 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func protectedNetworks() (nets []*net.IPNet) {
-	cfg := config.GetString(config.FlagFirewallProtectedNetworks)
+func protectedNetworks() []*net.IPNet {
+	return parseNetworks(config.GetString(config.FlagFirewallProtectedNetworks))
+}
+
+// DefaultAllowedNetworks returns the parts of the protected networks which every session is allowed to reach.
+func DefaultAllowedNetworks() (nets []net.IPNet) {
+	for _, ipNet := range parseNetworks(config.GetString(config.FlagFirewallAllowedNetworks)) {
+		nets = append(nets, *ipNet)
+	}
+	return nets
+}
+
+func parseNetworks(cfg string) (nets []*net.IPNet) {
 	if cfg == "" {
 		return nil
 	}
 	for _, s := range strings.Split(cfg, ",") {
 		_, ipNet, err := net.ParseCIDR(s)
 		if err != nil {
-			log.Error().Err(err).Msg("Could not parse protected network string")
+			log.Error().Err(err).Msgf("Could not parse network string %q", s)
 			continue
 		}
 		nets = append(nets, ipNet)
@@ -130,6 +130,10 @@ func (ics *serviceICS) enableRemoteAccessService() error {
 
 // Setup enables internet connection sharing for the local interface.
 func (ics *serviceICS) Setup(opts Options) (rules []interface{}, err error) {
+	if len(opts.Routes) > 0 {
+		return nil, errors.New("internet connection sharing is not able to restrict forwarding to the advertised routes")
+	}
+
 	ics.mu.Lock()
 	defer ics.mu.Unlock()
 
@@ -193,6 +193,13 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 		"--source", vpnNetwork, "--jump", chainMyst, "--table", "nat")
 	rules = append(rules, rule)
 
+	for _, ipNet := range opts.AllowedNetworks {
+		// Allowed part of the protected networks rule, DNS redirect rules are inserted above it
+		rule := iptables.InsertAt(chainMyst, 1).RuleSpec(
+			"--source", vpnNetwork, "--destination", ipNet.String(), "--jump", "RETURN", "--table", "nat")
+		rules = append(rules, rule)
+	}
+
 	if opts.EnableDNSRedirect {
 		// DNS port redirect rule (udp)
 		rule := iptables.InsertAt(chainMyst, 1).RuleSpec(
@@ -213,15 +220,32 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 		rules = append(rules, rule)
 	}
 
-	// NAT forwarding rule
-	rule = iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "!", "--destination", vpnNetwork,
-		"--jump", "SNAT", "--to", opts.ProviderExtIP.String(),
-		"--table", "nat")
-	rules = append(rules, rule)
+	if len(opts.Routes) == 0 {
+		// NAT forwarding rule
+		rule = iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "!", "--destination", vpnNetwork,
+			"--jump", "SNAT", "--to", opts.ProviderExtIP.String(),
+			"--table", "nat")
+		rules = append(rules, rule)
 
-	// ACCEPT forwarding rules
-	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "ACCEPT"))
+		// ACCEPT forwarding rules
+		rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "ACCEPT"))
+		rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork, "--jump", "ACCEPT"))
+		return rules
+	}
+
+	for _, route := range opts.Routes {
+		// NAT forwarding rule of the advertised route
+		rule = iptables.AppendTo(chainPostRouting).RuleSpec("--source", vpnNetwork, "--destination", route.String(),
+			"--jump", "SNAT", "--to", opts.ProviderExtIP.String(),
+			"--table", "nat")
+		rules = append(rules, rule)
+
+		// ACCEPT forwarding rule of the advertised route
+		rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--destination", route.String(), "--jump", "ACCEPT"))
+	}
 	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--destination", vpnNetwork, "--jump", "ACCEPT"))
+	// Traffic outside of the advertised routes is not forwarded
+	rules = append(rules, iptables.AppendTo(chainForward).RuleSpec("--source", vpnNetwork, "--jump", "DROP"))
 
 	return rules
 }
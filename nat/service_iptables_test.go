@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/config"
+)
+
+func Test_makeIPTablesRules_AllowsNetworksPerSession(t *testing.T) {
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:        net.IPNet{IP: net.ParseIP("10.182.0.0"), Mask: net.CIDRMask(24, 32)},
+		ProviderExtIP:     net.ParseIP("1.2.3.4"),
+		EnableDNSRedirect: true,
+		DNSIP:             net.ParseIP("10.182.0.1"),
+		DNSPort:           5353,
+		AllowedNetworks:   []net.IPNet{{IP: net.ParseIP("192.168.1.10").To4(), Mask: net.CIDRMask(32, 32)}},
+	})
+
+	var args [][]string
+	for _, rule := range rules {
+		args = append(args, rule.ApplyArgs())
+	}
+	// DNS redirect rules are inserted after the allow rule, so they are evaluated before it.
+	assert.Equal(t, []string{"-I", "MYST", "1", "--source", "10.182.0.0/24", "--destination", "192.168.1.10/32", "--jump", "RETURN", "--table", "nat"}, args[1])
+	assert.Contains(t, args[2], "REDIRECT")
+	assert.Contains(t, args[3], "REDIRECT")
+}
+
+func Test_makeIPTablesRules_NoAllowedNetworks(t *testing.T) {
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:    net.IPNet{IP: net.ParseIP("10.182.1.0"), Mask: net.CIDRMask(24, 32)},
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+	})
+
+	for _, rule := range rules {
+		assert.NotContains(t, rule.ApplyArgs(), "RETURN")
+	}
+}
+
+func Test_makeIPTablesRules_ForwardsOnlyRoutes(t *testing.T) {
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:    net.IPNet{IP: net.ParseIP("10.182.1.0"), Mask: net.CIDRMask(24, 32)},
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+		Routes:        []net.IPNet{{IP: net.ParseIP("203.0.113.0").To4(), Mask: net.CIDRMask(24, 32)}},
+	})
+
+	var args [][]string
+	for _, rule := range rules {
+		args = append(args, rule.ApplyArgs())
+	}
+	assert.Equal(t, [][]string{
+		{"-I", "PREROUTING", "1", "--source", "10.182.1.0/24", "--jump", "MYST", "--table", "nat"},
+		{"-A", "POSTROUTING", "--source", "10.182.1.0/24", "--destination", "203.0.113.0/24", "--jump", "SNAT", "--to", "1.2.3.4", "--table", "nat"},
+		{"-A", "FORWARD", "--source", "10.182.1.0/24", "--destination", "203.0.113.0/24", "--jump", "ACCEPT"},
+		{"-A", "FORWARD", "--destination", "10.182.1.0/24", "--jump", "ACCEPT"},
+		{"-A", "FORWARD", "--source", "10.182.1.0/24", "--jump", "DROP"},
+	}, args)
+}
+
+func Test_DefaultAllowedNetworks(t *testing.T) {
+	config.Current.SetUser(config.FlagFirewallAllowedNetworks.Name, "192.168.1.10/32,not-a-network")
+	defer config.Current.RemoveUser(config.FlagFirewallAllowedNetworks.Name)
+
+	assert.Equal(t, []net.IPNet{{IP: net.ParseIP("192.168.1.10").To4(), Mask: net.CIDRMask(32, 32)}}, DefaultAllowedNetworks())
+}
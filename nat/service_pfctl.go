@@ -104,6 +104,21 @@ func makePfctlRules(opts Options) (rules []string, err error) {
 		rules = append(rules, rule)
 	}
 
+	// Allowed part of the protected networks rule, first matching translation rule wins
+	if len(opts.AllowedNetworks) > 0 {
+		var targets []string
+		for _, network := range opts.AllowedNetworks {
+			targets = append(targets, network.String())
+		}
+		rule := fmt.Sprintf("nat on %s inet from %s to { %s } -> %s",
+			externalIface,
+			opts.VPNNetwork.String(),
+			strings.Join(targets, ", "),
+			opts.ProviderExtIP,
+		)
+		rules = append(rules, rule)
+	}
+
 	// Protect private networks rule
 	networks := protectedNetworks()
 	if len(networks) > 0 {
@@ -119,10 +134,19 @@ func makePfctlRules(opts Options) (rules []string, err error) {
 		rules = append(rules, rule)
 	}
 
-	// NAT forwarding rule
-	rule := fmt.Sprintf("nat on %s inet from %s to any -> %s",
+	// NAT forwarding rule, limited to the advertised routes if there are any
+	destination := "any"
+	if len(opts.Routes) > 0 {
+		var targets []string
+		for _, route := range opts.Routes {
+			targets = append(targets, route.String())
+		}
+		destination = fmt.Sprintf("{ %s }", strings.Join(targets, ", "))
+	}
+	rule := fmt.Sprintf("nat on %s inet from %s to %s -> %s",
 		externalIface,
 		opts.VPNNetwork.String(),
+		destination,
 		opts.ProviderExtIP,
 	)
 	rules = append(rules, rule)
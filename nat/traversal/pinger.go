@@ -29,11 +29,13 @@ import (
 
 	"github.com/rs/zerolog/log"
 	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/router"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 // StageName represents hole-punching stage of NAT traversal
@@ -140,7 +142,7 @@ func (p *Pinger) PingConsumerPeer(ctx context.Context, id string, remoteIP strin
 				continue
 			}
 
-			if err := ipv4.NewConn(res.conn).SetTTL(maxTTL); err != nil {
+			if err := setTTL(res.conn, maxTTL); err != nil {
 				res.conn.Close()
 				log.Warn().Err(res.err).Msg("Failed to set connection TTL")
 				continue
@@ -206,7 +208,7 @@ func (p *Pinger) PingProviderPeer(ctx context.Context, localIP, remoteIP string,
 				continue
 			}
 
-			if err := ipv4.NewConn(res.conn).SetTTL(maxTTL); err != nil {
+			if err := setTTL(res.conn, maxTTL); err != nil {
 				res.conn.Close()
 				log.Warn().Err(res.err).Msg("Failed to set connection TTL")
 				continue
@@ -319,7 +321,7 @@ func (p *Pinger) sendMsg(conn *net.UDPConn, msg string) {
 }
 
 func (p *Pinger) ping(ctx context.Context, conn *net.UDPConn, remoteAddr *net.UDPAddr, ttl int) error {
-	err := ipv4.NewConn(conn).SetTTL(ttl)
+	err := setTTL(conn, ttl)
 	if err != nil {
 		return fmt.Errorf("pinger setting ttl failed: %w", err)
 	}
@@ -342,6 +344,14 @@ func (p *Pinger) ping(ctx context.Context, conn *net.UDPConn, remoteAddr *net.UD
 	}
 }
 
+// setTTL sets TTL or hop limit depending on the address family of the connection.
+func setTTL(conn *net.UDPConn, ttl int) error {
+	if netutil.IsIPv6(conn.LocalAddr().(*net.UDPAddr).IP) {
+		return ipv6.NewConn(conn).SetHopLimit(ttl)
+	}
+	return ipv4.NewConn(conn).SetTTL(ttl)
+}
+
 func readFromConnWithContext(ctx context.Context, conn net.Conn, buf []byte) (n int, err error) {
 	readDone := make(chan struct{})
 	go func() {
@@ -448,7 +458,7 @@ func (p *Pinger) multiPingN(ctx context.Context, localIP, remoteIP string, local
 }
 
 func (p *Pinger) singlePing(ctx context.Context, localIP, remoteIP string, localPort, remotePort, ttl int) (*net.UDPConn, error) {
-	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP(localIP), Port: localPort})
+	conn, err := net.ListenUDP(netutil.UDPNetwork(net.ParseIP(remoteIP)), &net.UDPAddr{IP: net.ParseIP(localIP), Port: localPort})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get connection: %w", err)
 	}
@@ -483,7 +493,7 @@ func (p *Pinger) singlePing(ctx context.Context, localIP, remoteIP string, local
 	// need to dial same connection further
 	conn.Close()
 
-	newConn, err := net.DialUDP("udp4", laddr, raddr)
+	newConn, err := net.DialUDP(netutil.UDPNetwork(raddr.IP), laddr, raddr)
 	if err != nil {
 		return nil, err
 	}
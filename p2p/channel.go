@@ -31,9 +31,11 @@ import (
 	kcp "github.com/xtaci/kcp-go/v5"
 	"golang.org/x/crypto/nacl/box"
 
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/router"
 	"github.com/mysteriumnetwork/node/trace"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 var (
@@ -79,11 +81,17 @@ type Channel interface {
 	// Conn returns underlying channel's UDP connection.
 	Conn() *net.UDPConn
 
+	// PeerAddr returns current remote peer UDP address.
+	PeerAddr() *net.UDPAddr
+
 	// Close closes p2p communication channel.
 	Close() error
 
 	// Unique ID
 	ID() string
+
+	// Codec returns the codec of the messages negotiated with the peer.
+	Codec() dto.Codec
 }
 
 // HandlerFunc is channel request handler func signature.
@@ -155,6 +163,9 @@ type channel struct {
 	// peer identity authenticated by its signature in initial exchange
 	peerID identity.Identity
 
+	// codec encodes the messages in the encoding negotiated with the peer.
+	codec dto.Codec
+
 	// topicHandlers is similar to HTTP Server handlers and is responsible for handling peer requests.
 	topicHandlers map[string]HandlerFunc
 
@@ -233,6 +244,7 @@ func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey Publi
 		peer:             &peer,
 		localSessionAddr: localConn.LocalAddr().(*net.UDPAddr),
 		serviceConn:      nil,
+		codec:            dto.CodecFor(dto.EncodingProtobuf),
 		stop:             make(chan struct{}, 1),
 		sendQueue:        make(chan *transportMsg, 100),
 	}
@@ -244,6 +256,11 @@ func (c *channel) ID() string {
 	return fmt.Sprintf("%p", c)
 }
 
+// PeerAddr returns current remote peer UDP address.
+func (c *channel) PeerAddr() *net.UDPAddr {
+	return c.peer.addr()
+}
+
 func (c *channel) launchReadSendLoops() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -582,6 +599,21 @@ func (c *channel) setPeerID(id identity.Identity) {
 	c.peerID = id
 }
 
+func (c *channel) setEncoding(encoding dto.Encoding) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.codec = dto.CodecFor(encoding)
+}
+
+// Codec returns the codec of the messages negotiated with the peer.
+func (c *channel) Codec() dto.Codec {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.codec
+}
+
 func (c *channel) setUpnpPortsRelease(release func()) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -592,7 +624,8 @@ func (c *channel) setUpnpPortsRelease(release func()) {
 func reopenConn(conn *net.UDPConn) (*net.UDPConn, error) {
 	// conn first must be closed to prevent use of WriteTo with pre-connected connection error.
 	conn.Close()
-	conn, err := net.ListenUDP("udp4", conn.LocalAddr().(*net.UDPAddr))
+	laddr := conn.LocalAddr().(*net.UDPAddr)
+	conn, err := net.ListenUDP(netutil.UDPNetwork(laddr.IP), laddr)
 	if err != nil {
 		return nil, fmt.Errorf("could not listen UDP: %w", err)
 	}
@@ -29,7 +29,9 @@ import (
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/identity"
+	nattype "github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 const (
@@ -49,6 +51,36 @@ type natProviderPinger interface {
 	PingConsumerPeer(ctx context.Context, id string, ip string, localPorts, remotePorts []int, initialTTL int, n int) (conns []*net.UDPConn, err error)
 }
 
+type ipTranslator interface {
+	Translate(ip string) string
+}
+
+type natTypeProvider interface {
+	NATType() nattype.NATType
+}
+
+type featureToggle interface {
+	Enabled(name string) bool
+}
+
+// acceptsUnsolicited reports whether NAT of given type forwards packets
+// from any remote host to the already mapped port.
+func acceptsUnsolicited(natType string) bool {
+	switch nattype.NATType(natType) {
+	case nattype.NATTypeNone, nattype.NATTypeFullCone:
+		return true
+	default:
+		return false
+	}
+}
+
+func localNATType(natTypes natTypeProvider) string {
+	if natTypes == nil {
+		return ""
+	}
+	return string(natTypes.NATType())
+}
+
 func configExchangeSubject(providerID identity.Identity, serviceType string) string {
 	return fmt.Sprintf("%s.%s.p2p-config-exchange", providerID.Address, serviceType)
 }
@@ -73,6 +105,22 @@ func acquireLocalPorts(portPool port.ServicePortSupplier, n int) ([]int, error)
 	return res, nil
 }
 
+// dialPeer creates UDP connections for p2p channel and service from the first
+// two local ports to the corresponding peer ports.
+func dialPeer(localIP string, localPorts []int, peerIP string, peerPorts []int) (*net.UDPConn, *net.UDPConn, error) {
+	network := netutil.UDPNetwork(net.ParseIP(peerIP))
+	conn1, err := net.DialUDP(network, &net.UDPAddr{IP: net.ParseIP(localIP), Port: localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(peerIP), Port: peerPorts[0]})
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create UDP conn for p2p channel: %w", err)
+	}
+	conn2, err := net.DialUDP(network, &net.UDPAddr{IP: net.ParseIP(localIP), Port: localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(peerIP), Port: peerPorts[1]})
+	if err != nil {
+		conn1.Close()
+		return nil, nil, fmt.Errorf("could not create UDP conn for service: %w", err)
+	}
+	return conn1, conn2, nil
+}
+
 // packSignedMsg marshals, signs and returns ready to send bytes.
 func packSignedMsg(signer identity.SignerFactory, signerID identity.Identity, msg *pb.P2PConfigExchangeMsg) ([]byte, error) {
 	protoBytes, err := proto.Marshal(msg)
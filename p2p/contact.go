@@ -23,6 +23,7 @@ import (
 	"fmt"
 
 	"github.com/mysteriumnetwork/node/market"
+	nattype "github.com/mysteriumnetwork/node/nat"
 )
 
 // ErrContactNotFound represents that no p2p contact is found.
@@ -36,6 +37,46 @@ const (
 // ContactDefinition represents p2p contact which contains NATS broker addresses for connection.
 type ContactDefinition struct {
 	BrokerAddresses []string `json:"broker_addresses"`
+	// NATType is the provider NAT type detected at the time of announcement, empty if unknown.
+	NATType string `json:"nat_type,omitempty"`
+	// TraversalMethods lists NAT traversal methods provider is configured to use in the preferred order.
+	TraversalMethods []string `json:"traversal_methods,omitempty"`
+}
+
+// Reachable reports whether consumer behind NAT of given type is expected to establish
+// p2p connection with the provider announcing this contact. Unknown NAT type on either
+// side is considered reachable, so that only the definitely doomed attempts are avoided.
+func (c ContactDefinition) Reachable(consumerNATType nattype.NATType) bool {
+	if c.NATType == "" || consumerNATType == "" {
+		return true
+	}
+
+	if acceptsUnsolicited(c.NATType) || acceptsUnsolicited(string(consumerNATType)) {
+		return true
+	}
+
+	holePunching := len(c.TraversalMethods) == 0
+	for _, method := range c.TraversalMethods {
+		switch method {
+		case "manual", "upnp":
+			// Provider exposes the service ports by itself.
+			return true
+		case "holepunching":
+			holePunching = true
+		}
+	}
+
+	return holePunching && !hardNATPair(nattype.NATType(c.NATType), consumerNATType)
+}
+
+// hardNATPair reports whether hole punching is known to fail between given NAT types:
+// symmetric NAT allocates a new port per destination which is never allowed by
+// port restricted cone or another symmetric NAT.
+func hardNATPair(a, b nattype.NATType) bool {
+	hard := func(x, y nattype.NATType) bool {
+		return x == nattype.NATTypeSymmetric && (y == nattype.NATTypeSymmetric || y == nattype.NATTypePortRestrictedCone)
+	}
+	return hard(a, b) || hard(b, a)
 }
 
 // ParseContact tries to parse p2p contact from given contacts list.
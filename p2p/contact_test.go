@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/nat"
+)
+
+func TestContactDefinition_Reachable(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		contact  ContactDefinition
+		consumer nat.NATType
+		want     bool
+	}{
+		{"unknown provider", ContactDefinition{}, nat.NATTypeSymmetric, true},
+		{"unknown consumer", ContactDefinition{NATType: "symmetric"}, "", true},
+		{"public provider", ContactDefinition{NATType: "none"}, nat.NATTypeSymmetric, true},
+		{"fullcone consumer", ContactDefinition{NATType: "symmetric"}, nat.NATTypeFullCone, true},
+		{"symmetric pair", ContactDefinition{NATType: "symmetric"}, nat.NATTypeSymmetric, false},
+		{"symmetric and prcone", ContactDefinition{NATType: "prcone", TraversalMethods: []string{"holepunching"}}, nat.NATTypeSymmetric, false},
+		{"symmetric and rcone", ContactDefinition{NATType: "rcone", TraversalMethods: []string{"holepunching"}}, nat.NATTypeSymmetric, true},
+		{"port mapping", ContactDefinition{NATType: "symmetric", TraversalMethods: []string{"upnp", "holepunching"}}, nat.NATTypeSymmetric, true},
+		{"prcone pair", ContactDefinition{NATType: "prcone", TraversalMethods: []string{"holepunching"}}, nat.NATTypePortRestrictedCone, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.contact.Reachable(tc.consumer))
+		})
+	}
+}
@@ -30,11 +30,14 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/core/feature"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/nat64"
 	"github.com/mysteriumnetwork/node/nat/traversal"
 	"github.com/mysteriumnetwork/node/p2p/compat"
 	"github.com/mysteriumnetwork/node/pb"
@@ -52,15 +55,18 @@ type Dialer interface {
 }
 
 // NewDialer creates new p2p communication dialer which is used on consumer side.
-func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifierFactory identity.VerifierFactory, ipResolver ip.Resolver, portPool port.ServicePortSupplier, eventBus eventbus.EventBus) Dialer {
+func NewDialer(broker brokerConnector, signer identity.SignerFactory, verifierFactory identity.VerifierFactory, ipResolver ip.Resolver, portPool port.ServicePortSupplier, natTypes natTypeProvider, eventBus eventbus.EventBus, features featureToggle) Dialer {
 	return &dialer{
 		broker:          broker,
+		natTypes:        natTypes,
 		ipResolver:      ipResolver,
 		signer:          signer,
 		verifierFactory: verifierFactory,
 		portPool:        portPool,
 		consumerPinger:  traversal.NewPinger(traversal.DefaultPingConfig(), eventbus.New()),
 		eventBus:        eventBus,
+		nat64:           nat64.NewTranslator(),
+		features:        features,
 	}
 }
 
@@ -72,7 +78,10 @@ type dialer struct {
 	signer          identity.SignerFactory
 	verifierFactory identity.VerifierFactory
 	ipResolver      ip.Resolver
+	natTypes        natTypeProvider
 	eventBus        eventbus.EventBus
+	nat64           ipTranslator
+	features        featureToggle
 }
 
 // Dial exchanges p2p configuration via broker, performs NAT pinging if needed
@@ -109,13 +118,20 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 		return nil, fmt.Errorf("peer using compatibility version lower than 2: %d", config.compatibility)
 	}
 
+	if m.features.Enabled(feature.TraversalStrategies) {
+		if ip := m.nat64.Translate(config.peerPublicIP); ip != config.peerPublicIP {
+			log.Info().Msgf("Using NAT64 address %s for provider %s IP %s", ip, providerID.Address, config.peerPublicIP)
+			config.peerNAT64IP = ip
+		}
+	}
+
 	if serviceType != "openvpn" { // OpenVPN does this automatically, we don't need to perform it manually.
 		if err := router.ExcludeIP(net.ParseIP(config.peerIP())); err != nil {
 			return nil, fmt.Errorf("failed to exclude peer IP from default routes: %w", err)
 		}
 	}
 
-	if _, err := firewall.AllowIPAccess(config.peerPublicIP); err != nil {
+	if _, err := firewall.AllowIPAccess(config.peerFirewallIP()); err != nil {
 		return nil, fmt.Errorf("could not add peer IP firewall rule: %w", err)
 	}
 
@@ -135,6 +151,8 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	dial := m.dialPinger
 	if len(config.peerPorts) == requiredConnCount {
 		dial = m.dialDirect
+	} else if config.fullCone() {
+		dial = m.dialFullCone
 	}
 	conn1, conn2, err := dial(ctx, providerID, config)
 	if err != nil {
@@ -157,6 +175,7 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 	channel.setTracer(tracer)
 	channel.setServiceConn(conn2)
 	channel.setPeerID(providerID)
+	channel.setEncoding(dto.Negotiate(config.peerEncodings, dto.EncodingNames(dto.SupportedEncodings)))
 	channel.launchReadSendLoops()
 	config.tracer.EndStage(traceAck)
 
@@ -232,6 +251,9 @@ func (m *dialer) startConfigExchange(config *p2pConnectConfig, ctx context.Conte
 	config.peerPubKey = peerPubKey
 	config.peerPublicIP = peerConnConfig.PublicIP
 	config.peerPorts = int32ToIntSlice(peerConnConfig.Ports)
+	config.peerNATType = peerConnConfig.NatType
+	config.peerEncodings = peerConnConfig.Encodings
+	config.natType = localNATType(m.natTypes)
 	return config, nil
 }
 
@@ -243,6 +265,8 @@ func (m *dialer) ackConfigExchange(config *p2pConnectConfig, ctx context.Context
 		PublicIP:      config.publicIP,
 		Ports:         intToInt32Slice(config.publicPorts),
 		Compatibility: compat.Compatibility,
+		NatType:       config.natType,
+		Encodings:     dto.EncodingNames(dto.SupportedEncodings),
 	}
 	connConfigCiphertext, err := encryptConnConfigMsg(connConfig, config.privateKey, config.peerPubKey)
 	if err != nil {
@@ -294,15 +318,21 @@ func (m *dialer) dialDirect(ctx context.Context, providerID identity.Identity, c
 	defer config.tracer.EndStage(trace)
 
 	log.Debug().Msg("Skipping provider ping")
+	return m.dialPeer(config)
+}
 
-	ip := defaultInterfaceAddress()
-	conn1, err := net.DialUDP("udp4", &net.UDPAddr{IP: net.ParseIP(ip), Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
-	if err != nil {
-		return nil, nil, fmt.Errorf("could not create UDP conn for p2p channel: %w", err)
-	}
-	conn2, err := net.DialUDP("udp4", &net.UDPAddr{IP: net.ParseIP(ip), Port: config.localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
+func (m *dialer) dialFullCone(ctx context.Context, providerID identity.Identity, config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, error) {
+	trace := config.tracer.StartStage("Consumer P2P dial (full cone)")
+	defer config.tracer.EndStage(trace)
+
+	log.Debug().Msgf("Both peers are behind full cone NAT, skipping provider %s ping", providerID.Address)
+	return m.dialPeer(config)
+}
+
+func (m *dialer) dialPeer(config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, error) {
+	conn1, conn2, err := dialPeer(defaultInterfaceAddress(), config.localPorts, config.peerIP(), config.peerPorts)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not create UDP conn for service: %w", err)
+		return nil, nil, err
 	}
 
 	if err := router.ProtectUDPConn(conn1); err != nil {
@@ -313,14 +343,14 @@ func (m *dialer) dialDirect(ctx context.Context, providerID identity.Identity, c
 		return nil, nil, fmt.Errorf("failed to protect udp connection: %w", err)
 	}
 
-	return conn1, conn2, err
+	return conn1, conn2, nil
 }
 
 func (m *dialer) dialPinger(ctx context.Context, providerID identity.Identity, config *p2pConnectConfig) (*net.UDPConn, *net.UDPConn, error) {
 	trace := config.tracer.StartStage("Consumer P2P dial (pinger)")
 	defer config.tracer.EndStage(trace)
 
-	if _, err := firewall.AllowIPAccess(config.peerPublicIP); err != nil {
+	if _, err := firewall.AllowIPAccess(config.peerFirewallIP()); err != nil {
 		return nil, nil, fmt.Errorf("could not add peer IP firewall rule: %w", err)
 	}
 
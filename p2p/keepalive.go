@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// AppTopicPeerUnresponsive is published when the peer stops answering keep alive pings.
+const AppTopicPeerUnresponsive = "p2p-peer-unresponsive"
+
+func init() {
+	eventbus.RegisterSchema(AppTopicPeerUnresponsive, 1, AppEventPeerUnresponsive{})
+}
+
+// AppEventPeerUnresponsive is published when dead peer detection thresholds are reached.
+type AppEventPeerUnresponsive struct {
+	SessionID string
+	PeerID    identity.Identity
+	Stats     KeepAliveSnapshot
+}
+
+// rttSmoothing is the weight of the latest sample in the smoothed RTT, same as TCP uses.
+const rttSmoothing = 0.125
+
+// DeadPeerConfig holds thresholds after which the peer is considered unresponsive.
+type DeadPeerConfig struct {
+	// MaxConsecutiveLoss is the number of pings lost in a row, disabled if zero.
+	MaxConsecutiveLoss int
+	// MaxLossRatio is the ratio of pings lost within the window, disabled if zero.
+	MaxLossRatio float64
+	// Window is the number of latest pings the loss ratio is calculated over.
+	Window int
+}
+
+// Unresponsive reports whether the peer with given keep alive stats should be considered dead.
+func (c DeadPeerConfig) Unresponsive(stats KeepAliveSnapshot) bool {
+	if c.MaxConsecutiveLoss > 0 && stats.ConsecutiveLoss >= c.MaxConsecutiveLoss {
+		return true
+	}
+	// Loss ratio is meaningful only once the window is filled.
+	if c.MaxLossRatio > 0 && c.Window > 0 && stats.Samples >= c.Window && stats.LossRatio >= c.MaxLossRatio {
+		return true
+	}
+	return false
+}
+
+// KeepAliveSnapshot is a point in time view of keep alive stats.
+type KeepAliveSnapshot struct {
+	Sent            uint64
+	Lost            uint64
+	ConsecutiveLoss int
+	// Samples is the number of pings the loss ratio is calculated over.
+	Samples   int
+	LossRatio float64
+	LastRTT   time.Duration
+	// SmoothedRTT is an exponentially weighted moving average of RTT.
+	SmoothedRTT time.Duration
+}
+
+// KeepAliveStats tracks round trip time and loss of keep alive pings.
+type KeepAliveStats struct {
+	mu     sync.Mutex
+	window []bool
+	next   int
+	filled int
+
+	sent            uint64
+	lost            uint64
+	consecutiveLoss int
+	lastRTT         time.Duration
+	smoothedRTT     time.Duration
+}
+
+// NewKeepAliveStats returns keep alive stats calculating loss ratio over given number of latest pings.
+func NewKeepAliveStats(window int) *KeepAliveStats {
+	if window < 1 {
+		window = 1
+	}
+	return &KeepAliveStats{window: make([]bool, window)}
+}
+
+// RecordRTT records ping answered in given round trip time.
+func (s *KeepAliveStats) RecordRTT(rtt time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(false)
+	s.consecutiveLoss = 0
+	s.lastRTT = rtt
+	if s.smoothedRTT == 0 {
+		s.smoothedRTT = rtt
+	} else {
+		s.smoothedRTT += time.Duration(rttSmoothing * float64(rtt-s.smoothedRTT))
+	}
+}
+
+// RecordLoss records ping which was not answered.
+func (s *KeepAliveStats) RecordLoss() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.record(true)
+	s.lost++
+	s.consecutiveLoss++
+}
+
+func (s *KeepAliveStats) record(lost bool) {
+	s.sent++
+	s.window[s.next] = lost
+	s.next = (s.next + 1) % len(s.window)
+	if s.filled < len(s.window) {
+		s.filled++
+	}
+}
+
+// Snapshot returns current keep alive stats.
+func (s *KeepAliveStats) Snapshot() KeepAliveSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lost int
+	for i := 0; i < s.filled; i++ {
+		if s.window[i] {
+			lost++
+		}
+	}
+
+	snapshot := KeepAliveSnapshot{
+		Sent:            s.sent,
+		Lost:            s.lost,
+		ConsecutiveLoss: s.consecutiveLoss,
+		Samples:         s.filled,
+		LastRTT:         s.lastRTT,
+		SmoothedRTT:     s.smoothedRTT,
+	}
+	if s.filled > 0 {
+		snapshot.LossRatio = float64(lost) / float64(s.filled)
+	}
+	return snapshot
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeepAliveStats(t *testing.T) {
+	stats := NewKeepAliveStats(4)
+	assert.Equal(t, KeepAliveSnapshot{}, stats.Snapshot())
+
+	stats.RecordRTT(80 * time.Millisecond)
+	stats.RecordLoss()
+	stats.RecordLoss()
+	assert.Equal(t, KeepAliveSnapshot{
+		Sent:            3,
+		Lost:            2,
+		ConsecutiveLoss: 2,
+		Samples:         3,
+		LossRatio:       2.0 / 3,
+		LastRTT:         80 * time.Millisecond,
+		SmoothedRTT:     80 * time.Millisecond,
+	}, stats.Snapshot())
+
+	stats.RecordRTT(160 * time.Millisecond)
+	stats.RecordRTT(160 * time.Millisecond)
+	assert.Equal(t, KeepAliveSnapshot{
+		Sent:        5,
+		Lost:        2,
+		Samples:     4,
+		LossRatio:   0.5,
+		LastRTT:     160 * time.Millisecond,
+		SmoothedRTT: 98750 * time.Microsecond,
+	}, stats.Snapshot())
+}
+
+func TestDeadPeerConfig_Unresponsive(t *testing.T) {
+	tests := []struct {
+		name   string
+		config DeadPeerConfig
+		stats  KeepAliveSnapshot
+		want   bool
+	}{
+		{
+			name:   "responsive peer",
+			config: DeadPeerConfig{MaxConsecutiveLoss: 3, MaxLossRatio: 0.5, Window: 10},
+			stats:  KeepAliveSnapshot{ConsecutiveLoss: 2, Samples: 10, LossRatio: 0.4},
+		},
+		{
+			name:   "consecutive loss reached",
+			config: DeadPeerConfig{MaxConsecutiveLoss: 3},
+			stats:  KeepAliveSnapshot{ConsecutiveLoss: 3},
+			want:   true,
+		},
+		{
+			name:   "loss ratio reached",
+			config: DeadPeerConfig{MaxConsecutiveLoss: 3, MaxLossRatio: 0.5, Window: 10},
+			stats:  KeepAliveSnapshot{ConsecutiveLoss: 1, Samples: 10, LossRatio: 0.5},
+			want:   true,
+		},
+		{
+			name:   "loss ratio ignored until window is filled",
+			config: DeadPeerConfig{MaxLossRatio: 0.5, Window: 10},
+			stats:  KeepAliveSnapshot{ConsecutiveLoss: 2, Samples: 2, LossRatio: 1},
+		},
+		{
+			name:  "detection disabled",
+			stats: KeepAliveSnapshot{ConsecutiveLoss: 100, Samples: 100, LossRatio: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.Unresponsive(tt.stats))
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
@@ -52,10 +53,12 @@ type Listener interface {
 }
 
 // NewListener creates new p2p communication listener which is used on provider side.
-func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, eventBus eventbus.EventBus) Listener {
+func NewListener(brokerConn nats.Connection, signer identity.SignerFactory, verifier identity.Verifier, ipResolver ip.Resolver, natTypes natTypeProvider, eventBus eventbus.EventBus) Listener {
 	return &listener{
 		brokerConn:     brokerConn,
+		natTypes:       natTypes,
 		pendingConfigs: map[PublicKey]p2pConnectConfig{},
+		failedMethods:  map[string]bool{},
 		ipResolver:     ipResolver,
 		signer:         signer,
 		verifier:       verifier,
@@ -70,16 +73,23 @@ type listener struct {
 	signer     identity.SignerFactory
 	verifier   identity.Verifier
 	ipResolver ip.Resolver
+	natTypes   natTypeProvider
 
 	// Keys holds pendingConfigs temporary configs for provider side since it
 	// need to handle key exchange in two steps.
 	pendingConfigs   map[PublicKey]p2pConnectConfig
 	pendingConfigsMu sync.Mutex
+
+	// failedMethods holds traversal methods which failed on the last attempt,
+	// they are not announced in the contact until they succeed again.
+	failedMethods   map[string]bool
+	failedMethodsMu sync.Mutex
 }
 
 type p2pConnectConfig struct {
 	publicIP         string
 	peerPublicIP     string
+	peerNAT64IP      string
 	compatibility    int
 	peerPorts        []int
 	localPorts       []int
@@ -91,6 +101,9 @@ type p2pConnectConfig struct {
 	upnpPortsRelease func()
 	start            nat.StartPorts
 	peerID           identity.Identity
+	natType          string
+	peerNATType      string
+	peerEncodings    []string
 }
 
 func (c *p2pConnectConfig) peerIP() string {
@@ -98,13 +111,35 @@ func (c *p2pConnectConfig) peerIP() string {
 		// Assume that both peers are on the same network.
 		return "127.0.0.1"
 	}
+	if c.peerNAT64IP != "" {
+		return c.peerNAT64IP
+	}
 	return c.peerPublicIP
 }
 
+// peerFirewallIP returns the peer address the packets are exchanged with, the NAT64 one if it is in use.
+func (c *p2pConnectConfig) peerFirewallIP() string {
+	if c.peerNAT64IP != "" {
+		return c.peerNAT64IP
+	}
+	return c.peerPublicIP
+}
+
+// fullCone reports whether both peers announced NAT which accepts packets from
+// any host on mapped ports. In such case peers can connect directly to the
+// STUN discovered addresses without the hole punching.
+func (c *p2pConnectConfig) fullCone() bool {
+	return acceptsUnsolicited(c.natType) && acceptsUnsolicited(c.peerNATType)
+}
+
 func (m *listener) GetContact() market.Contact {
 	return market.Contact{
-		Type:       ContactTypeV1,
-		Definition: ContactDefinition{BrokerAddresses: m.brokerConn.Servers()},
+		Type: ContactTypeV1,
+		Definition: ContactDefinition{
+			BrokerAddresses:  m.brokerConn.Servers(),
+			NATType:          localNATType(m.natTypes),
+			TraversalMethods: m.traversalMethods(),
+		},
 	}
 }
 
@@ -138,11 +173,21 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 			return
 		}
 
+		fullCone := config.start != nil && config.fullCone()
 		trace := config.tracer.StartStage("Provider P2P exchange ack")
 		// Send ack in separate goroutine and start pinging.
 		// It is important that provider starts sending pings first otherwise
 		// providers router can think that consumer is sending DDoS packets.
 		go func(reply string) {
+			if fullCone {
+				// No pings are sent in full cone fast path, so there is nothing to wait for.
+				if err := m.brokerConn.Publish(reply, []byte("OK")); err != nil {
+					log.Err(err).Msg("Could not publish exchange ack")
+				}
+				config.tracer.EndStage(trace)
+				return
+			}
+
 			// race condition still happens when consumer starts to ping until provider did not manage to complete required number of pings
 			// this might be provider / consumer performance dependent
 			// make sleep time dependent on pinger interval and wait for 2 ping iterations
@@ -158,7 +203,16 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		}(msg.Reply)
 
 		var conn1, conn2 *net.UDPConn
-		if config.start != nil {
+		if fullCone {
+			traceDial := config.tracer.StartStage("Provider P2P dial (full cone)")
+			log.Debug().Msg("Both peers are behind full cone NAT, skipping consumer ping")
+			conn1, conn2, err = dialPeer("", config.localPorts, config.peerIP(), config.peerPorts)
+			if err != nil {
+				log.Err(err).Msg("Could not create UDP conns")
+				return
+			}
+			config.tracer.EndStage(traceDial)
+		} else if config.start != nil {
 			traceDial := config.tracer.StartStage("Provider P2P dial (preparation)")
 			log.Debug().Msgf("Pinging consumer with IP %s using ports %v:%v initial ttl: %v",
 				config.peerIP(), config.localPorts, config.peerPorts, 1)
@@ -180,14 +234,9 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		} else {
 			traceDial := config.tracer.StartStage("Provider P2P dial (direct)")
 			log.Debug().Msg("Skipping consumer ping")
-			conn1, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[0]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[0]})
-			if err != nil {
-				log.Err(err).Msg("Could not create UDP conn for p2p channel")
-				return
-			}
-			conn2, err = net.DialUDP("udp4", &net.UDPAddr{Port: config.localPorts[1]}, &net.UDPAddr{IP: net.ParseIP(config.peerIP()), Port: config.peerPorts[1]})
+			conn1, conn2, err = dialPeer("", config.localPorts, config.peerIP(), config.peerPorts)
 			if err != nil {
-				log.Err(err).Msg("Could not create UDP conn for service")
+				log.Err(err).Msg("Could not create UDP conns")
 				return
 			}
 			config.tracer.EndStage(traceDial)
@@ -203,6 +252,7 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		channel.setServiceConn(conn2)
 		channel.setPeerID(config.peerID)
 		channel.setUpnpPortsRelease(config.upnpPortsRelease)
+		channel.setEncoding(dto.Negotiate(dto.EncodingNames(dto.SupportedEncodings), config.peerEncodings))
 
 		channelHandlers(channel)
 
@@ -265,6 +315,7 @@ func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg
 	}
 
 	p2pConnConfig := p2pConnectConfig{
+		natType:          localNATType(m.natTypes),
 		publicIP:         publicIP,
 		localPorts:       localPorts,
 		publicPorts:      stunPorts(providerID, m.eventBus, localPorts...),
@@ -284,6 +335,8 @@ func (m *listener) providerStartConfigExchange(providerID identity.Identity, msg
 		PublicIP:      publicIP,
 		Ports:         intToInt32Slice(p2pConnConfig.publicPorts),
 		Compatibility: compat.Compatibility,
+		NatType:       p2pConnConfig.natType,
+		Encodings:     dto.EncodingNames(dto.SupportedEncodings),
 	}
 	configCiphertext, err := encryptConnConfigMsg(&config, privateKey, peerPubKey)
 	if err != nil {
@@ -320,6 +373,7 @@ func (m *listener) prepareLocalPorts(id string, tracer *trace.Tracer) (string, [
 
 	for _, p := range nat.OrderedPortProviders() {
 		ports, release, start, err := p.Provider.PreparePorts()
+		m.setMethodFailed(p.Method, err != nil)
 		if err == nil {
 			m.eventBus.Publish(nat.AppTopicNATTraversalMethod, nat.NATTraversalMethod{
 				Identity: id,
@@ -339,6 +393,27 @@ func (m *listener) prepareLocalPorts(id string, tracer *trace.Tracer) (string, [
 	return "", nil, nil, nil, fmt.Errorf("failed to prepare local ports")
 }
 
+func (m *listener) setMethodFailed(method string, failed bool) {
+	m.failedMethodsMu.Lock()
+	defer m.failedMethodsMu.Unlock()
+
+	m.failedMethods[method] = failed
+}
+
+// traversalMethods returns configured traversal methods excluding the ones known to fail.
+func (m *listener) traversalMethods() (methods []string) {
+	m.failedMethodsMu.Lock()
+	defer m.failedMethodsMu.Unlock()
+
+	for _, method := range nat.OrderedMethods() {
+		if !m.failedMethods[method] {
+			methods = append(methods, method)
+		}
+	}
+
+	return methods
+}
+
 func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConfig, error) {
 	signedMsg, peerID, err := unpackSignedMsg(m.verifier, msg.Data)
 	if err != nil {
@@ -382,6 +457,9 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 		upnpPortsRelease: config.upnpPortsRelease,
 		start:            config.start,
 		peerID:           config.peerID,
+		natType:          config.natType,
+		peerNATType:      peerConfig.NatType,
+		peerEncodings:    peerConfig.Encodings,
 	}, nil
 }
 
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	nattype "github.com/mysteriumnetwork/node/nat"
+)
+
+func TestP2PConnectConfig_FullCone(t *testing.T) {
+	tests := []struct {
+		natType     nattype.NATType
+		peerNATType nattype.NATType
+		want        bool
+	}{
+		{natType: nattype.NATTypeFullCone, peerNATType: nattype.NATTypeFullCone, want: true},
+		{natType: nattype.NATTypeNone, peerNATType: nattype.NATTypeFullCone, want: true},
+		{natType: nattype.NATTypeFullCone, peerNATType: nattype.NATTypePortRestrictedCone, want: false},
+		{natType: nattype.NATTypeSymmetric, peerNATType: nattype.NATTypeFullCone, want: false},
+		{natType: nattype.NATTypeFullCone, peerNATType: "", want: false},
+		{natType: "", peerNATType: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.natType)+"-"+string(tt.peerNATType), func(t *testing.T) {
+			config := p2pConnectConfig{natType: string(tt.natType), peerNATType: string(tt.peerNATType)}
+			assert.Equal(t, tt.want, config.fullCone())
+		})
+	}
+}
+
+func TestP2PConnectConfig_PeerIP(t *testing.T) {
+	tests := []struct {
+		name   string
+		config p2pConnectConfig
+		want   string
+	}{
+		{
+			name:   "public IP",
+			config: p2pConnectConfig{publicIP: "1.1.1.1", peerPublicIP: "2.2.2.2"},
+			want:   "2.2.2.2",
+		},
+		{
+			name:   "same network",
+			config: p2pConnectConfig{publicIP: "2.2.2.2", peerPublicIP: "2.2.2.2", peerNAT64IP: "64:ff9b::202:202"},
+			want:   "127.0.0.1",
+		},
+		{
+			name:   "NAT64",
+			config: p2pConnectConfig{publicIP: "1.1.1.1", peerPublicIP: "2.2.2.2", peerNAT64IP: "64:ff9b::202:202"},
+			want:   "64:ff9b::202:202",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.config.peerIP())
+		})
+	}
+}
+
+func TestP2PConnectConfig_PeerFirewallIP(t *testing.T) {
+	config := p2pConnectConfig{publicIP: "1.1.1.1", peerPublicIP: "2.2.2.2"}
+	assert.Equal(t, "2.2.2.2", config.peerFirewallIP())
+
+	config.peerNAT64IP = "64:ff9b::202:202"
+	assert.Equal(t, "64:ff9b::202:202", config.peerFirewallIP())
+}
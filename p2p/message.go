@@ -20,6 +20,8 @@ package p2p
 import (
 	"github.com/rs/zerolog/log"
 	"google.golang.org/protobuf/proto"
+
+	"github.com/mysteriumnetwork/node/dto"
 )
 
 const (
@@ -34,11 +36,24 @@ const (
 	TopicSessionStatus = "p2p-session-connectivity-status"
 	// TopicSessionDestroy is a session destroy endpoint for p2p communication.
 	TopicSessionDestroy = "p2p-session-destroy"
+	// TopicSessionTerminate is a session termination notification sent by provider for p2p communication.
+	TopicSessionTerminate = "p2p-session-terminate"
+	// TopicSessionPause is a request sent by consumer to stop the billing and throttle the tunnel of the session.
+	TopicSessionPause = "p2p-session-pause"
+	// TopicSessionResume is a request sent by consumer to continue the paused session,
+	// provider sends it to consumer when the session stays paused for too long and gets resumed.
+	TopicSessionResume = "p2p-session-resume"
 
 	// TopicPaymentMessage is a payment messages endpoint for p2p communication.
 	TopicPaymentMessage = "p2p-payment-message"
 	// TopicPaymentInvoice is a payment invoices endpoint for p2p communication.
 	TopicPaymentInvoice = "p2p-payment-invoice"
+	// TopicPaymentShortfall is a notification sent by provider when hermes did not cover the whole payment.
+	TopicPaymentShortfall = "p2p-payment-shortfall"
+	// TopicPaymentHermesFee is a notification sent by provider when hermes raised its fee above the limit during the session.
+	TopicPaymentHermesFee = "p2p-payment-hermes-fee"
+	// TopicPaymentReady is a ping sent by provider before billing starts, consumer acknowledges it once it is ready to pay.
+	TopicPaymentReady = "p2p-payment-ready"
 )
 
 // Message represent message with data bytes.
@@ -63,6 +78,21 @@ func ProtoMessage(m proto.Message) *Message {
 	return &Message{Data: pbBytes}
 }
 
+// UnmarshalDTO is convenient helper to decode message data encoded by the given codec.
+func (m *Message) UnmarshalDTO(codec dto.Codec, to dto.Message) error {
+	return codec.Unmarshal(m.Data, to)
+}
+
+// DTOMessage is convenient helper to return message with data bytes encoded by the given codec.
+func DTOMessage(codec dto.Codec, m dto.Message) *Message {
+	data, err := codec.Marshal(m)
+	if err != nil {
+		log.Err(err).Msgf("Failed to marshal %s message", codec.Encoding())
+		return &Message{Data: []byte{}}
+	}
+	return &Message{Data: data}
+}
+
 const (
 	statusCodeOK                 = 1
 	statusCodePublicErr          = 2
@@ -17,6 +17,8 @@
 
 package nat
 
+import "github.com/mysteriumnetwork/node/eventbus"
+
 const (
 	// AppTopicNATTraversalMethod represent NAT traversal method topic.
 	AppTopicNATTraversalMethod = "NAT-traversal-method"
@@ -25,6 +27,10 @@ const (
 	pingMaxPorts      = 20
 )
 
+func init() {
+	eventbus.RegisterSchema(AppTopicNATTraversalMethod, 1, NATTraversalMethod{})
+}
+
 // NATTraversalMethod represents information about NAT traversal methods results.
 type NATTraversalMethod struct {
 	Identity string
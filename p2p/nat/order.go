@@ -42,26 +42,29 @@ var traversalOptions map[string]func() PortProvider = map[string]func() PortProv
 	"holepunching": NewNATHolePunchingPortProvider,
 }
 
-// OrderedPortProviders returns a ordered list of the port providers.
-func OrderedPortProviders() (list []NamedPortProvider) {
-	methods := strings.Split(config.GetString(config.FlagTraversal), ",")
-
-	for _, m := range methods {
-		if t, ok := traversalOptions[m]; ok {
-			list = append(list, NamedPortProvider{Method: m, Provider: t()})
+// OrderedMethods returns a ordered list of the configured traversal method names.
+func OrderedMethods() (methods []string) {
+	for _, m := range strings.Split(config.GetString(config.FlagTraversal), ",") {
+		if _, ok := traversalOptions[m]; ok {
+			methods = append(methods, m)
 		} else {
 			log.Warn().Msgf("Unsupported traversal method %s, ignoring it", m)
 		}
 	}
 
-	if len(list) == 0 {
+	if len(methods) == 0 {
 		log.Warn().Msg("Failed to parse ordered list of traversal methods, falling back to default values")
 
-		return []NamedPortProvider{
-			{"manual", NewManualPortProvider()},
-			{"upnp", NewUPnPPortProvider()},
-			{"holepunching", NewNATHolePunchingPortProvider()},
-		}
+		return []string{"manual", "upnp", "holepunching"}
+	}
+
+	return methods
+}
+
+// OrderedPortProviders returns a ordered list of the port providers.
+func OrderedPortProviders() (list []NamedPortProvider) {
+	for _, m := range OrderedMethods() {
+		list = append(list, NamedPortProvider{Method: m, Provider: traversalOptions[m]()})
 	}
 
 	return list
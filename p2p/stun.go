@@ -29,12 +29,18 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/nat64"
 	"github.com/mysteriumnetwork/node/requests/resolver"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 // AppTopicSTUN represents the STUN detection topic.
 const AppTopicSTUN = "STUN detection"
 
+func init() {
+	eventbus.RegisterSchema(AppTopicSTUN, 1, STUNDetectionStatus{})
+}
+
 // STUNDetectionStatus represents information about detected NAT type using STUN servers.
 type STUNDetectionStatus struct {
 	Identity string
@@ -47,6 +53,13 @@ func stunPorts(identity identity.Identity, eventBus eventbus.Publisher, localPor
 		return localPorts
 	}
 
+	// On IPv6-only networks STUN servers are reached via NAT64, so the discovered
+	// ports are mapped by the NAT64 gateway the same way as by a regular NAT.
+	network := "udp4"
+	if !nat64.HasIPv4Route() {
+		network = "udp6"
+	}
+
 	m := make(map[int]int)
 
 	mu := sync.Mutex{}
@@ -57,7 +70,7 @@ func stunPorts(identity identity.Identity, eventBus eventbus.Publisher, localPor
 		go func(p int) {
 			defer wg.Done()
 
-			resp := multiServerSTUN(serverList, p, 2)
+			resp := multiServerSTUN(network, serverList, p, 2)
 
 			mu.Lock()
 			defer mu.Unlock()
@@ -107,8 +120,8 @@ func stunPorts(identity identity.Identity, eventBus eventbus.Publisher, localPor
 	return remotePorts
 }
 
-func multiServerSTUN(servers []string, p, limit int) (respPort []int) {
-	conn, err := net.ListenUDP("udp4", &net.UDPAddr{Port: p})
+func multiServerSTUN(network string, servers []string, p, limit int) (respPort []int) {
+	conn, err := net.ListenUDP(network, &net.UDPAddr{Port: p})
 	if err != nil {
 		log.Error().Err(err).Msg("failed to listen UDP address for STUN server")
 		return nil
@@ -134,7 +147,7 @@ func multiServerSTUN(servers []string, p, limit int) (respPort []int) {
 		go func(server string) {
 			defer wg.Done()
 
-			port, err := stunPort(conn, server)
+			port, err := stunPort(network, conn, server)
 			if err != nil {
 				log.Trace().Err(err).Msg("failed to get public UDP port from STUN server")
 				return
@@ -154,17 +167,20 @@ func multiServerSTUN(servers []string, p, limit int) (respPort []int) {
 	return respPort
 }
 
-func stunPort(conn *net.UDPConn, server string) (remotePort int, err error) {
+func stunPort(network string, conn *net.UDPConn, server string) (remotePort int, err error) {
 	host, port, err := net.SplitHostPort(server)
 	if err != nil {
 		return 0, fmt.Errorf("failed to parse STUN server address: %w", err)
 	}
 
-	if addrs := resolver.FetchDNSFromCache(host); len(addrs) > 0 {
-		server = net.JoinHostPort(addrs[0], port)
+	for _, addr := range resolver.FetchDNSFromCache(host) {
+		if netutil.UDPNetwork(net.ParseIP(addr)) == network {
+			server = net.JoinHostPort(addr, port)
+			break
+		}
 	}
 
-	serverAddr, err := net.ResolveUDPAddr("udp", server)
+	serverAddr, err := net.ResolveUDPAddr(network, server)
 	if err != nil {
 		return 0, fmt.Errorf("failed to resolve STUN server address: %w", err)
 	}
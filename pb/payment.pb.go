@@ -36,6 +36,7 @@ type Invoice struct {
 	Hashlock       string `protobuf:"bytes,4,opt,name=Hashlock,proto3" json:"Hashlock,omitempty"`
 	Provider       string `protobuf:"bytes,5,opt,name=Provider,proto3" json:"Provider,omitempty"`
 	ChainID        int64  `protobuf:"varint,6,opt,name=ChainID,proto3" json:"ChainID,omitempty"`
+	SentAt         int64  `protobuf:"varint,7,opt,name=SentAt,proto3" json:"SentAt,omitempty"`
 }
 
 func (x *Invoice) Reset() {
@@ -112,6 +113,13 @@ func (x *Invoice) GetChainID() int64 {
 	return 0
 }
 
+func (x *Invoice) GetSentAt() int64 {
+	if x != nil {
+		return x.SentAt
+	}
+	return 0
+}
+
 type ExchangeMessage struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -302,11 +310,264 @@ func (x *Promise) GetSignature() []byte {
 	return nil
 }
 
+type PromiseShortfall struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SessionID   string `protobuf:"bytes,1,opt,name=SessionID,proto3" json:"SessionID,omitempty"`
+	AgreementID string `protobuf:"bytes,2,opt,name=AgreementID,proto3" json:"AgreementID,omitempty"`
+	Requested   string `protobuf:"bytes,3,opt,name=Requested,proto3" json:"Requested,omitempty"`
+	Covered     string `protobuf:"bytes,4,opt,name=Covered,proto3" json:"Covered,omitempty"`
+	Total       string `protobuf:"bytes,5,opt,name=Total,proto3" json:"Total,omitempty"`
+}
+
+func (x *PromiseShortfall) Reset() {
+	*x = PromiseShortfall{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_payment_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PromiseShortfall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromiseShortfall) ProtoMessage() {}
+
+func (x *PromiseShortfall) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_payment_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromiseShortfall.ProtoReflect.Descriptor instead.
+func (*PromiseShortfall) Descriptor() ([]byte, []int) {
+	return file_pb_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PromiseShortfall) GetSessionID() string {
+	if x != nil {
+		return x.SessionID
+	}
+	return ""
+}
+
+func (x *PromiseShortfall) GetAgreementID() string {
+	if x != nil {
+		return x.AgreementID
+	}
+	return ""
+}
+
+func (x *PromiseShortfall) GetRequested() string {
+	if x != nil {
+		return x.Requested
+	}
+	return ""
+}
+
+func (x *PromiseShortfall) GetCovered() string {
+	if x != nil {
+		return x.Covered
+	}
+	return ""
+}
+
+func (x *PromiseShortfall) GetTotal() string {
+	if x != nil {
+		return x.Total
+	}
+	return ""
+}
+
+type HermesFeeChange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SessionID    string `protobuf:"bytes,1,opt,name=SessionID,proto3" json:"SessionID,omitempty"`
+	Fee          uint32 `protobuf:"varint,2,opt,name=Fee,proto3" json:"Fee,omitempty"`
+	PreviousFee  uint32 `protobuf:"varint,3,opt,name=PreviousFee,proto3" json:"PreviousFee,omitempty"`
+	MaxFee       uint32 `protobuf:"varint,4,opt,name=MaxFee,proto3" json:"MaxFee,omitempty"`
+	Policy       string `protobuf:"bytes,5,opt,name=Policy,proto3" json:"Policy,omitempty"`
+	Deadline     int64  `protobuf:"varint,6,opt,name=Deadline,proto3" json:"Deadline,omitempty"`
+	PricePerHour string `protobuf:"bytes,7,opt,name=PricePerHour,proto3" json:"PricePerHour,omitempty"`
+	PricePerGiB  string `protobuf:"bytes,8,opt,name=PricePerGiB,proto3" json:"PricePerGiB,omitempty"`
+	Applied      bool   `protobuf:"varint,9,opt,name=Applied,proto3" json:"Applied,omitempty"`
+}
+
+func (x *HermesFeeChange) Reset() {
+	*x = HermesFeeChange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_payment_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HermesFeeChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HermesFeeChange) ProtoMessage() {}
+
+func (x *HermesFeeChange) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_payment_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HermesFeeChange.ProtoReflect.Descriptor instead.
+func (*HermesFeeChange) Descriptor() ([]byte, []int) {
+	return file_pb_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HermesFeeChange) GetSessionID() string {
+	if x != nil {
+		return x.SessionID
+	}
+	return ""
+}
+
+func (x *HermesFeeChange) GetFee() uint32 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+func (x *HermesFeeChange) GetPreviousFee() uint32 {
+	if x != nil {
+		return x.PreviousFee
+	}
+	return 0
+}
+
+func (x *HermesFeeChange) GetMaxFee() uint32 {
+	if x != nil {
+		return x.MaxFee
+	}
+	return 0
+}
+
+func (x *HermesFeeChange) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *HermesFeeChange) GetDeadline() int64 {
+	if x != nil {
+		return x.Deadline
+	}
+	return 0
+}
+
+func (x *HermesFeeChange) GetPricePerHour() string {
+	if x != nil {
+		return x.PricePerHour
+	}
+	return ""
+}
+
+func (x *HermesFeeChange) GetPricePerGiB() string {
+	if x != nil {
+		return x.PricePerGiB
+	}
+	return ""
+}
+
+func (x *HermesFeeChange) GetApplied() bool {
+	if x != nil {
+		return x.Applied
+	}
+	return false
+}
+
+type InvoiceReceipt struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Hashlock   string `protobuf:"bytes,1,opt,name=Hashlock,proto3" json:"Hashlock,omitempty"`
+	SentAt     int64  `protobuf:"varint,2,opt,name=SentAt,proto3" json:"SentAt,omitempty"`
+	ReceivedAt int64  `protobuf:"varint,3,opt,name=ReceivedAt,proto3" json:"ReceivedAt,omitempty"`
+}
+
+func (x *InvoiceReceipt) Reset() {
+	*x = InvoiceReceipt{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pb_payment_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InvoiceReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvoiceReceipt) ProtoMessage() {}
+
+func (x *InvoiceReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_pb_payment_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvoiceReceipt.ProtoReflect.Descriptor instead.
+func (*InvoiceReceipt) Descriptor() ([]byte, []int) {
+	return file_pb_payment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *InvoiceReceipt) GetHashlock() string {
+	if x != nil {
+		return x.Hashlock
+	}
+	return ""
+}
+
+func (x *InvoiceReceipt) GetSentAt() int64 {
+	if x != nil {
+		return x.SentAt
+	}
+	return 0
+}
+
+func (x *InvoiceReceipt) GetReceivedAt() int64 {
+	if x != nil {
+		return x.ReceivedAt
+	}
+	return 0
+}
+
 var File_pb_payment_proto protoreflect.FileDescriptor
 
 var file_pb_payment_proto_rawDesc = []byte{
 	0x0a, 0x10, 0x70, 0x62, 0x2f, 0x70, 0x61, 0x79, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0xcb, 0x01, 0x0a, 0x07, 0x49, 0x6e, 0x76, 0x6f, 0x69,
+	0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0xe3, 0x01, 0x0a, 0x07, 0x49, 0x6e, 0x76, 0x6f, 0x69,
 	0x63, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49,
 	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65,
 	0x6e, 0x74, 0x49, 0x44, 0x12, 0x26, 0x0a, 0x0e, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e,
@@ -319,34 +580,70 @@ var file_pb_payment_proto_rawDesc = []byte{
 	0x0a, 0x08, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x08, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x68,
 	0x61, 0x69, 0x6e, 0x49, 0x44, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x43, 0x68, 0x61,
-	0x69, 0x6e, 0x49, 0x44, 0x22, 0xf2, 0x01, 0x0a, 0x0f, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67,
-	0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x25, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x6d,
-	0x69, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x50,
-	0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x52, 0x07, 0x50, 0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x12,
-	0x20, 0x0a, 0x0b, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49,
-	0x44, 0x12, 0x26, 0x0a, 0x0e, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f,
-	0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x41, 0x67, 0x72, 0x65, 0x65,
-	0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f,
-	0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x50, 0x72, 0x6f,
-	0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74,
-	0x75, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x48, 0x65, 0x72, 0x6d, 0x65, 0x73, 0x49, 0x44, 0x18,
-	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x48, 0x65, 0x72, 0x6d, 0x65, 0x73, 0x49, 0x44, 0x12,
-	0x18, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x22, 0xb3, 0x01, 0x0a, 0x07, 0x50, 0x72,
-	0x6f, 0x6d, 0x69, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c,
-	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65,
-	0x6c, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x41, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x46,
-	0x65, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x46, 0x65, 0x65, 0x12, 0x1a, 0x0a,
-	0x08, 0x48, 0x61, 0x73, 0x68, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52,
-	0x08, 0x48, 0x61, 0x73, 0x68, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x0c, 0x0a, 0x01, 0x52, 0x18, 0x05,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x52, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e,
-	0x49, 0x44, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49,
-	0x44, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x07,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x42,
-	0x06, 0x5a, 0x04, 0x2e, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x69, 0x6e, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x65, 0x6e, 0x74, 0x41, 0x74, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x53, 0x65, 0x6e, 0x74, 0x41, 0x74, 0x22, 0xf2, 0x01, 0x0a,
+	0x0f, 0x45, 0x78, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
+	0x12, 0x25, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0b, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x52, 0x07,
+	0x50, 0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x0b, 0x41, 0x67, 0x72, 0x65, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x41, 0x67,
+	0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x12, 0x26, 0x0a, 0x0e, 0x41, 0x67, 0x72,
+	0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0e, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x6f, 0x74, 0x61,
+	0x6c, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x64, 0x65, 0x72, 0x12, 0x1c, 0x0a,
+	0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x48,
+	0x65, 0x72, 0x6d, 0x65, 0x73, 0x49, 0x44, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x48,
+	0x65, 0x72, 0x6d, 0x65, 0x73, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e,
+	0x49, 0x44, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49,
+	0x44, 0x22, 0xb3, 0x01, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x6d, 0x69, 0x73, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x43, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x41,
+	0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x41, 0x6d, 0x6f,
+	0x75, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x46, 0x65, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x46, 0x65, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x48, 0x61, 0x73, 0x68, 0x6c, 0x6f, 0x63,
+	0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x48, 0x61, 0x73, 0x68, 0x6c, 0x6f, 0x63,
+	0x6b, 0x12, 0x0c, 0x0a, 0x01, 0x52, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x52, 0x12,
+	0x18, 0x0a, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x49, 0x44, 0x12, 0x1c, 0x0a, 0x09, 0x53, 0x69, 0x67,
+	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x53, 0x69,
+	0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0xa0, 0x01, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x6d,
+	0x69, 0x73, 0x65, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x66, 0x61, 0x6c, 0x6c, 0x12, 0x1c, 0x0a, 0x09,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12, 0x20, 0x0a, 0x0b, 0x41, 0x67,
+	0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x41, 0x67, 0x72, 0x65, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x49, 0x44, 0x12, 0x1c, 0x0a, 0x09,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x6f,
+	0x76, 0x65, 0x72, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x43, 0x6f, 0x76,
+	0x65, 0x72, 0x65, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x22, 0x8f, 0x02, 0x0a, 0x0f, 0x48,
+	0x65, 0x72, 0x6d, 0x65, 0x73, 0x46, 0x65, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x1c,
+	0x0a, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x49, 0x44, 0x12, 0x10, 0x0a, 0x03,
+	0x46, 0x65, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x46, 0x65, 0x65, 0x12, 0x20,
+	0x0a, 0x0b, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x46, 0x65, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0b, 0x50, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x46, 0x65, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x4d, 0x61, 0x78, 0x46, 0x65, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x06, 0x4d, 0x61, 0x78, 0x46, 0x65, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79,
+	0x12, 0x1a, 0x0a, 0x08, 0x44, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x08, 0x44, 0x65, 0x61, 0x64, 0x6c, 0x69, 0x6e, 0x65, 0x12, 0x22, 0x0a, 0x0c,
+	0x50, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x48, 0x6f, 0x75, 0x72, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0c, 0x50, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x48, 0x6f, 0x75, 0x72,
+	0x12, 0x20, 0x0a, 0x0b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x47, 0x69, 0x42, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x50, 0x72, 0x69, 0x63, 0x65, 0x50, 0x65, 0x72, 0x47,
+	0x69, 0x42, 0x12, 0x18, 0x0a, 0x07, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x18, 0x09, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x07, 0x41, 0x70, 0x70, 0x6c, 0x69, 0x65, 0x64, 0x22, 0x64, 0x0a, 0x0e,
+	0x49, 0x6e, 0x76, 0x6f, 0x69, 0x63, 0x65, 0x52, 0x65, 0x63, 0x65, 0x69, 0x70, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x48, 0x61, 0x73, 0x68, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x48, 0x61, 0x73, 0x68, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x53, 0x65,
+	0x6e, 0x74, 0x41, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x53, 0x65, 0x6e, 0x74,
+	0x41, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x41, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64,
+	0x41, 0x74, 0x42, 0x06, 0x5a, 0x04, 0x2e, 0x3b, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -361,11 +658,14 @@ func file_pb_payment_proto_rawDescGZIP() []byte {
 	return file_pb_payment_proto_rawDescData
 }
 
-var file_pb_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_pb_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_pb_payment_proto_goTypes = []interface{}{
-	(*Invoice)(nil),         // 0: pb.Invoice
-	(*ExchangeMessage)(nil), // 1: pb.ExchangeMessage
-	(*Promise)(nil),         // 2: pb.Promise
+	(*Invoice)(nil),          // 0: pb.Invoice
+	(*ExchangeMessage)(nil),  // 1: pb.ExchangeMessage
+	(*Promise)(nil),          // 2: pb.Promise
+	(*PromiseShortfall)(nil), // 3: pb.PromiseShortfall
+	(*HermesFeeChange)(nil),  // 4: pb.HermesFeeChange
+	(*InvoiceReceipt)(nil),   // 5: pb.InvoiceReceipt
 }
 var file_pb_payment_proto_depIdxs = []int32{
 	2, // 0: pb.ExchangeMessage.Promise:type_name -> pb.Promise
@@ -418,6 +718,42 @@ func file_pb_payment_proto_init() {
 				return nil
 			}
 		}
+		file_pb_payment_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PromiseShortfall); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_payment_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HermesFeeChange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pb_payment_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InvoiceReceipt); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -425,7 +761,7 @@ func file_pb_payment_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pb_payment_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   3,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
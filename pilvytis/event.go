@@ -17,6 +17,10 @@
 
 package pilvytis
 
+import (
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
 // AppTopicOrderUpdated is an topic when the payment order is updated.
 const AppTopicOrderUpdated = "order_updated"
 
@@ -24,3 +28,7 @@ const AppTopicOrderUpdated = "order_updated"
 type AppEventOrderUpdated struct {
 	OrderSummary
 }
+
+func init() {
+	eventbus.RegisterSchema(AppTopicOrderUpdated, 1, AppEventOrderUpdated{})
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package requests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ParseProxyURL parses and validates the outbound proxy address.
+// HTTP proxies (using CONNECT for TLS targets) and SOCKS5 proxies are supported.
+func ParseProxyURL(addr string) (*url.URL, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy address %q: %w", addr, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, expected one of: http, https, socks5", u.Scheme)
+	}
+
+	if u.Hostname() == "" || u.Port() == "" {
+		return nil, fmt.Errorf("proxy address %q must contain host and port", addr)
+	}
+
+	return u, nil
+}
+
+// SetOutboundProxy routes the requests made via given transports through the proxy.
+func SetOutboundProxy(proxyURL *url.URL, transports ...*http.Transport) {
+	for _, t := range transports {
+		t.Proxy = http.ProxyURL(proxyURL)
+	}
+}
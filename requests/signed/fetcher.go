@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signed
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/logconfig/httptrace"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+// SignatureHeader is the response header carrying the signature of the document.
+const SignatureHeader = "X-Signature"
+
+// Storage caches the fetched documents.
+type Storage interface {
+	GetValue(bucket string, key interface{}, to interface{}) error
+	SetValue(bucket string, key interface{}, to interface{}) error
+}
+
+type cachedDocument struct {
+	Body      []byte
+	Signature string
+	ETag      string
+}
+
+// Fetcher fetches remote JSON documents, verifies their signature and caches them for the time the remote is unreachable.
+type Fetcher struct {
+	client   *requests.HTTPClient
+	verifier identity.Verifier
+	storage  Storage
+	bucket   string
+}
+
+// NewFetcher creates a fetcher of signed documents.
+// Documents are verified with given verifier and cached in given storage bucket, both verifier and storage are optional.
+func NewFetcher(client *requests.HTTPClient, verifier identity.Verifier, storage Storage, bucket string) *Fetcher {
+	return &Fetcher{
+		client:   client,
+		verifier: verifier,
+		storage:  storage,
+		bucket:   bucket,
+	}
+}
+
+// Fetch decodes the document at the given URL into the given value, unless it still matches the given ETag.
+// Returns the ETag of the document and whether the value was decoded.
+func (f *Fetcher) Fetch(url, eTag string, to interface{}) (string, bool, error) {
+	req, err := requests.NewGetRequest(url, "", nil)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to create request")
+	}
+	req.Header.Add("If-None-Match", eTag)
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer res.Body.Close()
+
+	httptrace.TraceRequestResponse(req, res)
+
+	if res.StatusCode == http.StatusNotModified {
+		return eTag, false, nil
+	}
+	if err := requests.ParseResponseError(res); err != nil {
+		return "", false, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", false, errors.Wrap(err, "failed to read document")
+	}
+	doc := cachedDocument{
+		Body:      body,
+		Signature: res.Header.Get(SignatureHeader),
+		ETag:      res.Header.Get("ETag"),
+	}
+	if err := f.decode(doc, to); err != nil {
+		return "", false, err
+	}
+
+	if f.storage != nil {
+		if err := f.storage.SetValue(f.bucket, url, doc); err != nil {
+			log.Warn().Err(err).Msgf("Failed to cache document %s", url)
+		}
+	}
+	return doc.ETag, true, nil
+}
+
+// Restore decodes the cached document of the given URL into the given value.
+// Returns the ETag of the document and whether a valid document was cached.
+func (f *Fetcher) Restore(url string, to interface{}) (string, bool) {
+	if f.storage == nil {
+		return "", false
+	}
+
+	var doc cachedDocument
+	if err := f.storage.GetValue(f.bucket, url, &doc); err != nil {
+		return "", false
+	}
+	if err := f.decode(doc, to); err != nil {
+		log.Warn().Err(err).Msgf("Ignoring cached document %s", url)
+		return "", false
+	}
+	return doc.ETag, true
+}
+
+func (f *Fetcher) decode(doc cachedDocument, to interface{}) error {
+	if f.verifier != nil {
+		if doc.Signature == "" {
+			return errors.New("document is not signed")
+		}
+		if ok, _ := f.verifier.Verify(doc.Body, identity.SignatureHex(doc.Signature)); !ok {
+			return errors.New("invalid document signature")
+		}
+	}
+
+	return errors.Wrap(json.Unmarshal(doc.Body, to), "failed to parse document")
+}
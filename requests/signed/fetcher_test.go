@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package signed
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+type document struct {
+	Value string `json:"value"`
+}
+
+func Test_Fetcher_FetchAndRestore(t *testing.T) {
+	body := []byte(`{"value": "signed"}`)
+	signature, _ := (&identity.SignerFake{}).Sign(body)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == "v1" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", "v1")
+		w.Header().Set(SignatureHeader, hex.EncodeToString(signature.Bytes()))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	storage := newMockStorage()
+	fetcher := NewFetcher(requests.NewHTTPClient("0.0.0.0", time.Second), &identity.VerifierFake{}, storage, "documents")
+
+	var doc document
+	eTag, modified, err := fetcher.Fetch(server.URL, "", &doc)
+	assert.NoError(t, err)
+	assert.True(t, modified)
+	assert.Equal(t, "v1", eTag)
+	assert.Equal(t, "signed", doc.Value)
+
+	eTag, modified, err = fetcher.Fetch(server.URL, eTag, &doc)
+	assert.NoError(t, err)
+	assert.False(t, modified)
+	assert.Equal(t, "v1", eTag)
+
+	var restored document
+	eTag, ok := fetcher.Restore(server.URL, &restored)
+	assert.True(t, ok)
+	assert.Equal(t, "v1", eTag)
+	assert.Equal(t, doc, restored)
+
+	_, ok = fetcher.Restore(server.URL+"/unknown", &restored)
+	assert.False(t, ok)
+}
+
+func Test_Fetcher_RejectsUnsignedDocuments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"value": "unsigned"}`))
+	}))
+	defer server.Close()
+
+	storage := newMockStorage()
+	unverified := NewFetcher(requests.NewHTTPClient("0.0.0.0", time.Second), nil, storage, "documents")
+	var doc document
+	_, _, err := unverified.Fetch(server.URL, "", &doc)
+	assert.NoError(t, err)
+
+	verified := NewFetcher(requests.NewHTTPClient("0.0.0.0", time.Second), &identity.VerifierFake{}, storage, "documents")
+	_, _, err = verified.Fetch(server.URL, "", &doc)
+	assert.EqualError(t, err, "document is not signed")
+
+	// Documents cached before the verifier was configured are not trusted either.
+	_, ok := verified.Restore(server.URL, &doc)
+	assert.False(t, ok)
+}
+
+type mockStorage struct {
+	values map[interface{}][]byte
+}
+
+func newMockStorage() *mockStorage {
+	return &mockStorage{values: make(map[interface{}][]byte)}
+}
+
+func (s *mockStorage) GetValue(bucket string, key interface{}, to interface{}) error {
+	value, ok := s.values[key]
+	if !ok {
+		return errors.New("not found")
+	}
+	return json.Unmarshal(value, to)
+}
+
+func (s *mockStorage) SetValue(bucket string, key interface{}, to interface{}) error {
+	value, err := json.Marshal(to)
+	s.values[key] = value
+	return err
+}
@@ -18,11 +18,14 @@
 package network
 
 import (
+	"fmt"
 	"net"
+	"strings"
 
 	"github.com/jackpal/gateway"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 // RoutingTable implements a set of platform specific tool for creating, deleting
@@ -38,11 +41,52 @@ func (t *RoutingTable) DiscoverGateway() (net.IP, error) {
 // Traffic sent to the IP address will be directed to the system default gaitway
 // instead of tunnel.
 func (t *RoutingTable) ExcludeRule(ip, gw net.IP) error {
+	if netutil.IsIPv6(ip) {
+		return excludeRule6(ip)
+	}
 	return cmdutil.SudoExec("route", "add", "-host", ip.String(), gw.String())
 }
 
+// excludeRule6 pins the current IPv6 route of the given address, so it is not
+// captured by the tunnel default routes added later.
+func excludeRule6(ip net.IP) error {
+	out, err := cmdutil.ExecOutput("route", "-n", "get", "-inet6", ip.String())
+	if err != nil {
+		return err
+	}
+
+	var gw, iface string
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "gateway:":
+			gw = fields[1]
+		case "interface:":
+			iface = fields[1]
+		}
+	}
+
+	switch {
+	case gw != "":
+		if strings.HasPrefix(gw, "fe80:") && !strings.Contains(gw, "%") && iface != "" {
+			gw += "%" + iface
+		}
+		return cmdutil.SudoExec("route", "add", "-inet6", "-host", ip.String(), gw)
+	case iface != "":
+		return cmdutil.SudoExec("route", "add", "-inet6", "-host", ip.String(), "-interface", iface)
+	default:
+		return fmt.Errorf("no IPv6 route to %s", ip)
+	}
+}
+
 // DeleteRule removes excluded routing table rule to return it back to routing
 // thought the tunnel.
 func (t *RoutingTable) DeleteRule(ip, gw net.IP) error {
+	if netutil.IsIPv6(ip) {
+		return cmdutil.SudoExec("route", "delete", "-inet6", "-host", ip.String())
+	}
 	return cmdutil.SudoExec("route", "delete", ip.String(), gw.String())
 }
@@ -21,10 +21,12 @@ package network
 
 import (
 	"net"
+	"strings"
 
 	"github.com/jackpal/gateway"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 // RoutingTable implements a set of platform specific tool for creating, deleting
@@ -40,11 +42,37 @@ func (t *RoutingTable) DiscoverGateway() (net.IP, error) {
 // Traffic sent to the IP address will be directed to the system default gaitway
 // instead of tunnel.
 func (t *RoutingTable) ExcludeRule(ip, gw net.IP) error {
+	if netutil.IsIPv6(ip) {
+		return excludeRule6(ip)
+	}
 	return cmdutil.SudoExec("ip", "route", "add", ip.String(), "via", gw.String())
 }
 
+// excludeRule6 pins the current IPv6 route of the given address, so it is not
+// captured by the tunnel default routes added later.
+func excludeRule6(ip net.IP) error {
+	out, err := cmdutil.ExecOutput("ip", "-6", "route", "get", ip.String())
+	if err != nil {
+		return err
+	}
+
+	args := []string{"ip", "-6", "route", "add", ip.String()}
+	fields := strings.Fields(out)
+	for i := 0; i < len(fields)-1; i++ {
+		switch fields[i] {
+		case "via", "dev":
+			args = append(args, fields[i], fields[i+1])
+		}
+	}
+
+	return cmdutil.SudoExec(args...)
+}
+
 // DeleteRule removes excluded routing table rule to return it back to routing
 // thought the tunnel.
 func (t *RoutingTable) DeleteRule(ip, gw net.IP) error {
+	if netutil.IsIPv6(ip) {
+		return cmdutil.SudoExec("ip", "-6", "route", "delete", ip.String())
+	}
 	return cmdutil.SudoExec("ip", "route", "delete", ip.String(), "via", gw.String())
 }
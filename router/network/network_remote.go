@@ -42,7 +42,7 @@ func (t *RoutingTableRemote) DiscoverGateway() (net.IP, error) {
 // Traffic sent to the IP address will be directed to the system default gaitway
 // instead of tunnel.
 func (t *RoutingTableRemote) ExcludeRule(ip, gw net.IP) error {
-	_, err := client.Command("exclude-route", "-ip", ip.String(), "-gw", gw.String())
+	_, err := client.Command(routeCommand("exclude-route", ip, gw)...)
 	if err != nil {
 		return fmt.Errorf("failed to exclude route via supervisor: %w", err)
 	}
@@ -53,10 +53,19 @@ func (t *RoutingTableRemote) ExcludeRule(ip, gw net.IP) error {
 // DeleteRule removes excluded routing table rule to return it back to routing
 // thought the tunnel.
 func (t *RoutingTableRemote) DeleteRule(ip, gw net.IP) error {
-	_, err := client.Command("delete-route", "-ip", ip.String(), "-gw", gw.String())
+	_, err := client.Command(routeCommand("delete-route", ip, gw)...)
 	if err != nil {
 		return fmt.Errorf("failed to delete route via supervisor: %w", err)
 	}
 
 	return nil
 }
+
+// routeCommand omits the gateway of IPv6 rules, their route is resolved by the supervisor.
+func routeCommand(command string, ip, gw net.IP) []string {
+	args := []string{command, "-ip", ip.String()}
+	if gw != nil {
+		args = append(args, "-gw", gw.String())
+	}
+	return args
+}
@@ -25,6 +25,8 @@ import (
 	"os/exec"
 
 	"github.com/jackpal/gateway"
+
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 // RoutingTable implements a set of platform specific tool for creating, deleting
@@ -40,6 +42,9 @@ func (t *RoutingTable) DiscoverGateway() (net.IP, error) {
 // Traffic sent to the IP address will be directed to the system default gaitway
 // instead of tunnel.
 func (t *RoutingTable) ExcludeRule(ip, gw net.IP) error {
+	if netutil.IsIPv6(ip) {
+		return excludeRule6(ip)
+	}
 	out, err := exec.Command("powershell", "-Command", "route add "+ip.String()+"/32 "+gw.String()).CombinedOutput()
 	return fmt.Errorf("%s: %w", string(out), err)
 }
@@ -47,10 +52,30 @@ func (t *RoutingTable) ExcludeRule(ip, gw net.IP) error {
 // DeleteRule removes excluded routing table rule to return it back to routing
 // thought the tunnel.
 func (t *RoutingTable) DeleteRule(ip, gw net.IP) error {
-	out, err := exec.Command("powershell", "-Command", "route delete "+ip.String()+"/32").CombinedOutput()
+	prefix := "/32"
+	if netutil.IsIPv6(ip) {
+		prefix = "/128"
+	}
+	out, err := exec.Command("powershell", "-Command", "route delete "+ip.String()+prefix).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to delete route: %w, %s", err, string(out))
 	}
 
 	return nil
 }
+
+// excludeRule6 pins the current IPv6 route of the given address with a /128 rule,
+// so it is not captured by the tunnel default routes added later.
+func excludeRule6(ip net.IP) error {
+	cmd := fmt.Sprintf(
+		"$r = Find-NetRoute -RemoteIPAddress %[1]s | Select-Object -Last 1; "+
+			"New-NetRoute -DestinationPrefix %[1]s/128 -InterfaceIndex $r.InterfaceIndex -NextHop $r.NextHop -PolicyStore ActiveStore",
+		ip.String(),
+	)
+	out, err := exec.Command("powershell", "-Command", cmd).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to exclude route: %w, %s", err, string(out))
+	}
+
+	return nil
+}
@@ -29,6 +29,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/router/network"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 type manager struct {
@@ -74,7 +75,9 @@ func NewManager() *manager {
 }
 
 func (m *manager) ExcludeIP(ip net.IP) error {
-	m.ensureStarted()
+	if !netutil.IsIPv6(ip) {
+		m.ensureStarted()
+	}
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -95,7 +98,7 @@ func (m *manager) ExcludeIP(ip net.IP) error {
 		return nil
 	}
 
-	if err := m.routingTable.ExcludeRule(ip, m.currentGW); err != nil {
+	if err := m.routingTable.ExcludeRule(ip, m.gatewayFor(ip)); err != nil {
 		return fmt.Errorf("failed to exclude rule: %w", err)
 	}
 
@@ -121,7 +124,7 @@ func (m *manager) RemoveExcludedIP(ip net.IP) error {
 		if m.rules[i].usage == 0 {
 			m.rules = append(m.rules[:i], m.rules[i+1:]...)
 
-			if err := m.routingTable.DeleteRule(ip, m.currentGW); err != nil {
+			if err := m.routingTable.DeleteRule(ip, m.gatewayFor(ip)); err != nil {
 				return fmt.Errorf("failed to remove excluded rule: %w", err)
 			}
 		}
@@ -169,13 +172,35 @@ func (m *manager) Clean() (lastErr error) {
 		return fmt.Errorf("failed to clean routes: %w", err)
 	}
 
+	for _, rule := range m.rules {
+		if !netutil.IsIPv6(rule.ip) {
+			continue
+		}
+		if err := m.routingTable.DeleteRule(rule.ip, nil); err != nil {
+			return fmt.Errorf("failed to clean routes: %w", err)
+		}
+	}
+
 	m.rules = nil
 
 	return nil
 }
 
+// gatewayFor returns the gateway used to exclude the given IP. IPv6 rules carry
+// no gateway, the routing table of each platform pins them to the current IPv6
+// route of the peer, IPv4 gateway tracking does not apply to them.
+func (m *manager) gatewayFor(ip net.IP) net.IP {
+	if netutil.IsIPv6(ip) {
+		return nil
+	}
+	return m.currentGW
+}
+
 func (m *manager) clean() (lastErr error) {
 	for _, rule := range m.rules {
+		if netutil.IsIPv6(rule.ip) {
+			continue
+		}
 		err := m.routingTable.DeleteRule(rule.ip, m.currentGW)
 		if err != nil {
 			lastErr = err
@@ -188,6 +213,9 @@ func (m *manager) clean() (lastErr error) {
 
 func (m *manager) apply(gw net.IP) (lastErr error) {
 	for _, rule := range m.rules {
+		if netutil.IsIPv6(rule.ip) {
+			continue
+		}
 		err := m.routingTable.ExcludeRule(rule.ip, gw)
 		if err != nil {
 			lastErr = err
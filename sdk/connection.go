@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// DefaultServiceType is the service used when filter does not specify one.
+const DefaultServiceType = "wireguard"
+
+// Filter narrows down providers considered by ConnectBest.
+type Filter struct {
+	// ServiceType to connect to, empty means DefaultServiceType.
+	ServiceType string
+	// CountryCode of the provider, e.g. "DE".
+	CountryCode string
+	// IPType of the provider, e.g. "residential".
+	IPType string
+	// Providers restricts the choice to the given provider identities.
+	Providers []string
+	// SortBy orders the candidates before picking, empty uses the node default.
+	SortBy string
+	// Options of the connection, ProxyPort identifies the connection on the node.
+	Options contract.ConnectOptions
+}
+
+// Connection describes an established connection.
+type Connection struct {
+	// ID identifies the connection on the node, zero for the default connection.
+	ID          int
+	SessionID   string
+	ProviderID  string
+	ServiceType string
+	Country     string
+	Status      string
+	// DisconnectReason given by provider when it terminated the session.
+	DisconnectReason string
+}
+
+// ConnectBest connects consumer to the best provider matching the filter.
+func (c *Client) ConnectBest(consumerID string, filter Filter) (Connection, error) {
+	serviceType := filter.ServiceType
+	if serviceType == "" {
+		serviceType = DefaultServiceType
+	}
+
+	info, err := c.api.SmartConnectionCreate(consumerID, "", serviceType, contract.ConnectionCreateFilter{
+		Providers:   filter.Providers,
+		CountryCode: filter.CountryCode,
+		IPType:      filter.IPType,
+		SortBy:      filter.SortBy,
+	}, filter.Options)
+	if err != nil {
+		return Connection{}, fmt.Errorf("could not connect: %w", err)
+	}
+
+	return newConnection(filter.Options.ProxyPort, info), nil
+}
+
+// Status returns the current state of the connection with the given ID.
+func (c *Client) Status(id int) (Connection, error) {
+	info, err := c.api.ConnectionStatus(id)
+	if err != nil {
+		return Connection{}, fmt.Errorf("could not get connection status: %w", err)
+	}
+	return newConnection(id, info), nil
+}
+
+// Disconnect closes the connection with the given ID.
+func (c *Client) Disconnect(id int) error {
+	if err := c.api.ConnectionDestroy(id); err != nil {
+		return fmt.Errorf("could not disconnect: %w", err)
+	}
+	return nil
+}
+
+func newConnection(id int, info contract.ConnectionInfoDTO) Connection {
+	conn := Connection{
+		ID:               id,
+		SessionID:        info.SessionID,
+		Status:           info.Status,
+		DisconnectReason: info.DisconnectReason,
+	}
+	if p := info.Proposal; p != nil {
+		conn.ProviderID = p.ProviderID
+		conn.ServiceType = p.ServiceType
+		conn.Country = p.Location.Country
+	}
+	return conn
+}
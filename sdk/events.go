@@ -0,0 +1,157 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+)
+
+// Event is emitted while watching the connection, it is one of StateEvent, StatisticsEvent or ErrorEvent.
+type Event interface {
+	isEvent()
+}
+
+// StateEvent is emitted when connection state changes.
+type StateEvent struct {
+	Connection Connection
+	// Previous state of the connection, empty for the first event.
+	Previous string
+}
+
+// StatisticsEvent is emitted periodically while connection is established.
+type StatisticsEvent struct {
+	ConnectionID int
+	Statistics   Statistics
+}
+
+// ErrorEvent is emitted when the node could not be polled, watching continues afterwards.
+type ErrorEvent struct {
+	Err error
+}
+
+func (StateEvent) isEvent()      {}
+func (StatisticsEvent) isEvent() {}
+func (ErrorEvent) isEvent()      {}
+
+// Statistics represents traffic and spending of the connection.
+type Statistics struct {
+	BytesSent          uint64
+	BytesReceived      uint64
+	ThroughputSent     uint64
+	ThroughputReceived uint64
+	Duration           time.Duration
+	TokensSpent        *big.Int
+}
+
+// Events watches the connection with the given ID until context is done.
+func (c *Client) Events(ctx context.Context, id int) <-chan Event {
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		emit := func(e Event) bool {
+			select {
+			case events <- e:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var previous string
+		ticker := time.NewTicker(c.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			if !c.poll(id, &previous, emit) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return events
+}
+
+// StreamStats streams statistics of the connection with the given ID while it is established, until context is done.
+func (c *Client) StreamStats(ctx context.Context, id int) <-chan Statistics {
+	stats := make(chan Statistics)
+	go func() {
+		defer close(stats)
+
+		for e := range c.Events(ctx, id) {
+			se, ok := e.(StatisticsEvent)
+			if !ok {
+				continue
+			}
+
+			select {
+			case stats <- se.Statistics:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return stats
+}
+
+// poll emits events reflecting the current connection state, returns false once emitting is cancelled.
+func (c *Client) poll(id int, previous *string, emit func(Event) bool) bool {
+	conn, err := c.Status(id)
+	if err != nil {
+		return emit(ErrorEvent{Err: err})
+	}
+
+	if conn.Status != *previous {
+		if !emit(StateEvent{Connection: conn, Previous: *previous}) {
+			return false
+		}
+		*previous = conn.Status
+	}
+
+	if conn.Status != string(connectionstate.Connected) {
+		return true
+	}
+
+	stats, err := c.api.ConnectionStatistics(conn.SessionID)
+	if err != nil {
+		return emit(ErrorEvent{Err: fmt.Errorf("could not get connection statistics: %w", err)})
+	}
+
+	return emit(StatisticsEvent{
+		ConnectionID: id,
+		Statistics: Statistics{
+			BytesSent:          stats.BytesSent,
+			BytesReceived:      stats.BytesReceived,
+			ThroughputSent:     stats.ThroughputSent,
+			ThroughputReceived: stats.ThroughputReceived,
+			Duration:           time.Duration(stats.Duration) * time.Second,
+			TokensSpent:        stats.TokensSpent,
+		},
+	})
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package sdk provides high level flows for third-party applications embedding Mysterium connectivity.
+// It wraps TequilAPI of a locally running node, hiding identity, registration and connection details.
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// ErrRegistrationFailed is returned when identity registration ends with an error.
+var ErrRegistrationFailed = errors.New("identity registration failed")
+
+// Config represents SDK client configuration.
+type Config struct {
+	// Address and Port of the node TequilAPI.
+	Address string
+	Port    int
+	// Username and Password used to authenticate against TequilAPI, empty username skips authentication.
+	Username string
+	Password string
+	// PollInterval is how often the node is polled for registration, connection state and statistics.
+	PollInterval time.Duration
+}
+
+// DefaultConfig returns configuration matching the node defaults.
+func DefaultConfig() Config {
+	return Config{
+		Address:      "127.0.0.1",
+		Port:         4050,
+		Username:     "myst",
+		Password:     "mystberry",
+		PollInterval: time.Second,
+	}
+}
+
+// tequilapi is the subset of TequilAPI client used by the SDK.
+type tequilapi interface {
+	AuthAuthenticate(request contract.AuthRequest) (contract.AuthResponse, error)
+	CurrentIdentity(identity, passphrase string) (contract.IdentityRefDTO, error)
+	IdentityRegistrationStatus(address string) (contract.IdentityRegistrationResponse, error)
+	RegisterIdentity(address, beneficiary string, token *string) error
+	SmartConnectionCreate(consumerID, hermesID, serviceType string, filter contract.ConnectionCreateFilter, options contract.ConnectOptions) (contract.ConnectionInfoDTO, error)
+	ConnectionStatus(port int) (contract.ConnectionInfoDTO, error)
+	ConnectionStatistics(sessionID ...string) (contract.ConnectionStatisticsDTO, error)
+	ConnectionDestroy(port int) error
+}
+
+// Client provides convenience flows on top of TequilAPI.
+type Client struct {
+	api    tequilapi
+	config Config
+}
+
+// New creates SDK client connected to the node TequilAPI, authenticating if credentials are configured.
+func New(config Config) (*Client, error) {
+	c := newClient(client.NewClient(config.Address, config.Port), config)
+	if config.Username == "" {
+		return c, nil
+	}
+
+	if _, err := c.api.AuthAuthenticate(contract.AuthRequest{Username: config.Username, Password: config.Password}); err != nil {
+		return nil, fmt.Errorf("could not authenticate to tequilapi: %w", err)
+	}
+	return c, nil
+}
+
+func newClient(api tequilapi, config Config) *Client {
+	if config.PollInterval <= 0 {
+		config.PollInterval = DefaultConfig().PollInterval
+	}
+	return &Client{api: api, config: config}
+}
+
+// EnsureIdentityRegistered unlocks the current identity, creating one if needed, registers it
+// if it is not registered yet and waits until the registration completes or context is done.
+func (c *Client) EnsureIdentityRegistered(ctx context.Context, passphrase string) (string, error) {
+	id, err := c.api.CurrentIdentity("", passphrase)
+	if err != nil {
+		return "", fmt.Errorf("could not get current identity: %w", err)
+	}
+
+	status, err := c.api.IdentityRegistrationStatus(id.Address)
+	if err != nil {
+		return "", fmt.Errorf("could not get identity registration status: %w", err)
+	}
+	if status.Registered {
+		return id.Address, nil
+	}
+
+	if status.Status != registry.InProgress.String() {
+		if err := c.api.RegisterIdentity(id.Address, "", nil); err != nil {
+			return "", fmt.Errorf("could not register identity: %w", err)
+		}
+	}
+
+	ticker := time.NewTicker(c.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+
+		status, err := c.api.IdentityRegistrationStatus(id.Address)
+		if err != nil {
+			return "", fmt.Errorf("could not get identity registration status: %w", err)
+		}
+		switch {
+		case status.Registered:
+			return id.Address, nil
+		case status.Status == registry.RegistrationError.String():
+			return "", ErrRegistrationFailed
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type fakeTequilapi struct {
+	mu           sync.Mutex
+	statuses     []contract.IdentityRegistrationResponse
+	registered   string
+	filter       contract.ConnectionCreateFilter
+	serviceType  string
+	connStatuses []string
+	destroyed    []int
+}
+
+func (f *fakeTequilapi) AuthAuthenticate(contract.AuthRequest) (contract.AuthResponse, error) {
+	return contract.AuthResponse{}, nil
+}
+
+func (f *fakeTequilapi) CurrentIdentity(_, _ string) (contract.IdentityRefDTO, error) {
+	return contract.IdentityRefDTO{Address: "0x1"}, nil
+}
+
+func (f *fakeTequilapi) IdentityRegistrationStatus(_ string) (contract.IdentityRegistrationResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := f.statuses[0]
+	if len(f.statuses) > 1 {
+		f.statuses = f.statuses[1:]
+	}
+	return status, nil
+}
+
+func (f *fakeTequilapi) RegisterIdentity(address, _ string, _ *string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.registered = address
+	return nil
+}
+
+func (f *fakeTequilapi) SmartConnectionCreate(_, _, serviceType string, filter contract.ConnectionCreateFilter, _ contract.ConnectOptions) (contract.ConnectionInfoDTO, error) {
+	f.serviceType = serviceType
+	f.filter = filter
+	return contract.ConnectionInfoDTO{
+		Status:    "Connected",
+		SessionID: "session",
+		Proposal:  &contract.ProposalDTO{ProviderID: "0x2", ServiceType: serviceType, Location: contract.ServiceLocationDTO{Country: "DE"}},
+	}, nil
+}
+
+func (f *fakeTequilapi) ConnectionStatus(_ int) (contract.ConnectionInfoDTO, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	status := f.connStatuses[0]
+	if len(f.connStatuses) > 1 {
+		f.connStatuses = f.connStatuses[1:]
+	}
+	return contract.ConnectionInfoDTO{Status: status, SessionID: "session"}, nil
+}
+
+func (f *fakeTequilapi) ConnectionStatistics(_ ...string) (contract.ConnectionStatisticsDTO, error) {
+	return contract.ConnectionStatisticsDTO{BytesSent: 10, BytesReceived: 20, Duration: 3, TokensSpent: big.NewInt(5)}, nil
+}
+
+func (f *fakeTequilapi) ConnectionDestroy(port int) error {
+	f.destroyed = append(f.destroyed, port)
+	return nil
+}
+
+func TestClient_EnsureIdentityRegistered(t *testing.T) {
+	api := &fakeTequilapi{statuses: []contract.IdentityRegistrationResponse{
+		{Status: "Unregistered"},
+		{Status: "InProgress"},
+		{Status: "Registered", Registered: true},
+	}}
+	c := newClient(api, Config{PollInterval: time.Millisecond})
+
+	id, err := c.EnsureIdentityRegistered(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", id)
+	assert.Equal(t, "0x1", api.registered)
+
+	api = &fakeTequilapi{statuses: []contract.IdentityRegistrationResponse{
+		{Status: "InProgress"},
+		{Status: "RegistrationError"},
+	}}
+	c = newClient(api, Config{PollInterval: time.Millisecond})
+
+	_, err = c.EnsureIdentityRegistered(context.Background(), "")
+	assert.ErrorIs(t, err, ErrRegistrationFailed)
+	assert.Empty(t, api.registered, "registration in progress should not be restarted")
+}
+
+func TestClient_ConnectBest(t *testing.T) {
+	api := &fakeTequilapi{}
+	c := newClient(api, Config{})
+
+	conn, err := c.ConnectBest("0x1", Filter{CountryCode: "DE", IPType: "residential"})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultServiceType, api.serviceType)
+	assert.Equal(t, contract.ConnectionCreateFilter{CountryCode: "DE", IPType: "residential"}, api.filter)
+	assert.Equal(t, Connection{SessionID: "session", ProviderID: "0x2", ServiceType: DefaultServiceType, Country: "DE", Status: "Connected"}, conn)
+
+	assert.NoError(t, c.Disconnect(conn.ID))
+	assert.Equal(t, []int{0}, api.destroyed)
+}
+
+func TestClient_Events(t *testing.T) {
+	api := &fakeTequilapi{connStatuses: []string{"Connecting", "Connected"}}
+	c := newClient(api, Config{PollInterval: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := c.Events(ctx, 0)
+	first := (<-events).(StateEvent)
+	assert.Equal(t, "Connecting", first.Connection.Status)
+	assert.Empty(t, first.Previous)
+
+	second := (<-events).(StateEvent)
+	assert.Equal(t, "Connected", second.Connection.Status)
+	assert.Equal(t, "Connecting", second.Previous)
+
+	stats := (<-events).(StatisticsEvent)
+	assert.Equal(t, Statistics{BytesSent: 10, BytesReceived: 20, Duration: 3 * time.Second, TokensSpent: big.NewInt(5)}, stats.Statistics)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestClient_StreamStats(t *testing.T) {
+	api := &fakeTequilapi{connStatuses: []string{"Connected"}}
+	c := newClient(api, Config{PollInterval: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stats := c.StreamStats(ctx, 0)
+	s, ok := <-stats
+	require.True(t, ok)
+	assert.Equal(t, uint64(10), s.BytesSent)
+
+	cancel()
+	for range stats {
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -41,7 +42,7 @@ type Manager struct {
 }
 
 // ProvideConfig provides the session configuration
-func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*service.ConfigParams, error) {
+func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn, _ *zerolog.Logger) (*service.ConfigParams, error) {
 	return &service.ConfigParams{}, nil
 }
 
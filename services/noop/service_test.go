@@ -29,7 +29,7 @@ var _ service.Service = NewManager()
 
 func Test_Manager_ProvideConfig(t *testing.T) {
 	manager := NewManager()
-	sessionConfig, err := manager.ProvideConfig("", nil, nil)
+	sessionConfig, err := manager.ProvideConfig("", nil, nil, nil)
 	assert.NoError(t, err)
 	assert.Nil(t, sessionConfig.SessionServiceConfig)
 	assert.Nil(t, sessionConfig.SessionDestroyCallback)
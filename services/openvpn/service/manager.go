@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/go-openvpn/openvpn"
@@ -143,11 +144,13 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 		EnableDNSRedirect: m.dnsOK,
 		DNSIP:             m.dnsIP,
 		DNSPort:           dnsPort,
+		// Sessions share the OpenVPN server, so only the exemptions of every session apply.
+		AllowedNetworks: nat.DefaultAllowedNetworks(),
 	}); err != nil {
 		return fmt.Errorf("failed to setup NAT/firewall rules: %w", err)
 	}
 
-	s := shaper.New(m.bus)
+	s := shaper.New(m.bus, m.serviceOptions.BandwidthSchedule)
 	err = s.Start(m.openvpnProcess.DeviceName())
 	if err != nil {
 		log.Error().Err(err).Msg("Could not start traffic shaper")
@@ -174,7 +177,7 @@ func (m *Manager) Stop() error {
 }
 
 // ProvideConfig takes session creation config from end consumer and provides the service configuration to the end consumer
-func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn) (*service.ConfigParams, error) {
+func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn, logger *zerolog.Logger) (*service.ConfigParams, error) {
 	if m.vpnServerPort == 0 {
 		return nil, errors.New("service port not initialized")
 	}
@@ -201,12 +204,12 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	}
 
 	destroy := func() {
-		log.Info().Msgf("Cleaning up session %s", sessionID)
+		logger.Info().Msgf("Cleaning up session %s", sessionID)
 
 		sessionClients := m.openvpnClients.GetSessionClients(session.ID(sessionID))
 		for clientID := range sessionClients {
 			if err := m.openvpnAuth.ClientKill(clientID); err != nil {
-				log.Error().Err(err).Msgf("Cleaning up session %s failed. Error disconnecting Openvpn client %d", sessionID, clientID)
+				logger.Error().Err(err).Msgf("Cleaning up session %s failed. Error disconnecting Openvpn client %d", sessionID, clientID)
 			}
 		}
 	}
@@ -227,9 +230,9 @@ func (m *Manager) startServer() error {
 	)
 
 	openvpnFilterDeny := stringutil.Split(config.GetString(config.FlagFirewallProtectedNetworks), ',')
-	var openvpnFilterAllow []string
+	openvpnFilterAllow := stringutil.Split(config.GetString(config.FlagFirewallAllowedNetworks), ',')
 	if m.dnsOK {
-		openvpnFilterAllow = []string{m.dnsIP.String()}
+		openvpnFilterAllow = append(openvpnFilterAllow, m.dnsIP.String())
 	}
 
 	stateChannel := make(chan openvpn.State, 10)
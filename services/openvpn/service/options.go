@@ -22,6 +22,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/rs/zerolog/log"
 )
 
@@ -31,26 +32,43 @@ type Options struct {
 	Port     int    `json:"port"`
 	Subnet   string `json:"subnet"`
 	Netmask  string `json:"netmask"`
+	// BandwidthSchedule limits service bandwidth depending on the time of day.
+	BandwidthSchedule shaper.Schedule `json:"bandwidth_schedule,omitempty"`
 }
 
 // GetOptions returns effective OpenVPN service options from application configuration.
-func GetOptions() Options {
+func GetOptions() (Options, error) {
+	schedule, err := shaper.ConfiguredSchedule()
+	if err != nil {
+		return Options{}, err
+	}
+
 	return Options{
 		Protocol: config.GetString(config.FlagOpenvpnProtocol),
 		Port:     config.GetInt(config.FlagOpenvpnPort),
 		Subnet:   config.GetString(config.FlagOpenvpnSubnet),
 		Netmask:  config.GetString(config.FlagOpenvpnNetmask),
-	}
+
+		BandwidthSchedule: schedule,
+	}, nil
+}
+
+// ShaperSchedule returns time-of-day bandwidth limits of the service.
+func (o Options) ShaperSchedule() shaper.Schedule {
+	return o.BandwidthSchedule
 }
 
 // ParseJSONOptions function fills in OpenVPN options from JSON request, falling back to configured options for
 // missing values
 func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
-	var requestOptions = GetOptions()
+	requestOptions, err := GetOptions()
+	if err != nil {
+		return nil, err
+	}
 	if request == nil {
 		return requestOptions, nil
 	}
-	err := json.Unmarshal(*request, &requestOptions)
+	err = json.Unmarshal(*request, &requestOptions)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to parse options from request, using effective options")
 		return &Options{}, err
@@ -53,15 +53,15 @@ func Types() []string {
 func TypeConfiguredOptions(serviceType string) (service.Options, error) {
 	switch serviceType {
 	case openvpn.ServiceType:
-		return openvpn_service.GetOptions(), nil
+		return openvpn_service.GetOptions()
 	case wireguard.ServiceType:
-		return wireguard_service.GetOptions(), nil
+		return wireguard_service.GetOptions()
 	case noop.ServiceType:
 		return noop.GetOptions(), nil
 	case scraping.ServiceType:
-		return wireguard_service.GetOptions(), nil
+		return wireguard_service.GetOptions()
 	case datatransfer.ServiceType:
-		return wireguard_service.GetOptions(), nil
+		return wireguard_service.GetOptions()
 	default:
 		return nil, errors.Errorf("unknown service type: %q", serviceType)
 	}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wireguard
+
+import "sync/atomic"
+
+// Backend identifies WireGuard implementation used for tunnels.
+type Backend string
+
+const (
+	// BackendAuto selects kernel module when it is available, userspace implementation otherwise.
+	BackendAuto = Backend("auto")
+	// BackendKernel is the WireGuard kernel module.
+	BackendKernel = Backend("kernel")
+	// BackendUserspace is the wireguard-go implementation on top of TUN device.
+	BackendUserspace = Backend("userspace")
+	// BackendNetstack is the wireguard-go implementation on top of userspace network stack.
+	BackendNetstack = Backend("netstack")
+	// BackendProxy is the wireguard-go implementation exposed as a local proxy.
+	BackendProxy = Backend("proxy")
+	// BackendRemote is the WireGuard implementation managed by the supervisor.
+	BackendRemote = Backend("remote")
+)
+
+var activeBackend atomic.Value
+
+// SetActiveBackend records WireGuard implementation which was selected for tunnels.
+func SetActiveBackend(backend Backend) {
+	activeBackend.Store(backend)
+}
+
+// ActiveBackend returns WireGuard implementation which was selected for tunnels, empty if none was started yet.
+func ActiveBackend() Backend {
+	backend, _ := activeBackend.Load().(Backend)
+	return backend
+}
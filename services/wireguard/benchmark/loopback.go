@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package benchmark
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+
+	"golang.zx2c4.com/wireguard/device"
+
+	"github.com/mysteriumnetwork/node/core/benchmark"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/bind"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/netstack"
+	"github.com/mysteriumnetwork/node/services/wireguard/key"
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+)
+
+const sinkPort = 5201
+
+var (
+	providerIP = netip.MustParseAddr("10.182.0.1")
+	consumerIP = netip.MustParseAddr("10.182.0.2")
+)
+
+type loopbackTunnel struct {
+	provider *device.Device
+	consumer *device.Device
+
+	consumerNet *netstack.Net
+	listener    net.Listener
+}
+
+// NewLoopbackTunnel creates a pair of userspace WireGuard devices connected over the loopback interface.
+func NewLoopbackTunnel() (benchmark.Tunnel, error) {
+	providerKey, err := key.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	consumerKey, err := key.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	providerPort, err := freeUDPPort()
+	if err != nil {
+		return nil, err
+	}
+	consumerPort, err := freeUDPPort()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &loopbackTunnel{}
+
+	var providerNet *netstack.Net
+	t.provider, providerNet, err = newDevice(providerIP, providerKey, providerPort, consumerKey, consumerIP, consumerPort)
+	if err != nil {
+		return nil, fmt.Errorf("could not create provider device: %w", err)
+	}
+	t.consumer, t.consumerNet, err = newDevice(consumerIP, consumerKey, consumerPort, providerKey, providerIP, providerPort)
+	if err != nil {
+		t.Close()
+		return nil, fmt.Errorf("could not create consumer device: %w", err)
+	}
+
+	t.listener, err = providerNet.ListenTCP(&net.TCPAddr{IP: providerIP.AsSlice(), Port: sinkPort})
+	if err != nil {
+		t.Close()
+		return nil, fmt.Errorf("could not listen on provider tunnel address: %w", err)
+	}
+
+	return t, nil
+}
+
+func (t *loopbackTunnel) Dial(ctx context.Context) (net.Conn, error) {
+	return t.consumerNet.DialContextTCP(ctx, &net.TCPAddr{IP: providerIP.AsSlice(), Port: sinkPort})
+}
+
+func (t *loopbackTunnel) Listener() net.Listener {
+	return t.listener
+}
+
+func (t *loopbackTunnel) Close() error {
+	if t.listener != nil {
+		t.listener.Close()
+	}
+	for _, dev := range []*device.Device{t.consumer, t.provider} {
+		if dev == nil {
+			continue
+		}
+		// Stop the peers before closing so no in-flight packet gets delivered into the closed netstack.
+		dev.Down()
+		dev.Close()
+	}
+	return nil
+}
+
+func newDevice(ip netip.Addr, privateKey string, port int, peerPrivateKey string, peerIP netip.Addr, peerPort int) (*device.Device, *netstack.Net, error) {
+	peerPublicKey, err := key.PrivateKeyToPublicKey(peerPrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tun, tnet, err := netstack.CreateNetTUN([]netip.Addr{ip}, nil, device.DefaultMTU)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create netstack device: %w", err)
+	}
+
+	cfg := wgcfg.DeviceConfig{
+		PrivateKey: privateKey,
+		ListenPort: port,
+		Peer: wgcfg.Peer{
+			PublicKey:  peerPublicKey,
+			Endpoint:   &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: peerPort},
+			AllowedIPs: []string{peerIP.String() + "/32"},
+		},
+	}
+
+	dev := device.NewDevice(tun, bind.New(), device.NewLogger(device.LogLevelSilent, ""))
+	if err := dev.IpcSetOperation(bufio.NewReader(strings.NewReader(cfg.Encode()))); err != nil {
+		dev.Close()
+		return nil, nil, fmt.Errorf("could not configure device: %w", err)
+	}
+	if err := dev.Up(); err != nil {
+		dev.Close()
+		return nil, nil, fmt.Errorf("could not bring device up: %w", err)
+	}
+
+	return dev, tnet, nil
+}
+
+func freeUDPPort() (int, error) {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return 0, fmt.Errorf("could not find free UDP port: %w", err)
+	}
+	defer conn.Close()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port, nil
+}
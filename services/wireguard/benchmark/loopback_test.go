@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package benchmark
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/core/benchmark"
+)
+
+func TestLoopbackTunnel(t *testing.T) {
+	harness := benchmark.New(500*time.Millisecond, 1)
+	harness.Register("wireguard", NewLoopbackTunnel)
+
+	results := harness.Run(context.Background(), "wireguard")
+
+	require.Len(t, results, 1)
+	assert.Empty(t, results[0].Error)
+	assert.NotZero(t, results[0].Bytes)
+}
@@ -35,6 +35,7 @@ import (
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 type startConn func(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint, error)
@@ -43,6 +44,10 @@ type startConn func(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint, error)
 type Options struct {
 	DNSScriptDir     string
 	HandshakeTimeout time.Duration
+	// KeepAlive is the interval of keepalive packets sent to the provider, zero disables them.
+	KeepAlive time.Duration
+	// RekeyTimeout is the age of the last handshake after which the tunnel is re-keyed, zero disables re-keying.
+	RekeyTimeout time.Duration
 }
 
 // NewConnection returns new WireGuard connection.
@@ -77,6 +82,12 @@ type Connection struct {
 	opts                Options
 	connEndpointFactory wg.EndpointFactory
 	handshakeWaiter     HandshakeWaiter
+
+	tunnelInfo     *connection.TunnelInfo
+	deviceConfig   *wgcfg.DeviceConfig
+	tunnelInfoLock sync.Mutex
+
+	lastRekey time.Time
 }
 
 var _ connection.Connection = &Connection{}
@@ -92,13 +103,40 @@ func (c *Connection) Statistics() (connectionstate.Statistics, error) {
 	if err != nil {
 		return connectionstate.Statistics{}, err
 	}
+
+	now := time.Now()
+	handshakeAge := stats.HandshakeAge(now)
+	c.rekeyIfStalled(now, handshakeAge)
+
 	return connectionstate.Statistics{
-		At:            time.Now(),
-		BytesSent:     stats.BytesSent,
-		BytesReceived: stats.BytesReceived,
+		At:               now,
+		BytesSent:        stats.BytesSent,
+		BytesReceived:    stats.BytesReceived,
+		LastHandshakeAge: handshakeAge,
 	}, nil
 }
 
+// rekeyIfStalled reconfigures the provider peer to force a new handshake once the last one is older than the rekey timeout.
+// Stalled tunnel does not pass traffic, so the session would otherwise be dropped only when payments time out.
+func (c *Connection) rekeyIfStalled(now time.Time, handshakeAge time.Duration) {
+	if c.opts.RekeyTimeout <= 0 || handshakeAge <= c.opts.RekeyTimeout || now.Sub(c.lastRekey) <= c.opts.RekeyTimeout {
+		return
+	}
+
+	c.tunnelInfoLock.Lock()
+	deviceConfig := c.deviceConfig
+	c.tunnelInfoLock.Unlock()
+	if deviceConfig == nil {
+		return
+	}
+
+	c.lastRekey = now
+	log.Warn().Msgf("Last handshake with provider was %s ago, re-keying the tunnel", handshakeAge.Round(time.Second))
+	if err := c.connectionEndpoint.ReconfigureConsumerMode(*deviceConfig); err != nil {
+		log.Warn().Err(err).Msg("Failed to re-key the tunnel")
+	}
+}
+
 // Start establish wireguard connection to the service provider.
 func (c *Connection) Start(ctx context.Context, options connection.ConnectOptions) error {
 	return c.start(ctx, c.startConn, options)
@@ -137,14 +175,13 @@ func (c *Connection) start(ctx context.Context, start startConn, options connect
 	}
 
 	var dnsIPs []string
-	dnsIPs, err = options.Params.DNS.ResolveIPs(config.Consumer.DNSIPs)
+	dnsIPs, err = options.ResolveDNS(config.Consumer.DNSIPs)
 	if err != nil {
 		return errors.Wrap(err, "could not resolve DNS IPs")
 	}
 
 	log.Info().Msg("Starting new connection")
-	var conn wg.ConnectionEndpoint
-	conn, err = start(wgcfg.DeviceConfig{
+	deviceConfig := wgcfg.DeviceConfig{
 		IfaceName:    "", // Interface name will be generated by connection endpoint.
 		Subnet:       config.Consumer.IPAddress,
 		PrivateKey:   c.privateKey,
@@ -154,12 +191,14 @@ func (c *Connection) start(ctx context.Context, start startConn, options connect
 		Peer: wgcfg.Peer{
 			Endpoint:               &config.Provider.Endpoint,
 			PublicKey:              config.Provider.PublicKey,
-			AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
-			KeepAlivePeriodSeconds: 18,
+			AllowedIPs:             config.AllowedIPs(),
+			KeepAlivePeriodSeconds: int(c.opts.KeepAlive.Seconds()),
 		},
 		ReplacePeers: true,
 		ProxyPort:    options.Params.ProxyPort,
-	})
+	}
+	var conn wg.ConnectionEndpoint
+	conn, err = start(deviceConfig)
 	if err != nil {
 		return errors.Wrap(err, "could not start new connection")
 	}
@@ -172,10 +211,44 @@ func (c *Connection) start(ctx context.Context, start startConn, options connect
 		return errors.Wrap(err, "failed while waiting for a peer handshake")
 	}
 
+	c.tunnelInfoLock.Lock()
+	c.deviceConfig = &deviceConfig
+	// In proxy mode the tunnel lives in userspace and is not reachable from the host network stack.
+	if options.Params.ProxyPort == 0 {
+		c.tunnelInfo = &connection.TunnelInfo{
+			ProviderIP: netutil.FirstIP(config.Consumer.IPAddress),
+			DNSServers: dnsIPs,
+		}
+	}
+	c.tunnelInfoLock.Unlock()
+
 	c.stateCh <- connectionstate.Connected
 	return nil
 }
 
+// TunnelInfo returns the addressing of the established tunnel.
+func (c *Connection) TunnelInfo() (connection.TunnelInfo, bool) {
+	c.tunnelInfoLock.Lock()
+	defer c.tunnelInfoLock.Unlock()
+
+	if c.tunnelInfo == nil {
+		return connection.TunnelInfo{}, false
+	}
+	return *c.tunnelInfo, true
+}
+
+// ExportConfig returns negotiated tunnel configuration in wg-quick format.
+// The private key of the running tunnel is never exported.
+func (c *Connection) ExportConfig() (string, error) {
+	c.tunnelInfoLock.Lock()
+	defer c.tunnelInfoLock.Unlock()
+
+	if c.deviceConfig == nil {
+		return "", connection.ErrNoConnection
+	}
+	return c.deviceConfig.QuickConfig(), nil
+}
+
 func (c *Connection) startConn(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint, error) {
 	conn, err := c.connEndpointFactory()
 	if err != nil {
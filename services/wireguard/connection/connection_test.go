@@ -51,6 +51,11 @@ func TestConnectionStartStop(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, 10, stats.BytesSent)
 	assert.EqualValues(t, 11, stats.BytesReceived)
+	exported, err := conn.ExportConfig()
+	assert.NoError(t, err)
+	assert.Contains(t, exported, "PrivateKey = <redacted>\n")
+	assert.NotContains(t, exported, conn.privateKey)
+	assert.NotContains(t, exported, "ListenPort")
 
 	// Stop connection.
 	stopCh := make(chan struct{})
@@ -69,6 +74,35 @@ func TestConnectionStartStop(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestConnectionRekeysStalledTunnel(t *testing.T) {
+	conn := newConn(t)
+	conn.opts.RekeyTimeout = 3 * time.Minute
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params:        connection.ConnectParams{DNS: "1.2.3.4"},
+		SessionConfig: sessionConfig,
+	})
+	assert.NoError(t, err)
+	defer conn.Stop()
+
+	endpoint := conn.connectionEndpoint.(*mockConnectionEndpoint)
+	endpoint.lastHandshake = time.Now().Add(-time.Minute)
+	stats, err := conn.Statistics()
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Minute, stats.LastHandshakeAge, float64(time.Second))
+	assert.Equal(t, 0, endpoint.reconfigured)
+
+	endpoint.lastHandshake = time.Now().Add(-5 * time.Minute)
+	_, err = conn.Statistics()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, endpoint.reconfigured)
+
+	// Re-key is not repeated until the new handshake had the time to complete.
+	_, err = conn.Statistics()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, endpoint.reconfigured)
+}
+
 func TestConnectionStopAfterHandshakeError(t *testing.T) {
 	conn := newConn(t)
 	handshakeTimeoutErr := errors.New("handshake timeout")
@@ -140,9 +174,13 @@ func newServiceConfig() wg.ServiceConfig {
 	}
 }
 
-type mockConnectionEndpoint struct{}
+type mockConnectionEndpoint struct {
+	lastHandshake time.Time
+	reconfigured  int
+}
 
 func (mce *mockConnectionEndpoint) ReconfigureConsumerMode(config wgcfg.DeviceConfig) error {
+	mce.reconfigured++
 	return nil
 }
 func (mce *mockConnectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig) error { return nil }
@@ -156,7 +194,11 @@ func (mce *mockConnectionEndpoint) AddPeer(_ string, _ wgcfg.Peer) error { retur
 func (mce *mockConnectionEndpoint) RemovePeer(_ string) error            { return nil }
 func (mce *mockConnectionEndpoint) ConfigureRoutes(_ net.IP) error       { return nil }
 func (mce *mockConnectionEndpoint) PeerStats() (wgcfg.Stats, error) {
-	return wgcfg.Stats{LastHandshake: time.Now(), BytesSent: 10, BytesReceived: 11}, nil
+	lastHandshake := mce.lastHandshake
+	if lastHandshake.IsZero() {
+		lastHandshake = time.Now()
+	}
+	return wgcfg.Stats{LastHandshake: lastHandshake, BytesSent: 10, BytesReceived: 11}, nil
 }
 
 type mockHandshakeWaiter struct {
@@ -0,0 +1,387 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bind
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"sync"
+	"syscall"
+
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+	"golang.org/x/sys/unix"
+	"golang.zx2c4.com/wireguard/conn"
+)
+
+const (
+	// batchSize is the number of datagrams read by a single recvmmsg call.
+	batchSize = 32
+	// bufferSize fits any datagram of a tunnel running with jumbo frames.
+	bufferSize = 1 << 14
+)
+
+// controlSize fits the packet info control message of both address families.
+var controlSize = len(ipv6.NewControlMessage(ipv6.FlagDst | ipv6.FlagInterface))
+
+// batchBind is a WireGuard bind reading the datagrams in batches with recvmmsg to cut
+// the syscalls per packet at high throughput. The batch buffers are allocated once per socket.
+//
+// The pinned wireguard-go receives into a single buffer per call, so only the first datagram
+// of a batch is read into it directly, the rest are copied from the batch buffers.
+// It also hands the packets to the bind one at a time, so the send path stays a single sendmsg per packet.
+//
+// Like the sticky sockets of the default Linux bind, it records the local address and interface every datagram
+// was received on (IP_PKTINFO), so the replies leave a multihomed host from the address the peer talks to.
+type batchBind struct {
+	mu   sync.Mutex
+	ipv4 *net.UDPConn
+	ipv6 *net.UDPConn
+}
+
+// New returns a WireGuard bind with batched receive.
+func New() conn.Bind {
+	return &batchBind{}
+}
+
+var _ conn.Bind = (*batchBind)(nil)
+
+// Open puts the bind into a listening state on a given port and reports the actual port.
+func (b *batchBind) Open(port uint16) ([]conn.ReceiveFunc, uint16, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.ipv4 != nil || b.ipv6 != nil {
+		return nil, 0, conn.ErrBindAlreadyOpen
+	}
+
+	var tries int
+again:
+	actualPort := int(port)
+	ipv4Conn, actualPort, err := listen("udp4", actualPort)
+	if err != nil && !errors.Is(err, syscall.EAFNOSUPPORT) {
+		return nil, 0, err
+	}
+
+	// Listen on the same port as we're using for IPv4.
+	ipv6Conn, actualPort, err := listen("udp6", actualPort)
+	if port == 0 && errors.Is(err, syscall.EADDRINUSE) && tries < 100 {
+		ipv4Conn.Close()
+		tries++
+		goto again
+	}
+	if err != nil && !errors.Is(err, syscall.EAFNOSUPPORT) {
+		ipv4Conn.Close()
+		return nil, 0, err
+	}
+
+	var fns []conn.ReceiveFunc
+	if ipv4Conn != nil {
+		pc := ipv4.NewPacketConn(ipv4Conn)
+		if err := pc.SetControlMessage(ipv4.FlagDst|ipv4.FlagInterface, true); err != nil {
+			closeAll(ipv4Conn, ipv6Conn)
+			return nil, 0, err
+		}
+		fns = append(fns, newReceiver(pc, parseSource4).receive)
+	}
+	if ipv6Conn != nil {
+		pc := ipv6.NewPacketConn(ipv6Conn)
+		if err := pc.SetControlMessage(ipv6.FlagDst|ipv6.FlagInterface, true); err != nil {
+			closeAll(ipv4Conn, ipv6Conn)
+			return nil, 0, err
+		}
+		fns = append(fns, newReceiver(pc, parseSource6).receive)
+	}
+	if len(fns) == 0 {
+		return nil, 0, syscall.EAFNOSUPPORT
+	}
+
+	b.ipv4, b.ipv6 = ipv4Conn, ipv6Conn
+	return fns, uint16(actualPort), nil
+}
+
+func closeAll(conns ...*net.UDPConn) {
+	for _, c := range conns {
+		if c != nil {
+			c.Close()
+		}
+	}
+}
+
+// Close closes the bind listeners.
+func (b *batchBind) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var err4, err6 error
+	if b.ipv4 != nil {
+		err4 = b.ipv4.Close()
+		b.ipv4 = nil
+	}
+	if b.ipv6 != nil {
+		err6 = b.ipv6.Close()
+		b.ipv6 = nil
+	}
+	if err4 != nil {
+		return err4
+	}
+	return err6
+}
+
+// SetMark sets the mark for each packet sent through this bind.
+func (b *batchBind) SetMark(mark uint32) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, c := range []*net.UDPConn{b.ipv4, b.ipv6} {
+		if c == nil {
+			continue
+		}
+		if err := setMark(c, mark); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Send writes a packet to the given endpoint, from the local address the endpoint was last heard on.
+func (b *batchBind) Send(buf []byte, endpoint conn.Endpoint) error {
+	ep, ok := endpoint.(*stickyEndpoint)
+	if !ok {
+		return conn.ErrWrongEndpointType
+	}
+	dst, src, ifIndex := ep.addresses()
+
+	b.mu.Lock()
+	c := b.ipv4
+	if dst.Addr().Is6() {
+		c = b.ipv6
+	}
+	b.mu.Unlock()
+
+	if c == nil {
+		return syscall.EAFNOSUPPORT
+	}
+
+	oob := sourceControlMessage(src, ifIndex)
+	_, _, err := c.WriteMsgUDPAddrPort(buf, oob, dst)
+	if oob != nil && errors.Is(err, unix.EINVAL) {
+		// The source address is gone, e.g. it was removed from the interface, let the kernel pick one.
+		ep.ClearSrc()
+		_, _, err = c.WriteMsgUDPAddrPort(buf, nil, dst)
+	}
+	return err
+}
+
+// ParseEndpoint creates a new endpoint from a string.
+func (b *batchBind) ParseEndpoint(s string) (conn.Endpoint, error) {
+	addrPort, err := netip.ParseAddrPort(s)
+	return &stickyEndpoint{dst: addrPort}, err
+}
+
+// stickyEndpoint is a peer address together with the local address and interface it was last heard on.
+type stickyEndpoint struct {
+	dst netip.AddrPort
+
+	mu      sync.Mutex
+	src     netip.Addr
+	ifIndex int
+}
+
+var _ conn.Endpoint = (*stickyEndpoint)(nil)
+
+func (e *stickyEndpoint) addresses() (netip.AddrPort, netip.Addr, int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.dst, e.src, e.ifIndex
+}
+
+// ClearSrc clears the source address.
+func (e *stickyEndpoint) ClearSrc() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.src, e.ifIndex = netip.Addr{}, 0
+}
+
+// SrcIP returns the local source address.
+func (e *stickyEndpoint) SrcIP() netip.Addr {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.src
+}
+
+// SrcToString returns the local source address.
+func (e *stickyEndpoint) SrcToString() string {
+	if src := e.SrcIP(); src.IsValid() {
+		return src.String()
+	}
+	return ""
+}
+
+// DstIP returns the peer address.
+func (e *stickyEndpoint) DstIP() netip.Addr {
+	return e.dst.Addr()
+}
+
+// DstToString returns the peer address and port.
+func (e *stickyEndpoint) DstToString() string {
+	return e.dst.String()
+}
+
+// DstToBytes returns the peer address and port for the mac2 cookie calculations.
+func (e *stickyEndpoint) DstToBytes() []byte {
+	b, _ := e.dst.MarshalBinary()
+	return b
+}
+
+// sourceControlMessage returns the packet info pinning the source of the sent datagram, nil if the source is unknown.
+func sourceControlMessage(src netip.Addr, ifIndex int) []byte {
+	switch {
+	case src.Is4():
+		return (&ipv4.ControlMessage{Src: src.AsSlice(), IfIndex: ifIndex}).Marshal()
+	case src.Is6():
+		return (&ipv6.ControlMessage{Src: src.AsSlice(), IfIndex: ifIndex}).Marshal()
+	default:
+		return nil
+	}
+}
+
+// parseSource4 returns the local address and interface the IPv4 datagram was received on.
+func parseSource4(oob []byte) (netip.Addr, int) {
+	var cm ipv4.ControlMessage
+	if err := cm.Parse(oob); err != nil {
+		return netip.Addr{}, 0
+	}
+	addr, ok := netip.AddrFromSlice(cm.Dst.To4())
+	if !ok {
+		return netip.Addr{}, 0
+	}
+	return addr, cm.IfIndex
+}
+
+// parseSource6 returns the local address and interface the IPv6 datagram was received on.
+func parseSource6(oob []byte) (netip.Addr, int) {
+	var cm ipv6.ControlMessage
+	if err := cm.Parse(oob); err != nil {
+		return netip.Addr{}, 0
+	}
+	addr, ok := netip.AddrFromSlice(cm.Dst.To16())
+	if !ok {
+		return netip.Addr{}, 0
+	}
+	return addr, cm.IfIndex
+}
+
+type batchReader interface {
+	ReadBatch(ms []ipv4.Message, flags int) (int, error)
+}
+
+// receiver serves the datagrams of the last read batch one by one.
+// WireGuard calls each receive function from a single goroutine, so it needs no locking.
+// The first message of the batch has no buffer of its own, it is read into the buffer of the caller.
+type receiver struct {
+	conn        batchReader
+	parseSource func(oob []byte) (netip.Addr, int)
+	messages    []ipv4.Message
+	next        int
+	count       int
+}
+
+func newReceiver(conn batchReader, parseSource func(oob []byte) (netip.Addr, int)) *receiver {
+	messages := make([]ipv4.Message, batchSize)
+	messages[0].Buffers = [][]byte{nil}
+	for i := range messages {
+		if i > 0 {
+			messages[i].Buffers = [][]byte{make([]byte, bufferSize)}
+		}
+		messages[i].OOB = make([]byte, controlSize)
+	}
+
+	return &receiver{
+		conn:        conn,
+		parseSource: parseSource,
+		messages:    messages,
+	}
+}
+
+func (r *receiver) receive(buf []byte) (int, conn.Endpoint, error) {
+	for {
+		for r.next < r.count {
+			msg := &r.messages[r.next]
+			r.next++
+
+			if endpoint, ok := r.messageEndpoint(msg); ok {
+				return copy(buf, msg.Buffers[0][:msg.N]), endpoint, nil
+			}
+		}
+
+		r.messages[0].Buffers[0] = buf
+		count, err := r.conn.ReadBatch(r.messages, 0)
+		r.messages[0].Buffers[0] = nil
+		if err != nil {
+			return 0, nil, err
+		}
+		if count == 0 {
+			continue
+		}
+
+		r.next, r.count = 1, count
+		if endpoint, ok := r.messageEndpoint(&r.messages[0]); ok {
+			return r.messages[0].N, endpoint, nil
+		}
+	}
+}
+
+// messageEndpoint returns the sender of the received message, false if the message has to be dropped.
+func (r *receiver) messageEndpoint(msg *ipv4.Message) (conn.Endpoint, bool) {
+	addr, ok := msg.Addr.(*net.UDPAddr)
+	if !ok || msg.Flags&unix.MSG_TRUNC != 0 {
+		return nil, false
+	}
+
+	addrPort := addr.AddrPort()
+	endpoint := &stickyEndpoint{dst: netip.AddrPortFrom(addrPort.Addr().Unmap(), addrPort.Port())}
+	endpoint.src, endpoint.ifIndex = r.parseSource(msg.OOB[:msg.NN])
+	return endpoint, true
+}
+
+func listen(network string, port int) (*net.UDPConn, int, error) {
+	c, err := net.ListenUDP(network, &net.UDPAddr{Port: port})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return c, c.LocalAddr().(*net.UDPAddr).Port, nil
+}
+
+func setMark(c *net.UDPConn, mark uint32) error {
+	raw, err := c.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_MARK, int(mark))
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package bind
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/ipv4"
+	"golang.zx2c4.com/wireguard/conn"
+)
+
+func TestBatchBind_SendReceive(t *testing.T) {
+	sender, receiver := New(), New()
+
+	_, _, err := sender.Open(0)
+	require.NoError(t, err)
+	defer sender.Close()
+
+	fns, port, err := receiver.Open(0)
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	endpoint, err := sender.ParseEndpoint(fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err)
+
+	packets := 2*batchSize + 1
+	for i := 0; i < packets; i++ {
+		require.NoError(t, sender.Send([]byte(fmt.Sprintf("packet-%d", i)), endpoint))
+	}
+
+	buf := make([]byte, 1500)
+	for i := 0; i < packets; i++ {
+		n, from, err := fns[0](buf)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("packet-%d", i), string(buf[:n]))
+		assert.Equal(t, "127.0.0.1", from.DstIP().String())
+	}
+}
+
+func TestBatchBind_RepliesFromReceivingAddress(t *testing.T) {
+	peer, local := New(), New()
+
+	peerFns, peerPort, err := peer.Open(0)
+	require.NoError(t, err)
+	defer peer.Close()
+
+	fns, port, err := local.Open(0)
+	require.NoError(t, err)
+	defer local.Close()
+
+	// The local bind listens on all addresses, the peer talks to the one which is not the default source.
+	endpoint, err := peer.ParseEndpoint(fmt.Sprintf("127.0.0.2:%d", port))
+	require.NoError(t, err)
+	require.NoError(t, peer.Send([]byte("ping"), endpoint))
+
+	buf := make([]byte, 1500)
+	_, from, err := fns[0](buf)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.2", from.SrcIP().String())
+	assert.Equal(t, fmt.Sprintf("127.0.0.1:%d", peerPort), from.DstToString())
+
+	require.NoError(t, local.Send([]byte("pong"), from))
+	n, reply, err := peerFns[0](buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf[:n]))
+	assert.Equal(t, fmt.Sprintf("127.0.0.2:%d", port), reply.DstToString())
+}
+
+func TestBatchBind_ReceiveAfterClose(t *testing.T) {
+	b := New()
+	fns, _, err := b.Open(0)
+	require.NoError(t, err)
+	require.NoError(t, b.Close())
+
+	for _, fn := range fns {
+		_, _, err := fn(make([]byte, 1500))
+		assert.ErrorIs(t, err, net.ErrClosed)
+	}
+}
+
+func TestBatchBind_OpenTwice(t *testing.T) {
+	b := New()
+	_, _, err := b.Open(0)
+	require.NoError(t, err)
+	defer b.Close()
+
+	_, _, err = b.Open(0)
+	assert.ErrorIs(t, err, conn.ErrBindAlreadyOpen)
+}
+
+type mockBatchReader struct {
+	batches [][]string
+}
+
+func (m *mockBatchReader) ReadBatch(ms []ipv4.Message, _ int) (int, error) {
+	batch := m.batches[0]
+	m.batches = m.batches[1:]
+	for i, payload := range batch {
+		ms[i].N = copy(ms[i].Buffers[0], payload)
+		ms[i].Addr = &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 51820}
+		ms[i].Flags = 0
+	}
+	return len(batch), nil
+}
+
+func TestReceiver_ReadsFirstDatagramIntoCallerBuffer(t *testing.T) {
+	reader := &mockBatchReader{batches: [][]string{{"first", "second"}, {"third"}}}
+	r := newReceiver(reader, parseSource4)
+
+	var received []string
+	for i := 0; i < 3; i++ {
+		buf := make([]byte, 1500)
+		n, _, err := r.receive(buf)
+		require.NoError(t, err)
+		received = append(received, string(buf[:n]))
+	}
+
+	assert.Equal(t, []string{"first", "second", "third"}, received)
+	assert.Nil(t, r.messages[0].Buffers[0], "caller buffer must not be retained")
+}
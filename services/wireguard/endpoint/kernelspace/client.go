@@ -73,7 +73,7 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	})
 
 	if config.Peer.Endpoint != nil {
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
+		if err := netutil.AddRoutes(config.IfaceName, config.Peer.Routes()); err != nil {
 			rollback.Run()
 			return err
 		}
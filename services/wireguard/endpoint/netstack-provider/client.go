@@ -25,16 +25,18 @@ import (
 	"sync"
 
 	"github.com/rs/zerolog/log"
-	"golang.zx2c4.com/wireguard/conn"
 	"golang.zx2c4.com/wireguard/device"
 
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/bind"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/userspace"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"github.com/mysteriumnetwork/node/session/traffic"
 )
 
 type client struct {
-	mu     sync.Mutex
-	Device *device.Device
+	mu         sync.Mutex
+	Device     *device.Device
+	classifier *traffic.Classifier
 }
 
 // New create new WireGuard client in full userspace environment using netstack.
@@ -54,7 +56,7 @@ func (c *client) ConfigureDevice(cfg wgcfg.DeviceConfig) error {
 	}
 
 	logger := device.NewLogger(device.LogLevelVerbose, fmt.Sprintf("(%s) ", cfg.IfaceName))
-	wgDevice := device.NewDevice(tunnel, conn.NewDefaultBind(), logger)
+	wgDevice := device.NewDevice(tunnel, bind.New(), logger)
 
 	log.Info().Msg("Applying interface configuration")
 	if err := wgDevice.IpcSetOperation(bufio.NewReader(strings.NewReader(cfg.Encode()))); err != nil {
@@ -67,6 +69,7 @@ func (c *client) ConfigureDevice(cfg wgcfg.DeviceConfig) error {
 
 	c.mu.Lock()
 	c.Device = wgDevice
+	c.classifier = tunnel.(*netTun).classifier
 	c.mu.Unlock()
 
 	return nil
@@ -87,6 +90,11 @@ func (c *client) PeerStats(iface string) (wgcfg.Stats, error) {
 		err = statErr
 		log.Warn().Err(err).Msg("Failed to parse device stats, will try again")
 	} else {
+		c.mu.Lock()
+		if c.classifier != nil {
+			stats.Traffic = c.classifier.Counters()
+		}
+		c.mu.Unlock()
 		return stats, nil
 	}
 
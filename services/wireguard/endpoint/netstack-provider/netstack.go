@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/session/traffic"
 
 	"github.com/rs/zerolog/log"
 	"golang.org/x/time/rate"
@@ -57,7 +58,9 @@ type netTun struct {
 	localAddresses []netip.Addr
 
 	limiter           *rate.Limiter
+	classifier        *traffic.Classifier
 	privateIPv4Blocks []*net.IPNet
+	allowedIPv4Blocks []*net.IPNet
 }
 
 type (
@@ -138,7 +141,14 @@ func CreateNetTUN(localAddresses []netip.Addr, dnsPort, mtu int) (tun.Device, *N
 		limiter = rate.NewLimiter(rate.Limit(bandwidthBytes), int(bandwidthBytes))
 	}
 
+	var classifier *traffic.Classifier
+	if config.GetBool(config.FlagWireguardTrafficClassification) {
+		classifier = traffic.NewClassifier()
+	}
+
 	privateIPv4Blocks := parseCIDR(strings.Split(config.FlagFirewallProtectedNetworks.GetValue(), ","))
+	// The userspace data plane is created before the session is known, so only the exemptions of every session apply.
+	allowedIPv4Blocks := parseCIDR(strings.Split(config.GetString(config.FlagFirewallAllowedNetworks), ","))
 	dev := &netTun{
 		stack:             stack.New(opts),
 		events:            make(chan tun.Event, 10),
@@ -147,7 +157,9 @@ func CreateNetTUN(localAddresses []netip.Addr, dnsPort, mtu int) (tun.Device, *N
 		dnsPort:           dnsPort,
 		localAddresses:    localAddresses,
 		limiter:           limiter,
+		classifier:        classifier,
 		privateIPv4Blocks: privateIPv4Blocks,
+		allowedIPv4Blocks: allowedIPv4Blocks,
 	}
 
 	tcpFwd := tcp.NewForwarder(dev.stack, 0, 10000, dev.acceptTCP)
@@ -202,6 +214,7 @@ func (tun *netTun) Read(buf []byte, offset int) (int, error) {
 	if !ok {
 		return 0, os.ErrClosed
 	}
+	defer view.Release()
 
 	return view.Read(buf[offset:])
 }
@@ -212,8 +225,14 @@ func (tun *netTun) Write(buf []byte, offset int) (int, error) {
 		return 0, nil
 	}
 
+	// Packets written by WireGuard are the ones sent by consumer.
+	if tun.classifier != nil {
+		tun.classifier.Classify(packet)
+	}
+
 	pkb := stack.NewPacketBuffer(stack.PacketBufferOptions{
-		Payload: bufferv2.MakeWithData(append([]byte(nil), packet...)),
+		// Copies the packet into a pooled chunk, the buffer is reused by WireGuard after return.
+		Payload: bufferv2.MakeWithData(packet),
 	})
 
 	switch packet[0] >> 4 {
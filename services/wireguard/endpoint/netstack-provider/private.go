@@ -43,6 +43,12 @@ func (tun *netTun) isPrivateIP(ip net.IP) bool {
 		return false
 	}
 
+	for _, block := range tun.allowedIPv4Blocks {
+		if block.Contains(ip) {
+			return false
+		}
+	}
+
 	for _, block := range tun.privateIPv4Blocks {
 		if block.Contains(ip) {
 			return true
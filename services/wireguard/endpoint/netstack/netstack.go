@@ -222,7 +222,8 @@ func (tun *netTun) Write(buf []byte, offset int) (int, error) {
 	}
 
 	pkb := stack.NewPacketBuffer(stack.PacketBufferOptions{
-		Payload: bufferv2.MakeWithData(append([]byte(nil), packet...)),
+		// Copies the packet into a pooled chunk, the buffer is reused by WireGuard after return.
+		Payload: bufferv2.MakeWithData(packet),
 	})
 
 	switch packet[0] >> 4 {
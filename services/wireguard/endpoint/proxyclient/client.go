@@ -28,9 +28,9 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
-	"golang.zx2c4.com/wireguard/conn"
 	"golang.zx2c4.com/wireguard/device"
 
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/bind"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/netstack"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/userspace"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
@@ -70,7 +70,7 @@ func (c *client) ConfigureDevice(cfg wgcfg.DeviceConfig) error {
 	}
 
 	logger := device.NewLogger(device.LogLevelVerbose, fmt.Sprintf("(%s) ", cfg.IfaceName))
-	wgDevice := device.NewDevice(tunnel, conn.NewDefaultBind(), logger)
+	wgDevice := device.NewDevice(tunnel, bind.New(), logger)
 
 	log.Info().Msg("Applying interface configuration")
 	if err := wgDevice.IpcSetOperation(bufio.NewReader(strings.NewReader(cfg.Encode()))); err != nil {
@@ -30,6 +30,14 @@ import (
 
 const copyBufferSize = 128 * 1024
 
+// copyBufferPool reuses the copy buffers across the proxied connections.
+var copyBufferPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, copyBufferSize)
+		return &buf
+	},
+}
+
 func proxyHTTP1(ctx context.Context, left, right net.Conn) {
 	wg := sync.WaitGroup{}
 
@@ -163,7 +171,9 @@ func flush(flusher interface{}) bool {
 }
 
 func copyBody(wr io.Writer, body io.Reader) {
-	buf := make([]byte, copyBufferSize)
+	bufPtr := copyBufferPool.Get().(*[]byte)
+	defer copyBufferPool.Put(bufPtr)
+	buf := *bufPtr
 	for {
 		bread, readErr := body.Read(buf)
 		var writeErr error
@@ -178,7 +188,9 @@ func copyBody(wr io.Writer, body io.Reader) {
 }
 
 func copyBuffer(dst io.Writer, src io.Reader, extend func()) (written int64, err error) {
-	buf := make([]byte, copyBufferSize)
+	bufPtr := copyBufferPool.Get().(*[]byte)
+	defer copyBufferPool.Put(bufPtr)
+	buf := *bufPtr
 
 	for {
 		extend()
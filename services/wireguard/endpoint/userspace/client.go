@@ -23,11 +23,11 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
-	"golang.zx2c4.com/wireguard/conn"
 	"golang.zx2c4.com/wireguard/device"
 	"golang.zx2c4.com/wireguard/tun"
 
 	"github.com/mysteriumnetwork/node/services/wireguard/connection/dns"
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/bind"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/utils/actionstack"
 	"github.com/mysteriumnetwork/node/utils/netutil"
@@ -52,7 +52,7 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 		return errors.Wrap(err, "failed to create TUN device")
 	}
 
-	devAPI := device.NewDevice(c.tun, conn.NewDefaultBind(), device.NewLogger(device.LogLevelVerbose, "[userspace-wg]"))
+	devAPI := device.NewDevice(c.tun, bind.New(), device.NewLogger(device.LogLevelVerbose, "[userspace-wg]"))
 	c.devAPI = devAPI
 	rollback.Push(func() {
 		devAPI.Close()
@@ -65,9 +65,9 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 	}
 
 	if config.Peer.Endpoint != nil {
-		if err := netutil.AddDefaultRoute(config.IfaceName); err != nil {
+		if err := netutil.AddRoutes(config.IfaceName, config.Peer.Routes()); err != nil {
 			rollback.Run()
-			return fmt.Errorf("could not add default route for %s: %w", config.IfaceName, err)
+			return fmt.Errorf("could not add routes for %s: %w", config.IfaceName, err)
 		}
 	}
 
@@ -18,11 +18,15 @@
 package endpoint
 
 import (
+	"errors"
+	"fmt"
 	"runtime"
+	"sync"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/config"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/kernelspace"
 	netstack_provider "github.com/mysteriumnetwork/node/services/wireguard/endpoint/netstack-provider"
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/proxyclient"
@@ -42,25 +46,79 @@ type WgClient interface {
 }
 
 func newWGClient() (WgClient, error) {
+	backend, err := selectBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	var client WgClient
+	switch backend {
+	case wg.BackendProxy:
+		client, err = proxyclient.New()
+	case wg.BackendNetstack:
+		client, err = netstack_provider.New()
+	case wg.BackendRemote:
+		client, err = remoteclient.New()
+	case wg.BackendKernel:
+		client, err = kernelspace.NewWireguardClient()
+	default:
+		client, err = userspace.NewWireguardClient()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if wg.ActiveBackend() != backend {
+		log.Info().Msgf("Using WireGuard %s backend", backend)
+	}
+	wg.SetActiveBackend(backend)
+	return client, nil
+}
+
+func selectBackend() (wg.Backend, error) {
 	if config.GetBool(config.FlagProxyMode) {
-		return proxyclient.New()
+		return wg.BackendProxy, nil
 	}
 
 	if config.GetBool(config.FlagUserspace) {
-		return netstack_provider.New()
+		return wg.BackendNetstack, nil
 	}
 
 	if config.GetBool(config.FlagUserMode) {
-		return remoteclient.New()
+		return wg.BackendRemote, nil
 	}
 
-	if isKernelSpaceSupported() {
-		return kernelspace.NewWireguardClient()
+	switch backend := wg.Backend(config.GetString(config.FlagWireguardBackend)); backend {
+	case wg.BackendKernel:
+		if !kernelSpaceSupported() {
+			return "", errors.New("WireGuard kernel backend is requested, but it is not supported")
+		}
+		return wg.BackendKernel, nil
+	case wg.BackendUserspace:
+		return wg.BackendUserspace, nil
+	case wg.BackendAuto, "":
+		if kernelSpaceSupported() {
+			return wg.BackendKernel, nil
+		}
+		log.Info().Msg("Wireguard kernel space is not supported. Switching to user space implementation.")
+		return wg.BackendUserspace, nil
+	default:
+		return "", fmt.Errorf("unknown WireGuard backend: %s", backend)
 	}
+}
 
-	log.Info().Msg("Wireguard kernel space is not supported. Switching to user space implementation.")
+var (
+	kernelSpaceSupportOnce sync.Once
+	kernelSpaceSupport     bool
+)
 
-	return userspace.NewWireguardClient()
+// kernelSpaceSupported is a variable to be replaced in tests.
+var kernelSpaceSupported = func() bool {
+	// Probing creates a network interface, so it is done only once per process.
+	kernelSpaceSupportOnce.Do(func() {
+		kernelSpaceSupport = isKernelSpaceSupported()
+	})
+	return kernelSpaceSupport
 }
 
 func isKernelSpaceSupported() bool {
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/config"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+)
+
+func Test_selectBackend(t *testing.T) {
+	defer func(supported func() bool) { kernelSpaceSupported = supported }(kernelSpaceSupported)
+	defer config.Current.RemoveUser(config.FlagWireguardBackend.Name)
+
+	tests := []struct {
+		backend         string
+		kernelSupported bool
+		want            wg.Backend
+		wantErr         bool
+	}{
+		{backend: "auto", kernelSupported: true, want: wg.BackendKernel},
+		{backend: "auto", kernelSupported: false, want: wg.BackendUserspace},
+		{backend: "kernel", kernelSupported: true, want: wg.BackendKernel},
+		{backend: "kernel", kernelSupported: false, wantErr: true},
+		{backend: "userspace", kernelSupported: true, want: wg.BackendUserspace},
+		{backend: "unknown", kernelSupported: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			supported := tt.kernelSupported
+			kernelSpaceSupported = func() bool { return supported }
+			config.Current.SetUser(config.FlagWireguardBackend.Name, tt.backend)
+
+			backend, err := selectBackend()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, backend)
+		})
+	}
+}
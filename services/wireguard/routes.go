@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wireguard
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MaxRoutes is the largest number of routes a provider may advertise.
+const MaxRoutes = 64
+
+// ParseRoutes parses and validates the subnets routed through the tunnel, empty values are skipped.
+func ParseRoutes(values []string) ([]net.IPNet, error) {
+	var routes []net.IPNet
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		_, route, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidateRoute(*route); err != nil {
+			return nil, err
+		}
+		routes = append(routes, *route)
+	}
+
+	if len(routes) > MaxRoutes {
+		return nil, fmt.Errorf("too many routes: %d, at most %d are allowed", len(routes), MaxRoutes)
+	}
+	return routes, nil
+}
+
+// ValidateRoute checks that the subnet may be routed through the tunnel,
+// loopback, link-local and multicast subnets have to stay on the consumer host.
+func ValidateRoute(route net.IPNet) error {
+	switch ip := route.IP; {
+	case ip.IsLoopback():
+		return fmt.Errorf("invalid route %s: loopback subnet", route.String())
+	case ip.IsLinkLocalUnicast():
+		return fmt.Errorf("invalid route %s: link-local subnet", route.String())
+	case ip.IsMulticast():
+		return fmt.Errorf("invalid route %s: multicast subnet", route.String())
+	}
+	return nil
+}
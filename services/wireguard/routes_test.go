@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package wireguard
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes([]string{"192.168.1.0/24", " 10.1.0.0/16", "", "2001:db8::/32"})
+	assert.NoError(t, err)
+	assert.Equal(t, []net.IPNet{
+		{IP: net.ParseIP("192.168.1.0").To4(), Mask: net.CIDRMask(24, 32)},
+		{IP: net.ParseIP("10.1.0.0").To4(), Mask: net.CIDRMask(16, 32)},
+		{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(32, 128)},
+	}, routes)
+
+	for _, invalid := range []string{"invalid", "10.0.0.1", "127.0.0.0/8", "::1/128", "169.254.0.0/16", "fe80::/10", "224.0.0.0/4"} {
+		_, err := ParseRoutes([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+
+	var tooMany []string
+	for i := 0; i <= MaxRoutes; i++ {
+		tooMany = append(tooMany, fmt.Sprintf("10.%d.0.0/16", i))
+	}
+	_, err = ParseRoutes(tooMany)
+	assert.Error(t, err)
+}
@@ -19,17 +19,28 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 	"net"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/market"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
 )
 
 // Options describes options which are required to start Wireguard service.
 type Options struct {
 	Subnet net.IPNet
+	// Routes advertised to consumers to be routed through the tunnel, empty means all the traffic.
+	Routes []net.IPNet
+	// BandwidthSchedule limits service bandwidth depending on the time of day.
+	BandwidthSchedule shaper.Schedule
+	// BandwidthTiers offered to consumers at different prices, empty means a single unlimited tier.
+	BandwidthTiers []market.BandwidthTier
 }
 
 // DefaultOptions is a wireguard service configuration that will be used if no options provided.
@@ -40,44 +51,102 @@ var DefaultOptions = Options{
 	},
 }
 
-// GetOptions returns effective Wireguard service options from application configuration.
-func GetOptions() Options {
+// ConfiguredSubnet returns the subnet sessions get their addresses from according to the application configuration.
+func ConfiguredSubnet() net.IPNet {
 	_, ipnet, err := net.ParseCIDR(config.GetString(config.FlagWireguardListenSubnet))
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to parse subnet option, using default value")
-		ipnet = &DefaultOptions.Subnet
+		return DefaultOptions.Subnet
 	}
+	return *ipnet
+}
 
-	return Options{
-		Subnet: *ipnet,
+// GetOptions returns effective Wireguard service options from application configuration.
+func GetOptions() (Options, error) {
+	routes, err := wg.ParseRoutes(strings.Split(config.GetString(config.FlagWireguardRoutes), ","))
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid %s option: %w", config.FlagWireguardRoutes.Name, err)
+	}
+
+	tiers, err := market.ParseBandwidthTiers(config.GetString(config.FlagWireguardBandwidthTiers))
+	if err != nil {
+		return Options{}, fmt.Errorf("invalid %s option: %w", config.FlagWireguardBandwidthTiers.Name, err)
 	}
+	schedule, err := shaper.ConfiguredSchedule()
+	if err != nil {
+		return Options{}, err
+	}
+	if len(tiers) > 0 && !shaper.Supported() {
+		log.Warn().Msg("Bandwidth tiers are not advertised, as bandwidth limits are not supported on this platform")
+	}
+
+	return Options{
+		Subnet:            ConfiguredSubnet(),
+		Routes:            routes,
+		BandwidthSchedule: schedule,
+		BandwidthTiers:    tiers,
+	}, nil
 }
 
 // ParseJSONOptions function fills in Wireguard options from JSON request
 func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
-	requestOptions := GetOptions()
+	requestOptions, err := GetOptions()
+	if err != nil {
+		return nil, err
+	}
 	if request == nil {
 		return requestOptions, nil
 	}
 
 	opts := DefaultOptions
-	err := json.Unmarshal(*request, &opts)
+	opts.Routes = requestOptions.Routes
+	opts.BandwidthSchedule = requestOptions.BandwidthSchedule
+	opts.BandwidthTiers = requestOptions.BandwidthTiers
+	err = json.Unmarshal(*request, &opts)
 	return opts, err
 }
 
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (o Options) MarshalJSON() ([]byte, error) {
+	var routes []string
+	for _, route := range o.Routes {
+		routes = append(routes, route.String())
+	}
+
 	return json.Marshal(&struct {
-		Subnet string `json:"subnet"`
+		Subnet            string                 `json:"subnet"`
+		Routes            []string               `json:"routes,omitempty"`
+		BandwidthSchedule shaper.Schedule        `json:"bandwidth_schedule,omitempty"`
+		BandwidthTiers    []market.BandwidthTier `json:"bandwidth_tiers,omitempty"`
 	}{
-		Subnet: o.Subnet.String(),
+		Subnet:            o.Subnet.String(),
+		Routes:            routes,
+		BandwidthSchedule: o.BandwidthSchedule,
+		BandwidthTiers:    o.BandwidthTiers,
 	})
 }
 
+// ShaperSchedule returns time-of-day bandwidth limits of the service.
+func (o Options) ShaperSchedule() shaper.Schedule {
+	return o.BandwidthSchedule
+}
+
+// ProposalBandwidthTiers returns bandwidth tiers advertised in the service proposal,
+// none if the tier limits can not be enforced on this platform.
+func (o Options) ProposalBandwidthTiers() []market.BandwidthTier {
+	if !shaper.Supported() {
+		return nil
+	}
+	return o.BandwidthTiers
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
-		Subnet string `json:"subnet"`
+		Subnet            string                  `json:"subnet"`
+		Routes            []string                `json:"routes"`
+		BandwidthSchedule *shaper.Schedule        `json:"bandwidth_schedule"`
+		BandwidthTiers    *[]market.BandwidthTier `json:"bandwidth_tiers"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -92,5 +161,21 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		o.Subnet = *ipnet
 	}
 
+	if options.Routes != nil {
+		routes, err := wg.ParseRoutes(options.Routes)
+		if err != nil {
+			return err
+		}
+		o.Routes = routes
+	}
+
+	if options.BandwidthSchedule != nil {
+		o.BandwidthSchedule = *options.BandwidthSchedule
+	}
+
+	if options.BandwidthTiers != nil {
+		o.BandwidthTiers = *options.BandwidthTiers
+	}
+
 	return nil
 }
@@ -22,11 +22,14 @@ import (
 	"flag"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/urfave/cli/v2"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/market"
 )
 
 func Test_ParseJSONOptions_HandlesNil(t *testing.T) {
@@ -60,6 +63,82 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 	}, options)
 }
 
+func Test_ParseJSONOptions_Routes(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"routes":["192.168.1.0/24"," 10.1.0.0/16"]}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []net.IPNet{
+		{IP: net.ParseIP("192.168.1.0").To4(), Mask: net.IPv4Mask(255, 255, 255, 0)},
+		{IP: net.ParseIP("10.1.0.0").To4(), Mask: net.IPv4Mask(255, 255, 0, 0)},
+	}, options.(Options).Routes)
+
+	request = json.RawMessage(`{"routes":["invalid"]}`)
+	_, err = ParseJSONOptions(&request)
+	assert.Error(t, err)
+}
+
+func Test_GetOptions_FailsOnInvalidRoutes(t *testing.T) {
+	configureDefaults()
+	config.Current.SetUser(config.FlagWireguardRoutes.Name, "127.0.0.0/8")
+	defer config.Current.RemoveUser(config.FlagWireguardRoutes.Name)
+
+	_, err := GetOptions()
+	assert.Error(t, err)
+
+	_, err = ParseJSONOptions(nil)
+	assert.Error(t, err)
+}
+
+func Test_ParseJSONOptions_BandwidthSchedule(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"bandwidth_schedule":"09:00-18:00=2500"}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, shaper.Schedule{
+		{From: 9 * time.Hour, To: 18 * time.Hour, Bandwidth: 2500},
+	}, options.(Options).BandwidthSchedule)
+
+	data, err := json.Marshal(options)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"subnet":"10.182.0.0/16","bandwidth_schedule":"09:00-18:00=2500"}`, string(data))
+}
+
+func Test_ParseJSONOptions_BandwidthTiers(t *testing.T) {
+	configureDefaults()
+	request := json.RawMessage(`{"bandwidth_tiers":[{"name":"basic","bandwidth":1250,"price_percent":50}]}`)
+	options, err := ParseJSONOptions(&request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []market.BandwidthTier{
+		{Name: "basic", Bandwidth: 1250, PricePercent: 50},
+	}, options.(Options).BandwidthTiers)
+
+	data, err := json.Marshal(options)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"subnet":"10.182.0.0/16","bandwidth_tiers":[{"name":"basic","bandwidth":1250,"price_percent":50}]}`, string(data))
+}
+
+func Test_GetOptions_FailsOnInvalidBandwidthTiers(t *testing.T) {
+	configureDefaults()
+	config.Current.SetUser(config.FlagWireguardBandwidthTiers.Name, "basic:fast:50")
+	defer config.Current.RemoveUser(config.FlagWireguardBandwidthTiers.Name)
+
+	_, err := GetOptions()
+	assert.Error(t, err)
+}
+
+func Test_GetOptions_FailsOnInvalidBandwidthSchedule(t *testing.T) {
+	configureDefaults()
+	config.Current.SetUser(config.FlagShaperSchedule.Name, "09:00-18:00=fast")
+	defer config.Current.RemoveUser(config.FlagShaperSchedule.Name)
+
+	_, err := GetOptions()
+	assert.Error(t, err)
+}
+
 func configureDefaults() {
 	ctx := emptyContext()
 	config.ParseFlagsServiceWireguard(ctx)
@@ -25,11 +25,13 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/shaper"
+	"github.com/mysteriumnetwork/node/core/speedtest"
 	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
@@ -39,6 +41,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
@@ -50,8 +53,11 @@ func NewManager(
 	eventBus eventbus.EventBus,
 	trafficFirewall firewall.IncomingTrafficFirewall,
 	resourcesAllocator *resources.Allocator,
+	fairScheduler *shaper.FairScheduler,
+	tunnelOptions TunnelOptions,
 ) *Manager {
 	return &Manager{
+		fairScheduler:      fairScheduler,
 		done:               make(chan struct{}),
 		resourcesAllocator: resourcesAllocator,
 		ipResolver:         ipResolver,
@@ -64,6 +70,10 @@ func NewManager(
 		},
 		country:        country,
 		sessionCleanup: map[string]func(){},
+		sessionCaps:    map[string]uint64{},
+		sessionLimits:  map[string]*shaper.CapLimiter{},
+		sessionNets:    map[string][]net.IPNet{},
+		tunnelOptions:  tunnelOptions,
 	}
 }
 
@@ -77,6 +87,7 @@ type Manager struct {
 	natService      nat.NATService
 	eventBus        eventbus.EventBus
 	trafficFirewall firewall.IncomingTrafficFirewall
+	fairScheduler   *shaper.FairScheduler
 
 	dnsOK    bool
 	dnsPort  int
@@ -89,14 +100,85 @@ type Manager struct {
 	serviceInstance  *service.Instance
 	sessionCleanup   map[string]func()
 	sessionCleanupMu sync.Mutex
+	sessionCaps      map[string]uint64
+	sessionLimits    map[string]*shaper.CapLimiter
+	sessionCapsMu    sync.Mutex
+	sessionNets      map[string][]net.IPNet
+	sessionNetsMu    sync.Mutex
 
 	country    string
 	outboundIP string
+
+	tunnelOptions TunnelOptions
+}
+
+// TunnelOptions describes the keepalive behaviour of provider WireGuard tunnels.
+type TunnelOptions struct {
+	// KeepAlive is the interval of keepalive packets sent to consumers, zero disables them.
+	KeepAlive time.Duration
+	// RekeyTimeout is the age of the last handshake after which the tunnel is reported stalled, zero disables reporting.
+	RekeyTimeout time.Duration
+}
+
+// CanCapBandwidth reports whether the session bandwidth limits are enforced on this platform.
+func (m *Manager) CanCapBandwidth() bool {
+	return shaper.Supported()
+}
+
+// CapSessionBandwidth limits bandwidth of the session, the change applies to the running tunnel of the session too.
+func (m *Manager) CapSessionBandwidth(sessionID string, bandwidth uint64) {
+	m.sessionCapsMu.Lock()
+	defer m.sessionCapsMu.Unlock()
+
+	if limiter, ok := m.sessionLimits[sessionID]; ok {
+		limiter.SetCap(bandwidth)
+	}
+	if bandwidth == 0 {
+		delete(m.sessionCaps, sessionID)
+		return
+	}
+	m.sessionCaps[sessionID] = bandwidth
+}
+
+// sessionLimiter creates the limiter of the session tunnel following the session bandwidth cap.
+func (m *Manager) sessionLimiter(sessionID string, schedule shaper.Schedule) *shaper.CapLimiter {
+	m.sessionCapsMu.Lock()
+	defer m.sessionCapsMu.Unlock()
+
+	limiter := shaper.NewCapLimiter(schedule, m.sessionCaps[sessionID])
+	m.sessionLimits[sessionID] = limiter
+	return limiter
+}
+
+func (m *Manager) releaseSessionLimiter(sessionID string) {
+	m.sessionCapsMu.Lock()
+	defer m.sessionCapsMu.Unlock()
+
+	delete(m.sessionLimits, sessionID)
+}
+
+// AllowSessionNetworks exempts parts of the protected networks for the session, applied when its data plane comes up.
+func (m *Manager) AllowSessionNetworks(sessionID string, networks []net.IPNet) {
+	m.sessionNetsMu.Lock()
+	defer m.sessionNetsMu.Unlock()
+
+	if len(networks) == 0 {
+		delete(m.sessionNets, sessionID)
+		return
+	}
+	m.sessionNets[sessionID] = networks
+}
+
+func (m *Manager) sessionNetworks(sessionID string) []net.IPNet {
+	m.sessionNetsMu.Lock()
+	defer m.sessionNetsMu.Unlock()
+
+	return m.sessionNets[sessionID]
 }
 
 // ProvideConfig provides the config for consumer and handles new WireGuard connection.
-func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, remoteConn *net.UDPConn) (*service.ConfigParams, error) {
-	log.Info().Msg("Accepting new WireGuard connection")
+func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, remoteConn *net.UDPConn, logger *zerolog.Logger) (*service.ConfigParams, error) {
+	logger.Info().Msg("Accepting new WireGuard connection")
 	consumerConfig := wg.ConsumerConfig{}
 	err := json.Unmarshal(sessionConfig, &consumerConfig)
 	if err != nil {
@@ -125,6 +207,14 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		return nil, errors.Wrap(err, "could not get peer config")
 	}
 
+	var schedule shaper.Schedule
+	if m.serviceInstance != nil {
+		if options, ok := m.serviceInstance.Options.(Options); ok {
+			config.Routes = options.Routes
+			schedule = options.BandwidthSchedule
+		}
+	}
+
 	var dnsIP net.IP
 	var releaseTrafficFirewall firewall.IncomingRuleRemove
 	if m.dnsOK {
@@ -145,54 +235,76 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		ProviderExtIP:     net.ParseIP(m.outboundIP),
 		EnableDNSRedirect: m.dnsOK,
 		DNSPort:           m.dnsPort,
+		AllowedNetworks:   m.sessionNetworks(sessionID),
+		Routes:            config.Routes,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to setup NAT/firewall rules")
 	}
 
-	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
+	statsPublisher := newStatsPublisher(m.eventBus, time.Second, m.tunnelOptions.RekeyTimeout)
 	go statsPublisher.start(sessionID, conn)
 
 	ifaceName := conn.InterfaceName()
-	s := shaper.New(m.eventBus)
+	var limiter shaper.Limiter = m.sessionLimiter(sessionID, schedule)
+	var allocation *shaper.Allocation
+	if m.fairScheduler != nil {
+		allocation = m.fairScheduler.Join(sessionID, limiter)
+		limiter = allocation
+	}
+	s := shaper.New(m.eventBus, limiter)
 	err = s.Start(ifaceName)
 	if err != nil {
-		log.Error().Err(err).Msg("Could not start traffic shaper")
+		logger.Error().Err(err).Msg("Could not start traffic shaper")
+	}
+	m.eventBus.Publish(event.AppTopicSessionTunnel, event.AppEventSessionTunnel{ID: sessionID, Interface: ifaceName, Up: true})
+
+	speedtestServer, err := speedtest.Listen(netutil.FirstIP(config.Consumer.IPAddress))
+	if err != nil {
+		logger.Warn().Err(err).Msg("Could not start speedtest endpoint")
 	}
 
 	destroy := func() {
-		log.Info().Msgf("Cleaning up session %s", sessionID)
+		logger.Info().Msgf("Cleaning up session %s", sessionID)
 		m.sessionCleanupMu.Lock()
 		_, ok := m.sessionCleanup[sessionID]
 		if !ok {
-			log.Info().Msgf("Session '%s' was already cleaned up, returning without changes", sessionID)
+			logger.Info().Msgf("Session '%s' was already cleaned up, returning without changes", sessionID)
 			return
 		}
 		delete(m.sessionCleanup, sessionID)
 		m.sessionCleanupMu.Unlock()
 
 		statsPublisher.stop()
+		if speedtestServer != nil {
+			speedtestServer.Stop()
+		}
+		m.eventBus.Publish(event.AppTopicSessionTunnel, event.AppEventSessionTunnel{ID: sessionID, Interface: ifaceName})
 
 		s.Clear(ifaceName)
+		m.releaseSessionLimiter(sessionID)
+		if allocation != nil {
+			allocation.Leave()
+		}
 
 		if releaseTrafficFirewall != nil {
 			if err := releaseTrafficFirewall(); err != nil {
-				log.Warn().Err(err).Msg("failed to disable traffic blocking")
+				logger.Warn().Err(err).Msg("failed to disable traffic blocking")
 			}
 		}
 
-		log.Trace().Msg("Deleting nat rules")
+		logger.Trace().Msg("Deleting nat rules")
 		if err := m.natService.Del(natRules); err != nil {
-			log.Error().Err(err).Msg("Failed to delete NAT rules")
+			logger.Error().Err(err).Msg("Failed to delete NAT rules")
 		}
 
-		log.Trace().Msg("Stopping connection endpoint")
+		logger.Trace().Msg("Stopping connection endpoint")
 		if err := conn.Stop(); err != nil {
-			log.Error().Err(err).Msg("Failed to stop connection endpoint")
+			logger.Error().Err(err).Msg("Failed to stop connection endpoint")
 		}
 
 		if err := m.resourcesAllocator.ReleaseIPNet(providerConfig.Subnet); err != nil {
-			log.Error().Err(err).Msg("Failed to release IP network")
+			logger.Error().Err(err).Msg("Failed to release IP network")
 		}
 	}
 
@@ -226,7 +338,7 @@ func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wg
 			// Peer endpoint is set automatically by wg once client does handshake.
 			Endpoint:               nil,
 			AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
-			KeepAlivePeriodSeconds: 0,
+			KeepAlivePeriodSeconds: int(m.tunnelOptions.KeepAlive.Seconds()),
 		},
 		ReplacePeers: true,
 	}, nil
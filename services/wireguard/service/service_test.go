@@ -22,12 +22,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat"
@@ -69,12 +71,33 @@ func Test_Manager_Stop(t *testing.T) {
 func Test_Manager_ProviderConfig_FailsWhenSessionConfigIsInvalid(t *testing.T) {
 	manager := newManagerStub(pubIP, outIP, country)
 
-	params, err := manager.ProvideConfig("", nil, nil)
+	params, err := manager.ProvideConfig("", nil, nil, &log.Logger)
 
 	assert.Nil(t, params)
 	assert.Error(t, err)
 }
 
+func Test_Manager_CapSessionBandwidth_AppliesToRunningSession(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+	now := time.Now()
+
+	manager.CapSessionBandwidth("session", 1250)
+	limiter := manager.sessionLimiter("session", shaper.Schedule{{Bandwidth: 5000}})
+	changed := limiter.Changed()
+	assert.Equal(t, uint64(1250), limiter.Limit(now))
+
+	manager.CapSessionBandwidth("session", 1)
+	<-changed
+	assert.Equal(t, uint64(1), limiter.Limit(now))
+
+	manager.CapSessionBandwidth("session", 0)
+	assert.Equal(t, uint64(5000), limiter.Limit(now))
+
+	manager.releaseSessionLimiter("session")
+	manager.CapSessionBandwidth("session", 1)
+	assert.Equal(t, uint64(5000), limiter.Limit(now))
+}
+
 // usually time.Sleep call gives a chance for other goroutines to kick in important when testing async code
 func waitABit() {
 	time.Sleep(10 * time.Millisecond)
@@ -108,6 +131,8 @@ func newManagerStub(pub, out, country string) *Manager {
 		connEndpointFactory: func() (wg.ConnectionEndpoint, error) {
 			return connectionEndpointStub, nil
 		},
+		sessionCaps:   map[string]uint64{},
+		sessionLimits: map[string]*shaper.CapLimiter{},
 	}
 }
 
@@ -36,18 +36,23 @@ type statsPublisher struct {
 	done      chan struct{}
 	bus       eventbus.Publisher
 	frequency time.Duration
-	once      sync.Once
+	// stalledAfter is the age of the last handshake after which the tunnel is reported stalled, zero disables reporting.
+	stalledAfter time.Duration
+	once         sync.Once
 }
 
-func newStatsPublisher(bus eventbus.Publisher, frequency time.Duration) statsPublisher {
+func newStatsPublisher(bus eventbus.Publisher, frequency, stalledAfter time.Duration) statsPublisher {
 	return statsPublisher{
-		done:      make(chan struct{}),
-		bus:       bus,
-		frequency: frequency,
+		done:         make(chan struct{}),
+		bus:          bus,
+		frequency:    frequency,
+		stalledAfter: stalledAfter,
 	}
 }
 
 func (s *statsPublisher) start(sessionID string, supplier statsSupplier) {
+	dataStarted := false
+	stalled := false
 	for {
 		select {
 		case <-time.After(s.frequency):
@@ -56,10 +61,25 @@ func (s *statsPublisher) start(sessionID string, supplier statsSupplier) {
 				log.Warn().Err(err).Msg("Could not get peer statistics")
 				continue
 			}
+			if !dataStarted && stats.BytesSent+stats.BytesReceived > 0 {
+				dataStarted = true
+				s.bus.Publish(event.AppTopicDataStarted, event.AppEventDataStarted{ID: sessionID})
+			}
+			handshakeAge := stats.HandshakeAge(time.Now())
+			if s.stalledAfter > 0 && handshakeAge > s.stalledAfter != stalled {
+				stalled = !stalled
+				if stalled {
+					log.Warn().Msgf("Tunnel of session %s is stalled, last handshake was %s ago", sessionID, handshakeAge.Round(time.Second))
+				} else {
+					log.Info().Msgf("Tunnel of session %s recovered", sessionID)
+				}
+			}
 			s.bus.Publish(event.AppTopicDataTransferred, event.AppEventDataTransferred{
-				ID:   sessionID,
-				Up:   stats.BytesSent,
-				Down: stats.BytesReceived,
+				ID:               sessionID,
+				Up:               stats.BytesSent,
+				Down:             stats.BytesReceived,
+				Traffic:          stats.Traffic,
+				LastHandshakeAge: handshakeAge,
 			})
 		case <-s.done:
 			log.Info().Msgf("Stopped publishing statistics for session %s", sessionID)
@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/session/traffic"
 )
 
 type fakeSupplier struct{}
@@ -35,12 +36,13 @@ func (f fakeSupplier) PeerStats() (wgcfg.Stats, error) {
 		BytesSent:     25,
 		BytesReceived: 52,
 		LastHandshake: time.Now(),
+		Traffic:       traffic.Counters{"udp/dns": {Packets: 1, Bytes: 60}},
 	}, nil
 }
 
 func Test_statsPublisher_start(t *testing.T) {
 	bus := mocks.NewEventBus()
-	publisher := newStatsPublisher(bus, time.Microsecond)
+	publisher := newStatsPublisher(bus, time.Microsecond, time.Minute)
 
 	go publisher.start("kappa", &fakeSupplier{})
 
@@ -50,8 +52,10 @@ func Test_statsPublisher_start(t *testing.T) {
 			return false
 		}
 		evt, ok := lastEvt.(event.AppEventDataTransferred)
-		assert.True(t, ok)
-		return evt.ID == "kappa" && evt.Down == 52 && evt.Up == 25
+		if !ok {
+			return false
+		}
+		return evt.ID == "kappa" && evt.Down == 52 && evt.Up == 25 && evt.Traffic["udp/dns"].Packets == 1
 	}, 2*time.Second, 10*time.Millisecond)
 
 	publisher.stop()
@@ -65,3 +69,27 @@ func Test_statsPublisher_start(t *testing.T) {
 		return bus.Pop() != nil
 	}, time.Millisecond, time.Microsecond)
 }
+
+func Test_statsPublisher_PublishesDataStartedOnce(t *testing.T) {
+	bus := mocks.NewEventBus()
+	publisher := newStatsPublisher(bus, time.Microsecond, time.Minute)
+
+	go publisher.start("kappa", &fakeSupplier{})
+
+	countStarted := func() int {
+		count := 0
+		for _, e := range bus.GetEventHistory() {
+			if e.Topic == event.AppTopicDataStarted {
+				count++
+			}
+		}
+		return count
+	}
+
+	assert.Eventually(t, func() bool {
+		return len(bus.GetEventHistory()) > 5
+	}, 2*time.Second, 10*time.Millisecond)
+	publisher.stop()
+
+	assert.Equal(t, 1, countStarted())
+}
@@ -40,6 +40,21 @@ type ServiceConfig struct {
 		IPAddress net.IPNet
 		DNSIPs    string
 	}
+	// Routes advertised by the provider to be routed through the tunnel, empty means all the traffic.
+	Routes []net.IPNet
+}
+
+// AllowedIPs returns the subnets which consumer should route through the tunnel.
+func (s ServiceConfig) AllowedIPs() []string {
+	if len(s.Routes) == 0 {
+		return []string{"0.0.0.0/0", "::/0"}
+	}
+
+	allowedIPs := make([]string, len(s.Routes))
+	for i, route := range s.Routes {
+		allowedIPs[i] = route.String()
+	}
+	return allowedIPs
 }
 
 // ConsumerConfig is used for sending the public key and IP from consumer to provider.
@@ -61,12 +76,18 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 		DNSIPs    string `json:"dns_ips"`
 	}
 
+	var routes []string
+	for _, route := range s.Routes {
+		routes = append(routes, route.String())
+	}
+
 	return json.Marshal(&struct {
 		LocalPort  int      `json:"local_port"`
 		RemotePort int      `json:"remote_port"`
 		Ports      []int    `json:"ports"`
 		Provider   provider `json:"provider"`
 		Consumer   consumer `json:"consumer"`
+		Routes     []string `json:"routes,omitempty"`
 	}{
 		Ports:      s.Ports,
 		LocalPort:  s.LocalPort,
@@ -79,6 +100,7 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 			IPAddress: s.Consumer.IPAddress.String(),
 			DNSIPs:    s.Consumer.DNSIPs,
 		},
+		Routes: routes,
 	})
 }
 
@@ -98,6 +120,7 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		Ports      []int    `json:"ports"`
 		Provider   provider `json:"provider"`
 		Consumer   consumer `json:"consumer"`
+		Routes     []string `json:"routes"`
 	}
 
 	if err := json.Unmarshal(data, &config); err != nil {
@@ -113,6 +136,11 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	routes, err := ParseRoutes(config.Routes)
+	if err != nil {
+		return err
+	}
+
 	s.Ports = config.Ports
 	s.LocalPort = config.LocalPort
 	s.RemotePort = config.RemotePort
@@ -121,6 +149,7 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 	s.Consumer.DNSIPs = config.Consumer.DNSIPs
 	s.Consumer.IPAddress = *ipnet
 	s.Consumer.IPAddress.IP = ip
+	s.Routes = routes
 
 	return nil
 }
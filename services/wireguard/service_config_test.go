@@ -89,3 +89,22 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expecteConfig, actualConfig)
 }
+
+func TestServiceConfig_Routes(t *testing.T) {
+	configJSON := json.RawMessage(`{"provider":{"public_key":"wg1","endpoint":"127.0.0.1:51001"},"consumer":{"ip_address":"10.182.0.2/24"},"routes":["192.168.10.0/24","10.20.0.0/16"]}`)
+
+	var config ServiceConfig
+	err := json.Unmarshal(configJSON, &config)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"192.168.10.0/24", "10.20.0.0/16"}, config.AllowedIPs())
+
+	configBytes, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.Contains(t, string(configBytes), `"routes":["192.168.10.0/24","10.20.0.0/16"]`)
+
+	config.Routes = nil
+	assert.Equal(t, []string{"0.0.0.0/0", "::/0"}, config.AllowedIPs())
+
+	configJSON = json.RawMessage(`{"provider":{"public_key":"wg1","endpoint":"127.0.0.1:51001"},"consumer":{"ip_address":"10.182.0.2/24"},"routes":["127.0.0.0/8"]}`)
+	assert.Error(t, json.Unmarshal(configJSON, &config))
+}
@@ -27,6 +27,8 @@ import (
 	"time"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/session/traffic"
 )
 
 // Stats represents wireguard peer statistics information.
@@ -34,6 +36,16 @@ type Stats struct {
 	BytesSent     uint64    `json:"bytes_sent"`
 	BytesReceived uint64    `json:"bytes_received"`
 	LastHandshake time.Time `json:"last_handshake"`
+	// Traffic is the classified forwarded traffic, empty if classification is not supported or disabled.
+	Traffic traffic.Counters `json:"traffic,omitempty"`
+}
+
+// HandshakeAge returns the time passed since the last handshake, zero if no handshake was made yet.
+func (s Stats) HandshakeAge(now time.Time) time.Duration {
+	if s.LastHandshake.IsZero() {
+		return 0
+	}
+	return now.Sub(s.LastHandshake)
 }
 
 // DeviceConfig describes wireguard device configuration.
@@ -178,6 +190,35 @@ func (dc *DeviceConfig) Encode() string {
 	return res.String()
 }
 
+// RedactedKey replaces private key in exported configuration.
+const RedactedKey = "<redacted>"
+
+// QuickConfig encodes device config into wg-quick configuration file format
+// which can be used by external WireGuard clients. The private key and the listen port
+// belong to the running device, so the key is redacted and the port is left out.
+func (dc *DeviceConfig) QuickConfig() string {
+	var res strings.Builder
+	res.WriteString("[Interface]\n")
+	res.WriteString(fmt.Sprintf("PrivateKey = %s\n", RedactedKey))
+	res.WriteString(fmt.Sprintf("Address = %s\n", dc.Subnet.String()))
+	if len(dc.DNS) > 0 {
+		res.WriteString(fmt.Sprintf("DNS = %s\n", strings.Join(dc.DNS, ", ")))
+	}
+
+	res.WriteString("\n[Peer]\n")
+	res.WriteString(fmt.Sprintf("PublicKey = %s\n", dc.Peer.PublicKey))
+	if len(dc.Peer.AllowedIPs) > 0 {
+		res.WriteString(fmt.Sprintf("AllowedIPs = %s\n", strings.Join(dc.Peer.AllowedIPs, ", ")))
+	}
+	if dc.Peer.Endpoint != nil {
+		res.WriteString(fmt.Sprintf("Endpoint = %s\n", dc.Peer.Endpoint.String()))
+	}
+	if dc.Peer.KeepAlivePeriodSeconds > 0 {
+		res.WriteString(fmt.Sprintf("PersistentKeepalive = %d\n", dc.Peer.KeepAlivePeriodSeconds))
+	}
+	return res.String()
+}
+
 // Peer represents wireguard peer.
 type Peer struct {
 	PublicKey              string       `json:"public_key"`
@@ -186,6 +227,23 @@ type Peer struct {
 	KeepAlivePeriodSeconds int          `json:"keep_alive_period_seconds"`
 }
 
+// Routes returns the subnets to be routed through the tunnel, nil means all the traffic.
+func (p *Peer) Routes() []net.IPNet {
+	var routes []net.IPNet
+	for _, allowedIP := range p.AllowedIPs {
+		_, route, err := net.ParseCIDR(allowedIP)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Skipping invalid allowed IP: %s", allowedIP)
+			continue
+		}
+		if ones, _ := route.Mask.Size(); ones == 0 {
+			return nil
+		}
+		routes = append(routes, *route)
+	}
+	return routes
+}
+
 // Encode encodes device peer config into string representation which is used for
 // userspace and kernel space wireguard configuration.
 func (p *Peer) Encode() string {
@@ -73,6 +73,33 @@ persistent_keepalive_interval=0
 	}
 }
 
+func TestDeviceConfig_QuickConfig(t *testing.T) {
+	config := DeviceConfig{
+		Subnet:     net.IPNet{IP: net.ParseIP("10.182.0.2").To4(), Mask: net.IPv4Mask(255, 255, 255, 0)},
+		PrivateKey: "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+		ListenPort: 53511,
+		DNS:        []string{"10.182.0.1"},
+		Peer: Peer{
+			PublicKey:              "wg/Zk1qxs2Xn4eOBaZGSpInZ2/YfTgsN4Q6zcMKLlGk=",
+			Endpoint:               endpoint(),
+			AllowedIPs:             []string{"0.0.0.0/0", "::/0"},
+			KeepAlivePeriodSeconds: 18,
+		},
+	}
+
+	assert.Equal(t, `[Interface]
+PrivateKey = <redacted>
+Address = 10.182.0.2/24
+DNS = 10.182.0.1
+
+[Peer]
+PublicKey = wg/Zk1qxs2Xn4eOBaZGSpInZ2/YfTgsN4Q6zcMKLlGk=
+AllowedIPs = 0.0.0.0/0, ::/0
+Endpoint = 182.122.22.19:3233
+PersistentKeepalive = 18
+`, config.QuickConfig())
+}
+
 func TestDeviceConfig_MarshalJSON(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -163,6 +190,17 @@ func TestDeviceConfig_UnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestPeer_Routes(t *testing.T) {
+	peer := Peer{AllowedIPs: []string{"0.0.0.0/0", "::/0"}}
+	assert.Nil(t, peer.Routes())
+
+	peer = Peer{AllowedIPs: []string{"192.168.1.0/24", "fd00::/8"}}
+	assert.Equal(t, []net.IPNet{
+		{IP: net.ParseIP("192.168.1.0").To4(), Mask: net.CIDRMask(24, 32)},
+		{IP: net.ParseIP("fd00::"), Mask: net.CIDRMask(8, 128)},
+	}, peer.Routes())
+}
+
 func endpoint() *net.UDPAddr {
 	res, _ := net.ResolveUDPAddr("udp", "182.122.22.19:3233")
 	return res
@@ -23,8 +23,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/traffic"
 )
 
 const (
@@ -32,14 +35,42 @@ const (
 	AppTopicSession = "Session change"
 	// AppTopicDataTransferred represents the data transfer topic.
 	AppTopicDataTransferred = "Session data transferred"
+	// AppTopicDataStarted represents the topic to which the data plane reports the first traffic of a session.
+	AppTopicDataStarted = "Session data started"
+	// AppTopicSessionTerminated represents the topic to which the session termination by provider is reported.
+	AppTopicSessionTerminated = "Session terminated"
 	// AppTopicTokensEarned is a topic for publish events about tokens earned as a provider.
 	AppTopicTokensEarned = "SessionTokensEarned"
+	// AppTopicSessionAdmission represents the topic to which the occupancy of the provider session slots is reported.
+	AppTopicSessionAdmission = "Session admission"
+	// AppTopicSessionPayment represents the topic to which the payment state of provider sessions is reported.
+	AppTopicSessionPayment = "Session payment"
+	// AppTopicSessionTunnel represents the topic to which the data plane reports the tunnel interface of a session.
+	AppTopicSessionTunnel = "Session tunnel"
+	// AppTopicResourcePressure represents the topic to which the provider reports shedding sessions under resource pressure.
+	AppTopicResourcePressure = "Resource pressure"
 )
 
 // AppEventDataTransferred represents the data transfer event
 type AppEventDataTransferred struct {
 	ID       string
 	Up, Down uint64
+	// Traffic is the classified traffic sent by consumer, empty if classification is disabled.
+	Traffic traffic.Counters
+	// LastHandshakeAge is the time passed since the last tunnel handshake, zero if the tunnel does not report it.
+	LastHandshakeAge time.Duration
+}
+
+// AppEventDataStarted indicates that the session tunnel has started passing traffic
+type AppEventDataStarted struct {
+	ID string
+}
+
+// AppEventSessionTerminated indicates that the provider has terminated the session for the given reason
+type AppEventSessionTerminated struct {
+	ID      string
+	Reason  session.TerminationReason
+	Message string
 }
 
 // AppEventTokensEarned is an update on tokens earned during current session
@@ -49,6 +80,50 @@ type AppEventTokensEarned struct {
 	Total      *big.Int
 }
 
+// AppEventSessionPayment is an update on the payment state of an ongoing provider session
+type AppEventSessionPayment struct {
+	SessionID             string
+	LastInvoiceAmount     *big.Int
+	LastInvoiceAt         time.Time
+	LastExchangeMessageAt time.Time
+	Paid                  *big.Int
+	Unpaid                *big.Int
+	// Shortfall is the part of the paid amount which hermes did not cover with promises.
+	Shortfall *big.Int
+}
+
+// AppEventSessionTunnel reports the tunnel interface of the session going up or down
+type AppEventSessionTunnel struct {
+	ID        string
+	Interface string
+	Up        bool
+}
+
+// AppEventSessionAdmission is an update on the occupancy of the provider session slots
+type AppEventSessionAdmission struct {
+	Active   int
+	Limit    int
+	Queued   int
+	Rejected uint64
+}
+
+// AppEventResourcePressure is an update on the system resource usage of the provider and the sessions shed because of it
+type AppEventResourcePressure struct {
+	// Shedding is true while new sessions are rejected.
+	Shedding bool
+	// CPU, Memory, Conntrack and FileDescriptors are the usage percentages at the time of the update.
+	CPU             float64
+	Memory          float64
+	Conntrack       float64
+	FileDescriptors float64
+	// Exceeded lists the resources above their thresholds.
+	Exceeded []string
+	// Deprioritized lists the IDs of the lowest-paying sessions throttled while shedding.
+	Deprioritized []string
+	// Rejected is the number of session requests rejected because of the resource pressure.
+	Rejected uint64
+}
+
 // Status represents the different actions that might happen on a session
 type Status string
 
@@ -59,6 +134,10 @@ const (
 	RemovedStatus Status = "RemovedStatus"
 	// AcknowledgedStatus indicates a session has been reported as a success from consumer side
 	AcknowledgedStatus Status = "AcknowledgedStatus"
+	// PausedStatus indicates consumer has paused the session
+	PausedStatus Status = "PausedStatus"
+	// ResumedStatus indicates consumer has resumed the paused session
+	ResumedStatus Status = "ResumedStatus"
 )
 
 // AppEventSession represents the session change payload
@@ -82,3 +161,15 @@ type SessionContext struct {
 	HermesID         common.Address
 	Proposal         market.ServiceProposal
 }
+
+func init() {
+	eventbus.RegisterSchema(AppTopicSession, 1, AppEventSession{})
+	eventbus.RegisterSchema(AppTopicDataTransferred, 1, AppEventDataTransferred{})
+	eventbus.RegisterSchema(AppTopicDataStarted, 1, AppEventDataStarted{})
+	eventbus.RegisterSchema(AppTopicSessionTerminated, 1, AppEventSessionTerminated{})
+	eventbus.RegisterSchema(AppTopicTokensEarned, 1, AppEventTokensEarned{})
+	eventbus.RegisterSchema(AppTopicSessionAdmission, 1, AppEventSessionAdmission{})
+	eventbus.RegisterSchema(AppTopicSessionPayment, 1, AppEventSessionPayment{})
+	eventbus.RegisterSchema(AppTopicSessionTunnel, 1, AppEventSessionTunnel{})
+	eventbus.RegisterSchema(AppTopicResourcePressure, 1, AppEventResourcePressure{})
+}
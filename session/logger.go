@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Log field names identifying the session in structured logs.
+const (
+	LogFieldSessionID   = "session_id"
+	LogFieldConsumerID  = "consumer_id"
+	LogFieldServiceType = "service_type"
+)
+
+// NewLogger returns a logger annotating every message with the session identifiers,
+// so that all the logs of a single session can be filtered by the session_id field.
+func NewLogger(id ID, consumerID, serviceType string) zerolog.Logger {
+	return log.With().
+		Str(LogFieldSessionID, string(id)).
+		Str(LogFieldConsumerID, consumerID).
+		Str(LogFieldServiceType, serviceType).
+		Logger()
+}
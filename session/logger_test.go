@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLogger(t *testing.T) {
+	original := log.Logger
+	defer func() { log.Logger = original }()
+
+	var buf bytes.Buffer
+	log.Logger = zerolog.New(&buf)
+
+	logger := NewLogger("session1", "0xconsumer", "wireguard")
+	logger.Info().Msg("Session started")
+
+	var entry map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "session1", entry[LogFieldSessionID])
+	assert.Equal(t, "0xconsumer", entry[LogFieldConsumerID])
+	assert.Equal(t, "wireguard", entry[LogFieldServiceType])
+	assert.Equal(t, "Session started", entry["message"])
+}
@@ -0,0 +1,354 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+const (
+	// autoPricingHistorySize is how many price changes are kept for the API.
+	autoPricingHistorySize = 100
+
+	autoPricingBucket     = "auto_pricing"
+	autoPricingHistoryKey = "history"
+)
+
+type networkPricing interface {
+	LatestPrices() market.LatestPrices
+	IsPriceValid(in market.Price, nodeType string, country string, serviceType string) bool
+}
+
+// AutoPricingConfig describes how the provider prices its services.
+type AutoPricingConfig struct {
+	// Enabled makes the provider announce its own price following the network average.
+	Enabled  bool
+	Interval time.Duration
+	// MinPerHour and MinPerGiB are the lowest prices in wei the provider charges.
+	MinPerHour *big.Int
+	MinPerGiB  *big.Int
+	// MaxPerHour and MaxPerGiB are the highest prices in wei the provider charges, zero leaves the price unbounded.
+	MaxPerHour *big.Int
+	MaxPerGiB  *big.Int
+}
+
+// PriceChange is a change of the price announced by the provider.
+type PriceChange struct {
+	NodeType    string
+	Country     string
+	ServiceType string
+	// Previous is the price before the change, nil for the first price of the service.
+	Previous *market.Price
+	Price    market.Price
+	// NetworkAverage is the average network price in the provider country the new price was derived from.
+	NetworkAverage market.Price
+	ChangedAt      time.Time
+}
+
+type autoPriceKey struct {
+	nodeType    string
+	country     string
+	serviceType string
+}
+
+type autoPrice struct {
+	current  market.Price
+	previous *market.Price
+}
+
+// AutoPricer keeps the prices of the provider services at the network average of the provider country
+// within the bounds set by the operator. Consumers pay the price announced in the proposal, the previous price
+// is accepted as well until consumers pick up the updated proposal. Prices of the network are accepted for consumers
+// which do not know the announced prices. Price changes are persisted, so the announced prices survive restarts.
+type AutoPricer struct {
+	network networkPricing
+	storage persistentStorage
+	config  AutoPricingConfig
+
+	lock    sync.Mutex
+	prices  map[autoPriceKey]*autoPrice
+	history []PriceChange
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewAutoPricer returns a new auto pricer following the prices of the given network pricing.
+func NewAutoPricer(network networkPricing, storage persistentStorage, config AutoPricingConfig) *AutoPricer {
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Minute
+	}
+	return &AutoPricer{
+		network: network,
+		storage: storage,
+		config:  config,
+		prices:  make(map[autoPriceKey]*autoPrice),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Enabled reports whether the provider prices its services itself.
+func (ap *AutoPricer) Enabled() bool {
+	return ap.config.Enabled
+}
+
+// Start restores the persisted prices and starts adjusting them periodically.
+func (ap *AutoPricer) Start() {
+	if !ap.Enabled() {
+		return
+	}
+
+	ap.restore()
+	go func() {
+		for {
+			select {
+			case <-ap.stop:
+				return
+			case <-time.After(ap.config.Interval):
+				ap.adjust(ap.network.LatestPrices(), time.Now().UTC())
+			}
+		}
+	}()
+}
+
+// Stop stops adjusting the prices.
+func (ap *AutoPricer) Stop() {
+	ap.stopOnce.Do(func() {
+		close(ap.stop)
+	})
+}
+
+// ProposalPrice returns the price announced in the proposal of the given service, nil if the network price is charged.
+func (ap *AutoPricer) ProposalPrice(nodeType, country, serviceType string) *market.Price {
+	if !ap.Enabled() {
+		return nil
+	}
+
+	key := newAutoPriceKey(nodeType, country, serviceType)
+	ap.lock.Lock()
+	price, ok := ap.prices[key]
+	ap.lock.Unlock()
+	if !ok {
+		pricing := ap.network.LatestPrices()
+
+		ap.lock.Lock()
+		ap.updateLocked(key, pricing, time.Now().UTC())
+		price, ok = ap.prices[key]
+		ap.lock.Unlock()
+		if !ok {
+			return nil
+		}
+	}
+
+	return copyPrice(&price.current)
+}
+
+// IsPriceValid checks if the consumer pays the announced price or the price of the network.
+func (ap *AutoPricer) IsPriceValid(in market.Price, nodeType string, country string, serviceType string) bool {
+	if ap.Enabled() {
+		ap.lock.Lock()
+		price, ok := ap.prices[newAutoPriceKey(nodeType, country, serviceType)]
+		valid := ok && (pricesMatch(&price.current, in) || pricesMatch(price.previous, in))
+		ap.lock.Unlock()
+		if valid {
+			return true
+		}
+	}
+
+	return ap.network.IsPriceValid(in, nodeType, country, serviceType)
+}
+
+// History returns the changes of the announced prices, newest first.
+func (ap *AutoPricer) History() []PriceChange {
+	ap.lock.Lock()
+	defer ap.lock.Unlock()
+
+	res := make([]PriceChange, len(ap.history))
+	for i, change := range ap.history {
+		res[len(ap.history)-1-i] = change
+	}
+	return res
+}
+
+// restore loads the persisted price changes, the latest change of every service sets its prices.
+func (ap *AutoPricer) restore() {
+	var history []PriceChange
+	if err := ap.storage.GetValue(autoPricingBucket, autoPricingHistoryKey, &history); err != nil {
+		if err.Error() != errBoltNotFound {
+			log.Warn().Err(err).Msg("Could not load auto pricing history")
+		}
+		return
+	}
+
+	ap.lock.Lock()
+	defer ap.lock.Unlock()
+
+	for _, change := range history {
+		ap.prices[newAutoPriceKey(change.NodeType, change.Country, change.ServiceType)] = &autoPrice{
+			current:  change.Price,
+			previous: change.Previous,
+		}
+	}
+	ap.history = history
+}
+
+func (ap *AutoPricer) adjust(pricing market.LatestPrices, now time.Time) {
+	ap.lock.Lock()
+	defer ap.lock.Unlock()
+
+	for key := range ap.prices {
+		ap.updateLocked(key, pricing, now)
+	}
+}
+
+func (ap *AutoPricer) updateLocked(key autoPriceKey, pricing market.LatestPrices, now time.Time) {
+	average, ok := networkAverage(pricing, key.nodeType, key.country, key.serviceType)
+	if !ok {
+		return
+	}
+
+	next := market.Price{
+		PricePerHour: bound(average.PricePerHour, ap.config.MinPerHour, ap.config.MaxPerHour),
+		PricePerGiB:  bound(average.PricePerGiB, ap.config.MinPerGiB, ap.config.MaxPerGiB),
+	}
+
+	price, ok := ap.prices[key]
+	if ok && pricesMatch(&price.current, next) {
+		return
+	}
+
+	change := PriceChange{
+		NodeType:       key.nodeType,
+		Country:        key.country,
+		ServiceType:    key.serviceType,
+		Price:          next,
+		NetworkAverage: average,
+		ChangedAt:      now,
+	}
+	if ok {
+		change.Previous = copyPrice(&price.current)
+		price.previous, price.current = change.Previous, next
+		log.Info().Msgf("Auto pricing changed %s price of %s node in %s from %s to %s, network average is %s", key.serviceType, key.nodeType, key.country, change.Previous, next, average)
+	} else {
+		ap.prices[key] = &autoPrice{current: next}
+		log.Info().Msgf("Auto pricing set %s price of %s node in %s to %s, network average is %s", key.serviceType, key.nodeType, key.country, next, average)
+	}
+
+	ap.history = append(ap.history, change)
+	if len(ap.history) > autoPricingHistorySize {
+		ap.history = ap.history[len(ap.history)-autoPricingHistorySize:]
+	}
+	if err := ap.storage.SetValue(autoPricingBucket, autoPricingHistoryKey, ap.history); err != nil {
+		log.Warn().Err(err).Msg("Could not save auto pricing history")
+	}
+}
+
+func newAutoPriceKey(nodeType, country, serviceType string) autoPriceKey {
+	return autoPriceKey{
+		nodeType:    strings.ToLower(nodeType),
+		country:     strings.ToUpper(country),
+		serviceType: strings.ToLower(serviceType),
+	}
+}
+
+// networkAverage returns the average of the current and previous prices charged by the nodes of the given type
+// in the given country, or by default if the network has no prices for the country.
+func networkAverage(pricing market.LatestPrices, nodeType, country, serviceType string) (market.Price, bool) {
+	history, ok := pricing.PerCountry[country]
+	if !ok {
+		history = pricing.Defaults
+	}
+	if history == nil {
+		return market.Price{}, false
+	}
+
+	perHour, perGiB := new(big.Int), new(big.Int)
+	count := int64(0)
+	for _, byType := range []*market.PriceByType{history.Current, history.Previous} {
+		price := priceByType(byType, nodeType, serviceType)
+		if price == nil || price.PricePerHour == nil || price.PricePerGiB == nil {
+			continue
+		}
+		perHour.Add(perHour, price.PricePerHour)
+		perGiB.Add(perGiB, price.PricePerGiB)
+		count++
+	}
+	if count == 0 {
+		return market.Price{}, false
+	}
+
+	return market.Price{
+		PricePerHour: perHour.Div(perHour, big.NewInt(count)),
+		PricePerGiB:  perGiB.Div(perGiB, big.NewInt(count)),
+	}, true
+}
+
+func priceByType(byType *market.PriceByType, nodeType, serviceType string) *market.Price {
+	if byType == nil {
+		return nil
+	}
+
+	byServiceType := byType.Other
+	if nodeType == "residential" {
+		byServiceType = byType.Residential
+	}
+	if byServiceType == nil {
+		return nil
+	}
+
+	switch serviceType {
+	case "wireguard":
+		return byServiceType.Wireguard
+	case "scraping":
+		return byServiceType.Scraping
+	default:
+		return byServiceType.DataTransfer
+	}
+}
+
+func bound(v, min, max *big.Int) *big.Int {
+	res := new(big.Int).Set(v)
+	if min != nil && res.Cmp(min) < 0 {
+		res.Set(min)
+	}
+	if max != nil && max.Sign() > 0 && res.Cmp(max) > 0 {
+		res.Set(max)
+	}
+	return res
+}
+
+func pricesMatch(price *market.Price, in market.Price) bool {
+	if price == nil || in.PricePerHour == nil || in.PricePerGiB == nil {
+		return false
+	}
+	return price.PricePerHour.Cmp(in.PricePerHour) == 0 && price.PricePerGiB.Cmp(in.PricePerGiB) == 0
+}
+
+func copyPrice(price *market.Price) *market.Price {
+	return &market.Price{
+		PricePerHour: new(big.Int).Set(price.PricePerHour),
+		PricePerGiB:  new(big.Int).Set(price.PricePerGiB),
+	}
+}
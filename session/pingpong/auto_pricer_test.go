@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type mockNetworkPricing struct {
+	prices market.LatestPrices
+	valid  bool
+}
+
+func (m *mockNetworkPricing) LatestPrices() market.LatestPrices {
+	return m.prices
+}
+
+func (m *mockNetworkPricing) IsPriceValid(in market.Price, nodeType string, country string, serviceType string) bool {
+	return m.valid
+}
+
+type mockPricingStorage struct {
+	values map[string][]byte
+}
+
+func newMockPricingStorage() *mockPricingStorage {
+	return &mockPricingStorage{values: make(map[string][]byte)}
+}
+
+func (m *mockPricingStorage) GetValue(bucket string, key interface{}, to interface{}) error {
+	value, ok := m.values[bucket+fmt.Sprint(key)]
+	if !ok {
+		return errors.New(errBoltNotFound)
+	}
+	return json.Unmarshal(value, to)
+}
+
+func (m *mockPricingStorage) SetValue(bucket string, key interface{}, to interface{}) error {
+	value, err := json.Marshal(to)
+	m.values[bucket+fmt.Sprint(key)] = value
+	return err
+}
+
+// networkPrices returns the network prices with the current and previous wireguard prices of residential nodes
+// by default and in DE.
+func networkPrices(defaults, current, previous *market.Price) market.LatestPrices {
+	byType := func(p *market.Price) *market.PriceByType {
+		return &market.PriceByType{
+			Residential: &market.PriceByServiceType{Wireguard: p},
+			Other:       &market.PriceByServiceType{Wireguard: market.NewPrice(1, 1)},
+		}
+	}
+	return market.LatestPrices{
+		Defaults: &market.PriceHistory{Current: byType(defaults)},
+		PerCountry: map[string]*market.PriceHistory{
+			"DE": {Current: byType(current), Previous: byType(previous)},
+		},
+	}
+}
+
+func TestAutoPricer_FollowsCountryAverageWithinBounds(t *testing.T) {
+	network := &mockNetworkPricing{prices: networkPrices(market.NewPrice(50, 50), market.NewPrice(100, 1000), market.NewPrice(200, 3000))}
+	pricer := NewAutoPricer(network, newMockPricingStorage(), AutoPricingConfig{
+		Enabled:    true,
+		MinPerHour: big.NewInt(0),
+		MaxPerHour: big.NewInt(0),
+		MinPerGiB:  big.NewInt(2500),
+		MaxPerGiB:  big.NewInt(0),
+	})
+
+	assert.Equal(t, market.NewPrice(150, 2500), pricer.ProposalPrice("Residential", "de", "wireguard"))
+	assert.Equal(t, market.NewPrice(1, 2500), pricer.ProposalPrice("hosting", "DE", "wireguard"))
+	// Countries without network prices follow the default prices.
+	assert.Equal(t, market.NewPrice(50, 2500), pricer.ProposalPrice("residential", "US", "wireguard"))
+
+	now := time.Unix(1000, 0).UTC()
+	network.prices = networkPrices(market.NewPrice(50, 50), market.NewPrice(500, 3000), market.NewPrice(300, 5000))
+	pricer.config.MaxPerHour = big.NewInt(350)
+	pricer.adjust(network.prices, now)
+	assert.Equal(t, market.NewPrice(350, 4000), pricer.ProposalPrice("residential", "DE", "wireguard"))
+
+	// Unchanged prices are not recorded.
+	pricer.adjust(network.prices, now.Add(time.Minute))
+
+	history := pricer.History()
+	assert.Len(t, history, 4)
+	assert.Equal(t, PriceChange{
+		NodeType:       "residential",
+		Country:        "DE",
+		ServiceType:    "wireguard",
+		Previous:       market.NewPrice(150, 2500),
+		Price:          *market.NewPrice(350, 4000),
+		NetworkAverage: *market.NewPrice(400, 4000),
+		ChangedAt:      now,
+	}, history[0])
+}
+
+func TestAutoPricer_RestoresPersistedPrices(t *testing.T) {
+	storage := newMockPricingStorage()
+	network := &mockNetworkPricing{prices: networkPrices(market.NewPrice(50, 50), market.NewPrice(100, 1000), market.NewPrice(100, 1000))}
+	pricer := NewAutoPricer(network, storage, AutoPricingConfig{Enabled: true})
+	pricer.ProposalPrice("residential", "DE", "wireguard")
+
+	network.prices = networkPrices(market.NewPrice(50, 50), market.NewPrice(200, 2000), market.NewPrice(200, 2000))
+	pricer.adjust(network.prices, time.Unix(1000, 0).UTC())
+
+	restarted := NewAutoPricer(network, storage, AutoPricingConfig{Enabled: true})
+	restarted.Start()
+	defer restarted.Stop()
+
+	assert.Equal(t, pricer.History(), restarted.History())
+	assert.Equal(t, market.NewPrice(200, 2000), restarted.ProposalPrice("residential", "DE", "wireguard"))
+	// Consumers which have not picked up the updated proposal before the restart still pay the previous price.
+	assert.True(t, restarted.IsPriceValid(*market.NewPrice(100, 1000), "residential", "DE", "wireguard"))
+}
+
+func TestAutoPricer_IsPriceValid(t *testing.T) {
+	network := &mockNetworkPricing{prices: networkPrices(market.NewPrice(50, 50), market.NewPrice(100, 1000), market.NewPrice(100, 1000))}
+	pricer := NewAutoPricer(network, newMockPricingStorage(), AutoPricingConfig{Enabled: true})
+	pricer.ProposalPrice("residential", "DE", "wireguard")
+
+	network.prices = networkPrices(market.NewPrice(50, 50), market.NewPrice(200, 2000), market.NewPrice(200, 2000))
+	pricer.adjust(network.prices, time.Now())
+
+	assert.True(t, pricer.IsPriceValid(*market.NewPrice(200, 2000), "residential", "DE", "wireguard"))
+	// Consumers which have not picked up the updated proposal yet pay the previous price.
+	assert.True(t, pricer.IsPriceValid(*market.NewPrice(100, 1000), "residential", "DE", "wireguard"))
+	assert.False(t, pricer.IsPriceValid(*market.NewPrice(50, 500), "residential", "DE", "wireguard"))
+	// Announced prices are specific to the provider country.
+	assert.False(t, pricer.IsPriceValid(*market.NewPrice(200, 2000), "residential", "US", "wireguard"))
+
+	network.valid = true
+	assert.True(t, pricer.IsPriceValid(*market.NewPrice(50, 500), "residential", "DE", "wireguard"))
+}
+
+func TestAutoPricer_Disabled(t *testing.T) {
+	network := &mockNetworkPricing{prices: networkPrices(market.NewPrice(50, 50), market.NewPrice(100, 1000), nil), valid: true}
+	pricer := NewAutoPricer(network, newMockPricingStorage(), AutoPricingConfig{})
+
+	assert.Nil(t, pricer.ProposalPrice("residential", "DE", "wireguard"))
+	assert.True(t, pricer.IsPriceValid(*market.NewPrice(50, 500), "residential", "DE", "wireguard"))
+	assert.Empty(t, pricer.History())
+}
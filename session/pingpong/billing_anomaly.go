@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// BillingAnomaly describes why billing of a session looks wrong.
+type BillingAnomaly string
+
+const (
+	// BillingAnomalyClockJump indicates that the billed time advanced faster than the local clock,
+	// e.g. after the host was suspended or the clock was moved.
+	BillingAnomalyClockJump BillingAnomaly = "clock_jump"
+	// BillingAnomalyRate indicates that the invoiced amount grew faster than the traffic and elapsed time justify.
+	BillingAnomalyRate BillingAnomaly = "rate"
+	// BillingAnomalyPrice indicates that the session is billed at an hourly rate above the configured ceiling,
+	// which usually means the service pricing is misconfigured.
+	BillingAnomalyPrice BillingAnomaly = "price"
+)
+
+// billingAnomalyMinAge is how long the session has to run before its hourly rate is judged,
+// short sessions extrapolate the rate of a few invoices to an hour which is too noisy.
+const billingAnomalyMinAge = 5 * time.Minute
+
+// BillingAnomalyConfig configures the detection of billing anomalies on provider side.
+type BillingAnomalyConfig struct {
+	// Tolerance is the allowed difference between the billed time and the local clock, zero disables the detection.
+	Tolerance time.Duration
+	// MaxHourlyRate is the highest amount a session may be billed per hour, nil or zero disables the check.
+	MaxHourlyRate *big.Int
+	// PauseBilling stops increasing the invoiced amount of a session once an anomaly is detected.
+	PauseBilling bool
+	// PauseDuration is how long the billing stays paused, zero keeps it paused until the session ends.
+	// The amount accrued while paused is never invoiced.
+	PauseDuration time.Duration
+}
+
+// billingAnomalyDetector compares the amounts invoiced for a session with the traffic and time
+// observed independently of the billing time tracker.
+type billingAnomalyDetector struct {
+	config BillingAnomalyConfig
+	price  market.Price
+	clock  Clock
+
+	started     time.Time
+	lastCheck   time.Time
+	lastElapsed time.Duration
+	lastData    DataTransferred
+	lastAmount  *big.Int
+	// discount is the amount accrued during the pauses, it is subtracted from the invoiced amounts.
+	discount *big.Int
+	invoiced *big.Int
+	paused   bool
+	pausedAt time.Time
+}
+
+func newBillingAnomalyDetector(config BillingAnomalyConfig, price market.Price, clock Clock) *billingAnomalyDetector {
+	return &billingAnomalyDetector{
+		config:     config,
+		price:      price,
+		clock:      clock,
+		lastAmount: new(big.Int),
+		discount:   new(big.Int),
+		invoiced:   new(big.Int),
+	}
+}
+
+// check records the amount about to be invoiced and reports an anomaly if the amount is not justified.
+// The returned amount is the one to invoice, it stays at the last invoiced amount while billing is paused.
+func (bad *billingAnomalyDetector) check(elapsed time.Duration, data DataTransferred, amount *big.Int) (*big.Int, BillingAnomaly, bool) {
+	now := bad.clock.Now()
+	if bad.paused {
+		if bad.config.PauseDuration <= 0 || now.Sub(bad.pausedAt) < bad.config.PauseDuration {
+			return bad.invoiced, "", false
+		}
+
+		bad.paused = false
+		bad.discount = safeSub(amount, bad.invoiced)
+		bad.record(now, elapsed, data, amount)
+		return bad.invoiced, "", false
+	}
+
+	if bad.started.IsZero() {
+		bad.started = now
+		bad.record(now, elapsed, data, amount)
+		return bad.invoiced, "", false
+	}
+
+	anomaly, found := bad.detect(now, elapsed, data, amount)
+	if found && bad.config.PauseBilling {
+		bad.paused = true
+		bad.pausedAt = now
+		return bad.invoiced, anomaly, true
+	}
+
+	bad.record(now, elapsed, data, amount)
+	return bad.invoiced, anomaly, found
+}
+
+func (bad *billingAnomalyDetector) detect(now time.Time, elapsed time.Duration, data DataTransferred, amount *big.Int) (BillingAnomaly, bool) {
+	wall := now.Sub(bad.lastCheck)
+	if elapsed-bad.lastElapsed > wall+bad.config.Tolerance {
+		return BillingAnomalyClockJump, true
+	}
+
+	traffic := DataTransferred{
+		Up:   safeSubUint64(data.Up, bad.lastData.Up),
+		Down: safeSubUint64(data.Down, bad.lastData.Down),
+	}
+	justified := CalculatePaymentAmount(wall+bad.config.Tolerance, traffic, bad.price)
+	if safeSub(amount, bad.lastAmount).Cmp(justified) > 0 {
+		return BillingAnomalyRate, true
+	}
+
+	age := now.Sub(bad.started)
+	if bad.config.MaxHourlyRate != nil && bad.config.MaxHourlyRate.Sign() > 0 && age >= billingAnomalyMinAge {
+		// amount / age > max / hour, cross multiplied to stay in integers.
+		billed := new(big.Int).Mul(amount, big.NewInt(int64(time.Hour)))
+		allowed := new(big.Int).Mul(bad.config.MaxHourlyRate, big.NewInt(int64(age)))
+		if billed.Cmp(allowed) > 0 {
+			return BillingAnomalyPrice, true
+		}
+	}
+
+	return "", false
+}
+
+func (bad *billingAnomalyDetector) record(now time.Time, elapsed time.Duration, data DataTransferred, amount *big.Int) {
+	bad.lastCheck = now
+	bad.lastElapsed = elapsed
+	bad.lastData = data
+	bad.lastAmount = amount
+	bad.invoiced = safeSub(amount, bad.discount)
+}
+
+func safeSubUint64(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAnomalyDetector(config BillingAnomalyConfig) (*billingAnomalyDetector, *mockClock) {
+	clock := newMockClock()
+	return newBillingAnomalyDetector(config, *market.NewPrice(3600, 0), clock), clock
+}
+
+func Test_BillingAnomalyDetector(t *testing.T) {
+	config := BillingAnomalyConfig{Tolerance: time.Second}
+
+	t.Run("accepts amount justified by time", func(t *testing.T) {
+		bad, clock := newTestAnomalyDetector(config)
+		bad.check(0, DataTransferred{}, big.NewInt(0))
+
+		clock.Advance(time.Minute)
+		amount, _, found := bad.check(time.Minute, DataTransferred{}, big.NewInt(60))
+		assert.False(t, found)
+		assert.Equal(t, big.NewInt(60), amount)
+	})
+
+	t.Run("detects clock jump", func(t *testing.T) {
+		bad, clock := newTestAnomalyDetector(config)
+		bad.check(0, DataTransferred{}, big.NewInt(0))
+
+		clock.Advance(time.Minute)
+		_, anomaly, found := bad.check(time.Hour, DataTransferred{}, big.NewInt(60))
+		assert.True(t, found)
+		assert.Equal(t, BillingAnomalyClockJump, anomaly)
+	})
+
+	t.Run("detects amount growing too fast", func(t *testing.T) {
+		bad, clock := newTestAnomalyDetector(config)
+		bad.check(0, DataTransferred{}, big.NewInt(0))
+
+		clock.Advance(time.Minute)
+		_, anomaly, found := bad.check(time.Minute, DataTransferred{}, big.NewInt(600))
+		assert.True(t, found)
+		assert.Equal(t, BillingAnomalyRate, anomaly)
+	})
+
+	t.Run("detects hourly rate above ceiling", func(t *testing.T) {
+		bad, clock := newTestAnomalyDetector(BillingAnomalyConfig{Tolerance: time.Second, MaxHourlyRate: big.NewInt(1800)})
+		bad.check(0, DataTransferred{}, big.NewInt(0))
+
+		clock.Advance(10 * time.Minute)
+		_, anomaly, found := bad.check(10*time.Minute, DataTransferred{}, big.NewInt(600))
+		assert.True(t, found)
+		assert.Equal(t, BillingAnomalyPrice, anomaly)
+	})
+
+	t.Run("pauses billing", func(t *testing.T) {
+		bad, clock := newTestAnomalyDetector(BillingAnomalyConfig{Tolerance: time.Second, PauseBilling: true})
+		bad.check(0, DataTransferred{}, big.NewInt(0))
+		clock.Advance(time.Minute)
+		bad.check(time.Minute, DataTransferred{}, big.NewInt(60))
+
+		clock.Advance(time.Minute)
+		amount, _, found := bad.check(time.Hour, DataTransferred{}, big.NewInt(3600))
+		assert.True(t, found)
+		assert.Equal(t, big.NewInt(60), amount)
+
+		clock.Advance(time.Minute)
+		amount, _, found = bad.check(time.Hour+time.Minute, DataTransferred{}, big.NewInt(3660))
+		assert.False(t, found)
+		assert.Equal(t, big.NewInt(60), amount)
+	})
+
+	t.Run("resumes billing after pause duration", func(t *testing.T) {
+		bad, clock := newTestAnomalyDetector(BillingAnomalyConfig{Tolerance: time.Second, PauseBilling: true, PauseDuration: 10 * time.Minute})
+		bad.check(0, DataTransferred{}, big.NewInt(0))
+		clock.Advance(time.Minute)
+		bad.check(time.Minute, DataTransferred{}, big.NewInt(60))
+
+		clock.Advance(time.Minute)
+		amount, _, found := bad.check(time.Hour, DataTransferred{}, big.NewInt(3600))
+		assert.True(t, found)
+		assert.Equal(t, big.NewInt(60), amount)
+
+		clock.Advance(5 * time.Minute)
+		amount, _, _ = bad.check(time.Hour+5*time.Minute, DataTransferred{}, big.NewInt(3900))
+		assert.True(t, bad.paused)
+		assert.Equal(t, big.NewInt(60), amount)
+
+		clock.Advance(5 * time.Minute)
+		amount, _, found = bad.check(time.Hour+10*time.Minute, DataTransferred{}, big.NewInt(4200))
+		assert.False(t, found)
+		assert.False(t, bad.paused)
+		assert.Equal(t, big.NewInt(60), amount)
+
+		clock.Advance(time.Minute)
+		amount, _, found = bad.check(time.Hour+11*time.Minute, DataTransferred{}, big.NewInt(4260))
+		assert.False(t, found)
+		assert.Equal(t, big.NewInt(120), amount)
+	})
+}
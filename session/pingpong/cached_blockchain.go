@@ -0,0 +1,222 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// CachedBlockchainConfig represents the cache durations of the cached blockchain client.
+type CachedBlockchainConfig struct {
+	// StaticTTL is used for values that rarely change, like registration status or hermes fees.
+	StaticTTL time.Duration
+	// ChannelTTL is used for channel state, which includes balances.
+	ChannelTTL time.Duration
+}
+
+type cachedBlockchainSource interface {
+	GetHermesFee(chainID int64, hermesAddress common.Address) (uint16, error)
+	IsRegistered(chainID int64, registryAddress, addressToCheck common.Address) (bool, error)
+	GetProviderChannel(chainID int64, hermesAddress common.Address, addressToCheck common.Address, pending bool) (client.ProviderChannel, error)
+	GetConsumerChannel(chainID int64, addr common.Address, mystSCAddress common.Address) (client.ConsumerChannel, error)
+}
+
+type cachedBlockchainEntry struct {
+	value      interface{}
+	validUntil time.Time
+}
+
+func (cbe cachedBlockchainEntry) isValid() bool {
+	return time.Now().Before(cbe.validUntil)
+}
+
+// CachedBlockchain wraps the multichain blockchain client and caches the results of the most frequent lookups.
+// Cached values are dropped once they expire or once an event indicates that they have changed.
+type CachedBlockchain struct {
+	*client.MultichainBlockchainClient
+
+	source cachedBlockchainSource
+	cfg    CachedBlockchainConfig
+
+	cachedValues map[string]cachedBlockchainEntry
+	lock         sync.Mutex
+}
+
+// NewCachedBlockchain returns a new instance of cached blockchain client.
+func NewCachedBlockchain(bc *client.MultichainBlockchainClient, cfg CachedBlockchainConfig) *CachedBlockchain {
+	cb := newCachedBlockchain(bc, cfg)
+	cb.MultichainBlockchainClient = bc
+	return cb
+}
+
+func newCachedBlockchain(source cachedBlockchainSource, cfg CachedBlockchainConfig) *CachedBlockchain {
+	return &CachedBlockchain{
+		source:       source,
+		cfg:          cfg,
+		cachedValues: make(map[string]cachedBlockchainEntry),
+	}
+}
+
+// Subscribe subscribes the cache to the events that invalidate cached values.
+func (cb *CachedBlockchain) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(registry.AppTopicIdentityRegistration, cb.handleRegistrationEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(event.AppTopicSettlementComplete, cb.handleSettlementEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(event.AppTopicWithdrawalRequested, cb.handleWithdrawalEvent)
+}
+
+// GetHermesFee returns the hermes fee.
+func (cb *CachedBlockchain) GetHermesFee(chainID int64, hermesAddress common.Address) (uint16, error) {
+	key := cb.formKey("fee", chainID, hermesAddress)
+	if v, ok := cb.getFromCache(key); ok {
+		return v.(uint16), nil
+	}
+
+	fee, err := cb.source.GetHermesFee(chainID, hermesAddress)
+	if err != nil {
+		return 0, err
+	}
+
+	cb.setInCache(key, fee, cb.cfg.StaticTTL)
+	return fee, nil
+}
+
+// IsRegistered checks if the given identity is registered.
+func (cb *CachedBlockchain) IsRegistered(chainID int64, registryAddress, addressToCheck common.Address) (bool, error) {
+	key := cb.formKey("registration", chainID, addressToCheck, registryAddress)
+	if v, ok := cb.getFromCache(key); ok {
+		return v.(bool), nil
+	}
+
+	registered, err := cb.source.IsRegistered(chainID, registryAddress, addressToCheck)
+	if err != nil {
+		return false, err
+	}
+
+	cb.setInCache(key, registered, cb.cfg.StaticTTL)
+	return registered, nil
+}
+
+// GetProviderChannel returns the provider channel.
+func (cb *CachedBlockchain) GetProviderChannel(chainID int64, hermesAddress common.Address, addressToCheck common.Address, pending bool) (client.ProviderChannel, error) {
+	key := cb.formKey("provider_channel", chainID, addressToCheck, hermesAddress) + fmt.Sprint(pending)
+	if v, ok := cb.getFromCache(key); ok {
+		return v.(client.ProviderChannel), nil
+	}
+
+	channel, err := cb.source.GetProviderChannel(chainID, hermesAddress, addressToCheck, pending)
+	if err != nil {
+		return client.ProviderChannel{}, err
+	}
+
+	cb.setInCache(key, channel, cb.cfg.ChannelTTL)
+	return channel, nil
+}
+
+// GetConsumerChannel returns the consumer channel.
+func (cb *CachedBlockchain) GetConsumerChannel(chainID int64, addr common.Address, mystSCAddress common.Address) (client.ConsumerChannel, error) {
+	key := cb.formKey("consumer_channel", chainID, addr, mystSCAddress)
+	if v, ok := cb.getFromCache(key); ok {
+		return v.(client.ConsumerChannel), nil
+	}
+
+	channel, err := cb.source.GetConsumerChannel(chainID, addr, mystSCAddress)
+	if err != nil {
+		return client.ConsumerChannel{}, err
+	}
+
+	cb.setInCache(key, channel, cb.cfg.ChannelTTL)
+	return channel, nil
+}
+
+func (cb *CachedBlockchain) handleRegistrationEvent(ev registry.AppEventIdentityRegistration) {
+	log.Debug().Msgf("Registration status changed for %v, dropping cached blockchain values", ev.ID.Address)
+	cb.invalidate(cb.formKey("registration", ev.ChainID, ev.ID.ToCommonAddress()))
+	cb.invalidate(cb.formKey("provider_channel", ev.ChainID, ev.ID.ToCommonAddress()))
+	// consumer channels are keyed by channel address, so we can't tell which ones belong to the identity.
+	cb.invalidate(cb.formKey("consumer_channel", ev.ChainID))
+}
+
+func (cb *CachedBlockchain) handleSettlementEvent(ev event.AppEventSettlementComplete) {
+	cb.invalidate(cb.formKey("provider_channel", ev.ChainID, ev.ProviderID.ToCommonAddress(), ev.HermesID))
+}
+
+func (cb *CachedBlockchain) handleWithdrawalEvent(ev event.AppEventWithdrawalRequested) {
+	cb.invalidate(cb.formKey("provider_channel", ev.FromChain, ev.ProviderID.ToCommonAddress(), ev.HermesID))
+}
+
+func (cb *CachedBlockchain) formKey(kind string, chainID int64, addresses ...common.Address) string {
+	key := fmt.Sprintf("%v_%v_", kind, chainID)
+	for _, addr := range addresses {
+		key += addr.Hex() + "_"
+	}
+	return key
+}
+
+// invalidate drops all the cached values which keys start with the given prefix.
+func (cb *CachedBlockchain) invalidate(prefix string) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	for k := range cb.cachedValues {
+		if strings.HasPrefix(k, prefix) {
+			delete(cb.cachedValues, k)
+		}
+	}
+}
+
+func (cb *CachedBlockchain) setInCache(key string, value interface{}, ttl time.Duration) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	cb.cachedValues[key] = cachedBlockchainEntry{
+		value:      value,
+		validUntil: time.Now().Add(ttl),
+	}
+}
+
+func (cb *CachedBlockchain) getFromCache(key string) (interface{}, bool) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+
+	v, ok := cb.cachedValues[key]
+	if !ok {
+		return nil, false
+	}
+
+	if v.isValid() {
+		return v.value, true
+	}
+
+	delete(cb.cachedValues, key)
+	return nil, false
+}
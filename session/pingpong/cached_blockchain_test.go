@@ -0,0 +1,180 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+var cachedBlockchainCfg = CachedBlockchainConfig{
+	StaticTTL:  time.Minute,
+	ChannelTTL: time.Minute,
+}
+
+func Test_CachedBlockchain_GetHermesFee(t *testing.T) {
+	t.Run("caches successful lookups", func(t *testing.T) {
+		source := &mockCachedBlockchainSource{fee: 5}
+		cb := newCachedBlockchain(source, cachedBlockchainCfg)
+
+		for i := 0; i < 3; i++ {
+			fee, err := cb.GetHermesFee(chainID, hid)
+			assert.NoError(t, err)
+			assert.Equal(t, uint16(5), fee)
+		}
+		assert.Equal(t, 1, source.getTimesCalled())
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		source := &mockCachedBlockchainSource{err: errors.New("boom")}
+		cb := newCachedBlockchain(source, cachedBlockchainCfg)
+
+		_, err := cb.GetHermesFee(chainID, hid)
+		assert.Error(t, err)
+		_, err = cb.GetHermesFee(chainID, hid)
+		assert.Error(t, err)
+		assert.Equal(t, 2, source.getTimesCalled())
+	})
+
+	t.Run("refetches expired values", func(t *testing.T) {
+		source := &mockCachedBlockchainSource{fee: 5}
+		cb := newCachedBlockchain(source, CachedBlockchainConfig{})
+
+		_, err := cb.GetHermesFee(chainID, hid)
+		assert.NoError(t, err)
+		_, err = cb.GetHermesFee(chainID, hid)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, source.getTimesCalled())
+	})
+}
+
+func Test_CachedBlockchain_Invalidation(t *testing.T) {
+	id := identity.FromAddress("0x1111111111111111111111111111111111111111")
+
+	t.Run("registration event drops registration status", func(t *testing.T) {
+		source := &mockCachedBlockchainSource{registered: false}
+		cb := newCachedBlockchain(source, cachedBlockchainCfg)
+
+		registered, err := cb.IsRegistered(chainID, rid, id.ToCommonAddress())
+		assert.NoError(t, err)
+		assert.False(t, registered)
+
+		source.setRegistered(true)
+		cb.handleRegistrationEvent(registry.AppEventIdentityRegistration{ID: id, ChainID: chainID, Status: registry.Registered})
+
+		registered, err = cb.IsRegistered(chainID, rid, id.ToCommonAddress())
+		assert.NoError(t, err)
+		assert.True(t, registered)
+		assert.Equal(t, 2, source.getTimesCalled())
+	})
+
+	t.Run("settlement event drops provider channel", func(t *testing.T) {
+		source := &mockCachedBlockchainSource{}
+		cb := newCachedBlockchain(source, cachedBlockchainCfg)
+
+		_, err := cb.GetProviderChannel(chainID, hid, id.ToCommonAddress(), false)
+		assert.NoError(t, err)
+		_, err = cb.GetProviderChannel(chainID, hid, id.ToCommonAddress(), true)
+		assert.NoError(t, err)
+		_, err = cb.GetProviderChannel(chainID, hid, id.ToCommonAddress(), false)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, source.getTimesCalled())
+
+		cb.handleSettlementEvent(event.AppEventSettlementComplete{ProviderID: id, HermesID: hid, ChainID: chainID})
+
+		_, err = cb.GetProviderChannel(chainID, hid, id.ToCommonAddress(), false)
+		assert.NoError(t, err)
+		_, err = cb.GetProviderChannel(chainID, hid, id.ToCommonAddress(), true)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, source.getTimesCalled())
+	})
+
+	t.Run("settlement event for other chain keeps provider channel", func(t *testing.T) {
+		source := &mockCachedBlockchainSource{}
+		cb := newCachedBlockchain(source, cachedBlockchainCfg)
+
+		_, err := cb.GetProviderChannel(chainID, hid, id.ToCommonAddress(), false)
+		assert.NoError(t, err)
+
+		cb.handleSettlementEvent(event.AppEventSettlementComplete{ProviderID: id, HermesID: hid, ChainID: chainID + 1})
+
+		_, err = cb.GetProviderChannel(chainID, hid, id.ToCommonAddress(), false)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, source.getTimesCalled())
+	})
+}
+
+type mockCachedBlockchainSource struct {
+	fee        uint16
+	registered bool
+	err        error
+
+	timesCalled int
+	lock        sync.Mutex
+}
+
+func (m *mockCachedBlockchainSource) setRegistered(registered bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.registered = registered
+}
+
+func (m *mockCachedBlockchainSource) getTimesCalled() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.timesCalled
+}
+
+func (m *mockCachedBlockchainSource) GetHermesFee(chainID int64, hermesAddress common.Address) (uint16, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.timesCalled++
+	return m.fee, m.err
+}
+
+func (m *mockCachedBlockchainSource) IsRegistered(chainID int64, registryAddress, addressToCheck common.Address) (bool, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.timesCalled++
+	return m.registered, m.err
+}
+
+func (m *mockCachedBlockchainSource) GetProviderChannel(chainID int64, hermesAddress common.Address, addressToCheck common.Address, pending bool) (client.ProviderChannel, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.timesCalled++
+	return client.ProviderChannel{Stake: big.NewInt(1)}, m.err
+}
+
+func (m *mockCachedBlockchainSource) GetConsumerChannel(chainID int64, addr common.Address, mystSCAddress common.Address) (client.ConsumerChannel, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.timesCalled++
+	return client.ConsumerChannel{Balance: big.NewInt(1)}, m.err
+}
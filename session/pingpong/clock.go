@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/mbtime"
+)
+
+// Clock is the source of time for the billing components.
+// Elapsed session time is measured with Monotonic, which keeps counting while the host is suspended
+// and is not affected by wall clock adjustments, while Now is only used for reported timestamps.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Monotonic() mbtime.Time
+}
+
+// SystemClock is the clock backed by the operating system.
+type SystemClock struct{}
+
+// Now returns the current wall clock time.
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and then sends the current time on the returned channel.
+func (SystemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Monotonic returns the suspend aware monotonic time.
+func (SystemClock) Monotonic() mbtime.Time {
+	return mbtime.Now()
+}
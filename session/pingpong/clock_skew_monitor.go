@@ -0,0 +1,185 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// ClockSkewMonitor periodically compares the local clock with the Date reported by remote servers.
+// Invoice and promise validation as well as the charge period math assume roughly synced clocks,
+// so a drift beyond the threshold is announced and the peer clock skew tolerance falls back to the drift
+// until the peer clock is measured.
+type ClockSkewMonitor struct {
+	client    httpDoer
+	publisher eventbus.Publisher
+	urls      []string
+	interval  time.Duration
+	threshold time.Duration
+	now       func() time.Time
+
+	lock     sync.Mutex
+	offset   time.Duration
+	exceeded bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewClockSkewMonitor returns a new instance of clock skew monitor.
+func NewClockSkewMonitor(
+	client httpDoer,
+	publisher eventbus.Publisher,
+	urls []string,
+	interval time.Duration,
+	threshold time.Duration,
+) *ClockSkewMonitor {
+	return &ClockSkewMonitor{
+		client:    client,
+		publisher: publisher,
+		urls:      urls,
+		interval:  interval,
+		threshold: threshold,
+		now:       time.Now,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start samples the remote time until stopped.
+func (csm *ClockSkewMonitor) Start() {
+	for {
+		csm.check()
+
+		select {
+		case <-csm.stop:
+			return
+		case <-time.After(csm.interval):
+		}
+	}
+}
+
+// Stop stops sampling the remote time.
+func (csm *ClockSkewMonitor) Stop() {
+	csm.stopOnce.Do(func() {
+		close(csm.stop)
+	})
+}
+
+// Offset returns the last measured offset of the remote time from the local clock.
+func (csm *ClockSkewMonitor) Offset() time.Duration {
+	csm.lock.Lock()
+	defer csm.lock.Unlock()
+
+	return csm.offset
+}
+
+// Skew returns the clock drift once it exceeds the threshold, zero otherwise.
+func (csm *ClockSkewMonitor) Skew() time.Duration {
+	csm.lock.Lock()
+	defer csm.lock.Unlock()
+
+	if !csm.exceeded {
+		return 0
+	}
+	return absDuration(csm.offset)
+}
+
+func (csm *ClockSkewMonitor) check() {
+	offsets := make([]time.Duration, 0, len(csm.urls))
+	for _, url := range csm.urls {
+		offset, err := csm.sample(url)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Could not sample time of %s", url)
+			continue
+		}
+		offsets = append(offsets, offset)
+	}
+	if len(offsets) == 0 {
+		return
+	}
+
+	// Median keeps a single misconfigured server from skewing the result.
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	csm.report(offsets[len(offsets)/2])
+}
+
+// sample estimates the remote time in the middle of the request round trip.
+// Date header only has a second precision, so half a second is added to its truncated value.
+func (csm *ClockSkewMonitor) sample(url string) (time.Duration, error) {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not create request: %w", err)
+	}
+
+	started := csm.now()
+	resp, err := csm.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("could not reach server: %w", err)
+	}
+	defer resp.Body.Close()
+	roundTrip := csm.now().Sub(started)
+
+	remote, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return 0, fmt.Errorf("could not parse server date: %w", err)
+	}
+
+	local := started.Add(roundTrip / 2)
+	return remote.Add(500 * time.Millisecond).Sub(local), nil
+}
+
+func (csm *ClockSkewMonitor) report(offset time.Duration) {
+	exceeded := absDuration(offset) > csm.threshold
+
+	csm.lock.Lock()
+	wasExceeded := csm.exceeded
+	csm.offset = offset
+	csm.exceeded = exceeded
+	csm.lock.Unlock()
+
+	switch {
+	case exceeded:
+		log.Warn().Dur("offset", offset).Dur("threshold", csm.threshold).Msg("Local clock is out of sync, payments may be rejected by peers")
+	case wasExceeded:
+		log.Info().Dur("offset", offset).Msg("Local clock is in sync again")
+	default:
+		return
+	}
+
+	csm.publisher.Publish(event.AppTopicClockSkew, event.AppEventClockSkew{
+		Offset:    offset,
+		Threshold: csm.threshold,
+		Exceeded:  exceeded,
+	})
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func TestClockSkewMonitor_DetectsDrift(t *testing.T) {
+	remote := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Date", remote.Format(http.TimeFormat))
+	}))
+	defer server.Close()
+
+	publisher := mocks.NewEventBus()
+	monitor := NewClockSkewMonitor(http.DefaultClient, publisher, []string{server.URL}, time.Minute, 30*time.Second)
+
+	monitor.now = func() time.Time { return remote.Add(-time.Second) }
+	monitor.check()
+	assert.Equal(t, 1500*time.Millisecond, monitor.Offset())
+	assert.Zero(t, monitor.Skew())
+	assert.Nil(t, publisher.Pop())
+
+	monitor.now = func() time.Time { return remote.Add(time.Minute) }
+	monitor.check()
+	assert.Equal(t, -time.Minute+500*time.Millisecond, monitor.Offset())
+	assert.Equal(t, 59500*time.Millisecond, monitor.Skew())
+	assert.Equal(t, event.AppEventClockSkew{
+		Offset:    -time.Minute + 500*time.Millisecond,
+		Threshold: 30 * time.Second,
+		Exceeded:  true,
+	}, publisher.Pop())
+
+	monitor.now = func() time.Time { return remote }
+	monitor.check()
+	assert.Zero(t, monitor.Skew())
+	assert.False(t, publisher.Pop().(event.AppEventClockSkew).Exceeded)
+}
+
+func TestClockSkewMonitor_UsesMedianOffset(t *testing.T) {
+	remote := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	newServer := func(drift time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Date", remote.Add(drift).Format(http.TimeFormat))
+		}))
+	}
+	sane, saneToo, broken := newServer(0), newServer(time.Second), newServer(time.Hour)
+	defer sane.Close()
+	defer saneToo.Close()
+	defer broken.Close()
+
+	publisher := mocks.NewEventBus()
+	monitor := NewClockSkewMonitor(http.DefaultClient, publisher, []string{broken.URL, sane.URL, "http://127.0.0.1:0", saneToo.URL}, time.Minute, 30*time.Second)
+	monitor.now = func() time.Time { return remote }
+
+	monitor.check()
+	assert.Equal(t, 1500*time.Millisecond, monitor.Offset())
+	assert.Nil(t, publisher.Pop())
+}
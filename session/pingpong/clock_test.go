@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/mbtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClockTimer struct {
+	deadline time.Duration
+	c        chan time.Time
+}
+
+type mockClock struct {
+	lock   sync.Mutex
+	wall   time.Time
+	mono   time.Duration
+	timers []mockClockTimer
+}
+
+func newMockClock() *mockClock {
+	return &mockClock{wall: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (mc *mockClock) Now() time.Time {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	return mc.wall
+}
+
+func (mc *mockClock) After(d time.Duration) <-chan time.Time {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	c := make(chan time.Time, 1)
+	mc.timers = append(mc.timers, mockClockTimer{deadline: mc.mono + d, c: c})
+	return c
+}
+
+func (mc *mockClock) Monotonic() mbtime.Time {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	return mbtime.New(0, int64(mc.mono))
+}
+
+// Advance moves both clocks forward and fires the timers which are due.
+func (mc *mockClock) Advance(d time.Duration) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	mc.wall = mc.wall.Add(d)
+	mc.mono += d
+
+	pending := mc.timers[:0]
+	for _, timer := range mc.timers {
+		if timer.deadline <= mc.mono {
+			timer.c <- mc.wall
+		} else {
+			pending = append(pending, timer)
+		}
+	}
+	mc.timers = pending
+}
+
+// SetWall adjusts the wall clock only, as NTP or the user would.
+func (mc *mockClock) SetWall(t time.Time) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	mc.wall = t
+}
+
+func (mc *mockClock) waitTimers(t *testing.T, count int) {
+	require.Eventually(t, func() bool {
+		mc.lock.Lock()
+		defer mc.lock.Unlock()
+		return len(mc.timers) == count
+	}, 2*time.Second, time.Millisecond)
+}
+
+func TestInvoiceTracker_ChargePeriodFollowsClock(t *testing.T) {
+	clock := newMockClock()
+	tracker := session.NewTracker(clock.Monotonic)
+	tracker.StartTracking()
+	invoiceTracker := NewInvoiceTracker(InvoiceTrackerDeps{
+		TimeTracker:       &tracker,
+		EventBus:          mocks.NewEventBus(),
+		AgreedPrice:       *market.NewPrice(0, 0),
+		MaxNotPaidInvoice: big.NewInt(100),
+		ChargePeriod:      time.Minute,
+		LimitChargePeriod: time.Minute,
+		Clock:             clock,
+	})
+	defer invoiceTracker.Stop()
+	go invoiceTracker.sendInvoicesWhenNeeded(time.Second)
+
+	for i := 0; i < 60; i++ {
+		clock.waitTimers(t, 1)
+		if i == 30 {
+			// Wall clock adjustments do not affect the charge period.
+			clock.SetWall(clock.Now().Add(-time.Hour))
+		}
+		clock.Advance(time.Second)
+	}
+	// Scheduler is waiting for the next tick, so no invoice was sent within the charge period.
+	clock.waitTimers(t, 1)
+
+	clock.Advance(time.Second)
+	select {
+	case critical := <-invoiceTracker.invoiceChannel:
+		assert.False(t, critical)
+	case <-time.After(2 * time.Second):
+		t.Fatal("invoice was not sent after the charge period")
+	}
+	assert.Equal(t, 61*time.Second, invoiceTracker.lastInvoiceSent)
+}
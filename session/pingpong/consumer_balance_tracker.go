@@ -26,7 +26,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/config"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
@@ -35,6 +34,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity/registry"
 	pevent "github.com/mysteriumnetwork/node/pilvytis"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/utils/retry"
 	"github.com/mysteriumnetwork/payments/client"
 	"github.com/mysteriumnetwork/payments/units"
 	"github.com/rs/zerolog/log"
@@ -334,24 +334,9 @@ func (cbt *ConsumerBalanceTracker) periodicSync(stop <-chan struct{}, chainID in
 }
 
 func (cbt *ConsumerBalanceTracker) alignWithHermes(chainID int64, id identity.Identity) (*big.Int, *big.Int, error) {
-	var boff backoff.BackOff
-	eback := backoff.NewExponentialBackOff()
-	eback.MaxElapsedTime = time.Second * 15
-	eback.InitialInterval = time.Second * 1
-
-	boff = backoff.WithMaxRetries(eback, 5)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := retry.UntilClosed(cbt.stop)
 	defer cancel()
 
-	go func() {
-		select {
-		case <-cbt.stop:
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
-
-	boff = backoff.WithContext(boff, ctx)
 	balance := cbt.GetBalance(chainID, id)
 	promised := new(big.Int)
 	alignBalance := func() error {
@@ -359,23 +344,20 @@ func (cbt *ConsumerBalanceTracker) alignWithHermes(chainID int64, id identity.Id
 		if err != nil {
 			var syntax *json.SyntaxError
 			if errors.As(err, &syntax) {
-				cancel()
 				log.Err(err).Msg("hermes response is malformed JSON can't check if offchain")
-				return err
+				return retry.Permanent(err)
 			}
 
 			if errors.Is(err, ErrHermesNotFound) {
 				// Hermes doesn't know about this identity meaning it's not offchain. Cancel.
-				cancel()
-				return errBalanceNotOffchain
+				return retry.Permanent(errBalanceNotOffchain)
 			}
 
 			return err
 		}
 		if !consumer.IsOffchain {
 			// Hermes knows about this identity, but it's not offchain. Cancel.
-			cancel()
-			return errBalanceNotOffchain
+			return retry.Permanent(errBalanceNotOffchain)
 		}
 
 		if consumer.LatestPromise.Amount != nil {
@@ -397,7 +379,7 @@ func (cbt *ConsumerBalanceTracker) alignWithHermes(chainID int64, id identity.Id
 		return nil
 	}
 
-	return balance, promised, backoff.Retry(alignBalance, boff)
+	return balance, promised, retry.Do(ctx, retry.HermesSync, alignBalance)
 }
 
 // ForceBalanceUpdateCached forces a balance update for the given identity only if the last call to this func was done no sooner than a minute ago.
@@ -439,6 +421,18 @@ func getKeyForForceBalanceCache(chainID int64, id identity.Identity) string {
 
 // ForceBalanceUpdate forces a balance update and returns the updated balance
 func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(chainID int64, id identity.Identity) *big.Int {
+	balance, _ := cbt.forceBalanceUpdate(chainID, id)
+	return balance
+}
+
+// FetchBalance forces a balance update like ForceBalanceUpdate, but reports a failed lookup
+// instead of falling back to the last known balance.
+func (cbt *ConsumerBalanceTracker) FetchBalance(chainID int64, id identity.Identity) (*big.Int, error) {
+	return cbt.forceBalanceUpdate(chainID, id)
+}
+
+// forceBalanceUpdate returns the updated balance, or the last known balance together with the error if the update failed.
+func (cbt *ConsumerBalanceTracker) forceBalanceUpdate(chainID int64, id identity.Identity) (*big.Int, error) {
 	fallback, ok := cbt.getBalance(chainID, id)
 	if !ok {
 		fallback.BCBalance = big.NewInt(0)
@@ -447,13 +441,13 @@ func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(chainID int64, id identity
 	addr, err := cbt.addressProvider.GetActiveChannelAddress(chainID, id.ToCommonAddress())
 	if err != nil {
 		log.Error().Err(err).Msg("Could not calculate channel address")
-		return fallback.BCBalance
+		return fallback.BCBalance, err
 	}
 
 	myst, err := cbt.addressProvider.GetMystAddress(chainID)
 	if err != nil {
 		log.Error().Err(err).Msg("could not get myst address")
-		return new(big.Int)
+		return new(big.Int), err
 	}
 
 	balance, lastPromised, err := cbt.alignWithHermes(chainID, id)
@@ -463,10 +457,10 @@ func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(chainID int64, id identity
 		}
 		if !errors.Is(err, errBalanceNotOffchain) && fallback.IsOffchain {
 			log.Warn().Msg("offchain sync failed but found a cache entry, will return that")
-			return fallback.BCBalance
+			return fallback.BCBalance, err
 		}
 	} else {
-		return balance
+		return balance, nil
 	}
 
 	cc, err := cbt.consumerBalanceChecker.GetConsumerChannel(chainID, addr, myst)
@@ -475,7 +469,7 @@ func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(chainID int64, id identity
 		log.Warn().Err(err).Msg("Could not get consumer channel")
 		if client.IsErrConnectionFailed(err) {
 			log.Debug().Msg("tried to get consumer channel and got a connection error, will return last known balance")
-			return fallback.BCBalance
+			return fallback.BCBalance, err
 		}
 
 		var unregisteredBalance *big.Int
@@ -489,7 +483,7 @@ func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(chainID int64, id identity
 			unregisteredBalance, err = cbt.getUnregisteredChannelBalance(chainID, id)
 			if err != nil {
 				log.Error().Err(err).Msg("could not get unregistered balance")
-				return fallback.BCBalance
+				return fallback.BCBalance, err
 			}
 		}
 
@@ -501,13 +495,13 @@ func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(chainID int64, id identity
 
 		currentBalance, _ := cbt.getBalance(chainID, id)
 		go cbt.publishChangeEvent(id, new(big.Int), currentBalance.GetBalance())
-		return unregisteredBalance
+		return unregisteredBalance, nil
 	}
 
 	hermes, err := cbt.addressProvider.GetActiveHermes(chainID)
 	if err != nil {
 		log.Error().Err(err).Msg("could not get active hermes address")
-		return fallback.BCBalance
+		return fallback.BCBalance, err
 	}
 
 	grandTotal, err := cbt.consumerGrandTotalsStorage.Get(chainID, id, hermes)
@@ -519,7 +513,7 @@ func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(chainID int64, id identity
 	}
 	if err != nil && !errors.Is(err, ErrNotFound) {
 		log.Error().Err(err).Msg("Could not get consumer grand total promised")
-		return fallback.BCBalance
+		return fallback.BCBalance, err
 	}
 
 	var before = new(big.Int)
@@ -535,7 +529,7 @@ func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(chainID int64, id identity
 
 	currentBalance, _ := cbt.getBalance(chainID, id)
 	go cbt.publishChangeEvent(id, before, currentBalance.GetBalance())
-	return currentBalance.GetBalance()
+	return currentBalance.GetBalance(), nil
 }
 
 func (cbt *ConsumerBalanceTracker) handleRegistrationEvent(event registry.AppEventIdentityRegistration) {
@@ -612,25 +606,10 @@ func (cbt *ConsumerBalanceTracker) getTransactorBalance(ctx context.Context, cha
 }
 
 func (cbt *ConsumerBalanceTracker) recoverGrandTotalPromised(chainID int64, identity identity.Identity) error {
-	var boff backoff.BackOff
-	eback := backoff.NewExponentialBackOff()
-	eback.MaxElapsedTime = time.Second * 20
-	eback.InitialInterval = time.Second * 2
-
-	boff = backoff.WithMaxRetries(eback, 10)
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := retry.UntilClosed(cbt.stop)
 	defer cancel()
 
-	go func() {
-		select {
-		case <-cbt.stop:
-			cancel()
-		case <-ctx.Done():
-		}
-	}()
-
 	var data HermesUserInfo
-	boff = backoff.WithContext(boff, ctx)
 	toRetry := func() error {
 		d, err := cbt.consumerInfoGetter.GetConsumerData(chainID, identity.Address)
 		if err != nil {
@@ -644,7 +623,7 @@ func (cbt *ConsumerBalanceTracker) recoverGrandTotalPromised(chainID int64, iden
 		return nil
 	}
 
-	if err := backoff.Retry(toRetry, boff); err != nil {
+	if err := retry.Do(ctx, retry.HermesSync, toRetry); err != nil {
 		return err
 	}
 
@@ -734,7 +713,6 @@ func (cbt *ConsumerBalanceTracker) updateGrandTotal(chainID int64, id identity.I
 // identityRegistrationStatus returns the registration status of a given identity.
 func (cbt *ConsumerBalanceTracker) identityRegistrationStatus(ctx context.Context, id identity.Identity, chainID int64) (registry.TransactorStatusResponse, error) {
 	var data registry.TransactorStatusResponse
-	boff := backoff.WithContext(backoff.NewConstantBackOff(time.Millisecond*500), ctx)
 	toRetry := func() error {
 		resp, err := cbt.transactorRegistrationStatusProvider.FetchRegistrationStatus(id.Address)
 		if err != nil {
@@ -751,14 +729,14 @@ func (cbt *ConsumerBalanceTracker) identityRegistrationStatus(ctx context.Contex
 
 		if status == nil {
 			err := fmt.Errorf("got response but failed to find status for id '%s' on chain '%d'", id.Address, chainID)
-			return backoff.Permanent(err)
+			return retry.Permanent(err)
 		}
 
 		data = *status
 		return nil
 	}
 
-	return data, backoff.Retry(toRetry, boff)
+	return data, retry.Do(ctx, retry.TransactorStatus, toRetry)
 }
 
 func safeSub(a, b *big.Int) *big.Int {
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// hermesFeeDenominator is the denominator of the hermes fee, which is set in myriads.
+const hermesFeeDenominator = 10000
+
+// SessionCostEstimate is the expected cost of a session for the consumer.
+type SessionCostEstimate struct {
+	// Service is the amount paid to the provider.
+	Service *big.Int
+	// HermesFee is charged by the hermes on top of the service amount.
+	HermesFee *big.Int
+	// TransactorFee is the cost of settling the session payments on chain.
+	TransactorFee *big.Int
+	// Total is the sum of all of the above.
+	Total *big.Int
+	// PerHour is the service amount and the hermes fee scaled to an hour of the planned duration,
+	// it is nil if the duration is not known. The transactor fee does not grow with time and is left out.
+	PerHour *big.Int
+}
+
+// EstimateSessionCost estimates the cost of a session with the given duration and traffic
+// using the same calculation as the invoices do.
+func EstimateSessionCost(price market.Price, duration time.Duration, data uint64, hermesFeePerMyriad uint16, transactorFee *big.Int) SessionCostEstimate {
+	service := CalculatePaymentAmount(duration, DataTransferred{Down: data}, price)
+
+	hermesFee := new(big.Int).Mul(service, big.NewInt(int64(hermesFeePerMyriad)))
+	hermesFee.Div(hermesFee, big.NewInt(hermesFeeDenominator))
+
+	if transactorFee == nil || service.Sign() == 0 {
+		// Nothing is settled for free sessions.
+		transactorFee = new(big.Int)
+	}
+
+	estimate := SessionCostEstimate{
+		Service:       service,
+		HermesFee:     hermesFee,
+		TransactorFee: new(big.Int).Set(transactorFee),
+		Total:         new(big.Int).Add(service, hermesFee),
+	}
+	estimate.Total.Add(estimate.Total, estimate.TransactorFee)
+
+	if duration > 0 {
+		perHour := new(big.Int).Add(service, hermesFee)
+		perHour.Mul(perHour, big.NewInt(int64(time.Hour)))
+		estimate.PerHour = perHour.Div(perHour, big.NewInt(int64(duration)))
+	}
+	return estimate
+}
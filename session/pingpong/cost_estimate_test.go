@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func TestEstimateSessionCost(t *testing.T) {
+	price := *market.NewPrice(1000, 2000)
+
+	estimate := EstimateSessionCost(price, 30*time.Minute, 1024*1024*1024, 2000, big.NewInt(100))
+	assert.Equal(t, big.NewInt(2500), estimate.Service)
+	assert.Equal(t, big.NewInt(500), estimate.HermesFee)
+	assert.Equal(t, big.NewInt(100), estimate.TransactorFee)
+	assert.Equal(t, big.NewInt(3100), estimate.Total)
+	assert.Equal(t, big.NewInt(6000), estimate.PerHour)
+}
+
+func TestEstimateSessionCost_DataOnly(t *testing.T) {
+	estimate := EstimateSessionCost(*market.NewPrice(1000, 2000), 0, 512*1024*1024, 0, nil)
+	assert.Equal(t, big.NewInt(1000), estimate.Service)
+	assert.Equal(t, big.NewInt(0), estimate.TransactorFee)
+	assert.Equal(t, big.NewInt(1000), estimate.Total)
+	assert.Nil(t, estimate.PerHour)
+}
+
+func TestEstimateSessionCost_Free(t *testing.T) {
+	estimate := EstimateSessionCost(*market.NewPrice(0, 0), time.Hour, 1024, 2000, big.NewInt(100))
+	assert.Equal(t, big.NewInt(0), estimate.Total)
+	assert.Equal(t, big.NewInt(0), estimate.PerHour)
+}
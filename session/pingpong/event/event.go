@@ -19,8 +19,10 @@ package event
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -40,8 +42,104 @@ const (
 	AppTopicSettlementComplete = "provider_settlement_complete"
 	// AppTopicWithdrawalRequested topic for succesfull withdrawal requests.
 	AppTopicWithdrawalRequested = "provider_withdrawal_requested"
+	// AppTopicWithdrawalJob topic for progress of the guided withdrawal jobs.
+	AppTopicWithdrawalJob = "provider_withdrawal_job"
+	// AppTopicHermesAvailability topic for the changes of hermes availability.
+	AppTopicHermesAvailability = "hermes_availability"
+	// AppTopicClockSkew topic for warnings about the local clock drifting away from the remote servers.
+	AppTopicClockSkew = "clock_skew"
+	// AppTopicBillingAnomaly topic for sessions which provider bills in a suspicious way.
+	AppTopicBillingAnomaly = "billing_anomaly"
+	// AppTopicHermesPromiseShortfall topic for hermes promises which cover less than the consumer paid for.
+	AppTopicHermesPromiseShortfall = "hermes_promise_shortfall"
+	// AppTopicInvoiceTrackerStuck topic for diagnostics of sessions whose payment goroutines stopped making progress.
+	AppTopicInvoiceTrackerStuck = "invoice_tracker_stuck"
+	// AppTopicConsumerPayment topic for the payment state of the ongoing consumer sessions.
+	AppTopicConsumerPayment = "consumer_payment"
+	// AppTopicHermesFeeChanged represents the topic to which hermes fee raises above the allowed limit during a session are published.
+	AppTopicHermesFeeChanged = "hermes_fee_changed"
 )
 
+// AppEventConsumerPayment is an update on the payment state of an ongoing consumer session.
+type AppEventConsumerPayment struct {
+	SessionID         string
+	ConsumerID        identity.Identity
+	LastInvoiceAmount *big.Int
+	LastInvoiceAt     time.Time
+	// Hashlock of the last invoice, the promise for it is not redeemable until provider reveals its R.
+	Hashlock       string
+	LastPromiseAt  time.Time
+	AgreementTotal *big.Int
+	// TotalPromised is the cumulative amount promised to hermes by the consumer channel.
+	TotalPromised *big.Int
+	// LastError is the reason the last invoice was not paid, empty if it was.
+	LastError string
+}
+
+// AppEventHermesFeeChanged is published when hermes raised its fee above the limit during a session
+// and when the provider policy was applied to the session after the grace period.
+type AppEventHermesFeeChanged struct {
+	SessionID   string
+	HermesID    common.Address
+	Fee         uint16
+	PreviousFee uint16
+	MaxFee      uint16
+	// Policy is the provider policy applied to the session once the Deadline passes.
+	Policy   string
+	Deadline time.Time
+	// Applied indicates that the grace period passed and the policy was applied.
+	Applied bool
+	// PricePerHour and PricePerGiB are the renegotiated session price, nil unless the price was renegotiated.
+	PricePerHour *big.Int
+	PricePerGiB  *big.Int
+}
+
+// AppEventHermesAvailability represents the hermes availability check result which changed the availability.
+type AppEventHermesAvailability struct {
+	ChainID   int64
+	HermesID  common.Address
+	Available bool
+	Latency   time.Duration
+	Error     string
+}
+
+// AppEventClockSkew represents the measured drift of the local clock.
+type AppEventClockSkew struct {
+	Offset    time.Duration
+	Threshold time.Duration
+	Exceeded  bool
+}
+
+// AppEventBillingAnomaly represents a session whose invoiced amount is not justified by its traffic and duration.
+type AppEventBillingAnomaly struct {
+	SessionID  string
+	ConsumerID identity.Identity
+	Anomaly    string
+	Amount     *big.Int
+	Invoiced   *big.Int
+	Paused     bool
+}
+
+// AppEventInvoiceTrackerStuck represents the diagnostics of a session terminated because its payment goroutines got stuck.
+type AppEventInvoiceTrackerStuck struct {
+	SessionID  string
+	Goroutines []string
+	LastTicks  map[string]time.Time
+	Deadline   time.Duration
+	Stack      string
+}
+
+// AppEventHermesPromiseShortfall represents a hermes promise which covers less than the exchange message asked for.
+type AppEventHermesPromiseShortfall struct {
+	SessionID  string
+	ProviderID identity.Identity
+	HermesID   common.Address
+	Requested  *big.Int
+	Covered    *big.Int
+	// Total is the shortfall accumulated during the whole session.
+	Total *big.Int
+}
+
 // AppEventSettlementRequest represents the payload that is sent on the AppTopicSettlementRequest topic.
 type AppEventSettlementRequest struct {
 	HermesID   common.Address
@@ -113,3 +211,30 @@ type AppEventWithdrawalRequested struct {
 	HermesID           common.Address
 	FromChain, ToChain int64
 }
+
+// AppEventWithdrawalJob represents a progress update of the guided withdrawal job.
+type AppEventWithdrawalJob struct {
+	ID         string
+	ProviderID identity.Identity
+	Status     string
+	Error      string
+}
+
+func init() {
+	eventbus.RegisterSchema(AppTopicHermesPromise, 1, AppEventHermesPromise{})
+	eventbus.RegisterSchema(AppTopicBalanceChanged, 1, AppEventBalanceChanged{})
+	eventbus.RegisterSchema(AppTopicEarningsChanged, 1, AppEventEarningsChanged{})
+	eventbus.RegisterSchema(AppTopicInvoicePaid, 1, AppEventInvoicePaid{})
+	eventbus.RegisterSchema(AppTopicSettlementRequest, 1, AppEventSettlementRequest{})
+	eventbus.RegisterSchema(AppTopicSettlementComplete, 1, AppEventSettlementComplete{})
+	eventbus.RegisterSchema(AppTopicWithdrawalRequested, 1, AppEventWithdrawalRequested{})
+	eventbus.RegisterSchema(AppTopicWithdrawalJob, 1, AppEventWithdrawalJob{})
+	eventbus.RegisterSchema(AppTopicHermesAvailability, 1, AppEventHermesAvailability{})
+	eventbus.RegisterSchema(AppTopicClockSkew, 1, AppEventClockSkew{})
+	eventbus.RegisterSchema(AppTopicBillingAnomaly, 1, AppEventBillingAnomaly{})
+	eventbus.RegisterSchema(AppTopicHermesPromiseShortfall, 1, AppEventHermesPromiseShortfall{})
+	eventbus.RegisterSchema(AppTopicInvoiceTrackerStuck, 1, AppEventInvoiceTrackerStuck{})
+	eventbus.RegisterSchema(AppTopicConsumerPayment, 1, AppEventConsumerPayment{})
+	eventbus.RegisterSchema(AppTopicHermesFeeChanged, 1, AppEventHermesFeeChanged{})
+	eventbus.RegisterSchema(AppTopicGrandTotalChanged, 1, AppEventGrandTotalChanged{})
+}
@@ -21,8 +21,8 @@ import (
 	"context"
 	"time"
 
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/p2p"
-	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/rs/zerolog/log"
 )
@@ -36,39 +36,25 @@ type ExchangeRequest struct {
 
 // ExchangeSender is responsible for sending the exchange messages.
 type ExchangeSender struct {
-	ch p2p.ChannelSender
+	ch    p2p.ChannelSender
+	codec dto.Codec
 }
 
-// NewExchangeSender returns a new instance of exchange message sender.
-func NewExchangeSender(ch p2p.ChannelSender) *ExchangeSender {
+// NewExchangeSender returns a new instance of exchange message sender encoding the messages with the given codec.
+func NewExchangeSender(ch p2p.ChannelSender, codec dto.Codec) *ExchangeSender {
 	return &ExchangeSender{
-		ch: ch,
+		ch:    ch,
+		codec: codec,
 	}
 }
 
 // Send sends the given exchange message.
 func (es *ExchangeSender) Send(em crypto.ExchangeMessage) error {
-	pMessage := &pb.ExchangeMessage{
-		Promise: &pb.Promise{
-			ChannelID: em.Promise.ChannelID,
-			Amount:    em.Promise.Amount.Text(bigIntBase),
-			Fee:       em.Promise.Fee.Text(bigIntBase),
-			Hashlock:  em.Promise.Hashlock,
-			R:         em.Promise.R,
-			Signature: em.Promise.Signature,
-			ChainID:   em.Promise.ChainID,
-		},
-		AgreementID:    em.AgreementID.Text(bigIntBase),
-		AgreementTotal: em.AgreementTotal.Text(bigIntBase),
-		Provider:       em.Provider,
-		Signature:      em.Signature,
-		HermesID:       em.HermesID,
-		ChainID:        em.ChainID,
-	}
-	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicPaymentMessage, pMessage.String())
+	msg := dto.NewExchangeMessage(em)
+	log.Debug().Msgf("Sending P2P message to %q: %+v", p2p.TopicPaymentMessage, msg)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
 	defer cancel()
-	_, err := es.ch.Send(ctx, p2p.TopicPaymentMessage, p2p.ProtoMessage(pMessage))
+	_, err := es.ch.Send(ctx, p2p.TopicPaymentMessage, p2p.DTOMessage(es.codec, msg))
 	return err
 }
@@ -28,14 +28,16 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
-	"github.com/mysteriumnetwork/node/session/mbtime"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
@@ -48,12 +50,19 @@ const (
 
 	// DefaultHermesFailureCount defines how many times we're allowed to fail to reach hermes in a row before announcing the failure.
 	DefaultHermesFailureCount uint64 = 10
+
+	// chargePeriodLeeway is how long the provider waits for the consumer to pay before giving up on the session.
+	chargePeriodLeeway = 2 * time.Minute
+
+	// minLeewayChargePeriods is how many charge periods the leeway covers at least,
+	// so that consumers negotiating long charge periods are not dropped after a single late payment.
+	minLeewayChargePeriods = 2
 )
 
 // InvoiceFactoryCreator returns a payment engine factory.
 func InvoiceFactoryCreator(
 	channel p2p.Channel,
-	balanceSendPeriod, limitBalanceSendPeriod, promiseTimeout time.Duration,
+	balanceSendPeriod, limitBalanceSendPeriod, promiseTimeout, initialFreeWindow time.Duration,
 	invoiceStorage providerInvoiceStorage,
 	maxHermesFailureCount uint64,
 	maxAllowedHermesFee uint16,
@@ -63,13 +72,33 @@ func InvoiceFactoryCreator(
 	promiseHandler promiseHandler,
 	addressProvider addressProvider,
 	observer observerApi,
-) func(identity.Identity, identity.Identity, int64, common.Address, string, chan crypto.ExchangeMessage, market.Price) (service.PaymentEngine, error) {
-	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, price market.Price) (service.PaymentEngine, error) {
-		timeTracker := session.NewTracker(mbtime.Now)
+	billingAnomaly BillingAnomalyConfig,
+	hermesFeeChange HermesFeeChangeConfig,
+	peerClockSkew PeerClockSkewConfig,
+	watchdog *InvoiceWatchdog,
+) service.PaymentEngineFactory {
+	return func(providerID, consumerID identity.Identity, chainID int64, hermesID common.Address, sessionID string, exchangeChan chan crypto.ExchangeMessage, price market.Price, negotiatedChargePeriod time.Duration, logger *zerolog.Logger) (service.PaymentEngine, error) {
+		chargePeriod, limitChargePeriod := balanceSendPeriod, limitBalanceSendPeriod
+		if negotiatedChargePeriod > 0 {
+			// Negotiated charge period is kept for the whole session instead of growing towards the limit.
+			chargePeriod, limitChargePeriod = negotiatedChargePeriod, negotiatedChargePeriod
+		}
+		// Charge leeway is settled before the peer clock is measured, so it is widened by the local clock drift only.
+		chargeLeeway := chargePeriodLeeway + peerClockSkew.localSkew()
+		if minLeeway := minLeewayChargePeriods * chargePeriod; chargeLeeway < minLeeway {
+			chargeLeeway = minLeeway
+		}
+
+		clock := SystemClock{}
+		timeTracker := session.NewTracker(clock.Monotonic)
+		invoiceSender := NewInvoiceSender(channel, channel.Codec())
 		deps := InvoiceTrackerDeps{
 			AgreedPrice:                price,
 			Peer:                       consumerID,
-			PeerInvoiceSender:          NewInvoiceSender(channel),
+			PeerInvoiceSender:          invoiceSender,
+			PeerShortfallNotifier:      invoiceSender,
+			PeerHermesFeeNotifier:      invoiceSender,
+			PeerReadinessChecker:       invoiceSender,
 			InvoiceStorage:             invoiceStorage,
 			TimeTracker:                &timeTracker,
 			ExchangeMessageChan:        exchangeChan,
@@ -86,10 +115,19 @@ func InvoiceFactoryCreator(
 			AddressProvider:            addressProvider,
 			MaxNotPaidInvoice:          maxUnpaidInvoiceValue,
 			LimitNotPaidInvoice:        limitUnpaidInvoiceValue,
-			ChargePeriod:               balanceSendPeriod,
-			LimitChargePeriod:          limitBalanceSendPeriod,
-			ChargePeriodLeeway:         2 * time.Minute,
+			ChargePeriod:               chargePeriod,
+			LimitChargePeriod:          limitChargePeriod,
+			ChargePeriodLeeway:         chargeLeeway,
 			Observer:                   observer,
+			InitialFreeWindow:          initialFreeWindow,
+			BillingAnomaly:             billingAnomaly,
+			HermesFeeChange:            hermesFeeChange,
+			PeerClockSkew:              peerClockSkew,
+			Logger:                     logger,
+			Clock:                      clock,
+		}
+		if watchdog != nil {
+			deps.Watchdog = watchdog
 		}
 		paymentEngine := NewInvoiceTracker(deps)
 		return paymentEngine, nil
@@ -103,16 +141,22 @@ func ExchangeFactoryFunc(
 	totalStorage consumerTotalsStorage,
 	addressProvider addressProvider,
 	eventBus eventbus.EventBus,
-	dataLeewayMegabytes uint64) func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal proposal.PricedServiceProposal, price market.Price) (connection.PaymentIssuer, error) {
+	dataLeewayMegabytes uint64,
+	peerClockSkew PeerClockSkewConfig,
+	hermesStatusChecker hermesStatusChecker) func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal proposal.PricedServiceProposal, price market.Price) (connection.PaymentIssuer, error) {
 	return func(channel p2p.Channel, consumer, provider identity.Identity, hermes common.Address, proposal proposal.PricedServiceProposal, price market.Price) (connection.PaymentIssuer, error) {
-		invoices, err := invoiceReceiver(channel)
+		peerClock := NewPeerClockSkew(peerClockSkew)
+		invoices, err := invoiceReceiver(channel, channel.Codec(), peerClock)
 		if err != nil {
 			return nil, err
 		}
-		timeTracker := session.NewTracker(mbtime.Now)
+		shortfallReceiver(channel, hermes, eventBus)
+		readyResponder(channel)
+		clock := SystemClock{}
+		timeTracker := session.NewTracker(clock.Monotonic)
 		deps := InvoicePayerDeps{
 			InvoiceChan:               invoices,
-			PeerExchangeMessageSender: NewExchangeSender(channel),
+			PeerExchangeMessageSender: NewExchangeSender(channel, channel.Codec()),
 			ConsumerTotalsStorage:     totalStorage,
 			TimeTracker:               &timeTracker,
 			Ks:                        keystore,
@@ -124,52 +168,140 @@ func ExchangeFactoryFunc(
 			HermesAddress:             hermes,
 			DataLeeway:                datasize.MiB * datasize.BitSize(dataLeewayMegabytes),
 			ChainID:                   config.GetInt64(config.FlagChainID),
+			Clock:                     clock,
+			PeerClockSkew:             peerClock,
+			HermesStatusChecker:       hermesStatusChecker,
 		}
-		return NewInvoicePayer(deps), nil
+		payer := NewInvoicePayer(deps)
+		hermesFeeReceiver(channel, hermes, eventBus, payer)
+		return payer, nil
 	}
 }
 
-func invoiceReceiver(channel p2p.ChannelHandler) (chan crypto.Invoice, error) {
-	invoices := make(chan crypto.Invoice)
+// readyResponder acknowledges provider readiness checks, it is registered together with
+// the other payment handlers so the acknowledgement means the consumer is ready to pay.
+func readyResponder(channel p2p.ChannelHandler) {
+	channel.Handle(p2p.TopicPaymentReady, func(c p2p.Context) error {
+		var msg pb.SessionInfo
+		if err := c.Request().UnmarshalProto(&msg); err != nil {
+			return err
+		}
 
-	channel.Handle(p2p.TopicPaymentInvoice, func(c p2p.Context) error {
-		var msg pb.Invoice
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicPaymentReady, msg.String())
+		return c.OkWithReply(p2p.ProtoMessage(&msg))
+	})
+}
+
+// shortfallReceiver announces the payments which the provider reports as not covered by hermes.
+func shortfallReceiver(channel p2p.ChannelHandler, hermes common.Address, publisher eventbus.Publisher) {
+	channel.Handle(p2p.TopicPaymentShortfall, func(c p2p.Context) error {
+		var msg pb.PromiseShortfall
 		if err := c.Request().UnmarshalProto(&msg); err != nil {
 			return err
 		}
-		if identity.FromAddress(msg.Provider) != c.PeerID() {
-			return fmt.Errorf("wrong provider identity in invoice. Expected: %s, got: %s",
-				c.PeerID().ToCommonAddress(),
-				identity.FromAddress(msg.GetProvider()).ToCommonAddress(),
-			)
+
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicPaymentShortfall, msg.String())
+
+		amounts := make([]*big.Int, 3)
+		for i, value := range []string{msg.GetRequested(), msg.GetCovered(), msg.GetTotal()} {
+			amount, ok := new(big.Int).SetString(value, bigIntBase)
+			if !ok {
+				return fmt.Errorf("could not unmarshal shortfall amount of value %v", value)
+			}
+			amounts[i] = amount
 		}
 
-		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicPaymentInvoice, msg.String())
+		log.Warn().Msgf("Provider %s reports that hermes did not cover %v of session %s payments", c.PeerID().Address, amounts[2], msg.GetSessionID())
+		publisher.Publish(event.AppTopicHermesPromiseShortfall, event.AppEventHermesPromiseShortfall{
+			SessionID:  msg.GetSessionID(),
+			ProviderID: c.PeerID(),
+			HermesID:   hermes,
+			Requested:  amounts[0],
+			Covered:    amounts[1],
+			Total:      amounts[2],
+		})
+		return nil
+	})
+}
 
-		agreementID, ok := new(big.Int).SetString(msg.GetAgreementID(), bigIntBase)
-		if !ok {
-			return fmt.Errorf("could not unmarshal field agreementID of value %v", agreementID)
+// hermesFeeReceiver announces hermes fee raises reported by the provider and accepts the session price renegotiated because of them.
+func hermesFeeReceiver(channel p2p.ChannelHandler, hermes common.Address, publisher eventbus.Publisher, payer *InvoicePayer) {
+	channel.Handle(p2p.TopicPaymentHermesFee, func(c p2p.Context) error {
+		var msg pb.HermesFeeChange
+		if err := c.Request().UnmarshalProto(&msg); err != nil {
+			return err
 		}
-		agreementTotal, ok := new(big.Int).SetString(msg.GetAgreementTotal(), bigIntBase)
-		if !ok {
-			return fmt.Errorf("could not unmarshal field agreementTotal of value %v", agreementTotal)
+
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicPaymentHermesFee, msg.String())
+		if err := payer.checkSessionID(msg.GetSessionID()); err != nil {
+			return err
 		}
-		transactorFee, ok := new(big.Int).SetString(msg.GetTransactorFee(), bigIntBase)
-		if !ok {
-			return fmt.Errorf("could not unmarshal field transactorFee of value %v", transactorFee)
+
+		change := HermesFeeChange{
+			Fee:         uint16(msg.GetFee()),
+			PreviousFee: uint16(msg.GetPreviousFee()),
+			MaxFee:      uint16(msg.GetMaxFee()),
+			Policy:      HermesFeePolicy(msg.GetPolicy()),
+			Deadline:    time.Unix(msg.GetDeadline(), 0).UTC(),
+			Applied:     msg.GetApplied(),
 		}
+		if msg.GetPricePerHour() != "" || msg.GetPricePerGiB() != "" {
+			amounts := make([]*big.Int, 2)
+			for i, value := range []string{msg.GetPricePerHour(), msg.GetPricePerGiB()} {
+				amount, ok := new(big.Int).SetString(value, bigIntBase)
+				if !ok {
+					return fmt.Errorf("could not unmarshal renegotiated price of value %v", value)
+				}
+				amounts[i] = amount
+			}
+			change.Price = &market.Price{PricePerHour: amounts[0], PricePerGiB: amounts[1]}
 
-		invoices <- crypto.Invoice{
-			AgreementID:    agreementID,
-			AgreementTotal: agreementTotal,
-			TransactorFee:  transactorFee,
-			Hashlock:       msg.GetHashlock(),
-			Provider:       msg.GetProvider(),
-			ChainID:        msg.GetChainID(),
+			previousFee, fee, err := payer.acceptRenegotiatedPrice(msg.GetSessionID(), *change.Price)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Rejected price renegotiated by provider %s for session %s", c.PeerID().Address, msg.GetSessionID())
+				return err
+			}
+			change.PreviousFee, change.Fee = previousFee, fee
 		}
 
+		log.Warn().Msgf("Provider %s reports that hermes raised its fee to %v for session %s, policy: %s", c.PeerID().Address, change.Fee, msg.GetSessionID(), change.Policy)
+		publisher.Publish(event.AppTopicHermesFeeChanged, hermesFeeChangedEvent(msg.GetSessionID(), hermes, change))
 		return nil
 	})
+}
+
+// invoiceReceiver passes the invoices of the provider to the payer. Invoices stamped with the provider's time
+// are used to measure the peer clock skew and are replied with a receipt, so the provider can measure it too.
+func invoiceReceiver(channel p2p.ChannelHandler, codec dto.Codec, peerClock *PeerClockSkew) (chan crypto.Invoice, error) {
+	invoices := make(chan crypto.Invoice)
+
+	channel.Handle(p2p.TopicPaymentInvoice, func(c p2p.Context) error {
+		receivedAt := time.Now()
+		var msg dto.Invoice
+		if err := c.Request().UnmarshalDTO(codec, &msg); err != nil {
+			return err
+		}
+		if identity.FromAddress(msg.Provider) != c.PeerID() {
+			return fmt.Errorf("wrong provider identity in invoice. Expected: %s, got: %s",
+				c.PeerID().ToCommonAddress(),
+				identity.FromAddress(msg.Provider).ToCommonAddress(),
+			)
+		}
+
+		log.Debug().Msgf("Received P2P message for %q: %+v", p2p.TopicPaymentInvoice, msg)
+
+		invoices <- msg.Crypto()
+
+		if msg.SentAt.IsZero() {
+			return nil
+		}
+		peerClock.ObserveOneWay(msg.SentAt, receivedAt)
+		return c.OkWithReply(p2p.DTOMessage(codec, &dto.InvoiceReceipt{
+			Hashlock:   msg.Hashlock,
+			SentAt:     msg.SentAt,
+			ReceivedAt: receivedAt,
+		}))
+	})
 
 	return invoices, nil
 }
@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+type hermesAvailabilityURLGetter interface {
+	GetHermesURL(chainID int64, address common.Address) (string, error)
+}
+
+type activeHermesProvider interface {
+	GetActiveHermes(chainID int64) (common.Address, error)
+}
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// HermesAvailabilityMonitor periodically checks whether the active hermes of every chain is reachable,
+// regardless of any running sessions, and publishes the check result whenever the availability changes.
+type HermesAvailabilityMonitor struct {
+	urlGetter       hermesAvailabilityURLGetter
+	addressProvider activeHermesProvider
+	client          httpDoer
+	publisher       eventbus.Publisher
+	chains          []int64
+	interval        time.Duration
+
+	lock      sync.Mutex
+	available map[string]bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewHermesAvailabilityMonitor returns a new instance of hermes availability monitor.
+func NewHermesAvailabilityMonitor(
+	urlGetter hermesAvailabilityURLGetter,
+	addressProvider activeHermesProvider,
+	client httpDoer,
+	publisher eventbus.Publisher,
+	chains []int64,
+	interval time.Duration,
+) *HermesAvailabilityMonitor {
+	return &HermesAvailabilityMonitor{
+		urlGetter:       urlGetter,
+		addressProvider: addressProvider,
+		client:          client,
+		publisher:       publisher,
+		chains:          chains,
+		interval:        interval,
+		available:       make(map[string]bool),
+		stop:            make(chan struct{}),
+	}
+}
+
+// Start checks the hermes availability until stopped.
+func (ham *HermesAvailabilityMonitor) Start() {
+	for {
+		ham.checkAll()
+
+		select {
+		case <-ham.stop:
+			return
+		case <-time.After(ham.interval):
+		}
+	}
+}
+
+// Stop stops the hermes availability checks.
+func (ham *HermesAvailabilityMonitor) Stop() {
+	ham.stopOnce.Do(func() {
+		close(ham.stop)
+	})
+}
+
+func (ham *HermesAvailabilityMonitor) checkAll() {
+	for _, chainID := range ham.chains {
+		hermesID, err := ham.addressProvider.GetActiveHermes(chainID)
+		if err != nil {
+			log.Warn().Err(err).Int64("chain_id", chainID).Msg("Could not get active hermes to check availability")
+			continue
+		}
+
+		ham.report(ham.Check(chainID, hermesID))
+	}
+}
+
+// Check probes whether the given hermes is reachable.
+func (ham *HermesAvailabilityMonitor) Check(chainID int64, hermesID common.Address) event.AppEventHermesAvailability {
+	result := event.AppEventHermesAvailability{
+		ChainID:  chainID,
+		HermesID: hermesID,
+	}
+
+	hermesURL, err := ham.urlGetter.GetHermesURL(chainID, hermesID)
+	if err != nil {
+		result.Error = fmt.Sprintf("could not get hermes url: %v", err)
+		return result
+	}
+
+	result.Latency, err = ham.probe(hermesURL)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Available = true
+	return result
+}
+
+// probe considers hermes available if it responds to the request without a server error.
+func (ham *HermesAvailabilityMonitor) probe(hermesURL string) (time.Duration, error) {
+	req, err := http.NewRequest(http.MethodGet, hermesURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not create hermes request: %w", err)
+	}
+
+	started := time.Now()
+	resp, err := ham.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("could not reach hermes: %w", err)
+	}
+	defer resp.Body.Close()
+	latency := time.Since(started)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return latency, fmt.Errorf("hermes responded with status %d", resp.StatusCode)
+	}
+	return latency, nil
+}
+
+func (ham *HermesAvailabilityMonitor) report(result event.AppEventHermesAvailability) {
+	key := fmt.Sprintf("%v_%v", result.HermesID.Hex(), result.ChainID)
+
+	ham.lock.Lock()
+	wasAvailable, checked := ham.available[key]
+	ham.available[key] = result.Available
+	ham.lock.Unlock()
+
+	fields := map[string]interface{}{
+		"chain_id":  result.ChainID,
+		"hermes_id": result.HermesID.Hex(),
+	}
+	switch {
+	case checked && result.Available == wasAvailable:
+		return
+	case !result.Available:
+		log.Warn().Fields(fields).Str("error", result.Error).Msg("Hermes is unavailable, settlements and sessions may fail")
+	case checked:
+		log.Info().Fields(fields).Dur("latency", result.Latency).Msg("Hermes is available again")
+	}
+
+	ham.publisher.Publish(event.AppTopicHermesAvailability, result)
+}
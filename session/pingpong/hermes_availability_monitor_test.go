@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+type mockHermesAvailabilityURLGetter struct {
+	url string
+	err error
+}
+
+func (m *mockHermesAvailabilityURLGetter) GetHermesURL(_ int64, _ common.Address) (string, error) {
+	return m.url, m.err
+}
+
+func TestHermesAvailabilityMonitor_PublishesAvailabilityChanges(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := mocks.NewEventBus()
+	monitor := NewHermesAvailabilityMonitor(
+		&mockHermesAvailabilityURLGetter{url: server.URL},
+		&mockAddressProvider{},
+		http.DefaultClient,
+		publisher,
+		[]int64{1},
+		time.Minute,
+	)
+
+	monitor.checkAll()
+	result := publisher.Pop().(event.AppEventHermesAvailability)
+	assert.True(t, result.Available)
+	assert.Equal(t, int64(1), result.ChainID)
+	assert.Empty(t, result.Error)
+
+	monitor.checkAll()
+	assert.Nil(t, publisher.Pop())
+
+	status = http.StatusServiceUnavailable
+	monitor.checkAll()
+	result = publisher.Pop().(event.AppEventHermesAvailability)
+	assert.False(t, result.Available)
+	assert.Equal(t, "hermes responded with status 503", result.Error)
+
+	monitor.checkAll()
+	assert.Nil(t, publisher.Pop())
+
+	status = http.StatusNotFound
+	monitor.checkAll()
+	result = publisher.Pop().(event.AppEventHermesAvailability)
+	assert.True(t, result.Available)
+}
+
+func TestHermesAvailabilityMonitor_ReportsUnknownURL(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	monitor := NewHermesAvailabilityMonitor(
+		&mockHermesAvailabilityURLGetter{err: errors.New("boom")},
+		&mockAddressProvider{},
+		http.DefaultClient,
+		publisher,
+		[]int64{1, 2},
+		time.Minute,
+	)
+
+	monitor.checkAll()
+	history := publisher.GetEventHistory()
+	assert.Len(t, history, 2)
+	for _, entry := range history {
+		assert.Equal(t, event.AppTopicHermesAvailability, entry.Topic)
+		result := entry.Event.(event.AppEventHermesAvailability)
+		assert.False(t, result.Available)
+		assert.Equal(t, "could not get hermes url: boom", result.Error)
+	}
+}
+
+func TestHermesAvailabilityMonitor_Stop(t *testing.T) {
+	monitor := NewHermesAvailabilityMonitor(
+		&mockHermesAvailabilityURLGetter{err: errors.New("boom")},
+		&mockAddressProvider{},
+		http.DefaultClient,
+		mocks.NewEventBus(),
+		nil,
+		time.Hour,
+	)
+
+	done := make(chan struct{})
+	go func() {
+		monitor.Start()
+		close(done)
+	}()
+	monitor.Stop()
+	monitor.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("monitor did not stop")
+	}
+}
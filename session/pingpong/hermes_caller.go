@@ -29,10 +29,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/node/utils/retry"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
@@ -122,19 +122,12 @@ func (ac *HermesCaller) RequestPromise(rp RequestPromise) (crypto.Promise, error
 }
 
 func (ac *HermesCaller) promiseRequest(rp RequestPromise, endpoint string) (crypto.Promise, error) {
-	eback := backoff.NewConstantBackOff(time.Millisecond * 500)
-	boff := backoff.WithMaxRetries(eback, 3)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	boff = backoff.WithContext(boff, ctx)
-
 	res := crypto.Promise{}
 
-	return res, backoff.Retry(func() error {
+	return res, retry.Do(context.Background(), retry.Hermes, func() error {
 		req, err := requests.NewPostRequest(ac.hermesBaseURI, endpoint, rp)
 		if err != nil {
-			cancel()
-			return fmt.Errorf("could not form %v request: %w", endpoint, err)
+			return retry.Permanent(fmt.Errorf("could not form %v request: %w", endpoint, err))
 		}
 
 		err = ac.doRequest(req, &res)
@@ -144,11 +137,10 @@ func (ac *HermesCaller) promiseRequest(rp RequestPromise, endpoint string) (cryp
 				return err
 			}
 			// otherwise, do not retry anymore and return the error
-			cancel()
-			return fmt.Errorf("could not request promise: %w", err)
+			return retry.Permanent(fmt.Errorf("could not request promise: %w", err))
 		}
 		return nil
-	}, boff)
+	})
 }
 
 // PayAndSettle requests a promise from hermes.
@@ -185,20 +177,14 @@ type RevealObject struct {
 
 // RevealR reveals hashlock key 'r' from 'provider' to the hermes for the agreement identified by 'agreementID'.
 func (ac *HermesCaller) RevealR(r, provider string, agreementID *big.Int) error {
-	eback := backoff.NewConstantBackOff(time.Millisecond * 500)
-	boff := backoff.WithMaxRetries(eback, 3)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	boff = backoff.WithContext(boff, ctx)
-	return backoff.Retry(func() error {
+	return retry.Do(context.Background(), retry.Hermes, func() error {
 		req, err := requests.NewPostRequest(ac.hermesBaseURI, "reveal_r", RevealObject{
 			R:           r,
 			Provider:    provider,
 			AgreementID: agreementID,
 		})
 		if err != nil {
-			cancel()
-			return fmt.Errorf("could not form reveal_r request: %w", err)
+			return retry.Permanent(fmt.Errorf("could not form reveal_r request: %w", err))
 		}
 
 		err = ac.doRequest(req, &RevealSuccess{})
@@ -208,11 +194,10 @@ func (ac *HermesCaller) RevealR(r, provider string, agreementID *big.Int) error
 				return err
 			}
 			// otherwise, do not retry anymore and return the error
-			cancel()
-			return fmt.Errorf("could not reveal R for hermes: %w", err)
+			return retry.Permanent(fmt.Errorf("could not reveal R for hermes: %w", err))
 		}
 		return nil
-	}, boff)
+	})
 }
 
 // IsIdentityOffchain returns true if identity is considered offchain in hermes.
@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// HermesFeePolicy determines what happens to a session once hermes keeps its fee above the allowed limit.
+type HermesFeePolicy string
+
+const (
+	// HermesFeePolicyAbsorb keeps the session running at the agreed price, provider earns less.
+	HermesFeePolicyAbsorb HermesFeePolicy = "absorb"
+	// HermesFeePolicyRenegotiate raises the price of the rest of the session so that provider earns as much as before the fee change,
+	// the session is terminated if the consumer does not accept the new price.
+	HermesFeePolicyRenegotiate HermesFeePolicy = "renegotiate"
+	// HermesFeePolicyTerminate terminates the session.
+	HermesFeePolicyTerminate HermesFeePolicy = "terminate"
+)
+
+// HermesFeeChangeConfig configures the handling of hermes fee changes during the session on provider side.
+type HermesFeeChangeConfig struct {
+	Policy HermesFeePolicy
+	// GracePeriod is how long the session continues unchanged after the fee exceeded the limit.
+	GracePeriod time.Duration
+	// CheckInterval is how often the hermes fee is checked, zero disables the checks after the session start.
+	CheckInterval time.Duration
+}
+
+// HermesFeeChange describes the hermes fee raise reported to the consumer.
+type HermesFeeChange struct {
+	Fee, PreviousFee, MaxFee uint16
+	Policy                   HermesFeePolicy
+	Deadline                 time.Time
+	// Applied indicates that the grace period passed and the policy is being applied.
+	Applied bool
+	// Price is the renegotiated price of the rest of the session, nil unless the price is being renegotiated.
+	Price *market.Price
+}
+
+func hermesFeeChangedEvent(sessionID string, hermesID common.Address, change HermesFeeChange) event.AppEventHermesFeeChanged {
+	ev := event.AppEventHermesFeeChanged{
+		SessionID:   sessionID,
+		HermesID:    hermesID,
+		Fee:         change.Fee,
+		PreviousFee: change.PreviousFee,
+		MaxFee:      change.MaxFee,
+		Policy:      string(change.Policy),
+		Deadline:    change.Deadline,
+		Applied:     change.Applied,
+	}
+	if change.Price != nil {
+		ev.PricePerHour = change.Price.PricePerHour
+		ev.PricePerGiB = change.Price.PricePerGiB
+	}
+	return ev
+}
+
+// errHermesFeeUnbearable indicates that hermes takes the whole promised amount, so no price can compensate the fee.
+var errHermesFeeUnbearable = errors.New("hermes fee leaves nothing to the provider")
+
+// hermesFeeAdjustedPrice returns the price at which the provider earns as much with the new fee as with the previous one.
+func hermesFeeAdjustedPrice(price market.Price, previousFee, fee uint16) (market.Price, error) {
+	if fee >= hermesFeeDenominator || previousFee >= hermesFeeDenominator {
+		return market.Price{}, errHermesFeeUnbearable
+	}
+
+	adjust := func(amount *big.Int) *big.Int {
+		if amount == nil {
+			return nil
+		}
+		numerator := new(big.Int).Mul(amount, big.NewInt(int64(hermesFeeDenominator-previousFee)))
+		denominator := big.NewInt(int64(hermesFeeDenominator - fee))
+		// Rounding up, so that the provider does not lose a wei.
+		numerator.Add(numerator, new(big.Int).Sub(denominator, big.NewInt(1)))
+		return numerator.Div(numerator, denominator)
+	}
+
+	return market.Price{
+		PricePerHour: adjust(price.PricePerHour),
+		PricePerGiB:  adjust(price.PricePerGiB),
+	}, nil
+}
+
+// maxRenegotiatedPriceIncrease is the percentage by which a renegotiated price may exceed the price agreed at the session start.
+const maxRenegotiatedPriceIncrease = 50
+
+// checkRenegotiatedPrice makes sure the renegotiated price is justified by the fee change and
+// stays within maxRenegotiatedPriceIncrease of the price agreed at the session start.
+func checkRenegotiatedPrice(original, agreed, renegotiated market.Price, previousFee, fee uint16) error {
+	if fee <= previousFee {
+		return fmt.Errorf("hermes fee did not increase, %v is not above %v", fee, previousFee)
+	}
+
+	justified, err := hermesFeeAdjustedPrice(agreed, previousFee, fee)
+	if err != nil {
+		return err
+	}
+
+	if renegotiated.PricePerHour.Cmp(justified.PricePerHour) > 0 || renegotiated.PricePerGiB.Cmp(justified.PricePerGiB) > 0 {
+		return fmt.Errorf("renegotiated price %v is above the justified %v", renegotiated.String(), justified.String())
+	}
+
+	limit := func(amount *big.Int) *big.Int {
+		res := new(big.Int).Mul(amount, big.NewInt(100+maxRenegotiatedPriceIncrease))
+		return res.Div(res, big.NewInt(100))
+	}
+	if renegotiated.PricePerHour.Cmp(limit(original.PricePerHour)) > 0 || renegotiated.PricePerGiB.Cmp(limit(original.PricePerGiB)) > 0 {
+		return fmt.Errorf("renegotiated price %v is more than %d%% above the agreed %v", renegotiated.String(), maxRenegotiatedPriceIncrease, original.String())
+	}
+	return nil
+}
+
+// hermesFeeState is the outcome of a single hermes fee check.
+type hermesFeeState int
+
+const (
+	// hermesFeeUnchanged means there is nothing to do.
+	hermesFeeUnchanged hermesFeeState = iota
+	// hermesFeeExceeded means that the fee has just exceeded the limit and the grace period started.
+	hermesFeeExceeded
+	// hermesFeeRestored means that the fee went back within the limit during the grace period.
+	hermesFeeRestored
+	// hermesFeeGraceExpired means that the fee stayed above the limit for the whole grace period.
+	hermesFeeGraceExpired
+)
+
+// hermesFeeMonitor tracks the hermes fee during the session, it is not safe for concurrent use.
+type hermesFeeMonitor struct {
+	config HermesFeeChangeConfig
+	limit  uint16
+	clock  Clock
+
+	// fee is the fee the session price is based on.
+	fee         uint16
+	exceededFee uint16
+	exceededAt  time.Time
+	handled     bool
+}
+
+func newHermesFeeMonitor(config HermesFeeChangeConfig, limit, fee uint16, clock Clock) *hermesFeeMonitor {
+	return &hermesFeeMonitor{
+		config: config,
+		limit:  limit,
+		clock:  clock,
+		fee:    fee,
+	}
+}
+
+// check records the current hermes fee and reports what should be done about it.
+func (m *hermesFeeMonitor) check(fee uint16) hermesFeeState {
+	if fee <= m.limit {
+		if m.exceededAt.IsZero() {
+			return hermesFeeUnchanged
+		}
+		restored := !m.handled
+		m.exceededAt, m.exceededFee, m.handled = time.Time{}, 0, false
+		if restored {
+			return hermesFeeRestored
+		}
+		return hermesFeeUnchanged
+	}
+
+	// Once handled, only a further change of the fee starts a new grace period.
+	if m.exceededAt.IsZero() || (m.handled && fee != m.exceededFee) {
+		m.exceededAt, m.exceededFee, m.handled = m.clock.Now(), fee, false
+		return hermesFeeExceeded
+	}
+
+	m.exceededFee = fee
+	if m.handled || m.clock.Now().Before(m.deadline()) {
+		return hermesFeeUnchanged
+	}
+
+	m.handled = true
+	return hermesFeeGraceExpired
+}
+
+// deadline returns the time when the grace period of the exceeded fee ends.
+func (m *hermesFeeMonitor) deadline() time.Time {
+	return m.exceededAt.Add(m.config.GracePeriod)
+}
+
+// rebase makes the given fee the one the session price is based on.
+func (m *hermesFeeMonitor) rebase(fee uint16) {
+	m.fee = fee
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func Test_hermesFeeAdjustedPrice(t *testing.T) {
+	price, err := hermesFeeAdjustedPrice(*market.NewPrice(3600, 1001), 1000, 2000)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(4050), price.PricePerHour)
+	// 1001 * 9000 / 8000 = 1126.125, rounded up.
+	assert.Equal(t, big.NewInt(1127), price.PricePerGiB)
+
+	_, err = hermesFeeAdjustedPrice(*market.NewPrice(3600, 0), 1000, hermesFeeDenominator)
+	assert.ErrorIs(t, err, errHermesFeeUnbearable)
+}
+
+func Test_checkRenegotiatedPrice(t *testing.T) {
+	agreed := *market.NewPrice(3600, 1000)
+
+	assert.NoError(t, checkRenegotiatedPrice(agreed, agreed, *market.NewPrice(4050, 1125), 1000, 2000))
+	assert.NoError(t, checkRenegotiatedPrice(agreed, agreed, *market.NewPrice(4000, 1000), 1000, 2000))
+	assert.Error(t, checkRenegotiatedPrice(agreed, agreed, *market.NewPrice(4051, 1125), 1000, 2000))
+	assert.Error(t, checkRenegotiatedPrice(agreed, agreed, *market.NewPrice(3600, 1000), 2000, 2000))
+	// A raise justified by the fee change is still capped.
+	assert.Error(t, checkRenegotiatedPrice(agreed, agreed, *market.NewPrice(36000, 10000), 0, 9000))
+	assert.NoError(t, checkRenegotiatedPrice(agreed, agreed, *market.NewPrice(5400, 1500), 0, 9000))
+}
+
+func TestInvoicePayer_acceptRenegotiatedPrice(t *testing.T) {
+	newPayer := func(fee uint16) (*InvoicePayer, *mockHermesStatusChecker) {
+		checker := &mockHermesStatusChecker{statusToReturn: HermesStatus{IsActive: true, Fee: 1000}}
+		payer := NewInvoicePayer(InvoicePayerDeps{
+			SessionID:           "session",
+			AgreedPrice:         *market.NewPrice(3600, 1000),
+			TimeTracker:         &startRecordingTimeTracker{},
+			AddressProvider:     &mockAddressProvider{},
+			HermesStatusChecker: checker,
+		})
+		payer.recordHermesFee()
+		checker.statusToReturn.Fee = fee
+		return payer, checker
+	}
+
+	t.Run("accepts the price justified by the fee from hermes status", func(t *testing.T) {
+		payer, _ := newPayer(2000)
+
+		previousFee, fee, err := payer.acceptRenegotiatedPrice("session", *market.NewPrice(4050, 1125))
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(1000), previousFee)
+		assert.Equal(t, uint16(2000), fee)
+		assert.Equal(t, *market.NewPrice(4050, 1125), payer.pricing.current())
+	})
+
+	t.Run("rejects the price if hermes did not raise its fee", func(t *testing.T) {
+		payer, _ := newPayer(1000)
+
+		_, _, err := payer.acceptRenegotiatedPrice("session", *market.NewPrice(4050, 1125))
+		assert.Error(t, err)
+		assert.Equal(t, *market.NewPrice(3600, 1000), payer.pricing.current())
+	})
+
+	t.Run("rejects the price of another session", func(t *testing.T) {
+		payer, _ := newPayer(2000)
+
+		_, _, err := payer.acceptRenegotiatedPrice("other", *market.NewPrice(4050, 1125))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects the price if the fee at the session start is unknown", func(t *testing.T) {
+		payer, checker := newPayer(2000)
+		payer.hermesFeeKnown = false
+		checker.errToReturn = errors.New("unavailable")
+
+		_, _, err := payer.acceptRenegotiatedPrice("session", *market.NewPrice(4050, 1125))
+		assert.Error(t, err)
+	})
+}
+
+func Test_hermesFeeMonitor(t *testing.T) {
+	clock := newMockClock()
+	monitor := newHermesFeeMonitor(HermesFeeChangeConfig{GracePeriod: time.Minute}, 1000, 500, clock)
+
+	assert.Equal(t, hermesFeeUnchanged, monitor.check(1000))
+	assert.Equal(t, hermesFeeExceeded, monitor.check(1500))
+	assert.Equal(t, clock.Now().Add(time.Minute), monitor.deadline())
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, hermesFeeUnchanged, monitor.check(1600))
+	assert.Equal(t, hermesFeeRestored, monitor.check(900))
+	assert.Equal(t, hermesFeeExceeded, monitor.check(1500))
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, hermesFeeGraceExpired, monitor.check(1500))
+	assert.Equal(t, hermesFeeUnchanged, monitor.check(1500))
+
+	// A further raise after the policy was applied starts a new grace period.
+	assert.Equal(t, hermesFeeExceeded, monitor.check(1800))
+	assert.Equal(t, hermesFeeUnchanged, monitor.check(1800))
+	assert.Equal(t, hermesFeeRestored, monitor.check(1000))
+	assert.Equal(t, hermesFeeUnchanged, monitor.check(1000))
+}
+
+func Test_sessionPricing(t *testing.T) {
+	pricing := newSessionPricing(*market.NewPrice(1000, 0))
+	assert.Equal(t, big.NewInt(2000), pricing.amount(2*time.Hour, DataTransferred{}))
+
+	pricing.reprice(*market.NewPrice(3000, 0), 2*time.Hour, DataTransferred{})
+	assert.Equal(t, big.NewInt(2000), pricing.amount(2*time.Hour, DataTransferred{}))
+	assert.Equal(t, big.NewInt(5000), pricing.amount(3*time.Hour, DataTransferred{}))
+	assert.Equal(t, *market.NewPrice(3000, 0), pricing.current())
+}
+
+func TestInvoiceTracker_checkHermesFee(t *testing.T) {
+	newTracker := func(policy HermesFeePolicy, notifier *mockHermesFeeNotifier) (*InvoiceTracker, *mocks.EventBus) {
+		bus := mocks.NewEventBus()
+		it := NewInvoiceTracker(InvoiceTrackerDeps{
+			SessionID:             "session",
+			EventBus:              bus,
+			AgreedPrice:           *market.NewPrice(3600, 0),
+			TimeTracker:           &startRecordingTimeTracker{},
+			MaxAllowedHermesFee:   1500,
+			HermesStatusChecker:   &mockHermesStatusChecker{statusToReturn: HermesStatus{IsActive: true, Fee: 2000}},
+			PeerHermesFeeNotifier: notifier,
+			HermesFeeChange:       HermesFeeChangeConfig{Policy: policy},
+		})
+		it.feeMonitor = newHermesFeeMonitor(it.deps.HermesFeeChange, 1500, 1000, SystemClock{})
+		return it, bus
+	}
+
+	t.Run("notifies consumer once the fee exceeds the limit", func(t *testing.T) {
+		notifier := &mockHermesFeeNotifier{}
+		it, bus := newTracker(HermesFeePolicyTerminate, notifier)
+
+		assert.NoError(t, it.checkHermesFee(common.Address{}))
+		assert.Len(t, notifier.sent, 1)
+		assert.False(t, notifier.sent[0].Applied)
+
+		changed, ok := bus.Pop().(event.AppEventHermesFeeChanged)
+		assert.True(t, ok)
+		assert.Equal(t, uint16(2000), changed.Fee)
+		assert.Equal(t, uint16(1000), changed.PreviousFee)
+		assert.Equal(t, "terminate", changed.Policy)
+	})
+
+	t.Run("terminates the session after the grace period", func(t *testing.T) {
+		it, _ := newTracker(HermesFeePolicyTerminate, &mockHermesFeeNotifier{})
+
+		assert.NoError(t, it.checkHermesFee(common.Address{}))
+		assert.ErrorIs(t, it.checkHermesFee(common.Address{}), ErrHermesFeeTooLarge)
+	})
+
+	t.Run("absorbs the fee after the grace period", func(t *testing.T) {
+		notifier := &mockHermesFeeNotifier{}
+		it, _ := newTracker(HermesFeePolicyAbsorb, notifier)
+
+		assert.NoError(t, it.checkHermesFee(common.Address{}))
+		assert.NoError(t, it.checkHermesFee(common.Address{}))
+		assert.Len(t, notifier.sent, 2)
+		assert.True(t, notifier.sent[1].Applied)
+		assert.Equal(t, *market.NewPrice(3600, 0), it.pricing.current())
+	})
+
+	t.Run("renegotiates the price after the grace period", func(t *testing.T) {
+		notifier := &mockHermesFeeNotifier{}
+		it, _ := newTracker(HermesFeePolicyRenegotiate, notifier)
+
+		assert.NoError(t, it.checkHermesFee(common.Address{}))
+		assert.NoError(t, it.checkHermesFee(common.Address{}))
+		assert.Equal(t, big.NewInt(4050), notifier.sent[1].Price.PricePerHour)
+		assert.Equal(t, big.NewInt(4050), it.pricing.current().PricePerHour)
+		assert.Equal(t, uint16(2000), it.feeMonitor.fee)
+	})
+
+	t.Run("terminates the session if consumer rejects the renegotiated price", func(t *testing.T) {
+		it, _ := newTracker(HermesFeePolicyRenegotiate, &mockHermesFeeNotifier{err: errors.New("rejected")})
+
+		assert.NoError(t, it.checkHermesFee(common.Address{}))
+		assert.ErrorIs(t, it.checkHermesFee(common.Address{}), ErrHermesFeeTooLarge)
+		assert.Equal(t, *market.NewPrice(3600, 0), it.pricing.current())
+	})
+}
+
+type mockHermesFeeNotifier struct {
+	sent []HermesFeeChange
+	err  error
+}
+
+func (m *mockHermesFeeNotifier) SendHermesFeeChange(_ string, change HermesFeeChange) error {
+	m.sent = append(m.sent, change)
+	return m.err
+}
@@ -86,6 +86,28 @@ type HermesPromiseHandler struct {
 	stopOnce       sync.Once
 	startOnce      sync.Once
 	transactorFees map[int64]registry.FeesResponse
+
+	coverage     map[string]*promiseCoverage
+	coverageLock sync.Mutex
+}
+
+// promiseCoverage keeps track of how much of the session payments hermes has covered with promises.
+type promiseCoverage struct {
+	requested *big.Int
+	shortfall *big.Int
+}
+
+// PromiseShortfallError indicates that hermes issued a promise for less than the exchange message asked for,
+// e.g. because of the provider stake limits.
+type PromiseShortfallError struct {
+	Requested *big.Int
+	Covered   *big.Int
+	// Total is the shortfall accumulated during the whole session.
+	Total *big.Int
+}
+
+func (e *PromiseShortfallError) Error() string {
+	return fmt.Sprintf("hermes promise covers %v out of requested %v, session shortfall %v", e.Covered, e.Requested, e.Total)
 }
 
 // NewHermesPromiseHandler returns a new instance of hermes promise handler.
@@ -99,6 +121,7 @@ func NewHermesPromiseHandler(deps HermesPromiseHandlerDeps) *HermesPromiseHandle
 		queue:          make(chan enqueuedRequest, 100),
 		stop:           make(chan struct{}),
 		transactorFees: make(map[int64]registry.FeesResponse),
+		coverage:       make(map[string]*promiseCoverage),
 	}
 }
 
@@ -246,9 +269,24 @@ func (aph *HermesPromiseHandler) Subscribe(bus eventbus.Subscriber) error {
 		return fmt.Errorf("could not subscribe to node events: %w", err)
 	}
 
+	err = bus.Subscribe(sessionEvent.AppTopicSession, aph.handleSessionEvents)
+	if err != nil {
+		return fmt.Errorf("could not subscribe to session events: %w", err)
+	}
+
 	return nil
 }
 
+func (aph *HermesPromiseHandler) handleSessionEvents(e sessionEvent.AppEventSession) {
+	if e.Status != sessionEvent.RemovedStatus {
+		return
+	}
+
+	aph.coverageLock.Lock()
+	defer aph.coverageLock.Unlock()
+	delete(aph.coverage, e.Session.ID)
+}
+
 func (aph *HermesPromiseHandler) doStop() {
 	aph.stopOnce.Do(func() {
 		close(aph.stop)
@@ -310,6 +348,8 @@ func (aph *HermesPromiseHandler) requestPromise(er enqueuedRequest) {
 		RRecoveryData:   hex.EncodeToString(encrypted),
 	}
 
+	previous := aph.lastPromiseAmount(providerID, er.em)
+
 	promise, err := er.requestFunc(request)
 	err = aph.handleHermesError(err, providerID, er.em.ChainID, hermesID)
 	if err != nil {
@@ -346,6 +386,22 @@ func (aph *HermesPromiseHandler) requestPromise(er enqueuedRequest) {
 		return
 	}
 
+	var shortfall *PromiseShortfallError
+	if promise.Amount != nil {
+		shortfall = aph.reconcile(er.sessionID, er.em.AgreementTotal, safeSub(promise.Amount, previous))
+	}
+	if shortfall != nil {
+		log.Warn().Err(shortfall).Msgf("Hermes did not cover the whole payment of session %s", er.sessionID)
+		aph.deps.EventBus.Publish(pinge.AppTopicHermesPromiseShortfall, pinge.AppEventHermesPromiseShortfall{
+			SessionID:  er.sessionID,
+			ProviderID: providerID,
+			HermesID:   hermesID,
+			Requested:  shortfall.Requested,
+			Covered:    shortfall.Covered,
+			Total:      shortfall.Total,
+		})
+	}
+
 	aph.deps.EventBus.Publish(pinge.AppTopicHermesPromise, pinge.AppEventHermesPromise{
 		Promise:    promise,
 		HermesID:   hermesID,
@@ -354,8 +410,11 @@ func (aph *HermesPromiseHandler) requestPromise(er enqueuedRequest) {
 	aph.deps.EventBus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{
 		ProviderID: providerID,
 		SessionID:  er.sessionID,
-		Total:      er.em.AgreementTotal,
+		Total:      aph.coveredTotal(er.sessionID, er.em.AgreementTotal),
 	})
+	if shortfall != nil {
+		er.errChan <- shortfall
+	}
 
 	err = aph.revealR(ap)
 	err = aph.handleHermesError(err, providerID, ap.Promise.ChainID, hermesID)
@@ -369,6 +428,65 @@ func (aph *HermesPromiseHandler) requestPromise(er enqueuedRequest) {
 	}
 }
 
+// lastPromiseAmount returns the amount of the last known promise for the provider channel.
+func (aph *HermesPromiseHandler) lastPromiseAmount(providerID identity.Identity, em crypto.ExchangeMessage) *big.Int {
+	chid, err := crypto.GenerateProviderChannelID(providerID.Address, em.HermesID)
+	if err != nil {
+		return new(big.Int)
+	}
+
+	stored, err := aph.deps.HermesPromiseStorage.Get(em.ChainID, chid)
+	if err != nil || stored.Promise.Amount == nil {
+		return new(big.Int)
+	}
+	return stored.Promise.Amount
+}
+
+// reconcile compares the amount covered by the hermes promise with the increase of the session agreement total,
+// returning the shortfall if hermes covered less than requested.
+func (aph *HermesPromiseHandler) reconcile(sessionID string, agreementTotal, covered *big.Int) *PromiseShortfallError {
+	if agreementTotal == nil {
+		return nil
+	}
+
+	aph.coverageLock.Lock()
+	defer aph.coverageLock.Unlock()
+
+	if aph.coverage == nil {
+		aph.coverage = make(map[string]*promiseCoverage)
+	}
+	c, ok := aph.coverage[sessionID]
+	if !ok {
+		c = &promiseCoverage{requested: new(big.Int), shortfall: new(big.Int)}
+		aph.coverage[sessionID] = c
+	}
+
+	requested := safeSub(agreementTotal, c.requested)
+	c.requested = agreementTotal
+	if covered.Cmp(requested) >= 0 {
+		return nil
+	}
+
+	c.shortfall = new(big.Int).Add(c.shortfall, new(big.Int).Sub(requested, covered))
+	return &PromiseShortfallError{
+		Requested: requested,
+		Covered:   covered,
+		Total:     new(big.Int).Set(c.shortfall),
+	}
+}
+
+// coveredTotal returns the part of the session agreement total which is covered by hermes promises.
+func (aph *HermesPromiseHandler) coveredTotal(sessionID string, agreementTotal *big.Int) *big.Int {
+	aph.coverageLock.Lock()
+	defer aph.coverageLock.Unlock()
+
+	c, ok := aph.coverage[sessionID]
+	if !ok || agreementTotal == nil {
+		return agreementTotal
+	}
+	return safeSub(agreementTotal, c.shortfall)
+}
+
 func (aph *HermesPromiseHandler) normalizeChannelID(chid []byte) string {
 	hexStr := common.Bytes2Hex(chid)
 	return "0x" + hexStr
@@ -382,6 +500,11 @@ func (aph *HermesPromiseHandler) getHermesCaller(chainID int64, hermesID common.
 	return aph.deps.HermesCallerFactory(addr), nil
 }
 
+// RevealR reveals the R of the given stored promise to hermes, unless it was revealed already.
+func (aph *HermesPromiseHandler) RevealR(hermesPromise HermesPromise) error {
+	return aph.revealR(hermesPromise)
+}
+
 func (aph *HermesPromiseHandler) revealR(hermesPromise HermesPromise) error {
 	if hermesPromise.Revealed {
 		return nil
@@ -441,33 +564,65 @@ func (aph *HermesPromiseHandler) handleHermesError(err error, providerID identit
 
 func (aph *HermesPromiseHandler) recoverR(aerr hermesError, providerID identity.Identity, chainID int64, hermesID common.Address) error {
 	log.Info().Msg("Recovering R...")
+	res, err := aph.decryptRecoveryDetails(aerr, providerID)
+	if err != nil {
+		// Hermes tells which agreement it expects the R for, but when its recovery data is unusable
+		// the R of the latest promise we've stored for the channel is the only other candidate.
+		log.Warn().Err(err).Msg("Could not use R recovery details from hermes, will try the stored promise")
+		stored, storedErr := aph.storedRecoveryDetails(providerID, chainID, hermesID)
+		if storedErr != nil {
+			return fmt.Errorf("%w, no stored promise to recover from: %v", err, storedErr)
+		}
+		res = stored
+	}
+
+	log.Info().Msg("R recovered, will reveal...")
+	hermesCaller, err := aph.getHermesCaller(chainID, hermesID)
+	if err != nil {
+		return fmt.Errorf("could not get hermes caller: %w", err)
+	}
+
+	err = hermesCaller.RevealR(res.R, providerID.Address, res.AgreementID)
+	if err != nil {
+		return fmt.Errorf("could not reveal R: %w", err)
+	}
+
+	log.Info().Msg("R recovered successfully")
+	return nil
+}
+
+func (aph *HermesPromiseHandler) decryptRecoveryDetails(aerr hermesError, providerID identity.Identity) (rRecoveryDetails, error) {
+	res := rRecoveryDetails{}
 	decoded, err := hex.DecodeString(aerr.Data())
 	if err != nil {
-		return fmt.Errorf("could not decode R recovery details: %w", err)
+		return res, fmt.Errorf("could not decode R recovery details: %w", err)
 	}
 
 	decrypted, err := aph.deps.Encryption.Decrypt(providerID.ToCommonAddress(), decoded)
 	if err != nil {
-		return fmt.Errorf("could not decrypt R details: %w", err)
+		return res, fmt.Errorf("could not decrypt R details: %w", err)
 	}
 
-	res := rRecoveryDetails{}
 	err = json.Unmarshal(decrypted, &res)
 	if err != nil {
-		return fmt.Errorf("could not unmarshal R details: %w", err)
+		return res, fmt.Errorf("could not unmarshal R details: %w", err)
 	}
+	return res, nil
+}
 
-	log.Info().Msg("R recovered, will reveal...")
-	hermesCaller, err := aph.getHermesCaller(chainID, hermesID)
+func (aph *HermesPromiseHandler) storedRecoveryDetails(providerID identity.Identity, chainID int64, hermesID common.Address) (rRecoveryDetails, error) {
+	chid, err := crypto.GenerateProviderChannelID(providerID.Address, hermesID.Hex())
 	if err != nil {
-		return fmt.Errorf("could not get hermes caller: %w", err)
+		return rRecoveryDetails{}, fmt.Errorf("could not generate channel ID: %w", err)
 	}
 
-	err = hermesCaller.RevealR(res.R, providerID.Address, res.AgreementID)
+	stored, err := aph.deps.HermesPromiseStorage.Get(chainID, chid)
 	if err != nil {
-		return fmt.Errorf("could not reveal R: %w", err)
+		return rRecoveryDetails{}, err
+	}
+	if stored.R == "" || stored.AgreementID == nil {
+		return rRecoveryDetails{}, errors.New("stored promise has no R")
 	}
 
-	log.Info().Msg("R recovered successfully")
-	return nil
+	return rRecoveryDetails{R: stored.R, AgreementID: stored.AgreementID}, nil
 }
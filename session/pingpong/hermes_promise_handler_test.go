@@ -19,6 +19,7 @@ package pingpong
 
 import (
 	"errors"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -26,6 +27,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/assert"
@@ -183,6 +185,7 @@ func TestHermesPromiseHandler_recoverR(t *testing.T) {
 					Encryption: &mockEncryptor{
 						errToReturn: errors.New("explosions"),
 					},
+					HermesPromiseStorage: &mockHermesPromiseStorage{errToReturn: ErrNotFound},
 				},
 			},
 			err: HermesErrorResponse{
@@ -196,6 +199,30 @@ func TestHermesPromiseHandler_recoverR(t *testing.T) {
 				mockFactory.errToReturn = nil
 			},
 		},
+		{
+			name: "recovers from stored promise when recovery details are unusable",
+			fields: fields{
+				providerID: identity.FromAddress("0x00000000000000000000000000000000000000aa"),
+				deps: HermesPromiseHandlerDeps{
+					HermesCallerFactory: mockFactory.Get,
+					HermesURLGetter:     &mockHermesURLGetter{},
+					Encryption: &mockEncryptor{
+						errToReturn: errors.New("explosions"),
+					},
+					HermesPromiseStorage: &mockHermesPromiseStorage{toReturn: HermesPromise{R: "abc", AgreementID: big.NewInt(123456)}},
+				},
+			},
+			err: HermesErrorResponse{
+				ErrorMessage: `Secret R for previous promise exchange (Encrypted recovery data: "7b2272223a223731373736353731373736353731373736353731333133343333333433333334363137333634363636313733363636343733363436363738363337363332373336363634376136633733363136623637363136653632363136333632366436653631363436363663366236613631373336343636363137333636222c2261677265656d656e745f6964223a3132333435367d"`,
+				CausedBy:     ErrNeedsRRecovery.Error(),
+				c:            ErrNeedsRRecovery,
+				ErrorData:    "7b2272223a223731373736353731373736353731373736353731333133343333333433333334363137333634363636313733363636343733363436363738363337363332373336363634376136633733363136623637363136653632363136333632366436653631363436363663366236613631373336343636363137333636222c2261677265656d656e745f6964223a3132333435367d",
+			},
+			wantErr: false,
+			before: func() {
+				mockFactory.errToReturn = nil
+			},
+		},
 	}
 	for _, tt := range tests {
 		if tt.before != nil {
@@ -272,6 +299,37 @@ func TestHermesPromiseHandler_handleHermesError(t *testing.T) {
 	}
 }
 
+func TestHermesPromiseHandler_reconcile(t *testing.T) {
+	aph := &HermesPromiseHandler{}
+
+	// fully covered promise
+	shortfall := aph.reconcile("session", big.NewInt(100), big.NewInt(100))
+	assert.Nil(t, shortfall)
+	assert.Equal(t, big.NewInt(100), aph.coveredTotal("session", big.NewInt(100)))
+
+	// partially covered promise
+	shortfall = aph.reconcile("session", big.NewInt(250), big.NewInt(120))
+	assert.Equal(t, &PromiseShortfallError{
+		Requested: big.NewInt(150),
+		Covered:   big.NewInt(120),
+		Total:     big.NewInt(30),
+	}, shortfall)
+	assert.Equal(t, big.NewInt(220), aph.coveredTotal("session", big.NewInt(250)))
+
+	// shortfall accumulates over the session
+	shortfall = aph.reconcile("session", big.NewInt(300), big.NewInt(40))
+	assert.Equal(t, big.NewInt(40), shortfall.Total)
+
+	// other sessions are reconciled separately
+	assert.Nil(t, aph.reconcile("other", big.NewInt(50), big.NewInt(50)))
+
+	aph.handleSessionEvents(sessionEvent.AppEventSession{
+		Status:  sessionEvent.RemovedStatus,
+		Session: sessionEvent.SessionContext{ID: "session"},
+	})
+	assert.Equal(t, big.NewInt(300), aph.coveredTotal("session", big.NewInt(300)))
+}
+
 type mockFeeProvider struct {
 	toReturn    registry.FeesResponse
 	errToReturn error
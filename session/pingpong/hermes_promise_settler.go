@@ -19,6 +19,7 @@ package pingpong
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
@@ -39,6 +40,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/utils/retry"
 	"github.com/mysteriumnetwork/payments/bindings"
 	"github.com/mysteriumnetwork/payments/client"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -80,10 +82,12 @@ type promiseStorage interface {
 
 type transactor interface {
 	SettleAndRebalance(hermesID, providerID string, promise crypto.Promise) (string, error)
+	SettleAndRebalanceBatch(providerID string, settlements []registry.BatchSettlement) (string, error)
 	SettleWithBeneficiary(id, beneficiary, hermesID string, promise crypto.Promise) (string, error)
 	PayAndSettle(hermesID, providerID string, promise crypto.Promise, beneficiary string, beneficiarySignature string) (string, error)
 	SettleIntoStake(hermesID, providerID string, promise crypto.Promise) (string, error)
 	FetchSettleFees(chainID int64) (registry.FeesResponse, error)
+	FetchBatchSettleFees(chainID int64) (registry.FeesResponse, error)
 	GetQueueStatus(ID string) (registry.QueueResponse, error)
 }
 
@@ -105,6 +109,8 @@ type HermesPromiseSettler interface {
 	ForceSettle(chainID int64, providerID identity.Identity, hermesID ...common.Address) error
 	SettleWithBeneficiary(chainID int64, providerID identity.Identity, beneficiary common.Address, hermeses []common.Address) error
 	SettleIntoStake(chainID int64, providerID identity.Identity, hermesID ...common.Address) error
+	PlanSettlementBatch(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) (SettlementBatchPlan, error)
+	SettleBatch(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) error
 	GetHermesFee(chainID int64, hermesID common.Address) (uint16, error)
 	Withdraw(fromChainID int64, toChainID int64, providerID identity.Identity, hermesID, beneficiary common.Address, amount *big.Int) error
 	CheckLatestWithdrawal(chainID int64, providerID identity.Identity, hermesID common.Address) (*big.Int, string, error)
@@ -168,6 +174,9 @@ type HermesPromiseSettlerConfig struct {
 	SettlementCheckInterval time.Duration
 	SettlementCheckTimeout  time.Duration
 	BalanceThreshold        float64
+	// BatchSettlementEnabled reports whether promises of multiple hermeses may be settled in a single transaction,
+	// they always may if it is nil.
+	BatchSettlementEnabled func() bool
 }
 
 var errFeeNotCovered = errors.New("fee not covered, cannot continue")
@@ -579,6 +588,9 @@ func (aps *hermesPromiseSettler) SettleWithBeneficiary(chainID int64, providerID
 // ErrSettleTimeout indicates that the settlement has timed out
 var ErrSettleTimeout = errors.New("settle timeout")
 
+// ErrSettlementInProgress indicates that the provider already has a settlement with the hermes in progress.
+var ErrSettlementInProgress = errors.New("provider already has settlement in progress")
+
 func (aps *hermesPromiseSettler) updatePromiseWithLatestFee(hermesID common.Address, promise crypto.Promise, maxFee *big.Int) (crypto.Promise, error) {
 	log.Debug().Msgf("Updating promise with latest fee. HermesID %v", hermesID.Hex())
 	fees, err := aps.transactor.FetchSettleFees(promise.ChainID)
@@ -590,12 +602,17 @@ func (aps *hermesPromiseSettler) updatePromiseWithLatestFee(hermesID common.Addr
 		return crypto.Promise{}, fmt.Errorf("current fee is more than the max")
 	}
 
+	return aps.reissuePromise(hermesID, promise, fees.Fee)
+}
+
+// reissuePromise asks hermes to issue the promise again with the given transactor fee.
+func (aps *hermesPromiseSettler) reissuePromise(hermesID common.Address, promise crypto.Promise, fee *big.Int) (crypto.Promise, error) {
 	hermesCaller, err := aps.getHermesCaller(promise.ChainID, hermesID)
 	if err != nil {
 		return crypto.Promise{}, fmt.Errorf("could not fetch settle fees: %w", err)
 	}
 
-	updatedPromise, err := hermesCaller.UpdatePromiseFee(promise, fees.Fee)
+	updatedPromise, err := hermesCaller.UpdatePromiseFee(promise, fee)
 	if err != nil {
 		var hermesErr *HermesErrorResponse
 		if errors.As(err, &hermesErr) {
@@ -617,13 +634,11 @@ func (aps *hermesPromiseSettler) Withdraw(
 	beneficiary common.Address,
 	amountToWithdraw *big.Int,
 ) error {
-	if aps.isSettling(providerID, hermesID) {
-		return errors.New("provider already has settlement in progress")
+	if !aps.startSettling(providerID, hermesID) {
+		return ErrSettlementInProgress
 	}
-
-	aps.setSettling(providerID, hermesID, true)
 	log.Info().Msgf("Marked provider %v as requesting settlement", providerID)
-	defer aps.setSettling(providerID, hermesID, false)
+	defer aps.stopSettling(providerID, hermesID)
 
 	if toChainID == 0 {
 		toChainID = aps.config.L1ChainID
@@ -893,44 +908,45 @@ func (aps *hermesPromiseSettler) settlePayAndSettleWithRetry(
 		return "", fmt.Errorf("amount too small for settlement. Need at least %v, have %v", promise.Fee.String(), withdrawalAmount.String())
 	}
 
-	id, err := settleFunc(promise)
-	if err == nil {
-		return id, nil
-	}
+	ctx, cancel := retry.UntilClosed(aps.stop)
+	defer cancel()
 
-	if err != nil && !payAndSettleErrorShouldRetry(err) {
-		log.Err(err).Msg("tried to settle withdrawal but failed")
-		return "", err
-	}
-
-	log.Warn().Err(err).Msg("got an error for which we can retry a withdrawal, will do that")
-	// Retry 10 times incase transactor failed to accept our settlement request.
+	// Retry incase transactor failed to accept our settlement request.
 	// There is no point in going for longer than 5 minutes, after that fees
 	// will expire and there will be a different amount of fees to pay meaning
 	// user would get a different amount of money after the withdrawal.
 	// It would be rather strange from users perspective if he ends up paying
 	// way more than he agreed when creating the request.
-	for i := 0; i < 10; i++ {
-		select {
-		case <-time.After(time.Second * 30):
-			log.Info().Int("count", i+1).Msg("retrying a call to settle withdrawal")
-
-			id, err := settleFunc(promise)
-			if err != nil {
-				if payAndSettleErrorShouldRetry(err) {
-					log.Warn().Err(err).Msg("failed when retrying withdrawal")
-					continue
-				}
-				return "", err
-			}
+	var id string
+	attempt := 0
+	err = retry.Do(ctx, retry.Transactor, func() error {
+		if attempt > 0 {
+			log.Info().Int("count", attempt).Msg("retrying a call to settle withdrawal")
+		}
+		attempt++
 
-			return id, nil
-		case <-aps.stop:
-			return "", errors.New("stopped trying to withdraw, will not finish")
+		var err error
+		id, err = settleFunc(promise)
+		if err != nil && !payAndSettleErrorShouldRetry(err) {
+			log.Err(err).Msg("tried to settle withdrawal but failed")
+			return retry.Permanent(err)
+		}
+		if err != nil {
+			log.Warn().Err(err).Msg("got an error for which we can retry a withdrawal, will do that")
 		}
+		return err
+	})
+	if err == nil {
+		return id, nil
+	}
+	if errors.Is(err, context.Canceled) {
+		return "", errors.New("stopped trying to withdraw, will not finish")
+	}
+	if payAndSettleErrorShouldRetry(err) {
+		return "", errors.New("out of retries, transactor never accepted our request to pay and settle")
 	}
 
-	return "", errors.New("out of retries, transactor never accepted our request to pay and settle")
+	return "", err
 }
 
 func (aps *hermesPromiseSettler) issueSelfPromise(fromChain, toChain int64, amount, previousPromiseAmount *big.Int, providerID identity.Identity, consumerChannelAddress, hermesAddress common.Address) (*crypto.ExchangeMessage, error) {
@@ -1012,11 +1028,10 @@ func (aps *hermesPromiseSettler) settle(
 	settled *big.Int,
 	maxFee *big.Int,
 ) error {
-	if aps.isSettling(provider, hermesID) {
-		return errors.New("provider already has settlement in progress")
+	if !aps.startSettling(provider, hermesID) {
+		return ErrSettlementInProgress
 	}
-	aps.setSettling(provider, hermesID, true)
-	defer aps.setSettling(provider, hermesID, false)
+	defer aps.stopSettling(provider, hermesID)
 
 	log.Info().Msgf("Marked provider %v as requesting settlement", provider)
 
@@ -1255,39 +1270,33 @@ func (aps *hermesPromiseSettler) safeSub(a uint64, b uint64) uint64 {
 	return a - b
 }
 
-func (aps *hermesPromiseSettler) isSettling(id identity.Identity, hermesID common.Address) bool {
-	aps.lock.RLock()
-	defer aps.lock.RUnlock()
-	v, ok := aps.currentState[id]
-	if !ok {
-		return false
-	}
+// startSettling marks the provider as settling with the given hermes.
+// It returns false if a settlement is already in progress, so concurrent callers can not both proceed.
+func (aps *hermesPromiseSettler) startSettling(id identity.Identity, hermesID common.Address) bool {
+	aps.lock.Lock()
+	defer aps.lock.Unlock()
+	v := aps.currentState[id]
 
 	if v.settleInProgress == nil {
 		v.settleInProgress = make(map[common.Address]struct{})
-		aps.currentState[id] = v
 	}
 
-	_, ok = v.settleInProgress[hermesID]
+	if _, ok := v.settleInProgress[hermesID]; ok {
+		return false
+	}
+
+	v.settleInProgress[hermesID] = struct{}{}
+	aps.currentState[id] = v
 
-	return ok
+	return true
 }
 
-func (aps *hermesPromiseSettler) setSettling(id identity.Identity, hermesID common.Address, settling bool) {
+func (aps *hermesPromiseSettler) stopSettling(id identity.Identity, hermesID common.Address) {
 	aps.lock.Lock()
 	defer aps.lock.Unlock()
 	v := aps.currentState[id]
 
-	if v.settleInProgress == nil {
-		v.settleInProgress = make(map[common.Address]struct{})
-	}
-
-	if settling {
-		v.settleInProgress[hermesID] = struct{}{}
-	} else {
-		delete(v.settleInProgress, hermesID)
-	}
-
+	delete(v.settleInProgress, hermesID)
 	aps.currentState[id] = v
 }
 
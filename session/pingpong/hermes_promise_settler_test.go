@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -432,6 +434,32 @@ func TestPromiseSettler_AcceptsIfFeesDoNotExceedSettlementAmount(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestPromiseSettler_startSettlingRejectsConcurrentSettlement(t *testing.T) {
+	hps := &hermesPromiseSettler{
+		currentState: make(map[identity.Identity]settlementState),
+	}
+
+	var wg sync.WaitGroup
+	var started int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if hps.startSettling(mockID, hermesID) {
+				atomic.AddInt32(&started, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), started)
+
+	err := hps.settle(nil, mockID, hermesID, crypto.Promise{}, common.Address{}, nil, nil)
+	assert.ErrorIs(t, err, ErrSettlementInProgress)
+
+	hps.stopSettling(mockID, hermesID)
+	assert.True(t, hps.startSettling(mockID, hermesID))
+}
+
 func TestPromiseSettlerState_needsSettling(t *testing.T) {
 	hps := &hermesPromiseSettler{
 		transactor: &mockTransactor{
@@ -673,6 +701,15 @@ type mockTransactor struct {
 
 	idToReturn  string
 	settleError error
+
+	batchFeesToReturn registry.FeesResponse
+	batchFeesError    error
+	batchSettlements  []registry.BatchSettlement
+	batchError        error
+}
+
+func (mt *mockTransactor) FetchBatchSettleFees(chainID int64) (registry.FeesResponse, error) {
+	return mt.batchFeesToReturn, mt.batchFeesError
 }
 
 func (mt *mockTransactor) FetchSettleFees(chainID int64) (registry.FeesResponse, error) {
@@ -683,6 +720,14 @@ func (mt *mockTransactor) SettleAndRebalance(_, _ string, _ crypto.Promise) (str
 	return mt.idToReturn, mt.settleError
 }
 
+func (mt *mockTransactor) SettleAndRebalanceBatch(_ string, settlements []registry.BatchSettlement) (string, error) {
+	if mt.batchError != nil {
+		return "", mt.batchError
+	}
+	mt.batchSettlements = settlements
+	return mt.idToReturn, mt.settleError
+}
+
 func (mt *mockTransactor) SettleWithBeneficiary(_, _, _ string, _ crypto.Promise) (string, error) {
 	return mt.idToReturn, mt.settleError
 }
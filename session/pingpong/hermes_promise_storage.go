@@ -36,16 +36,24 @@ const hermesPromiseBucketName = "hermes_promises"
 // ErrAttemptToOverwrite occurs when a promise with lower value is attempted to be overwritten on top of an existing promise.
 var ErrAttemptToOverwrite = errors.New("attempted to overwrite a promise with and equal or lower value")
 
+type promiseWriter interface {
+	SetValue(bucket string, key interface{}, to interface{}) error
+	DeleteKey(bucket string, key interface{}) error
+}
+
 // HermesPromiseStorage allows for storing of hermes promises.
 type HermesPromiseStorage struct {
-	lock sync.Mutex
-	bolt *boltdb.Bolt
+	lock   sync.Mutex
+	bolt   *boltdb.Bolt
+	writer promiseWriter
 }
 
 // NewHermesPromiseStorage returns a new instance of the hermes promise storage.
-func NewHermesPromiseStorage(bolt *boltdb.Bolt) *HermesPromiseStorage {
+// Promises are written through the given writer, which is either the bolt itself or a journal guarding it.
+func NewHermesPromiseStorage(bolt *boltdb.Bolt, writer promiseWriter) *HermesPromiseStorage {
 	return &HermesPromiseStorage{
-		bolt: bolt,
+		bolt:   bolt,
+		writer: writer,
 	}
 }
 
@@ -78,7 +86,7 @@ func (aps *HermesPromiseStorage) Store(promise HermesPromise) error {
 		return ErrAttemptToOverwrite
 	}
 
-	if err := aps.bolt.SetValue(aps.getBucketName(promise.Promise.ChainID), promise.ChannelID, promise); err != nil {
+	if err := aps.writer.SetValue(aps.getBucketName(promise.Promise.ChainID), promise.ChannelID, promise); err != nil {
 		return fmt.Errorf("could not store hermes promise: %w", err)
 	}
 	return nil
@@ -103,7 +111,7 @@ func (aps *HermesPromiseStorage) shouldOverride(old, new HermesPromise) bool {
 
 // Delete deletes the given hermes promise.
 func (aps *HermesPromiseStorage) Delete(promise HermesPromise) error {
-	return aps.bolt.DeleteKey(aps.getBucketName(promise.Promise.ChainID), promise.ChannelID)
+	return aps.writer.DeleteKey(aps.getBucketName(promise.Promise.ChainID), promise.ChannelID)
 }
 
 func (aps *HermesPromiseStorage) get(chainID int64, channelID string) (HermesPromise, error) {
@@ -143,6 +151,7 @@ func (aps *HermesPromiseStorage) List(filter HermesPromiseFilter) ([]HermesPromi
 	defer aps.lock.Unlock()
 
 	result := make([]HermesPromise, 0)
+	defer aps.bolt.Track("list", aps.getBucketName(filter.ChainID), nil)()
 	aps.bolt.RLock()
 	defer aps.bolt.RUnlock()
 	err := aps.bolt.DB().Bolt.View(func(tx *bbolt.Tx) error {
@@ -39,7 +39,7 @@ func TestHermesPromiseStorage(t *testing.T) {
 	assert.NoError(t, err)
 	defer bolt.Close()
 
-	hermesStorage := NewHermesPromiseStorage(bolt)
+	hermesStorage := NewHermesPromiseStorage(bolt, bolt)
 
 	id := identity.FromAddress("0x44440954558C5bFA0D4153B0002B1d1E3E3f5Ff5")
 	firstHermes := common.HexToAddress("0x000000acc1")
@@ -114,7 +114,7 @@ func TestHermesPromiseStorageDelete(t *testing.T) {
 	assert.NoError(t, err)
 	defer bolt.Close()
 
-	hermesStorage := NewHermesPromiseStorage(bolt)
+	hermesStorage := NewHermesPromiseStorage(bolt, bolt)
 
 	id := identity.FromAddress("0x44440954558C5bFA0D4153B0002B1d1E3E3f5Ff5")
 	firstHermes := common.HexToAddress("0x000000acc1")
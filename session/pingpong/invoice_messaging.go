@@ -19,8 +19,10 @@ package pingpong
 
 import (
 	"context"
+	"math/big"
 	"time"
 
+	"github.com/mysteriumnetwork/node/dto"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -34,29 +36,94 @@ type InvoiceRequest struct {
 
 // InvoiceSender is responsible for sending the invoice messages.
 type InvoiceSender struct {
-	ch p2p.ChannelSender
+	ch    p2p.ChannelSender
+	codec dto.Codec
 }
 
-// NewInvoiceSender returns a new instance of the invoice sender.
-func NewInvoiceSender(ch p2p.ChannelSender) *InvoiceSender {
+// NewInvoiceSender returns a new instance of the invoice sender encoding the invoices with the given codec.
+func NewInvoiceSender(ch p2p.ChannelSender, codec dto.Codec) *InvoiceSender {
 	return &InvoiceSender{
-		ch: ch,
+		ch:    ch,
+		codec: codec,
 	}
 }
 
-// Send sends the given invoice.
-func (is *InvoiceSender) Send(invoice crypto.Invoice) error {
-	pInvoice := &pb.Invoice{
-		AgreementID:    invoice.AgreementID.Text(bigIntBase),
-		AgreementTotal: invoice.AgreementTotal.Text(bigIntBase),
-		TransactorFee:  invoice.TransactorFee.Text(bigIntBase),
-		Hashlock:       invoice.Hashlock,
-		Provider:       invoice.Provider,
-		ChainID:        invoice.ChainID,
+// InvoiceReceipt describes when the consumer received an invoice, it is used to measure the peer clock skew.
+// Receipt is empty if the consumer does not report it.
+type InvoiceReceipt struct {
+	// SentAt and RepliedAt are the local times of the invoice round trip.
+	SentAt, RepliedAt time.Time
+	// ReceivedAt is the time the consumer received the invoice at by its own clock.
+	ReceivedAt time.Time
+}
+
+// Send sends the given invoice stamped with the local time and returns the receipt of the consumer.
+func (is *InvoiceSender) Send(invoice crypto.Invoice) (InvoiceReceipt, error) {
+	sentAt := time.Now()
+	msg := dto.NewInvoice(invoice, sentAt)
+	log.Debug().Msgf("Sending P2P message to %q: %+v", p2p.TopicPaymentInvoice, msg)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	reply, err := is.ch.Send(ctx, p2p.TopicPaymentInvoice, p2p.DTOMessage(is.codec, msg))
+	if err != nil {
+		return InvoiceReceipt{}, err
+	}
+
+	receipt := InvoiceReceipt{SentAt: sentAt, RepliedAt: time.Now()}
+	var dtoReceipt dto.InvoiceReceipt
+	// Consumers which do not report receipts reply with an empty message.
+	if reply != nil && len(reply.Data) > 0 && reply.UnmarshalDTO(is.codec, &dtoReceipt) == nil &&
+		dtoReceipt.SentAt.Equal(sentAt) && !dtoReceipt.ReceivedAt.IsZero() {
+		receipt.ReceivedAt = dtoReceipt.ReceivedAt
+	}
+	return receipt, nil
+}
+
+// SendReady checks whether the consumer is ready to pay for the session.
+func (is *InvoiceSender) SendReady(ctx context.Context, sessionID string) error {
+	pReady := &pb.SessionInfo{
+		SessionID: sessionID,
+	}
+	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicPaymentReady, pReady.String())
+	_, err := is.ch.Send(ctx, p2p.TopicPaymentReady, p2p.ProtoMessage(pReady))
+	return err
+}
+
+// SendShortfall lets the consumer know that hermes did not cover the whole payment of the session.
+func (is *InvoiceSender) SendShortfall(sessionID string, agreementID *big.Int, shortfall PromiseShortfallError) error {
+	pShortfall := &pb.PromiseShortfall{
+		SessionID:   sessionID,
+		AgreementID: agreementID.Text(bigIntBase),
+		Requested:   shortfall.Requested.Text(bigIntBase),
+		Covered:     shortfall.Covered.Text(bigIntBase),
+		Total:       shortfall.Total.Text(bigIntBase),
+	}
+	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicPaymentShortfall, pShortfall.String())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err := is.ch.Send(ctx, p2p.TopicPaymentShortfall, p2p.ProtoMessage(pShortfall))
+	return err
+}
+
+// SendHermesFeeChange lets the consumer know that hermes raised its fee above the limit during the session.
+// If the change carries a renegotiated price, an error means the consumer did not accept it.
+func (is *InvoiceSender) SendHermesFeeChange(sessionID string, change HermesFeeChange) error {
+	pChange := &pb.HermesFeeChange{
+		SessionID:   sessionID,
+		Fee:         uint32(change.Fee),
+		PreviousFee: uint32(change.PreviousFee),
+		MaxFee:      uint32(change.MaxFee),
+		Policy:      string(change.Policy),
+		Deadline:    change.Deadline.Unix(),
+		Applied:     change.Applied,
+	}
+	if change.Price != nil {
+		pChange.PricePerHour = change.Price.PricePerHour.Text(bigIntBase)
+		pChange.PricePerGiB = change.Price.PricePerGiB.Text(bigIntBase)
 	}
-	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicPaymentInvoice, pInvoice.String())
+	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicPaymentHermesFee, pChange.String())
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	_, err := is.ch.Send(ctx, p2p.TopicPaymentInvoice, p2p.ProtoMessage(pInvoice))
+	_, err := is.ch.Send(ctx, p2p.TopicPaymentHermesFee, p2p.ProtoMessage(pChange))
 	return err
 }
@@ -79,11 +79,20 @@ type InvoicePayer struct {
 
 	lastInvoice crypto.Invoice
 	deps        InvoicePayerDeps
+	pricing     *sessionPricing
 
 	dataTransferred     DataTransferred
 	dataTransferredLock sync.Mutex
 
 	sessionIDLock sync.Mutex
+
+	// hermesFee is the fee the current session price is based on.
+	hermesFee      uint16
+	hermesFeeKnown bool
+	hermesFeeLock  sync.Mutex
+
+	paymentState     event.AppEventConsumerPayment
+	paymentStateLock sync.Mutex
 }
 
 type hashSigner interface {
@@ -105,13 +114,28 @@ type InvoicePayerDeps struct {
 	HermesAddress             common.Address
 	DataLeeway                datasize.BitSize
 	ChainID                   int64
+	// Clock is the source of time for payments, SystemClock is used if it is not set.
+	Clock Clock
+	// PeerClockSkew widens the invoice tolerance by the measured provider clock skew, it is optional.
+	PeerClockSkew *PeerClockSkew
+	// HermesStatusChecker provides the hermes fee which justifies the prices renegotiated by provider, it is optional.
+	HermesStatusChecker hermesStatusChecker
+}
+
+// clock returns the source of time for payments.
+func (ip *InvoicePayer) clock() Clock {
+	if ip.deps.Clock == nil {
+		return SystemClock{}
+	}
+	return ip.deps.Clock
 }
 
 // NewInvoicePayer returns a new instance of exchange message tracker.
 func NewInvoicePayer(ipd InvoicePayerDeps) *InvoicePayer {
 	return &InvoicePayer{
-		stop: make(chan struct{}),
-		deps: ipd,
+		stop:    make(chan struct{}),
+		deps:    ipd,
+		pricing: newSessionPricing(ipd.AgreedPrice),
 		lastInvoice: crypto.Invoice{
 			AgreementID:    new(big.Int),
 			AgreementTotal: new(big.Int),
@@ -133,6 +157,7 @@ func (ip *InvoicePayer) Start() error {
 	ip.channelAddress = identity.FromAddress(addr.Hex())
 
 	ip.deps.TimeTracker.StartTracking()
+	ip.recordHermesFee()
 
 	uid, err := uuid.NewV4()
 	if err != nil {
@@ -152,13 +177,25 @@ func (ip *InvoicePayer) Start() error {
 			return nil
 		case invoice := <-ip.deps.InvoiceChan:
 			log.Debug().Msgf("Invoice received: %v", invoice)
+			ip.updatePaymentState(func(state *event.AppEventConsumerPayment) {
+				state.LastInvoiceAmount = invoice.AgreementTotal
+				state.LastInvoiceAt = ip.clock().Now().UTC()
+				state.Hashlock = invoice.Hashlock
+			})
+
 			err := ip.isInvoiceOK(invoice)
 			if err != nil {
+				ip.updatePaymentState(func(state *event.AppEventConsumerPayment) {
+					state.LastError = err.Error()
+				})
 				return errors.Wrap(err, "invoice not valid")
 			}
 
 			err = ip.issueExchangeMessage(invoice)
 			if err != nil {
+				ip.updatePaymentState(func(state *event.AppEventConsumerPayment) {
+					state.LastError = err.Error()
+				})
 				return err
 			}
 
@@ -191,10 +228,12 @@ func (ip *InvoicePayer) isInvoiceOK(invoice crypto.Invoice) error {
 	transferred := ip.getDataTransferred()
 	transferred.Up += ip.deps.DataLeeway.Bytes()
 
-	shouldBe := CalculatePaymentAmount(ip.deps.TimeTracker.Elapsed(), transferred, ip.deps.AgreedPrice)
+	shouldBe := ip.pricing.amount(ip.deps.TimeTracker.Elapsed(), transferred)
 	estimatedTolerance := estimateInvoiceTolerance(ip.deps.TimeTracker.Elapsed(), transferred)
 
 	upperBound, _ := new(big.Float).Mul(new(big.Float).SetInt(shouldBe), big.NewFloat(estimatedTolerance)).Int(nil)
+	// Provider measures the session time by its own clock, so it may be a bit ahead of ours.
+	upperBound.Add(upperBound, ip.deps.PeerClockSkew.toleratedAmount(ip.pricing.current()))
 
 	log.Debug().Msgf("Estimated tolerance %.4v, upper bound %v", estimatedTolerance, upperBound)
 
@@ -279,7 +318,35 @@ func (ip *InvoicePayer) issueExchangeMessage(invoice crypto.Invoice) error {
 
 	// TODO: we'd probably want to check if we have enough balance here
 	err = ip.incrementGrandTotalPromised(*diff)
-	return errors.Wrap(err, "could not increment grand total")
+	if err != nil {
+		return errors.Wrap(err, "could not increment grand total")
+	}
+
+	ip.updatePaymentState(func(state *event.AppEventConsumerPayment) {
+		state.LastPromiseAt = ip.clock().Now().UTC()
+		state.AgreementTotal = invoice.AgreementTotal
+		state.TotalPromised = amountToPromise
+		state.LastError = ""
+	})
+	return nil
+}
+
+// updatePaymentState applies the given change to the session payment state and announces it once the session is known.
+func (ip *InvoicePayer) updatePaymentState(update func(state *event.AppEventConsumerPayment)) {
+	ip.paymentStateLock.Lock()
+	update(&ip.paymentState)
+	state := ip.paymentState
+	ip.paymentStateLock.Unlock()
+
+	ip.sessionIDLock.Lock()
+	defer ip.sessionIDLock.Unlock()
+	if ip.deps.SessionID == "" {
+		return
+	}
+
+	state.SessionID = ip.deps.SessionID
+	state.ConsumerID = ip.deps.Identity
+	ip.deps.EventBus.Publish(event.AppTopicConsumerPayment, state)
 }
 
 func (ip *InvoicePayer) publishInvoicePayedEvent(invoice crypto.Invoice) {
@@ -346,3 +413,72 @@ func (ip *InvoicePayer) SetSessionID(sessionID string) {
 	defer ip.sessionIDLock.Unlock()
 	ip.deps.SessionID = sessionID
 }
+
+// checkSessionID makes sure a message of the provider is about the session being paid for.
+func (ip *InvoicePayer) checkSessionID(sessionID string) error {
+	ip.sessionIDLock.Lock()
+	defer ip.sessionIDLock.Unlock()
+
+	if sessionID != ip.deps.SessionID {
+		return fmt.Errorf("message is for session %q, expected %q", sessionID, ip.deps.SessionID)
+	}
+	return nil
+}
+
+// acceptRenegotiatedPrice bills the rest of the session at the price the provider asks for because hermes raised its fee,
+// as long as the raise of the fee read from hermes status justifies the price. The fees reported by the provider are not trusted.
+func (ip *InvoicePayer) acceptRenegotiatedPrice(sessionID string, price market.Price) (previousFee, fee uint16, err error) {
+	if err := ip.checkSessionID(sessionID); err != nil {
+		return 0, 0, err
+	}
+
+	ip.hermesFeeLock.Lock()
+	defer ip.hermesFeeLock.Unlock()
+
+	if !ip.hermesFeeKnown {
+		return 0, 0, errors.New("hermes fee at the session start is unknown")
+	}
+
+	fee, err = ip.fetchHermesFee()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := checkRenegotiatedPrice(ip.deps.AgreedPrice, ip.pricing.current(), price, ip.hermesFee, fee); err != nil {
+		return 0, 0, err
+	}
+
+	ip.pricing.reprice(price, ip.deps.TimeTracker.Elapsed(), ip.getDataTransferred())
+	previousFee, ip.hermesFee = ip.hermesFee, fee
+	return previousFee, fee, nil
+}
+
+// recordHermesFee remembers the hermes fee the session price is based on.
+func (ip *InvoicePayer) recordHermesFee() {
+	fee, err := ip.fetchHermesFee()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not check hermes fee, price renegotiations will be rejected")
+		return
+	}
+
+	ip.hermesFeeLock.Lock()
+	defer ip.hermesFeeLock.Unlock()
+	ip.hermesFee, ip.hermesFeeKnown = fee, true
+}
+
+func (ip *InvoicePayer) fetchHermesFee() (uint16, error) {
+	if ip.deps.HermesStatusChecker == nil {
+		return 0, errors.New("hermes status is not available")
+	}
+
+	registry, err := ip.deps.AddressProvider.GetRegistryAddress(ip.chainID())
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get registry address")
+	}
+
+	status, err := ip.deps.HermesStatusChecker.GetHermesStatus(ip.chainID(), registry, ip.deps.HermesAddress)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not check hermes status")
+	}
+	return status.Fee, nil
+}
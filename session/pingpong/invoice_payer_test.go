@@ -330,6 +330,7 @@ func TestInvoicePayer_isInvoiceOK(t *testing.T) {
 					AgreedPrice: tt.fields.price,
 					Peer:        tt.fields.peer,
 				},
+				pricing: newSessionPricing(tt.fields.price),
 			}
 			if err := emt.isInvoiceOK(tt.invoice); (err != nil) != tt.wantErr {
 				t.Errorf("InvoicePayer.isInvoiceOK() error = %v, wantErr %v", err, tt.wantErr)
@@ -592,13 +593,24 @@ func TestInvoicePayer_issueExchangeMessage_publishesEvents(t *testing.T) {
 		SessionID: emt.deps.SessionID,
 	}, ev.value)
 
-	ev = <-mp.publicationChan
-	assert.Equal(t, event.AppTopicGrandTotalChanged, ev.name)
+	// grand total change is published asynchronously, so it may come after the payment state
+	published := make(map[string]interface{})
+	for i := 0; i < 2; i++ {
+		ev = <-mp.publicationChan
+		published[ev.name] = ev.value
+	}
 	assert.EqualValues(t, event.AppEventGrandTotalChanged{
 		ChainID:    1,
 		ConsumerID: emt.deps.Identity,
 		Current:    big.NewInt(5),
-	}, ev.value)
+	}, published[event.AppTopicGrandTotalChanged])
+
+	payment := published[event.AppTopicConsumerPayment].(event.AppEventConsumerPayment)
+	assert.Equal(t, emt.deps.SessionID, payment.SessionID)
+	assert.Equal(t, big.NewInt(15), payment.AgreementTotal)
+	assert.Equal(t, big.NewInt(5), payment.TotalPromised)
+	assert.False(t, payment.LastPromiseAt.IsZero())
+	assert.Empty(t, payment.LastError)
 }
 
 func TestInvoicePayer_issueExchangeMessage(t *testing.T) {
@@ -19,6 +19,7 @@ package pingpong
 
 import (
 	"bytes"
+	"context"
 	crand "crypto/rand"
 	"encoding/hex"
 	stdErr "errors"
@@ -31,13 +32,16 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -67,11 +71,37 @@ var ErrExchangeValidationFailed = errors.New("exchange validation failed")
 // ErrConsumerNotRegistered represents the error that the consumer is not registered
 var ErrConsumerNotRegistered = errors.New("consumer not registered")
 
+// ErrHermesPromiseShortfall indicates that hermes left more of the session payments uncovered than the consumer is allowed to owe.
+var ErrHermesPromiseShortfall = errors.New("hermes promises do not cover the session payments")
+
+// ErrPeerNotReady represents an error where consumer did not confirm it is ready to pay for the session.
+var ErrPeerNotReady = errors.New("consumer did not confirm it is ready to pay")
+
+const (
+	peerReadyTimeout       = 10 * time.Second
+	peerReadyRetryInterval = 500 * time.Millisecond
+)
+
 var providerFirstInvoiceValue = big.NewInt(1)
 
 // PeerInvoiceSender allows to send invoices.
 type PeerInvoiceSender interface {
-	Send(crypto.Invoice) error
+	Send(crypto.Invoice) (InvoiceReceipt, error)
+}
+
+// PeerShortfallNotifier allows to inform the consumer about payments not covered by hermes.
+type PeerShortfallNotifier interface {
+	SendShortfall(sessionID string, agreementID *big.Int, shortfall PromiseShortfallError) error
+}
+
+// PeerHermesFeeNotifier allows to inform the consumer about hermes raising its fee during the session.
+type PeerHermesFeeNotifier interface {
+	SendHermesFeeChange(sessionID string, change HermesFeeChange) error
+}
+
+// PeerReadinessChecker allows to check whether the consumer is ready to pay for the session.
+type PeerReadinessChecker interface {
+	SendReady(ctx context.Context, sessionID string) error
 }
 
 type hermesStatusChecker interface {
@@ -101,11 +131,16 @@ type DataTransferred struct {
 }
 
 func (dt DataTransferred) sum() uint64 {
-	return dt.Up + dt.Down
+	sum, err := money.AddUint64(dt.Up, dt.Down)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Data transferred sum saturated, up: %v, down: %v", dt.Up, dt.Down)
+	}
+	return sum
 }
 
 // InvoiceTracker keeps tab of invoices and sends them to the consumer.
 type InvoiceTracker struct {
+	sessionLogger          *zerolog.Logger
 	stop                   chan struct{}
 	promiseErrors          chan error
 	invoiceChannel         chan bool
@@ -128,12 +163,52 @@ type InvoiceTracker struct {
 	dataTransferred     DataTransferred
 	dataTransferredLock sync.Mutex
 
+	billingStarted  sync.Once
+	timeTrackerLock sync.Mutex
+	dataStarted     bool
+	peerReady       bool
+
 	criticalInvoiceErrors chan error
 	lastInvoiceSent       time.Duration
 	invoiceDebounceRate   time.Duration
 
 	lastExchangeMessage     crypto.ExchangeMessage
 	lastExchangeMessageLock sync.Mutex
+
+	anomalyDetector     *billingAnomalyDetector
+	anomalyDetectorLock sync.Mutex
+
+	pricing    *sessionPricing
+	feeMonitor *hermesFeeMonitor
+	peerClock  *PeerClockSkew
+
+	paymentState     sessionEvent.AppEventSessionPayment
+	paymentStateLock sync.Mutex
+
+	peerUnresponsive chan struct{}
+
+	liveness *Liveness
+	stuck    chan error
+
+	// Time and traffic of the periods when consumer has paused the session are not billed.
+	pauseLock    sync.Mutex
+	paused       bool
+	pausedAt     time.Duration
+	pausedDataAt DataTransferred
+	pausedTime   time.Duration
+	pausedData   DataTransferred
+}
+
+const (
+	goroutineInvoiceLoop      = "invoice loop"
+	goroutineInvoiceScheduler = "invoice scheduler"
+	goroutineExchangeListener = "exchange message listener"
+)
+
+// trackerWatchdog supervises the payment goroutines of the invoice tracker.
+type trackerWatchdog interface {
+	Watch(sessionID string, deadline time.Duration, terminate func(error)) *Liveness
+	Unwatch(sessionID string)
 }
 
 // InvoiceTrackerDeps contains all the deps needed for invoice tracker.
@@ -141,6 +216,9 @@ type InvoiceTrackerDeps struct {
 	AgreedPrice                market.Price
 	Peer                       identity.Identity
 	PeerInvoiceSender          PeerInvoiceSender
+	PeerShortfallNotifier      PeerShortfallNotifier
+	PeerHermesFeeNotifier      PeerHermesFeeNotifier
+	PeerReadinessChecker       PeerReadinessChecker
 	InvoiceStorage             providerInvoiceStorage
 	TimeTracker                timeTracker
 	ChargePeriodLeeway         time.Duration
@@ -161,13 +239,33 @@ type InvoiceTrackerDeps struct {
 	LimitNotPaidInvoice        *big.Int
 	MaxNotPaidInvoice          *big.Int
 	Observer                   observerApi
+	// InitialFreeWindow is the duration after the data plane start that is not charged for.
+	InitialFreeWindow time.Duration
+	// BillingAnomaly configures the detection of sessions which are billed suspiciously.
+	BillingAnomaly BillingAnomalyConfig
+	// Watchdog terminates the session if the tracker stops making progress, it is optional.
+	Watchdog trackerWatchdog
+	// HermesFeeChange configures the handling of hermes fee raises during the session.
+	HermesFeeChange HermesFeeChangeConfig
+	// PeerClockSkew configures how much the promised total may fall behind the invoiced one.
+	PeerClockSkew PeerClockSkewConfig
+	// Logger is annotated with the session identifiers, it is optional.
+	Logger *zerolog.Logger
+	// Clock is the source of time for invoicing, SystemClock is used if it is not set.
+	Clock Clock
 }
 
 // NewInvoiceTracker creates a new instance of invoice tracker.
 func NewInvoiceTracker(
 	itd InvoiceTrackerDeps,
 ) *InvoiceTracker {
-	return &InvoiceTracker{
+	logger := itd.Logger
+	if logger == nil {
+		l := log.With().Str(session.LogFieldSessionID, itd.SessionID).Logger()
+		logger = &l
+	}
+
+	it := &InvoiceTracker{
 		lastExchangeMessage: crypto.ExchangeMessage{
 			Promise: crypto.Promise{
 				Amount: new(big.Int),
@@ -178,14 +276,42 @@ func NewInvoiceTracker(
 		},
 		stop:                           make(chan struct{}),
 		deps:                           itd,
+		sessionLogger:                  logger,
 		maxNotReceivedExchangeMessages: calculateMaxNotReceivedExchangeMessageCount(itd.ChargePeriodLeeway, itd.ChargePeriod),
 		maxNotSentExchangeMessages:     calculateMaxNotSentExchangeMessageCount(itd.ChargePeriodLeeway, itd.ChargePeriod),
 		invoicesSent:                   make(map[string]sentInvoice),
 		promiseErrors:                  make(chan error),
 		criticalInvoiceErrors:          make(chan error),
 		invoiceChannel:                 make(chan bool),
+		peerUnresponsive:               make(chan struct{}, 1),
+		stuck:                          make(chan error, 1),
+		peerReady:                      itd.PeerReadinessChecker == nil,
 		invoiceDebounceRate:            time.Second * 5,
+		pricing:                        newSessionPricing(itd.AgreedPrice),
+		peerClock:                      NewPeerClockSkew(itd.PeerClockSkew),
+		paymentState: sessionEvent.AppEventSessionPayment{
+			LastInvoiceAmount: new(big.Int),
+			Paid:              new(big.Int),
+		},
+	}
+	it.anomalyDetector = newBillingAnomalyDetector(itd.BillingAnomaly, itd.AgreedPrice, it.clock())
+	return it
+}
+
+// logger returns the logger annotated with the session identifiers.
+func (it *InvoiceTracker) logger() *zerolog.Logger {
+	if it.sessionLogger == nil {
+		return &log.Logger
+	}
+	return it.sessionLogger
+}
+
+// clock returns the source of time for invoicing.
+func (it *InvoiceTracker) clock() Clock {
+	if it.deps.Clock == nil {
+		return SystemClock{}
 	}
+	return it.deps.Clock
 }
 
 func calculateMaxNotReceivedExchangeMessageCount(chargeLeeway, chargePeriod time.Duration) uint64 {
@@ -222,13 +348,17 @@ func (it *InvoiceTracker) getMarkedInvoice(hashlock []byte) (invoice sentInvoice
 }
 
 func (it *InvoiceTracker) listenForExchangeMessages() error {
+	defer it.liveness.Done(goroutineExchangeListener)
 	for {
+		it.liveness.Tick(goroutineExchangeListener)
+
 		select {
 		case pm := <-it.deps.ExchangeMessageChan:
 			err := it.handleExchangeMessage(pm)
 			if err != nil && err != ErrInvoiceExpired {
 				return err
 			}
+		case <-it.heartbeat():
 		case <-it.stop:
 			return nil
 		}
@@ -247,7 +377,7 @@ func (it *InvoiceTracker) generateAgreementID() {
 func (it *InvoiceTracker) handleExchangeMessage(em crypto.ExchangeMessage) error {
 	invoice, ok := it.getMarkedInvoice(em.Promise.Hashlock)
 	if !ok {
-		log.Debug().Msgf("consumer sent exchange message with missing expired hashlock %s, skipping", invoice.invoice.Hashlock)
+		it.logger().Debug().Msgf("consumer sent exchange message with missing expired hashlock %s, skipping", invoice.invoice.Hashlock)
 		return ErrInvoiceExpired
 	}
 
@@ -256,13 +386,22 @@ func (it *InvoiceTracker) handleExchangeMessage(em crypto.ExchangeMessage) error
 		return err
 	}
 
+	err = it.validatePromisedTotal(em, invoice.invoice)
+	if err != nil {
+		return err
+	}
+
 	it.saveLastExchangeMessage(em)
 	it.markInvoicePaid(em.Promise.Hashlock)
+	it.updatePaymentState(func(state *sessionEvent.AppEventSessionPayment) {
+		state.Paid = em.AgreementTotal
+		state.LastExchangeMessageAt = it.clock().Now().UTC()
+	})
 	it.resetNotReceivedExchangeMessageCount()
 	it.resetNotSentExchangeMessageCount()
 
 	// incase of zero payment, we'll just skip going to the hermes
-	if it.deps.AgreedPrice.IsFree() {
+	if it.pricing.current().IsFree() {
 		return nil
 	}
 
@@ -277,13 +416,21 @@ func (it *InvoiceTracker) handleExchangeMessage(em crypto.ExchangeMessage) error
 
 // Start stars the invoice tracker
 func (it *InvoiceTracker) Start() error {
-	log.Debug().Msgf("Starting invoice tracker for session %s", it.deps.SessionID)
-	it.deps.TimeTracker.StartTracking()
+	it.logger().Debug().Msgf("Starting invoice tracker for session %s", it.deps.SessionID)
+
+	// Billing time starts once the data plane reports traffic, not when the session is created.
+	if err := it.deps.EventBus.SubscribeWithUID(sessionEvent.AppTopicDataStarted, it.deps.SessionID, it.consumeDataStartedEvent); err != nil {
+		return err
+	}
 
 	if err := it.deps.EventBus.SubscribeWithUID(sessionEvent.AppTopicDataTransferred, it.deps.SessionID, it.consumeDataTransferredEvent); err != nil {
 		return err
 	}
 
+	if err := it.deps.EventBus.SubscribeWithUID(p2p.AppTopicPeerUnresponsive, it.deps.SessionID, it.consumePeerUnresponsiveEvent); err != nil {
+		return err
+	}
+
 	registry, err := it.deps.AddressProvider.GetRegistryAddress(it.deps.ChainID)
 	if err != nil {
 		return err
@@ -295,17 +442,28 @@ func (it *InvoiceTracker) Start() error {
 	}
 
 	if !status.IsActive {
-		log.Error().Msgf("Hermes(%v) is inactive", it.deps.ConsumersHermesID.Hex())
+		it.logger().Error().Msgf("Hermes(%v) is inactive", it.deps.ConsumersHermesID.Hex())
 		return ErrHermesInactive
 	}
 
 	if status.Fee > it.deps.MaxAllowedHermesFee {
-		log.Error().Msgf("Hermes fee too large, asking for %v where %v is the limit", status.Fee, it.deps.MaxAllowedHermesFee)
+		it.logger().Error().Msgf("Hermes fee too large, asking for %v where %v is the limit", status.Fee, it.deps.MaxAllowedHermesFee)
 		return ErrHermesFeeTooLarge
 	}
+	it.feeMonitor = newHermesFeeMonitor(it.deps.HermesFeeChange, it.deps.MaxAllowedHermesFee, status.Fee, it.clock())
+
+	if err := it.waitPeerReady(); err != nil {
+		return err
+	}
 
 	it.generateAgreementID()
 
+	if it.deps.Watchdog != nil {
+		it.liveness = it.deps.Watchdog.Watch(it.deps.SessionID, it.deps.ChargePeriod+it.deps.ChargePeriodLeeway, it.terminateStuck)
+		defer it.deps.Watchdog.Unwatch(it.deps.SessionID)
+	}
+	it.liveness.Tick(goroutineInvoiceLoop)
+
 	emErrors := make(chan error)
 	go func() {
 		emErrors <- it.listenForExchangeMessages()
@@ -317,20 +475,41 @@ func (it *InvoiceTracker) Start() error {
 	}
 
 	go it.sendInvoicesWhenNeeded(time.Second)
+
+	var feeCheck <-chan time.Time
+	scheduleFeeCheck := func() {
+		if it.deps.HermesFeeChange.CheckInterval > 0 {
+			feeCheck = it.clock().After(it.deps.HermesFeeChange.CheckInterval)
+		}
+	}
+	scheduleFeeCheck()
+
 	for {
+		it.liveness.Tick(goroutineInvoiceLoop)
+
 		select {
+		case <-it.heartbeat():
 		case <-it.stop:
 			return nil
+		case <-it.peerUnresponsive:
+			// No point to keep invoicing and waiting for exchange messages until they time out.
+			it.logger().Warn().Msgf("Consumer of session %s is unresponsive, stopping invoice tracker", it.deps.SessionID)
+			return nil
 		case critical := <-it.invoiceChannel:
 			err := it.sendInvoice(critical)
 			if err != nil {
 				if stdErr.Is(err, p2p.ErrSendTimeout) {
-					log.Warn().Err(err).Msg("Marking invoice as not sent")
+					it.logger().Warn().Err(err).Msg("Marking invoice as not sent")
 					it.markExchangeMessageNotSent()
 				} else {
 					return fmt.Errorf("sending of invoice failed: %w", err)
 				}
 			}
+		case <-feeCheck:
+			if err := it.checkHermesFee(registry); err != nil {
+				return err
+			}
+			scheduleFeeCheck()
 		case err := <-it.criticalInvoiceErrors:
 			return err
 		case emErr := <-emErrors:
@@ -347,23 +526,31 @@ func (it *InvoiceTracker) Start() error {
 }
 
 func (it *InvoiceTracker) sendInvoicesWhenNeeded(interval time.Duration) {
-	it.lastInvoiceSent = it.deps.TimeTracker.Elapsed()
+	defer it.liveness.Done(goroutineInvoiceScheduler)
+	it.lastInvoiceSent = it.elapsed()
 	for {
+		it.liveness.Tick(goroutineInvoiceScheduler)
+
 		select {
 		case <-it.stop:
 			return
-		case <-time.After(interval):
-			currentlyElapsed := it.deps.TimeTracker.Elapsed()
-			shouldBe := CalculatePaymentAmount(currentlyElapsed, it.getDataTransferred(), it.deps.AgreedPrice)
+		case <-it.clock().After(interval):
+			if it.isPaused() {
+				continue
+			}
+
+			currentlyElapsed := it.elapsed()
+			shouldBe := it.pricing.amount(it.billableElapsed(), it.getDataTransferred())
 			lastEM := it.getLastExchangeMessage()
-			diff := safeSub(shouldBe, lastEM.AgreementTotal)
-			if diff.Cmp(it.deps.MaxNotPaidInvoice) >= 0 && currentlyElapsed-it.lastInvoiceSent > it.invoiceDebounceRate {
-				it.lastInvoiceSent = it.deps.TimeTracker.Elapsed()
+			// Payments not covered by hermes are still owed, so they bring the next critical invoice closer.
+			diff := new(big.Int).Add(safeSub(shouldBe, lastEM.AgreementTotal), it.getShortfall())
+			if diff.Cmp(it.deps.MaxNotPaidInvoice) >= 0 && currentlyElapsed-it.lastInvoiceSent > it.invoiceDebounceRate && !it.billingPaused() {
+				it.lastInvoiceSent = it.elapsed()
 				it.invoiceChannel <- true
 
 				it.updateMaxUnpaid()
 			} else if currentlyElapsed-it.lastInvoiceSent > it.deps.ChargePeriod {
-				it.lastInvoiceSent = it.deps.TimeTracker.Elapsed()
+				it.lastInvoiceSent = it.elapsed()
 				it.invoiceChannel <- false
 
 				it.updateTimer()
@@ -387,7 +574,7 @@ func (it *InvoiceTracker) updateMaxUnpaid() {
 	}
 
 	it.deps.MaxNotPaidInvoice = bigger
-	log.Debug().Str("invoice_amount", it.deps.MaxNotPaidInvoice.String()).Msg("Max invoice amount increased")
+	it.logger().Debug().Str("invoice_amount", it.deps.MaxNotPaidInvoice.String()).Msg("Max invoice amount increased")
 }
 
 func (it *InvoiceTracker) updateTimer() {
@@ -401,16 +588,16 @@ func (it *InvoiceTracker) updateTimer() {
 		newMaxTime = maxTime
 	}
 	it.deps.ChargePeriod = newMaxTime
-	log.Debug().Int64("change_period (ms)", it.deps.ChargePeriod.Milliseconds()).Msg("Max charge period increased")
+	it.logger().Debug().Int64("change_period (ms)", it.deps.ChargePeriod.Milliseconds()).Msg("Max charge period increased")
 }
 
 // WaitFirstInvoice waits for a first invoice to be paid.
 func (it *InvoiceTracker) WaitFirstInvoice(wait time.Duration) error {
-	timeout := time.After(wait)
+	timeout := it.clock().After(wait)
 
 	for {
 		select {
-		case <-time.After(10 * time.Millisecond):
+		case <-it.clock().After(10 * time.Millisecond):
 			it.invoiceLock.Lock()
 			paid := it.firstInvoicePaid
 			it.invoiceLock.Unlock()
@@ -492,28 +679,33 @@ func (it *InvoiceTracker) sendInvoice(isCritical bool) error {
 		return ErrExchangeWaitTimeout
 	}
 
-	shouldBe := CalculatePaymentAmount(it.deps.TimeTracker.Elapsed(), it.getDataTransferred(), it.deps.AgreedPrice)
+	shouldBe := it.checkBillingAnomaly(it.pricing.amount(it.billableElapsed(), it.getDataTransferred()))
 
 	lastEm := it.getLastExchangeMessage()
 	if lastEm.AgreementTotal.Cmp(big.NewInt(0)) == 0 && shouldBe.Cmp(big.NewInt(0)) == 1 {
 		// The first invoice should have minimal static value.
 		shouldBe = providerFirstInvoiceValue
-		log.Debug().Msgf("Being lenient for the first payment, asking for %v", shouldBe)
+		it.logger().Debug().Msgf("Being lenient for the first payment, asking for %v", shouldBe)
 	}
 
 	r := crypto.GenerateR()
 	invoice := crypto.CreateInvoice(it.agreementID, shouldBe, new(big.Int), r, it.chainID())
 	invoice.Provider = it.deps.ProviderID.Address
-	err := it.deps.PeerInvoiceSender.Send(invoice)
+	receipt, err := it.deps.PeerInvoiceSender.Send(invoice)
 	if err != nil {
 		return err
 	}
+	it.observePeerClock(receipt)
 
 	it.markInvoiceSent(sentInvoice{
 		invoice:    invoice,
 		r:          r,
 		isCritical: isCritical,
 	})
+	it.updatePaymentState(func(state *sessionEvent.AppEventSessionPayment) {
+		state.LastInvoiceAmount = invoice.AgreementTotal
+		state.LastInvoiceAt = it.clock().Now().UTC()
+	})
 
 	hlock, err := hex.DecodeString(invoice.Hashlock)
 	if err != nil {
@@ -526,21 +718,193 @@ func (it *InvoiceTracker) sendInvoice(isCritical bool) error {
 	return errors.Wrap(err, "could not store invoice")
 }
 
+// checkHermesFee makes sure hermes did not raise its fee above the allowed limit during the session,
+// applying the configured policy once the fee stays above the limit for the grace period.
+func (it *InvoiceTracker) checkHermesFee(registry common.Address) error {
+	status, err := it.deps.HermesStatusChecker.GetHermesStatus(it.deps.ChainID, registry, it.deps.ConsumersHermesID)
+	if err != nil {
+		it.logger().Warn().Err(err).Msgf("Could not check hermes fee of session %s", it.deps.SessionID)
+		return nil
+	}
+
+	switch it.feeMonitor.check(status.Fee) {
+	case hermesFeeExceeded:
+		it.logger().Warn().Msgf("Hermes raised its fee to %v where %v is the limit, session %s will %s after %s",
+			status.Fee, it.deps.MaxAllowedHermesFee, it.deps.SessionID, it.deps.HermesFeeChange.Policy, it.feeMonitor.deadline())
+		if err := it.notifyHermesFeeChange(status.Fee, false, nil); err != nil {
+			it.logger().Warn().Err(err).Msgf("Could not notify consumer about hermes fee change of session %s", it.deps.SessionID)
+		}
+	case hermesFeeRestored:
+		it.logger().Info().Msgf("Hermes fee is back within the limit at %v for session %s", status.Fee, it.deps.SessionID)
+	case hermesFeeGraceExpired:
+		return it.applyHermesFeePolicy(status.Fee)
+	}
+	return nil
+}
+
+func (it *InvoiceTracker) applyHermesFeePolicy(fee uint16) error {
+	switch it.deps.HermesFeeChange.Policy {
+	case HermesFeePolicyAbsorb:
+		it.logger().Info().Msgf("Absorbing hermes fee of %v in session %s", fee, it.deps.SessionID)
+		it.notifyHermesFeeApplied(fee)
+		return nil
+	case HermesFeePolicyRenegotiate:
+		price, err := hermesFeeAdjustedPrice(it.pricing.current(), it.feeMonitor.fee, fee)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrHermesFeeTooLarge, err)
+		}
+
+		if err := it.notifyHermesFeeChange(fee, true, &price); err != nil {
+			return fmt.Errorf("%w: consumer did not accept the renegotiated price: %v", ErrHermesFeeTooLarge, err)
+		}
+
+		it.pricing.reprice(price, it.billableElapsed(), it.getDataTransferred())
+		it.anomalyDetectorLock.Lock()
+		it.anomalyDetector.price = price
+		it.anomalyDetectorLock.Unlock()
+		it.feeMonitor.rebase(fee)
+
+		it.logger().Info().Msgf("Session %s price renegotiated to %v because of hermes fee of %v", it.deps.SessionID, price.String(), fee)
+		return nil
+	default:
+		it.notifyHermesFeeApplied(fee)
+		return ErrHermesFeeTooLarge
+	}
+}
+
+// notifyHermesFeeApplied lets the consumer know the policy was applied, failures are only logged as the session outcome does not depend on them.
+func (it *InvoiceTracker) notifyHermesFeeApplied(fee uint16) {
+	if err := it.notifyHermesFeeChange(fee, true, nil); err != nil {
+		it.logger().Warn().Err(err).Msgf("Could not notify consumer about hermes fee policy of session %s", it.deps.SessionID)
+	}
+}
+
+// notifyHermesFeeChange announces the hermes fee change and reports it to the consumer,
+// which has to accept the price if one is given.
+func (it *InvoiceTracker) notifyHermesFeeChange(fee uint16, applied bool, price *market.Price) error {
+	change := HermesFeeChange{
+		Fee:         fee,
+		PreviousFee: it.feeMonitor.fee,
+		MaxFee:      it.deps.MaxAllowedHermesFee,
+		Policy:      it.deps.HermesFeeChange.Policy,
+		Deadline:    it.feeMonitor.deadline(),
+		Applied:     applied,
+		Price:       price,
+	}
+
+	var err error
+	if it.deps.PeerHermesFeeNotifier != nil {
+		err = it.deps.PeerHermesFeeNotifier.SendHermesFeeChange(it.deps.SessionID, change)
+	}
+	if price != nil && err != nil {
+		return err
+	}
+
+	it.deps.EventBus.Publish(event.AppTopicHermesFeeChanged, hermesFeeChangedEvent(it.deps.SessionID, it.deps.ConsumersHermesID, change))
+	return err
+}
+
+// checkBillingAnomaly announces amounts which are not justified by the session traffic and time,
+// returning the amount which should be invoiced.
+func (it *InvoiceTracker) checkBillingAnomaly(amount *big.Int) *big.Int {
+	if it.deps.BillingAnomaly.Tolerance <= 0 {
+		return amount
+	}
+
+	it.anomalyDetectorLock.Lock()
+	defer it.anomalyDetectorLock.Unlock()
+
+	wasPaused := it.anomalyDetector.paused
+	invoiced, anomaly, found := it.anomalyDetector.check(it.elapsed(), it.getDataTransferred(), amount)
+	if wasPaused && !it.anomalyDetector.paused {
+		it.logger().Info().Str("invoiced", invoiced.String()).Msg("Session billing resumed after the anomaly pause")
+	}
+	if !found {
+		return invoiced
+	}
+
+	it.logger().Warn().
+		Str("anomaly", string(anomaly)).
+		Str("amount", amount.String()).
+		Bool("paused", it.anomalyDetector.paused).
+		Msg("Session billing looks wrong")
+	it.deps.EventBus.Publish(event.AppTopicBillingAnomaly, event.AppEventBillingAnomaly{
+		SessionID:  it.deps.SessionID,
+		ConsumerID: it.deps.Peer,
+		Anomaly:    string(anomaly),
+		Amount:     amount,
+		Invoiced:   invoiced,
+		Paused:     it.anomalyDetector.paused,
+	})
+	return invoiced
+}
+
+// updatePaymentState applies the given change to the session payment state and announces it.
+func (it *InvoiceTracker) updatePaymentState(update func(state *sessionEvent.AppEventSessionPayment)) {
+	it.paymentStateLock.Lock()
+	update(&it.paymentState)
+	it.paymentState.SessionID = it.deps.SessionID
+	it.paymentState.Unpaid = safeSub(it.paymentState.LastInvoiceAmount, it.paymentState.Paid)
+	if it.paymentState.Shortfall != nil {
+		it.paymentState.Unpaid.Add(it.paymentState.Unpaid, it.paymentState.Shortfall)
+	}
+	state := it.paymentState
+	it.paymentStateLock.Unlock()
+
+	it.deps.EventBus.Publish(sessionEvent.AppTopicSessionPayment, state)
+}
+
+func (it *InvoiceTracker) getShortfall() *big.Int {
+	it.paymentStateLock.Lock()
+	defer it.paymentStateLock.Unlock()
+	if it.paymentState.Shortfall == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(it.paymentState.Shortfall)
+}
+
+// handlePromiseShortfall records the part of the payments which hermes did not cover and informs the consumer about it.
+// The shortfall is treated as unpaid, so the session ends once it exceeds the amount the consumer is allowed to owe.
+func (it *InvoiceTracker) handlePromiseShortfall(shortfall *PromiseShortfallError) error {
+	it.updatePaymentState(func(state *sessionEvent.AppEventSessionPayment) {
+		state.Shortfall = shortfall.Total
+	})
+
+	if it.deps.PeerShortfallNotifier != nil {
+		go func() {
+			if err := it.deps.PeerShortfallNotifier.SendShortfall(it.deps.SessionID, it.agreementID, *shortfall); err != nil {
+				it.logger().Warn().Err(err).Msgf("Could not inform consumer about the payment shortfall of session %s", it.deps.SessionID)
+			}
+		}()
+	}
+
+	if limit := it.deps.LimitNotPaidInvoice; limit != nil && shortfall.Total.Cmp(limit) > 0 {
+		return fmt.Errorf("%w: %v", ErrHermesPromiseShortfall, shortfall)
+	}
+	return nil
+}
+
+func (it *InvoiceTracker) billingPaused() bool {
+	it.anomalyDetectorLock.Lock()
+	defer it.anomalyDetectorLock.Unlock()
+	return it.anomalyDetector.paused
+}
+
 func (it *InvoiceTracker) waitForInvoicePayment(hlock []byte) {
 	select {
-	case <-time.After(it.deps.ExchangeMessageWaitTimeout):
+	case <-it.clock().After(it.deps.ExchangeMessageWaitTimeout):
 		inv, ok := it.getMarkedInvoice(hlock)
 		if !ok {
 			return
 		}
 
 		if inv.isCritical {
-			log.Info().Msgf("did not get paid for invoice with hashlock %v, invoice is critical. Aborting.", inv.invoice.Hashlock)
+			it.logger().Info().Msgf("did not get paid for invoice with hashlock %v, invoice is critical. Aborting.", inv.invoice.Hashlock)
 			it.criticalInvoiceErrors <- fmt.Errorf("did not get paid for critical invoice with hashlock %v", inv.invoice.Hashlock)
 			return
 		}
 
-		log.Info().Msgf("did not get paid for invoice with hashlock %v, incrementing failure count", inv.invoice.Hashlock)
+		it.logger().Info().Msgf("did not get paid for invoice with hashlock %v, incrementing failure count", inv.invoice.Hashlock)
 		it.markInvoicePaid(hlock)
 		it.markExchangeMessageNotReceived()
 	case <-it.stop:
@@ -554,6 +918,11 @@ func (it *InvoiceTracker) handleHermesError(err error) error {
 		return nil
 	}
 
+	var shortfall *PromiseShortfallError
+	if stdErr.As(err, &shortfall) {
+		return it.handlePromiseShortfall(shortfall)
+	}
+
 	switch {
 	case
 		stdErr.Is(err, ErrHermesHashlockMissmatch),
@@ -566,7 +935,7 @@ func (it *InvoiceTracker) handleHermesError(err error) error {
 		if it.incrementHermesFailureCount() > it.deps.MaxHermesFailureCount {
 			return err
 		}
-		log.Warn().Err(err).Msg("hermes error, will retry")
+		it.logger().Warn().Err(err).Msg("hermes error, will retry")
 		return nil
 	case
 		stdErr.Is(err, ErrHermesInvalidSignature),
@@ -595,7 +964,7 @@ func (it *InvoiceTracker) handleHermesError(err error) error {
 		if it.incrementHermesFailureCount() > it.deps.MaxHermesFailureCount {
 			return err
 		}
-		log.Warn().Err(err).Msg("unknown hermes error encountered, will retry")
+		it.logger().Warn().Err(err).Msg("unknown hermes error encountered, will retry")
 		return nil
 	}
 }
@@ -604,7 +973,7 @@ func (it *InvoiceTracker) incrementHermesFailureCount() uint64 {
 	it.hermesFailureCountLock.Lock()
 	defer it.hermesFailureCountLock.Unlock()
 	it.hermesFailureCount++
-	log.Trace().Msgf("hermes error count %v/%v", it.hermesFailureCount, it.deps.MaxHermesFailureCount)
+	it.logger().Trace().Msgf("hermes error count %v/%v", it.hermesFailureCount, it.deps.MaxHermesFailureCount)
 	return it.hermesFailureCount
 }
 
@@ -635,7 +1004,7 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 
 	lastEm := it.getLastExchangeMessage()
 	if em.Promise.Amount.Cmp(lastEm.Promise.Amount) == -1 {
-		log.Warn().Msgf("Consumer sent an invalid amount. Expected < %v, got %v", lastEm.Promise.Amount, em.Promise.Amount)
+		it.logger().Warn().Msgf("Consumer sent an invalid amount. Expected < %v, got %v", lastEm.Promise.Amount, em.Promise.Amount)
 		return errors.Wrap(ErrConsumerPromiseValidationFailed, "invalid amount")
 	}
 
@@ -647,7 +1016,7 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 	hermesId := common.HexToAddress(em.HermesID)
 	chimp, err := it.deps.AddressProvider.GetChannelImplementationForHermes(em.ChainID, hermesId)
 	if err != nil {
-		log.Err(err).Msgf("Failed to get channel implementation for hermes %s, using fallback", em.HermesID)
+		it.logger().Err(err).Msgf("Failed to get channel implementation for hermes %s, using fallback", em.HermesID)
 		hermesData, err := it.deps.Observer.GetHermesData(em.ChainID, hermesId)
 		if err != nil {
 			return errors.Wrap(err, "could not get channel implementation")
@@ -666,27 +1035,205 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 	}
 
 	if !bytes.Equal(expectedChannel, em.Promise.ChannelID) {
-		log.Warn().Msgf("Consumer sent an invalid channel address. Expected %q, got %q", addr.Hex(), hex.EncodeToString(em.Promise.ChannelID))
+		it.logger().Warn().Msgf("Consumer sent an invalid channel address. Expected %q, got %q", addr.Hex(), hex.EncodeToString(em.Promise.ChannelID))
 		return errors.Wrap(ErrConsumerPromiseValidationFailed, "invalid channel address")
 	}
 	return nil
 }
 
+// validatePromisedTotal makes sure the consumer paid the invoice. Consumer derives the amounts from its own
+// measurement of the session time, so the promised total may fall behind the invoiced one by the tolerance
+// widened by the measured peer clock skew.
+func (it *InvoiceTracker) validatePromisedTotal(em crypto.ExchangeMessage, invoice crypto.Invoice) error {
+	if em.AgreementTotal == nil || invoice.AgreementTotal == nil {
+		return nil
+	}
+
+	tolerated := it.peerClock.toleratedAmount(it.pricing.current())
+	lowerBound := new(big.Int).Sub(invoice.AgreementTotal, tolerated)
+	if em.AgreementTotal.Cmp(lowerBound) < 0 {
+		it.logger().Warn().Msgf("Consumer promised a total below the invoice. Expected >= %v (invoiced %v), got %v", lowerBound, invoice.AgreementTotal, em.AgreementTotal)
+		return errors.Wrap(ErrConsumerPromiseValidationFailed, "agreement total below invoice")
+	}
+	return nil
+}
+
+// observePeerClock updates the peer clock skew from the invoice receipt of the consumer.
+func (it *InvoiceTracker) observePeerClock(receipt InvoiceReceipt) {
+	if receipt.ReceivedAt.IsZero() {
+		return
+	}
+
+	it.peerClock.ObserveRoundTrip(receipt.SentAt, receipt.ReceivedAt, receipt.RepliedAt)
+	if offset, _ := it.peerClock.Offset(); absDuration(offset) > it.deps.PeerClockSkew.Tolerance {
+		it.logger().Debug().Dur("offset", offset).Msg("Consumer clock is out of sync with the provider")
+	}
+}
+
 // Stop stops the invoice tracker.
 func (it *InvoiceTracker) Stop() {
 	it.once.Do(func() {
-		log.Debug().Msgf("Stopping invoice tracker for session %s", it.deps.SessionID)
+		it.logger().Debug().Msgf("Stopping invoice tracker for session %s", it.deps.SessionID)
+		_ = it.deps.EventBus.UnsubscribeWithUID(sessionEvent.AppTopicDataStarted, it.deps.SessionID, it.consumeDataStartedEvent)
 		_ = it.deps.EventBus.UnsubscribeWithUID(sessionEvent.AppTopicDataTransferred, it.deps.SessionID, it.consumeDataTransferredEvent)
+		_ = it.deps.EventBus.UnsubscribeWithUID(p2p.AppTopicPeerUnresponsive, it.deps.SessionID, it.consumePeerUnresponsiveEvent)
 		close(it.stop)
 	})
 }
 
+// heartbeat wakes up the otherwise idle goroutines to tick the liveness.
+func (it *InvoiceTracker) heartbeat() <-chan time.Time {
+	if it.liveness == nil {
+		return nil
+	}
+	return it.clock().After(it.deps.ChargePeriod)
+}
+
+// Stuck returns a channel which receives the reason once the watchdog finds the tracker stuck.
+// Stuck tracker can not return from Start, so the session has to be terminated by the caller.
+func (it *InvoiceTracker) Stuck() <-chan error {
+	return it.stuck
+}
+
+func (it *InvoiceTracker) terminateStuck(err error) {
+	select {
+	case it.stuck <- err:
+	default:
+	}
+}
+
+func (it *InvoiceTracker) consumePeerUnresponsiveEvent(e p2p.AppEventPeerUnresponsive) {
+	// skip irrelevant sessions
+	if !strings.EqualFold(e.SessionID, it.deps.SessionID) {
+		return
+	}
+
+	select {
+	case it.peerUnresponsive <- struct{}{}:
+	default:
+	}
+}
+
+func (it *InvoiceTracker) consumeDataStartedEvent(e sessionEvent.AppEventDataStarted) {
+	// skip irrelevant sessions
+	if !strings.EqualFold(e.ID, it.deps.SessionID) {
+		return
+	}
+
+	it.startBilling()
+}
+
+// startBilling marks the data plane as started. Billing time tracking starts once the consumer is ready to pay too.
+// It's safe to call multiple times.
+func (it *InvoiceTracker) startBilling() {
+	it.timeTrackerLock.Lock()
+	it.dataStarted = true
+	ready := it.peerReady
+	it.timeTrackerLock.Unlock()
+
+	if ready {
+		it.startTracking()
+	}
+}
+
+// markPeerReady marks the consumer as ready to pay, billing starts if the data plane has started already.
+func (it *InvoiceTracker) markPeerReady() {
+	it.timeTrackerLock.Lock()
+	it.peerReady = true
+	started := it.dataStarted
+	it.timeTrackerLock.Unlock()
+
+	if started {
+		it.startTracking()
+	}
+}
+
+func (it *InvoiceTracker) startTracking() {
+	it.billingStarted.Do(func() {
+		it.logger().Debug().Msgf("Data plane started and consumer is ready for session %s, starting billing", it.deps.SessionID)
+		it.timeTrackerLock.Lock()
+		defer it.timeTrackerLock.Unlock()
+		it.deps.TimeTracker.StartTracking()
+	})
+}
+
+// waitPeerReady pings the consumer until it confirms it is ready to pay, so the time
+// spent setting up the network between the peers is not billed.
+func (it *InvoiceTracker) waitPeerReady() error {
+	if it.deps.PeerReadinessChecker == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), peerReadyTimeout)
+	defer cancel()
+
+	for {
+		err := it.pingPeerReady(ctx)
+		if err == nil {
+			it.markPeerReady()
+			return nil
+		}
+		if stdErr.Is(err, p2p.ErrHandlerNotFound) {
+			// Consumers which do not know about the readiness check are ready once they have created the session.
+			it.logger().Debug().Msgf("Consumer of session %s does not support readiness check", it.deps.SessionID)
+			it.markPeerReady()
+			return nil
+		}
+		it.logger().Debug().Err(err).Msgf("Consumer of session %s is not ready yet", it.deps.SessionID)
+
+		select {
+		case <-it.stop:
+			return nil
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ErrPeerNotReady, err)
+		case <-it.clock().After(peerReadyRetryInterval):
+		}
+	}
+}
+
+func (it *InvoiceTracker) pingPeerReady(ctx context.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx, peerReadyRetryInterval*2)
+	defer cancel()
+	return it.deps.PeerReadinessChecker.SendReady(pingCtx, it.deps.SessionID)
+}
+
+func (it *InvoiceTracker) elapsed() time.Duration {
+	elapsed := it.trackedElapsed()
+
+	it.pauseLock.Lock()
+	defer it.pauseLock.Unlock()
+	if it.paused {
+		elapsed = it.pausedAt
+	}
+	return elapsed - it.pausedTime
+}
+
+func (it *InvoiceTracker) trackedElapsed() time.Duration {
+	it.timeTrackerLock.Lock()
+	defer it.timeTrackerLock.Unlock()
+	return it.deps.TimeTracker.Elapsed()
+}
+
+// billableElapsed returns the elapsed billing time excluding the initial free window.
+func (it *InvoiceTracker) billableElapsed() time.Duration {
+	elapsed := it.elapsed() - it.deps.InitialFreeWindow
+	if elapsed < 0 {
+		return 0
+	}
+	return elapsed
+}
+
 func (it *InvoiceTracker) consumeDataTransferredEvent(e sessionEvent.AppEventDataTransferred) {
 	// skip irrelevant sessions
 	if !strings.EqualFold(e.ID, it.deps.SessionID) {
 		return
 	}
 
+	// not every data plane reports the data start, so the first traffic counts as one too.
+	if e.Up+e.Down > 0 {
+		it.startBilling()
+	}
+
 	// From a server perspective, bytes up are the actual bytes the client downloaded(aka the bytes we pushed to the consumer)
 	// To lessen the confusion, I suggest having the bytes reversed on the session instance.
 	// This way, the session will show that it downloaded the bytes in a manner that is easier to comprehend.
@@ -714,8 +1261,61 @@ func (it *InvoiceTracker) updateDataTransfer(up, down uint64) {
 }
 
 func (it *InvoiceTracker) getDataTransferred() DataTransferred {
+	data := it.trackedDataTransferred()
+
+	it.pauseLock.Lock()
+	defer it.pauseLock.Unlock()
+	if it.paused {
+		data = it.pausedDataAt
+	}
+	return DataTransferred{
+		Up:   data.Up - it.pausedData.Up,
+		Down: data.Down - it.pausedData.Down,
+	}
+}
+
+func (it *InvoiceTracker) trackedDataTransferred() DataTransferred {
 	it.dataTransferredLock.Lock()
 	defer it.dataTransferredLock.Unlock()
 
 	return it.dataTransferred
 }
+
+// Pause stops invoicing the session until it is resumed, time and traffic in between are not billed.
+func (it *InvoiceTracker) Pause() {
+	elapsed, data := it.trackedElapsed(), it.trackedDataTransferred()
+
+	it.pauseLock.Lock()
+	defer it.pauseLock.Unlock()
+	if it.paused {
+		return
+	}
+
+	it.logger().Info().Msgf("Pausing billing of session %s", it.deps.SessionID)
+	it.paused = true
+	it.pausedAt = elapsed
+	it.pausedDataAt = data
+}
+
+// Resume continues invoicing the paused session under the same agreement.
+func (it *InvoiceTracker) Resume() {
+	elapsed, data := it.trackedElapsed(), it.trackedDataTransferred()
+
+	it.pauseLock.Lock()
+	defer it.pauseLock.Unlock()
+	if !it.paused {
+		return
+	}
+
+	it.logger().Info().Msgf("Resuming billing of session %s", it.deps.SessionID)
+	it.paused = false
+	it.pausedTime += elapsed - it.pausedAt
+	it.pausedData.Up += data.Up - it.pausedDataAt.Up
+	it.pausedData.Down += data.Down - it.pausedDataAt.Down
+}
+
+func (it *InvoiceTracker) isPaused() bool {
+	it.pauseLock.Lock()
+	defer it.pauseLock.Unlock()
+	return it.paused
+}
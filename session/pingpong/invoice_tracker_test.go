@@ -18,6 +18,7 @@
 package pingpong
 
 import (
+	"context"
 	"encoding/hex"
 	"io/ioutil"
 	"math/big"
@@ -31,7 +32,9 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/session"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/mbtime"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/mysteriumnetwork/payments/observer"
@@ -47,14 +50,15 @@ const (
 
 type MockPeerInvoiceSender struct {
 	mockError     error
+	mockReceipt   InvoiceReceipt
 	chanToWriteTo chan crypto.Invoice
 }
 
-func (mpis *MockPeerInvoiceSender) Send(invoice crypto.Invoice) error {
+func (mpis *MockPeerInvoiceSender) Send(invoice crypto.Invoice) (InvoiceReceipt, error) {
 	if mpis.chanToWriteTo != nil {
 		mpis.chanToWriteTo <- invoice
 	}
-	return mpis.mockError
+	return mpis.mockReceipt, mpis.mockError
 }
 
 type mockHermesCaller struct {
@@ -74,6 +78,7 @@ func (mac *mockHermesCaller) RevealR(r string, provider string, agreementID *big
 }
 
 func (mac *mockHermesCaller) UpdatePromiseFee(promise crypto.Promise, newFee *big.Int) (crypto.Promise, error) {
+	promise.Fee = newFee
 	return promise, nil
 }
 
@@ -142,6 +147,61 @@ func Test_InvoiceTracker_Start_Stop(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func Test_InvoiceTracker_StopsWhenPeerUnresponsive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "invoice_tracker_test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	assert.Nil(t, err)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.Nil(t, err)
+	defer bolt.Close()
+
+	tracker := session.NewTracker(mbtime.Now)
+	deps := InvoiceTrackerDeps{
+		AgreedPrice:                *market.NewPrice(600, 0),
+		Peer:                       identity.FromAddress("some peer"),
+		PeerInvoiceSender:          &MockPeerInvoiceSender{chanToWriteTo: make(chan crypto.Invoice, 10)},
+		EventBus:                   mocks.NewEventBus(),
+		InvoiceStorage:             NewProviderInvoiceStorage(NewInvoiceStorage(bolt)),
+		TimeTracker:                &tracker,
+		ChargePeriod:               time.Nanosecond,
+		ChargePeriodLeeway:         15 * time.Minute,
+		LimitChargePeriod:          time.Nanosecond,
+		LimitNotPaidInvoice:        big.NewInt(0),
+		ExchangeMessageChan:        make(chan crypto.ExchangeMessage),
+		ExchangeMessageWaitTimeout: time.Second,
+		ProviderID:                 identity.FromAddress(acc.Address.Hex()),
+		ConsumersHermesID:          acc.Address,
+		AddressProvider:            &mockAddressProvider{},
+		HermesStatusChecker:        &mockHermesStatusChecker{statusToReturn: HermesStatus{IsActive: true}},
+		SessionID:                  "session-1",
+	}
+	invoiceTracker := NewInvoiceTracker(deps)
+	defer invoiceTracker.Stop()
+
+	invoiceTracker.consumePeerUnresponsiveEvent(p2p.AppEventPeerUnresponsive{SessionID: "session-2"})
+	assert.Len(t, invoiceTracker.peerUnresponsive, 0)
+
+	invoiceTracker.consumePeerUnresponsiveEvent(p2p.AppEventPeerUnresponsive{SessionID: "session-1"})
+	invoiceTracker.consumePeerUnresponsiveEvent(p2p.AppEventPeerUnresponsive{SessionID: "session-1"})
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- invoiceTracker.Start()
+	}()
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("invoice tracker did not stop for unresponsive peer")
+	}
+}
+
 func Test_InvoiceTracker_Start_RefusesLargeFee(t *testing.T) {
 	dir, err := ioutil.TempDir("", "invoice_tracker_test")
 	assert.Nil(t, err)
@@ -212,7 +272,7 @@ func Test_InvoiceTracker_Start_BubblesHermesCheckError(t *testing.T) {
 	mockErr := errors.New("explosions everywhere")
 	tracker := session.NewTracker(mbtime.Now)
 	invoiceStorage := NewProviderInvoiceStorage(NewInvoiceStorage(bolt))
-	NewHermesPromiseStorage(bolt)
+	NewHermesPromiseStorage(bolt, bolt)
 	deps := InvoiceTrackerDeps{
 		AgreedPrice:                *market.NewPrice(600, 0),
 		Peer:                       identity.FromAddress("some peer"),
@@ -338,6 +398,8 @@ func Test_InvoiceTracker_SendsInvoice(t *testing.T) {
 		EventBus:                   mocks.NewEventBus(),
 	}
 	invoiceTracker := NewInvoiceTracker(deps)
+	// simulate the data plane reporting the first traffic
+	invoiceTracker.startBilling()
 	defer invoiceTracker.Stop()
 
 	errChan := make(chan error)
@@ -390,6 +452,8 @@ func Test_InvoiceTracker_FirstInvoice_Has_Static_Value(t *testing.T) {
 		EventBus:                   mocks.NewEventBus(),
 	}
 	invoiceTracker := NewInvoiceTracker(deps)
+	// simulate the data plane reporting the first traffic
+	invoiceTracker.startBilling()
 	defer invoiceTracker.Stop()
 
 	errChan := make(chan error)
@@ -722,6 +786,7 @@ func TestInvoiceTracker_receiveExchangeMessageOrTimeout(t *testing.T) {
 				agreementID:         tt.fields.AgreementID,
 				deps:                deps,
 				invoicesSent:        tt.fields.invoicesSent,
+				pricing:             newSessionPricing(deps.AgreedPrice),
 			}
 			if err := it.handleExchangeMessage(*tt.em); (err != nil) != tt.wantErr {
 				t.Errorf("InvoiceTracker.receiveExchangeMessageOrTimeout() error = %v, wantErr %v", err, tt.wantErr)
@@ -806,6 +871,128 @@ func TestInvoiceTracker_handleHermesError(t *testing.T) {
 	}
 }
 
+func TestInvoiceTracker_handlePromiseShortfall(t *testing.T) {
+	notifier := &mockShortfallNotifier{sent: make(chan PromiseShortfallError, 1)}
+	bus := mocks.NewEventBus()
+	it := &InvoiceTracker{
+		deps: InvoiceTrackerDeps{
+			SessionID:             "session",
+			EventBus:              bus,
+			PeerShortfallNotifier: notifier,
+			LimitNotPaidInvoice:   big.NewInt(100),
+		},
+		agreementID: big.NewInt(1),
+		paymentState: sessionEvent.AppEventSessionPayment{
+			LastInvoiceAmount: big.NewInt(300),
+			Paid:              big.NewInt(300),
+		},
+	}
+
+	shortfall := &PromiseShortfallError{Requested: big.NewInt(100), Covered: big.NewInt(40), Total: big.NewInt(60)}
+	err := it.handleHermesError(errors.Wrap(shortfall, "wrapped"))
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(60), it.getShortfall())
+
+	payment, ok := bus.Pop().(sessionEvent.AppEventSessionPayment)
+	assert.True(t, ok)
+	assert.Equal(t, big.NewInt(60), payment.Shortfall)
+	assert.Equal(t, big.NewInt(60), payment.Unpaid)
+
+	select {
+	case sent := <-notifier.sent:
+		assert.Equal(t, *shortfall, sent)
+	case <-time.After(time.Second):
+		t.Fatal("consumer was not informed about the shortfall")
+	}
+
+	err = it.handleHermesError(&PromiseShortfallError{Requested: big.NewInt(100), Covered: big.NewInt(40), Total: big.NewInt(120)})
+	assert.True(t, errors.Is(err, ErrHermesPromiseShortfall))
+}
+
+func TestInvoiceTracker_waitPeerReady(t *testing.T) {
+	t.Run("billing starts once data plane started and consumer is ready", func(t *testing.T) {
+		tracker := &startRecordingTimeTracker{}
+		checker := &mockReadinessChecker{errs: []error{p2p.ErrSendTimeout, nil}}
+		invoiceTracker := NewInvoiceTracker(InvoiceTrackerDeps{
+			TimeTracker:          tracker,
+			PeerReadinessChecker: checker,
+			SessionID:            "session",
+		})
+
+		invoiceTracker.startBilling()
+		assert.False(t, tracker.started, "consumer has not confirmed it is ready yet")
+
+		assert.NoError(t, invoiceTracker.waitPeerReady())
+		assert.Equal(t, 2, checker.calls)
+		assert.True(t, tracker.started)
+	})
+
+	t.Run("billing waits for data plane after consumer is ready", func(t *testing.T) {
+		tracker := &startRecordingTimeTracker{}
+		invoiceTracker := NewInvoiceTracker(InvoiceTrackerDeps{
+			TimeTracker:          tracker,
+			PeerReadinessChecker: &mockReadinessChecker{},
+		})
+
+		assert.NoError(t, invoiceTracker.waitPeerReady())
+		assert.False(t, tracker.started)
+
+		invoiceTracker.startBilling()
+		assert.True(t, tracker.started)
+	})
+
+	t.Run("consumer without readiness check support is ready", func(t *testing.T) {
+		tracker := &startRecordingTimeTracker{}
+		checker := &mockReadinessChecker{errs: []error{p2p.ErrHandlerNotFound}}
+		invoiceTracker := NewInvoiceTracker(InvoiceTrackerDeps{
+			TimeTracker:          tracker,
+			PeerReadinessChecker: checker,
+		})
+
+		assert.NoError(t, invoiceTracker.waitPeerReady())
+		assert.Equal(t, 1, checker.calls)
+
+		invoiceTracker.startBilling()
+		assert.True(t, tracker.started)
+	})
+}
+
+type mockReadinessChecker struct {
+	errs  []error
+	calls int
+}
+
+func (mrc *mockReadinessChecker) SendReady(_ context.Context, _ string) error {
+	mrc.calls++
+	if len(mrc.errs) == 0 {
+		return nil
+	}
+	err := mrc.errs[0]
+	mrc.errs = mrc.errs[1:]
+	return err
+}
+
+type startRecordingTimeTracker struct {
+	started bool
+}
+
+func (srt *startRecordingTimeTracker) StartTracking() {
+	srt.started = true
+}
+
+func (srt *startRecordingTimeTracker) Elapsed() time.Duration {
+	return 0
+}
+
+type mockShortfallNotifier struct {
+	sent chan PromiseShortfallError
+}
+
+func (msn *mockShortfallNotifier) SendShortfall(_ string, _ *big.Int, shortfall PromiseShortfallError) error {
+	msn.sent <- shortfall
+	return nil
+}
+
 type mockEncryptor struct {
 	errToReturn error
 }
@@ -926,6 +1113,79 @@ func TestInvoiceTracker_validateExchangeMessage(t *testing.T) {
 	}
 }
 
+func TestInvoiceTracker_BillingStartsWithDataPlane(t *testing.T) {
+	tracker := &mockTimeTracker{timeToReturn: time.Minute * 3}
+	it := NewInvoiceTracker(InvoiceTrackerDeps{
+		SessionID:         "session",
+		TimeTracker:       &startAwareTimeTracker{mockTimeTracker: tracker},
+		InitialFreeWindow: time.Minute,
+	})
+	assert.Equal(t, time.Duration(0), it.billableElapsed())
+
+	it.consumeDataStartedEvent(sessionEvent.AppEventDataStarted{ID: "other session"})
+	assert.Equal(t, time.Duration(0), it.billableElapsed())
+
+	it.consumeDataStartedEvent(sessionEvent.AppEventDataStarted{ID: "session"})
+	assert.Equal(t, time.Minute*2, it.billableElapsed())
+
+	tracker.timeToReturn = time.Second * 30
+	assert.Equal(t, time.Duration(0), it.billableElapsed())
+}
+
+func TestInvoiceTracker_BillingStartsOnFirstTraffic(t *testing.T) {
+	it := NewInvoiceTracker(InvoiceTrackerDeps{
+		SessionID:   "session",
+		TimeTracker: &startAwareTimeTracker{mockTimeTracker: &mockTimeTracker{timeToReturn: time.Minute}},
+	})
+
+	it.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: "session"})
+	assert.Equal(t, time.Duration(0), it.billableElapsed())
+
+	it.consumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: "session", Up: 1})
+	assert.Equal(t, time.Minute, it.billableElapsed())
+}
+
+func TestInvoiceTracker_PausedPeriodIsNotBilled(t *testing.T) {
+	tracker := &mockTimeTracker{timeToReturn: time.Minute}
+	it := NewInvoiceTracker(InvoiceTrackerDeps{
+		SessionID:   "session",
+		TimeTracker: tracker,
+	})
+	it.updateDataTransfer(100, 1000)
+
+	it.Pause()
+	assert.True(t, it.isPaused())
+
+	tracker.timeToReturn = time.Minute * 5
+	it.updateDataTransfer(110, 1100)
+	assert.Equal(t, time.Minute, it.elapsed())
+	assert.Equal(t, DataTransferred{Up: 100, Down: 1000}, it.getDataTransferred())
+
+	it.Resume()
+	assert.False(t, it.isPaused())
+
+	tracker.timeToReturn = time.Minute * 6
+	it.updateDataTransfer(120, 1300)
+	assert.Equal(t, time.Minute*2, it.elapsed())
+	assert.Equal(t, DataTransferred{Up: 110, Down: 1200}, it.getDataTransferred())
+}
+
+type startAwareTimeTracker struct {
+	*mockTimeTracker
+	started bool
+}
+
+func (satt *startAwareTimeTracker) StartTracking() {
+	satt.started = true
+}
+
+func (satt *startAwareTimeTracker) Elapsed() time.Duration {
+	if !satt.started {
+		return 0
+	}
+	return satt.mockTimeTracker.Elapsed()
+}
+
 type mockHermesStatusChecker struct {
 	statusToReturn HermesStatus
 	errToReturn    error
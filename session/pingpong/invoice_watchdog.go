@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// ErrInvoiceTrackerStuck represents an error where payment goroutines of a session stopped making progress.
+var ErrInvoiceTrackerStuck = errors.New("invoice tracker is stuck")
+
+// maxStackDumpSize limits the goroutine dump attached to the diagnostics.
+const maxStackDumpSize = 64 * 1024
+
+// Liveness accounts the last time each payment goroutine of a session made progress.
+// A nil liveness accepts the ticks and accounts nothing.
+type Liveness struct {
+	now   func() time.Time
+	lock  sync.Mutex
+	ticks map[string]time.Time
+}
+
+func newLiveness(now func() time.Time) *Liveness {
+	return &Liveness{
+		now:   now,
+		ticks: make(map[string]time.Time),
+	}
+}
+
+// Tick marks the given goroutine as alive.
+func (l *Liveness) Tick(goroutine string) {
+	if l == nil {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.ticks[goroutine] = l.now()
+}
+
+// Done stops accounting the given goroutine, it is called once the goroutine exits.
+func (l *Liveness) Done(goroutine string) {
+	if l == nil {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	delete(l.ticks, goroutine)
+}
+
+// stalled returns the goroutines which did not tick within the deadline.
+func (l *Liveness) stalled(now time.Time, deadline time.Duration) map[string]time.Time {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	stalled := make(map[string]time.Time)
+	for goroutine, tick := range l.ticks {
+		if now.Sub(tick) > deadline {
+			stalled[goroutine] = tick
+		}
+	}
+	return stalled
+}
+
+type watchedTracker struct {
+	liveness  *Liveness
+	deadline  time.Duration
+	terminate func(error)
+}
+
+// InvoiceWatchdog supervises the payment goroutines of provider sessions.
+// A tracker which does not tick within its deadline is most likely blocked on a channel or a storage lock,
+// and would keep the session open without billing it, so such session is force-terminated.
+type InvoiceWatchdog struct {
+	publisher eventbus.Publisher
+	interval  time.Duration
+	clock     Clock
+
+	lock    sync.Mutex
+	watched map[string]watchedTracker
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewInvoiceWatchdog returns a new instance of invoice watchdog.
+func NewInvoiceWatchdog(publisher eventbus.Publisher, interval time.Duration) *InvoiceWatchdog {
+	return &InvoiceWatchdog{
+		publisher: publisher,
+		interval:  interval,
+		clock:     SystemClock{},
+		watched:   make(map[string]watchedTracker),
+		stop:      make(chan struct{}),
+	}
+}
+
+// Start checks the watched trackers until stopped.
+func (iw *InvoiceWatchdog) Start() {
+	for {
+		select {
+		case <-iw.stop:
+			return
+		case <-iw.clock.After(iw.interval):
+			iw.check()
+		}
+	}
+}
+
+// Stop stops checking the watched trackers.
+func (iw *InvoiceWatchdog) Stop() {
+	iw.stopOnce.Do(func() {
+		close(iw.stop)
+	})
+}
+
+// Watch starts supervising the session. Goroutines ticking the returned liveness must tick within the deadline,
+// otherwise terminate is called once with the reason.
+func (iw *InvoiceWatchdog) Watch(sessionID string, deadline time.Duration, terminate func(error)) *Liveness {
+	liveness := newLiveness(iw.clock.Now)
+
+	iw.lock.Lock()
+	defer iw.lock.Unlock()
+
+	iw.watched[sessionID] = watchedTracker{
+		liveness:  liveness,
+		deadline:  deadline,
+		terminate: terminate,
+	}
+	return liveness
+}
+
+// Unwatch stops supervising the session.
+func (iw *InvoiceWatchdog) Unwatch(sessionID string) {
+	iw.lock.Lock()
+	defer iw.lock.Unlock()
+
+	delete(iw.watched, sessionID)
+}
+
+func (iw *InvoiceWatchdog) check() {
+	now := iw.clock.Now()
+
+	stuck := make(map[string]watchedTracker)
+	diagnostics := make(map[string]event.AppEventInvoiceTrackerStuck)
+	iw.lock.Lock()
+	for sessionID, tracker := range iw.watched {
+		stalled := tracker.liveness.stalled(now, tracker.deadline)
+		if len(stalled) == 0 {
+			continue
+		}
+
+		diagnostic := event.AppEventInvoiceTrackerStuck{
+			SessionID: sessionID,
+			Deadline:  tracker.deadline,
+			LastTicks: stalled,
+		}
+		for goroutine := range stalled {
+			diagnostic.Goroutines = append(diagnostic.Goroutines, goroutine)
+		}
+		sort.Strings(diagnostic.Goroutines)
+
+		// Session is terminated only once, whatever happens to its goroutines afterwards.
+		delete(iw.watched, sessionID)
+		stuck[sessionID] = tracker
+		diagnostics[sessionID] = diagnostic
+	}
+	iw.lock.Unlock()
+
+	if len(stuck) == 0 {
+		return
+	}
+
+	stack := stackDump()
+	for sessionID, tracker := range stuck {
+		diagnostic := diagnostics[sessionID]
+		diagnostic.Stack = stack
+
+		log.Error().Msgf("Payment goroutines %s of session %s did not make progress in %s, terminating session", strings.Join(diagnostic.Goroutines, ", "), sessionID, tracker.deadline)
+		iw.publisher.Publish(event.AppTopicInvoiceTrackerStuck, diagnostic)
+		tracker.terminate(fmt.Errorf("%w: %s", ErrInvoiceTrackerStuck, strings.Join(diagnostic.Goroutines, ", ")))
+	}
+}
+
+func stackDump() string {
+	buf := make([]byte, maxStackDumpSize)
+	return string(buf[:runtime.Stack(buf, true)])
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func TestInvoiceWatchdog_TerminatesStuckTracker(t *testing.T) {
+	clock := newMockClock()
+	started := clock.Now()
+
+	publisher := mocks.NewEventBus()
+	watchdog := NewInvoiceWatchdog(publisher, time.Second)
+	watchdog.clock = clock
+
+	var terminated []error
+	liveness := watchdog.Watch("session", time.Minute, func(err error) { terminated = append(terminated, err) })
+	liveness.Tick(goroutineInvoiceLoop)
+	liveness.Tick(goroutineInvoiceScheduler)
+	liveness.Tick(goroutineExchangeListener)
+	liveness.Done(goroutineExchangeListener)
+
+	clock.Advance(time.Minute)
+	watchdog.check()
+	assert.Empty(t, terminated)
+	assert.Nil(t, publisher.Pop())
+
+	liveness.Tick(goroutineInvoiceScheduler)
+	clock.Advance(time.Second)
+	watchdog.check()
+	assert.Len(t, terminated, 1)
+	assert.True(t, errors.Is(terminated[0], ErrInvoiceTrackerStuck))
+
+	diagnostic := publisher.Pop().(event.AppEventInvoiceTrackerStuck)
+	assert.Equal(t, "session", diagnostic.SessionID)
+	assert.Equal(t, []string{goroutineInvoiceLoop}, diagnostic.Goroutines)
+	assert.Equal(t, map[string]time.Time{goroutineInvoiceLoop: started}, diagnostic.LastTicks)
+	assert.Equal(t, time.Minute, diagnostic.Deadline)
+	assert.Contains(t, diagnostic.Stack, "goroutine")
+
+	// stuck session is terminated only once
+	clock.Advance(time.Hour)
+	watchdog.check()
+	assert.Len(t, terminated, 1)
+}
+
+func TestInvoiceWatchdog_IgnoresUnwatchedTracker(t *testing.T) {
+	clock := newMockClock()
+
+	publisher := mocks.NewEventBus()
+	watchdog := NewInvoiceWatchdog(publisher, time.Second)
+	watchdog.clock = clock
+
+	liveness := watchdog.Watch("session", time.Minute, func(err error) { t.Fatal("unexpected termination") })
+	liveness.Tick(goroutineInvoiceLoop)
+	watchdog.Unwatch("session")
+
+	clock.Advance(time.Hour)
+	watchdog.check()
+	assert.Nil(t, publisher.Pop())
+}
+
+func TestInvoiceTracker_ReportsStuck(t *testing.T) {
+	tracker := NewInvoiceTracker(InvoiceTrackerDeps{})
+
+	tracker.terminateStuck(ErrInvoiceTrackerStuck)
+	tracker.terminateStuck(errors.New("ignored"))
+
+	assert.Equal(t, ErrInvoiceTrackerStuck, <-tracker.Stuck())
+	select {
+	case err := <-tracker.Stuck():
+		t.Fatalf("unexpected second report: %v", err)
+	default:
+	}
+}
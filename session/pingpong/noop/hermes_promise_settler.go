@@ -22,6 +22,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
 )
 
 // NoopHermesPromiseSettler doesn't do much.
@@ -38,6 +39,16 @@ func (n *NoopHermesPromiseSettler) SettleIntoStake(chainID int64, providerID ide
 	return nil
 }
 
+// PlanSettlementBatch returns an empty plan.
+func (n *NoopHermesPromiseSettler) PlanSettlementBatch(chainID int64, providerID identity.Identity, _ ...common.Address) (pingpong.SettlementBatchPlan, error) {
+	return pingpong.SettlementBatchPlan{ChainID: chainID, ProviderID: providerID}, nil
+}
+
+// SettleBatch does nothing.
+func (n *NoopHermesPromiseSettler) SettleBatch(chainID int64, _ identity.Identity, _ ...common.Address) error {
+	return nil
+}
+
 // SettleWithBeneficiary does nothing.
 func (n *NoopHermesPromiseSettler) SettleWithBeneficiary(chainID int64, _ identity.Identity, _, _ common.Address) error {
 	return nil
@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// localClockSkew reports the drift of the local clock from the remote servers, zero while it is in sync.
+type localClockSkew interface {
+	Skew() time.Duration
+}
+
+// PeerClockSkewConfig configures how far the time-derived amounts of the peers may drift apart.
+type PeerClockSkewConfig struct {
+	// Tolerance is the session time worth of the agreed price the invoiced and promised amounts may differ by.
+	Tolerance time.Duration
+	// MaxSkew caps the clock skew which widens the tolerance and the charge leeway, so a peer can not buy itself leniency.
+	MaxSkew time.Duration
+	// LocalClock estimates the skew until the peer clock is measured, it is optional.
+	LocalClock localClockSkew
+}
+
+// localSkew returns the drift of the local clock, capped by the maximum skew.
+func (c PeerClockSkewConfig) localSkew() time.Duration {
+	if c.LocalClock == nil {
+		return 0
+	}
+	return c.capSkew(c.LocalClock.Skew())
+}
+
+func (c PeerClockSkewConfig) capSkew(skew time.Duration) time.Duration {
+	if skew > c.MaxSkew {
+		return c.MaxSkew
+	}
+	return skew
+}
+
+// PeerClockSkew estimates the offset of the peer's clock from the timestamps exchanged with the invoices.
+// Provider measures it over the invoice round trip, consumer from the time the invoice was sent at.
+// Until the peer clock is measured, the drift of the local clock detected by the clock skew monitor is used instead.
+type PeerClockSkew struct {
+	config PeerClockSkewConfig
+
+	lock        sync.Mutex
+	offset      time.Duration
+	uncertainty time.Duration
+	measured    bool
+}
+
+// NewPeerClockSkew returns a new peer clock skew estimate.
+func NewPeerClockSkew(config PeerClockSkewConfig) *PeerClockSkew {
+	return &PeerClockSkew{config: config}
+}
+
+// ObserveRoundTrip records a sample of a message round trip.
+// Sent and replied are the local times, peerTime is when the peer received the message by its own clock.
+func (s *PeerClockSkew) ObserveRoundTrip(sent, peerTime, replied time.Time) {
+	halfTrip := replied.Sub(sent) / 2
+	if halfTrip < 0 {
+		return
+	}
+	s.observe(peerTime.Sub(sent.Add(halfTrip)), halfTrip)
+}
+
+// ObserveOneWay records a sample of a message the peer sent at peerTime and was received locally at received.
+// The transit time can not be told apart from the skew, so it is accounted as a part of the offset.
+func (s *PeerClockSkew) ObserveOneWay(peerTime, received time.Time) {
+	s.observe(peerTime.Sub(received), 0)
+}
+
+func (s *PeerClockSkew) observe(offset, uncertainty time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.offset = offset
+	s.uncertainty = uncertainty
+	s.measured = true
+}
+
+// Offset returns the last measured offset of the peer's clock from the local clock and whether it was measured at all.
+func (s *PeerClockSkew) Offset() (time.Duration, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.offset, s.measured
+}
+
+// Skew returns the measured skew widened by its uncertainty, or the local clock drift if the peer clock
+// was not measured yet, capped by the maximum skew.
+func (s *PeerClockSkew) Skew() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.measured {
+		return s.config.localSkew()
+	}
+	return s.config.capSkew(absDuration(s.offset) + s.uncertainty)
+}
+
+// Tolerance returns the configured tolerance widened by the skew.
+func (s *PeerClockSkew) Tolerance() time.Duration {
+	return s.config.Tolerance + s.Skew()
+}
+
+// toleratedAmount returns the amount worth of the tolerated time at the given price.
+func (s *PeerClockSkew) toleratedAmount(price market.Price) *big.Int {
+	if s == nil {
+		return new(big.Int)
+	}
+	return CalculatePaymentAmount(s.Tolerance(), DataTransferred{}, price)
+}
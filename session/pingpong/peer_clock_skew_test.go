@@ -0,0 +1,149 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+func TestPeerClockSkew_ObserveRoundTrip(t *testing.T) {
+	skew := NewPeerClockSkew(PeerClockSkewConfig{Tolerance: 5 * time.Second, MaxSkew: time.Minute})
+
+	_, measured := skew.Offset()
+	assert.False(t, measured)
+	assert.Equal(t, 5*time.Second, skew.Tolerance())
+
+	sent := time.Unix(1000, 0)
+	// Peer clock is 10s ahead, invoice took 1s to arrive and 1s for the reply to come back.
+	skew.ObserveRoundTrip(sent, sent.Add(11*time.Second), sent.Add(2*time.Second))
+
+	offset, measured := skew.Offset()
+	assert.True(t, measured)
+	assert.Equal(t, 10*time.Second, offset)
+	assert.Equal(t, 16*time.Second, skew.Tolerance())
+}
+
+func TestPeerClockSkew_ObserveOneWay(t *testing.T) {
+	skew := NewPeerClockSkew(PeerClockSkewConfig{Tolerance: time.Second, MaxSkew: 30 * time.Second})
+
+	received := time.Unix(1000, 0)
+	skew.ObserveOneWay(received.Add(-3*time.Second), received)
+	offset, _ := skew.Offset()
+	assert.Equal(t, -3*time.Second, offset)
+	assert.Equal(t, 4*time.Second, skew.Tolerance())
+
+	// Tolerated skew is capped.
+	skew.ObserveOneWay(received.Add(time.Hour), received)
+	assert.Equal(t, 31*time.Second, skew.Tolerance())
+}
+
+type mockLocalClockSkew time.Duration
+
+func (m mockLocalClockSkew) Skew() time.Duration {
+	return time.Duration(m)
+}
+
+func TestPeerClockSkew_FallsBackToLocalClock(t *testing.T) {
+	config := PeerClockSkewConfig{Tolerance: time.Second, MaxSkew: 30 * time.Second, LocalClock: mockLocalClockSkew(20 * time.Second)}
+	skew := NewPeerClockSkew(config)
+	assert.Equal(t, 20*time.Second, skew.Skew())
+	assert.Equal(t, 21*time.Second, skew.Tolerance())
+
+	// Measured peer clock replaces the local drift.
+	received := time.Unix(1000, 0)
+	skew.ObserveOneWay(received.Add(-3*time.Second), received)
+	assert.Equal(t, 4*time.Second, skew.Tolerance())
+
+	// Local drift is capped too.
+	config.LocalClock = mockLocalClockSkew(time.Hour)
+	assert.Equal(t, 30*time.Second, config.localSkew())
+}
+
+func TestInvoiceTracker_validatePromisedTotal(t *testing.T) {
+	// 36 per second.
+	price := *market.NewPrice(3600*36, 0)
+	invoice := crypto.Invoice{AgreementTotal: big.NewInt(1000)}
+	newTracker := func(config PeerClockSkewConfig) *InvoiceTracker {
+		return &InvoiceTracker{
+			deps:      InvoiceTrackerDeps{AgreedPrice: price, PeerClockSkew: config},
+			pricing:   newSessionPricing(price),
+			peerClock: NewPeerClockSkew(config),
+		}
+	}
+
+	it := newTracker(PeerClockSkewConfig{Tolerance: 2 * time.Second, MaxSkew: time.Minute})
+	assert.NoError(t, it.validatePromisedTotal(crypto.ExchangeMessage{AgreementTotal: big.NewInt(1000)}, invoice))
+	assert.NoError(t, it.validatePromisedTotal(crypto.ExchangeMessage{AgreementTotal: big.NewInt(1000 - 70)}, invoice))
+	err := it.validatePromisedTotal(crypto.ExchangeMessage{AgreementTotal: big.NewInt(1000 - 80)}, invoice)
+	assert.True(t, errors.Is(err, ErrConsumerPromiseValidationFailed))
+
+	// Consumer clock measured 3s behind widens the tolerance.
+	sent := time.Unix(1000, 0)
+	it.observePeerClock(InvoiceReceipt{SentAt: sent, ReceivedAt: sent.Add(-3 * time.Second), RepliedAt: sent})
+	assert.NoError(t, it.validatePromisedTotal(crypto.ExchangeMessage{AgreementTotal: big.NewInt(1000 - 170)}, invoice))
+	err = it.validatePromisedTotal(crypto.ExchangeMessage{AgreementTotal: big.NewInt(1000 - 190)}, invoice)
+	assert.True(t, errors.Is(err, ErrConsumerPromiseValidationFailed))
+
+	// Consumers which do not report receipts are held to the configured tolerance.
+	it = newTracker(PeerClockSkewConfig{Tolerance: 2 * time.Second, MaxSkew: time.Minute})
+	it.observePeerClock(InvoiceReceipt{SentAt: sent, RepliedAt: sent})
+	_, measured := it.peerClock.Offset()
+	assert.False(t, measured)
+}
+
+func TestInvoicePayer_isInvoiceOK_ToleratesPeerClockSkew(t *testing.T) {
+	price := *market.NewPrice(3600*36, 0)
+	provider := identity.FromAddress("0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C")
+	newPayer := func(skew *PeerClockSkew) *InvoicePayer {
+		return &InvoicePayer{
+			deps: InvoicePayerDeps{
+				TimeTracker:   &mockTimeTracker{timeToReturn: time.Minute},
+				AgreedPrice:   price,
+				Peer:          provider,
+				PeerClockSkew: skew,
+			},
+			pricing: newSessionPricing(price),
+		}
+	}
+	tolerance := estimateInvoiceTolerance(time.Minute, DataTransferred{})
+	upperBound, _ := new(big.Float).Mul(big.NewFloat(36*60), big.NewFloat(tolerance)).Int(nil)
+	invoice := crypto.Invoice{
+		AgreementID:    big.NewInt(1),
+		AgreementTotal: new(big.Int).Add(upperBound, big.NewInt(36*8)),
+		TransactorFee:  big.NewInt(0),
+		Provider:       provider.Address,
+	}
+
+	assert.Equal(t, ErrProviderOvercharge, newPayer(nil).isInvoiceOK(invoice))
+
+	skew := NewPeerClockSkew(PeerClockSkewConfig{Tolerance: 5 * time.Second, MaxSkew: time.Minute})
+	assert.Equal(t, ErrProviderOvercharge, newPayer(skew).isInvoiceOK(invoice))
+
+	received := time.Unix(1000, 0)
+	skew.ObserveOneWay(received.Add(5*time.Second), received)
+	assert.NoError(t, newPayer(skew).isInvoiceOK(invoice))
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpongtest
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/client"
+)
+
+// Blockchain is an in-memory fake of the blockchain queries used by pingpong.
+// Hermeses are active and registered, identities are unregistered and channels are empty unless set otherwise.
+type Blockchain struct {
+	// Err is returned by every call when set.
+	Err error
+
+	lock             sync.Mutex
+	hermesFee        uint16
+	hermesURLs       map[common.Address]string
+	inactiveHermeses map[common.Address]bool
+	registered       map[common.Address]bool
+	providerChannels map[common.Address]client.ProviderChannel
+	consumerChannels map[common.Address]client.ConsumerChannel
+}
+
+// NewBlockchain returns a new fake blockchain.
+func NewBlockchain() *Blockchain {
+	return &Blockchain{
+		hermesURLs:       make(map[common.Address]string),
+		inactiveHermeses: make(map[common.Address]bool),
+		registered:       make(map[common.Address]bool),
+		providerChannels: make(map[common.Address]client.ProviderChannel),
+		consumerChannels: make(map[common.Address]client.ConsumerChannel),
+	}
+}
+
+// SetHermesFee sets the fee returned for every hermes.
+func (b *Blockchain) SetHermesFee(fee uint16) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.hermesFee = fee
+}
+
+// SetHermesURL sets the URL returned for the given hermes.
+func (b *Blockchain) SetHermesURL(hermesID common.Address, url string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.hermesURLs[hermesID] = url
+}
+
+// SetHermesActive marks the given hermes as active or inactive.
+func (b *Blockchain) SetHermesActive(hermesID common.Address, active bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.inactiveHermeses[hermesID] = !active
+}
+
+// SetRegistered marks the given address as registered.
+func (b *Blockchain) SetRegistered(addr common.Address) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.registered[addr] = true
+}
+
+// SetProviderChannel sets the channel returned for the given provider.
+func (b *Blockchain) SetProviderChannel(addr common.Address, channel client.ProviderChannel) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.providerChannels[addr] = channel
+}
+
+// SetConsumerChannel sets the channel returned for the given consumer.
+func (b *Blockchain) SetConsumerChannel(addr common.Address, channel client.ConsumerChannel) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.consumerChannels[addr] = channel
+}
+
+// GetHermesFee returns the configured hermes fee.
+func (b *Blockchain) GetHermesFee(chainID int64, hermesAddress common.Address) (uint16, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.hermesFee, b.Err
+}
+
+// CalculateHermesFee calculates the configured hermes fee for the given value.
+func (b *Blockchain) CalculateHermesFee(chainID int64, hermesAddress common.Address, value *big.Int) (*big.Int, error) {
+	fee, err := b.GetHermesFee(chainID, hermesAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(big.Int).Mul(value, big.NewInt(int64(fee)))
+	return result.Div(result, big.NewInt(10000)), nil
+}
+
+// GetHermesURL returns the URL set for the given hermes.
+func (b *Blockchain) GetHermesURL(chainID int64, address common.Address) (string, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.hermesURLs[address], b.Err
+}
+
+// IsHermesActive reports whether the given hermes was not marked inactive.
+func (b *Blockchain) IsHermesActive(chainID int64, hermesID common.Address) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return !b.inactiveHermeses[hermesID], b.Err
+}
+
+// IsHermesRegistered always reports hermes as registered.
+func (b *Blockchain) IsHermesRegistered(chainID int64, registryAddress, hermesID common.Address) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return true, b.Err
+}
+
+// IsRegistered reports whether the given address was marked as registered.
+func (b *Blockchain) IsRegistered(chainID int64, registryAddress, addressToCheck common.Address) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.registered[addressToCheck], b.Err
+}
+
+// GetProviderChannel returns the channel set for the given provider.
+func (b *Blockchain) GetProviderChannel(chainID int64, hermesAddress common.Address, addressToCheck common.Address, pending bool) (client.ProviderChannel, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.providerChannels[addressToCheck], b.Err
+}
+
+// GetConsumerChannel returns the channel set for the given consumer.
+func (b *Blockchain) GetConsumerChannel(chainID int64, addr common.Address, mystSCAddress common.Address) (client.ConsumerChannel, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.consumerChannels[addr], b.Err
+}
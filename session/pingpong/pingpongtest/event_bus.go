@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpongtest
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+)
+
+// Event is a single event published through the EventBus.
+type Event struct {
+	Topic string
+	Event interface{}
+}
+
+// EventBus is a real event bus which additionally records every published event.
+// Unlike mocks.EventBus it delivers events to subscribers, so that components reacting to each other can be wired together.
+type EventBus struct {
+	eventbus.EventBus
+
+	lock      sync.Mutex
+	published []Event
+}
+
+// NewEventBus returns a new recording event bus.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		EventBus: eventbus.New(),
+	}
+}
+
+// Publish records the event and delivers it to subscribers.
+func (eb *EventBus) Publish(topic string, data interface{}) {
+	eb.lock.Lock()
+	eb.published = append(eb.published, Event{Topic: topic, Event: data})
+	eb.lock.Unlock()
+
+	eb.EventBus.Publish(topic, data)
+}
+
+// Published returns the events published on the given topic so far.
+func (eb *EventBus) Published(topic string) []interface{} {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+
+	var result []interface{}
+	for _, e := range eb.published {
+		if e.Topic == topic {
+			result = append(result, e.Event)
+		}
+	}
+	return result
+}
+
+// History returns all the events published so far.
+func (eb *EventBus) History() []Event {
+	eb.lock.Lock()
+	defer eb.lock.Unlock()
+	return append([]Event(nil), eb.published...)
+}
@@ -0,0 +1,198 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package pingpongtest provides in-memory fakes of the pingpong payment dependencies,
+// so that payment flows can be exercised in tests without hermes, blockchain or bolt.
+package pingpongtest
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/payments/crypto"
+)
+
+var _ pingpong.HermesHTTPRequester = &HermesCaller{}
+
+// HermesCaller is an in-memory fake of the hermes API.
+// Requested promises are issued for the agreement total and recorded for inspection.
+type HermesCaller struct {
+	// Err is returned by every call when set.
+	Err error
+
+	lock      sync.Mutex
+	consumers map[string]pingpong.HermesUserInfo
+	providers map[string]pingpong.HermesUserInfo
+	requested []pingpong.RequestPromise
+	revealed  map[string]string
+	synced    []crypto.Promise
+}
+
+// NewHermesCaller returns a new fake hermes caller without any known identities.
+func NewHermesCaller() *HermesCaller {
+	return &HermesCaller{
+		consumers: make(map[string]pingpong.HermesUserInfo),
+		providers: make(map[string]pingpong.HermesUserInfo),
+		revealed:  make(map[string]string),
+	}
+}
+
+// Factory returns hermes caller factory which always returns this fake.
+func (hc *HermesCaller) Factory() pingpong.HermesCallerFactory {
+	return func(url string) pingpong.HermesHTTPRequester {
+		return hc
+	}
+}
+
+// SetConsumerData sets the data returned for the given consumer.
+func (hc *HermesCaller) SetConsumerData(id string, data pingpong.HermesUserInfo) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	hc.consumers[id] = data
+}
+
+// SetProviderData sets the data returned for the given provider.
+func (hc *HermesCaller) SetProviderData(id string, data pingpong.HermesUserInfo) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	hc.providers[id] = data
+}
+
+// RequestPromise issues a promise for the agreement total of the given exchange message.
+func (hc *HermesCaller) RequestPromise(rp pingpong.RequestPromise) (crypto.Promise, error) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	if hc.Err != nil {
+		return crypto.Promise{}, hc.Err
+	}
+
+	hc.requested = append(hc.requested, rp)
+	em := rp.ExchangeMessage
+	return crypto.Promise{
+		ChannelID: em.Promise.ChannelID,
+		ChainID:   em.ChainID,
+		Amount:    new(big.Int).Set(em.AgreementTotal),
+		Fee:       rp.TransactorFee,
+		Hashlock:  em.Promise.Hashlock,
+	}, nil
+}
+
+// PayAndSettle issues a promise the same way as RequestPromise does.
+func (hc *HermesCaller) PayAndSettle(rp pingpong.RequestPromise) (crypto.Promise, error) {
+	return hc.RequestPromise(rp)
+}
+
+// RevealR records the revealed R for the given agreement.
+func (hc *HermesCaller) RevealR(r string, provider string, agreementID *big.Int) error {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	if hc.Err != nil {
+		return hc.Err
+	}
+
+	hc.revealed[provider+agreementID.String()] = r
+	return nil
+}
+
+// UpdatePromiseFee returns the given promise with the new fee.
+func (hc *HermesCaller) UpdatePromiseFee(promise crypto.Promise, newFee *big.Int) (crypto.Promise, error) {
+	if hc.Err != nil {
+		return crypto.Promise{}, hc.Err
+	}
+
+	promise.Fee = newFee
+	return promise, nil
+}
+
+// GetConsumerData returns the data set for the given consumer.
+func (hc *HermesCaller) GetConsumerData(chainID int64, id string) (pingpong.HermesUserInfo, error) {
+	return hc.userData(hc.consumers, id)
+}
+
+// GetProviderData returns the data set for the given provider.
+func (hc *HermesCaller) GetProviderData(chainID int64, id string) (pingpong.HermesUserInfo, error) {
+	return hc.userData(hc.providers, id)
+}
+
+// SyncProviderPromise records the synced promise.
+func (hc *HermesCaller) SyncProviderPromise(promise crypto.Promise, signer identity.Signer) error {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	if hc.Err != nil {
+		return hc.Err
+	}
+
+	hc.synced = append(hc.synced, promise)
+	return nil
+}
+
+// RefreshLatestProviderPromise returns the latest promise set for the given provider.
+func (hc *HermesCaller) RefreshLatestProviderPromise(chainID int64, id string, hashlock, recoveryData []byte, signer identity.Signer) (crypto.Promise, error) {
+	data, err := hc.userData(hc.providers, id)
+	if err != nil {
+		return crypto.Promise{}, err
+	}
+
+	return crypto.Promise{
+		ChainID:  chainID,
+		Amount:   data.LatestPromise.Amount,
+		Fee:      data.LatestPromise.Fee,
+		Hashlock: hashlock,
+	}, nil
+}
+
+// Requested returns the promise requests received so far.
+func (hc *HermesCaller) Requested() []pingpong.RequestPromise {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	return append([]pingpong.RequestPromise(nil), hc.requested...)
+}
+
+// Revealed returns R revealed by the provider for the given agreement.
+func (hc *HermesCaller) Revealed(provider string, agreementID *big.Int) (string, bool) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	r, ok := hc.revealed[provider+agreementID.String()]
+	return r, ok
+}
+
+// Synced returns the promises synced so far.
+func (hc *HermesCaller) Synced() []crypto.Promise {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+	return append([]crypto.Promise(nil), hc.synced...)
+}
+
+func (hc *HermesCaller) userData(users map[string]pingpong.HermesUserInfo, id string) (pingpong.HermesUserInfo, error) {
+	hc.lock.Lock()
+	defer hc.lock.Unlock()
+
+	if hc.Err != nil {
+		return pingpong.HermesUserInfo{}, hc.Err
+	}
+
+	data, ok := users[id]
+	if !ok {
+		return pingpong.HermesUserInfo{}, pingpong.ErrHermesNotFound
+	}
+	return data, nil
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpongtest
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHermesCaller_IssuesPromiseForAgreementTotal(t *testing.T) {
+	hc := NewHermesCaller()
+	caller := hc.Factory()("http://hermes")
+
+	promise, err := caller.RequestPromise(pingpong.RequestPromise{
+		ExchangeMessage: crypto.ExchangeMessage{
+			Promise:        crypto.Promise{Hashlock: []byte{1}},
+			AgreementTotal: big.NewInt(100),
+			ChainID:        1,
+		},
+		TransactorFee: big.NewInt(5),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), promise.Amount)
+	assert.Equal(t, big.NewInt(5), promise.Fee)
+	assert.Equal(t, []byte{1}, promise.Hashlock)
+	assert.Len(t, hc.Requested(), 1)
+
+	_, err = caller.GetConsumerData(1, "0x1")
+	assert.ErrorIs(t, err, pingpong.ErrHermesNotFound)
+}
+
+func TestInvoiceStorage_ReportsNotFound(t *testing.T) {
+	is := NewInvoiceStorage()
+	provider := identity.FromAddress("0x1")
+
+	_, err := is.GetR(provider, big.NewInt(1))
+	assert.ErrorIs(t, err, pingpong.ErrNotFound)
+
+	assert.NoError(t, is.StoreR(provider, big.NewInt(1), "r"))
+	r, err := is.GetR(provider, big.NewInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "r", r)
+}
+
+func TestPromiseStorage_RejectsLowerPromise(t *testing.T) {
+	ps := NewPromiseStorage()
+	hermesID := common.HexToAddress("0x2")
+	promise := pingpong.HermesPromise{
+		ChannelID: "ch",
+		HermesID:  hermesID,
+		Promise:   crypto.Promise{ChainID: 1, Amount: big.NewInt(10)},
+	}
+	assert.NoError(t, ps.Store(promise))
+
+	lower := promise
+	lower.Promise.Amount = big.NewInt(5)
+	assert.ErrorIs(t, ps.Store(lower), pingpong.ErrAttemptToOverwrite)
+
+	revealed := promise
+	revealed.Revealed = true
+	assert.NoError(t, ps.Store(revealed))
+
+	list, err := ps.List(pingpong.HermesPromiseFilter{ChainID: 1, HermesID: &hermesID})
+	assert.NoError(t, err)
+	assert.Equal(t, []pingpong.HermesPromise{revealed}, list)
+}
+
+func TestEventBus_DeliversAndRecords(t *testing.T) {
+	bus := NewEventBus()
+	received := make(chan interface{}, 1)
+	assert.NoError(t, bus.Subscribe("topic", func(e string) { received <- e }))
+
+	bus.Publish("topic", "event")
+
+	select {
+	case e := <-received:
+		assert.Equal(t, "event", e)
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+	assert.Equal(t, []interface{}{"event"}, bus.Published("topic"))
+}
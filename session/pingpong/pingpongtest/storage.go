@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpongtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// ErrNotFound mimics the error returned by bolt for missing keys, which pingpong translates into pingpong.ErrNotFound.
+var ErrNotFound = errors.New("not found")
+
+// MemoryStorage is an in-memory key-value storage which can back pingpong storages instead of bolt.
+// Values are stored JSON encoded, the same way bolt does, so that stored values are copies.
+type MemoryStorage struct {
+	lock    sync.Mutex
+	buckets map[string]map[string][]byte
+}
+
+// NewMemoryStorage returns a new empty in-memory storage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		buckets: make(map[string]map[string][]byte),
+	}
+}
+
+// NewInvoiceStorage returns invoice storage backed by a new in-memory storage.
+func NewInvoiceStorage() *pingpong.InvoiceStorage {
+	return pingpong.NewInvoiceStorage(NewMemoryStorage())
+}
+
+// GetValue decodes the value stored under the given key into to.
+func (ms *MemoryStorage) GetValue(bucket string, key interface{}, to interface{}) error {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	value, ok := ms.buckets[bucket][fmt.Sprint(key)]
+	if !ok {
+		return ErrNotFound
+	}
+	return json.Unmarshal(value, to)
+}
+
+// SetValue stores the given value under the given key.
+func (ms *MemoryStorage) SetValue(bucket string, key interface{}, to interface{}) error {
+	value, err := json.Marshal(to)
+	if err != nil {
+		return err
+	}
+
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	if _, ok := ms.buckets[bucket]; !ok {
+		ms.buckets[bucket] = make(map[string][]byte)
+	}
+	ms.buckets[bucket][fmt.Sprint(key)] = value
+	return nil
+}
+
+// DeleteKey removes the given key.
+func (ms *MemoryStorage) DeleteKey(bucket string, key interface{}) error {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+
+	delete(ms.buckets[bucket], fmt.Sprint(key))
+	return nil
+}
+
+// PromiseStorage is an in-memory fake of pingpong.HermesPromiseStorage.
+type PromiseStorage struct {
+	lock     sync.Mutex
+	promises map[int64]map[string]pingpong.HermesPromise
+}
+
+// NewPromiseStorage returns a new empty in-memory promise storage.
+func NewPromiseStorage() *PromiseStorage {
+	return &PromiseStorage{
+		promises: make(map[int64]map[string]pingpong.HermesPromise),
+	}
+}
+
+// Store stores the given promise, unless a promise of higher or equal value is already stored for its channel.
+func (ps *PromiseStorage) Store(promise pingpong.HermesPromise) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	chainID := promise.Promise.ChainID
+	if old, ok := ps.promises[chainID][promise.ChannelID]; ok && promise.Promise.Amount != nil {
+		cmp := old.Promise.Amount.Cmp(promise.Promise.Amount)
+		if cmp > 0 || cmp == 0 && (old.Revealed || !promise.Revealed) {
+			return pingpong.ErrAttemptToOverwrite
+		}
+	}
+
+	if _, ok := ps.promises[chainID]; !ok {
+		ps.promises[chainID] = make(map[string]pingpong.HermesPromise)
+	}
+	ps.promises[chainID][promise.ChannelID] = promise
+	return nil
+}
+
+// Get returns the promise stored for the given channel.
+func (ps *PromiseStorage) Get(chainID int64, channelID string) (pingpong.HermesPromise, error) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	promise, ok := ps.promises[chainID][channelID]
+	if !ok {
+		return pingpong.HermesPromise{}, pingpong.ErrNotFound
+	}
+	return promise, nil
+}
+
+// List returns the promises matching the given filter.
+func (ps *PromiseStorage) List(filter pingpong.HermesPromiseFilter) ([]pingpong.HermesPromise, error) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	result := make([]pingpong.HermesPromise, 0)
+	for _, promise := range ps.promises[filter.ChainID] {
+		if filter.Identity != nil && *filter.Identity != promise.Identity {
+			continue
+		}
+		if filter.HermesID != nil && *filter.HermesID != promise.HermesID {
+			continue
+		}
+		result = append(result, promise)
+	}
+	return result, nil
+}
+
+// Delete removes the given promise.
+func (ps *PromiseStorage) Delete(promise pingpong.HermesPromise) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	delete(ps.promises[promise.Promise.ChainID], promise.ChannelID)
+	return nil
+}
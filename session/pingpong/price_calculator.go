@@ -19,6 +19,7 @@ package pingpong
 
 import (
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -53,3 +54,63 @@ func CalculatePaymentAmount(timePassed time.Duration, bytesTransferred DataTrans
 		total, timeComponent, dataComponent, bytesTransferred.sum(), timePassed.Seconds(), price.String())
 	return total
 }
+
+// sessionPricing calculates the session payment amount at a price which may change during the session,
+// the amount billed before the price change is kept and only the rest of the session is billed at the new price.
+type sessionPricing struct {
+	lock  sync.Mutex
+	price market.Price
+
+	// The time, data and amount billed at the previous prices.
+	sinceElapsed time.Duration
+	sinceData    DataTransferred
+	base         *big.Int
+}
+
+func newSessionPricing(price market.Price) *sessionPricing {
+	return &sessionPricing{
+		price: price,
+		base:  new(big.Int),
+	}
+}
+
+// amount returns the payment amount for the whole session.
+func (sp *sessionPricing) amount(elapsed time.Duration, data DataTransferred) *big.Int {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	return sp.amountLocked(elapsed, data)
+}
+
+func (sp *sessionPricing) amountLocked(elapsed time.Duration, data DataTransferred) *big.Int {
+	if sp.sinceElapsed == 0 && sp.sinceData == (DataTransferred{}) {
+		return CalculatePaymentAmount(elapsed, data, sp.price)
+	}
+
+	since := DataTransferred{
+		Up:   safeSubUint64(data.Up, sp.sinceData.Up),
+		Down: safeSubUint64(data.Down, sp.sinceData.Down),
+	}
+	if elapsed < sp.sinceElapsed {
+		elapsed = sp.sinceElapsed
+	}
+	return new(big.Int).Add(sp.base, CalculatePaymentAmount(elapsed-sp.sinceElapsed, since, sp.price))
+}
+
+// reprice bills the rest of the session, starting at the given elapsed time and data, at the given price.
+func (sp *sessionPricing) reprice(price market.Price, elapsed time.Duration, data DataTransferred) {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	sp.base = sp.amountLocked(elapsed, data)
+	sp.sinceElapsed, sp.sinceData = elapsed, data
+	sp.price = price
+}
+
+// current returns the price the session is billed at.
+func (sp *sessionPricing) current() market.Price {
+	sp.lock.Lock()
+	defer sp.lock.Unlock()
+
+	return sp.price
+}
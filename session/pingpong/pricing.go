@@ -96,6 +96,11 @@ func (p *Pricer) GetCurrentPrice(nodeType string, country string, serviceType st
 	return *p.getCurrentByType(pricing, nodeType, country, serviceType), nil
 }
 
+// LatestPrices returns the prices currently charged in the network.
+func (p *Pricer) LatestPrices() market.LatestPrices {
+	return p.getPricing()
+}
+
 func (p *Pricer) getPriceForCountry(pricing market.LatestPrices, country string) *market.PriceHistory {
 	v, ok := pricing.PerCountry[strings.ToUpper(country)]
 	if ok {
@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+// SettlementBatchItem is a promise of a single hermes considered for the batch settlement.
+type SettlementBatchItem struct {
+	HermesID common.Address
+	// Amount is the unsettled amount of the promise.
+	Amount *big.Int
+	// HermesFee is the fee hermes takes for settling the amount.
+	HermesFee *big.Int
+	// Reason explains why the promise is left out of the batch, empty for included promises.
+	Reason string
+
+	promise     crypto.Promise
+	beneficiary common.Address
+}
+
+// SettlementBatchPlan describes which promises are worth settling in a single transaction and the expected savings.
+type SettlementBatchPlan struct {
+	ChainID    int64
+	ProviderID identity.Identity
+	Items      []SettlementBatchItem
+	Skipped    []SettlementBatchItem
+	// Supported tells whether the transactor settles multiple promises in a single transaction and the node has it enabled.
+	// If not, the promises are settled separately and there are no savings.
+	Supported bool
+	// TransactorFee is the fee paid for a single settlement transaction.
+	TransactorFee *big.Int
+	// BatchTransactorFee is the fee paid for the batch settlement transaction.
+	BatchTransactorFee *big.Int
+	// SeparateFees are the total fees of settling every included promise in its own transaction.
+	SeparateFees *big.Int
+	// BatchFees are the total fees of settling the included promises in a single transaction.
+	BatchFees *big.Int
+	// Savings are the fees saved by settling in a single transaction.
+	Savings *big.Int
+	// Amount is the total unsettled amount of the included promises.
+	Amount *big.Int
+}
+
+func (p SettlementBatchPlan) hermesIDs() []common.Address {
+	ids := make([]common.Address, len(p.Items))
+	for i, item := range p.Items {
+		ids[i] = item.HermesID
+	}
+	return ids
+}
+
+// PlanSettlementBatch weighs the fees against the unsettled amounts of the given hermeses.
+// A promise is included if its amount covers the hermes fee, the batch transactor fee is paid once for the whole batch.
+func (aps *hermesPromiseSettler) PlanSettlementBatch(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) (SettlementBatchPlan, error) {
+	fees, err := aps.transactor.FetchSettleFees(chainID)
+	if err != nil {
+		return SettlementBatchPlan{}, fmt.Errorf("could not fetch settle fees: %w", err)
+	}
+
+	plan := SettlementBatchPlan{
+		ChainID:       chainID,
+		ProviderID:    providerID,
+		Supported:     true,
+		TransactorFee: fees.Fee,
+		SeparateFees:  new(big.Int),
+		BatchFees:     new(big.Int),
+		Savings:       new(big.Int),
+		Amount:        new(big.Int),
+	}
+
+	batchFees, err := aps.fetchBatchSettleFees(chainID)
+	switch {
+	case errors.Is(err, registry.ErrBatchSettlementUnsupported):
+		plan.Supported = false
+		plan.BatchTransactorFee = new(big.Int)
+	case err != nil:
+		return SettlementBatchPlan{}, fmt.Errorf("could not fetch batch settle fees: %w", err)
+	default:
+		plan.BatchTransactorFee = batchFees.Fee
+	}
+
+	hermesFees := new(big.Int)
+	for _, hermesID := range hermesIDs {
+		channel, err := aps.channelProvider.Fetch(chainID, providerID, hermesID)
+		if err != nil {
+			return SettlementBatchPlan{}, fmt.Errorf("could not fetch channel with hermes %q: %w", hermesID.Hex(), err)
+		}
+
+		item := SettlementBatchItem{
+			HermesID:    hermesID,
+			Amount:      channel.UnsettledBalance(),
+			HermesFee:   new(big.Int),
+			promise:     channel.lastPromise.Promise,
+			beneficiary: channel.Beneficiary,
+		}
+		if item.Amount.Sign() <= 0 {
+			item.Reason = "nothing to settle"
+			plan.Skipped = append(plan.Skipped, item)
+			continue
+		}
+
+		r, err := hex.DecodeString(channel.lastPromise.R)
+		if err != nil {
+			return SettlementBatchPlan{}, fmt.Errorf("could not decode R: %w", err)
+		}
+		item.promise.R = r
+
+		item.HermesFee, err = aps.bc.CalculateHermesFee(chainID, hermesID, item.Amount)
+		if err != nil {
+			return SettlementBatchPlan{}, fmt.Errorf("could not calculate hermes fee: %w", err)
+		}
+		if item.HermesFee.Cmp(item.Amount) >= 0 {
+			item.Reason = "hermes fee exceeds the unsettled amount"
+			plan.Skipped = append(plan.Skipped, item)
+			continue
+		}
+
+		plan.Items = append(plan.Items, item)
+		plan.Amount.Add(plan.Amount, item.Amount)
+		hermesFees.Add(hermesFees, item.HermesFee)
+	}
+
+	if len(plan.Items) == 0 {
+		return plan, nil
+	}
+
+	separateTransactorFees := new(big.Int).Mul(fees.Fee, big.NewInt(int64(len(plan.Items))))
+	plan.SeparateFees.Add(hermesFees, separateTransactorFees)
+	if !plan.Supported {
+		plan.BatchFees.Set(plan.SeparateFees)
+		return plan, nil
+	}
+	plan.BatchFees.Add(hermesFees, plan.BatchTransactorFee)
+	plan.Savings.Sub(plan.SeparateFees, plan.BatchFees)
+
+	return plan, nil
+}
+
+// fetchBatchSettleFees treats the batch settlement disabled on this node as unsupported by the transactor.
+func (aps *hermesPromiseSettler) fetchBatchSettleFees(chainID int64) (registry.FeesResponse, error) {
+	if aps.config.BatchSettlementEnabled != nil && !aps.config.BatchSettlementEnabled() {
+		return registry.FeesResponse{}, registry.ErrBatchSettlementUnsupported
+	}
+	return aps.transactor.FetchBatchSettleFees(chainID)
+}
+
+// SettleBatch settles the promises of the given hermeses in a single transaction.
+// It settles each promise separately if the transactor does not support the batch settlement,
+// which is checked before any promise is re-issued for the batch.
+func (aps *hermesPromiseSettler) SettleBatch(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) error {
+	plan, err := aps.PlanSettlementBatch(chainID, providerID, hermesIDs...)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.Items) == 0 {
+		return ErrNothingToSettle
+	}
+
+	if plan.Amount.Cmp(plan.BatchFees) <= 0 {
+		return fmt.Errorf("settlement fees exceed earning amount. Current earnings: %v, current fees: %v: %w", plan.Amount, plan.BatchFees, errFeeNotCovered)
+	}
+
+	if !plan.Supported {
+		log.Warn().Int64("chain_id", chainID).Msg("Batch settlement is not supported, settling promises separately")
+		return aps.ForceSettle(chainID, providerID, plan.hermesIDs()...)
+	}
+
+	if len(plan.Items) == 1 {
+		return aps.ForceSettle(chainID, providerID, plan.hermesIDs()...)
+	}
+
+	return aps.settleBatch(plan)
+}
+
+func (aps *hermesPromiseSettler) settleBatch(plan SettlementBatchPlan) error {
+	var started []common.Address
+	defer func() {
+		for _, hermesID := range started {
+			aps.stopSettling(plan.ProviderID, hermesID)
+		}
+	}()
+	for _, item := range plan.Items {
+		if !aps.startSettling(plan.ProviderID, item.HermesID) {
+			return ErrSettlementInProgress
+		}
+		started = append(started, item.HermesID)
+	}
+
+	settlements := make([]registry.BatchSettlement, len(plan.Items))
+	for i, item := range plan.Items {
+		// The batch is a single transaction, so only the first promise pays the transactor fee.
+		fee := new(big.Int)
+		if i == 0 {
+			fee = plan.BatchTransactorFee
+		}
+
+		promise, err := aps.reissuePromise(item.HermesID, item.promise, fee)
+		if err != nil {
+			return err
+		}
+
+		plan.Items[i].promise = promise
+		settlements[i] = registry.BatchSettlement{HermesID: item.HermesID.Hex(), Promise: promise}
+	}
+
+	log.Info().Fields(map[string]interface{}{
+		"provider": plan.ProviderID.Address,
+		"promises": len(settlements),
+		"savings":  plan.Savings.String(),
+	}).Msg("Settling promises in a single transaction")
+
+	queueID, err := aps.transactor.SettleAndRebalanceBatch(plan.ProviderID.Address, settlements)
+	if err != nil {
+		return err
+	}
+
+	errChs := make([]<-chan error, len(plan.Items))
+	for i, item := range plan.Items {
+		channelID, err := crypto.GenerateProviderChannelID(plan.ProviderID.Address, item.HermesID.Hex())
+		if err != nil {
+			return fmt.Errorf("could not generate provider channel address: %w", err)
+		}
+		errChs[i] = aps.listenForSettlement(item.HermesID, item.beneficiary, item.promise, plan.ProviderID, aps.toBytes32(channelID), queueID, false)
+	}
+
+	var errs []error
+	for _, errCh := range errChs {
+		if err := <-errCh; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("batch settlement failed for %d of %d promises: %w", len(errs), len(errChs), errs[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/bindings"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+var (
+	batchProvider = identity.FromAddress("0x92fE1c838b08dB4c072DDa805FB4292d9b76B5E7")
+	batchHermeses = []common.Address{
+		common.HexToAddress("0x07b5fD382b5e375F202184052BeF2C50b3B1404F"),
+		common.HexToAddress("0x7119442C7E627438deb0ec59291e31378F88DD06"),
+	}
+	batchR = "d56e23228dc2c7d2cc2e0ee08d7d6e5be6aa196c9f95046d83fab06913d2a9c2"
+)
+
+func newBatchSettler(t *testing.T, transactor *mockTransactor, hermesFee int64, settled, promised int64) *hermesPromiseSettler {
+	r, err := hex.DecodeString(batchR)
+	require.NoError(t, err)
+
+	var events []bindings.HermesImplementationPromiseSettled
+	for _, hermesID := range batchHermeses {
+		channelID, err := crypto.GenerateProviderChannelID(batchProvider.Address, hermesID.Hex())
+		require.NoError(t, err)
+
+		ev := bindings.HermesImplementationPromiseSettled{}
+		copy(ev.ChannelId[:], common.FromHex(channelID))
+		copy(ev.Lock[:], r)
+		events = append(events, ev)
+	}
+
+	fac := &mockHermesCallerFactory{}
+	return &hermesPromiseSettler{
+		currentState:        make(map[identity.Identity]settlementState),
+		transactor:          transactor,
+		hermesCallerFactory: fac.Get,
+		hermesURLGetter:     &mockHermesURLGetter{},
+		bc: &mockProviderChannelStatusProvider{
+			calculatedFees:        big.NewInt(hermesFee),
+			promiseEventsToReturn: events,
+			headerToReturn:        &types.Header{Number: big.NewInt(0)},
+		},
+		channelProvider: &mockHermesChannelProvider{
+			channelToReturn: HermesChannel{
+				Channel: client.ProviderChannel{Settled: big.NewInt(settled)},
+				lastPromise: HermesPromise{
+					Promise: crypto.Promise{Amount: big.NewInt(promised), Fee: big.NewInt(100), ChainID: 1},
+					R:       batchR,
+				},
+			},
+		},
+		config: HermesPromiseSettlerConfig{
+			SettlementCheckTimeout:  time.Second,
+			SettlementCheckInterval: time.Millisecond,
+		},
+		settlementHistoryStorage: &settlementHistoryStorageMock{},
+		publisher:                &mockPublisher{publicationChan: make(chan testEvent, 10)},
+	}
+}
+
+func TestPromiseSettler_PlanSettlementBatch(t *testing.T) {
+	transactor := &mockTransactor{
+		feesToReturn:      registry.FeesResponse{Fee: big.NewInt(100)},
+		batchFeesToReturn: registry.FeesResponse{Fee: big.NewInt(120)},
+	}
+	settler := newBatchSettler(t, transactor, 10, 100, 600)
+
+	plan, err := settler.PlanSettlementBatch(1, batchProvider, batchHermeses...)
+	assert.NoError(t, err)
+
+	assert.True(t, plan.Supported)
+	assert.Len(t, plan.Items, 2)
+	assert.Empty(t, plan.Skipped)
+	assert.Equal(t, big.NewInt(1000), plan.Amount)
+	assert.Equal(t, big.NewInt(220), plan.SeparateFees)
+	assert.Equal(t, big.NewInt(140), plan.BatchFees)
+	assert.Equal(t, big.NewInt(80), plan.Savings)
+}
+
+func TestPromiseSettler_PlanSettlementBatchWithoutTransactorSupport(t *testing.T) {
+	transactor := &mockTransactor{
+		feesToReturn:   registry.FeesResponse{Fee: big.NewInt(100)},
+		batchFeesError: registry.ErrBatchSettlementUnsupported,
+	}
+	settler := newBatchSettler(t, transactor, 10, 100, 600)
+
+	plan, err := settler.PlanSettlementBatch(1, batchProvider, batchHermeses...)
+	assert.NoError(t, err)
+
+	assert.False(t, plan.Supported)
+	assert.Len(t, plan.Items, 2)
+	assert.Equal(t, big.NewInt(220), plan.SeparateFees)
+	assert.Equal(t, big.NewInt(220), plan.BatchFees)
+	assert.Equal(t, big.NewInt(0), plan.Savings)
+}
+
+func TestPromiseSettler_PlanSettlementBatchWhenDisabled(t *testing.T) {
+	transactor := &mockTransactor{
+		feesToReturn:      registry.FeesResponse{Fee: big.NewInt(100)},
+		batchFeesToReturn: registry.FeesResponse{Fee: big.NewInt(120)},
+	}
+	settler := newBatchSettler(t, transactor, 10, 100, 600)
+	settler.config.BatchSettlementEnabled = func() bool { return false }
+
+	plan, err := settler.PlanSettlementBatch(1, batchProvider, batchHermeses...)
+	assert.NoError(t, err)
+
+	assert.False(t, plan.Supported)
+	assert.Equal(t, big.NewInt(220), plan.BatchFees)
+	assert.Equal(t, big.NewInt(0), plan.Savings)
+}
+
+func TestPromiseSettler_PlanSettlementBatchSkipsUnprofitablePromises(t *testing.T) {
+	transactor := &mockTransactor{feesToReturn: registry.FeesResponse{Fee: big.NewInt(100)}}
+	settler := newBatchSettler(t, transactor, 50, 580, 600)
+
+	plan, err := settler.PlanSettlementBatch(1, batchProvider, batchHermeses...)
+	assert.NoError(t, err)
+
+	assert.Empty(t, plan.Items)
+	assert.Len(t, plan.Skipped, 2)
+	assert.Equal(t, "hermes fee exceeds the unsettled amount", plan.Skipped[0].Reason)
+	assert.ErrorIs(t, settler.SettleBatch(1, batchProvider, batchHermeses...), ErrNothingToSettle)
+}
+
+func TestPromiseSettler_SettleBatchChargesTransactorFeeOnce(t *testing.T) {
+	transactor := &mockTransactor{
+		feesToReturn:      registry.FeesResponse{Fee: big.NewInt(100)},
+		batchFeesToReturn: registry.FeesResponse{Fee: big.NewInt(120)},
+		idToReturn:        "queue-1",
+		queueToReturn:     registry.QueueResponse{State: "done"},
+	}
+	settler := newBatchSettler(t, transactor, 10, 100, 600)
+
+	err := settler.SettleBatch(1, batchProvider, batchHermeses...)
+	assert.NoError(t, err)
+
+	require.Len(t, transactor.batchSettlements, 2)
+	assert.Equal(t, big.NewInt(120), transactor.batchSettlements[0].Promise.Fee)
+	assert.Equal(t, big.NewInt(0), transactor.batchSettlements[1].Promise.Fee)
+	assert.True(t, settler.startSettling(batchProvider, batchHermeses[0]), "settlement lock must be released")
+}
+
+func TestPromiseSettler_SettleBatchFallsBackIfUnsupported(t *testing.T) {
+	transactor := &mockTransactor{
+		feesToReturn:   registry.FeesResponse{Fee: big.NewInt(100)},
+		batchFeesError: registry.ErrBatchSettlementUnsupported,
+		idToReturn:     "queue-1",
+		queueToReturn:  registry.QueueResponse{State: "done"},
+	}
+	settler := newBatchSettler(t, transactor, 10, 100, 600)
+	hermesCaller := &mockFeeRecordingHermesCaller{}
+	settler.hermesCallerFactory = func(_ string) HermesHTTPRequester { return hermesCaller }
+
+	err := settler.SettleBatch(1, batchProvider, batchHermeses...)
+	assert.NoError(t, err)
+	assert.Empty(t, transactor.batchSettlements)
+	for _, fee := range hermesCaller.fees {
+		assert.Equal(t, big.NewInt(100), fee, "promises must not be re-issued for the batch")
+	}
+}
+
+type mockFeeRecordingHermesCaller struct {
+	mockHermesCaller
+	fees []*big.Int
+}
+
+func (m *mockFeeRecordingHermesCaller) UpdatePromiseFee(promise crypto.Promise, newFee *big.Int) (crypto.Promise, error) {
+	m.fees = append(m.fees, newFee)
+	promise.Fee = newFee
+	return promise, nil
+}
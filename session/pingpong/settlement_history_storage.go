@@ -74,6 +74,7 @@ const settlementHistoryBucket = "settlement-history"
 
 // Store stores a given settlement history entry.
 func (shs *SettlementHistoryStorage) Store(she SettlementHistoryEntry) error {
+	defer shs.bolt.Track("save", settlementHistoryBucket, nil)()
 	shs.bolt.Lock()
 	defer shs.bolt.Unlock()
 	return shs.bolt.DB().From(settlementHistoryBucket).Save(&she)
@@ -113,6 +114,7 @@ func (shs *SettlementHistoryStorage) List(filter SettlementHistoryFilter) (resul
 		}
 	}
 
+	defer shs.bolt.Track("list", settlementHistoryBucket, nil)()
 	shs.bolt.RLock()
 	defer shs.bolt.RUnlock()
 	sq := shs.bolt.DB().
@@ -0,0 +1,218 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// ErrShutdownSettleTimeout indicates that the final settlement did not finish before the node had to exit.
+var ErrShutdownSettleTimeout = errors.New("final settlement timed out")
+
+type promiseLister interface {
+	List(filter HermesPromiseFilter) ([]HermesPromise, error)
+}
+
+type rRevealer interface {
+	RevealR(hermesPromise HermesPromise) error
+}
+
+type forceSettler interface {
+	ForceSettle(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) error
+}
+
+type knownHermesesProvider interface {
+	GetKnownHermeses(chainID int64) ([]common.Address, error)
+}
+
+// ShutdownSettlerConfig configures the final settlement attempt made on node shutdown.
+type ShutdownSettlerConfig struct {
+	ChainID int64
+	// Threshold is the unsettled amount in MYST above which a channel is settled. Zero only reveals pending R values.
+	Threshold float64
+	Timeout   time.Duration
+}
+
+// ShutdownSettler makes a last attempt to secure provider earnings before the node exits,
+// so that decommissioning a machine does not leave them stranded.
+// Only the identities which provided a service since the node started are settled.
+type ShutdownSettler struct {
+	settler  forceSettler
+	channels hermesChannelProvider
+	promises promiseLister
+	revealer rRevealer
+	hermeses knownHermesesProvider
+	config   ShutdownSettlerConfig
+
+	lock      sync.Mutex
+	providers map[identity.Identity]struct{}
+}
+
+// NewShutdownSettler returns a new instance of shutdown settler.
+func NewShutdownSettler(settler forceSettler, channels hermesChannelProvider, promises promiseLister, revealer rRevealer, hermeses knownHermesesProvider, config ShutdownSettlerConfig) *ShutdownSettler {
+	return &ShutdownSettler{
+		settler:   settler,
+		channels:  channels,
+		promises:  promises,
+		revealer:  revealer,
+		hermeses:  hermeses,
+		config:    config,
+		providers: make(map[identity.Identity]struct{}),
+	}
+}
+
+// Subscribe subscribes to the service status events to learn which identities provide services.
+func (ss *ShutdownSettler) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(servicestate.AppTopicServiceStatus, ss.handleServiceEvent)
+}
+
+func (ss *ShutdownSettler) handleServiceEvent(e servicestate.AppEventServiceStatus) {
+	if e.Status != string(servicestate.Running) {
+		return
+	}
+
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	ss.providers[identity.FromAddress(e.ProviderID)] = struct{}{}
+}
+
+func (ss *ShutdownSettler) providerIDs() []identity.Identity {
+	ss.lock.Lock()
+	defer ss.lock.Unlock()
+
+	ids := make([]identity.Identity, 0, len(ss.providers))
+	for id := range ss.providers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Address < ids[j].Address
+	})
+	return ids
+}
+
+// Settle reveals all pending R values to hermes and settles the channels with enough unsettled earnings.
+// It gives up once the configured timeout passes, leaving the rest to the next node start.
+func (ss *ShutdownSettler) Settle() error {
+	done := make(chan error, 1)
+	go func() {
+		done <- ss.settle()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(ss.config.Timeout):
+		return ErrShutdownSettleTimeout
+	}
+}
+
+func (ss *ShutdownSettler) settle() error {
+	providers := ss.providerIDs()
+	if len(providers) == 0 {
+		return nil
+	}
+
+	hermeses, err := ss.hermeses.GetKnownHermeses(ss.config.ChainID)
+	if err != nil {
+		return fmt.Errorf("could not get known hermeses: %w", err)
+	}
+
+	var lastErr error
+	for _, id := range providers {
+		if err := ss.revealPending(id); err != nil {
+			log.Err(err).Msgf("Could not reveal pending R values of %v on shutdown", id.Address)
+			lastErr = err
+		}
+
+		if ss.config.Threshold <= 0 {
+			continue
+		}
+
+		if err := ss.settleAboveThreshold(id, hermeses); err != nil {
+			log.Err(err).Msgf("Could not settle earnings of %v on shutdown", id.Address)
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+func (ss *ShutdownSettler) revealPending(id identity.Identity) error {
+	promises, err := ss.promises.List(HermesPromiseFilter{
+		Identity: &id,
+		ChainID:  ss.config.ChainID,
+	})
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, p := range promises {
+		if p.Revealed {
+			continue
+		}
+
+		if err := ss.revealer.RevealR(p); err != nil {
+			lastErr = fmt.Errorf("could not reveal R for hermes %v: %w", p.HermesID.Hex(), err)
+			continue
+		}
+		log.Info().Msgf("Revealed pending R for hermes %v of %v", p.HermesID.Hex(), id.Address)
+	}
+
+	return lastErr
+}
+
+func (ss *ShutdownSettler) settleAboveThreshold(id identity.Identity, hermeses []common.Address) error {
+	threshold := crypto.FloatToBigMyst(ss.config.Threshold)
+
+	toSettle := make([]common.Address, 0)
+	for _, hermesID := range hermeses {
+		channel, err := ss.channels.Fetch(ss.config.ChainID, id, hermesID)
+		if err != nil {
+			log.Debug().Err(err).Msgf("No channel with hermes %v for %v", hermesID.Hex(), id.Address)
+			continue
+		}
+
+		unsettled := channel.UnsettledBalance()
+		if unsettled.Cmp(big.NewInt(0)) <= 0 || unsettled.Cmp(threshold) < 0 {
+			continue
+		}
+		toSettle = append(toSettle, hermesID)
+	}
+
+	if len(toSettle) == 0 {
+		return nil
+	}
+
+	log.Info().Msgf("Settling earnings of %v with %d hermes(es) before shutdown", id.Address, len(toSettle))
+	return ss.settler.ForceSettle(ss.config.ChainID, id, toSettle...)
+}
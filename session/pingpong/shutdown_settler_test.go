@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockPromiseLister struct {
+	promises []HermesPromise
+}
+
+func (mpl *mockPromiseLister) List(_ HermesPromiseFilter) ([]HermesPromise, error) {
+	return mpl.promises, nil
+}
+
+type mockRevealer struct {
+	revealed []HermesPromise
+}
+
+func (mr *mockRevealer) RevealR(p HermesPromise) error {
+	mr.revealed = append(mr.revealed, p)
+	return nil
+}
+
+type mockForceSettler struct {
+	lock     sync.Mutex
+	settled  []common.Address
+	blockFor time.Duration
+}
+
+func (mfs *mockForceSettler) ForceSettle(_ int64, _ identity.Identity, hermesIDs ...common.Address) error {
+	time.Sleep(mfs.blockFor)
+	mfs.lock.Lock()
+	defer mfs.lock.Unlock()
+	mfs.settled = append(mfs.settled, hermesIDs...)
+	return nil
+}
+
+type mockKnownHermeses struct {
+	hermeses []common.Address
+}
+
+func (mkh *mockKnownHermeses) GetKnownHermeses(_ int64) ([]common.Address, error) {
+	return mkh.hermeses, nil
+}
+
+func shutdownSettlerChannel(settled, promised int64) *mockHermesChannelProvider {
+	return &mockHermesChannelProvider{
+		channelToReturn: HermesChannel{
+			Channel: client.ProviderChannel{
+				Stake:   big.NewInt(0),
+				Settled: big.NewInt(settled),
+			},
+			lastPromise: HermesPromise{
+				Promise: crypto.Promise{Amount: big.NewInt(promised)},
+			},
+		},
+	}
+}
+
+func runShutdownSettlerService(ss *ShutdownSettler) *ShutdownSettler {
+	ss.handleServiceEvent(servicestate.AppEventServiceStatus{ProviderID: "0x1", Status: string(servicestate.Running)})
+	return ss
+}
+
+func TestShutdownSettler_RevealsPendingRAndSettlesAboveThreshold(t *testing.T) {
+	hermesID := common.HexToAddress("0x2")
+	promises := &mockPromiseLister{promises: []HermesPromise{
+		{HermesID: hermesID, R: "aa", Revealed: true},
+		{HermesID: hermesID, R: "bb"},
+	}}
+	revealer := &mockRevealer{}
+	settler := &mockForceSettler{}
+
+	ss := runShutdownSettlerService(NewShutdownSettler(
+		settler,
+		shutdownSettlerChannel(0, crypto.FloatToBigMyst(2).Int64()),
+		promises,
+		revealer,
+		&mockKnownHermeses{hermeses: []common.Address{hermesID}},
+		ShutdownSettlerConfig{Threshold: 1, Timeout: time.Second},
+	))
+
+	assert.NoError(t, ss.Settle())
+	assert.Len(t, revealer.revealed, 1)
+	assert.Equal(t, "bb", revealer.revealed[0].R)
+	assert.Equal(t, []common.Address{hermesID}, settler.settled)
+}
+
+func TestShutdownSettler_SkipsBelowThreshold(t *testing.T) {
+	hermesID := common.HexToAddress("0x2")
+	settler := &mockForceSettler{}
+
+	ss := runShutdownSettlerService(NewShutdownSettler(
+		settler,
+		shutdownSettlerChannel(10, 15),
+		&mockPromiseLister{},
+		&mockRevealer{},
+		&mockKnownHermeses{hermeses: []common.Address{hermesID}},
+		ShutdownSettlerConfig{Threshold: 1, Timeout: time.Second},
+	))
+
+	assert.NoError(t, ss.Settle())
+	assert.Empty(t, settler.settled)
+}
+
+func TestShutdownSettler_ZeroThresholdOnlyReveals(t *testing.T) {
+	hermesID := common.HexToAddress("0x2")
+	revealer := &mockRevealer{}
+	settler := &mockForceSettler{}
+
+	ss := runShutdownSettlerService(NewShutdownSettler(
+		settler,
+		shutdownSettlerChannel(0, crypto.FloatToBigMyst(5).Int64()),
+		&mockPromiseLister{promises: []HermesPromise{{HermesID: hermesID, R: "bb"}}},
+		revealer,
+		&mockKnownHermeses{hermeses: []common.Address{hermesID}},
+		ShutdownSettlerConfig{Threshold: 0, Timeout: time.Second},
+	))
+
+	assert.NoError(t, ss.Settle())
+	assert.Len(t, revealer.revealed, 1)
+	assert.Empty(t, settler.settled)
+}
+
+func TestShutdownSettler_GivesUpAfterTimeout(t *testing.T) {
+	hermesID := common.HexToAddress("0x2")
+
+	ss := runShutdownSettlerService(NewShutdownSettler(
+		&mockForceSettler{blockFor: time.Second},
+		shutdownSettlerChannel(0, crypto.FloatToBigMyst(2).Int64()),
+		&mockPromiseLister{},
+		&mockRevealer{},
+		&mockKnownHermeses{hermeses: []common.Address{hermesID}},
+		ShutdownSettlerConfig{Threshold: 1, Timeout: 10 * time.Millisecond},
+	))
+
+	assert.ErrorIs(t, ss.Settle(), ErrShutdownSettleTimeout)
+}
+
+func TestShutdownSettler_SkipsIdentitiesWithoutService(t *testing.T) {
+	hermesID := common.HexToAddress("0x2")
+	revealer := &mockRevealer{}
+	settler := &mockForceSettler{}
+
+	ss := NewShutdownSettler(
+		settler,
+		shutdownSettlerChannel(0, crypto.FloatToBigMyst(10).Int64()),
+		&mockPromiseLister{promises: []HermesPromise{{HermesID: hermesID, R: "bb"}}},
+		revealer,
+		&mockKnownHermeses{hermeses: []common.Address{hermesID}},
+		ShutdownSettlerConfig{Threshold: 1, Timeout: time.Second},
+	)
+	ss.handleServiceEvent(servicestate.AppEventServiceStatus{ProviderID: "0x1", Status: string(servicestate.Starting)})
+
+	assert.NoError(t, ss.Settle())
+	assert.Empty(t, revealer.revealed)
+	assert.Empty(t, settler.settled)
+}
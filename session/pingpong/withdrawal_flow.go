@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gofrs/uuid"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// WithdrawalJobStatus represents the stage of the guided withdrawal job.
+type WithdrawalJobStatus string
+
+const (
+	// WithdrawalJobSettling means the outstanding promises are being settled and confirmed on chain.
+	WithdrawalJobSettling WithdrawalJobStatus = "settling"
+	// WithdrawalJobWithdrawing means the settled balance is being transferred to the external address.
+	WithdrawalJobWithdrawing WithdrawalJobStatus = "withdrawing"
+	// WithdrawalJobCompleted means the settled balance was transferred to the external address.
+	WithdrawalJobCompleted WithdrawalJobStatus = "completed"
+	// WithdrawalJobFailed means the withdrawal was interrupted with an error.
+	WithdrawalJobFailed WithdrawalJobStatus = "failed"
+)
+
+// withdrawalJobRetention is how long finished jobs are kept, so clients are able to poll their outcome.
+const withdrawalJobRetention = time.Hour
+
+// ErrWithdrawalInProgress indicates that the provider already has a running withdrawal job.
+var ErrWithdrawalInProgress = errors.New("provider already has withdrawal in progress")
+
+// WithdrawalRequest describes the guided withdrawal to an external address.
+type WithdrawalRequest struct {
+	ProviderID  identity.Identity
+	HermesID    common.Address
+	Beneficiary common.Address
+	FromChainID int64
+	ToChainID   int64
+	// Amount to withdraw, the whole settled balance if nil.
+	Amount *big.Int
+}
+
+// WithdrawalJob represents the state of the guided withdrawal.
+type WithdrawalJob struct {
+	ID        string
+	Request   WithdrawalRequest
+	Status    WithdrawalJobStatus
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Finished returns whether the job is no longer running.
+func (j WithdrawalJob) Finished() bool {
+	return j.Status == WithdrawalJobCompleted || j.Status == WithdrawalJobFailed
+}
+
+type withdrawalSettler interface {
+	ForceSettle(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) error
+	Withdraw(fromChainID int64, toChainID int64, providerID identity.Identity, hermesID, beneficiary common.Address, amount *big.Int) error
+}
+
+// WithdrawalFlow settles the provider earnings and withdraws them to an external address
+// in the background, reporting the progress of each job as events.
+type WithdrawalFlow struct {
+	settler   withdrawalSettler
+	publisher eventbus.Publisher
+	clock     Clock
+
+	lock sync.Mutex
+	jobs map[string]*WithdrawalJob
+}
+
+// NewWithdrawalFlow returns a new guided withdrawal flow.
+func NewWithdrawalFlow(settler withdrawalSettler, publisher eventbus.Publisher) *WithdrawalFlow {
+	return &WithdrawalFlow{
+		settler:   settler,
+		publisher: publisher,
+		clock:     SystemClock{},
+		jobs:      make(map[string]*WithdrawalJob),
+	}
+}
+
+// Start starts a new withdrawal job, only a single job per provider can run at a time.
+func (wf *WithdrawalFlow) Start(req WithdrawalRequest) (WithdrawalJob, error) {
+	wf.lock.Lock()
+	defer wf.lock.Unlock()
+
+	wf.evictFinished()
+	for _, job := range wf.jobs {
+		if job.Request.ProviderID == req.ProviderID && !job.Finished() {
+			return WithdrawalJob{}, ErrWithdrawalInProgress
+		}
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return WithdrawalJob{}, fmt.Errorf("could not generate job id: %w", err)
+	}
+
+	now := wf.clock.Now().UTC()
+	job := &WithdrawalJob{
+		ID:        id.String(),
+		Request:   req,
+		Status:    WithdrawalJobSettling,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	wf.jobs[job.ID] = job
+	wf.publish(*job)
+
+	go wf.run(job.ID, req)
+
+	return *job, nil
+}
+
+// Job returns the withdrawal job with the given ID.
+func (wf *WithdrawalFlow) Job(id string) (WithdrawalJob, bool) {
+	wf.lock.Lock()
+	defer wf.lock.Unlock()
+
+	job, ok := wf.jobs[id]
+	if !ok {
+		return WithdrawalJob{}, false
+	}
+	return *job, true
+}
+
+func (wf *WithdrawalFlow) run(id string, req WithdrawalRequest) {
+	// ForceSettle returns only after the settlement is found on chain.
+	err := wf.settler.ForceSettle(req.FromChainID, req.ProviderID, req.HermesID)
+	if err != nil && !errors.Is(err, ErrNothingToSettle) {
+		wf.update(id, WithdrawalJobFailed, fmt.Errorf("could not settle promises: %w", err))
+		return
+	}
+
+	wf.update(id, WithdrawalJobWithdrawing, nil)
+	err = wf.settler.Withdraw(req.FromChainID, req.ToChainID, req.ProviderID, req.HermesID, req.Beneficiary, req.Amount)
+	if err != nil {
+		wf.update(id, WithdrawalJobFailed, fmt.Errorf("could not withdraw: %w", err))
+		return
+	}
+
+	wf.update(id, WithdrawalJobCompleted, nil)
+}
+
+func (wf *WithdrawalFlow) update(id string, status WithdrawalJobStatus, err error) {
+	wf.lock.Lock()
+	defer wf.lock.Unlock()
+
+	job := wf.jobs[id]
+	job.Status = status
+	job.UpdatedAt = wf.clock.Now().UTC()
+	if err != nil {
+		job.Error = err.Error()
+		log.Err(err).Str("job_id", id).Msg("Withdrawal job failed")
+	}
+	wf.publish(*job)
+}
+
+// evictFinished removes the jobs finished longer than the retention period ago, must be called with the lock held.
+func (wf *WithdrawalFlow) evictFinished() {
+	now := wf.clock.Now()
+	for id, job := range wf.jobs {
+		if job.Finished() && now.Sub(job.UpdatedAt) > withdrawalJobRetention {
+			delete(wf.jobs, id)
+		}
+	}
+}
+
+func (wf *WithdrawalFlow) publish(job WithdrawalJob) {
+	wf.publisher.Publish(event.AppTopicWithdrawalJob, event.AppEventWithdrawalJob{
+		ID:         job.ID,
+		ProviderID: job.Request.ProviderID,
+		Status:     string(job.Status),
+		Error:      job.Error,
+	})
+}
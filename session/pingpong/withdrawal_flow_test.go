@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func TestWithdrawalFlow_Completes(t *testing.T) {
+	settler := &mockWithdrawalSettler{settleErr: ErrNothingToSettle}
+	publisher := mocks.NewEventBus()
+	flow := NewWithdrawalFlow(settler, publisher)
+
+	req := WithdrawalRequest{
+		ProviderID:  identity.FromAddress("0x1"),
+		HermesID:    common.HexToAddress("0x2"),
+		Beneficiary: common.HexToAddress("0x3"),
+		FromChainID: 137,
+		Amount:      big.NewInt(10),
+	}
+	job, err := flow.Start(req)
+	assert.NoError(t, err)
+	assert.Equal(t, WithdrawalJobSettling, job.Status)
+
+	assert.Eventually(t, func() bool {
+		job, _ := flow.Job(job.ID)
+		return job.Status == WithdrawalJobCompleted
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, req, settler.withdrawnWith())
+
+	var statuses []string
+	for _, e := range publisher.GetEventHistory() {
+		assert.Equal(t, event.AppTopicWithdrawalJob, e.Topic)
+		statuses = append(statuses, e.Event.(event.AppEventWithdrawalJob).Status)
+	}
+	assert.Equal(t, []string{"settling", "withdrawing", "completed"}, statuses)
+}
+
+func TestWithdrawalFlow_EvictsFinishedJobs(t *testing.T) {
+	clock := newMockClock()
+	flow := NewWithdrawalFlow(&mockWithdrawalSettler{settleErr: ErrNothingToSettle}, mocks.NewEventBus())
+	flow.clock = clock
+
+	job, err := flow.Start(WithdrawalRequest{ProviderID: identity.FromAddress("0x1")})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		job, _ := flow.Job(job.ID)
+		return job.Finished()
+	}, 2*time.Second, 10*time.Millisecond)
+
+	clock.Advance(withdrawalJobRetention / 2)
+	_, err = flow.Start(WithdrawalRequest{ProviderID: identity.FromAddress("0x2")})
+	assert.NoError(t, err)
+	_, ok := flow.Job(job.ID)
+	assert.True(t, ok)
+
+	clock.Advance(withdrawalJobRetention)
+	_, err = flow.Start(WithdrawalRequest{ProviderID: identity.FromAddress("0x3")})
+	assert.NoError(t, err)
+	_, ok = flow.Job(job.ID)
+	assert.False(t, ok)
+}
+
+func TestWithdrawalFlow_FailsOnSettlementError(t *testing.T) {
+	settler := &mockWithdrawalSettler{settleErr: errors.New("boom")}
+	flow := NewWithdrawalFlow(settler, mocks.NewEventBus())
+
+	job, err := flow.Start(WithdrawalRequest{ProviderID: identity.FromAddress("0x1")})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, _ := flow.Job(job.ID)
+		return job.Status == WithdrawalJobFailed
+	}, 2*time.Second, 10*time.Millisecond)
+
+	job, _ = flow.Job(job.ID)
+	assert.Equal(t, "could not settle promises: boom", job.Error)
+	assert.Equal(t, WithdrawalRequest{}, settler.withdrawnWith())
+}
+
+func TestWithdrawalFlow_RejectsConcurrentJobs(t *testing.T) {
+	settler := &mockWithdrawalSettler{block: make(chan struct{})}
+	defer close(settler.block)
+	flow := NewWithdrawalFlow(settler, mocks.NewEventBus())
+
+	_, err := flow.Start(WithdrawalRequest{ProviderID: identity.FromAddress("0x1")})
+	assert.NoError(t, err)
+
+	_, err = flow.Start(WithdrawalRequest{ProviderID: identity.FromAddress("0x1")})
+	assert.ErrorIs(t, err, ErrWithdrawalInProgress)
+
+	_, err = flow.Start(WithdrawalRequest{ProviderID: identity.FromAddress("0x2")})
+	assert.NoError(t, err)
+}
+
+type mockWithdrawalSettler struct {
+	settleErr error
+	block     chan struct{}
+
+	lock      sync.Mutex
+	withdrawn WithdrawalRequest
+}
+
+func (m *mockWithdrawalSettler) ForceSettle(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) error {
+	if m.block != nil {
+		<-m.block
+	}
+	return m.settleErr
+}
+
+func (m *mockWithdrawalSettler) Withdraw(fromChainID int64, toChainID int64, providerID identity.Identity, hermesID, beneficiary common.Address, amount *big.Int) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.withdrawn = WithdrawalRequest{
+		ProviderID:  providerID,
+		HermesID:    hermesID,
+		Beneficiary: beneficiary,
+		FromChainID: fromChainID,
+		ToChainID:   toChainID,
+		Amount:      amount,
+	}
+	return nil
+}
+
+func (m *mockWithdrawalSettler) withdrawnWith() WithdrawalRequest {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.withdrawn
+}
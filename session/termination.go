@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+// TerminationReason is a reason code sent to the consumer when provider terminates the session.
+type TerminationReason uint32
+
+const (
+	// TerminationReasonUnspecified indicates that provider has not given any reason.
+	TerminationReasonUnspecified TerminationReason = 0
+
+	// TerminationReasonAbusiveConsumer indicates that consumer was abusing the service.
+	TerminationReasonAbusiveConsumer TerminationReason = 1
+
+	// TerminationReasonPolicyViolation indicates that consumer has violated the access policy of the service.
+	TerminationReasonPolicyViolation TerminationReason = 2
+
+	// TerminationReasonMaintenance indicates that provider is going under maintenance.
+	TerminationReasonMaintenance TerminationReason = 3
+
+	// TerminationReasonPaymentFailure indicates that session payments between the peers failed.
+	TerminationReasonPaymentFailure TerminationReason = 4
+
+	// TerminationReasonProviderShutdown indicates that provider has stopped the service.
+	TerminationReasonProviderShutdown TerminationReason = 5
+
+	// TerminationReasonIdleTimeout indicates that consumer stopped responding to the provider.
+	TerminationReasonIdleTimeout TerminationReason = 6
+
+	// TerminationReasonInactive indicates that session neither transferred data nor was paid for too long.
+	TerminationReasonInactive TerminationReason = 7
+)
+
+var terminationReasonNames = map[TerminationReason]string{
+	TerminationReasonUnspecified:      "unspecified",
+	TerminationReasonAbusiveConsumer:  "abusive_consumer",
+	TerminationReasonPolicyViolation:  "policy_violation",
+	TerminationReasonMaintenance:      "maintenance",
+	TerminationReasonPaymentFailure:   "payment_failure",
+	TerminationReasonProviderShutdown: "provider_shutdown",
+	TerminationReasonIdleTimeout:      "idle_timeout",
+	TerminationReasonInactive:         "inactive",
+}
+
+// String returns the name of the termination reason.
+func (r TerminationReason) String() string {
+	if name, ok := terminationReasonNames[r]; ok {
+		return name
+	}
+	return terminationReasonNames[TerminationReasonUnspecified]
+}
+
+// ParseTerminationReason returns the termination reason by its name.
+func ParseTerminationReason(name string) (TerminationReason, bool) {
+	for reason, reasonName := range terminationReasonNames {
+		if reasonName == name {
+			return reason, true
+		}
+	}
+	return TerminationReasonUnspecified, false
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traffic
+
+import (
+	"encoding/binary"
+	"sync/atomic"
+)
+
+// Protocol names used in the traffic classes.
+const (
+	ProtocolTCP   = "tcp"
+	ProtocolUDP   = "udp"
+	ProtocolOther = "other"
+)
+
+// Port bucket names used in the traffic classes.
+const (
+	PortsWeb   = "web"
+	PortsDNS   = "dns"
+	PortsMail  = "mail"
+	PortsSSH   = "ssh"
+	PortsP2P   = "p2p"
+	PortsOther = "other"
+)
+
+const (
+	ipProtocolTCP = 6
+	ipProtocolUDP = 17
+)
+
+var (
+	protocols = []string{ProtocolTCP, ProtocolUDP, ProtocolOther}
+	buckets   = []string{PortsWeb, PortsDNS, PortsMail, PortsSSH, PortsP2P, PortsOther}
+)
+
+// Counter holds the amount of traffic of a single class.
+type Counter struct {
+	Packets uint64 `json:"packets"`
+	Bytes   uint64 `json:"bytes"`
+}
+
+// Counters holds the amount of traffic per class, e.g. "tcp/web" or "udp/dns".
+// Classes without any traffic are omitted.
+type Counters map[string]Counter
+
+// Classifier counts packets by their transport protocol and destination port bucket.
+// It only looks at the IP and transport headers, payload is never inspected.
+type Classifier struct {
+	packets [3][6]uint64
+	bytes   [3][6]uint64
+}
+
+// NewClassifier returns a new traffic classifier.
+func NewClassifier() *Classifier {
+	return &Classifier{}
+}
+
+// Classify counts the given IP packet.
+func (c *Classifier) Classify(packet []byte) {
+	protocol, bucket := classify(packet)
+	atomic.AddUint64(&c.packets[protocol][bucket], 1)
+	atomic.AddUint64(&c.bytes[protocol][bucket], uint64(len(packet)))
+}
+
+// Counters returns the amount of traffic classified so far.
+func (c *Classifier) Counters() Counters {
+	counters := make(Counters)
+	for p := range c.packets {
+		for b := range c.packets[p] {
+			packets := atomic.LoadUint64(&c.packets[p][b])
+			if packets == 0 {
+				continue
+			}
+			counters[className(p, b)] = Counter{
+				Packets: packets,
+				Bytes:   atomic.LoadUint64(&c.bytes[p][b]),
+			}
+		}
+	}
+	return counters
+}
+
+func className(protocol, bucket int) string {
+	if protocols[protocol] == ProtocolOther {
+		return ProtocolOther
+	}
+	return protocols[protocol] + "/" + buckets[bucket]
+}
+
+// classify returns the indexes of protocol and port bucket of the packet.
+func classify(packet []byte) (protocol, bucket int) {
+	other := len(protocols) - 1
+	otherBucket := len(buckets) - 1
+	if len(packet) == 0 {
+		return other, otherBucket
+	}
+
+	var ipProtocol byte
+	var transport []byte
+	switch packet[0] >> 4 {
+	case 4:
+		if len(packet) < 20 {
+			return other, otherBucket
+		}
+		headerLen := int(packet[0]&0x0f) * 4
+		// Only the first fragment carries the transport header.
+		if fragmentOffset := binary.BigEndian.Uint16(packet[6:8]) & 0x1fff; fragmentOffset != 0 || len(packet) < headerLen {
+			return other, otherBucket
+		}
+		ipProtocol, transport = packet[9], packet[headerLen:]
+	case 6:
+		// Extension headers are not followed, such packets are rare and counted as other.
+		if len(packet) < 40 {
+			return other, otherBucket
+		}
+		ipProtocol, transport = packet[6], packet[40:]
+	default:
+		return other, otherBucket
+	}
+
+	switch ipProtocol {
+	case ipProtocolTCP:
+		protocol = 0
+	case ipProtocolUDP:
+		protocol = 1
+	default:
+		return other, otherBucket
+	}
+	if len(transport) < 4 {
+		return protocol, otherBucket
+	}
+
+	return protocol, portBucket(binary.BigEndian.Uint16(transport[2:4]))
+}
+
+func portBucket(port uint16) int {
+	switch {
+	case port == 80 || port == 443 || port == 8080 || port == 8443:
+		return 0
+	case port == 53 || port == 853:
+		return 1
+	case port == 25 || port == 110 || port == 143 || port == 465 || port == 587 || port == 993 || port == 995:
+		return 2
+	case port == 22:
+		return 3
+	case port >= 6881 && port <= 6889, port == 51413:
+		return 4
+	default:
+		return 5
+	}
+}
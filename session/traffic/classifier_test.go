@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traffic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ipv4Packet(protocol byte, dstPort uint16, size int) []byte {
+	packet := make([]byte, size)
+	packet[0] = 0x45
+	packet[9] = protocol
+	packet[22] = byte(dstPort >> 8)
+	packet[23] = byte(dstPort)
+	return packet
+}
+
+func ipv6Packet(protocol byte, dstPort uint16, size int) []byte {
+	packet := make([]byte, size)
+	packet[0] = 0x60
+	packet[6] = protocol
+	packet[42] = byte(dstPort >> 8)
+	packet[43] = byte(dstPort)
+	return packet
+}
+
+func TestClassifier_Counters(t *testing.T) {
+	c := NewClassifier()
+	assert.Empty(t, c.Counters())
+
+	c.Classify(ipv4Packet(ipProtocolTCP, 443, 60))
+	c.Classify(ipv6Packet(ipProtocolTCP, 80, 100))
+	c.Classify(ipv4Packet(ipProtocolUDP, 53, 40))
+	c.Classify(ipv4Packet(ipProtocolUDP, 6881, 1000))
+	c.Classify(ipv4Packet(ipProtocolTCP, 25, 50))
+	c.Classify(ipv4Packet(ipProtocolUDP, 12345, 70))
+	c.Classify(ipv4Packet(1, 0, 30))
+	c.Classify([]byte{0x45, 0x00})
+
+	assert.Equal(t, Counters{
+		"tcp/web":   {Packets: 2, Bytes: 160},
+		"tcp/mail":  {Packets: 1, Bytes: 50},
+		"udp/dns":   {Packets: 1, Bytes: 40},
+		"udp/p2p":   {Packets: 1, Bytes: 1000},
+		"udp/other": {Packets: 1, Bytes: 70},
+		"other":     {Packets: 2, Bytes: 32},
+	}, c.Counters())
+}
+
+func TestClassifier_IgnoresPortsOfFragments(t *testing.T) {
+	c := NewClassifier()
+
+	packet := ipv4Packet(ipProtocolTCP, 443, 60)
+	packet[7] = 0x10
+	c.Classify(packet)
+
+	assert.Equal(t, Counters{"other": {Packets: 1, Bytes: 60}}, c.Counters())
+}
@@ -41,6 +41,10 @@ const (
 	EventSleep
 )
 
+func init() {
+	eventbus.RegisterSchema(AppTopicSleepNotification, 1, EventWakeup)
+}
+
 var eventChannel chan Event
 
 // Notifier represents sleep event notifier structure
@@ -35,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/supervisor/daemon/transport"
 	"github.com/mysteriumnetwork/node/supervisor/daemon/wireguard"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 )
 
 // Daemon - supervisor process.
@@ -139,7 +140,7 @@ func (d *Daemon) excludeRoute(args ...string) error {
 	flags := flag.NewFlagSet("", flag.ContinueOnError)
 
 	ip := flags.String("ip", "", "Destination IP address")
-	gw := flags.String("gw", "", "Gateway")
+	gw := flags.String("gw", "", "Gateway, resolved from the current route for IPv6")
 
 	if err := flags.Parse(args[1:]); err != nil {
 		return err
@@ -148,11 +149,11 @@ func (d *Daemon) excludeRoute(args ...string) error {
 	if *ip == "" {
 		return errors.New("-ip is required")
 	}
-	if *gw == "" {
-		return errors.New("-gw is required")
-	}
 
 	ipAddr := net.ParseIP(*ip)
+	if *gw == "" && !netutil.IsIPv6(ipAddr) {
+		return errors.New("-gw is required")
+	}
 	gwAddr := net.ParseIP(*gw)
 
 	t := &network.RoutingTable{}
@@ -163,7 +164,7 @@ func (d *Daemon) deleteRoute(args ...string) error {
 	flags := flag.NewFlagSet("", flag.ContinueOnError)
 
 	ip := flags.String("ip", "", "Destination IP address")
-	gw := flags.String("gw", "", "Gateway")
+	gw := flags.String("gw", "", "Gateway, resolved from the current route for IPv6")
 
 	if err := flags.Parse(args[1:]); err != nil {
 		return err
@@ -172,11 +173,11 @@ func (d *Daemon) deleteRoute(args ...string) error {
 	if *ip == "" {
 		return errors.New("-ip is required")
 	}
-	if *gw == "" {
-		return errors.New("-gw is required")
-	}
 
 	ipAddr := net.ParseIP(*ip)
+	if *gw == "" && !netutil.IsIPv6(ipAddr) {
+		return errors.New("-gw is required")
+	}
 	gwAddr := net.ParseIP(*gw)
 
 	t := &network.RoutingTable{}
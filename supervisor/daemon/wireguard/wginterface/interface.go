@@ -153,8 +153,8 @@ func configureNetwork(cfg wgcfg.DeviceConfig, dnsManager dns.Manager) error {
 	}
 
 	if cfg.Peer.Endpoint != nil {
-		if err := netutil.AddDefaultRoute(cfg.IfaceName); err != nil {
-			return fmt.Errorf("could not add default route for %s: %w", cfg.IfaceName, err)
+		if err := netutil.AddRoutes(cfg.IfaceName, cfg.Peer.Routes()); err != nil {
+			return fmt.Errorf("could not add routes for %s: %w", cfg.IfaceName, err)
 		}
 	}
 
@@ -43,7 +43,7 @@ func (testSuite *tequilapiTestSuite) SetupSuite() {
 	listener, err := net.Listen("tcp", "localhost:0")
 	assert.Nil(testSuite.T(), err)
 	testSuite.server, err = NewServer(listener, *node.GetOptions(), []func(e *gin.Engine) error{func(e *gin.Engine) error {
-		e.GET("/healthcheck", endpoints.HealthCheckEndpointFactory(time.Now, os.Getpid).HealthCheck)
+		e.GET("/healthcheck", endpoints.HealthCheckEndpointFactory(time.Now, os.Getpid, nil).HealthCheck)
 		return nil
 	}})
 	assert.NoError(testSuite.T(), err)
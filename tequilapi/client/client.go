@@ -18,17 +18,19 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/big"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 
-	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/payments/exchange"
@@ -190,6 +192,19 @@ func (client *Client) Identity(identityAddress string) (id contract.IdentityDTO,
 	return id, err
 }
 
+// IdentityStatuses returns details of all identities
+func (client *Client) IdentityStatuses() ([]contract.IdentityStatusDTO, error) {
+	response, err := client.http.Get("identities-status", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	var list contract.ListIdentityStatusesResponse
+	err = parseResponseJSON(response, &list)
+	return list.Identities, err
+}
+
 // IdentityRegistrationStatus returns information of identity needed to register it on blockchain
 func (client *Client) IdentityRegistrationStatus(address string) (contract.IdentityRegistrationResponse, error) {
 	response, err := client.http.Get("identities/"+address+"/registration", url.Values{})
@@ -290,6 +305,30 @@ func (client *Client) ConnectionDestroy(port int) (err error) {
 	return nil
 }
 
+// ConnectionPause pauses billing and throttles the given connection
+func (client *Client) ConnectionPause(port int) error {
+	url := fmt.Sprintf("connection/pause?%s", url.Values{"id": []string{strconv.Itoa(port)}}.Encode())
+	response, err := client.http.Put(url, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ConnectionResume resumes the given paused connection
+func (client *Client) ConnectionResume(port int) error {
+	url := fmt.Sprintf("connection/resume?%s", url.Values{"id": []string{strconv.Itoa(port)}}.Encode())
+	response, err := client.http.Put(url, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
 // ConnectionStatistics returns statistics about current connection
 func (client *Client) ConnectionStatistics(sessionID ...string) (statistics contract.ConnectionStatisticsDTO, err error) {
 	response, err := client.http.Get("connection/statistics", url.Values{
@@ -318,6 +357,36 @@ func (client *Client) ConnectionTraffic(sessionID ...string) (traffic contract.C
 	return traffic, err
 }
 
+// ConnectionDiagnostics runs tunnel internal checks of current connection and returns the health report
+func (client *Client) ConnectionDiagnostics(sessionID ...string) (report contract.ConnectionDiagnosticsDTO, err error) {
+	response, err := client.http.Get("connection/diagnostics", url.Values{
+		"id": sessionID,
+	})
+	if err != nil {
+		return report, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &report)
+	return report, err
+}
+
+// ProviderPreflight runs provider startup checks for given identity and services and returns the report
+func (client *Client) ProviderPreflight(providerID string, serviceTypes []string) (report contract.PreflightReportDTO, err error) {
+	params := url.Values{"id": []string{providerID}}
+	for _, serviceType := range serviceTypes {
+		params.Add("services", serviceType)
+	}
+	response, err := client.http.Get("node/provider/preflight", params)
+	if err != nil {
+		return report, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &report)
+	return report, err
+}
+
 // ConnectionStatus returns connection status
 func (client *Client) ConnectionStatus(port int) (status contract.ConnectionInfoDTO, err error) {
 	response, err := client.http.Get("connection", url.Values{"id": []string{strconv.Itoa(port)}})
@@ -439,15 +508,55 @@ func (client *Client) ProposalsNATCompatible() ([]contract.ProposalDTO, error) {
 }
 
 func (client *Client) proposals(query url.Values) ([]contract.ProposalDTO, error) {
-	response, err := client.http.Get("proposals", query)
+	proposals := []contract.ProposalDTO{}
+	err := client.ProposalsStream(query, func(p contract.ProposalDTO) error {
+		proposals = append(proposals, p)
+		return nil
+	})
+	return proposals, err
+}
+
+// ProposalsStream fetches proposals matching the query and passes them to the handler
+// one by one while the response is being parsed, without buffering the whole list.
+func (client *Client) ProposalsStream(query url.Values, handler func(contract.ProposalDTO) error) error {
+	streamQuery := url.Values{}
+	for key, values := range query {
+		streamQuery[key] = values
+	}
+	streamQuery.Set("format", contract.ProposalsFormatNDJSON)
+
+	response, err := client.http.Get("proposals", streamQuery)
 	if err != nil {
-		return []contract.ProposalDTO{}, err
+		return err
 	}
 	defer response.Body.Close()
 
-	var proposals contract.ListProposalsResponse
-	err = parseResponseJSON(response, &proposals)
-	return proposals.Proposals, err
+	// Nodes not supporting streaming ignore the format and respond with the whole list.
+	if !strings.HasPrefix(response.Header.Get("Content-Type"), "application/x-ndjson") {
+		var proposals contract.ListProposalsResponse
+		if err := parseResponseJSON(response, &proposals); err != nil {
+			return err
+		}
+		for _, p := range proposals.Proposals {
+			if err := handler(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var p contract.ProposalDTO
+		if err := decoder.Decode(&p); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err := handler(p); err != nil {
+			return err
+		}
+	}
 }
 
 // Unlock allows using identity in following commands
@@ -520,6 +629,101 @@ func (client *Client) Sessions() (sessions contract.SessionListResponse, err err
 	return sessions, err
 }
 
+// SessionsActive returns ongoing provider sessions with their payment state
+func (client *Client) SessionsActive() (sessions contract.ActiveSessionListResponse, err error) {
+	response, err := client.http.Get("sessions/active", url.Values{})
+	if err != nil {
+		return sessions, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &sessions)
+	return sessions, err
+}
+
+// SessionsByConsumer returns session history of the given consumer matching the query
+func (client *Client) SessionsByConsumer(consumerID string, query url.Values) (sessions contract.ConsumerSessionsResponse, err error) {
+	response, err := client.http.Get("sessions/consumers/"+consumerID, query)
+	if err != nil {
+		return sessions, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &sessions)
+	return sessions, err
+}
+
+// SignAudit returns signing operations performed by the identity
+func (client *Client) SignAudit(identityAddress string, query url.Values) (records contract.SignAuditListResponse, err error) {
+	response, err := client.http.Get("identities/"+identityAddress+"/sign-audit", query)
+	if err != nil {
+		return records, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &records)
+	return records, err
+}
+
+// Favorites returns consumer curated providers
+func (client *Client) Favorites(query url.Values) (favorites contract.ProviderFavoritesDTO, err error) {
+	response, err := client.http.Get("favorites", query)
+	if err != nil {
+		return favorites, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &favorites)
+	return favorites, err
+}
+
+// SaveFavorite stores consumer curated details of the provider
+func (client *Client) SaveFavorite(providerID string, request contract.ProviderFavoriteRequest) (favorite contract.ProviderFavoriteDTO, err error) {
+	response, err := client.http.Put("favorites/"+providerID, request)
+	if err != nil {
+		return favorite, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &favorite)
+	return favorite, err
+}
+
+// DeleteFavorite removes consumer curated details of the provider
+func (client *Client) DeleteFavorite(providerID string) error {
+	response, err := client.http.Delete("favorites/"+providerID, nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// EstimateCost estimates the cost of a session with the provider
+func (client *Client) EstimateCost(request contract.CostEstimateRequest) (estimate contract.CostEstimateDTO, err error) {
+	response, err := client.http.Post("proposals/estimate", request)
+	if err != nil {
+		return estimate, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &estimate)
+	return estimate, err
+}
+
+// Features returns experimental features and their state on the node
+func (client *Client) Features() (features contract.FeatureListResponse, err error) {
+	response, err := client.http.Get("features", url.Values{})
+	if err != nil {
+		return features, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &features)
+	return features, err
+}
+
 // SessionsByServiceType returns sessions from history filtered by type
 func (client *Client) SessionsByServiceType(serviceType string) (contract.SessionListResponse, error) {
 	sessions, err := client.Sessions()
@@ -570,6 +774,30 @@ func (client *Client) ServiceStart(request contract.ServiceStartRequest) (servic
 	return service, err
 }
 
+// ServicePreview returns the proposal which would be published for the requested service.
+func (client *Client) ServicePreview(request contract.ServiceStartRequest) (proposal contract.ProposalDTO, err error) {
+	response, err := client.http.Post("services/preview", request)
+	if err != nil {
+		return proposal, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &proposal)
+	return proposal, err
+}
+
+// ServiceRestart applies new options to the running service instance by the requested id.
+func (client *Client) ServiceRestart(id string, request contract.ServiceRestartRequest) (service contract.ServiceInfoDTO, err error) {
+	response, err := client.http.Put(fmt.Sprintf("services/%s", id), request)
+	if err != nil {
+		return service, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &service)
+	return service, err
+}
+
 // ServiceStop stops the running service instance by the requested id.
 func (client *Client) ServiceStop(id string) error {
 	path := fmt.Sprintf("services/%s", id)
@@ -594,6 +822,18 @@ func (client *Client) NATStatus() (status contract.NodeStatusResponse, err error
 	return status, err
 }
 
+// NATTimeline returns the latest NAT traversal attempts
+func (client *Client) NATTimeline() (timeline contract.NATTimelineDTO, err error) {
+	response, err := client.http.Get("nat/timeline", nil)
+	if err != nil {
+		return timeline, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &timeline)
+	return timeline, err
+}
+
 // NATType returns type of NAT in sense of traversal capabilities
 func (client *Client) NATType() (status contract.NATTypeDTO, err error) {
 	response, err := client.http.Get("nat/type", nil)
@@ -821,6 +1061,36 @@ func (client *Client) MigrateHermesStatus(address string) (contract.MigrationSta
 	return res, err
 }
 
+// MigrateProviderHermes starts provider migration to the given hermes
+func (client *Client) MigrateProviderHermes(address, hermesID string) (contract.ProviderHermesMigrationResponse, error) {
+	var res contract.ProviderHermesMigrationResponse
+
+	response, err := client.http.Post(fmt.Sprintf("identities/%s/migrate-hermes/provider", address), contract.ProviderHermesMigrationRequest{HermesID: hermesID})
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+
+	return res, err
+}
+
+// ProviderHermesMigrationStatus returns state of the provider migration to another hermes
+func (client *Client) ProviderHermesMigrationStatus(address string) (contract.ProviderHermesMigrationResponse, error) {
+	var res contract.ProviderHermesMigrationResponse
+
+	response, err := client.http.Get(fmt.Sprintf("identities/%s/migrate-hermes/provider", address), nil)
+	if err != nil {
+		return res, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+
+	return res, err
+}
+
 // Beneficiary gets beneficiary address for the provided identity.
 func (client *Client) Beneficiary(address string) (res contract.IdentityBeneficiaryResponse, err error) {
 	response, err := client.http.Get("identities/"+address+"/beneficiary", nil)
@@ -844,12 +1114,47 @@ func (client *Client) SetMMNApiKey(data contract.MMNApiKeyRequest) error {
 	defer response.Body.Close()
 
 	if response.StatusCode != 200 {
-		return apierror.Parse(response)
+		return parseError(response)
 	}
 
 	return nil
 }
 
+// ReferralToken returns the referral token attached to registration and first session events.
+func (client *Client) ReferralToken() (contract.ReferralTokenResponse, error) {
+	response, err := client.http.Get("referral/token", nil)
+	if err != nil {
+		return contract.ReferralTokenResponse{}, err
+	}
+	defer response.Body.Close()
+
+	res := contract.ReferralTokenResponse{}
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// SetReferralToken sets the referral token attached to registration and first session events.
+func (client *Client) SetReferralToken(token string) error {
+	response, err := client.http.Put("referral/token", contract.ReferralTokenRequest{Token: token})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// ClearReferralToken removes the referral token.
+func (client *Client) ClearReferralToken() error {
+	response, err := client.http.Delete("referral/token", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
 // IdentityReferralCode returns a referral token for the given identity.
 func (client *Client) IdentityReferralCode(identity string) (contract.ReferralTokenResponse, error) {
 	response, err := client.http.Get(fmt.Sprintf("identities/%v/referral", identity), nil)
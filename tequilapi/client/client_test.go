@@ -18,12 +18,14 @@
 package client
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/core/node"
 
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
@@ -90,6 +92,43 @@ func TestConnectionErrorIsReturnedByClientInsteadOfDoubleParsing(t *testing.T) {
 	assert.True(t, responseBody.Closed)
 }
 
+func Test_ProposalsStream_DecodesNDJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/proposals", r.URL.Path)
+		assert.Equal(t, "ndjson", r.URL.Query().Get("format"))
+		assert.Equal(t, "wireguard", r.URL.Query().Get("service_type"))
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		w.Write([]byte(`{"provider_id":"0x1"}` + "\n" + `{"provider_id":"0x2"}` + "\n"))
+	}))
+	defer server.Close()
+	client := Client{http: newHTTPClient(server.URL, "")}
+
+	proposals, err := client.ProposalsByType("wireguard")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []contract.ProposalDTO{{ProviderID: "0x1"}, {ProviderID: "0x2"}}, proposals)
+}
+
+func Test_ProposalsStream_FallsBackToJSONList(t *testing.T) {
+	httpClient := mockHTTPClient(
+		t,
+		http.MethodGet,
+		"/proposals",
+		http.StatusOK,
+		`{"proposals": [{"provider_id":"0x1"}]}`,
+	)
+	client := Client{http: httpClient}
+
+	var providers []string
+	err := client.ProposalsStream(nil, func(p contract.ProposalDTO) error {
+		providers = append(providers, p.ProviderID)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0x1"}, providers)
+}
+
 func mockHTTPClient(t *testing.T, method, url string, statusCode int, response string) httpClientInterface {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, method, r.Method)
@@ -124,3 +163,24 @@ func (tc *trackingCloser) Close() error {
 }
 
 var _ io.ReadCloser = (*trackingCloser)(nil)
+
+func TestClientErrorIsCategorized(t *testing.T) {
+	client := Client{
+		http: &httpClient{
+			http: onAnyRequestReturn(&http.Response{
+				StatusCode: http.StatusNotFound,
+				Header:     http.Header{"Content-Type": []string{apierror.ContentTypeV1}},
+				Body:       io.NopCloser(strings.NewReader(`{"error": {"code": "err_no_connection_exists", "message": "No connection exists", "category": "not_found"}, "status": 404}`)),
+			}),
+			baseURL: "http://test-api-whatever",
+		},
+	}
+
+	err := client.ConnectionDestroy(0)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.False(t, errors.Is(err, ErrConnection))
+
+	var apiErr *apierror.APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, contract.ErrCodeNoConnectionExists, apiErr.Err.Code)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package client
+
+import (
+	"net/http"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/tequilapi/errcode"
+)
+
+// Errors matching the category of the TequilAPI error, to be used with errors.Is.
+var (
+	ErrValidation   = &categoryError{category: errcode.CategoryValidation}
+	ErrNotFound     = &categoryError{category: errcode.CategoryNotFound}
+	ErrUnauthorized = &categoryError{category: errcode.CategoryUnauthorized}
+	ErrUnavailable  = &categoryError{category: errcode.CategoryUnavailable}
+	ErrIdentity     = &categoryError{category: errcode.CategoryIdentity}
+	ErrConnection   = &categoryError{category: errcode.CategoryConnection}
+	ErrService      = &categoryError{category: errcode.CategoryService}
+	ErrPayment      = &categoryError{category: errcode.CategoryPayment}
+	ErrBlockchain   = &categoryError{category: errcode.CategoryBlockchain}
+	ErrNAT          = &categoryError{category: errcode.CategoryNAT}
+	ErrDiscovery    = &categoryError{category: errcode.CategoryDiscovery}
+	ErrSession      = &categoryError{category: errcode.CategorySession}
+	ErrNode         = &categoryError{category: errcode.CategoryNode}
+	ErrInternal     = &categoryError{category: errcode.CategoryInternal}
+)
+
+type categoryError struct {
+	category errcode.Category
+}
+
+func (e *categoryError) Error() string {
+	return string(e.category) + " error"
+}
+
+// Error represents an error response of TequilAPI along with the category of its code.
+type Error struct {
+	*apierror.APIError
+	Category errcode.Category
+}
+
+// Unwrap returns the underlying API error.
+func (e *Error) Unwrap() error {
+	return e.APIError
+}
+
+// Is reports whether the error belongs to the category of the given category error.
+func (e *Error) Is(target error) bool {
+	ce, ok := target.(*categoryError)
+	return ok && ce.category == e.Category
+}
+
+// parseError parses the error response into a categorized error, closing the response body.
+func parseError(response *http.Response) *Error {
+	apiErr := apierror.Parse(response)
+	return &Error{
+		APIError: apiErr,
+		Category: errcode.Of(apiErr.Err.Code),
+	}
+}
@@ -27,7 +27,6 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/requests"
@@ -129,7 +128,7 @@ func (client *httpClient) executeRequest(method, fullPath string, payloadJSON []
 
 func parseResponseError(response *http.Response) error {
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
-		return apierror.Parse(response)
+		return parseError(response)
 	}
 	return nil
 }
@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/accesslog"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+const (
+	// AccessLogFormatJSON exports the access log as paginated JSON.
+	AccessLogFormatJSON = "json"
+	// AccessLogFormatCSV exports the whole filtered access log as CSV.
+	AccessLogFormatCSV = "csv"
+)
+
+// NewAccessLogListQuery creates access log list query with default values.
+func NewAccessLogListQuery() AccessLogListQuery {
+	return AccessLogListQuery{
+		PaginationQuery: NewPaginationQuery(),
+		Format:          AccessLogFormatJSON,
+	}
+}
+
+// AccessLogListQuery allows to filter the listed consumer connections.
+// swagger:parameters accessLogList
+type AccessLogListQuery struct {
+	PaginationQuery
+
+	// Filter the connections from this date. Formatted in RFC3339 e.g. 2020-07-01.
+	// in: query
+	DateFrom *strfmt.Date `json:"date_from"`
+
+	// Filter the connections until this date. Formatted in RFC3339 e.g. 2020-07-30.
+	// in: query
+	DateTo *strfmt.Date `json:"date_to"`
+
+	// Consumer identity, anonymized the same way as the recorded connections.
+	// in: query
+	ConsumerID *string `json:"consumer_id"`
+
+	// Consumer country code.
+	// in: query
+	Country *string `json:"country"`
+
+	// Export format, either json (default) or csv.
+	// in: query
+	Format string `json:"format"`
+}
+
+// Bind creates and validates query from API request.
+func (q *AccessLogListQuery) Bind(request *http.Request) *apierror.APIError {
+	v := apierror.NewValidator()
+	if err := q.PaginationQuery.Bind(request); err != nil {
+		for field, fieldErr := range err.Err.Fields {
+			v.Fail(field, fieldErr.Code, fieldErr.Message)
+		}
+	}
+
+	qs := request.URL.Query()
+	if qStr := qs.Get("date_from"); qStr != "" {
+		if qVal, err := parseDate(qStr); err != nil {
+			v.Invalid("date_from", "Could not parse 'date_from'")
+		} else {
+			q.DateFrom = qVal
+		}
+	}
+	if qStr := qs.Get("date_to"); qStr != "" {
+		if qVal, err := parseDate(qStr); err != nil {
+			v.Invalid("date_to", "Could not parse 'date_to'")
+		} else {
+			q.DateTo = qVal
+		}
+	}
+	if qStr := qs.Get("consumer_id"); qStr != "" {
+		q.ConsumerID = &qStr
+	}
+	if qStr := qs.Get("country"); qStr != "" {
+		q.Country = &qStr
+	}
+	if qStr := qs.Get("format"); qStr != "" {
+		if qStr != AccessLogFormatJSON && qStr != AccessLogFormatCSV {
+			v.Invalid("format", "'format' must be one of: json, csv")
+		} else {
+			q.Format = qStr
+		}
+	}
+
+	return v.Err()
+}
+
+// ToFilter converts API query to the access log filter.
+func (q *AccessLogListQuery) ToFilter() accesslog.Filter {
+	var filter accesslog.Filter
+	if q.DateFrom != nil {
+		from := time.Time(*q.DateFrom).Truncate(24 * time.Hour)
+		filter.From = &from
+	}
+	if q.DateTo != nil {
+		to := time.Time(*q.DateTo).Truncate(24 * time.Hour).Add(24*time.Hour - time.Second)
+		filter.To = &to
+	}
+	if q.ConsumerID != nil {
+		filter.ConsumerID = *q.ConsumerID
+	}
+	if q.Country != nil {
+		filter.Country = *q.Country
+	}
+	return filter
+}
+
+// NewAccessLogListResponse maps to API access log list.
+func NewAccessLogListResponse(entries []accesslog.Entry, anonymization accesslog.Anonymization, paginator *utils.Paginator) AccessLogListResponse {
+	dtoArray := make([]AccessLogEntryDTO, len(entries))
+	for i, entry := range entries {
+		dtoArray[i] = AccessLogEntryDTO{
+			SessionID:     entry.SessionID,
+			Consumer:      entry.Consumer,
+			Country:       entry.Country,
+			ServiceType:   entry.ServiceType,
+			StartedAt:     entry.StartedAt.Format(time.RFC3339),
+			Duration:      uint64(entry.Duration.Seconds()),
+			BytesSent:     entry.BytesSent,
+			BytesReceived: entry.BytesReceived,
+		}
+	}
+
+	return AccessLogListResponse{
+		Anonymization: string(anonymization),
+		Items:         dtoArray,
+		PageableDTO:   NewPageableDTO(paginator),
+	}
+}
+
+// AccessLogListResponse defines consumer connection list representable as json.
+// swagger:model AccessLogListResponse
+type AccessLogListResponse struct {
+	// how consumer identities are recorded: none, hash or strict
+	// example: hash
+	Anonymization string              `json:"anonymization"`
+	Items         []AccessLogEntryDTO `json:"items"`
+	PageableDTO
+}
+
+// AccessLogEntryDTO represents a consumer connection served by the provider.
+// swagger:model AccessLogEntryDTO
+type AccessLogEntryDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// consumer identity or its keyed hash, empty if consumer identities are not recorded
+	// example: 8d1f7c3a9b2e4d6f0a1c3e5b7d9f1a3c
+	Consumer string `json:"consumer,omitempty"`
+
+	// example: LT
+	Country string `json:"country"`
+
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// example: 2022-10-01T12:00:00Z
+	StartedAt string `json:"started_at"`
+
+	// duration in seconds
+	// example: 3600
+	Duration uint64 `json:"duration"`
+
+	// example: 1048576
+	BytesSent uint64 `json:"bytes_sent"`
+
+	// example: 524288
+	BytesReceived uint64 `json:"bytes_received"`
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// NewAutoPricingResponse maps to API auto pricing state.
+func NewAutoPricingResponse(enabled bool, history []pingpong.PriceChange) AutoPricingResponse {
+	dtoArray := make([]PriceChangeDTO, len(history))
+	for i, change := range history {
+		dtoArray[i] = PriceChangeDTO{
+			NodeType:       change.NodeType,
+			Country:        change.Country,
+			ServiceType:    change.ServiceType,
+			Price:          newPriceDTO(change.Price),
+			NetworkAverage: newPriceDTO(change.NetworkAverage),
+			ChangedAt:      change.ChangedAt.Format(time.RFC3339),
+		}
+		if change.Previous != nil {
+			previous := newPriceDTO(*change.Previous)
+			dtoArray[i].Previous = &previous
+		}
+	}
+	return AutoPricingResponse{Enabled: enabled, History: dtoArray}
+}
+
+// AutoPricingResponse defines the auto pricing state representable as json.
+// swagger:model AutoPricingResponse
+type AutoPricingResponse struct {
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// changes of the announced prices, newest first
+	History []PriceChangeDTO `json:"history"`
+}
+
+// PriceChangeDTO represents a change of the price announced by the provider.
+// swagger:model PriceChangeDTO
+type PriceChangeDTO struct {
+	// example: residential
+	NodeType string `json:"node_type"`
+
+	// example: DE
+	Country string `json:"country"`
+
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// price before the change, omitted for the first price of the service
+	Previous *Price `json:"previous,omitempty"`
+
+	Price Price `json:"price"`
+
+	// average network price in the country the announced price was derived from
+	NetworkAverage Price `json:"network_average"`
+
+	// example: 2022-10-01T12:00:00Z
+	ChangedAt string `json:"changed_at"`
+}
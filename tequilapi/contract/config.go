@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import "github.com/mysteriumnetwork/node/config"
+
+// ConfigSchemaResponse describes all configuration options of the node.
+// swagger:model ConfigSchemaResponse
+type ConfigSchemaResponse struct {
+	Options []ConfigOptionDTO `json:"options"`
+}
+
+// NewConfigSchemaResponse maps configuration options to a response.
+func NewConfigSchemaResponse(options []config.Option) ConfigSchemaResponse {
+	res := ConfigSchemaResponse{Options: make([]ConfigOptionDTO, len(options))}
+	for i, o := range options {
+		res.Options[i] = ConfigOptionDTO{
+			Key:             o.Key,
+			Type:            string(o.Type),
+			Description:     o.Description,
+			Default:         o.Default,
+			Min:             o.Constraints.Min,
+			Max:             o.Constraints.Max,
+			Enum:            o.Constraints.Enum,
+			RestartRequired: o.RestartRequired,
+		}
+	}
+	return res
+}
+
+// ConfigOptionDTO describes a single configuration option.
+// swagger:model ConfigOptionDTO
+type ConfigOptionDTO struct {
+	// example: openvpn.port
+	Key string `json:"key"`
+	// one of: bool, int, int64, uint64, float64, duration, string, string_slice
+	// example: int
+	Type        string      `json:"type"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default"`
+	// example: 0
+	Min *float64 `json:"min,omitempty"`
+	// example: 65535
+	Max  *float64 `json:"max,omitempty"`
+	Enum []string `json:"enum,omitempty"`
+	// whether node has to be restarted for the changed value to take effect
+	// example: true
+	RestartRequired bool `json:"restart_required"`
+}
+
+// ConfigValidationResponse holds the result of configuration values validation.
+// swagger:model ConfigValidationResponse
+type ConfigValidationResponse struct {
+	Valid bool `json:"valid"`
+	// reasons of the rejected values by option key
+	// example: {"openvpn.port": "must be at most 65535"}
+	Errors map[string]string `json:"errors,omitempty"`
+}
@@ -19,15 +19,21 @@ package contract
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/blacklist"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/connection/diagnostics"
+	"github.com/mysteriumnetwork/node/core/connection/leaktest"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/core/speedtest"
 	"github.com/mysteriumnetwork/node/datasize"
+	node_session "github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
@@ -39,6 +45,10 @@ func NewConnectionInfoDTO(session connectionstate.Status) ConnectionInfoDTO {
 		Status:     string(session.State),
 		ConsumerID: session.ConsumerID.Address,
 		SessionID:  string(session.SessionID),
+		Paused:     session.Paused,
+	}
+	if session.DisconnectReason != node_session.TerminationReasonUnspecified {
+		response.DisconnectReason = session.DisconnectReason.String()
 	}
 	if session.HermesID != emptyAddress {
 		response.HermesID = session.HermesID.Hex()
@@ -68,6 +78,14 @@ type ConnectionInfoDTO struct {
 
 	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
 	SessionID string `json:"session_id,omitempty"`
+
+	// reason given by provider when it terminated the session
+	// example: payment_failure
+	DisconnectReason string `json:"disconnect_reason,omitempty"`
+
+	// true while the session is paused and not billed
+	// example: false
+	Paused bool `json:"paused,omitempty"`
 }
 
 // NewConnectionDTO maps to API connection.
@@ -103,6 +121,7 @@ func NewConnectionStatisticsDTO(session connectionstate.Status, statistics conne
 		ThroughputReceived: datasize.BitSize(throughput.Down).Bits(),
 		TokensSpent:        agreementTotal,
 		SpentTokens:        NewTokens(agreementTotal),
+		LastHandshakeAge:   int(statistics.LastHandshakeAge.Seconds()),
 	}
 }
 
@@ -131,6 +150,10 @@ type ConnectionStatisticsDTO struct {
 	TokensSpent *big.Int `json:"tokens_spent"`
 
 	SpentTokens Tokens `json:"spent_tokens"`
+
+	// seconds since the last tunnel handshake, omitted if the tunnel does not report it
+	// example: 30
+	LastHandshakeAge int `json:"last_handshake_age,omitempty"`
 }
 
 // ConnectionTrafficDTO holds consumer connection traffic information.
@@ -143,10 +166,242 @@ type ConnectionTrafficDTO struct {
 	BytesReceived uint64 `json:"bytes_received"`
 }
 
+// ConnectionPaymentDTO holds the payment engine state of the consumer connection.
+// swagger:model ConnectionPaymentDTO
+type ConnectionPaymentDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id,omitempty"`
+
+	// agreement total of the last invoice received from provider
+	LastInvoiceAmount Tokens `json:"last_invoice_amount"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	LastInvoiceAt string `json:"last_invoice_at,omitempty"`
+
+	// hashlock of the last invoice, promise for it is not redeemable until provider reveals its R
+	// example: 0x528b0f9fc7ce9d1d43f8ad4cd0a1f0a06f8d1fb0dc7d9a3f9f4c0dbcb8a1aa7c
+	Hashlock string `json:"hashlock,omitempty"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	LastPromiseAt string `json:"last_promise_at,omitempty"`
+
+	// agreement total covered by the last promise
+	AgreementTotal Tokens `json:"agreement_total"`
+
+	// cumulative amount promised to hermes by the consumer channel
+	TotalPromised Tokens `json:"total_promised"`
+
+	// reason the last invoice was not paid
+	// example: provider trying to overcharge
+	LastError string `json:"last_error,omitempty"`
+}
+
+// NewConnectionDiagnosticsDTO maps to API connection diagnostics report.
+func NewConnectionDiagnosticsDTO(report diagnostics.Report) ConnectionDiagnosticsDTO {
+	response := ConnectionDiagnosticsDTO{
+		Healthy: report.Healthy,
+		Checks:  make([]DiagnosticsCheckDTO, len(report.Checks)),
+	}
+	for i, check := range report.Checks {
+		response.Checks[i] = DiagnosticsCheckDTO{
+			Name:       check.Name,
+			OK:         check.OK,
+			Skipped:    check.Skipped,
+			DurationMs: check.Duration.Milliseconds(),
+			Details:    check.Details,
+			Error:      check.Error,
+		}
+	}
+	return response
+}
+
+// ConnectionDiagnosticsDTO holds the tunnel health report of consumer connection.
+// swagger:model ConnectionDiagnosticsDTO
+type ConnectionDiagnosticsDTO struct {
+	// false if any of the checks has failed
+	// example: true
+	Healthy bool `json:"healthy"`
+
+	Checks []DiagnosticsCheckDTO `json:"checks"`
+}
+
+// DiagnosticsCheckDTO holds the result of a single diagnostics check.
+// swagger:model DiagnosticsCheckDTO
+type DiagnosticsCheckDTO struct {
+	// example: tunnel_ping
+	Name string `json:"name"`
+
+	// example: true
+	OK bool `json:"ok"`
+
+	// example: false
+	Skipped bool `json:"skipped"`
+
+	// example: 42
+	DurationMs int64 `json:"duration_ms"`
+
+	// example: 10.182.0.1
+	Details string `json:"details,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// NewConnectionLeakTestDTO maps to API connection leak test report.
+func NewConnectionLeakTestDTO(report leaktest.Report) ConnectionLeakTestDTO {
+	response := ConnectionLeakTestDTO{
+		Leaked:    report.Leaked,
+		Checks:    make([]LeakTestCheckDTO, len(report.Checks)),
+		CheckedAt: report.CheckedAt.Format(time.RFC3339),
+	}
+	for i, check := range report.Checks {
+		response.Checks[i] = LeakTestCheckDTO{
+			Name:       check.Name,
+			Leaked:     check.Leaked,
+			Skipped:    check.Skipped,
+			DurationMs: check.Duration.Milliseconds(),
+			Details:    check.Details,
+			Error:      check.Error,
+		}
+	}
+	return response
+}
+
+// ConnectionLeakTestDTO holds the leak test report of consumer connection.
+// swagger:model ConnectionLeakTestDTO
+type ConnectionLeakTestDTO struct {
+	// true if any of the checks has detected traffic bypassing the tunnel
+	// example: false
+	Leaked bool `json:"leaked"`
+
+	Checks []LeakTestCheckDTO `json:"checks"`
+
+	// example: 2024-06-19T10:11:12Z
+	CheckedAt string `json:"checked_at"`
+}
+
+// NewConnectionSpeedtestDTO maps to API connection speedtest result.
+func NewConnectionSpeedtestDTO(result speedtest.Result) ConnectionSpeedtestDTO {
+	return ConnectionSpeedtestDTO{
+		Download:      result.Download,
+		Upload:        result.Upload,
+		LatencyMs:     result.Latency.Milliseconds(),
+		DurationMs:    result.Duration.Milliseconds(),
+		DownloadBytes: result.DownloadBytes,
+		UploadBytes:   result.UploadBytes,
+		StartedAt:     result.StartedAt.Format(time.RFC3339),
+	}
+}
+
+// ConnectionSpeedtestDTO holds the throughput of consumer connection measured against the provider.
+// swagger:model ConnectionSpeedtestDTO
+type ConnectionSpeedtestDTO struct {
+	// download throughput in bits per second
+	// example: 52428800
+	Download uint64 `json:"download"`
+
+	// upload throughput in bits per second
+	// example: 10485760
+	Upload uint64 `json:"upload"`
+
+	// time to establish the test connection through the tunnel
+	// example: 42
+	LatencyMs int64 `json:"latency_ms"`
+
+	// duration of each direction
+	// example: 5000
+	DurationMs int64 `json:"duration_ms"`
+
+	// example: 32768000
+	DownloadBytes uint64 `json:"download_bytes"`
+
+	// example: 6553600
+	UploadBytes uint64 `json:"upload_bytes"`
+
+	// example: 2024-06-19T10:11:12Z
+	StartedAt string `json:"started_at"`
+}
+
+// LeakTestCheckDTO holds the result of a single leak check.
+// swagger:model LeakTestCheckDTO
+type LeakTestCheckDTO struct {
+	// example: dns
+	Name string `json:"name"`
+
+	// example: false
+	Leaked bool `json:"leaked"`
+
+	// example: false
+	Skipped bool `json:"skipped"`
+
+	// example: 42
+	DurationMs int64 `json:"duration_ms"`
+
+	// example: DNS queries are sent from 10.182.0.1
+	Details string `json:"details,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// ConnectionConfigExportDTO holds the negotiated tunnel configuration of consumer connection.
+// swagger:model ConnectionConfigExportDTO
+type ConnectionConfigExportDTO struct {
+	// configuration in wg-quick format, private key is redacted unless requested explicitly
+	// example: [Interface]\nPrivateKey = <redacted>\nAddress = 10.182.0.2/24\n...
+	Config string `json:"config"`
+}
+
+// NewProviderBlacklistDTO maps to API provider blacklist.
+func NewProviderBlacklistDTO(entries []blacklist.Entry) ProviderBlacklistDTO {
+	response := ProviderBlacklistDTO{
+		Entries: make([]ProviderBlacklistEntryDTO, len(entries)),
+	}
+	for i, e := range entries {
+		response.Entries[i] = ProviderBlacklistEntryDTO{
+			ProviderID:  e.ProviderID,
+			Score:       e.Score,
+			Failures:    e.Failures,
+			LastFailure: e.LastFailure.UTC().Format(time.RFC3339),
+			LastReason:  string(e.LastReason),
+			Blacklisted: e.Blacklisted,
+		}
+	}
+	return response
+}
+
+// ProviderBlacklistDTO holds the providers with recent connection failures.
+// swagger:model ProviderBlacklistDTO
+type ProviderBlacklistDTO struct {
+	Entries []ProviderBlacklistEntryDTO `json:"entries"`
+}
+
+// ProviderBlacklistEntryDTO holds the failure record of a single provider.
+// swagger:model ProviderBlacklistEntryDTO
+type ProviderBlacklistEntryDTO struct {
+	// example: 0x0000000000000000000000000000000000000002
+	ProviderID string `json:"provider_id"`
+
+	// failure score, decays over time
+	// example: 2.5
+	Score float64 `json:"score"`
+
+	// example: 3
+	Failures int `json:"failures"`
+
+	// example: 2022-01-02T15:04:05Z
+	LastFailure string `json:"last_failure"`
+
+	// example: connection_failed
+	LastReason string `json:"last_reason"`
+
+	// true if provider is excluded from auto-selection
+	// example: true
+	Blacklisted bool `json:"blacklisted"`
+}
+
 // ConnectionCreateRequest request used to start a connection.
 // swagger:model ConnectionCreateRequestDTO
 type ConnectionCreateRequest struct {
-	// consumer identity
+	// consumer identity, defaults to the wallet spending identity
 	// required: true
 	// example: 0x0000000000000000000000000000000000000001
 	ConsumerID string `json:"consumer_id"`
@@ -168,6 +423,16 @@ type ConnectionCreateRequest struct {
 	// example: openvpn
 	ServiceType string `json:"service_type"`
 
+	// register unregistered consumer identity paying the fee from its balance and wait for confirmation before connecting
+	// required: false
+	// example: false
+	AutoRegister bool `json:"auto_register,omitempty"`
+
+	// connect with a new throwaway identity created and funded from the consumer identity, defaults to the node configuration
+	// required: false
+	// example: true
+	EphemeralIdentity *bool `json:"ephemeral_identity,omitempty"`
+
 	// connect options
 	// required: false
 	ConnectOptions ConnectOptions `json:"connect_options,omitempty"`
@@ -218,4 +483,18 @@ type ConnectOptions struct {
 	DNS connection.DNSOption `json:"dns"`
 
 	ProxyPort int `json:"proxy_port"`
+
+	// consumer attribute token for providers requiring attestation
+	// required: false
+	AttestationToken string `json:"attestation_token,omitempty"`
+
+	// how often in seconds consumer asks to be charged, provider picks one within its bounds
+	// required: false
+	// example: 60
+	ChargePeriod uint32 `json:"charge_period,omitempty"`
+
+	// bandwidth tier advertised in proposal, empty uses the proposal price without limits
+	// required: false
+	// example: basic
+	BandwidthTier string `json:"bandwidth_tier,omitempty"`
 }
@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// CostEstimateRequest describes the session planned with a provider.
+// swagger:model CostEstimateRequestDTO
+type CostEstimateRequest struct {
+	// provider identity
+	// required: true
+	// example: 0x0000000000000000000000000000000000000002
+	ProviderID string `json:"provider_id"`
+
+	// service type, "wireguard" if not given
+	// example: wireguard
+	ServiceType string `json:"service_type,omitempty"`
+
+	// planned duration of the session in seconds
+	// example: 3600
+	DurationSeconds uint64 `json:"duration_seconds"`
+
+	// planned amount of data transferred during the session in bytes
+	// example: 1073741824
+	DataBytes uint64 `json:"data_bytes"`
+
+	// bandwidth tier of the proposal to estimate, base price is used if not given
+	// example: premium
+	BandwidthTier string `json:"bandwidth_tier,omitempty"`
+}
+
+// Validate validates fields in request.
+func (r CostEstimateRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if r.ProviderID == "" {
+		v.Required("provider_id")
+	}
+	if r.DurationSeconds == 0 && r.DataBytes == 0 {
+		v.Invalid("duration_seconds", "Either duration or data should be given")
+	}
+	return v.Err()
+}
+
+// CostEstimateDTO is the expected cost of a session for the consumer.
+// swagger:model CostEstimateDTO
+type CostEstimateDTO struct {
+	Service             *big.Int `json:"service"`
+	ServiceTokens       Tokens   `json:"service_tokens"`
+	HermesFee           *big.Int `json:"hermes_fee"`
+	HermesFeeTokens     Tokens   `json:"hermes_fee_tokens"`
+	HermesPercent       string   `json:"hermes_percent"`
+	TransactorFee       *big.Int `json:"transactor_fee"`
+	TransactorFeeTokens Tokens   `json:"transactor_fee_tokens"`
+	Total               *big.Int `json:"total"`
+	TotalTokens         Tokens   `json:"total_tokens"`
+	// service amount and hermes fee scaled to an hour of the planned session, missing if the duration was not given.
+	// The transactor fee is paid once and is not included.
+	PerHourTokens *Tokens `json:"per_hour_tokens,omitempty"`
+}
+
+// NewCostEstimateDTO maps to API cost estimate.
+func NewCostEstimateDTO(estimate pingpong.SessionCostEstimate, hermesPercent string) CostEstimateDTO {
+	dto := CostEstimateDTO{
+		Service:             estimate.Service,
+		ServiceTokens:       NewTokens(estimate.Service),
+		HermesFee:           estimate.HermesFee,
+		HermesFeeTokens:     NewTokens(estimate.HermesFee),
+		HermesPercent:       hermesPercent,
+		TransactorFee:       estimate.TransactorFee,
+		TransactorFeeTokens: NewTokens(estimate.TransactorFee),
+		Total:               estimate.Total,
+		TotalTokens:         NewTokens(estimate.Total),
+	}
+	if estimate.PerHour != nil {
+		perHour := NewTokens(estimate.PerHour)
+		dto.PerHourTokens = &perHour
+	}
+	return dto
+}
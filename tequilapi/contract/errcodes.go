@@ -19,6 +19,7 @@ package contract
 
 // Err codes returned from TequilAPI.
 // Once created, do not change the string value, because consumers may depend on it - it's part of the contract.
+// Every new code should also be assigned a category in the tequilapi/errcode package.
 const (
 
 	// Identity
@@ -34,11 +35,18 @@ const (
 	ErrCodeIDRegistrationCheck           = "err_id_registration_status_check"
 	ErrCodeIDBlockchainRegistrationCheck = "err_id_registration_blockchain_status_check"
 	ErrCodeIDRegistrationInProgress      = "err_id_registration_in_progress"
+	ErrCodeIDRegistrationBalance         = "err_id_registration_insufficient_balance"
+	ErrCodeIDRegistrationPending         = "err_id_registration_pending"
+	ErrCodeIDRegistrationBump            = "err_id_registration_bump"
+	ErrCodeIDRegistrationCancel          = "err_id_registration_cancel"
 	ErrCodeIDCalculateAddress            = "err_id_calculate_address"
 	ErrCodeIDSavePayoutAddress           = "err_id_save_payout_invalid_address"
 	ErrCodeIDGetPayoutAddress            = "err_id_get_payout_address"
 	ErrCodeHermesMigration               = "err_id_check_hermes_migration"
 	ErrCodeCheckHermesMigrationStatus    = "err_id_check_hermes_migration_status"
+	ErrCodeProviderHermesMigration       = "err_id_provider_hermes_migration"
+	ErrCodeIDSignAuditList               = "err_id_sign_audit_list"
+	ErrCodeIDSignAuditPaginate           = "err_id_sign_audit_paginate"
 
 	// Payment
 
@@ -57,15 +65,23 @@ const (
 
 	// Config
 
-	ErrCodeConfigSave = "err_config_save"
+	ErrCodeConfigSave    = "err_config_save"
+	ErrCodeConfigCLIOnly = "err_config_cli_only"
 
 	// Connection
 
-	ErrCodeConnectionAlreadyExists = "err_connection_already_exists"
-	ErrCodeConnectionCancelled     = "err_connection_cancelled"
-	ErrCodeConnect                 = "err_connect"
-	ErrCodeNoConnectionExists      = "err_no_connection_exists"
-	ErrCodeDisconnect              = "err_disconnect"
+	ErrCodeConnectionAlreadyExists  = "err_connection_already_exists"
+	ErrCodeConnectionCancelled      = "err_connection_cancelled"
+	ErrCodeConnect                  = "err_connect"
+	ErrCodeNoConnectionExists       = "err_no_connection_exists"
+	ErrCodeDisconnect               = "err_disconnect"
+	ErrCodeConnectionDiagnostics    = "err_connection_diagnostics"
+	ErrCodeConnectionConfigExport   = "err_connection_config_export"
+	ErrCodeConnectionLeakTest       = "err_connection_leak_test"
+	ErrCodeConnectionSpeedtest      = "err_connection_speedtest"
+	ErrCodeEphemeralIdentity        = "err_ephemeral_identity"
+	ErrCodeEphemeralIdentityPending = "err_ephemeral_identity_pending"
+	ErrCodeConnectionPause          = "err_connection_pause"
 
 	// Feedback
 
@@ -89,6 +105,20 @@ const (
 	ErrCodeProposalsPrices         = "err_proposals_prices"
 	ErrCodeProposalsPresets        = "err_proposals_presets"
 	ErrCodeProposalsServiceType    = "err_proposals_service_type"
+	ErrCodeProposalsFavorites      = "err_proposals_favorites"
+	ErrCodeProposalsBandwidthTier  = "err_proposals_bandwidth_tier"
+
+	// Favorites
+
+	ErrCodeFavoritesList   = "err_favorites_list"
+	ErrCodeFavoritesGet    = "err_favorites_get"
+	ErrCodeFavoritesSave   = "err_favorites_save"
+	ErrCodeFavoritesDelete = "err_favorites_delete"
+
+	// Wallet
+
+	ErrCodeWalletSummary          = "err_wallet_summary"
+	ErrCodeWalletSpendingIdentity = "err_wallet_spending_identity"
 
 	// Service
 
@@ -98,13 +128,24 @@ const (
 	ErrCodeServiceLocation = "err_service_location"
 	ErrCodeServiceStart    = "err_service_start"
 	ErrCodeServiceStop     = "err_service_stop"
+	ErrCodeServiceRestart  = "err_service_restart"
+	ErrCodeServicePreview  = "err_service_preview"
 
 	// Sessions
 
-	ErrCodeSessionList         = "err_session_list"
-	ErrCodeSessionListPaginate = "err_session_list_paginate"
-	ErrCodeSessionStats        = "err_session_stats"
-	ErrCodeSessionStatsDaily   = "err_session_stats_daily"
+	ErrCodeSessionList          = "err_session_list"
+	ErrCodeSessionListPaginate  = "err_session_list_paginate"
+	ErrCodeSessionStats         = "err_session_stats"
+	ErrCodeSessionStatsDaily    = "err_session_stats_daily"
+	ErrCodeSessionTerminate     = "err_session_terminate"
+	ErrCodeSessionPacketCapture = "err_session_packet_capture"
+	ErrCodeAccessLogList        = "err_access_log_list"
+	ErrCodeAccessLogPaginate    = "err_access_log_paginate"
+	ErrCodeAccessLogExport      = "err_access_log_export"
+
+	// Reports
+
+	ErrCodeReportStatement = "err_report_statement"
 
 	// Transactor
 
@@ -114,6 +155,7 @@ const (
 	ErrCodeTransactorSettleHistory         = "err_transactor_settle_history"
 	ErrCodeTransactorSettleHistoryPaginate = "err_transactor_settle_history_paginate"
 	ErrCodeTransactorWithdraw              = "err_transactor_withdraw"
+	ErrCodeTransactorWithdrawalJob         = "err_transactor_withdrawal_job"
 	ErrCodeTransactorSettle                = "err_transactor_settle_into_stake"
 	ErrCodeTransactorSettleAsync           = "err_transactor_settle_into_stake_async"
 	ErrCodeTransactorNoReward              = "err_transactor_no_reward"
@@ -131,6 +173,8 @@ const (
 	ErrCodeHermesFee                       = "err_hermes_fee"
 	ErrCodeHermesSettle                    = "err_hermes_settle"
 	ErrCodeHermesSettleAsync               = "err_hermes_settle_async"
+	ErrCodeHermesSettleBatch               = "err_hermes_settle_batch"
+	ErrCodeHermesPromiseList               = "err_hermes_promise_list"
 	ErrCodeUILocalVersions                 = "err_ui_local_versions"
 	ErrCodeUISwitchVersion                 = "err_ui_switch_version"
 	ErrCodeUIDownload                      = "err_ui_download"
@@ -144,7 +188,9 @@ const (
 	ErrorCodeProviderSessionsSeries        = "err_provider_sessions_series"
 	ErrorCodeProviderTransferredDataSeries = "err_provider_transferred_data_series"
 	ErrorCodeProviderQuality               = "err_provider_quality"
+	ErrorCodeProviderReputation            = "err_provider_reputation"
 	ErrorCodeProviderActivityStats         = "err_provider_activity_stats"
 	ErrorCodeLatestReleaseInformation      = "err_latest_release_information"
 	ErrorCodeProviderServiceEarnings       = "err_provider_service_earnings"
+	ErrCodeNodeAttestationKey              = "err_node_attestation_key"
 )
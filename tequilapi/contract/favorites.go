@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/consumer/favorites"
+)
+
+const (
+	favoriteNoteMaxLength  = 1000
+	favoriteLabelsMax      = 20
+	favoriteLabelMaxLength = 50
+)
+
+// ProviderFavoriteRequest holds consumer curated details of a provider.
+// swagger:model ProviderFavoriteRequestDTO
+type ProviderFavoriteRequest struct {
+	// example: true
+	Favorite bool `json:"favorite"`
+
+	// personal note about the provider
+	// example: fast in the evenings
+	Note string `json:"note,omitempty"`
+
+	// custom labels grouping providers
+	// example: ["streaming","work"]
+	Labels []string `json:"labels,omitempty"`
+}
+
+// Validate validates fields in request.
+func (r ProviderFavoriteRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if len(r.Note) > favoriteNoteMaxLength {
+		v.Invalid("note", "Should be at most 1000 characters long")
+	}
+	if len(r.Labels) > favoriteLabelsMax {
+		v.Invalid("labels", "Should contain at most 20 labels")
+	}
+	for _, label := range r.Labels {
+		if len(label) > favoriteLabelMaxLength {
+			v.Invalid("labels", "Each label should be at most 50 characters long")
+			break
+		}
+	}
+	return v.Err()
+}
+
+// ToEntry converts API request to the curated details of the given provider.
+func (r ProviderFavoriteRequest) ToEntry(providerID string) favorites.Entry {
+	return favorites.Entry{
+		ProviderID: providerID,
+		Favorite:   r.Favorite,
+		Note:       r.Note,
+		Labels:     r.Labels,
+	}
+}
+
+// NewProviderFavoriteDTO maps to API provider favorite.
+func NewProviderFavoriteDTO(e favorites.Entry) ProviderFavoriteDTO {
+	return ProviderFavoriteDTO{
+		ProviderID: e.ProviderID,
+		Favorite:   e.Favorite,
+		Note:       e.Note,
+		Labels:     e.Labels,
+		UpdatedAt:  e.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// NewProviderFavoritesDTO maps to API provider favorites list.
+func NewProviderFavoritesDTO(entries []favorites.Entry) ProviderFavoritesDTO {
+	response := ProviderFavoritesDTO{
+		Entries: make([]ProviderFavoriteDTO, len(entries)),
+	}
+	for i, e := range entries {
+		response.Entries[i] = NewProviderFavoriteDTO(e)
+	}
+	return response
+}
+
+// ProviderFavoritesDTO holds the consumer curated providers.
+// swagger:model ProviderFavoritesDTO
+type ProviderFavoritesDTO struct {
+	Entries []ProviderFavoriteDTO `json:"entries"`
+}
+
+// ProviderFavoriteDTO holds consumer curated details of a single provider.
+// swagger:model ProviderFavoriteDTO
+type ProviderFavoriteDTO struct {
+	// example: 0x0000000000000000000000000000000000000002
+	ProviderID string `json:"provider_id"`
+
+	// example: true
+	Favorite bool `json:"favorite"`
+
+	// example: fast in the evenings
+	Note string `json:"note,omitempty"`
+
+	// example: ["streaming","work"]
+	Labels []string `json:"labels,omitempty"`
+
+	// example: 2022-01-02T15:04:05Z
+	UpdatedAt string `json:"updated_at"`
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import "github.com/mysteriumnetwork/node/core/feature"
+
+// FeatureListResponse defines experimental features and their state on the node.
+// swagger:model FeatureListResponse
+type FeatureListResponse struct {
+	Features []FeatureDTO `json:"features"`
+}
+
+// NewFeatureListResponse maps feature states to a response.
+func NewFeatureListResponse(features []feature.Status) FeatureListResponse {
+	res := FeatureListResponse{Features: make([]FeatureDTO, len(features))}
+	for i, f := range features {
+		res.Features[i] = FeatureDTO{
+			Name:        f.Name,
+			Description: f.Description,
+			Enabled:     f.Enabled,
+			Source:      string(f.Source),
+			Rollout:     f.Rollout,
+		}
+	}
+	return res
+}
+
+// FeatureDTO represents the state of an experimental feature.
+// swagger:model FeatureDTO
+type FeatureDTO struct {
+	// example: payment-aggregation
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	// where the feature state comes from: default, remote or config
+	// example: remote
+	Source string `json:"source"`
+	// percentage of nodes the feature is rolled out to remotely
+	// example: 25
+	Rollout int `json:"rollout,omitempty"`
+}
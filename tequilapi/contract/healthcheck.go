@@ -17,6 +17,12 @@
 
 package contract
 
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/supervision"
+)
+
 // HealthCheckDTO holds API healthcheck.
 // swagger:model HealthCheckDTO
 type HealthCheckDTO struct {
@@ -29,6 +35,43 @@ type HealthCheckDTO struct {
 	// example: 0.0.6
 	Version   string       `json:"version"`
 	BuildInfo BuildInfoDTO `json:"build_info"`
+
+	// WireGuard implementation used for tunnels, empty until first tunnel is started
+	// example: kernel
+	WireguardBackend string `json:"wireguard_backend,omitempty"`
+
+	// Subsystems which recently recovered from a panic, empty if the node is healthy
+	DegradedSubsystems []DegradedSubsystemDTO `json:"degraded_subsystems,omitempty"`
+}
+
+// DegradedSubsystemDTO holds the panics recovered in the node subsystem.
+// swagger:model DegradedSubsystemDTO
+type DegradedSubsystemDTO struct {
+	// example: payments
+	Name string `json:"name"`
+	// example: 1
+	Panics int `json:"panics"`
+	// example: runtime error: invalid memory address or nil pointer dereference
+	LastPanic   string `json:"last_panic"`
+	LastPanicAt string `json:"last_panic_at"`
+}
+
+// NewDegradedSubsystemsDTO maps to API degraded subsystems.
+func NewDegradedSubsystemsDTO(subsystems []supervision.SubsystemStatus) []DegradedSubsystemDTO {
+	if len(subsystems) == 0 {
+		return nil
+	}
+
+	result := make([]DegradedSubsystemDTO, len(subsystems))
+	for i, s := range subsystems {
+		result[i] = DegradedSubsystemDTO{
+			Name:        s.Name,
+			Panics:      s.Panics,
+			LastPanic:   s.LastPanic,
+			LastPanicAt: s.LastPanicAt.UTC().Format(time.RFC3339),
+		}
+	}
+	return result
 }
 
 // BuildInfoDTO holds info about build.
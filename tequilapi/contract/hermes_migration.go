@@ -17,6 +17,12 @@
 
 package contract
 
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/migration"
+)
+
 // MigrationStatus status of the migration
 type MigrationStatus = string
 
@@ -32,3 +38,33 @@ const (
 type MigrationStatusResponse struct {
 	Status MigrationStatus `json:"status"`
 }
+
+// ProviderHermesMigrationRequest request to migrate provider to another hermes
+// swagger:model ProviderHermesMigrationRequest
+type ProviderHermesMigrationRequest struct {
+	// example: 0x80ed28d84792d8b153bf2f25f0c4b7a1381de4ab
+	HermesID string `json:"hermes_id"`
+}
+
+// ProviderHermesMigrationResponse represents state of the provider migration between hermeses
+// swagger:model ProviderHermesMigrationResponse
+type ProviderHermesMigrationResponse struct {
+	OldHermesID string `json:"old_hermes_id"`
+	NewHermesID string `json:"new_hermes_id"`
+	// Step which is currently in progress: settle, stake, verify, switch or done.
+	Step  string `json:"step"`
+	Error string `json:"error,omitempty"`
+	// example: 2022-01-02T15:04:05Z
+	UpdatedAt string `json:"updated_at"`
+}
+
+// NewProviderHermesMigrationResponse maps provider migration state to response.
+func NewProviderHermesMigrationResponse(pm migration.ProviderMigration) ProviderHermesMigrationResponse {
+	return ProviderHermesMigrationResponse{
+		OldHermesID: pm.OldHermes.Hex(),
+		NewHermesID: pm.NewHermes.Hex(),
+		Step:        string(pm.Step),
+		Error:       pm.Error,
+		UpdatedAt:   pm.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -19,10 +19,12 @@ package contract
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
 	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
 )
 
@@ -87,6 +89,20 @@ func NewEarningsPerHermesDTO(earnings map[common.Address]pingpong_event.Earnings
 	return settlementsPerHermes
 }
 
+// IdentityStatusDTO holds identity information or the error which prevented resolving it.
+// swagger:model IdentityStatusDTO
+type IdentityStatusDTO struct {
+	IdentityDTO
+	// example: Failed to check ID registration status
+	Error string `json:"error,omitempty"`
+}
+
+// ListIdentityStatusesResponse holds details of all identities.
+// swagger:model ListIdentityStatusesResponse
+type ListIdentityStatusesResponse struct {
+	Identities []IdentityStatusDTO `json:"identities"`
+}
+
 // NewIdentityDTO maps to API identity.
 func NewIdentityDTO(id identity.Identity) IdentityRefDTO {
 	return IdentityRefDTO{Address: id.Address}
@@ -174,6 +190,67 @@ type IdentityRegistrationResponse struct {
 	Registered bool `json:"registered"`
 }
 
+// NewPendingRegistrationDTO maps to API pending registration.
+func NewPendingRegistrationDTO(pending registry.PendingRegistration) PendingRegistrationDTO {
+	dto := PendingRegistrationDTO{
+		Status:           pending.Status.String(),
+		TransactorStatus: string(pending.TransactorStatus),
+		TxHash:           pending.TxHash,
+		Fee:              pending.Fee,
+		Stuck:            pending.Stuck,
+	}
+	if !pending.CreatedAt.IsZero() {
+		dto.CreatedAt = pending.CreatedAt.Format(time.RFC3339)
+	}
+	if !pending.UpdatedAt.IsZero() {
+		dto.UpdatedAt = pending.UpdatedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// PendingRegistrationDTO represents a registration which is not confirmed yet.
+// swagger:model PendingRegistrationDTO
+type PendingRegistrationDTO struct {
+	// example: InProgress
+	Status string `json:"status"`
+
+	// status reported by transactor: created, priceIncreased, failed or succeed
+	// example: created
+	TransactorStatus string `json:"transactor_status"`
+
+	// example: 0xb6b5f8e8c8c2e0a8e8c5d8c6d8b6f8e8c8c2e0a8e8c5d8c6d8b6f8e8c8c2e0a8
+	TxHash string `json:"tx_hash"`
+
+	// example: 100000000000000000
+	Fee *big.Int `json:"fee"`
+
+	// example: 2019-06-06T11:04:43Z
+	CreatedAt string `json:"created_at,omitempty"`
+
+	// example: 2019-06-06T11:04:43Z
+	UpdatedAt string `json:"updated_at,omitempty"`
+
+	// whether the registration was not confirmed in time and can be re-submitted with a higher fee or cancelled
+	// example: false
+	Stuck bool `json:"stuck"`
+}
+
+// RegistrationFeeBumpRequest represents the request to re-submit a stuck registration with a higher fee.
+// swagger:model RegistrationFeeBumpRequestDTO
+type RegistrationFeeBumpRequest struct {
+	// example: 200000000000000000
+	Fee *big.Int `json:"fee"`
+}
+
+// Validate validates the fee bump request.
+func (r RegistrationFeeBumpRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if r.Fee == nil || r.Fee.Sign() <= 0 {
+		v.Invalid("fee", "'fee' should be a positive integer")
+	}
+	return v.Err()
+}
+
 // IdentityBeneficiaryResponse represents the provider beneficiary address.
 // swagger:model IdentityBeneficiaryResponseDTO
 type IdentityBeneficiaryResponse struct {
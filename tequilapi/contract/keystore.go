@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// KeystoreHealthDTO is the result of the keystore directory check done on startup.
+// swagger:model KeystoreHealthDTO
+type KeystoreHealthDTO struct {
+	// example: true
+	Healthy bool `json:"healthy"`
+
+	// example: 2022-06-01T10:00:00Z
+	CheckedAt time.Time `json:"checked_at"`
+
+	// addresses of usable keys
+	// example: ["0x000000000000000000000000000000000000000a"]
+	Keys []string `json:"keys"`
+
+	// files which can not be used
+	Issues []KeyFileIssueDTO `json:"issues"`
+
+	// true if the remembered identity index was reset and will be created again
+	// example: false
+	IndexRepaired bool `json:"index_repaired"`
+}
+
+// KeyFileIssueDTO describes a keystore file which can not be used.
+// swagger:model KeyFileIssueDTO
+type KeyFileIssueDTO struct {
+	// example: UTC--2022-06-01T10-00-00.000000000Z--000000000000000000000000000000000000000a
+	File string `json:"file"`
+
+	// example: 0x000000000000000000000000000000000000000a
+	Address string `json:"address,omitempty"`
+
+	// example: one of 2 key files for the same address
+	Problem string `json:"problem"`
+
+	// path the file was moved to, missing if the file was left in place
+	Quarantined string `json:"quarantined,omitempty"`
+}
+
+// NewKeystoreHealthDTO maps to API keystore health.
+func NewKeystoreHealthDTO(r identity.KeystoreHealthReport) KeystoreHealthDTO {
+	dto := KeystoreHealthDTO{
+		Healthy:       r.Healthy(),
+		CheckedAt:     r.CheckedAt,
+		Keys:          r.Keys,
+		Issues:        make([]KeyFileIssueDTO, len(r.Issues)),
+		IndexRepaired: r.IndexRepaired,
+	}
+	if dto.Keys == nil {
+		dto.Keys = []string{}
+	}
+	for i, issue := range r.Issues {
+		dto.Issues[i] = KeyFileIssueDTO{
+			File:        issue.File,
+			Address:     issue.Address,
+			Problem:     issue.Problem,
+			Quarantined: issue.Quarantined,
+		}
+	}
+	return dto
+}
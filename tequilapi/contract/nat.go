@@ -27,3 +27,29 @@ type NATTypeDTO struct {
 	Type  nat.NATType `json:"type"`
 	Error string      `json:"error,omitempty"`
 }
+
+// NATTimelineDTO holds the latest NAT traversal attempts
+// swagger:model NATTimelineDTO
+type NATTimelineDTO struct {
+	Traversals []NATTraversalDTO `json:"traversals"`
+}
+
+// NATTraversalDTO holds the stages of a single NAT traversal attempt
+// swagger:model NATTraversalDTO
+type NATTraversalDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	ID     string                 `json:"id"`
+	Stages []NATTraversalStageDTO `json:"stages"`
+}
+
+// NATTraversalStageDTO holds the outcome of a single NAT traversal stage
+// swagger:model NATTraversalStageDTO
+type NATTraversalStageDTO struct {
+	// example: hole_punching
+	Stage      string `json:"stage"`
+	Successful bool   `json:"successful"`
+	// example: too few connections were made
+	Error string `json:"error,omitempty"`
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+}
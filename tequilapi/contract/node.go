@@ -18,6 +18,7 @@
 package contract
 
 import (
+	"math"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -121,6 +122,86 @@ type QualityInfoResponse struct {
 	Quality float64 `json:"quality"`
 }
 
+// NewProviderReputationResponse maps quality oracle reputation to the API response.
+func NewProviderReputationResponse(r node.Reputation) ProviderReputationResponse {
+	res := ProviderReputationResponse{
+		Quality:               r.Quality,
+		Rank:                  r.Rank,
+		ProvidersCount:        r.ProvidersCount,
+		TopPercent:            topPercent(r.Rank, r.ProvidersCount),
+		Country:               r.Country,
+		CountryRank:           r.CountryRank,
+		CountryProvidersCount: r.CountryProvidersCount,
+		CountryTopPercent:     topPercent(r.CountryRank, r.CountryProvidersCount),
+		Services:              []ServiceReputationDTO{},
+	}
+	if r.UpdatedAt > 0 {
+		res.UpdatedAt = time.Unix(r.UpdatedAt, 0).UTC().Format(time.RFC3339)
+	}
+	for _, s := range r.Services {
+		res.Services = append(res.Services, ServiceReputationDTO{
+			ServiceType:      s.ServiceType,
+			Quality:          s.Quality,
+			Latency:          s.Latency,
+			Bandwidth:        s.Bandwidth,
+			Uptime:           s.Uptime,
+			MonitoringFailed: s.MonitoringFailed,
+		})
+	}
+	return res
+}
+
+func topPercent(rank, count int) float64 {
+	if rank <= 0 || count <= 0 {
+		return 0
+	}
+	return math.Round(float64(rank)/float64(count)*10000) / 100
+}
+
+// ProviderReputationResponse reflects provider standing among the other providers as seen by quality oracle.
+// swagger:model ProviderReputationResponse
+type ProviderReputationResponse struct {
+	// example: 2.5
+	Quality float64 `json:"quality"`
+	// position among all providers, zero if node is not ranked yet
+	// example: 120
+	Rank int `json:"rank"`
+	// example: 4000
+	ProvidersCount int `json:"providers_count"`
+	// example: 3
+	TopPercent float64 `json:"top_percent"`
+	// example: DE
+	Country string `json:"country"`
+	// example: 12
+	CountryRank int `json:"country_rank"`
+	// example: 300
+	CountryProvidersCount int `json:"country_providers_count"`
+	// example: 4
+	CountryTopPercent float64                `json:"country_top_percent"`
+	Services          []ServiceReputationDTO `json:"services"`
+	// example: 2022-06-01T12:00:00Z
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// ServiceReputationDTO reflects quality oracle metrics of a single service provided by the node.
+// swagger:model ServiceReputationDTO
+type ServiceReputationDTO struct {
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+	// example: 2.5
+	Quality float64 `json:"quality"`
+	// latency in milliseconds
+	// example: 85.3
+	Latency float64 `json:"latency"`
+	// bandwidth in Mbps
+	// example: 45.2
+	Bandwidth float64 `json:"bandwidth"`
+	// example: 0.98
+	Uptime float64 `json:"uptime"`
+	// example: false
+	MonitoringFailed bool `json:"monitoring_failed"`
+}
+
 // ProviderSession contains provided session information.
 // swagger:model ProviderSession
 type ProviderSession struct {
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/nodeattest"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+// NodeAttestationKeyResponse represents the attestation key of the provider node.
+// swagger:model NodeAttestationKeyResponse
+type NodeAttestationKeyResponse struct {
+	// hex encoded ed25519 public key signing the node metadata
+	// example: 3b6a27bcceb6a42d62a3a8d02a6f0d73653215771de243a63ac048a18b59da29
+	PublicKey string `json:"public_key"`
+}
+
+// NodeAttestationDTO represents the metadata of the provider node, as reported and signed by the provider itself.
+// swagger:model NodeAttestationDTO
+type NodeAttestationDTO struct {
+	// example: 1.2.3
+	Version string `json:"version"`
+
+	// example: 0.5.0/docker
+	Launcher string `json:"launcher,omitempty"`
+
+	// example: linux
+	OS string `json:"os"`
+
+	// example: 2022-08-01T10:00:00Z
+	IssuedAt string `json:"issued_at"`
+
+	// whether the metadata is signed by a key endorsed by the provider identity,
+	// it does not prove the node really runs the reported software
+	// example: true
+	ProviderSigned bool `json:"provider_signed"`
+
+	// reason the signature check failed
+	Error string `json:"error,omitempty"`
+}
+
+// NewNodeAttestationDTO maps and verifies the node attestation of the proposal, nil if the proposal is not attested.
+func NewNodeAttestationDTO(proposal market.ServiceProposal) *NodeAttestationDTO {
+	if proposal.Attestation == nil {
+		return nil
+	}
+
+	metadata := proposal.Attestation.Metadata
+	dto := &NodeAttestationDTO{
+		Version:        metadata.Version,
+		Launcher:       metadata.Launcher,
+		OS:             metadata.OS,
+		IssuedAt:       time.Unix(metadata.IssuedAt, 0).UTC().Format(time.RFC3339),
+		ProviderSigned: true,
+	}
+	if err := nodeattest.Verify(proposal); err != nil {
+		dto.ProviderSigned = false
+		dto.Error = err.Error()
+	}
+
+	return dto
+}
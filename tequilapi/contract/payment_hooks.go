@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/hooks"
+)
+
+// PaymentHookDeliveriesQuery allows to filter the listed payment event deliveries.
+// swagger:parameters paymentHookDeliveries
+type PaymentHookDeliveriesQuery struct {
+	// Delivery status, one of: pending, delivered, failed.
+	// in: query
+	Status string `json:"status"`
+}
+
+// Bind creates and validates query from API request.
+func (q *PaymentHookDeliveriesQuery) Bind(request *http.Request) *apierror.APIError {
+	v := apierror.NewValidator()
+
+	status := request.URL.Query().Get("status")
+	switch hooks.DeliveryStatus(status) {
+	case "", hooks.DeliveryPending, hooks.DeliveryDelivered, hooks.DeliveryFailed:
+		q.Status = status
+	default:
+		v.Invalid("status", "'status' must be one of: pending, delivered, failed")
+	}
+
+	return v.Err()
+}
+
+// NewPaymentHookDeliveriesResponse maps to API payment event deliveries.
+func NewPaymentHookDeliveriesResponse(deliveries []hooks.Delivery) PaymentHookDeliveriesResponse {
+	dtoArray := make([]PaymentHookDeliveryDTO, len(deliveries))
+	for i, d := range deliveries {
+		dtoArray[i] = PaymentHookDeliveryDTO{
+			ID:             d.ID,
+			Event:          d.Event,
+			URL:            d.URL,
+			Status:         string(d.Status),
+			Attempts:       d.Attempts,
+			LastError:      d.LastError,
+			LastStatusCode: d.LastStatusCode,
+			CreatedAt:      d.CreatedAt.Format(time.RFC3339),
+			UpdatedAt:      d.UpdatedAt.Format(time.RFC3339),
+		}
+	}
+	return PaymentHookDeliveriesResponse{Items: dtoArray}
+}
+
+// PaymentHookDeliveriesResponse defines payment event deliveries representable as json.
+// swagger:model PaymentHookDeliveriesResponse
+type PaymentHookDeliveriesResponse struct {
+	Items []PaymentHookDeliveryDTO `json:"items"`
+}
+
+// PaymentHookDeliveryDTO represents the delivery of a payment event to a single endpoint.
+// swagger:model PaymentHookDeliveryDTO
+type PaymentHookDeliveryDTO struct {
+	// example: 42
+	ID uint64 `json:"id"`
+
+	// example: settlement_completed
+	Event string `json:"event"`
+
+	// example: https://operator.example.com/myst/payments
+	URL string `json:"url"`
+
+	// pending, delivered or failed
+	// example: delivered
+	Status string `json:"status"`
+
+	// example: 1
+	Attempts int `json:"attempts"`
+
+	// error of the last failed attempt
+	// example: webhook responded with status 503
+	LastError string `json:"last_error,omitempty"`
+
+	// HTTP status of the last response, zero if no response was received
+	// example: 200
+	LastStatusCode int `json:"last_status_code,omitempty"`
+
+	// example: 2022-10-01T12:00:00Z
+	CreatedAt string `json:"created_at"`
+
+	// example: 2022-10-01T12:00:01Z
+	UpdatedAt string `json:"updated_at"`
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/pcap"
+)
+
+// PacketCaptureRequest request to capture packets of the session tunnel.
+// swagger:model PacketCaptureRequest
+type PacketCaptureRequest struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// capture duration, defaults to 30 seconds, at most 600
+	// example: 60
+	DurationSeconds int `json:"duration_seconds"`
+
+	// capture file size limit, defaults to 10 MB, at most 100
+	// example: 20
+	MaxMegabytes int `json:"max_megabytes"`
+}
+
+// Validate validates the packet capture request.
+func (r PacketCaptureRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if r.SessionID == "" {
+		v.Required("session_id")
+	}
+	if r.DurationSeconds < 0 || time.Duration(r.DurationSeconds)*time.Second > pcap.MaxDuration {
+		v.Invalid("duration_seconds", "'duration_seconds' should be between 0 and 600")
+	}
+	if r.MaxMegabytes < 0 || int64(r.MaxMegabytes)<<20 > pcap.MaxBytes {
+		v.Invalid("max_megabytes", "'max_megabytes' should be between 0 and 100")
+	}
+	return v.Err()
+}
+
+// PacketCaptureDTO represents the packet capture of the session tunnel.
+// swagger:model PacketCaptureDTO
+type PacketCaptureDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918-1641038400
+	ID string `json:"id"`
+
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// example: myst0
+	Interface string `json:"interface"`
+
+	// example: /var/lib/mysterium-node/pcap/4cfb0324-daf6-4ad8-448b-e61fe0a1f918-1641038400.pcap
+	File string `json:"file"`
+
+	// example: 60
+	DurationSeconds int `json:"duration_seconds"`
+
+	// example: 20
+	MaxMegabytes int64 `json:"max_megabytes"`
+
+	// captured bytes
+	// example: 1048576
+	Size int64 `json:"size"`
+
+	// example: true
+	Running bool `json:"running"`
+
+	// example: 2022-01-01T12:00:00Z
+	StartedAt string `json:"started_at"`
+
+	// example: 2022-01-01T12:01:00Z
+	FinishedAt string `json:"finished_at,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// NewPacketCaptureDTO maps to API packet capture.
+func NewPacketCaptureDTO(c pcap.Capture) PacketCaptureDTO {
+	dto := PacketCaptureDTO{
+		ID:              c.ID,
+		SessionID:       c.SessionID,
+		Interface:       c.Interface,
+		File:            c.Path,
+		DurationSeconds: int(c.Duration / time.Second),
+		MaxMegabytes:    c.MaxBytes >> 20,
+		Size:            c.Size,
+		Running:         c.Running(),
+		StartedAt:       c.StartedAt.Format(time.RFC3339),
+		Error:           c.Error,
+	}
+	if !c.Running() {
+		dto.FinishedAt = c.FinishedAt.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// PacketCaptureListResponse represents the packet captures.
+// swagger:model PacketCaptureListResponse
+type PacketCaptureListResponse struct {
+	Items []PacketCaptureDTO `json:"items"`
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import "github.com/mysteriumnetwork/node/core/preflight"
+
+// NewPreflightReportDTO maps to API provider startup check report.
+func NewPreflightReportDTO(report preflight.Report) PreflightReportDTO {
+	response := PreflightReportDTO{
+		Passed: report.Passed,
+		Checks: make([]DiagnosticsCheckDTO, len(report.Checks)),
+	}
+	for i, check := range report.Checks {
+		response.Checks[i] = DiagnosticsCheckDTO{
+			Name:       check.Name,
+			OK:         check.OK,
+			Skipped:    check.Skipped,
+			DurationMs: check.Duration.Milliseconds(),
+			Details:    check.Details,
+			Error:      check.Error,
+		}
+	}
+	return response
+}
+
+// PreflightReportDTO holds the report of provider startup checks.
+// swagger:model PreflightReportDTO
+type PreflightReportDTO struct {
+	// false if any of the checks has failed
+	// example: true
+	Passed bool `json:"passed"`
+
+	Checks []DiagnosticsCheckDTO `json:"checks"`
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"math/big"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// NewHermesPromiseListResponse maps to API hermes promise list.
+func NewHermesPromiseListResponse(promises []pingpong.HermesPromise) HermesPromiseListResponse {
+	items := make([]HermesPromiseDTO, len(promises))
+	for i, promise := range promises {
+		items[i] = NewHermesPromiseDTO(promise)
+	}
+	return HermesPromiseListResponse{Items: items}
+}
+
+// HermesPromiseListResponse defines hermes promise list representable as json.
+// swagger:model HermesPromiseListResponse
+type HermesPromiseListResponse struct {
+	Items []HermesPromiseDTO `json:"items"`
+}
+
+// NewHermesPromiseDTO maps to API hermes promise.
+func NewHermesPromiseDTO(promise pingpong.HermesPromise) HermesPromiseDTO {
+	return HermesPromiseDTO{
+		ChannelID:    promise.ChannelID,
+		HermesID:     promise.HermesID.Hex(),
+		ChainID:      promise.Promise.ChainID,
+		Amount:       promise.Promise.Amount,
+		AmountTokens: NewTokens(promise.Promise.Amount),
+		Fee:          promise.Promise.Fee,
+		FeeTokens:    NewTokens(promise.Promise.Fee),
+		AgreementID:  promise.AgreementID,
+		Revealed:     promise.Revealed,
+	}
+}
+
+// HermesPromiseDTO represents the latest hermes promise of the provider channel.
+// swagger:model HermesPromiseDTO
+type HermesPromiseDTO struct {
+	// example: 0x3a2e6ea5a9a4a1e1c35b05bc6e6a3c0d7c45c3e43e1e2f76e0e9a45b0e1a1e4c
+	ChannelID string `json:"channel_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID string `json:"hermes_id"`
+
+	// example: 137
+	ChainID int64 `json:"chain_id"`
+
+	// example: 500000
+	Amount       *big.Int `json:"amount"`
+	AmountTokens Tokens   `json:"amount_tokens"`
+
+	// example: 0
+	Fee       *big.Int `json:"fee"`
+	FeeTokens Tokens   `json:"fee_tokens"`
+
+	// example: 1234
+	AgreementID *big.Int `json:"agreement_id"`
+
+	// whether the hashlock preimage has been revealed to hermes, only revealed promises can be settled
+	// example: true
+	Revealed bool `json:"revealed"`
+}
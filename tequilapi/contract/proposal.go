@@ -30,6 +30,10 @@ import (
 // request
 const AutoNATType = "auto"
 
+// ProposalsFormatNDJSON passed as format parameter to proposal discovery
+// streams proposals as newline delimited JSON.
+const ProposalsFormatNDJSON = "ndjson"
+
 // NewProposalDTO maps to API service proposal.
 func NewProposalDTO(p proposal.PricedServiceProposal) ProposalDTO {
 	return ProposalDTO{
@@ -45,16 +49,37 @@ func NewProposalDTO(p proposal.PricedServiceProposal) ProposalDTO {
 			Bandwidth: p.Quality.Bandwidth,
 			Uptime:    p.Quality.Uptime,
 		},
-		Price: Price{
-			Currency:      money.CurrencyMyst.String(),
-			PerHour:       p.Price.PricePerHour.Uint64(),
-			PerHourTokens: NewTokens(p.Price.PricePerHour),
-			PerGiB:        p.Price.PricePerGiB.Uint64(),
-			PerGiBTokens:  NewTokens(p.Price.PricePerGiB),
-		},
+		Price:          newPriceDTO(p.Price),
+		BandwidthTiers: newBandwidthTiersDTO(p.BandwidthTiers, p.Price),
+	}
+}
+
+func newPriceDTO(p market.Price) Price {
+	return Price{
+		Currency:      money.CurrencyMyst.String(),
+		PerHour:       money.SaturatedUint64(p.PricePerHour),
+		PerHourTokens: NewTokens(p.PricePerHour),
+		PerGiB:        money.SaturatedUint64(p.PricePerGiB),
+		PerGiBTokens:  NewTokens(p.PricePerGiB),
 	}
 }
 
+func newBandwidthTiersDTO(tiers []market.BandwidthTier, base market.Price) *[]BandwidthTierDTO {
+	if len(tiers) == 0 {
+		return nil
+	}
+
+	res := make([]BandwidthTierDTO, 0, len(tiers))
+	for _, tier := range tiers {
+		res = append(res, BandwidthTierDTO{
+			Name:      tier.Name,
+			Bandwidth: tier.Bandwidth,
+			Price:     newPriceDTO(tier.Price(base)),
+		})
+	}
+	return &res
+}
+
 // NewServiceLocationsDTO maps to API service location.
 func NewServiceLocationsDTO(l market.Location) ServiceLocationDTO {
 	return ServiceLocationDTO{
@@ -105,6 +130,27 @@ type ProposalDTO struct {
 
 	// Quality of the service.
 	Quality Quality `json:"quality"`
+
+	// Bandwidth tiers consumer can choose from when connecting
+	BandwidthTiers *[]BandwidthTierDTO `json:"bandwidth_tiers,omitempty"`
+
+	// Consumer curated details of the provider, included on request
+	Favorite *ProviderFavoriteDTO `json:"favorite,omitempty"`
+
+	// Signed metadata of the provider node and its verification result, included on request
+	Attestation *NodeAttestationDTO `json:"attestation,omitempty"`
+}
+
+// BandwidthTierDTO represents a bandwidth tier offered within the proposal.
+// swagger:model BandwidthTierDTO
+type BandwidthTierDTO struct {
+	// example: basic
+	Name string `json:"name"`
+	// bandwidth limit in Kbytes, zero means unlimited
+	// example: 1250
+	Bandwidth uint64 `json:"bandwidth"`
+	// price of the tier
+	Price Price `json:"price"`
 }
 
 // Price represents the service price.
@@ -0,0 +1,37 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/go-rest/apierror"
+)
+
+// ReferralTokenRequest request used to set the referral token of the node.
+// swagger:model ReferralTokenRequest
+type ReferralTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// Validate validates fields in request
+func (r ReferralTokenRequest) Validate() *apierror.APIError {
+	v := apierror.NewValidator()
+	if len(r.Token) == 0 {
+		v.Required("token")
+	}
+	return v.Err()
+}
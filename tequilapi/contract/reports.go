@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/reports"
+)
+
+// NewStatementDTO maps to API statement.
+func NewStatementDTO(st reports.Statement) StatementDTO {
+	dto := StatementDTO{
+		PeriodStart:  st.From.Format(time.RFC3339),
+		PeriodEnd:    st.To.Format(time.RFC3339),
+		Earnings:     make([]ServiceEarningsDTO, len(st.Earnings)),
+		TotalEarned:  NewTokens(st.TotalEarned),
+		Settlements:  make([]StatementSettlementDTO, len(st.Settlements)),
+		TotalSettled: NewTokens(st.TotalSettled),
+		TotalFees:    NewTokens(st.TotalFees),
+		Spending:     make([]ProviderSpendingDTO, len(st.Spending)),
+		TotalSpent:   NewTokens(st.TotalSpent),
+	}
+	for i, e := range st.Earnings {
+		dto.Earnings[i] = ServiceEarningsDTO{
+			ServiceType: e.ServiceType,
+			Sessions:    e.Sessions,
+			Tokens:      NewTokens(e.Tokens),
+		}
+	}
+	for i, s := range st.Settlements {
+		dto.Settlements[i] = StatementSettlementDTO{
+			Time:             s.Time.Format(time.RFC3339),
+			TxHash:           s.TxHash.Hex(),
+			BlockExplorerURL: s.BlockExplorerURL,
+			ProviderID:       s.ProviderID.Address,
+			HermesID:         s.HermesID.Hex(),
+			Beneficiary:      s.Beneficiary.Hex(),
+			Amount:           NewTokens(s.Amount),
+			Fees:             NewTokens(s.Fees),
+			IsWithdrawal:     s.IsWithdrawal,
+		}
+	}
+	for i, s := range st.Spending {
+		dto.Spending[i] = ProviderSpendingDTO{
+			ProviderID: s.ProviderID.Address,
+			Sessions:   s.Sessions,
+			Tokens:     NewTokens(s.Tokens),
+		}
+	}
+	return dto
+}
+
+// StatementDTO represents a statement of earnings, settlements and spending over a period.
+// swagger:model StatementDTO
+type StatementDTO struct {
+	// example: 2022-05-01T00:00:00Z
+	PeriodStart string `json:"period_start"`
+
+	// example: 2022-05-31T23:59:59Z
+	PeriodEnd string `json:"period_end"`
+
+	Earnings    []ServiceEarningsDTO `json:"earnings"`
+	TotalEarned Tokens               `json:"total_earned"`
+
+	Settlements  []StatementSettlementDTO `json:"settlements"`
+	TotalSettled Tokens                   `json:"total_settled"`
+	TotalFees    Tokens                   `json:"total_fees"`
+
+	Spending   []ProviderSpendingDTO `json:"spending"`
+	TotalSpent Tokens                `json:"total_spent"`
+}
+
+// ServiceEarningsDTO represents earnings of a single service type.
+// swagger:model ServiceEarningsDTO
+type ServiceEarningsDTO struct {
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// example: 12
+	Sessions int `json:"sessions"`
+
+	Tokens Tokens `json:"tokens"`
+}
+
+// StatementSettlementDTO represents a settlement or withdrawal in the statement.
+// swagger:model StatementSettlementDTO
+type StatementSettlementDTO struct {
+	// example: 2022-05-11T10:04:43Z
+	Time string `json:"time"`
+
+	// example: 0x20c070a9be65355adbd2ba479e095e2e8ed7e692596548734984eab75d3fdfa5
+	TxHash string `json:"tx_hash"`
+
+	// example: https://polygonscan.com/tx/0x20c070a9be65355adbd2ba479e095e2e8ed7e692596548734984eab75d3fdfa5
+	BlockExplorerURL string `json:"block_explorer_url"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID string `json:"hermes_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	Beneficiary string `json:"beneficiary"`
+
+	Amount Tokens `json:"amount"`
+	Fees   Tokens `json:"fees"`
+
+	// example: false
+	IsWithdrawal bool `json:"is_withdrawal"`
+}
+
+// ProviderSpendingDTO represents consumer spending on a single provider.
+// swagger:model ProviderSpendingDTO
+type ProviderSpendingDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+
+	// example: 3
+	Sessions int `json:"sessions"`
+
+	Tokens Tokens `json:"tokens"`
+}
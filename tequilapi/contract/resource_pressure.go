@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+// ResourcePressureDTO describes the system resource usage of the provider and the sessions shed because of it.
+// swagger:model ResourcePressureDTO
+type ResourcePressureDTO struct {
+	// true while new sessions are rejected
+	// example: true
+	Shedding bool `json:"shedding"`
+
+	// usage of the resources in percent
+	// example: 93.5
+	CPU             float64 `json:"cpu"`
+	Memory          float64 `json:"memory"`
+	Conntrack       float64 `json:"conntrack"`
+	FileDescriptors float64 `json:"file_descriptors"`
+
+	// resources above their thresholds
+	// example: ["cpu"]
+	Exceeded []string `json:"exceeded"`
+
+	// IDs of the lowest-paying sessions throttled while shedding
+	Deprioritized []string `json:"deprioritized"`
+
+	// number of session requests rejected because of the resource pressure
+	// example: 3
+	Rejected uint64 `json:"rejected"`
+}
@@ -40,6 +40,15 @@ type ServiceStartRequest struct {
 	Options interface{} `json:"options"`
 }
 
+// ServiceRestartRequest request used to apply new options to the running service.
+// swagger:model ServiceRestartRequestDTO
+type ServiceRestartRequest struct {
+	// service options. Every service has a unique list of allowed options.
+	// required: false
+	// example: {"port": 1123, "protocol": "udp"}
+	Options interface{} `json:"options"`
+}
+
 // ServiceAccessPolicies represents the access controls for service start
 // swagger:model ServiceAccessPolicies
 type ServiceAccessPolicies struct {
@@ -20,13 +20,17 @@ package contract
 import (
 	"math/big"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/go-openapi/strfmt"
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/traffic"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/trace"
 )
 
 // NewSessionQuery creates session query with default values.
@@ -68,6 +72,14 @@ type SessionQuery struct {
 	// Status to filter the sessions by. Possible values are "New", "Completed".
 	// in: query
 	Status *string `json:"status"`
+
+	// Minimal duration of the sessions in seconds.
+	// in: query
+	MinDuration *uint64 `json:"min_duration"`
+
+	// Minimal amount of bytes transferred in both directions during the sessions.
+	// in: query
+	MinData *uint64 `json:"min_data"`
 }
 
 // Bind creates and validates query from API request.
@@ -107,6 +119,20 @@ func (q *SessionQuery) Bind(request *http.Request) *apierror.APIError {
 	if qStr := qs.Get("status"); qStr != "" {
 		q.Status = &qStr
 	}
+	if qStr := qs.Get("min_duration"); qStr != "" {
+		if qVal, err := strconv.ParseUint(qStr, 10, 64); err != nil {
+			v.Invalid("min_duration", "Cannot parse 'min_duration'")
+		} else {
+			q.MinDuration = &qVal
+		}
+	}
+	if qStr := qs.Get("min_data"); qStr != "" {
+		if qVal, err := strconv.ParseUint(qStr, 10, 64); err != nil {
+			v.Invalid("min_data", "Cannot parse 'min_data'")
+		} else {
+			q.MinData = &qVal
+		}
+	}
 
 	return v.Err()
 }
@@ -138,6 +164,12 @@ func (q *SessionQuery) ToFilter() *session.Filter {
 	if q.Status != nil {
 		filter.SetStatus(*q.Status)
 	}
+	if q.MinDuration != nil {
+		filter.SetMinDuration(time.Duration(*q.MinDuration) * time.Second)
+	}
+	if q.MinData != nil {
+		filter.SetMinData(*q.MinData)
+	}
 	return filter
 }
 
@@ -149,7 +181,7 @@ func NewSessionListQuery() SessionListQuery {
 }
 
 // SessionListQuery allows to filter requested sessions.
-// swagger:parameters sessionList
+// swagger:parameters sessionList sessionListByConsumer
 type SessionListQuery struct {
 	PaginationQuery
 	SessionQuery
@@ -191,6 +223,55 @@ type SessionListResponse struct {
 	PageableDTO
 }
 
+// NewConsumerSessionsResponse maps consumer session history to API response.
+func NewConsumerSessionsResponse(consumerID string, sessionsAll []session.History, sessions []session.History, paginator *utils.Paginator) ConsumerSessionsResponse {
+	stats := session.NewStats()
+	services := make(map[string]int)
+	var firstSeen, lastSeen time.Time
+	for _, se := range sessionsAll {
+		stats.Add(se)
+		services[se.ServiceType]++
+		if firstSeen.IsZero() || se.Started.Before(firstSeen) {
+			firstSeen = se.Started
+		}
+		if se.Started.After(lastSeen) {
+			lastSeen = se.Started
+		}
+	}
+
+	response := ConsumerSessionsResponse{
+		ConsumerID:          consumerID,
+		Stats:               NewSessionStatsDTO(stats),
+		CountByService:      services,
+		SessionListResponse: NewSessionListResponse(sessions, paginator),
+	}
+	if !firstSeen.IsZero() {
+		response.FirstSeen = firstSeen.Format(time.RFC3339)
+		response.LastSeen = lastSeen.Format(time.RFC3339)
+	}
+	return response
+}
+
+// ConsumerSessionsResponse defines session history of a single consumer representable as json.
+// swagger:model ConsumerSessionsResponse
+type ConsumerSessionsResponse struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	FirstSeen string `json:"first_seen,omitempty"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	LastSeen string `json:"last_seen,omitempty"`
+
+	// number of matched sessions by service type
+	CountByService map[string]int `json:"count_by_service"`
+
+	Stats SessionStatsDTO `json:"stats"`
+
+	SessionListResponse
+}
+
 // NewSessionStatsAggregatedResponse maps to API aggregated stats.
 func NewSessionStatsAggregatedResponse(stats session.Stats) SessionStatsAggregatedResponse {
 	return SessionStatsAggregatedResponse{
@@ -265,7 +346,37 @@ func NewSessionDTO(se session.History) SessionDTO {
 		Tokens:          se.Tokens,
 		Status:          se.Status,
 		IPType:          se.IPType,
+
+		TerminationReason: se.TerminationReason,
+		Speedtests:        NewSessionSpeedtestsDTO(se.Speedtests),
+	}
+}
+
+// NewSessionSpeedtestsDTO maps to API session speedtests.
+func NewSessionSpeedtestsDTO(speedtests []session.Speedtest) []SessionSpeedtestDTO {
+	if len(speedtests) == 0 {
+		return nil
+	}
+	result := make([]SessionSpeedtestDTO, len(speedtests))
+	for i, st := range speedtests {
+		result[i] = SessionSpeedtestDTO{
+			Download:  st.Download,
+			Upload:    st.Upload,
+			LatencyMs: st.Latency.Milliseconds(),
+			CreatedAt: st.Time.Format(time.RFC3339),
+		}
 	}
+	return result
+}
+
+// SessionTerminateRequest request used to terminate the session on provider side.
+// swagger:model SessionTerminateRequestDTO
+type SessionTerminateRequest struct {
+	// example: abusive_consumer
+	Reason string `json:"reason"`
+
+	// example: Port scanning is not allowed
+	Message string `json:"message"`
 }
 
 // SessionDTO represents the session object.
@@ -316,4 +427,132 @@ type SessionDTO struct {
 
 	// example: residential
 	IPType string `json:"ip_type"`
+
+	// reason given by provider for terminating the session, empty if session was not terminated by provider
+	// example: abusive_consumer
+	TerminationReason string `json:"termination_reason,omitempty"`
+
+	// bandwidth allocation of the ongoing provider session
+	Allocation *SessionAllocationDTO `json:"allocation,omitempty"`
+
+	// throughput tests run over the consumer session, the most recent last
+	Speedtests []SessionSpeedtestDTO `json:"speedtests,omitempty"`
+}
+
+// SessionSpeedtestDTO holds the throughput of the session tunnel measured by the consumer.
+// swagger:model SessionSpeedtestDTO
+type SessionSpeedtestDTO struct {
+	// download throughput in bits per second
+	// example: 52428800
+	Download uint64 `json:"download"`
+
+	// upload throughput in bits per second
+	// example: 10485760
+	Upload uint64 `json:"upload"`
+
+	// example: 42
+	LatencyMs int64 `json:"latency_ms"`
+
+	// example: 2024-06-19T10:11:12Z
+	CreatedAt string `json:"created_at"`
+}
+
+// ActiveSessionListResponse defines response for the ongoing provider sessions.
+// swagger:model ActiveSessionListResponse
+type ActiveSessionListResponse struct {
+	Items []ActiveSessionDTO `json:"items"`
+}
+
+// ActiveSessionDTO represents the ongoing provider session with its payment state.
+// swagger:model ActiveSessionDTO
+type ActiveSessionDTO struct {
+	SessionDTO
+
+	// amount of the last invoice sent to the consumer
+	// example: 500000
+	LastInvoiceAmount *big.Int `json:"last_invoice_amount"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	LastInvoiceAt string `json:"last_invoice_at,omitempty"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	LastExchangeMessageAt string `json:"last_exchange_message_at,omitempty"`
+
+	// amount invoiced but not yet paid by the consumer
+	// example: 100000
+	Unpaid *big.Int `json:"unpaid"`
+
+	// amount paid by the consumer which hermes did not cover with promises
+	// example: 0
+	Shortfall *big.Int `json:"shortfall"`
+
+	// traffic sent by the consumer per protocol and destination port bucket, empty unless traffic classification is enabled
+	Traffic traffic.Counters `json:"traffic,omitempty"`
+}
+
+// SessionAllocationDTO represents bandwidth allocation of the ongoing session.
+// swagger:model SessionAllocationDTO
+type SessionAllocationDTO struct {
+	// relative weight of the session when dividing the bandwidth
+	// example: 1
+	Share uint64 `json:"share"`
+
+	// currently allocated bandwidth in Kbytes, 0 means unlimited
+	// example: 2500
+	Bandwidth uint64 `json:"bandwidth"`
+}
+
+// NewSessionAllocationDTO maps to API session allocation.
+func NewSessionAllocationDTO(allocation shaper.AllocationInfo) *SessionAllocationDTO {
+	return &SessionAllocationDTO{
+		Share:     allocation.Share,
+		Bandwidth: allocation.Bandwidth,
+	}
+}
+
+// SessionShareRequest request used to change bandwidth share of the provider session.
+// swagger:model SessionShareRequestDTO
+type SessionShareRequest struct {
+	// example: 2
+	Share uint64 `json:"share"`
+}
+
+// SessionTraceDTO represents timings of the session establishment stages.
+// swagger:model SessionTraceDTO
+type SessionTraceDTO struct {
+	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
+	SessionID string `json:"session_id"`
+
+	// stages of the session establishment, the first one covers the whole establishment
+	Spans []SessionTraceSpanDTO `json:"spans"`
+}
+
+// SessionTraceSpanDTO represents timing of a single session establishment stage.
+// swagger:model SessionTraceSpanDTO
+type SessionTraceSpanDTO struct {
+	// example: Consumer P2P channel creation
+	Key string `json:"key"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	StartedAt string `json:"started_at"`
+
+	// duration in milliseconds
+	// example: 1250
+	Duration int64 `json:"duration"`
+}
+
+// NewSessionTraceDTO maps to API session trace.
+func NewSessionTraceDTO(sessionID string, spans []trace.Span) SessionTraceDTO {
+	dto := SessionTraceDTO{
+		SessionID: sessionID,
+		Spans:     make([]SessionTraceSpanDTO, 0, len(spans)),
+	}
+	for _, span := range spans {
+		dto.Spans = append(dto.Spans, SessionTraceSpanDTO{
+			Key:       span.Key,
+			StartedAt: span.Started.Format(time.RFC3339Nano),
+			Duration:  span.Duration.Milliseconds(),
+		})
+	}
+	return dto
 }
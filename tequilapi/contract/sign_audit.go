@@ -0,0 +1,154 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/identity/audit"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+// NewSignAuditListQuery creates signing audit list query with default values.
+func NewSignAuditListQuery() SignAuditListQuery {
+	return SignAuditListQuery{
+		PaginationQuery: NewPaginationQuery(),
+	}
+}
+
+// SignAuditListQuery allows to filter the listed signing operations.
+// swagger:parameters signAuditList
+type SignAuditListQuery struct {
+	PaginationQuery
+
+	// Filter the signing operations from this date. Formatted in RFC3339 e.g. 2020-07-01.
+	// in: query
+	DateFrom *strfmt.Date `json:"date_from"`
+
+	// Filter the signing operations until this date. Formatted in RFC3339 e.g. 2020-07-30.
+	// in: query
+	DateTo *strfmt.Date `json:"date_to"`
+
+	// Subsystem which requested the signing, e.g. "payments" or "p2p".
+	// in: query
+	Subsystem *string `json:"subsystem"`
+
+	// Type of the signed message, e.g. "exchange-message".
+	// in: query
+	MessageType *string `json:"message_type"`
+}
+
+// Bind creates and validates query from API request.
+func (q *SignAuditListQuery) Bind(request *http.Request) *apierror.APIError {
+	v := apierror.NewValidator()
+	if err := q.PaginationQuery.Bind(request); err != nil {
+		for field, fieldErr := range err.Err.Fields {
+			v.Fail(field, fieldErr.Code, fieldErr.Message)
+		}
+	}
+
+	qs := request.URL.Query()
+	if qStr := qs.Get("date_from"); qStr != "" {
+		if qVal, err := parseDate(qStr); err != nil {
+			v.Invalid("date_from", "Could not parse 'date_from'")
+		} else {
+			q.DateFrom = qVal
+		}
+	}
+	if qStr := qs.Get("date_to"); qStr != "" {
+		if qVal, err := parseDate(qStr); err != nil {
+			v.Invalid("date_to", "Could not parse 'date_to'")
+		} else {
+			q.DateTo = qVal
+		}
+	}
+	if qStr := qs.Get("subsystem"); qStr != "" {
+		q.Subsystem = &qStr
+	}
+	if qStr := qs.Get("message_type"); qStr != "" {
+		q.MessageType = &qStr
+	}
+
+	return v.Err()
+}
+
+// ToFilter converts API query to the signing audit filter of the given identity.
+func (q *SignAuditListQuery) ToFilter(identity string) audit.Filter {
+	filter := audit.Filter{Identity: identity}
+	if q.DateFrom != nil {
+		from := time.Time(*q.DateFrom).Truncate(24 * time.Hour)
+		filter.From = &from
+	}
+	if q.DateTo != nil {
+		to := time.Time(*q.DateTo).Truncate(24 * time.Hour).Add(24*time.Hour - time.Second)
+		filter.To = &to
+	}
+	if q.Subsystem != nil {
+		filter.Subsystem = *q.Subsystem
+	}
+	if q.MessageType != nil {
+		filter.MessageType = *q.MessageType
+	}
+	return filter
+}
+
+// NewSignAuditListResponse maps to API signing audit list.
+func NewSignAuditListResponse(records []audit.Record, paginator *utils.Paginator) SignAuditListResponse {
+	dtoArray := make([]SignAuditRecordDTO, len(records))
+	for i, record := range records {
+		dtoArray[i] = SignAuditRecordDTO{
+			Subsystem:   record.Subsystem,
+			MessageType: record.MessageType,
+			Digest:      record.Digest,
+			CreatedAt:   record.Timestamp.Format(time.RFC3339),
+		}
+	}
+
+	return SignAuditListResponse{
+		Items:       dtoArray,
+		PageableDTO: NewPageableDTO(paginator),
+	}
+}
+
+// SignAuditListResponse defines signing operation list representable as json.
+// swagger:model SignAuditListResponse
+type SignAuditListResponse struct {
+	Items []SignAuditRecordDTO `json:"items"`
+	PageableDTO
+}
+
+// SignAuditRecordDTO represents a signing operation performed by an identity.
+// swagger:model SignAuditRecordDTO
+type SignAuditRecordDTO struct {
+	// example: payments
+	Subsystem string `json:"subsystem"`
+
+	// example: exchange-message
+	MessageType string `json:"message_type"`
+
+	// Keccak256 digest of the signed message, the same hash the signature is made of.
+	// example: 1c8aff950685c2ed4bc3174f3472287b56d9517b9c948127319a09a7a36deac8
+	Digest string `json:"digest"`
+
+	// example: 2022-10-01T12:00:00Z
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+)
+
+// NewStorageMetricsResponse maps to API storage metrics.
+func NewStorageMetricsResponse(stats []boltdb.OperationStats) StorageMetricsResponse {
+	dtoArray := make([]StorageOperationDTO, len(stats))
+	for i, s := range stats {
+		dtoArray[i] = StorageOperationDTO{
+			Operation: s.Operation,
+			Bucket:    s.Bucket,
+			Count:     s.Count,
+			Slow:      s.Slow,
+			AverageMs: float64(s.Average().Microseconds()) / 1000,
+			MaxMs:     float64(s.Max.Microseconds()) / 1000,
+		}
+	}
+	return StorageMetricsResponse{Operations: dtoArray}
+}
+
+// StorageMetricsResponse defines the storage latency metrics representable as json.
+// swagger:model StorageMetricsResponse
+type StorageMetricsResponse struct {
+	Operations []StorageOperationDTO `json:"operations"`
+}
+
+// StorageOperationDTO represents the aggregated latencies of a storage operation on a bucket.
+// swagger:model StorageOperationDTO
+type StorageOperationDTO struct {
+	// example: get
+	Operation string `json:"operation"`
+
+	// example: myst_promises_137
+	Bucket string `json:"bucket"`
+
+	// example: 120
+	Count uint64 `json:"count"`
+
+	// number of operations which took longer than the slow operation threshold
+	// example: 1
+	Slow uint64 `json:"slow"`
+
+	// example: 0.35
+	AverageMs float64 `json:"average_ms"`
+
+	// example: 612.5
+	MaxMs float64 `json:"max_ms"`
+}
@@ -359,3 +359,143 @@ type ChainSummary struct {
 	Chains       map[int64]string `json:"chains"`
 	CurrentChain int64            `json:"current_chain"`
 }
+
+// NewWithdrawalJobDTO maps to API withdrawal job.
+func NewWithdrawalJobDTO(job pingpong.WithdrawalJob) WithdrawalJobDTO {
+	dto := WithdrawalJobDTO{
+		ID:          job.ID,
+		ProviderID:  job.Request.ProviderID.Address,
+		HermesID:    job.Request.HermesID.Hex(),
+		Beneficiary: job.Request.Beneficiary.Hex(),
+		FromChainID: job.Request.FromChainID,
+		ToChainID:   job.Request.ToChainID,
+		Status:      string(job.Status),
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   job.UpdatedAt.Format(time.RFC3339),
+	}
+	if job.Request.Amount != nil {
+		dto.Amount = job.Request.Amount.String()
+	}
+	return dto
+}
+
+// WithdrawalJobDTO represents the progress of the guided withdrawal.
+// swagger:model WithdrawalJobDTO
+type WithdrawalJobDTO struct {
+	// example: 7e4b5a66-86b2-4e3a-b9b4-2a1a3c7f1d01
+	ID string `json:"id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID string `json:"hermes_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	Beneficiary string `json:"beneficiary"`
+
+	// example: 137
+	FromChainID int64 `json:"from_chain_id"`
+
+	// example: 1
+	ToChainID int64 `json:"to_chain_id"`
+
+	// empty when the whole settled balance is withdrawn
+	// example: 1000000000000000000
+	Amount string `json:"amount,omitempty"`
+
+	// one of: settling, withdrawing, completed, failed
+	// example: settling
+	Status string `json:"status"`
+
+	// example: could not withdraw
+	Error string `json:"error,omitempty"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	CreatedAt string `json:"created_at"`
+
+	// example: 2019-06-06T11:04:43.910035Z
+	UpdatedAt string `json:"updated_at"`
+}
+
+// SettlementBatchPlanDTO represents the plan of settling multiple promises in a single transaction.
+// swagger:model SettlementBatchPlanDTO
+type SettlementBatchPlanDTO struct {
+	// example: 137
+	ChainID int64 `json:"chain_id"`
+
+	// example: 0x0000000000000000000000000000000000000001
+	ProviderID string `json:"provider_id"`
+
+	// promises settled within the batch
+	Items []SettlementBatchItemDTO `json:"items"`
+
+	// promises left out of the batch
+	Skipped []SettlementBatchItemDTO `json:"skipped"`
+
+	// whether the transactor settles multiple promises in a single transaction, promises are settled separately otherwise
+	Supported bool `json:"supported"`
+
+	// fee paid for a single settlement transaction
+	TransactorFee Tokens `json:"transactor_fee"`
+
+	// fee paid for the batch settlement transaction
+	BatchTransactorFee Tokens `json:"batch_transactor_fee"`
+
+	// total fees of settling every included promise in its own transaction
+	SeparateFees Tokens `json:"separate_fees"`
+
+	// total fees of settling the included promises in a single transaction
+	BatchFees Tokens `json:"batch_fees"`
+
+	// fees saved by settling in a single transaction
+	Savings Tokens `json:"savings"`
+
+	// total unsettled amount of the included promises
+	Amount Tokens `json:"amount"`
+}
+
+// SettlementBatchItemDTO represents an unsettled promise considered for the batch settlement.
+// swagger:model SettlementBatchItemDTO
+type SettlementBatchItemDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	HermesID string `json:"hermes_id"`
+
+	Amount Tokens `json:"amount"`
+
+	HermesFee Tokens `json:"hermes_fee"`
+
+	// example: hermes fee exceeds the unsettled amount
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewSettlementBatchPlanDTO maps to API settlement batch plan.
+func NewSettlementBatchPlanDTO(plan pingpong.SettlementBatchPlan) SettlementBatchPlanDTO {
+	return SettlementBatchPlanDTO{
+		ChainID:            plan.ChainID,
+		ProviderID:         plan.ProviderID.Address,
+		Items:              newSettlementBatchItemsDTO(plan.Items),
+		Skipped:            newSettlementBatchItemsDTO(plan.Skipped),
+		Supported:          plan.Supported,
+		TransactorFee:      NewTokens(plan.TransactorFee),
+		BatchTransactorFee: NewTokens(plan.BatchTransactorFee),
+		SeparateFees:       NewTokens(plan.SeparateFees),
+		BatchFees:          NewTokens(plan.BatchFees),
+		Savings:            NewTokens(plan.Savings),
+		Amount:             NewTokens(plan.Amount),
+	}
+}
+
+func newSettlementBatchItemsDTO(items []pingpong.SettlementBatchItem) []SettlementBatchItemDTO {
+	res := make([]SettlementBatchItemDTO, len(items))
+	for i, item := range items {
+		res[i] = SettlementBatchItemDTO{
+			HermesID:  item.HermesID.Hex(),
+			Amount:    NewTokens(item.Amount),
+			HermesFee: NewTokens(item.HermesFee),
+			Reason:    item.Reason,
+		}
+	}
+	return res
+}
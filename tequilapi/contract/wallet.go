@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/consumer/wallet"
+)
+
+// WalletDTO aggregates balances, registrations and spending of all local consumer identities.
+// swagger:model WalletDTO
+type WalletDTO struct {
+	// example: 137
+	ChainID int64 `json:"chain_id"`
+
+	// total balance of all identities
+	Balance Tokens `json:"balance_tokens"`
+
+	// total amount spent on connections by all identities
+	Spent Tokens `json:"spent_tokens"`
+
+	// number of registered identities
+	// example: 2
+	Registered int `json:"registered"`
+
+	// identity new connections spend from when consumer_id is not given
+	// example: 0x0000000000000000000000000000000000000001
+	SpendingIdentity string `json:"spending_identity,omitempty"`
+
+	Identities []WalletIdentityDTO `json:"identities"`
+}
+
+// WalletIdentityDTO represents the wallet state of a single consumer identity.
+// swagger:model WalletIdentityDTO
+type WalletIdentityDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	ID string `json:"id"`
+
+	// example: Registered
+	RegistrationStatus string `json:"registration_status"`
+
+	Balance Tokens `json:"balance_tokens"`
+
+	// amount spent on connections
+	Spent Tokens `json:"spent_tokens"`
+
+	// number of consumed sessions
+	// example: 12
+	Sessions int `json:"sessions"`
+
+	// whether new connections spend from this identity
+	// example: true
+	Spending bool `json:"spending"`
+}
+
+// NewWalletDTO maps to API wallet.
+func NewWalletDTO(s wallet.Summary) WalletDTO {
+	dto := WalletDTO{
+		ChainID:    s.ChainID,
+		Balance:    NewTokens(s.Balance),
+		Spent:      NewTokens(s.Spent),
+		Registered: s.Registered,
+		Identities: make([]WalletIdentityDTO, len(s.Entries)),
+	}
+	if s.SpendingIdentity != nil {
+		dto.SpendingIdentity = s.SpendingIdentity.Address
+	}
+	for i, e := range s.Entries {
+		dto.Identities[i] = WalletIdentityDTO{
+			ID:                 e.Identity.Address,
+			RegistrationStatus: e.RegistrationStatus.String(),
+			Balance:            NewTokens(e.Balance),
+			Spent:              NewTokens(e.Spent),
+			Sessions:           e.Sessions,
+			Spending:           e.Spending,
+		}
+	}
+	return dto
+}
+
+// WalletSpendingIdentityRequest selects the identity new connections spend from.
+// swagger:model WalletSpendingIdentityRequestDTO
+type WalletSpendingIdentityRequest struct {
+	// identity to spend from, empty value clears the selection
+	// example: 0x0000000000000000000000000000000000000001
+	ID string `json:"id"`
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/vcraescu/go-paginator/adapter"
+
+	"github.com/mysteriumnetwork/node/core/accesslog"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type accessLog interface {
+	List(filter accesslog.Filter) ([]accesslog.Entry, error)
+	Anonymization() accesslog.Anonymization
+}
+
+type accessLogEndpoint struct {
+	log accessLog
+}
+
+// List lists consumer connections served by the provider
+// swagger:operation GET /node/access-log Provider accessLogList
+// ---
+// summary: Returns consumer connections served by the provider
+// description: Returns the provider access log, most recent connections first. Consumer identities are recorded according to the configured anonymization level. The csv format exports all the matching connections for abuse investigations.
+// responses:
+//   200:
+//     description: Consumer connections
+//     schema:
+//       "$ref": "#/definitions/AccessLogListResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ae *accessLogEndpoint) List(c *gin.Context) {
+	query := contract.NewAccessLogListQuery()
+	if err := query.Bind(c.Request); err != nil {
+		c.Error(err)
+		return
+	}
+
+	entriesAll, err := ae.log.List(query.ToFilter())
+	if err != nil {
+		c.Error(apierror.Internal("Could not list access log: "+err.Error(), contract.ErrCodeAccessLogList))
+		return
+	}
+
+	if query.Format == contract.AccessLogFormatCSV {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", `attachment; filename="access-log.csv"`)
+		c.Status(http.StatusOK)
+		if err := accesslog.WriteCSV(c.Writer, entriesAll); err != nil {
+			c.Error(apierror.Internal("Could not export access log: "+err.Error(), contract.ErrCodeAccessLogExport))
+		}
+		return
+	}
+
+	var entries []accesslog.Entry
+	p := utils.NewPaginator(adapter.NewSliceAdapter(entriesAll), query.PageSize, query.Page)
+	if err := p.Results(&entries); err != nil {
+		c.Error(apierror.Internal("Could not paginate access log: "+err.Error(), contract.ErrCodeAccessLogPaginate))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewAccessLogListResponse(entries, ae.log.Anonymization(), p), c.Writer)
+}
+
+// AddRoutesForAccessLog attaches provider access log endpoints to router
+func AddRoutesForAccessLog(log accessLog) func(*gin.Engine) error {
+	ae := &accessLogEndpoint{log: log}
+	return func(e *gin.Engine) error {
+		e.GET("/node/access-log", ae.List)
+		return nil
+	}
+}
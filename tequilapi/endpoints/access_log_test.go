@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/accesslog"
+)
+
+type mockAccessLog struct {
+	filter  accesslog.Filter
+	entries []accesslog.Entry
+}
+
+func (m *mockAccessLog) List(filter accesslog.Filter) ([]accesslog.Entry, error) {
+	m.filter = filter
+	return m.entries, nil
+}
+
+func (m *mockAccessLog) Anonymization() accesslog.Anonymization {
+	return accesslog.AnonymizationHash
+}
+
+func Test_AccessLogEndpoint_List(t *testing.T) {
+	log := &mockAccessLog{
+		entries: []accesslog.Entry{
+			{SessionID: "s2", Consumer: "aa", Country: "LT", ServiceType: "wireguard", StartedAt: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC), Duration: time.Minute, BytesSent: 20, BytesReceived: 10},
+			{SessionID: "s1", Consumer: "aa", Country: "LT", ServiceType: "wireguard", StartedAt: time.Date(2022, 10, 1, 11, 0, 0, 0, time.UTC)},
+		},
+	}
+	router := summonTestGin()
+	err := AddRoutesForAccessLog(log)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/access-log?consumer_id=0x1&country=LT&date_from=2022-10-01&page_size=1", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	from := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, accesslog.Filter{ConsumerID: "0x1", Country: "LT", From: &from}, log.filter)
+	assert.JSONEq(t, `{
+		"anonymization": "hash",
+		"items": [
+			{"session_id": "s2", "consumer": "aa", "country": "LT", "service_type": "wireguard", "started_at": "2022-10-01T12:00:00Z", "duration": 60, "bytes_sent": 20, "bytes_received": 10}
+		],
+		"page": 1,
+		"page_size": 1,
+		"total_items": 2,
+		"total_pages": 2
+	}`, resp.Body.String())
+}
+
+func Test_AccessLogEndpoint_ExportCSV(t *testing.T) {
+	log := &mockAccessLog{
+		entries: []accesslog.Entry{
+			{SessionID: "s1", Country: "LT", ServiceType: "wireguard", StartedAt: time.Date(2022, 10, 1, 11, 0, 0, 0, time.UTC)},
+		},
+	}
+	router := summonTestGin()
+	err := AddRoutesForAccessLog(log)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/access-log?format=csv", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "text/csv", resp.Header().Get("Content-Type"))
+	assert.Equal(t, "session_id,consumer,country,service_type,started_at,duration_seconds,bytes_sent,bytes_received\n"+
+		"s1,,LT,wireguard,2022-10-01T11:00:00Z,0,0,0\n", resp.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/node/access-log?format=xml", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type autoPricer interface {
+	Enabled() bool
+	History() []pingpong.PriceChange
+}
+
+type autoPricingEndpoint struct {
+	pricer autoPricer
+}
+
+// AutoPricing returns the history of prices announced by auto pricing
+// swagger:operation GET /node/auto-pricing Provider autoPricing
+// ---
+// summary: Returns auto pricing history
+// description: Returns whether the provider adjusts its price to the network average and the most recent price changes, newest first.
+// responses:
+//   200:
+//     description: Auto pricing state
+//     schema:
+//       "$ref": "#/definitions/AutoPricingResponse"
+func (ape *autoPricingEndpoint) AutoPricing(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewAutoPricingResponse(ape.pricer.Enabled(), ape.pricer.History()), c.Writer)
+}
+
+// AddRoutesForAutoPricing attaches auto pricing endpoints to router
+func AddRoutesForAutoPricing(pricer autoPricer) func(*gin.Engine) error {
+	ape := &autoPricingEndpoint{pricer: pricer}
+	return func(e *gin.Engine) error {
+		e.GET("/node/auto-pricing", ape.AutoPricing)
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockAutoPricer struct {
+	history []pingpong.PriceChange
+}
+
+func (m *mockAutoPricer) Enabled() bool {
+	return true
+}
+
+func (m *mockAutoPricer) History() []pingpong.PriceChange {
+	return m.history
+}
+
+func Test_AutoPricingEndpoint_AutoPricing(t *testing.T) {
+	pricer := &mockAutoPricer{
+		history: []pingpong.PriceChange{
+			{
+				NodeType:       "residential",
+				Country:        "DE",
+				ServiceType:    "wireguard",
+				Previous:       market.NewPrice(100, 1000),
+				Price:          *market.NewPrice(150, 2000),
+				NetworkAverage: *market.NewPrice(150, 2000),
+				ChangedAt:      time.Date(2022, 10, 1, 12, 10, 0, 0, time.UTC),
+			},
+			{
+				NodeType:       "residential",
+				Country:        "DE",
+				ServiceType:    "wireguard",
+				Price:          *market.NewPrice(100, 1000),
+				NetworkAverage: *market.NewPrice(90, 1000),
+				ChangedAt:      time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	router := summonTestGin()
+	err := AddRoutesForAutoPricing(pricer)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/auto-pricing", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res contract.AutoPricingResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.True(t, res.Enabled)
+	assert.Len(t, res.History, 2)
+	assert.Equal(t, uint64(100), res.History[0].Previous.PerHour)
+	assert.Equal(t, uint64(2000), res.History[0].Price.PerGiB)
+	assert.Equal(t, "DE", res.History[0].Country)
+	assert.Equal(t, "2022-10-01T12:10:00Z", res.History[0].ChangedAt)
+	assert.Nil(t, res.History[1].Previous)
+	assert.Equal(t, uint64(90), res.History[1].NetworkAverage.PerHour)
+}
@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
 
 	"github.com/gin-gonic/gin"
@@ -37,6 +38,8 @@ type configProvider interface {
 	SetUser(key string, value interface{})
 	RemoveUser(key string)
 	SaveUserConfig() error
+	Schema() []config.Option
+	Validate(values map[string]interface{}) map[string]string
 }
 
 // swagger:model configPayload
@@ -102,7 +105,7 @@ func (api *configAPI) GetUserConfig(c *gin.Context) {
 // swagger:operation POST /config/user Configuration serUserConfig
 // ---
 // summary: Sets and returns user configuration
-// description: For keys present in the payload, it will set or remove the user config values (if the key is null). Changes are persisted to the config file.
+// description: For keys present in the payload, it will set or remove the user config values (if the key is null). Changes are persisted to the config file. Options which may only be set by flags or environment variables are rejected.
 // parameters:
 //   - in: body
 //     name: body
@@ -129,6 +132,12 @@ func (api *configAPI) SetUserConfig(c *gin.Context) {
 		c.Error(apierror.ParseFailed())
 		return
 	}
+	for k := range req.Data {
+		if config.IsCLIOnly(k) {
+			c.Error(apierror.BadRequestField(fmt.Sprintf("%q may only be set by a flag or an environment variable", k), contract.ErrCodeConfigCLIOnly, k))
+			return
+		}
+	}
 	for k, v := range req.Data {
 		if isNil(v) {
 			log.Debug().Msgf("Clearing user config value: %q", v)
@@ -146,6 +155,56 @@ func (api *configAPI) SetUserConfig(c *gin.Context) {
 	api.GetUserConfig(c)
 }
 
+// GetConfigSchema returns descriptions of configuration options
+// swagger:operation GET /config/schema Configuration getConfigSchema
+// ---
+// summary: Returns configuration schema
+// description: Describes every configuration option with its type, default value, constraints and whether changing it requires node restart
+// responses:
+//   200:
+//     description: Configuration schema
+//     schema:
+//       "$ref": "#/definitions/ConfigSchemaResponse"
+func (api *configAPI) GetConfigSchema(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewConfigSchemaResponse(api.config.Schema()), c.Writer)
+}
+
+// ValidateConfig validates configuration values without applying them
+// swagger:operation POST /config/validate Configuration validateConfig
+// ---
+// summary: Validates configuration values
+// description: Checks the configuration values against the schema without applying them. Payload has the same format as for setting user configuration.
+// parameters:
+//   - in: body
+//     name: body
+//     description: configuration keys/values
+//     schema:
+//       $ref: "#/definitions/configPayload"
+// responses:
+//   200:
+//     description: Validation result
+//     schema:
+//       "$ref": "#/definitions/ConfigValidationResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (api *configAPI) ValidateConfig(c *gin.Context) {
+	var req configPayload
+	err := json.NewDecoder(c.Request.Body).Decode(&req)
+	if err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	problems := api.config.Validate(req.Data)
+	res := contract.ConfigValidationResponse{Valid: len(problems) == 0}
+	if !res.Valid {
+		res.Errors = problems
+	}
+	utils.WriteAsJSON(res, c.Writer)
+}
+
 func isNil(val interface{}) bool {
 	if val == nil {
 		return true
@@ -168,6 +227,8 @@ func AddRoutesForConfig(
 		g.GET("/default", api.GetDefaultConfig)
 		g.GET("/user", api.GetUserConfig)
 		g.POST("/user", api.SetUserConfig)
+		g.GET("/schema", api.GetConfigSchema)
+		g.POST("/validate", api.ValidateConfig)
 	}
 	return nil
 }
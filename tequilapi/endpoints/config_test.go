@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/config"
+)
+
+func Test_ConfigEndpoint_RejectsCLIOnlyOptions(t *testing.T) {
+	cfg := config.NewConfig()
+	api := newConfigAPI(cfg)
+	router := summonTestGin()
+	router.POST("/config/user", api.SetUserConfig)
+
+	for _, body := range []string{
+		`{"data": {"hooks.script": "/tmp/evil.sh"}}`,
+		`{"data": {"hooks": {"script": "/tmp/evil.sh"}}}`,
+		`{"data": {"openvpn.port": 1194, "hooks.webhook-url": "http://evil"}}`,
+	} {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/config/user", strings.NewReader(body)))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, body)
+	}
+	assert.Empty(t, cfg.GetUserConfig())
+}
@@ -18,10 +18,13 @@
 package endpoints
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
@@ -29,6 +32,8 @@ import (
 
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/blacklist"
+	"github.com/mysteriumnetwork/node/consumer/ephemeral"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/quality"
@@ -51,10 +56,25 @@ type ProposalGetter interface {
 	GetProposal(id market.ProposalID) (*market.ServiceProposal, error)
 }
 
+type providerBlacklist interface {
+	IsBlacklisted(providerID string) bool
+	List() []blacklist.Entry
+	Remove(providerID string) bool
+	Clear()
+}
+
 type identityRegistry interface {
 	GetRegistrationStatus(int64, identity.Identity) (registry.RegistrationStatus, error)
 }
 
+type identityRegistrar interface {
+	Register(ctx context.Context, chainID int64, id identity.Identity) error
+}
+
+type ephemeralIdentityCreator interface {
+	Create(ctx context.Context, chainID int64, parent identity.Identity) (identity.Identity, error)
+}
+
 // ConnectionEndpoint struct represents /connection resource and it's subresources
 type ConnectionEndpoint struct {
 	manager       connection.MultiManager
@@ -64,10 +84,14 @@ type ConnectionEndpoint struct {
 	proposalRepository proposalRepository
 	identityRegistry   identityRegistry
 	addressProvider    addressProvider
+	blacklist          providerBlacklist
+	registrar          identityRegistrar
+	wallet             spendingIdentityProvider
+	ephemeral          ephemeralIdentityCreator
 }
 
 // NewConnectionEndpoint creates and returns connection endpoint
-func NewConnectionEndpoint(manager connection.MultiManager, stateProvider stateProvider, proposalRepository proposalRepository, identityRegistry identityRegistry, publisher eventbus.Publisher, addressProvider addressProvider) *ConnectionEndpoint {
+func NewConnectionEndpoint(manager connection.MultiManager, stateProvider stateProvider, proposalRepository proposalRepository, identityRegistry identityRegistry, publisher eventbus.Publisher, addressProvider addressProvider, blacklist providerBlacklist, registrar identityRegistrar, wallet spendingIdentityProvider, ephemeral ephemeralIdentityCreator) *ConnectionEndpoint {
 	return &ConnectionEndpoint{
 		manager:            manager,
 		publisher:          publisher,
@@ -75,6 +99,10 @@ func NewConnectionEndpoint(manager connection.MultiManager, stateProvider stateP
 		proposalRepository: proposalRepository,
 		identityRegistry:   identityRegistry,
 		addressProvider:    addressProvider,
+		blacklist:          blacklist,
+		registrar:          registrar,
+		wallet:             wallet,
+		ephemeral:          ephemeral,
 	}
 }
 
@@ -88,6 +116,10 @@ func NewConnectionEndpoint(manager connection.MultiManager, stateProvider stateP
 //     description: Status
 //     schema:
 //       "$ref": "#/definitions/ConnectionInfoDTO"
+//   202:
+//     description: Ephemeral identity is being prepared, retry the connection later
+//     schema:
+//       "$ref": "#/definitions/APIError"
 //   400:
 //     description: Failed to parse or request validation failed
 //     schema:
@@ -154,12 +186,36 @@ func (ce *ConnectionEndpoint) Create(c *gin.Context) {
 		return
 	}
 
+	if cr.ConsumerID == "" && ce.wallet != nil {
+		if id, ok := ce.wallet.SpendingIdentity(); ok {
+			cr.ConsumerID = id.Address
+		}
+	}
+
 	if err := cr.Validate(); err != nil {
 		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageValidateRequest, err.Detail()))
 		c.Error(err)
 		return
 	}
 
+	useEphemeral := config.GetBool(config.FlagEphemeralIdentitiesEnabled)
+	if cr.EphemeralIdentity != nil {
+		useEphemeral = *cr.EphemeralIdentity
+	}
+	if useEphemeral && ce.ephemeral != nil {
+		id, err := ce.ephemeral.Create(c.Request.Context(), config.GetInt64(config.FlagChainID), identity.FromAddress(cr.ConsumerID))
+		if errors.Is(err, ephemeral.ErrPending) {
+			c.Error(apierror.Error(http.StatusAccepted, "Ephemeral identity is being prepared, retry the connection later", contract.ErrCodeEphemeralIdentityPending))
+			return
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Could not create ephemeral identity for %q", cr.ConsumerID)
+			c.Error(apierror.Internal("Failed to create ephemeral identity: "+err.Error(), contract.ErrCodeEphemeralIdentity))
+			return
+		}
+		cr.ConsumerID = id.Address
+	}
+
 	consumerID := identity.FromAddress(cr.ConsumerID)
 	status, err := ce.identityRegistry.GetRegistrationStatus(config.GetInt64(config.FlagChainID), consumerID)
 	if err != nil {
@@ -169,6 +225,29 @@ func (ce *ConnectionEndpoint) Create(c *gin.Context) {
 		return
 	}
 
+	if cr.AutoRegister && ce.registrar != nil && !status.Registered() {
+		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageRegistrationAutoRegister, ""))
+		err := ce.registrar.Register(c.Request.Context(), config.GetInt64(config.FlagChainID), consumerID)
+		switch {
+		case errors.Is(err, registry.ErrRegistrationPending):
+			log.Info().Err(err).Msgf("Identity %q registration is not confirmed yet", cr.ConsumerID)
+			status = registry.InProgress
+		case err != nil:
+			ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageRegistrationAutoRegister, err.Error()))
+			log.Error().Err(err).Msgf("Could not auto register identity %q", cr.ConsumerID)
+
+			var balanceErr *registry.InsufficientBalanceError
+			if errors.As(err, &balanceErr) {
+				c.Error(apierror.Unprocessable(fmt.Sprintf("Identity %q has insufficient balance to pay registration fee. Please top up channel %s", cr.ConsumerID, balanceErr.ChannelAddress.Hex()), contract.ErrCodeIDRegistrationBalance))
+			} else {
+				c.Error(apierror.Internal("Failed to register identity: "+err.Error(), contract.ErrCodeTransactorRegistration))
+			}
+			return
+		default:
+			status = registry.Registered
+		}
+	}
+
 	switch status {
 	case registry.Unregistered, registry.RegistrationError, registry.Unknown:
 		ce.publisher.Publish(quality.AppTopicConnectionEvents, cr.Event(quality.StageRegistrationUnregistered, ""))
@@ -200,7 +279,7 @@ func (ce *ConnectionEndpoint) Create(c *gin.Context) {
 		IncludeMonitoringFailed: cr.Filter.IncludeMonitoringFailed,
 		AccessPolicy:            "all",
 	}
-	proposalLookup := connection.FilteredProposals(f, cr.Filter.SortBy, ce.proposalRepository)
+	proposalLookup := connection.FilteredProposals(f, cr.Filter.SortBy, ce.proposalRepository, ce.blacklist)
 
 	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.HermesID), proposalLookup, getConnectOptions(cr))
 	if err != nil {
@@ -272,6 +351,106 @@ func (ce *ConnectionEndpoint) Kill(c *gin.Context) {
 	c.Status(http.StatusAccepted)
 }
 
+// Pause asks provider to stop billing the session and throttle its traffic until resumed
+// swagger:operation PUT /connection/pause Connection connectionPause
+// ---
+// summary: Pauses connection
+// description: Asks provider to stop billing the session and throttle its traffic until resumed
+// parameters:
+// - in: query
+//   name: id
+//   description: Connection ID
+//   type: integer
+// responses:
+//   202:
+//     description: Connection paused
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point (e.g. no active connection exists)
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *ConnectionEndpoint) Pause(c *gin.Context) {
+	n := 0
+	id := c.Query("id")
+	if len(id) > 0 {
+		var err error
+		n, err = strconv.Atoi(id)
+		if err != nil {
+			c.Error(apierror.ParseFailed())
+			return
+		}
+	}
+
+	err := ce.manager.Pause(n)
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			c.Error(apierror.Unprocessable("No connection exists", contract.ErrCodeNoConnectionExists))
+		default:
+			c.Error(apierror.Internal("Could not pause connection: "+err.Error(), contract.ErrCodeConnectionPause))
+		}
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+// Resume asks provider to continue billing the paused session and lift its throttling
+// swagger:operation PUT /connection/resume Connection connectionResume
+// ---
+// summary: Resumes connection
+// description: Asks provider to continue billing the paused session and lift its throttling
+// parameters:
+// - in: query
+//   name: id
+//   description: Connection ID
+//   type: integer
+// responses:
+//   202:
+//     description: Connection resumed
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point (e.g. no active connection exists)
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *ConnectionEndpoint) Resume(c *gin.Context) {
+	n := 0
+	id := c.Query("id")
+	if len(id) > 0 {
+		var err error
+		n, err = strconv.Atoi(id)
+		if err != nil {
+			c.Error(apierror.ParseFailed())
+			return
+		}
+	}
+
+	err := ce.manager.Resume(n)
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			c.Error(apierror.Unprocessable("No connection exists", contract.ErrCodeNoConnectionExists))
+		default:
+			c.Error(apierror.Internal("Could not resume connection: "+err.Error(), contract.ErrCodeConnectionPause))
+		}
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
 // GetStatistics returns statistics about current connection
 // swagger:operation GET /connection/statistics Connection connectionStatistics
 // ---
@@ -290,6 +469,34 @@ func (ce *ConnectionEndpoint) GetStatistics(c *gin.Context) {
 	utils.WriteAsJSON(response, c.Writer)
 }
 
+// GetPayment returns payment engine state of current connection
+// swagger:operation GET /connection/payment Connection connectionPayment
+// ---
+// summary: Returns connection payment state
+// description: Returns the last invoice received, the last promise issued and the cumulative promised amount of current connection, for debugging payment stalls
+// responses:
+//   200:
+//     description: Connection payment state
+//     schema:
+//       "$ref": "#/definitions/ConnectionPaymentDTO"
+func (ce *ConnectionEndpoint) GetPayment(c *gin.Context) {
+	id := c.Query("id")
+	conn := ce.stateProvider.GetConnection(id)
+
+	payment := conn.Payment
+	response := contract.ConnectionPaymentDTO{
+		SessionID:         string(conn.Session.SessionID),
+		LastInvoiceAmount: contract.NewTokens(payment.LastInvoiceAmount),
+		LastInvoiceAt:     formatTime(payment.LastInvoiceAt),
+		Hashlock:          payment.Hashlock,
+		LastPromiseAt:     formatTime(payment.LastPromiseAt),
+		AgreementTotal:    contract.NewTokens(payment.AgreementTotal),
+		TotalPromised:     contract.NewTokens(payment.TotalPromised),
+		LastError:         payment.LastError,
+	}
+	utils.WriteAsJSON(response, c.Writer)
+}
+
 // GetTraffic returns traffic information about requested connection
 // swagger:operation GET /connection/traffic Connection connectionTraffic
 // ---
@@ -325,6 +532,286 @@ func (ce *ConnectionEndpoint) GetTraffic(c *gin.Context) {
 	utils.WriteAsJSON(response, c.Writer)
 }
 
+// Diagnose runs tunnel internal checks of requested connection
+// swagger:operation GET /connection/diagnostics Connection connectionDiagnostics
+// ---
+// summary: Returns connection health report
+// description: Runs tunnel internal checks (provider ping, DNS resolution, external IP) of requested connection
+// responses:
+//   200:
+//     description: Connection health report
+//     schema:
+//       "$ref": "#/definitions/ConnectionDiagnosticsDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point (e.g. no active connection exists)
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *ConnectionEndpoint) Diagnose(c *gin.Context) {
+	n := 0
+	id := c.Query("id")
+	if len(id) > 0 {
+		var err error
+		n, err = strconv.Atoi(id)
+		if err != nil {
+			c.Error(apierror.ParseFailed())
+			return
+		}
+	}
+
+	report, err := ce.manager.Diagnose(c.Request.Context(), n)
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			c.Error(apierror.Unprocessable("No connection exists", contract.ErrCodeNoConnectionExists))
+		default:
+			c.Error(apierror.Internal("Could not diagnose connection: "+err.Error(), contract.ErrCodeConnectionDiagnostics))
+		}
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionDiagnosticsDTO(report), c.Writer)
+}
+
+// LeakTest checks whether traffic of requested connection bypasses the tunnel
+// swagger:operation GET /connection/leak-test Connection connectionLeakTest
+// ---
+// summary: Returns connection leak test report
+// description: Checks whether DNS, IPv6 or WebRTC (STUN) traffic of requested connection bypasses the tunnel
+// parameters:
+// - in: query
+//   name: id
+//   description: Connection ID
+//   type: integer
+// responses:
+//   200:
+//     description: Connection leak test report
+//     schema:
+//       "$ref": "#/definitions/ConnectionLeakTestDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point (e.g. no active connection exists)
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *ConnectionEndpoint) LeakTest(c *gin.Context) {
+	n := 0
+	id := c.Query("id")
+	if len(id) > 0 {
+		var err error
+		n, err = strconv.Atoi(id)
+		if err != nil {
+			c.Error(apierror.ParseFailed())
+			return
+		}
+	}
+
+	report, err := ce.manager.LeakTest(c.Request.Context(), n)
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			c.Error(apierror.Unprocessable("No connection exists", contract.ErrCodeNoConnectionExists))
+		case connection.ErrLeakTestUnsupported:
+			c.Error(apierror.Unprocessable("Proxy connection can not be tested for leaks", contract.ErrCodeConnectionLeakTest))
+		default:
+			c.Error(apierror.Internal("Could not run leak test: "+err.Error(), contract.ErrCodeConnectionLeakTest))
+		}
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionLeakTestDTO(report), c.Writer)
+}
+
+// Speedtest measures throughput of requested connection
+// swagger:operation PUT /connection/speedtest Connection connectionSpeedtest
+// ---
+// summary: Runs connection speedtest
+// description: Measures download and upload throughput of requested connection against the provider's echo endpoint. The result is recorded into the session history.
+// parameters:
+// - in: query
+//   name: id
+//   description: Connection ID
+//   type: integer
+// - in: query
+//   name: duration
+//   description: Duration of each direction in seconds, 5 by default and 15 at most
+//   type: integer
+// responses:
+//   200:
+//     description: Connection speedtest result
+//     schema:
+//       "$ref": "#/definitions/ConnectionSpeedtestDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point (e.g. no active connection exists)
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *ConnectionEndpoint) Speedtest(c *gin.Context) {
+	n := 0
+	id := c.Query("id")
+	if len(id) > 0 {
+		var err error
+		n, err = strconv.Atoi(id)
+		if err != nil {
+			c.Error(apierror.ParseFailed())
+			return
+		}
+	}
+
+	var duration time.Duration
+	if d := c.Query("duration"); len(d) > 0 {
+		seconds, err := strconv.Atoi(d)
+		if err != nil || seconds < 0 {
+			c.Error(apierror.ParseFailed())
+			return
+		}
+		duration = time.Duration(seconds) * time.Second
+	}
+
+	result, err := ce.manager.Speedtest(c.Request.Context(), n, duration)
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			c.Error(apierror.Unprocessable("No connection exists", contract.ErrCodeNoConnectionExists))
+		case connection.ErrSpeedtestUnsupported:
+			c.Error(apierror.Unprocessable("Connection can not be tested for speed", contract.ErrCodeConnectionSpeedtest))
+		default:
+			c.Error(apierror.Internal("Could not run speedtest: "+err.Error(), contract.ErrCodeConnectionSpeedtest))
+		}
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionSpeedtestDTO(result), c.Writer)
+}
+
+// ExportWireguardConfig exports negotiated WireGuard configuration of requested connection
+// swagger:operation GET /connection/wireguard-config Connection connectionWireguardConfig
+// ---
+// summary: Returns WireGuard configuration of the connection
+// description: Exports negotiated peer configuration of an active WireGuard connection in wg-quick format. The private key of the running tunnel is always redacted.
+// parameters:
+// - in: query
+//   name: id
+//   description: Connection ID
+//   type: integer
+// responses:
+//   200:
+//     description: WireGuard configuration
+//     schema:
+//       "$ref": "#/definitions/ConnectionConfigExportDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point (e.g. no active WireGuard connection exists)
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *ConnectionEndpoint) ExportWireguardConfig(c *gin.Context) {
+	n := 0
+	id := c.Query("id")
+	if len(id) > 0 {
+		var err error
+		n, err = strconv.Atoi(id)
+		if err != nil {
+			c.Error(apierror.ParseFailed())
+			return
+		}
+	}
+
+	config, err := ce.manager.ExportConfig(n)
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			c.Error(apierror.Unprocessable("No connection exists", contract.ErrCodeNoConnectionExists))
+		case connection.ErrConfigExportUnsupported:
+			c.Error(apierror.Unprocessable("Connection is not a WireGuard connection", contract.ErrCodeConnectionConfigExport))
+		default:
+			c.Error(apierror.Internal("Could not export connection config: "+err.Error(), contract.ErrCodeConnectionConfigExport))
+		}
+		return
+	}
+
+	utils.WriteAsJSON(contract.ConnectionConfigExportDTO{Config: config}, c.Writer)
+}
+
+// Blacklist returns providers with recent connection failures
+// swagger:operation GET /connection/blacklist Connection connectionBlacklist
+// ---
+// summary: Returns provider blacklist
+// description: Returns providers with recent connection failures. Blacklisted providers are skipped when connecting without explicit provider.
+// responses:
+//   200:
+//     description: Provider blacklist
+//     schema:
+//       "$ref": "#/definitions/ProviderBlacklistDTO"
+func (ce *ConnectionEndpoint) Blacklist(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewProviderBlacklistDTO(ce.blacklist.List()), c.Writer)
+}
+
+// ClearBlacklist forgets failures of all providers
+// swagger:operation DELETE /connection/blacklist Connection connectionBlacklistClear
+// ---
+// summary: Clears provider blacklist
+// description: Forgets connection failures of all providers
+// responses:
+//   202:
+//     description: Provider blacklist cleared
+func (ce *ConnectionEndpoint) ClearBlacklist(c *gin.Context) {
+	ce.blacklist.Clear()
+	c.Status(http.StatusAccepted)
+}
+
+// RemoveFromBlacklist forgets failures of the given provider
+// swagger:operation DELETE /connection/blacklist/{id} Connection connectionBlacklistRemove
+// ---
+// summary: Removes provider from blacklist
+// description: Forgets connection failures of the given provider
+// parameters:
+// - in: path
+//   name: id
+//   description: Provider identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Provider removed from blacklist
+//   404:
+//     description: Provider not found in blacklist
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *ConnectionEndpoint) RemoveFromBlacklist(c *gin.Context) {
+	if !ce.blacklist.Remove(c.Param("id")) {
+		c.Error(apierror.NotFound("Provider not found in blacklist"))
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
 type proposalRepository interface {
 	Proposal(id market.ProposalID) (*proposal.PricedServiceProposal, error)
 	Proposals(filter *proposal.Filter) ([]proposal.PricedServiceProposal, error)
@@ -340,16 +827,30 @@ func AddRoutesForConnection(
 	identityRegistry identityRegistry,
 	publisher eventbus.Publisher,
 	addressProvider addressProvider,
+	blacklist providerBlacklist,
+	registrar identityRegistrar,
+	wallet spendingIdentityProvider,
+	ephemeral ephemeralIdentityCreator,
 ) func(*gin.Engine) error {
-	connectionEndpoint := NewConnectionEndpoint(manager, stateProvider, proposalRepository, identityRegistry, publisher, addressProvider)
+	connectionEndpoint := NewConnectionEndpoint(manager, stateProvider, proposalRepository, identityRegistry, publisher, addressProvider, blacklist, registrar, wallet, ephemeral)
 	return func(e *gin.Engine) error {
 		connGroup := e.Group("")
 		{
 			connGroup.GET("/connection", connectionEndpoint.Status)
 			connGroup.PUT("/connection", connectionEndpoint.Create)
 			connGroup.DELETE("/connection", connectionEndpoint.Kill)
+			connGroup.PUT("/connection/pause", connectionEndpoint.Pause)
+			connGroup.PUT("/connection/resume", connectionEndpoint.Resume)
 			connGroup.GET("/connection/statistics", connectionEndpoint.GetStatistics)
 			connGroup.GET("/connection/traffic", connectionEndpoint.GetTraffic)
+			connGroup.GET("/connection/payment", connectionEndpoint.GetPayment)
+			connGroup.GET("/connection/diagnostics", connectionEndpoint.Diagnose)
+			connGroup.GET("/connection/wireguard-config", connectionEndpoint.ExportWireguardConfig)
+			connGroup.GET("/connection/leak-test", connectionEndpoint.LeakTest)
+			connGroup.PUT("/connection/speedtest", connectionEndpoint.Speedtest)
+			connGroup.GET("/connection/blacklist", connectionEndpoint.Blacklist)
+			connGroup.DELETE("/connection/blacklist", connectionEndpoint.ClearBlacklist)
+			connGroup.DELETE("/connection/blacklist/:id", connectionEndpoint.RemoveFromBlacklist)
 		}
 		return nil
 	}
@@ -380,5 +881,8 @@ func getConnectOptions(cr *contract.ConnectionCreateRequest) connection.ConnectP
 		DisableKillSwitch: cr.ConnectOptions.DisableKillSwitch,
 		DNS:               dns,
 		ProxyPort:         cr.ConnectOptions.ProxyPort,
+		AttestationToken:  cr.ConnectOptions.AttestationToken,
+		ChargePeriod:      time.Duration(cr.ConnectOptions.ChargePeriod) * time.Second,
+		BandwidthTier:     cr.ConnectOptions.BandwidthTier,
 	}
 }
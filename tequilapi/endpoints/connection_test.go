@@ -19,6 +19,7 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"math/big"
 	"net/http"
 	"net/http/httptest"
@@ -32,15 +33,21 @@ import (
 
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/blacklist"
+	"github.com/mysteriumnetwork/node/consumer/ephemeral"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/connection/connectionstate"
+	"github.com/mysteriumnetwork/node/core/connection/diagnostics"
+	"github.com/mysteriumnetwork/node/core/connection/leaktest"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/core/speedtest"
 	"github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
@@ -54,6 +61,18 @@ type mockConnectionManager struct {
 	requestedProvider    identity.Identity
 	requestedHermesID    common.Address
 	requestedServiceType string
+	onDiagnoseReturn     diagnostics.Report
+	onDiagnoseErr        error
+	onLeakTestReturn     leaktest.Report
+	onLeakTestErr        error
+	onSpeedtestReturn    speedtest.Result
+	onSpeedtestErr       error
+	speedtestDuration    time.Duration
+	onExportConfigReturn string
+	onExportConfigErr    error
+	onPauseReturn        error
+	pauseCount           int
+	resumeCount          int
 }
 
 func (cm *mockConnectionManager) Connect(consumerID identity.Identity, hermesID common.Address, proposalLookup connection.ProposalLookup, options connection.ConnectParams) error {
@@ -90,6 +109,33 @@ func (cm *mockConnectionManager) Reconnect(int) {
 	return
 }
 
+func (cm *mockConnectionManager) Diagnose(context.Context, int) (diagnostics.Report, error) {
+	return cm.onDiagnoseReturn, cm.onDiagnoseErr
+}
+
+func (cm *mockConnectionManager) LeakTest(context.Context, int) (leaktest.Report, error) {
+	return cm.onLeakTestReturn, cm.onLeakTestErr
+}
+
+func (cm *mockConnectionManager) Speedtest(_ context.Context, _ int, duration time.Duration) (speedtest.Result, error) {
+	cm.speedtestDuration = duration
+	return cm.onSpeedtestReturn, cm.onSpeedtestErr
+}
+
+func (cm *mockConnectionManager) ExportConfig(_ int) (string, error) {
+	return cm.onExportConfigReturn, cm.onExportConfigErr
+}
+
+func (cm *mockConnectionManager) Pause(int) error {
+	cm.pauseCount++
+	return cm.onPauseReturn
+}
+
+func (cm *mockConnectionManager) Resume(int) error {
+	cm.resumeCount++
+	return cm.onPauseReturn
+}
+
 func mockRepositoryWithProposal(providerID, serviceType string) *mockProposalRepository {
 	sampleProposal := proposal.PricedServiceProposal{
 		ServiceProposal: market.ServiceProposal{
@@ -117,7 +163,7 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 	}
 
 	mockedProposalProvider := mockRepositoryWithProposal("node1", "noop")
-	err := AddRoutesForConnection(fakeManager, fakeState, mockedProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(fakeManager, fakeState, mockedProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	tests := []struct {
@@ -183,7 +229,7 @@ func TestStateIsReturnedFromStore(t *testing.T) {
 	}
 
 	router := summonTestGin()
-	err := AddRoutesForConnection(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodGet, "/connection", nil)
@@ -206,7 +252,7 @@ func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	router := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodPut, "/connection", strings.NewReader("a"))
@@ -221,7 +267,7 @@ func TestPutReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	router := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(router)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(router)
 	assert.NoError(t, err)
 
 	req := httptest.NewRequest(http.MethodPut, "/connection", strings.NewReader("{}"))
@@ -260,7 +306,7 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, fakeState, proposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, fakeState, proposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -281,6 +327,31 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	)
 }
 
+func TestPutWithoutConsumerIDSpendsFromWalletSpendingIdentity(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	wallet := &mockConsumerWallet{spending: identity.FromAddress("spending-identity")}
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/connection",
+		strings.NewReader(
+			`{
+				"provider_id" : "required-node",
+				"hermes_id" : "hermes"
+			}`))
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, proposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, wallet, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, identity.FromAddress("spending-identity"), fakeManager.requestedConsumerID)
+}
+
 func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
@@ -300,7 +371,7 @@ func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -309,6 +380,206 @@ func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
 	assert.Equal(t, "err_id_not_registered", apierror.Parse(resp.Result()).Err.Code)
 }
 
+type mockIdentityRegistrar struct {
+	registered identity.Identity
+	err        error
+}
+
+func (m *mockIdentityRegistrar) Register(_ context.Context, _ int64, id identity.Identity) error {
+	m.registered = id
+	return m.err
+}
+
+func TestPutUnregisteredIdentityWithAutoRegistrationConnects(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	fakeManager.onStatusReturn = connectionstate.Status{State: connectionstate.Connected}
+
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	mir := *mockIdentityRegistryInstance
+	mir.RegistrationStatus = registry.Unregistered
+	registrar := &mockIdentityRegistrar{}
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/connection",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"hermes_id" : "hermes",
+				"auto_register" : true
+			}`))
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil, registrar, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, identity.FromAddress("my-identity"), registrar.registered)
+	assert.Equal(t, identity.FromAddress("my-identity"), fakeManager.requestedConsumerID)
+}
+
+func TestPutUnregisteredIdentityWithPendingAutoRegistrationConnects(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	fakeManager.onStatusReturn = connectionstate.Status{State: connectionstate.Connected}
+
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	mir := *mockIdentityRegistryInstance
+	mir.RegistrationStatus = registry.Unregistered
+	registrar := &mockIdentityRegistrar{err: errors.Wrap(registry.ErrRegistrationPending, "not confirmed")}
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/connection",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"hermes_id" : "hermes",
+				"auto_register" : true
+			}`))
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil, registrar, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, identity.FromAddress("my-identity"), fakeManager.requestedConsumerID)
+}
+
+func TestPutUnregisteredIdentityWithAutoRegistrationReturnsBalanceError(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	mir := *mockIdentityRegistryInstance
+	mir.RegistrationStatus = registry.Unregistered
+	registrar := &mockIdentityRegistrar{err: &registry.InsufficientBalanceError{
+		Fee:            big.NewInt(10),
+		Balance:        big.NewInt(0),
+		ChannelAddress: common.HexToAddress("0x1"),
+	}}
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/connection",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"hermes_id" : "hermes",
+				"auto_register" : true
+			}`))
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil, registrar, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Equal(t, "err_id_registration_insufficient_balance", apierror.Parse(resp.Result()).Err.Code)
+	assert.Equal(t, identity.Identity{}, fakeManager.requestedConsumerID)
+}
+
+type mockEphemeralIdentities struct {
+	parent identity.Identity
+	err    error
+}
+
+func (m *mockEphemeralIdentities) Create(_ context.Context, _ int64, parent identity.Identity) (identity.Identity, error) {
+	m.parent = parent
+	return identity.FromAddress("ephemeral-identity"), m.err
+}
+
+func TestPutWithEphemeralIdentityConnectsWithNewIdentity(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	fakeManager.onStatusReturn = connectionstate.Status{State: connectionstate.Connected}
+	ephemeral := &mockEphemeralIdentities{}
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/connection",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"hermes_id" : "hermes",
+				"ephemeral_identity" : true
+			}`))
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mockRepositoryWithProposal("required-node", "openvpn"), mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, ephemeral)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, identity.FromAddress("my-identity"), ephemeral.parent)
+	assert.Equal(t, identity.FromAddress("ephemeral-identity"), fakeManager.requestedConsumerID)
+}
+
+func TestPutWithEphemeralIdentityReturnsCreationError(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	ephemeral := &mockEphemeralIdentities{err: errors.New("no earnings")}
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/connection",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"hermes_id" : "hermes",
+				"ephemeral_identity" : true
+			}`))
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mockRepositoryWithProposal("required-node", "openvpn"), mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, ephemeral)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, "err_ephemeral_identity", apierror.Parse(resp.Result()).Err.Code)
+	assert.Equal(t, identity.Identity{}, fakeManager.requestedConsumerID)
+}
+
+func TestPutWithPendingEphemeralIdentityAsksToRetry(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	identities := &mockEphemeralIdentities{err: errors.Wrap(ephemeral.ErrPending, "waiting for funds")}
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/connection",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"hermes_id" : "hermes",
+				"ephemeral_identity" : true
+			}`))
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mockRepositoryWithProposal("required-node", "openvpn"), mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, identities)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, "err_ephemeral_identity_pending", apierror.Parse(resp.Result()).Err.Code)
+	assert.Equal(t, identity.Identity{}, fakeManager.requestedConsumerID)
+}
+
 func TestPutFailedRegistrationCheckReturnsError(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
@@ -328,7 +599,7 @@ func TestPutFailedRegistrationCheckReturnsError(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -356,7 +627,7 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, &mockStateProvider{}, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -376,7 +647,7 @@ func TestDeleteCallsDisconnect(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -386,6 +657,290 @@ func TestDeleteCallsDisconnect(t *testing.T) {
 	assert.Equal(t, fakeManager.disconnectCount, 1)
 }
 
+func TestGetDiagnosticsReturnsReport(t *testing.T) {
+	fakeManager := mockConnectionManager{
+		onDiagnoseReturn: diagnostics.Report{
+			Healthy: false,
+			Checks: []diagnostics.CheckResult{
+				{Name: diagnostics.CheckTunnelPing, OK: true, Duration: 42 * time.Millisecond, Details: "10.182.0.1"},
+				{Name: diagnostics.CheckDNS, Error: "i/o timeout"},
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/connection/diagnostics", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"healthy": false,
+			"checks": [
+				{"name": "tunnel_ping", "ok": true, "skipped": false, "duration_ms": 42, "details": "10.182.0.1"},
+				{"name": "dns", "ok": false, "skipped": false, "duration_ms": 0, "error": "i/o timeout"}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestGetDiagnosticsReturns422WithoutConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{onDiagnoseErr: connection.ErrNoConnection}
+
+	req := httptest.NewRequest(http.MethodGet, "/connection/diagnostics", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestGetLeakTestReturnsReport(t *testing.T) {
+	fakeManager := mockConnectionManager{
+		onLeakTestReturn: leaktest.Report{
+			Leaked: true,
+			Checks: []leaktest.Result{
+				{Name: leaktest.CheckDNS, Duration: 42 * time.Millisecond, Details: "DNS queries are sent from 10.182.0.1"},
+				{Name: leaktest.CheckIPv6, Leaked: true, Details: "IPv6 traffic is routed outside the tunnel from 2001:db8::1"},
+			},
+			CheckedAt: time.Date(2024, 6, 19, 10, 11, 12, 0, time.UTC),
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/connection/leak-test", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"leaked": true,
+			"checks": [
+				{"name": "dns", "leaked": false, "skipped": false, "duration_ms": 42, "details": "DNS queries are sent from 10.182.0.1"},
+				{"name": "ipv6", "leaked": true, "skipped": false, "duration_ms": 0, "details": "IPv6 traffic is routed outside the tunnel from 2001:db8::1"}
+			],
+			"checked_at": "2024-06-19T10:11:12Z"
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestGetLeakTestReturns422ForProxyConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{onLeakTestErr: connection.ErrLeakTestUnsupported}
+
+	req := httptest.NewRequest(http.MethodGet, "/connection/leak-test?id=10000", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestPutSpeedtestReturnsResult(t *testing.T) {
+	fakeManager := mockConnectionManager{
+		onSpeedtestReturn: speedtest.Result{
+			StartedAt:     time.Date(2024, 6, 19, 10, 11, 12, 0, time.UTC),
+			Latency:       42 * time.Millisecond,
+			Duration:      3 * time.Second,
+			DownloadBytes: 3000,
+			UploadBytes:   1500,
+			Download:      8000,
+			Upload:        4000,
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/connection/speedtest?duration=3", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, 3*time.Second, fakeManager.speedtestDuration)
+	assert.JSONEq(
+		t,
+		`{
+			"download": 8000,
+			"upload": 4000,
+			"latency_ms": 42,
+			"duration_ms": 3000,
+			"download_bytes": 3000,
+			"upload_bytes": 1500,
+			"started_at": "2024-06-19T10:11:12Z"
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestPutSpeedtestReturns422WithoutConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{onSpeedtestErr: connection.ErrNoConnection}
+
+	req := httptest.NewRequest(http.MethodPut, "/connection/speedtest", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestExportWireguardConfig(t *testing.T) {
+	fakeManager := mockConnectionManager{onExportConfigReturn: "[Interface]\nPrivateKey = <redacted>\n"}
+
+	req := httptest.NewRequest(http.MethodGet, "/connection/wireguard-config", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"config": "[Interface]\nPrivateKey = <redacted>\n"}`, resp.Body.String())
+}
+
+func TestExportWireguardConfigReturns422(t *testing.T) {
+	for _, exportErr := range []error{connection.ErrNoConnection, connection.ErrConfigExportUnsupported} {
+		fakeManager := mockConnectionManager{onExportConfigErr: exportErr}
+
+		req := httptest.NewRequest(http.MethodGet, "/connection/wireguard-config", nil)
+		resp := httptest.NewRecorder()
+
+		g := summonTestGin()
+		err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+		assert.NoError(t, err)
+
+		g.ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	}
+}
+
+func TestPauseAndResumeConnection(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/connection/pause", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, 1, fakeManager.pauseCount)
+
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/connection/resume", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, 1, fakeManager.resumeCount)
+}
+
+func TestPauseConnectionReturns422WhenNotConnected(t *testing.T) {
+	fakeManager := mockConnectionManager{onPauseReturn: connection.ErrNoConnection}
+
+	req := httptest.NewRequest(http.MethodPut, "/connection/pause", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestGetBlacklistReturnsEntries(t *testing.T) {
+	bl := blacklist.New(blacklist.Config{Threshold: 1, HalfLife: time.Hour})
+	bl.ReportFailure("0x2", blacklist.ReasonConnectionFailed)
+
+	req := httptest.NewRequest(http.MethodGet, "/connection/blacklist", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&mockConnectionManager{}, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, bl, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	dto := parseProviderBlacklist(t, resp.Body.String())
+	assert.Len(t, dto.Entries, 1)
+	assert.Equal(t, "0x2", dto.Entries[0].ProviderID)
+	assert.Equal(t, 1, dto.Entries[0].Failures)
+	assert.Equal(t, "connection_failed", dto.Entries[0].LastReason)
+	assert.True(t, dto.Entries[0].Blacklisted)
+}
+
+func TestDeleteBlacklistClearsEntries(t *testing.T) {
+	bl := blacklist.New(blacklist.Config{Threshold: 1, HalfLife: time.Hour})
+	bl.ReportFailure("0x2", blacklist.ReasonConnectionFailed)
+
+	req := httptest.NewRequest(http.MethodDelete, "/connection/blacklist", nil)
+	resp := httptest.NewRecorder()
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&mockConnectionManager{}, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, bl, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Empty(t, bl.List())
+}
+
+func TestDeleteBlacklistProvider(t *testing.T) {
+	bl := blacklist.New(blacklist.Config{Threshold: 1, HalfLife: time.Hour})
+	bl.ReportFailure("0x2", blacklist.ReasonConnectionFailed)
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&mockConnectionManager{}, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, bl, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/connection/blacklist/0x2", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.False(t, bl.IsBlacklisted("0x2"))
+
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/connection/blacklist/0x2", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func parseProviderBlacklist(t *testing.T, body string) contract.ProviderBlacklistDTO {
+	var dto contract.ProviderBlacklistDTO
+	err := json.Unmarshal([]byte(body), &dto)
+	assert.NoError(t, err)
+	return dto
+}
+
 func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	fakeState := &mockStateProvider{stateToReturn: event.State{Connections: make(map[string]event.Connection)}}
 	fakeState.stateToReturn.Connections["1"] = event.Connection{
@@ -410,7 +965,7 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 			}`))
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -434,6 +989,42 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	)
 }
 
+func TestGetPaymentEndpointReturnsPaymentState(t *testing.T) {
+	fakeState := &mockStateProvider{stateToReturn: event.State{Connections: make(map[string]event.Connection)}}
+	fakeState.stateToReturn.Connections["1"] = event.Connection{
+		Session: connectionstate.Status{SessionID: "session"},
+		Payment: event.ConsumerPayment{
+			LastInvoiceAmount: big.NewInt(15),
+			LastInvoiceAt:     time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC),
+			Hashlock:          "0x1",
+			LastError:         "provider trying to overcharge",
+		},
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/connection/payment", nil)
+
+	g := summonTestGin()
+	err := AddRoutesForConnection(&mockConnectionManager{}, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.JSONEq(
+		t,
+		`{
+			"session_id": "session",
+			"last_invoice_amount": {"ether": "0.000000000000000015", "human": "0", "wei": "15"},
+			"last_invoice_at": "2022-06-01T12:00:00Z",
+			"hashlock": "0x1",
+			"agreement_total": {"ether": "0", "human": "0", "wei": "0"},
+			"total_promised": {"ether": "0", "human": "0", "wei": "0"},
+			"last_error": "provider trying to overcharge"
+		}`,
+		resp.Body.String(),
+	)
+}
+
 func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onConnectReturn = connection.ErrAlreadyExists
@@ -452,7 +1043,7 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&manager, nil, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, mystAPI, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -465,7 +1056,7 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onDisconnectReturn = connection.ErrNoConnection
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)
 
 	req := httptest.NewRequest(
 		http.MethodDelete,
@@ -503,7 +1094,7 @@ func TestConnectReturnsConnectCancelledStatusWhenErrConnectionCancelledIsEncount
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -528,7 +1119,7 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 	resp := httptest.NewRecorder()
 
 	g := summonTestGin()
-	err := AddRoutesForConnection(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{})(g)
+	err := AddRoutesForConnection(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, eventbus.New(), &mockAddressProvider{}, nil, nil, nil, nil)(g)
 	assert.NoError(t, err)
 
 	g.ServeHTTP(resp, req)
@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/shopspring/decimal"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type hermesFeeProvider interface {
+	GetHermesFee(chainID int64, id common.Address) (uint16, error)
+}
+
+type settleFeeProvider interface {
+	FetchSettleFees(chainID int64) (registry.FeesResponse, error)
+}
+
+type activeHermesProvider interface {
+	GetActiveHermes(chainID int64) (common.Address, error)
+}
+
+type costEstimateEndpoint struct {
+	proposalRepository proposalRepository
+	addressProvider    activeHermesProvider
+	hermesFees         hermesFeeProvider
+	transactorFees     settleFeeProvider
+}
+
+// Estimate estimates the cost of a session
+// swagger:operation POST /proposals/estimate Proposal proposalCostEstimate
+// ---
+// summary: Estimates the cost of a session
+// description: Estimates how much a session with the provider costs for the given duration and traffic, including hermes and transactor fees
+// parameters:
+// - in: body
+//   name: body
+//   required: true
+//   schema:
+//     $ref: "#/definitions/CostEstimateRequestDTO"
+// responses:
+//   200:
+//     description: Session cost estimate
+//     schema:
+//       "$ref": "#/definitions/CostEstimateDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: Proposal not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ce *costEstimateEndpoint) Estimate(c *gin.Context) {
+	var req contract.CostEstimateRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	serviceType := req.ServiceType
+	if serviceType == "" {
+		serviceType = wireguard.ServiceType
+	}
+	proposal, err := ce.proposalRepository.Proposal(market.ProposalID{ProviderID: req.ProviderID, ServiceType: serviceType})
+	if err != nil {
+		c.Error(apierror.Internal("Could not get proposal: "+err.Error(), contract.ErrCodeProposalsQuery))
+		return
+	}
+	if proposal == nil {
+		c.Error(apierror.NotFound("Proposal not found"))
+		return
+	}
+
+	price := proposal.Price
+	if req.BandwidthTier != "" {
+		tier, ok := proposal.BandwidthTier(req.BandwidthTier)
+		if !ok {
+			c.Error(apierror.BadRequest("Proposal has no bandwidth tier "+req.BandwidthTier, contract.ErrCodeProposalsBandwidthTier))
+			return
+		}
+		price = tier.Price(price)
+	}
+
+	chainID := config.GetInt64(config.FlagChainID)
+	hermes, err := ce.addressProvider.GetActiveHermes(chainID)
+	if err != nil {
+		c.Error(apierror.Internal("Failed to get active hermes", contract.ErrCodeActiveHermes))
+		return
+	}
+
+	hermesFeePerMyriad, err := ce.hermesFees.GetHermesFee(chainID, hermes)
+	if err != nil {
+		c.Error(apierror.Internal("Could not get hermes fee: "+err.Error(), contract.ErrCodeHermesFee))
+		return
+	}
+
+	settleFees, err := ce.transactorFees.FetchSettleFees(chainID)
+	if err != nil {
+		utils.ForwardError(c, err, apierror.Internal("Failed to fetch fees", contract.ErrCodeTransactorFetchFees))
+		return
+	}
+
+	estimate := pingpong.EstimateSessionCost(price, time.Duration(req.DurationSeconds)*time.Second, req.DataBytes, hermesFeePerMyriad, settleFees.Fee)
+	hermesPercent := decimal.NewFromInt(int64(hermesFeePerMyriad)).Div(decimal.NewFromInt(10000))
+	utils.WriteAsJSON(contract.NewCostEstimateDTO(estimate, hermesPercent.StringFixed(4)), c.Writer)
+}
+
+// AddRoutesForCostEstimate attaches session cost estimate endpoint to router.
+func AddRoutesForCostEstimate(
+	proposalRepository proposalRepository,
+	addressProvider activeHermesProvider,
+	hermesFees hermesFeeProvider,
+	transactorFees settleFeeProvider,
+) func(*gin.Engine) error {
+	ce := &costEstimateEndpoint{
+		proposalRepository: proposalRepository,
+		addressProvider:    addressProvider,
+		hermesFees:         hermesFees,
+		transactorFees:     transactorFees,
+	}
+	return func(e *gin.Engine) error {
+		e.POST("/proposals/estimate", ce.Estimate)
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockSettleFees struct {
+	fee *big.Int
+}
+
+func (m mockSettleFees) FetchSettleFees(_ int64) (registry.FeesResponse, error) {
+	return registry.FeesResponse{Fee: m.fee}, nil
+}
+
+func TestCostEstimateEndpoint(t *testing.T) {
+	repository := &mockProposalRepository{proposals: []proposal.PricedServiceProposal{{
+		ServiceProposal: market.ServiceProposal{
+			ProviderID:     "0xprovider",
+			ServiceType:    "wireguard",
+			BandwidthTiers: []market.BandwidthTier{{Name: "premium", PricePercent: 200}},
+		},
+		Price: *market.NewPrice(1000, 2000),
+	}}}
+	router := summonTestGin()
+	err := AddRoutesForCostEstimate(repository, &mockAddressProvider{}, &mockSettler{feeToReturn: 2000}, mockSettleFees{fee: big.NewInt(100)})(router)
+	assert.NoError(t, err)
+
+	estimate := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/proposals/estimate", bytes.NewBufferString(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := estimate(`{"provider_id": "0xprovider", "duration_seconds": 1800, "data_bytes": 1073741824}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res contract.CostEstimateDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, big.NewInt(2500), res.Service)
+	assert.Equal(t, big.NewInt(500), res.HermesFee)
+	assert.Equal(t, "0.2000", res.HermesPercent)
+	assert.Equal(t, big.NewInt(100), res.TransactorFee)
+	assert.Equal(t, big.NewInt(3100), res.Total)
+	assert.Equal(t, "6000", res.PerHourTokens.Wei)
+
+	resp = estimate(`{"provider_id": "0xprovider", "duration_seconds": 3600, "bandwidth_tier": "premium"}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, big.NewInt(2000), res.Service)
+
+	resp = estimate(`{"provider_id": "0xprovider", "duration_seconds": 3600, "bandwidth_tier": "unknown"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = estimate(`{"provider_id": "0xprovider"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestCostEstimateEndpoint_ProposalNotFound(t *testing.T) {
+	router := summonTestGin()
+	err := AddRoutesForCostEstimate(&mockProposalRepository{}, &mockAddressProvider{}, &mockSettler{}, mockSettleFees{})(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/proposals/estimate", bytes.NewBufferString(`{"provider_id": "0xprovider", "duration_seconds": 60}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
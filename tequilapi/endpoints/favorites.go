@@ -0,0 +1,217 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/consumer/favorites"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type favoritesStorage interface {
+	Save(entry favorites.Entry) (favorites.Entry, error)
+	Get(providerID string) (favorites.Entry, error)
+	Delete(providerID string) error
+	List(filter favorites.Filter) ([]favorites.Entry, error)
+}
+
+type favoritesEndpoint struct {
+	storage favoritesStorage
+}
+
+// List lists consumer curated providers
+// swagger:operation GET /favorites Favorites favoritesList
+// ---
+// summary: Returns consumer curated providers
+// description: Returns favorite providers and providers with personal notes or labels, favorites first
+// parameters:
+// - in: query
+//   name: favorites_only
+//   description: Return only favorite providers
+//   type: boolean
+// - in: query
+//   name: label
+//   description: Return only providers with the given label
+//   type: string
+// responses:
+//   200:
+//     description: Curated providers
+//     schema:
+//       "$ref": "#/definitions/ProviderFavoritesDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (fe *favoritesEndpoint) List(c *gin.Context) {
+	favoritesOnly, _ := strconv.ParseBool(c.Query("favorites_only"))
+	entries, err := fe.storage.List(favorites.Filter{
+		FavoritesOnly: favoritesOnly,
+		Label:         c.Query("label"),
+	})
+	if err != nil {
+		c.Error(apierror.Internal("Could not list favorites: "+err.Error(), contract.ErrCodeFavoritesList))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderFavoritesDTO(entries), c.Writer)
+}
+
+// Get returns consumer curated details of the provider
+// swagger:operation GET /favorites/{id} Favorites favoritesGet
+// ---
+// summary: Returns consumer curated details of the provider
+// parameters:
+// - in: path
+//   name: id
+//   description: Provider identity
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Curated provider
+//     schema:
+//       "$ref": "#/definitions/ProviderFavoriteDTO"
+//   404:
+//     description: Provider is not curated
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (fe *favoritesEndpoint) Get(c *gin.Context) {
+	entry, err := fe.storage.Get(c.Param("id"))
+	if errors.Is(err, favorites.ErrNotFound) {
+		c.Error(apierror.NotFound("Provider is not curated"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not get favorite: "+err.Error(), contract.ErrCodeFavoritesGet))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderFavoriteDTO(entry), c.Writer)
+}
+
+// Save stores consumer curated details of the provider
+// swagger:operation PUT /favorites/{id} Favorites favoritesSave
+// ---
+// summary: Stores consumer curated details of the provider
+// description: Marks provider as favorite and stores personal note and labels, request without any details removes the provider from the lists
+// parameters:
+// - in: path
+//   name: id
+//   description: Provider identity
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   required: true
+//   schema:
+//     $ref: "#/definitions/ProviderFavoriteRequestDTO"
+// responses:
+//   200:
+//     description: Curated provider
+//     schema:
+//       "$ref": "#/definitions/ProviderFavoriteDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (fe *favoritesEndpoint) Save(c *gin.Context) {
+	var req contract.ProviderFavoriteRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	entry, err := fe.storage.Save(req.ToEntry(c.Param("id")))
+	if err != nil {
+		c.Error(apierror.Internal("Could not save favorite: "+err.Error(), contract.ErrCodeFavoritesSave))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderFavoriteDTO(entry), c.Writer)
+}
+
+// Delete removes consumer curated details of the provider
+// swagger:operation DELETE /favorites/{id} Favorites favoritesDelete
+// ---
+// summary: Removes consumer curated details of the provider
+// parameters:
+// - in: path
+//   name: id
+//   description: Provider identity
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Provider removed from the lists
+//   404:
+//     description: Provider is not curated
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (fe *favoritesEndpoint) Delete(c *gin.Context) {
+	err := fe.storage.Delete(c.Param("id"))
+	if errors.Is(err, favorites.ErrNotFound) {
+		c.Error(apierror.NotFound("Provider is not curated"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not delete favorite: "+err.Error(), contract.ErrCodeFavoritesDelete))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// AddRoutesForFavorites attaches consumer curated provider lists endpoints to router
+func AddRoutesForFavorites(storage favoritesStorage) func(*gin.Engine) error {
+	fe := &favoritesEndpoint{storage: storage}
+	return func(e *gin.Engine) error {
+		g := e.Group("/favorites")
+		{
+			g.GET("", fe.List)
+			g.GET("/:id", fe.Get)
+			g.PUT("/:id", fe.Save)
+			g.DELETE("/:id", fe.Delete)
+		}
+		return nil
+	}
+}
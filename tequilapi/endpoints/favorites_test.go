@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/consumer/favorites"
+)
+
+type mockFavoritesStorage struct {
+	entries map[string]favorites.Entry
+	filter  favorites.Filter
+}
+
+func (m *mockFavoritesStorage) Save(entry favorites.Entry) (favorites.Entry, error) {
+	entry.UpdatedAt = time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	m.entries[entry.ProviderID] = entry
+	return entry, nil
+}
+
+func (m *mockFavoritesStorage) Get(providerID string) (favorites.Entry, error) {
+	entry, ok := m.entries[providerID]
+	if !ok {
+		return favorites.Entry{}, favorites.ErrNotFound
+	}
+	return entry, nil
+}
+
+func (m *mockFavoritesStorage) Delete(providerID string) error {
+	if _, ok := m.entries[providerID]; !ok {
+		return favorites.ErrNotFound
+	}
+	delete(m.entries, providerID)
+	return nil
+}
+
+func (m *mockFavoritesStorage) List(filter favorites.Filter) ([]favorites.Entry, error) {
+	m.filter = filter
+	var result []favorites.Entry
+	for _, entry := range m.entries {
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+func Test_FavoritesEndpoint(t *testing.T) {
+	storage := &mockFavoritesStorage{entries: map[string]favorites.Entry{}}
+	router := summonTestGin()
+	err := AddRoutesForFavorites(storage)(router)
+	assert.NoError(t, err)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := serve(http.MethodPut, "/favorites/0x1", `{"favorite": true, "note": "fast", "labels": ["work"]}`)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"provider_id": "0x1", "favorite": true, "note": "fast", "labels": ["work"], "updated_at": "2022-10-01T12:00:00Z"}`, resp.Body.String())
+
+	resp = serve(http.MethodPut, "/favorites/0x1", `{"labels": ["`+strings.Repeat("a", 51)+`"]}`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = serve(http.MethodGet, "/favorites/0x1", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = serve(http.MethodGet, "/favorites?favorites_only=true&label=work", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, favorites.Filter{FavoritesOnly: true, Label: "work"}, storage.filter)
+	assert.JSONEq(t, `{"entries": [{"provider_id": "0x1", "favorite": true, "note": "fast", "labels": ["work"], "updated_at": "2022-10-01T12:00:00Z"}]}`, resp.Body.String())
+
+	resp = serve(http.MethodDelete, "/favorites/0x1", "")
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	resp = serve(http.MethodGet, "/favorites/0x1", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = serve(http.MethodDelete, "/favorites/0x1", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
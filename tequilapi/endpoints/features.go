@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/feature"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type featureProvider interface {
+	Features() []feature.Status
+}
+
+type featuresEndpoint struct {
+	features featureProvider
+}
+
+// Features lists experimental features and their state on the node
+// swagger:operation GET /features Features listFeatures
+// ---
+// summary: Returns experimental features
+// description: Returns experimental features and whether they are enabled on this node
+// responses:
+//   200:
+//     description: List of features
+//     schema:
+//       "$ref": "#/definitions/FeatureListResponse"
+func (fe *featuresEndpoint) Features(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewFeatureListResponse(fe.features.Features()), c.Writer)
+}
+
+// AddRoutesForFeatures attaches feature endpoints to router
+func AddRoutesForFeatures(features featureProvider) func(*gin.Engine) error {
+	fe := &featuresEndpoint{features: features}
+	return func(e *gin.Engine) error {
+		e.GET("/features", fe.Features)
+		return nil
+	}
+}
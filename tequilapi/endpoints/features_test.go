@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/feature"
+)
+
+type mockFeatureProvider []feature.Status
+
+func (m mockFeatureProvider) Features() []feature.Status {
+	return m
+}
+
+func Test_FeaturesEndpoint_Features(t *testing.T) {
+	router := gin.Default()
+	err := AddRoutesForFeatures(mockFeatureProvider{
+		{Name: feature.PaymentAggregation, Description: "Aggregated payments", Enabled: true, Source: feature.SourceRemote, Rollout: 25},
+		{Name: feature.TraversalStrategies, Source: feature.SourceDefault},
+	})(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/features", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"features": [
+			{"name": "payment-aggregation", "description": "Aggregated payments", "enabled": true, "source": "remote", "rollout": 25},
+			{"name": "traversal-strategies", "enabled": false, "source": "default"}
+		]
+	}`, resp.Body.String())
+}
@@ -22,27 +22,35 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/mysteriumnetwork/node/core/supervision"
 	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
+type subsystemSupervisor interface {
+	Degraded() []supervision.SubsystemStatus
+}
+
 type healthCheckEndpoint struct {
 	startTime       time.Time
 	currentTimeFunc func() time.Time
 	processNumber   int
+	supervisor      subsystemSupervisor
 }
 
 /*
 HealthCheckEndpointFactory creates a structure with single HealthCheck method for healthcheck serving as http,
-currentTimeFunc is injected for easier testing
+currentTimeFunc is injected for easier testing, supervisor reports the degraded subsystems and may be nil
 */
-func HealthCheckEndpointFactory(currentTimeFunc func() time.Time, procID func() int) *healthCheckEndpoint {
+func HealthCheckEndpointFactory(currentTimeFunc func() time.Time, procID func() int, supervisor subsystemSupervisor) *healthCheckEndpoint {
 	startTime := currentTimeFunc()
 	return &healthCheckEndpoint{
 		startTime,
 		currentTimeFunc,
 		procID(),
+		supervisor,
 	}
 }
 
@@ -65,6 +73,10 @@ func (hce *healthCheckEndpoint) HealthCheck(c *gin.Context) {
 			Branch:      metadata.BuildBranch,
 			BuildNumber: metadata.BuildNumber,
 		},
+		WireguardBackend: string(wireguard.ActiveBackend()),
+	}
+	if hce.supervisor != nil {
+		status.DegradedSubsystems = contract.NewDegradedSubsystemsDTO(hce.supervisor.Degraded())
 	}
 	utils.WriteAsJSON(status, c.Writer)
 }
@@ -18,6 +18,7 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -25,7 +26,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/mysteriumnetwork/node/core/supervision"
 	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -45,6 +48,7 @@ func TestHealthCheckReturnsExpectedJSONObject(t *testing.T) {
 	handlerFunc := HealthCheckEndpointFactory(
 		newMockTimer([]time.Time{tick1, tick2}).Now,
 		func() int { return 1 },
+		nil,
 	).HealthCheck
 	g.GET("/healthcheck", handlerFunc)
 
@@ -68,6 +72,30 @@ func TestHealthCheckReturnsExpectedJSONObject(t *testing.T) {
 		resp.Body.String())
 }
 
+func TestHealthCheckReportsDegradedSubsystems(t *testing.T) {
+	g := gin.Default()
+	g.GET("/healthcheck", HealthCheckEndpointFactory(time.Now, func() int { return 1 }, mockSupervisor{
+		{Name: "payments", Panics: 2, LastPanic: "boom", LastPanicAt: time.Unix(60, 0)},
+	}).HealthCheck)
+
+	req, err := http.NewRequest("GET", "/healthcheck", nil)
+	assert.NoError(t, err)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	var status contract.HealthCheckDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &status))
+	assert.Equal(t, []contract.DegradedSubsystemDTO{
+		{Name: "payments", Panics: 2, LastPanic: "boom", LastPanicAt: "1970-01-01T00:01:00Z"},
+	}, status.DegradedSubsystems)
+}
+
+type mockSupervisor []supervision.SubsystemStatus
+
+func (m mockSupervisor) Degraded() []supervision.SubsystemStatus {
+	return m
+}
+
 type mockTimer struct {
 	values  []time.Time
 	current int
@@ -35,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	identity_selector "github.com/mysteriumnetwork/node/identity/selector"
+	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
@@ -48,6 +49,7 @@ type balanceProvider interface {
 
 type earningsProvider interface {
 	GetEarningsDetailed(chainID int64, id identity.Identity) *pingpong_event.EarningsDetailed
+	List(chainID int64) []pingpong.HermesChannel
 }
 
 type beneficiaryProvider interface {
@@ -75,6 +77,12 @@ type identitiesAPI struct {
 	bprovider        beneficiaryProvider
 	addressStorage   *payout.AddressStorage
 	hermesMigrator   *migration.HermesMigrator
+	providerMigrator providerMigrator
+}
+
+type providerMigrator interface {
+	Start(chainID int64, id identity.Identity, oldHermes, newHermes common.Address) (migration.ProviderMigration, error)
+	Status(chainID int64, id identity.Identity) (migration.ProviderMigration, bool)
 }
 
 // AddressProvider provides sc addresses.
@@ -325,30 +333,91 @@ func (ia *identitiesAPI) Get(c *gin.Context) {
 	}
 
 	chainID := config.GetInt64(config.FlagChainID)
-	regStatus, err := ia.registry.GetRegistrationStatus(chainID, id)
+	defaultHermesID, err := ia.addressProvider.GetActiveHermes(chainID)
 	if err != nil {
-		c.Error(apierror.Internal("Failed to check ID registration status: "+err.Error(), contract.ErrCodeIDRegistrationCheck))
+		c.Error(apierror.Internal("Could not get active hermes: "+err.Error(), contract.ErrCodeActiveHermes))
 		return
 	}
 
-	channelAddress, err := ia.addressProvider.GetActiveChannelAddress(chainID, id.ToCommonAddress())
+	status, err := ia.identityStatus(chainID, defaultHermesID, id, nil)
 	if err != nil {
-		c.Error(apierror.Internal("Failed to calculate channel address: "+err.Error(), contract.ErrCodeIDCalculateAddress))
+		c.Error(err)
 		return
 	}
+	utils.WriteAsJSON(status, c.Writer)
+}
 
+// swagger:operation GET /identities-status Identity listIdentityStatuses
+// ---
+// summary: Get status of all identities
+// description: Provides details of all local identities in a single call, identities which could not be resolved are listed with an error
+// responses:
+//   200:
+//     description: Identities retrieved
+//     schema:
+//       "$ref": "#/definitions/ListIdentityStatusesResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ia *identitiesAPI) ListStatuses(c *gin.Context) {
+	chainID := config.GetInt64(config.FlagChainID)
 	defaultHermesID, err := ia.addressProvider.GetActiveHermes(chainID)
 	if err != nil {
 		c.Error(apierror.Internal("Could not get active hermes: "+err.Error(), contract.ErrCodeActiveHermes))
 		return
 	}
 
+	channels := make(map[identity.Identity]client.ProviderChannel)
+	for _, ch := range ia.earningsProvider.List(chainID) {
+		if ch.HermesID == defaultHermesID {
+			channels[ch.Identity] = ch.Channel
+		}
+	}
+
+	ids := ia.idm.GetIdentities()
+	statuses := make([]contract.IdentityStatusDTO, len(ids))
+	for i, id := range ids {
+		status, err := ia.identityStatus(chainID, defaultHermesID, id, channels)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to resolve status of identity %s", id.Address)
+			msg := err.Error()
+			var apiErr *apierror.APIError
+			if errors.As(err, &apiErr) {
+				msg = apiErr.Message()
+			}
+			statuses[i] = contract.IdentityStatusDTO{
+				IdentityDTO: contract.IdentityDTO{Address: id.Address},
+				Error:       msg,
+			}
+			continue
+		}
+		statuses[i] = contract.IdentityStatusDTO{IdentityDTO: status}
+	}
+
+	utils.WriteAsJSON(contract.ListIdentityStatusesResponse{Identities: statuses}, c.Writer)
+}
+
+// identityStatus resolves details of the identity, known channels with the default hermes are used instead of querying blockchain for the stake.
+func (ia *identitiesAPI) identityStatus(chainID int64, defaultHermesID common.Address, id identity.Identity, channels map[identity.Identity]client.ProviderChannel) (contract.IdentityDTO, error) {
+	regStatus, err := ia.registry.GetRegistrationStatus(chainID, id)
+	if err != nil {
+		return contract.IdentityDTO{}, apierror.Internal("Failed to check ID registration status: "+err.Error(), contract.ErrCodeIDRegistrationCheck)
+	}
+
+	channelAddress, err := ia.addressProvider.GetActiveChannelAddress(chainID, id.ToCommonAddress())
+	if err != nil {
+		return contract.IdentityDTO{}, apierror.Internal("Failed to calculate channel address: "+err.Error(), contract.ErrCodeIDCalculateAddress)
+	}
+
 	var stake = new(big.Int)
 	if regStatus == registry.Registered {
-		data, err := ia.bc.GetProviderChannel(chainID, defaultHermesID, common.HexToAddress(address), false)
-		if err != nil {
-			c.Error(apierror.Internal("Failed to check blockchain registration status: "+err.Error(), contract.ErrCodeIDBlockchainRegistrationCheck))
-			return
+		data, ok := channels[id]
+		if !ok {
+			data, err = ia.bc.GetProviderChannel(chainID, defaultHermesID, id.ToCommonAddress(), false)
+			if err != nil {
+				return contract.IdentityDTO{}, apierror.Internal("Failed to check blockchain registration status: "+err.Error(), contract.ErrCodeIDBlockchainRegistrationCheck)
+			}
 		}
 		stake = data.Stake
 	}
@@ -356,16 +425,8 @@ func (ia *identitiesAPI) Get(c *gin.Context) {
 	balance := ia.balanceProvider.GetBalance(chainID, id)
 	earnings := ia.earningsProvider.GetEarningsDetailed(chainID, id)
 
-	settlementsPerHermes := make(map[string]contract.EarningsDTO)
-	for h, earn := range earnings.PerHermes {
-		settlementsPerHermes[h.Hex()] = contract.EarningsDTO{
-			Earnings:      contract.NewTokens(earn.UnsettledBalance),
-			EarningsTotal: contract.NewTokens(earn.LifetimeBalance),
-		}
-	}
-
-	status := contract.IdentityDTO{
-		Address:             address,
+	return contract.IdentityDTO{
+		Address:             id.Address,
 		RegistrationStatus:  regStatus.String(),
 		ChannelAddress:      channelAddress.Hex(),
 		Balance:             balance,
@@ -377,8 +438,7 @@ func (ia *identitiesAPI) Get(c *gin.Context) {
 		Stake:               stake,
 		HermesID:            defaultHermesID.Hex(),
 		EarningsPerHermes:   contract.NewEarningsPerHermesDTO(earnings.PerHermes),
-	}
-	utils.WriteAsJSON(status, c.Writer)
+	}, nil
 }
 
 // swagger:operation GET /identities/{id}/registration Identity identityRegistration
@@ -675,6 +735,133 @@ func (ia *identitiesAPI) MigrationHermesStatus(c *gin.Context) {
 	utils.WriteAsJSON(contract.MigrationStatusResponse{Status: status}, c.Writer)
 }
 
+// swagger:operation POST /identities/:id/migrate-hermes/provider Identity MigrateProviderHermes
+// ---
+// summary: Migrate provider to another Hermes
+// description: Starts or resumes provider migration to the given Hermes. Promises with the current Hermes
+//   are settled, channel with the new Hermes is opened and sessions are accepted through the new Hermes
+//   only after it is verified.
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Hermes to migrate to
+//   schema:
+//     $ref: "#/definitions/ProviderHermesMigrationRequest"
+// responses:
+//   202:
+//     description: Migration started
+//     schema:
+//       "$ref": "#/definitions/ProviderHermesMigrationResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   403:
+//     description: Identity is locked
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Migration to another Hermes is in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ia *identitiesAPI) MigrateProviderHermes(c *gin.Context) {
+	id := c.Param("id")
+	if !ia.idm.IsUnlocked(id) {
+		c.Error(apierror.Forbidden("Identity is locked", contract.ErrCodeIDLocked))
+		return
+	}
+
+	var req contract.ProviderHermesMigrationRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if !common.IsHexAddress(req.HermesID) {
+		c.Error(apierror.BadRequest("Invalid hermes ID", contract.ErrCodeProviderHermesMigration))
+		return
+	}
+
+	chainID := config.GetInt64(config.FlagChainID)
+	newHermes := common.HexToAddress(req.HermesID)
+	known, err := ia.addressProvider.GetKnownHermeses(chainID)
+	if err != nil {
+		c.Error(apierror.Internal("Could not get known hermeses", contract.ErrCodeProviderHermesMigration))
+		return
+	}
+	if !containsAddress(known, newHermes) {
+		c.Error(apierror.BadRequest("Unknown hermes", contract.ErrCodeProviderHermesMigration))
+		return
+	}
+
+	activeHermes, err := ia.addressProvider.GetActiveHermes(chainID)
+	if err != nil {
+		c.Error(apierror.Internal("Could not get active hermes", contract.ErrCodeProviderHermesMigration))
+		return
+	}
+
+	pm, err := ia.providerMigrator.Start(chainID, identity.FromAddress(id), activeHermes, newHermes)
+	if err != nil {
+		if errors.Is(err, migration.ErrProviderMigrationInProgress) {
+			c.Error(apierror.Conflict(err.Error(), contract.ErrCodeProviderHermesMigration, "hermes_id"))
+			return
+		}
+		c.Error(apierror.Internal(err.Error(), contract.ErrCodeProviderHermesMigration))
+		log.Err(err).Msgf("Could not start provider %s migration", id)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderHermesMigrationResponse(pm), c.Writer, http.StatusAccepted)
+}
+
+// swagger:operation GET /identities/:id/migrate-hermes/provider Identity ProviderHermesMigrationStatus
+// ---
+// summary: Provider Hermes migration status
+// description: Returns the state of provider migration to another Hermes
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity stored in keystore
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Migration state
+//     schema:
+//       "$ref": "#/definitions/ProviderHermesMigrationResponse"
+//   404:
+//     description: Migration was never started
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ia *identitiesAPI) ProviderHermesMigrationStatus(c *gin.Context) {
+	id := c.Param("id")
+	pm, found := ia.providerMigrator.Status(config.GetInt64(config.FlagChainID), identity.FromAddress(id))
+	if !found {
+		c.Error(apierror.NotFound("Provider migration not found"))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderHermesMigrationResponse(pm), c.Writer)
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
 func isBenenficiarySetToChannel(addressProvider addressProvider, chainID int64, identity, beneficiary common.Address) (bool, error) {
 	hermeses, err := addressProvider.GetKnownHermeses(chainID)
 	if err != nil {
@@ -707,6 +894,7 @@ func AddRoutesForIdentities(
 	mover identityMover,
 	addressStorage *payout.AddressStorage,
 	hermesMigrator *migration.HermesMigrator,
+	providerMigrator *migration.ProviderMigrator,
 ) func(*gin.Engine) error {
 	idAPI := &identitiesAPI{
 		mover:            mover,
@@ -721,6 +909,7 @@ func AddRoutesForIdentities(
 		bprovider:        bprovider,
 		addressStorage:   addressStorage,
 		hermesMigrator:   hermesMigrator,
+		providerMigrator: providerMigrator,
 	}
 	return func(e *gin.Engine) error {
 		identityGroup := e.Group("/identities")
@@ -738,8 +927,11 @@ func AddRoutesForIdentities(
 			identityGroup.PUT("/:id/balance/refresh", idAPI.BalanceRefresh)
 			identityGroup.POST("/:id/migrate-hermes", idAPI.MigrateHermes)
 			identityGroup.GET("/:id/migrate-hermes/status", idAPI.MigrationHermesStatus)
+			identityGroup.POST("/:id/migrate-hermes/provider", idAPI.MigrateProviderHermes)
+			identityGroup.GET("/:id/migrate-hermes/provider", idAPI.ProviderHermesMigrationStatus)
 		}
 		e.POST("/identities-import", idAPI.Import)
+		e.GET("/identities-status", idAPI.ListStatuses)
 		return nil
 	}
 }
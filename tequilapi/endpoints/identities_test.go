@@ -19,6 +19,8 @@ package endpoints
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
@@ -27,11 +29,12 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gin-gonic/gin"
-	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/errcode"
 	"github.com/mysteriumnetwork/payments/client"
 	"github.com/stretchr/testify/assert"
 )
@@ -59,7 +62,7 @@ type selectorFake struct {
 
 func summonTestGin() *gin.Engine {
 	g := gin.Default()
-	g.Use(apierror.ErrorHandler)
+	g.Use(errcode.ErrorHandler)
 	return g
 }
 
@@ -177,7 +180,8 @@ func TestUnlockIdentityWithNoPassphrase(t *testing.T) {
         "code": "required",
         "message": "'passphrase' is required"
       }
-    }
+    },
+    "category": "validation"
   },
   "status": 400,
   "path": "/identities/0x000000000000000000000000000000000000000a/unlock"
@@ -336,7 +340,8 @@ func TestCreateNewIdentityNoPassphrase(t *testing.T) {
         "code": "required",
         "message": "'passphrase' is required"
       }
-    }
+    },
+    "category": "validation"
   },
   "status": 400,
   "path": "/identities"
@@ -488,6 +493,129 @@ func Test_IdentityGet(t *testing.T) {
 		resp.Body.String())
 }
 
+func Test_IdentityListStatuses(t *testing.T) {
+	endpoint := &identitiesAPI{
+		idm:      identity.NewIdentityManagerFake(existingIdentities, newIdentity),
+		registry: &registry.FakeRegistry{RegistrationStatus: registry.Registered},
+		addressProvider: &mockAddressProvider{
+			channelAddressToReturn: common.HexToAddress("0x100000000000000000000000000000000000000a"),
+			hermesToReturn:         common.HexToAddress("0x200000000000000000000000000000000000000a"),
+		},
+		bc: &mockProviderChannelStatusProvider{
+			channelToReturn: client.ProviderChannel{Stake: big.NewInt(2)},
+		},
+		earningsProvider: &mockEarningsProvider{
+			earnings: pingpongEvent.EarningsDetailed{
+				Total: pingpongEvent.Earnings{
+					LifetimeBalance:  big.NewInt(100),
+					UnsettledBalance: big.NewInt(50),
+				},
+			},
+		},
+		balanceProvider: &mockBalanceProvider{
+			balance: big.NewInt(25),
+		},
+	}
+
+	router := gin.Default()
+	router.GET("/identities-status", endpoint.ListStatuses)
+
+	req, err := http.NewRequest(http.MethodGet, "/identities-status", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var statuses contract.ListIdentityStatusesResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &statuses))
+	assert.Len(t, statuses.Identities, len(existingIdentities))
+	for i, status := range statuses.Identities {
+		assert.Equal(t, existingIdentities[i].Address, status.Address)
+		assert.Equal(t, "Registered", status.RegistrationStatus)
+		assert.Equal(t, "0x100000000000000000000000000000000000000A", status.ChannelAddress)
+		assert.Equal(t, big.NewInt(25), status.Balance)
+		assert.Equal(t, big.NewInt(2), status.Stake)
+	}
+}
+
+func Test_IdentityListStatuses_UsesKnownChannels(t *testing.T) {
+	hermesID := common.HexToAddress("0x200000000000000000000000000000000000000a")
+	bc := &mockProviderChannelStatusProvider{
+		channelToReturn: client.ProviderChannel{Stake: big.NewInt(2)},
+	}
+	endpoint := &identitiesAPI{
+		idm:      identity.NewIdentityManagerFake(existingIdentities, newIdentity),
+		registry: &registry.FakeRegistry{RegistrationStatus: registry.Registered},
+		addressProvider: &mockAddressProvider{
+			channelAddressToReturn: common.HexToAddress("0x100000000000000000000000000000000000000a"),
+			hermesToReturn:         hermesID,
+		},
+		bc: bc,
+		earningsProvider: &mockEarningsProvider{
+			channels: []pingpong.HermesChannel{
+				{Identity: existingIdentities[0], HermesID: hermesID, Channel: client.ProviderChannel{Stake: big.NewInt(7)}},
+				{Identity: existingIdentities[1], HermesID: common.HexToAddress("0x3"), Channel: client.ProviderChannel{Stake: big.NewInt(9)}},
+			},
+		},
+		balanceProvider: &mockBalanceProvider{balance: big.NewInt(25)},
+	}
+
+	router := gin.Default()
+	router.GET("/identities-status", endpoint.ListStatuses)
+
+	req, err := http.NewRequest(http.MethodGet, "/identities-status", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var statuses contract.ListIdentityStatusesResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &statuses))
+	assert.Len(t, statuses.Identities, 2)
+	assert.Equal(t, big.NewInt(7), statuses.Identities[0].Stake)
+	assert.Equal(t, big.NewInt(2), statuses.Identities[1].Stake)
+	assert.Equal(t, []common.Address{existingIdentities[1].ToCommonAddress()}, bc.queried)
+}
+
+func Test_IdentityListStatuses_ReturnsErrorPerIdentity(t *testing.T) {
+	endpoint := &identitiesAPI{
+		idm:      identity.NewIdentityManagerFake(existingIdentities, newIdentity),
+		registry: &registry.FakeRegistry{RegistrationStatus: registry.Registered},
+		addressProvider: &mockAddressProvider{
+			channelAddressToReturn: common.HexToAddress("0x100000000000000000000000000000000000000a"),
+			hermesToReturn:         common.HexToAddress("0x200000000000000000000000000000000000000a"),
+		},
+		bc: &mockProviderChannelStatusProvider{
+			channelToReturn: client.ProviderChannel{Stake: big.NewInt(2)},
+			errToReturn: map[common.Address]error{
+				existingIdentities[0].ToCommonAddress(): errors.New("rpc unavailable"),
+			},
+		},
+		earningsProvider: &mockEarningsProvider{},
+		balanceProvider:  &mockBalanceProvider{balance: big.NewInt(25)},
+	}
+
+	router := gin.Default()
+	router.GET("/identities-status", endpoint.ListStatuses)
+
+	req, err := http.NewRequest(http.MethodGet, "/identities-status", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var statuses contract.ListIdentityStatusesResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &statuses))
+	assert.Len(t, statuses.Identities, 2)
+	assert.Equal(t, existingIdentities[0].Address, statuses.Identities[0].Address)
+	assert.Equal(t, "Failed to check blockchain registration status: rpc unavailable", statuses.Identities[0].Error)
+	assert.Empty(t, statuses.Identities[1].Error)
+	assert.Equal(t, big.NewInt(2), statuses.Identities[1].Stake)
+}
+
 type mockAddressProvider struct {
 	hermesToReturn         common.Address
 	registryToReturn       common.Address
@@ -529,9 +657,15 @@ func (ma *mockAddressProvider) GetKnownHermeses(chainID int64) ([]common.Address
 
 type mockProviderChannelStatusProvider struct {
 	channelToReturn client.ProviderChannel
+	errToReturn     map[common.Address]error
+	queried         []common.Address
 }
 
 func (m *mockProviderChannelStatusProvider) GetProviderChannel(chainID int64, hermesAddress common.Address, provider common.Address, pending bool) (client.ProviderChannel, error) {
+	m.queried = append(m.queried, provider)
+	if err, ok := m.errToReturn[provider]; ok {
+		return client.ProviderChannel{}, err
+	}
 	return m.channelToReturn, nil
 }
 
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type keystoreHealthReporter interface {
+	Report() (identity.KeystoreHealthReport, bool)
+}
+
+type keystoreEndpoint struct {
+	reporter keystoreHealthReporter
+}
+
+// Health returns the result of the keystore check
+// swagger:operation GET /keystore/health Identity keystoreHealth
+// ---
+// summary: Returns keystore health
+// description: Returns corrupt and duplicate key files found in the keystore directory on startup and whether corrupt ones were quarantined
+// responses:
+//   200:
+//     description: Keystore health
+//     schema:
+//       "$ref": "#/definitions/KeystoreHealthDTO"
+//   404:
+//     description: Keystore was not checked
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ke *keystoreEndpoint) Health(c *gin.Context) {
+	report, ok := ke.reporter.Report()
+	if !ok {
+		c.Error(apierror.NotFound("Keystore was not checked"))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewKeystoreHealthDTO(report), c.Writer)
+}
+
+// AddRoutesForKeystore attaches keystore endpoints to router.
+func AddRoutesForKeystore(reporter keystoreHealthReporter) func(*gin.Engine) error {
+	ke := &keystoreEndpoint{reporter: reporter}
+	return func(e *gin.Engine) error {
+		e.GET("/keystore/health", ke.Health)
+		return nil
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockKeystoreHealthReporter struct {
+	report  identity.KeystoreHealthReport
+	checked bool
+}
+
+func (m mockKeystoreHealthReporter) Report() (identity.KeystoreHealthReport, bool) {
+	return m.report, m.checked
+}
+
+func TestKeystoreHealthEndpoint(t *testing.T) {
+	reporter := mockKeystoreHealthReporter{
+		checked: true,
+		report: identity.KeystoreHealthReport{
+			Keys: []string{"0x000000000000000000000000000000000000000a"},
+			Issues: []identity.KeyFileIssue{
+				{File: "corrupt", Problem: "corrupt key file", Quarantined: "/keystore/.quarantine/corrupt"},
+			},
+		},
+	}
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForKeystore(reporter)(router))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/keystore/health", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var res contract.KeystoreHealthDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.False(t, res.Healthy)
+	assert.Equal(t, []string{"0x000000000000000000000000000000000000000a"}, res.Keys)
+	assert.Equal(t, []contract.KeyFileIssueDTO{
+		{File: "corrupt", Problem: "corrupt key file", Quarantined: "/keystore/.quarantine/corrupt"},
+	}, res.Issues)
+}
+
+func TestKeystoreHealthEndpoint_NotChecked(t *testing.T) {
+	router := summonTestGin()
+	assert.NoError(t, AddRoutesForKeystore(mockKeystoreHealthReporter{})(router))
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/keystore/health", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
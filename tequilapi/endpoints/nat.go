@@ -78,6 +78,35 @@ func (ne *NATEndpoint) NATType(c *gin.Context) {
 	}, c.Writer)
 }
 
+// NATTimeline provides the latest NAT traversal attempts
+// swagger:operation GET /nat/timeline NAT NATTimelineDTO
+// ---
+// summary: Shows the latest NAT traversal attempts.
+// description: Returns the timeline of traversal stages with their outcomes for each of the latest NAT traversal attempts
+// responses:
+//   200:
+//     description: NAT traversal timeline
+//     schema:
+//       "$ref": "#/definitions/NATTimelineDTO"
+func (ne *NATEndpoint) NATTimeline(c *gin.Context) {
+	traversals := ne.stateProvider.GetState().NATTraversals
+
+	res := contract.NATTimelineDTO{Traversals: make([]contract.NATTraversalDTO, len(traversals))}
+	for i, traversal := range traversals {
+		stages := make([]contract.NATTraversalStageDTO, len(traversal.Stages))
+		for j, stage := range traversal.Stages {
+			stages[j] = contract.NATTraversalStageDTO{
+				Stage:      stage.Stage,
+				Successful: stage.Successful,
+				Error:      stage.Error,
+				CreatedAt:  formatTime(stage.CreatedAt),
+			}
+		}
+		res.Traversals[i] = contract.NATTraversalDTO{ID: traversal.ID, Stages: stages}
+	}
+	utils.WriteAsJSON(res, c.Writer)
+}
+
 // AddRoutesForNAT adds nat routes to given router
 func AddRoutesForNAT(stateProvider stateProvider, natProber natProber) func(*gin.Engine) error {
 	natEndpoint := NewNATEndpoint(stateProvider, natProber)
@@ -86,6 +115,7 @@ func AddRoutesForNAT(stateProvider stateProvider, natProber natProber) func(*gin
 		v1Group := e.Group("/nat")
 		{
 			v1Group.GET("/type", natEndpoint.NATType)
+			v1Group.GET("/timeline", natEndpoint.NATTimeline)
 		}
 		return nil
 	}
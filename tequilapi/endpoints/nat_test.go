@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/state/event"
+)
+
+func TestNATTimelineEndpointReturnsTraversals(t *testing.T) {
+	createdAt := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	fakeState := &mockStateProvider{stateToReturn: event.State{
+		NATTraversals: []event.NATTraversal{{
+			ID: "attempt-1",
+			Stages: []event.NATTraversalStage{
+				{Stage: "hole_punching", Successful: false, Error: "too few connections", CreatedAt: createdAt},
+				{Stage: "hole_punching", Successful: true, CreatedAt: createdAt.Add(time.Second)},
+			},
+		}},
+	}}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/nat/timeline", nil)
+
+	g := summonTestGin()
+	err := AddRoutesForNAT(fakeState, nil)(g)
+	assert.NoError(t, err)
+
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"traversals": [{
+				"id": "attempt-1",
+				"stages": [
+					{"stage": "hole_punching", "successful": false, "error": "too few connections", "created_at": "2022-06-01T12:00:00Z"},
+					{"stage": "hole_punching", "successful": true, "created_at": "2022-06-01T12:00:01Z"}
+				]
+			}]
+		}`,
+		resp.Body.String(),
+	)
+}
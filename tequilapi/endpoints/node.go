@@ -45,6 +45,7 @@ type nodeMonitoringAgent interface {
 	TransferredDataSeries(rangeTime string) (node.TransferredDataSeries, error)
 	ProviderActivityStats() (node.ActivityStats, error)
 	ProviderQuality() (node.QualityInfo, error)
+	ProviderReputation() (node.Reputation, error)
 	EarningsPerService() (node.EarningsPerService, error)
 }
 
@@ -420,6 +421,30 @@ func (ne *NodeEndpoint) GetProviderQuality(c *gin.Context) {
 	utils.WriteAsJSON(res, c.Writer)
 }
 
+// GetProviderReputation is a ranking of provider among the other providers
+// swagger:operation GET /node/provider/reputation provider GetProviderReputation
+// ---
+// summary: Provides Node reputation
+// description: Node ranking and per service quality metrics as seen by quality oracle
+// responses:
+//   200:
+//     description: Provider reputation
+//     schema:
+//       "$ref": "#/definitions/ProviderReputationResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ne *NodeEndpoint) GetProviderReputation(c *gin.Context) {
+	res, err := ne.nodeMonitoringAgent.ProviderReputation()
+	if err != nil {
+		c.Error(apierror.Internal("Could not get provider reputation: "+err.Error(), contract.ErrorCodeProviderReputation))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderReputationResponse(res), c.Writer)
+}
+
 // GetProviderActivityStats is an activity stats of provider
 // swagger:operation GET /node/provider/activity-stats provider GetProviderActivityStats
 // ---
@@ -539,6 +564,7 @@ func AddRoutesForNode(nodeStatusProvider nodeStatusProvider, nodeMonitoringAgent
 			nodeGroup.GET("/provider/service-earnings", nodeEndpoints.GetProviderServiceEarnings)
 			nodeGroup.GET("/latest-release", nodeEndpoints.GetLatestRelease)
 			nodeGroup.GET("/provider/quality", nodeEndpoints.GetProviderQuality)
+			nodeGroup.GET("/provider/reputation", nodeEndpoints.GetProviderReputation)
 			nodeGroup.GET("/provider/activity-stats", nodeEndpoints.GetProviderActivityStats)
 		}
 		return nil
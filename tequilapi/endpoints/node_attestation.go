@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type nodeAttester interface {
+	PublicKey() (string, error)
+	RotateKey() (string, error)
+}
+
+type nodeAttestationEndpoint struct {
+	attester nodeAttester
+}
+
+// swagger:operation GET /node/attestation/key provider getNodeAttestationKey
+// ---
+// summary: Returns the node attestation key
+// description: Returns the public key signing the node metadata attached to the provider proposals
+// responses:
+//   200:
+//     description: Node attestation key
+//     schema:
+//       "$ref": "#/definitions/NodeAttestationKeyResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ne *nodeAttestationEndpoint) Key(c *gin.Context) {
+	publicKey, err := ne.attester.PublicKey()
+	if err != nil {
+		c.Error(apierror.Internal("Could not get node attestation key: "+err.Error(), contract.ErrCodeNodeAttestationKey))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NodeAttestationKeyResponse{PublicKey: publicKey}, c.Writer)
+}
+
+// swagger:operation POST /node/attestation/key/rotate provider rotateNodeAttestationKey
+// ---
+// summary: Rotates the node attestation key
+// description: Replaces the key signing the node metadata, proposals are attested with the new key on the next announcement
+// responses:
+//   200:
+//     description: New node attestation key
+//     schema:
+//       "$ref": "#/definitions/NodeAttestationKeyResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (ne *nodeAttestationEndpoint) RotateKey(c *gin.Context) {
+	publicKey, err := ne.attester.RotateKey()
+	if err != nil {
+		c.Error(apierror.Internal("Could not rotate node attestation key: "+err.Error(), contract.ErrCodeNodeAttestationKey))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NodeAttestationKeyResponse{PublicKey: publicKey}, c.Writer)
+}
+
+// AddRoutesForNodeAttestation attaches node attestation endpoints to router.
+func AddRoutesForNodeAttestation(attester nodeAttester) func(*gin.Engine) error {
+	ne := &nodeAttestationEndpoint{attester: attester}
+	return func(e *gin.Engine) error {
+		g := e.Group("/node/attestation")
+		{
+			g.GET("/key", ne.Key)
+			g.POST("/key/rotate", ne.RotateKey)
+		}
+		return nil
+	}
+}
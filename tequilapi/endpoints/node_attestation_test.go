@@ -0,0 +1,73 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockNodeAttester struct {
+	publicKey string
+	rotateErr error
+}
+
+func (m *mockNodeAttester) PublicKey() (string, error) {
+	return m.publicKey, nil
+}
+
+func (m *mockNodeAttester) RotateKey() (string, error) {
+	if m.rotateErr != nil {
+		return "", m.rotateErr
+	}
+	m.publicKey = "rotated"
+	return m.publicKey, nil
+}
+
+func Test_NodeAttestationEndpoint_RotateKey(t *testing.T) {
+	attester := &mockNodeAttester{publicKey: "initial"}
+	router := summonTestGin()
+	err := AddRoutesForNodeAttestation(attester)(router)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/node/attestation/key", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var key contract.NodeAttestationKeyResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &key))
+	assert.Equal(t, "initial", key.PublicKey)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/node/attestation/key/rotate", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &key))
+	assert.Equal(t, "rotated", key.PublicKey)
+
+	attester.rotateErr = errors.New("storage failure")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/node/attestation/key/rotate", nil))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
@@ -45,6 +45,7 @@ type mockMonitoringAgent struct {
 	sessionsSeries        node.SessionsSeries
 	transferredDataSeries node.TransferredDataSeries
 	providerQuality       node.QualityInfo
+	providerReputation    node.Reputation
 	providerActivityStats node.ActivityStats
 	serviceEarnings       node.EarningsPerService
 }
@@ -89,6 +90,10 @@ func (nodeMonitoringAgentTracker *mockMonitoringAgent) ProviderQuality() (node.Q
 	return nodeMonitoringAgentTracker.providerQuality, nil
 }
 
+func (nodeMonitoringAgentTracker *mockMonitoringAgent) ProviderReputation() (node.Reputation, error) {
+	return nodeMonitoringAgentTracker.providerReputation, nil
+}
+
 func (nodeMonitoringAgentTracker *mockMonitoringAgent) ProviderActivityStats() (node.ActivityStats, error) {
 	return nodeMonitoringAgentTracker.providerActivityStats, nil
 }
@@ -126,3 +131,49 @@ func Test_NodeStatus(t *testing.T) {
 		})
 	}
 }
+
+func Test_ProviderReputation(t *testing.T) {
+	// given:
+	mockMonitoringAgentTracker := &mockMonitoringAgent{
+		providerReputation: node.Reputation{
+			Quality:               2.5,
+			Rank:                  120,
+			ProvidersCount:        4000,
+			Country:               "DE",
+			CountryRank:           12,
+			CountryProvidersCount: 300,
+			Services: []node.ServiceReputation{
+				{ServiceType: "wireguard", Quality: 2.5, Latency: 85.3, Bandwidth: 45.2, Uptime: 0.98},
+			},
+			UpdatedAt: 1654084800,
+		},
+	}
+
+	router := gin.Default()
+	err := AddRoutesForNode(&mockNodeStatusProvider{}, mockMonitoringAgentTracker)(router)
+	assert.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "/node/provider/reputation", nil)
+	assert.NoError(t, err)
+
+	// when:
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	// then:
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"quality": 2.5,
+		"rank": 120,
+		"providers_count": 4000,
+		"top_percent": 3,
+		"country": "DE",
+		"country_rank": 12,
+		"country_providers_count": 300,
+		"country_top_percent": 4,
+		"services": [
+			{"service_type": "wireguard", "quality": 2.5, "latency": 85.3, "bandwidth": 45.2, "uptime": 0.98, "monitoring_failed": false}
+		],
+		"updated_at": "2022-06-01T12:00:00Z"
+	}`, resp.Body.String())
+}
@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/hooks"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type paymentHooks interface {
+	Deliveries(status hooks.DeliveryStatus) []hooks.Delivery
+}
+
+type paymentHooksEndpoint struct {
+	hooks paymentHooks
+}
+
+// Deliveries lists the deliveries of payment events to the operator endpoints
+// swagger:operation GET /node/payment-hooks/deliveries Provider paymentHookDeliveries
+// ---
+// summary: Returns payment event deliveries
+// description: Returns the most recent deliveries of payment events to the configured webhook endpoints, newest first.
+// responses:
+//   200:
+//     description: Payment event deliveries
+//     schema:
+//       "$ref": "#/definitions/PaymentHookDeliveriesResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (pe *paymentHooksEndpoint) Deliveries(c *gin.Context) {
+	var query contract.PaymentHookDeliveriesQuery
+	if err := query.Bind(c.Request); err != nil {
+		c.Error(err)
+		return
+	}
+
+	deliveries := pe.hooks.Deliveries(hooks.DeliveryStatus(query.Status))
+	utils.WriteAsJSON(contract.NewPaymentHookDeliveriesResponse(deliveries), c.Writer)
+}
+
+// AddRoutesForPaymentHooks attaches payment hooks endpoints to router
+func AddRoutesForPaymentHooks(hooks paymentHooks) func(*gin.Engine) error {
+	pe := &paymentHooksEndpoint{hooks: hooks}
+	return func(e *gin.Engine) error {
+		e.GET("/node/payment-hooks/deliveries", pe.Deliveries)
+		return nil
+	}
+}
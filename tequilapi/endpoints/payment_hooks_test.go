@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/hooks"
+)
+
+type mockPaymentHooks struct {
+	status     hooks.DeliveryStatus
+	deliveries []hooks.Delivery
+}
+
+func (m *mockPaymentHooks) Deliveries(status hooks.DeliveryStatus) []hooks.Delivery {
+	m.status = status
+	return m.deliveries
+}
+
+func Test_PaymentHooksEndpoint_Deliveries(t *testing.T) {
+	at := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	paymentHooks := &mockPaymentHooks{
+		deliveries: []hooks.Delivery{{
+			ID:             7,
+			Event:          hooks.EventSettlementCompleted,
+			URL:            "https://operator.example.com/payments",
+			Status:         hooks.DeliveryFailed,
+			Attempts:       6,
+			LastError:      "webhook responded with status 503",
+			LastStatusCode: http.StatusServiceUnavailable,
+			CreatedAt:      at,
+			UpdatedAt:      at.Add(time.Minute),
+		}},
+	}
+	router := summonTestGin()
+	err := AddRoutesForPaymentHooks(paymentHooks)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/payment-hooks/deliveries?status=failed", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, hooks.DeliveryFailed, paymentHooks.status)
+	assert.JSONEq(t, `{
+		"items": [{
+			"id": 7,
+			"event": "settlement_completed",
+			"url": "https://operator.example.com/payments",
+			"status": "failed",
+			"attempts": 6,
+			"last_error": "webhook responded with status 503",
+			"last_status_code": 503,
+			"created_at": "2022-10-01T12:00:00Z",
+			"updated_at": "2022-10-01T12:01:00Z"
+		}]
+	}`, resp.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/node/payment-hooks/deliveries?status=lost", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
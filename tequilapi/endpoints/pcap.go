@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/mysteriumnetwork/node/core/pcap"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type packetCapturer interface {
+	Start(sessionID string, duration time.Duration, maxBytes int64) (pcap.Capture, error)
+	List() []pcap.Capture
+	Get(id string) (pcap.Capture, error)
+}
+
+type pcapEndpoint struct {
+	capturer packetCapturer
+}
+
+// swagger:operation POST /debug/pcap Debug startPacketCapture
+// ---
+// summary: Starts packet capture of the session tunnel
+// description: Captures packets of the provider session tunnel interface into a pcap file for the given time or until the file size limit is reached.
+// parameters:
+// - in: body
+//   name: body
+//   description: Packet capture request
+//   schema:
+//     $ref: "#/definitions/PacketCaptureRequest"
+// responses:
+//   202:
+//     description: Packet capture started
+//     schema:
+//       "$ref": "#/definitions/PacketCaptureDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: Session tunnel not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Session tunnel is already being captured
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (pe *pcapEndpoint) Start(c *gin.Context) {
+	var req contract.PacketCaptureRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	capture, err := pe.capturer.Start(req.SessionID, time.Duration(req.DurationSeconds)*time.Second, int64(req.MaxMegabytes)<<20)
+	switch {
+	case errors.Is(err, pcap.ErrSessionNotFound):
+		c.Error(apierror.NotFound("Session tunnel not found"))
+		return
+	case errors.Is(err, pcap.ErrCaptureRunning):
+		c.Error(apierror.Conflict("Session tunnel is already being captured", contract.ErrCodeSessionPacketCapture, "session_id"))
+		return
+	case err != nil:
+		log.Err(err).Msg("Could not start packet capture")
+		c.Error(apierror.Internal("Could not start packet capture: "+err.Error(), contract.ErrCodeSessionPacketCapture))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewPacketCaptureDTO(capture), c.Writer, http.StatusAccepted)
+}
+
+// swagger:operation GET /debug/pcap Debug listPacketCaptures
+// ---
+// summary: Returns packet captures
+// description: Returns packet captures started since the node start, the most recent first
+// responses:
+//   200:
+//     description: Packet captures
+//     schema:
+//       "$ref": "#/definitions/PacketCaptureListResponse"
+func (pe *pcapEndpoint) List(c *gin.Context) {
+	res := contract.PacketCaptureListResponse{Items: []contract.PacketCaptureDTO{}}
+	for _, capture := range pe.capturer.List() {
+		res.Items = append(res.Items, contract.NewPacketCaptureDTO(capture))
+	}
+
+	utils.WriteAsJSON(res, c.Writer)
+}
+
+// swagger:operation GET /debug/pcap/{id}/file Debug downloadPacketCapture
+// ---
+// summary: Downloads packet capture file
+// description: Returns the pcap file of the packet capture
+// parameters:
+// - in: path
+//   name: id
+//   description: Packet capture ID
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Packet capture file
+//   404:
+//     description: Packet capture not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (pe *pcapEndpoint) File(c *gin.Context) {
+	capture, err := pe.capturer.Get(c.Param("id"))
+	if err != nil {
+		c.Error(apierror.NotFound("Packet capture not found"))
+		return
+	}
+
+	c.FileAttachment(capture.Path, filepath.Base(capture.Path))
+}
+
+// AddRoutesForPcap attaches packet capture endpoints to router.
+func AddRoutesForPcap(capturer packetCapturer) func(*gin.Engine) error {
+	pe := &pcapEndpoint{capturer: capturer}
+	return func(e *gin.Engine) error {
+		g := e.Group("/debug/pcap")
+		{
+			g.POST("", pe.Start)
+			g.GET("", pe.List)
+			g.GET("/:id/file", pe.File)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/pcap"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockPacketCapturer struct {
+	startErr error
+	started  []pcap.Capture
+}
+
+func (m *mockPacketCapturer) Start(sessionID string, duration time.Duration, maxBytes int64) (pcap.Capture, error) {
+	if m.startErr != nil {
+		return pcap.Capture{}, m.startErr
+	}
+	capture := pcap.Capture{ID: sessionID + "-1", SessionID: sessionID, Interface: "myst0", Duration: duration, MaxBytes: maxBytes}
+	m.started = append(m.started, capture)
+	return capture, nil
+}
+
+func (m *mockPacketCapturer) List() []pcap.Capture {
+	return m.started
+}
+
+func (m *mockPacketCapturer) Get(id string) (pcap.Capture, error) {
+	for _, c := range m.started {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return pcap.Capture{}, pcap.ErrCaptureNotFound
+}
+
+func Test_PcapEndpoint_Start(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		startErr       error
+		expectedStatus int
+	}{
+		{name: "starts capture", body: `{"session_id": "s1", "duration_seconds": 60, "max_megabytes": 20}`, expectedStatus: http.StatusAccepted},
+		{name: "rejects missing session", body: `{"duration_seconds": 60}`, expectedStatus: http.StatusBadRequest},
+		{name: "rejects too long capture", body: `{"session_id": "s1", "duration_seconds": 601}`, expectedStatus: http.StatusBadRequest},
+		{name: "rejects too large capture", body: `{"session_id": "s1", "max_megabytes": 101}`, expectedStatus: http.StatusBadRequest},
+		{name: "rejects unknown session", body: `{"session_id": "s1"}`, startErr: pcap.ErrSessionNotFound, expectedStatus: http.StatusNotFound},
+		{name: "rejects concurrent capture", body: `{"session_id": "s1"}`, startErr: pcap.ErrCaptureRunning, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			capturer := &mockPacketCapturer{startErr: tt.startErr}
+			router := summonTestGin()
+			err := AddRoutesForPcap(capturer)(router)
+			assert.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/debug/pcap", strings.NewReader(tt.body))
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus != http.StatusAccepted {
+				return
+			}
+
+			var capture contract.PacketCaptureDTO
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &capture))
+			assert.Equal(t, "s1", capture.SessionID)
+			assert.Equal(t, 60, capture.DurationSeconds)
+			assert.Equal(t, int64(20), capture.MaxMegabytes)
+			assert.True(t, capture.Running)
+		})
+	}
+}
+
+func Test_PcapEndpoint_List(t *testing.T) {
+	capturer := &mockPacketCapturer{started: []pcap.Capture{{ID: "s1-1", SessionID: "s1"}}}
+	router := summonTestGin()
+	err := AddRoutesForPcap(capturer)(router)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/debug/pcap", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var list contract.PacketCaptureListResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+	assert.Len(t, list.Items, 1)
+	assert.Equal(t, "s1-1", list.Items[0].ID)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/debug/pcap/unknown/file", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/preflight"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type preflightRunner interface {
+	Run(ctx context.Context, providerID identity.Identity, serviceTypes []string) preflight.Report
+}
+
+type preflightEndpoint struct {
+	runner preflightRunner
+}
+
+// Preflight runs provider startup checks
+// swagger:operation GET /node/provider/preflight Provider providerPreflight
+// ---
+// summary: Returns provider startup check report
+// description: Checks whether identity is registered, stake is sufficient, hermes is reachable, NAT is traversable, ports are free and configuration is sane before starting services
+// parameters:
+// - in: query
+//   name: id
+//   description: Provider identity
+//   type: string
+//   required: true
+// - in: query
+//   name: services
+//   description: Service types to be started, either repeated or comma separated
+//   type: string
+// responses:
+//   200:
+//     description: Provider startup check report
+//     schema:
+//       "$ref": "#/definitions/PreflightReportDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (pe *preflightEndpoint) Preflight(c *gin.Context) {
+	id := c.Query("id")
+	if id == "" {
+		c.Error(apierror.BadRequestField("'id' is required", apierror.ValidateErrRequired, "id"))
+		return
+	}
+
+	var serviceTypes []string
+	for _, services := range c.QueryArray("services") {
+		if services != "" {
+			serviceTypes = append(serviceTypes, strings.Split(services, ",")...)
+		}
+	}
+
+	report := pe.runner.Run(c.Request.Context(), identity.FromAddress(id), serviceTypes)
+	utils.WriteAsJSON(contract.NewPreflightReportDTO(report), c.Writer)
+}
+
+// AddRoutesForPreflight attaches provider startup check endpoints to router
+func AddRoutesForPreflight(runner preflightRunner) func(*gin.Engine) error {
+	pe := &preflightEndpoint{runner: runner}
+	return func(e *gin.Engine) error {
+		e.GET("/node/provider/preflight", pe.Preflight)
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/preflight"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+type mockPreflightRunner struct {
+	providerID   identity.Identity
+	serviceTypes []string
+}
+
+func (m *mockPreflightRunner) Run(_ context.Context, providerID identity.Identity, serviceTypes []string) preflight.Report {
+	m.providerID = providerID
+	m.serviceTypes = serviceTypes
+	return preflight.Report{
+		Passed: false,
+		Checks: []preflight.CheckResult{
+			{Name: preflight.CheckIdentityRegistered, OK: true, Duration: 12 * time.Millisecond, Details: "0x1"},
+			{Name: preflight.CheckNATTraversal, Error: "consumers will not be able to traverse symmetric NAT"},
+		},
+	}
+}
+
+func Test_PreflightEndpoint(t *testing.T) {
+	runner := &mockPreflightRunner{}
+	router := gin.Default()
+	err := AddRoutesForPreflight(runner)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/provider/preflight?id=0x1&services=wireguard,scraping", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, identity.FromAddress("0x1"), runner.providerID)
+	assert.Equal(t, []string{"wireguard", "scraping"}, runner.serviceTypes)
+	assert.JSONEq(t, `{
+		"passed": false,
+		"checks": [
+			{"name": "identity_registered", "ok": true, "skipped": false, "duration_ms": 12, "details": "0x1"},
+			{"name": "nat_traversal", "ok": false, "skipped": false, "duration_ms": 0, "error": "consumers will not be able to traverse symmetric NAT"}
+		]
+	}`, resp.Body.String())
+}
+
+func Test_PreflightEndpoint_RequiresIdentity(t *testing.T) {
+	router := summonTestGin()
+	err := AddRoutesForPreflight(&mockPreflightRunner{})(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/provider/preflight", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
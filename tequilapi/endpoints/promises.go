@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type hermesPromiseStorage interface {
+	Get(chainID int64, channelID string) (pingpong.HermesPromise, error)
+	List(filter pingpong.HermesPromiseFilter) ([]pingpong.HermesPromise, error)
+}
+
+type promiseForceSettler interface {
+	ForceSettle(chainID int64, providerID identity.Identity, hermesID ...common.Address) error
+}
+
+type promisesEndpoint struct {
+	storage hermesPromiseStorage
+	settler promiseForceSettler
+}
+
+// NewPromisesEndpoint creates and returns hermes promises endpoint.
+func NewPromisesEndpoint(storage hermesPromiseStorage, settler promiseForceSettler) *promisesEndpoint {
+	return &promisesEndpoint{
+		storage: storage,
+		settler: settler,
+	}
+}
+
+// swagger:operation GET /identities/{id}/promises Identity listHermesPromises
+// ---
+// summary: Returns stored hermes promises
+// description: Returns the latest hermes promise of every provider channel of the given identity
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity address
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: List of hermes promises
+//     schema:
+//       "$ref": "#/definitions/HermesPromiseListResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (pe *promisesEndpoint) List(c *gin.Context) {
+	id := identity.FromAddress(c.Param("id"))
+
+	promises, err := pe.storage.List(pingpong.HermesPromiseFilter{
+		Identity: &id,
+		ChainID:  config.GetInt64(config.FlagChainID),
+	})
+	if err != nil {
+		c.Error(apierror.Internal("Could not list hermes promises: "+err.Error(), contract.ErrCodeHermesPromiseList))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewHermesPromiseListResponse(promises), c.Writer)
+}
+
+// swagger:operation POST /identities/{id}/promises/{channel_id}/settle Identity settleHermesPromise
+// ---
+// summary: Settles the given hermes promise
+// description: Forces the settlement of the stored hermes promise and blocks until the settlement is complete
+// parameters:
+// - in: path
+//   name: id
+//   description: Identity address
+//   type: string
+//   required: true
+// - in: path
+//   name: channel_id
+//   description: Provider channel ID of the promise
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Promise settled
+//   404:
+//     description: Promise not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Promise can not be settled (e.g. preimage is not revealed yet)
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (pe *promisesEndpoint) Settle(c *gin.Context) {
+	id := identity.FromAddress(c.Param("id"))
+
+	promise, err := pe.storage.Get(config.GetInt64(config.FlagChainID), c.Param("channel_id"))
+	if errors.Is(err, pingpong.ErrNotFound) || (err == nil && promise.Identity != id) {
+		c.Error(apierror.NotFound("Promise not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not get hermes promise: "+err.Error(), contract.ErrCodeHermesPromiseList))
+		return
+	}
+	if !promise.Revealed {
+		c.Error(apierror.Unprocessable("Promise preimage is not revealed yet", contract.ErrCodeHermesSettle))
+		return
+	}
+
+	if err := pe.settler.ForceSettle(promise.Promise.ChainID, id, promise.HermesID); err != nil {
+		log.Err(err).Msgf("Could not settle promise of channel %s", promise.ChannelID)
+		utils.ForwardError(c, err, apierror.Internal("Could not settle promise", contract.ErrCodeHermesSettle))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// AddRoutesForPromises attaches hermes promise endpoints to router.
+func AddRoutesForPromises(storage hermesPromiseStorage, settler promiseForceSettler) func(*gin.Engine) error {
+	pe := NewPromisesEndpoint(storage, settler)
+	return func(e *gin.Engine) error {
+		g := e.Group("/identities")
+		{
+			g.GET("/:id/promises", pe.List)
+			g.POST("/:id/promises/:channel_id/settle", pe.Settle)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockHermesPromiseStorage struct {
+	promises []pingpong.HermesPromise
+}
+
+func (m *mockHermesPromiseStorage) Get(_ int64, channelID string) (pingpong.HermesPromise, error) {
+	for _, p := range m.promises {
+		if p.ChannelID == channelID {
+			return p, nil
+		}
+	}
+	return pingpong.HermesPromise{}, pingpong.ErrNotFound
+}
+
+func (m *mockHermesPromiseStorage) List(filter pingpong.HermesPromiseFilter) ([]pingpong.HermesPromise, error) {
+	var result []pingpong.HermesPromise
+	for _, p := range m.promises {
+		if filter.Identity == nil || *filter.Identity == p.Identity {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+type mockPromiseForceSettler struct {
+	settledProvider identity.Identity
+	settledHermes   []common.Address
+}
+
+func (m *mockPromiseForceSettler) ForceSettle(_ int64, providerID identity.Identity, hermesID ...common.Address) error {
+	m.settledProvider = providerID
+	m.settledHermes = hermesID
+	return nil
+}
+
+func newTestPromiseStorage() *mockHermesPromiseStorage {
+	return &mockHermesPromiseStorage{promises: []pingpong.HermesPromise{
+		{
+			ChannelID:   "0xchannel1",
+			Identity:    identity.FromAddress("0x1"),
+			HermesID:    common.HexToAddress("0xaa"),
+			Promise:     crypto.Promise{Amount: big.NewInt(500), Fee: big.NewInt(0)},
+			Revealed:    true,
+			AgreementID: big.NewInt(7),
+		},
+		{
+			ChannelID: "0xchannel2",
+			Identity:  identity.FromAddress("0x1"),
+			HermesID:  common.HexToAddress("0xbb"),
+			Promise:   crypto.Promise{Amount: big.NewInt(100), Fee: big.NewInt(0)},
+		},
+		{
+			ChannelID: "0xchannel3",
+			Identity:  identity.FromAddress("0x2"),
+			HermesID:  common.HexToAddress("0xaa"),
+			Promise:   crypto.Promise{Amount: big.NewInt(100), Fee: big.NewInt(0)},
+			Revealed:  true,
+		},
+	}}
+}
+
+func Test_PromisesEndpoint_List(t *testing.T) {
+	router := summonTestGin()
+	err := AddRoutesForPromises(newTestPromiseStorage(), &mockPromiseForceSettler{})(router)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/identities/0x1/promises", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var list contract.HermesPromiseListResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+	assert.Len(t, list.Items, 2)
+	assert.Equal(t, "0xchannel1", list.Items[0].ChannelID)
+	assert.Equal(t, big.NewInt(500), list.Items[0].Amount)
+	assert.Equal(t, big.NewInt(7), list.Items[0].AgreementID)
+	assert.True(t, list.Items[0].Revealed)
+	assert.False(t, list.Items[1].Revealed)
+}
+
+func Test_PromisesEndpoint_Settle(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedHermes []common.Address
+	}{
+		{name: "settles revealed promise", path: "/identities/0x1/promises/0xchannel1/settle", expectedStatus: http.StatusOK, expectedHermes: []common.Address{common.HexToAddress("0xaa")}},
+		{name: "rejects unrevealed promise", path: "/identities/0x1/promises/0xchannel2/settle", expectedStatus: http.StatusUnprocessableEntity},
+		{name: "hides promises of other identities", path: "/identities/0x1/promises/0xchannel3/settle", expectedStatus: http.StatusNotFound},
+		{name: "unknown promise", path: "/identities/0x1/promises/0xunknown/settle", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settler := &mockPromiseForceSettler{}
+			router := summonTestGin()
+			err := AddRoutesForPromises(newTestPromiseStorage(), settler)(router)
+			assert.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedHermes, settler.settledHermes)
+		})
+	}
+}
@@ -18,11 +18,16 @@
 package endpoints
 
 import (
+	"encoding/json"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/rs/zerolog/log"
 
+	"github.com/mysteriumnetwork/node/consumer/favorites"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/quality"
@@ -44,22 +49,28 @@ type priceAPI interface {
 	GetCurrentPrice(nodeType string, country string, serviceType string) (market.Price, error)
 }
 
+type favoritesLookup interface {
+	Lookup() (map[string]favorites.Entry, error)
+}
+
 type proposalsEndpoint struct {
 	proposalRepository proposalRepository
 	pricer             priceAPI
 	locationResolver   location.Resolver
 	filterPresets      proposal.FilterPresetRepository
 	natProber          natProber
+	favorites          favoritesLookup
 }
 
 // NewProposalsEndpoint creates and returns proposal creation endpoint
-func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI, locationResolver location.Resolver, filterPresetRepository proposal.FilterPresetRepository, natProber natProber) *proposalsEndpoint {
+func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI, locationResolver location.Resolver, filterPresetRepository proposal.FilterPresetRepository, natProber natProber, favorites favoritesLookup) *proposalsEndpoint {
 	return &proposalsEndpoint{
 		proposalRepository: proposalRepository,
 		pricer:             pricer,
 		locationResolver:   locationResolver,
 		filterPresets:      filterPresetRepository,
 		natProber:          natProber,
+		favorites:          favorites,
 	}
 }
 
@@ -89,6 +100,10 @@ func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI
 //     description: If given will filter proposals by node location country.
 //     type: string
 //   - in: query
+//     name: consumer_country
+//     description: If given will filter out proposals not allowing consumers from this country.
+//     type: string
+//   - in: query
 //     name: ip_type
 //     description: IP Type (residential, datacenter, etc.).
 //     type: string
@@ -108,11 +123,29 @@ func NewProposalsEndpoint(proposalRepository proposalRepository, pricer priceAPI
 //     name: nat_compatibility
 //     description: Pick nodes compatible with NAT of specified type. Specify "auto" to probe NAT.
 //     type: string
+//   - in: query
+//     name: include
+//     description: Comma separated list of additional details. Specify "favorites" to include consumer curated details of the providers and "attestation" to include the node metadata reported and signed by the provider.
+//     type: string
+//   - in: query
+//     name: favorites_only
+//     description: Return only proposals of favorite providers.
+//     type: boolean
+//   - in: query
+//     name: format
+//     description: Specify "ndjson" to stream proposals as newline delimited JSON, one ProposalDTO per line. Responses are gzip compressed when the client accepts it.
+//     type: string
+//   - in: header
+//     name: If-None-Match
+//     description: ETag of the proposals held by the client, 304 Not Modified is returned while they are unchanged. Streamed proposals are not tagged.
+//     type: string
 // responses:
 //   200:
 //     description: List of proposals
 //     schema:
 //       "$ref": "#/definitions/ListProposalsResponse"
+//   304:
+//     description: Proposals have not changed
 //   500:
 //     description: Internal server error
 //     schema:
@@ -152,6 +185,7 @@ func (pe *proposalsEndpoint) List(c *gin.Context) {
 		AccessPolicy:            req.URL.Query().Get("access_policy"),
 		AccessPolicySource:      req.URL.Query().Get("access_policy_source"),
 		LocationCountry:         req.URL.Query().Get("location_country"),
+		ConsumerCountry:         req.URL.Query().Get("consumer_country"),
 		IPType:                  req.URL.Query().Get("ip_type"),
 		NATCompatibility:        natCompatibility,
 		CompatibilityMin:        compatibilityMin,
@@ -165,12 +199,84 @@ func (pe *proposalsEndpoint) List(c *gin.Context) {
 		return
 	}
 
+	favoritesOnly, _ := strconv.ParseBool(req.URL.Query().Get("favorites_only"))
+	var curated map[string]favorites.Entry
+	if pe.favorites != nil && (favoritesOnly || includes(req.URL.Query(), "favorites")) {
+		curated, err = pe.favorites.Lookup()
+		if err != nil {
+			c.Error(apierror.Internal("Proposal favorites lookup failed: "+err.Error(), contract.ErrCodeProposalsFavorites))
+			return
+		}
+	}
+
+	includeAttestation := includes(req.URL.Query(), "attestation")
+	proposalDTO := func(p proposal.PricedServiceProposal) (contract.ProposalDTO, bool) {
+		dto := contract.NewProposalDTO(p)
+		if entry, ok := curated[strings.ToLower(p.ProviderID)]; ok {
+			favorite := contract.NewProviderFavoriteDTO(entry)
+			dto.Favorite = &favorite
+		}
+		if includeAttestation {
+			dto.Attestation = contract.NewNodeAttestationDTO(p.ServiceProposal)
+		}
+		if favoritesOnly && (dto.Favorite == nil || !dto.Favorite.Favorite) {
+			return dto, false
+		}
+		return dto, true
+	}
+
+	if req.URL.Query().Get("format") == contract.ProposalsFormatNDJSON {
+		writer, closeWriter := utils.CompressedWriter(c.Writer, req)
+		defer closeWriter()
+
+		stream := utils.NewNDJSONStream(writer)
+		for _, p := range proposals {
+			if dto, ok := proposalDTO(p); ok {
+				if err := stream.Write(dto); err != nil {
+					log.Warn().Err(err).Msg("Proposals stream interrupted")
+					return
+				}
+			}
+		}
+		return
+	}
+
 	proposalsRes := contract.ListProposalsResponse{Proposals: []contract.ProposalDTO{}}
 	for _, p := range proposals {
-		proposalsRes.Proposals = append(proposalsRes.Proposals, contract.NewProposalDTO(p))
+		if dto, ok := proposalDTO(p); ok {
+			proposalsRes.Proposals = append(proposalsRes.Proposals, dto)
+		}
 	}
 
-	utils.WriteAsJSON(proposalsRes, c.Writer)
+	blob, err := json.Marshal(proposalsRes)
+	if err != nil {
+		c.Error(apierror.Internal("Proposals encoding failed: "+err.Error(), contract.ErrCodeProposalsQuery))
+		return
+	}
+	// Proposals are not tracked by the state keeper, so the entity tag is derived from the response itself.
+	if utils.NotModified(c.Writer, req, utils.ContentETag(blob)) {
+		return
+	}
+
+	writer, closeWriter := utils.CompressedWriter(c.Writer, req)
+	defer closeWriter()
+
+	writer.Header().Set("Content-type", "application/json; charset=utf-8")
+	if _, err := writer.Write(blob); err != nil {
+		log.Error().Err(err).Msg("Writing response body failed")
+	}
+}
+
+// includes checks whether the comma separated "include" query parameter lists the given detail.
+func includes(query url.Values, detail string) bool {
+	for _, value := range query["include"] {
+		for _, v := range strings.Split(value, ",") {
+			if strings.TrimSpace(v) == detail {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // swagger:operation GET /proposals/countries Countries listCountries
@@ -199,6 +305,10 @@ func (pe *proposalsEndpoint) List(c *gin.Context) {
 //     description: If given will filter proposals by node location country.
 //     type: string
 //   - in: query
+//     name: consumer_country
+//     description: If given will filter out proposals not allowing consumers from this country.
+//     type: string
+//   - in: query
 //     name: ip_type
 //     description: IP Type (residential, datacenter, etc.).
 //     type: string
@@ -263,6 +373,7 @@ func (pe *proposalsEndpoint) Countries(c *gin.Context) {
 		AccessPolicy:            req.URL.Query().Get("access_policy"),
 		AccessPolicySource:      req.URL.Query().Get("access_policy_source"),
 		LocationCountry:         req.URL.Query().Get("location_country"),
+		ConsumerCountry:         req.URL.Query().Get("consumer_country"),
 		IPType:                  req.URL.Query().Get("ip_type"),
 		NATCompatibility:        natCompatibility,
 		CompatibilityMin:        compatibilityMin,
@@ -400,8 +511,9 @@ func AddRoutesForProposals(
 	locationResolver location.Resolver,
 	filterPresetRepository proposal.FilterPresetRepository,
 	natProber natProber,
+	favorites favoritesLookup,
 ) func(*gin.Engine) error {
-	pe := NewProposalsEndpoint(proposalRepository, pricer, locationResolver, filterPresetRepository, natProber)
+	pe := NewProposalsEndpoint(proposalRepository, pricer, locationResolver, filterPresetRepository, natProber, favorites)
 	return func(e *gin.Engine) error {
 		proposalGroup := e.Group("/proposals")
 		{
@@ -18,6 +18,8 @@
 package endpoints
 
 import (
+	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -30,11 +32,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 
+	"github.com/mysteriumnetwork/node/consumer/favorites"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/location/locationstate"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/nat"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 )
 
 var TestLocation = market.Location{ASN: 123, Country: "Lithuania", City: "Vilnius"}
@@ -116,7 +120,7 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber)
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber, nil)
 	g := gin.Default()
 	g.GET(path, endpoint.List)
 	g.ServeHTTP(resp, req)
@@ -187,7 +191,7 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber)
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber, nil)
 
 	g := gin.Default()
 	g.GET(path, endpoint.List)
@@ -263,7 +267,7 @@ func TestCurrentPrices(t *testing.T) {
 			PricePerHour: big.NewInt(123_000_000_000_000_000),
 			PricePerGiB:  big.NewInt(456_000_000_000_000_000),
 		},
-	}, &mockResolver{}, presetRepository, mockedNATProber)
+	}, &mockResolver{}, presetRepository, mockedNATProber, nil)
 
 	path := "/prices/current"
 	req, err := http.NewRequest(
@@ -324,7 +328,7 @@ func TestProposalsEndpointFilterByPresetID(t *testing.T) {
 			},
 		}},
 	}
-	endpoint := NewProposalsEndpoint(repository, nil, nil, presetRepository, mockedNATProber)
+	endpoint := NewProposalsEndpoint(repository, nil, nil, presetRepository, mockedNATProber, nil)
 	g := gin.Default()
 	g.GET(path, endpoint.List)
 	g.ServeHTTP(resp, req)
@@ -403,6 +407,125 @@ func TestProposalsEndpointFilterByPresetID(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointIncludesFavorites(t *testing.T) {
+	repository := &mockProposalRepository{proposals: serviceProposals}
+	lookup := mockFavoritesLookup{
+		"0xproviderid":   {ProviderID: "0xproviderid", Favorite: true, Labels: []string{"work"}},
+		"other_provider": {ProviderID: "other_provider", Note: "slow"},
+	}
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber, lookup)
+	g := gin.Default()
+	g.GET("/proposals", endpoint.List)
+
+	list := func(query string) contract.ListProposalsResponse {
+		req := httptest.NewRequest(http.MethodGet, "/proposals?"+query, nil)
+		resp := httptest.NewRecorder()
+		g.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusOK, resp.Code)
+
+		var res contract.ListProposalsResponse
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+		return res
+	}
+
+	res := list("")
+	assert.Len(t, res.Proposals, 2)
+	assert.Nil(t, res.Proposals[0].Favorite)
+
+	res = list("include=favorites")
+	assert.Len(t, res.Proposals, 2)
+	assert.True(t, res.Proposals[0].Favorite.Favorite)
+	assert.Equal(t, []string{"work"}, res.Proposals[0].Favorite.Labels)
+	assert.Equal(t, "slow", res.Proposals[1].Favorite.Note)
+
+	res = list("favorites_only=true")
+	assert.Len(t, res.Proposals, 1)
+	assert.Equal(t, "0xProviderId", res.Proposals[0].ProviderID)
+}
+
+func TestProposalsEndpointStreamsCompressedNDJSON(t *testing.T) {
+	repository := &mockProposalRepository{proposals: serviceProposals}
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber, nil)
+	g := gin.Default()
+	g.GET("/proposals", endpoint.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/proposals?format=ndjson", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "gzip", resp.Header().Get("Content-Encoding"))
+	assert.Equal(t, "application/x-ndjson; charset=utf-8", resp.Header().Get("Content-Type"))
+
+	body, err := gzip.NewReader(resp.Body)
+	assert.NoError(t, err)
+	decoder := json.NewDecoder(body)
+
+	var providers []string
+	for decoder.More() {
+		var dto contract.ProposalDTO
+		assert.NoError(t, decoder.Decode(&dto))
+		providers = append(providers, dto.ProviderID)
+	}
+	assert.Equal(t, []string{"0xProviderId", "other_provider"}, providers)
+}
+
+func TestProposalsEndpointCompressesOnlyWhenAccepted(t *testing.T) {
+	repository := &mockProposalRepository{proposals: serviceProposals}
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber, nil)
+	g := gin.Default()
+	g.GET("/proposals", endpoint.List)
+
+	req := httptest.NewRequest(http.MethodGet, "/proposals", nil)
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Empty(t, resp.Header().Get("Content-Encoding"))
+
+	var res contract.ListProposalsResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Len(t, res.Proposals, 2)
+}
+
+func TestProposalsEndpointAnswersNotModified(t *testing.T) {
+	repository := &mockProposalRepository{proposals: serviceProposals}
+	endpoint := NewProposalsEndpoint(repository, nil, nil, &mockFilterPresetRepository{}, mockedNATProber, nil)
+	g := gin.Default()
+	g.GET("/proposals", endpoint.List)
+
+	list := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/proposals", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp := httptest.NewRecorder()
+		g.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := list("")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	etag := resp.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	resp = list(etag)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.Bytes())
+
+	repository.proposals = serviceProposals[:1]
+	resp = list(etag)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEqual(t, etag, resp.Header().Get("ETag"))
+}
+
+type mockFavoritesLookup map[string]favorites.Entry
+
+func (m mockFavoritesLookup) Lookup() (map[string]favorites.Entry, error) {
+	return m, nil
+}
+
 type mockProposalRepository struct {
 	proposals      []proposal.PricedServiceProposal
 	recordedFilter *proposal.Filter
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/referral"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type referralTracker interface {
+	Token() string
+	SetToken(token string) error
+	ClearToken() error
+}
+
+type referralAPI struct {
+	tracker referralTracker
+}
+
+func newReferralAPI(tracker referralTracker) *referralAPI {
+	return &referralAPI{tracker: tracker}
+}
+
+// GetToken returns the referral token of the node
+// swagger:operation GET /referral/token Referral getReferralToken
+// ---
+// summary: Returns the referral token
+// description: Returns the referral token attached to identity registration and first session events
+// responses:
+//   200:
+//     description: Referral token, empty if not set
+//     schema:
+//       "$ref": "#/definitions/ReferralTokenResponse"
+func (api *referralAPI) GetToken(c *gin.Context) {
+	utils.WriteAsJSON(contract.ReferralTokenResponse{Token: api.tracker.Token()}, c.Writer)
+}
+
+// SetToken sets the referral token of the node
+// swagger:operation PUT /referral/token Referral setReferralToken
+// ---
+// summary: Sets the referral token
+// description: Sets the referral token attached to identity registration and first session events
+// parameters:
+//   - in: body
+//     name: body
+//     description: token field
+//     schema:
+//       $ref: "#/definitions/ReferralTokenRequest"
+// responses:
+//   200:
+//     description: Referral token has been set
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (api *referralAPI) SetToken(c *gin.Context) {
+	var req contract.ReferralTokenRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := api.tracker.SetToken(req.Token); err != nil {
+		if errors.Is(err, referral.ErrInvalidToken) {
+			v := apierror.NewValidator()
+			v.Invalid("token", "Should be up to 64 printable characters without spaces")
+			c.Error(v.Err())
+			return
+		}
+		c.Error(apierror.Internal("Failed to save referral token", contract.ErrCodeConfigSave))
+		return
+	}
+}
+
+// ClearToken removes the referral token of the node
+// swagger:operation DELETE /referral/token Referral clearReferralToken
+// ---
+// summary: Clears the referral token
+// description: Clears the referral token, registration and session events are no longer attributed
+// responses:
+//   200:
+//     description: Referral token removed
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (api *referralAPI) ClearToken(c *gin.Context) {
+	if err := api.tracker.ClearToken(); err != nil {
+		c.Error(apierror.Internal("Failed to clear referral token", contract.ErrCodeConfigSave))
+		return
+	}
+}
+
+// AddRoutesForReferral registers /referral endpoints in Tequilapi
+func AddRoutesForReferral(tracker referralTracker) func(*gin.Engine) error {
+	api := newReferralAPI(tracker)
+	return func(e *gin.Engine) error {
+		g := e.Group("/referral")
+		{
+			g.GET("/token", api.GetToken)
+			g.PUT("/token", api.SetToken)
+			g.DELETE("/token", api.ClearToken)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/referral"
+)
+
+type mockReferralTracker struct {
+	token string
+}
+
+func (m *mockReferralTracker) Token() string {
+	return m.token
+}
+
+func (m *mockReferralTracker) SetToken(token string) error {
+	if strings.Contains(token, " ") {
+		return referral.ErrInvalidToken
+	}
+	m.token = token
+	return nil
+}
+
+func (m *mockReferralTracker) ClearToken() error {
+	m.token = ""
+	return nil
+}
+
+func Test_ReferralEndpoint_Token(t *testing.T) {
+	tracker := &mockReferralTracker{}
+	router := summonTestGin()
+	err := AddRoutesForReferral(tracker)(router)
+	assert.NoError(t, err)
+
+	for _, tc := range []struct {
+		body         string
+		expectedCode int
+		expected     string
+	}{
+		{body: `{"token": "friend-42"}`, expectedCode: http.StatusOK, expected: "friend-42"},
+		{body: `{"token": ""}`, expectedCode: http.StatusBadRequest, expected: "friend-42"},
+		{body: `{"token": "has space"}`, expectedCode: http.StatusBadRequest, expected: "friend-42"},
+		{body: `not json`, expectedCode: http.StatusBadRequest, expected: "friend-42"},
+	} {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/referral/token", strings.NewReader(tc.body))
+		router.ServeHTTP(resp, req)
+		assert.Equal(t, tc.expectedCode, resp.Code, tc.body)
+		assert.Equal(t, tc.expected, tracker.token, tc.body)
+	}
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/referral/token", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"token": "friend-42"}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/referral/token", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "", tracker.token)
+}
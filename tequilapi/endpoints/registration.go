@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type pendingRegistrations interface {
+	Get(chainID int64, id identity.Identity) (registry.PendingRegistration, error)
+	BumpFee(chainID int64, id identity.Identity, fee *big.Int) error
+	Cancel(chainID int64, id identity.Identity) error
+}
+
+type registrationEndpoint struct {
+	pending pendingRegistrations
+}
+
+// NewRegistrationEndpoint creates and returns pending registration endpoint.
+func NewRegistrationEndpoint(pending pendingRegistrations) *registrationEndpoint {
+	return &registrationEndpoint{pending: pending}
+}
+
+// swagger:operation GET /identities/{id}/registration/pending Identity pendingRegistration
+// ---
+// summary: Provides the pending registration of the identity
+// description: Provides the transactor status and transaction hash of the registration which is not confirmed yet, reporting whether it is stuck
+// parameters:
+//   - in: path
+//     name: id
+//     description: hex address of identity
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Pending registration
+//     schema:
+//       "$ref": "#/definitions/PendingRegistrationDTO"
+//   404:
+//     description: Identity has no pending registration
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (re *registrationEndpoint) Pending(c *gin.Context) {
+	id := identity.FromAddress(c.Param("id"))
+
+	pending, err := re.pending.Get(config.GetInt64(config.FlagChainID), id)
+	if errors.Is(err, registry.ErrNoPendingRegistration) {
+		c.Error(apierror.NotFound("Identity has no pending registration"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not get pending registration: "+err.Error(), contract.ErrCodeIDRegistrationPending))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewPendingRegistrationDTO(pending), c.Writer)
+}
+
+// swagger:operation POST /identities/{id}/registration/bump Identity bumpRegistrationFee
+// ---
+// summary: Re-submits the stuck registration with a higher fee
+// parameters:
+//   - in: path
+//     name: id
+//     description: hex address of identity
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     description: New registration fee
+//     schema:
+//       $ref: "#/definitions/RegistrationFeeBumpRequestDTO"
+// responses:
+//   202:
+//     description: Registration re-submitted
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: Identity has no pending registration
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Registration is not stuck yet
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (re *registrationEndpoint) BumpFee(c *gin.Context) {
+	var req contract.RegistrationFeeBumpRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	id := identity.FromAddress(c.Param("id"))
+	err := re.pending.BumpFee(config.GetInt64(config.FlagChainID), id, req.Fee)
+	switch {
+	case err == nil:
+		c.Status(http.StatusAccepted)
+	case errors.Is(err, registry.ErrNoPendingRegistration):
+		c.Error(apierror.NotFound("Identity has no pending registration"))
+	case errors.Is(err, registry.ErrRegistrationNotStuck):
+		c.Error(apierror.Conflict("Registration is still being processed", contract.ErrCodeIDRegistrationBump, "id"))
+	case errors.Is(err, registry.ErrFeeTooLow):
+		c.Error(apierror.BadRequestField("'fee' must be higher than the fee of the pending registration", apierror.ValidateErrInvalidVal, "fee"))
+	default:
+		log.Err(err).Msgf("Could not re-submit registration of %s", id.Address)
+		utils.ForwardError(c, err, apierror.Internal("Could not re-submit registration", contract.ErrCodeIDRegistrationBump))
+	}
+}
+
+// swagger:operation POST /identities/{id}/registration/cancel Identity cancelRegistration
+// ---
+// summary: Cancels the pending registration
+// description: Stops waiting for the pending registration and marks the identity as unregistered, so the registration can be started again
+// parameters:
+//   - in: path
+//     name: id
+//     description: hex address of identity
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Registration cancelled
+//   404:
+//     description: Identity has no pending registration
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (re *registrationEndpoint) Cancel(c *gin.Context) {
+	err := re.pending.Cancel(config.GetInt64(config.FlagChainID), identity.FromAddress(c.Param("id")))
+	if errors.Is(err, registry.ErrNoPendingRegistration) {
+		c.Error(apierror.NotFound("Identity has no pending registration"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not cancel registration: "+err.Error(), contract.ErrCodeIDRegistrationCancel))
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// AddRoutesForRegistration attaches pending registration endpoints to router.
+func AddRoutesForRegistration(pending pendingRegistrations) func(*gin.Engine) error {
+	re := NewRegistrationEndpoint(pending)
+	return func(e *gin.Engine) error {
+		g := e.Group("/identities")
+		{
+			g.GET("/:id/registration/pending", re.Pending)
+			g.POST("/:id/registration/bump", re.BumpFee)
+			g.POST("/:id/registration/cancel", re.Cancel)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockPendingRegistrations struct {
+	pending   map[string]registry.PendingRegistration
+	bumpErr   error
+	bumpedFee *big.Int
+	cancelled []identity.Identity
+}
+
+func (m *mockPendingRegistrations) Get(_ int64, id identity.Identity) (registry.PendingRegistration, error) {
+	pending, ok := m.pending[id.Address]
+	if !ok {
+		return registry.PendingRegistration{}, registry.ErrNoPendingRegistration
+	}
+	return pending, nil
+}
+
+func (m *mockPendingRegistrations) BumpFee(_ int64, _ identity.Identity, fee *big.Int) error {
+	if m.bumpErr != nil {
+		return m.bumpErr
+	}
+	m.bumpedFee = fee
+	return nil
+}
+
+func (m *mockPendingRegistrations) Cancel(_ int64, id identity.Identity) error {
+	if _, ok := m.pending[id.Address]; !ok {
+		return registry.ErrNoPendingRegistration
+	}
+	m.cancelled = append(m.cancelled, id)
+	return nil
+}
+
+func Test_RegistrationEndpoint_Pending(t *testing.T) {
+	pending := &mockPendingRegistrations{pending: map[string]registry.PendingRegistration{
+		"0x1": {
+			Status:           registry.InProgress,
+			TransactorStatus: registry.TransactorRegistrationEntryStatusCreated,
+			TxHash:           "0xhash",
+			Fee:              big.NewInt(100),
+			Stuck:            true,
+		},
+	}}
+	router := summonTestGin()
+	err := AddRoutesForRegistration(pending)(router)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/identities/0x1/registration/pending", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var dto contract.PendingRegistrationDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &dto))
+	assert.Equal(t, contract.PendingRegistrationDTO{
+		Status:           "InProgress",
+		TransactorStatus: "created",
+		TxHash:           "0xhash",
+		Fee:              big.NewInt(100),
+		Stuck:            true,
+	}, dto)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/identities/0x2/registration/pending", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func Test_RegistrationEndpoint_BumpFee(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		bumpErr        error
+		expectedStatus int
+		expectedFee    *big.Int
+	}{
+		{name: "re-submits registration", body: `{"fee": 200}`, expectedStatus: http.StatusAccepted, expectedFee: big.NewInt(200)},
+		{name: "rejects missing fee", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "rejects lower fee", body: `{"fee": 50}`, bumpErr: registry.ErrFeeTooLow, expectedStatus: http.StatusBadRequest},
+		{name: "rejects registration in progress", body: `{"fee": 200}`, bumpErr: registry.ErrRegistrationNotStuck, expectedStatus: http.StatusConflict},
+		{name: "no pending registration", body: `{"fee": 200}`, bumpErr: registry.ErrNoPendingRegistration, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pending := &mockPendingRegistrations{bumpErr: tt.bumpErr}
+			router := summonTestGin()
+			err := AddRoutesForRegistration(pending)(router)
+			assert.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/identities/0x1/registration/bump", strings.NewReader(tt.body)))
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedFee, pending.bumpedFee)
+		})
+	}
+}
+
+func Test_RegistrationEndpoint_Cancel(t *testing.T) {
+	pending := &mockPendingRegistrations{pending: map[string]registry.PendingRegistration{"0x1": {}}}
+	router := summonTestGin()
+	err := AddRoutesForRegistration(pending)(router)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/identities/0x1/registration/cancel", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []identity.Identity{identity.FromAddress("0x1")}, pending.cancelled)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/identities/0x2/registration/cancel", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
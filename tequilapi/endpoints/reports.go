@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/core/reports"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+const (
+	statementFormatJSON = "json"
+	statementFormatCSV  = "csv"
+)
+
+type statementGenerator interface {
+	MonthlyStatement(year int, month time.Month) (reports.Statement, error)
+}
+
+type reportsEndpoint struct {
+	generator statementGenerator
+	now       func() time.Time
+}
+
+// NewReportsEndpoint creates and returns reports endpoint.
+func NewReportsEndpoint(generator statementGenerator) *reportsEndpoint {
+	return &reportsEndpoint{
+		generator: generator,
+		now:       time.Now,
+	}
+}
+
+// swagger:operation GET /reports/statement Reports monthlyStatement
+// ---
+// summary: Returns monthly statement
+// description: Returns earnings per service, settlements with transaction hashes and fees, and spending per provider for the given month
+// parameters:
+// - in: query
+//   name: month
+//   description: Month of the statement formatted as YYYY-MM, current month by default
+//   type: string
+// - in: query
+//   name: format
+//   description: Export format, either json (default) or csv
+//   type: string
+// responses:
+//   200:
+//     description: Monthly statement
+//     schema:
+//       "$ref": "#/definitions/StatementDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *reportsEndpoint) MonthlyStatement(c *gin.Context) {
+	month := endpoint.now().UTC()
+	if m := c.Query("month"); m != "" {
+		var err error
+		if month, err = time.Parse("2006-01", m); err != nil {
+			c.Error(apierror.BadRequestField("'month' must be formatted as YYYY-MM", apierror.ValidateErrInvalidVal, "month"))
+			return
+		}
+	}
+
+	format := c.DefaultQuery("format", statementFormatJSON)
+	if format != statementFormatJSON && format != statementFormatCSV {
+		c.Error(apierror.BadRequestField("'format' must be one of: json, csv", apierror.ValidateErrInvalidVal, "format"))
+		return
+	}
+
+	statement, err := endpoint.generator.MonthlyStatement(month.Year(), month.Month())
+	if err != nil {
+		c.Error(apierror.Internal("Could not generate statement: "+err.Error(), contract.ErrCodeReportStatement))
+		return
+	}
+
+	if format == statementFormatCSV {
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%s.csv"`, month.Format("2006-01")))
+		c.Status(http.StatusOK)
+		if err := reports.WriteCSV(c.Writer, statement); err != nil {
+			c.Error(apierror.Internal("Could not export statement: "+err.Error(), contract.ErrCodeReportStatement))
+		}
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewStatementDTO(statement), c.Writer)
+}
+
+// AddRoutesForReports attaches reports endpoints to router.
+func AddRoutesForReports(generator statementGenerator) func(*gin.Engine) error {
+	endpoint := NewReportsEndpoint(generator)
+	return func(e *gin.Engine) error {
+		g := e.Group("/reports")
+		{
+			g.GET("/statement", endpoint.MonthlyStatement)
+		}
+		return nil
+	}
+}
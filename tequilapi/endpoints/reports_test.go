@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/reports"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+func Test_ReportsEndpoint_MonthlyStatement(t *testing.T) {
+	statement := reports.Statement{
+		From:         time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC),
+		To:           time.Date(2022, 5, 31, 23, 59, 59, 0, time.UTC),
+		Earnings:     []reports.ServiceEarnings{{ServiceType: "wireguard", Sessions: 2, Tokens: big.NewInt(100)}},
+		TotalEarned:  big.NewInt(100),
+		TotalSettled: big.NewInt(0),
+		TotalFees:    big.NewInt(0),
+		TotalSpent:   big.NewInt(0),
+	}
+
+	tests := []struct {
+		name           string
+		query          string
+		generatorErr   error
+		expectedStatus int
+		expectedMonth  time.Month
+	}{
+		{name: "returns JSON statement", query: "?month=2022-05", expectedStatus: http.StatusOK, expectedMonth: time.May},
+		{name: "exports CSV statement", query: "?month=2022-05&format=csv", expectedStatus: http.StatusOK, expectedMonth: time.May},
+		{name: "defaults to current month", query: "", expectedStatus: http.StatusOK, expectedMonth: time.March},
+		{name: "rejects invalid month", query: "?month=May", expectedStatus: http.StatusBadRequest},
+		{name: "rejects unknown format", query: "?format=xls", expectedStatus: http.StatusBadRequest},
+		{name: "fails on storage error", query: "", generatorErr: errors.New("boom"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := &mockStatementGenerator{statement: statement, err: tt.generatorErr}
+			endpoint := NewReportsEndpoint(generator)
+			endpoint.now = func() time.Time { return time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC) }
+
+			req := httptest.NewRequest(http.MethodGet, "/reports/statement"+tt.query, nil)
+			resp := httptest.NewRecorder()
+			g := summonTestGin()
+			g.GET("/reports/statement", endpoint.MonthlyStatement)
+			g.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, 2022, generator.calledWithYear)
+			assert.Equal(t, tt.expectedMonth, generator.calledWithMonth)
+
+			if strings.Contains(tt.query, "csv") {
+				assert.Equal(t, "text/csv", resp.Header().Get("Content-Type"))
+				assert.Contains(t, resp.Header().Get("Content-Disposition"), "statement-2022-05.csv")
+				assert.Contains(t, resp.Body.String(), "earning,2022-05-01T00:00:00Z")
+				return
+			}
+
+			var dto contract.StatementDTO
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &dto))
+			assert.Equal(t, "2022-05-01T00:00:00Z", dto.PeriodStart)
+			assert.Len(t, dto.Earnings, 1)
+			assert.Equal(t, "100", dto.TotalEarned.Wei)
+		})
+	}
+}
+
+type mockStatementGenerator struct {
+	statement reports.Statement
+	err       error
+
+	calledWithYear  int
+	calledWithMonth time.Month
+}
+
+func (m *mockStatementGenerator) MonthlyStatement(year int, month time.Month) (reports.Statement, error) {
+	m.calledWithYear = year
+	m.calledWithMonth = month
+	return m.statement, m.err
+}
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/mysteriumnetwork/go-rest/apierror"
@@ -30,6 +31,7 @@ import (
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/services"
 	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
@@ -208,6 +210,69 @@ func (se *ServiceEndpoint) ServiceStart(c *gin.Context) {
 	utils.WriteAsJSON(statusResponse, c.Writer)
 }
 
+// ServicePreview builds the proposal of requested service without starting it.
+// swagger:operation POST /services/preview Service servicePreview
+// ---
+// summary: Previews service proposal
+// description: Returns the proposal which would be published for the service, so it is shown exactly as consumers would see it
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (providerID) of the service to preview
+//     schema:
+//       $ref: "#/definitions/ServiceStartRequestDTO"
+// responses:
+//   200:
+//     description: Proposal of the service
+//     schema:
+//       "$ref": "#/definitions/ProposalDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   422:
+//     description: Unable to process the request at this point
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (se *ServiceEndpoint) ServicePreview(c *gin.Context) {
+	sr, err := se.toServiceRequest(c.Request)
+	if err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if err := validateServiceRequest(sr); err != nil {
+		c.Error(err)
+		return
+	}
+
+	proposal, err := se.serviceManager.Preview(
+		identity.FromAddress(sr.ProviderID),
+		sr.Type,
+		sr.AccessPolicies.IDs,
+		sr.Options,
+	)
+	if err == service.ErrorLocation {
+		c.Error(apierror.Unprocessable("Cannot detect location", contract.ErrCodeServiceLocation))
+		return
+	} else if err != nil {
+		c.Error(apierror.Internal("Cannot preview service: "+err.Error(), contract.ErrCodeServicePreview))
+		return
+	}
+
+	priced, err := se.proposalRepository.EnrichProposalWithPrice(proposal)
+	if err != nil {
+		c.Error(apierror.Internal("Cannot price proposal: "+err.Error(), contract.ErrCodeServicePreview))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProposalDTO(priced), c.Writer)
+}
+
 // ServiceStop stops service on the node.
 // swagger:operation DELETE /services/:id Service serviceStop
 // ---
@@ -244,6 +309,78 @@ func (se *ServiceEndpoint) ServiceStop(c *gin.Context) {
 	c.Status(http.StatusAccepted)
 }
 
+// ServiceRestart applies new options to the running service on the node.
+// swagger:operation PUT /services/:id Service serviceRestart
+// ---
+// summary: Restarts service with new options
+// description: Starts new service instance with given options which takes over new sessions, while the old instance serves its active sessions until they end
+// parameters:
+//   - in: body
+//     name: body
+//     description: New service options
+//     schema:
+//       $ref: "#/definitions/ServiceRestartRequestDTO"
+// responses:
+//   200:
+//     description: Service restarted
+//     schema:
+//       "$ref": "#/definitions/ServiceInfoDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: No service exists
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (se *ServiceEndpoint) ServiceRestart(c *gin.Context) {
+	id := service.ID(c.Param("id"))
+	instance := se.serviceManager.Service(id)
+	if instance == nil {
+		c.Error(apierror.NotFound("Service not found"))
+		return
+	}
+
+	var req struct {
+		Options *json.RawMessage `json:"options"`
+	}
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	options := se.toServiceOptions(instance.Type, req.Options)
+	if options == serviceOptionsInvalid {
+		v := apierror.NewValidator()
+		v.Invalid("options", "Invalid options")
+		c.Error(v.Err())
+		return
+	}
+
+	newID, err := se.serviceManager.Restart(id, options, config.GetDuration(config.FlagServiceDrainTimeout))
+	if err == service.ErrorLocation {
+		c.Error(apierror.Unprocessable("Cannot detect location", contract.ErrCodeServiceLocation))
+		return
+	} else if err != nil {
+		c.Error(apierror.Internal("Cannot restart service: "+err.Error(), contract.ErrCodeServiceRestart))
+		return
+	}
+
+	statusResponse, err := se.toServiceInfoResponse(newID, se.serviceManager.Service(newID))
+	if err != nil {
+		c.Error(apierror.Internal("Cannot generate response: "+err.Error(), contract.ErrCodeServiceGet))
+		return
+	}
+
+	utils.WriteAsJSON(statusResponse, c.Writer)
+}
+
 func (se *ServiceEndpoint) updateActiveServicesInUserConfig() {
 	runningInstances := se.serviceManager.List(false)
 	activeServices := make([]string, len(runningInstances))
@@ -279,7 +416,9 @@ func AddRoutesForService(
 		{
 			g.GET("", serviceEndpoint.ServiceList)
 			g.POST("", serviceEndpoint.ServiceStart)
+			g.POST("/preview", serviceEndpoint.ServicePreview)
 			g.GET("/:id", serviceEndpoint.ServiceGet)
+			g.PUT("/:id", serviceEndpoint.ServiceRestart)
 			g.DELETE("/:id", serviceEndpoint.ServiceStop)
 		}
 		return nil
@@ -394,7 +533,9 @@ func validateServiceRequest(sr contract.ServiceStartRequest) *apierror.APIError
 // ServiceManager represents service manager that is used for services management.
 type ServiceManager interface {
 	Start(providerID identity.Identity, serviceType string, policies []string, options service.Options) (service.ID, error)
+	Preview(providerID identity.Identity, serviceType string, policies []string, options service.Options) (market.ServiceProposal, error)
 	Stop(id service.ID) error
+	Restart(id service.ID, options service.Options, drainTimeout time.Duration) (service.ID, error)
 	Service(id service.ID) *service.Instance
 	Kill() error
 	List(includeAll bool) []*service.Instance
@@ -26,6 +26,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/core/service"
@@ -33,6 +34,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/services"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -90,7 +92,16 @@ func (sm *mockServiceManager) Start(_ identity.Identity, serviceType string, _ [
 	}
 	return mockServiceID, nil
 }
+func (sm *mockServiceManager) Preview(_ identity.Identity, serviceType string, _ []string, _ service.Options) (market.ServiceProposal, error) {
+	if serviceType == serviceTypeWithAccessPolicy {
+		return mockProposalWithAccessPolicy, nil
+	}
+	return mockProposal, nil
+}
 func (sm *mockServiceManager) Stop(id service.ID) error { return nil }
+func (sm *mockServiceManager) Restart(id service.ID, _ service.Options, _ time.Duration) (service.ID, error) {
+	return id, nil
+}
 func (sm *mockServiceManager) Service(id service.ID) *service.Instance {
 	if id == "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
 		return mockServiceRunning
@@ -252,7 +263,7 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 		},
 		{
 			http.MethodDelete, "/services/00000000-9dad-11d1-80b4-00c04fd43000", "",
-			http.StatusNotFound, `{ "error": {"code":"not_found", "message":"Service not found"}, "path":"/services/00000000-9dad-11d1-80b4-00c04fd43000", "status":404 }`,
+			http.StatusNotFound, `{ "error": {"code":"not_found", "message":"Service not found", "category":"not_found"}, "path":"/services/00000000-9dad-11d1-80b4-00c04fd43000", "status":404 }`,
 		},
 	}
 
@@ -368,6 +379,55 @@ func Test_ServiceStartAlreadyRunning(t *testing.T) {
 	assert.Equal(t, "err_service_running", apierror.Parse(resp.Result()).Err.Code)
 }
 
+func Test_ServiceRestart(t *testing.T) {
+	g := summonTestGin()
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{}, nil)(g)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/services/unknown", strings.NewReader(`{"options": {}}`)))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/services/"+string(mockServiceID), strings.NewReader(`{"options": {}}`)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var info map[string]interface{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &info))
+	assert.Equal(t, string(mockServiceID), info["id"])
+	assert.Equal(t, mockServiceType, info["type"])
+}
+
+func Test_ServicePreview(t *testing.T) {
+	g := summonTestGin()
+	err := AddRoutesForService(&mockServiceManager{}, fakeOptionsParser, &mockProposalRepository{
+		priceToAdd: market.Price{
+			PricePerHour: big.NewInt(500_000_000_000_000_000),
+			PricePerGiB:  big.NewInt(1_000_000_000_000_000_000),
+		},
+	}, nil)(g)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/services/preview", strings.NewReader(`{
+		"type": "`+serviceTypeWithAccessPolicy+`",
+		"provider_id": "0xproviderid",
+		"options": {}
+	}`)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var proposal contract.ProposalDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &proposal))
+	assert.Equal(t, "0xproviderid", proposal.ProviderID)
+	assert.Equal(t, serviceTypeWithAccessPolicy, proposal.ServiceType)
+	assert.Len(t, *proposal.AccessPolicies, 4)
+	assert.Equal(t, uint64(500_000_000_000_000_000), proposal.Price.PerHour)
+
+	resp = httptest.NewRecorder()
+	g.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/services/preview", strings.NewReader(`{"type": "openvpn", "provider_id": "0xproviderid"}`)))
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
 func Test_ServiceStatus_NotFoundIsReturnedWhenNotStarted(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/services/1", nil)
 	resp := httptest.NewRecorder()
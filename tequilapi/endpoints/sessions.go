@@ -18,6 +18,11 @@
 package endpoints
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -25,8 +30,13 @@ import (
 	"github.com/go-openapi/strfmt/conv"
 	"github.com/mysteriumnetwork/go-rest/apierror"
 	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	node_session "github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/trace"
 	"github.com/vcraescu/go-paginator/adapter"
 )
 
@@ -36,14 +46,39 @@ type sessionStorage interface {
 	StatsByDay(*session.Filter) (map[time.Time]session.Stats, error)
 }
 
+type sessionTerminator interface {
+	Terminate(id node_session.ID, reason node_session.TerminationReason, message string) error
+}
+
+type bandwidthScheduler interface {
+	Allocation(sessionID string) (shaper.AllocationInfo, bool)
+	SetShare(sessionID string, share uint64) error
+}
+
+type sessionTraces interface {
+	Get(sessionID string) ([]trace.Span, bool)
+}
+
+type sessionStateProvider interface {
+	GetState() stateEvent.State
+}
+
 type sessionsEndpoint struct {
-	sessionStorage sessionStorage
+	sessionStorage     sessionStorage
+	sessionTerminator  sessionTerminator
+	bandwidthScheduler bandwidthScheduler
+	sessionTraces      sessionTraces
+	stateProvider      sessionStateProvider
 }
 
 // NewSessionsEndpoint creates and returns sessions endpoint
-func NewSessionsEndpoint(sessionStorage sessionStorage) *sessionsEndpoint {
+func NewSessionsEndpoint(sessionStorage sessionStorage, sessionTerminator sessionTerminator, bandwidthScheduler bandwidthScheduler, sessionTraces sessionTraces, stateProvider sessionStateProvider) *sessionsEndpoint {
 	return &sessionsEndpoint{
-		sessionStorage: sessionStorage,
+		sessionStorage:     sessionStorage,
+		sessionTerminator:  sessionTerminator,
+		bandwidthScheduler: bandwidthScheduler,
+		sessionTraces:      sessionTraces,
+		stateProvider:      stateProvider,
 	}
 }
 
@@ -85,9 +120,113 @@ func (endpoint *sessionsEndpoint) List(c *gin.Context) {
 	}
 
 	sessionsDTO := contract.NewSessionListResponse(sessions, p)
+	if endpoint.bandwidthScheduler != nil {
+		for i := range sessionsDTO.Items {
+			if allocation, ok := endpoint.bandwidthScheduler.Allocation(sessionsDTO.Items[i].ID); ok {
+				sessionsDTO.Items[i].Allocation = contract.NewSessionAllocationDTO(allocation)
+			}
+		}
+	}
 	utils.WriteAsJSON(sessionsDTO, c.Writer)
 }
 
+// swagger:operation GET /sessions/consumers/{id} Session sessionListByConsumer
+// ---
+// summary: Returns session history of a consumer
+// description: Returns sessions of the given consumer filtered by given query together with their summary
+// parameters:
+// - name: id
+//   in: path
+//   description: Consumer identity
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Consumer sessions
+//     schema:
+//       "$ref": "#/definitions/ConsumerSessionsResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *sessionsEndpoint) ListByConsumer(c *gin.Context) {
+	query := contract.NewSessionListQuery()
+	if err := query.Bind(c.Request); err != nil {
+		c.Error(err)
+		return
+	}
+	consumerID := c.Param("id")
+	query.ConsumerID = &consumerID
+
+	sessionsAll, err := endpoint.sessionStorage.List(query.ToFilter())
+	if err != nil {
+		c.Error(apierror.Internal("Could not list sessions: "+err.Error(), contract.ErrCodeSessionList))
+		return
+	}
+
+	var sessions []session.History
+	p := utils.NewPaginator(adapter.NewSliceAdapter(sessionsAll), query.PageSize, query.Page)
+	if err := p.Results(&sessions); err != nil {
+		c.Error(apierror.Internal("Could not paginate sessions: "+err.Error(), contract.ErrCodeSessionListPaginate))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConsumerSessionsResponse(consumerID, sessionsAll, sessions, p), c.Writer)
+}
+
+// swagger:operation GET /sessions/active Session sessionListActive
+// ---
+// summary: Returns ongoing provider sessions
+// description: Returns list of ongoing provider sessions with their live payment state
+// responses:
+//   200:
+//     description: List of ongoing sessions
+//     schema:
+//       "$ref": "#/definitions/ActiveSessionListResponse"
+func (endpoint *sessionsEndpoint) Active(c *gin.Context) {
+	state := endpoint.stateProvider.GetState()
+
+	response := contract.ActiveSessionListResponse{
+		Items: make([]contract.ActiveSessionDTO, 0, len(state.Sessions)),
+	}
+	for _, se := range state.Sessions {
+		item := contract.ActiveSessionDTO{
+			SessionDTO:        contract.NewSessionDTO(se),
+			LastInvoiceAmount: new(big.Int),
+			Unpaid:            new(big.Int),
+			Shortfall:         new(big.Int),
+		}
+		if payment, ok := state.SessionPayments[string(se.SessionID)]; ok {
+			item.LastInvoiceAmount = payment.LastInvoiceAmount
+			item.LastInvoiceAt = formatTime(payment.LastInvoiceAt)
+			item.LastExchangeMessageAt = formatTime(payment.LastExchangeMessageAt)
+			item.Unpaid = payment.Unpaid
+			if payment.Shortfall != nil {
+				item.Shortfall = payment.Shortfall
+			}
+		}
+		item.Traffic = state.SessionTraffic[string(se.SessionID)]
+		if endpoint.bandwidthScheduler != nil {
+			if allocation, ok := endpoint.bandwidthScheduler.Allocation(item.ID); ok {
+				item.Allocation = contract.NewSessionAllocationDTO(allocation)
+			}
+		}
+		response.Items = append(response.Items, item)
+	}
+	utils.WriteAsJSON(response, c.Writer)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
 // swagger:operation GET /sessions/stats-aggregated Session sessionStatsAggregated
 // ---
 // summary: Returns sessions stats
@@ -166,15 +305,178 @@ func (endpoint *sessionsEndpoint) StatsDaily(c *gin.Context) {
 	utils.WriteAsJSON(sessionsDTO, c.Writer)
 }
 
+// swagger:operation POST /sessions/{id}/terminate Session sessionTerminate
+// ---
+// summary: Terminates provided session
+// description: Terminates the ongoing provider session and notifies the consumer about the reason
+// parameters:
+// - in: path
+//   name: id
+//   description: Session ID
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Termination reason
+//   schema:
+//     $ref: "#/definitions/SessionTerminateRequestDTO"
+// responses:
+//   202:
+//     description: Session terminated
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: Session not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *sessionsEndpoint) Terminate(c *gin.Context) {
+	// Body is optional, session is terminated without a reason if it is empty.
+	req := contract.SessionTerminateRequest{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	reason := node_session.TerminationReasonUnspecified
+	if req.Reason != "" {
+		var ok bool
+		if reason, ok = node_session.ParseTerminationReason(req.Reason); !ok {
+			c.Error(apierror.BadRequestField("Unknown termination reason", apierror.ValidateErrInvalidVal, "reason"))
+			return
+		}
+	}
+
+	err := endpoint.sessionTerminator.Terminate(node_session.ID(c.Param("id")), reason, req.Message)
+	if errors.Is(err, service.ErrorSessionNotExists) {
+		c.Error(apierror.NotFound("Session not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not terminate session: "+err.Error(), contract.ErrCodeSessionTerminate))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// swagger:operation PUT /sessions/{id}/share Session sessionShare
+// ---
+// summary: Changes bandwidth share of provided session
+// description: Changes relative weight of the ongoing provider session used to divide the bandwidth between sessions
+// parameters:
+// - in: path
+//   name: id
+//   description: Session ID
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Session share
+//   schema:
+//     $ref: "#/definitions/SessionShareRequestDTO"
+// responses:
+//   200:
+//     description: Updated session allocation
+//     schema:
+//       "$ref": "#/definitions/SessionAllocationDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: Session not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   503:
+//     description: Bandwidth scheduling is not available
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *sessionsEndpoint) SetShare(c *gin.Context) {
+	if endpoint.bandwidthScheduler == nil {
+		c.Error(apierror.ServiceUnavailable())
+		return
+	}
+
+	req := contract.SessionShareRequest{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if req.Share == 0 {
+		c.Error(apierror.BadRequestField("Share must be positive", apierror.ValidateErrInvalidVal, "share"))
+		return
+	}
+
+	id := c.Param("id")
+	err := endpoint.bandwidthScheduler.SetShare(id, req.Share)
+	if errors.Is(err, shaper.ErrAllocationNotFound) {
+		c.Error(apierror.NotFound("Session not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.BadRequestField(err.Error(), apierror.ValidateErrInvalidVal, "share"))
+		return
+	}
+
+	allocation, ok := endpoint.bandwidthScheduler.Allocation(id)
+	if !ok {
+		c.Error(apierror.NotFound("Session not found"))
+		return
+	}
+	utils.WriteAsJSON(contract.NewSessionAllocationDTO(allocation), c.Writer)
+}
+
+// swagger:operation GET /sessions/{id}/trace Session sessionTrace
+// ---
+// summary: Returns session establishment timings
+// description: Returns durations of the session establishment stages, like proposal fetch, P2P dial, NAT traversal, service configuration and first payment
+// parameters:
+// - in: path
+//   name: id
+//   description: Session ID
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Session trace
+//     schema:
+//       "$ref": "#/definitions/SessionTraceDTO"
+//   404:
+//     description: Session trace not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (endpoint *sessionsEndpoint) Trace(c *gin.Context) {
+	id := c.Param("id")
+	spans, ok := endpoint.sessionTraces.Get(id)
+	if !ok {
+		c.Error(apierror.NotFound("Session trace not found"))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSessionTraceDTO(id, spans), c.Writer)
+}
+
 // AddRoutesForSessions attaches sessions endpoints to router
-func AddRoutesForSessions(sessionStorage sessionStorage) func(*gin.Engine) error {
-	sessionsEndpoint := NewSessionsEndpoint(sessionStorage)
+func AddRoutesForSessions(sessionStorage sessionStorage, sessionTerminator sessionTerminator, bandwidthScheduler bandwidthScheduler, sessionTraces sessionTraces, stateProvider sessionStateProvider) func(*gin.Engine) error {
+	sessionsEndpoint := NewSessionsEndpoint(sessionStorage, sessionTerminator, bandwidthScheduler, sessionTraces, stateProvider)
 	return func(e *gin.Engine) error {
 		g := e.Group("/sessions")
 		{
 			g.GET("", sessionsEndpoint.List)
+			g.GET("/active", sessionsEndpoint.Active)
+			g.GET("/consumers/:id", sessionsEndpoint.ListByConsumer)
 			g.GET("/stats-aggregated", sessionsEndpoint.StatsAggregated)
 			g.GET("/stats-daily", sessionsEndpoint.StatsDaily)
+			g.POST("/:id/terminate", sessionsEndpoint.Terminate)
+			g.PUT("/:id/share", sessionsEndpoint.SetShare)
+			g.GET("/:id/trace", sessionsEndpoint.Trace)
 		}
 		return nil
 	}
@@ -20,8 +20,10 @@ package endpoints
 import (
 	"encoding/json"
 	"errors"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -30,8 +32,13 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/core/shaper"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/identity"
 	node_session "github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/traffic"
+	"github.com/mysteriumnetwork/node/trace"
 )
 
 var (
@@ -91,7 +98,7 @@ func Test_SessionsEndpoint_List(t *testing.T) {
 	}
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewSessionsEndpoint(ssm).List
+	handlerFunc := NewSessionsEndpoint(ssm, nil, nil, nil, nil).List
 
 	g := summonTestGin()
 	g.GET(url, handlerFunc)
@@ -119,6 +126,61 @@ func Test_SessionsEndpoint_List(t *testing.T) {
 	assert.Equal(t, session.NewFilter(), ssm.calledWithFilter)
 }
 
+func Test_SessionsEndpoint_Active(t *testing.T) {
+	invoicedAt := time.Date(2010, time.January, 1, 12, 00, 50, 0, time.UTC)
+	unpaidSession := connectionSessionMock
+	unpaidSession.SessionID = "unpaid"
+	state := &mockStateProvider{stateToReturn: stateEvent.State{
+		Sessions: []session.History{connectionSessionMock, unpaidSession},
+		SessionPayments: map[string]stateEvent.SessionPayment{
+			"unpaid": {
+				LastInvoiceAmount: big.NewInt(300),
+				LastInvoiceAt:     invoicedAt,
+				Unpaid:            big.NewInt(100),
+				Shortfall:         big.NewInt(50),
+			},
+		},
+		SessionTraffic: map[string]traffic.Counters{
+			"unpaid": {"tcp/web": {Packets: 2, Bytes: 120}},
+		},
+	}}
+
+	req, err := http.NewRequest(http.MethodGet, "/sessions/active", nil)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	g := summonTestGin()
+	g.GET("/sessions/active", NewSessionsEndpoint(&sessionStorageMock{}, nil, nil, nil, state).Active)
+	g.ServeHTTP(resp, req)
+
+	parsedResponse := contract.ActiveSessionListResponse{}
+	err = json.Unmarshal(resp.Body.Bytes(), &parsedResponse)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(
+		t,
+		contract.ActiveSessionListResponse{
+			Items: []contract.ActiveSessionDTO{
+				{
+					SessionDTO:        contract.NewSessionDTO(connectionSessionMock),
+					LastInvoiceAmount: big.NewInt(0),
+					Unpaid:            big.NewInt(0),
+					Shortfall:         big.NewInt(0),
+				},
+				{
+					SessionDTO:        contract.NewSessionDTO(unpaidSession),
+					LastInvoiceAmount: big.NewInt(300),
+					LastInvoiceAt:     "2010-01-01T12:00:50Z",
+					Unpaid:            big.NewInt(100),
+					Shortfall:         big.NewInt(50),
+					Traffic:           traffic.Counters{"tcp/web": {Packets: 2, Bytes: 120}},
+				},
+			},
+		},
+		parsedResponse,
+	)
+}
+
 func Test_SessionsEndpoint_ListRespectsFilters(t *testing.T) {
 	path := "/sessions"
 	ssm := &sessionStorageMock{
@@ -133,7 +195,7 @@ func Test_SessionsEndpoint_ListRespectsFilters(t *testing.T) {
 	)
 	resp := httptest.NewRecorder()
 	g := summonTestGin()
-	g.GET(path, NewSessionsEndpoint(ssm).List)
+	g.GET(path, NewSessionsEndpoint(ssm, nil, nil, nil, nil).List)
 	g.ServeHTTP(resp, req)
 
 	// then
@@ -150,6 +212,59 @@ func Test_SessionsEndpoint_ListRespectsFilters(t *testing.T) {
 	assert.Equal(t, http.StatusOK, resp.Code)
 }
 
+func Test_SessionsEndpoint_ListByConsumer(t *testing.T) {
+	path := "/sessions/consumers/:id"
+	ssm := &sessionStorageMock{
+		sessionsToReturn: sessionsMock,
+	}
+
+	// when
+	req, _ := http.NewRequest(
+		http.MethodGet,
+		"/sessions/consumers/0x1?service_type=wireguard&min_duration=60&min_data=1024",
+		nil,
+	)
+	resp := httptest.NewRecorder()
+	g := summonTestGin()
+	g.GET(path, NewSessionsEndpoint(ssm, nil, nil, nil, nil).ListByConsumer)
+	g.ServeHTTP(resp, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(
+		t,
+		session.NewFilter().
+			SetConsumerID(identity.FromAddress("0x1")).
+			SetServiceType("wireguard").
+			SetMinDuration(time.Minute).
+			SetMinData(1024),
+		ssm.calledWithFilter,
+	)
+
+	parsedResponse := contract.ConsumerSessionsResponse{}
+	err := json.Unmarshal(resp.Body.Bytes(), &parsedResponse)
+	assert.NoError(t, err)
+	assert.Equal(t, "0x1", parsedResponse.ConsumerID)
+	assert.Equal(t, "2010-01-01T12:00:00Z", parsedResponse.FirstSeen)
+	assert.Equal(t, "2010-01-01T12:00:00Z", parsedResponse.LastSeen)
+	assert.Equal(t, map[string]int{"serviceType": 1}, parsedResponse.CountByService)
+	assert.Equal(t, 1, parsedResponse.Stats.Count)
+	assert.Equal(t, uint64(20), parsedResponse.Stats.SumBytesReceived+parsedResponse.Stats.SumBytesSent)
+	assert.Equal(t, []contract.SessionDTO{contract.NewSessionDTO(connectionSessionMock)}, parsedResponse.Items)
+}
+
+func Test_SessionsEndpoint_ListByConsumerValidatesQuery(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/sessions/consumers/0x1?min_duration=abc", nil)
+	resp := httptest.NewRecorder()
+	g := summonTestGin()
+	g.GET("/sessions/consumers/:id", NewSessionsEndpoint(&sessionStorageMock{}, nil, nil, nil, nil).ListByConsumer)
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	apiErr := apierror.Parse(resp.Result())
+	assert.Contains(t, apiErr.Err.Fields, "min_duration")
+}
+
 func Test_SessionsEndpoint_ListBubblesError(t *testing.T) {
 	path := "/sessions"
 	req, err := http.NewRequest(
@@ -165,7 +280,7 @@ func Test_SessionsEndpoint_ListBubblesError(t *testing.T) {
 	}
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewSessionsEndpoint(ssm).List
+	handlerFunc := NewSessionsEndpoint(ssm, nil, nil, nil, nil).List
 	g := summonTestGin()
 	g.GET(path, handlerFunc)
 	g.ServeHTTP(resp, req)
@@ -188,7 +303,7 @@ func Test_SessionsEndpoint_StatsAggregated(t *testing.T) {
 	}
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewSessionsEndpoint(ssm).StatsAggregated
+	handlerFunc := NewSessionsEndpoint(ssm, nil, nil, nil, nil).StatsAggregated
 	g := summonTestGin()
 	g.GET(path, handlerFunc)
 	g.ServeHTTP(resp, req)
@@ -222,7 +337,7 @@ func Test_SessionsEndpoint_StatsDaily(t *testing.T) {
 	}
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewSessionsEndpoint(ssm).StatsDaily
+	handlerFunc := NewSessionsEndpoint(ssm, nil, nil, nil, nil).StatsDaily
 	g := summonTestGin()
 	g.GET(path, handlerFunc)
 	g.ServeHTTP(resp, req)
@@ -246,6 +361,204 @@ func Test_SessionsEndpoint_StatsDaily(t *testing.T) {
 	assert.Equal(t, time.Now().UTC().Day(), ssm.calledWithFilter.StartedTo.Day())
 }
 
+func Test_SessionsEndpoint_Terminate(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		terminateErr   error
+		expectedStatus int
+		expectedReason node_session.TerminationReason
+	}{
+		{
+			name:           "terminates session with the given reason",
+			body:           `{"reason": "abusive_consumer", "message": "bye"}`,
+			expectedStatus: http.StatusAccepted,
+			expectedReason: node_session.TerminationReasonAbusiveConsumer,
+		},
+		{
+			name:           "defaults to unspecified reason",
+			body:           `{}`,
+			expectedStatus: http.StatusAccepted,
+			expectedReason: node_session.TerminationReasonUnspecified,
+		},
+		{
+			name:           "accepts empty body",
+			body:           ``,
+			expectedStatus: http.StatusAccepted,
+			expectedReason: node_session.TerminationReasonUnspecified,
+		},
+		{
+			name:           "rejects malformed body",
+			body:           `{"reason":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "rejects unknown reason",
+			body:           `{"reason": "boredom"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "returns not found for unknown session",
+			body:           `{}`,
+			terminateErr:   service.ErrorSessionNotExists,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "/sessions/ID/terminate", strings.NewReader(tt.body))
+			assert.Nil(t, err)
+
+			terminator := &sessionTerminatorMock{errToReturn: tt.terminateErr}
+			resp := httptest.NewRecorder()
+			g := summonTestGin()
+			g.POST("/sessions/:id/terminate", NewSessionsEndpoint(&sessionStorageMock{}, terminator, nil, nil, nil).Terminate)
+			g.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedStatus == http.StatusAccepted {
+				assert.Equal(t, node_session.ID("ID"), terminator.calledWithID)
+				assert.Equal(t, tt.expectedReason, terminator.calledWithReason)
+			}
+		})
+	}
+}
+
+func Test_SessionsEndpoint_SetShare(t *testing.T) {
+	scheduler := shaper.NewFairScheduler(shaper.LimiterFunc(func(time.Time) uint64 { return 3000 }))
+	scheduler.Join("ID", nil)
+	scheduler.Join("other", nil)
+
+	tests := []struct {
+		name           string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "changes session share",
+			path:           "/sessions/ID/share",
+			body:           `{"share": 2}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"share": 2, "bandwidth": 2000}`,
+		},
+		{
+			name:           "rejects zero share",
+			path:           "/sessions/ID/share",
+			body:           `{"share": 0}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "returns not found for unknown session",
+			path:           "/sessions/unknown/share",
+			body:           `{"share": 2}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPut, tt.path, strings.NewReader(tt.body))
+			assert.Nil(t, err)
+
+			resp := httptest.NewRecorder()
+			g := summonTestGin()
+			g.PUT("/sessions/:id/share", NewSessionsEndpoint(&sessionStorageMock{}, nil, scheduler, nil, nil).SetShare)
+			g.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+func Test_SessionsEndpoint_SetShareWithoutScheduler(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPut, "/sessions/ID/share", strings.NewReader(`{"share": 2}`))
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	g := summonTestGin()
+	g.PUT("/sessions/:id/share", NewSessionsEndpoint(&sessionStorageMock{}, nil, nil, nil, nil).SetShare)
+	g.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+}
+
+func Test_SessionsEndpoint_Trace(t *testing.T) {
+	started := time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)
+	traces := traceStoreMock{
+		"session-1": {
+			{Key: "Consumer whole Connect", Started: started, Duration: 2 * time.Second},
+			{Key: "Consumer proposal fetch", Started: started, Duration: 150 * time.Millisecond},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "returns session trace",
+			path:           "/sessions/session-1/trace",
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"session_id": "session-1",
+				"spans": [
+					{"key": "Consumer whole Connect", "started_at": "2022-06-01T10:00:00Z", "duration": 2000},
+					{"key": "Consumer proposal fetch", "started_at": "2022-06-01T10:00:00Z", "duration": 150}
+				]
+			}`,
+		},
+		{
+			name:           "returns not found for unknown session",
+			path:           "/sessions/unknown/trace",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			assert.Nil(t, err)
+
+			resp := httptest.NewRecorder()
+			g := summonTestGin()
+			g.GET("/sessions/:id/trace", NewSessionsEndpoint(&sessionStorageMock{}, nil, nil, traces, nil).Trace)
+			g.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, resp.Body.String())
+			}
+		})
+	}
+}
+
+type traceStoreMock map[string][]trace.Span
+
+func (tsm traceStoreMock) Get(sessionID string) ([]trace.Span, bool) {
+	spans, ok := tsm[sessionID]
+	return spans, ok
+}
+
+type sessionTerminatorMock struct {
+	calledWithID     node_session.ID
+	calledWithReason node_session.TerminationReason
+	errToReturn      error
+}
+
+func (stm *sessionTerminatorMock) Terminate(id node_session.ID, reason node_session.TerminationReason, message string) error {
+	stm.calledWithID = id
+	stm.calledWithReason = reason
+	return stm.errToReturn
+}
+
 type sessionStorageMock struct {
 	sessionsToReturn   []session.History
 	statsToReturn      session.Stats
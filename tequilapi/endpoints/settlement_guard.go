@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// settlementGuard keeps track of the settlement requests that are being processed,
+// so repeated or concurrent requests can not submit duplicate settlements for the same promise.
+type settlementGuard struct {
+	lock     sync.Mutex
+	inFlight map[string]struct{}
+}
+
+func newSettlementGuard() *settlementGuard {
+	return &settlementGuard{inFlight: make(map[string]struct{})}
+}
+
+// acquire marks the provider settlements with the given hermeses as in flight.
+// It fails with pingpong.ErrSettlementInProgress if any of them is already being processed.
+// The returned function must be called once the settlement completes.
+func (sg *settlementGuard) acquire(provider identity.Identity, hermesIDs ...common.Address) (func(), error) {
+	keys := make([]string, 0, len(hermesIDs))
+	for _, hermesID := range hermesIDs {
+		keys = append(keys, strings.ToLower(provider.Address)+"/"+hermesID.Hex())
+	}
+
+	sg.lock.Lock()
+	defer sg.lock.Unlock()
+
+	for _, key := range keys {
+		if _, ok := sg.inFlight[key]; ok {
+			return nil, pingpong.ErrSettlementInProgress
+		}
+	}
+	for _, key := range keys {
+		sg.inFlight[key] = struct{}{}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			sg.lock.Lock()
+			defer sg.lock.Unlock()
+			for _, key := range keys {
+				delete(sg.inFlight, key)
+			}
+		})
+	}, nil
+}
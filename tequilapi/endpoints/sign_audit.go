@@ -0,0 +1,92 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/vcraescu/go-paginator/adapter"
+
+	"github.com/mysteriumnetwork/node/identity/audit"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type signAuditStorage interface {
+	List(filter audit.Filter) ([]audit.Record, error)
+}
+
+type signAuditEndpoint struct {
+	storage signAuditStorage
+}
+
+// List lists signing operations performed by the identity
+// swagger:operation GET /identities/{id}/sign-audit Identity signAuditList
+// ---
+// summary: Returns signing operations performed by the identity
+// description: Returns the signing audit log of the identity, most recent operations first, so it can be verified the keys are not used unexpectedly
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Signing operations
+//     schema:
+//       "$ref": "#/definitions/SignAuditListResponse"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (se *signAuditEndpoint) List(c *gin.Context) {
+	query := contract.NewSignAuditListQuery()
+	if err := query.Bind(c.Request); err != nil {
+		c.Error(err)
+		return
+	}
+
+	recordsAll, err := se.storage.List(query.ToFilter(c.Param("id")))
+	if err != nil {
+		c.Error(apierror.Internal("Could not list signing operations: "+err.Error(), contract.ErrCodeIDSignAuditList))
+		return
+	}
+
+	var records []audit.Record
+	p := utils.NewPaginator(adapter.NewSliceAdapter(recordsAll), query.PageSize, query.Page)
+	if err := p.Results(&records); err != nil {
+		c.Error(apierror.Internal("Could not paginate signing operations: "+err.Error(), contract.ErrCodeIDSignAuditPaginate))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSignAuditListResponse(records, p), c.Writer)
+}
+
+// AddRoutesForSignAudit attaches identity signing audit endpoints to router
+func AddRoutesForSignAudit(storage signAuditStorage) func(*gin.Engine) error {
+	se := &signAuditEndpoint{storage: storage}
+	return func(e *gin.Engine) error {
+		e.GET("/identities/:id/sign-audit", se.List)
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/identity/audit"
+)
+
+type mockSignAuditStorage struct {
+	filter  audit.Filter
+	records []audit.Record
+}
+
+func (m *mockSignAuditStorage) List(filter audit.Filter) ([]audit.Record, error) {
+	m.filter = filter
+	return m.records, nil
+}
+
+func Test_SignAuditEndpoint_List(t *testing.T) {
+	storage := &mockSignAuditStorage{
+		records: []audit.Record{
+			{Identity: "0x1", Subsystem: "payments", MessageType: "exchange-message", Digest: "aa", Timestamp: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)},
+			{Identity: "0x1", Subsystem: "payments", MessageType: "exchange-message", Digest: "bb", Timestamp: time.Date(2022, 10, 1, 11, 0, 0, 0, time.UTC)},
+		},
+	}
+	router := summonTestGin()
+	err := AddRoutesForSignAudit(storage)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/0x1/sign-audit?subsystem=payments&date_from=2022-10-01&page_size=1", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	from := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, audit.Filter{Identity: "0x1", Subsystem: "payments", From: &from}, storage.filter)
+	assert.JSONEq(t, `{
+		"items": [
+			{"subsystem": "payments", "message_type": "exchange-message", "digest": "aa", "created_at": "2022-10-01T12:00:00Z"}
+		],
+		"page": 1,
+		"page_size": 1,
+		"total_items": 2,
+		"total_pages": 2
+	}`, resp.Body.String())
+}
+
+func Test_SignAuditEndpoint_ListValidatesQuery(t *testing.T) {
+	router := summonTestGin()
+	err := AddRoutesForSignAudit(&mockSignAuditStorage{})(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/identities/0x1/sign-audit?date_to=yesterday", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -226,6 +226,8 @@ type stateRes struct {
 	Consumer      consumerStateRes             `json:"consumer"`
 	Identities    []contract.IdentityDTO       `json:"identities"`
 	Channels      []contract.PaymentChannelDTO `json:"channels"`
+	// ResourcePressure is reported once the provider started shedding load.
+	ResourcePressure *contract.ResourcePressureDTO `json:"resource_pressure,omitempty"`
 }
 
 type consumerStateRes struct {
@@ -287,6 +289,18 @@ func mapState(state stateEvent.State) stateRes {
 		Identities: identitiesRes,
 		Channels:   channelsRes,
 	}
+	if pressure := state.ResourcePressure; pressure.Shedding || pressure.Rejected > 0 || len(pressure.Deprioritized) > 0 {
+		res.ResourcePressure = &contract.ResourcePressureDTO{
+			Shedding:        pressure.Shedding,
+			CPU:             pressure.CPU,
+			Memory:          pressure.Memory,
+			Conntrack:       pressure.Conntrack,
+			FileDescriptors: pressure.FileDescriptors,
+			Exceeded:        pressure.Exceeded,
+			Deprioritized:   pressure.Deprioritized,
+			Rejected:        pressure.Rejected,
+		}
+	}
 	return res
 }
 
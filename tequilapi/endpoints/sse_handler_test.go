@@ -38,6 +38,7 @@ import (
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 )
 
 type mockStateProvider struct {
@@ -289,3 +290,20 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 
 	<-serveExit
 }
+
+func TestMapState_ReportsResourcePressureOnceShedding(t *testing.T) {
+	assert.Nil(t, mapState(stateEvent.State{}).ResourcePressure)
+
+	res := mapState(stateEvent.State{ResourcePressure: stateEvent.ResourcePressure{
+		Shedding: true,
+		CPU:      95,
+		Exceeded: []string{"cpu"},
+		Rejected: 1,
+	}})
+	assert.Equal(t, &contract.ResourcePressureDTO{
+		Shedding: true,
+		CPU:      95,
+		Exceeded: []string{"cpu"},
+		Rejected: 1,
+	}, res.ResourcePressure)
+}
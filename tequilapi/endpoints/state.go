@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type revisionedStateProvider interface {
+	GetStateWithRevision() (stateEvent.State, uint64)
+}
+
+type stateEndpoint struct {
+	stateProvider revisionedStateProvider
+	// epoch distinguishes the revisions of this node run from the revisions of the previous runs.
+	epoch string
+}
+
+// State returns the current state of the node
+// swagger:operation GET /state State getState
+// ---
+// summary: Returns the node state
+// description: Returns the same state as sent in the state events. Response carries an ETag, clients polling the state
+//   should send it in If-None-Match header to receive 304 Not Modified while the state is unchanged.
+// parameters:
+//   - in: header
+//     name: If-None-Match
+//     description: ETag of the state held by the client
+//     type: string
+// responses:
+//   200:
+//     description: Node state
+//   304:
+//     description: State has not changed
+func (se *stateEndpoint) State(c *gin.Context) {
+	state, revision := se.stateProvider.GetStateWithRevision()
+	if utils.NotModified(c.Writer, c.Request, utils.WeakETag(se.epoch+"-"+strconv.FormatUint(revision, 10))) {
+		return
+	}
+
+	writer, closeWriter := utils.CompressedWriter(c.Writer, c.Request)
+	defer closeWriter()
+
+	utils.WriteAsJSON(mapState(state), writer)
+}
+
+// AddRoutesForState attaches state endpoints to router
+func AddRoutesForState(stateProvider revisionedStateProvider) func(*gin.Engine) error {
+	se := &stateEndpoint{
+		stateProvider: stateProvider,
+		epoch:         strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+	return func(e *gin.Engine) error {
+		e.GET("/state", se.State)
+		return nil
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+)
+
+type mockRevisionedStateProvider struct {
+	state    stateEvent.State
+	revision uint64
+}
+
+func (m *mockRevisionedStateProvider) GetStateWithRevision() (stateEvent.State, uint64) {
+	return m.state, m.revision
+}
+
+func Test_StateEndpoint_State(t *testing.T) {
+	stateProvider := &mockRevisionedStateProvider{
+		state: stateEvent.State{
+			Identities: []stateEvent.Identity{{Address: "0xd535eba31e9bd2d7a4e34852e6292b359e5c77f7"}},
+		},
+		revision: 7,
+	}
+	router := summonTestGin()
+	err := AddRoutesForState(stateProvider)(router)
+	assert.NoError(t, err)
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/state", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := get("")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res stateRes
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Len(t, res.Identities, 1)
+	etag := resp.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	resp = get(`"other", ` + etag)
+	assert.Equal(t, http.StatusNotModified, resp.Code)
+	assert.Empty(t, resp.Body.Bytes())
+
+	stateProvider.revision++
+	stateProvider.state.Identities = append(stateProvider.state.Identities, stateEvent.Identity{Address: "0x1"})
+	resp = get(etag)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.NotEqual(t, etag, resp.Header().Get("ETag"))
+}
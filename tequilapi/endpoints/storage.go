@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type storageMetrics interface {
+	Metrics() []boltdb.OperationStats
+}
+
+type storageEndpoint struct {
+	storage storageMetrics
+}
+
+// StorageMetrics returns the latencies of the storage operations
+// swagger:operation GET /node/storage/metrics Node storageMetrics
+// ---
+// summary: Returns storage latency metrics
+// description: Returns the latencies of the storage operations aggregated by operation and bucket since the node start.
+// responses:
+//   200:
+//     description: Storage latency metrics
+//     schema:
+//       "$ref": "#/definitions/StorageMetricsResponse"
+func (se *storageEndpoint) StorageMetrics(c *gin.Context) {
+	utils.WriteAsJSON(contract.NewStorageMetricsResponse(se.storage.Metrics()), c.Writer)
+}
+
+// AddRoutesForStorage attaches storage endpoints to router
+func AddRoutesForStorage(storage storageMetrics) func(*gin.Engine) error {
+	se := &storageEndpoint{storage: storage}
+	return func(e *gin.Engine) error {
+		e.GET("/node/storage/metrics", se.StorageMetrics)
+		return nil
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockStorageMetrics struct {
+	stats []boltdb.OperationStats
+}
+
+func (m *mockStorageMetrics) Metrics() []boltdb.OperationStats {
+	return m.stats
+}
+
+func Test_StorageEndpoint_StorageMetrics(t *testing.T) {
+	storage := &mockStorageMetrics{
+		stats: []boltdb.OperationStats{
+			{Operation: "get", Bucket: "myst_promises_137", Count: 4, Slow: 1, Total: 1200 * time.Millisecond, Max: 900500 * time.Microsecond},
+		},
+	}
+	router := summonTestGin()
+	err := AddRoutesForStorage(storage)(router)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/node/storage/metrics", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res contract.StorageMetricsResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	assert.Equal(t, []contract.StorageOperationDTO{
+		{Operation: "get", Bucket: "myst_promises_137", Count: 4, Slow: 1, AverageMs: 300, MaxMs: 900.5},
+	}, res.Operations)
+}
@@ -65,6 +65,8 @@ type Transactor interface {
 type promiseSettler interface {
 	ForceSettle(chainID int64, providerID identity.Identity, hermesID ...common.Address) error
 	SettleIntoStake(chainID int64, providerID identity.Identity, hermesID ...common.Address) error
+	PlanSettlementBatch(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) (pingpong.SettlementBatchPlan, error)
+	SettleBatch(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) error
 	GetHermesFee(chainID int64, id common.Address) (uint16, error)
 	Withdraw(fromChainID int64, toChainID int64, providerID identity.Identity, hermesID, beneficiary common.Address, amount *big.Int) error
 }
@@ -99,6 +101,7 @@ type transactorEndpoint struct {
 	bprovider                 beneficiaryProvider
 	bhandler                  beneficiarySaver
 	pilvytis                  pilvytisApi
+	settlements               *settlementGuard
 }
 
 // NewTransactorEndpoint creates and returns transactor endpoint
@@ -121,6 +124,7 @@ func NewTransactorEndpoint(
 		bprovider:                 bprovider,
 		bhandler:                  bhandler,
 		pilvytis:                  pilvytis,
+		settlements:               newSettlementGuard(),
 	}
 }
 
@@ -249,6 +253,10 @@ func (te *transactorEndpoint) TransactorFees(c *gin.Context) {
 // responses:
 //   202:
 //     description: Settle request accepted
+//   409:
+//     description: Settlement of the provider with the hermes is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
 //   500:
 //     description: Internal server error
 //     schema:
@@ -257,7 +265,7 @@ func (te *transactorEndpoint) SettleSync(c *gin.Context) {
 	err := te.settle(c.Request, te.promiseSettler.ForceSettle)
 	if err != nil {
 		log.Err(err).Msg("Settle failed")
-		utils.ForwardError(c, err, apierror.Internal("Could not force settle", contract.ErrCodeHermesSettle))
+		forwardSettleError(c, err, apierror.Internal("Could not force settle", contract.ErrCodeHermesSettle))
 		return
 	}
 	c.Status(http.StatusOK)
@@ -276,12 +284,16 @@ func (te *transactorEndpoint) SettleSync(c *gin.Context) {
 // responses:
 //   202:
 //     description: Settle request accepted
+//   409:
+//     description: Settlement of the provider with the hermes is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
 //   500:
 //     description: Internal server error
 //     schema:
 //       "$ref": "#/definitions/APIError"
 func (te *transactorEndpoint) SettleAsync(c *gin.Context) {
-	err := te.settle(c.Request, func(chainID int64, provider identity.Identity, hermes ...common.Address) error {
+	err := te.settleAsync(c.Request, te.promiseSettler.ForceSettle, func(chainID int64, provider identity.Identity) error {
 		providerId := provider.ToCommonAddress()
 		beneficiary, err := te.bprovider.GetBeneficiary(providerId)
 		if err != nil {
@@ -294,28 +306,140 @@ func (te *transactorEndpoint) SettleAsync(c *gin.Context) {
 		if isChannel {
 			return fmt.Errorf("payment channel is set as beneficiary, please turn on auto-withdrawals using your personal wallet address")
 		}
-		go func() {
-			err := te.promiseSettler.ForceSettle(chainID, provider, hermes...)
-			if err != nil {
-				log.Error().Err(err).Msgf("Could not settle provider(%q) promises", provider.Address)
-			}
-		}()
 		return nil
 	})
 	if err != nil {
-		utils.ForwardError(c, err, apierror.Internal("Failed to force settle async", contract.ErrCodeHermesSettleAsync))
+		forwardSettleError(c, err, apierror.Internal("Failed to force settle async", contract.ErrCodeHermesSettleAsync))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// swagger:operation POST /transactor/settle/batch/plan SettleBatchPlan
+// ---
+// summary: Plans the settlement of promises with multiple hermeses in a single transaction
+// description: Compares the fees against the unsettled amounts and reports the expected savings of settling the promises in a single transaction.
+// parameters:
+// - in: body
+//   name: body
+//   description: Settle request
+//   schema:
+//     $ref: "#/definitions/SettleRequestDTO"
+// responses:
+//   200:
+//     description: Settlement batch plan
+//     schema:
+//       "$ref": "#/definitions/SettlementBatchPlanDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (te *transactorEndpoint) SettleBatchPlan(c *gin.Context) {
+	chainID, provider, hermesIDs, err := parseSettleRequest(c.Request)
+	if err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	plan, err := te.promiseSettler.PlanSettlementBatch(chainID, provider, hermesIDs...)
+	if err != nil {
+		c.Error(apierror.Internal("Could not plan batch settlement: "+err.Error(), contract.ErrCodeHermesSettleBatch))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSettlementBatchPlanDTO(plan), c.Writer)
+}
+
+// swagger:operation POST /transactor/settle/batch/async SettleBatchAsync
+// ---
+// summary: Settles promises with multiple hermeses in a single transaction
+// description: Settles the promises worth settling in a single transaction, falls back to separate settlements if transactor does not support batching. Does not wait for completion.
+// parameters:
+// - in: body
+//   name: body
+//   description: Settle request
+//   schema:
+//     $ref: "#/definitions/SettleRequestDTO"
+// responses:
+//   202:
+//     description: Settle request accepted
+//   409:
+//     description: Settlement of the provider with the hermes is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (te *transactorEndpoint) SettleBatchAsync(c *gin.Context) {
+	err := te.settleAsync(c.Request, te.promiseSettler.SettleBatch, nil)
+	if err != nil {
+		forwardSettleError(c, err, apierror.Internal("Failed to settle batch async", contract.ErrCodeHermesSettleBatch))
 		return
 	}
 
 	c.Status(http.StatusAccepted)
 }
 
-func (te *transactorEndpoint) settle(request *http.Request, settler func(int64, identity.Identity, ...common.Address) error) error {
+type settleFunc func(chainID int64, provider identity.Identity, hermesIDs ...common.Address) error
+
+// settle runs the settlement and blocks until it completes.
+func (te *transactorEndpoint) settle(request *http.Request, settler settleFunc) error {
+	chainID, provider, hermesIDs, err := parseSettleRequest(request)
+	if err != nil {
+		return err
+	}
+
+	release, err := te.settlements.acquire(provider, hermesIDs...)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return settler(chainID, provider, hermesIDs...)
+}
+
+// settleAsync runs the optional check and starts the settlement in the background.
+// The settlement is kept in flight until it completes, so repeated requests are rejected meanwhile.
+func (te *transactorEndpoint) settleAsync(request *http.Request, settler settleFunc, check func(chainID int64, provider identity.Identity) error) error {
+	chainID, provider, hermesIDs, err := parseSettleRequest(request)
+	if err != nil {
+		return err
+	}
+
+	release, err := te.settlements.acquire(provider, hermesIDs...)
+	if err != nil {
+		return err
+	}
+
+	if check != nil {
+		if err := check(chainID, provider); err != nil {
+			release()
+			return err
+		}
+	}
+
+	go func() {
+		defer release()
+		if err := settler(chainID, provider, hermesIDs...); err != nil {
+			log.Error().Err(err).Msgf("Could not settle provider(%q) promises", provider.Address)
+		}
+	}()
+
+	return nil
+}
+
+func parseSettleRequest(request *http.Request) (int64, identity.Identity, []common.Address, error) {
 	req := contract.SettleRequest{}
 
 	err := json.NewDecoder(request.Body).Decode(&req)
 	if err != nil {
-		return errors.Wrap(err, "failed to unmarshal settle request")
+		return 0, identity.Identity{}, nil, errors.Wrap(err, "failed to unmarshal settle request")
 	}
 
 	hermesIDs := []common.Address{
@@ -328,11 +452,19 @@ func (te *transactorEndpoint) settle(request *http.Request, settler func(int64,
 	}
 
 	if len(hermesIDs) == 0 {
-		return errors.New("must specify a hermes to settle with")
+		return 0, identity.Identity{}, nil, errors.New("must specify a hermes to settle with")
 	}
 
-	chainID := config.GetInt64(config.FlagChainID)
-	return settler(chainID, identity.FromAddress(req.ProviderID), hermesIDs...)
+	return config.GetInt64(config.FlagChainID), identity.FromAddress(req.ProviderID), hermesIDs, nil
+}
+
+func forwardSettleError(c *gin.Context, err error, fallback *apierror.APIError) {
+	if errors.Is(err, pingpong.ErrSettlementInProgress) {
+		c.Error(apierror.Conflict("Settlement is already in progress", fallback.Err.Code, "provider_id"))
+		return
+	}
+
+	utils.ForwardError(c, err, fallback)
 }
 
 // swagger:operation POST /identities/{id}/register Identity RegisterIdentity
@@ -531,6 +663,10 @@ func (te *transactorEndpoint) DecreaseStake(c *gin.Context) {
 //     description: Failed to parse or request validation failed
 //     schema:
 //       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Settlement of the provider with the hermes is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
 //   500:
 //     description: Internal server error
 //     schema:
@@ -574,7 +710,15 @@ func (te *transactorEndpoint) Withdraw(c *gin.Context) {
 		toChainID = req.ToChainID
 	}
 
-	err = te.promiseSettler.Withdraw(fromChainID, toChainID, identity.FromAddress(req.ProviderID), common.HexToAddress(req.HermesID), common.HexToAddress(req.Beneficiary), amount)
+	providerID, hermesID := identity.FromAddress(req.ProviderID), common.HexToAddress(req.HermesID)
+	release, err := te.settlements.acquire(providerID, hermesID)
+	if err != nil {
+		forwardSettleError(c, err, apierror.Internal("Could not withdraw", contract.ErrCodeTransactorWithdraw))
+		return
+	}
+	defer release()
+
+	err = te.promiseSettler.Withdraw(fromChainID, toChainID, providerID, hermesID, common.HexToAddress(req.Beneficiary), amount)
 	if err != nil {
 		log.Err(err).Fields(map[string]interface{}{
 			"from_chain_id": fromChainID,
@@ -584,7 +728,7 @@ func (te *transactorEndpoint) Withdraw(c *gin.Context) {
 			"beneficiary":   req.Beneficiary,
 			"amount":        amount.String(),
 		}).Msg("Withdrawal failed")
-		utils.ForwardError(c, err, apierror.Internal("Could not withdraw", contract.ErrCodeTransactorWithdraw))
+		forwardSettleError(c, err, apierror.Internal("Could not withdraw", contract.ErrCodeTransactorWithdraw))
 		return
 	}
 
@@ -617,6 +761,10 @@ func (te *transactorEndpoint) parseWithdrawalAmount(amount string) (*big.Int, er
 // responses:
 //   202:
 //     description: Settle request accepted
+//   409:
+//     description: Settlement of the provider with the hermes is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
 //   500:
 //     description: Internal server error
 //     schema:
@@ -625,7 +773,7 @@ func (te *transactorEndpoint) SettleIntoStakeSync(c *gin.Context) {
 	err := te.settle(c.Request, te.promiseSettler.SettleIntoStake)
 	if err != nil {
 		log.Err(err).Msg("Settle into stake failed")
-		utils.ForwardError(c, err, apierror.Internal("Could not settle into stake", contract.ErrCodeTransactorSettle))
+		forwardSettleError(c, err, apierror.Internal("Could not settle into stake", contract.ErrCodeTransactorSettle))
 		return
 	}
 
@@ -645,22 +793,18 @@ func (te *transactorEndpoint) SettleIntoStakeSync(c *gin.Context) {
 // responses:
 //   202:
 //     description: Settle request accepted
+//   409:
+//     description: Settlement of the provider with the hermes is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
 //   500:
 //     description: Internal server error
 //     schema:
 //       "$ref": "#/definitions/APIError"
 func (te *transactorEndpoint) SettleIntoStakeAsync(c *gin.Context) {
-	err := te.settle(c.Request, func(chainID int64, provider identity.Identity, hermes ...common.Address) error {
-		go func() {
-			err := te.promiseSettler.SettleIntoStake(chainID, provider, hermes...)
-			if err != nil {
-				log.Error().Err(err).Msgf("could not settle into stake provider(%q) promises", provider.Address)
-			}
-		}()
-		return nil
-	})
+	err := te.settleAsync(c.Request, te.promiseSettler.SettleIntoStake, nil)
 	if err != nil {
-		utils.ForwardError(c, err, apierror.Internal("Could not settle into stake async", contract.ErrCodeTransactorSettle))
+		forwardSettleError(c, err, apierror.Internal("Could not settle into stake async", contract.ErrCodeTransactorSettle))
 		return
 	}
 
@@ -860,6 +1004,10 @@ func (te *transactorEndpoint) BeneficiaryTxStatus(c *gin.Context) {
 //     description: Failed to parse or request validation failed
 //     schema:
 //       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Settlement of the provider with the hermes is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
 func (te *transactorEndpoint) SettleWithBeneficiaryAsync(c *gin.Context) {
 	id := c.Param("id")
 
@@ -882,8 +1030,15 @@ func (te *transactorEndpoint) SettleWithBeneficiaryAsync(c *gin.Context) {
 		}
 	}
 
+	release, err := te.settlements.acquire(identity.FromAddress(id), hermeses...)
+	if err != nil {
+		forwardSettleError(c, err, apierror.Internal("Could not settle with beneficiary", contract.ErrCodeHermesSettleAsync))
+		return
+	}
+
 	go func() {
-		err = te.bhandler.SettleAndSaveBeneficiary(identity.FromAddress(id), hermeses, common.HexToAddress(req.Beneficiary))
+		defer release()
+		err := te.bhandler.SettleAndSaveBeneficiary(identity.FromAddress(id), hermeses, common.HexToAddress(req.Beneficiary))
 		if err != nil {
 			log.Err(err).Msgf("Failed set beneficiary request for ID: %s, %+v", id, req)
 		}
@@ -922,6 +1077,8 @@ func AddRoutesForTransactor(
 			transGroup.GET("/fees", te.TransactorFees)
 			transGroup.POST("/settle/sync", te.SettleSync)
 			transGroup.POST("/settle/async", te.SettleAsync)
+			transGroup.POST("/settle/batch/plan", te.SettleBatchPlan)
+			transGroup.POST("/settle/batch/async", te.SettleBatchAsync)
 			transGroup.GET("/settle/history", te.SettlementHistory)
 			transGroup.POST("/stake/increase/sync", te.SettleIntoStakeSync)
 			transGroup.POST("/stake/increase/async", te.SettleIntoStakeAsync)
@@ -57,7 +57,7 @@ func Test_RegisterIdentity(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 	err := AddRoutesForTransactor(&registry.FakeRegistry{RegistrationStatus: registry.Unregistered}, tr, a, nil, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, &mockPilvytis{})(router)
 	assert.NoError(t, err)
@@ -82,7 +82,7 @@ func Test_Get_TransactorFees(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{
 		feeToReturn: 11_000,
@@ -133,7 +133,7 @@ func Test_SettleAsync_OK(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, &mockBeneficiaryProvider{
 		b: common.HexToAddress("0x0000000000000000000000000000000000000001"),
@@ -161,7 +161,7 @@ func Test_SettleAsync_ReturnsError(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{errToReturn: errors.New("explosions everywhere")}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
 	assert.NoError(t, err)
@@ -187,7 +187,7 @@ func Test_SettleSync_OK(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
 	assert.NoError(t, err)
@@ -213,7 +213,7 @@ func Test_SettleSync_ReturnsError(t *testing.T) {
 
 	router := summonTestGin()
 
-	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, &mockSettler{errToReturn: errors.New("explosions everywhere")}, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
 	assert.NoError(t, err)
@@ -233,6 +233,87 @@ func Test_SettleSync_ReturnsError(t *testing.T) {
 	assert.Equal(t, "err_hermes_settle", apierror.Parse(resp.Result()).Err.Code)
 }
 
+func Test_SettleSync_RejectsConcurrentSettlement(t *testing.T) {
+	server := newTestTransactorServer(http.StatusAccepted, "")
+
+	router := summonTestGin()
+
+	tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
+	a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
+	settler := &mockSettler{
+		settleStarted: make(chan struct{}),
+		settleRelease: make(chan struct{}),
+	}
+	err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, settler, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
+	assert.NoError(t, err)
+
+	settle := func() *httptest.ResponseRecorder {
+		settleRequest := `{"hermes_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10", "provider_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10"}`
+		req, err := http.NewRequest(http.MethodPost, "/transactor/settle/sync", bytes.NewBufferString(settleRequest))
+		assert.Nil(t, err)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	first := make(chan *httptest.ResponseRecorder)
+	go func() { first <- settle() }()
+	<-settler.settleStarted
+
+	resp := settle()
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Equal(t, "err_hermes_settle", apierror.Parse(resp.Result()).Err.Code)
+
+	close(settler.settleRelease)
+	assert.Equal(t, http.StatusOK, (<-first).Code)
+
+	// once the first settlement is done, the next one is accepted again
+	go func() {
+		<-settler.settleStarted
+	}()
+	assert.Equal(t, http.StatusOK, settle().Code)
+}
+
+func Test_SettleBatchPlan(t *testing.T) {
+	router := summonTestGin()
+	settler := &mockSettler{planToReturn: pingpong.SettlementBatchPlan{
+		ChainID:       1,
+		ProviderID:    identity.FromAddress("0xbe180c8CA53F280C7BE8669596fF7939d933AA10"),
+		Items:         []pingpong.SettlementBatchItem{{HermesID: common.HexToAddress("0x1"), Amount: big.NewInt(500), HermesFee: big.NewInt(10)}},
+		Skipped:       []pingpong.SettlementBatchItem{{HermesID: common.HexToAddress("0x2"), Amount: big.NewInt(5), HermesFee: big.NewInt(10), Reason: "hermes fee exceeds the unsettled amount"}},
+		TransactorFee: big.NewInt(100),
+		SeparateFees:  big.NewInt(220),
+		BatchFees:     big.NewInt(120),
+		Savings:       big.NewInt(100),
+		Amount:        big.NewInt(1000),
+	}, batchSettled: make(chan []common.Address, 1)}
+	err := AddRoutesForTransactor(mockIdentityRegistryInstance, nil, nil, settler, &settlementHistoryProviderMock{}, &mockAddressProvider{}, nil, nil, nil)(router)
+	assert.NoError(t, err)
+
+	settleRequest := `{"hermes_ids": ["0x0000000000000000000000000000000000000001", "0x0000000000000000000000000000000000000002"], "provider_id": "0xbe180c8CA53F280C7BE8669596fF7939d933AA10"}`
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/transactor/settle/batch/plan", bytes.NewBufferString(settleRequest)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var plan contract.SettlementBatchPlanDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &plan))
+	assert.Len(t, plan.Items, 1)
+	assert.Len(t, plan.Skipped, 1)
+	assert.Equal(t, "100", plan.Savings.Wei)
+	assert.Equal(t, "hermes fee exceeds the unsettled amount", plan.Skipped[0].Reason)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/transactor/settle/batch/async", bytes.NewBufferString(settleRequest)))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	select {
+	case hermesIDs := <-settler.batchSettled:
+		assert.Len(t, hermesIDs, 2)
+	case <-time.After(time.Second):
+		t.Fatal("batch settlement was not started")
+	}
+}
+
 func Test_SettleHistory(t *testing.T) {
 	t.Run("returns error on failed history retrieval", func(t *testing.T) {
 		mockResponse := ""
@@ -240,7 +321,7 @@ func Test_SettleHistory(t *testing.T) {
 		defer server.Close()
 
 		router := summonTestGin()
-		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 		a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, &settlementHistoryProviderMock{errToReturn: errors.New("explosions everywhere")}, &mockAddressProvider{}, nil, nil, nil)(router)
 		assert.NoError(t, err)
@@ -277,7 +358,7 @@ func Test_SettleHistory(t *testing.T) {
 		defer server.Close()
 
 		router := summonTestGin()
-		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 		a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, mockStorage, &mockAddressProvider{}, nil, nil, nil)(router)
 		assert.NoError(t, err)
@@ -335,7 +416,7 @@ func Test_SettleHistory(t *testing.T) {
 		server := newTestTransactorServer(http.StatusAccepted, "")
 		defer server.Close()
 		router := summonTestGin()
-		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute)
+		tr := registry.NewTransactor(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL, &mockAddressProvider{}, fakeSignerFactory, mocks.NewEventBus(), nil, time.Minute, nil)
 		a := registry.NewAffiliator(requests.NewHTTPClient(server.URL, requests.DefaultTimeout), server.URL)
 		err := AddRoutesForTransactor(mockIdentityRegistryInstance, tr, a, nil, mockStorage, &mockAddressProvider{}, nil, nil, nil)(router)
 		assert.NoError(t, err)
@@ -508,14 +589,24 @@ func (fs *fakeSigner) Sign(message []byte) (identity.Signature, error) {
 type mockSettler struct {
 	errToReturn error
 
+	settleStarted chan struct{}
+	settleRelease chan struct{}
+
 	feeToReturn      uint16
 	feeErrorToReturn error
 
 	capturedToChainID   int64
 	capturedFromChainID int64
+
+	planToReturn pingpong.SettlementBatchPlan
+	batchSettled chan []common.Address
 }
 
 func (ms *mockSettler) ForceSettle(_ int64, _ identity.Identity, _ ...common.Address) error {
+	if ms.settleStarted != nil {
+		ms.settleStarted <- struct{}{}
+		<-ms.settleRelease
+	}
 	return ms.errToReturn
 }
 
@@ -523,6 +614,17 @@ func (ms *mockSettler) SettleIntoStake(_ int64, providerID identity.Identity, he
 	return nil
 }
 
+func (ms *mockSettler) PlanSettlementBatch(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) (pingpong.SettlementBatchPlan, error) {
+	return ms.planToReturn, ms.errToReturn
+}
+
+func (ms *mockSettler) SettleBatch(chainID int64, providerID identity.Identity, hermesIDs ...common.Address) error {
+	if ms.batchSettled != nil {
+		ms.batchSettled <- hermesIDs
+	}
+	return ms.errToReturn
+}
+
 func (ms *mockSettler) GetHermesFee(_ int64, _ common.Address) (uint16, error) {
 	return ms.feeToReturn, ms.feeErrorToReturn
 }
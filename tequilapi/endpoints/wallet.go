@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/wallet"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type spendingIdentityProvider interface {
+	SpendingIdentity() (identity.Identity, bool)
+}
+
+type consumerWallet interface {
+	spendingIdentityProvider
+	Summary(chainID int64) (wallet.Summary, error)
+	SetSpendingIdentity(address string) error
+}
+
+type walletEndpoint struct {
+	wallet consumerWallet
+}
+
+// Summary returns aggregated wallet of local consumer identities
+// swagger:operation GET /wallet Wallet walletSummary
+// ---
+// summary: Returns aggregated wallet of local consumer identities
+// description: Sums balances, registrations and spending across all local identities
+// responses:
+//   200:
+//     description: Wallet
+//     schema:
+//       "$ref": "#/definitions/WalletDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (we *walletEndpoint) Summary(c *gin.Context) {
+	summary, err := we.wallet.Summary(config.GetInt64(config.FlagChainID))
+	if err != nil {
+		c.Error(apierror.Internal("Could not get wallet: "+err.Error(), contract.ErrCodeWalletSummary))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewWalletDTO(summary), c.Writer)
+}
+
+// SetSpendingIdentity selects the identity new connections spend from
+// swagger:operation PUT /wallet/spending-identity Wallet walletSetSpendingIdentity
+// ---
+// summary: Selects the identity new connections spend from
+// description: Connections created without consumer_id spend from the selected identity, empty id clears the selection
+// parameters:
+// - in: body
+//   name: body
+//   required: true
+//   schema:
+//     $ref: "#/definitions/WalletSpendingIdentityRequestDTO"
+// responses:
+//   202:
+//     description: Spending identity selected
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   404:
+//     description: Identity not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (we *walletEndpoint) SetSpendingIdentity(c *gin.Context) {
+	var req contract.WalletSpendingIdentityRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	err := we.wallet.SetSpendingIdentity(req.ID)
+	if errors.Is(err, wallet.ErrUnknownIdentity) {
+		c.Error(apierror.NotFound("Identity not found"))
+		return
+	}
+	if err != nil {
+		c.Error(apierror.Internal("Could not select spending identity: "+err.Error(), contract.ErrCodeWalletSpendingIdentity))
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// AddRoutesForWallet attaches consumer wallet endpoints to router
+func AddRoutesForWallet(wallet consumerWallet) func(*gin.Engine) error {
+	we := &walletEndpoint{wallet: wallet}
+	return func(e *gin.Engine) error {
+		g := e.Group("/wallet")
+		{
+			g.GET("", we.Summary)
+			g.PUT("/spending-identity", we.SetSpendingIdentity)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/consumer/wallet"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+)
+
+type mockConsumerWallet struct {
+	spending identity.Identity
+}
+
+func (m *mockConsumerWallet) SpendingIdentity() (identity.Identity, bool) {
+	return m.spending, m.spending.Address != ""
+}
+
+func (m *mockConsumerWallet) Summary(_ int64) (wallet.Summary, error) {
+	id := identity.FromAddress("0x1")
+	return wallet.Summary{
+		ChainID:          137,
+		Balance:          big.NewInt(100),
+		Spent:            big.NewInt(7),
+		Registered:       1,
+		SpendingIdentity: &id,
+		Entries: []wallet.Entry{{
+			Identity:           id,
+			RegistrationStatus: registry.Registered,
+			Balance:            big.NewInt(100),
+			Spent:              big.NewInt(7),
+			Sessions:           3,
+			Spending:           true,
+		}},
+	}, nil
+}
+
+func (m *mockConsumerWallet) SetSpendingIdentity(address string) error {
+	if address == "0x2" {
+		return wallet.ErrUnknownIdentity
+	}
+	m.spending = identity.FromAddress(address)
+	return nil
+}
+
+func Test_WalletEndpoint(t *testing.T) {
+	w := &mockConsumerWallet{}
+	router := summonTestGin()
+	err := AddRoutesForWallet(w)(router)
+	assert.NoError(t, err)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := serve(http.MethodGet, "/wallet", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"chain_id": 137,
+		"balance_tokens": {"wei": "100", "ether": "0.0000000000000001", "human": "0"},
+		"spent_tokens": {"wei": "7", "ether": "0.000000000000000007", "human": "0"},
+		"registered": 1,
+		"spending_identity": "0x1",
+		"identities": [{
+			"id": "0x1",
+			"registration_status": "Registered",
+			"balance_tokens": {"wei": "100", "ether": "0.0000000000000001", "human": "0"},
+			"spent_tokens": {"wei": "7", "ether": "0.000000000000000007", "human": "0"},
+			"sessions": 3,
+			"spending": true
+		}]
+	}`, resp.Body.String())
+
+	resp = serve(http.MethodPut, "/wallet/spending-identity", `{"id": "0x3"}`)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, identity.FromAddress("0x3"), w.spending)
+
+	resp = serve(http.MethodPut, "/wallet/spending-identity", `{"id": "0x2"}`)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = serve(http.MethodPut, "/wallet/spending-identity", `{`)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
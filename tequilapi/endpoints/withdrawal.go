@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type withdrawalFlow interface {
+	Start(req pingpong.WithdrawalRequest) (pingpong.WithdrawalJob, error)
+	Job(id string) (pingpong.WithdrawalJob, bool)
+}
+
+type withdrawalEndpoint struct {
+	flow withdrawalFlow
+}
+
+// NewWithdrawalEndpoint creates and returns guided withdrawal endpoint.
+func NewWithdrawalEndpoint(flow withdrawalFlow) *withdrawalEndpoint {
+	return &withdrawalEndpoint{flow: flow}
+}
+
+// swagger:operation POST /transactor/withdrawal/jobs Withdrawal startWithdrawalJob
+// ---
+// summary: Starts the guided withdrawal
+// description: Settles the outstanding promises, waits for the settlement to be confirmed and then withdraws the settled balance to the given beneficiary. Progress is reported by the job status and the provider_withdrawal_job events.
+// parameters:
+// - in: body
+//   name: body
+//   description: Withdrawal request
+//   schema:
+//     $ref: "#/definitions/WithdrawRequestDTO"
+// responses:
+//   202:
+//     description: Withdrawal job started
+//     schema:
+//       "$ref": "#/definitions/WithdrawalJobDTO"
+//   400:
+//     description: Failed to parse or request validation failed
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   409:
+//     description: Withdrawal of the provider is already in progress
+//     schema:
+//       "$ref": "#/definitions/APIError"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (we *withdrawalEndpoint) Start(c *gin.Context) {
+	var req contract.WithdrawRequest
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		c.Error(apierror.ParseFailed())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.Error(err)
+		return
+	}
+
+	amount, err := req.AmountInMYST()
+	if err != nil {
+		c.Error(apierror.BadRequestField("'amount' is invalid", apierror.ValidateErrInvalidVal, "amount"))
+		return
+	}
+
+	fromChainID := config.GetInt64(config.FlagChainID)
+	if req.FromChainID != 0 {
+		if _, ok := registry.Chains()[req.FromChainID]; !ok {
+			c.Error(apierror.BadRequestField("Unsupported from_chain_id", apierror.ValidateErrInvalidVal, "from_chain_id"))
+			return
+		}
+
+		fromChainID = req.FromChainID
+	}
+
+	var toChainID int64
+	if req.ToChainID != 0 {
+		if _, ok := registry.Chains()[req.ToChainID]; !ok {
+			c.Error(apierror.BadRequestField("Unsupported to_chain_id", apierror.ValidateErrInvalidVal, "to_chain_id"))
+			return
+		}
+
+		toChainID = req.ToChainID
+	}
+
+	job, err := we.flow.Start(pingpong.WithdrawalRequest{
+		ProviderID:  identity.FromAddress(req.ProviderID),
+		HermesID:    common.HexToAddress(req.HermesID),
+		Beneficiary: common.HexToAddress(req.Beneficiary),
+		FromChainID: fromChainID,
+		ToChainID:   toChainID,
+		Amount:      amount,
+	})
+	if errors.Is(err, pingpong.ErrWithdrawalInProgress) {
+		c.Error(apierror.Conflict("Withdrawal is already in progress", contract.ErrCodeTransactorWithdrawalJob, "provider_id"))
+		return
+	}
+	if err != nil {
+		log.Err(err).Msg("Could not start withdrawal job")
+		utils.ForwardError(c, err, apierror.Internal("Could not start withdrawal", contract.ErrCodeTransactorWithdrawalJob))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewWithdrawalJobDTO(job), c.Writer, http.StatusAccepted)
+}
+
+// swagger:operation GET /transactor/withdrawal/jobs/{id} Withdrawal getWithdrawalJob
+// ---
+// summary: Returns the guided withdrawal job
+// description: Returns the current progress of the guided withdrawal job
+// parameters:
+// - in: path
+//   name: id
+//   description: Withdrawal job ID
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Withdrawal job
+//     schema:
+//       "$ref": "#/definitions/WithdrawalJobDTO"
+//   404:
+//     description: Withdrawal job not found
+//     schema:
+//       "$ref": "#/definitions/APIError"
+func (we *withdrawalEndpoint) Job(c *gin.Context) {
+	job, ok := we.flow.Job(c.Param("id"))
+	if !ok {
+		c.Error(apierror.NotFound("Withdrawal job not found"))
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewWithdrawalJobDTO(job), c.Writer)
+}
+
+// AddRoutesForWithdrawal attaches guided withdrawal endpoints to router.
+func AddRoutesForWithdrawal(flow withdrawalFlow) func(*gin.Engine) error {
+	we := NewWithdrawalEndpoint(flow)
+	return func(e *gin.Engine) error {
+		g := e.Group("/transactor/withdrawal")
+		{
+			g.POST("/jobs", we.Start)
+			g.GET("/jobs/:id", we.Job)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type mockWithdrawalFlow struct {
+	started  []pingpong.WithdrawalRequest
+	startErr error
+	jobs     map[string]pingpong.WithdrawalJob
+}
+
+func (m *mockWithdrawalFlow) Start(req pingpong.WithdrawalRequest) (pingpong.WithdrawalJob, error) {
+	if m.startErr != nil {
+		return pingpong.WithdrawalJob{}, m.startErr
+	}
+	m.started = append(m.started, req)
+	return pingpong.WithdrawalJob{ID: "job1", Request: req, Status: pingpong.WithdrawalJobSettling}, nil
+}
+
+func (m *mockWithdrawalFlow) Job(id string) (pingpong.WithdrawalJob, bool) {
+	job, ok := m.jobs[id]
+	return job, ok
+}
+
+const validWithdrawalRequest = `{
+	"provider_id": "0x0000000000000000000000000000000000000001",
+	"hermes_id": "0x0000000000000000000000000000000000000002",
+	"beneficiary": "0x0000000000000000000000000000000000000003",
+	"amount": "1000"
+}`
+
+func Test_WithdrawalEndpoint_Start(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		startErr       error
+		expectedStatus int
+		expectedStarts int
+	}{
+		{name: "starts job", body: validWithdrawalRequest, expectedStatus: http.StatusAccepted, expectedStarts: 1},
+		{name: "rejects invalid beneficiary", body: `{"provider_id": "0x0000000000000000000000000000000000000001", "hermes_id": "0x0000000000000000000000000000000000000002", "beneficiary": "0x0"}`, expectedStatus: http.StatusBadRequest},
+		{name: "rejects unparsable body", body: `{`, expectedStatus: http.StatusBadRequest},
+		{name: "rejects concurrent withdrawal", body: validWithdrawalRequest, startErr: pingpong.ErrWithdrawalInProgress, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := &mockWithdrawalFlow{startErr: tt.startErr}
+			router := summonTestGin()
+			err := AddRoutesForWithdrawal(flow)(router)
+			assert.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/transactor/withdrawal/jobs", strings.NewReader(tt.body))
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Len(t, flow.started, tt.expectedStarts)
+			if tt.expectedStarts == 0 {
+				return
+			}
+
+			var job contract.WithdrawalJobDTO
+			assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &job))
+			assert.Equal(t, "job1", job.ID)
+			assert.Equal(t, "settling", job.Status)
+			assert.Equal(t, "1000", job.Amount)
+			assert.Equal(t, "0x0000000000000000000000000000000000000003", job.Beneficiary)
+		})
+	}
+}
+
+func Test_WithdrawalEndpoint_Job(t *testing.T) {
+	flow := &mockWithdrawalFlow{jobs: map[string]pingpong.WithdrawalJob{
+		"job1": {ID: "job1", Status: pingpong.WithdrawalJobFailed, Error: "could not withdraw"},
+	}}
+	router := summonTestGin()
+	err := AddRoutesForWithdrawal(flow)(router)
+	assert.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/transactor/withdrawal/jobs/job1", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	var job contract.WithdrawalJobDTO
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &job))
+	assert.Equal(t, "failed", job.Status)
+	assert.Equal(t, "could not withdraw", job.Error)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/transactor/withdrawal/jobs/unknown", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,210 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package errcode groups the stable TequilAPI error codes into categories shared by the handlers and the client.
+package errcode
+
+import (
+	"github.com/mysteriumnetwork/go-rest/apierror"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// Category groups error codes by the kind of failure.
+// Once created, do not change the string value, because consumers may depend on it - it's part of the contract.
+type Category string
+
+const (
+	// CategoryValidation marks errors caused by a malformed or invalid request.
+	CategoryValidation Category = "validation"
+	// CategoryNotFound marks errors caused by a missing resource.
+	CategoryNotFound Category = "not_found"
+	// CategoryUnauthorized marks errors caused by missing or invalid credentials.
+	CategoryUnauthorized Category = "unauthorized"
+	// CategoryUnavailable marks errors caused by a temporarily unavailable dependency.
+	CategoryUnavailable Category = "unavailable"
+	// CategoryIdentity marks errors of identity management.
+	CategoryIdentity Category = "identity"
+	// CategoryConnection marks errors of consumer connection management.
+	CategoryConnection Category = "connection"
+	// CategoryService marks errors of provider service management.
+	CategoryService Category = "service"
+	// CategoryPayment marks errors of payments, promises and settlements.
+	CategoryPayment Category = "payment"
+	// CategoryBlockchain marks errors of registration and other on-chain lookups.
+	CategoryBlockchain Category = "blockchain"
+	// CategoryNAT marks errors of NAT detection and traversal.
+	CategoryNAT Category = "nat"
+	// CategoryDiscovery marks errors of proposal discovery and pricing.
+	CategoryDiscovery Category = "discovery"
+	// CategorySession marks errors of session history and management.
+	CategorySession Category = "session"
+	// CategoryNode marks errors of node configuration, UI and reporting.
+	CategoryNode Category = "node"
+	// CategoryInternal marks every other error.
+	CategoryInternal Category = "internal"
+)
+
+var categories = map[string]Category{
+	apierror.ErrCodeParseFailed:      CategoryValidation,
+	apierror.ErrCodeValidationFailed: CategoryValidation,
+	apierror.ErrCodeNotFound:         CategoryNotFound,
+	apierror.ErrCodeUnauthorized:     CategoryUnauthorized,
+	apierror.ErrCodeUnavailable:      CategoryUnavailable,
+	apierror.ErrCodeInternal:         CategoryInternal,
+
+	contract.ErrCodeIDImport:                      CategoryIdentity,
+	contract.ErrCodeIDSetDefault:                  CategoryIdentity,
+	contract.ErrCodeIDUseOrCreate:                 CategoryIdentity,
+	contract.ErrCodeIDUnlock:                      CategoryIdentity,
+	contract.ErrCodeIDLocked:                      CategoryIdentity,
+	contract.ErrCodeIDCreate:                      CategoryIdentity,
+	contract.ErrCodeIDGetPayoutAddress:            CategoryIdentity,
+	contract.ErrCodeIDSavePayoutAddress:           CategoryValidation,
+	contract.ErrCodeIDCalculateAddress:            CategoryBlockchain,
+	contract.ErrCodeIDNotRegistered:               CategoryBlockchain,
+	contract.ErrCodeIDStatusUnknown:               CategoryBlockchain,
+	contract.ErrCodeIDRegistrationCheck:           CategoryBlockchain,
+	contract.ErrCodeIDRegistrationBalance:         CategoryBlockchain,
+	contract.ErrCodeIDRegistrationPending:         CategoryBlockchain,
+	contract.ErrCodeIDRegistrationBump:            CategoryBlockchain,
+	contract.ErrCodeIDRegistrationCancel:          CategoryBlockchain,
+	contract.ErrCodeIDBlockchainRegistrationCheck: CategoryBlockchain,
+	contract.ErrCodeIDRegistrationInProgress:      CategoryBlockchain,
+	contract.ErrCodeHermesMigration:               CategoryBlockchain,
+	contract.ErrCodeCheckHermesMigrationStatus:    CategoryBlockchain,
+	contract.ErrCodeProviderHermesMigration:       CategoryBlockchain,
+	contract.ErrCodeIDSignAuditList:               CategoryIdentity,
+	contract.ErrCodeIDSignAuditPaginate:           CategoryIdentity,
+	contract.ErrCodeNodeAttestationKey:            CategoryIdentity,
+
+	contract.ErrCodePaymentCreate:          CategoryPayment,
+	contract.ErrCodePaymentGet:             CategoryPayment,
+	contract.ErrCodePaymentGetInvoice:      CategoryPayment,
+	contract.ErrCodePaymentList:            CategoryPayment,
+	contract.ErrCodePaymentListCurrencies:  CategoryPayment,
+	contract.ErrCodePaymentGetOptions:      CategoryPayment,
+	contract.ErrCodePaymentListGateways:    CategoryPayment,
+	contract.ErrCodeReferralGetToken:       CategoryPayment,
+	contract.ErrCodeBeneficiaryGet:         CategoryPayment,
+	contract.ErrCodeWalletSummary:          CategoryPayment,
+	contract.ErrCodeWalletSpendingIdentity: CategoryIdentity,
+
+	contract.ErrCodeConnectionAlreadyExists:  CategoryConnection,
+	contract.ErrCodeConnectionCancelled:      CategoryConnection,
+	contract.ErrCodeConnect:                  CategoryConnection,
+	contract.ErrCodeNoConnectionExists:       CategoryNotFound,
+	contract.ErrCodeDisconnect:               CategoryConnection,
+	contract.ErrCodeConnectionDiagnostics:    CategoryConnection,
+	contract.ErrCodeConnectionConfigExport:   CategoryConnection,
+	contract.ErrCodeConnectionLeakTest:       CategoryConnection,
+	contract.ErrCodeConnectionSpeedtest:      CategoryConnection,
+	contract.ErrCodeEphemeralIdentity:        CategoryConnection,
+	contract.ErrCodeEphemeralIdentityPending: CategoryConnection,
+	contract.ErrCodeConnectionPause:          CategoryConnection,
+
+	contract.ErrCodeNATProbe: CategoryNAT,
+
+	contract.ErrCodeProposalsQuery:          CategoryDiscovery,
+	contract.ErrCodeProposalsCountryQuery:   CategoryDiscovery,
+	contract.ErrCodeProposalsPresets:        CategoryDiscovery,
+	contract.ErrCodeProposalsServiceType:    CategoryValidation,
+	contract.ErrCodeProposalsPrices:         CategoryDiscovery,
+	contract.ErrCodeProposalsDetectLocation: CategoryDiscovery,
+	contract.ErrCodeProposalsFavorites:      CategoryDiscovery,
+	contract.ErrCodeProposalsBandwidthTier:  CategoryValidation,
+	contract.ErrCodeFavoritesList:           CategoryDiscovery,
+	contract.ErrCodeFavoritesGet:            CategoryDiscovery,
+	contract.ErrCodeFavoritesSave:           CategoryDiscovery,
+	contract.ErrCodeFavoritesDelete:         CategoryDiscovery,
+
+	contract.ErrCodeSessionList:          CategorySession,
+	contract.ErrCodeSessionListPaginate:  CategorySession,
+	contract.ErrCodeSessionStats:         CategorySession,
+	contract.ErrCodeSessionStatsDaily:    CategorySession,
+	contract.ErrCodeSessionTerminate:     CategorySession,
+	contract.ErrCodeSessionPacketCapture: CategorySession,
+
+	contract.ErrCodeServiceList:       CategoryService,
+	contract.ErrCodeServiceGet:        CategoryService,
+	contract.ErrCodeServiceRunning:    CategoryService,
+	contract.ErrCodeServiceLocation:   CategoryService,
+	contract.ErrCodeServiceStart:      CategoryService,
+	contract.ErrCodeServiceStop:       CategoryService,
+	contract.ErrCodeServiceRestart:    CategoryService,
+	contract.ErrCodeServicePreview:    CategoryService,
+	contract.ErrCodeAccessLogList:     CategoryService,
+	contract.ErrCodeAccessLogPaginate: CategoryService,
+	contract.ErrCodeAccessLogExport:   CategoryService,
+
+	contract.ErrorCodeProviderSessions:              CategoryService,
+	contract.ErrorCodeProviderTransferredData:       CategoryService,
+	contract.ErrorCodeProviderSessionsCount:         CategoryService,
+	contract.ErrorCodeProviderConsumersCount:        CategoryService,
+	contract.ErrorCodeProviderEarningsSeries:        CategoryService,
+	contract.ErrorCodeProviderSessionsSeries:        CategoryService,
+	contract.ErrorCodeProviderTransferredDataSeries: CategoryService,
+	contract.ErrorCodeProviderQuality:               CategoryService,
+	contract.ErrorCodeProviderReputation:            CategoryService,
+	contract.ErrorCodeProviderActivityStats:         CategoryService,
+	contract.ErrorCodeProviderServiceEarnings:       CategoryService,
+
+	contract.ErrCodeTransactorRegistration:          CategoryBlockchain,
+	contract.ErrCodeTransactorFetchFees:             CategoryBlockchain,
+	contract.ErrCodeTransactorDecreaseStake:         CategoryBlockchain,
+	contract.ErrCodeTransactorBeneficiary:           CategoryBlockchain,
+	contract.ErrCodeTransactorBeneficiaryTxStatus:   CategoryBlockchain,
+	contract.ErrCodeTransactorSettleHistory:         CategoryPayment,
+	contract.ErrCodeTransactorSettleHistoryPaginate: CategoryPayment,
+	contract.ErrCodeTransactorWithdraw:              CategoryPayment,
+	contract.ErrCodeTransactorWithdrawalJob:         CategoryPayment,
+	contract.ErrCodeTransactorSettle:                CategoryPayment,
+	contract.ErrCodeTransactorSettleAsync:           CategoryPayment,
+	contract.ErrCodeTransactorNoReward:              CategoryPayment,
+
+	contract.ErrCodeAffiliatorNoReward: CategoryPayment,
+	contract.ErrCodeAffiliatorFailed:   CategoryPayment,
+
+	contract.ErrCodeActiveHermes:      CategoryBlockchain,
+	contract.ErrCodeHermesFee:         CategoryBlockchain,
+	contract.ErrCodeHermesSettle:      CategoryPayment,
+	contract.ErrCodeHermesSettleAsync: CategoryPayment,
+	contract.ErrCodeHermesSettleBatch: CategoryPayment,
+	contract.ErrCodeHermesPromiseList: CategoryPayment,
+
+	contract.ErrCodeConfigSave:                 CategoryNode,
+	contract.ErrCodeConfigCLIOnly:              CategoryValidation,
+	contract.ErrCodeFeedbackSubmit:             CategoryNode,
+	contract.ErrCodeMMNAPIKey:                  CategoryNode,
+	contract.ErrCodeMMNNodeAlreadyClaimed:      CategoryNode,
+	contract.ErrCodeMMNRegistration:            CategoryNode,
+	contract.ErrCodeUILocalVersions:            CategoryNode,
+	contract.ErrCodeUISwitchVersion:            CategoryNode,
+	contract.ErrCodeUIDownload:                 CategoryNode,
+	contract.ErrCodeUIBundledVersion:           CategoryNode,
+	contract.ErrCodeUIUsedVersion:              CategoryNode,
+	contract.ErrCodeReportStatement:            CategoryNode,
+	contract.ErrorCodeLatestReleaseInformation: CategoryNode,
+}
+
+// Of returns the category of the given error code, unknown codes are treated as internal errors.
+func Of(code string) Category {
+	if category, ok := categories[code]; ok {
+		return category
+	}
+	return CategoryInternal
+}
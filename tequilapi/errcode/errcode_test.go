@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package errcode
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+func TestOf(t *testing.T) {
+	assert.Equal(t, CategoryValidation, Of(apierror.ErrCodeValidationFailed))
+	assert.Equal(t, CategoryNotFound, Of(contract.ErrCodeNoConnectionExists))
+	assert.Equal(t, CategoryPayment, Of(contract.ErrCodeHermesSettle))
+	assert.Equal(t, CategoryBlockchain, Of(contract.ErrCodeIDRegistrationCheck))
+	assert.Equal(t, CategoryNAT, Of(contract.ErrCodeNATProbe))
+	assert.Equal(t, CategoryDiscovery, Of(contract.ErrCodeProposalsQuery))
+	assert.Equal(t, CategorySession, Of(contract.ErrCodeSessionList))
+	assert.Equal(t, CategoryNode, Of(contract.ErrCodeMMNAPIKey))
+	assert.Equal(t, CategoryInternal, Of("err_unknown"))
+}
+
+func TestAllContractCodesAreCategorized(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "../contract/errcodes.go", nil, 0)
+	assert.NoError(t, err)
+
+	var codes int
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for i, name := range spec.Names {
+			lit, ok := spec.Values[i].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				continue
+			}
+			code, err := strconv.Unquote(lit.Value)
+			assert.NoError(t, err)
+
+			codes++
+			_, ok = categories[code]
+			assert.True(t, ok, "error code %s (%s) has no category", name.Name, code)
+		}
+		return false
+	})
+	assert.NotZero(t, codes)
+}
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedJSON string
+	}{
+		{
+			name:         "categorizes api error",
+			err:          apierror.Internal("Could not probe NAT", contract.ErrCodeNATProbe),
+			expectedCode: http.StatusInternalServerError,
+			expectedJSON: `{"error": {"code": "err_nat_probe", "message": "Could not probe NAT", "category": "nat"}, "status": 500, "path": "/test"}`,
+		},
+		{
+			name:         "wraps plain error as internal",
+			err:          errors.New("boom"),
+			expectedCode: http.StatusInternalServerError,
+			expectedJSON: `{"error": {"code": "internal", "message": "boom", "category": "internal"}, "status": 500, "path": "/test"}`,
+		},
+		{
+			name:         "keeps validation fields",
+			err:          apierror.BadRequestField("'amount' is invalid", apierror.ValidateErrInvalidVal, "amount"),
+			expectedCode: http.StatusBadRequest,
+			expectedJSON: `{"error": {"code": "validation_failed", "message": "Request validation failed", "detail": "'amount' is invalid: amount: 'amount' is invalid [invalid_value]", "fields": {"amount": {"code": "invalid_value", "message": "'amount' is invalid"}}, "category": "validation"}, "status": 400, "path": "/test"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gin.New()
+			g.Use(ErrorHandler)
+			g.GET("/test", func(c *gin.Context) {
+				c.Error(tt.err)
+			})
+
+			resp := httptest.NewRecorder()
+			g.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+			assert.Equal(t, tt.expectedCode, resp.Code)
+			assert.Equal(t, apierror.ContentTypeV1, resp.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedJSON, resp.Body.String())
+		})
+	}
+}
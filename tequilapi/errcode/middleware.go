@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package errcode
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/mysteriumnetwork/go-rest/apierror"
+)
+
+// Envelope is the JSON body of every TequilAPI error response.
+// It extends apierror.APIError with the error category, so clients parsing the plain APIError keep working.
+type Envelope struct {
+	Err    Err    `json:"error"`
+	Status int    `json:"status"`
+	Path   string `json:"path"`
+}
+
+// Err contains the error details along with the category of its code.
+type Err struct {
+	apierror.Err
+	Category Category `json:"category"`
+}
+
+// NewEnvelope wraps the given API error into the categorized envelope.
+func NewEnvelope(apiErr *apierror.APIError) Envelope {
+	return Envelope{
+		Err: Err{
+			Err:      apiErr.Err,
+			Category: Of(apiErr.Err.Code),
+		},
+		Status: apiErr.Status,
+		Path:   apiErr.Path,
+	}
+}
+
+// ErrorHandler gets the first error from request context and formats it to a categorized error response.
+func ErrorHandler(c *gin.Context) {
+	c.Next()
+	if len(c.Errors) < 1 {
+		return
+	}
+	err := c.Errors[0].Err
+
+	var apiErr *apierror.APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = apierror.Internal(err.Error(), apierror.ErrCodeInternal)
+	}
+	apiErr.Path = c.Request.URL.String()
+
+	blob, err := json.Marshal(NewEnvelope(apiErr))
+	if err != nil {
+		c.Data(http.StatusInternalServerError, apierror.ContentTypeV1, apierror.DefaultErrStatic)
+		return
+	}
+	c.Data(apiErr.Status, apierror.ContentTypeV1, blob)
+}
@@ -24,7 +24,7 @@ import (
 	"time"
 
 	"github.com/gin-contrib/cors"
-	"github.com/mysteriumnetwork/go-rest/apierror"
+	"github.com/mysteriumnetwork/node/tequilapi/errcode"
 	"github.com/mysteriumnetwork/node/tequilapi/middlewares"
 
 	"github.com/mysteriumnetwork/node/core/node"
@@ -65,11 +65,19 @@ func NewServer(
 ) (APIServer, error) {
 	gin.SetMode(modeFromOptions(nodeOptions))
 	g := gin.New()
+	if nodeOptions.TequilapiRequestLog.Enabled {
+		g.Use(middlewares.NewRequestLogger(middlewares.RequestLogConfig{
+			SampleRate: nodeOptions.TequilapiRequestLog.SampleRate,
+			Bodies:     nodeOptions.TequilapiRequestLog.Bodies,
+			Include:    nodeOptions.TequilapiRequestLog.Include,
+			Exclude:    nodeOptions.TequilapiRequestLog.Exclude,
+		}))
+	}
 	g.Use(middlewares.ApplyCacheConfigMiddleware)
 	g.Use(gin.Recovery())
 	g.Use(cors.New(corsConfig))
 	g.Use(middlewares.NewHostFilter())
-	g.Use(apierror.ErrorHandler)
+	g.Use(errcode.ErrorHandler)
 
 	for _, h := range handlers {
 		err := h(g)
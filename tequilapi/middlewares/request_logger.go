@@ -0,0 +1,225 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	redacted = "[REDACTED]"
+	// maxLoggedBody limits how much of request and response bodies ends up in the log.
+	maxLoggedBody = 4096
+)
+
+var redactedHeaders = map[string]struct{}{
+	"Authorization":       {},
+	"Proxy-Authorization": {},
+	"Cookie":              {},
+	"Set-Cookie":          {},
+}
+
+var sensitiveKeys = []string{"passphrase", "password", "token", "secret", "private", "mnemonic"}
+
+// RequestLogConfig configures the tequilapi request logging.
+type RequestLogConfig struct {
+	// SampleRate is the fraction of matching requests which are logged, from 0 to 1.
+	SampleRate float64
+	// Bodies enables logging of request and response bodies.
+	Bodies bool
+	// Include limits logging to the paths with given prefixes, all paths are logged if empty.
+	Include []string
+	// Exclude disables logging for the paths with given prefixes.
+	Exclude []string
+}
+
+func (c RequestLogConfig) matches(path string) bool {
+	for _, prefix := range c.Exclude {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+
+	if len(c.Include) == 0 {
+		return true
+	}
+	for _, prefix := range c.Include {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewRequestLogger returns a middleware logging the tequilapi requests and responses
+// with credentials and passphrases redacted.
+func NewRequestLogger(cfg RequestLogConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.matches(c.Request.URL.Path) || rand.Float64() >= cfg.SampleRate {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		var reqBody []byte
+		var resp *bodyCapture
+		if cfg.Bodies {
+			reqBody = readRequestBody(c.Request)
+			resp = &bodyCapture{ResponseWriter: c.Writer}
+			c.Writer = resp
+		}
+
+		c.Next()
+
+		entry := log.Info().
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Str("query", redactQuery(c.Request.URL.Query())).
+			Int("status", c.Writer.Status()).
+			Dur("duration", time.Since(start)).
+			Interface("request_headers", redactHeaders(c.Request.Header)).
+			Interface("response_headers", redactHeaders(c.Writer.Header()))
+		if cfg.Bodies {
+			entry = entry.
+				Str("request_body", redactBody(reqBody)).
+				Str("response_body", redactBody(resp.buf.Bytes()))
+		}
+		entry.Msg("Tequilapi request")
+	}
+}
+
+func readRequestBody(r *http.Request) []byte {
+	if r.Body == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+type bodyCapture struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *bodyCapture) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCapture) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyCapture) capture(b []byte) {
+	room := maxLoggedBody - w.buf.Len()
+	if room <= 0 {
+		return
+	}
+	if len(b) > room {
+		b = b[:room]
+	}
+	w.buf.Write(b)
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactHeaders(h http.Header) map[string]string {
+	res := make(map[string]string, len(h))
+	for k, v := range h {
+		if _, ok := redactedHeaders[http.CanonicalHeaderKey(k)]; ok {
+			res[k] = redacted
+			continue
+		}
+		res[k] = strings.Join(v, ", ")
+	}
+	return res
+}
+
+func redactQuery(q url.Values) string {
+	for k := range q {
+		if isSensitive(k) {
+			q.Set(k, redacted)
+		}
+	}
+	return q.Encode()
+}
+
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		// Truncated or non JSON bodies can not be redacted reliably.
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+
+	redactedBody, err := json.Marshal(redactValue(parsed))
+	if err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	return string(redactedBody)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, inner := range val {
+			if isSensitive(k) {
+				val[k] = redacted
+				continue
+			}
+			val[k] = redactValue(inner)
+		}
+		return val
+	case []interface{}:
+		for i := range val {
+			val[i] = redactValue(val[i])
+		}
+		return val
+	default:
+		return v
+	}
+}
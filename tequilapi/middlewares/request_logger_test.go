@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package middlewares
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	buf := &bytes.Buffer{}
+	original := log.Logger
+	log.Logger = zerolog.New(buf)
+	t.Cleanup(func() { log.Logger = original })
+	return buf
+}
+
+func serveLogged(cfg RequestLogConfig, method, target, body string) (*httptest.ResponseRecorder, string) {
+	g := gin.New()
+	g.Use(NewRequestLogger(cfg))
+	g.Any("/*path", func(c *gin.Context) {
+		received, _ := io.ReadAll(c.Request.Body)
+		c.Header("Set-Cookie", "session=abc")
+		c.Data(http.StatusOK, "application/json", received)
+	})
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer very-secret")
+	resp := httptest.NewRecorder()
+	g.ServeHTTP(resp, req)
+	return resp, string(resp.Body.Bytes())
+}
+
+func TestRequestLogger_RedactsCredentials(t *testing.T) {
+	buf := captureLog(t)
+	body := `{"id":"0x1","passphrase":"hunter2","nested":{"newPassword":"p4ss"},"list":[{"access_token":"t0k3n"}]}`
+
+	resp, echoed := serveLogged(RequestLogConfig{SampleRate: 1, Bodies: true}, http.MethodPut, "/identities/0x1/unlock?token=abc&limit=2", body)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, body, echoed, "handler must receive the original body")
+
+	logged := buf.String()
+	for _, secret := range []string{"very-secret", "hunter2", "p4ss", "t0k3n", "session=abc", "token=abc"} {
+		assert.NotContains(t, logged, secret)
+	}
+	assert.Contains(t, logged, `"path":"/identities/0x1/unlock"`)
+	assert.Contains(t, logged, "limit=2")
+	assert.Contains(t, logged, `\"id\":\"0x1\"`)
+	assert.Contains(t, logged, redacted)
+}
+
+func TestRequestLogger_SkipsBodiesByDefault(t *testing.T) {
+	buf := captureLog(t)
+
+	serveLogged(RequestLogConfig{SampleRate: 1}, http.MethodPost, "/identities", `{"passphrase":"hunter2"}`)
+
+	assert.Contains(t, buf.String(), `"status":200`)
+	assert.NotContains(t, buf.String(), "request_body")
+}
+
+func TestRequestLogger_EndpointToggles(t *testing.T) {
+	cfg := RequestLogConfig{SampleRate: 1, Include: []string{"/identities"}, Exclude: []string{"/identities/current"}}
+
+	for path, expected := range map[string]bool{
+		"/identities":         true,
+		"/identities/0x1":     true,
+		"/identities/current": false,
+		"/healthcheck":        false,
+	} {
+		buf := captureLog(t)
+		serveLogged(cfg, http.MethodGet, path, "")
+		assert.Equal(t, expected, buf.Len() > 0, path)
+	}
+}
+
+func TestRequestLogger_Sampling(t *testing.T) {
+	buf := captureLog(t)
+
+	serveLogged(RequestLogConfig{SampleRate: 0}, http.MethodGet, "/identities", "")
+
+	assert.Zero(t, buf.Len())
+}
+
+func TestRedactBody_NonJSON(t *testing.T) {
+	assert.Equal(t, "<9 bytes>", redactBody([]byte("not json!")))
+	assert.Equal(t, "", redactBody(nil))
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// WeakETag returns the weak entity tag of the given version. Entity tags are weak
+// as the same version of the response may be sent either compressed or not.
+func WeakETag(version string) string {
+	return `W/"` + version + `"`
+}
+
+// ContentETag returns the weak entity tag derived from the response body.
+func ContentETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return WeakETag(hex.EncodeToString(sum[:16]))
+}
+
+// NotModified sets the entity tag of the response and reports whether the client already holds it.
+// Response is completed with 304 Not Modified status in that case and no body must be written.
+func NotModified(writer http.ResponseWriter, req *http.Request, etag string) bool {
+	writer.Header().Set("ETag", etag)
+	writer.Header().Set("Cache-Control", "no-cache")
+
+	if !etagMatches(req.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+
+	writer.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches compares the entity tags listed in If-None-Match header using the weak comparison.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package utils
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ContentTypeNDJSON is a content type of newline delimited JSON stream.
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// AcceptsGzip reports whether the client is able to decode gzip compressed response.
+func AcceptsGzip(req *http.Request) bool {
+	for _, value := range req.Header.Values("Accept-Encoding") {
+		for _, encoding := range strings.Split(value, ",") {
+			if strings.TrimSpace(strings.Split(encoding, ";")[0]) == "gzip" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CompressedWriter returns a writer which gzip compresses the response body if the
+// client supports it. Returned close function must be called once the body is written.
+func CompressedWriter(writer http.ResponseWriter, req *http.Request) (http.ResponseWriter, func()) {
+	if !AcceptsGzip(req) {
+		return writer, func() {}
+	}
+
+	writer.Header().Set("Content-Encoding", "gzip")
+	writer.Header().Add("Vary", "Accept-Encoding")
+	writer.Header().Del("Content-Length")
+
+	gz := gzip.NewWriter(writer)
+	return &gzipResponseWriter{ResponseWriter: writer, gz: gz}, func() {
+		if err := gz.Close(); err != nil {
+			log.Error().Err(err).Msg("Closing compressed response body failed")
+		}
+	}
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	gz *gzip.Writer
+}
+
+func (w *gzipResponseWriter) Write(b []byte) (int, error) {
+	return w.gz.Write(b)
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if err := w.gz.Flush(); err != nil {
+		return
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// ndjsonFlushEvery is the number of values written between flushes to the client.
+const ndjsonFlushEvery = 100
+
+// NDJSONStream writes values as newline delimited JSON flushing them to the
+// client in batches, so large lists do not have to be kept in memory.
+type NDJSONStream struct {
+	writer  http.ResponseWriter
+	encoder *json.Encoder
+	written int
+}
+
+// NewNDJSONStream creates a new newline delimited JSON stream.
+func NewNDJSONStream(writer http.ResponseWriter) *NDJSONStream {
+	writer.Header().Set("Content-type", ContentTypeNDJSON+"; charset=utf-8")
+	return &NDJSONStream{
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+}
+
+// Write encodes value as a single line of the stream.
+func (s *NDJSONStream) Write(v interface{}) error {
+	if err := s.encoder.Encode(v); err != nil {
+		return err
+	}
+
+	s.written++
+	if s.written%ndjsonFlushEvery == 0 {
+		s.Flush()
+	}
+	return nil
+}
+
+// Flush sends the buffered values to the client.
+func (s *NDJSONStream) Flush() {
+	if flusher, ok := s.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
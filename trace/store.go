@@ -0,0 +1,177 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trace
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// StageFirstPayment is the span from the start of the consumer connection until its first paid invoice.
+const StageFirstPayment = "Consumer first payment"
+
+// DefaultStoreSize is the number of the most recent session traces kept by the store.
+const DefaultStoreSize = 100
+
+// Store keeps the finished traces of the most recent sessions.
+type Store struct {
+	mu     sync.Mutex
+	size   int
+	traces map[string]*sessionTrace
+	order  []string
+
+	// paid holds the first payments which arrived before the session trace,
+	// most sessions are never traced so they are kept apart not to evict the traces.
+	paid      map[string]time.Time
+	paidOrder []string
+}
+
+type sessionTrace struct {
+	spans       []Span
+	firstPaidAt time.Time
+}
+
+// NewStore returns a new store keeping up to size session traces.
+func NewStore(size int) *Store {
+	return &Store{
+		size:   size,
+		traces: make(map[string]*sessionTrace),
+		paid:   make(map[string]time.Time),
+	}
+}
+
+// Subscribe subscribes the store to the trace and payment events.
+func (s *Store) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(AppTopicTraceFinished, s.consumeTraceFinished); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(pingpongEvent.AppTopicInvoicePaid, s.consumeInvoicePaid)
+}
+
+// Get returns the spans of the given session trace.
+func (s *Store) Get(sessionID string) ([]Span, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.traces[sessionID]
+	if !ok || len(t.spans) == 0 {
+		return nil, false
+	}
+
+	spans := make([]Span, len(t.spans))
+	copy(spans, t.spans)
+	return spans, true
+}
+
+func (s *Store) consumeTraceFinished(e FinishedEvent) {
+	if e.ID == "" || len(e.Spans) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.traces[e.ID]
+	if !ok {
+		t = s.add(e.ID)
+	}
+	t.spans = append([]Span(nil), e.Spans...)
+	if paidAt, ok := s.takePaid(e.ID); ok {
+		t.firstPaidAt = paidAt
+	}
+	s.addFirstPayment(t)
+}
+
+func (s *Store) consumeInvoicePaid(e pingpongEvent.AppEventInvoicePaid) {
+	if e.SessionID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.traces[e.SessionID]
+	if !ok {
+		s.addPaid(e.SessionID, time.Now())
+		return
+	}
+	if !t.firstPaidAt.IsZero() {
+		return
+	}
+	t.firstPaidAt = time.Now()
+	s.addFirstPayment(t)
+}
+
+// addFirstPayment adds the first payment span once both the trace and the first payment are known.
+func (s *Store) addFirstPayment(t *sessionTrace) {
+	if t.firstPaidAt.IsZero() || len(t.spans) == 0 {
+		return
+	}
+
+	started := t.spans[0].Started
+	t.spans = append(t.spans, Span{
+		Key:      StageFirstPayment,
+		Started:  started,
+		Duration: t.firstPaidAt.Sub(started),
+	})
+}
+
+func (s *Store) add(sessionID string) *sessionTrace {
+	if len(s.order) >= s.size {
+		delete(s.traces, s.order[0])
+		s.order = s.order[1:]
+	}
+
+	t := &sessionTrace{}
+	s.traces[sessionID] = t
+	s.order = append(s.order, sessionID)
+	return t
+}
+
+func (s *Store) addPaid(sessionID string, paidAt time.Time) {
+	if _, ok := s.paid[sessionID]; ok {
+		return
+	}
+
+	if len(s.paidOrder) >= s.size {
+		delete(s.paid, s.paidOrder[0])
+		s.paidOrder = s.paidOrder[1:]
+	}
+
+	s.paid[sessionID] = paidAt
+	s.paidOrder = append(s.paidOrder, sessionID)
+}
+
+func (s *Store) takePaid(sessionID string) (time.Time, bool) {
+	paidAt, ok := s.paid[sessionID]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	delete(s.paid, sessionID)
+	for i, id := range s.paidOrder {
+		if id == sessionID {
+			s.paidOrder = append(s.paidOrder[:i], s.paidOrder[i+1:]...)
+			break
+		}
+	}
+	return paidAt, true
+}
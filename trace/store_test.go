@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package trace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+func TestStore_KeepsFinishedTraces(t *testing.T) {
+	store := NewStore(2)
+	started := time.Now()
+
+	store.consumeTraceFinished(FinishedEvent{ID: "1", Spans: []Span{{Key: "Consumer whole Connect", Started: started, Duration: time.Second}}})
+	store.consumeTraceFinished(FinishedEvent{ID: "", Spans: []Span{{Key: "Consumer whole Connect", Started: started, Duration: time.Second}}})
+
+	spans, ok := store.Get("1")
+	assert.True(t, ok)
+	assert.Equal(t, []Span{{Key: "Consumer whole Connect", Started: started, Duration: time.Second}}, spans)
+
+	_, ok = store.Get("")
+	assert.False(t, ok)
+}
+
+func TestStore_EvictsOldestTraces(t *testing.T) {
+	store := NewStore(2)
+
+	for _, id := range []string{"1", "2", "3"} {
+		store.consumeTraceFinished(FinishedEvent{ID: id, Spans: []Span{{Key: "Consumer whole Connect"}}})
+	}
+
+	_, ok := store.Get("1")
+	assert.False(t, ok)
+	_, ok = store.Get("2")
+	assert.True(t, ok)
+	_, ok = store.Get("3")
+	assert.True(t, ok)
+}
+
+func TestStore_PaymentsDoNotEvictTraces(t *testing.T) {
+	store := NewStore(2)
+	store.consumeTraceFinished(FinishedEvent{ID: "1", Spans: []Span{{Key: "Consumer whole Connect"}}})
+
+	for _, id := range []string{"2", "3", "4"} {
+		store.consumeInvoicePaid(pingpongEvent.AppEventInvoicePaid{SessionID: id})
+	}
+
+	_, ok := store.Get("1")
+	assert.True(t, ok)
+	_, ok = store.Get("2")
+	assert.False(t, ok)
+	assert.Len(t, store.traces, 1)
+	assert.Len(t, store.paid, 2)
+
+	store.consumeTraceFinished(FinishedEvent{ID: "2", Spans: []Span{{Key: "Consumer whole Connect"}}})
+	result, ok := store.Get("2")
+	assert.True(t, ok)
+	assert.Len(t, result, 1)
+
+	store.consumeTraceFinished(FinishedEvent{ID: "4", Spans: []Span{{Key: "Consumer whole Connect"}}})
+	result, ok = store.Get("4")
+	assert.True(t, ok)
+	assert.Len(t, result, 2)
+	assert.Len(t, store.paid, 1)
+}
+
+func TestStore_AddsFirstPayment(t *testing.T) {
+	started := time.Now().Add(-time.Minute)
+	spans := []Span{{Key: "Consumer whole Connect", Started: started, Duration: time.Second}}
+
+	t.Run("payment after trace", func(t *testing.T) {
+		store := NewStore(DefaultStoreSize)
+		store.consumeTraceFinished(FinishedEvent{ID: "1", Spans: spans})
+		store.consumeInvoicePaid(pingpongEvent.AppEventInvoicePaid{SessionID: "1"})
+		store.consumeInvoicePaid(pingpongEvent.AppEventInvoicePaid{SessionID: "1"})
+
+		result, ok := store.Get("1")
+		assert.True(t, ok)
+		assert.Len(t, result, 2)
+		assert.Equal(t, StageFirstPayment, result[1].Key)
+		assert.Equal(t, started, result[1].Started)
+		assert.GreaterOrEqual(t, result[1].Duration, time.Minute)
+	})
+
+	t.Run("payment before trace", func(t *testing.T) {
+		store := NewStore(DefaultStoreSize)
+		store.consumeInvoicePaid(pingpongEvent.AppEventInvoicePaid{SessionID: "1"})
+
+		_, ok := store.Get("1")
+		assert.False(t, ok)
+
+		store.consumeTraceFinished(FinishedEvent{ID: "1", Spans: spans})
+
+		result, ok := store.Get("1")
+		assert.True(t, ok)
+		assert.Len(t, result, 2)
+		assert.Equal(t, StageFirstPayment, result[1].Key)
+	})
+}
@@ -30,8 +30,15 @@ import (
 const (
 	// AppTopicTraceEvent represents event topic for Trace events
 	AppTopicTraceEvent = "Trace"
+	// AppTopicTraceFinished represents event topic for finished traces with all of their spans
+	AppTopicTraceFinished = "TraceFinished"
 )
 
+func init() {
+	eventbus.RegisterSchema(AppTopicTraceEvent, 1, Event{})
+	eventbus.RegisterSchema(AppTopicTraceFinished, 1, FinishedEvent{})
+}
+
 // NewTracer returns new tracer instance.
 func NewTracer(name string) *Tracer {
 	tracer := &Tracer{
@@ -98,15 +105,21 @@ func (t *Tracer) Finish(eventPublisher eventbus.Publisher, id string) string {
 	t.finished = true
 
 	var strs []string
+	var spans []Span
 	for _, s := range t.stages {
 		if s.end.After(time.Time{}) {
 			t.publishStageEvent(eventPublisher, id, *s)
 			strs = append(strs, fmt.Sprintf("%q took %s", s.key, s.end.Sub(s.start).String()))
+			spans = append(spans, Span{Key: s.key, Started: s.start, Duration: s.end.Sub(s.start)})
 		} else {
 			strs = append(strs, fmt.Sprintf("%q did not start", s.key))
 		}
 	}
 
+	if eventPublisher != nil {
+		eventPublisher.Publish(AppTopicTraceFinished, FinishedEvent{ID: id, Spans: spans})
+	}
+
 	return strings.Join(strs, ", ")
 }
 
@@ -144,3 +157,16 @@ type Event struct {
 	Key      string
 	Duration time.Duration
 }
+
+// Span represents a finished stage of the trace.
+type Span struct {
+	Key      string
+	Started  time.Time
+	Duration time.Duration
+}
+
+// FinishedEvent represents a published finished trace, the first span covers the whole trace.
+type FinishedEvent struct {
+	ID    string
+	Spans []Span
+}
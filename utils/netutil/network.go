@@ -33,6 +33,33 @@ func AddDefaultRoute(iface string) error {
 	return addDefaultRoute(iface)
 }
 
+// AddRoutes adds given VPN tunnel routes, default route is added if no routes are given.
+func AddRoutes(iface string, routes []net.IPNet) error {
+	if len(routes) == 0 {
+		return addDefaultRoute(iface)
+	}
+
+	for _, route := range routes {
+		if err := addRoute(iface, route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsIPv6 reports whether the given IP is an IPv6 address, IPv4-mapped addresses are treated as IPv4.
+func IsIPv6(ip net.IP) bool {
+	return ip.To4() == nil && ip.To16() != nil
+}
+
+// UDPNetwork returns UDP network matching the address family of the given IP.
+func UDPNetwork(ip net.IP) string {
+	if IsIPv6(ip) {
+		return "udp6"
+	}
+	return "udp4"
+}
+
 // AssignIP assigns subnet to given interface.
 func AssignIP(iface string, subnet net.IPNet) error {
 	return assignIP(iface, subnet)
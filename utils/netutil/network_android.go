@@ -40,6 +40,10 @@ func deleteRoute(ip, gw string) error {
 	return nil
 }
 
+func addRoute(iface string, route net.IPNet) error {
+	return nil
+}
+
 func addDefaultRoute(iface string) error {
 	return nil
 }
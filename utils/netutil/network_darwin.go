@@ -45,6 +45,13 @@ func deleteRoute(ip, gw string) error {
 	return cmdutil.SudoExec("route", "delete", ip, gw)
 }
 
+func addRoute(iface string, route net.IPNet) error {
+	if route.IP.To4() == nil {
+		return cmdutil.SudoExec("route", "add", "-inet6", route.String(), fmt.Sprintf("100::1%%%s", iface))
+	}
+	return cmdutil.SudoExec("route", "add", "-net", route.String(), "-interface", iface)
+}
+
 func addDefaultRoute(iface string) error {
 	if err := cmdutil.SudoExec("route", "add", "-net", "0.0.0.0/1", "-interface", iface); err != nil {
 		return err
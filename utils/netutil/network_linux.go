@@ -44,6 +44,13 @@ func deleteRoute(ip, gw string) error {
 	return cmdutil.SudoExec("ip", "route", "delete", ip, "via", gw)
 }
 
+func addRoute(iface string, route net.IPNet) error {
+	if route.IP.To4() == nil {
+		return cmdutil.SudoExec("ip", "-6", "route", "add", route.String(), "dev", iface)
+	}
+	return cmdutil.SudoExec("ip", "route", "add", route.String(), "dev", iface)
+}
+
 func addDefaultRoute(iface string) error {
 	if err := cmdutil.SudoExec("ip", "route", "add", "0.0.0.0/1", "dev", iface); err != nil {
 		return err
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netutil
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUDPNetwork(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   net.IP
+		want string
+	}{
+		{name: "IPv4", ip: net.ParseIP("1.2.3.4"), want: "udp4"},
+		{name: "IPv4-mapped IPv6", ip: net.ParseIP("::ffff:1.2.3.4"), want: "udp4"},
+		{name: "IPv6", ip: net.ParseIP("64:ff9b::102:304"), want: "udp6"},
+		{name: "Invalid IP", ip: nil, want: "udp4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UDPNetwork(tt.ip))
+			assert.Equal(t, tt.want == "udp6", IsIPv6(tt.ip))
+		})
+	}
+}
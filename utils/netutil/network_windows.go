@@ -48,6 +48,22 @@ func deleteRoute(ip, gw string) error {
 	return nil
 }
 
+func addRoute(name string, route net.IPNet) error {
+	id, gw, err := interfaceInfo(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get info of interface: "+name)
+	}
+
+	if route.IP.To4() == nil {
+		gw = "100::1"
+	}
+	if out, err := exec.Command("powershell", "-Command", "route add "+route.String()+" "+gw+" if "+id).CombinedOutput(); err != nil {
+		return errors.Wrap(err, string(out))
+	}
+
+	return nil
+}
+
 func addDefaultRoute(name string) error {
 	id, gw, err := interfaceInfo(name)
 	if err != nil {
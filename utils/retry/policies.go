@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package retry
+
+import "time"
+
+// Retry policies of the node, kept together so that the behaviour is tuned in one place.
+var (
+	// Hermes is used for hermes (accountant) calls made while exchanging promises.
+	Hermes = Constant(500*time.Millisecond, 3)
+	// HermesSync is used when node state is synced with hermes in the background.
+	HermesSync = Exponential(time.Second, 20*time.Second, 10)
+	// Transactor is used when transactor did not accept a submitted transaction.
+	// Fees quoted for the transaction expire in about 5 minutes, so retrying longer makes no sense.
+	Transactor = Constant(30*time.Second, 10)
+	// TransactorStatus is used for transactor status checks, it is bound by the caller context only.
+	TransactorStatus = Constant(500*time.Millisecond, 0)
+	// Discovery is used for the proposal registration in discovery.
+	Discovery = Policy{Interval: time.Minute, Jitter: 0.2}
+	// RPC is used when reconnecting blockchain RPC clients. Default DNS resolver reloads
+	// /etc/resolv.conf at most once per 5 seconds, a single retry covers that lag.
+	RPC = Constant(0, 1)
+	// Webhook is used for the delivery of events to the webhooks of the node operator.
+	// The first delay and the number of retries are configured by the operator.
+	Webhook = Policy{Interval: time.Second, Multiplier: 2, MaxInterval: 10 * time.Minute}
+)
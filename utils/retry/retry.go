@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package retry
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// Policy describes how a failing operation is retried.
+type Policy struct {
+	// Interval is the delay before the first retry.
+	Interval time.Duration
+	// Multiplier grows the delay after each retry, values below 1 keep it constant.
+	Multiplier float64
+	// MaxInterval caps the delay, zero means no cap.
+	MaxInterval time.Duration
+	// Jitter randomizes each delay by the given factor, e.g. 0.2 gives ±20%.
+	Jitter float64
+	// MaxElapsedTime stops retrying once exceeded, zero means no limit.
+	MaxElapsedTime time.Duration
+	// MaxRetries limits the number of retries, zero means no limit.
+	MaxRetries uint64
+}
+
+// Constant returns a policy retrying with a fixed interval.
+func Constant(interval time.Duration, maxRetries uint64) Policy {
+	return Policy{Interval: interval, MaxRetries: maxRetries}
+}
+
+// Exponential returns a policy with the exponentially growing interval.
+func Exponential(interval time.Duration, maxElapsedTime time.Duration, maxRetries uint64) Policy {
+	return Policy{
+		Interval:       interval,
+		Multiplier:     backoff.DefaultMultiplier,
+		Jitter:         backoff.DefaultRandomizationFactor,
+		MaxElapsedTime: maxElapsedTime,
+		MaxRetries:     maxRetries,
+	}
+}
+
+// Do runs the operation until it succeeds, the policy gives up or the context is done.
+// The last error of the operation is returned, unless the operation marks it as Permanent.
+func Do(ctx context.Context, policy Policy, operation func() error) error {
+	return backoff.Retry(operation, policy.backOff(ctx))
+}
+
+// Permanent wraps the error to stop retrying, Do returns the wrapped error as is.
+func Permanent(err error) error {
+	return backoff.Permanent(err)
+}
+
+// UntilClosed returns a context which is cancelled once the stop channel is closed.
+func UntilClosed(stop <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+func (p Policy) backOff(ctx context.Context) backoff.BackOff {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	maxInterval := p.MaxInterval
+	if maxInterval == 0 {
+		maxInterval = time.Duration(math.MaxInt64)
+	}
+
+	eb := &backoff.ExponentialBackOff{
+		InitialInterval:     p.Interval,
+		RandomizationFactor: p.Jitter,
+		Multiplier:          multiplier,
+		MaxInterval:         maxInterval,
+		MaxElapsedTime:      p.MaxElapsedTime,
+		Stop:                backoff.Stop,
+		Clock:               backoff.SystemClock,
+	}
+	eb.Reset()
+
+	var b backoff.BackOff = eb
+	if p.MaxRetries > 0 {
+		b = backoff.WithMaxRetries(b, p.MaxRetries)
+	}
+	return backoff.WithContext(b, ctx)
+}
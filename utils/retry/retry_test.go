@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2022 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errFailed = errors.New("failed")
+
+func TestDo_RetriesUntilSuccess(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Constant(time.Millisecond, 5), func() error {
+		calls++
+		if calls < 3 {
+			return errFailed
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDo_GivesUpAfterMaxRetries(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Constant(time.Millisecond, 2), func() error {
+		calls++
+		return errFailed
+	})
+
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestDo_StopsOnPermanentError(t *testing.T) {
+	calls := 0
+	err := Do(context.Background(), Constant(time.Millisecond, 5), func() error {
+		calls++
+		return Permanent(errFailed)
+	})
+
+	assert.Equal(t, errFailed, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestDo_StopsWhenMaxElapsedTimeExceeded(t *testing.T) {
+	policy := Policy{Interval: 10 * time.Millisecond, MaxElapsedTime: 25 * time.Millisecond}
+
+	calls := 0
+	err := Do(context.Background(), policy, func() error {
+		calls++
+		return errFailed
+	})
+
+	assert.Equal(t, errFailed, err)
+	assert.True(t, calls >= 2 && calls <= 4, "unexpected calls: %d", calls)
+}
+
+func TestDo_StopsWhenClosed(t *testing.T) {
+	stop := make(chan struct{})
+	ctx, cancel := UntilClosed(stop)
+	defer cancel()
+
+	calls := 0
+	err := Do(ctx, Constant(time.Hour, 0), func() error {
+		calls++
+		close(stop)
+		return errFailed
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestPolicy_Jitter(t *testing.T) {
+	b := Policy{Interval: 100 * time.Millisecond, Multiplier: 2, MaxInterval: 300 * time.Millisecond, Jitter: 0.5}.backOff(context.Background())
+
+	for _, base := range []time.Duration{100, 200, 300, 300} {
+		next := b.NextBackOff()
+		base *= time.Millisecond
+		assert.GreaterOrEqual(t, next, base/2)
+		assert.LessOrEqual(t, next, base*3/2)
+	}
+}